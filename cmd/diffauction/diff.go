@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+// defaultIgnoredKeys change on every auction, so only their presence is compared
+var defaultIgnoredKeys = []string{"hb_adid", "hb_cache_id", "hb_uuid", "hb_cache_host", "hb_cache_path"}
+
+// compareOptions control what counts as a difference
+type compareOptions struct {
+	priceTolerance float64
+	ignoredKeys    map[string]bool
+}
+
+// winner is the highest-priced bid for one impression
+type winner struct {
+	Seat      string
+	Price     float64
+	DealID    string
+	Targeting map[string]string
+}
+
+// Difference is one mismatch between the two builds
+type Difference struct {
+	Request string `json:"request"`
+	ImpID   string `json:"imp_id,omitempty"`
+	Field   string `json:"field"`
+	A       string `json:"a"`
+	B       string `json:"b"`
+}
+
+// Report summarises a replay run
+type Report struct {
+	Requests    int          `json:"requests"`
+	Identical   int          `json:"identical"`
+	Differing   int          `json:"differing"`
+	Failed      int          `json:"failed"`
+	Differences []Difference `json:"differences"`
+}
+
+// replay sends every request to both targets and compares the responses
+func replay(ctx context.Context, requests []capturedRequest, a, b target, opts compareOptions) *Report {
+	report := &Report{Requests: len(requests), Differences: []Difference{}}
+	for _, req := range requests {
+		respA, errA := a.Auction(ctx, req.Body)
+		respB, errB := b.Auction(ctx, req.Body)
+		if errA != nil || errB != nil {
+			report.Failed++
+			report.Differences = append(report.Differences, Difference{
+				Request: req.Name,
+				Field:   "error",
+				A:       errString(errA),
+				B:       errString(errB),
+			})
+			continue
+		}
+
+		diffs := compareResponses(req.Name, respA, respB, opts)
+		if len(diffs) == 0 {
+			report.Identical++
+			continue
+		}
+		report.Differing++
+		report.Differences = append(report.Differences, diffs...)
+	}
+	return report
+}
+
+// compareResponses diffs the per-impression winners of two responses
+func compareResponses(name string, a, b *openrtb.BidResponse, opts compareOptions) []Difference {
+	winnersA := winners(a)
+	winnersB := winners(b)
+
+	var diffs []Difference
+	add := func(impID, field, va, vb string) {
+		diffs = append(diffs, Difference{Request: name, ImpID: impID, Field: field, A: va, B: vb})
+	}
+
+	for _, impID := range impIDs(winnersA, winnersB) {
+		wa, wb := winnersA[impID], winnersB[impID]
+		switch {
+		case wa == nil:
+			add(impID, "winner", "no bid", wb.Seat)
+			continue
+		case wb == nil:
+			add(impID, "winner", wa.Seat, "no bid")
+			continue
+		}
+
+		if wa.Seat != wb.Seat {
+			add(impID, "seat", wa.Seat, wb.Seat)
+		}
+		if math.Abs(wa.Price-wb.Price) > opts.priceTolerance {
+			add(impID, "price", formatPrice(wa.Price), formatPrice(wb.Price))
+		}
+		if wa.DealID != wb.DealID {
+			add(impID, "dealid", wa.DealID, wb.DealID)
+		}
+		for _, key := range targetingKeys(wa.Targeting, wb.Targeting) {
+			va, okA := wa.Targeting[key]
+			vb, okB := wb.Targeting[key]
+			if okA && okB && (va == vb || opts.ignoredKeys[key]) {
+				continue
+			}
+			if !okA {
+				va = "<missing>"
+			}
+			if !okB {
+				vb = "<missing>"
+			}
+			add(impID, "targeting."+key, va, vb)
+		}
+	}
+	return diffs
+}
+
+// winners picks the highest-priced bid for each impression
+func winners(resp *openrtb.BidResponse) map[string]*winner {
+	result := make(map[string]*winner)
+	if resp == nil {
+		return result
+	}
+	for _, sb := range resp.SeatBid {
+		for i := range sb.Bid {
+			bid := &sb.Bid[i]
+			if current, ok := result[bid.ImpID]; ok && current.Price >= bid.Price {
+				continue
+			}
+			result[bid.ImpID] = &winner{
+				Seat:      sb.Seat,
+				Price:     bid.Price,
+				DealID:    bid.DealID,
+				Targeting: bidTargeting(bid.Ext),
+			}
+		}
+	}
+	return result
+}
+
+// bidTargeting reads ext.prebid.targeting from a bid extension
+func bidTargeting(ext json.RawMessage) map[string]string {
+	if len(ext) == 0 {
+		return nil
+	}
+	var parsed struct {
+		Prebid struct {
+			Targeting map[string]string `json:"targeting"`
+		} `json:"prebid"`
+	}
+	if err := json.Unmarshal(ext, &parsed); err != nil {
+		return nil
+	}
+	return parsed.Prebid.Targeting
+}
+
+// impIDs returns the sorted union of impression IDs
+func impIDs(a, b map[string]*winner) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	for id := range a {
+		seen[id] = true
+	}
+	for id := range b {
+		seen[id] = true
+	}
+	return sortedKeys(seen)
+}
+
+// targetingKeys returns the sorted union of targeting keys
+func targetingKeys(a, b map[string]string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	for k := range a {
+		seen[k] = true
+	}
+	for k := range b {
+		seen[k] = true
+	}
+	return sortedKeys(seen)
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatPrice(p float64) string {
+	return fmt.Sprintf("%.4f", p)
+}
+
+func errString(err error) string {
+	if err == nil {
+		return "ok"
+	}
+	return err.Error()
+}
+
+// writeReport prints the report as text or JSON
+func writeReport(w io.Writer, report *Report, format string) error {
+	if format == outputJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+
+	for _, d := range report.Differences {
+		location := d.Request
+		if d.ImpID != "" {
+			location += " imp=" + d.ImpID
+		}
+		if _, err := fmt.Fprintf(w, "%s %s: %s -> %s\n", location, d.Field, quote(d.A), quote(d.B)); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "\n%d requests: %d identical, %d differing, %d failed\n",
+		report.Requests, report.Identical, report.Differing, report.Failed)
+	return err
+}
+
+func quote(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t") {
+		return fmt.Sprintf("%q", s)
+	}
+	return s
+}
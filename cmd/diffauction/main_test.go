@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+// fakeAuction serves a response built from the request's imps, so both
+// builds see the same request IDs
+func fakeAuction(t *testing.T, seat string, price float64, cacheID string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var req openrtb.BidRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		resp := openrtb.BidResponse{ID: req.ID}
+		sb := openrtb.SeatBid{Seat: seat}
+		for _, imp := range req.Imp {
+			ext, _ := json.Marshal(map[string]interface{}{"prebid": map[string]interface{}{"targeting": map[string]string{
+				"hb_bidder":   seat,
+				"hb_pb":       "1.50",
+				"hb_cache_id": cacheID,
+			}}})
+			sb.Bid = append(sb.Bid,
+				openrtb.Bid{ID: "low", ImpID: imp.ID, Price: 0.10},
+				openrtb.Bid{ID: "win", ImpID: imp.ID, Price: price, Ext: ext},
+			)
+		}
+		resp.SeatBid = []openrtb.SeatBid{sb}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func writeRequests(t *testing.T, lines ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "requests.log")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func runDiff(args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun_Identical(t *testing.T) {
+	a := fakeAuction(t, "appnexus", 1.5, "cache-a")
+	b := fakeAuction(t, "appnexus", 1.5, "cache-b")
+	requests := writeRequests(t,
+		`{"id":"r1","imp":[{"id":"1"}]}`,
+		`{"level":"debug","message":"Bid request received","request":{"id":"r2","imp":[{"id":"1"},{"id":"2"}]}}`,
+		`{"level":"info","message":"unrelated"}`,
+	)
+
+	code, out, errOut := runDiff("-a", a.URL, "-b", b.URL, "-requests", requests)
+	if code != 0 {
+		t.Fatalf("expected exit 0, got %d: %s%s", code, out, errOut)
+	}
+	if !strings.Contains(out, "2 requests: 2 identical, 0 differing, 0 failed") {
+		t.Errorf("unexpected summary: %s", out)
+	}
+}
+
+func TestRun_Differences(t *testing.T) {
+	a := fakeAuction(t, "appnexus", 1.5, "x")
+	b := fakeAuction(t, "rubicon", 1.2, "x")
+	requests := writeRequests(t, `{"id":"r1","imp":[{"id":"1"}]}`)
+
+	code, out, _ := runDiff("-a", a.URL, "-b", b.URL, "-requests", requests, "-o", "json")
+	if code != 1 {
+		t.Fatalf("expected exit 1, got %d", code)
+	}
+	var report Report
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatal(err)
+	}
+	fields := make(map[string]Difference)
+	for _, d := range report.Differences {
+		fields[d.Field] = d
+	}
+	for _, f := range []string{"seat", "price", "targeting.hb_bidder"} {
+		if _, ok := fields[f]; !ok {
+			t.Errorf("expected %s difference, got %+v", f, report.Differences)
+		}
+	}
+	if d := fields["price"]; d.A != "1.5000" || d.B != "1.2000" || d.ImpID != "1" {
+		t.Errorf("unexpected price difference: %+v", d)
+	}
+	if _, ok := fields["targeting.hb_pb"]; ok {
+		t.Error("hb_pb is equal and should not be reported")
+	}
+}
+
+func TestRun_Failures(t *testing.T) {
+	a := fakeAuction(t, "appnexus", 1.5, "x")
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer down.Close()
+	requests := writeRequests(t, `{"id":"r1","imp":[{"id":"1"}]}`)
+
+	code, out, _ := runDiff("-a", a.URL, "-b", down.URL, "-requests", requests)
+	if code != 1 || !strings.Contains(out, "1 failed") {
+		t.Errorf("expected a failed request, got %d: %s", code, out)
+	}
+
+	if code, _, _ := runDiff("-a", a.URL, "-requests", requests); code != 2 {
+		t.Errorf("expected usage error for missing -b, got %d", code)
+	}
+	if code, _, _ := runDiff("-a", a.URL, "-b", a.URL, "-requests", filepath.Join(t.TempDir(), "missing")); code != 2 {
+		t.Errorf("expected I/O error for missing file, got %d", code)
+	}
+}
+
+func TestCompareResponses(t *testing.T) {
+	ext := func(cacheID string) json.RawMessage {
+		return json.RawMessage(`{"prebid":{"targeting":{"hb_pb":"2.00","hb_cache_id":"` + cacheID + `"}}}`)
+	}
+	resp := func(price float64, cacheID string, imps ...string) *openrtb.BidResponse {
+		sb := openrtb.SeatBid{Seat: "appnexus"}
+		for _, id := range imps {
+			sb.Bid = append(sb.Bid, openrtb.Bid{ImpID: id, Price: price, Ext: ext(cacheID)})
+		}
+		return &openrtb.BidResponse{SeatBid: []openrtb.SeatBid{sb}}
+	}
+	opts := compareOptions{priceTolerance: 0.001, ignoredKeys: splitKeys("hb_cache_id")}
+
+	if diffs := compareResponses("r", resp(2, "a", "1"), resp(2.0005, "b", "1"), opts); len(diffs) != 0 {
+		t.Errorf("expected ignored key and tolerated price to match, got %+v", diffs)
+	}
+
+	diffs := compareResponses("r", resp(2, "a", "1", "2"), resp(2, "a", "1"), opts)
+	if len(diffs) != 1 || diffs[0].ImpID != "2" || diffs[0].B != "no bid" {
+		t.Errorf("expected missing winner for imp 2, got %+v", diffs)
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// maxLineSize bounds one JSON-lines entry (matches the auction body limit)
+const maxLineSize = 1024 * 1024
+
+// capturedRequest is one bid request to replay
+type capturedRequest struct {
+	Name string // file or file:line, for the report
+	Body []byte
+}
+
+// loadRequests reads requests from a .json file, a directory of .json files
+// or a JSON-lines file
+func loadRequests(path string) ([]capturedRequest, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return loadDir(path)
+	}
+	if strings.HasSuffix(path, ".json") {
+		body, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return []capturedRequest{{Name: filepath.Base(path), Body: body}}, nil
+	}
+	return loadLines(path)
+}
+
+// loadDir reads every .json file in a directory, in name order
+func loadDir(dir string) ([]capturedRequest, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(matches)
+
+	requests := make([]capturedRequest, 0, len(matches))
+	for _, m := range matches {
+		body, err := os.ReadFile(m)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, capturedRequest{Name: filepath.Base(m), Body: body})
+	}
+	return requests, nil
+}
+
+// loadLines reads one request per line. A line that is a log entry with a
+// "request" object yields that object; other log lines are skipped.
+func loadLines(path string) ([]capturedRequest, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var requests []capturedRequest
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	name := filepath.Base(path)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		body, ok, err := extractRequest(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", name, lineNo, err)
+		}
+		if ok {
+			requests = append(requests, capturedRequest{Name: fmt.Sprintf("%s:%d", name, lineNo), Body: body})
+		}
+	}
+	return requests, scanner.Err()
+}
+
+// extractRequest returns the bid request held by a JSON line: either the
+// line itself, or the "request" field of a log entry
+func extractRequest(line []byte) ([]byte, bool, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(line, &fields); err != nil {
+		return nil, false, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if req, ok := fields["request"]; ok {
+		return req, true, nil
+	}
+	if _, ok := fields["imp"]; ok {
+		return append([]byte(nil), line...), true, nil
+	}
+	return nil, false, nil
+}
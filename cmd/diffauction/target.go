@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+// maxResponseBody bounds how much of an auction response is read
+const maxResponseBody = 4 * 1024 * 1024
+
+// target runs an auction for a raw bid request. An in-process exchange
+// pipeline can implement it to diff two pipeline versions without servers.
+type target interface {
+	Name() string
+	Auction(ctx context.Context, body []byte) (*openrtb.BidResponse, error)
+}
+
+// httpTarget posts requests to a running PBS build
+type httpTarget struct {
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+// newHTTPTarget creates a target for baseURL+path
+func newHTTPTarget(baseURL, path, apiKey string, timeout time.Duration) *httpTarget {
+	return &httpTarget{
+		url:    strings.TrimRight(baseURL, "/") + path,
+		apiKey: apiKey,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Name implements target
+func (t *httpTarget) Name() string {
+	return t.url
+}
+
+// Auction implements target. A 204 is returned as an empty response.
+func (t *httpTarget) Auction(ctx context.Context, body []byte) (*openrtb.BidResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if t.apiKey != "" {
+		req.Header.Set("X-API-Key", t.apiKey)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNoContent {
+		return &openrtb.BidResponse{}, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	var bidResp openrtb.BidResponse
+	if err := json.Unmarshal(data, &bidResp); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+	return &bidResp, nil
+}
@@ -1,13 +1,38 @@
 package main
 
 import (
+	"crypto/ed25519"
+	"encoding/base64"
 	"flag"
 	"fmt"
 	"os"
 	"strconv"
 	"time"
 
+	"github.com/thenexusengine/tne_springwire/internal/analytics"
+	"github.com/thenexusengine/tne_springwire/internal/analytics/kafka"
+	"github.com/thenexusengine/tne_springwire/internal/auctionregistry"
+	"github.com/thenexusengine/tne_springwire/internal/auctiontrail"
+	"github.com/thenexusengine/tne_springwire/internal/bidcache"
+	"github.com/thenexusengine/tne_springwire/internal/creatives"
+	"github.com/thenexusengine/tne_springwire/internal/currency"
+	"github.com/thenexusengine/tne_springwire/internal/deals"
+	"github.com/thenexusengine/tne_springwire/internal/devicegraph"
+	"github.com/thenexusengine/tne_springwire/internal/endpoints"
 	"github.com/thenexusengine/tne_springwire/internal/exchange"
+	"github.com/thenexusengine/tne_springwire/internal/floors"
+	"github.com/thenexusengine/tne_springwire/internal/health"
+	"github.com/thenexusengine/tne_springwire/internal/houseads"
+	"github.com/thenexusengine/tne_springwire/internal/idmodules"
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+	"github.com/thenexusengine/tne_springwire/internal/rollup"
+	"github.com/thenexusengine/tne_springwire/internal/slo"
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+	"github.com/thenexusengine/tne_springwire/pkg/deadline"
+	"github.com/thenexusengine/tne_springwire/pkg/domainmatch"
+	"github.com/thenexusengine/tne_springwire/pkg/featureflags"
+	"github.com/thenexusengine/tne_springwire/pkg/kv"
+	"github.com/thenexusengine/tne_springwire/pkg/sessionhash"
 )
 
 // ServerConfig holds all server configuration
@@ -22,21 +47,209 @@ type ServerConfig struct {
 	// Redis
 	RedisURL string
 
+	// KV store backend: redis (default), memcached or memory
+	KVBackend        string
+	MemcachedServers []string
+
 	// IDR
 	IDREnabled bool
 	IDRUrl     string
 	IDRAPIKey  string
 
+	// Longest a Postgres, Redis, Memcached or IDR call may take on the
+	// auction path, keyed by deadline dependency name (0 = no cap)
+	DependencyCaps map[string]time.Duration
+
 	// Currency
 	CurrencyConversionEnabled bool
 	DefaultCurrency           string
 
 	// Privacy
-	DisableGDPREnforcement bool
+	DisableGDPREnforcement bool // Deprecated: per-bidder GDPR scopes take precedence
 
 	// Cookie Sync
 	HostURL string
 
+	// Billing window for bids without exp
+	ImpExpiry time.Duration
+
+	// Per-request cap on bidders called (0 = exchange default)
+	MaxBidders int
+
+	// JSON file of generic OpenRTB bidders registered alongside the
+	// built-in adapters (empty = none)
+	OrtbBiddersFile string
+
+	// JSON file of identity modules (UID2, RampID or HTTP identity
+	// services) whose IDs are added to user.eids (empty = none)
+	IDModulesFile string
+
+	// UID2 operator endpoint that validates and refreshes the UID2 tokens
+	// publishers send (empty URL = forward them unvalidated)
+	UID2 idmodules.UID2Config
+
+	// Comma-separated Go plugin files or directories of private adapters
+	// registered alongside the built-in ones (empty = none)
+	AdapterPlugins string
+
+	// How long stored request templates are cached in the KV store
+	// (0 = storedrequest default)
+	StoredRequestCacheTTL time.Duration
+
+	// How long admin mutation results are kept for replay to retries with
+	// the same Idempotency-Key (0 = middleware default)
+	AdminIdempotencyTTL time.Duration
+
+	// Bids above this CPM are rejected as anomalous (0 = exchange default)
+	MaxBidCPM float64
+
+	// Bidder responses larger than this are rejected unread (0 = 1MB)
+	MaxBidderResponseBytes int
+
+	// Fraction of auctions whose allocations and heap size are exported as
+	// histograms (0 = off)
+	AllocSampleRate float64
+
+	// Banner markup sanitization level for publishers without their own
+	// (off, standard, strict) and the click macro prefixed to creative links
+	CreativeSanitization string
+	CreativeClickMacro   string
+
+	// How bids at the same price are ranked (response_order, weighted or
+	// deal_priority) and per-bidder weights ("bidder:weight,...")
+	TieBreak        string
+	TieBreakWeights string
+
+	// Identifier fields scrubbed before fan-out on COPPA and limited ad
+	// tracking traffic ("user_ids,buyeruid,geo,ifa,ip", all or none; "" =
+	// the defaults). COPPA must scrub at least user_ids and ifa.
+	COPPAScrubFields string
+	LMTScrubFields   string
+
+	// SSAI partners (publisher IDs) whose latency budget adherence is
+	// reported under their own label; other callers are reported as "other"
+	LatencyBudgetPartners []string
+
+	// ISO 3166-1 alpha-3 countries no auction is run for, e.g. sanctioned
+	// countries; publishers can block or allow further countries themselves
+	BlockedCountries []string
+
+	// Supply chain node appended to bid requests: the exchange's domain and
+	// the seller ID, when not the publisher's own ID (see SCHAIN_ASI)
+	SChainASI string
+	SChainSID string
+
+	// Currency rates ("CUR:rate" in DefaultCurrency units) and per-bidder
+	// bidding currencies ("bidder:CUR"); used when conversion is enabled
+	CurrencyRates    string
+	BidderCurrencies string
+
+	// Live exchange rates fetched from ECB or openexchangerates; static
+	// CurrencyRates fill in currencies the source doesn't quote
+	CurrencyFeed currency.FeedConfig
+
+	// Win/billing notice workers (0 = notices are not processed)
+	WinQueueWorkers int
+
+	// Start in warm standby: serve only mirrored shadow traffic until
+	// activated via /admin/standby/activate
+	Standby bool
+
+	// Window in which repeat (bid_id, event) video tracking pixels are
+	// dropped (0 = no dedup)
+	VideoEventDedupWindow time.Duration
+
+	// Validate outgoing VAST against the target version (off, debug,
+	// strict); strict serves a VAST error in place of invalid documents
+	VASTValidation string
+
+	// Share /admin/cache/invalidate commands with other replicas over Redis pub/sub
+	CacheInvalidationPubSub bool
+
+	// Signing keys for X-Bid-Injection ("id:secret"); production only
+	// accepts the key IDs listed in BidInjectionProdKeys
+	BidInjectionKeys     string
+	BidInjectionProdKeys []string
+
+	// Salts for hashing session IDs into experiment and exploration buckets;
+	// SessionHashNextSalt replaces SessionHashSalt at SessionHashRotateAt
+	// (RFC 3339)
+	SessionHashSalt     string
+	SessionHashNextSalt string
+	SessionHashRotateAt string
+
+	// Expose /admin/chaos for injecting faults into bidder and dependency
+	// calls; refused in production
+	ChaosEnabled bool
+
+	// Serve the simulated DSP at /bidder-sim for local development; off by
+	// default and refused in production
+	BidderSimEnabled bool
+
+	// Per-entry size, per-publisher quota and TTL limits for /cache
+	// (0 = bidcache default)
+	BidCache bidcache.Config
+
+	// Player SDK settings served from /video/config, signed with an Ed25519
+	// key (base64 32-byte seed); the endpoint is off without a key
+	PlayerDefaults   storage.PlayerConfig
+	PlayerSigningKey string
+	PlayerKeyID      string
+
+	// Flush interval and retention of the hourly business metrics rollup in
+	// Postgres (0 = rollup default)
+	Rollup rollup.Config
+
+	// How often guaranteed deal delivery is shared between instances
+	// (0 = deals default)
+	Deals deals.Config
+
+	// How often new creatives are registered and review statuses reloaded
+	// (0 = creatives default)
+	Creatives creatives.Config
+
+	// How often house ads are reloaded from stored_creatives
+	// (0 = houseads default)
+	HouseAds houseads.Config
+
+	// How often floor rules are reloaded from floor_rules
+	// (0 = floors default)
+	Floors floors.Config
+
+	// Default p95 auction latency target for publishers without their own
+	// slo_p95_ms (0 = only track publishers with a target)
+	SLO slo.Config
+
+	// Window and latency target of the /health/weight serving weight
+	HealthWeight health.Config
+
+	// Compact auction summaries written to a Redis stream for downstream
+	// joins (billing, reporting); requires Redis
+	AuctionRegistry auctionregistry.Config
+
+	// Per-auction decision trails kept in the KV store for
+	// /admin/debug/auction/{id}
+	AuctionTrail auctiontrail.Config
+
+	// Sinks, batching and backpressure for the analytics event pipeline;
+	// disabled without sinks
+	Analytics analytics.Config
+
+	// Kafka brokers the analytics pipeline produces auction, win and video
+	// events to; disabled without brokers
+	Kafka kafka.Config
+
+	// Device graph service resolving CTV households for cross-device
+	// frequency caps; disabled without a URL
+	DeviceGraph devicegraph.Config
+
+	// Outbound header policy for bidder http_headers
+	BidderHeaders storage.HeaderPolicy
+
+	// Feature flags; disabled when no provider is configured
+	FeatureFlags        featureflags.Config
+	FeatureFlagsRefresh time.Duration
+
 	// CORS
 	CORSOrigins []string
 }
@@ -63,17 +276,169 @@ func ParseConfig() *ServerConfig {
 	timeout := flag.Duration("timeout", 1000*time.Millisecond, "Default auction timeout")
 	flag.Parse()
 
+	pauseAds := getEnvBoolOrDefault("PLAYER_PAUSE_ADS_ENABLED", false)
 	cfg := &ServerConfig{
-		Port:                      *port,
-		Timeout:                   *timeout,
-		RedisURL:                  os.Getenv("REDIS_URL"),
-		IDREnabled:                *idrEnabled,
-		IDRUrl:                    *idrURL,
-		IDRAPIKey:                 os.Getenv("IDR_API_KEY"),
+		Port:             *port,
+		Timeout:          *timeout,
+		RedisURL:         os.Getenv("REDIS_URL"),
+		KVBackend:        getEnvOrDefault("KV_BACKEND", "redis"),
+		MemcachedServers: splitAndTrim(os.Getenv("MEMCACHED_SERVERS"), ","),
+		IDREnabled:       *idrEnabled,
+		IDRUrl:           *idrURL,
+		IDRAPIKey:        os.Getenv("IDR_API_KEY"),
+		DependencyCaps: map[string]time.Duration{
+			deadline.DependencyPostgres:  time.Duration(getEnvIntOrDefault("DEADLINE_POSTGRES_MS", int(deadline.DefaultPostgresCap/time.Millisecond))) * time.Millisecond,
+			deadline.DependencyRedis:     time.Duration(getEnvIntOrDefault("DEADLINE_REDIS_MS", int(deadline.DefaultRedisCap/time.Millisecond))) * time.Millisecond,
+			deadline.DependencyMemcached: time.Duration(getEnvIntOrDefault("DEADLINE_MEMCACHED_MS", int(deadline.DefaultMemcachedCap/time.Millisecond))) * time.Millisecond,
+			deadline.DependencyIDR:       time.Duration(getEnvIntOrDefault("DEADLINE_IDR_MS", int(deadline.DefaultIDRCap/time.Millisecond))) * time.Millisecond,
+		},
 		CurrencyConversionEnabled: os.Getenv("CURRENCY_CONVERSION_ENABLED") != "false",
 		DefaultCurrency:           "USD",
-		DisableGDPREnforcement:    os.Getenv("PBS_DISABLE_GDPR_ENFORCEMENT") == "true",
-		HostURL:                   getEnvOrDefault("PBS_HOST_URL", "https://catalyst.springwire.ai"),
+		CurrencyRates:             os.Getenv("CURRENCY_RATES"),
+		BidderCurrencies:          os.Getenv("BIDDER_CURRENCIES"),
+		CurrencyFeed: currency.FeedConfig{
+			Source:   toLower(trimSpace(os.Getenv("CURRENCY_RATES_SOURCE"))),
+			AppID:    os.Getenv("CURRENCY_RATES_APP_ID"),
+			URL:      os.Getenv("CURRENCY_RATES_URL"),
+			Interval: time.Duration(getEnvIntOrDefault("CURRENCY_RATES_INTERVAL_SECONDS", 3600)) * time.Second,
+			MaxAge:   time.Duration(getEnvIntOrDefault("CURRENCY_RATES_MAX_AGE_SECONDS", 86400)) * time.Second,
+		},
+		DisableGDPREnforcement:  os.Getenv("PBS_DISABLE_GDPR_ENFORCEMENT") == "true",
+		HostURL:                 getEnvOrDefault("PBS_HOST_URL", "https://catalyst.springwire.ai"),
+		ImpExpiry:               time.Duration(getEnvIntOrDefault("PBS_IMP_EXPIRY_SECONDS", 300)) * time.Second,
+		MaxBidders:              getEnvIntOrDefault("PBS_MAX_BIDDERS", 50),
+		OrtbBiddersFile:         os.Getenv("ORTB_BIDDERS_FILE"),
+		IDModulesFile:           os.Getenv("ID_MODULES_FILE"),
+		AdapterPlugins:          os.Getenv("ADAPTER_PLUGINS"),
+		StoredRequestCacheTTL:   time.Duration(getEnvIntOrDefault("STORED_REQUEST_CACHE_TTL_SECONDS", 300)) * time.Second,
+		AdminIdempotencyTTL:     time.Duration(getEnvIntOrDefault("ADMIN_IDEMPOTENCY_TTL_SECONDS", 86400)) * time.Second,
+		MaxBidCPM:               getEnvFloatOrDefault("MAX_BID_CPM", 0),
+		MaxBidderResponseBytes:  getEnvIntOrDefault("BIDDER_MAX_RESPONSE_BYTES", 1024*1024),
+		AllocSampleRate:         getEnvFloatOrDefault("AUCTION_ALLOC_SAMPLE_RATE", 0),
+		CreativeSanitization:    getEnvOrDefault("CREATIVE_SANITIZATION", exchange.SanitizeOff),
+		CreativeClickMacro:      os.Getenv("CREATIVE_CLICK_MACRO"),
+		TieBreak:                toLower(trimSpace(getEnvOrDefault("AUCTION_TIE_BREAK", exchange.TieBreakWeighted))),
+		TieBreakWeights:         os.Getenv("AUCTION_TIE_BREAK_WEIGHTS"),
+		COPPAScrubFields:        os.Getenv("COPPA_SCRUB_FIELDS"),
+		LMTScrubFields:          os.Getenv("LMT_SCRUB_FIELDS"),
+		BlockedCountries:        splitAndTrim(os.Getenv("BLOCKED_COUNTRIES"), ","),
+		LatencyBudgetPartners:   splitAndTrim(os.Getenv("LATENCY_BUDGET_PARTNERS"), ","),
+		SChainASI:               os.Getenv("SCHAIN_ASI"),
+		SChainSID:               os.Getenv("SCHAIN_SID"),
+		WinQueueWorkers:         getEnvIntOrDefault("WIN_QUEUE_WORKERS", 4),
+		Standby:                 getEnvBoolOrDefault("STANDBY_MODE", false),
+		VideoEventDedupWindow:   time.Duration(getEnvIntOrDefault("VIDEO_EVENT_DEDUP_SECONDS", 30)) * time.Second,
+		VASTValidation:          toLower(trimSpace(getEnvOrDefault("VAST_VALIDATION", endpoints.VASTValidationOff))),
+		CacheInvalidationPubSub: getEnvBoolOrDefault("CACHE_INVALIDATION_PUBSUB", true),
+		BidInjectionKeys:        os.Getenv("BID_INJECTION_KEYS"),
+		BidInjectionProdKeys:    splitAndTrim(os.Getenv("BID_INJECTION_PRODUCTION_KEYS"), ","),
+		ChaosEnabled:            getEnvBoolOrDefault("CHAOS_ENABLED", false),
+		BidderSimEnabled:        getEnvBoolOrDefault("BIDDER_SIM_ENABLED", false),
+		SessionHashSalt:         os.Getenv("SESSION_HASH_SALT"),
+		SessionHashNextSalt:     os.Getenv("SESSION_HASH_NEXT_SALT"),
+		SessionHashRotateAt:     os.Getenv("SESSION_HASH_ROTATE_AT"),
+		BidCache: bidcache.Config{
+			MaxValueBytes:       getEnvIntOrDefault("BID_CACHE_MAX_VALUE_BYTES", 64*1024),
+			PublisherQuotaBytes: int64(getEnvIntOrDefault("BID_CACHE_PUBLISHER_QUOTA_MB", 50)) * 1024 * 1024,
+			DefaultTTL:          time.Duration(getEnvIntOrDefault("BID_CACHE_DEFAULT_TTL_SECONDS", 300)) * time.Second,
+			MaxTTL:              time.Duration(getEnvIntOrDefault("BID_CACHE_MAX_TTL_SECONDS", 3600)) * time.Second,
+			Compression:         getEnvOrDefault("BID_CACHE_COMPRESSION", bidcache.CompressionZstd),
+			CompressionLevel:    getEnvIntOrDefault("BID_CACHE_COMPRESSION_LEVEL", bidcache.LevelDefault),
+			MinCompressBytes:    getEnvIntOrDefault("BID_CACHE_COMPRESS_MIN_BYTES", 1024),
+		},
+		PlayerDefaults: storage.PlayerConfig{
+			TrackingBaseURL:      os.Getenv("PLAYER_TRACKING_BASE_URL"),
+			EventBatchIntervalMs: getEnvIntOrDefault("PLAYER_EVENT_BATCH_MS", 5000),
+			PauseAdsEnabled:      &pauseAds,
+			VASTVersion:          getEnvOrDefault("PLAYER_VAST_VERSION", "4.0"),
+		},
+		PlayerSigningKey: os.Getenv("PLAYER_CONFIG_SIGNING_KEY"),
+		PlayerKeyID:      getEnvOrDefault("PLAYER_CONFIG_KEY_ID", "default"),
+		Rollup: rollup.Config{
+			Interval:        time.Duration(getEnvIntOrDefault("ROLLUP_INTERVAL_SECONDS", 3600)) * time.Second,
+			RetentionMonths: getEnvIntOrDefault("ROLLUP_RETENTION_MONTHS", rollup.DefaultRetentionMonths),
+		},
+		Deals: deals.Config{
+			Interval: time.Duration(getEnvIntOrDefault("DEAL_PACING_INTERVAL_SECONDS", 60)) * time.Second,
+		},
+		Creatives: creatives.Config{
+			Interval: time.Duration(getEnvIntOrDefault("CREATIVE_REGISTRY_INTERVAL_SECONDS", 60)) * time.Second,
+		},
+		HouseAds: houseads.Config{
+			Interval: time.Duration(getEnvIntOrDefault("HOUSE_ADS_INTERVAL_SECONDS", 60)) * time.Second,
+		},
+		Floors: floors.Config{
+			Interval: time.Duration(getEnvIntOrDefault("FLOOR_RULES_INTERVAL_SECONDS", 60)) * time.Second,
+		},
+		SLO: slo.Config{
+			DefaultTarget: time.Duration(getEnvIntOrDefault("SLO_P95_TARGET_MS", 0)) * time.Millisecond,
+		},
+		UID2: idmodules.UID2Config{
+			OperatorURL: os.Getenv("UID2_OPERATOR_URL"),
+			APIKey:      os.Getenv("UID2_API_KEY"),
+			Timeout:     time.Duration(getEnvIntOrDefault("UID2_TIMEOUT_MS", 50)) * time.Millisecond,
+		},
+		HealthWeight: health.Config{
+			Window:        time.Duration(getEnvIntOrDefault("HEALTH_WEIGHT_WINDOW_SECONDS", 60)) * time.Second,
+			LatencyTarget: time.Duration(getEnvIntOrDefault("HEALTH_WEIGHT_LATENCY_TARGET_MS", 500)) * time.Millisecond,
+		},
+		AuctionRegistry: auctionregistry.Config{
+			Enabled: getEnvBoolOrDefault("AUCTION_REGISTRY_ENABLED", false),
+			Stream:  getEnvOrDefault("AUCTION_REGISTRY_STREAM", auctionregistry.DefaultConfig().Stream),
+			MaxLen:  int64(getEnvIntOrDefault("AUCTION_REGISTRY_MAXLEN", int(auctionregistry.DefaultConfig().MaxLen))),
+		},
+		AuctionTrail: auctiontrail.Config{
+			Enabled: getEnvBoolOrDefault("AUCTION_TRAIL_ENABLED", false),
+			TTL:     time.Duration(getEnvIntOrDefault("AUCTION_TRAIL_TTL_MINUTES", 1440)) * time.Minute,
+		},
+		Analytics: analytics.Config{
+			Sinks:         splitAndTrim(toLower(os.Getenv("ANALYTICS_SINKS")), ","),
+			BufferSize:    getEnvIntOrDefault("ANALYTICS_BUFFER_SIZE", analytics.DefaultConfig().BufferSize),
+			BatchSize:     getEnvIntOrDefault("ANALYTICS_BATCH_SIZE", analytics.DefaultConfig().BatchSize),
+			FlushInterval: time.Duration(getEnvIntOrDefault("ANALYTICS_FLUSH_INTERVAL_MS", 1000)) * time.Millisecond,
+			Backpressure:  toLower(trimSpace(getEnvOrDefault("ANALYTICS_BACKPRESSURE", analytics.BackpressureDrop))),
+			MaxAttempts:   getEnvIntOrDefault("ANALYTICS_MAX_ATTEMPTS", analytics.DefaultConfig().MaxAttempts),
+			WebhookURL:    os.Getenv("ANALYTICS_WEBHOOK_URL"),
+			WebhookToken:  os.Getenv("ANALYTICS_WEBHOOK_TOKEN"),
+			FilePath:      os.Getenv("ANALYTICS_FILE_PATH"),
+			IDs: analytics.IDConfig{
+				Mode:   toLower(trimSpace(getEnvOrDefault("ANALYTICS_ID_MODE", analytics.IDModeOff))),
+				Keys:   os.Getenv("ANALYTICS_ID_KEYS"),
+				KeyID:  os.Getenv("ANALYTICS_ID_KEY_ID"),
+				Fields: splitAndTrim(os.Getenv("ANALYTICS_ID_FIELDS"), ","),
+			},
+		},
+		Kafka: kafka.Config{
+			Brokers:  splitAndTrim(os.Getenv("KAFKA_BROKERS"), ","),
+			ClientID: getEnvOrDefault("KAFKA_CLIENT_ID", kafka.DefaultConfig().ClientID),
+			Topics: map[string]string{
+				analytics.EventAuction: getEnvOrDefault("KAFKA_TOPIC_AUCTIONS", kafka.DefaultConfig().Topics[analytics.EventAuction]),
+				analytics.EventWin:     getEnvOrDefault("KAFKA_TOPIC_WINS", kafka.DefaultConfig().Topics[analytics.EventWin]),
+				analytics.EventVideo:   getEnvOrDefault("KAFKA_TOPIC_VIDEO", kafka.DefaultConfig().Topics[analytics.EventVideo]),
+			},
+			Format:            toLower(trimSpace(getEnvOrDefault("KAFKA_FORMAT", kafka.FormatJSON))),
+			SchemaRegistryURL: os.Getenv("KAFKA_SCHEMA_REGISTRY_URL"),
+		},
+		DeviceGraph: devicegraph.Config{
+			URL:       os.Getenv("DEVICE_GRAPH_URL"),
+			APIKey:    os.Getenv("DEVICE_GRAPH_API_KEY"),
+			Timeout:   time.Duration(getEnvIntOrDefault("DEVICE_GRAPH_TIMEOUT_MS", 30)) * time.Millisecond,
+			CacheTTL:  time.Duration(getEnvIntOrDefault("DEVICE_GRAPH_CACHE_TTL_SECONDS", 3600)) * time.Second,
+			CacheSize: getEnvIntOrDefault("DEVICE_GRAPH_CACHE_SIZE", devicegraph.DefaultConfig().CacheSize),
+		},
+		BidderHeaders: storage.HeaderPolicy{
+			Strict:                    getEnvBoolOrDefault("BIDDER_HEADERS_STRICT", false),
+			AuthorizationHosts:        os.Getenv("BIDDER_AUTH_HOSTS"),
+			AllowAuthorizationAnyHost: getEnvBoolOrDefault("BIDDER_AUTH_ANY_HOST", false),
+			SecretsDir:                os.Getenv("BIDDER_SECRETS_DIR"),
+		},
+		FeatureFlags: featureflags.Config{
+			Provider: os.Getenv("FEATURE_FLAGS_PROVIDER"),
+			File:     os.Getenv("FEATURE_FLAGS_FILE"),
+			URL:      os.Getenv("FEATURE_FLAGS_URL"),
+			APIKey:   os.Getenv("FEATURE_FLAGS_API_KEY"),
+		},
+		FeatureFlagsRefresh: time.Duration(getEnvIntOrDefault("FEATURE_FLAGS_REFRESH_SECONDS", 30)) * time.Second,
 	}
 
 	// Parse database config if DB_HOST is set
@@ -91,6 +456,11 @@ func ParseConfig() *ServerConfig {
 		}
 	}
 
+	// Players send tracking events to this server unless told otherwise
+	if cfg.PlayerDefaults.TrackingBaseURL == "" {
+		cfg.PlayerDefaults.TrackingBaseURL = cfg.HostURL
+	}
+
 	// Parse CORS origins
 	corsOrigins := os.Getenv("CORS_ORIGINS")
 	if corsOrigins != "" {
@@ -109,17 +479,46 @@ func ParseConfig() *ServerConfig {
 
 // ToExchangeConfig converts ServerConfig to exchange.Config
 func (c *ServerConfig) ToExchangeConfig() *exchange.Config {
+	maxBidders := c.MaxBidders
+	if maxBidders <= 0 {
+		maxBidders = 50
+	}
+	// Validate rejects malformed weights
+	tieBreakWeights, _ := exchange.ParseTieBreakWeights(c.TieBreakWeights)
 	return &exchange.Config{
-		DefaultTimeout:     c.Timeout,
-		MaxBidders:         50,
-		IDREnabled:         c.IDREnabled,
-		IDRServiceURL:      c.IDRUrl,
-		IDRAPIKey:          c.IDRAPIKey,
-		EventRecordEnabled: true,
-		EventBufferSize:    100,
-		CurrencyConv:       c.CurrencyConversionEnabled,
-		DefaultCurrency:    c.DefaultCurrency,
+		DefaultTimeout:       c.Timeout,
+		MaxBidders:           maxBidders,
+		IDREnabled:           c.IDREnabled,
+		IDRServiceURL:        c.IDRUrl,
+		IDRAPIKey:            c.IDRAPIKey,
+		EventRecordEnabled:   true,
+		EventBufferSize:      100,
+		CurrencyConv:         c.CurrencyConversionEnabled,
+		DefaultCurrency:      c.DefaultCurrency,
+		ImpExpiry:            c.ImpExpiry,
+		MaxBidCPM:            c.MaxBidCPM,
+		MaxResponseBytes:     c.MaxBidderResponseBytes,
+		AllocSampleRate:      c.AllocSampleRate,
+		CreativeSanitization: c.CreativeSanitization,
+		CreativeClickMacro:   c.CreativeClickMacro,
+		TieBreak:             c.TieBreak,
+		TieBreakWeights:      tieBreakWeights,
+		COPPAScrub:           scrubPolicy(c.COPPAScrubFields),
+		LMTScrub:             scrubPolicy(c.LMTScrubFields),
+		BlockedCountries:     c.BlockedCountries,
+		SChainASI:            c.SChainASI,
+		SChainSID:            c.SChainSID,
+	}
+}
+
+// scrubPolicy parses a scrub field list; nil leaves the exchange default.
+// Validate rejects unknown fields.
+func scrubPolicy(fields string) *middleware.ScrubPolicy {
+	if trimSpace(fields) == "" {
+		return nil
 	}
+	policy, _ := middleware.ParseScrubPolicy(fields)
+	return &policy
 }
 
 // getEnvOrDefault returns the environment variable value or a default
@@ -152,6 +551,19 @@ func getEnvIntOrDefault(key string, defaultValue int) int {
 	return intVal
 }
 
+// getEnvFloatOrDefault returns the environment variable as float64 or a default
+func getEnvFloatOrDefault(key string, defaultValue float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	floatVal, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return defaultValue
+	}
+	return floatVal
+}
+
 // splitAndTrim splits a string by delimiter and trims whitespace from each part
 func splitAndTrim(s, delimiter string) []string {
 	parts := []string{}
@@ -241,6 +653,174 @@ func (c *ServerConfig) Validate() error {
 		return fmt.Errorf("timeout must be less than 30s, got %v", c.Timeout)
 	}
 
+	if c.MaxBidders < 0 {
+		return fmt.Errorf("max bidders must not be negative, got %d", c.MaxBidders)
+	}
+
+	if c.MaxBidCPM < 0 || c.MaxBidCPM > 1000 {
+		return fmt.Errorf("max bid CPM must be between 0 and 1000, got %v", c.MaxBidCPM)
+	}
+
+	if c.MaxBidderResponseBytes < 0 || c.MaxBidderResponseBytes > maxBidderResponseBytes {
+		return fmt.Errorf("bidder max response bytes must be between 0 and %d, got %d", maxBidderResponseBytes, c.MaxBidderResponseBytes)
+	}
+
+	if c.AllocSampleRate < 0 || c.AllocSampleRate > 1 {
+		return fmt.Errorf("auction alloc sample rate must be between 0 and 1, got %v", c.AllocSampleRate)
+	}
+
+	switch c.CreativeSanitization {
+	case "", exchange.SanitizeOff, exchange.SanitizeStandard, exchange.SanitizeStrict:
+	default:
+		return fmt.Errorf("creative sanitization must be %q, %q or %q, got %q", exchange.SanitizeOff, exchange.SanitizeStandard, exchange.SanitizeStrict, c.CreativeSanitization)
+	}
+
+	if !exchange.ValidTieBreak(c.TieBreak) {
+		return fmt.Errorf("tie break must be %q, %q or %q, got %q", exchange.TieBreakResponseOrder, exchange.TieBreakWeighted, exchange.TieBreakDealPriority, c.TieBreak)
+	}
+	if _, err := exchange.ParseTieBreakWeights(c.TieBreakWeights); err != nil {
+		return err
+	}
+
+	if p, err := middleware.ParseScrubPolicy(c.COPPAScrubFields); err != nil {
+		return fmt.Errorf("COPPA scrub fields: %w", err)
+	} else if trimSpace(c.COPPAScrubFields) != "" && !p.Covers(middleware.MinimumCOPPAScrub) {
+		return fmt.Errorf("COPPA scrub fields must include %s and %s", middleware.ScrubFieldUserIDs, middleware.ScrubFieldIFA)
+	}
+	if _, err := middleware.ParseScrubPolicy(c.LMTScrubFields); err != nil {
+		return fmt.Errorf("LMT scrub fields: %w", err)
+	}
+
+	for _, country := range c.BlockedCountries {
+		if !isCountryCode(country) {
+			return fmt.Errorf("blocked countries must be ISO 3166-1 alpha-3 codes, got %q", country)
+		}
+	}
+
+	if c.SChainSID != "" && c.SChainASI == "" {
+		return fmt.Errorf("schain sid requires SCHAIN_ASI")
+	}
+
+	if c.WinQueueWorkers < 0 {
+		return fmt.Errorf("win queue workers must not be negative, got %d", c.WinQueueWorkers)
+	}
+
+	if c.VideoEventDedupWindow < 0 {
+		return fmt.Errorf("video event dedup window must not be negative, got %v", c.VideoEventDedupWindow)
+	}
+
+	switch c.VASTValidation {
+	case "", endpoints.VASTValidationOff, endpoints.VASTValidationDebug, endpoints.VASTValidationStrict:
+	default:
+		return fmt.Errorf("VAST validation must be %q, %q or %q, got %q", endpoints.VASTValidationOff, endpoints.VASTValidationDebug, endpoints.VASTValidationStrict, c.VASTValidation)
+	}
+
+	if c.BidCache.MaxValueBytes < 0 || c.BidCache.PublisherQuotaBytes < 0 || c.BidCache.DefaultTTL < 0 || c.BidCache.MaxTTL < 0 {
+		return fmt.Errorf("bid cache limits must not be negative")
+	}
+
+	if c.BidCache.MaxTTL > 0 && c.BidCache.DefaultTTL > c.BidCache.MaxTTL {
+		return fmt.Errorf("bid cache default TTL %v exceeds max TTL %v", c.BidCache.DefaultTTL, c.BidCache.MaxTTL)
+	}
+
+	switch c.BidCache.Compression {
+	case "", bidcache.CompressionZstd, bidcache.CompressionNone:
+	default:
+		return fmt.Errorf("bid cache compression must be %q or %q, got %q", bidcache.CompressionZstd, bidcache.CompressionNone, c.BidCache.Compression)
+	}
+
+	if c.BidCache.CompressionLevel != 0 && (c.BidCache.CompressionLevel < bidcache.LevelFastest || c.BidCache.CompressionLevel > bidcache.LevelBest) {
+		return fmt.Errorf("bid cache compression level must be %d-%d, got %d", bidcache.LevelFastest, bidcache.LevelBest, c.BidCache.CompressionLevel)
+	}
+
+	if c.Rollup.Interval < 0 || c.Rollup.RetentionMonths < 0 {
+		return fmt.Errorf("rollup interval and retention must not be negative")
+	}
+
+	if c.Deals.Interval < 0 {
+		return fmt.Errorf("deal pacing interval must not be negative")
+	}
+
+	if c.Creatives.Interval < 0 {
+		return fmt.Errorf("creative registry interval must not be negative")
+	}
+
+	if c.HouseAds.Interval < 0 {
+		return fmt.Errorf("house ads interval must not be negative")
+	}
+
+	if c.Floors.Interval < 0 {
+		return fmt.Errorf("floor rules interval must not be negative")
+	}
+
+	if c.SLO.DefaultTarget < 0 {
+		return fmt.Errorf("SLO p95 target must not be negative")
+	}
+
+	for dependency, limit := range c.DependencyCaps {
+		if limit < 0 {
+			return fmt.Errorf("%s deadline must not be negative", dependency)
+		}
+	}
+
+	if c.UID2.Timeout < 0 {
+		return fmt.Errorf("UID2 timeout must not be negative")
+	}
+
+	if c.HealthWeight.Window < 0 || c.HealthWeight.LatencyTarget < 0 {
+		return fmt.Errorf("health weight window and latency target must not be negative")
+	}
+
+	if c.AuctionRegistry.MaxLen < 0 {
+		return fmt.Errorf("auction registry max length must not be negative, got %d", c.AuctionRegistry.MaxLen)
+	}
+
+	if err := analytics.ValidateConfig(c.Analytics); err != nil {
+		return fmt.Errorf("invalid analytics config: %w", err)
+	}
+
+	if err := kafka.ValidateConfig(c.Kafka); err != nil {
+		return fmt.Errorf("invalid kafka config: %w", err)
+	}
+
+	if err := devicegraph.ValidateConfig(c.DeviceGraph); err != nil {
+		return fmt.Errorf("invalid device graph config: %w", err)
+	}
+
+	if c.AuctionTrail.TTL < 0 {
+		return fmt.Errorf("auction trail TTL must not be negative")
+	}
+
+	if c.StoredRequestCacheTTL < 0 {
+		return fmt.Errorf("stored request cache TTL must not be negative")
+	}
+
+	if c.AdminIdempotencyTTL < 0 {
+		return fmt.Errorf("admin idempotency TTL must not be negative")
+	}
+
+	if err := c.validatePlayerConfig(); err != nil {
+		return err
+	}
+
+	if err := c.validateCurrencies(); err != nil {
+		return err
+	}
+
+	if _, err := c.BidInjectionKeyring(); err != nil {
+		return fmt.Errorf("invalid BID_INJECTION_KEYS: %w", err)
+	}
+
+	if _, err := c.SessionHashConfig(); err != nil {
+		return err
+	}
+
+	if c.BidderHeaders.AuthorizationHosts != "" {
+		if err := domainmatch.Validate(c.BidderHeaders.AuthorizationHosts); err != nil {
+			return fmt.Errorf("invalid BIDDER_AUTH_HOSTS: %w", err)
+		}
+	}
+
 	// Validate IDR configuration when enabled
 	if c.IDREnabled {
 		if c.IDRUrl == "" {
@@ -252,6 +832,37 @@ func (c *ServerConfig) Validate() error {
 		}
 	}
 
+	// Validate KV backend selection
+	switch c.KVBackend {
+	case "", kv.BackendRedis, kv.BackendMemory:
+	case kv.BackendMemcached:
+		if len(c.MemcachedServers) == 0 {
+			return fmt.Errorf("MEMCACHED_SERVERS is required when KV_BACKEND is memcached")
+		}
+	default:
+		return fmt.Errorf("unknown KV backend %q", c.KVBackend)
+	}
+	// Features built on Redis streams, pub/sub or scripts can't run on the
+	// other backends; refuse to start rather than silently dropping them
+	if c.KVBackend == kv.BackendMemcached || c.KVBackend == kv.BackendMemory {
+		if c.AuctionRegistry.Enabled {
+			return fmt.Errorf("AUCTION_REGISTRY_ENABLED requires KV_BACKEND=redis")
+		}
+		if c.KVBackend == kv.BackendMemcached && c.CacheInvalidationPubSub {
+			return fmt.Errorf("CACHE_INVALIDATION_PUBSUB requires KV_BACKEND=redis; set it to false to apply invalidations per instance")
+		}
+	}
+
+	// Validate feature flag provider when configured
+	if c.FeatureFlagsEnabled() {
+		if _, err := featureflags.NewProvider(c.FeatureFlags); err != nil {
+			return fmt.Errorf("feature flags: %w", err)
+		}
+		if c.FeatureFlagsRefresh <= 0 {
+			return fmt.Errorf("feature flag refresh interval must be positive, got %v", c.FeatureFlagsRefresh)
+		}
+	}
+
 	// Validate database configuration when present
 	if c.DatabaseConfig != nil {
 		if err := c.DatabaseConfig.Validate(); err != nil {
@@ -281,11 +892,189 @@ func (c *ServerConfig) Validate() error {
 				return fmt.Errorf("CORS wildcard '*' is not allowed in production - specify explicit origins")
 			}
 		}
+
+		// Fault injection is for rehearsals in staging only
+		if c.ChaosEnabled {
+			return fmt.Errorf("CHAOS_ENABLED is not allowed in production")
+		}
+
+		// The simulated DSP would let anyone place bids
+		if c.BidderSimEnabled {
+			return fmt.Errorf("BIDDER_SIM_ENABLED is not allowed in production")
+		}
+	}
+
+	return nil
+}
+
+// validateCurrencies checks currency rates and that every bidder currency
+// can be converted to the default currency
+func (c *ServerConfig) validateCurrencies() error {
+	rates, err := currency.ParseRates(c.CurrencyRates)
+	if err != nil {
+		return fmt.Errorf("invalid CURRENCY_RATES: %w", err)
+	}
+	bidderCurrencies, err := parseBidderCurrencies(c.BidderCurrencies)
+	if err != nil {
+		return fmt.Errorf("invalid BIDDER_CURRENCIES: %w", err)
+	}
+	if err := currency.ValidateFeedConfig(c.CurrencyFeed); err != nil {
+		return fmt.Errorf("invalid CURRENCY_RATES_SOURCE: %w", err)
+	}
+
+	// Live rates arrive after startup, so bidder currencies can't be
+	// checked against them here
+	if c.CurrencyConversionEnabled && c.CurrencyFeed.Source != "" {
+		return nil
+	}
+
+	converter := c.CurrencyConverter(rates)
+	for bidder, cur := range bidderCurrencies {
+		if _, err := converter.Rate(cur, c.DefaultCurrency); err != nil {
+			return fmt.Errorf("bidder %s bids in %s but no rate is configured: %w", bidder, cur, err)
+		}
+	}
+	return nil
+}
+
+// CurrencyConverter returns the converter for the given rates, or nil when
+// conversion is disabled
+func (c *ServerConfig) CurrencyConverter(rates map[string]float64) *currency.Converter {
+	if !c.CurrencyConversionEnabled || len(rates) == 0 {
+		return nil
+	}
+	return currency.NewConverter(c.DefaultCurrency, rates)
+}
+
+// parseBidderCurrencies parses a "bidder:CUR,bidder:CUR" list
+func parseBidderCurrencies(s string) (map[string]string, error) {
+	currencies := make(map[string]string)
+	for _, entry := range splitAndTrim(s, ",") {
+		parts := splitString(entry, ":")
+		if len(parts) != 2 || trimSpace(parts[0]) == "" || len(currency.Normalize(parts[1])) != 3 {
+			return nil, fmt.Errorf("invalid entry %q: expected bidder:CUR", entry)
+		}
+		currencies[trimSpace(parts[0])] = currency.Normalize(parts[1])
+	}
+	return currencies, nil
+}
+
+// isCountryCode reports whether s is three ASCII letters, the shape of an
+// ISO 3166-1 alpha-3 code
+func isCountryCode(s string) bool {
+	if len(s) != 3 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if c := s[i] | 0x20; c < 'a' || c > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+// maxBidderResponseBytes bounds BIDDER_MAX_RESPONSE_BYTES
+const maxBidderResponseBytes = 16 * 1024 * 1024
+
+// SessionHashConfig returns the session hashing salts. A rotation needs both
+// the next salt and the time it takes over.
+func (c *ServerConfig) SessionHashConfig() (sessionhash.Config, error) {
+	cfg := sessionhash.Config{Salt: c.SessionHashSalt, NextSalt: c.SessionHashNextSalt}
+	if c.SessionHashRotateAt == "" {
+		if c.SessionHashNextSalt != "" {
+			return cfg, fmt.Errorf("SESSION_HASH_NEXT_SALT requires SESSION_HASH_ROTATE_AT")
+		}
+		return cfg, nil
+	}
+	rotateAt, err := time.Parse(time.RFC3339, c.SessionHashRotateAt)
+	if err != nil {
+		return cfg, fmt.Errorf("invalid SESSION_HASH_ROTATE_AT: %w", err)
+	}
+	if c.SessionHashNextSalt == "" {
+		return cfg, fmt.Errorf("SESSION_HASH_ROTATE_AT requires SESSION_HASH_NEXT_SALT")
+	}
+	cfg.RotateAt = rotateAt
+	return cfg, nil
+}
+
+// minBidInjectionSecretLen is the shortest accepted bid injection secret
+const minBidInjectionSecretLen = 32
+
+// BidInjectionKeyring parses BidInjectionKeys into secrets by key ID. In
+// production only allowlisted key IDs are returned, so injection stays off
+// unless a key is explicitly approved there.
+func (c *ServerConfig) BidInjectionKeyring() (map[string][]byte, error) {
+	keys := make(map[string][]byte)
+	for _, entry := range splitAndTrim(c.BidInjectionKeys, ",") {
+		sep := -1
+		for i := 0; i < len(entry); i++ {
+			if entry[i] == ':' {
+				sep = i
+				break
+			}
+		}
+		if sep <= 0 {
+			return nil, fmt.Errorf("invalid entry: expected id:secret")
+		}
+		id, secret := trimSpace(entry[:sep]), trimSpace(entry[sep+1:])
+		if len(secret) < minBidInjectionSecretLen {
+			return nil, fmt.Errorf("secret for key %q must be at least %d characters", id, minBidInjectionSecretLen)
+		}
+		keys[id] = []byte(secret)
+	}
+
+	for _, id := range c.BidInjectionProdKeys {
+		if _, ok := keys[id]; !ok {
+			return nil, fmt.Errorf("production key %q is not configured", id)
+		}
+	}
+
+	if isProduction() {
+		allowed := make(map[string][]byte, len(c.BidInjectionProdKeys))
+		for _, id := range c.BidInjectionProdKeys {
+			allowed[id] = keys[id]
+		}
+		keys = allowed
+	}
+	return keys, nil
+}
+
+// PlayerSigningKeyPair decodes PlayerSigningKey; nil when unset
+func (c *ServerConfig) PlayerSigningKeyPair() (ed25519.PrivateKey, error) {
+	if c.PlayerSigningKey == "" {
+		return nil, nil
+	}
+	seed, err := base64.StdEncoding.DecodeString(c.PlayerSigningKey)
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("must be a base64-encoded %d-byte seed", ed25519.SeedSize)
 	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
 
+// validatePlayerConfig checks the /video/config defaults and signing key
+func (c *ServerConfig) validatePlayerConfig() error {
+	if _, err := c.PlayerSigningKeyPair(); err != nil {
+		return fmt.Errorf("invalid PLAYER_CONFIG_SIGNING_KEY: %w", err)
+	}
+	if c.PlayerSigningKey == "" {
+		return nil
+	}
+	if c.PlayerDefaults.EventBatchIntervalMs <= 0 {
+		return fmt.Errorf("player event batch interval must be positive, got %d", c.PlayerDefaults.EventBatchIntervalMs)
+	}
+	switch c.PlayerDefaults.VASTVersion {
+	case "2.0", "3.0", "4.0", "4.1", "4.2":
+	default:
+		return fmt.Errorf("unsupported player VAST version %q", c.PlayerDefaults.VASTVersion)
+	}
 	return nil
 }
 
+// FeatureFlagsEnabled reports whether a feature flag provider is configured
+func (c *ServerConfig) FeatureFlagsEnabled() bool {
+	return c.FeatureFlags.Provider != "" || c.FeatureFlags.File != ""
+}
+
 // Validate validates the database configuration
 func (dc *DatabaseConfig) Validate() error {
 	if dc.Host == "" {
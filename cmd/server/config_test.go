@@ -1,10 +1,31 @@
 package main
 
 import (
+	"crypto/ed25519"
 	"flag"
 	"os"
 	"testing"
 	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/analytics"
+	"github.com/thenexusengine/tne_springwire/internal/analytics/kafka"
+	"github.com/thenexusengine/tne_springwire/internal/auctionregistry"
+	"github.com/thenexusengine/tne_springwire/internal/auctiontrail"
+	"github.com/thenexusengine/tne_springwire/internal/bidcache"
+	"github.com/thenexusengine/tne_springwire/internal/creatives"
+	"github.com/thenexusengine/tne_springwire/internal/currency"
+	"github.com/thenexusengine/tne_springwire/internal/deals"
+	"github.com/thenexusengine/tne_springwire/internal/devicegraph"
+	"github.com/thenexusengine/tne_springwire/internal/floors"
+	"github.com/thenexusengine/tne_springwire/internal/health"
+	"github.com/thenexusengine/tne_springwire/internal/idmodules"
+	"github.com/thenexusengine/tne_springwire/internal/houseads"
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+	"github.com/thenexusengine/tne_springwire/internal/rollup"
+	"github.com/thenexusengine/tne_springwire/internal/slo"
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+	"github.com/thenexusengine/tne_springwire/pkg/deadline"
+	"github.com/thenexusengine/tne_springwire/pkg/featureflags"
 )
 
 func TestParseConfig_Defaults(t *testing.T) {
@@ -51,6 +72,14 @@ func TestParseConfig_Defaults(t *testing.T) {
 	if cfg.RedisURL != "" {
 		t.Error("Expected empty Redis URL when REDIS_URL is not set")
 	}
+
+	if cfg.CreativeSanitization != "off" {
+		t.Errorf("Expected creative sanitization off by default, got '%s'", cfg.CreativeSanitization)
+	}
+
+	if cfg.BidderSimEnabled {
+		t.Error("Expected bidder simulator to be disabled by default")
+	}
 }
 
 func TestParseConfig_EnvironmentOverrides(t *testing.T) {
@@ -289,6 +318,25 @@ func TestToExchangeConfig(t *testing.T) {
 		t.Errorf("Expected max bidders 50, got %d", exCfg.MaxBidders)
 	}
 
+	cfg.MaxBidders = 12
+	if got := cfg.ToExchangeConfig().MaxBidders; got != 12 {
+		t.Errorf("Expected configured max bidders 12, got %d", got)
+	}
+
+	cfg.TieBreak = "deal_priority"
+	cfg.TieBreakWeights = "appnexus:2, rubicon:0.5"
+	if got := cfg.ToExchangeConfig(); got.TieBreak != "deal_priority" || got.TieBreakWeights["appnexus"] != 2 || got.TieBreakWeights["rubicon"] != 0.5 {
+		t.Errorf("Expected tie-breaking passed through, got %q %v", got.TieBreak, got.TieBreakWeights)
+	}
+
+	if exCfg.COPPAScrub != nil || exCfg.LMTScrub != nil {
+		t.Error("Expected the exchange's default scrub policies when unset")
+	}
+	cfg.LMTScrubFields = "ifa, geo"
+	if got := cfg.ToExchangeConfig().LMTScrub; got == nil || *got != (middleware.ScrubPolicy{IFA: true, Geo: true}) {
+		t.Errorf("Expected the LMT scrub policy passed through, got %+v", got)
+	}
+
 	if !exCfg.IDREnabled {
 		t.Error("Expected IDR to be enabled")
 	}
@@ -661,6 +709,605 @@ func TestServerConfigValidate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "negative max bidders",
+			config: &ServerConfig{
+				Port:            "8000",
+				Timeout:         1 * time.Second,
+				HostURL:         "https://example.com",
+				DefaultCurrency: "USD",
+				MaxBidders:      -1,
+			},
+			wantErr: true,
+			errMsg:  "max bidders must not be negative",
+		},
+		{
+			name: "max bid CPM above hard ceiling",
+			config: &ServerConfig{
+				Port:            "8000",
+				Timeout:         1 * time.Second,
+				HostURL:         "https://example.com",
+				DefaultCurrency: "USD",
+				MaxBidCPM:       5000,
+			},
+			wantErr: true,
+			errMsg:  "max bid CPM must be between 0 and 1000",
+		},
+		{
+			name: "bidder max response bytes above ceiling",
+			config: &ServerConfig{
+				Port:                   "8000",
+				Timeout:                1 * time.Second,
+				HostURL:                "https://example.com",
+				DefaultCurrency:        "USD",
+				MaxBidderResponseBytes: 64 * 1024 * 1024,
+			},
+			wantErr: true,
+			errMsg:  "bidder max response bytes must be between 0 and",
+		},
+		{
+			name: "alloc sample rate above one",
+			config: &ServerConfig{
+				Port:            "8000",
+				Timeout:         1 * time.Second,
+				HostURL:         "https://example.com",
+				DefaultCurrency: "USD",
+				AllocSampleRate: 1.5,
+			},
+			wantErr: true,
+			errMsg:  "auction alloc sample rate must be between 0 and 1",
+		},
+		{
+			name: "unknown creative sanitization level",
+			config: &ServerConfig{
+				Port:                 "8000",
+				Timeout:              1 * time.Second,
+				HostURL:              "https://example.com",
+				DefaultCurrency:      "USD",
+				CreativeSanitization: "paranoid",
+			},
+			wantErr: true,
+			errMsg:  "creative sanitization must be",
+		},
+		{
+			name: "unknown tie break rule",
+			config: &ServerConfig{
+				Port:            "8000",
+				Timeout:         1 * time.Second,
+				HostURL:         "https://example.com",
+				DefaultCurrency: "USD",
+				TieBreak:        "coin_flip",
+			},
+			wantErr: true,
+			errMsg:  "tie break must be",
+		},
+		{
+			name: "tie break weight without a bidder",
+			config: &ServerConfig{
+				Port:            "8000",
+				Timeout:         1 * time.Second,
+				HostURL:         "https://example.com",
+				DefaultCurrency: "USD",
+				TieBreak:        "weighted",
+				TieBreakWeights: "appnexus:2,:3",
+			},
+			wantErr: true,
+			errMsg:  "invalid tie-break weight",
+		},
+		{
+			name: "unknown COPPA scrub field",
+			config: &ServerConfig{
+				Port:             "8000",
+				Timeout:          1 * time.Second,
+				HostURL:          "https://example.com",
+				DefaultCurrency:  "USD",
+				COPPAScrubFields: "user_ids,email",
+			},
+			wantErr: true,
+			errMsg:  "COPPA scrub fields: unknown scrub field",
+		},
+		{
+			name: "COPPA scrub fields none",
+			config: &ServerConfig{
+				Port:             "8000",
+				Timeout:          1 * time.Second,
+				HostURL:          "https://example.com",
+				DefaultCurrency:  "USD",
+				COPPAScrubFields: "none",
+			},
+			wantErr: true,
+			errMsg:  "COPPA scrub fields must include user_ids and ifa",
+		},
+		{
+			name: "COPPA scrub fields without ifa",
+			config: &ServerConfig{
+				Port:             "8000",
+				Timeout:          1 * time.Second,
+				HostURL:          "https://example.com",
+				DefaultCurrency:  "USD",
+				COPPAScrubFields: "user_ids,geo",
+			},
+			wantErr: true,
+			errMsg:  "COPPA scrub fields must include user_ids and ifa",
+		},
+		{
+			name: "alpha-2 blocked country",
+			config: &ServerConfig{
+				Port:             "8000",
+				Timeout:          1 * time.Second,
+				HostURL:          "https://example.com",
+				DefaultCurrency:  "USD",
+				BlockedCountries: []string{"PRK", "IR"},
+			},
+			wantErr: true,
+			errMsg:  "blocked countries must be ISO 3166-1 alpha-3 codes",
+		},
+		{
+			name: "schain sid without asi",
+			config: &ServerConfig{
+				Port:            "8000",
+				Timeout:         1 * time.Second,
+				HostURL:         "https://example.com",
+				DefaultCurrency: "USD",
+				SChainSID:       "seat-1",
+			},
+			wantErr: true,
+			errMsg:  "schain sid requires SCHAIN_ASI",
+		},
+		{
+			name: "negative win queue workers",
+			config: &ServerConfig{
+				Port:            "8000",
+				Timeout:         1 * time.Second,
+				HostURL:         "https://example.com",
+				DefaultCurrency: "USD",
+				WinQueueWorkers: -1,
+			},
+			wantErr: true,
+			errMsg:  "win queue workers must not be negative",
+		},
+		{
+			name: "invalid currency rates",
+			config: &ServerConfig{
+				Port:            "8000",
+				Timeout:         1 * time.Second,
+				HostURL:         "https://example.com",
+				DefaultCurrency: "USD",
+				CurrencyRates:   "EUR:zero",
+			},
+			wantErr: true,
+			errMsg:  "invalid CURRENCY_RATES",
+		},
+		{
+			name: "bidder currency without rate",
+			config: &ServerConfig{
+				Port:                      "8000",
+				Timeout:                   1 * time.Second,
+				HostURL:                   "https://example.com",
+				DefaultCurrency:           "USD",
+				CurrencyConversionEnabled: true,
+				CurrencyRates:             "EUR:1.08",
+				BidderCurrencies:          "rubicon:EUR,adform:GBP",
+			},
+			wantErr: true,
+			errMsg:  "bidder adform bids in GBP but no rate is configured",
+		},
+		{
+			name: "bidder currencies with rates",
+			config: &ServerConfig{
+				Port:                      "8000",
+				Timeout:                   1 * time.Second,
+				HostURL:                   "https://example.com",
+				DefaultCurrency:           "USD",
+				CurrencyConversionEnabled: true,
+				CurrencyRates:             "EUR:1.08,GBP:1.27",
+				BidderCurrencies:          "rubicon:EUR,adform:gbp",
+			},
+			wantErr: false,
+		},
+		{
+			name: "bidder currencies with live rates",
+			config: &ServerConfig{
+				Port:                      "8000",
+				Timeout:                   1 * time.Second,
+				HostURL:                   "https://example.com",
+				DefaultCurrency:           "USD",
+				CurrencyConversionEnabled: true,
+				BidderCurrencies:          "rubicon:EUR,adform:GBP",
+				CurrencyFeed:              currency.FeedConfig{Source: currency.SourceECB},
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown currency rate source",
+			config: &ServerConfig{
+				Port:            "8000",
+				Timeout:         1 * time.Second,
+				HostURL:         "https://example.com",
+				DefaultCurrency: "USD",
+				CurrencyFeed:    currency.FeedConfig{Source: "fixer"},
+			},
+			wantErr: true,
+			errMsg:  "invalid CURRENCY_RATES_SOURCE",
+		},
+		{
+			name: "negative video event dedup window",
+			config: &ServerConfig{
+				Port:                  "8000",
+				Timeout:               1 * time.Second,
+				HostURL:               "https://example.com",
+				DefaultCurrency:       "USD",
+				VideoEventDedupWindow: -time.Second,
+			},
+			wantErr: true,
+			errMsg:  "video event dedup window must not be negative",
+		},
+		{
+			name: "unknown VAST validation mode",
+			config: &ServerConfig{
+				Port:            "8000",
+				Timeout:         1 * time.Second,
+				HostURL:         "https://example.com",
+				DefaultCurrency: "USD",
+				VASTValidation:  "paranoid",
+			},
+			wantErr: true,
+			errMsg:  "VAST validation must be",
+		},
+		{
+			name: "unknown analytics sink",
+			config: &ServerConfig{
+				Port:            "8000",
+				Timeout:         1 * time.Second,
+				HostURL:         "https://example.com",
+				DefaultCurrency: "USD",
+				Analytics:       analytics.Config{Sinks: []string{"kafka"}},
+			},
+			wantErr: true,
+			errMsg:  "invalid analytics config",
+		},
+		{
+			name: "analytics identifier hashing without a key",
+			config: &ServerConfig{
+				Port:            "8000",
+				Timeout:         1 * time.Second,
+				HostURL:         "https://example.com",
+				DefaultCurrency: "USD",
+				Analytics:       analytics.Config{IDs: analytics.IDConfig{Mode: analytics.IDModeHash}},
+			},
+			wantErr: true,
+			errMsg:  "invalid analytics config",
+		},
+		{
+			name: "avro kafka format without schema registry",
+			config: &ServerConfig{
+				Port:            "8000",
+				Timeout:         1 * time.Second,
+				HostURL:         "https://example.com",
+				DefaultCurrency: "USD",
+				Kafka:           kafka.Config{Brokers: []string{"localhost:9092"}, Format: kafka.FormatAvro},
+			},
+			wantErr: true,
+			errMsg:  "invalid kafka config",
+		},
+		{
+			name: "negative device graph timeout",
+			config: &ServerConfig{
+				Port:            "8000",
+				Timeout:         1 * time.Second,
+				HostURL:         "https://example.com",
+				DefaultCurrency: "USD",
+				DeviceGraph:     devicegraph.Config{URL: "http://graph", Timeout: -time.Millisecond},
+			},
+			wantErr: true,
+			errMsg:  "invalid device graph config",
+		},
+		{
+			name: "bid cache default TTL above max TTL",
+			config: &ServerConfig{
+				Port:            "8000",
+				Timeout:         1 * time.Second,
+				HostURL:         "https://example.com",
+				DefaultCurrency: "USD",
+				BidCache:        bidcache.Config{DefaultTTL: 2 * time.Hour, MaxTTL: time.Hour},
+			},
+			wantErr: true,
+			errMsg:  "bid cache default TTL",
+		},
+		{
+			name: "unknown bid cache compression",
+			config: &ServerConfig{
+				Port:            "8000",
+				Timeout:         1 * time.Second,
+				HostURL:         "https://example.com",
+				DefaultCurrency: "USD",
+				BidCache:        bidcache.Config{Compression: "brotli"},
+			},
+			wantErr: true,
+			errMsg:  "bid cache compression must be",
+		},
+		{
+			name: "bid cache compression level out of range",
+			config: &ServerConfig{
+				Port:            "8000",
+				Timeout:         1 * time.Second,
+				HostURL:         "https://example.com",
+				DefaultCurrency: "USD",
+				BidCache:        bidcache.Config{CompressionLevel: 9},
+			},
+			wantErr: true,
+			errMsg:  "bid cache compression level must be 1-4",
+		},
+		{
+			name: "negative rollup retention",
+			config: &ServerConfig{
+				Port:            "8000",
+				Timeout:         1 * time.Second,
+				HostURL:         "https://example.com",
+				DefaultCurrency: "USD",
+				Rollup:          rollup.Config{RetentionMonths: -1},
+			},
+			wantErr: true,
+			errMsg:  "rollup interval and retention must not be negative",
+		},
+		{
+			name: "negative deal pacing interval",
+			config: &ServerConfig{
+				Port:            "8000",
+				Timeout:         1 * time.Second,
+				HostURL:         "https://example.com",
+				DefaultCurrency: "USD",
+				Deals:           deals.Config{Interval: -time.Second},
+			},
+			wantErr: true,
+			errMsg:  "deal pacing interval must not be negative",
+		},
+		{
+			name: "negative creative registry interval",
+			config: &ServerConfig{
+				Port:            "8000",
+				Timeout:         1 * time.Second,
+				HostURL:         "https://example.com",
+				DefaultCurrency: "USD",
+				Creatives:       creatives.Config{Interval: -time.Second},
+			},
+			wantErr: true,
+			errMsg:  "creative registry interval must not be negative",
+		},
+		{
+			name: "negative house ads interval",
+			config: &ServerConfig{
+				Port:            "8000",
+				Timeout:         1 * time.Second,
+				HostURL:         "https://example.com",
+				DefaultCurrency: "USD",
+				HouseAds:        houseads.Config{Interval: -time.Second},
+			},
+			wantErr: true,
+			errMsg:  "house ads interval must not be negative",
+		},
+		{
+			name: "negative floor rules interval",
+			config: &ServerConfig{
+				Port:            "8000",
+				Timeout:         1 * time.Second,
+				HostURL:         "https://example.com",
+				DefaultCurrency: "USD",
+				Floors:          floors.Config{Interval: -time.Second},
+			},
+			wantErr: true,
+			errMsg:  "floor rules interval must not be negative",
+		},
+		{
+			name: "negative SLO target",
+			config: &ServerConfig{
+				Port:            "8000",
+				Timeout:         1 * time.Second,
+				HostURL:         "https://example.com",
+				DefaultCurrency: "USD",
+				SLO:             slo.Config{DefaultTarget: -time.Millisecond},
+			},
+			wantErr: true,
+			errMsg:  "SLO p95 target must not be negative",
+		},
+		{
+			name: "negative dependency deadline",
+			config: &ServerConfig{
+				Port:            "8000",
+				Timeout:         1 * time.Second,
+				HostURL:         "https://example.com",
+				DefaultCurrency: "USD",
+				DependencyCaps:  map[string]time.Duration{deadline.DependencyRedis: -time.Millisecond},
+			},
+			wantErr: true,
+			errMsg:  "redis deadline must not be negative",
+		},
+		{
+			name: "auction registry without redis",
+			config: &ServerConfig{
+				Port:            "8000",
+				Timeout:         1 * time.Second,
+				HostURL:         "https://example.com",
+				DefaultCurrency: "USD",
+				KVBackend:       "memory",
+				AuctionRegistry: auctionregistry.Config{Enabled: true},
+			},
+			wantErr: true,
+			errMsg:  "AUCTION_REGISTRY_ENABLED requires KV_BACKEND=redis",
+		},
+		{
+			name: "invalidation pub/sub on memcached",
+			config: &ServerConfig{
+				Port:                    "8000",
+				Timeout:                 1 * time.Second,
+				HostURL:                 "https://example.com",
+				DefaultCurrency:         "USD",
+				KVBackend:               "memcached",
+				MemcachedServers:        []string{"localhost:11211"},
+				CacheInvalidationPubSub: true,
+			},
+			wantErr: true,
+			errMsg:  "CACHE_INVALIDATION_PUBSUB requires KV_BACKEND=redis",
+		},
+		{
+			name: "negative UID2 timeout",
+			config: &ServerConfig{
+				Port:            "8000",
+				Timeout:         1 * time.Second,
+				HostURL:         "https://example.com",
+				DefaultCurrency: "USD",
+				UID2:            idmodules.UID2Config{Timeout: -time.Millisecond},
+			},
+			wantErr: true,
+			errMsg:  "UID2 timeout must not be negative",
+		},
+		{
+			name: "negative health weight latency target",
+			config: &ServerConfig{
+				Port:            "8000",
+				Timeout:         1 * time.Second,
+				HostURL:         "https://example.com",
+				DefaultCurrency: "USD",
+				HealthWeight:    health.Config{LatencyTarget: -time.Millisecond},
+			},
+			wantErr: true,
+			errMsg:  "health weight window and latency target must not be negative",
+		},
+		{
+			name: "negative auction registry max length",
+			config: &ServerConfig{
+				Port:            "8000",
+				Timeout:         1 * time.Second,
+				HostURL:         "https://example.com",
+				DefaultCurrency: "USD",
+				AuctionRegistry: auctionregistry.Config{MaxLen: -1},
+			},
+			wantErr: true,
+			errMsg:  "auction registry max length must not be negative",
+		},
+		{
+			name: "negative auction trail TTL",
+			config: &ServerConfig{
+				Port:            "8000",
+				Timeout:         1 * time.Second,
+				HostURL:         "https://example.com",
+				DefaultCurrency: "USD",
+				AuctionTrail:    auctiontrail.Config{TTL: -time.Minute},
+			},
+			wantErr: true,
+			errMsg:  "auction trail TTL must not be negative",
+		},
+		{
+			name: "negative stored request cache TTL",
+			config: &ServerConfig{
+				Port:                  "8000",
+				Timeout:               1 * time.Second,
+				HostURL:               "https://example.com",
+				DefaultCurrency:       "USD",
+				StoredRequestCacheTTL: -time.Second,
+			},
+			wantErr: true,
+			errMsg:  "stored request cache TTL must not be negative",
+		},
+		{
+			name: "negative admin idempotency TTL",
+			config: &ServerConfig{
+				Port:                "8000",
+				Timeout:             1 * time.Second,
+				HostURL:             "https://example.com",
+				DefaultCurrency:     "USD",
+				AdminIdempotencyTTL: -time.Second,
+			},
+			wantErr: true,
+			errMsg:  "admin idempotency TTL must not be negative",
+		},
+		{
+			name: "invalid player signing key",
+			config: &ServerConfig{
+				Port:             "8000",
+				Timeout:          1 * time.Second,
+				HostURL:          "https://example.com",
+				DefaultCurrency:  "USD",
+				PlayerSigningKey: "c2hvcnQ=",
+			},
+			wantErr: true,
+			errMsg:  "invalid PLAYER_CONFIG_SIGNING_KEY",
+		},
+		{
+			name: "unsupported player VAST version",
+			config: &ServerConfig{
+				Port:             "8000",
+				Timeout:          1 * time.Second,
+				HostURL:          "https://example.com",
+				DefaultCurrency:  "USD",
+				PlayerSigningKey: "AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8=",
+				PlayerDefaults:   storage.PlayerConfig{EventBatchIntervalMs: 5000, VASTVersion: "5.0"},
+			},
+			wantErr: true,
+			errMsg:  "unsupported player VAST version",
+		},
+		{
+			name: "session hash rotation without next salt",
+			config: &ServerConfig{
+				Port:                "8000",
+				Timeout:             1 * time.Second,
+				HostURL:             "https://example.com",
+				DefaultCurrency:     "USD",
+				SessionHashRotateAt: "2026-11-01T00:00:00Z",
+			},
+			wantErr: true,
+			errMsg:  "SESSION_HASH_ROTATE_AT requires SESSION_HASH_NEXT_SALT",
+		},
+		{
+			name: "invalid session hash rotation time",
+			config: &ServerConfig{
+				Port:                "8000",
+				Timeout:             1 * time.Second,
+				HostURL:             "https://example.com",
+				DefaultCurrency:     "USD",
+				SessionHashNextSalt: "next",
+				SessionHashRotateAt: "tomorrow",
+			},
+			wantErr: true,
+			errMsg:  "invalid SESSION_HASH_ROTATE_AT",
+		},
+		{
+			name: "short bid injection secret",
+			config: &ServerConfig{
+				Port:             "8000",
+				Timeout:          1 * time.Second,
+				HostURL:          "https://example.com",
+				DefaultCurrency:  "USD",
+				BidInjectionKeys: "staging:short",
+			},
+			wantErr: true,
+			errMsg:  "invalid BID_INJECTION_KEYS",
+		},
+		{
+			name: "unknown bid injection production key",
+			config: &ServerConfig{
+				Port:                 "8000",
+				Timeout:              1 * time.Second,
+				HostURL:              "https://example.com",
+				DefaultCurrency:      "USD",
+				BidInjectionKeys:     "staging:0123456789abcdef0123456789abcdef",
+				BidInjectionProdKeys: []string{"qa"},
+			},
+			wantErr: true,
+			errMsg:  "production key \"qa\" is not configured",
+		},
+		{
+			name: "invalid bidder auth hosts",
+			config: &ServerConfig{
+				Port:            "8000",
+				Timeout:         1 * time.Second,
+				HostURL:         "https://example.com",
+				DefaultCurrency: "USD",
+				BidderHeaders:   storage.HeaderPolicy{AuthorizationHosts: "*.co.uk"},
+			},
+			wantErr: true,
+			errMsg:  "invalid BIDDER_AUTH_HOSTS",
+		},
 		{
 			name: "valid config with IDR enabled",
 			config: &ServerConfig{
@@ -841,6 +1488,44 @@ func TestServerConfigValidate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "host is required",
 		},
+		{
+			name: "valid config with feature flag file",
+			config: &ServerConfig{
+				Port:                "8000",
+				Timeout:             1 * time.Second,
+				HostURL:             "https://example.com",
+				DefaultCurrency:     "USD",
+				FeatureFlags:        featureflags.Config{File: "/etc/pbs/flags.json"},
+				FeatureFlagsRefresh: 30 * time.Second,
+			},
+			wantErr: false,
+		},
+		{
+			name: "unleash provider without URL",
+			config: &ServerConfig{
+				Port:                "8000",
+				Timeout:             1 * time.Second,
+				HostURL:             "https://example.com",
+				DefaultCurrency:     "USD",
+				FeatureFlags:        featureflags.Config{Provider: featureflags.ProviderUnleash},
+				FeatureFlagsRefresh: 30 * time.Second,
+			},
+			wantErr: true,
+			errMsg:  "feature flags",
+		},
+		{
+			name: "unknown feature flag provider",
+			config: &ServerConfig{
+				Port:                "8000",
+				Timeout:             1 * time.Second,
+				HostURL:             "https://example.com",
+				DefaultCurrency:     "USD",
+				FeatureFlags:        featureflags.Config{Provider: "split"},
+				FeatureFlagsRefresh: 30 * time.Second,
+			},
+			wantErr: true,
+			errMsg:  "unknown feature flag provider",
+		},
 	}
 
 	for _, tt := range tests {
@@ -1474,6 +2159,50 @@ func TestServerConfigValidate_CORSProduction(t *testing.T) {
 	}
 }
 
+func TestServerConfigValidate_ChaosProduction(t *testing.T) {
+	config := &ServerConfig{
+		Port:            "8000",
+		Timeout:         1 * time.Second,
+		HostURL:         "https://example.com",
+		DefaultCurrency: "USD",
+		CORSOrigins:     []string{"https://example.com"},
+		ChaosEnabled:    true,
+	}
+
+	t.Setenv("ENVIRONMENT", "staging")
+	if err := config.Validate(); err != nil {
+		t.Errorf("Expected chaos to be allowed outside production, got %v", err)
+	}
+
+	t.Setenv("ENVIRONMENT", "production")
+	err := config.Validate()
+	if err == nil || !contains(err.Error(), "CHAOS_ENABLED is not allowed in production") {
+		t.Errorf("Expected chaos to be refused in production, got %v", err)
+	}
+}
+
+func TestServerConfigValidate_BidderSimProduction(t *testing.T) {
+	config := &ServerConfig{
+		Port:             "8000",
+		Timeout:          1 * time.Second,
+		HostURL:          "https://example.com",
+		DefaultCurrency:  "USD",
+		CORSOrigins:      []string{"https://example.com"},
+		BidderSimEnabled: true,
+	}
+
+	t.Setenv("ENVIRONMENT", "development")
+	if err := config.Validate(); err != nil {
+		t.Errorf("Expected the bidder simulator to be allowed outside production, got %v", err)
+	}
+
+	t.Setenv("ENVIRONMENT", "production")
+	err := config.Validate()
+	if err == nil || !contains(err.Error(), "BIDDER_SIM_ENABLED is not allowed in production") {
+		t.Errorf("Expected the bidder simulator to be refused in production, got %v", err)
+	}
+}
+
 func TestGetEnvIntOrDefault(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -1676,3 +2405,75 @@ func TestParseConfig_WithCORSOrigins(t *testing.T) {
 		}
 	}
 }
+
+func TestParseConfig_PlayerDefaults(t *testing.T) {
+	clearEnvVars(t)
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	t.Setenv("PBS_HOST_URL", "https://ads.example.com")
+	t.Setenv("PLAYER_PAUSE_ADS_ENABLED", "true")
+	t.Setenv("PLAYER_CONFIG_SIGNING_KEY", "AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8=")
+
+	cfg := ParseConfig()
+	if cfg.PlayerDefaults.TrackingBaseURL != "https://ads.example.com" {
+		t.Errorf("expected tracking base URL to default to host URL, got %q", cfg.PlayerDefaults.TrackingBaseURL)
+	}
+	if cfg.PlayerDefaults.EventBatchIntervalMs != 5000 || cfg.PlayerDefaults.VASTVersion != "4.0" || !*cfg.PlayerDefaults.PauseAdsEnabled {
+		t.Errorf("unexpected player defaults: %+v", cfg.PlayerDefaults)
+	}
+
+	key, err := cfg.PlayerSigningKeyPair()
+	if err != nil || len(key) != ed25519.PrivateKeySize {
+		t.Fatalf("expected signing key, got %v, %v", key, err)
+	}
+	if err := cfg.validatePlayerConfig(); err != nil {
+		t.Errorf("expected valid player config, got %v", err)
+	}
+}
+
+func TestParseConfig_DependencyCaps(t *testing.T) {
+	clearEnvVars(t)
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	t.Setenv("DEADLINE_REDIS_MS", "20")
+	t.Setenv("DEADLINE_IDR_MS", "0")
+
+	cfg := ParseConfig()
+	if cfg.DependencyCaps[deadline.DependencyRedis] != 20*time.Millisecond {
+		t.Errorf("expected the Redis cap overridden, got %v", cfg.DependencyCaps)
+	}
+	if cfg.DependencyCaps[deadline.DependencyPostgres] != deadline.DefaultPostgresCap {
+		t.Errorf("expected the Postgres default kept, got %v", cfg.DependencyCaps)
+	}
+	if limit, ok := cfg.DependencyCaps[deadline.DependencyIDR]; !ok || limit != 0 {
+		t.Errorf("expected 0 to disable the IDR cap, got %v", cfg.DependencyCaps)
+	}
+}
+
+func TestServerConfig_BidInjectionKeyring(t *testing.T) {
+	cfg := &ServerConfig{
+		BidInjectionKeys:     "staging:0123456789abcdef0123456789abcdef, qa:abcdef0123456789:abcdef0123456789",
+		BidInjectionProdKeys: []string{"qa"},
+	}
+
+	t.Setenv("ENVIRONMENT", "staging")
+	keys, err := cfg.BidInjectionKeyring()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(keys) != 2 || string(keys["qa"]) != "abcdef0123456789:abcdef0123456789" {
+		t.Errorf("expected both keys outside production, got %v", keys)
+	}
+
+	t.Setenv("ENVIRONMENT", "production")
+	keys, err = cfg.BidInjectionKeyring()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(keys) != 1 || keys["qa"] == nil {
+		t.Errorf("expected only the allowlisted key in production, got %v", keys)
+	}
+
+	cfg.BidInjectionProdKeys = nil
+	if keys, _ = cfg.BidInjectionKeyring(); len(keys) != 0 {
+		t.Errorf("expected injection disabled in production without allowlist, got %v", keys)
+	}
+}
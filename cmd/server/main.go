@@ -8,10 +8,16 @@ import (
 	"syscall"
 
 	pbsconfig "github.com/thenexusengine/tne_springwire/internal/config"
+	"github.com/thenexusengine/tne_springwire/pkg/lifecycle"
 	"github.com/thenexusengine/tne_springwire/pkg/logger"
 )
 
 func main() {
+	// `server selftest` runs a smoke test against an in-memory server
+	if len(os.Args) > 1 && os.Args[1] == "selftest" {
+		os.Exit(runSelfTest(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
 	// Parse configuration from flags and environment
 	cfg := ParseConfig()
 
@@ -29,6 +35,8 @@ func main() {
 	if err != nil {
 		log.Fatal().Err(err).Msg("Failed to create server")
 	}
+	// NewServer stamps the logger with the registered bidder set
+	log = logger.Log
 
 	// Start server in goroutine
 	go func() {
@@ -48,7 +56,12 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), pbsconfig.ShutdownTimeout)
 	defer cancel()
 
+	// Only a failed HTTP drain means requests were cut off; other hooks
+	// already logged their failures
 	if err := server.Shutdown(ctx); err != nil {
-		log.Fatal().Err(err).Msg("Server forced to shutdown")
+		if lifecycle.PhaseFailed(err, lifecycle.PhaseHTTP) {
+			log.Fatal().Err(err).Msg("Graceful shutdown incomplete")
+		}
+		log.Warn().Err(err).Msg("Shutdown hooks failed")
 	}
 }
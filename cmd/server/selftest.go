@@ -0,0 +1,529 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/endpoints"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+	"github.com/thenexusengine/tne_springwire/pkg/kv"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+const selfTestUsage = `Usage: server selftest [flags]
+
+Boots the server on a loopback port with in-memory dependencies and two
+simulated bidders, runs a scripted battery of requests (banner auction, video
+VAST, ad pod, pause ad config, win and video events, consent variations) and
+reports pass/fail per scenario. The environment's configuration is not used.
+Exits 1 if any scenario fails.
+
+Flags:
+`
+
+const (
+	selfTestPublisher = "selftest-pub"
+	selfTestDomain    = "selftest.example"
+	selfTestKeyID     = "selftest"
+
+	// sim_b bids above sim_a and should win every impression
+	selfTestLowPrice  = 1.50
+	selfTestHighPrice = 2.75
+
+	// TCF v2 consent to every purpose and to vendors 1-1000
+	selfTestConsent = "CP1R2oAP1R2oAAKABBENEsEAAP___wAAAAAAH0QAYAAgfQAAAAA"
+)
+
+// selfTestEnv configures the env-driven middleware: API keys and registered
+// publishers held in memory, and privacy enforcement at production defaults
+func selfTestEnv(apiKey string) map[string]string {
+	return map[string]string{
+		"AUTH_ENABLED":                 "true",
+		"AUTH_USE_REDIS":               "false",
+		"API_KEYS":                     apiKey + ":" + selfTestPublisher,
+		"PUBLISHER_AUTH_ENABLED":       "true",
+		"PUBLISHER_ALLOW_UNREGISTERED": "false",
+		"PUBLISHER_AUTH_USE_REDIS":     "false",
+		"REGISTERED_PUBLISHERS":        selfTestPublisher + ":" + selfTestDomain,
+		"PBS_ENFORCE_GDPR":             "true",
+		"PBS_ENFORCE_CCPA":             "true",
+		"PBS_ENFORCE_COPPA":            "true",
+		"PBS_PRIVACY_STRICT_MODE":      "true",
+		"PBS_MALFORMED_TCF_POLICY":     "reject",
+	}
+}
+
+// selfTestBidders are generic OpenRTB bidders answered by the server's own
+// simulated DSP, so no request leaves the host
+func selfTestBidders(baseURL string) []map[string]interface{} {
+	bidder := func(code string, price float64) map[string]interface{} {
+		return map[string]interface{}{
+			"bidder_code": code,
+			"name":        "Self-test " + code,
+			"endpoint": map[string]interface{}{
+				"url":        fmt.Sprintf("%s%s?price=%.2f", baseURL, endpoints.BidderSimPath, price),
+				"timeout_ms": 300,
+			},
+			"capabilities": map[string]interface{}{
+				"media_types":  []string{"banner", "video"},
+				"site_enabled": true,
+				"app_enabled":  true,
+			},
+			"demand_type": "publisher",
+		}
+	}
+	return []map[string]interface{}{bidder("sim_a", selfTestLowPrice), bidder("sim_b", selfTestHighPrice)}
+}
+
+// selfTestConfig is a server with no database, Redis, IDR or external
+// bidders: the KV store is in memory and only the simulated bidders run
+func selfTestConfig(port, baseURL, biddersFile, signingSeed string) *ServerConfig {
+	pauseAds := true
+	return &ServerConfig{
+		Port:                      port,
+		Timeout:                   1000 * time.Millisecond,
+		KVBackend:                 kv.BackendMemory,
+		IDREnabled:                false,
+		CurrencyConversionEnabled: false,
+		DefaultCurrency:           "USD",
+		HostURL:                   baseURL,
+		OrtbBiddersFile:           biddersFile,
+		WinQueueWorkers:           1,
+		VideoEventDedupWindow:     time.Minute,
+		BidderSimEnabled:          true,
+		PlayerDefaults: storage.PlayerConfig{
+			TrackingBaseURL:      baseURL,
+			EventBatchIntervalMs: 5000,
+			PauseAdsEnabled:      &pauseAds,
+			VASTVersion:          "4.0",
+		},
+		PlayerSigningKey: signingSeed,
+		PlayerKeyID:      selfTestKeyID,
+	}
+}
+
+// selfTestResult is one scenario's outcome
+type selfTestResult struct {
+	Scenario   string `json:"scenario"`
+	Passed     bool   `json:"passed"`
+	DurationMs int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+// selfTestScenario is a named check against the running server
+type selfTestScenario struct {
+	name string
+	run  func(c *selfTestClient) error
+}
+
+// runSelfTest implements `server selftest`, returning the exit code
+func runSelfTest(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("selftest", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	output := fs.String("o", "text", "Output format: text or json")
+	timeout := fs.Duration("timeout", 5*time.Second, "Per-request timeout")
+	verbose := fs.Bool("v", false, "Show server logs")
+	fs.Usage = func() {
+		fmt.Fprint(stderr, selfTestUsage)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *output != "text" && *output != "json" {
+		fmt.Fprintf(stderr, "unknown output format %q\n", *output)
+		return 2
+	}
+
+	level := zerolog.ErrorLevel
+	if *verbose {
+		level = zerolog.InfoLevel
+	}
+	// Handlers logging through zerolog's global logger are quieted too
+	zerolog.SetGlobalLevel(level)
+	logger.Init(logger.Config{Level: level.String(), Format: "json", TimeFormat: time.RFC3339})
+
+	client, shutdown, err := startSelfTestServer(*timeout)
+	if err != nil {
+		fmt.Fprintf(stderr, "selftest: failed to start server: %v\n", err)
+		return 1
+	}
+	defer shutdown()
+
+	results := client.run(selfTestScenarios())
+	writeSelfTestResults(stdout, *output, results)
+	for _, r := range results {
+		if !r.Passed {
+			return 1
+		}
+	}
+	return 0
+}
+
+// startSelfTestServer boots the server on a loopback port and returns a
+// client for it and a func that shuts it down
+func startSelfTestServer(timeout time.Duration) (*selfTestClient, func(), error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, nil, err
+	}
+	port := fmt.Sprint(ln.Addr().(*net.TCPAddr).Port)
+	baseURL := "http://127.0.0.1:" + port
+
+	dir, err := os.MkdirTemp("", "selftest")
+	if err != nil {
+		ln.Close()
+		return nil, nil, err
+	}
+	cleanup := func() {
+		ln.Close()
+		os.RemoveAll(dir)
+	}
+
+	biddersFile := filepath.Join(dir, "bidders.json")
+	data, _ := json.Marshal(selfTestBidders(baseURL))
+	if err := os.WriteFile(biddersFile, data, 0o600); err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+
+	secret := make([]byte, 16+ed25519.SeedSize)
+	if _, err := rand.Read(secret); err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	apiKey := hex.EncodeToString(secret[:16])
+	seed := secret[16:]
+
+	for k, v := range selfTestEnv(apiKey) {
+		os.Setenv(k, v)
+	}
+	os.Unsetenv("REDIS_URL")
+
+	// Only the simulated bidders are registered, never the built-in ones
+	adapters.DefaultRegistry = adapters.NewRegistry()
+
+	server, err := NewServer(selfTestConfig(port, baseURL, biddersFile, base64.StdEncoding.EncodeToString(seed)))
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	go func() {
+		if err := server.httpServer.Serve(ln); err != nil && err != http.ErrServerClosed {
+			logger.Log.Error().Err(err).Msg("Self-test server error")
+		}
+	}()
+
+	shutdown := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(ctx)
+		cleanup()
+	}
+	client := &selfTestClient{
+		baseURL:   baseURL,
+		apiKey:    apiKey,
+		http:      &http.Client{Timeout: timeout},
+		playerKey: ed25519.NewKeyFromSeed(seed).Public().(ed25519.PublicKey),
+	}
+	return client, shutdown, nil
+}
+
+// selfTestScenarios is the scripted battery, run in order: the event
+// scenarios notify on the banner auction's winning bid
+func selfTestScenarios() []selfTestScenario {
+	return []selfTestScenario{
+		{"health", checkSelfTestHealth},
+		{"banner auction", checkSelfTestBannerAuction},
+		{"video vast", checkSelfTestVAST},
+		{"video pod", checkSelfTestPod},
+		{"pause ad config", checkSelfTestPlayerConfig},
+		{"win and billing notices", checkSelfTestWinNotices},
+		{"video events", checkSelfTestVideoEvents},
+		{"consent: gdpr without consent", expectSelfTestConsent(http.StatusBadRequest, func(req *openrtb.BidRequest) {
+			req.Regs = selfTestGDPRRegs()
+		})},
+		{"consent: malformed tcf", expectSelfTestConsent(http.StatusBadRequest, func(req *openrtb.BidRequest) {
+			req.Regs = selfTestGDPRRegs()
+			req.User = &openrtb.User{Consent: "not-a-tcf-string"}
+		})},
+		{"consent: gdpr with consent", expectSelfTestConsent(http.StatusOK, func(req *openrtb.BidRequest) {
+			req.Regs = selfTestGDPRRegs()
+			req.User = &openrtb.User{Consent: selfTestConsent}
+		})},
+		{"consent: coppa", expectSelfTestConsent(http.StatusBadRequest, func(req *openrtb.BidRequest) {
+			req.Regs = &openrtb.Regs{COPPA: 1}
+		})},
+		{"consent: us privacy opt-out", expectSelfTestConsent(http.StatusOK, func(req *openrtb.BidRequest) {
+			req.Regs = &openrtb.Regs{USPrivacy: "1YYN"}
+		})},
+	}
+}
+
+// writeSelfTestResults prints a line per scenario and a summary, or the
+// results as JSON
+func writeSelfTestResults(w io.Writer, format string, results []selfTestResult) {
+	passed := 0
+	for _, r := range results {
+		if r.Passed {
+			passed++
+		}
+	}
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.Encode(map[string]interface{}{
+			"passed":    passed == len(results),
+			"scenarios": results,
+		})
+		return
+	}
+	for _, r := range results {
+		if r.Passed {
+			fmt.Fprintf(w, "PASS  %s (%dms)\n", r.Scenario, r.DurationMs)
+		} else {
+			fmt.Fprintf(w, "FAIL  %s (%dms): %s\n", r.Scenario, r.DurationMs, r.Error)
+		}
+	}
+	fmt.Fprintf(w, "%d/%d scenarios passed\n", passed, len(results))
+}
+
+// selfTestClient calls the self-test server with the test publisher's API
+// key, carrying state between scenarios
+type selfTestClient struct {
+	baseURL   string
+	apiKey    string
+	http      *http.Client
+	playerKey ed25519.PublicKey
+
+	// Winning bid of the banner auction
+	bid *openrtb.Bid
+}
+
+func (c *selfTestClient) run(scenarios []selfTestScenario) []selfTestResult {
+	results := make([]selfTestResult, 0, len(scenarios))
+	for _, sc := range scenarios {
+		start := time.Now()
+		err := sc.run(c)
+		r := selfTestResult{Scenario: sc.name, Passed: err == nil, DurationMs: time.Since(start).Milliseconds()}
+		if err != nil {
+			r.Error = err.Error()
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+// do sends a request, JSON-encoding body when set, and reads the response
+func (c *selfTestClient) do(method, path string, body interface{}) (*http.Response, []byte, error) {
+	var r io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, nil, err
+		}
+		r = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, c.baseURL+path, r)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("X-API-Key", c.apiKey)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	return resp, data, err
+}
+
+// auction posts a request from the test publisher's site
+func (c *selfTestClient) auction(id string, imp openrtb.Imp, modify func(*openrtb.BidRequest)) (*http.Response, []byte, error) {
+	req := &openrtb.BidRequest{
+		ID:   id,
+		Imp:  []openrtb.Imp{imp},
+		Site: &openrtb.Site{Domain: selfTestDomain, Page: "https://" + selfTestDomain + "/", Publisher: &openrtb.Publisher{ID: selfTestPublisher}},
+		TMax: 500,
+	}
+	if modify != nil {
+		modify(req)
+	}
+	return c.do(http.MethodPost, "/openrtb2/auction", req)
+}
+
+func selfTestBanner() openrtb.Imp {
+	return openrtb.Imp{ID: "1", Banner: &openrtb.Banner{W: 300, H: 250}}
+}
+
+func checkSelfTestHealth(c *selfTestClient) error {
+	resp, body, err := c.do(http.MethodGet, "/health/ready", nil)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("expected 200, got %d: %s", resp.StatusCode, body)
+	}
+	return nil
+}
+
+func checkSelfTestBannerAuction(c *selfTestClient) error {
+	resp, body, err := c.auction("selftest-banner", selfTestBanner(), nil)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("expected 200, got %d: %s", resp.StatusCode, body)
+	}
+	var br openrtb.BidResponse
+	if err := json.Unmarshal(body, &br); err != nil {
+		return fmt.Errorf("invalid bid response: %v", err)
+	}
+	if len(br.SeatBid) == 0 || len(br.SeatBid[0].Bid) == 0 {
+		return errors.New("expected a winning bid, got none")
+	}
+	bid := br.SeatBid[0].Bid[0]
+	if bid.Price != selfTestHighPrice {
+		return fmt.Errorf("expected sim_b's %.2f to win, got %.2f", selfTestHighPrice, bid.Price)
+	}
+	c.bid = &bid
+	return nil
+}
+
+// vast requests VAST from the test publisher's site with extra params
+func (c *selfTestClient) vast(id string, extra url.Values) (string, error) {
+	q := url.Values{"id": {id}, "w": {"1920"}, "h": {"1080"}, "site_id": {selfTestPublisher}, "domain": {selfTestDomain}}
+	for k, v := range extra {
+		q[k] = v
+	}
+	resp, body, err := c.do(http.MethodGet, "/video/vast?"+q.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("expected 200, got %d: %s", resp.StatusCode, body)
+	}
+	return string(body), nil
+}
+
+func checkSelfTestVAST(c *selfTestClient) error {
+	body, err := c.vast("selftest-vast", nil)
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(body, "bidder-sim.example/ad.mp4") {
+		return fmt.Errorf("expected the simulated creative, got %s", body)
+	}
+	return nil
+}
+
+func checkSelfTestPod(c *selfTestClient) error {
+	body, err := c.vast("selftest-pod", url.Values{"poddur": {"60"}, "maxads": {"2"}})
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(body, `sequence="1"`) || !strings.Contains(body, "bidder-sim.example/ad.mp4") {
+		return fmt.Errorf("expected a sequenced pod ad, got %s", body)
+	}
+	return nil
+}
+
+func checkSelfTestPlayerConfig(c *selfTestClient) error {
+	resp, body, err := c.do(http.MethodGet, "/video/config?pub="+selfTestPublisher, nil)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("expected 200, got %d: %s", resp.StatusCode, body)
+	}
+	var cfg endpoints.PlayerConfig
+	if err := json.Unmarshal(body, &cfg); err != nil || cfg.PublisherID != selfTestPublisher || !cfg.PauseAdsEnabled {
+		return fmt.Errorf("expected pause ads enabled for %s, got %s", selfTestPublisher, body)
+	}
+	keyID, sig, _ := strings.Cut(resp.Header.Get(endpoints.PlayerConfigSignatureHeader), ".")
+	raw, err := base64.RawURLEncoding.DecodeString(sig)
+	if keyID != selfTestKeyID || err != nil || !ed25519.Verify(c.playerKey, body, raw) {
+		return fmt.Errorf("invalid signature %q", resp.Header.Get(endpoints.PlayerConfigSignatureHeader))
+	}
+	return nil
+}
+
+func checkSelfTestWinNotices(c *selfTestClient) error {
+	if c.bid == nil {
+		return errors.New("no winning bid from the banner auction")
+	}
+	for _, typ := range []string{"", "billing"} {
+		path := "/event/win?bid_id=" + url.QueryEscape(c.bid.ID)
+		if typ != "" {
+			path += "&type=" + typ
+		}
+		resp, body, err := c.do(http.MethodGet, path, nil)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode != http.StatusNoContent {
+			return fmt.Errorf("notice %q: expected 204, got %d: %s", typ, resp.StatusCode, body)
+		}
+	}
+	return nil
+}
+
+func checkSelfTestVideoEvents(c *selfTestClient) error {
+	if c.bid == nil {
+		return errors.New("no winning bid from the banner auction")
+	}
+	for _, event := range []string{"start", "complete"} {
+		resp, body, err := c.do(http.MethodGet, "/api/v1/video/event?event="+event+"&bid_id="+url.QueryEscape(c.bid.ID), nil)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "image/gif" {
+			return fmt.Errorf("%s: expected a tracking pixel, got %d: %s", event, resp.StatusCode, body)
+		}
+	}
+	return nil
+}
+
+// selfTestGDPRRegs flags the request as in scope of GDPR
+func selfTestGDPRRegs() *openrtb.Regs {
+	gdpr := 1
+	return &openrtb.Regs{GDPR: &gdpr}
+}
+
+// expectSelfTestConsent runs a banner auction with modified privacy
+// signals and checks its status: 400 for requests the privacy middleware
+// must block, 200 for ones it must let through
+func expectSelfTestConsent(status int, modify func(*openrtb.BidRequest)) func(c *selfTestClient) error {
+	return func(c *selfTestClient) error {
+		resp, body, err := c.auction("selftest-consent", selfTestBanner(), modify)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode != status {
+			return fmt.Errorf("expected %d, got %d: %s", status, resp.StatusCode, body)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestSelfTest(t *testing.T) {
+	// NewServer registers Prometheus metrics once per process, so the
+	// battery runs in a child process
+	if os.Getenv("SELFTEST_CHILD") == "1" {
+		os.Exit(runSelfTest([]string{"-o", "json"}, os.Stdout, os.Stderr))
+	}
+	if testing.Short() {
+		t.Skip("boots a full server")
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestSelfTest$")
+	cmd.Env = append(os.Environ(), "SELFTEST_CHILD=1")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		t.Errorf("selftest failed: %v\n%s%s", err, out, stderr.String())
+	}
+
+	var report struct {
+		Passed    bool             `json:"passed"`
+		Scenarios []selfTestResult `json:"scenarios"`
+	}
+	if err := json.NewDecoder(bytes.NewReader(out)).Decode(&report); err != nil {
+		t.Fatalf("invalid report: %v\n%s", err, out)
+	}
+	if len(report.Scenarios) != len(selfTestScenarios()) {
+		t.Errorf("expected a result per scenario, got %d", len(report.Scenarios))
+	}
+	for _, r := range report.Scenarios {
+		if !r.Passed {
+			t.Errorf("scenario %q failed: %s", r.Scenario, r.Error)
+		}
+	}
+}
+
+func TestSelfTest_Output(t *testing.T) {
+	results := []selfTestResult{
+		{Scenario: "banner auction", Passed: true, DurationMs: 4},
+		{Scenario: "video vast", Error: "expected 200, got 500"},
+	}
+	var buf bytes.Buffer
+	writeSelfTestResults(&buf, "text", results)
+
+	want := "PASS  banner auction (4ms)\nFAIL  video vast (0ms): expected 200, got 500\n1/2 scenarios passed\n"
+	if buf.String() != want {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+}
+
+func TestSelfTest_BadFlags(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := runSelfTest([]string{"-o", "yaml"}, &stdout, &stderr); code != 2 {
+		t.Errorf("expected exit code 2, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), "unknown output format") {
+		t.Errorf("unexpected stderr: %s", stderr.String())
+	}
+}
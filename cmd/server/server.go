@@ -2,25 +2,62 @@ package main
 
 import (
 	"context"
+	"crypto/ed25519"
 	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"sort"
 	"time"
 
 	"github.com/thenexusengine/tne_springwire/internal/adapters"
 	_ "github.com/thenexusengine/tne_springwire/internal/adapters/appnexus"
 	_ "github.com/thenexusengine/tne_springwire/internal/adapters/demo"
+	"github.com/thenexusengine/tne_springwire/internal/adapters/ortb"
+	"github.com/thenexusengine/tne_springwire/internal/adapters/plugins"
 	_ "github.com/thenexusengine/tne_springwire/internal/adapters/pubmatic"
 	_ "github.com/thenexusengine/tne_springwire/internal/adapters/rubicon"
+	"github.com/thenexusengine/tne_springwire/internal/adminui"
+	"github.com/thenexusengine/tne_springwire/internal/analytics"
+	"github.com/thenexusengine/tne_springwire/internal/analytics/kafka"
+	"github.com/thenexusengine/tne_springwire/internal/auctionregistry"
+	"github.com/thenexusengine/tne_springwire/internal/auctiontrail"
+	"github.com/thenexusengine/tne_springwire/internal/bidcache"
+	"github.com/thenexusengine/tne_springwire/internal/chaos"
 	pbsconfig "github.com/thenexusengine/tne_springwire/internal/config"
+	"github.com/thenexusengine/tne_springwire/internal/creatives"
+	"github.com/thenexusengine/tne_springwire/internal/currency"
+	"github.com/thenexusengine/tne_springwire/internal/deals"
+	"github.com/thenexusengine/tne_springwire/internal/devicegraph"
 	"github.com/thenexusengine/tne_springwire/internal/endpoints"
 	"github.com/thenexusengine/tne_springwire/internal/exchange"
+	"github.com/thenexusengine/tne_springwire/internal/floors"
+	"github.com/thenexusengine/tne_springwire/internal/health"
+	"github.com/thenexusengine/tne_springwire/internal/houseads"
+	"github.com/thenexusengine/tne_springwire/internal/idmodules"
 	"github.com/thenexusengine/tne_springwire/internal/metrics"
 	"github.com/thenexusengine/tne_springwire/internal/middleware"
+	"github.com/thenexusengine/tne_springwire/internal/pauseads"
+	"github.com/thenexusengine/tne_springwire/internal/qpslimit"
+	"github.com/thenexusengine/tne_springwire/internal/rollup"
+	"github.com/thenexusengine/tne_springwire/internal/slo"
 	"github.com/thenexusengine/tne_springwire/internal/storage"
+	"github.com/thenexusengine/tne_springwire/internal/storedrequest"
+	"github.com/thenexusengine/tne_springwire/internal/warmcache"
+	"github.com/thenexusengine/tne_springwire/internal/winqueue"
+	"github.com/thenexusengine/tne_springwire/pkg/buildinfo"
+	"github.com/thenexusengine/tne_springwire/pkg/deadline"
+	"github.com/thenexusengine/tne_springwire/pkg/featureflags"
+	"github.com/thenexusengine/tne_springwire/pkg/idr"
+	"github.com/thenexusengine/tne_springwire/pkg/kv"
+	"github.com/thenexusengine/tne_springwire/pkg/lifecycle"
 	"github.com/thenexusengine/tne_springwire/pkg/logger"
+	"github.com/thenexusengine/tne_springwire/pkg/lru"
 	"github.com/thenexusengine/tne_springwire/pkg/redis"
+	"github.com/thenexusengine/tne_springwire/pkg/sessionhash"
 )
 
 // Server represents the PBS server
@@ -30,15 +67,86 @@ type Server struct {
 	metrics     *metrics.Metrics
 	exchange    *exchange.Exchange
 	rateLimiter *middleware.RateLimiter
+	standby     *middleware.Standby
 	db          *storage.BidderStore
 	publisher   *storage.PublisherStore
-	redisClient *redis.Client
+	kvStore     kv.Store // Shared KV state (Redis by default)
+
+	// Live exchange rates (nil when currency conversion is disabled)
+	currencyFeed *currency.Feed
+
+	// Warm cache persistence across restarts
+	publisherAuth *middleware.PublisherAuth
+	warmCache     *warmcache.Manager
+
+	// Runtime feature flags (nil when no provider is configured)
+	featureFlags *featureflags.Service
+
+	// Salted session hashing shared by flags and the exchange
+	sessionHasher *sessionhash.Hasher
+
+	// Async win/billing notice processing (nil when disabled)
+	winQueue *winqueue.Queue
+
+	// Hourly business metrics rollup into Postgres (nil without a database)
+	rollups   *storage.RollupStore
+	rollupAgg *rollup.Aggregator
+	rollupJob *rollup.Job
+
+	// Guaranteed deal delivery and pacing (nil without a database)
+	dealStore *storage.DealStore
+	dealPacer *deals.Pacer
+	dealJob   *deals.Job
+
+	// Creative review statuses for approval (nil without a database)
+	creativeStore    *storage.CreativeStore
+	creativeRegistry *creatives.Registry
+
+	// Publisher house ads for auctions without a valid bid (nil without a database)
+	storedCreativeStore *storage.StoredCreativeStore
+	houseAds            *houseads.Library
+
+	// Publisher floor rules merged into imp floors (nil without a database)
+	floorRuleStore *storage.FloorRuleStore
+	floorEngine    *floors.Engine
+
+	// Stored request templates, cached in the KV store (nil without a database)
+	storedRequestStore *storage.StoredRequestStore
+	storedRequests     *storedrequest.Resolver
+
+	// Per-publisher auction latency SLO burn rates
+	sloTracker *slo.Tracker
+
+	// Serving weight for load balancers, scored on auction latency, errors
+	// and degradation
+	healthScorer *health.Scorer
+
+	auctionRegistry *auctionregistry.Registry
+	auctionTrail    *auctiontrail.Recorder
+
+	// Auction, bid, win and video events shipped to ANALYTICS_SINKS (nil
+	// when no sink is configured); analyticsDB backs the postgres sink
+	analytics   *analytics.Pipeline
+	analyticsDB *sql.DB
+
+	// Live auction streams for /admin/debug/tail, closed first on shutdown
+	auctionTail *endpoints.AuctionTail
+
+	// Shutdown hooks registered by each subsystem as it starts
+	lifecycle *lifecycle.Manager
+
+	// Fault injection for resilience rehearsals (nil unless CHAOS_ENABLED)
+	chaos *chaos.Injector
+
+	// Stops the cache invalidation pub/sub listener (nil when not listening)
+	stopInvalidationListener context.CancelFunc
 }
 
 // NewServer creates a new PBS server instance
 func NewServer(cfg *ServerConfig) (*Server, error) {
 	s := &Server{
-		config: cfg,
+		config:    cfg,
+		lifecycle: lifecycle.New(),
 	}
 
 	if err := s.initialize(); err != nil {
@@ -63,6 +171,16 @@ func (s *Server) initialize() error {
 	s.metrics = metrics.NewMetrics("pbs")
 	log.Info().Msg("Prometheus metrics enabled")
 
+	// Cap dependency (postgres, redis, idr) calls and record deadline hits
+	for dependency, limit := range s.config.DependencyCaps {
+		deadline.SetCap(dependency, limit)
+	}
+	deadline.SetRecorder(s.metrics)
+	lru.SetRecorder(s.metrics)
+
+	// Record Redis command latency, errors and error budget state
+	redis.SetRecorder(s.metrics)
+
 	// Initialize database if configured
 	if err := s.initDatabase(); err != nil {
 		// Database failures are non-fatal, log and continue
@@ -72,15 +190,87 @@ func (s *Server) initialize() error {
 	// Initialize middleware
 	s.initMiddleware()
 
+	// Register generic OpenRTB bidders and private adapter plugins before
+	// the exchange lists bidders
+	if err := s.initOrtbBidders(); err != nil {
+		return err
+	}
+	if err := s.initAdapterPlugins(); err != nil {
+		return err
+	}
+
+	// Stamp logs and events with the bidder set once generic bidders and
+	// plugins have registered
+	buildinfo.SetAdapterRegistryHash(adapters.DefaultRegistry.Hash())
+	logger.Restamp()
+
 	// Initialize exchange
 	s.initExchange()
 
+	// Load feature flags before serving so gated features start in the right state
+	s.initFeatureFlags()
+
+	// Aggregate business metrics for long-term reporting
+	s.initRollup()
+
+	// Pace guaranteed deals against their daily bookings
+	s.initDeals()
+
+	// Reject blocked creatives and hold or flag unreviewed ones
+	s.initCreatives()
+
+	// Serve house ads when auctions end without a valid bid
+	s.initHouseAds()
+
+	// Cap house ads per CTV household through the device graph
+	s.initDeviceGraph()
+
+	// Add identity provider IDs to user.eids
+	if err := s.initIDModules(); err != nil {
+		return err
+	}
+
+	// Validate publisher UID2 tokens before they reach bidders
+	s.initUID2()
+
+	// Raise impression floors to publishers' floor rules
+	s.initFloors()
+
+	// Track auction latency against publisher SLO targets
+	s.initSLO()
+
+	// Score a serving weight for load balancers
+	s.initHealthWeight()
+
 	// Initialize Redis if configured
 	if err := s.initRedis(); err != nil {
 		// Redis failures are non-fatal, log and continue
 		log.Warn().Err(err).Msg("Redis initialization failed, continuing with reduced functionality")
 	}
 
+	// Keep currency rates current, sharing fetches through the KV store
+	s.initCurrencyFeed()
+
+	// Share bidder QPS budgets across replicas (per replica without Redis)
+	s.initBidderQPS()
+
+	// Ship auction, bid, win and video events to the analytics sinks
+	if err := s.initAnalytics(); err != nil {
+		return err
+	}
+
+	// Start win/billing notice workers (uses Redis Streams when available)
+	s.initWinQueue()
+
+	// Publish auction summaries for downstream joins (requires Redis)
+	s.initAuctionRegistry()
+
+	// Keep per-auction decision trails for support lookups (any KV backend)
+	s.initAuctionTrail()
+
+	// Expand stored request references (requires a database)
+	s.initStoredRequests()
+
 	// List registered bidders
 	bidders := adapters.DefaultRegistry.ListBidders()
 	log.Info().
@@ -91,9 +281,86 @@ func (s *Server) initialize() error {
 	// Initialize handlers and build HTTP server
 	s.initHandlers()
 
+	// Restore hot caches from the previous instance's snapshot
+	s.initWarmCache()
+
 	return nil
 }
 
+// initWarmCache restores hot caches saved by a previous instance
+func (s *Server) initWarmCache() {
+	log := logger.Log
+
+	cfg := warmcache.DefaultConfig()
+	if !cfg.Enabled {
+		return
+	}
+
+	var backend warmcache.Backend = &warmcache.FileBackend{Path: cfg.FilePath}
+	if cfg.UseRedis && s.kvStore != nil {
+		backend = &warmcache.RedisBackend{Client: s.kvStore, Key: cfg.RedisKey, TTL: cfg.MaxAge}
+	}
+
+	s.warmCache = warmcache.NewManager(backend, cfg.MaxAge)
+	// Snapshot hot caches for the next instance once traffic has stopped
+	s.lifecycle.Register(lifecycle.Hook{Name: "warm cache snapshot", Phase: lifecycle.PhaseSchedulers, Stop: func(ctx context.Context) error {
+		if err := s.warmCache.Save(ctx); err != nil {
+			return err
+		}
+		logger.Log.Info().Msg("Warm cache snapshot saved")
+		return nil
+	}})
+	s.warmCache.Register(s.exchange)
+	if s.publisherAuth != nil {
+		s.warmCache.Register(s.publisherAuth)
+	}
+	if s.currencyFeed != nil {
+		s.warmCache.Register(s.currencyFeed.WarmCache())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	restored, err := s.warmCache.Load(ctx)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to restore warm caches, starting cold")
+		return
+	}
+	log.Info().Int("caches", restored).Msg("Warm caches restored")
+}
+
+// initFeatureFlags loads flags from the configured provider and keeps them
+// refreshed. Without a provider every flag uses its default.
+func (s *Server) initFeatureFlags() {
+	log := logger.Log
+
+	if !s.config.FeatureFlagsEnabled() {
+		return
+	}
+
+	provider, err := featureflags.NewProvider(s.config.FeatureFlags)
+	if err != nil {
+		log.Warn().Err(err).Msg("Feature flags disabled: invalid provider configuration")
+		return
+	}
+
+	s.featureFlags = featureflags.New(provider, s.metrics)
+	s.lifecycle.Register(lifecycle.Hook{Name: "feature flags", Phase: lifecycle.PhaseSchedulers, Stop: lifecycle.Func(s.featureFlags.Stop)})
+	s.featureFlags.SetSessionHasher(s.sessionHasher)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.featureFlags.Refresh(ctx); err != nil {
+		log.Warn().Err(err).Str("provider", provider.Name()).Msg("Initial feature flag load failed, using defaults until next refresh")
+	}
+	s.featureFlags.Start(s.config.FeatureFlagsRefresh)
+	s.exchange.SetFeatureFlags(s.featureFlags)
+
+	log.Info().
+		Str("provider", provider.Name()).
+		Dur("refresh", s.config.FeatureFlagsRefresh).
+		Msg("Feature flags enabled")
+}
+
 // initDatabase initializes database connections
 func (s *Server) initDatabase() error {
 	log := logger.Log
@@ -123,7 +390,16 @@ func (s *Server) initDatabase() error {
 	}
 
 	s.db = storage.NewBidderStore(dbConn)
+	s.db.SetHeaderPolicy(s.config.BidderHeaders)
 	s.publisher = storage.NewPublisherStore(dbConn)
+	s.publisher.SetMaxBidders(s.config.MaxBidders)
+	s.rollups = storage.NewRollupStore(dbConn)
+	s.dealStore = storage.NewDealStore(dbConn)
+	s.creativeStore = storage.NewCreativeStore(dbConn)
+	s.storedCreativeStore = storage.NewStoredCreativeStore(dbConn)
+	s.floorRuleStore = storage.NewFloorRuleStore(dbConn)
+	s.storedRequestStore = storage.NewStoredRequestStore(dbConn)
+	s.analyticsDB = dbConn
 
 	// Load and log bidders from database
 	bidders, err := s.db.ListActive(ctx)
@@ -132,7 +408,16 @@ func (s *Server) initDatabase() error {
 	} else {
 		log.Info().
 			Int("count", len(bidders)).
+			Bool("strict_headers", s.config.BidderHeaders.Strict).
 			Msg("Bidders loaded from PostgreSQL")
+
+		// Rows written before the header policy was tightened still load;
+		// flag them so they can be fixed
+		for _, b := range bidders {
+			if err := s.config.BidderHeaders.Validate(b.HTTPHeaders, b.EndpointURL); err != nil {
+				log.Warn().Err(err).Str("bidder", b.BidderCode).Msg("Bidder http_headers violate header policy")
+			}
+		}
 	}
 
 	// Test publisher store
@@ -157,6 +442,12 @@ func (s *Server) initMiddleware() {
 
 	// Build Auth config with conditional bypass for /openrtb2/auction
 	authConfig := middleware.DefaultAuthConfig()
+	// The admin UI's static files hold no data; its API calls send a key
+	authConfig.PublicReads = append(authConfig.PublicReads, adminui.Paths()...)
+	// The server's own adapters call the simulated DSP without a key
+	if s.config.BidderSimEnabled {
+		authConfig.BypassPaths = append(authConfig.BypassPaths, endpoints.BidderSimPath)
+	}
 	if publisherAuth.IsEnabled() {
 		authConfig.BypassPaths = append(authConfig.BypassPaths, "/openrtb2/auction")
 		log.Info().Msg("PublisherAuth enabled - /openrtb2/auction bypasses general Auth")
@@ -166,6 +457,13 @@ func (s *Server) initMiddleware() {
 
 	// Store rate limiter for graceful shutdown
 	s.rateLimiter = middleware.NewRateLimiter(middleware.DefaultRateLimitConfig())
+	s.lifecycle.Register(lifecycle.Hook{Name: "rate limiter", Phase: lifecycle.PhaseSchedulers, Stop: lifecycle.Func(s.rateLimiter.Stop)})
+
+	// New blue/green instances warm up on shadow traffic before going live
+	s.standby = middleware.NewStandby(s.config.Standby)
+	if s.config.Standby {
+		log.Info().Msg("Starting in standby - serving shadow traffic until POST /admin/standby/activate")
+	}
 
 	log.Info().Msg("Middleware initialized")
 }
@@ -176,50 +474,784 @@ func (s *Server) initExchange() {
 
 	// Create exchange with default registry
 	s.exchange = exchange.New(adapters.DefaultRegistry, s.config.ToExchangeConfig())
+	// Close breakers and flush IDR events once nothing calls bidders
+	s.lifecycle.Register(lifecycle.Hook{Name: "bidder circuit breakers", Phase: lifecycle.PhaseClose, Stop: lifecycle.Func(s.exchange.CloseCircuitBreakers)})
+	if recorder := s.exchange.EventRecorder(); recorder != nil {
+		s.lifecycle.Register(lifecycle.Hook{Name: "idr event recorder", Phase: lifecycle.PhaseClose, Stop: lifecycle.ErrFunc(recorder.Close)})
+	}
 
 	// Wire up metrics for margin tracking
 	s.exchange.SetMetrics(s.metrics)
 	log.Info().Msg("Metrics connected to exchange for margin tracking")
+
+	// Currency rates for converting floors and bids (validated at startup)
+	rates, _ := currency.ParseRates(s.config.CurrencyRates)
+	if converter := s.config.CurrencyConverter(rates); converter != nil {
+		s.exchange.SetCurrencyConverter(converter)
+		log.Info().Int("rates", len(rates)).Msg("Currency conversion enabled")
+	}
+	if bidderCurrencies, _ := parseBidderCurrencies(s.config.BidderCurrencies); len(bidderCurrencies) > 0 {
+		s.exchange.SetBidderCurrencies(bidderCurrencies)
+	}
+
+	// Signed canned bid responses for staging rendering tests
+	if keys, _ := s.config.BidInjectionKeyring(); len(keys) > 0 {
+		s.exchange.SetBidInjectionKeys(keys)
+		log.Warn().Int("keys", len(keys)).Msg("Bid injection enabled")
+	}
+
+	// Sticky session buckets for experiments and IDR exploration (validated at startup)
+	sessionCfg, _ := s.config.SessionHashConfig()
+	s.sessionHasher = sessionhash.New(sessionCfg)
+	s.exchange.SetSessionHasher(s.sessionHasher)
+
+	// Injected bidder and dependency faults (refused in production by Validate)
+	if s.config.ChaosEnabled {
+		s.chaos = chaos.New()
+		s.exchange.SetFaultInjector(s.chaos)
+		log.Warn().Msg("Chaos fault injection enabled: /admin/chaos")
+	}
+
+	// Load per-bidder GDPR scope, ext passthrough and QPS policies from PostgreSQL
+	if s.db != nil {
+		s.loadBidderPolicies()
+	}
 }
 
-// initRedis initializes Redis client
+// loadBidderPolicies (re)loads per-bidder GDPR scopes, ext passthrough
+// policies, UID2 passthrough, QPS caps and media types into the exchange and returns how many
+// bidders have policies
+func (s *Server) loadBidderPolicies() int {
+	log := logger.Log
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	loaded := 0
+	scopes, err := s.db.GetGDPRScopes(ctx)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to load bidder GDPR scopes, using eea_only for all bidders")
+	} else {
+		gdprScopes := make(map[string]middleware.GDPRScope, len(scopes))
+		for code, scope := range scopes {
+			gdprScopes[code] = middleware.ParseGDPRScope(scope)
+		}
+		s.exchange.SetBidderGDPRScopes(gdprScopes)
+		log.Info().Int("count", len(gdprScopes)).Msg("Bidder GDPR scopes loaded")
+		loaded = len(gdprScopes)
+	}
+
+	// Load per-bidder ext passthrough policies
+	extPolicies, err := s.db.GetExtPassthroughPolicies(ctx)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to load bidder ext passthrough policies, forwarding all ext fields")
+	} else {
+		policies := make(map[string]exchange.ExtPassthroughPolicy, len(extPolicies))
+		for code, p := range extPolicies {
+			policies[code] = exchange.NewExtPassthroughPolicy(p.Mode, p.Allowlist)
+		}
+		s.exchange.SetBidderExtPassthrough(policies)
+		log.Info().Int("count", len(policies)).Msg("Bidder ext passthrough policies loaded")
+		if len(policies) > loaded {
+			loaded = len(policies)
+		}
+	}
+
+	// Load the bidders UID2 tokens are forwarded to
+	uid2Bidders, err := s.db.GetUID2Bidders(ctx)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to load UID2 bidders, keeping current list")
+	} else {
+		s.exchange.SetBidderUID2(uid2Bidders)
+		log.Info().Int("count", len(uid2Bidders)).Msg("UID2 bidders loaded")
+	}
+
+	// Load per-bidder outbound QPS caps
+	maxQPS, err := s.db.GetMaxQPS(ctx)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to load bidder max QPS, keeping current caps")
+	} else {
+		s.exchange.SetBidderMaxQPS(maxQPS)
+		log.Info().Int("count", len(maxQPS)).Msg("Bidder max QPS loaded")
+		if len(maxQPS) > loaded {
+			loaded = len(maxQPS)
+		}
+	}
+
+	// Load per-bidder outbound headers, resolving secret references now so
+	// auctions never read the environment or secret files
+	active, err := s.db.ListActive(ctx)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to load bidder http_headers, keeping current headers")
+	} else {
+		resolver := storage.EnvSecretResolver{SecretsDir: s.config.BidderHeaders.SecretsDir}
+		headers := make(map[string]http.Header, len(active))
+		for _, b := range active {
+			if len(b.HTTPHeaders) == 0 {
+				continue
+			}
+			resolved, err := b.ResolveHTTPHeaders(resolver)
+			if err != nil {
+				log.Warn().Err(err).Str("bidder", b.BidderCode).Msg("Failed to resolve bidder http_headers, sending none")
+				continue
+			}
+			headers[b.BidderCode] = resolved
+		}
+		s.exchange.SetBidderHeaders(headers)
+		log.Info().Int("count", len(headers)).Msg("Bidder http_headers loaded")
+	}
+
+	// Load per-bidder media types; bidders without a row keep their
+	// adapter's declared capabilities
+	capable, err := s.db.GetCapabilities(ctx, false, false, false, false)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to load bidder media types, using adapter capabilities")
+	} else {
+		mediaTypes := bidderMediaTypes(capable)
+		s.exchange.SetBidderMediaTypes(mediaTypes)
+		log.Info().Int("count", len(mediaTypes)).Msg("Bidder media types loaded")
+		if len(mediaTypes) > loaded {
+			loaded = len(mediaTypes)
+		}
+	}
+	return loaded
+}
+
+// bidderMediaTypes maps bidders to the media types their rows declare.
+// Rows declaring none are left out rather than cutting the bidder off.
+func bidderMediaTypes(bidders []*storage.Bidder) map[string][]adapters.BidType {
+	mediaTypes := make(map[string][]adapters.BidType, len(bidders))
+	for _, b := range bidders {
+		var types []adapters.BidType
+		if b.SupportsBanner {
+			types = append(types, adapters.BidTypeBanner)
+		}
+		if b.SupportsVideo {
+			types = append(types, adapters.BidTypeVideo)
+		}
+		if b.SupportsNative {
+			types = append(types, adapters.BidTypeNative)
+		}
+		if b.SupportsAudio {
+			types = append(types, adapters.BidTypeAudio)
+		}
+		if len(types) > 0 {
+			mediaTypes[b.BidderCode] = types
+		}
+	}
+	return mediaTypes
+}
+
+// initRedis initializes the shared KV store (Redis unless KV_BACKEND selects
+// memcached or memory)
 func (s *Server) initRedis() error {
 	log := logger.Log
 
-	if s.config.RedisURL == "" {
+	backend := s.config.KVBackend
+	if backend == "" {
+		backend = kv.BackendRedis
+	}
+	if backend == kv.BackendRedis && s.config.RedisURL == "" {
 		log.Info().Msg("REDIS_URL not set, Redis-backed features disabled")
 		return nil
 	}
 
-	var err error
-	s.redisClient, err = redis.New(s.config.RedisURL)
+	store, err := kv.Open(kv.Config{
+		Backend:          backend,
+		RedisURL:         s.config.RedisURL,
+		MemcachedServers: s.config.MemcachedServers,
+	})
 	if err != nil {
-		log.Warn().Err(err).Msg("Failed to connect to Redis")
+		log.Warn().Err(err).Str("backend", backend).Msg("Failed to initialize KV store")
 		return err
 	}
+	s.kvStore = store
 
-	log.Info().Msg("Redis client initialized")
+	log.Info().Str("backend", backend).Msg("KV store initialized")
 	return nil
 }
 
+// initBidderQPS enforces bidders.max_qps on outbound calls. With Redis the
+// token buckets are shared, so the cap holds across replicas.
+func (s *Server) initBidderQPS() {
+	var backend qpslimit.Backend
+	if client, ok := s.kvStore.(*redis.Client); ok {
+		backend = client
+	} else if s.kvStore != nil {
+		logger.Log.Warn().Str("kv_backend", s.config.KVBackend).Msg("Bidder QPS caps need Redis to be shared, each instance enforces max_qps on its own")
+	}
+	s.exchange.SetQPSLimiter(qpslimit.New(backend))
+	logger.Log.Info().Bool("shared", backend != nil).Msg("Bidder QPS shaping enabled")
+}
+
+// initWinQueue starts the workers that fire bidder notice URLs and record
+// win analytics off the request path
+func (s *Server) initWinQueue() {
+	log := logger.Log
+
+	if s.config.WinQueueWorkers == 0 {
+		log.Info().Msg("Win queue disabled (WIN_QUEUE_WORKERS=0)")
+		return
+	}
+
+	// Redis Streams lets any instance process notices received by another;
+	// other KV backends fall back to an in-process buffer
+	var streams winqueue.Streams
+	if client, ok := s.kvStore.(*redis.Client); ok {
+		streams = client
+	} else if s.kvStore != nil {
+		log.Warn().Str("kv_backend", s.config.KVBackend).Msg("Win queue needs Redis Streams to be shared, notices are processed by the instance that received them")
+	}
+
+	processors := []winqueue.Processor{winqueue.NewNoticeFirer(5*time.Second, s.metrics)}
+	if recorder := s.exchange.EventRecorder(); recorder != nil {
+		processors = append(processors, winqueue.NewWinAnalytics(recorder))
+	}
+	if s.rollupAgg != nil {
+		processors = append(processors, s.rollupAgg)
+	}
+	if s.dealPacer != nil {
+		processors = append(processors, s.dealPacer)
+	}
+	// Last, so notices retried by the other processors are shipped once
+	if s.analytics != nil {
+		processors = append(processors, winqueue.NewEventAnalytics(s.analytics))
+	}
+
+	cfg := winqueue.DefaultConfig()
+	cfg.Workers = s.config.WinQueueWorkers
+	queue := winqueue.New(streams, cfg, s.metrics, processors...)
+	if err := queue.Start(context.Background()); err != nil {
+		log.Warn().Err(err).Msg("Failed to start win queue, notices will not be processed")
+		return
+	}
+	s.winQueue = queue
+	s.lifecycle.Register(lifecycle.Hook{Name: "win queue", Phase: lifecycle.PhaseQueues, Stop: lifecycle.Func(queue.Stop)})
+	s.exchange.SetLossNotifier(queue)
+}
+
+// initAnalytics starts the analytics pipeline for the sinks in
+// ANALYTICS_SINKS, plus the Kafka producer when KAFKA_BROKERS is set, and
+// hooks it into the exchange. The postgres sink is skipped without a
+// database, like other database-backed features; any other sink that can't
+// be built (an unwritable file) fails startup.
+func (s *Server) initAnalytics() error {
+	log := logger.Log
+
+	cfg := s.config.Analytics
+	if s.analyticsDB == nil {
+		names := make([]string, 0, len(cfg.Sinks))
+		for _, name := range cfg.Sinks {
+			if name == analytics.SinkPostgres {
+				log.Warn().Msg("Postgres analytics sink disabled (no database)")
+				continue
+			}
+			names = append(names, name)
+		}
+		cfg.Sinks = names
+	}
+	if len(cfg.Sinks) == 0 && len(s.config.Kafka.Brokers) == 0 {
+		log.Info().Msg("Analytics pipeline disabled (no ANALYTICS_SINKS or KAFKA_BROKERS)")
+		return nil
+	}
+
+	sinks, err := analytics.NewSinks(cfg, s.analyticsDB)
+	if err != nil {
+		return fmt.Errorf("failed to create analytics sinks: %w", err)
+	}
+	if len(s.config.Kafka.Brokers) > 0 {
+		producer, err := kafka.NewSink(s.config.Kafka, s.metrics)
+		if err != nil {
+			return fmt.Errorf("failed to create kafka sink: %w", err)
+		}
+		sinks = append(sinks, producer)
+		log.Info().
+			Strs("brokers", s.config.Kafka.Brokers).
+			Str("format", s.config.Kafka.Format).
+			Msg("Kafka analytics producer enabled")
+	}
+	ids, err := analytics.NewIDObfuscator(cfg.IDs)
+	if err != nil {
+		return fmt.Errorf("invalid analytics identifier config: %w", err)
+	}
+	s.analytics = analytics.New(cfg, s.metrics, sinks...)
+	// Ship the events still buffered once auctions, notices and video
+	// events have stopped
+	s.lifecycle.Register(lifecycle.Hook{Name: "analytics pipeline", Phase: lifecycle.PhaseFlush, Timeout: 10 * time.Second, Stop: lifecycle.Func(s.analytics.Stop)})
+	// User and session IDs leave as per-publisher tokens
+	s.analytics.SetIDObfuscator(ids)
+	s.analytics.Start()
+	s.exchange.SetAnalytics(s.analytics)
+	return nil
+}
+
+// initOrtbBidders registers the generic OpenRTB bidders defined in
+// ORTB_BIDDERS_FILE, e.g. the simulated bidders of the e2e harness
+func (s *Server) initOrtbBidders() error {
+	if s.config.OrtbBiddersFile == "" {
+		return nil
+	}
+
+	configs, err := ortb.LoadFile(s.config.OrtbBiddersFile)
+	if err != nil {
+		return err
+	}
+	if err := ortb.Register(adapters.DefaultRegistry, configs); err != nil {
+		return fmt.Errorf("failed to register OpenRTB bidders: %w", err)
+	}
+
+	codes := make([]string, 0, len(configs))
+	for _, c := range configs {
+		codes = append(codes, c.BidderCode)
+	}
+	logger.Log.Info().Strs("bidders", codes).Str("file", s.config.OrtbBiddersFile).Msg("Generic OpenRTB bidders registered")
+	return nil
+}
+
+// initAdapterPlugins registers the private adapters of the Go plugins listed
+// in ADAPTER_PLUGINS
+func (s *Server) initAdapterPlugins() error {
+	if s.config.AdapterPlugins == "" {
+		return nil
+	}
+
+	paths, err := plugins.Paths(s.config.AdapterPlugins)
+	if err != nil {
+		return err
+	}
+	codes, err := plugins.Load(adapters.DefaultRegistry, paths)
+	if err != nil {
+		return err
+	}
+	logger.Log.Info().Strs("bidders", codes).Strs("plugins", paths).Msg("Adapter plugins registered")
+	return nil
+}
+
+// initAuctionRegistry writes a compact summary of every auction to a Redis
+// stream so billing and reporting can join their data to auction IDs
+func (s *Server) initAuctionRegistry() {
+	log := logger.Log
+
+	if !s.config.AuctionRegistry.Enabled {
+		log.Info().Msg("Auction registry disabled (AUCTION_REGISTRY_ENABLED=false)")
+		return
+	}
+	// Config validation rejects the registry on other KV backends
+	client, ok := s.kvStore.(*redis.Client)
+	if !ok {
+		log.Warn().Msg("Auction registry requires Redis (REDIS_URL not set), not publishing auction summaries")
+		return
+	}
+
+	s.auctionRegistry = auctionregistry.New(client, s.config.AuctionRegistry, s.metrics)
+	s.lifecycle.Register(lifecycle.Hook{Name: "auction registry", Phase: lifecycle.PhaseFlush, Stop: lifecycle.Func(s.auctionRegistry.Stop)})
+	s.auctionRegistry.Start()
+	s.exchange.SetAuctionRegistry(s.auctionRegistry)
+}
+
+// initAuctionTrail records each auction's decision trail in the KV store so
+// /admin/debug/auction/{id} can explain its outcome
+func (s *Server) initAuctionTrail() {
+	log := logger.Log
+
+	if !s.config.AuctionTrail.Enabled {
+		log.Info().Msg("Auction trail disabled (AUCTION_TRAIL_ENABLED=false)")
+		return
+	}
+	if s.kvStore == nil {
+		log.Warn().Msg("Auction trail requires a KV store, not recording auction trails")
+		return
+	}
+
+	s.auctionTrail = auctiontrail.New(s.kvStore, s.config.AuctionTrail, s.metrics)
+	s.lifecycle.Register(lifecycle.Hook{Name: "auction trail", Phase: lifecycle.PhaseFlush, Stop: lifecycle.Func(s.auctionTrail.Stop)})
+	s.auctionTrail.Start()
+	s.exchange.SetAuctionTrail(s.auctionTrail)
+}
+
+// initCurrencyFeed fetches exchange rates from CURRENCY_RATES_SOURCE every
+// interval, caching them in the KV store so replicas share one fetch. Without
+// a source the static CURRENCY_RATES stay in use and are only reported at
+// /currency/rates.
+func (s *Server) initCurrencyFeed() {
+	log := logger.Log
+
+	if !s.config.CurrencyConversionEnabled {
+		return
+	}
+
+	cfg := s.config.CurrencyFeed
+	rates, _ := currency.ParseRates(s.config.CurrencyRates)
+	s.currencyFeed = currency.NewFeed(cfg, s.config.DefaultCurrency, rates, s.kvStore, s.metrics, s.exchange.SetCurrencyConverter)
+	s.lifecycle.Register(lifecycle.Hook{Name: "currency feed", Phase: lifecycle.PhaseSchedulers, Stop: lifecycle.Func(s.currencyFeed.Stop)})
+	if cfg.Source == "" {
+		log.Info().Msg("Live currency rates disabled (CURRENCY_RATES_SOURCE not set)")
+		return
+	}
+
+	s.currencyFeed.Start()
+	log.Info().
+		Str("source", cfg.Source).
+		Dur("interval", cfg.Interval).
+		Bool("cached", s.kvStore != nil).
+		Msg("Live currency rates enabled")
+}
+
+// initStoredRequests merges the templates referenced by
+// ext.prebid.storedrequest.id into auction requests, caching them in the KV
+// store when one is available
+func (s *Server) initStoredRequests() {
+	log := logger.Log
+
+	if s.storedRequestStore == nil {
+		log.Info().Msg("Stored requests disabled (no database)")
+		return
+	}
+
+	s.storedRequests = storedrequest.NewResolver(s.storedRequestStore, s.kvStore, s.config.StoredRequestCacheTTL)
+	log.Info().
+		Bool("cached", s.kvStore != nil).
+		Dur("cache_ttl", s.config.StoredRequestCacheTTL).
+		Msg("Stored requests enabled")
+}
+
+// initRollup counts auctions, wins and revenue per publisher and bidder and
+// writes hourly totals to Postgres, which keeps them far longer than Prometheus
+func (s *Server) initRollup() {
+	log := logger.Log
+
+	if s.rollups == nil {
+		log.Info().Msg("Metrics rollup disabled (no database)")
+		return
+	}
+
+	s.rollupAgg = rollup.NewAggregator()
+	s.exchange.SetRollup(s.rollupAgg)
+	s.rollupJob = rollup.NewJob(s.rollupAgg, s.rollups, s.config.Rollup)
+	// Write the final totals once wins have stopped arriving
+	s.lifecycle.Register(lifecycle.Hook{Name: "metrics rollup", Phase: lifecycle.PhaseFlush, Timeout: 10 * time.Second, Stop: s.rollupJob.Stop})
+	s.rollupJob.Start()
+
+	log.Info().
+		Dur("interval", s.config.Rollup.Interval).
+		Int("retention_months", s.config.Rollup.RetentionMonths).
+		Msg("Metrics rollup enabled")
+}
+
+// initDeals counts billed impressions per guaranteed deal and ranks bids for
+// deals behind their pro-rata daily goal first. Delivery is shared between
+// instances through Postgres.
+func (s *Server) initDeals() {
+	log := logger.Log
+
+	if s.dealStore == nil {
+		log.Info().Msg("Deal pacing disabled (no database)")
+		return
+	}
+
+	s.dealPacer = deals.NewPacer()
+	s.dealJob = deals.NewJob(s.dealPacer, s.dealStore, s.config.Deals)
+	// Write the final delivery once billing notices have stopped arriving
+	s.lifecycle.Register(lifecycle.Hook{Name: "deal delivery", Phase: lifecycle.PhaseFlush, Timeout: 10 * time.Second, Stop: s.dealJob.Stop})
+	s.dealJob.Start()
+	s.exchange.SetDealPacer(s.dealPacer)
+
+	log.Info().
+		Dur("interval", s.config.Deals.Interval).
+		Msg("Deal pacing enabled")
+}
+
+// initCreatives registers the creatives bidders return for publishers with an
+// approval policy, for manual review. Blocked creatives are rejected
+// everywhere; unreviewed ones are held or flagged per publisher. Review
+// statuses are shared between instances through Postgres.
+func (s *Server) initCreatives() {
+	log := logger.Log
+
+	if s.creativeStore == nil {
+		log.Info().Msg("Creative approval disabled (no database)")
+		return
+	}
+
+	s.creativeRegistry = creatives.NewRegistry(s.creativeStore, s.config.Creatives)
+	s.lifecycle.Register(lifecycle.Hook{Name: "creative registry", Phase: lifecycle.PhaseFlush, Stop: s.creativeRegistry.Stop})
+	s.creativeRegistry.Start()
+	s.exchange.SetCreativeRegistry(s.creativeRegistry)
+
+	log.Info().
+		Dur("interval", s.config.Creatives.Interval).
+		Msg("Creative approval enabled")
+}
+
+// initHouseAds fills auctions that end without a valid bid with the
+// publisher's house ads from stored_creatives, reloaded every interval
+func (s *Server) initHouseAds() {
+	log := logger.Log
+
+	if s.storedCreativeStore == nil {
+		log.Info().Msg("House ads disabled (no database)")
+		return
+	}
+
+	s.houseAds = houseads.NewLibrary(s.storedCreativeStore, s.config.HouseAds)
+	s.lifecycle.Register(lifecycle.Hook{Name: "house ads", Phase: lifecycle.PhaseSchedulers, Stop: lifecycle.Func(s.houseAds.Stop)})
+	s.houseAds.Start()
+	s.exchange.SetHouseAds(s.houseAds)
+
+	log.Info().
+		Dur("interval", s.config.HouseAds.Interval).
+		Msg("House ads enabled")
+}
+
+// initDeviceGraph resolves CTV viewers to households through the configured
+// device graph service, so house ad frequency caps follow the household
+func (s *Server) initDeviceGraph() {
+	log := logger.Log
+
+	cfg := s.config.DeviceGraph
+	if cfg.URL == "" {
+		log.Info().Msg("Device graph disabled (no DEVICE_GRAPH_URL)")
+		return
+	}
+
+	s.exchange.SetDeviceGraph(devicegraph.New(cfg, s.metrics))
+
+	log.Info().
+		Dur("timeout", cfg.Timeout).
+		Dur("cache_ttl", cfg.CacheTTL).
+		Msg("Device graph enabled")
+}
+
+// initIDModules asks the identity providers defined in ID_MODULES_FILE for
+// the viewer's IDs, which bidders then see in user.eids. With IDR enabled,
+// the IDR service is one of them unless the file defines its own idr module.
+func (s *Server) initIDModules() error {
+	log := logger.Log
+
+	var configs []idmodules.Config
+	if s.config.IDModulesFile != "" {
+		var err error
+		if configs, err = idmodules.LoadFile(s.config.IDModulesFile); err != nil {
+			return err
+		}
+	}
+	if s.config.IDREnabled && s.config.IDRUrl != "" && !idmodules.HasType(configs, idmodules.TypeIDR) {
+		configs = append(configs, idmodules.IDRConfig(s.config.IDRUrl, s.config.IDRAPIKey))
+		if err := idmodules.ValidateConfigs(configs); err != nil {
+			return err
+		}
+	}
+	if len(configs) == 0 {
+		log.Info().Msg("Identity modules disabled (no ID_MODULES_FILE or IDR)")
+		return nil
+	}
+
+	enricher := idmodules.New(configs, s.metrics)
+	s.exchange.SetIdentityResolver(enricher)
+
+	names := make([]string, 0, len(configs))
+	for _, m := range enricher.Modules() {
+		names = append(names, m.Name())
+	}
+	log.Info().
+		Str("file", s.config.IDModulesFile).
+		Strs("modules", names).
+		Msg("Identity modules enabled")
+	return nil
+}
+
+// initUID2 checks the UID2 tokens publishers send against the UID2 operator
+// when UID2_OPERATOR_URL is set, and counts them either way. Which bidders
+// receive them is bidders.uid2_enabled, loaded with the bidder policies.
+func (s *Server) initUID2() {
+	validator := idmodules.NewUID2Validator(s.config.UID2, s.metrics)
+	s.exchange.SetUID2Checker(validator)
+
+	logger.Log.Info().
+		Bool("validating", validator.Validates()).
+		Dur("timeout", s.config.UID2.Timeout).
+		Msg("UID2 token handling enabled")
+}
+
+// initFloors raises each impression's bidfloor to the publisher's matching
+// floor rule before bidders are called, reloading rules every interval
+func (s *Server) initFloors() {
+	log := logger.Log
+
+	if s.floorRuleStore == nil {
+		log.Info().Msg("Floor rules disabled (no database)")
+		return
+	}
+
+	s.floorEngine = floors.NewEngine(s.floorRuleStore, s.config.Floors)
+	s.lifecycle.Register(lifecycle.Hook{Name: "floor rules", Phase: lifecycle.PhaseSchedulers, Stop: lifecycle.Func(s.floorEngine.Stop)})
+	s.floorEngine.Start()
+	s.exchange.SetFloors(s.floorEngine)
+
+	log.Info().
+		Dur("interval", s.config.Floors.Interval).
+		Msg("Floor rules enabled")
+}
+
+// initSLO tracks auction response times against each publisher's p95
+// target (slo_p95_ms, or SLO_P95_TARGET_MS) and exports burn rate gauges
+func (s *Server) initSLO() {
+	s.sloTracker = slo.NewTracker(s.config.SLO, s.metrics)
+	s.lifecycle.Register(lifecycle.Hook{Name: "slo tracker", Phase: lifecycle.PhaseSchedulers, Stop: lifecycle.Func(s.sloTracker.Stop)})
+	s.sloTracker.Start()
+
+	logger.Log.Info().
+		Dur("default_target", s.config.SLO.DefaultTarget).
+		Msg("Publisher latency SLO tracking enabled")
+}
+
+// initHealthWeight scores the /health/weight serving weight so weighted load
+// balancers shed traffic from a struggling instance before it fails readiness
+func (s *Server) initHealthWeight() {
+	s.healthScorer = health.NewScorer(s.config.HealthWeight, s.degradationLevel)
+
+	logger.Log.Info().
+		Dur("window", s.config.HealthWeight.Window).
+		Dur("latency_target", s.config.HealthWeight.LatencyTarget).
+		Msg("Health weight scoring enabled")
+}
+
+// degradationLevel reports how degraded the instance's dependencies are for
+// the serving weight. An inactive standby must not serve; Redis over its
+// error budget or an open IDR circuit only cost optional work; bidder
+// circuits open for at least half the bidders visibly thin auctions.
+func (s *Server) degradationLevel() (health.Level, []string) {
+	level := health.LevelNone
+	var reasons []string
+	raise := func(l health.Level, reason string) {
+		if l > level {
+			level = l
+		}
+		reasons = append(reasons, reason)
+	}
+
+	if s.standby != nil && !s.standby.Active() {
+		raise(health.LevelOffline, "standby")
+	}
+	if kv.IsDegraded(s.kvStore) {
+		raise(health.LevelMinor, "redis")
+	}
+	if client := s.exchange.GetIDRClient(); client != nil && client.IsCircuitOpen() {
+		raise(health.LevelMinor, "idr")
+	}
+
+	breakers := s.exchange.GetBidderCircuitBreakerStats()
+	open := 0
+	for _, stats := range breakers {
+		if stats.State == idr.StateOpen {
+			open++
+		}
+	}
+	switch {
+	case open > 0 && open*2 >= len(breakers):
+		raise(health.LevelMajor, "bidders")
+	case open > 0:
+		raise(health.LevelMinor, "bidders")
+	}
+	return level, reasons
+}
+
+// initCacheInvalidation shares cache invalidation commands between replicas
+// over Redis pub/sub so CMS-driven config changes apply everywhere
+func (s *Server) initCacheInvalidation(h *endpoints.CacheAdminHandler) {
+	log := logger.Log
+
+	client, ok := s.kvStore.(*redis.Client)
+	if !ok || !s.config.CacheInvalidationPubSub {
+		log.Info().Msg("Cache invalidation pub/sub disabled, invalidations apply to this instance only")
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	messages, err := client.Subscribe(ctx, endpoints.CacheInvalidationChannel)
+	if err != nil {
+		cancel()
+		log.Warn().Err(err).Msg("Failed to subscribe to cache invalidations, invalidations apply to this instance only")
+		return
+	}
+
+	h.SetPublisher(client)
+	go h.Listen(messages)
+	s.stopInvalidationListener = cancel
+	s.lifecycle.Register(lifecycle.Hook{Name: "cache invalidation listener", Phase: lifecycle.PhaseSchedulers, Stop: lifecycle.Func(cancel)})
+	log.Info().Str("channel", endpoints.CacheInvalidationChannel).Msg("Cache invalidation pub/sub enabled")
+}
+
 // initHandlers initializes HTTP handlers and builds the handler chain
 func (s *Server) initHandlers() {
 	log := logger.Log
 
 	// Create handlers
 	auctionHandler := endpoints.NewAuctionHandler(s.exchange)
+	s.auctionTail = endpoints.NewAuctionTail()
+	// Tail streams never go idle, so the HTTP server would wait on them
+	// until the deadline. Clients are told to reconnect, which lands them
+	// on another instance.
+	s.lifecycle.Register(lifecycle.Hook{Name: "auction tail streams", Phase: lifecycle.PhaseDrain, Stop: s.auctionTail.Drain})
+	auctionHandler.SetTail(s.auctionTail)
+	auctionHandler.SetUpgradeRecorder(s.metrics)
+	if s.sloTracker != nil {
+		auctionHandler.SetSLORecorder(s.sloTracker)
+	}
+	if s.storedRequests != nil {
+		auctionHandler.SetStoredRequests(s.storedRequests)
+	}
 	statusHandler := endpoints.NewStatusHandler()
 	biddersHandler := endpoints.NewDynamicInfoBiddersHandler(adapters.DefaultRegistry)
+	if s.db != nil {
+		biddersHandler.SetCapabilityStore(s.db)
+	}
 
 	// Video handlers
 	videoHandler := endpoints.NewVideoHandler(s.exchange, s.config.HostURL)
-	videoEventHandler := endpoints.NewVideoEventHandler(nil) // Analytics can be added later
+	if s.config.VASTValidation != "" && s.config.VASTValidation != endpoints.VASTValidationOff {
+		videoHandler.SetValidation(s.config.VASTValidation)
+		log.Info().Str("mode", s.config.VASTValidation).Msg("VAST response validation enabled")
+	}
+	var videoAnalytics endpoints.VideoAnalytics
+	if s.analytics != nil {
+		videoAnalytics = endpoints.NewPipelineVideoAnalytics(s.analytics)
+	}
+	videoEventHandler := endpoints.NewVideoEventHandler(videoAnalytics)
+
+	// Players double-fire quartile pixels; SETNX in the KV store keeps only
+	// the first (bid_id, event) within the window across all instances
+	if s.kvStore != nil && s.config.VideoEventDedupWindow > 0 {
+		videoEventHandler.SetDeduplication(s.kvStore, s.config.VideoEventDedupWindow, s.metrics)
+		log.Info().Dur("window", s.config.VideoEventDedupWindow).Msg("Video event deduplication enabled")
+	}
+
+	// Pause ads auction a non-linear impression; caps are kept per hashed
+	// session, shared across replicas when a KV store is configured
+	pauseAdConfig := pauseads.DefaultConfig()
+	pauseAdService := pauseads.NewPauseAdService(pauseAdConfig, pauseads.NewExchangeRequester(s.exchange, pauseAdConfig))
+	pauseAdService.SetSessionHasher(s.sessionHasher)
+	if s.kvStore != nil {
+		pauseAdService.SetKVStore(s.kvStore)
+	}
+	s.lifecycle.Register(lifecycle.Hook{Name: "pause ad tracker", Phase: lifecycle.PhaseSchedulers, Stop: lifecycle.Func(pauseAdService.Shutdown)})
 
 	log.Info().Msg("Video handlers initialized")
 
-	// Cookie sync handlers
+	// Initialize privacy middleware
+	privacyConfig := middleware.DefaultPrivacyConfig()
+	if s.config.DisableGDPREnforcement {
+		privacyConfig.EnforceGDPR = false
+		log.Warn().Msg("Request-level GDPR enforcement disabled via deprecated PBS_DISABLE_GDPR_ENFORCEMENT; bidder GDPR scopes still apply")
+	}
+
+	// Cookie sync handlers: only bidders enabled for auctions are synced, under
+	// the same GDPR/CCPA enforcement as auctions
 	cookieSyncConfig := endpoints.DefaultCookieSyncConfig(s.config.HostURL)
+	cookieSyncConfig.Bidders = adapters.DefaultRegistry
+	cookieSyncConfig.Privacy = &privacyConfig
 	cookieSyncHandler := endpoints.NewCookieSyncHandler(cookieSyncConfig)
+	cookieSyncHandler.SetMetrics(s.metrics)
 	setuidHandler := endpoints.NewSetUIDHandler(cookieSyncHandler.ListBidders())
 	optoutHandler := endpoints.NewOptOutHandler()
 
@@ -228,13 +1260,7 @@ func (s *Server) initHandlers() {
 		Int("syncers", len(cookieSyncHandler.ListBidders())).
 		Msg("Cookie sync initialized")
 
-	// Initialize privacy middleware
-	privacyConfig := middleware.DefaultPrivacyConfig()
-	if s.config.DisableGDPREnforcement {
-		privacyConfig.EnforceGDPR = false
-		log.Warn().Msg("GDPR enforcement disabled via PBS_DISABLE_GDPR_ENFORCEMENT")
-	}
-	privacyMiddleware := middleware.NewPrivacyMiddleware(privacyConfig)
+	privacyMiddleware := middleware.NewPrivacyMiddlewareWithMetrics(privacyConfig, s.metrics)
 
 	// Wrap auction handler with privacy middleware
 	privacyProtectedAuction := privacyMiddleware(auctionHandler)
@@ -250,8 +1276,18 @@ func (s *Server) initHandlers() {
 	mux.Handle("/openrtb2/auction", privacyProtectedAuction)
 	mux.Handle("/status", statusHandler)
 	mux.Handle("/health", healthHandler())
-	mux.Handle("/health/ready", readyHandler(s.redisClient, s.publisher, s.exchange))
+	mux.Handle("/version", versionHandler(adapters.DefaultRegistry))
+	mux.Handle("/health/ready", readyHandler(s.kvStore, s.publisher, s.exchange, s.standby))
+	mux.Handle("/health/weight", s.healthScorer.Handler())
 	mux.Handle("/info/bidders", biddersHandler)
+	if s.currencyFeed != nil {
+		mux.Handle("/currency/rates", endpoints.NewCurrencyRatesHandler(s.currencyFeed))
+	}
+
+	// Prebid.js s2sConfig generated from the publisher's bidders in the database
+	if s.db != nil {
+		mux.Handle("/prebid/settings", endpoints.NewPrebidSettingsHandler(s.config.HostURL, s.config.Timeout, s.db))
+	}
 
 	// Cookie sync endpoints
 	mux.Handle("/cookie_sync", cookieSyncHandler)
@@ -261,9 +1297,52 @@ func (s *Server) initHandlers() {
 	// Video endpoints
 	mux.HandleFunc("/video/vast", videoHandler.HandleVASTRequest)
 	mux.HandleFunc("/video/openrtb", videoHandler.HandleOpenRTBVideo)
+	mux.Handle("/video/pause", pauseads.NewPauseAdHandler(pauseAdService))
 	endpoints.RegisterVideoEventRoutes(mux, videoEventHandler)
 
-	log.Info().Msg("Video endpoints registered: /video/vast, /video/openrtb, /video/event/*")
+	log.Info().Msg("Video endpoints registered: /video/vast, /video/openrtb, /video/pause, /video/event/*")
+
+	// Player SDK settings, signed so the player can verify them before use
+	if key, _ := s.config.PlayerSigningKeyPair(); key != nil {
+		var publishers endpoints.PublisherLookup
+		if s.publisher != nil {
+			publishers = s.publisher
+		}
+		mux.Handle("/video/config", endpoints.NewPlayerConfigHandler(s.config.PlayerDefaults, publishers, key, s.config.PlayerKeyID))
+		log.Info().
+			Str("key_id", s.config.PlayerKeyID).
+			Str("public_key", base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey))).
+			Msg("Player config endpoint registered: /video/config")
+	} else {
+		log.Info().Msg("Player config endpoint disabled (PLAYER_CONFIG_SIGNING_KEY not set)")
+	}
+
+	// Win/billing notices are rejected once the bid's exp window has passed
+	// and accepted ones are processed asynchronously by the win queue. A
+	// video start bills its bid like the burl pixel. Returned bids are shared
+	// through the KV store so any replica can accept their notices.
+	if s.kvStore != nil {
+		s.exchange.BidExpiry().SetStore(s.kvStore)
+	}
+	winHandler := endpoints.NewWinNoticeHandler(s.exchange.BidExpiry(), s.metrics)
+	if s.winQueue != nil {
+		winHandler.SetQueue(s.winQueue)
+		videoEventHandler.SetBillingNotifier(winHandler)
+	}
+	mux.Handle("/event/win", winHandler)
+
+	// Bid cache: writes need a publisher API key and count against that
+	// publisher's quota in Redis; reads by UUID are public for players
+	if client, ok := s.kvStore.(*redis.Client); ok {
+		bidCache := bidcache.New(client, s.config.BidCache, s.metrics)
+		mux.Handle("/cache", endpoints.NewBidCacheHandler(bidCache))
+		log.Info().
+			Int("max_value_bytes", bidCache.Config().MaxValueBytes).
+			Int64("publisher_quota_bytes", bidCache.Config().PublisherQuotaBytes).
+			Msg("Bid cache endpoint registered: /cache")
+	} else if s.kvStore != nil {
+		log.Warn().Str("kv_backend", s.config.KVBackend).Msg("Bid cache needs Redis scripts, /cache not registered")
+	}
 
 	// Prometheus metrics endpoint
 	mux.Handle("/metrics", metrics.Handler())
@@ -272,15 +1351,140 @@ func (s *Server) initHandlers() {
 	mux.HandleFunc("/admin/circuit-breaker", s.circuitBreakerHandler)
 	dashboardHandler := endpoints.NewDashboardHandler()
 	metricsAPIHandler := endpoints.NewMetricsAPIHandler()
-	publisherAdminHandler := endpoints.NewPublisherAdminHandler(s.redisClient)
+	publisherAdminHandler := endpoints.NewPublisherAdminHandler(s.kvStore)
 	mux.Handle("/admin/dashboard", dashboardHandler)
 	mux.Handle("/admin/metrics", metricsAPIHandler)
 	mux.Handle("/admin/publishers", publisherAdminHandler)
 	mux.Handle("/admin/publishers/", publisherAdminHandler)
 
+	var bidderAdminStore endpoints.BidderAdminStore
+	if s.db != nil {
+		bidderAdminStore = s.db
+	}
+	bidderAdminHandler := endpoints.NewBidderAdminHandler(bidderAdminStore)
+	mux.Handle("/admin/api/bidders", bidderAdminHandler)
+	mux.Handle("/admin/api/bidders/", bidderAdminHandler)
+
+	// Configuration history and rollback (database-backed)
+	var publisherHistoryStore, bidderHistoryStore endpoints.ConfigHistoryStore
+	if s.publisher != nil {
+		publisherHistoryStore = s.publisher
+	}
+	if s.db != nil {
+		bidderHistoryStore = s.db
+	}
+	publisherAdminHandler.SetHistoryHandler(endpoints.NewConfigHistoryHandler("publishers", publisherHistoryStore))
+	var billingStore endpoints.BillingStore
+	if s.publisher != nil {
+		billingStore = s.publisher
+	}
+	publisherAdminHandler.SetBillingHandler(endpoints.NewPublisherBillingHandler(billingStore))
+	if s.publisher != nil {
+		publisherAdminHandler.SetArchiveStore(s.publisher)
+	}
+	mux.Handle("/admin/bidders/", endpoints.NewConfigHistoryHandler("bidders", bidderHistoryStore))
+	cacheAdminHandler := endpoints.NewCacheAdminHandler()
+	publisherAdminHandler.SetInvalidator(cacheAdminHandler)
+	bidderAdminHandler.SetInvalidator(cacheAdminHandler)
+	mux.Handle("/admin/cache/purge", cacheAdminHandler)
+	mux.Handle("/admin/cache/invalidate", cacheAdminHandler)
+	mux.Handle("/admin/debug/tail", s.auctionTail)
+	var auctionTrails endpoints.AuctionTrailReader
+	if s.auctionTrail != nil {
+		auctionTrails = s.auctionTrail
+	}
+	mux.Handle("/admin/debug/auction/", endpoints.NewAuctionDebugHandler(auctionTrails))
+	mux.Handle("/admin/logging", endpoints.NewLogLevelHandler())
+	if s.chaos != nil {
+		mux.Handle("/admin/chaos", endpoints.NewChaosHandler(s.chaos))
+	}
+	if s.config.BidderSimEnabled {
+		mux.Handle(endpoints.BidderSimPath, endpoints.NewBidderSimHandler())
+	}
+	ivtAdminHandler := endpoints.NewIVTAdminHandler()
+	if s.kvStore != nil {
+		ivtAdminHandler.SetKVStore(s.kvStore)
+		ivtAdminHandler.SetInvalidator(cacheAdminHandler)
+	}
+	mux.Handle("/admin/ivt", ivtAdminHandler)
+	mux.Handle("/admin/errors", endpoints.NewRecentErrorsHandler())
+	mux.Handle("/admin/ui", adminui.Handler())
+	mux.Handle(adminui.Prefix, adminui.Handler())
+
+	var rollupReader endpoints.RollupReader
+	if s.rollups != nil {
+		rollupReader = s.rollups
+	}
+	reportsHandler := endpoints.NewReportsHandler(rollupReader)
+	if s.sloTracker != nil {
+		reportsHandler.SetSLOReporter(s.sloTracker)
+	}
+	if s.publisher != nil {
+		reportsHandler.SetBillingLister(s.publisher)
+	}
+	mux.Handle("/admin/reports/hourly", reportsHandler)
+
+	// Publishers read their own advertiser report with their API key
+	var advertiserReader endpoints.AdvertiserFrequencyReader
+	if s.rollups != nil {
+		advertiserReader = s.rollups
+	}
+	mux.Handle("/api/v1/reports/advertisers", endpoints.NewAdvertiserReportHandler(advertiserReader))
+
+	var dealReporter endpoints.DealReporter
+	if s.dealJob != nil {
+		dealReporter = s.dealJob
+	}
+	mux.Handle("/admin/deals", endpoints.NewDealsAdminHandler(dealReporter))
+
+	var creativeReviewer endpoints.CreativeReviewer
+	if s.creativeRegistry != nil {
+		creativeReviewer = s.creativeRegistry
+	}
+	creativesAdminHandler := endpoints.NewCreativesAdminHandler(creativeReviewer)
+	mux.Handle("/admin/api/creatives", creativesAdminHandler)
+	mux.Handle("/admin/api/creatives/", creativesAdminHandler)
+
+	var storedRequestEditor endpoints.StoredRequestEditor
+	if s.storedRequests != nil {
+		storedRequestEditor = s.storedRequests
+	}
+	storedRequestsAdminHandler := endpoints.NewStoredRequestsAdminHandler(storedRequestEditor)
+	if s.storedRequests != nil {
+		// Instances on the memory KV backend cache templates separately
+		storedRequestsAdminHandler.SetInvalidator(cacheAdminHandler)
+		cacheAdminHandler.RegisterInvalidator(endpoints.StoredRequestInvalidationScope, s.storedRequests)
+	}
+	mux.Handle("/admin/api/stored-requests/", storedRequestsAdminHandler)
+
+	if s.standby != nil {
+		standbyHandler := endpoints.NewStandbyHandler(s.standby)
+		mux.Handle("/admin/standby", standbyHandler)
+		mux.Handle("/admin/standby/activate", standbyHandler)
+	}
+
 	// Build middleware chain
 	handler := s.buildHandler(mux)
 
+	// Publisher auth is created while building the chain
+	if s.publisherAuth != nil {
+		cacheAdminHandler.Register("publisher_auth", s.publisherAuth)
+		cacheAdminHandler.RegisterInvalidator("publisher", s.publisherAuth)
+		ivtAdminHandler.SetConfigStore(s.publisherAuth)
+		if s.kvStore != nil {
+			// Pick up scoring saved through /admin/ivt before this start
+			ivtAdminHandler.InvalidateCache()
+			cacheAdminHandler.RegisterInvalidator(endpoints.IVTInvalidationScope, ivtAdminHandler)
+		}
+	}
+	if s.db != nil {
+		// Bidder policies are small, so any bidder change reloads them all
+		cacheAdminHandler.RegisterInvalidator("bidder", endpoints.CacheInvalidatorFunc(func(...string) int {
+			return s.loadBidderPolicies()
+		}))
+	}
+	s.initCacheInvalidation(cacheAdminHandler)
+
 	// Create HTTP server
 	s.httpServer = &http.Server{
 		Addr:         ":" + s.config.Port,
@@ -289,6 +1493,8 @@ func (s *Server) initHandlers() {
 		WriteTimeout: pbsconfig.ServerWriteTimeout,
 		IdleTimeout:  pbsconfig.ServerIdleTimeout,
 	}
+	// Finish in-flight requests before anything they record into stops
+	s.lifecycle.Register(lifecycle.Hook{Name: "http server", Phase: lifecycle.PhaseHTTP, Timeout: 20 * time.Second, Stop: s.httpServer.Shutdown})
 }
 
 // buildHandler builds the middleware chain
@@ -299,19 +1505,36 @@ func (s *Server) buildHandler(mux *http.ServeMux) http.Handler {
 	cors := middleware.NewCORS(middleware.DefaultCORSConfig())
 	security := middleware.NewSecurity(nil)
 	publisherAuth := middleware.NewPublisherAuth(middleware.DefaultPublisherAuthConfig())
+	s.publisherAuth = publisherAuth
 
 	// Build Auth config with conditional bypass
 	authConfig := middleware.DefaultAuthConfig()
+	// The admin UI's static files hold no data; its API calls send a key
+	authConfig.PublicReads = append(authConfig.PublicReads, adminui.Paths()...)
+	// The server's own adapters call the simulated DSP without a key
+	if s.config.BidderSimEnabled {
+		authConfig.BypassPaths = append(authConfig.BypassPaths, endpoints.BidderSimPath)
+	}
 	if publisherAuth.IsEnabled() {
 		authConfig.BypassPaths = append(authConfig.BypassPaths, "/openrtb2/auction")
 	}
 	auth := middleware.NewAuth(authConfig)
 	sizeLimiter := middleware.NewSizeLimiter(middleware.DefaultSizeLimitConfig())
-	gzipMiddleware := middleware.NewGzip(middleware.DefaultGzipConfig())
+	clientHints := middleware.NewClientHints(middleware.DefaultClientHintsConfig())
+	compression := middleware.NewCompression(middleware.DefaultCompressionConfig())
+
+	// Admin retries replay through the shared KV store; without one, only
+	// retries reaching this instance are recognized
+	var idempotencyStore middleware.IdempotencyStore = kv.NewMemory()
+	if s.kvStore != nil {
+		idempotencyStore = s.kvStore
+	}
+	idempotency := middleware.NewIdempotency(idempotencyStore, s.config.AdminIdempotencyTTL)
 
 	// Wire up metrics
 	auth.SetMetrics(s.metrics)
 	s.rateLimiter.SetMetrics(s.metrics)
+	compression.SetMetrics(s.metrics)
 
 	// Wire up stores
 	if s.publisher != nil {
@@ -319,11 +1542,11 @@ func (s *Server) buildHandler(mux *http.ServeMux) http.Handler {
 		log.Info().Msg("Publisher store connected to authentication middleware")
 	}
 
-	// Wire up Redis
-	if s.redisClient != nil {
-		auth.SetRedisClient(s.redisClient)
-		publisherAuth.SetRedisClient(s.redisClient)
-		log.Info().Msg("Redis client set for auth middlewares")
+	// Wire up the shared KV store
+	if s.kvStore != nil {
+		auth.SetRedisClient(s.kvStore)
+		publisherAuth.SetRedisClient(s.kvStore)
+		log.Info().Msg("KV store set for auth middlewares")
 	}
 
 	log.Info().
@@ -333,14 +1556,21 @@ func (s *Server) buildHandler(mux *http.ServeMux) http.Handler {
 		Bool("rate_limiting_enabled", s.rateLimiter != nil).
 		Msg("Middleware chain built")
 
-	// Build chain: CORS -> Security -> Logging -> Size Limit -> Auth -> PublisherAuth -> Rate Limit -> Metrics -> Gzip -> Handler
+	// Build chain: CORS -> Security -> Logging -> Standby -> Latency Budget -> Size Limit -> Auth -> PublisherAuth -> Rate Limit -> Metrics -> Client Hints -> Compression -> Idempotency -> Handler
 	handler := http.Handler(mux)
-	handler = gzipMiddleware.Middleware(handler)
+	handler = idempotency.Middleware(handler)
+	handler = compression.Middleware(handler)
+	handler = clientHints.Middleware(handler)
 	handler = s.metrics.Middleware(handler)
 	handler = s.rateLimiter.Middleware(handler)
 	handler = publisherAuth.Middleware(handler)
 	handler = auth.Middleware(handler)
 	handler = sizeLimiter.Middleware(handler)
+	handler = middleware.NewLatencyBudgetMiddleware(s.metrics, s.config.LatencyBudgetPartners).Middleware(handler)
+	handler = s.healthScorer.Middleware(handler)
+	if s.standby != nil {
+		handler = s.standby.Middleware(handler)
+	}
 	handler = loggingMiddleware(handler)
 	handler = security.Middleware(handler)
 	handler = cors.Middleware(handler)
@@ -381,27 +1611,15 @@ func (s *Server) Start() error {
 	return nil
 }
 
-// Shutdown performs graceful shutdown
+// Shutdown performs graceful shutdown, running the hooks subsystems
+// registered as they started: live streams are drained, the HTTP server
+// finishes in-flight requests, then schedulers, queues and buffered writers
+// stop in that order, and the event recorder and circuit breakers last
 func (s *Server) Shutdown(ctx context.Context) error {
 	log := logger.Log
 	log.Info().Msg("Starting graceful shutdown")
 
-	// Stop rate limiter cleanup goroutine
-	if s.rateLimiter != nil {
-		s.rateLimiter.Stop()
-	}
-
-	// Flush pending events from exchange
-	if s.exchange != nil {
-		if err := s.exchange.Close(); err != nil {
-			log.Warn().Err(err).Msg("Error flushing event recorder")
-		} else {
-			log.Info().Msg("Event recorder flushed")
-		}
-	}
-
-	// Shutdown HTTP server
-	if err := s.httpServer.Shutdown(ctx); err != nil {
+	if err := s.lifecycle.Shutdown(ctx); err != nil {
 		return err
 	}
 
@@ -420,6 +1638,11 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+// Unwrap exposes the underlying writer to http.ResponseController
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // loggingMiddleware logs HTTP requests with structured logging
 func loggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -428,15 +1651,18 @@ func loggingMiddleware(next http.Handler) http.Handler {
 		// Wrap response writer to capture status
 		wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
 
-		// Generate request ID
+		// Use the publisher-supplied request ID when well-formed, else generate one
 		requestID := r.Header.Get("X-Request-ID")
-		if requestID == "" {
+		if !isValidRequestID(requestID) {
 			requestID = generateRequestID()
 		}
 
 		// Add request ID to response
 		w.Header().Set("X-Request-ID", requestID)
 
+		// Propagate request ID to handlers, bidder requests and events
+		r = r.WithContext(logger.WithRequestID(r.Context(), requestID))
+
 		// Process request
 		next.ServeHTTP(wrapped, r)
 
@@ -469,7 +1695,7 @@ func healthHandler() http.Handler {
 		health := map[string]interface{}{
 			"status":    "healthy",
 			"timestamp": time.Now().UTC().Format(time.RFC3339),
-			"version":   "1.0.0",
+			"version":   buildinfo.Version,
 		}
 
 		w.Header().Set("Content-Type", "application/json")
@@ -479,6 +1705,28 @@ func healthHandler() http.Handler {
 	})
 }
 
+// versionHandler describes the running build and the bidder adapters
+// compiled into it
+func versionHandler(registry *adapters.Registry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bidders := registry.ListBidders()
+		sort.Strings(bidders)
+
+		info := buildinfo.Get()
+		info.AdapterRegistryHash = registry.Hash()
+
+		response := struct {
+			buildinfo.Info
+			Bidders []string `json:"bidders"`
+		}{Info: info, Bidders: bidders}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			logger.Log.Error().Err(err).Msg("failed to encode version response")
+		}
+	})
+}
+
 // sanitizeHealthCheckError returns a safe, generic error message for health check responses.
 // SECURITY: Raw error messages from database/Redis may contain sensitive information such as:
 // - Connection strings with hostnames, ports, or credentials
@@ -500,7 +1748,7 @@ func sanitizeHealthCheckError(service string, err error) string {
 // readyHandler returns a readiness check with dependency verification
 // SECURITY: Error messages are sanitized to prevent information disclosure.
 // Raw errors may contain connection strings, hostnames, or internal network details.
-func readyHandler(redisClient *redis.Client, publisherStore *storage.PublisherStore, ex *exchange.Exchange) http.Handler {
+func readyHandler(kvStore kv.Store, publisherStore *storage.PublisherStore, ex *exchange.Exchange, standby *middleware.Standby) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
 		defer cancel()
@@ -508,6 +1756,14 @@ func readyHandler(redisClient *redis.Client, publisherStore *storage.PublisherSt
 		checks := make(map[string]interface{})
 		allHealthy := true
 
+		// A standby instance stays out of load balancer rotation until activated
+		if standby != nil && !standby.Active() {
+			checks["standby"] = map[string]interface{}{
+				"status": "standby",
+			}
+			allHealthy = false
+		}
+
 		// Check database if available
 		if publisherStore != nil {
 			if err := publisherStore.Ping(ctx); err != nil {
@@ -527,14 +1783,21 @@ func readyHandler(redisClient *redis.Client, publisherStore *storage.PublisherSt
 			}
 		}
 
-		// Check Redis if available
-		if redisClient != nil {
-			if err := redisClient.Ping(ctx); err != nil {
+		// Check the KV store if available (reported as "redis" for existing probes)
+		if kvStore != nil {
+			if err := kvStore.Ping(ctx); err != nil {
 				checks["redis"] = map[string]interface{}{
 					"status": "unhealthy",
 					"error":  sanitizeHealthCheckError("redis", err),
 				}
 				allHealthy = false
+			} else if kv.IsDegraded(kvStore) {
+				// Over its error budget but answering: every instance shares
+				// Redis, so this is reported without taking the instance out
+				// of rotation, and optional Redis work is shed meanwhile
+				checks["redis"] = map[string]interface{}{
+					"status": "degraded",
+				}
 			} else {
 				checks["redis"] = map[string]interface{}{
 					"status": "healthy",
@@ -585,6 +1848,24 @@ func readyHandler(redisClient *redis.Client, publisherStore *storage.PublisherSt
 	})
 }
 
+// maxRequestIDLength bounds publisher-supplied request IDs
+const maxRequestIDLength = 128
+
+// isValidRequestID reports whether a client-supplied request ID is safe to
+// forward to bidders and write to logs (bounded length, no control characters)
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		isAlnum := (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+		if !isAlnum && c != '-' && c != '_' && c != '.' && c != ':' {
+			return false
+		}
+	}
+	return true
+}
+
 // generateRequestID creates a unique request ID
 func generateRequestID() string {
 	b := make([]byte, 8)
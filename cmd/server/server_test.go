@@ -4,10 +4,16 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/alicebob/miniredis/v2"
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+	"github.com/thenexusengine/tne_springwire/pkg/buildinfo"
+	"github.com/thenexusengine/tne_springwire/pkg/kv"
 	"github.com/thenexusengine/tne_springwire/pkg/logger"
 	"github.com/thenexusengine/tne_springwire/pkg/redis"
 )
@@ -117,8 +123,64 @@ func TestServer_HealthHandler(t *testing.T) {
 		t.Error("Expected 'timestamp' field in response")
 	}
 
-	if response["version"] != "1.0.0" {
-		t.Errorf("Expected version '1.0.0', got '%v'", response["version"])
+	if response["version"] != buildinfo.Version {
+		t.Errorf("Expected version '%s', got '%v'", buildinfo.Version, response["version"])
+	}
+}
+
+func TestServer_VersionHandler(t *testing.T) {
+	registry := adapters.NewRegistry()
+	registry.Register("rubicon", nil, adapters.BidderInfo{Enabled: true})
+	registry.Register("appnexus", nil, adapters.BidderInfo{Enabled: true})
+
+	rr := httptest.NewRecorder()
+	versionHandler(registry).ServeHTTP(rr, httptest.NewRequest("GET", "/version", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rr.Code)
+	}
+
+	var response struct {
+		buildinfo.Info
+		Bidders []string `json:"bidders"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if response.Version != buildinfo.Version {
+		t.Errorf("Expected version '%s', got '%s'", buildinfo.Version, response.Version)
+	}
+	if response.GoVersion == "" {
+		t.Error("Expected go_version in response")
+	}
+	if response.AdapterRegistryHash != registry.Hash() {
+		t.Errorf("Expected adapter registry hash '%s', got '%s'", registry.Hash(), response.AdapterRegistryHash)
+	}
+	if strings.Join(response.Bidders, ",") != "appnexus,rubicon" {
+		t.Errorf("Expected sorted bidders, got %v", response.Bidders)
+	}
+}
+
+func TestServer_ReadyHandler_Standby(t *testing.T) {
+	if testServer == nil {
+		t.Skip("Test server not initialized")
+	}
+
+	standby := middleware.NewStandby(true)
+	handler := readyHandler(nil, nil, testServer.exchange, standby)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/health/ready", nil))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503 in standby, got %d", rr.Code)
+	}
+
+	standby.Activate()
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/health/ready", nil))
+	if rr.Code != http.StatusOK {
+		t.Errorf("Expected status 200 once active, got %d", rr.Code)
 	}
 }
 
@@ -128,7 +190,7 @@ func TestServer_ReadyHandler_NoRedis(t *testing.T) {
 		t.Skip("Test server not initialized")
 	}
 
-	handler := readyHandler(nil, nil, testServer.exchange) // nil Redis client
+	handler := readyHandler(nil, nil, testServer.exchange, nil) // nil Redis client
 
 	req := httptest.NewRequest("GET", "/health/ready", nil)
 	rr := httptest.NewRecorder()
@@ -218,6 +280,40 @@ func TestLoggingMiddleware_WithExistingRequestID(t *testing.T) {
 	}
 }
 
+func TestLoggingMiddleware_PropagatesRequestIDToContext(t *testing.T) {
+	var ctxRequestID string
+	handler := loggingMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctxRequestID = logger.RequestIDFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("X-Request-ID", "pub-trace-123")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if ctxRequestID != "pub-trace-123" {
+		t.Errorf("Expected request ID in context, got '%s'", ctxRequestID)
+	}
+}
+
+func TestLoggingMiddleware_RejectsMalformedRequestID(t *testing.T) {
+	handler := loggingMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for _, id := range []string{"bad id with spaces", "line\nbreak", strings.Repeat("a", maxRequestIDLength+1)} {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set("X-Request-ID", id)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		if got := rr.Header().Get("X-Request-ID"); got == id || got == "" {
+			t.Errorf("Expected malformed request ID %q to be replaced, got %q", id, got)
+		}
+	}
+}
+
 func TestGenerateRequestID(t *testing.T) {
 	// Generate multiple IDs and check they're unique
 	ids := make(map[string]bool)
@@ -405,8 +501,8 @@ func TestServer_InitRedis_NoURL(t *testing.T) {
 		t.Errorf("Expected no error, got %v", err)
 	}
 
-	if server.redisClient != nil {
-		t.Error("Expected no Redis client when URL is empty")
+	if server.kvStore != nil {
+		t.Error("Expected no KV store when URL is empty")
 	}
 }
 
@@ -609,8 +705,8 @@ func TestHealthHandler_JSONFormat(t *testing.T) {
 		t.Error("Expected 'timestamp' field in response")
 	}
 
-	if response["version"] != "1.0.0" {
-		t.Errorf("Expected version '1.0.0', got '%v'", response["version"])
+	if response["version"] != buildinfo.Version {
+		t.Errorf("Expected version '%s', got '%v'", buildinfo.Version, response["version"])
 	}
 }
 
@@ -620,7 +716,7 @@ func TestReadyHandler_IDRDisabled(t *testing.T) {
 	}
 
 	// Test with IDR disabled (our test server has IDR disabled)
-	handler := readyHandler(nil, nil, testServer.exchange)
+	handler := readyHandler(nil, nil, testServer.exchange, nil)
 
 	req := httptest.NewRequest("GET", "/health/ready", nil)
 	rr := httptest.NewRecorder()
@@ -697,7 +793,7 @@ func TestReadyHandler_WithMockRedis(t *testing.T) {
 		t.Fatalf("Failed to create Redis client: %v", err)
 	}
 
-	handler := readyHandler(testRedis, nil, testServer.exchange)
+	handler := readyHandler(testRedis, nil, testServer.exchange, nil)
 
 	req := httptest.NewRequest("GET", "/health/ready", nil)
 	rr := httptest.NewRecorder()
@@ -732,6 +828,40 @@ func TestReadyHandler_WithMockRedis(t *testing.T) {
 	}
 }
 
+// degradedKV is a KV store over its error budget
+type degradedKV struct {
+	*kv.Memory
+}
+
+func (degradedKV) Degraded() bool { return true }
+
+func TestReadyHandler_RedisDegraded(t *testing.T) {
+	if testServer == nil {
+		t.Skip("Test server not initialized")
+	}
+
+	handler := readyHandler(degradedKV{kv.NewMemory()}, nil, testServer.exchange, nil)
+
+	req := httptest.NewRequest("GET", "/health/ready", nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	// A degraded Redis is shared by every instance, so it doesn't fail readiness
+	if rr.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", rr.Code)
+	}
+
+	var response map[string]interface{}
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	checks, _ := response["checks"].(map[string]interface{})
+	redisCheck, _ := checks["redis"].(map[string]interface{})
+	if redisCheck["status"] != "degraded" {
+		t.Errorf("Expected Redis status 'degraded', got '%v'", redisCheck["status"])
+	}
+}
+
 func TestReadyHandler_RedisConnectionClosed(t *testing.T) {
 	if testServer == nil {
 		t.Skip("Test server not initialized")
@@ -751,7 +881,7 @@ func TestReadyHandler_RedisConnectionClosed(t *testing.T) {
 	// Close miniredis to simulate unhealthy connection
 	mr.Close()
 
-	handler := readyHandler(testRedis, nil, testServer.exchange)
+	handler := readyHandler(testRedis, nil, testServer.exchange, nil)
 
 	req := httptest.NewRequest("GET", "/health/ready", nil)
 	rr := httptest.NewRecorder()
@@ -998,7 +1128,7 @@ func TestReadyHandler_JSONFormat(t *testing.T) {
 		t.Skip("Test server not initialized")
 	}
 
-	handler := readyHandler(nil, nil, testServer.exchange)
+	handler := readyHandler(nil, nil, testServer.exchange, nil)
 
 	req := httptest.NewRequest("GET", "/health/ready", nil)
 	rr := httptest.NewRecorder()
@@ -1085,8 +1215,8 @@ func TestHealthHandler_Standalone(t *testing.T) {
 	// Check version field exists
 	if version, ok := response["version"]; !ok {
 		t.Error("Expected 'version' field in response")
-	} else if version != "1.0.0" {
-		t.Errorf("Expected version '1.0.0', got '%v'", version)
+	} else if version != buildinfo.Version {
+		t.Errorf("Expected version '%s', got '%v'", buildinfo.Version, version)
 	}
 }
 
@@ -1111,7 +1241,7 @@ func TestReadyHandler_ChecksStructure(t *testing.T) {
 		t.Skip("Test server or exchange not initialized")
 	}
 
-	handler := readyHandler(nil, nil, testServer.exchange)
+	handler := readyHandler(nil, nil, testServer.exchange, nil)
 
 	req := httptest.NewRequest("GET", "/health/ready", nil)
 	rr := httptest.NewRecorder()
@@ -1139,3 +1269,20 @@ func TestReadyHandler_ChecksStructure(t *testing.T) {
 		t.Error("Expected 'idr' check in response")
 	}
 }
+
+func TestBidderMediaTypes(t *testing.T) {
+	got := bidderMediaTypes([]*storage.Bidder{
+		{BidderCode: "audio", SupportsAudio: true, SupportsVideo: true},
+		{BidderCode: "banner", SupportsBanner: true},
+		{BidderCode: "undeclared"},
+	})
+	if len(got) != 2 {
+		t.Fatalf("Expected rows without media types left out, got %v", got)
+	}
+	if types := got["audio"]; len(types) != 2 || types[0] != adapters.BidTypeVideo || types[1] != adapters.BidTypeAudio {
+		t.Errorf("Unexpected media types: %v", types)
+	}
+	if types := got["banner"]; len(types) != 1 || types[0] != adapters.BidTypeBanner {
+		t.Errorf("Unexpected media types: %v", types)
+	}
+}
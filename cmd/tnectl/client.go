@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// apiKeyHeader matches the default header checked by the auth middleware
+const apiKeyHeader = "X-API-Key"
+
+// maxErrorBody bounds how much of an error response is read
+const maxErrorBody = 4096
+
+// client calls the PBS admin API
+type client struct {
+	baseURL string
+	apiKey  string
+	http    *http.Client
+}
+
+// apiError is an error response from the admin API
+type apiError struct {
+	Status  int
+	Code    string
+	Message string
+}
+
+func (e *apiError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("%d %s: %s", e.Status, e.Code, e.Message)
+	}
+	return fmt.Sprintf("%d %s", e.Status, e.Code)
+}
+
+// newClient creates an admin API client
+func newClient(baseURL, apiKey string, timeout time.Duration) *client {
+	return &client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		http:    &http.Client{Timeout: timeout},
+	}
+}
+
+// do sends a request with an optional JSON body and decodes the JSON reply into out
+func (c *client) do(method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		switch b := body.(type) {
+		case []byte:
+			reader = bytes.NewReader(b)
+		default:
+			data, err := json.Marshal(body)
+			if err != nil {
+				return fmt.Errorf("encoding request: %w", err)
+			}
+			reader = bytes.NewReader(data)
+		}
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		req.Header.Set(apiKeyHeader, c.apiKey)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		apiErr := &apiError{Status: resp.StatusCode, Code: http.StatusText(resp.StatusCode)}
+		var payload struct {
+			Error   string `json:"error"`
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(data, &payload) == nil && payload.Error != "" {
+			apiErr.Code = payload.Error
+			apiErr.Message = payload.Message
+		} else {
+			apiErr.Message = strings.TrimSpace(string(data))
+		}
+		return apiErr
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/thenexusengine/tne_springwire/internal/endpoints"
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+)
+
+// Admin API paths
+const (
+	bidderPath        = "/admin/api/bidders"
+	publisherPath     = "/admin/publishers"
+	storedRequestPath = "/admin/api/stored-requests"
+	cachePurgePath    = "/admin/cache/purge"
+)
+
+// cli runs subcommands against the admin API
+type cli struct {
+	client *client
+	out    *printer
+}
+
+// dispatch runs the subcommand for a resource and action
+func (c *cli) dispatch(resource, action string, args []string) error {
+	switch resource + " " + action {
+	case "bidder list":
+		return c.bidderList()
+	case "bidder get":
+		return c.bidderGet(args)
+	case "bidder enable":
+		return c.bidderSetEnabled(args, true)
+	case "bidder disable":
+		return c.bidderSetEnabled(args, false)
+	case "bidder gdpr-scope":
+		return c.bidderSetGDPRScope(args)
+	case "publisher list":
+		return c.publisherList()
+	case "publisher create":
+		return c.publisherSave(args, http.MethodPost)
+	case "publisher update":
+		return c.publisherSave(args, http.MethodPut)
+	case "stored-request push":
+		return c.storedRequestPush(args)
+	case "cache purge":
+		return c.cachePurge(args)
+	default:
+		return fmt.Errorf("unknown command %q %q", resource, action)
+	}
+}
+
+// bidderRows renders bidders as table rows
+func bidderRows(bidders []*storage.Bidder) [][]string {
+	rows := make([][]string, 0, len(bidders))
+	for _, b := range bidders {
+		rows = append(rows, []string{
+			b.BidderCode,
+			b.BidderName,
+			strconv.FormatBool(b.Enabled),
+			b.Status,
+			strconv.Itoa(b.TimeoutMs),
+		})
+	}
+	return rows
+}
+
+var bidderHeader = []string{"CODE", "NAME", "ENABLED", "STATUS", "TIMEOUT_MS"}
+
+// pageQuery returns the query string for one page of a list, starting after cursor
+func pageQuery(cursor string) string {
+	q := url.Values{"limit": {strconv.Itoa(storage.MaxListLimit)}}
+	if cursor != "" {
+		q.Set("cursor", cursor)
+	}
+	return "?" + q.Encode()
+}
+
+func (c *cli) bidderList() error {
+	var resp endpoints.BidderListResponse
+	cursor := ""
+	for {
+		var page endpoints.BidderListResponse
+		if err := c.client.do(http.MethodGet, bidderPath+pageQuery(cursor), nil, &page); err != nil {
+			return err
+		}
+		resp.Bidders = append(resp.Bidders, page.Bidders...)
+		resp.Total = page.Total
+		if page.NextCursor == "" || page.NextCursor == cursor {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	resp.Count = len(resp.Bidders)
+	return c.out.print(resp, bidderHeader, bidderRows(resp.Bidders))
+}
+
+func (c *cli) bidderGet(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: bidder get <code>")
+	}
+	var bidder storage.Bidder
+	if err := c.client.do(http.MethodGet, bidderPath+"/"+url.PathEscape(args[0]), nil, &bidder); err != nil {
+		return err
+	}
+	return c.out.print(bidder, bidderHeader, bidderRows([]*storage.Bidder{&bidder}))
+}
+
+func (c *cli) bidderSetEnabled(args []string, enabled bool) error {
+	action := "disable"
+	if enabled {
+		action = "enable"
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("usage: bidder %s <code>", action)
+	}
+	var bidder storage.Bidder
+	if err := c.client.do(http.MethodPost, bidderPath+"/"+url.PathEscape(args[0])+"/"+action, nil, &bidder); err != nil {
+		return err
+	}
+	return c.out.print(bidder, bidderHeader, bidderRows([]*storage.Bidder{&bidder}))
+}
+
+func (c *cli) bidderSetGDPRScope(args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("usage: bidder gdpr-scope <code> <always|eea_only|never>")
+	}
+	var resp map[string]string
+	req := endpoints.GDPRScopeRequest{GDPRScope: args[1]}
+	if err := c.client.do(http.MethodPut, bidderPath+"/"+url.PathEscape(args[0])+"/gdpr_scope", req, &resp); err != nil {
+		return err
+	}
+	return c.out.print(resp, []string{"CODE", "GDPR_SCOPE"}, [][]string{{resp["bidder_code"], resp["gdpr_scope"]}})
+}
+
+var publisherHeader = []string{"ID", "ALLOWED_DOMAINS"}
+
+func (c *cli) publisherList() error {
+	var resp endpoints.PublisherListResponse
+	cursor := ""
+	for {
+		var page endpoints.PublisherListResponse
+		if err := c.client.do(http.MethodGet, publisherPath+pageQuery(cursor), nil, &page); err != nil {
+			return err
+		}
+		resp.Publishers = append(resp.Publishers, page.Publishers...)
+		resp.Total = page.Total
+		if page.NextCursor == "" || page.NextCursor == cursor {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	resp.Count = len(resp.Publishers)
+	sort.Slice(resp.Publishers, func(i, j int) bool { return resp.Publishers[i].ID < resp.Publishers[j].ID })
+	rows := make([][]string, 0, len(resp.Publishers))
+	for _, p := range resp.Publishers {
+		rows = append(rows, []string{p.ID, p.AllowedDomains})
+	}
+	return c.out.print(resp, publisherHeader, rows)
+}
+
+// publisherSave creates (POST) or updates (PUT) a publisher
+func (c *cli) publisherSave(args []string, method string) error {
+	fs := flag.NewFlagSet("publisher", flag.ContinueOnError)
+	id := fs.String("id", "", "Publisher ID")
+	domains := fs.String("domains", "", "Pipe-separated allowed domains")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *id == "" || *domains == "" {
+		return fmt.Errorf("-id and -domains are required")
+	}
+
+	path := publisherPath
+	if method == http.MethodPut {
+		path += "/" + url.PathEscape(*id)
+	}
+	var publisher endpoints.Publisher
+	req := endpoints.PublisherRequest{ID: *id, AllowedDomains: *domains}
+	if err := c.client.do(method, path, req, &publisher); err != nil {
+		return err
+	}
+	return c.out.print(publisher, publisherHeader, [][]string{{publisher.ID, publisher.AllowedDomains}})
+}
+
+// storedRequestPush uploads a stored request or stored imp JSON file under an ID
+func (c *cli) storedRequestPush(args []string) error {
+	fs := flag.NewFlagSet("stored-request push", flag.ContinueOnError)
+	publisherID := fs.String("publisher", "", "Only let this publisher use the template")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	args = fs.Args()
+	if len(args) != 3 || (args[0] != storage.StoredKindRequest && args[0] != storage.StoredKindImp) {
+		return fmt.Errorf("usage: stored-request push [-publisher <id>] <request|imp> <id> <file.json>")
+	}
+	kind, id, file := args[0], args[1], args[2]
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	if !json.Valid(data) {
+		return fmt.Errorf("%s is not valid JSON", file)
+	}
+
+	path := storedRequestPath + "/" + kind + "/" + url.PathEscape(id)
+	if *publisherID != "" {
+		path += "?publisher_id=" + url.QueryEscape(*publisherID)
+	}
+	var resp storage.StoredRequest
+	if err := c.client.do(http.MethodPut, path, data, &resp); err != nil {
+		return err
+	}
+	return c.out.print(resp, []string{"KIND", "ID", "PUBLISHER_ID"}, [][]string{{resp.Kind, resp.ID, resp.PublisherID}})
+}
+
+// cachePurge drops in-memory caches on the server
+func (c *cli) cachePurge(args []string) error {
+	fs := flag.NewFlagSet("cache purge", flag.ContinueOnError)
+	name := fs.String("cache", "", "Only purge this cache")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	path := cachePurgePath
+	if *name != "" {
+		path += "?cache=" + url.QueryEscape(*name)
+	}
+	var resp endpoints.CachePurgeResponse
+	if err := c.client.do(http.MethodPost, path, nil, &resp); err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(resp.Purged))
+	for n := range resp.Purged {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	rows := make([][]string, 0, len(names))
+	for _, n := range names {
+		rows = append(rows, []string{n, strconv.Itoa(resp.Purged[n])})
+	}
+	return c.out.print(resp, []string{"CACHE", "PURGED"}, rows)
+}
@@ -0,0 +1,77 @@
+// Command tnectl administers bidders, publishers, stored requests and caches
+// through the PBS admin API
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+const usage = `Usage: tnectl [flags] <resource> <action> [args]
+
+Resources and actions:
+  bidder list
+  bidder get <code>
+  bidder enable <code>
+  bidder disable <code>
+  bidder gdpr-scope <code> <always|eea_only|never>
+  publisher list
+  publisher create -id <id> -domains <a.com|*.b.com>
+  publisher update -id <id> -domains <a.com|*.b.com>
+  stored-request push [-publisher <id>] <request|imp> <id> <file.json>
+  cache purge [-cache <name>]
+
+Flags:
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run parses global flags and dispatches to a subcommand, returning the exit code
+func run(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("tnectl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	server := fs.String("server", envOrDefault("TNECTL_SERVER", "http://localhost:8000"), "PBS server base URL (env TNECTL_SERVER)")
+	apiKey := fs.String("api-key", os.Getenv("TNECTL_API_KEY"), "Admin API key (env TNECTL_API_KEY)")
+	output := fs.String("o", "table", "Output format: table or json")
+	timeout := fs.Duration("timeout", 10*time.Second, "Request timeout")
+	fs.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *output != outputTable && *output != outputJSON {
+		fmt.Fprintf(stderr, "unknown output format %q\n", *output)
+		return 2
+	}
+	rest := fs.Args()
+	if len(rest) < 2 {
+		fs.Usage()
+		return 2
+	}
+
+	cli := &cli{
+		client: newClient(*server, *apiKey, *timeout),
+		out:    newPrinter(stdout, *output),
+	}
+	if err := cli.dispatch(rest[0], rest[1], rest[2:]); err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// envOrDefault returns the environment variable value or a default
+func envOrDefault(key, defaultValue string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return defaultValue
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/thenexusengine/tne_springwire/internal/endpoints"
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+)
+
+// fakeAdminAPI records requests and serves canned admin API responses
+func fakeAdminAPI(t *testing.T, requests *[]string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests = append(*requests, r.Method+" "+r.URL.RequestURI())
+		if r.Header.Get(apiKeyHeader) != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"error": "unauthorized", "message": "bad key"})
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == bidderPath && r.URL.Query().Get("cursor") == "":
+			w.Write([]byte(`{"bidders":[{"bidder_code":"appnexus","bidder_name":"AppNexus","enabled":true,"status":"active","timeout_ms":200}],"count":1,"total":2,"next_cursor":"appnexus"}`))
+		case r.URL.Path == bidderPath:
+			w.Write([]byte(`{"bidders":[{"bidder_code":"rubicon","bidder_name":"Rubicon","enabled":true,"status":"active","timeout_ms":300}],"count":1,"total":2}`))
+		case r.URL.Path == bidderPath+"/rubicon/disable":
+			w.Write([]byte(`{"bidder_code":"rubicon","enabled":false,"status":"active"}`))
+		case r.URL.Path == bidderPath+"/rubicon/gdpr_scope" && r.Method == http.MethodPut:
+			var req map[string]string
+			json.NewDecoder(r.Body).Decode(&req)
+			json.NewEncoder(w).Encode(map[string]string{"bidder_code": "rubicon", "gdpr_scope": req["gdpr_scope"]})
+		case r.URL.Path == publisherPath:
+			var req map[string]string
+			json.NewDecoder(r.Body).Decode(&req)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]string{"id": req["id"], "allowed_domains": req["allowed_domains"]})
+		case r.URL.Path == cachePurgePath:
+			w.Write([]byte(`{"purged":{"publisher_auth":3}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func runCLI(srv *httptest.Server, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	base := []string{"-server", srv.URL, "-api-key", "secret"}
+	code := run(append(base, args...), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestBidderCommands(t *testing.T) {
+	var requests []string
+	srv := fakeAdminAPI(t, &requests)
+
+	code, out, errOut := runCLI(srv, "bidder", "list")
+	if code != 0 {
+		t.Fatalf("bidder list failed: %s", errOut)
+	}
+	if !strings.Contains(out, "CODE") || !strings.Contains(out, "appnexus") || !strings.Contains(out, "rubicon") {
+		t.Errorf("expected table output with both pages, got %q", out)
+	}
+	if requests[1] != "GET /admin/api/bidders?cursor=appnexus&limit=1000" {
+		t.Errorf("expected second page request, got %v", requests)
+	}
+
+	code, out, _ = runCLI(srv, "-o", "json", "bidder", "disable", "rubicon")
+	if code != 0 || !strings.Contains(out, `"enabled": false`) {
+		t.Errorf("expected JSON output for disable, got %d %q", code, out)
+	}
+	if requests[len(requests)-1] != "POST /admin/api/bidders/rubicon/disable" {
+		t.Errorf("unexpected request %q", requests[len(requests)-1])
+	}
+
+	code, out, _ = runCLI(srv, "bidder", "gdpr-scope", "rubicon", "always")
+	if code != 0 || !strings.Contains(out, "always") {
+		t.Errorf("expected the scope set, got %d %q", code, out)
+	}
+	if requests[len(requests)-1] != "PUT /admin/api/bidders/rubicon/gdpr_scope" {
+		t.Errorf("unexpected request %q", requests[len(requests)-1])
+	}
+}
+
+func TestPublisherCreate(t *testing.T) {
+	var requests []string
+	srv := fakeAdminAPI(t, &requests)
+
+	code, out, errOut := runCLI(srv, "publisher", "create", "-id", "pub1", "-domains", "example.com|*.example.org")
+	if code != 0 {
+		t.Fatalf("publisher create failed: %s", errOut)
+	}
+	if !strings.Contains(out, "pub1") || requests[0] != "POST /admin/publishers" {
+		t.Errorf("unexpected output %q / requests %v", out, requests)
+	}
+
+	if code, _, _ := runCLI(srv, "publisher", "update", "-id", "pub1"); code == 0 {
+		t.Error("expected update without -domains to fail")
+	}
+}
+
+// memStoredRequests is an in-memory endpoints.StoredRequestEditor
+type memStoredRequests map[string]*storage.StoredRequest
+
+func (m memStoredRequests) Get(ctx context.Context, kind, id string) (*storage.StoredRequest, error) {
+	if sr, ok := m[kind+"/"+id]; ok {
+		return sr, nil
+	}
+	return nil, storage.ErrNotFound
+}
+
+func (m memStoredRequests) Put(ctx context.Context, sr *storage.StoredRequest) error {
+	m[sr.Kind+"/"+sr.ID] = sr
+	return nil
+}
+
+func (m memStoredRequests) Delete(ctx context.Context, kind, id string) error {
+	delete(m, kind+"/"+id)
+	return nil
+}
+
+func TestStoredRequestPush(t *testing.T) {
+	// Pushes go to the real admin handler so the CLI's paths can't drift from its routes
+	templates := memStoredRequests{}
+	mux := http.NewServeMux()
+	mux.Handle(storedRequestPath+"/", endpoints.NewStoredRequestsAdminHandler(templates))
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	file := filepath.Join(t.TempDir(), "imp.json")
+	os.WriteFile(file, []byte(`{"banner":{"w":300,"h":250}}`), 0o600)
+
+	code, out, errOut := runCLI(srv, "stored-request", "push", "-publisher", "pub-1", "imp", "mrec", file)
+	if code != 0 {
+		t.Fatalf("stored-request push failed: %s", errOut)
+	}
+	sr, ok := templates["imp/mrec"]
+	if !ok || sr.PublisherID != "pub-1" || string(sr.Data) != `{"banner":{"w":300,"h":250}}` {
+		t.Errorf("expected the imp template saved for pub-1, got %+v", sr)
+	}
+	if !strings.Contains(out, "mrec") || !strings.Contains(out, "pub-1") {
+		t.Errorf("unexpected output %q", out)
+	}
+
+	if code, _, _ := runCLI(srv, "stored-request", "push", "mrec", file); code == 0 {
+		t.Error("expected push without a kind to fail")
+	}
+}
+
+func TestCachePurge(t *testing.T) {
+	var requests []string
+	srv := fakeAdminAPI(t, &requests)
+
+	code, out, _ := runCLI(srv, "cache", "purge", "-cache", "publisher_auth")
+	if code != 0 || !strings.Contains(out, "publisher_auth") {
+		t.Errorf("unexpected cache purge output %d %q", code, out)
+	}
+	if requests[len(requests)-1] != "POST /admin/cache/purge?cache=publisher_auth" {
+		t.Errorf("unexpected request %q", requests[len(requests)-1])
+	}
+}
+
+func TestAPIErrors(t *testing.T) {
+	var requests []string
+	srv := fakeAdminAPI(t, &requests)
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-server", srv.URL, "-api-key", "wrong", "bidder", "list"}, &stdout, &stderr)
+	if code != 1 || !strings.Contains(stderr.String(), "401 unauthorized: bad key") {
+		t.Errorf("expected auth error, got %d %q", code, stderr.String())
+	}
+
+	if code, _, _ := runCLI(srv, "bidder", "explode"); code != 1 {
+		t.Error("expected unknown command to fail")
+	}
+	if code, _, _ := runCLI(srv, "-o", "yaml", "bidder", "list"); code != 2 {
+		t.Error("expected unknown output format to be a usage error")
+	}
+}
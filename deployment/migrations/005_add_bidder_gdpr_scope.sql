@@ -0,0 +1,19 @@
+-- =====================================================
+-- Add Per-Bidder GDPR Scope
+-- =====================================================
+-- Some bidders require GDPR treatment on all traffic,
+-- others only for EEA users, and some (non-EU demand)
+-- never. gdpr_scope replaces the single global
+-- enforcement switch with a per-bidder setting:
+--
+--   always   - vendor consent checked on every request
+--   eea_only - vendor consent checked when geo is EEA/UK
+--              and regs.gdpr = 1 (previous behaviour)
+--   never    - GDPR vendor filtering skipped for bidder
+-- =====================================================
+
+ALTER TABLE bidders
+ADD COLUMN gdpr_scope VARCHAR(20) NOT NULL DEFAULT 'eea_only'
+CHECK (gdpr_scope IN ('always', 'eea_only', 'never'));
+
+COMMENT ON COLUMN bidders.gdpr_scope IS 'GDPR enforcement scope for this bidder: always, eea_only (default), never';
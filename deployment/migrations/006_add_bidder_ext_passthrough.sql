@@ -0,0 +1,26 @@
+-- =====================================================
+-- Add Per-Bidder Ext Passthrough Policy
+-- =====================================================
+-- Controls which unknown ext fields (request, imp, site,
+-- app and user ext keys the exchange doesn't consume)
+-- are forwarded to each bidder:
+--
+--   all       - forward every unknown ext subtree (default)
+--   allowlist - forward only keys listed in
+--               ext_passthrough_allowlist, e.g.
+--               ["custom", "imp.gpid_extra", "user.segments"]
+--   none      - strip every unknown ext subtree
+--
+-- Known keys (prebid, bidder params, data, eids, consent,
+-- schain, ...) are always forwarded.
+-- =====================================================
+
+ALTER TABLE bidders
+ADD COLUMN ext_passthrough VARCHAR(20) NOT NULL DEFAULT 'all'
+CHECK (ext_passthrough IN ('all', 'allowlist', 'none'));
+
+ALTER TABLE bidders
+ADD COLUMN ext_passthrough_allowlist JSONB NOT NULL DEFAULT '[]';
+
+COMMENT ON COLUMN bidders.ext_passthrough IS 'Unknown ext field passthrough mode: all (default), allowlist, none';
+COMMENT ON COLUMN bidders.ext_passthrough_allowlist IS 'Ext keys forwarded in allowlist mode; "key" matches any ext object, "imp.key" only imp.ext';
@@ -0,0 +1,79 @@
+-- =====================================================
+-- Publisher and Bidder Configuration History
+-- =====================================================
+-- Every insert or update of a publisher or bidder row
+-- stores a full snapshot of the new row, keyed by its
+-- optimistic-locking version (migration 004). This lets
+-- operators see what changed and roll back a bad edit
+-- (e.g. bidder_params) via:
+--
+--   GET  /admin/publishers/{id}/history
+--   POST /admin/publishers/{id}/rollback?version=N
+--   GET  /admin/bidders/{code}/history
+--   POST /admin/bidders/{code}/rollback?version=N
+--
+-- A rollback copies the old snapshot back into the row,
+-- which bumps the version and records a new history entry;
+-- history is never rewritten.
+-- =====================================================
+
+CREATE TABLE IF NOT EXISTS publisher_history (
+    id BIGSERIAL PRIMARY KEY,
+    publisher_id VARCHAR(255) NOT NULL,
+    version INTEGER NOT NULL,
+    snapshot JSONB NOT NULL,
+    changed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT publisher_history_version UNIQUE (publisher_id, version)
+);
+
+CREATE TABLE IF NOT EXISTS bidder_history (
+    id BIGSERIAL PRIMARY KEY,
+    bidder_code VARCHAR(50) NOT NULL,
+    version INTEGER NOT NULL,
+    snapshot JSONB NOT NULL,
+    changed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT bidder_history_version UNIQUE (bidder_code, version)
+);
+
+-- Record the new row after every insert/update
+CREATE OR REPLACE FUNCTION record_publisher_history()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO publisher_history (publisher_id, version, snapshot)
+    VALUES (NEW.publisher_id, NEW.version, to_jsonb(NEW))
+    ON CONFLICT (publisher_id, version) DO UPDATE SET snapshot = EXCLUDED.snapshot;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION record_bidder_history()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO bidder_history (bidder_code, version, snapshot)
+    VALUES (NEW.bidder_code, NEW.version, to_jsonb(NEW))
+    ON CONFLICT (bidder_code, version) DO UPDATE SET snapshot = EXCLUDED.snapshot;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_publishers_history
+    AFTER INSERT OR UPDATE ON publishers
+    FOR EACH ROW
+    EXECUTE FUNCTION record_publisher_history();
+
+CREATE TRIGGER trigger_bidders_history
+    AFTER INSERT OR UPDATE ON bidders
+    FOR EACH ROW
+    EXECUTE FUNCTION record_bidder_history();
+
+-- Seed history with the current state of existing rows
+INSERT INTO publisher_history (publisher_id, version, snapshot)
+SELECT publisher_id, version, to_jsonb(p) FROM publishers p
+ON CONFLICT DO NOTHING;
+
+INSERT INTO bidder_history (bidder_code, version, snapshot)
+SELECT bidder_code, version, to_jsonb(b) FROM bidders b
+ON CONFLICT DO NOTHING;
+
+COMMENT ON TABLE publisher_history IS 'Snapshot of every publisher row version, written by trigger';
+COMMENT ON TABLE bidder_history IS 'Snapshot of every bidder row version, written by trigger';
@@ -0,0 +1,18 @@
+-- =====================================================
+-- Add Publisher Default Blocked Creative Attributes
+-- =====================================================
+-- OpenRTB creative attribute IDs (AdCOM List: Creative
+-- Attributes) blocked by default for a publisher, e.g.
+--
+--   [1, 6]        - no auto-play audio or in-banner video
+--   [1, 3, 6, 8]  - also no auto-expandable or pop creatives
+--
+-- Merged into every banner/video/audio/native object that
+-- doesn't carry its own battr, and enforced against
+-- bid.attr during bid validation.
+-- =====================================================
+
+ALTER TABLE publishers
+ADD COLUMN blocked_attributes JSONB NOT NULL DEFAULT '[]';
+
+COMMENT ON COLUMN publishers.blocked_attributes IS 'Default blocked creative attributes (battr) applied to media objects without their own battr';
@@ -0,0 +1,17 @@
+-- =====================================================
+-- Add Publisher Max Bid CPM
+-- =====================================================
+-- Bids priced above this CPM are rejected as anomalous
+-- (e.g. a partner sending $12,000 because of a unit bug)
+-- instead of winning and breaking reconciliation.
+--
+--   0      - use the exchange-wide cap (MAX_BID_CPM)
+--   50.00  - reject bids above $50 CPM for this publisher
+--
+-- A publisher cap never raises the exchange-wide cap.
+-- =====================================================
+
+ALTER TABLE publishers
+ADD COLUMN max_bid_cpm NUMERIC(10, 4) NOT NULL DEFAULT 0 CHECK (max_bid_cpm >= 0);
+
+COMMENT ON COLUMN publishers.max_bid_cpm IS 'Maximum accepted bid CPM for this publisher (0 = exchange-wide cap)';
@@ -0,0 +1,20 @@
+-- =====================================================
+-- Add Publisher Player Config
+-- =====================================================
+-- Per-publisher overrides for the settings the player
+-- SDK fetches from /video/config, e.g.
+--
+--   {}                                   - server defaults
+--   {"pause_ads_enabled": true}          - enable pause ads
+--   {"event_batch_interval_ms": 2000,
+--    "vast_version": "4.2"}              - faster batching, VAST 4.2
+--
+-- Keys: tracking_base_url, event_batch_interval_ms,
+-- pause_ads_enabled, vast_version. Missing keys use the
+-- server defaults (PLAYER_* environment variables).
+-- =====================================================
+
+ALTER TABLE publishers
+ADD COLUMN player_config JSONB NOT NULL DEFAULT '{}';
+
+COMMENT ON COLUMN publishers.player_config IS 'Player SDK setting overrides served from /video/config';
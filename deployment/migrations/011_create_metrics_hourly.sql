@@ -0,0 +1,43 @@
+-- =====================================================
+-- Hourly Business Metrics Rollup
+-- =====================================================
+-- Prometheus keeps weeks of data; finance needs 13 months.
+-- Each instance aggregates requests, bids, wins,
+-- impressions, revenue, payout and margin per publisher,
+-- bidder and media type in memory and upserts its running
+-- totals here every ROLLUP_INTERVAL_SECONDS and on shutdown.
+--
+-- Rows are keyed by instance so concurrent writers never
+-- overwrite each other, and a repeated flush rewrites the
+-- same totals. Reports sum across instances:
+--
+--   GET /admin/reports/hourly?from=...&to=...&publisher_id=...
+--
+-- Request counts are per auction and use bidder = ''.
+-- Rows older than ROLLUP_RETENTION_MONTHS are deleted by
+-- the flush job.
+-- =====================================================
+
+CREATE TABLE IF NOT EXISTS metrics_hourly (
+    hour TIMESTAMP WITH TIME ZONE NOT NULL,
+    publisher_id VARCHAR(255) NOT NULL,
+    bidder VARCHAR(50) NOT NULL DEFAULT '',
+    media_type VARCHAR(20) NOT NULL DEFAULT '',
+    instance_id VARCHAR(100) NOT NULL,
+    requests BIGINT NOT NULL DEFAULT 0,
+    bids BIGINT NOT NULL DEFAULT 0,
+    wins BIGINT NOT NULL DEFAULT 0,
+    impressions BIGINT NOT NULL DEFAULT 0,
+    revenue NUMERIC(18, 6) NOT NULL DEFAULT 0,
+    payout NUMERIC(18, 6) NOT NULL DEFAULT 0,
+    margin NUMERIC(18, 6) NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (hour, publisher_id, bidder, media_type, instance_id)
+);
+
+-- Reports filter by time range, optionally per publisher
+CREATE INDEX IF NOT EXISTS idx_metrics_hourly_publisher_hour ON metrics_hourly(publisher_id, hour);
+
+COMMENT ON TABLE metrics_hourly IS 'Hourly business metrics per publisher/bidder/media type, one row per writing instance';
+COMMENT ON COLUMN metrics_hourly.revenue IS 'Gross revenue from won bids (bidder price before bid multiplier), in the default currency';
+COMMENT ON COLUMN metrics_hourly.payout IS 'Publisher revenue from won bids (price after bid multiplier)';
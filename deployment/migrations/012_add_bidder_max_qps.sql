@@ -0,0 +1,17 @@
+-- =====================================================
+-- Add Per-Bidder Maximum QPS
+-- =====================================================
+-- Some partners contractually cap the QPS we may send
+-- them and answer 429 above it. max_qps caps outbound
+-- calls to the bidder across all replicas (a token
+-- bucket shared through Redis); auctions over the cap
+-- skip the bidder instead of calling it.
+--
+--   0 - no cap (default)
+-- =====================================================
+
+ALTER TABLE bidders
+ADD COLUMN max_qps INTEGER NOT NULL DEFAULT 0
+CHECK (max_qps >= 0);
+
+COMMENT ON COLUMN bidders.max_qps IS 'Outbound requests per second allowed to this bidder across all replicas; 0 = unlimited';
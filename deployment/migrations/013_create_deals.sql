@@ -0,0 +1,44 @@
+-- =====================================================
+-- Programmatic Guaranteed Deal Pacing
+-- =====================================================
+-- deals books a daily impression goal for each
+-- programmatic guaranteed deal. While a deal is behind
+-- its pro-rata goal for the day (goal x fraction of the
+-- UTC day elapsed), its bids are ranked ahead of open
+-- market bids on the same impression.
+--
+-- deal_delivery counts rendered impressions (billing
+-- notices for bids carrying the deal ID) per UTC day.
+-- Like metrics_hourly, each instance upserts its running
+-- totals every DEAL_PACING_INTERVAL_SECONDS and on
+-- shutdown; reports sum across instances:
+--
+--   GET /admin/deals?date=YYYY-MM-DD
+-- =====================================================
+
+CREATE TABLE IF NOT EXISTS deals (
+    deal_id VARCHAR(255) PRIMARY KEY,
+    publisher_id VARCHAR(255) NOT NULL DEFAULT '',
+    bidder_code VARCHAR(50) NOT NULL DEFAULT '',
+    daily_impressions BIGINT NOT NULL CHECK (daily_impressions > 0),
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active'
+        CHECK (status IN ('active', 'paused', 'archived')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK (end_date >= start_date)
+);
+
+CREATE TABLE IF NOT EXISTS deal_delivery (
+    day DATE NOT NULL,
+    deal_id VARCHAR(255) NOT NULL,
+    instance_id VARCHAR(100) NOT NULL,
+    impressions BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (day, deal_id, instance_id)
+);
+
+COMMENT ON TABLE deals IS 'Programmatic guaranteed deal bookings, paced per UTC day';
+COMMENT ON COLUMN deals.daily_impressions IS 'Impressions booked per UTC day between start_date and end_date (inclusive)';
+COMMENT ON TABLE deal_delivery IS 'Rendered impressions per deal and UTC day, one row per writing instance';
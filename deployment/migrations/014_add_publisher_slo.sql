@@ -0,0 +1,18 @@
+-- =====================================================
+-- Add Publisher Latency SLO Target
+-- =====================================================
+-- p95 auction response time target in milliseconds. Each
+-- instance counts auctions over the target and exports
+-- multi-window error budget burn rates
+-- (pbs_publisher_slo_burn_rate{publisher,window}); the
+-- current status is included in /admin/reports/hourly.
+--
+--   0    - use the exchange-wide target (SLO_P95_TARGET_MS),
+--          untracked if that is 0 too
+--   250  - 95% of auctions must answer within 250ms
+-- =====================================================
+
+ALTER TABLE publishers
+ADD COLUMN slo_p95_ms INTEGER NOT NULL DEFAULT 0 CHECK (slo_p95_ms >= 0);
+
+COMMENT ON COLUMN publishers.slo_p95_ms IS 'p95 auction response time target in ms (0 = exchange-wide SLO_P95_TARGET_MS)';
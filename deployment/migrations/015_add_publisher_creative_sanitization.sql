@@ -0,0 +1,23 @@
+-- =====================================================
+-- Add Publisher Creative Sanitization Level
+-- =====================================================
+-- How strictly banner ad markup (bid.adm) is sanitized
+-- before it is returned for this publisher:
+--
+--   ''        - use the exchange-wide level (CREATIVE_SANITIZATION)
+--   off       - pass markup through untouched
+--   standard  - strip event handlers other than img/iframe/script
+--               onload/onerror and anchor onclick, upgrade http
+--               resources to https when imp.secure=1, and wrap
+--               links in CREATIVE_CLICK_MACRO when set
+--   strict    - strip every event handler and reject creatives
+--               loading http resources when imp.secure=1
+--
+-- Actions are counted in pbs_creative_sanitization_actions_total.
+-- =====================================================
+
+ALTER TABLE publishers
+ADD COLUMN creative_sanitization VARCHAR(20) NOT NULL DEFAULT ''
+    CHECK (creative_sanitization IN ('', 'off', 'standard', 'strict'));
+
+COMMENT ON COLUMN publishers.creative_sanitization IS 'Banner markup sanitization level: off, standard or strict ('''' = exchange-wide CREATIVE_SANITIZATION)';
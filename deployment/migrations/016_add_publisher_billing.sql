@@ -0,0 +1,28 @@
+-- =====================================================
+-- Add Publisher Payment Terms and Invoice Metadata
+-- =====================================================
+-- What finance needs to pay a publisher, kept next to the
+-- publisher instead of in a separate spreadsheet:
+--
+--   payment_terms          - net-30 or net-60
+--   billing_currency       - ISO 4217 currency invoices are paid in
+--   invoice_contact_name   - who receives the invoice
+--   invoice_contact_email  - where the invoice is sent
+--
+-- Edited via GET/PUT /admin/publishers/{id}/billing and added
+-- to each publisher in /admin/reports/hourly (including the
+-- CSV export).
+-- =====================================================
+
+ALTER TABLE publishers
+ADD COLUMN payment_terms VARCHAR(10) NOT NULL DEFAULT 'net-30'
+    CHECK (payment_terms IN ('net-30', 'net-60')),
+ADD COLUMN billing_currency CHAR(3) NOT NULL DEFAULT 'USD'
+    CHECK (billing_currency ~ '^[A-Z]{3}$'),
+ADD COLUMN invoice_contact_name VARCHAR(255) NOT NULL DEFAULT '',
+ADD COLUMN invoice_contact_email VARCHAR(255) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN publishers.payment_terms IS 'Payment terms: net-30 or net-60';
+COMMENT ON COLUMN publishers.billing_currency IS 'ISO 4217 currency the publisher is paid in';
+COMMENT ON COLUMN publishers.invoice_contact_name IS 'Name of the invoice recipient';
+COMMENT ON COLUMN publishers.invoice_contact_email IS 'Email address invoices are sent to';
@@ -0,0 +1,23 @@
+-- =====================================================
+-- Add Publisher Ad Language Filter
+-- =====================================================
+-- Whether bids must be in the language of the content
+-- (site/app content.language, falling back to
+-- device.language) for this publisher:
+--
+--   ''                  - no filtering
+--   match               - reject bids whose bid.language
+--                         differs, including unlabeled bids
+--   match_or_unlabeled  - reject bids labeled with another
+--                         language, accept unlabeled ones
+--
+-- Languages are compared by ISO 639-1 code (fr-CA matches
+-- fr). Requests without a language aren't filtered. Bids are
+-- counted in pbs_bids_by_language_total.
+-- =====================================================
+
+ALTER TABLE publishers
+ADD COLUMN language_filter VARCHAR(20) NOT NULL DEFAULT ''
+    CHECK (language_filter IN ('', 'match', 'match_or_unlabeled'));
+
+COMMENT ON COLUMN publishers.language_filter IS 'Ad language filter: match or match_or_unlabeled ('''' = no filtering)';
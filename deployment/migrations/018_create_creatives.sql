@@ -0,0 +1,48 @@
+-- =====================================================
+-- Creative Approval
+-- =====================================================
+-- creatives registers every creative bidders return,
+-- keyed by hash (SHA-256 of bidder code and crid, or of
+-- the markup for bids without a crid), for manual review:
+--
+--   new       - seen but not reviewed yet
+--   approved  - served to every publisher
+--   blocked   - rejected for every publisher
+--
+-- publishers.creative_approval decides what happens to
+-- bids whose creative is still new:
+--
+--   ''    - served
+--   flag  - served, counted as flagged for review
+--   hold  - rejected until approved
+--
+-- Instances insert newly seen creatives and reload the
+-- statuses every CREATIVE_REGISTRY_INTERVAL_SECONDS.
+-- Review in bulk with:
+--
+--   GET  /admin/api/creatives?status=new
+--   POST /admin/api/creatives/approve {"hashes": [...]}
+--   POST /admin/api/creatives/block   {"hashes": [...]}
+-- =====================================================
+
+CREATE TABLE IF NOT EXISTS creatives (
+    hash CHAR(64) PRIMARY KEY,
+    status VARCHAR(20) NOT NULL DEFAULT 'new'
+        CHECK (status IN ('new', 'approved', 'blocked')),
+    bidder_code VARCHAR(50) NOT NULL DEFAULT '',
+    creative_id VARCHAR(255) NOT NULL DEFAULT '',
+    adomain TEXT NOT NULL DEFAULT '',
+    markup TEXT NOT NULL DEFAULT '',
+    first_seen_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_creatives_status ON creatives(status, first_seen_at);
+
+ALTER TABLE publishers
+ADD COLUMN creative_approval VARCHAR(20) NOT NULL DEFAULT ''
+    CHECK (creative_approval IN ('', 'flag', 'hold'));
+
+COMMENT ON TABLE creatives IS 'Creatives returned by bidders and their review status';
+COMMENT ON COLUMN creatives.markup IS 'Markup of the first bid seen, truncated to 16 KiB for review';
+COMMENT ON COLUMN publishers.creative_approval IS 'Unreviewed creatives: flag (serve, flagged) or hold (reject until approved); '''' = serve';
@@ -0,0 +1,36 @@
+-- =====================================================
+-- Stored Requests
+-- =====================================================
+-- stored_requests holds server-side OpenRTB templates
+-- that auction requests reference by ID instead of
+-- sending full bodies:
+--
+--   request - referenced by ext.prebid.storedrequest.id,
+--             a partial bid request
+--   imp     - referenced by imp[].ext.prebid.storedrequest.id,
+--             a partial impression
+--
+-- Incoming fields override the template's (JSON merge
+-- patch), so requests only send what changes per call.
+-- A non-empty publisher_id restricts the template to
+-- that publisher's requests.
+--
+-- Templates are cached in the KV store for
+-- STORED_REQUEST_CACHE_TTL_SECONDS; edits take up to
+-- that long to reach every instance.
+-- =====================================================
+
+CREATE TABLE IF NOT EXISTS stored_requests (
+    kind VARCHAR(16) NOT NULL CHECK (kind IN ('request', 'imp')),
+    id VARCHAR(255) NOT NULL,
+    publisher_id VARCHAR(255) NOT NULL DEFAULT '',
+    data JSONB NOT NULL CHECK (jsonb_typeof(data) = 'object'),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (kind, id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stored_requests_publisher ON stored_requests(publisher_id);
+
+COMMENT ON TABLE stored_requests IS 'OpenRTB request and impression templates referenced by ext.prebid.storedrequest.id';
+COMMENT ON COLUMN stored_requests.publisher_id IS 'Publisher allowed to reference the template; '''' = any publisher';
@@ -0,0 +1,25 @@
+-- =====================================================
+-- Add Publisher Country Allow/Deny Lists
+-- =====================================================
+-- Which countries a publisher's traffic is auctioned for,
+-- as comma-separated ISO 3166-1 alpha-3 codes matched
+-- against device.geo.country (falling back to
+-- user.geo.country):
+--
+--   allowed_countries  - only auction these countries;
+--                        requests without a country are
+--                        rejected too ('' = any country)
+--   blocked_countries  - never auction these countries
+--
+-- Rejected requests get an empty response with nbr 502
+-- before any bidder is called, and are counted in
+-- pbs_geo_rejections_total. Countries in BLOCKED_COUNTRIES
+-- are rejected for every publisher.
+-- =====================================================
+
+ALTER TABLE publishers
+ADD COLUMN allowed_countries TEXT NOT NULL DEFAULT '',
+ADD COLUMN blocked_countries TEXT NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN publishers.allowed_countries IS 'Comma-separated ISO 3166-1 alpha-3 countries auctions are limited to ('''' = any country)';
+COMMENT ON COLUMN publishers.blocked_countries IS 'Comma-separated ISO 3166-1 alpha-3 countries auctions are rejected for';
@@ -0,0 +1,20 @@
+-- =====================================================
+-- Add Advertiser to the Hourly Metrics Rollup
+-- =====================================================
+-- Wins and billed impressions are also split by the
+-- advertiser that ran, taken from the winning bid's
+-- buyer metadata: ext.prebid.meta.advertiserName or the
+-- bidder's advertiser ext keys, falling back to the first
+-- adomain. Request and bid rows keep advertiser = ''.
+--
+-- The advertiser joins the primary key so each
+-- instance writes one row per advertiser per hour.
+-- =====================================================
+
+ALTER TABLE metrics_hourly
+ADD COLUMN advertiser VARCHAR(128) NOT NULL DEFAULT '';
+
+ALTER TABLE metrics_hourly DROP CONSTRAINT metrics_hourly_pkey;
+ALTER TABLE metrics_hourly ADD PRIMARY KEY (hour, publisher_id, bidder, media_type, advertiser, instance_id);
+
+COMMENT ON COLUMN metrics_hourly.advertiser IS 'Advertiser name or domain of won bids ('''' on request and bid rows)';
@@ -0,0 +1,44 @@
+-- =====================================================
+-- Stored Creatives (House Ads)
+-- =====================================================
+-- stored_creatives holds each publisher's house ads:
+-- static banner markup or VAST served in place of an
+-- empty response when an auction ends without a valid
+-- bid. An impression gets the first active house ad of
+-- its media type (and, for banners, a size it allows),
+-- by priority then id.
+--
+-- max_impressions per cap_window_seconds limits how
+-- often one user (user.id, else device.ifa, else
+-- device.ip) sees the same house ad on an instance;
+-- 0 leaves it uncapped. Capped house ads are skipped
+-- for the next one.
+--
+-- Instances reload active house ads every
+-- HOUSE_ADS_INTERVAL_SECONDS. Fills are counted in
+-- pbs_fills_total by source (paid, house, none).
+-- =====================================================
+
+CREATE TABLE IF NOT EXISTS stored_creatives (
+    id VARCHAR(255) PRIMARY KEY,
+    publisher_id VARCHAR(255) NOT NULL,
+    media_type VARCHAR(20) NOT NULL CHECK (media_type IN ('banner', 'video')),
+    markup TEXT NOT NULL,
+    w INTEGER NOT NULL DEFAULT 0,
+    h INTEGER NOT NULL DEFAULT 0,
+    duration INTEGER NOT NULL DEFAULT 0,
+    adomain TEXT NOT NULL DEFAULT '',
+    priority INTEGER NOT NULL DEFAULT 0,
+    max_impressions INTEGER NOT NULL DEFAULT 0 CHECK (max_impressions >= 0),
+    cap_window_seconds INTEGER NOT NULL DEFAULT 0 CHECK (cap_window_seconds >= 0),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_stored_creatives_publisher ON stored_creatives(publisher_id) WHERE active;
+
+COMMENT ON TABLE stored_creatives IS 'Publisher house ads served when an auction has no valid bids';
+COMMENT ON COLUMN stored_creatives.markup IS 'Banner HTML or a VAST document';
+COMMENT ON COLUMN stored_creatives.priority IS 'Higher priority house ads are served first';
+COMMENT ON COLUMN stored_creatives.max_impressions IS 'Impressions per user per cap_window_seconds (0 = uncapped)';
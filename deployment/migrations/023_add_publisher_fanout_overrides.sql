@@ -0,0 +1,30 @@
+-- =====================================================
+-- Add Publisher Fan-out Overrides
+-- =====================================================
+-- Per-publisher overrides of the exchange-wide auction
+-- fan-out settings:
+--
+--   timeout_ms       - auction timeout for requests that
+--                      don't set tmax (0 = the -timeout flag;
+--                      capped at 10000 like tmax)
+--   max_bidders      - bidders called per auction
+--                      (0 = PBS_MAX_BIDDERS)
+--   allowed_bidders  - only call these comma-separated
+--                      bidder codes ('' = every enabled
+--                      bidder)
+--   blocked_bidders  - never call these bidder codes
+--
+-- Bidders left out are reported in ext.debug as excluded
+-- and never receive the request.
+-- =====================================================
+
+ALTER TABLE publishers
+ADD COLUMN timeout_ms INTEGER NOT NULL DEFAULT 0 CHECK (timeout_ms >= 0),
+ADD COLUMN max_bidders INTEGER NOT NULL DEFAULT 0 CHECK (max_bidders >= 0),
+ADD COLUMN allowed_bidders TEXT NOT NULL DEFAULT '',
+ADD COLUMN blocked_bidders TEXT NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN publishers.timeout_ms IS 'Auction timeout in milliseconds for requests without tmax (0 = exchange default)';
+COMMENT ON COLUMN publishers.max_bidders IS 'Maximum bidders called per auction (0 = exchange default)';
+COMMENT ON COLUMN publishers.allowed_bidders IS 'Comma-separated bidder codes auctions are limited to ('''' = every enabled bidder)';
+COMMENT ON COLUMN publishers.blocked_bidders IS 'Comma-separated bidder codes never called for the publisher';
@@ -0,0 +1,44 @@
+-- =====================================================
+-- Floor Rules
+-- =====================================================
+-- floor_rules holds each publisher's price floors. A rule
+-- matches an impression when every dimension it sets
+-- matches ('' = any):
+--
+--   media_type   - banner, video, native or audio
+--   size         - WxH, matched against the banner's
+--                  sizes or the video player size
+--   country      - ISO 3166-1 alpha-3 device.geo.country
+--                  (falling back to user.geo.country)
+--   device_type  - mobile, desktop or ctv
+--
+-- The most specific matching rule (most dimensions set)
+-- wins, the higher floor breaking ties. Its floor_cpm, in
+-- the exchange currency, is merged into imp.bidfloor
+-- before bidders are called when it is higher than the
+-- request's own floor, and bids below it are rejected.
+--
+-- Instances reload active rules every
+-- FLOOR_RULES_INTERVAL_SECONDS. Floors raised and bids
+-- rejected are counted per rule in
+-- pbs_floor_adjustments_total.
+-- =====================================================
+
+CREATE TABLE IF NOT EXISTS floor_rules (
+    id VARCHAR(255) PRIMARY KEY,
+    publisher_id VARCHAR(255) NOT NULL,
+    media_type VARCHAR(20) NOT NULL DEFAULT '' CHECK (media_type IN ('', 'banner', 'video', 'native', 'audio')),
+    size VARCHAR(20) NOT NULL DEFAULT '',
+    country CHAR(3) NOT NULL DEFAULT '',
+    device_type VARCHAR(20) NOT NULL DEFAULT '' CHECK (device_type IN ('', 'mobile', 'desktop', 'ctv')),
+    floor_cpm DECIMAL(10,4) NOT NULL CHECK (floor_cpm >= 0),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_floor_rules_publisher ON floor_rules(publisher_id) WHERE active;
+
+COMMENT ON TABLE floor_rules IS 'Publisher price floors by media type, size, country and device';
+COMMENT ON COLUMN floor_rules.size IS 'WxH the rule applies to ('''' = any size)';
+COMMENT ON COLUMN floor_rules.floor_cpm IS 'Floor CPM in the exchange currency';
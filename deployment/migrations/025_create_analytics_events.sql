@@ -0,0 +1,45 @@
+-- =====================================================
+-- Analytics Events
+-- =====================================================
+-- analytics_events receives the analytics pipeline's
+-- events when ANALYTICS_SINKS includes postgres:
+--
+--   auction - one per auction; status filled/unfilled,
+--             price and bidder of the highest winning bid
+--   bid     - one per bid returned; status won/lost,
+--             price in the exchange currency
+--   win     - win notices; billing - billing notices
+--   video   - video tracking events; status is the event
+--             name (start, firstQuartile, complete, ...)
+--
+-- Events are inserted in batches of ANALYTICS_BATCH_SIZE
+-- and retried as a batch, so rare duplicates are
+-- possible; deduplicate on (event_type, auction_id,
+-- bid_id, status) downstream. The table grows with
+-- traffic; prune or partition it by event_time.
+-- =====================================================
+
+CREATE TABLE IF NOT EXISTS analytics_events (
+    id BIGSERIAL PRIMARY KEY,
+    event_type VARCHAR(20) NOT NULL,
+    event_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    auction_id VARCHAR(255) NOT NULL DEFAULT '',
+    request_id VARCHAR(255) NOT NULL DEFAULT '',
+    publisher_id VARCHAR(255) NOT NULL DEFAULT '',
+    bidder VARCHAR(100) NOT NULL DEFAULT '',
+    bid_id VARCHAR(255) NOT NULL DEFAULT '',
+    imp_id VARCHAR(255) NOT NULL DEFAULT '',
+    media_type VARCHAR(20) NOT NULL DEFAULT '',
+    status VARCHAR(50) NOT NULL DEFAULT '',
+    price DECIMAL(12,6) NOT NULL DEFAULT 0,
+    currency CHAR(3) NOT NULL DEFAULT '',
+    fields JSONB,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_analytics_events_time ON analytics_events(event_time);
+CREATE INDEX IF NOT EXISTS idx_analytics_events_auction ON analytics_events(auction_id) WHERE auction_id <> '';
+CREATE INDEX IF NOT EXISTS idx_analytics_events_publisher_type ON analytics_events(publisher_id, event_type, event_time);
+
+COMMENT ON TABLE analytics_events IS 'Auction, bid, win, billing and video events from the analytics pipeline';
+COMMENT ON COLUMN analytics_events.fields IS 'Type-specific event details';
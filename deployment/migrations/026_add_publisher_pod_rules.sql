@@ -0,0 +1,26 @@
+-- =====================================================
+-- Add Publisher Pod Rules
+-- =====================================================
+-- Limits on a whole ad pod, on top of each slot's own
+-- floor:
+--
+--   pod_min_cpm           - least the filled slots must
+--                           total, in the exchange
+--                           currency, for the pod to be
+--                           served (0 = no pod floor)
+--   pod_max_bidder_share  - share of the pod's slots one
+--                           bidder can fill, between 0 and
+--                           1; always at least one slot
+--                           (0 = no cap)
+--
+-- A pod below pod_min_cpm is returned unfilled. A slot
+-- whose best bid is over the share cap goes to the next
+-- bid returned for it, or stays unfilled.
+-- =====================================================
+
+ALTER TABLE publishers
+ADD COLUMN pod_min_cpm DECIMAL(10,4) NOT NULL DEFAULT 0 CHECK (pod_min_cpm >= 0),
+ADD COLUMN pod_max_bidder_share DECIMAL(4,3) NOT NULL DEFAULT 0 CHECK (pod_max_bidder_share >= 0 AND pod_max_bidder_share <= 1);
+
+COMMENT ON COLUMN publishers.pod_min_cpm IS 'Minimum total CPM of an ad pod''s filled slots in the exchange currency (0 = no pod floor)';
+COMMENT ON COLUMN publishers.pod_max_bidder_share IS 'Maximum share of an ad pod''s slots one bidder can fill (0 = no cap)';
@@ -0,0 +1,44 @@
+-- =====================================================
+-- Hourly Advertiser Domain Frequency
+-- =====================================================
+-- How often each advertiser domain wins a publisher's
+-- auctions, so publishers can see which advertisers
+-- dominate their inventory and set advertiser separation
+-- or block rules. Each bid placed in an auction response
+-- counts one win for its first adomain, lower-cased with
+-- "www." dropped; house ads aren't counted.
+--
+-- Viewers are identified by a salted hash of the user ID
+-- or device advertising ID, so raw IDs are never stored.
+-- Sessions are the distinct viewers an instance saw the
+-- domain win for within the hour, and session_wins the
+-- wins among them; max_session_wins is the most any one
+-- viewer saw. Rows are keyed by instance like
+-- metrics_hourly and summed by
+--
+--   GET /api/v1/reports/advertisers?from=...&to=...
+--
+-- Rows older than ROLLUP_RETENTION_MONTHS are deleted by
+-- the rollup flush job.
+-- =====================================================
+
+CREATE TABLE IF NOT EXISTS advertiser_frequency_hourly (
+    hour TIMESTAMP WITH TIME ZONE NOT NULL,
+    publisher_id VARCHAR(255) NOT NULL,
+    advertiser_domain VARCHAR(128) NOT NULL,
+    instance_id VARCHAR(100) NOT NULL,
+    wins BIGINT NOT NULL DEFAULT 0,
+    sessions BIGINT NOT NULL DEFAULT 0,
+    session_wins BIGINT NOT NULL DEFAULT 0,
+    max_session_wins BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (hour, publisher_id, advertiser_domain, instance_id)
+);
+
+-- Reports are per publisher over a time range
+CREATE INDEX IF NOT EXISTS idx_advertiser_frequency_hourly_publisher_hour ON advertiser_frequency_hourly(publisher_id, hour);
+
+COMMENT ON TABLE advertiser_frequency_hourly IS 'Hourly winning advertiser domain counts per publisher, one row per writing instance';
+COMMENT ON COLUMN advertiser_frequency_hourly.sessions IS 'Distinct viewers the domain won for in the hour on this instance';
+COMMENT ON COLUMN advertiser_frequency_hourly.session_wins IS 'Wins for requests that identified a viewer';
+COMMENT ON COLUMN advertiser_frequency_hourly.max_session_wins IS 'Most wins for the domain by a single viewer in the hour';
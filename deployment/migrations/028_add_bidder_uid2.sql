@@ -0,0 +1,16 @@
+-- =====================================================
+-- Add Per-Bidder UID2 Passthrough
+-- =====================================================
+-- UID2 tokens (user.eids source uidapi.com) may only go
+-- to participants that signed the UID2 terms. Bidders
+-- without uid2_enabled get requests with the UID2 EID
+-- removed; every other ID is forwarded as before.
+--
+--   false - UID2 tokens stripped (default)
+--   true  - UID2 tokens forwarded
+-- =====================================================
+
+ALTER TABLE bidders
+ADD COLUMN uid2_enabled BOOLEAN NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN bidders.uid2_enabled IS 'Whether UID2 tokens (user.eids source uidapi.com) are forwarded to this bidder';
@@ -0,0 +1,22 @@
+-- =====================================================
+-- Add Publisher Required Ad Language
+-- =====================================================
+-- The language every bid must be in for this publisher,
+-- as a lowercase ISO 639-1 code (e.g. fr), whatever the
+-- request's content or device language:
+--
+--   ''  - use the request's language under
+--         language_filter (see migration 017)
+--   fr  - reject bids whose bid.language isn't fr, even
+--         on requests without a language
+--
+-- Unlabeled bids are rejected unless language_filter is
+-- match_or_unlabeled. Bids are counted in
+-- pbs_bids_by_language_total.
+-- =====================================================
+
+ALTER TABLE publishers
+ADD COLUMN required_language VARCHAR(2) NOT NULL DEFAULT ''
+    CHECK (required_language = '' OR required_language ~ '^[a-z]{2}$');
+
+COMMENT ON COLUMN publishers.required_language IS 'ISO 639-1 language every bid must be in ('''' = the request language under language_filter)';
@@ -15,9 +15,14 @@ import (
 	"github.com/thenexusengine/tne_springwire/pkg/logger"
 )
 
-// maxResponseSize limits bidder response size to prevent OOM attacks
+// maxResponseSize is the default bidder response size limit, preventing OOM
+// attacks and multi-megabyte responses stalling the fan-out
 const maxResponseSize = 1024 * 1024 // 1MB
 
+// ErrResponseTooLarge is returned when a bidder response exceeds the
+// client's size limit
+var ErrResponseTooLarge = errors.New("response too large")
+
 // Adapter defines the interface for bidder adapters
 type Adapter interface {
 	// MakeRequests builds HTTP requests for the bidder
@@ -115,6 +120,8 @@ type BidderInfo struct {
 	Endpoint                string
 	ExtraInfo               string
 	DemandType              DemandType // platform (obfuscated) or publisher (transparent)
+	USPrivacyUnsupported    bool       // Bidder can't honor US Privacy opt-outs; filtered when the user opts out
+	GPPUnsupported          bool       // Bidder reads us_privacy but not GPP; sent the equivalent us_privacy string
 }
 
 // MaintainerInfo contains maintainer info
@@ -171,7 +178,8 @@ type HTTPClient interface {
 
 // DefaultHTTPClient implements HTTPClient
 type DefaultHTTPClient struct {
-	client *http.Client
+	client           *http.Client
+	maxResponseBytes int
 }
 
 // NewHTTPClient creates a new HTTP client with connection pooling
@@ -210,7 +218,18 @@ func NewHTTPClient(timeout time.Duration) *DefaultHTTPClient {
 			Timeout:   timeout,
 			Transport: transport,
 		},
+		maxResponseBytes: maxResponseSize,
+	}
+}
+
+// SetMaxResponseBytes sets the largest response body the client reads before
+// rejecting the response with ErrResponseTooLarge (0 or less restores the
+// 1MB default). Call it before the client is shared.
+func (c *DefaultHTTPClient) SetMaxResponseBytes(n int) {
+	if n <= 0 {
+		n = maxResponseSize
 	}
+	c.maxResponseBytes = n
 }
 
 // Do executes an HTTP request with proper timeout handling
@@ -250,6 +269,13 @@ func (c *DefaultHTTPClient) Do(ctx context.Context, req *RequestData, timeout ti
 		return nil, err
 	}
 
+	// Reject declared oversized bodies without reading them
+	limit := c.maxResponseBytes
+	if resp.ContentLength > int64(limit) {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%w: content length %d exceeds %d bytes", ErrResponseTooLarge, resp.ContentLength, limit)
+	}
+
 	// P1-NEW-1: Use single goroutine for entire read with proper cleanup on cancellation
 	// This prevents goroutine leaks that occurred when spawning per-read goroutines
 	type readResult struct {
@@ -262,7 +288,7 @@ func (c *DefaultHTTPClient) Do(ctx context.Context, req *RequestData, timeout ti
 	go func() {
 		defer resp.Body.Close()
 		// Read with size limit to prevent OOM from malicious bidders
-		limitedReader := io.LimitReader(resp.Body, maxResponseSize+1) // +1 to detect overflow
+		limitedReader := io.LimitReader(resp.Body, int64(limit)+1) // +1 to detect overflow
 		data, err := io.ReadAll(limitedReader)
 		readCh <- readResult{data: data, err: err}
 	}()
@@ -288,8 +314,8 @@ func (c *DefaultHTTPClient) Do(ctx context.Context, req *RequestData, timeout ti
 		if result.err != nil {
 			return nil, result.err
 		}
-		if len(result.data) > maxResponseSize {
-			return nil, fmt.Errorf("response too large: exceeded %d bytes", maxResponseSize)
+		if len(result.data) > limit {
+			return nil, fmt.Errorf("%w: exceeded %d bytes", ErrResponseTooLarge, limit)
 		}
 		return &ResponseData{
 			StatusCode: resp.StatusCode,
@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -161,6 +162,39 @@ func TestHTTPClientDo_ResponseTooLarge(t *testing.T) {
 	}
 }
 
+func TestHTTPClientDo_MaxResponseBytes(t *testing.T) {
+	body := strings.Repeat("x", 2048)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("chunked") != "" {
+			// No Content-Length, so the limit applies while reading
+			w.(http.Flusher).Flush()
+		} else {
+			w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		}
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(5 * time.Second)
+	client.SetMaxResponseBytes(1024)
+
+	for _, uri := range []string{server.URL, server.URL + "?chunked=1"} {
+		_, err := client.Do(context.Background(), &RequestData{Method: "GET", URI: uri}, 0)
+		if !errors.Is(err, ErrResponseTooLarge) {
+			t.Errorf("%s: expected ErrResponseTooLarge, got %v", uri, err)
+		}
+	}
+
+	client.SetMaxResponseBytes(len(body))
+	resp, err := client.Do(context.Background(), &RequestData{Method: "GET", URI: server.URL}, 0)
+	if err != nil {
+		t.Fatalf("expected response at the limit to be accepted, got %v", err)
+	}
+	if len(resp.Body) != len(body) {
+		t.Errorf("expected %d bytes, got %d", len(body), len(resp.Body))
+	}
+}
+
 func TestHTTPClientDo_ServerError(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -0,0 +1,54 @@
+package ortb
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+)
+
+// LoadFile reads a JSON array of bidder definitions, filling in the
+// endpoint method, timeout, protocol version and status when omitted
+func LoadFile(path string) ([]*BidderConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bidder definitions: %w", err)
+	}
+
+	var configs []*BidderConfig
+	if err := json.Unmarshal(data, &configs); err != nil {
+		return nil, fmt.Errorf("failed to parse bidder definitions %s: %w", path, err)
+	}
+
+	for i, config := range configs {
+		if config == nil || config.BidderCode == "" || config.Endpoint.URL == "" {
+			return nil, fmt.Errorf("bidder definition %d needs a bidder_code and endpoint.url", i)
+		}
+		if config.Endpoint.Method == "" {
+			config.Endpoint.Method = http.MethodPost
+		}
+		if config.Endpoint.TimeoutMS <= 0 {
+			config.Endpoint.TimeoutMS = 500
+		}
+		if config.Endpoint.ProtocolVersion == "" {
+			config.Endpoint.ProtocolVersion = "2.6"
+		}
+		if config.Status == "" {
+			config.Status = "active"
+		}
+	}
+	return configs, nil
+}
+
+// Register adds a generic adapter for each definition to registry
+func Register(registry *adapters.Registry, configs []*BidderConfig) error {
+	for _, config := range configs {
+		adapter := New(config)
+		if err := registry.Register(config.BidderCode, adapter, adapter.Info()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
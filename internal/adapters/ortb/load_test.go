@@ -0,0 +1,67 @@
+package ortb
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+)
+
+func TestLoadFile_Register(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bidders.json")
+	definitions := `[
+		{"bidder_code": "sim_a", "endpoint": {"url": "http://bidder-a:9000/openrtb2"}, "capabilities": {"media_types": ["banner", "video"], "site_enabled": true}},
+		{"bidder_code": "sim_b", "endpoint": {"url": "http://bidder-b:9000/openrtb2", "timeout_ms": 200}, "status": "testing"}
+	]`
+	if err := os.WriteFile(path, []byte(definitions), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	configs, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if len(configs) != 2 {
+		t.Fatalf("expected 2 definitions, got %d", len(configs))
+	}
+	a := configs[0]
+	if a.Endpoint.Method != "POST" || a.Endpoint.TimeoutMS != 500 || a.Endpoint.ProtocolVersion != "2.6" || a.Status != "active" {
+		t.Errorf("expected defaults to be filled in, got %+v", a)
+	}
+	if configs[1].Endpoint.TimeoutMS != 200 || configs[1].Status != "testing" {
+		t.Errorf("expected explicit values to be kept, got %+v", configs[1])
+	}
+
+	registry := adapters.NewRegistry()
+	if err := Register(registry, configs); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if got := registry.ListEnabledBidders(); len(got) != 2 {
+		t.Errorf("expected both bidders enabled, got %v", got)
+	}
+	if err := Register(registry, configs[:1]); err == nil {
+		t.Error("expected an error registering a bidder twice")
+	}
+}
+
+func TestLoadFile_Errors(t *testing.T) {
+	dir := t.TempDir()
+	tests := map[string]string{
+		"invalid.json":     `{"bidder_code": "sim_a"}`,
+		"missing_url.json": `[{"bidder_code": "sim_a"}]`,
+	}
+	for name, content := range tests {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadFile(path); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	if _, err := LoadFile(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
@@ -465,6 +465,12 @@ func (a *GenericAdapter) Info() adapters.BidderInfo {
 		info.GVLVendorID = *config.GVLVendorID
 	}
 
+	// Bidders that don't read GPP get its US sections as us_privacy
+	info.GPPUnsupported = !config.Capabilities.SupportsGPP
+
+	// Bidders that can't honor US Privacy opt-outs are skipped for opted-out users
+	info.USPrivacyUnsupported = !config.Capabilities.SupportsCCPA
+
 	// Build capabilities
 	info.Capabilities = &adapters.CapabilitiesInfo{}
 
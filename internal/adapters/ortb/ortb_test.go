@@ -550,6 +550,22 @@ func TestGenericAdapter_Info(t *testing.T) {
 	if info.Endpoint != config.Endpoint.URL {
 		t.Error("expected endpoint URL")
 	}
+	if !info.GPPUnsupported {
+		t.Error("expected a bidder without supports_gpp to need us_privacy")
+	}
+
+	config.Capabilities.SupportsGPP = true
+	if New(config).Info().GPPUnsupported {
+		t.Error("expected supports_gpp to send GPP alone")
+	}
+
+	if !info.USPrivacyUnsupported {
+		t.Error("expected a bidder without supports_ccpa skipped on opt-outs")
+	}
+	config.Capabilities.SupportsCCPA = true
+	if New(config).Info().USPrivacyUnsupported {
+		t.Error("expected supports_ccpa to keep the bidder on opt-outs")
+	}
 }
 
 func TestGenericAdapter_Info_Capabilities(t *testing.T) {
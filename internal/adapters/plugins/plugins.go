@@ -0,0 +1,119 @@
+// Package plugins loads private bidder adapters from Go plugins (.so files
+// built with -buildmode=plugin) at startup, so proprietary partner
+// integrations can ship separately from the open codebase.
+//
+// A plugin is a main package that exports
+//
+//	func Adapters() map[string]adapters.AdapterWithInfo
+//
+// returning its adapters by bidder code. Go only loads plugins built with the
+// same toolchain, build flags and package versions as the server, so build
+// them from a checkout of this repository at the server's commit:
+//
+//	go build -buildmode=plugin -o partner.so ./path/to/partner
+//
+// Plugins need cgo; a server built with CGO_ENABLED=0 can't load them.
+package plugins
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"plugin"
+	"sort"
+	"strings"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+)
+
+// Symbol is the function every plugin exports
+const Symbol = "Adapters"
+
+// Factory is the type of Symbol
+type Factory = func() map[string]adapters.AdapterWithInfo
+
+// lookuper is the part of *plugin.Plugin used to find Symbol
+type lookuper interface {
+	Lookup(name string) (plugin.Symbol, error)
+}
+
+// open loads a plugin; replaced in tests, which can't build plugins
+var open = func(path string) (lookuper, error) {
+	return plugin.Open(path)
+}
+
+// Paths expands a comma-separated list of plugin files and directories into
+// plugin files. Directories contribute their *.so files in name order.
+func Paths(spec string) ([]string, error) {
+	var paths []string
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		info, err := os.Stat(entry)
+		if err != nil {
+			return nil, fmt.Errorf("adapter plugin %s: %w", entry, err)
+		}
+		if !info.IsDir() {
+			paths = append(paths, entry)
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(entry, "*.so"))
+		if err != nil {
+			return nil, fmt.Errorf("adapter plugin directory %s: %w", entry, err)
+		}
+		sort.Strings(matches)
+		paths = append(paths, matches...)
+	}
+	return paths, nil
+}
+
+// Load opens each plugin and registers its adapters in registry, returning
+// the bidder codes registered. Any plugin that fails to load or clashes with
+// a registered bidder code fails the whole load.
+func Load(registry *adapters.Registry, paths []string) ([]string, error) {
+	var codes []string
+	for _, path := range paths {
+		loaded, err := loadPlugin(registry, path)
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, loaded...)
+	}
+	return codes, nil
+}
+
+// loadPlugin registers the adapters of one plugin
+func loadPlugin(registry *adapters.Registry, path string) ([]string, error) {
+	p, err := open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open adapter plugin %s: %w", path, err)
+	}
+	sym, err := p.Lookup(Symbol)
+	if err != nil {
+		return nil, fmt.Errorf("adapter plugin %s does not export %s: %w", path, Symbol, err)
+	}
+	factory, ok := sym.(Factory)
+	if !ok {
+		return nil, fmt.Errorf("adapter plugin %s: %s is %T, want %T", path, Symbol, sym, Factory(nil))
+	}
+
+	byCode := factory()
+	codes := make([]string, 0, len(byCode))
+	for code, awi := range byCode {
+		if code == "" || awi.Adapter == nil {
+			return nil, fmt.Errorf("adapter plugin %s: every adapter needs a bidder code and an implementation", path)
+		}
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	for _, code := range codes {
+		awi := byCode[code]
+		if err := registry.Register(code, awi.Adapter, awi.Info); err != nil {
+			return nil, fmt.Errorf("adapter plugin %s: %w", path, err)
+		}
+	}
+	return codes, nil
+}
@@ -0,0 +1,138 @@
+package plugins
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"plugin"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+type stubAdapter struct{}
+
+func (stubAdapter) MakeRequests(*openrtb.BidRequest, *adapters.ExtraRequestInfo) ([]*adapters.RequestData, []error) {
+	return nil, nil
+}
+
+func (stubAdapter) MakeBids(*openrtb.BidRequest, *adapters.ResponseData) (*adapters.BidderResponse, []error) {
+	return nil, nil
+}
+
+// fakePlugin exports symbols by name
+type fakePlugin map[string]plugin.Symbol
+
+func (p fakePlugin) Lookup(name string) (plugin.Symbol, error) {
+	sym, ok := p[name]
+	if !ok {
+		return nil, errors.New("symbol not found")
+	}
+	return sym, nil
+}
+
+// withPlugins serves fake plugins by path for the rest of the test
+func withPlugins(t *testing.T, byPath map[string]fakePlugin) {
+	t.Helper()
+	orig := open
+	open = func(path string) (lookuper, error) {
+		p, ok := byPath[path]
+		if !ok {
+			return nil, errors.New("no such plugin")
+		}
+		return p, nil
+	}
+	t.Cleanup(func() { open = orig })
+}
+
+func adaptersFactory(codes ...string) Factory {
+	return func() map[string]adapters.AdapterWithInfo {
+		byCode := make(map[string]adapters.AdapterWithInfo, len(codes))
+		for _, code := range codes {
+			byCode[code] = adapters.AdapterWithInfo{Adapter: stubAdapter{}, Info: adapters.BidderInfo{Enabled: true}}
+		}
+		return byCode
+	}
+}
+
+func TestLoad_RegistersAdapters(t *testing.T) {
+	withPlugins(t, map[string]fakePlugin{
+		"a.so": {Symbol: adaptersFactory("partner_b", "partner_a")},
+		"b.so": {Symbol: adaptersFactory("partner_c")},
+	})
+	registry := adapters.NewRegistry()
+
+	codes, err := Load(registry, []string{"a.so", "b.so"})
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if want := []string{"partner_a", "partner_b", "partner_c"}; !reflect.DeepEqual(codes, want) {
+		t.Errorf("expected %v registered, got %v", want, codes)
+	}
+	if awi, ok := registry.Get("partner_a"); !ok || !awi.Info.Enabled {
+		t.Errorf("expected partner_a in the registry, got %+v", awi)
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	withPlugins(t, map[string]fakePlugin{
+		"missing.so": {},
+		"wrong.so":   {Symbol: func() []adapters.Adapter { return nil }},
+		"empty.so": {Symbol: Factory(func() map[string]adapters.AdapterWithInfo {
+			return map[string]adapters.AdapterWithInfo{"partner_a": {}}
+		})},
+		"clash.so": {Symbol: adaptersFactory("appnexus")},
+	})
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"absent.so", "failed to open"},
+		{"missing.so", "does not export Adapters"},
+		{"wrong.so", "want func() map[string]adapters.AdapterWithInfo"},
+		{"empty.so", "needs a bidder code and an implementation"},
+		{"clash.so", "already registered"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			registry := adapters.NewRegistry()
+			if err := registry.Register("appnexus", stubAdapter{}, adapters.BidderInfo{}); err != nil {
+				t.Fatal(err)
+			}
+			_, err := Load(registry, []string{tt.path})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestPaths(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.so", "a.so", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	file := filepath.Join(t.TempDir(), "partner.so")
+	if err := os.WriteFile(file, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	paths, err := Paths(" " + file + ", " + dir + ",")
+	if err != nil {
+		t.Fatalf("Paths failed: %v", err)
+	}
+	want := []string{file, filepath.Join(dir, "a.so"), filepath.Join(dir, "b.so")}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("expected %v, got %v", want, paths)
+	}
+
+	if _, err := Paths(filepath.Join(dir, "absent.so")); err == nil {
+		t.Error("expected an error for a missing plugin")
+	}
+}
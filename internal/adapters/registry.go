@@ -1,7 +1,10 @@
 package adapters
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"sort"
 	"sync"
 )
 
@@ -81,6 +84,27 @@ func (r *Registry) ListEnabledBidders() []string {
 	return bidders
 }
 
+// Hash fingerprints the registered bidder set (codes, enabled state,
+// endpoints and demand types) so instances can be compared for the exact
+// adapters they run
+func (r *Registry) Hash() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	codes := make([]string, 0, len(r.adapters))
+	for code := range r.adapters {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	h := sha256.New()
+	for _, code := range codes {
+		info := r.adapters[code].Info
+		fmt.Fprintf(h, "%s|%t|%s|%s\n", code, info.Enabled, info.Endpoint, info.DemandType)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
 // DefaultRegistry is the global adapter registry
 var DefaultRegistry = NewRegistry()
 
@@ -358,6 +358,34 @@ func TestRegistry_ConcurrentAccess(t *testing.T) {
 
 // Benchmark tests
 
+func TestRegistry_Hash(t *testing.T) {
+	a := NewRegistry()
+	a.Register("appnexus", &mockAdapter{}, BidderInfo{Enabled: true, Endpoint: "https://a.example.com"})
+	a.Register("rubicon", &mockAdapter{}, BidderInfo{Enabled: true, Endpoint: "https://r.example.com"})
+
+	b := NewRegistry()
+	b.Register("rubicon", &mockAdapter{}, BidderInfo{Enabled: true, Endpoint: "https://r.example.com"})
+	b.Register("appnexus", &mockAdapter{}, BidderInfo{Enabled: true, Endpoint: "https://a.example.com"})
+
+	if a.Hash() != b.Hash() {
+		t.Error("expected hash to be independent of registration order")
+	}
+	if len(a.Hash()) != 16 {
+		t.Errorf("expected 16 hex chars, got %q", a.Hash())
+	}
+
+	c := NewRegistry()
+	c.Register("appnexus", &mockAdapter{}, BidderInfo{Enabled: true, Endpoint: "https://a.example.com"})
+	c.Register("rubicon", &mockAdapter{}, BidderInfo{Enabled: false, Endpoint: "https://r.example.com"})
+	if a.Hash() == c.Hash() {
+		t.Error("expected disabling a bidder to change the hash")
+	}
+
+	if NewRegistry().Hash() == a.Hash() {
+		t.Error("expected empty registry to hash differently")
+	}
+}
+
 func BenchmarkRegistry_Get(b *testing.B) {
 	r := NewRegistry()
 	for i := 0; i < 100; i++ {
@@ -0,0 +1,55 @@
+// Package adminui serves a small web UI for operators at /admin/ui: bidder
+// health and circuit breaker states, the publisher list and recent errors.
+// The pages are static and embedded in the binary; they read everything from
+// the admin JSON APIs with the operator's API key, so small deployments get
+// observability without running Grafana.
+package adminui
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// Prefix is the path the UI is served under
+const Prefix = "/admin/ui/"
+
+//go:embed static
+var static embed.FS
+
+// Paths lists the UI's static files as request paths, including the bare
+// prefix for the index page. They hold no data, so they can be served
+// without an API key.
+func Paths() []string {
+	paths := []string{"/admin/ui", Prefix}
+	entries, _ := fs.ReadDir(static, "static")
+	for _, e := range entries {
+		if !e.IsDir() && e.Name() != "index.html" {
+			paths = append(paths, Prefix+e.Name())
+		}
+	}
+	return paths
+}
+
+// Handler serves the UI's static files under Prefix and redirects the bare
+// /admin/ui to it
+func Handler() http.Handler {
+	sub, err := fs.Sub(static, "static")
+	if err != nil {
+		panic(err) // The static directory is embedded at build time
+	}
+	files := http.StripPrefix(Prefix, http.FileServer(http.FS(sub)))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if r.URL.Path == "/admin/ui" {
+			http.Redirect(w, r, Prefix, http.StatusMovedPermanently)
+			return
+		}
+		files.ServeHTTP(w, r)
+	})
+}
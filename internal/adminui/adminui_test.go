@@ -0,0 +1,63 @@
+package adminui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	h := Handler()
+	tests := []struct {
+		path        string
+		contentType string
+		contains    string
+	}{
+		{"/admin/ui/", "text/html", `<script src="app.js"></script>`},
+		{"/admin/ui/app.js", "javascript", "/admin/circuit-breaker"},
+		{"/admin/ui/app.css", "text/css", ".state-open"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d", tt.path, w.Code)
+		}
+		if ct := w.Header().Get("Content-Type"); !strings.Contains(ct, tt.contentType) {
+			t.Errorf("%s: expected %s content type, got %q", tt.path, tt.contentType, ct)
+		}
+		if !strings.Contains(w.Body.String(), tt.contains) {
+			t.Errorf("%s: expected body to contain %q", tt.path, tt.contains)
+		}
+	}
+}
+
+func TestHandler_RedirectsAndMethods(t *testing.T) {
+	h := Handler()
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/ui", nil))
+	if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != Prefix {
+		t.Errorf("expected a redirect to %s, got %d %q", Prefix, w.Code, w.Header().Get("Location"))
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/ui/", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405 for POST, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/ui/missing.js", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown file, got %d", w.Code)
+	}
+}
+
+func TestPaths(t *testing.T) {
+	paths := strings.Join(Paths(), ",")
+	if paths != "/admin/ui,/admin/ui/,/admin/ui/app.css,/admin/ui/app.js" {
+		t.Errorf("unexpected paths: %s", paths)
+	}
+}
@@ -0,0 +1,148 @@
+// Catalyst admin UI: polls the admin JSON APIs and renders them. Values are
+// always inserted as text, never as HTML.
+(function () {
+    'use strict';
+
+    var REFRESH_MS = 10000;
+    var KEY_STORAGE = 'catalyst-admin-api-key';
+
+    var statusEl = document.getElementById('status');
+    var keyInput = document.getElementById('api-key');
+    var timer = null;
+
+    keyInput.value = sessionStorage.getItem(KEY_STORAGE) || '';
+
+    document.getElementById('key-form').addEventListener('submit', function (e) {
+        e.preventDefault();
+        sessionStorage.setItem(KEY_STORAGE, keyInput.value);
+        refresh();
+    });
+
+    function getJSON(path) {
+        var headers = {};
+        var key = sessionStorage.getItem(KEY_STORAGE);
+        if (key) {
+            headers['X-API-Key'] = key;
+        }
+        return fetch(path, { headers: headers, credentials: 'same-origin' }).then(function (resp) {
+            if (resp.status === 401 || resp.status === 403) {
+                throw new Error('Unauthorized: enter an admin API key');
+            }
+            if (!resp.ok) {
+                throw new Error(path + ' returned ' + resp.status);
+            }
+            return resp.json();
+        });
+    }
+
+    function setText(id, value) {
+        document.getElementById(id).textContent = value;
+    }
+
+    function cell(row, text, className) {
+        var td = document.createElement('td');
+        td.textContent = text;
+        if (className) {
+            td.className = className;
+        }
+        row.appendChild(td);
+        return td;
+    }
+
+    function fillTable(id, items, columns, render) {
+        var body = document.getElementById(id);
+        body.textContent = '';
+        if (items.length === 0) {
+            var row = document.createElement('tr');
+            cell(row, 'None', 'empty').colSpan = columns;
+            body.appendChild(row);
+            return;
+        }
+        items.forEach(function (item) {
+            var row = document.createElement('tr');
+            render(row, item);
+            body.appendChild(row);
+        });
+    }
+
+    function formatUptime(seconds) {
+        var d = Math.floor(seconds / 86400);
+        var h = Math.floor((seconds % 86400) / 3600);
+        var m = Math.floor((seconds % 3600) / 60);
+        return (d ? d + 'd ' : '') + h + 'h ' + m + 'm';
+    }
+
+    function renderMetrics(m) {
+        setText('total-auctions', m.total_auctions);
+        setText('failed-auctions', m.failed_auctions);
+        setText('avg-duration', Number(m.average_duration || 0).toFixed(1));
+        setText('uptime', formatUptime(m.uptime_seconds || 0));
+    }
+
+    function renderBreakers(cb) {
+        setText('idr-state', (cb.idr && (cb.idr.state || cb.idr.status)) || 'unknown');
+
+        var bidders = Object.keys(cb.bidders || {}).sort().map(function (code) {
+            var s = cb.bidders[code];
+            s.code = code;
+            return s;
+        });
+        fillTable('bidders', bidders, 7, function (row, s) {
+            var rate = s.total_requests ? (100 * s.total_failures / s.total_requests).toFixed(1) + '%' : '-';
+            cell(row, s.code);
+            cell(row, s.state, 'state-' + s.state);
+            cell(row, s.total_requests);
+            cell(row, s.total_failures);
+            cell(row, rate);
+            cell(row, s.total_rejected);
+            cell(row, s.concurrent);
+        });
+    }
+
+    function renderPublishers(resp) {
+        setText('publisher-count', resp.total);
+        fillTable('publishers', resp.publishers || [], 2, function (row, p) {
+            cell(row, p.id);
+            cell(row, (p.domain_list || []).join(', '));
+        });
+    }
+
+    function renderErrors(resp) {
+        fillTable('errors', resp.entries || [], 4, function (row, e) {
+            var details = {};
+            Object.keys(e).forEach(function (k) {
+                if (k !== 'time' && k !== 'level' && k !== 'message' && k !== 'service' && k !== 'version' &&
+                    k !== 'git_sha' && k !== 'adapters_hash') {
+                    details[k] = e[k];
+                }
+            });
+            cell(row, e.time ? new Date(e.time).toLocaleString() : '-');
+            cell(row, e.level, 'level-' + e.level);
+            cell(row, e.message || '');
+            cell(row, JSON.stringify(details), 'details');
+        });
+    }
+
+    function refresh() {
+        clearTimeout(timer);
+        Promise.all([
+            getJSON('/admin/metrics').then(renderMetrics),
+            getJSON('/admin/circuit-breaker').then(renderBreakers),
+            getJSON('/admin/publishers?limit=100').then(renderPublishers).catch(function (err) {
+                // Publisher management needs Redis; show the rest without it
+                setText('publisher-count', 'unavailable (' + err.message + ')');
+            }),
+            getJSON('/admin/errors?limit=50').then(renderErrors)
+        ]).then(function () {
+            statusEl.className = 'status';
+            statusEl.textContent = 'Updated ' + new Date().toLocaleTimeString();
+        }).catch(function (err) {
+            statusEl.className = 'status error';
+            statusEl.textContent = err.message;
+        }).then(function () {
+            timer = setTimeout(refresh, REFRESH_MS);
+        });
+    }
+
+    refresh();
+})();
@@ -0,0 +1,203 @@
+package analytics
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Identifier obfuscation modes
+const (
+	IDModeOff     = "off"
+	IDModeHash    = "hash"    // keyed hash: one-way tokens
+	IDModeEncrypt = "encrypt" // deterministic encryption: tokens the key holder can reveal
+)
+
+// minIDKeyLen is the shortest accepted identifier key secret
+const minIDKeyLen = 32
+
+// Lengths of hash tokens and of encryption nonces
+const (
+	idHashBytes  = 16
+	idNonceBytes = 12
+)
+
+// DefaultIDFields are the event fields holding user and session identifiers
+var DefaultIDFields = []string{"session_id", "user_id", "device_id"}
+
+// IDConfig configures how user and session identifiers are obfuscated
+// before events reach sinks
+type IDConfig struct {
+	Mode   string   // off, hash or encrypt
+	Keys   string   // "id:secret" pairs, comma-separated
+	KeyID  string   // Key new tokens are made with ("" = the only key)
+	Fields []string // Event fields holding identifiers
+}
+
+// IDObfuscator replaces identifiers in events with tokens. Tokens are keyed
+// per publisher (tenant), so the same viewer gets unrelated tokens at
+// different publishers and data shared with one publisher can't be joined
+// with another's or back to raw identifiers. Tokens are prefixed with the
+// key ID: after a rotation, new tokens use the new key while tokens made
+// with retired keys can still be revealed as long as the key is configured.
+type IDObfuscator struct {
+	mode   string
+	keyID  string
+	keys   map[string][]byte
+	fields []string
+}
+
+// NewIDObfuscator creates an obfuscator; it returns nil when the mode is off
+func NewIDObfuscator(cfg IDConfig) (*IDObfuscator, error) {
+	switch cfg.Mode {
+	case "", IDModeOff:
+		return nil, nil
+	case IDModeHash, IDModeEncrypt:
+	default:
+		return nil, fmt.Errorf("identifier mode must be %q, %q or %q, got %q", IDModeOff, IDModeHash, IDModeEncrypt, cfg.Mode)
+	}
+
+	keys := make(map[string][]byte)
+	for _, entry := range strings.Split(cfg.Keys, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, secret, ok := strings.Cut(entry, ":")
+		id = strings.TrimSpace(id)
+		if !ok || id == "" || strings.Contains(id, ".") {
+			return nil, fmt.Errorf("invalid identifier key: expected id:secret with no '.' in the id")
+		}
+		if len(secret) < minIDKeyLen {
+			return nil, fmt.Errorf("secret for identifier key %q must be at least %d characters", id, minIDKeyLen)
+		}
+		keys[id] = []byte(secret)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("identifier mode %q requires at least one key", cfg.Mode)
+	}
+
+	keyID := cfg.KeyID
+	if keyID == "" {
+		if len(keys) > 1 {
+			return nil, fmt.Errorf("a key ID is required with more than one identifier key")
+		}
+		for id := range keys {
+			keyID = id
+		}
+	}
+	if _, ok := keys[keyID]; !ok {
+		return nil, fmt.Errorf("identifier key %q is not configured", keyID)
+	}
+
+	fields := cfg.Fields
+	if len(fields) == 0 {
+		fields = DefaultIDFields
+	}
+	return &IDObfuscator{mode: cfg.Mode, keyID: keyID, keys: keys, fields: fields}, nil
+}
+
+// Apply replaces the identifier fields of event with tokens for its
+// publisher. The event's Fields map is copied, not modified, since callers
+// may share it.
+func (o *IDObfuscator) Apply(event *Event) {
+	if o == nil || len(event.Fields) == 0 {
+		return
+	}
+	var fields map[string]string
+	for _, name := range o.fields {
+		value, ok := event.Fields[name]
+		if !ok || value == "" {
+			continue
+		}
+		if fields == nil {
+			fields = make(map[string]string, len(event.Fields))
+			for k, v := range event.Fields {
+				fields[k] = v
+			}
+		}
+		fields[name] = o.Token(event.PublisherID, value)
+	}
+	if fields != nil {
+		event.Fields = fields
+	}
+}
+
+// Token returns the token for id at publisherID, made with the current key.
+// The same identifier always gets the same token at a publisher until the
+// key rotates.
+func (o *IDObfuscator) Token(publisherID, id string) string {
+	secret := o.keys[o.keyID]
+	sum := tenantMAC(secret, publisherID, id)
+	if o.mode == IDModeHash {
+		return o.keyID + "." + base64.RawURLEncoding.EncodeToString(sum[:idHashBytes])
+	}
+
+	// The nonce is derived from the identifier (as in SIV), so encryption
+	// is deterministic and tokens still join within a publisher
+	nonce := sum[:idNonceBytes]
+	aead, err := tenantAEAD(secret, publisherID)
+	if err != nil {
+		// AES-256 keys are always valid; never ship the raw identifier
+		return o.keyID + "."
+	}
+	sealed := aead.Seal(append([]byte(nil), nonce...), nonce, []byte(id), []byte(publisherID))
+	return o.keyID + "." + base64.RawURLEncoding.EncodeToString(sealed)
+}
+
+// Reveal decrypts a token made in encrypt mode with any configured key,
+// e.g. to honor a data subject request
+func (o *IDObfuscator) Reveal(publisherID, token string) (string, error) {
+	if o == nil || o.mode != IDModeEncrypt {
+		return "", errors.New("identifiers are not encrypted")
+	}
+	keyID, encoded, ok := strings.Cut(token, ".")
+	secret, known := o.keys[keyID]
+	if !ok || !known {
+		return "", fmt.Errorf("token key %q is not configured", keyID)
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < idNonceBytes {
+		return "", errors.New("malformed token")
+	}
+	aead, err := tenantAEAD(secret, publisherID)
+	if err != nil {
+		return "", err
+	}
+	id, err := aead.Open(nil, sealed[:idNonceBytes], sealed[idNonceBytes:], []byte(publisherID))
+	if err != nil {
+		return "", errors.New("token was not made for this publisher")
+	}
+	return string(id), nil
+}
+
+// tenantKey derives the publisher's key for purpose from secret, which is
+// the per-tenant salt
+func tenantKey(secret []byte, purpose, publisherID string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(purpose))
+	mac.Write([]byte{0})
+	mac.Write([]byte(publisherID))
+	return mac.Sum(nil)
+}
+
+// tenantMAC keys id with the publisher's hashing key
+func tenantMAC(secret []byte, publisherID, id string) []byte {
+	mac := hmac.New(sha256.New, tenantKey(secret, "hash", publisherID))
+	mac.Write([]byte(id))
+	return mac.Sum(nil)
+}
+
+// tenantAEAD is AES-256-GCM under the publisher's encryption key
+func tenantAEAD(secret []byte, publisherID string) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(tenantKey(secret, "encrypt", publisherID))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
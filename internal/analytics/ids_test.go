@@ -0,0 +1,130 @@
+package analytics
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testIDKey     = "k1:0123456789abcdef0123456789abcdef"
+	testIDNextKey = "k2:fedcba9876543210fedcba9876543210"
+)
+
+func TestIDObfuscator_Hash(t *testing.T) {
+	o, err := NewIDObfuscator(IDConfig{Mode: IDModeHash, Keys: testIDKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token := o.Token("pub-1", "session-42")
+	if !strings.HasPrefix(token, "k1.") || strings.Contains(token, "session-42") {
+		t.Fatalf("expected a k1 token hiding the identifier, got %q", token)
+	}
+	if o.Token("pub-1", "session-42") != token {
+		t.Error("expected the same token for the same identifier at a publisher")
+	}
+	if o.Token("pub-2", "session-42") == token {
+		t.Error("expected tokens not to join across publishers")
+	}
+	if _, err := o.Reveal("pub-1", token); err == nil {
+		t.Error("expected hash tokens not to be revealable")
+	}
+}
+
+func TestIDObfuscator_EncryptAndRotate(t *testing.T) {
+	old, err := NewIDObfuscator(IDConfig{Mode: IDModeEncrypt, Keys: testIDKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldToken := old.Token("pub-1", "session-42")
+	if old.Token("pub-1", "session-42") != oldToken {
+		t.Error("expected deterministic encryption")
+	}
+
+	// After rotating to k2, new tokens use it and k1 tokens still reveal
+	o, err := NewIDObfuscator(IDConfig{Mode: IDModeEncrypt, Keys: testIDKey + "," + testIDNextKey, KeyID: "k2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := o.Token("pub-1", "session-42")
+	if !strings.HasPrefix(token, "k2.") || token == oldToken {
+		t.Fatalf("expected a k2 token, got %q", token)
+	}
+	for _, tok := range []string{token, oldToken} {
+		if id, err := o.Reveal("pub-1", tok); err != nil || id != "session-42" {
+			t.Errorf("expected %q revealed, got %q (%v)", tok, id, err)
+		}
+	}
+	if _, err := o.Reveal("pub-2", token); err == nil {
+		t.Error("expected another publisher's token not to reveal")
+	}
+	if _, err := o.Reveal("pub-1", "k9."+strings.TrimPrefix(token, "k2.")); err == nil {
+		t.Error("expected a token from an unknown key rejected")
+	}
+}
+
+func TestIDObfuscator_Apply(t *testing.T) {
+	o, err := NewIDObfuscator(IDConfig{Mode: IDModeHash, Keys: testIDKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := map[string]string{"session_id": "session-42", "content_id": "episode-7"}
+	event := Event{Type: EventVideo, PublisherID: "pub-1", Fields: fields}
+	o.Apply(&event)
+
+	if event.Fields["session_id"] != o.Token("pub-1", "session-42") || event.Fields["content_id"] != "episode-7" {
+		t.Errorf("expected only the session ID replaced, got %v", event.Fields)
+	}
+	if fields["session_id"] != "session-42" {
+		t.Error("expected the caller's fields left untouched")
+	}
+
+	var off *IDObfuscator
+	event = Event{Fields: map[string]string{"session_id": "session-42"}}
+	off.Apply(&event)
+	if event.Fields["session_id"] != "session-42" {
+		t.Error("expected a nil obfuscator to leave events alone")
+	}
+}
+
+func TestNewIDObfuscator_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  IDConfig
+	}{
+		{"unknown mode", IDConfig{Mode: "rot13", Keys: testIDKey}},
+		{"no keys", IDConfig{Mode: IDModeHash}},
+		{"short secret", IDConfig{Mode: IDModeHash, Keys: "k1:short"}},
+		{"malformed key", IDConfig{Mode: IDModeHash, Keys: "0123456789abcdef0123456789abcdef"}},
+		{"ambiguous key", IDConfig{Mode: IDModeHash, Keys: testIDKey + "," + testIDNextKey}},
+		{"unknown key ID", IDConfig{Mode: IDModeHash, Keys: testIDKey, KeyID: "k3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewIDObfuscator(tt.cfg); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+	if o, err := NewIDObfuscator(IDConfig{Mode: IDModeOff, Keys: "ignored"}); o != nil || err != nil {
+		t.Errorf("expected no obfuscator when off, got %v (%v)", o, err)
+	}
+}
+
+func TestPipeline_ObfuscatesIdentifiers(t *testing.T) {
+	o, err := NewIDObfuscator(IDConfig{Mode: IDModeHash, Keys: testIDKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink := &memSink{}
+	p := New(Config{BatchSize: 1, FlushInterval: time.Hour}, nil, sink)
+	p.SetIDObfuscator(o)
+	p.Start()
+	p.Track(Event{Type: EventVideo, PublisherID: "pub-1", Fields: map[string]string{"session_id": "session-42"}})
+	p.Stop()
+
+	if got := sink.events(); len(got) != 1 || got[0].Fields["session_id"] != o.Token("pub-1", "session-42") {
+		t.Errorf("expected the sink to see only the token, got %+v", got)
+	}
+}
@@ -0,0 +1,144 @@
+package kafka
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/analytics"
+)
+
+// avroSchema is the Avro schema of analytics.Event. encodeAvro writes the
+// fields in this order.
+const avroSchema = `{
+  "type": "record",
+  "name": "AnalyticsEvent",
+  "namespace": "com.thenexusengine.pbs",
+  "fields": [
+    {"name": "type", "type": "string"},
+    {"name": "timestamp", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+    {"name": "auction_id", "type": "string", "default": ""},
+    {"name": "request_id", "type": "string", "default": ""},
+    {"name": "publisher_id", "type": "string", "default": ""},
+    {"name": "bidder", "type": "string", "default": ""},
+    {"name": "bid_id", "type": "string", "default": ""},
+    {"name": "imp_id", "type": "string", "default": ""},
+    {"name": "media_type", "type": "string", "default": ""},
+    {"name": "status", "type": "string", "default": ""},
+    {"name": "price", "type": "double", "default": 0},
+    {"name": "currency", "type": "string", "default": ""},
+    {"name": "fields", "type": {"type": "map", "values": "string"}, "default": {}}
+  ]
+}`
+
+// encodeAvro encodes e in the Confluent wire format: a zero magic byte, the
+// big-endian schema ID, then the Avro binary record
+func encodeAvro(schemaID int32, e analytics.Event) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte(0)
+	_ = binary.Write(&buf, binary.BigEndian, schemaID)
+
+	writeString := func(s string) {
+		writeLong(&buf, int64(len(s)))
+		buf.WriteString(s)
+	}
+	writeString(e.Type)
+	writeLong(&buf, e.Timestamp.UnixMilli())
+	for _, s := range []string{e.AuctionID, e.RequestID, e.PublisherID, e.Bidder, e.BidID, e.ImpID, e.MediaType, e.Status} {
+		writeString(s)
+	}
+	var price [8]byte
+	binary.LittleEndian.PutUint64(price[:], math.Float64bits(e.Price))
+	buf.Write(price[:])
+	writeString(e.Currency)
+
+	// A map is one block of entries then an empty block; keys are sorted so
+	// equal events encode identically
+	if len(e.Fields) > 0 {
+		keys := make([]string, 0, len(e.Fields))
+		for k := range e.Fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		writeLong(&buf, int64(len(keys)))
+		for _, k := range keys {
+			writeString(k)
+			writeString(e.Fields[k])
+		}
+	}
+	writeLong(&buf, 0)
+	return buf.Bytes()
+}
+
+// writeLong writes an Avro long: zig-zag encoded, then a base-128 varint
+func writeLong(buf *bytes.Buffer, v int64) {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], uint64((v<<1)^(v>>63)))
+	buf.Write(tmp[:n])
+}
+
+// schemaRegistry registers avroSchema under a subject per topic and caches
+// the IDs the registry assigns
+type schemaRegistry struct {
+	url    string
+	client *http.Client
+
+	mu  sync.Mutex
+	ids map[string]int32
+}
+
+func newSchemaRegistry(registryURL string) *schemaRegistry {
+	return &schemaRegistry{
+		url:    strings.TrimRight(registryURL, "/"),
+		client: &http.Client{Timeout: 5 * time.Second},
+		ids:    make(map[string]int32),
+	}
+}
+
+// schemaID returns the subject's ID for avroSchema, registering it on first
+// use. Registering a schema the subject already has returns its existing ID.
+func (r *schemaRegistry) schemaID(ctx context.Context, subject string) (int32, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if id, ok := r.ids[subject]; ok {
+		return id, nil
+	}
+
+	body, err := json.Marshal(map[string]string{"schema": avroSchema})
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode avro schema: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.url+"/subjects/"+url.PathEscape(subject)+"/versions", bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create schema registry request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to register avro schema: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+		return 0, fmt.Errorf("schema registry returned status %d for %s", resp.StatusCode, subject)
+	}
+	var result struct {
+		ID int32 `json:"id"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(&result); err != nil {
+		return 0, fmt.Errorf("failed to decode schema registry response: %w", err)
+	}
+	r.ids[subject] = result.ID
+	return result.ID, nil
+}
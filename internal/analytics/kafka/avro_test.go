@@ -0,0 +1,133 @@
+package kafka
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/analytics"
+)
+
+// decodeAvro reads an event encoded by encodeAvro back, returning the schema
+// ID it carries
+func decodeAvro(t *testing.T, data []byte) (int32, analytics.Event) {
+	t.Helper()
+	r := bytes.NewReader(data)
+	if magic, _ := r.ReadByte(); magic != 0 {
+		t.Fatalf("expected the zero magic byte, got %d", magic)
+	}
+	var id int32
+	binary.Read(r, binary.BigEndian, &id)
+
+	readLong := func() int64 {
+		u, err := binary.ReadUvarint(r)
+		if err != nil {
+			t.Fatalf("invalid long: %v", err)
+		}
+		return int64(u>>1) ^ -int64(u&1)
+	}
+	readString := func() string {
+		b := make([]byte, readLong())
+		r.Read(b)
+		return string(b)
+	}
+
+	var e analytics.Event
+	e.Type = readString()
+	e.Timestamp = time.UnixMilli(readLong()).UTC()
+	for _, s := range []*string{&e.AuctionID, &e.RequestID, &e.PublisherID, &e.Bidder, &e.BidID, &e.ImpID, &e.MediaType, &e.Status} {
+		*s = readString()
+	}
+	var price [8]byte
+	r.Read(price[:])
+	e.Price = math.Float64frombits(binary.LittleEndian.Uint64(price[:]))
+	e.Currency = readString()
+	for n := readLong(); n != 0; n = readLong() {
+		for ; n > 0; n-- {
+			if e.Fields == nil {
+				e.Fields = make(map[string]string)
+			}
+			k := readString()
+			e.Fields[k] = readString()
+		}
+	}
+	if r.Len() != 0 {
+		t.Errorf("expected the whole record read, %d bytes left", r.Len())
+	}
+	return id, e
+}
+
+func TestEncodeAvro(t *testing.T) {
+	e := analytics.Event{
+		Type:        analytics.EventAuction,
+		Timestamp:   time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC),
+		AuctionID:   "a1",
+		PublisherID: "pub-1",
+		Status:      "filled",
+		Price:       2.5,
+		Currency:    "USD",
+		Fields:      map[string]string{"bids": "3", "pod_slots": "2"},
+	}
+	id, decoded := decodeAvro(t, encodeAvro(42, e))
+	if id != 42 {
+		t.Errorf("expected schema ID 42, got %d", id)
+	}
+	if decoded.AuctionID != "a1" || decoded.PublisherID != "pub-1" || decoded.Price != 2.5 || !decoded.Timestamp.Equal(e.Timestamp) {
+		t.Errorf("unexpected decoded event %+v", decoded)
+	}
+	if decoded.Fields["bids"] != "3" || decoded.Fields["pod_slots"] != "2" {
+		t.Errorf("expected the fields map, got %v", decoded.Fields)
+	}
+
+	if !json.Valid([]byte(avroSchema)) {
+		t.Error("expected the schema to be valid JSON")
+	}
+}
+
+func TestSink_Avro(t *testing.T) {
+	var subjects []string
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		subjects = append(subjects, r.URL.Path)
+		var body struct{ Schema string }
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.Schema != avroSchema {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return
+		}
+		w.Write([]byte(`{"id":7}`))
+	}))
+	defer registry.Close()
+
+	writer := &fakeWriter{}
+	cfg := DefaultConfig()
+	cfg.Format = FormatAvro
+	cfg.SchemaRegistryURL = registry.URL
+	s := newTestSink(t, cfg, writer, nil)
+
+	if err := s.Write(context.Background(), testEvents); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if err := s.Write(context.Background(), testEvents[:1]); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if len(subjects) != 3 || subjects[0] != "/subjects/pbs-auctions-value/versions" {
+		t.Errorf("expected the schema registered once per topic, got %v", subjects)
+	}
+	id, decoded := decodeAvro(t, writer.msgs[1].Value)
+	if id != 7 || decoded.Type != analytics.EventWin || decoded.BidID != "b1" {
+		t.Errorf("unexpected avro message: schema %d, %+v", id, decoded)
+	}
+
+	// Without the registry the batch fails, to be retried
+	registry.Close()
+	s.registry = newSchemaRegistry(registry.URL)
+	if err := s.Write(context.Background(), testEvents); err == nil {
+		t.Error("expected an unreachable registry to fail the batch")
+	}
+}
@@ -0,0 +1,221 @@
+// Package kafka is an analytics sink producing events straight to Kafka
+// brokers. Auction, win and video events go to a topic per event type,
+// keyed by publisher ID so a publisher's events share a partition and stay
+// in order, encoded as JSON or as Avro through a Confluent schema registry.
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	kafkago "github.com/segmentio/kafka-go"
+
+	"github.com/thenexusengine/tne_springwire/internal/analytics"
+)
+
+// SinkName is the sink's name in analytics metrics, distinct from the REST
+// Proxy sink's
+const SinkName = "kafka_brokers"
+
+// Value encodings
+const (
+	FormatJSON = "json"
+	FormatAvro = "avro" // Confluent wire format, schema registered per topic
+)
+
+// Delivery statuses reported to Metrics
+const (
+	StatusDelivered = "delivered"
+	StatusFailed    = "failed"
+)
+
+// Metrics records message deliveries per topic
+type Metrics interface {
+	RecordKafkaDelivery(topic, status string, count int)
+}
+
+// Config configures the producer
+type Config struct {
+	Brokers           []string          // Bootstrap brokers; the sink is disabled when empty
+	ClientID          string            // Client ID reported to the brokers
+	Topics            map[string]string // Topic per analytics event type; types without one aren't produced
+	Format            string            // json or avro
+	SchemaRegistryURL string            // Confluent schema registry, required by avro
+}
+
+// DefaultConfig returns the default producer configuration: auction results,
+// wins and video events, as JSON
+func DefaultConfig() Config {
+	return Config{
+		ClientID: "prebid-server",
+		Topics: map[string]string{
+			analytics.EventAuction: "pbs-auctions",
+			analytics.EventWin:     "pbs-wins",
+			analytics.EventVideo:   "pbs-video",
+		},
+		Format: FormatJSON,
+	}
+}
+
+// ValidateConfig checks the format and the settings it needs. A config
+// without brokers is valid and disables the sink.
+func ValidateConfig(cfg Config) error {
+	if len(cfg.Brokers) == 0 {
+		return nil
+	}
+	switch cfg.Format {
+	case "", FormatJSON:
+	case FormatAvro:
+		if cfg.SchemaRegistryURL == "" {
+			return fmt.Errorf("avro kafka format requires a schema registry URL")
+		}
+	default:
+		return fmt.Errorf("kafka format must be %q or %q, got %q", FormatJSON, FormatAvro, cfg.Format)
+	}
+	for eventType, topic := range cfg.Topics {
+		if topic == "" {
+			return fmt.Errorf("kafka topic for %s events must not be empty", eventType)
+		}
+	}
+	return nil
+}
+
+// messageWriter is the part of kafkago.Writer the sink uses
+type messageWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafkago.Message) error
+	Close() error
+}
+
+// Sink produces events to Kafka. It implements analytics.Sink.
+type Sink struct {
+	topics   map[string]string
+	writer   messageWriter
+	registry *schemaRegistry // nil for JSON
+	metrics  Metrics
+}
+
+// NewSink creates a producer for cfg.Brokers. Brokers are only dialled on
+// the first write, so an unreachable cluster doesn't fail startup.
+func NewSink(cfg Config, metrics Metrics) (*Sink, error) {
+	if err := ValidateConfig(cfg); err != nil {
+		return nil, err
+	}
+	if len(cfg.Brokers) == 0 {
+		return nil, fmt.Errorf("kafka sink requires brokers")
+	}
+	if cfg.Topics == nil {
+		cfg.Topics = DefaultConfig().Topics
+	}
+
+	// The pipeline hands over whole batches, so the writer flushes them
+	// right away instead of waiting to fill its own
+	writer := &kafkago.Writer{
+		Addr:         kafkago.TCP(cfg.Brokers...),
+		Balancer:     &kafkago.Murmur2Balancer{},
+		RequiredAcks: kafkago.RequireAll,
+		BatchSize:    1000,
+		BatchTimeout: 5 * time.Millisecond,
+		MaxAttempts:  1, // the pipeline retries failed batches
+		Transport:    &kafkago.Transport{ClientID: cfg.ClientID},
+	}
+
+	s := &Sink{topics: cfg.Topics, writer: writer, metrics: metrics}
+	if cfg.Format == FormatAvro {
+		s.registry = newSchemaRegistry(cfg.SchemaRegistryURL)
+	}
+	return s, nil
+}
+
+// Name implements analytics.Sink
+func (s *Sink) Name() string { return SinkName }
+
+// Write implements analytics.Sink. Events of types without a topic are
+// skipped. A failed message fails the batch, and the pipeline's retry
+// resends the messages that were delivered too.
+func (s *Sink) Write(ctx context.Context, events []analytics.Event) error {
+	msgs := make([]kafkago.Message, 0, len(events))
+	for _, e := range events {
+		topic := s.topics[e.Type]
+		if topic == "" {
+			continue
+		}
+		value, err := s.encode(ctx, topic, e)
+		if err != nil {
+			return err
+		}
+		msg := kafkago.Message{
+			Topic:   topic,
+			Value:   value,
+			Time:    e.Timestamp,
+			Headers: []kafkago.Header{{Key: "event_type", Value: []byte(e.Type)}},
+		}
+		if e.PublisherID != "" {
+			msg.Key = []byte(e.PublisherID)
+		}
+		msgs = append(msgs, msg)
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	err := s.writer.WriteMessages(ctx, msgs...)
+	s.recordDeliveries(msgs, err)
+	if err != nil {
+		return fmt.Errorf("failed to produce to kafka: %w", err)
+	}
+	return nil
+}
+
+// Close flushes and closes the producer
+func (s *Sink) Close() error {
+	return s.writer.Close()
+}
+
+// encode encodes an event's value in the configured format
+func (s *Sink) encode(ctx context.Context, topic string, e analytics.Event) ([]byte, error) {
+	if s.registry == nil {
+		value, err := json.Marshal(e)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode kafka message: %w", err)
+		}
+		return value, nil
+	}
+	id, err := s.registry.schemaID(ctx, topic+"-value")
+	if err != nil {
+		return nil, err
+	}
+	return encodeAvro(id, e), nil
+}
+
+// recordDeliveries counts delivered and failed messages per topic.
+// kafkago.WriteErrors holds one entry per message; any other error failed
+// them all.
+func (s *Sink) recordDeliveries(msgs []kafkago.Message, err error) {
+	if s.metrics == nil {
+		return
+	}
+	var perMessage kafkago.WriteErrors
+	errors.As(err, &perMessage)
+
+	type key struct{ topic, status string }
+	counts := make(map[key]int)
+	for i, msg := range msgs {
+		status := StatusDelivered
+		if perMessage != nil {
+			if i < len(perMessage) && perMessage[i] != nil {
+				status = StatusFailed
+			}
+		} else if err != nil {
+			status = StatusFailed
+		}
+		counts[key{msg.Topic, status}]++
+	}
+	for k, count := range counts {
+		s.metrics.RecordKafkaDelivery(k.topic, k.status, count)
+	}
+}
+
+var _ analytics.Sink = (*Sink)(nil)
@@ -0,0 +1,126 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	kafkago "github.com/segmentio/kafka-go"
+
+	"github.com/thenexusengine/tne_springwire/internal/analytics"
+)
+
+// fakeWriter records produced messages, failing them with err
+type fakeWriter struct {
+	msgs []kafkago.Message
+	err  error
+}
+
+func (w *fakeWriter) WriteMessages(ctx context.Context, msgs ...kafkago.Message) error {
+	w.msgs = append(w.msgs, msgs...)
+	return w.err
+}
+
+func (w *fakeWriter) Close() error { return nil }
+
+type mockMetrics struct {
+	counts map[string]int
+}
+
+func (m *mockMetrics) RecordKafkaDelivery(topic, status string, count int) {
+	if m.counts == nil {
+		m.counts = make(map[string]int)
+	}
+	m.counts[topic+":"+status] += count
+}
+
+var testEvents = []analytics.Event{
+	{Type: analytics.EventAuction, Timestamp: time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC), AuctionID: "a1", PublisherID: "pub-1", Status: "filled", Price: 2.5},
+	{Type: analytics.EventBid, AuctionID: "a1", PublisherID: "pub-1", Bidder: "rubicon"},
+	{Type: analytics.EventWin, AuctionID: "a1", PublisherID: "pub-1", Bidder: "rubicon", BidID: "b1", Price: 2.5},
+	{Type: analytics.EventVideo, BidID: "b1", Status: "firstQuartile"},
+}
+
+func newTestSink(t *testing.T, cfg Config, writer *fakeWriter, metrics Metrics) *Sink {
+	t.Helper()
+	cfg.Brokers = []string{"localhost:9092"}
+	s, err := NewSink(cfg, metrics)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.writer = writer
+	return s
+}
+
+func TestSink_ProducesByEventType(t *testing.T) {
+	writer := &fakeWriter{}
+	metrics := &mockMetrics{}
+	s := newTestSink(t, DefaultConfig(), writer, metrics)
+
+	if err := s.Write(context.Background(), testEvents); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if len(writer.msgs) != 3 {
+		t.Fatalf("expected bid events skipped, got %d messages", len(writer.msgs))
+	}
+	auction, win, video := writer.msgs[0], writer.msgs[1], writer.msgs[2]
+	if auction.Topic != "pbs-auctions" || win.Topic != "pbs-wins" || video.Topic != "pbs-video" {
+		t.Errorf("unexpected topics %q %q %q", auction.Topic, win.Topic, video.Topic)
+	}
+	if string(auction.Key) != "pub-1" || video.Key != nil {
+		t.Errorf("expected messages keyed by publisher, got %q and %q", auction.Key, video.Key)
+	}
+	if len(auction.Headers) != 1 || string(auction.Headers[0].Value) != analytics.EventAuction {
+		t.Errorf("expected the event type header, got %+v", auction.Headers)
+	}
+	var decoded analytics.Event
+	if err := json.Unmarshal(auction.Value, &decoded); err != nil || decoded.AuctionID != "a1" || decoded.Price != 2.5 {
+		t.Errorf("expected the event as JSON, got %s (%v)", auction.Value, err)
+	}
+	if metrics.counts["pbs-auctions:delivered"] != 1 || metrics.counts["pbs-video:delivered"] != 1 {
+		t.Errorf("unexpected deliveries %v", metrics.counts)
+	}
+}
+
+func TestSink_RecordsFailures(t *testing.T) {
+	writer := &fakeWriter{err: kafkago.WriteErrors{nil, errors.New("leader not available"), nil}}
+	metrics := &mockMetrics{}
+	s := newTestSink(t, DefaultConfig(), writer, metrics)
+
+	if err := s.Write(context.Background(), testEvents); err == nil {
+		t.Fatal("expected a failed message to fail the batch")
+	}
+	if metrics.counts["pbs-wins:failed"] != 1 || metrics.counts["pbs-auctions:delivered"] != 1 {
+		t.Errorf("expected per-message outcomes, got %v", metrics.counts)
+	}
+
+	writer.err = errors.New("no brokers reachable")
+	metrics.counts = nil
+	s.Write(context.Background(), testEvents)
+	if metrics.counts["pbs-auctions:failed"] != 1 || metrics.counts["pbs-wins:failed"] != 1 || metrics.counts["pbs-video:failed"] != 1 {
+		t.Errorf("expected every message failed, got %v", metrics.counts)
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	brokers := []string{"localhost:9092"}
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{"disabled", Config{Format: "xml"}, false},
+		{"json", Config{Brokers: brokers, Format: FormatJSON}, false},
+		{"avro", Config{Brokers: brokers, Format: FormatAvro, SchemaRegistryURL: "http://registry"}, false},
+		{"avro without registry", Config{Brokers: brokers, Format: FormatAvro}, true},
+		{"unknown format", Config{Brokers: brokers, Format: "protobuf"}, true},
+		{"empty topic", Config{Brokers: brokers, Topics: map[string]string{analytics.EventWin: ""}}, true},
+	}
+	for _, tt := range tests {
+		if err := ValidateConfig(tt.cfg); (err != nil) != tt.wantErr {
+			t.Errorf("%s: expected error %v, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+}
@@ -0,0 +1,353 @@
+// Package analytics ships auction, bid, win and video events to downstream
+// systems. Events are buffered per sink and written in batches from a
+// background worker, so a slow or failing sink never blocks auctions or the
+// other sinks: its buffer fills and further events are dropped (or, with
+// block backpressure, briefly waited on) and counted.
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+// Event types
+const (
+	EventAuction = "auction"
+	EventBid     = "bid"
+	EventWin     = "win"
+	EventBilling = "billing"
+	EventVideo   = "video"
+)
+
+// Event is one analytics record. The common fields are columns in every
+// sink; type-specific details go in Fields.
+type Event struct {
+	Type        string            `json:"type"`
+	Timestamp   time.Time         `json:"timestamp"`
+	AuctionID   string            `json:"auction_id,omitempty"`
+	RequestID   string            `json:"request_id,omitempty"` // Edge X-Request-ID
+	PublisherID string            `json:"publisher_id,omitempty"`
+	Bidder      string            `json:"bidder,omitempty"` // real bidder, never the platform seat
+	BidID       string            `json:"bid_id,omitempty"`
+	ImpID       string            `json:"imp_id,omitempty"`
+	MediaType   string            `json:"media_type,omitempty"`
+	Status      string            `json:"status,omitempty"` // auction filled/unfilled, bid won/lost, video event name
+	Price       float64           `json:"price,omitempty"`
+	Currency    string            `json:"currency,omitempty"`
+	Fields      map[string]string `json:"fields,omitempty"`
+}
+
+// Sink writes batches of events to a downstream system. A returned error
+// retries the whole batch, so sinks see events at least once.
+type Sink interface {
+	Name() string
+	Write(ctx context.Context, events []Event) error
+}
+
+// Metrics records pipeline activity per sink
+type Metrics interface {
+	RecordAnalyticsEvents(sink, status string, count int)
+	SetAnalyticsQueueDepth(sink string, depth int)
+}
+
+// Event statuses reported to Metrics
+const (
+	StatusWritten = "written"
+	StatusRetried = "retried" // batch write failed and will be retried
+	StatusDropped = "dropped" // sink buffer full
+	StatusFailed  = "failed"  // batch dropped after MaxAttempts writes
+)
+
+// Backpressure policies for a full sink buffer
+const (
+	BackpressureDrop  = "drop"  // drop the event immediately
+	BackpressureBlock = "block" // wait up to BlockTimeout for room, then drop
+)
+
+// Config configures the pipeline and its sinks
+type Config struct {
+	Sinks         []string      // Sink names: postgres, webhook, file
+	BufferSize    int           // Events buffered per sink
+	BatchSize     int           // Events per sink write
+	FlushInterval time.Duration // Longest an event waits for its batch to fill
+	Backpressure  string        // drop or block
+	BlockTimeout  time.Duration // Longest Track waits per full sink under block
+	MaxAttempts   int           // Writes per batch before it is dropped
+	RetryBackoff  time.Duration // Delay before the first retry, doubled after each
+	Timeout       time.Duration // Per-write timeout
+
+	WebhookURL   string
+	WebhookToken string // Sent as a bearer token when set
+	FilePath     string // NDJSON file events are appended to
+
+	// Obfuscation of user and session identifiers before events are queued
+	IDs IDConfig
+}
+
+// DefaultConfig returns the default pipeline configuration
+func DefaultConfig() Config {
+	return Config{
+		BufferSize:    10000,
+		BatchSize:     500,
+		FlushInterval: time.Second,
+		Backpressure:  BackpressureDrop,
+		BlockTimeout:  10 * time.Millisecond,
+		MaxAttempts:   3,
+		RetryBackoff:  500 * time.Millisecond,
+		Timeout:       5 * time.Second,
+	}
+}
+
+// ValidateConfig checks sink names, the settings each sink needs and the
+// pipeline limits
+func ValidateConfig(cfg Config) error {
+	for _, name := range cfg.Sinks {
+		switch name {
+		case SinkPostgres:
+		case SinkWebhook:
+			if cfg.WebhookURL == "" {
+				return fmt.Errorf("webhook analytics sink requires a URL")
+			}
+		case SinkFile:
+			if cfg.FilePath == "" {
+				return fmt.Errorf("file analytics sink requires a path")
+			}
+		default:
+			return fmt.Errorf("unknown analytics sink %q (expected %s, %s or %s)", name, SinkPostgres, SinkWebhook, SinkFile)
+		}
+	}
+	switch cfg.Backpressure {
+	case "", BackpressureDrop, BackpressureBlock:
+	default:
+		return fmt.Errorf("analytics backpressure must be %q or %q, got %q", BackpressureDrop, BackpressureBlock, cfg.Backpressure)
+	}
+	if cfg.BufferSize < 0 || cfg.BatchSize < 0 || cfg.FlushInterval < 0 || cfg.BlockTimeout < 0 || cfg.MaxAttempts < 0 || cfg.RetryBackoff < 0 || cfg.Timeout < 0 {
+		return fmt.Errorf("analytics pipeline limits must not be negative")
+	}
+	if cfg.BatchSize > maxPostgresBatch {
+		return fmt.Errorf("analytics batch size must be at most %d, got %d", maxPostgresBatch, cfg.BatchSize)
+	}
+	if _, err := NewIDObfuscator(cfg.IDs); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Pipeline fans events out to its sinks, each with its own buffer and
+// batching worker
+type Pipeline struct {
+	cfg     Config
+	metrics Metrics
+	queues  []*sinkQueue
+	ids     *IDObfuscator
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	wg       sync.WaitGroup
+}
+
+type sinkQueue struct {
+	sink   Sink
+	events chan Event
+}
+
+// New creates a pipeline writing to sinks
+func New(cfg Config, metrics Metrics, sinks ...Sink) *Pipeline {
+	defaults := DefaultConfig()
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = defaults.BufferSize
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaults.BatchSize
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = defaults.FlushInterval
+	}
+	if cfg.Backpressure == "" {
+		cfg.Backpressure = defaults.Backpressure
+	}
+	if cfg.BlockTimeout <= 0 {
+		cfg.BlockTimeout = defaults.BlockTimeout
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaults.MaxAttempts
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = defaults.RetryBackoff
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaults.Timeout
+	}
+
+	p := &Pipeline{
+		cfg:     cfg,
+		metrics: metrics,
+		stop:    make(chan struct{}),
+	}
+	for _, sink := range sinks {
+		p.queues = append(p.queues, &sinkQueue{sink: sink, events: make(chan Event, cfg.BufferSize)})
+	}
+	return p
+}
+
+// Start launches a worker per sink
+func (p *Pipeline) Start() {
+	names := make([]string, len(p.queues))
+	for i, q := range p.queues {
+		names[i] = q.sink.Name()
+		p.wg.Add(1)
+		go p.run(q)
+	}
+	logger.Log.Info().
+		Strs("sinks", names).
+		Int("batch_size", p.cfg.BatchSize).
+		Dur("flush_interval", p.cfg.FlushInterval).
+		Str("backpressure", p.cfg.Backpressure).
+		Msg("Analytics pipeline started")
+}
+
+// Stop writes the events still buffered, one attempt per batch, then stops
+// the workers and closes sinks that hold resources
+func (p *Pipeline) Stop() {
+	p.stopOnce.Do(func() {
+		close(p.stop)
+	})
+	p.wg.Wait()
+	for _, q := range p.queues {
+		if closer, ok := q.sink.(interface{ Close() error }); ok {
+			if err := closer.Close(); err != nil {
+				logger.Log.Warn().Err(err).Str("sink", q.sink.Name()).Msg("Failed to close analytics sink")
+			}
+		}
+	}
+}
+
+// SetIDObfuscator sets how identifiers are obfuscated before events are
+// queued; call before Start
+func (p *Pipeline) SetIDObfuscator(o *IDObfuscator) {
+	p.ids = o
+}
+
+// Track queues an event for every sink. It never blocks under drop
+// backpressure; under block it waits up to BlockTimeout for each full sink.
+func (p *Pipeline) Track(event Event) {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	p.ids.Apply(&event)
+	for _, q := range p.queues {
+		select {
+		case q.events <- event:
+			continue
+		default:
+		}
+		if p.cfg.Backpressure == BackpressureBlock {
+			timer := time.NewTimer(p.cfg.BlockTimeout)
+			select {
+			case q.events <- event:
+				timer.Stop()
+				continue
+			case <-timer.C:
+			}
+		}
+		p.record(q.sink.Name(), StatusDropped, 1)
+	}
+}
+
+// run batches a sink's events until Stop, writing when the batch is full or
+// FlushInterval passes
+func (p *Pipeline) run(q *sinkQueue) {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Event, 0, p.cfg.BatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		p.write(q.sink, batch)
+		batch = make([]Event, 0, p.cfg.BatchSize)
+		if p.metrics != nil {
+			p.metrics.SetAnalyticsQueueDepth(q.sink.Name(), len(q.events))
+		}
+	}
+
+	for {
+		select {
+		case event := <-q.events:
+			batch = append(batch, event)
+			if len(batch) >= p.cfg.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-p.stop:
+			for {
+				select {
+				case event := <-q.events:
+					batch = append(batch, event)
+					if len(batch) >= p.cfg.BatchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// write writes a batch, retrying with exponential backoff. While the worker
+// retries its buffer fills, which is what pushes back on Track. Once
+// stopping, batches get a single attempt so shutdown isn't held up.
+func (p *Pipeline) write(sink Sink, batch []Event) {
+	backoff := p.cfg.RetryBackoff
+	var err error
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), p.cfg.Timeout)
+		err = sink.Write(ctx, batch)
+		cancel()
+		if err == nil {
+			p.record(sink.Name(), StatusWritten, len(batch))
+			return
+		}
+		if attempt >= p.cfg.MaxAttempts || p.stopping() {
+			break
+		}
+		p.record(sink.Name(), StatusRetried, len(batch))
+		select {
+		case <-time.After(backoff):
+		case <-p.stop:
+		}
+		backoff *= 2
+	}
+
+	logger.Log.Warn().
+		Err(err).
+		Str("sink", sink.Name()).
+		Int("events", len(batch)).
+		Msg("Dropping analytics batch after repeated failures")
+	p.record(sink.Name(), StatusFailed, len(batch))
+}
+
+func (p *Pipeline) stopping() bool {
+	select {
+	case <-p.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+func (p *Pipeline) record(sink, status string, count int) {
+	if p.metrics != nil {
+		p.metrics.RecordAnalyticsEvents(sink, status, count)
+	}
+}
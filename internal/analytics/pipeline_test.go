@@ -0,0 +1,205 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memSink records batches, failing the first failures writes and blocking
+// while gate is set
+type memSink struct {
+	mu       sync.Mutex
+	batches  [][]Event
+	failures int
+	gate     chan struct{}
+}
+
+func (s *memSink) Name() string { return "mem" }
+
+func (s *memSink) Write(ctx context.Context, events []Event) error {
+	if s.gate != nil {
+		<-s.gate
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("sink unavailable")
+	}
+	s.batches = append(s.batches, append([]Event(nil), events...))
+	return nil
+}
+
+func (s *memSink) events() []Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var all []Event
+	for _, b := range s.batches {
+		all = append(all, b...)
+	}
+	return all
+}
+
+type mockMetrics struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (m *mockMetrics) RecordAnalyticsEvents(sink, status string, count int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.counts == nil {
+		m.counts = make(map[string]int)
+	}
+	m.counts[sink+":"+status] += count
+}
+
+func (m *mockMetrics) SetAnalyticsQueueDepth(sink string, depth int) {}
+
+func (m *mockMetrics) get(key string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.counts[key]
+}
+
+func TestPipeline_BatchesPerSink(t *testing.T) {
+	first, second := &memSink{}, &memSink{}
+	metrics := &mockMetrics{}
+	p := New(Config{BatchSize: 2, FlushInterval: time.Hour}, metrics, first, second)
+	p.Start()
+
+	for _, id := range []string{"a1", "a2", "a3"} {
+		p.Track(Event{Type: EventAuction, AuctionID: id})
+	}
+	deadline := time.Now().Add(time.Second)
+	for len(first.events()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := first.events(); len(got) != 2 || got[0].AuctionID != "a1" || got[0].Timestamp.IsZero() {
+		t.Fatalf("expected a full batch of two timestamped events, got %+v", got)
+	}
+
+	// The partial batch is written on Stop
+	p.Stop()
+	for _, sink := range []*memSink{first, second} {
+		if got := sink.events(); len(got) != 3 || len(sink.batches) != 2 {
+			t.Errorf("expected every event in two batches, got %d batches %+v", len(sink.batches), got)
+		}
+	}
+	if metrics.get("mem:written") != 6 {
+		t.Errorf("expected 6 events written, got %v", metrics.counts)
+	}
+}
+
+func TestPipeline_FlushInterval(t *testing.T) {
+	sink := &memSink{}
+	p := New(Config{BatchSize: 100, FlushInterval: 10 * time.Millisecond}, nil, sink)
+	p.Start()
+	defer p.Stop()
+
+	p.Track(Event{Type: EventBid})
+	deadline := time.Now().Add(time.Second)
+	for len(sink.events()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if len(sink.events()) != 1 {
+		t.Error("expected a partial batch written after the flush interval")
+	}
+}
+
+func TestPipeline_RetriesFailedBatches(t *testing.T) {
+	sink := &memSink{failures: 2}
+	metrics := &mockMetrics{}
+	p := New(Config{BatchSize: 1, MaxAttempts: 3, RetryBackoff: time.Millisecond}, metrics, sink)
+	p.Start()
+	p.Track(Event{Type: EventWin, BidID: "b1"})
+
+	deadline := time.Now().Add(time.Second)
+	for len(sink.events()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	p.Stop()
+	if len(sink.events()) != 1 || metrics.get("mem:retried") != 2 || metrics.get("mem:written") != 1 {
+		t.Errorf("expected the batch written on the third attempt, got %v", metrics.counts)
+	}
+
+	// Exhausted batches are dropped
+	sink = &memSink{failures: 10}
+	metrics = &mockMetrics{}
+	p = New(Config{BatchSize: 1, MaxAttempts: 2, RetryBackoff: time.Millisecond}, metrics, sink)
+	p.Start()
+	p.Track(Event{Type: EventWin, BidID: "b2"})
+	deadline = time.Now().Add(time.Second)
+	for metrics.get("mem:failed") == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	p.Stop()
+	if metrics.get("mem:failed") != 1 {
+		t.Errorf("expected the batch dropped after two attempts, got %v", metrics.counts)
+	}
+}
+
+func TestPipeline_Backpressure(t *testing.T) {
+	// The worker is stuck writing the first event, so the one-event buffer
+	// fills behind it
+	sink := &memSink{gate: make(chan struct{})}
+	metrics := &mockMetrics{}
+	p := New(Config{BufferSize: 1, BatchSize: 1}, metrics, sink)
+	p.Start()
+
+	p.Track(Event{AuctionID: "a1"})
+	deadline := time.Now().Add(time.Second)
+	for len(p.queues[0].events) != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	p.Track(Event{AuctionID: "a2"})
+
+	start := time.Now()
+	p.Track(Event{AuctionID: "a3"})
+	if time.Since(start) > 50*time.Millisecond {
+		t.Error("expected drop backpressure not to block")
+	}
+	if metrics.get("mem:dropped") != 1 {
+		t.Errorf("expected the event over the buffer dropped, got %v", metrics.counts)
+	}
+
+	// Block backpressure waits for room before dropping
+	p.cfg.Backpressure = BackpressureBlock
+	p.cfg.BlockTimeout = 20 * time.Millisecond
+	start = time.Now()
+	p.Track(Event{AuctionID: "a4"})
+	if time.Since(start) < 20*time.Millisecond || metrics.get("mem:dropped") != 2 {
+		t.Errorf("expected block backpressure to wait then drop, got %v", metrics.counts)
+	}
+
+	close(sink.gate)
+	p.Stop()
+	if got := sink.events(); len(got) != 2 {
+		t.Errorf("expected the buffered events written, got %+v", got)
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{"no sinks", Config{}, false},
+		{"every sink", Config{Sinks: []string{SinkPostgres, SinkWebhook, SinkFile}, WebhookURL: "http://hook", FilePath: "/tmp/events"}, false},
+		{"unknown sink", Config{Sinks: []string{"s3"}}, true},
+		{"webhook without URL", Config{Sinks: []string{SinkWebhook}}, true},
+		{"file without path", Config{Sinks: []string{SinkFile}}, true},
+		{"unknown backpressure", Config{Backpressure: "spill"}, true},
+		{"negative buffer", Config{BufferSize: -1}, true},
+		{"batch over parameter limit", Config{BatchSize: maxPostgresBatch + 1}, true},
+	}
+	for _, tt := range tests {
+		if err := ValidateConfig(tt.cfg); (err != nil) != tt.wantErr {
+			t.Errorf("%s: expected error %v, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+}
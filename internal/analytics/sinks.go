@@ -0,0 +1,214 @@
+package analytics
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Sink names
+const (
+	SinkPostgres = "postgres"
+	SinkWebhook  = "webhook"
+	SinkFile     = "file"
+)
+
+// analyticsEventColumns are the analytics_events columns written per event
+// (see migration 025)
+const analyticsEventColumns = 13
+
+// maxPostgresBatch keeps one batch insert under PostgreSQL's 65535
+// parameter limit
+const maxPostgresBatch = 65535 / analyticsEventColumns
+
+// NewSinks builds the sinks named in cfg.Sinks. db is only needed by the
+// postgres sink.
+func NewSinks(cfg Config, db *sql.DB) ([]Sink, error) {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = DefaultConfig().Timeout
+	}
+	client := &http.Client{Timeout: timeout}
+
+	sinks := make([]Sink, 0, len(cfg.Sinks))
+	for _, name := range cfg.Sinks {
+		switch name {
+		case SinkPostgres:
+			if db == nil {
+				return nil, fmt.Errorf("postgres analytics sink requires the database")
+			}
+			sinks = append(sinks, NewPostgresSink(db))
+		case SinkWebhook:
+			sinks = append(sinks, NewWebhookSink(client, cfg.WebhookURL, cfg.WebhookToken))
+		case SinkFile:
+			sink, err := NewFileSink(cfg.FilePath)
+			if err != nil {
+				return nil, err
+			}
+			sinks = append(sinks, sink)
+		default:
+			return nil, fmt.Errorf("unknown analytics sink %q", name)
+		}
+	}
+	return sinks, nil
+}
+
+// PostgresSink inserts events into the analytics_events table
+type PostgresSink struct {
+	db *sql.DB
+}
+
+// NewPostgresSink creates a PostgreSQL sink
+func NewPostgresSink(db *sql.DB) *PostgresSink {
+	return &PostgresSink{db: db}
+}
+
+// Name implements Sink
+func (s *PostgresSink) Name() string { return SinkPostgres }
+
+// Write implements Sink with one multi-row insert per batch
+func (s *PostgresSink) Write(ctx context.Context, events []Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	var query strings.Builder
+	query.WriteString(`INSERT INTO analytics_events (
+		event_type, event_time, auction_id, request_id, publisher_id, bidder, bid_id,
+		imp_id, media_type, status, price, currency, fields
+	) VALUES `)
+	args := make([]interface{}, 0, len(events)*analyticsEventColumns)
+	for i, e := range events {
+		if i > 0 {
+			query.WriteString(", ")
+		}
+		query.WriteString("(")
+		for c := 1; c <= analyticsEventColumns; c++ {
+			if c > 1 {
+				query.WriteString(", ")
+			}
+			fmt.Fprintf(&query, "$%d", i*analyticsEventColumns+c)
+		}
+		query.WriteString(")")
+
+		var fields interface{}
+		if len(e.Fields) > 0 {
+			data, err := json.Marshal(e.Fields)
+			if err != nil {
+				return fmt.Errorf("failed to encode event fields: %w", err)
+			}
+			fields = string(data)
+		}
+		args = append(args,
+			e.Type, e.Timestamp, e.AuctionID, e.RequestID, e.PublisherID, e.Bidder, e.BidID,
+			e.ImpID, e.MediaType, e.Status, e.Price, e.Currency, fields,
+		)
+	}
+
+	if _, err := s.db.ExecContext(ctx, query.String(), args...); err != nil {
+		return fmt.Errorf("failed to insert analytics events: %w", err)
+	}
+	return nil
+}
+
+// WebhookSink posts batches as {"events": [...]} to an HTTP endpoint
+type WebhookSink struct {
+	client *http.Client
+	url    string
+	token  string
+}
+
+// NewWebhookSink creates a webhook sink; token is sent as a bearer token
+// when set
+func NewWebhookSink(client *http.Client, url, token string) *WebhookSink {
+	return &WebhookSink{client: client, url: url, token: token}
+}
+
+// Name implements Sink
+func (s *WebhookSink) Name() string { return SinkWebhook }
+
+// Write implements Sink. Any non-2xx response fails the batch.
+func (s *WebhookSink) Write(ctx context.Context, events []Event) error {
+	body, err := json.Marshal(map[string]interface{}{"events": events})
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook events: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post analytics webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("analytics webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// FileSink appends events to a file as newline-delimited JSON, for local
+// collection by a log shipper
+type FileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileSink opens path for appending, creating it if needed
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open analytics file: %w", err)
+	}
+	return &FileSink{file: file}, nil
+}
+
+// Name implements Sink
+func (s *FileSink) Name() string { return SinkFile }
+
+// Write implements Sink, writing the batch in one append
+func (s *FileSink) Write(ctx context.Context, events []Event) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range events {
+		if err := enc.Encode(e); err != nil {
+			return fmt.Errorf("failed to encode analytics event: %w", err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.file.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write analytics file: %w", err)
+	}
+	return nil
+}
+
+// Close closes the file
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+// Sinks used by the pipeline satisfy Sink
+var (
+	_ Sink = (*PostgresSink)(nil)
+	_ Sink = (*WebhookSink)(nil)
+	_ Sink = (*FileSink)(nil)
+)
@@ -0,0 +1,120 @@
+package analytics
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+var testEvents = []Event{
+	{Type: EventAuction, Timestamp: time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC), AuctionID: "a1", PublisherID: "pub-1", Status: "filled", Price: 2.5, Currency: "USD", Fields: map[string]string{"bids": "3"}},
+	{Type: EventBid, Timestamp: time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC), AuctionID: "a1", Bidder: "rubicon", BidID: "b1", ImpID: "imp-1", Status: "won", Price: 2.5},
+}
+
+func TestPostgresSink(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	e, b := testEvents[0], testEvents[1]
+	mock.ExpectExec("INSERT INTO analytics_events").
+		WithArgs(
+			e.Type, e.Timestamp, e.AuctionID, "", e.PublisherID, "", "", "", "", e.Status, e.Price, e.Currency, `{"bids":"3"}`,
+			b.Type, b.Timestamp, b.AuctionID, "", "", b.Bidder, b.BidID, b.ImpID, "", b.Status, b.Price, "", nil,
+		).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	if err := NewPostgresSink(db).Write(context.Background(), testEvents); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	status := http.StatusOK
+	var auth string
+	var body struct {
+		Events []Event `json:"events"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	sink := NewWebhookSink(server.Client(), server.URL, "secret")
+	if err := sink.Write(context.Background(), testEvents); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if auth != "Bearer secret" || len(body.Events) != 2 || body.Events[0].Fields["bids"] != "3" {
+		t.Errorf("unexpected delivery: auth %q, events %+v", auth, body.Events)
+	}
+
+	status = http.StatusServiceUnavailable
+	if err := sink.Write(context.Background(), testEvents); err == nil {
+		t.Error("expected a server error to fail the batch")
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.ndjson")
+	sink, err := NewFileSink(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Write(context.Background(), testEvents[:1]); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if err := sink.Write(context.Background(), testEvents[1:]); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	sink.Close()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var lines []Event
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("invalid line %q: %v", scanner.Text(), err)
+		}
+		lines = append(lines, e)
+	}
+	if len(lines) != 2 || lines[0].AuctionID != "a1" || lines[1].Bidder != "rubicon" {
+		t.Errorf("expected one event per line, got %+v", lines)
+	}
+}
+
+func TestNewSinks(t *testing.T) {
+	cfg := Config{Sinks: []string{SinkWebhook, SinkFile}, WebhookURL: "http://hook", FilePath: filepath.Join(t.TempDir(), "events.ndjson")}
+	sinks, err := NewSinks(cfg, nil)
+	if err != nil {
+		t.Fatalf("failed to build sinks: %v", err)
+	}
+	if len(sinks) != 2 || sinks[0].Name() != SinkWebhook || sinks[1].Name() != SinkFile {
+		t.Errorf("unexpected sinks %+v", sinks)
+	}
+	sinks[1].(io.Closer).Close()
+
+	if _, err := NewSinks(Config{Sinks: []string{SinkPostgres}}, nil); err == nil {
+		t.Error("expected the postgres sink to require a database")
+	}
+}
@@ -0,0 +1,183 @@
+// Package auctionregistry publishes a compact summary of every auction
+// impression (auction ID, impression ID, publisher, winning bidder, price,
+// time) to a Redis stream.
+// Downstream services such as billing and reporting consume the stream to
+// join their own data to auction IDs without parsing full event payloads.
+package auctionregistry
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+	"github.com/thenexusengine/tne_springwire/pkg/redis"
+)
+
+// Summary is the registry record for one impression of an auction.
+// Impressions without a winner have an empty Bidder and a zero Price.
+type Summary struct {
+	AuctionID   string
+	ImpID       string
+	PublisherID string
+	Bidder      string  // real winning bidder, never the obfuscated platform seat
+	Price       float64 // publisher-facing CPM
+	Currency    string
+	Timestamp   time.Time
+}
+
+// Values returns the stream entry fields. Field names are part of the
+// contract with consumers; add fields rather than renaming them.
+func (s Summary) Values() map[string]interface{} {
+	return map[string]interface{}{
+		"auction_id":   s.AuctionID,
+		"imp_id":       s.ImpID,
+		"publisher_id": s.PublisherID,
+		"bidder":       s.Bidder,
+		"price":        strconv.FormatFloat(s.Price, 'f', -1, 64),
+		"cur":          s.Currency,
+		"ts":           strconv.FormatInt(s.Timestamp.UnixMilli(), 10),
+	}
+}
+
+// Streams is the subset of the Redis client used by the registry
+type Streams interface {
+	XAdd(ctx context.Context, stream string, maxLen int64, values map[string]interface{}) (string, error)
+}
+
+// Redis client satisfies Streams directly
+var _ Streams = (*redis.Client)(nil)
+
+// Metrics records registry writes
+type Metrics interface {
+	RecordAuctionRegistry(status string)
+}
+
+// Record statuses reported to Metrics
+const (
+	StatusWritten = "written"
+	StatusDropped = "dropped" // buffer full
+	StatusFailed  = "failed"  // Redis write failed
+)
+
+// Config configures the registry
+type Config struct {
+	Enabled    bool
+	Stream     string        // Redis stream key
+	MaxLen     int64         // Approximate stream length cap
+	BufferSize int           // Summaries waiting to be written
+	Timeout    time.Duration // Per-write Redis timeout
+}
+
+// DefaultConfig returns the default registry configuration
+func DefaultConfig() Config {
+	return Config{
+		Stream:     "pbs:auctions",
+		MaxLen:     1000000,
+		BufferSize: 10000,
+		Timeout:    time.Second,
+	}
+}
+
+// Registry writes auction summaries to a Redis stream from a background
+// goroutine so auctions never wait on Redis. Summaries published faster than
+// Redis accepts them are dropped and counted.
+type Registry struct {
+	cfg     Config
+	streams Streams
+	metrics Metrics
+	records chan Summary
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// New creates a registry writing to streams
+func New(streams Streams, cfg Config, metrics Metrics) *Registry {
+	defaults := DefaultConfig()
+	if cfg.Stream == "" {
+		cfg.Stream = defaults.Stream
+	}
+	if cfg.MaxLen <= 0 {
+		cfg.MaxLen = defaults.MaxLen
+	}
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = defaults.BufferSize
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaults.Timeout
+	}
+	return &Registry{
+		cfg:     cfg,
+		streams: streams,
+		metrics: metrics,
+		records: make(chan Summary, cfg.BufferSize),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// Publish queues a summary for writing without blocking
+func (r *Registry) Publish(s Summary) {
+	select {
+	case r.records <- s:
+	default:
+		r.record(StatusDropped)
+	}
+}
+
+// Start launches the writer
+func (r *Registry) Start() {
+	go r.run()
+	logger.Log.Info().
+		Str("stream", r.cfg.Stream).
+		Int64("max_len", r.cfg.MaxLen).
+		Msg("Auction registry started")
+}
+
+// Stop writes the summaries already queued and stops the writer
+func (r *Registry) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.stop)
+	})
+	<-r.done
+}
+
+func (r *Registry) run() {
+	defer close(r.done)
+	for {
+		select {
+		case s := <-r.records:
+			r.write(s)
+		case <-r.stop:
+			for {
+				select {
+				case s := <-r.records:
+					r.write(s)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (r *Registry) write(s Summary) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.Timeout)
+	defer cancel()
+
+	if _, err := r.streams.XAdd(ctx, r.cfg.Stream, r.cfg.MaxLen, s.Values()); err != nil {
+		logger.Log.Debug().Err(err).Str("auction_id", s.AuctionID).Msg("Failed to write auction summary")
+		r.record(StatusFailed)
+		return
+	}
+	r.record(StatusWritten)
+}
+
+func (r *Registry) record(status string) {
+	if r.metrics != nil {
+		r.metrics.RecordAuctionRegistry(status)
+	}
+}
@@ -0,0 +1,108 @@
+package auctionregistry
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/thenexusengine/tne_springwire/pkg/redis"
+)
+
+type mockMetrics struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (m *mockMetrics) RecordAuctionRegistry(status string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.counts[status]++
+}
+
+func (m *mockMetrics) get(status string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.counts[status]
+}
+
+// failingStreams rejects every write
+type failingStreams struct{}
+
+func (failingStreams) XAdd(ctx context.Context, stream string, maxLen int64, values map[string]interface{}) (string, error) {
+	return "", errors.New("connection refused")
+}
+
+func TestRegistry_WritesSummaries(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("Failed to start miniredis: %v", err)
+	}
+	defer mr.Close()
+
+	client, err := redis.New("redis://" + mr.Addr())
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	metrics := &mockMetrics{counts: make(map[string]int)}
+	r := New(client, Config{Stream: "test:auctions"}, metrics)
+	r.Start()
+
+	ts := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	r.Publish(Summary{AuctionID: "a1", ImpID: "imp-1", PublisherID: "pub1", Bidder: "appnexus", Price: 2.5, Currency: "USD", Timestamp: ts})
+	r.Publish(Summary{AuctionID: "a2", PublisherID: "pub1", Currency: "USD", Timestamp: ts})
+	r.Stop()
+
+	entries, err := mr.Stream("test:auctions")
+	if err != nil {
+		t.Fatalf("Failed to read stream: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+
+	fields := make(map[string]string)
+	for i := 0; i+1 < len(entries[0].Values); i += 2 {
+		fields[entries[0].Values[i]] = entries[0].Values[i+1]
+	}
+	want := map[string]string{
+		"auction_id":   "a1",
+		"imp_id":       "imp-1",
+		"publisher_id": "pub1",
+		"bidder":       "appnexus",
+		"price":        "2.5",
+		"cur":          "USD",
+		"ts":           "1772366400000",
+	}
+	for k, v := range want {
+		if fields[k] != v {
+			t.Errorf("expected %s=%q, got %q", k, v, fields[k])
+		}
+	}
+	if got := metrics.get(StatusWritten); got != 2 {
+		t.Errorf("expected 2 written, got %d", got)
+	}
+}
+
+func TestRegistry_DropsAndFailures(t *testing.T) {
+	metrics := &mockMetrics{counts: make(map[string]int)}
+	r := New(failingStreams{}, Config{BufferSize: 2}, metrics)
+
+	// Not started, so the third summary finds the buffer full
+	for i := 0; i < 3; i++ {
+		r.Publish(Summary{AuctionID: "a"})
+	}
+	if got := metrics.get(StatusDropped); got != 1 {
+		t.Errorf("expected 1 dropped, got %d", got)
+	}
+
+	r.Start()
+	r.Stop()
+	if got := metrics.get(StatusFailed); got != 2 {
+		t.Errorf("expected 2 failed writes, got %d", got)
+	}
+}
@@ -0,0 +1,187 @@
+package auctiontrail
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/pkg/kv"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+// keyPrefix namespaces trails in the KV store; the auction ID follows
+const keyPrefix = "pbs:auction_trail:"
+
+// ErrNotFound is returned by Get for auctions without a trail, either never
+// recorded or expired
+var ErrNotFound = errors.New("auction trail not found")
+
+// Store is the subset of the KV store used by the recorder
+type Store interface {
+	Get(ctx context.Context, key string) (string, error)
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
+}
+
+// Every KV backend satisfies Store
+var _ Store = kv.Store(nil)
+
+// Metrics records trail writes
+type Metrics interface {
+	RecordAuctionTrail(status string)
+}
+
+// Record statuses reported to Metrics
+const (
+	StatusWritten = "written"
+	StatusDropped = "dropped" // buffer full
+	StatusFailed  = "failed"  // KV write failed
+	StatusShed    = "shed"    // KV store degraded
+)
+
+// Config configures the recorder
+type Config struct {
+	Enabled    bool
+	TTL        time.Duration // How long trails can be looked up
+	BufferSize int           // Trails waiting to be written
+	Timeout    time.Duration // Per-write KV timeout
+}
+
+// DefaultConfig returns the default recorder configuration
+func DefaultConfig() Config {
+	return Config{
+		TTL:        24 * time.Hour,
+		BufferSize: 10000,
+		Timeout:    time.Second,
+	}
+}
+
+// Recorder writes auction trails to the KV store from a background goroutine
+// so auctions never wait on it. Trails recorded faster than the store accepts
+// them are dropped and counted. A later auction with the same ID replaces the
+// trail.
+type Recorder struct {
+	cfg     Config
+	store   Store
+	metrics Metrics
+	trails  chan *Trail
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// New creates a recorder writing to store
+func New(store Store, cfg Config, metrics Metrics) *Recorder {
+	defaults := DefaultConfig()
+	if cfg.TTL <= 0 {
+		cfg.TTL = defaults.TTL
+	}
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = defaults.BufferSize
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaults.Timeout
+	}
+	return &Recorder{
+		cfg:     cfg,
+		store:   store,
+		metrics: metrics,
+		trails:  make(chan *Trail, cfg.BufferSize),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// Record queues a trail for writing without blocking. The recorder owns the
+// trail from here on. Trails are shed while the KV store is degraded so
+// debugging data doesn't add to a struggling Redis.
+func (r *Recorder) Record(t *Trail) {
+	if kv.IsDegraded(r.store) {
+		r.record(StatusShed)
+		return
+	}
+	select {
+	case r.trails <- t:
+	default:
+		r.record(StatusDropped)
+	}
+}
+
+// Get returns the trail recorded for auctionID
+func (r *Recorder) Get(ctx context.Context, auctionID string) (*Trail, error) {
+	raw, err := r.store.Get(ctx, keyPrefix+auctionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read auction trail: %w", err)
+	}
+	if raw == "" {
+		return nil, ErrNotFound
+	}
+	var t Trail
+	if err := json.Unmarshal([]byte(raw), &t); err != nil {
+		return nil, fmt.Errorf("failed to decode auction trail: %w", err)
+	}
+	return &t, nil
+}
+
+// Start launches the writer
+func (r *Recorder) Start() {
+	go r.run()
+	logger.Log.Info().
+		Dur("ttl", r.cfg.TTL).
+		Msg("Auction trail recorder started")
+}
+
+// Stop writes the trails already queued and stops the writer
+func (r *Recorder) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.stop)
+	})
+	<-r.done
+}
+
+func (r *Recorder) run() {
+	defer close(r.done)
+	for {
+		select {
+		case t := <-r.trails:
+			r.write(t)
+		case <-r.stop:
+			for {
+				select {
+				case t := <-r.trails:
+					r.write(t)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (r *Recorder) write(t *Trail) {
+	data, err := json.Marshal(t)
+	if err != nil {
+		logger.Log.Debug().Err(err).Str("auction_id", t.AuctionID).Msg("Failed to encode auction trail")
+		r.record(StatusFailed)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.Timeout)
+	defer cancel()
+
+	if err := r.store.Set(ctx, keyPrefix+t.AuctionID, string(data), r.cfg.TTL); err != nil {
+		logger.Log.Debug().Err(err).Str("auction_id", t.AuctionID).Msg("Failed to write auction trail")
+		r.record(StatusFailed)
+		return
+	}
+	r.record(StatusWritten)
+}
+
+func (r *Recorder) record(status string) {
+	if r.metrics != nil {
+		r.metrics.RecordAuctionTrail(status)
+	}
+}
@@ -0,0 +1,119 @@
+package auctiontrail
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/pkg/kv"
+)
+
+type mockMetrics struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (m *mockMetrics) RecordAuctionTrail(status string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.counts[status]++
+}
+
+func (m *mockMetrics) get(status string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.counts[status]
+}
+
+// failingStore rejects every write
+type failingStore struct{}
+
+func (failingStore) Get(ctx context.Context, key string) (string, error) {
+	return "", errors.New("connection refused")
+}
+
+func (failingStore) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	return errors.New("connection refused")
+}
+
+func TestRecorder_WritesAndReadsTrails(t *testing.T) {
+	store := kv.NewMemory()
+	metrics := &mockMetrics{counts: make(map[string]int)}
+	r := New(store, Config{}, metrics)
+	r.Start()
+
+	r.Record(&Trail{
+		AuctionID:   "a1",
+		PublisherID: "pub1",
+		Outcome:     OutcomeFilled,
+		Bids:        []Bid{{Bidder: "appnexus", BidID: "b1", ImpID: "1", Price: 2.5, Outcome: BidWon}},
+	})
+	r.Stop()
+
+	if got := metrics.get(StatusWritten); got != 1 {
+		t.Errorf("expected 1 written trail, got %d", got)
+	}
+	trail, err := r.Get(context.Background(), "a1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if trail.PublisherID != "pub1" || len(trail.Bids) != 1 || trail.Bids[0].Price != 2.5 {
+		t.Errorf("unexpected trail: %+v", trail)
+	}
+
+	if _, err := r.Get(context.Background(), "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestRecorder_CountsFailedWrites(t *testing.T) {
+	metrics := &mockMetrics{counts: make(map[string]int)}
+	r := New(failingStore{}, Config{}, metrics)
+	r.Start()
+	r.Record(&Trail{AuctionID: "a1"})
+	r.Stop()
+
+	if got := metrics.get(StatusFailed); got != 1 {
+		t.Errorf("expected 1 failed write, got %d", got)
+	}
+	if _, err := r.Get(context.Background(), "a1"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("expected a read error, got %v", err)
+	}
+}
+
+func TestRecorder_DropsWhenBufferFull(t *testing.T) {
+	metrics := &mockMetrics{counts: make(map[string]int)}
+	r := New(kv.NewMemory(), Config{BufferSize: 1}, metrics)
+
+	// Not started, so the second trail finds the buffer full
+	r.Record(&Trail{AuctionID: "a1"})
+	r.Record(&Trail{AuctionID: "a2"})
+
+	if got := metrics.get(StatusDropped); got != 1 {
+		t.Errorf("expected 1 dropped trail, got %d", got)
+	}
+}
+
+// degradedStore is a KV store over its error budget
+type degradedStore struct {
+	*kv.Memory
+}
+
+func (degradedStore) Degraded() bool { return true }
+
+func TestRecorder_ShedsWhenStoreDegraded(t *testing.T) {
+	metrics := &mockMetrics{counts: make(map[string]int)}
+	r := New(degradedStore{kv.NewMemory()}, Config{}, metrics)
+	r.Start()
+	r.Record(&Trail{AuctionID: "a1"})
+	r.Stop()
+
+	if got := metrics.get(StatusShed); got != 1 {
+		t.Errorf("expected 1 shed trail, got %d", got)
+	}
+	if _, err := r.Get(context.Background(), "a1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected no trail written, got %v", err)
+	}
+}
@@ -0,0 +1,138 @@
+// Package auctiontrail keeps the full decision trail of recent auctions
+// (bidders called or excluded, filters in force, every bid and why it was
+// rejected or lost, winners and margins) in the shared KV store, so support
+// can look up an auction ID and answer "why did bidder X lose?" without
+// replaying logs.
+package auctiontrail
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Auction outcomes
+const (
+	OutcomeFilled   = "filled"   // at least one bid was returned
+	OutcomeUnfilled = "unfilled" // no bid was returned; see NoBidReason
+)
+
+// Bid outcomes
+const (
+	BidWon      = "won"      // returned to the publisher
+	BidLost     = "lost"     // valid, but not returned
+	BidRejected = "rejected" // failed validation or a publisher filter
+)
+
+// Trail is one auction's decision trail
+type Trail struct {
+	AuctionID   string             `json:"auction_id"`
+	RequestID   string             `json:"request_id,omitempty"`
+	PublisherID string             `json:"publisher_id,omitempty"`
+	Timestamp   time.Time          `json:"timestamp"`
+	LatencyMS   int64              `json:"latency_ms"`
+	AuctionType string             `json:"auction_type"`             // first_price or second_price
+	TieBreak    string             `json:"tie_break,omitempty"`      // Rule for bids at the same price
+	TieSeed     string             `json:"tie_break_seed,omitempty"` // Hex tie-breaking seed drawn from the auction ID
+	Currency    string             `json:"currency"`
+	Outcome     string             `json:"outcome"`
+	NoBidReason int                `json:"nbr,omitempty"` // OpenRTB no-bid reason of unfilled auctions
+	Filters     Filters            `json:"filters"`
+	Floors      map[string]float64 `json:"floors,omitempty"` // Imp ID to floor CPM
+	Bidders     []Bidder           `json:"bidders"`
+	Bids        []Bid              `json:"bids"`
+	Winners     []Winner           `json:"winners"`
+	Errors      map[string]string  `json:"errors,omitempty"` // Auction-level errors such as FPD processing
+}
+
+// Filters are the publisher filters and pricing in force for the auction;
+// zero values mean the filter was off
+type Filters struct {
+	MaxBidCPM        float64 `json:"max_bid_cpm,omitempty"`
+	LanguageFilter   string  `json:"language_filter,omitempty"`
+	ContentLanguage  string  `json:"content_language,omitempty"`
+	CreativeApproval string  `json:"creative_approval,omitempty"`
+	Sanitization     string  `json:"sanitization,omitempty"`
+	BidMultiplier    float64 `json:"bid_multiplier,omitempty"`
+}
+
+// Bidder is one bidder's part in the auction
+type Bidder struct {
+	Code      string   `json:"code"`
+	Called    bool     `json:"called"`
+	Reason    string   `json:"reason,omitempty"` // Why IDR selected or excluded it, or the fan-out cap
+	LatencyMS int64    `json:"latency_ms,omitempty"`
+	TimedOut  bool     `json:"timed_out,omitempty"`
+	Bids      int      `json:"bids"`
+	Errors    []string `json:"errors,omitempty"`
+}
+
+// Bid is one bid as received, with what became of it
+type Bid struct {
+	Bidder     string   `json:"bidder"`
+	BidID      string   `json:"bid_id"`
+	ImpID      string   `json:"imp_id"`
+	Price      float64  `json:"price"` // As bid, before auction rules and margin
+	DealID     string   `json:"deal_id,omitempty"`
+	CreativeID string   `json:"crid,omitempty"`
+	ADomain    []string `json:"adomain,omitempty"`
+	Outcome    string   `json:"outcome"`
+	Reason     string   `json:"reason,omitempty"`
+}
+
+// Winner is a bid returned to the publisher and its pricing
+type Winner struct {
+	ImpID      string  `json:"imp_id"`
+	Bidder     string  `json:"bidder"` // Real bidder, never the obfuscated platform seat
+	BidID      string  `json:"bid_id"`
+	BidPrice   float64 `json:"bid_price"`   // As bid
+	ClearPrice float64 `json:"clear_price"` // After auction rules
+	NetPrice   float64 `json:"net_price"`   // Returned to the publisher
+	Margin     float64 `json:"margin"`      // ClearPrice - NetPrice, kept by the platform
+}
+
+// Explain says in a sentence or two why bidder won or lost the auction
+func (t *Trail) Explain(bidder string) string {
+	var b *Bidder
+	for i := range t.Bidders {
+		if t.Bidders[i].Code == bidder {
+			b = &t.Bidders[i]
+			break
+		}
+	}
+	if b == nil {
+		return fmt.Sprintf("%s was not considered: it was not enabled when the auction ran", bidder)
+	}
+	if !b.Called {
+		return fmt.Sprintf("%s was not called: %s", bidder, b.Reason)
+	}
+
+	var bids []Bid
+	for _, bid := range t.Bids {
+		if bid.Bidder == bidder {
+			bids = append(bids, bid)
+		}
+	}
+	if len(bids) == 0 {
+		switch {
+		case b.TimedOut:
+			return fmt.Sprintf("%s timed out after %dms without bidding", bidder, b.LatencyMS)
+		case len(b.Errors) > 0:
+			return fmt.Sprintf("%s did not bid: %s", bidder, strings.Join(b.Errors, "; "))
+		case b.Bids > 0:
+			return fmt.Sprintf("%s bid, but the auction ended before bids were evaluated (%s)", bidder, t.Outcome)
+		default:
+			return fmt.Sprintf("%s did not bid", bidder)
+		}
+	}
+
+	parts := make([]string, 0, len(bids))
+	for _, bid := range bids {
+		part := fmt.Sprintf("bid %s on imp %s at %.2f %s", bid.BidID, bid.ImpID, bid.Price, bid.Outcome)
+		if bid.Reason != "" {
+			part += ": " + bid.Reason
+		}
+		parts = append(parts, part)
+	}
+	return fmt.Sprintf("%s %s", bidder, strings.Join(parts, "; "))
+}
@@ -0,0 +1,48 @@
+package auctiontrail
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTrail_Explain(t *testing.T) {
+	trail := &Trail{
+		Outcome: OutcomeFilled,
+		Bidders: []Bidder{
+			{Code: "appnexus", Called: true, Bids: 1},
+			{Code: "rubicon", Called: true, Bids: 2},
+			{Code: "pubmatic", Called: false, Reason: "excluded by IDR: LOW_SCORE"},
+			{Code: "openx", Called: true, TimedOut: true, LatencyMS: 800},
+			{Code: "triplelift", Called: true, Errors: []string{"bad response: 500"}},
+			{Code: "sovrn", Called: true},
+		},
+		Bids: []Bid{
+			{Bidder: "appnexus", BidID: "b1", ImpID: "1", Price: 2.5, Outcome: BidWon, Reason: "net 2.38, margin 0.12"},
+			{Bidder: "rubicon", BidID: "b2", ImpID: "1", Price: 1.2, Outcome: BidLost, Reason: "outbid by appnexus at 2.50"},
+			{Bidder: "rubicon", BidID: "b3", ImpID: "2", Price: 0.1, Outcome: BidRejected, Reason: "bid price below floor"},
+		},
+	}
+
+	tests := []struct {
+		bidder string
+		want   []string
+	}{
+		{"appnexus", []string{"bid b1 on imp 1 at 2.50 won: net 2.38"}},
+		{"rubicon", []string{"bid b2 on imp 1 at 1.20 lost: outbid by appnexus", "bid b3 on imp 2 at 0.10 rejected: bid price below floor"}},
+		{"pubmatic", []string{"not called: excluded by IDR"}},
+		{"openx", []string{"timed out after 800ms"}},
+		{"triplelift", []string{"did not bid: bad response: 500"}},
+		{"sovrn", []string{"sovrn did not bid"}},
+		{"unknown", []string{"not considered"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.bidder, func(t *testing.T) {
+			got := trail.Explain(tt.bidder)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("expected %q in %q", want, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+// Package bidcache stores bid markup (VAST XML or JSON) so players can fetch
+// it by UUID, with per-entry size limits and per-publisher storage quotas
+// enforced in Redis
+package bidcache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Entry types accepted by Put
+const (
+	TypeXML  = "xml"
+	TypeJSON = "json"
+)
+
+const (
+	// entryKeyPrefix namespaces cached markup
+	entryKeyPrefix = "tne_catalyst:cache:"
+	// usageKeyPrefix namespaces per-publisher usage counters
+	usageKeyPrefix = "tne_catalyst:cache_usage:"
+)
+
+var (
+	// ErrValueTooLarge is returned for entries above Config.MaxValueBytes
+	ErrValueTooLarge = errors.New("cache value too large")
+	// ErrQuotaExceeded is returned when a publisher's storage quota is used up
+	ErrQuotaExceeded = errors.New("publisher cache quota exceeded")
+	// ErrInvalidType is returned for entry types other than xml and json
+	ErrInvalidType = errors.New("cache entry type must be xml or json")
+)
+
+// reserveScript adds ARGV[1] bytes to the publisher's usage for the current
+// window unless that would take the current and previous windows over the
+// quota (ARGV[2]). Entries live at most one window, so the two windows bound
+// the bytes a publisher can hold. Returns the new usage, or -1 when rejected.
+const reserveScript = `
+local current = tonumber(redis.call('GET', KEYS[1]) or '0')
+local previous = tonumber(redis.call('GET', KEYS[2]) or '0')
+local size = tonumber(ARGV[1])
+if current + previous + size > tonumber(ARGV[2]) then
+  return -1
+end
+current = redis.call('INCRBY', KEYS[1], size)
+redis.call('EXPIRE', KEYS[1], ARGV[3])
+return current + previous
+`
+
+// Backend is the Redis subset the cache needs; implemented by redis.Client
+type Backend interface {
+	Get(ctx context.Context, key string) (string, error)
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
+	Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error)
+}
+
+// Metrics records cache storage consumption per publisher
+type Metrics interface {
+	RecordBidCacheWrite(publisherID string, bytes int, usage int64)
+	RecordBidCacheRejected(publisherID, reason string)
+}
+
+// Config limits what publishers can store
+type Config struct {
+	MaxValueBytes       int           // Largest accepted entry
+	PublisherQuotaBytes int64         // Bytes a publisher may hold at once
+	DefaultTTL          time.Duration // TTL when the caller doesn't set one
+	MaxTTL              time.Duration // Longest accepted TTL; longer requests are clamped
+	Compression         string        // CompressionZstd or CompressionNone
+	CompressionLevel    int           // LevelFastest through LevelBest
+	MinCompressBytes    int           // Smaller entries are stored uncompressed
+}
+
+// DefaultConfig returns the default cache limits
+func DefaultConfig() Config {
+	return Config{
+		MaxValueBytes:       64 * 1024,
+		PublisherQuotaBytes: 50 * 1024 * 1024,
+		DefaultTTL:          5 * time.Minute,
+		MaxTTL:              time.Hour,
+		Compression:         CompressionZstd,
+		CompressionLevel:    LevelDefault,
+		MinCompressBytes:    1024,
+	}
+}
+
+// Entry is a cached value and its type
+type Entry struct {
+	Type  string
+	Value []byte
+}
+
+// Cache stores entries in Redis on behalf of publishers
+type Cache struct {
+	backend Backend
+	config  Config
+	metrics Metrics
+	codec   *codec
+	now     func() time.Time
+}
+
+// New creates a cache; zero limits use DefaultConfig and metrics may be nil.
+// An empty Compression or out-of-range CompressionLevel uses the default.
+func New(backend Backend, config Config, metrics Metrics) *Cache {
+	defaults := DefaultConfig()
+	if config.MaxValueBytes <= 0 {
+		config.MaxValueBytes = defaults.MaxValueBytes
+	}
+	if config.PublisherQuotaBytes <= 0 {
+		config.PublisherQuotaBytes = defaults.PublisherQuotaBytes
+	}
+	if config.MaxTTL <= 0 {
+		config.MaxTTL = defaults.MaxTTL
+	}
+	if config.DefaultTTL <= 0 || config.DefaultTTL > config.MaxTTL {
+		config.DefaultTTL = min(defaults.DefaultTTL, config.MaxTTL)
+	}
+	if config.Compression == "" {
+		config.Compression = defaults.Compression
+	}
+	if config.CompressionLevel < LevelFastest || config.CompressionLevel > LevelBest {
+		config.CompressionLevel = defaults.CompressionLevel
+	}
+	if config.MinCompressBytes <= 0 {
+		config.MinCompressBytes = defaults.MinCompressBytes
+	}
+	return &Cache{
+		backend: backend,
+		config:  config,
+		metrics: metrics,
+		codec:   newCodec(config),
+		now:     time.Now,
+	}
+}
+
+// Config returns the cache limits
+func (c *Cache) Config() Config {
+	return c.config
+}
+
+// Check validates an entry against the per-entry limits without storing it
+func (c *Cache) Check(entry Entry) error {
+	if entry.Type != TypeXML && entry.Type != TypeJSON {
+		return ErrInvalidType
+	}
+	if len(entry.Value) > c.config.MaxValueBytes {
+		return fmt.Errorf("%w: %d bytes exceeds %d", ErrValueTooLarge, len(entry.Value), c.config.MaxValueBytes)
+	}
+	return nil
+}
+
+// Put stores an entry for the publisher and returns its UUID. ttl of 0 uses
+// the default; longer than MaxTTL is clamped. Large entries are compressed
+// and charged to the quota at their stored size.
+func (c *Cache) Put(ctx context.Context, publisherID string, entry Entry, ttl time.Duration) (string, error) {
+	if err := c.Check(entry); err != nil {
+		c.recordRejected(publisherID, err)
+		return "", err
+	}
+	if ttl <= 0 {
+		ttl = c.config.DefaultTTL
+	}
+	if ttl > c.config.MaxTTL {
+		ttl = c.config.MaxTTL
+	}
+
+	stored, size := c.codec.encode(entry)
+	usage, err := c.reserve(ctx, publisherID, size)
+	if err != nil {
+		c.recordRejected(publisherID, err)
+		return "", err
+	}
+
+	id, err := newUUID()
+	if err != nil {
+		return "", err
+	}
+	if err := c.backend.Set(ctx, entryKeyPrefix+id, stored, ttl); err != nil {
+		return "", fmt.Errorf("failed to store cache entry: %w", err)
+	}
+
+	if c.metrics != nil {
+		c.metrics.RecordBidCacheWrite(publisherID, size, usage)
+	}
+	return id, nil
+}
+
+// Get returns a stored entry, decompressed, or nil when it doesn't exist or
+// has expired
+func (c *Cache) Get(ctx context.Context, id string) (*Entry, error) {
+	value, err := c.backend.Get(ctx, entryKeyPrefix+id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache entry: %w", err)
+	}
+	return c.codec.decode(value)
+}
+
+// reserve charges size bytes to the publisher's quota and returns its usage
+func (c *Cache) reserve(ctx context.Context, publisherID string, size int) (int64, error) {
+	window := c.config.MaxTTL
+	bucket := c.now().Unix() / int64(window.Seconds())
+	keys := []string{usageKey(publisherID, bucket), usageKey(publisherID, bucket-1)}
+
+	result, err := c.backend.Eval(ctx, reserveScript, keys, size, c.config.PublisherQuotaBytes, int64(2*window.Seconds()))
+	if err != nil {
+		return 0, fmt.Errorf("failed to reserve cache quota: %w", err)
+	}
+	usage, ok := result.(int64)
+	if !ok {
+		return 0, fmt.Errorf("failed to reserve cache quota: unexpected result %v", result)
+	}
+	if usage < 0 {
+		return 0, ErrQuotaExceeded
+	}
+	return usage, nil
+}
+
+// usageKey is the publisher's usage counter for a window. The publisher ID is
+// a hash tag so both windows reserveScript reads hash to the same Redis
+// Cluster slot.
+func usageKey(publisherID string, bucket int64) string {
+	return fmt.Sprintf("%s{%s}:%d", usageKeyPrefix, publisherID, bucket)
+}
+
+func (c *Cache) recordRejected(publisherID string, err error) {
+	if c.metrics == nil {
+		return
+	}
+	reason := "error"
+	switch {
+	case errors.Is(err, ErrValueTooLarge):
+		reason = "too_large"
+	case errors.Is(err, ErrQuotaExceeded):
+		reason = "quota_exceeded"
+	case errors.Is(err, ErrInvalidType):
+		reason = "invalid_type"
+	}
+	c.metrics.RecordBidCacheRejected(publisherID, reason)
+}
+
+// newUUID returns a random RFC 4122 version 4 UUID
+func newUUID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate cache id: %w", err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	h := hex.EncodeToString(b)
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:], nil
+}
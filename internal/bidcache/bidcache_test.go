@@ -0,0 +1,174 @@
+package bidcache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"github.com/thenexusengine/tne_springwire/pkg/redis"
+)
+
+type mockMetrics struct {
+	written  map[string]int
+	usage    map[string]int64
+	rejected map[string]int
+}
+
+func (m *mockMetrics) RecordBidCacheWrite(publisherID string, bytes int, usage int64) {
+	if m.written == nil {
+		m.written = make(map[string]int)
+		m.usage = make(map[string]int64)
+	}
+	m.written[publisherID] += bytes
+	m.usage[publisherID] = usage
+}
+
+func (m *mockMetrics) RecordBidCacheRejected(publisherID, reason string) {
+	if m.rejected == nil {
+		m.rejected = make(map[string]int)
+	}
+	m.rejected[publisherID+":"+reason]++
+}
+
+func newTestCache(t *testing.T, cfg Config) (*Cache, *miniredis.Miniredis, *mockMetrics) {
+	t.Helper()
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("failed to start miniredis: %v", err)
+	}
+	t.Cleanup(mr.Close)
+
+	client, err := redis.New("redis://" + mr.Addr())
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	metrics := &mockMetrics{}
+	return New(client, cfg, metrics), mr, metrics
+}
+
+func TestCache_PutGet(t *testing.T) {
+	cache, mr, metrics := newTestCache(t, DefaultConfig())
+	ctx := context.Background()
+
+	id, err := cache.Put(ctx, "pub-1", Entry{Type: TypeXML, Value: []byte("<VAST/>")}, 0)
+	if err != nil {
+		t.Fatalf("put failed: %v", err)
+	}
+	if len(id) != 36 {
+		t.Errorf("expected a UUID, got %q", id)
+	}
+	if ttl := mr.TTL(entryKeyPrefix + id); ttl != DefaultConfig().DefaultTTL {
+		t.Errorf("expected default TTL, got %v", ttl)
+	}
+
+	entry, err := cache.Get(ctx, id)
+	if err != nil || entry == nil {
+		t.Fatalf("get failed: %v, %v", entry, err)
+	}
+	if entry.Type != TypeXML || string(entry.Value) != "<VAST/>" {
+		t.Errorf("unexpected entry: %s %s", entry.Type, entry.Value)
+	}
+	if metrics.written["pub-1"] != 7 || metrics.usage["pub-1"] != 7 {
+		t.Errorf("expected 7 bytes recorded, got %v %v", metrics.written, metrics.usage)
+	}
+
+	if entry, err := cache.Get(ctx, "missing"); err != nil || entry != nil {
+		t.Errorf("expected nil for missing entry, got %v, %v", entry, err)
+	}
+}
+
+func TestCache_ClampsTTL(t *testing.T) {
+	cache, mr, _ := newTestCache(t, DefaultConfig())
+
+	id, err := cache.Put(context.Background(), "pub-1", Entry{Type: TypeJSON, Value: []byte(`{}`)}, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("put failed: %v", err)
+	}
+	if ttl := mr.TTL(entryKeyPrefix + id); ttl != time.Hour {
+		t.Errorf("expected TTL clamped to 1h, got %v", ttl)
+	}
+}
+
+func TestCache_Limits(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.MaxValueBytes = 10
+	cfg.PublisherQuotaBytes = 25
+	cache, _, metrics := newTestCache(t, cfg)
+	ctx := context.Background()
+	value := []byte("0123456789")
+
+	if _, err := cache.Put(ctx, "pub-1", Entry{Type: TypeXML, Value: []byte("01234567890")}, 0); !errors.Is(err, ErrValueTooLarge) {
+		t.Errorf("expected ErrValueTooLarge, got %v", err)
+	}
+	if _, err := cache.Put(ctx, "pub-1", Entry{Type: "html", Value: value}, 0); !errors.Is(err, ErrInvalidType) {
+		t.Errorf("expected ErrInvalidType, got %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := cache.Put(ctx, "pub-1", Entry{Type: TypeXML, Value: value}, 0); err != nil {
+			t.Fatalf("put %d failed: %v", i, err)
+		}
+	}
+	if _, err := cache.Put(ctx, "pub-1", Entry{Type: TypeXML, Value: value}, 0); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("expected ErrQuotaExceeded, got %v", err)
+	}
+
+	// Quotas are per publisher
+	if _, err := cache.Put(ctx, "pub-2", Entry{Type: TypeXML, Value: value}, 0); err != nil {
+		t.Errorf("expected pub-2 to have its own quota, got %v", err)
+	}
+
+	if metrics.rejected["pub-1:too_large"] != 1 || metrics.rejected["pub-1:quota_exceeded"] != 1 || metrics.rejected["pub-1:invalid_type"] != 1 {
+		t.Errorf("unexpected rejections: %v", metrics.rejected)
+	}
+}
+
+func TestCache_QuotaFreesAfterTwoWindows(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.PublisherQuotaBytes = 10
+	cache, _, _ := newTestCache(t, cfg)
+	ctx := context.Background()
+	value := []byte("0123456789")
+
+	now := time.Unix(1_700_000_000, 0)
+	cache.now = func() time.Time { return now }
+
+	if _, err := cache.Put(ctx, "pub-1", Entry{Type: TypeXML, Value: value}, 0); err != nil {
+		t.Fatalf("put failed: %v", err)
+	}
+
+	// Entries from the previous window may still be live
+	now = now.Add(cfg.MaxTTL)
+	if _, err := cache.Put(ctx, "pub-1", Entry{Type: TypeXML, Value: value}, 0); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("expected ErrQuotaExceeded in the next window, got %v", err)
+	}
+
+	// Two windows later every earlier entry has expired
+	now = now.Add(cfg.MaxTTL)
+	if _, err := cache.Put(ctx, "pub-1", Entry{Type: TypeXML, Value: value}, 0); err != nil {
+		t.Errorf("expected quota freed after two windows, got %v", err)
+	}
+}
+
+func TestUsageKey_SameClusterSlot(t *testing.T) {
+	// Redis Cluster hashes only the part of a key inside the first {...}
+	hashTag := func(key string) string {
+		start := strings.Index(key, "{")
+		end := strings.Index(key[start+1:], "}")
+		if start < 0 || end <= 0 {
+			return key
+		}
+		return key[start+1 : start+1+end]
+	}
+
+	current, previous := usageKey("pub-1", 100), usageKey("pub-1", 99)
+	if current == previous || hashTag(current) != "pub-1" || hashTag(previous) != "pub-1" {
+		t.Errorf("expected distinct window keys tagged with the publisher, got %q and %q", current, previous)
+	}
+}
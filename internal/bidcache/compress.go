@@ -0,0 +1,92 @@
+package bidcache
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression settings for Config.Compression
+const (
+	CompressionNone = "none"
+	CompressionZstd = "zstd"
+)
+
+// Compression levels for Config.CompressionLevel, fastest to smallest; they
+// match zstd's encoder levels
+const (
+	LevelFastest = 1
+	LevelDefault = 2
+	LevelBetter  = 3
+	LevelBest    = 4
+)
+
+// encodingZstd marks a zstd-compressed value. Stored values are
+// "<type>[+<encoding>]:<payload>", so entries written before compression
+// (or below MinCompressBytes) read back unchanged.
+const encodingZstd = "zstd"
+
+// minDecodedLimit bounds decompressed entries when MaxValueBytes is smaller,
+// so entries written under an older, larger limit still read back
+const minDecodedLimit = 16 << 20
+
+// codec compresses values on write and decompresses them on read
+type codec struct {
+	encoder  *zstd.Encoder // nil when compression is off
+	decoder  *zstd.Decoder
+	minBytes int
+}
+
+// newCodec creates the codec for a normalized config. The zstd options are
+// fixed, so construction can't fail in practice; if it does, values are
+// stored uncompressed and compressed entries fail to read.
+func newCodec(config Config) *codec {
+	limit := uint64(max(config.MaxValueBytes, minDecodedLimit))
+	c := &codec{minBytes: config.MinCompressBytes}
+	c.decoder, _ = zstd.NewReader(nil, zstd.WithDecoderMaxMemory(limit), zstd.WithDecoderConcurrency(0))
+	if config.Compression == CompressionZstd {
+		c.encoder, _ = zstd.NewWriter(nil,
+			zstd.WithEncoderLevel(zstd.EncoderLevel(config.CompressionLevel)),
+			zstd.WithEncoderConcurrency(1))
+	}
+	return c
+}
+
+// encode returns the stored form of an entry and the size of its payload
+func (c *codec) encode(entry Entry) (string, int) {
+	if c.encoder != nil && len(entry.Value) >= c.minBytes {
+		compressed := c.encoder.EncodeAll(entry.Value, make([]byte, 0, len(entry.Value)/2))
+		// Small or already-compressed payloads can grow; keep the smaller form
+		if len(compressed) < len(entry.Value) {
+			return entry.Type + "+" + encodingZstd + ":" + string(compressed), len(compressed)
+		}
+	}
+	return entry.Type + ":" + string(entry.Value), len(entry.Value)
+}
+
+// decode parses a stored value, returning nil for values with no type
+func (c *codec) decode(value string) (*Entry, error) {
+	header, payload, ok := strings.Cut(value, ":")
+	if !ok {
+		return nil, nil
+	}
+	entryType, encoding, _ := strings.Cut(header, "+")
+
+	switch encoding {
+	case "":
+		return &Entry{Type: entryType, Value: []byte(payload)}, nil
+	case encodingZstd:
+		if c.decoder == nil {
+			return nil, errors.New("failed to decompress cache entry: no zstd decoder")
+		}
+		decoded, err := c.decoder.DecodeAll([]byte(payload), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress cache entry: %w", err)
+		}
+		return &Entry{Type: entryType, Value: decoded}, nil
+	default:
+		return nil, fmt.Errorf("unknown cache entry encoding %q", encoding)
+	}
+}
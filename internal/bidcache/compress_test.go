@@ -0,0 +1,140 @@
+package bidcache
+
+import (
+	"context"
+	"crypto/rand"
+	"strings"
+	"testing"
+)
+
+// vastDocument returns a repetitive VAST payload like large CTV pods
+func vastDocument(ads int) []byte {
+	var b strings.Builder
+	b.WriteString(`<VAST version="4.0">`)
+	for i := 0; i < ads; i++ {
+		b.WriteString(`<Ad id="ad"><InLine><AdSystem>TNE</AdSystem><Impression><![CDATA[https://catalyst.springwire.ai/event/imp]]></Impression>`)
+		b.WriteString(`<Creatives><Creative><Linear><Duration>00:00:30</Duration><MediaFiles><MediaFile delivery="progressive" type="video/mp4" width="1920" height="1080"><![CDATA[https://cdn.example.com/creative.mp4]]></MediaFile></MediaFiles></Linear></Creative></Creatives></InLine></Ad>`)
+	}
+	b.WriteString(`</VAST>`)
+	return []byte(b.String())
+}
+
+func TestCache_CompressesLargeEntries(t *testing.T) {
+	cache, mr, metrics := newTestCache(t, DefaultConfig())
+	ctx := context.Background()
+	value := vastDocument(20)
+
+	id, err := cache.Put(ctx, "pub-1", Entry{Type: TypeXML, Value: value}, 0)
+	if err != nil {
+		t.Fatalf("put failed: %v", err)
+	}
+
+	stored, err := mr.Get(entryKeyPrefix + id)
+	if err != nil {
+		t.Fatalf("entry not stored: %v", err)
+	}
+	if !strings.HasPrefix(stored, TypeXML+"+zstd:") {
+		t.Fatalf("expected a zstd entry, got prefix %q", stored[:min(len(stored), 16)])
+	}
+	if len(stored) >= len(value)/2 {
+		t.Errorf("expected at least 2x compression, stored %d of %d bytes", len(stored), len(value))
+	}
+	if size := len(stored) - len(TypeXML+"+zstd:"); metrics.written["pub-1"] != size {
+		t.Errorf("expected %d compressed bytes recorded, got %d", size, metrics.written["pub-1"])
+	}
+
+	entry, err := cache.Get(ctx, id)
+	if err != nil || entry == nil {
+		t.Fatalf("get failed: %v, %v", entry, err)
+	}
+	if entry.Type != TypeXML || string(entry.Value) != string(value) {
+		t.Errorf("round trip mismatch: type %s, %d bytes", entry.Type, len(entry.Value))
+	}
+}
+
+func TestCache_StoresUncompressed(t *testing.T) {
+	random := make([]byte, 4096)
+	if _, err := rand.Read(random); err != nil {
+		t.Fatal(err)
+	}
+
+	off := DefaultConfig()
+	off.Compression = CompressionNone
+
+	tests := []struct {
+		name   string
+		config Config
+		value  []byte
+	}{
+		{"below minimum size", DefaultConfig(), []byte("<VAST/>")},
+		{"compression off", off, vastDocument(20)},
+		{"incompressible", DefaultConfig(), random},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache, mr, _ := newTestCache(t, tt.config)
+			ctx := context.Background()
+
+			id, err := cache.Put(ctx, "pub-1", Entry{Type: TypeJSON, Value: tt.value}, 0)
+			if err != nil {
+				t.Fatalf("put failed: %v", err)
+			}
+			if stored, _ := mr.Get(entryKeyPrefix + id); stored != TypeJSON+":"+string(tt.value) {
+				t.Errorf("expected the value stored as-is")
+			}
+			if entry, err := cache.Get(ctx, id); err != nil || string(entry.Value) != string(tt.value) {
+				t.Errorf("round trip failed: %v", err)
+			}
+		})
+	}
+}
+
+func TestCache_ReadsAcrossCompressionSettings(t *testing.T) {
+	cache, mr, _ := newTestCache(t, DefaultConfig())
+	ctx := context.Background()
+	value := vastDocument(20)
+
+	id, err := cache.Put(ctx, "pub-1", Entry{Type: TypeXML, Value: value}, 0)
+	if err != nil {
+		t.Fatalf("put failed: %v", err)
+	}
+
+	// A replica with compression turned off still reads compressed entries
+	off := DefaultConfig()
+	off.Compression = CompressionNone
+	reader := New(cache.backend, off, nil)
+	if entry, err := reader.Get(ctx, id); err != nil || string(entry.Value) != string(value) {
+		t.Errorf("expected compressed entry to read back with compression off: %v", err)
+	}
+
+	mr.Set(entryKeyPrefix+"legacy", "xml:<VAST/>")
+	if entry, err := cache.Get(ctx, "legacy"); err != nil || entry.Type != TypeXML || string(entry.Value) != "<VAST/>" {
+		t.Errorf("expected legacy entry to read back, got %v, %v", entry, err)
+	}
+
+	mr.Set(entryKeyPrefix+"brotli", "xml+br:data")
+	if _, err := cache.Get(ctx, "brotli"); err == nil || !strings.Contains(err.Error(), "unknown cache entry encoding") {
+		t.Errorf("expected unknown encoding error, got %v", err)
+	}
+
+	mr.Set(entryKeyPrefix+"corrupt", "xml+zstd:not zstd")
+	if _, err := cache.Get(ctx, "corrupt"); err == nil {
+		t.Error("expected corrupt entry to fail")
+	}
+}
+
+func TestCache_CompressionLevels(t *testing.T) {
+	value := vastDocument(50)
+	for level := LevelFastest; level <= LevelBest; level++ {
+		cfg := DefaultConfig()
+		cfg.CompressionLevel = level
+		cache, _, _ := newTestCache(t, cfg)
+
+		stored, _ := cache.codec.encode(Entry{Type: TypeXML, Value: value})
+		entry, err := cache.codec.decode(stored)
+		if err != nil || string(entry.Value) != string(value) {
+			t.Errorf("level %d: round trip failed: %v", level, err)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+// Package chaos injects latency, errors and dropped responses into calls to
+// bidders and dependencies, so circuit breakers and degradation paths can be
+// rehearsed before a real incident. Faults are set at runtime through the
+// admin API, live in this replica's memory and expire on their own. The
+// injector is never created in production.
+package chaos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/pkg/deadline"
+)
+
+// Fault scopes
+const (
+	ScopeBidder     = "bidder"
+	ScopeDependency = "dependency"
+)
+
+// Dependencies lists the dependencies whose calls consult the injector; a
+// fault on any other would be accepted but never fire
+var Dependencies = []string{deadline.DependencyIDR}
+
+// MaxFaultTTL bounds how long a fault stays active
+const MaxFaultTTL = time.Hour
+
+// ErrInjected is returned for calls failed by an error fault
+var ErrInjected = errors.New("chaos: injected error")
+
+// Fault describes what to inject into calls to one bidder or dependency.
+// Rates are fractions of calls (0-1) and are rolled independently: a call
+// may be delayed and then dropped or failed.
+type Fault struct {
+	Scope string
+	ID    string
+
+	// Latency delays LatencyRate of calls before they are made
+	Latency     time.Duration
+	LatencyRate float64
+
+	// ErrorRate of calls fail immediately with ErrInjected
+	ErrorRate float64
+
+	// DropRate of calls never get a response: they block until the caller's
+	// deadline and fail with its error, like an unresponsive bidder
+	DropRate float64
+
+	ExpiresAt time.Time
+}
+
+// Validate checks the fault's target and rates
+func (f Fault) Validate() error {
+	if f.Scope != ScopeBidder && f.Scope != ScopeDependency {
+		return fmt.Errorf("scope must be %s or %s", ScopeBidder, ScopeDependency)
+	}
+	if f.ID == "" {
+		return errors.New("id is required")
+	}
+	if f.Scope == ScopeDependency && !slices.Contains(Dependencies, f.ID) {
+		return fmt.Errorf("dependency must be one of %s, got %q", strings.Join(Dependencies, ", "), f.ID)
+	}
+	if f.Latency < 0 {
+		return errors.New("latency must not be negative")
+	}
+	for name, rate := range map[string]float64{
+		"latency_rate": f.LatencyRate,
+		"error_rate":   f.ErrorRate,
+		"drop_rate":    f.DropRate,
+	} {
+		if rate < 0 || rate > 1 {
+			return fmt.Errorf("%s must be between 0 and 1", name)
+		}
+	}
+	if f.Latency == 0 && f.ErrorRate == 0 && f.DropRate == 0 {
+		return errors.New("fault injects nothing; set latency, error_rate or drop_rate")
+	}
+	return nil
+}
+
+// Injector holds the active faults
+type Injector struct {
+	mu     sync.RWMutex
+	faults map[string]Fault // by scope:id
+
+	now  func() time.Time
+	roll func() float64
+}
+
+// New creates an injector with no active faults
+func New() *Injector {
+	return &Injector{
+		faults: make(map[string]Fault),
+		now:    time.Now,
+		roll:   rand.Float64, // #nosec G404 -- fault sampling, not security
+	}
+}
+
+func faultKey(scope, id string) string {
+	return scope + ":" + id
+}
+
+// Set activates f for ttl, replacing any fault on the same target. A fault
+// with Latency but no LatencyRate delays every call.
+func (i *Injector) Set(f Fault, ttl time.Duration) (Fault, error) {
+	if ttl <= 0 || ttl > MaxFaultTTL {
+		return Fault{}, fmt.Errorf("ttl must be between 1s and %s", MaxFaultTTL)
+	}
+	if f.Latency > 0 && f.LatencyRate == 0 {
+		f.LatencyRate = 1
+	}
+	if err := f.Validate(); err != nil {
+		return Fault{}, err
+	}
+	f.ExpiresAt = i.now().Add(ttl)
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.faults[faultKey(f.Scope, f.ID)] = f
+	return f, nil
+}
+
+// Clear removes the fault on a target, reporting whether one was active
+func (i *Injector) Clear(scope, id string) bool {
+	key := faultKey(scope, id)
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	f, ok := i.faults[key]
+	delete(i.faults, key)
+	return ok && i.now().Before(f.ExpiresAt)
+}
+
+// Faults returns the active faults ordered by scope and ID
+func (i *Injector) Faults() []Fault {
+	now := i.now()
+
+	i.mu.RLock()
+	active := make([]Fault, 0, len(i.faults))
+	for _, f := range i.faults {
+		if now.Before(f.ExpiresAt) {
+			active = append(active, f)
+		}
+	}
+	i.mu.RUnlock()
+
+	sort.Slice(active, func(a, b int) bool {
+		if active[a].Scope != active[b].Scope {
+			return active[a].Scope < active[b].Scope
+		}
+		return active[a].ID < active[b].ID
+	})
+	return active
+}
+
+// InjectBidder applies the fault on a bidder, if any, before calling it
+func (i *Injector) InjectBidder(ctx context.Context, bidder string) error {
+	return i.inject(ctx, ScopeBidder, bidder)
+}
+
+// InjectDependency applies the fault on a dependency (see the deadline
+// package's dependency names), if any, before calling it
+func (i *Injector) InjectDependency(ctx context.Context, dependency string) error {
+	return i.inject(ctx, ScopeDependency, dependency)
+}
+
+// inject delays, drops or fails the call per the target's fault. A nil
+// error means the call should proceed.
+func (i *Injector) inject(ctx context.Context, scope, id string) error {
+	if i == nil {
+		return nil
+	}
+	key := faultKey(scope, id)
+
+	i.mu.RLock()
+	f, ok := i.faults[key]
+	i.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+	if !i.now().Before(f.ExpiresAt) {
+		i.mu.Lock()
+		if cur, ok := i.faults[key]; ok && cur.ExpiresAt.Equal(f.ExpiresAt) {
+			delete(i.faults, key)
+		}
+		i.mu.Unlock()
+		return nil
+	}
+
+	if f.Latency > 0 && i.roll() < f.LatencyRate {
+		timer := time.NewTimer(f.Latency)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+	if f.DropRate > 0 && i.roll() < f.DropRate {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	if f.ErrorRate > 0 && i.roll() < f.ErrorRate {
+		return fmt.Errorf("%w for %s %s", ErrInjected, scope, id)
+	}
+	return nil
+}
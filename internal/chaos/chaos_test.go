@@ -0,0 +1,150 @@
+package chaos
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestInjector(roll float64) (*Injector, *time.Time) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	i := New()
+	i.now = func() time.Time { return now }
+	i.roll = func() float64 { return roll }
+	return i, &now
+}
+
+func TestSet_Validation(t *testing.T) {
+	i, _ := newTestInjector(0)
+	tests := []struct {
+		name  string
+		fault Fault
+		ttl   time.Duration
+	}{
+		{"unknown scope", Fault{Scope: "publisher", ID: "pub-1", ErrorRate: 1}, time.Minute},
+		{"missing id", Fault{Scope: ScopeBidder, ErrorRate: 1}, time.Minute},
+		{"dependency without a hook", Fault{Scope: ScopeDependency, ID: "redis", ErrorRate: 1}, time.Minute},
+		{"rate above one", Fault{Scope: ScopeBidder, ID: "rubicon", DropRate: 1.5}, time.Minute},
+		{"negative latency", Fault{Scope: ScopeBidder, ID: "rubicon", Latency: -time.Second}, time.Minute},
+		{"injects nothing", Fault{Scope: ScopeBidder, ID: "rubicon"}, time.Minute},
+		{"ttl too long", Fault{Scope: ScopeBidder, ID: "rubicon", ErrorRate: 1}, 2 * time.Hour},
+		{"no ttl", Fault{Scope: ScopeBidder, ID: "rubicon", ErrorRate: 1}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := i.Set(tt.fault, tt.ttl); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+	if len(i.Faults()) != 0 {
+		t.Error("invalid faults must not be stored")
+	}
+}
+
+func TestInject_Error(t *testing.T) {
+	i, _ := newTestInjector(0.2)
+	if _, err := i.Set(Fault{Scope: ScopeBidder, ID: "rubicon", ErrorRate: 0.5}, time.Minute); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	if err := i.InjectBidder(context.Background(), "rubicon"); !errors.Is(err, ErrInjected) {
+		t.Errorf("expected ErrInjected, got %v", err)
+	}
+	if err := i.InjectBidder(context.Background(), "appnexus"); err != nil {
+		t.Errorf("expected other bidders untouched, got %v", err)
+	}
+	if err := i.InjectDependency(context.Background(), "rubicon"); err != nil {
+		t.Errorf("expected scopes kept apart, got %v", err)
+	}
+
+	i.roll = func() float64 { return 0.7 }
+	if err := i.InjectBidder(context.Background(), "rubicon"); err != nil {
+		t.Errorf("expected call outside the error rate to proceed, got %v", err)
+	}
+}
+
+func TestInject_Drop(t *testing.T) {
+	i, _ := newTestInjector(0)
+	if _, err := i.Set(Fault{Scope: ScopeDependency, ID: "idr", DropRate: 1}, time.Minute); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := i.InjectDependency(ctx, "idr")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("expected drop to hold until the deadline, returned after %v", elapsed)
+	}
+}
+
+func TestInject_Latency(t *testing.T) {
+	i, _ := newTestInjector(0)
+	f, err := i.Set(Fault{Scope: ScopeBidder, ID: "rubicon", Latency: 30 * time.Millisecond}, time.Minute)
+	if err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if f.LatencyRate != 1 {
+		t.Errorf("expected latency without a rate to apply to every call, got rate %v", f.LatencyRate)
+	}
+
+	start := time.Now()
+	if err := i.InjectBidder(context.Background(), "rubicon"); err != nil {
+		t.Errorf("expected delayed call to proceed, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("expected 30ms delay, got %v", elapsed)
+	}
+
+	// The caller's deadline wins over injected latency
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	if err := i.InjectBidder(ctx, "rubicon"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestFaults_ExpiryAndClear(t *testing.T) {
+	i, now := newTestInjector(0)
+	for _, f := range []Fault{
+		{Scope: ScopeDependency, ID: "idr", ErrorRate: 1},
+		{Scope: ScopeBidder, ID: "rubicon", ErrorRate: 1},
+		{Scope: ScopeBidder, ID: "appnexus", ErrorRate: 1},
+	} {
+		if _, err := i.Set(f, time.Minute); err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+	}
+
+	faults := i.Faults()
+	if len(faults) != 3 || faults[0].ID != "appnexus" || faults[1].ID != "rubicon" || faults[2].ID != "idr" {
+		t.Fatalf("expected faults ordered by scope and id, got %+v", faults)
+	}
+
+	if !i.Clear(ScopeBidder, "rubicon") {
+		t.Error("expected Clear to report the active fault")
+	}
+	if i.Clear(ScopeBidder, "rubicon") {
+		t.Error("expected second Clear to report nothing")
+	}
+
+	*now = now.Add(2 * time.Minute)
+	if len(i.Faults()) != 0 {
+		t.Error("expected faults to expire")
+	}
+	if err := i.InjectBidder(context.Background(), "appnexus"); err != nil {
+		t.Errorf("expected expired fault to be ignored, got %v", err)
+	}
+}
+
+func TestInject_NilInjector(t *testing.T) {
+	var i *Injector
+	if err := i.InjectBidder(context.Background(), "rubicon"); err != nil {
+		t.Errorf("expected nil injector to inject nothing, got %v", err)
+	}
+}
@@ -0,0 +1,269 @@
+// Package creatives registers the creatives bidders return for publishers
+// with an approval policy, keyed by hash, so they can be reviewed: approved
+// creatives serve everywhere, blocked ones nowhere, and new ones according
+// to each publisher's approval policy.
+package creatives
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+// DefaultInterval registers new creatives and reloads statuses every minute,
+// which bounds how long a review on one instance takes to reach the others
+const DefaultInterval = time.Minute
+
+// maxMarkupBytes caps the markup stored for review
+const maxMarkupBytes = 16 << 10
+
+// maxPending caps the creatives queued for registration between flushes;
+// creatives seen once the queue is full are queued when next seen
+const maxPending = 10000
+
+// maxRegistered caps the hashes remembered as already in the store. Past it
+// the set starts over, and creatives are re-inserted (a no-op) when next seen.
+const maxRegistered = 100000
+
+// Store persists creatives and their review status; implemented by
+// storage.CreativeStore. LoadStatuses returns reviewed creatives only.
+type Store interface {
+	LoadStatuses(ctx context.Context) (map[string]string, error)
+	InsertNew(ctx context.Context, creatives []*storage.Creative) error
+	SetStatus(ctx context.Context, hashes []string, status string) error
+	ListPage(ctx context.Context, opts storage.ListOptions) ([]*storage.Creative, int, error)
+}
+
+// Config controls how often the registry syncs with the store
+type Config struct {
+	Interval time.Duration // 0 uses DefaultInterval
+}
+
+// Registry caches the status of reviewed creatives and queues newly seen
+// ones for the store. It implements exchange.CreativeRegistry.
+type Registry struct {
+	store Store
+	cfg   Config
+
+	mu         sync.RWMutex
+	statuses   map[string]string            // approved or blocked, by hash
+	pending    map[string]*storage.Creative // seen, waiting to be inserted
+	registered map[string]struct{}          // inserted by this instance
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewRegistry creates a registry for store. Every creative is new until
+// Start loads the statuses.
+func NewRegistry(store Store, cfg Config) *Registry {
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultInterval
+	}
+	return &Registry{
+		store:      store,
+		cfg:        cfg,
+		statuses:   make(map[string]string),
+		pending:    make(map[string]*storage.Creative),
+		registered: make(map[string]struct{}),
+		stopCh:     make(chan struct{}),
+	}
+}
+
+// Hash identifies a bid's creative by bidder and crid, or returns "" for
+// bids without a crid. Markup isn't hashed: it usually embeds
+// per-impression macros and tracking IDs, so every bid would be a new
+// creative.
+func Hash(bidderCode string, bid *openrtb.Bid) string {
+	if bid.CRID == "" {
+		return ""
+	}
+	h := sha256.Sum256([]byte("crid\x00" + bidderCode + "\x00" + bid.CRID))
+	return hex.EncodeToString(h[:])
+}
+
+// Review implements exchange.CreativeRegistry. Creatives without a reviewed
+// status are new; unseen ones are queued for the store when register is
+// set. Bids without a crid have no hash and are never registered.
+func (r *Registry) Review(bidderCode string, bid *openrtb.Bid, register bool) (hash, status string) {
+	hash = Hash(bidderCode, bid)
+	if hash == "" {
+		return "", storage.CreativeStatusNew
+	}
+
+	r.mu.RLock()
+	status, ok := r.statuses[hash]
+	_, registered := r.registered[hash]
+	r.mu.RUnlock()
+	if ok {
+		return hash, status
+	}
+	if !register || registered {
+		return hash, storage.CreativeStatusNew
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if status, ok := r.statuses[hash]; ok {
+		return hash, status
+	}
+	if _, queued := r.pending[hash]; !queued && len(r.pending) < maxPending {
+		r.pending[hash] = &storage.Creative{
+			Hash:       hash,
+			Status:     storage.CreativeStatusNew,
+			BidderCode: bidderCode,
+			CreativeID: bid.CRID,
+			ADomain:    strings.Join(bid.ADomain, ","),
+			Markup:     truncateMarkup(bid.AdM),
+		}
+	}
+	return hash, storage.CreativeStatusNew
+}
+
+// truncateMarkup cuts markup to maxMarkupBytes on a UTF-8 boundary
+func truncateMarkup(markup string) string {
+	if len(markup) <= maxMarkupBytes {
+		return markup
+	}
+	n := maxMarkupBytes
+	for n > 0 && !utf8.RuneStart(markup[n]) {
+		n--
+	}
+	return markup[:n]
+}
+
+// SetStatus reviews creatives by hash. The change applies on this instance
+// immediately and on the others at their next flush.
+func (r *Registry) SetStatus(ctx context.Context, hashes []string, status string) error {
+	if err := r.store.SetStatus(ctx, hashes, status); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, hash := range hashes {
+		if status == storage.CreativeStatusNew {
+			delete(r.statuses, hash)
+		} else {
+			r.statuses[hash] = status
+		}
+		delete(r.pending, hash)
+		r.markRegistered(hash)
+	}
+	return nil
+}
+
+// markRegistered remembers that hash is in the store; callers hold r.mu
+func (r *Registry) markRegistered(hash string) {
+	if len(r.registered) >= maxRegistered {
+		r.registered = make(map[string]struct{})
+	}
+	r.registered[hash] = struct{}{}
+}
+
+// ListPage returns a page of registered creatives from the store
+func (r *Registry) ListPage(ctx context.Context, opts storage.ListOptions) ([]*storage.Creative, int, error) {
+	return r.store.ListPage(ctx, opts)
+}
+
+// Start loads the statuses, then flushes every interval until Stop
+func (r *Registry) Start() {
+	r.flushLogged()
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(r.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.stopCh:
+				return
+			case <-ticker.C:
+				r.flushLogged()
+			}
+		}
+	}()
+}
+
+// flushLogged runs one flush, logging failures
+func (r *Registry) flushLogged() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := r.Flush(ctx); err != nil {
+		logger.Log.Warn().Err(err).Msg("Creative registry flush failed, will retry next interval")
+	}
+}
+
+// Stop ends periodic flushes and registers the creatives still queued
+func (r *Registry) Stop(ctx context.Context) error {
+	r.stopOnce.Do(func() { close(r.stopCh) })
+	r.wg.Wait()
+	return r.Flush(ctx)
+}
+
+// Flush registers the queued creatives, then reloads the reviewed statuses. Creatives
+// that fail to register stay queued for the next flush; statuses are still
+// reloaded.
+func (r *Registry) Flush(ctx context.Context) error {
+	r.mu.Lock()
+	queued := make([]*storage.Creative, 0, len(r.pending))
+	for _, c := range r.pending {
+		queued = append(queued, c)
+	}
+	r.pending = make(map[string]*storage.Creative)
+	r.mu.Unlock()
+
+	writeErr := r.store.InsertNew(ctx, queued)
+	if writeErr != nil {
+		r.requeue(queued)
+		writeErr = fmt.Errorf("failed to register creatives: %w", writeErr)
+	} else {
+		r.mu.Lock()
+		for _, c := range queued {
+			r.markRegistered(c.Hash)
+		}
+		r.mu.Unlock()
+	}
+
+	statuses, err := r.store.LoadStatuses(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load creative statuses: %w", err)
+	}
+	r.mu.Lock()
+	r.statuses = statuses
+	r.mu.Unlock()
+	if writeErr != nil {
+		return writeErr
+	}
+
+	logger.Log.Debug().
+		Int("reviewed", len(statuses)).
+		Int("registered", len(queued)).
+		Msg("Creative registry flushed")
+	return nil
+}
+
+// requeue puts creatives that failed to register back in the queue, up to
+// maxPending
+func (r *Registry) requeue(creatives []*storage.Creative) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, c := range creatives {
+		if len(r.pending) >= maxPending {
+			return
+		}
+		if _, queued := r.pending[c.Hash]; !queued {
+			r.pending[c.Hash] = c
+		}
+	}
+}
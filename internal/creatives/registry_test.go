@@ -0,0 +1,200 @@
+package creatives
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+)
+
+// memoryStore keeps creatives in memory, shared between registries like the
+// creatives table between instances
+type memoryStore struct {
+	creatives map[string]*storage.Creative
+	writeErr  error
+	inserts   int
+}
+
+func (s *memoryStore) LoadStatuses(_ context.Context) (map[string]string, error) {
+	statuses := make(map[string]string)
+	for hash, c := range s.creatives {
+		if c.Status != storage.CreativeStatusNew {
+			statuses[hash] = c.Status
+		}
+	}
+	return statuses, nil
+}
+
+func (s *memoryStore) InsertNew(_ context.Context, creatives []*storage.Creative) error {
+	if s.writeErr != nil {
+		return s.writeErr
+	}
+	s.inserts += len(creatives)
+	if s.creatives == nil {
+		s.creatives = make(map[string]*storage.Creative)
+	}
+	for _, c := range creatives {
+		if _, ok := s.creatives[c.Hash]; !ok {
+			s.creatives[c.Hash] = c
+		}
+	}
+	return nil
+}
+
+func (s *memoryStore) SetStatus(_ context.Context, hashes []string, status string) error {
+	if s.creatives == nil {
+		s.creatives = make(map[string]*storage.Creative)
+	}
+	for _, hash := range hashes {
+		if c, ok := s.creatives[hash]; ok {
+			c.Status = status
+		} else {
+			s.creatives[hash] = &storage.Creative{Hash: hash, Status: status}
+		}
+	}
+	return nil
+}
+
+func (s *memoryStore) ListPage(_ context.Context, _ storage.ListOptions) ([]*storage.Creative, int, error) {
+	var page []*storage.Creative
+	for _, c := range s.creatives {
+		page = append(page, c)
+	}
+	return page, len(page), nil
+}
+
+func TestHash(t *testing.T) {
+	a := Hash("appnexus", &openrtb.Bid{CRID: "cr-1", AdM: "<div>imp 1</div>"})
+	b := Hash("appnexus", &openrtb.Bid{CRID: "cr-1", AdM: "<div>imp 2</div>"})
+	if a != b || len(a) != 64 {
+		t.Errorf("expected bids with the same crid to share a 64 character hash, got %q and %q", a, b)
+	}
+	if Hash("rubicon", &openrtb.Bid{CRID: "cr-1"}) == a {
+		t.Error("expected the crid to be scoped to the bidder")
+	}
+	if Hash("appnexus", &openrtb.Bid{AdM: "<div>x</div>"}) != "" {
+		t.Error("expected bids without a crid to have no hash")
+	}
+}
+
+func TestRegistry_RegistersNewCreatives(t *testing.T) {
+	store := &memoryStore{}
+	r := NewRegistry(store, Config{})
+	bid := &openrtb.Bid{ID: "b1", CRID: "cr-1", AdM: "<div>ad</div>", ADomain: []string{"a.com", "b.com"}}
+
+	hash, status := r.Review("appnexus", bid, true)
+	if status != storage.CreativeStatusNew {
+		t.Fatalf("expected an unseen creative to be new, got %q", status)
+	}
+	r.Review("appnexus", bid, true)
+	if err := r.Flush(context.Background()); err != nil {
+		t.Fatalf("unexpected flush error: %v", err)
+	}
+
+	c := store.creatives[hash]
+	if len(store.creatives) != 1 || c == nil {
+		t.Fatalf("expected the creative registered once, got %v", store.creatives)
+	}
+	if c.BidderCode != "appnexus" || c.CreativeID != "cr-1" || c.ADomain != "a.com,b.com" || c.Markup != "<div>ad</div>" {
+		t.Errorf("unexpected registered creative: %+v", c)
+	}
+
+	// Still new after the reload, but not inserted again
+	if _, status := r.Review("appnexus", bid, true); status != storage.CreativeStatusNew {
+		t.Errorf("expected the registered creative to stay new, got %q", status)
+	}
+	if err := r.Flush(context.Background()); err != nil {
+		t.Fatalf("unexpected flush error: %v", err)
+	}
+	if store.inserts != 1 {
+		t.Errorf("expected one insert, got %d", store.inserts)
+	}
+}
+
+func TestRegistry_OnlyRegistersWhenAsked(t *testing.T) {
+	store := &memoryStore{}
+	r := NewRegistry(store, Config{})
+
+	if hash, status := r.Review("appnexus", &openrtb.Bid{AdM: "<div>no crid</div>"}, true); hash != "" || status != storage.CreativeStatusNew {
+		t.Errorf("expected a bid without a crid to be new with no hash, got %q %q", hash, status)
+	}
+	if _, status := r.Review("appnexus", &openrtb.Bid{CRID: "cr-1"}, false); status != storage.CreativeStatusNew {
+		t.Errorf("expected an unseen creative to be new, got %q", status)
+	}
+	if err := r.Flush(context.Background()); err != nil {
+		t.Fatalf("unexpected flush error: %v", err)
+	}
+	if len(store.creatives) != 0 {
+		t.Errorf("expected nothing registered, got %v", store.creatives)
+	}
+
+	// Blocked creatives are still found without registering
+	hash := Hash("appnexus", &openrtb.Bid{CRID: "cr-1"})
+	if err := r.SetStatus(context.Background(), []string{hash}, storage.CreativeStatusBlocked); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, status := r.Review("appnexus", &openrtb.Bid{CRID: "cr-1"}, false); status != storage.CreativeStatusBlocked {
+		t.Errorf("expected blocked, got %q", status)
+	}
+}
+
+func TestRegistry_SetStatus(t *testing.T) {
+	store := &memoryStore{}
+	r := NewRegistry(store, Config{})
+	other := NewRegistry(store, Config{})
+	bid := &openrtb.Bid{ID: "b1", CRID: "cr-1"}
+	hash := Hash("appnexus", bid)
+
+	if err := r.SetStatus(context.Background(), []string{hash}, storage.CreativeStatusBlocked); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, status := r.Review("appnexus", bid, true); status != storage.CreativeStatusBlocked {
+		t.Errorf("expected the status to apply immediately, got %q", status)
+	}
+
+	// Other instances pick the review up at their next flush
+	if _, status := other.Review("appnexus", bid, true); status != storage.CreativeStatusNew {
+		t.Errorf("expected new before the flush, got %q", status)
+	}
+	if err := other.Flush(context.Background()); err != nil {
+		t.Fatalf("unexpected flush error: %v", err)
+	}
+	if _, status := other.Review("appnexus", bid, true); status != storage.CreativeStatusBlocked {
+		t.Errorf("expected blocked after the flush, got %q", status)
+	}
+	if store.creatives[hash].Status != storage.CreativeStatusBlocked {
+		t.Errorf("expected the flush not to overwrite the review, got %q", store.creatives[hash].Status)
+	}
+}
+
+func TestRegistry_FlushRetriesFailedWrites(t *testing.T) {
+	store := &memoryStore{writeErr: errors.New("db down")}
+	r := NewRegistry(store, Config{})
+	hash, _ := r.Review("appnexus", &openrtb.Bid{CRID: "cr-1"}, true)
+
+	if err := r.Flush(context.Background()); err == nil {
+		t.Fatal("expected the write error")
+	}
+	store.writeErr = nil
+	if err := r.Flush(context.Background()); err != nil {
+		t.Fatalf("unexpected flush error: %v", err)
+	}
+	if _, ok := store.creatives[hash]; !ok {
+		t.Error("expected the creative registered on retry")
+	}
+}
+
+func TestTruncateMarkup(t *testing.T) {
+	markup := strings.Repeat("a", maxMarkupBytes-1) + "é" + "tail"
+	got := truncateMarkup(markup)
+	if len(got) > maxMarkupBytes || !utf8.ValidString(got) {
+		t.Errorf("expected valid UTF-8 of at most %d bytes, got %d bytes", maxMarkupBytes, len(got))
+	}
+	if truncateMarkup("<div>ad</div>") != "<div>ad</div>" {
+		t.Error("expected short markup unchanged")
+	}
+}
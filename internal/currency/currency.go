@@ -0,0 +1,99 @@
+// Package currency converts prices between currencies using a fixed rate table
+package currency
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is the currency OpenRTB assumes when cur/bidfloorcur is empty
+const DefaultCurrency = "USD"
+
+// ErrUnknownCurrency is returned when no rate is known for a currency pair
+var ErrUnknownCurrency = errors.New("unknown currency")
+
+// Converter converts amounts between currencies. Rates are expressed as
+// units of the base currency per unit of each currency. A nil Converter only
+// converts a currency to itself.
+type Converter struct {
+	base  string
+	rates map[string]float64
+}
+
+// NewConverter creates a converter from rates relative to base
+// (e.g. base "USD", rates {"EUR": 1.08} means 1 EUR = 1.08 USD)
+func NewConverter(base string, rates map[string]float64) *Converter {
+	base = Normalize(base)
+	c := &Converter{base: base, rates: make(map[string]float64, len(rates)+1)}
+	for cur, rate := range rates {
+		c.rates[Normalize(cur)] = rate
+	}
+	c.rates[base] = 1
+	return c
+}
+
+// Normalize upper-cases a currency code, mapping "" to DefaultCurrency
+func Normalize(cur string) string {
+	cur = strings.ToUpper(strings.TrimSpace(cur))
+	if cur == "" {
+		return DefaultCurrency
+	}
+	return cur
+}
+
+// Rate returns the multiplier converting amounts in from into to
+func (c *Converter) Rate(from, to string) (float64, error) {
+	from, to = Normalize(from), Normalize(to)
+	if from == to {
+		return 1, nil
+	}
+	if c == nil {
+		return 0, fmt.Errorf("%w: no rate from %s to %s", ErrUnknownCurrency, from, to)
+	}
+	fromRate, ok := c.rates[from]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownCurrency, from)
+	}
+	toRate, ok := c.rates[to]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownCurrency, to)
+	}
+	return fromRate / toRate, nil
+}
+
+// Convert converts an amount from one currency into another
+func (c *Converter) Convert(amount float64, from, to string) (float64, error) {
+	rate, err := c.Rate(from, to)
+	if err != nil {
+		return 0, err
+	}
+	return amount * rate, nil
+}
+
+// ParseRates parses a "CUR:rate,CUR:rate" list (e.g. "EUR:1.08,GBP:1.27")
+func ParseRates(s string) (map[string]float64, error) {
+	rates := make(map[string]float64)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		cur, value, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid rate %q: expected CUR:rate", entry)
+		}
+		cur = Normalize(cur)
+		if len(cur) != 3 {
+			return nil, fmt.Errorf("invalid currency code %q", cur)
+		}
+		rate, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || rate <= 0 || math.IsInf(rate, 0) || math.IsNaN(rate) {
+			return nil, fmt.Errorf("invalid rate for %s: %q", cur, value)
+		}
+		rates[cur] = rate
+	}
+	return rates, nil
+}
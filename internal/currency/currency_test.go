@@ -0,0 +1,63 @@
+package currency
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestConverter_Convert(t *testing.T) {
+	c := NewConverter("USD", map[string]float64{"EUR": 1.25, "gbp": 1.5})
+
+	tests := []struct {
+		amount   float64
+		from, to string
+		want     float64
+	}{
+		{2, "EUR", "USD", 2.5},
+		{2.5, "USD", "EUR", 2},
+		{3, "GBP", "EUR", 3.6},
+		{1, "", "usd", 1},
+		{7, "JPY", "JPY", 7},
+	}
+	for _, tt := range tests {
+		got, err := c.Convert(tt.amount, tt.from, tt.to)
+		if err != nil {
+			t.Errorf("Convert(%v, %s, %s): unexpected error %v", tt.amount, tt.from, tt.to, err)
+			continue
+		}
+		if math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Convert(%v, %s, %s) = %v, want %v", tt.amount, tt.from, tt.to, got, tt.want)
+		}
+	}
+
+	if _, err := c.Convert(1, "JPY", "USD"); !errors.Is(err, ErrUnknownCurrency) {
+		t.Errorf("expected ErrUnknownCurrency, got %v", err)
+	}
+}
+
+func TestConverter_Nil(t *testing.T) {
+	var c *Converter
+	if got, err := c.Convert(1.5, "", "USD"); err != nil || got != 1.5 {
+		t.Errorf("same currency: got %v, %v", got, err)
+	}
+	if _, err := c.Convert(1, "EUR", "USD"); !errors.Is(err, ErrUnknownCurrency) {
+		t.Errorf("expected ErrUnknownCurrency, got %v", err)
+	}
+}
+
+func TestParseRates(t *testing.T) {
+	rates, err := ParseRates(" eur:1.08, GBP:1.27 ,")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rates) != 2 || rates["EUR"] != 1.08 || rates["GBP"] != 1.27 {
+		t.Errorf("unexpected rates: %v", rates)
+	}
+
+	for _, bad := range []string{"EUR", "EUR:abc", "EUR:0", "EURO:1.1", "EUR:-1"} {
+		if _, err := ParseRates(bad); err == nil {
+			t.Errorf("ParseRates(%q): expected error", bad)
+		}
+	}
+}
@@ -0,0 +1,491 @@
+package currency
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/warmcache"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+// Rate sources a Feed can fetch from
+const (
+	SourceECB               = "ecb"
+	SourceOpenExchangeRates = "openexchangerates"
+)
+
+// Default provider endpoints
+const (
+	ECBURL               = "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-daily.xml"
+	OpenExchangeRatesURL = "https://openexchangerates.org/api/latest.json"
+)
+
+// Feed defaults. ECB publishes once per working day, so hourly fetches
+// pick up a new set within the hour and a day without one means the
+// provider or the network is failing.
+const (
+	DefaultFeedInterval = time.Hour
+	DefaultMaxAge       = 24 * time.Hour
+)
+
+// ratesCacheKey holds the last fetched rates in the KV store, shared by
+// every instance
+const ratesCacheKey = "currency:rates"
+
+// ratesCacheTTL keeps cached rates around long enough to start instances
+// through a provider outage
+const ratesCacheTTL = 7 * 24 * time.Hour
+
+// ageCheckInterval is how often the rates' age metric is refreshed between
+// fetches
+const ageCheckInterval = time.Minute
+
+// maxFeedBodySize bounds provider responses
+const maxFeedBodySize = 1024 * 1024
+
+// FeedConfig selects the live rate source
+type FeedConfig struct {
+	Source   string        // ecb or openexchangerates; "" disables the feed
+	AppID    string        // openexchangerates app_id
+	URL      string        // provider endpoint override ("" = the source's default)
+	Interval time.Duration // how often rates are fetched (0 = DefaultFeedInterval)
+	MaxAge   time.Duration // age after which rates are reported stale (0 = DefaultMaxAge)
+}
+
+// Cache stores fetched rates; kv.Store satisfies it
+type Cache interface {
+	Get(ctx context.Context, key string) (string, error)
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
+}
+
+// FeedRecorder records rate fetches and how old the rates in use are
+type FeedRecorder interface {
+	RecordCurrencyRateFetch(source, result string)
+	SetCurrencyRatesAge(age time.Duration, stale bool)
+}
+
+// Fetch results recorded by FeedRecorder
+const (
+	FetchSuccess = "success" // fetched from the provider
+	FetchCached  = "cached"  // another instance's fetch was still fresh
+	FetchError   = "error"   // the provider failed; the previous rates are kept
+)
+
+// quotes are a provider's rates: units of each currency per unit of base
+type quotes struct {
+	Source    string             `json:"source"`
+	Base      string             `json:"base"`
+	Rates     map[string]float64 `json:"rates"`
+	FetchedAt time.Time          `json:"fetched_at"`
+}
+
+// Snapshot describes the rates a Feed is converting with
+type Snapshot struct {
+	Base       string             `json:"base"`
+	Source     string             `json:"source"`
+	FetchedAt  time.Time          `json:"fetched_at,omitempty"`
+	AgeSeconds float64            `json:"age_seconds"`
+	Stale      bool               `json:"stale"`
+	Rates      map[string]float64 `json:"rates"`
+}
+
+// Feed fetches live exchange rates on a schedule, caches them in the KV
+// store so instances share one fetch, and hands each new set to onUpdate as
+// a Converter. Static rates fill in currencies the provider doesn't quote
+// and apply until the first fetch.
+type Feed struct {
+	cfg      FeedConfig
+	base     string
+	static   map[string]float64
+	cache    Cache
+	client   *http.Client
+	recorder FeedRecorder
+	onUpdate func(*Converter)
+	now      func() time.Time
+
+	mu      sync.RWMutex
+	current *quotes
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewFeed creates a feed converting into base. cache and recorder may be
+// nil. onUpdate is called with the static rates right away and with the
+// merged rates after every successful fetch.
+func NewFeed(cfg FeedConfig, base string, static map[string]float64, cache Cache, recorder FeedRecorder, onUpdate func(*Converter)) *Feed {
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultFeedInterval
+	}
+	if cfg.MaxAge <= 0 {
+		cfg.MaxAge = DefaultMaxAge
+	}
+	if cfg.URL == "" {
+		switch cfg.Source {
+		case SourceECB:
+			cfg.URL = ECBURL
+		case SourceOpenExchangeRates:
+			cfg.URL = OpenExchangeRatesURL
+		}
+	}
+	f := &Feed{
+		cfg:      cfg,
+		base:     Normalize(base),
+		static:   static,
+		cache:    cache,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		recorder: recorder,
+		onUpdate: onUpdate,
+		now:      time.Now,
+		stopCh:   make(chan struct{}),
+	}
+	if onUpdate != nil {
+		onUpdate(NewConverter(f.base, static))
+	}
+	return f
+}
+
+// ValidateFeedConfig checks a feed's source and its credentials
+func ValidateFeedConfig(cfg FeedConfig) error {
+	switch cfg.Source {
+	case "", SourceECB:
+	case SourceOpenExchangeRates:
+		if cfg.AppID == "" && cfg.URL == "" {
+			return fmt.Errorf("openexchangerates needs an app ID")
+		}
+	default:
+		return fmt.Errorf("unknown rate source %q (want %s or %s)", cfg.Source, SourceECB, SourceOpenExchangeRates)
+	}
+	if cfg.Interval < 0 || cfg.MaxAge < 0 {
+		return fmt.Errorf("rate feed interval and max age must not be negative")
+	}
+	return nil
+}
+
+// Start fetches rates, then refreshes them every interval until Stop
+func (f *Feed) Start() {
+	f.refreshLogged()
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+		fetch := time.NewTicker(f.cfg.Interval)
+		defer fetch.Stop()
+		age := time.NewTicker(ageCheckInterval)
+		defer age.Stop()
+		for {
+			select {
+			case <-f.stopCh:
+				return
+			case <-fetch.C:
+				f.refreshLogged()
+			case <-age.C:
+				f.recordAge()
+			}
+		}
+	}()
+}
+
+// Stop ends periodic refreshes
+func (f *Feed) Stop() {
+	f.stopOnce.Do(func() { close(f.stopCh) })
+	f.wg.Wait()
+}
+
+// refreshLogged runs one refresh, logging failures
+func (f *Feed) refreshLogged() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := f.Refresh(ctx); err != nil {
+		logger.Log.Warn().Err(err).Str("source", f.cfg.Source).Msg("Currency rate refresh failed, keeping the previous rates")
+	}
+	f.recordAge()
+}
+
+// Refresh updates the rates: from the cache when another instance fetched
+// them within the interval, otherwise from the provider. When the provider
+// fails, cached rates newer than the current ones are still applied.
+func (f *Feed) Refresh(ctx context.Context) error {
+	cached := f.loadCache(ctx)
+	if cached != nil && f.now().Sub(cached.FetchedAt) < f.cfg.Interval {
+		f.apply(cached)
+		f.recordFetch(FetchCached)
+		return nil
+	}
+
+	q, err := f.fetch(ctx)
+	if err != nil {
+		f.recordFetch(FetchError)
+		if cached != nil {
+			f.apply(cached)
+		}
+		return err
+	}
+	f.apply(q)
+	f.recordFetch(FetchSuccess)
+	f.storeCache(ctx, q)
+	return nil
+}
+
+// apply converts with q unless the current rates are newer
+func (f *Feed) apply(q *quotes) {
+	rates, err := q.relativeTo(f.base)
+	if err != nil {
+		logger.Log.Warn().Err(err).Str("source", q.Source).Msg("Currency rates unusable")
+		return
+	}
+
+	f.mu.Lock()
+	if f.current != nil && !q.FetchedAt.After(f.current.FetchedAt) {
+		f.mu.Unlock()
+		return
+	}
+	f.current = q
+	f.mu.Unlock()
+
+	if f.onUpdate != nil {
+		f.onUpdate(NewConverter(f.base, f.merged(rates)))
+	}
+	logger.Log.Info().
+		Str("source", q.Source).
+		Int("rates", len(rates)).
+		Time("fetched_at", q.FetchedAt).
+		Msg("Currency rates updated")
+}
+
+// merged overlays live rates on the static ones
+func (f *Feed) merged(live map[string]float64) map[string]float64 {
+	rates := make(map[string]float64, len(f.static)+len(live))
+	for cur, rate := range f.static {
+		rates[cur] = rate
+	}
+	for cur, rate := range live {
+		rates[cur] = rate
+	}
+	return rates
+}
+
+// Snapshot returns the rates in use and how old they are
+func (f *Feed) Snapshot() Snapshot {
+	f.mu.RLock()
+	current := f.current
+	f.mu.RUnlock()
+
+	snap := Snapshot{Base: f.base, Source: "static", Rates: f.merged(nil)}
+	if current == nil {
+		snap.Stale = f.cfg.Source != ""
+		return snap
+	}
+	live, _ := current.relativeTo(f.base)
+	age := f.now().Sub(current.FetchedAt)
+	snap.Source = current.Source
+	snap.FetchedAt = current.FetchedAt
+	snap.AgeSeconds = age.Seconds()
+	snap.Stale = age > f.cfg.MaxAge
+	snap.Rates = f.merged(live)
+	return snap
+}
+
+// WarmCache returns a warm cache provider persisting the live rates across
+// restarts, so an instance that can't reach the provider or the KV store
+// still starts with the last rates it converted with
+func (f *Feed) WarmCache() warmcache.Provider {
+	return feedWarmCache{f}
+}
+
+// feedWarmCache snapshots a Feed's live rates
+type feedWarmCache struct {
+	feed *Feed
+}
+
+// SnapshotName implements warmcache.Provider
+func (c feedWarmCache) SnapshotName() string {
+	return "currency_rates"
+}
+
+// Snapshot implements warmcache.Provider, exporting the last fetched rates
+func (c feedWarmCache) Snapshot() (json.RawMessage, error) {
+	c.feed.mu.RLock()
+	defer c.feed.mu.RUnlock()
+	return json.Marshal(c.feed.current)
+}
+
+// Restore implements warmcache.Provider. The rates keep their original
+// fetch time, so they never replace newer ones and are reported stale once
+// past the max age.
+func (c feedWarmCache) Restore(data json.RawMessage) error {
+	var q *quotes
+	if err := json.Unmarshal(data, &q); err != nil {
+		return err
+	}
+	if q == nil || q.Source != c.feed.cfg.Source || q.FetchedAt.IsZero() {
+		return nil
+	}
+	c.feed.apply(q)
+	c.feed.recordAge()
+	return nil
+}
+
+// recordAge reports how old the rates in use are
+func (f *Feed) recordAge() {
+	if f.recorder == nil {
+		return
+	}
+	snap := f.Snapshot()
+	f.recorder.SetCurrencyRatesAge(time.Duration(snap.AgeSeconds*float64(time.Second)), snap.Stale)
+}
+
+func (f *Feed) recordFetch(result string) {
+	if f.recorder != nil {
+		f.recorder.RecordCurrencyRateFetch(f.cfg.Source, result)
+	}
+}
+
+func (f *Feed) loadCache(ctx context.Context) *quotes {
+	if f.cache == nil {
+		return nil
+	}
+	raw, err := f.cache.Get(ctx, ratesCacheKey)
+	if err != nil || raw == "" {
+		return nil
+	}
+	var q quotes
+	if err := json.Unmarshal([]byte(raw), &q); err != nil || q.Source != f.cfg.Source {
+		return nil
+	}
+	return &q
+}
+
+func (f *Feed) storeCache(ctx context.Context, q *quotes) {
+	if f.cache == nil {
+		return
+	}
+	data, err := json.Marshal(q)
+	if err != nil {
+		return
+	}
+	if err := f.cache.Set(ctx, ratesCacheKey, string(data), ratesCacheTTL); err != nil {
+		logger.Log.Debug().Err(err).Msg("Failed to cache currency rates")
+	}
+}
+
+// fetch downloads the provider's current quotes
+func (f *Feed) fetch(ctx context.Context) (*quotes, error) {
+	endpoint := f.cfg.URL
+	if f.cfg.Source == SourceOpenExchangeRates && f.cfg.AppID != "" {
+		sep := "?"
+		if strings.Contains(endpoint, "?") {
+			sep = "&"
+		}
+		endpoint += sep + "app_id=" + url.QueryEscape(f.cfg.AppID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build rate request: %w", err)
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s rates: %w", f.cfg.Source, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s rates returned status %d", f.cfg.Source, resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxFeedBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s rates: %w", f.cfg.Source, err)
+	}
+
+	var q *quotes
+	switch f.cfg.Source {
+	case SourceECB:
+		q, err = parseECB(body)
+	case SourceOpenExchangeRates:
+		q, err = parseOpenExchangeRates(body)
+	default:
+		return nil, fmt.Errorf("unknown rate source %q", f.cfg.Source)
+	}
+	if err != nil {
+		return nil, err
+	}
+	q.Source = f.cfg.Source
+	q.FetchedAt = f.now()
+	return q, nil
+}
+
+// parseECB parses the ECB daily reference rates, quoted per euro
+func parseECB(body []byte) (*quotes, error) {
+	var doc struct {
+		Cube struct {
+			Cube struct {
+				Rates []struct {
+					Currency string  `xml:"currency,attr"`
+					Rate     float64 `xml:"rate,attr"`
+				} `xml:"Cube"`
+			} `xml:"Cube"`
+		} `xml:"Cube"`
+	}
+	if err := xml.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("invalid ECB rates: %w", err)
+	}
+	q := &quotes{Base: "EUR", Rates: make(map[string]float64)}
+	for _, r := range doc.Cube.Cube.Rates {
+		if r.Currency != "" && r.Rate > 0 {
+			q.Rates[Normalize(r.Currency)] = r.Rate
+		}
+	}
+	if len(q.Rates) == 0 {
+		return nil, fmt.Errorf("ECB rates contained no currencies")
+	}
+	return q, nil
+}
+
+// parseOpenExchangeRates parses an openexchangerates latest.json response
+func parseOpenExchangeRates(body []byte) (*quotes, error) {
+	var doc struct {
+		Base  string             `json:"base"`
+		Rates map[string]float64 `json:"rates"`
+	}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("invalid openexchangerates rates: %w", err)
+	}
+	q := &quotes{Base: Normalize(doc.Base), Rates: make(map[string]float64, len(doc.Rates))}
+	for cur, rate := range doc.Rates {
+		if rate > 0 {
+			q.Rates[Normalize(cur)] = rate
+		}
+	}
+	if len(q.Rates) == 0 {
+		return nil, fmt.Errorf("openexchangerates rates contained no currencies")
+	}
+	return q, nil
+}
+
+// relativeTo re-expresses the quotes as units of base per unit of each
+// currency, the form NewConverter takes
+func (q *quotes) relativeTo(base string) (map[string]float64, error) {
+	perQuoteBase := make(map[string]float64, len(q.Rates)+1)
+	for cur, rate := range q.Rates {
+		perQuoteBase[cur] = rate
+	}
+	perQuoteBase[Normalize(q.Base)] = 1
+
+	baseRate, ok := perQuoteBase[base]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s rates don't quote %s", ErrUnknownCurrency, q.Source, base)
+	}
+	rates := make(map[string]float64, len(perQuoteBase))
+	for cur, rate := range perQuoteBase {
+		rates[cur] = baseRate / rate
+	}
+	return rates, nil
+}
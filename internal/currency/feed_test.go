@@ -0,0 +1,253 @@
+package currency
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+const ecbBody = `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<gesmes:subject>Reference rates</gesmes:subject>
+	<Cube>
+		<Cube time="2026-10-15">
+			<Cube currency="USD" rate="1.10"/>
+			<Cube currency="GBP" rate="0.88"/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+// memCache is an in-memory Cache
+type memCache struct {
+	mu     sync.Mutex
+	values map[string]string
+}
+
+func (c *memCache) Get(ctx context.Context, key string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.values[key], nil
+}
+
+func (c *memCache) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.values == nil {
+		c.values = make(map[string]string)
+	}
+	c.values[key] = value.(string)
+	return nil
+}
+
+type mockFeedRecorder struct {
+	fetches map[string]int
+	stale   bool
+}
+
+func (r *mockFeedRecorder) RecordCurrencyRateFetch(source, result string) {
+	if r.fetches == nil {
+		r.fetches = make(map[string]int)
+	}
+	r.fetches[source+"/"+result]++
+}
+
+func (r *mockFeedRecorder) SetCurrencyRatesAge(age time.Duration, stale bool) {
+	r.stale = stale
+}
+
+// rateServer serves body, or a 503 while failing is set
+type rateServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	body     string
+	failing  bool
+	requests int
+	query    string
+}
+
+func newRateServer(t *testing.T, body string) *rateServer {
+	rs := &rateServer{body: body}
+	rs.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rs.mu.Lock()
+		defer rs.mu.Unlock()
+		rs.requests++
+		rs.query = r.URL.RawQuery
+		if rs.failing {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(rs.body))
+	}))
+	t.Cleanup(rs.Close)
+	return rs
+}
+
+func approx(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestFeed_ECB(t *testing.T) {
+	server := newRateServer(t, ecbBody)
+	var converter *Converter
+	rec := &mockFeedRecorder{}
+	feed := NewFeed(FeedConfig{Source: SourceECB, URL: server.URL}, "USD", map[string]float64{"JPY": 0.0067, "GBP": 2}, &memCache{}, rec, func(c *Converter) { converter = c })
+
+	// Static rates apply until the first fetch
+	if rate, err := converter.Rate("GBP", "USD"); err != nil || rate != 2 {
+		t.Fatalf("expected the static GBP rate, got %v (%v)", rate, err)
+	}
+
+	if err := feed.Refresh(context.Background()); err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+	if rate, _ := converter.Rate("EUR", "USD"); !approx(rate, 1.10) {
+		t.Errorf("expected 1 EUR = 1.10 USD, got %v", rate)
+	}
+	if rate, _ := converter.Rate("GBP", "USD"); !approx(rate, 1.10/0.88) {
+		t.Errorf("expected live rates to override static ones, got %v", rate)
+	}
+	if rate, _ := converter.Rate("JPY", "USD"); rate != 0.0067 {
+		t.Errorf("expected static rates to fill currencies ECB doesn't quote, got %v", rate)
+	}
+	if rec.fetches["ecb/success"] != 1 {
+		t.Errorf("expected a successful fetch recorded, got %v", rec.fetches)
+	}
+
+	snap := feed.Snapshot()
+	if snap.Source != SourceECB || snap.Base != "USD" || snap.Stale || snap.FetchedAt.IsZero() {
+		t.Errorf("unexpected snapshot %+v", snap)
+	}
+}
+
+func TestFeed_SharesCachedRates(t *testing.T) {
+	server := newRateServer(t, ecbBody)
+	cache := &memCache{}
+	cfg := FeedConfig{Source: SourceECB, URL: server.URL}
+
+	first := NewFeed(cfg, "USD", nil, cache, nil, nil)
+	if err := first.Refresh(context.Background()); err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+
+	var converter *Converter
+	rec := &mockFeedRecorder{}
+	second := NewFeed(cfg, "EUR", nil, cache, rec, func(c *Converter) { converter = c })
+	if err := second.Refresh(context.Background()); err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+	if server.requests != 1 || rec.fetches["ecb/cached"] != 1 {
+		t.Errorf("expected the second instance to use the cached rates, got %d requests, %v", server.requests, rec.fetches)
+	}
+	if rate, _ := converter.Rate("USD", "EUR"); !approx(rate, 1/1.10) {
+		t.Errorf("expected cached rates re-based on EUR, got %v", rate)
+	}
+}
+
+func TestFeed_ProviderFailureKeepsRates(t *testing.T) {
+	server := newRateServer(t, ecbBody)
+	var converter *Converter
+	rec := &mockFeedRecorder{}
+	feed := NewFeed(FeedConfig{Source: SourceECB, URL: server.URL, Interval: time.Minute, MaxAge: time.Hour}, "USD", nil, nil, rec, func(c *Converter) { converter = c })
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	feed.now = func() time.Time { return now }
+
+	if err := feed.Refresh(context.Background()); err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+	server.failing = true
+	now = now.Add(2 * time.Hour)
+	if err := feed.Refresh(context.Background()); err == nil {
+		t.Fatal("expected the provider failure returned")
+	}
+	if rate, _ := converter.Rate("EUR", "USD"); !approx(rate, 1.10) {
+		t.Errorf("expected the previous rates kept, got %v", rate)
+	}
+	feed.recordAge()
+	if rec.fetches["ecb/error"] != 1 || !rec.stale {
+		t.Errorf("expected the failure recorded and the rates reported stale, got %v stale=%v", rec.fetches, rec.stale)
+	}
+	if snap := feed.Snapshot(); snap.AgeSeconds != 7200 {
+		t.Errorf("expected rates 2h old, got %v", snap.AgeSeconds)
+	}
+}
+
+func TestFeed_WarmCache(t *testing.T) {
+	server := newRateServer(t, ecbBody)
+	cfg := FeedConfig{Source: SourceECB, URL: server.URL}
+	source := NewFeed(cfg, "USD", nil, nil, nil, nil)
+	if err := source.Refresh(context.Background()); err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+	data, err := source.WarmCache().Snapshot()
+	if err != nil {
+		t.Fatalf("snapshot failed: %v", err)
+	}
+
+	var converter *Converter
+	target := NewFeed(cfg, "USD", nil, nil, nil, func(c *Converter) { converter = c })
+	if err := target.WarmCache().Restore(data); err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	if rate, _ := converter.Rate("EUR", "USD"); !approx(rate, 1.10) {
+		t.Errorf("expected the snapshotted rates restored, got %v", rate)
+	}
+	if !target.Snapshot().FetchedAt.Equal(source.Snapshot().FetchedAt) {
+		t.Error("expected restored rates to keep their fetch time")
+	}
+
+	other := NewFeed(FeedConfig{Source: SourceOpenExchangeRates, AppID: "x"}, "USD", nil, nil, nil, nil)
+	if err := other.WarmCache().Restore(data); err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	if other.Snapshot().Source != "static" {
+		t.Error("expected rates from another source ignored")
+	}
+}
+
+func TestFeed_OpenExchangeRates(t *testing.T) {
+	server := newRateServer(t, `{"timestamp":1760600000,"base":"USD","rates":{"EUR":0.9,"GBP":0.8,"USD":1}}`)
+	var converter *Converter
+	feed := NewFeed(FeedConfig{Source: SourceOpenExchangeRates, URL: server.URL, AppID: "secret"}, "GBP", nil, nil, nil, func(c *Converter) { converter = c })
+	if err := feed.Refresh(context.Background()); err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+	if server.query != "app_id=secret" {
+		t.Errorf("expected the app ID sent, got %q", server.query)
+	}
+	if rate, _ := converter.Rate("EUR", "GBP"); !approx(rate, 0.8/0.9) {
+		t.Errorf("expected EUR in GBP, got %v", rate)
+	}
+}
+
+func TestFeed_BaseNotQuoted(t *testing.T) {
+	server := newRateServer(t, ecbBody)
+	updates := 0
+	feed := NewFeed(FeedConfig{Source: SourceECB, URL: server.URL}, "CHF", nil, nil, nil, func(*Converter) { updates++ })
+	feed.Refresh(context.Background())
+	if updates != 1 {
+		t.Errorf("expected rates without the base currency ignored, got %d updates", updates)
+	}
+}
+
+func TestValidateFeedConfig(t *testing.T) {
+	tests := []struct {
+		cfg     FeedConfig
+		wantErr bool
+	}{
+		{FeedConfig{}, false},
+		{FeedConfig{Source: SourceECB}, false},
+		{FeedConfig{Source: SourceOpenExchangeRates, AppID: "id"}, false},
+		{FeedConfig{Source: SourceOpenExchangeRates}, true},
+		{FeedConfig{Source: "fixer"}, true},
+		{FeedConfig{Source: SourceECB, Interval: -time.Second}, true},
+	}
+	for _, tt := range tests {
+		if err := ValidateFeedConfig(tt.cfg); (err != nil) != tt.wantErr {
+			t.Errorf("%+v: expected error %v, got %v", tt.cfg, tt.wantErr, err)
+		}
+	}
+}
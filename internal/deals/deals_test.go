@@ -0,0 +1,164 @@
+package deals
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+	"github.com/thenexusengine/tne_springwire/internal/winqueue"
+)
+
+// memoryStore keeps bookings and per-instance delivery in memory
+type memoryStore struct {
+	deals     []*storage.Deal
+	delivery  map[string]map[string]int64 // instance -> deal -> impressions, for one day
+	writeErr  error
+	listCalls int
+}
+
+func (s *memoryStore) ListActive(_ context.Context, _ time.Time) ([]*storage.Deal, error) {
+	s.listCalls++
+	return s.deals, nil
+}
+
+func (s *memoryStore) UpsertDelivery(_ context.Context, instanceID string, rows []*storage.DealDelivery) error {
+	if s.writeErr != nil {
+		return s.writeErr
+	}
+	if s.delivery == nil {
+		s.delivery = make(map[string]map[string]int64)
+	}
+	if s.delivery[instanceID] == nil {
+		s.delivery[instanceID] = make(map[string]int64)
+	}
+	for _, r := range rows {
+		s.delivery[instanceID][r.DealID] = r.Impressions
+	}
+	return nil
+}
+
+func (s *memoryStore) QueryDelivery(_ context.Context, _ time.Time) (map[string]int64, error) {
+	sum := make(map[string]int64)
+	for _, deals := range s.delivery {
+		for id, n := range deals {
+			sum[id] += n
+		}
+	}
+	return sum, nil
+}
+
+// noon is half way through the UTC day, so a deal booked for 1000 is
+// expected to have delivered 500
+var noon = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestJob(store *memoryStore) (*Job, *Pacer) {
+	pacer := NewPacer()
+	pacer.now = func() time.Time { return noon }
+	job := NewJob(pacer, store, Config{})
+	job.now = pacer.now
+	return job, pacer
+}
+
+func billing(bidID, dealID string) winqueue.Event {
+	return winqueue.Event{Type: winqueue.EventBilling, BidID: bidID, DealID: dealID}
+}
+
+func TestPacer_BehindUntilProRataGoal(t *testing.T) {
+	store := &memoryStore{
+		deals:    []*storage.Deal{{DealID: "pg-1", DailyImpressions: 1000}},
+		delivery: map[string]map[string]int64{"other-host": {"pg-1": 499}},
+	}
+	job, pacer := newTestJob(store)
+	if err := job.Flush(context.Background()); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	if !pacer.Behind("pg-1") {
+		t.Error("expected 499 of an expected 500 to be behind")
+	}
+	pacer.Process(context.Background(), billing("bid-1", "pg-1"))
+	pacer.Process(context.Background(), billing("bid-1", "pg-1")) // redelivered
+	if pacer.Behind("pg-1") {
+		t.Error("expected 500 of an expected 500 to be on track")
+	}
+	if pacer.Behind("unbooked") {
+		t.Error("expected deals without a booking never to be behind")
+	}
+
+	status := pacer.Status()
+	if len(status) != 1 || status[0].Delivered != 500 || status[0].Expected != 500 || status[0].Status != StatusOnTrack {
+		t.Errorf("unexpected status: %+v", status)
+	}
+}
+
+func TestPacer_IgnoresWinsAndOpenMarket(t *testing.T) {
+	pacer := NewPacer()
+	pacer.Process(context.Background(), winqueue.Event{Type: winqueue.EventWin, BidID: "b1", DealID: "pg-1"})
+	pacer.Process(context.Background(), billing("b2", ""))
+
+	if rows, _ := pacer.Snapshot(); len(rows) != 0 {
+		t.Errorf("expected only billed deal impressions to count, got %+v", rows)
+	}
+}
+
+func TestJob_FlushSharesDeliveryWithoutDoubleCounting(t *testing.T) {
+	store := &memoryStore{deals: []*storage.Deal{{DealID: "pg-1", DailyImpressions: 1000}}}
+	job, pacer := newTestJob(store)
+
+	for _, id := range []string{"b1", "b2", "b3"} {
+		pacer.Process(context.Background(), billing(id, "pg-1"))
+	}
+	if err := job.Flush(context.Background()); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+	if err := job.Flush(context.Background()); err != nil {
+		t.Fatalf("second flush failed: %v", err)
+	}
+
+	if got := store.delivery[job.instanceID]["pg-1"]; got != 3 {
+		t.Errorf("expected 3 impressions persisted, got %d", got)
+	}
+	if status := pacer.Status(); status[0].Delivered != 3 {
+		t.Errorf("expected 3 delivered after reload, got %d", status[0].Delivered)
+	}
+}
+
+func TestJob_FlushKeepsTotalsOnWriteFailure(t *testing.T) {
+	store := &memoryStore{
+		deals:    []*storage.Deal{{DealID: "pg-1", DailyImpressions: 1000}},
+		writeErr: errors.New("connection refused"),
+	}
+	job, pacer := newTestJob(store)
+	pacer.Process(context.Background(), billing("b1", "pg-1"))
+
+	if err := job.Flush(context.Background()); err == nil {
+		t.Fatal("expected the write error")
+	}
+	if store.listCalls != 1 {
+		t.Error("expected bookings to be loaded despite the failed write")
+	}
+	if rows, _ := pacer.Snapshot(); len(rows) != 1 || rows[0].Impressions != 1 {
+		t.Errorf("expected unwritten totals to be kept, got %+v", rows)
+	}
+	if status := pacer.Status(); status[0].Delivered != 1 {
+		t.Errorf("expected local delivery to count, got %d", status[0].Delivered)
+	}
+}
+
+func TestJob_ReportPastDay(t *testing.T) {
+	store := &memoryStore{
+		deals:    []*storage.Deal{{DealID: "pg-1", DailyImpressions: 1000}},
+		delivery: map[string]map[string]int64{"host-1": {"pg-1": 900}},
+	}
+	job, _ := newTestJob(store)
+
+	report, err := job.Report(context.Background(), noon.AddDate(0, 0, -1))
+	if err != nil {
+		t.Fatalf("report failed: %v", err)
+	}
+	if len(report) != 1 || report[0].Expected != 1000 || report[0].Status != StatusBehind {
+		t.Errorf("expected a finished day under its booking to be behind, got %+v", report)
+	}
+}
@@ -0,0 +1,170 @@
+package deals
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+// DefaultInterval flushes delivery and reloads bookings every minute, which
+// bounds how stale pacing decisions are across instances
+const DefaultInterval = time.Minute
+
+// Store reads deal bookings and persists delivery; implemented by
+// storage.DealStore
+type Store interface {
+	ListActive(ctx context.Context, day time.Time) ([]*storage.Deal, error)
+	UpsertDelivery(ctx context.Context, instanceID string, rows []*storage.DealDelivery) error
+	QueryDelivery(ctx context.Context, day time.Time) (map[string]int64, error)
+}
+
+// Config controls how often delivery is shared between instances
+type Config struct {
+	Interval time.Duration // 0 uses DefaultInterval
+}
+
+// Job periodically writes a Pacer's delivery to the store and reloads the
+// bookings and every instance's delivery for today
+type Job struct {
+	pacer      *Pacer
+	store      Store
+	cfg        Config
+	instanceID string
+	now        func() time.Time
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewJob creates a job for pacer. Like the metrics rollup, each instance
+// writes its own rows under a random instance ID.
+func NewJob(pacer *Pacer, store Store, cfg Config) *Job {
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultInterval
+	}
+	return &Job{
+		pacer:      pacer,
+		store:      store,
+		cfg:        cfg,
+		instanceID: newInstanceID(),
+		now:        time.Now,
+		stopCh:     make(chan struct{}),
+	}
+}
+
+// newInstanceID returns the hostname with a random suffix
+func newInstanceID() string {
+	host, _ := os.Hostname()
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	id := host + "-" + hex.EncodeToString(b)
+	if len(id) > 100 {
+		id = id[len(id)-100:]
+	}
+	return id
+}
+
+// Start loads today's bookings, then flushes every interval until Stop
+func (j *Job) Start() {
+	j.flushLogged()
+	j.wg.Add(1)
+	go func() {
+		defer j.wg.Done()
+		ticker := time.NewTicker(j.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-j.stopCh:
+				return
+			case <-ticker.C:
+				j.flushLogged()
+			}
+		}
+	}()
+}
+
+// flushLogged runs one flush, logging failures
+func (j *Job) flushLogged() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := j.Flush(ctx); err != nil {
+		logger.Log.Warn().Err(err).Msg("Deal pacing flush failed, will retry next interval")
+	}
+}
+
+// Stop ends periodic flushes and writes the final delivery
+func (j *Job) Stop(ctx context.Context) error {
+	j.stopOnce.Do(func() { close(j.stopCh) })
+	j.wg.Wait()
+	return j.Flush(ctx)
+}
+
+// Flush writes this instance's delivery, then reloads today's bookings and
+// delivery. Totals are kept in memory if the write fails, so the next flush
+// retries them; bookings are still reloaded.
+func (j *Job) Flush(ctx context.Context) error {
+	rows, today := j.pacer.Snapshot()
+	writeErr := j.store.UpsertDelivery(ctx, j.instanceID, rows)
+	if writeErr == nil {
+		j.pacer.Prune(today)
+	} else {
+		rows = nil
+	}
+
+	bookings, err := j.store.ListActive(ctx, today)
+	if err != nil {
+		return fmt.Errorf("failed to load deal bookings: %w", err)
+	}
+	delivered, err := j.store.QueryDelivery(ctx, today)
+	if err != nil {
+		return fmt.Errorf("failed to load deal delivery: %w", err)
+	}
+	j.pacer.Load(today, bookings, delivered, rows)
+	if writeErr != nil {
+		return writeErr
+	}
+
+	logger.Log.Debug().
+		Int("deals", len(bookings)).
+		Int("rows", len(rows)).
+		Str("instance_id", j.instanceID).
+		Msg("Deal pacing flushed")
+	return nil
+}
+
+// Report returns delivery against booking for every deal active on day.
+// Today is reported live from the pacer; earlier days from the store.
+func (j *Job) Report(ctx context.Context, day time.Time) ([]DealStatus, error) {
+	day = day.UTC().Truncate(24 * time.Hour)
+	if day.Equal(j.now().UTC().Truncate(24 * time.Hour)) {
+		return j.pacer.Status(), nil
+	}
+
+	bookings, err := j.store.ListActive(ctx, day)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load deal bookings: %w", err)
+	}
+	delivered, err := j.store.QueryDelivery(ctx, day)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load deal delivery: %w", err)
+	}
+
+	// Past days are over, future days haven't started
+	elapsed := 1.0
+	if day.After(j.now()) {
+		elapsed = 0
+	}
+	statuses := make([]DealStatus, 0, len(bookings))
+	for _, deal := range bookings {
+		statuses = append(statuses, dealStatus(deal, delivered[deal.DealID], elapsed))
+	}
+	return statuses, nil
+}
@@ -0,0 +1,243 @@
+// Package deals tracks delivery of programmatic guaranteed deals against
+// their daily bookings and paces them: a deal behind its pro-rata goal for
+// the day has its bids ranked ahead of open market bids.
+package deals
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+	"github.com/thenexusengine/tne_springwire/internal/winqueue"
+)
+
+// Delivery statuses reported per deal
+const (
+	StatusBehind   = "behind"   // under the pro-rata goal; bids are prioritized
+	StatusOnTrack  = "on_track" // at or over the pro-rata goal
+	StatusComplete = "complete" // the day's booking is delivered
+)
+
+// DealStatus is a deal's delivery against its booking for one UTC day
+type DealStatus struct {
+	DealID      string  `json:"deal_id"`
+	PublisherID string  `json:"publisher_id,omitempty"`
+	BidderCode  string  `json:"bidder_code,omitempty"`
+	Booked      int64   `json:"booked"`
+	Delivered   int64   `json:"delivered"`
+	Expected    int64   `json:"expected"` // pro-rata goal for the time of day
+	Pace        float64 `json:"pace"`     // delivered / expected; 1 when nothing is expected yet
+	Status      string  `json:"status"`
+}
+
+// dayKey identifies one deal's delivery on one UTC day
+type dayKey struct {
+	day    time.Time
+	dealID string
+}
+
+// Pacer counts this instance's deal impressions and, with the bookings and
+// other instances' delivery loaded by Job, decides which deals are behind.
+// It implements exchange.DealPacer and winqueue.Processor.
+type Pacer struct {
+	mu sync.Mutex
+
+	// local holds this instance's running totals until Job flushes them
+	local map[dayKey]int64
+	// seen holds the billing notices counted per day so redelivered events
+	// aren't counted twice
+	seen map[time.Time]map[string]struct{}
+
+	// day, bookings and others are the last state loaded by Job: today's
+	// active deals and what the other instances had delivered on it
+	day      time.Time
+	bookings map[string]*storage.Deal
+	others   map[string]int64
+
+	now func() time.Time
+}
+
+// NewPacer creates a pacer with no bookings; nothing is prioritized until
+// Job loads them
+func NewPacer() *Pacer {
+	return &Pacer{
+		local:    make(map[dayKey]int64),
+		seen:     make(map[time.Time]map[string]struct{}),
+		bookings: make(map[string]*storage.Deal),
+		others:   make(map[string]int64),
+		now:      time.Now,
+	}
+}
+
+// today returns the start of the current UTC day. Process calls it with mu
+// held, so nothing is counted into a day after Snapshot has seen it end.
+func (p *Pacer) today() time.Time {
+	return p.now().UTC().Truncate(24 * time.Hour)
+}
+
+// Process implements winqueue.Processor. Each billing notice for a bid
+// carrying a deal ID is one delivered impression.
+func (p *Pacer) Process(_ context.Context, event winqueue.Event) error {
+	if event.Type != winqueue.EventBilling || event.DealID == "" {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	day := p.today()
+	seen := p.seen[day]
+	if seen == nil {
+		seen = make(map[string]struct{})
+		p.seen[day] = seen
+	}
+	if _, dup := seen[event.BidID]; dup {
+		return nil
+	}
+	seen[event.BidID] = struct{}{}
+	p.local[dayKey{day: day, dealID: event.DealID}]++
+	return nil
+}
+
+// Behind reports whether a deal is under its pro-rata goal for today.
+// Implements exchange.DealPacer.
+func (p *Pacer) Behind(dealID string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	deal, ok := p.bookings[dealID]
+	if !ok || !p.day.Equal(p.today()) {
+		return false
+	}
+	status := dealStatus(deal, p.deliveredLocked(dealID), p.elapsed())
+	return status.Status == StatusBehind
+}
+
+// Owner returns the bidder and publisher a deal is booked for.
+// Implements exchange.DealPacer.
+func (p *Pacer) Owner(dealID string) (bidderCode, publisherID string, ok bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	deal, ok := p.bookings[dealID]
+	if !ok {
+		return "", "", false
+	}
+	return deal.BidderCode, deal.PublisherID, true
+}
+
+// Status returns every booked deal's delivery so far today
+func (p *Pacer) Status() []DealStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.day.Equal(p.today()) {
+		return []DealStatus{}
+	}
+	elapsed := p.elapsed()
+	statuses := make([]DealStatus, 0, len(p.bookings))
+	for id, deal := range p.bookings {
+		statuses = append(statuses, dealStatus(deal, p.deliveredLocked(id), elapsed))
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].DealID < statuses[j].DealID })
+	return statuses
+}
+
+// elapsed returns the fraction of the current UTC day that has passed
+func (p *Pacer) elapsed() float64 {
+	now := p.now().UTC()
+	return float64(now.Sub(now.Truncate(24*time.Hour))) / float64(24*time.Hour)
+}
+
+// deliveredLocked returns today's delivery across instances; mu must be held
+func (p *Pacer) deliveredLocked(dealID string) int64 {
+	return p.others[dealID] + p.local[dayKey{day: p.day, dealID: dealID}]
+}
+
+// Snapshot returns this instance's running totals, ordered by day and deal,
+// and the current day. Days before it can no longer change.
+func (p *Pacer) Snapshot() ([]*storage.DealDelivery, time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	rows := make([]*storage.DealDelivery, 0, len(p.local))
+	for k, n := range p.local {
+		rows = append(rows, &storage.DealDelivery{Day: k.day, DealID: k.dealID, Impressions: n})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if !rows[i].Day.Equal(rows[j].Day) {
+			return rows[i].Day.Before(rows[j].Day)
+		}
+		return rows[i].DealID < rows[j].DealID
+	})
+	return rows, p.today()
+}
+
+// Load replaces the bookings for day and the delivery summed across
+// instances. This instance's share of delivered is subtracted using the
+// totals from the Snapshot that was flushed, so impressions counted since
+// aren't lost; pass nil when the flush failed.
+func (p *Pacer) Load(day time.Time, bookings []*storage.Deal, delivered map[string]int64, flushed []*storage.DealDelivery) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.day = day
+	p.bookings = make(map[string]*storage.Deal, len(bookings))
+	for _, d := range bookings {
+		p.bookings[d.DealID] = d
+	}
+	p.others = make(map[string]int64, len(delivered))
+	for id, n := range delivered {
+		p.others[id] = n
+	}
+	for _, row := range flushed {
+		if row.Day.Equal(day) {
+			p.others[row.DealID] -= row.Impressions
+		}
+	}
+}
+
+// Prune drops days before cutoff once they have been persisted
+func (p *Pacer) Prune(cutoff time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for k := range p.local {
+		if k.day.Before(cutoff) {
+			delete(p.local, k)
+		}
+	}
+	for d := range p.seen {
+		if d.Before(cutoff) {
+			delete(p.seen, d)
+		}
+	}
+}
+
+// dealStatus compares delivery with the share of the booking expected after
+// elapsed (0 to 1) of the day
+func dealStatus(deal *storage.Deal, delivered int64, elapsed float64) DealStatus {
+	s := DealStatus{
+		DealID:      deal.DealID,
+		PublisherID: deal.PublisherID,
+		BidderCode:  deal.BidderCode,
+		Booked:      deal.DailyImpressions,
+		Delivered:   delivered,
+		Expected:    int64(float64(deal.DailyImpressions) * elapsed),
+		Pace:        1,
+	}
+	if s.Expected > 0 {
+		s.Pace = float64(delivered) / float64(s.Expected)
+	}
+	switch {
+	case delivered >= s.Booked:
+		s.Status = StatusComplete
+	case delivered < s.Expected:
+		s.Status = StatusBehind
+	default:
+		s.Status = StatusOnTrack
+	}
+	return s
+}
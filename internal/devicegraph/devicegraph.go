@@ -0,0 +1,197 @@
+// Package devicegraph resolves a viewer's IP address and user agent to the
+// household and device IDs held by an external device graph service, so
+// frequency caps can follow a household across its CTVs, phones and
+// browsers. Lookups are cached by a hash of the IP and user agent, and a
+// failed or slow lookup only ever means the auction falls back to its
+// per-device keys.
+package devicegraph
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/pkg/lru"
+)
+
+// Lookup results reported to Metrics
+const (
+	ResultCached   = "cached"    // answered from the cache, found or not
+	ResultResolved = "resolved"  // the service returned a household or device
+	ResultNotFound = "not_found" // the service doesn't know the viewer
+	ResultError    = "error"     // the call failed or timed out
+)
+
+// cacheMaxBytes bounds the cache alongside Config.CacheSize; viewers past
+// either bound are evicted least recently seen first
+const cacheMaxBytes = 32 << 20
+
+// Identity is what the device graph knows about a viewer
+type Identity struct {
+	HouseholdID string `json:"household_id,omitempty"`
+	DeviceID    string `json:"device_id,omitempty"`
+}
+
+// Found reports whether the graph resolved the viewer at all
+func (id Identity) Found() bool {
+	return id.HouseholdID != "" || id.DeviceID != ""
+}
+
+// Metrics records lookups by result
+type Metrics interface {
+	RecordDeviceGraphLookup(result string)
+}
+
+// Config configures the device graph client
+type Config struct {
+	URL       string        // Resolve endpoint; lookups are disabled when empty
+	APIKey    string        // Sent as a bearer token when set
+	Timeout   time.Duration // Longest a lookup may take, on top of the caller's deadline
+	CacheTTL  time.Duration // How long a result, found or not, is reused
+	CacheSize int           // Viewers cached
+}
+
+// DefaultConfig returns the default client configuration
+func DefaultConfig() Config {
+	return Config{
+		Timeout:   30 * time.Millisecond,
+		CacheTTL:  time.Hour,
+		CacheSize: 100000,
+	}
+}
+
+// ValidateConfig checks the client limits
+func ValidateConfig(cfg Config) error {
+	if cfg.Timeout < 0 || cfg.CacheTTL < 0 || cfg.CacheSize < 0 {
+		return fmt.Errorf("device graph limits must not be negative")
+	}
+	return nil
+}
+
+type cacheEntry struct {
+	identity Identity
+	expires  time.Time
+}
+
+// Client resolves viewers against the device graph service. It implements
+// exchange.DeviceGraph.
+type Client struct {
+	cfg     Config
+	http    *http.Client
+	metrics Metrics
+	cache   *lru.Cache[string, cacheEntry]
+	now     func() time.Time
+}
+
+// New creates a client; metrics may be nil
+func New(cfg Config, metrics Metrics) *Client {
+	defaults := DefaultConfig()
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaults.Timeout
+	}
+	if cfg.CacheTTL <= 0 {
+		cfg.CacheTTL = defaults.CacheTTL
+	}
+	if cfg.CacheSize <= 0 {
+		cfg.CacheSize = defaults.CacheSize
+	}
+	return &Client{
+		cfg:     cfg,
+		http:    &http.Client{Timeout: cfg.Timeout},
+		metrics: metrics,
+		cache: lru.New(lru.Config{
+			Name:       "device_graph",
+			MaxEntries: cfg.CacheSize,
+			MaxBytes:   cacheMaxBytes,
+		}, func(key string, e cacheEntry) int64 {
+			return int64(len(key)+len(e.identity.HouseholdID)+len(e.identity.DeviceID)) + 64
+		}),
+		now: time.Now,
+	}
+}
+
+// Resolve returns the household and device IDs for ip and ua. ok is false
+// when the graph doesn't know the viewer or the lookup failed; failures
+// aren't cached, so the next request tries again.
+func (c *Client) Resolve(ctx context.Context, ip, ua string) (householdID, deviceID string, ok bool) {
+	if c == nil || c.cfg.URL == "" || ip == "" || ua == "" {
+		return "", "", false
+	}
+
+	key := cacheKey(ip, ua)
+	if e, hit := c.cache.Get(key); hit && c.now().Before(e.expires) {
+		c.record(ResultCached)
+		return e.identity.HouseholdID, e.identity.DeviceID, e.identity.Found()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+	identity, err := c.lookup(ctx, ip, ua)
+	if err != nil {
+		c.record(ResultError)
+		return "", "", false
+	}
+
+	c.cache.Set(key, cacheEntry{identity: identity, expires: c.now().Add(c.cfg.CacheTTL)})
+	if !identity.Found() {
+		c.record(ResultNotFound)
+		return "", "", false
+	}
+	c.record(ResultResolved)
+	return identity.HouseholdID, identity.DeviceID, true
+}
+
+// lookup asks the service about one viewer. A 404 is a valid "not found"
+// answer; any other non-2xx status is an error.
+func (c *Client) lookup(ctx context.Context, ip, ua string) (Identity, error) {
+	body, err := json.Marshal(map[string]string{"ip": ip, "ua": ua})
+	if err != nil {
+		return Identity{}, fmt.Errorf("failed to encode device graph request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return Identity{}, fmt.Errorf("failed to create device graph request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.cfg.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.cfg.APIKey)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return Identity{}, fmt.Errorf("device graph request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+		return Identity{}, nil
+	}
+	if resp.StatusCode/100 != 2 {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+		return Identity{}, fmt.Errorf("device graph returned status %d", resp.StatusCode)
+	}
+
+	var identity Identity
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(&identity); err != nil {
+		return Identity{}, fmt.Errorf("failed to decode device graph response: %w", err)
+	}
+	return identity, nil
+}
+
+// cacheKey hashes the IP and user agent so the cache holds neither
+func cacheKey(ip, ua string) string {
+	sum := sha256.Sum256([]byte(ip + "\x00" + ua))
+	return hex.EncodeToString(sum[:16])
+}
+
+func (c *Client) record(result string) {
+	if c.metrics != nil {
+		c.metrics.RecordDeviceGraphLookup(result)
+	}
+}
@@ -0,0 +1,149 @@
+package devicegraph
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type mockMetrics struct {
+	mu      sync.Mutex
+	results map[string]int
+}
+
+func (m *mockMetrics) RecordDeviceGraphLookup(result string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.results == nil {
+		m.results = make(map[string]int)
+	}
+	m.results[result]++
+}
+
+// graphServer knows one viewer, 192.0.2.1 on a Roku
+type graphServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests int
+	auth     string
+	status   int
+	delay    time.Duration
+}
+
+func newGraphServer(t *testing.T) *graphServer {
+	gs := &graphServer{}
+	gs.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gs.mu.Lock()
+		gs.requests++
+		gs.auth = r.Header.Get("Authorization")
+		status, delay := gs.status, gs.delay
+		gs.mu.Unlock()
+		time.Sleep(delay)
+		if status != 0 {
+			w.WriteHeader(status)
+			return
+		}
+		var body struct{ IP, UA string }
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.IP != "192.0.2.1" || body.UA != "Roku" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"household_id":"hh-1","device_id":"dev-1"}`))
+	}))
+	t.Cleanup(gs.Close)
+	return gs
+}
+
+func TestResolve_CachesResults(t *testing.T) {
+	server := newGraphServer(t)
+	metrics := &mockMetrics{}
+	client := New(Config{URL: server.URL, APIKey: "secret", Timeout: time.Second}, metrics)
+
+	household, device, ok := client.Resolve(context.Background(), "192.0.2.1", "Roku")
+	if !ok || household != "hh-1" || device != "dev-1" {
+		t.Fatalf("expected the viewer resolved, got %q %q %v", household, device, ok)
+	}
+	if server.auth != "Bearer secret" {
+		t.Errorf("expected the API key sent, got %q", server.auth)
+	}
+	if _, _, ok := client.Resolve(context.Background(), "192.0.2.9", "Roku"); ok {
+		t.Error("expected an unknown viewer unresolved")
+	}
+
+	// Both answers, found or not, are reused
+	client.Resolve(context.Background(), "192.0.2.1", "Roku")
+	client.Resolve(context.Background(), "192.0.2.9", "Roku")
+	if server.requests != 2 {
+		t.Errorf("expected cached answers reused, got %d requests", server.requests)
+	}
+	if metrics.results[ResultResolved] != 1 || metrics.results[ResultNotFound] != 1 || metrics.results[ResultCached] != 2 {
+		t.Errorf("unexpected lookup results %v", metrics.results)
+	}
+
+	// Until they expire
+	client.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	client.Resolve(context.Background(), "192.0.2.1", "Roku")
+	if server.requests != 3 {
+		t.Errorf("expected an expired answer looked up again, got %d requests", server.requests)
+	}
+}
+
+func TestResolve_FailuresAreNotCached(t *testing.T) {
+	server := newGraphServer(t)
+	server.status = http.StatusServiceUnavailable
+	metrics := &mockMetrics{}
+	client := New(Config{URL: server.URL, Timeout: time.Second}, metrics)
+
+	if _, _, ok := client.Resolve(context.Background(), "192.0.2.1", "Roku"); ok {
+		t.Fatal("expected a server error unresolved")
+	}
+	server.mu.Lock()
+	server.status = 0
+	server.mu.Unlock()
+	if _, _, ok := client.Resolve(context.Background(), "192.0.2.1", "Roku"); !ok {
+		t.Error("expected the lookup retried after a failure")
+	}
+	if metrics.results[ResultError] != 1 || metrics.results[ResultResolved] != 1 {
+		t.Errorf("unexpected lookup results %v", metrics.results)
+	}
+}
+
+func TestResolve_Timeout(t *testing.T) {
+	server := newGraphServer(t)
+	server.delay = 200 * time.Millisecond
+	client := New(Config{URL: server.URL, Timeout: 10 * time.Millisecond}, nil)
+
+	start := time.Now()
+	if _, _, ok := client.Resolve(context.Background(), "192.0.2.1", "Roku"); ok {
+		t.Error("expected a slow lookup unresolved")
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("expected the lookup abandoned at its timeout, took %v", elapsed)
+	}
+}
+
+func TestResolve_Disabled(t *testing.T) {
+	var nilClient *Client
+	if _, _, ok := nilClient.Resolve(context.Background(), "192.0.2.1", "Roku"); ok {
+		t.Error("expected a nil client to resolve nothing")
+	}
+	server := newGraphServer(t)
+	client := New(Config{URL: server.URL}, nil)
+	if _, _, ok := client.Resolve(context.Background(), "", "Roku"); ok || server.requests != 0 {
+		t.Error("expected a request without an IP not looked up")
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	if err := ValidateConfig(DefaultConfig()); err != nil {
+		t.Errorf("expected the defaults valid, got %v", err)
+	}
+	if err := ValidateConfig(Config{Timeout: -time.Second}); err == nil {
+		t.Error("expected a negative timeout rejected")
+	}
+}
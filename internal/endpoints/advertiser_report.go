@@ -0,0 +1,154 @@
+package endpoints
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+const (
+	// defaultAdvertiserLimit is how many advertisers a report lists per
+	// publisher unless the request asks for more
+	defaultAdvertiserLimit = 50
+	maxAdvertiserLimit     = 1000
+)
+
+// AdvertiserFrequencyReader queries winning advertiser domain counts;
+// implemented by storage.RollupStore
+type AdvertiserFrequencyReader interface {
+	QueryAdvertiserFrequency(ctx context.Context, from, to time.Time, publisherID string) ([]*storage.AdvertiserFrequency, error)
+}
+
+// AdvertiserShare is how much of a publisher's inventory one advertiser
+// domain won over a report's range
+type AdvertiserShare struct {
+	AdvertiserDomain string  `json:"advertiser_domain"`
+	Wins             int64   `json:"wins"`
+	Share            float64 `json:"share"` // of the publisher's wins with an adomain
+	Sessions         int64   `json:"sessions"`
+	WinsPerSession   float64 `json:"wins_per_session"` // among wins for identified viewers
+	MaxSessionWins   int64   `json:"max_session_wins"` // most by one viewer in an hour
+}
+
+// PublisherAdvertisers ranks a publisher's advertiser domains, most wins first
+type PublisherAdvertisers struct {
+	PublisherID string             `json:"publisher_id"`
+	Wins        int64              `json:"wins"`
+	Advertisers []*AdvertiserShare `json:"advertisers"`
+}
+
+// AdvertiserReportResponse is the JSON form of /api/v1/reports/advertisers
+type AdvertiserReportResponse struct {
+	From       time.Time               `json:"from"`
+	To         time.Time               `json:"to"`
+	Publishers []*PublisherAdvertisers `json:"publishers"`
+}
+
+// AdvertiserReportHandler shows publishers which advertisers dominate their
+// inventory, so they can set advertiser separation and block rules
+type AdvertiserReportHandler struct {
+	store AdvertiserFrequencyReader
+	now   func() time.Time
+}
+
+// NewAdvertiserReportHandler creates an advertiser report handler; store may
+// be nil when no database is configured
+func NewAdvertiserReportHandler(store AdvertiserFrequencyReader) *AdvertiserReportHandler {
+	return &AdvertiserReportHandler{store: store, now: time.Now}
+}
+
+// ServeHTTP handles advertiser report requests
+// Routes:
+//
+//	GET /api/v1/reports/advertisers?from=&to=&publisher_id=&limit=
+//
+// from and to are as for /admin/reports/hourly. A publisher's API key only
+// reports its own inventory; publisher_id selects a publisher, or all of
+// them when empty, for keys not bound to one.
+func (h *AdvertiserReportHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		sendAdminError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+		return
+	}
+	if h.store == nil {
+		sendAdminError(w, http.StatusServiceUnavailable, "database_unavailable", "Reports require a database connection")
+		return
+	}
+
+	q := r.URL.Query()
+	publisherID := q.Get("publisher_id")
+	if keyPublisher := middleware.PublisherIDFromContext(r.Context()); keyPublisher != "" && keyPublisher != "default" {
+		if publisherID != "" && publisherID != keyPublisher {
+			sendAdminError(w, http.StatusForbidden, "forbidden", "API key is not authorized for this publisher")
+			return
+		}
+		publisherID = keyPublisher
+	}
+
+	limit := defaultAdvertiserLimit
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxAdvertiserLimit {
+			sendAdminError(w, http.StatusBadRequest, "invalid_limit", "limit must be between 1 and 1000")
+			return
+		}
+		limit = n
+	}
+	from, to, ok := reportRange(w, q, h.now())
+	if !ok {
+		return
+	}
+
+	rows, err := h.store.QueryAdvertiserFrequency(r.Context(), from, to, publisherID)
+	if err != nil {
+		logger.Log.Error().Err(err).Msg("Failed to query advertiser frequency")
+		sendAdminError(w, http.StatusInternalServerError, "query_failed", "Failed to query reports")
+		return
+	}
+	sendAdminJSON(w, http.StatusOK, AdvertiserReportResponse{
+		From:       from,
+		To:         to,
+		Publishers: advertiserShares(rows, limit),
+	})
+}
+
+// advertiserShares groups rows, ordered by publisher and most wins first, per
+// publisher and keeps each one's top limit domains. Shares are of all the
+// publisher's wins, including domains past the limit.
+func advertiserShares(rows []*storage.AdvertiserFrequency, limit int) []*PublisherAdvertisers {
+	publishers := []*PublisherAdvertisers{}
+	var current *PublisherAdvertisers
+	for _, row := range rows {
+		if current == nil || current.PublisherID != row.PublisherID {
+			current = &PublisherAdvertisers{PublisherID: row.PublisherID, Advertisers: []*AdvertiserShare{}}
+			publishers = append(publishers, current)
+		}
+		current.Wins += row.Wins
+		if len(current.Advertisers) == limit {
+			continue
+		}
+		share := &AdvertiserShare{
+			AdvertiserDomain: row.AdvertiserDomain,
+			Wins:             row.Wins,
+			Sessions:         row.Sessions,
+			MaxSessionWins:   row.MaxSessionWins,
+		}
+		if row.Sessions > 0 {
+			share.WinsPerSession = float64(row.SessionWins) / float64(row.Sessions)
+		}
+		current.Advertisers = append(current.Advertisers, share)
+	}
+	for _, p := range publishers {
+		for _, a := range p.Advertisers {
+			if p.Wins > 0 {
+				a.Share = float64(a.Wins) / float64(p.Wins)
+			}
+		}
+	}
+	return publishers
+}
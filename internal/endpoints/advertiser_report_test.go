@@ -0,0 +1,114 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+)
+
+type mockAdvertiserReader struct {
+	rows        []*storage.AdvertiserFrequency
+	publisherID string
+	calls       int
+}
+
+func (m *mockAdvertiserReader) QueryAdvertiserFrequency(_ context.Context, _, _ time.Time, publisherID string) ([]*storage.AdvertiserFrequency, error) {
+	m.publisherID = publisherID
+	m.calls++
+	return m.rows, nil
+}
+
+func TestAdvertiserReportHandler_Shares(t *testing.T) {
+	store := &mockAdvertiserReader{rows: []*storage.AdvertiserFrequency{
+		{PublisherID: "pub-1", AdvertiserDomain: "acme.example", Wins: 60, Sessions: 10, SessionWins: 50, MaxSessionWins: 9},
+		{PublisherID: "pub-1", AdvertiserDomain: "soda.example", Wins: 30, Sessions: 30, SessionWins: 30, MaxSessionWins: 1},
+		{PublisherID: "pub-1", AdvertiserDomain: "shoes.example", Wins: 10},
+	}}
+	h := NewAdvertiserReportHandler(store)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/reports/advertisers?from=2026-03-01&to=2026-03-02&limit=2", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp AdvertiserReportResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if len(resp.Publishers) != 1 || resp.Publishers[0].Wins != 100 {
+		t.Fatalf("expected one publisher with 100 wins, got %+v", resp.Publishers)
+	}
+	advertisers := resp.Publishers[0].Advertisers
+	if len(advertisers) != 2 {
+		t.Fatalf("expected the top 2 advertisers, got %d", len(advertisers))
+	}
+	if acme := advertisers[0]; acme.AdvertiserDomain != "acme.example" || acme.Share != 0.6 || acme.WinsPerSession != 5 || acme.MaxSessionWins != 9 {
+		t.Errorf("unexpected acme share: %+v", acme)
+	}
+	if soda := advertisers[1]; soda.Share != 0.3 || soda.WinsPerSession != 1 {
+		t.Errorf("unexpected soda share: %+v", soda)
+	}
+}
+
+func TestAdvertiserReportHandler_PublisherScope(t *testing.T) {
+	tests := []struct {
+		name          string
+		keyPublisher  string
+		target        string
+		status        int
+		wantPublisher string
+	}{
+		{"publisher key", "pub-1", "/api/v1/reports/advertisers", http.StatusOK, "pub-1"},
+		{"own publisher", "pub-1", "/api/v1/reports/advertisers?publisher_id=pub-1", http.StatusOK, "pub-1"},
+		{"other publisher", "pub-1", "/api/v1/reports/advertisers?publisher_id=pub-2", http.StatusForbidden, ""},
+		{"unbound key", "default", "/api/v1/reports/advertisers?publisher_id=pub-2", http.StatusOK, "pub-2"},
+		{"unbound key, all publishers", "default", "/api/v1/reports/advertisers", http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &mockAdvertiserReader{}
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			req = req.WithContext(middleware.NewContextWithPublisherID(req.Context(), tt.keyPublisher))
+			w := httptest.NewRecorder()
+			NewAdvertiserReportHandler(store).ServeHTTP(w, req)
+			if w.Code != tt.status {
+				t.Fatalf("expected %d, got %d", tt.status, w.Code)
+			}
+			if tt.status == http.StatusOK && store.publisherID != tt.wantPublisher {
+				t.Errorf("expected query for %q, got %q", tt.wantPublisher, store.publisherID)
+			}
+			if tt.status != http.StatusOK && store.calls != 0 {
+				t.Error("expected a rejected request not queried")
+			}
+		})
+	}
+}
+
+func TestAdvertiserReportHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		store  AdvertiserFrequencyReader
+		target string
+		status int
+	}{
+		{"no database", nil, "/api/v1/reports/advertisers", http.StatusServiceUnavailable},
+		{"bad limit", &mockAdvertiserReader{}, "/api/v1/reports/advertisers?limit=0", http.StatusBadRequest},
+		{"bad range", &mockAdvertiserReader{}, "/api/v1/reports/advertisers?from=2026-03-02&to=2026-03-01", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			NewAdvertiserReportHandler(tt.store).ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.target, nil))
+			if w.Code != tt.status {
+				t.Errorf("expected %d, got %d", tt.status, w.Code)
+			}
+		})
+	}
+}
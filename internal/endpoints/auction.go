@@ -14,7 +14,10 @@ import (
 	log "github.com/rs/zerolog/log"
 
 	"github.com/thenexusengine/tne_springwire/internal/exchange"
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
 	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/internal/scrub"
+	"github.com/thenexusengine/tne_springwire/internal/storedrequest"
 	"github.com/thenexusengine/tne_springwire/pkg/logger"
 )
 
@@ -33,9 +36,43 @@ func GetPublisherID(ctx context.Context) (string, bool) {
 	return publisherID, ok && publisherID != ""
 }
 
+// applyLatencyBudget lowers the request's tmax to fit the caller's latency
+// budget (X-Latency-Budget) and labels budget metrics with the publisher
+func applyLatencyBudget(ctx context.Context, bidReq *openrtb.BidRequest) {
+	budget := middleware.LatencyBudgetFromContext(ctx)
+	if budget == nil {
+		return
+	}
+
+	budget.SetPartner(requestPublisherID(ctx, bidReq))
+	bidReq.TMax = budget.ApplyTMax(bidReq.TMax)
+}
+
+// UpgradeRecorder counts legacy OpenRTB constructs upgraded per rule;
+// implemented by metrics.Metrics
+type UpgradeRecorder interface {
+	RecordLegacyUpgrade(rule string)
+}
+
+// SLORecorder counts auction response times against the publisher's p95
+// latency target; implemented by slo.Tracker
+type SLORecorder interface {
+	Record(publisherID string, targetMs int, latency time.Duration)
+}
+
+// StoredRequestResolver expands ext.prebid.storedrequest references into the
+// templates they name; implemented by storedrequest.Resolver
+type StoredRequestResolver interface {
+	Resolve(ctx context.Context, body []byte, publisherID string) ([]byte, error)
+}
+
 // AuctionHandler handles /openrtb2/auction requests
 type AuctionHandler struct {
 	exchange *exchange.Exchange
+	tail     *AuctionTail
+	upgrades UpgradeRecorder
+	slo      SLORecorder
+	stored   StoredRequestResolver
 }
 
 // NewAuctionHandler creates a new auction handler
@@ -43,8 +80,54 @@ func NewAuctionHandler(ex *exchange.Exchange) *AuctionHandler {
 	return &AuctionHandler{exchange: ex}
 }
 
+// SetTail streams auction summaries to /admin/debug/tail subscribers
+func (h *AuctionHandler) SetTail(tail *AuctionTail) {
+	h.tail = tail
+}
+
+// SetUpgradeRecorder counts the legacy constructs upgraded in requests
+func (h *AuctionHandler) SetUpgradeRecorder(r UpgradeRecorder) {
+	h.upgrades = r
+}
+
+// SetSLORecorder tracks response times against publisher latency targets
+func (h *AuctionHandler) SetSLORecorder(r SLORecorder) {
+	h.slo = r
+}
+
+// SetStoredRequests expands stored request and stored imp references before
+// requests are parsed
+func (h *AuctionHandler) SetStoredRequests(r StoredRequestResolver) {
+	h.stored = r
+}
+
+// recordSLO counts the auction's response time against its publisher's
+// latency target. Shadow traffic isn't served to anyone, so it doesn't count.
+func (h *AuctionHandler) recordSLO(ctx context.Context, req *openrtb.BidRequest, start time.Time) {
+	if h.slo == nil || middleware.IsShadowRequest(ctx) {
+		return
+	}
+	targetMs := 0
+	if pub, ok := middleware.PublisherFromContext(ctx).(interface{ GetSLOP95Ms() int }); ok {
+		targetMs = pub.GetSLOP95Ms()
+	}
+	h.slo.Record(requestPublisherID(ctx, req), targetMs, time.Since(start))
+}
+
+// publishTail hands the auction to anyone tailing its publisher
+func (h *AuctionHandler) publishTail(ctx context.Context, req *openrtb.BidRequest, result *exchange.AuctionResponse, duration time.Duration, err error) {
+	if h.tail == nil {
+		return
+	}
+	publisherID := requestPublisherID(ctx, req)
+	h.tail.Publish(publisherID, func() *AuctionSummary {
+		return summarizeAuction(ctx, publisherID, req, result, duration, err)
+	})
+}
+
 // ServeHTTP handles the auction request
 func (h *AuctionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	requestStart := time.Now()
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
@@ -58,18 +141,54 @@ func (h *AuctionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Parse OpenRTB request
+	// Merge server-side templates referenced by ext.prebid.storedrequest.id
+	if h.stored != nil {
+		publisherID, _ := GetPublisherID(r.Context())
+		ctx := storedrequest.WithQuery(r.Context(), r.URL.Query())
+		body, err = h.stored.Resolve(ctx, body, publisherID)
+		if err != nil {
+			if errors.Is(err, storedrequest.ErrInvalidReference) {
+				writeError(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			log := logger.FromContext(r.Context())
+			log.Error().Err(err).Msg("Failed to load stored requests")
+			writeError(w, "Stored requests unavailable", http.StatusServiceUnavailable)
+			return
+		}
+	}
+
+	// Parse OpenRTB request, upgrading constructs older integrations send
 	var bidRequest openrtb.BidRequest
-	err = json.Unmarshal(body, &bidRequest)
+	upgrades, err := openrtb.ParseBidRequest(body, &bidRequest)
 	if err != nil {
-		logger.Log.Warn().Err(err).Msg("Invalid JSON in bid request")
+		log := logger.FromContext(r.Context())
+		log.Warn().Err(err).Msg("Invalid JSON in bid request")
 		writeError(w, "Invalid JSON in request body", http.StatusBadRequest)
 		return
 	}
 
+	// Tag request-scoped logs with the edge request ID, the auction ID and
+	// the publisher, whose log level may be overridden via /admin/logging
+	ctx := logger.WithAuctionID(r.Context(), bidRequest.ID)
+	ctx = logger.WithPublisherID(ctx, requestPublisherID(ctx, &bidRequest))
+	log := logger.FromContext(ctx)
+	if e := log.Debug(); e.Enabled() {
+		e.RawJSON("request", scrub.JSON(&bidRequest)).Msg("Bid request received")
+	}
+	if len(upgrades) > 0 {
+		log.Debug().Strs("rules", upgrades).Msg("Upgraded legacy OpenRTB constructs")
+		if h.upgrades != nil {
+			for _, rule := range upgrades {
+				h.upgrades.RecordLegacyUpgrade(rule)
+			}
+		}
+	}
+
 	// Validate request
 	err = validateBidRequest(&bidRequest)
 	if err != nil {
+		log.Warn().Err(err).RawJSON("request", scrub.JSON(&bidRequest)).Msg("Invalid bid request")
 		writeError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -84,20 +203,37 @@ func (h *AuctionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			if hasAPIKey(r) {
 				debugEnabled = true
 			} else {
-				logger.Log.Debug().Msg("Debug mode requested without authentication, ignoring")
+				log.Debug().Msg("Debug mode requested without authentication, ignoring")
 			}
 		} else {
 			debugEnabled = true
 		}
 	}
 
+	// Structured user agent from client hints; the privacy middleware has
+	// set the consent context by now
+	middleware.DeviceSUA(ctx, bidRequest.Device)
+
+	// Fit the auction into the caller's latency budget
+	applyLatencyBudget(ctx, &bidRequest)
+
 	auctionReq := &exchange.AuctionRequest{
 		BidRequest: &bidRequest,
 		Debug:      debugEnabled,
+		Shadow:     middleware.IsShadowRequest(ctx),
+	}
+
+	// Staging tests may inject a signed canned response for one bidder
+	if header := r.Header.Get(exchange.BidInjectionHeader); header != "" {
+		injection, err := h.exchange.ParseBidInjection(header, bidRequest.ID)
+		if err != nil {
+			log.Warn().Err(err).Msg("Ignoring bid injection header")
+		} else {
+			auctionReq.BidInjection = injection
+		}
 	}
 
 	// Run auction
-	ctx := r.Context()
 	auctionStart := time.Now()
 	result, err := h.exchange.RunAuction(ctx, auctionReq)
 	auctionDuration := time.Since(auctionStart)
@@ -114,9 +250,8 @@ func (h *AuctionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			errorMsg = validationErr.Message
 		}
 
-		logger.Log.Error().
+		log.Error().
 			Err(err).
-			Str("request_id", bidRequest.ID).
 			Int("imp_count", len(bidRequest.Imp)).
 			Dur("duration_ms", auctionDuration).
 			Int("status_code", statusCode).
@@ -124,8 +259,10 @@ func (h *AuctionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 		// Log to dashboard
 		LogAuction(bidRequest.ID, len(bidRequest.Imp), 0, nil, auctionDuration, false, err)
+		h.publishTail(ctx, &bidRequest, result, auctionDuration, err)
 
 		writeError(w, errorMsg, statusCode)
+		h.recordSLO(ctx, &bidRequest, requestStart)
 		return
 	}
 
@@ -141,8 +278,7 @@ func (h *AuctionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	logger.Log.Info().
-		Str("request_id", bidRequest.ID).
+	log.Info().
 		Int("imp_count", len(bidRequest.Imp)).
 		Int("bid_count", bidCount).
 		Strs("winning_bidders", winningBidders).
@@ -152,6 +288,7 @@ func (h *AuctionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	// Log to dashboard
 	LogAuction(bidRequest.ID, len(bidRequest.Imp), bidCount, winningBidders, auctionDuration, true, nil)
+	h.publishTail(ctx, &bidRequest, result, auctionDuration, nil)
 
 	// Build response with extensions
 	response := result.BidResponse
@@ -169,6 +306,7 @@ func (h *AuctionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if err := json.NewEncoder(w).Encode(response); err != nil {
 		log.Error().Err(err).Str("request_id", bidRequest.ID).Msg("failed to encode auction response")
 	}
+	h.recordSLO(ctx, &bidRequest, requestStart)
 }
 
 // validateBidRequest validates the bid request
@@ -278,7 +416,8 @@ type BidderLister interface {
 
 // InfoBiddersHandler handles /info/bidders requests
 type InfoBiddersHandler struct {
-	staticRegistry BidderLister
+	staticRegistry  BidderLister
+	capabilityStore BidderCapabilityStore
 }
 
 // NewInfoBiddersHandler creates a new bidders info handler from a static list.
@@ -310,6 +449,12 @@ func NewDynamicInfoBiddersHandler(staticRegistry BidderLister) *InfoBiddersHandl
 
 // ServeHTTP handles info/bidders requests
 func (h *InfoBiddersHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Filtered capability listing for publisher tooling
+	if isCapabilityQuery(r) {
+		h.serveCapabilities(w, r)
+		return
+	}
+
 	// Collect bidders from the registry at request time
 	bidderSet := make(map[string]bool)
 
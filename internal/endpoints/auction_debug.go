@@ -0,0 +1,79 @@
+package endpoints
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/thenexusengine/tne_springwire/internal/auctiontrail"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+// auctionDebugPrefix is the path prefix for auction trail lookups
+const auctionDebugPrefix = "/admin/debug/auction/"
+
+// AuctionTrailReader looks up recorded auction trails; implemented by
+// auctiontrail.Recorder
+type AuctionTrailReader interface {
+	Get(ctx context.Context, auctionID string) (*auctiontrail.Trail, error)
+}
+
+// AuctionDebugResponse is an auction's decision trail, with an explanation
+// of one bidder's result when ?bidder= is given
+type AuctionDebugResponse struct {
+	Trail       *auctiontrail.Trail `json:"trail"`
+	Bidder      string              `json:"bidder,omitempty"`
+	Explanation string              `json:"explanation,omitempty"`
+}
+
+// AuctionDebugHandler serves recorded auction trails
+type AuctionDebugHandler struct {
+	trails AuctionTrailReader
+}
+
+// NewAuctionDebugHandler creates an auction trail handler; trails may be nil
+// when trails aren't recorded
+func NewAuctionDebugHandler(trails AuctionTrailReader) *AuctionDebugHandler {
+	return &AuctionDebugHandler{trails: trails}
+}
+
+// ServeHTTP handles auction trail lookups
+// Routes:
+//
+//	GET /admin/debug/auction/{id}               - The auction's decision trail
+//	GET /admin/debug/auction/{id}?bidder=rubicon - Plus why rubicon won or lost
+func (h *AuctionDebugHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		sendAdminError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+		return
+	}
+	if h.trails == nil {
+		sendAdminError(w, http.StatusServiceUnavailable, "auction_trail_disabled", "Auction trails are not recorded (AUCTION_TRAIL_ENABLED=false or no KV store)")
+		return
+	}
+
+	auctionID := strings.TrimPrefix(r.URL.Path, auctionDebugPrefix)
+	if auctionID == "" || strings.Contains(auctionID, "/") {
+		sendAdminError(w, http.StatusBadRequest, "invalid_request", "Auction ID is required")
+		return
+	}
+
+	trail, err := h.trails.Get(r.Context(), auctionID)
+	if errors.Is(err, auctiontrail.ErrNotFound) {
+		sendAdminError(w, http.StatusNotFound, "not_found", "No trail for this auction; it may have expired")
+		return
+	}
+	if err != nil {
+		logger.Log.Error().Err(err).Str("auction_id", auctionID).Msg("Failed to read auction trail")
+		sendAdminError(w, http.StatusInternalServerError, "storage_error", "Failed to read auction trail")
+		return
+	}
+
+	resp := AuctionDebugResponse{Trail: trail}
+	if bidder := r.URL.Query().Get("bidder"); bidder != "" {
+		resp.Bidder = bidder
+		resp.Explanation = trail.Explain(bidder)
+	}
+	sendAdminJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,84 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/thenexusengine/tne_springwire/internal/auctiontrail"
+)
+
+type mockAuctionTrails struct {
+	trails map[string]*auctiontrail.Trail
+	err    error
+}
+
+func (m *mockAuctionTrails) Get(_ context.Context, auctionID string) (*auctiontrail.Trail, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	trail, ok := m.trails[auctionID]
+	if !ok {
+		return nil, auctiontrail.ErrNotFound
+	}
+	return trail, nil
+}
+
+func TestAuctionDebugHandler_Trail(t *testing.T) {
+	trails := &mockAuctionTrails{trails: map[string]*auctiontrail.Trail{
+		"auction-1": {
+			AuctionID: "auction-1",
+			Outcome:   auctiontrail.OutcomeFilled,
+			Bidders:   []auctiontrail.Bidder{{Code: "rubicon", Called: true, Bids: 1}},
+			Bids: []auctiontrail.Bid{
+				{Bidder: "rubicon", BidID: "b1", ImpID: "1", Price: 1.2, Outcome: auctiontrail.BidLost, Reason: "outbid by appnexus at 2.50"},
+			},
+		},
+	}}
+	h := NewAuctionDebugHandler(trails)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/debug/auction/auction-1?bidder=rubicon", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp AuctionDebugResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if resp.Trail == nil || resp.Trail.AuctionID != "auction-1" || len(resp.Trail.Bids) != 1 {
+		t.Errorf("unexpected trail: %+v", resp.Trail)
+	}
+	if resp.Bidder != "rubicon" || !strings.Contains(resp.Explanation, "outbid by appnexus") {
+		t.Errorf("unexpected explanation: %q", resp.Explanation)
+	}
+}
+
+func TestAuctionDebugHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		trails AuctionTrailReader
+		method string
+		url    string
+		want   int
+	}{
+		{"disabled", nil, http.MethodGet, "/admin/debug/auction/auction-1", http.StatusServiceUnavailable},
+		{"wrong method", &mockAuctionTrails{}, http.MethodPost, "/admin/debug/auction/auction-1", http.StatusMethodNotAllowed},
+		{"missing ID", &mockAuctionTrails{}, http.MethodGet, "/admin/debug/auction/", http.StatusBadRequest},
+		{"expired", &mockAuctionTrails{}, http.MethodGet, "/admin/debug/auction/auction-1", http.StatusNotFound},
+		{"store failure", &mockAuctionTrails{err: errors.New("redis down")}, http.MethodGet, "/admin/debug/auction/auction-1", http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			NewAuctionDebugHandler(tt.trails).ServeHTTP(w, httptest.NewRequest(tt.method, tt.url, nil))
+			if w.Code != tt.want {
+				t.Errorf("expected %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,437 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/exchange"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/internal/scrub"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+const (
+	// defaultTailMinutes is how long a tail runs when ?minutes= is omitted
+	defaultTailMinutes = 5
+	// maxTailMinutes caps how long a single tail may stream
+	maxTailMinutes = 15
+	// maxTailSubscribers bounds concurrent tails across all publishers
+	maxTailSubscribers = 10
+	// tailBufferSize is how many summaries may queue for a slow client
+	// before new ones are dropped
+	tailBufferSize = 64
+	// tailHeartbeatInterval keeps idle streams open through proxies
+	tailHeartbeatInterval = 15 * time.Second
+	// tailReconnectAfter is the delay clients are told to wait before
+	// reconnecting when a tail is closed for shutdown, long enough for the
+	// load balancer to stop routing to the draining instance
+	tailReconnectAfter = 5 * time.Second
+)
+
+// AuctionSummary is a scrubbed, single-event view of an auction streamed to
+// /admin/debug/tail subscribers
+type AuctionSummary struct {
+	Time            time.Time                    `json:"time"`
+	AuctionID       string                       `json:"auction_id"`
+	RequestID       string                       `json:"request_id,omitempty"`
+	PublisherID     string                       `json:"publisher_id"`
+	Domain          string                       `json:"domain,omitempty"`
+	Bundle          string                       `json:"bundle,omitempty"`
+	Country         string                       `json:"country,omitempty"`
+	DeviceType      int                          `json:"device_type,omitempty"`
+	IP              string                       `json:"ip,omitempty"`
+	ImpCount        int                          `json:"imp_count"`
+	BidCount        int                          `json:"bid_count"`
+	WinningBidders  []string                     `json:"winning_bidders"`
+	ExcludedBidders []string                     `json:"excluded_bidders,omitempty"`
+	Bidders         map[string]TailBidderSummary `json:"bidders,omitempty"`
+	DurationMS      int64                        `json:"duration_ms"`
+	Status          string                       `json:"status"`
+	Error           string                       `json:"error,omitempty"`
+}
+
+// TailBidderSummary is one bidder's part in a tailed auction
+type TailBidderSummary struct {
+	Bids      int      `json:"bids"`
+	LatencyMS int64    `json:"latency_ms"`
+	TimedOut  bool     `json:"timed_out,omitempty"`
+	Errors    []string `json:"errors,omitempty"`
+}
+
+// tailSubscriber is one open /admin/debug/tail stream
+type tailSubscriber struct {
+	publisherID string
+	sample      float64
+	events      chan *AuctionSummary
+	dropped     atomic.Int64
+}
+
+// AuctionTail streams auction summaries for a single publisher over
+// server-sent events via GET /admin/debug/tail?publisher=pub-123.
+//
+// Optional parameters:
+//   - sample: fraction of auctions to stream, 0 < sample <= 1 (default 1)
+//   - minutes: how long to stream, 1-15 (default 5)
+//
+// Auctions are only summarized while someone is tailing their publisher,
+// so an idle tail costs the auction path one atomic load.
+type AuctionTail struct {
+	mu          sync.RWMutex
+	subscribers map[*tailSubscriber]struct{}
+	active      atomic.Int32
+
+	// draining is closed by Drain; streams wg tracks open streams
+	draining  chan struct{}
+	drainOnce sync.Once
+	streams   sync.WaitGroup
+
+	// minute is the unit for ?minutes=; shortened in tests
+	minute time.Duration
+}
+
+// NewAuctionTail creates an auction tail with no subscribers
+func NewAuctionTail() *AuctionTail {
+	return &AuctionTail{
+		subscribers: make(map[*tailSubscriber]struct{}),
+		draining:    make(chan struct{}),
+		minute:      time.Minute,
+	}
+}
+
+// Drain ends every open stream with a reconnect-after hint and refuses new
+// ones, then waits for the streams to close or ctx to end. Graceful shutdown
+// calls it first so tails, which never go idle on their own, don't hold the
+// instance up; clients reconnect to another instance.
+func (t *AuctionTail) Drain(ctx context.Context) error {
+	// Closed under mu so no stream subscribes after Wait starts
+	t.drainOnce.Do(func() {
+		t.mu.Lock()
+		close(t.draining)
+		t.mu.Unlock()
+	})
+
+	done := make(chan struct{})
+	go func() {
+		t.streams.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// isDraining reports whether Drain has been called
+func (t *AuctionTail) isDraining() bool {
+	select {
+	case <-t.draining:
+		return true
+	default:
+		return false
+	}
+}
+
+// Publish hands an auction to the publisher's subscribers. build is only
+// called when at least one subscriber samples the auction.
+func (t *AuctionTail) Publish(publisherID string, build func() *AuctionSummary) {
+	if t == nil || t.active.Load() == 0 || publisherID == "" {
+		return
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var summary *AuctionSummary
+	for sub := range t.subscribers {
+		if sub.publisherID != publisherID {
+			continue
+		}
+		if sub.sample < 1 && rand.Float64() >= sub.sample { // #nosec G404 -- sampling, not security
+			continue
+		}
+		if summary == nil {
+			summary = build()
+		}
+		// Never block the auction on a slow client
+		select {
+		case sub.events <- summary:
+		default:
+			sub.dropped.Add(1)
+		}
+	}
+}
+
+// subscribe registers a stream, or returns nil when the tail is full or
+// draining
+func (t *AuctionTail) subscribe(publisherID string, sample float64) *tailSubscriber {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.subscribers) >= maxTailSubscribers || t.isDraining() {
+		return nil
+	}
+	t.streams.Add(1)
+	sub := &tailSubscriber{
+		publisherID: publisherID,
+		sample:      sample,
+		events:      make(chan *AuctionSummary, tailBufferSize),
+	}
+	t.subscribers[sub] = struct{}{}
+	t.active.Add(1)
+	return sub
+}
+
+func (t *AuctionTail) unsubscribe(sub *tailSubscriber) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.subscribers[sub]; ok {
+		delete(t.subscribers, sub)
+		t.active.Add(-1)
+		t.streams.Done()
+	}
+}
+
+// ServeHTTP streams summaries until the client disconnects or the tail expires
+func (t *AuctionTail) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		sendAdminError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Use GET")
+		return
+	}
+
+	query := r.URL.Query()
+	publisherID := query.Get("publisher")
+	if publisherID == "" {
+		sendAdminError(w, http.StatusBadRequest, "missing_publisher", "publisher query parameter is required")
+		return
+	}
+
+	sample := 1.0
+	if v := query.Get("sample"); v != "" {
+		parsed, err := strconv.ParseFloat(v, 64)
+		if err != nil || parsed <= 0 || parsed > 1 {
+			sendAdminError(w, http.StatusBadRequest, "invalid_sample", "sample must be a number in (0, 1]")
+			return
+		}
+		sample = parsed
+	}
+
+	minutes := defaultTailMinutes
+	if v := query.Get("minutes"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 1 || parsed > maxTailMinutes {
+			sendAdminError(w, http.StatusBadRequest, "invalid_minutes", fmt.Sprintf("minutes must be between 1 and %d", maxTailMinutes))
+			return
+		}
+		minutes = parsed
+	}
+	duration := time.Duration(minutes) * t.minute
+
+	sub := t.subscribe(publisherID, sample)
+	if sub == nil && t.isDraining() {
+		w.Header().Set("Retry-After", strconv.Itoa(int(tailReconnectAfter.Seconds())))
+		sendAdminError(w, http.StatusServiceUnavailable, "draining", "Server is shutting down, reconnect to retry on another instance")
+		return
+	}
+	if sub == nil {
+		sendAdminError(w, http.StatusTooManyRequests, "too_many_tails", "Too many auction tails are open, try again later")
+		return
+	}
+	defer t.unsubscribe(sub)
+
+	// The stream outlives the server's write timeout, so extend it to the
+	// tail's own deadline. Writers that can't do this keep the default.
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Now().Add(duration + tailHeartbeatInterval))
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // Disable proxy buffering (nginx)
+	w.WriteHeader(http.StatusOK)
+
+	log := logger.Log.With().
+		Str("publisher_id", publisherID).
+		Float64("sample", sample).
+		Int("minutes", minutes).
+		Str("remote_addr", r.RemoteAddr).
+		Logger()
+	log.Info().Msg("Auction tail started")
+
+	expires := time.Now().Add(duration)
+	if err := writeTailEvent(w, rc, "start", map[string]interface{}{
+		"publisher_id": publisherID,
+		"sample":       sample,
+		"expires_at":   expires,
+	}); err != nil {
+		log.Warn().Err(err).Msg("Auction tail stream unsupported")
+		return
+	}
+
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	heartbeat := time.NewTicker(tailHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	sent := 0
+	reason := "client_closed"
+	defer func() {
+		log.Info().
+			Int("sent", sent).
+			Int64("dropped", sub.dropped.Load()).
+			Str("reason", reason).
+			Msg("Auction tail ended")
+	}()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-t.draining:
+			reason = "draining"
+			// retry: sets how long EventSource clients wait before reconnecting
+			if _, err := fmt.Fprintf(w, "retry: %d\n", tailReconnectAfter.Milliseconds()); err != nil {
+				return
+			}
+			_ = writeTailEvent(w, rc, "end", map[string]interface{}{
+				"reason":             reason,
+				"sent":               sent,
+				"dropped":            sub.dropped.Load(),
+				"reconnect_after_ms": tailReconnectAfter.Milliseconds(),
+			})
+			return
+		case <-timer.C:
+			reason = "expired"
+			_ = writeTailEvent(w, rc, "end", map[string]interface{}{
+				"reason":  reason,
+				"sent":    sent,
+				"dropped": sub.dropped.Load(),
+			})
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				reason = "write_failed"
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				reason = "write_failed"
+				return
+			}
+		case summary := <-sub.events:
+			if err := writeTailEvent(w, rc, "auction", summary); err != nil {
+				reason = "write_failed"
+				return
+			}
+			sent++
+		}
+	}
+}
+
+// writeTailEvent writes and flushes a single server-sent event
+func writeTailEvent(w http.ResponseWriter, rc *http.ResponseController, event string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+		return err
+	}
+	return rc.Flush()
+}
+
+// requestPublisherID returns the authenticated publisher, falling back to
+// the publisher named in the request
+func requestPublisherID(ctx context.Context, bidReq *openrtb.BidRequest) string {
+	if publisherID, ok := GetPublisherID(ctx); ok {
+		return publisherID
+	}
+	if bidReq.Site != nil && bidReq.Site.Publisher != nil {
+		return bidReq.Site.Publisher.ID
+	}
+	if bidReq.App != nil && bidReq.App.Publisher != nil {
+		return bidReq.App.Publisher.ID
+	}
+	return ""
+}
+
+// summarizeAuction builds the scrubbed tail view of an auction. result may
+// be nil when the auction failed.
+func summarizeAuction(ctx context.Context, publisherID string, req *openrtb.BidRequest, result *exchange.AuctionResponse, duration time.Duration, auctionErr error) *AuctionSummary {
+	summary := &AuctionSummary{
+		Time:           time.Now().UTC(),
+		AuctionID:      req.ID,
+		RequestID:      logger.RequestIDFromContext(ctx),
+		PublisherID:    publisherID,
+		ImpCount:       len(req.Imp),
+		WinningBidders: []string{},
+		DurationMS:     duration.Milliseconds(),
+		Status:         "success",
+	}
+	if req.Site != nil {
+		summary.Domain = req.Site.Domain
+	}
+	if req.App != nil {
+		summary.Bundle = req.App.Bundle
+	}
+	if req.Device != nil {
+		summary.DeviceType = req.Device.DeviceType
+		summary.IP = scrub.IP(req.Device.IP)
+		if req.Device.Geo != nil {
+			summary.Country = req.Device.Geo.Country
+		}
+	}
+
+	if auctionErr != nil {
+		summary.Status = "error"
+		summary.Error = auctionErr.Error()
+	}
+	if result == nil {
+		return summary
+	}
+
+	if result.BidResponse != nil {
+		for _, seatBid := range result.BidResponse.SeatBid {
+			summary.BidCount += len(seatBid.Bid)
+			if len(seatBid.Bid) > 0 && seatBid.Seat != "" {
+				summary.WinningBidders = append(summary.WinningBidders, seatBid.Seat)
+			}
+		}
+	}
+	if summary.BidCount == 0 && auctionErr == nil {
+		summary.Status = "no_bid"
+	}
+
+	if len(result.BidderResults) > 0 {
+		summary.Bidders = make(map[string]TailBidderSummary, len(result.BidderResults))
+		for code, br := range result.BidderResults {
+			if br == nil {
+				continue
+			}
+			bs := TailBidderSummary{
+				Bids:      len(br.Bids),
+				LatencyMS: br.Latency.Milliseconds(),
+				TimedOut:  br.TimedOut,
+			}
+			for _, err := range br.Errors {
+				bs.Errors = append(bs.Errors, err.Error())
+			}
+			summary.Bidders[code] = bs
+		}
+	}
+	if result.DebugInfo != nil && len(result.DebugInfo.ExcludedBidders) > 0 {
+		summary.ExcludedBidders = append([]string(nil), result.DebugInfo.ExcludedBidders...)
+		sort.Strings(summary.ExcludedBidders)
+	}
+
+	return summary
+}
@@ -0,0 +1,257 @@
+package endpoints
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/exchange"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+// readTailEvent reads the next named server-sent event, skipping comments
+func readTailEvent(t *testing.T, r *bufio.Reader) (string, string) {
+	t.Helper()
+	var event, data string
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("reading stream: %v", err)
+		}
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case line == "" && event != "":
+			return event, data
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func TestAuctionTail_PublishWithoutSubscribers(t *testing.T) {
+	tail := NewAuctionTail()
+	tail.Publish("pub-123", func() *AuctionSummary {
+		t.Fatal("summary built with no subscribers")
+		return nil
+	})
+
+	var nilTail *AuctionTail
+	nilTail.Publish("pub-123", func() *AuctionSummary {
+		t.Fatal("summary built on nil tail")
+		return nil
+	})
+}
+
+func TestAuctionTail_PublishFiltersByPublisher(t *testing.T) {
+	tail := NewAuctionTail()
+	sub := tail.subscribe("pub-123", 1)
+	defer tail.unsubscribe(sub)
+
+	builds := 0
+	build := func() *AuctionSummary {
+		builds++
+		return &AuctionSummary{AuctionID: "a1"}
+	}
+	tail.Publish("pub-other", build)
+	tail.Publish("pub-123", build)
+
+	if builds != 1 {
+		t.Errorf("expected 1 summary built, got %d", builds)
+	}
+	if len(sub.events) != 1 {
+		t.Errorf("expected 1 queued summary, got %d", len(sub.events))
+	}
+
+	// A full buffer drops summaries instead of blocking the auction
+	for i := 0; i < tailBufferSize; i++ {
+		tail.Publish("pub-123", build)
+	}
+	if sub.dropped.Load() != 1 {
+		t.Errorf("expected 1 dropped summary, got %d", sub.dropped.Load())
+	}
+}
+
+func TestAuctionTail_SubscriberLimit(t *testing.T) {
+	tail := NewAuctionTail()
+	for i := 0; i < maxTailSubscribers; i++ {
+		if tail.subscribe("pub-123", 1) == nil {
+			t.Fatalf("subscriber %d rejected", i)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/debug/tail?publisher=pub-123", nil)
+	rec := httptest.NewRecorder()
+	tail.ServeHTTP(rec, req)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Errorf("expected 429, got %d", rec.Code)
+	}
+}
+
+func TestAuctionTail_InvalidParams(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		query  string
+		status int
+	}{
+		{"wrong method", http.MethodPost, "?publisher=pub-123", http.StatusMethodNotAllowed},
+		{"missing publisher", http.MethodGet, "", http.StatusBadRequest},
+		{"zero sample", http.MethodGet, "?publisher=pub-123&sample=0", http.StatusBadRequest},
+		{"sample above one", http.MethodGet, "?publisher=pub-123&sample=1.5", http.StatusBadRequest},
+		{"minutes too long", http.MethodGet, "?publisher=pub-123&minutes=60", http.StatusBadRequest},
+		{"minutes not a number", http.MethodGet, "?publisher=pub-123&minutes=abc", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			NewAuctionTail().ServeHTTP(rec, httptest.NewRequest(tt.method, "/admin/debug/tail"+tt.query, nil))
+			if rec.Code != tt.status {
+				t.Errorf("expected %d, got %d", tt.status, rec.Code)
+			}
+		})
+	}
+}
+
+func TestAuctionTail_Stream(t *testing.T) {
+	tail := NewAuctionTail()
+	tail.minute = 200 * time.Millisecond
+	server := httptest.NewServer(tail)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/admin/debug/tail?publisher=pub-123&minutes=1")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("expected text/event-stream, got %q", ct)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	if event, _ := readTailEvent(t, reader); event != "start" {
+		t.Fatalf("expected start event, got %q", event)
+	}
+
+	tail.Publish("pub-123", func() *AuctionSummary {
+		return &AuctionSummary{AuctionID: "auction-1", PublisherID: "pub-123", Status: "no_bid"}
+	})
+
+	event, data := readTailEvent(t, reader)
+	if event != "auction" {
+		t.Fatalf("expected auction event, got %q", event)
+	}
+	var summary AuctionSummary
+	if err := json.Unmarshal([]byte(data), &summary); err != nil {
+		t.Fatalf("invalid summary: %v", err)
+	}
+	if summary.AuctionID != "auction-1" || summary.Status != "no_bid" {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+
+	if event, _ := readTailEvent(t, reader); event != "end" {
+		t.Errorf("expected end event, got %q", event)
+	}
+	// The handler unsubscribes after writing the end event
+	deadline := time.Now().Add(time.Second)
+	for tail.active.Load() != 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if tail.active.Load() != 0 {
+		t.Errorf("expected subscriber removed after expiry, got %d", tail.active.Load())
+	}
+}
+
+func TestAuctionTail_Drain(t *testing.T) {
+	tail := NewAuctionTail()
+	server := httptest.NewServer(tail)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/admin/debug/tail?publisher=pub-123")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	reader := bufio.NewReader(resp.Body)
+	if event, _ := readTailEvent(t, reader); event != "start" {
+		t.Fatalf("expected start event, got %q", event)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := tail.Drain(ctx); err != nil {
+		t.Fatalf("expected open streams to close, got %v", err)
+	}
+
+	retry, err := reader.ReadString('\n')
+	if err != nil || retry != "retry: 5000\n" {
+		t.Errorf("expected a reconnect hint, got %q, %v", retry, err)
+	}
+	event, data := readTailEvent(t, reader)
+	if event != "end" || !strings.Contains(data, `"reason":"draining"`) {
+		t.Errorf("expected a draining end event, got %q %s", event, data)
+	}
+
+	rec := httptest.NewRecorder()
+	tail.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/debug/tail?publisher=pub-123", nil))
+	if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") != "5" {
+		t.Errorf("expected new tails refused while draining, got %d (Retry-After %q)", rec.Code, rec.Header().Get("Retry-After"))
+	}
+}
+
+func TestSummarizeAuction(t *testing.T) {
+	req := &openrtb.BidRequest{
+		ID:   "auction-1",
+		Imp:  []openrtb.Imp{{ID: "imp1"}, {ID: "imp2"}},
+		Site: &openrtb.Site{Domain: "example.com"},
+		Device: &openrtb.Device{
+			IP:         "203.0.113.45",
+			DeviceType: 2,
+			Geo:        &openrtb.Geo{Country: "USA"},
+		},
+	}
+	result := &exchange.AuctionResponse{
+		BidResponse: &openrtb.BidResponse{
+			SeatBid: []openrtb.SeatBid{{Seat: "appnexus", Bid: []openrtb.Bid{{ID: "b1"}}}},
+		},
+		BidderResults: map[string]*exchange.BidderResult{
+			"appnexus": {Bids: []*adapters.TypedBid{{}}, Latency: 42 * time.Millisecond},
+			"rubicon":  {Errors: []error{errors.New("timeout")}, TimedOut: true},
+		},
+		DebugInfo: &exchange.DebugInfo{ExcludedBidders: []string{"pubmatic"}},
+	}
+
+	summary := summarizeAuction(context.Background(), "pub-123", req, result, 80*time.Millisecond, nil)
+
+	if summary.Status != "success" || summary.BidCount != 1 || summary.ImpCount != 2 {
+		t.Errorf("unexpected counts: %+v", summary)
+	}
+	if summary.IP == "203.0.113.45" {
+		t.Error("expected IP to be scrubbed")
+	}
+	if summary.Domain != "example.com" || summary.Country != "USA" || summary.DurationMS != 80 {
+		t.Errorf("unexpected request context: %+v", summary)
+	}
+	if b := summary.Bidders["appnexus"]; b.Bids != 1 || b.LatencyMS != 42 {
+		t.Errorf("unexpected appnexus summary: %+v", b)
+	}
+	if b := summary.Bidders["rubicon"]; !b.TimedOut || len(b.Errors) != 1 {
+		t.Errorf("unexpected rubicon summary: %+v", b)
+	}
+	if len(summary.ExcludedBidders) != 1 || summary.ExcludedBidders[0] != "pubmatic" {
+		t.Errorf("unexpected excluded bidders: %v", summary.ExcludedBidders)
+	}
+
+	failed := summarizeAuction(context.Background(), "pub-123", req, nil, time.Millisecond, errors.New("boom"))
+	if failed.Status != "error" || failed.Error != "boom" {
+		t.Errorf("unexpected failed summary: %+v", failed)
+	}
+}
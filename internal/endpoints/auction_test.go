@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -16,6 +17,7 @@ import (
 	"github.com/thenexusengine/tne_springwire/internal/exchange"
 	"github.com/thenexusengine/tne_springwire/internal/middleware"
 	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/internal/storedrequest"
 )
 
 // Mock adapter for testing
@@ -273,6 +275,113 @@ func TestAuctionHandler_ValidRequest_NoBidders(t *testing.T) {
 	}
 }
 
+// upgradeCounts records legacy upgrades by rule
+type upgradeCounts map[string]int
+
+func (c upgradeCounts) RecordLegacyUpgrade(rule string) {
+	c[rule]++
+}
+
+func TestAuctionHandler_LegacyRequestUpgraded(t *testing.T) {
+	registry := adapters.NewRegistry()
+	ex := exchange.New(registry, &exchange.Config{
+		DefaultTimeout: 100 * time.Millisecond,
+	})
+	handler := NewAuctionHandler(ex)
+	counts := upgradeCounts{}
+	handler.SetUpgradeRecorder(counts)
+
+	body := `{"id":"legacy-1","site":{"publisher":{"id":"pub-1"}},"imp":[{"id":"1","video":{"mimes":["video/mp4"],"protocols":2,"w":640,"h":480}}],"user":{"gender":1}}`
+	req := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(body))
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if counts["protocols"] != 1 || counts["gender"] != 1 || len(counts) != 2 {
+		t.Errorf("unexpected upgrade counts: %v", counts)
+	}
+}
+
+type sloRecord struct {
+	publisherID string
+	targetMs    int
+}
+
+type sloRecords []sloRecord
+
+func (r *sloRecords) Record(publisherID string, targetMs int, latency time.Duration) {
+	*r = append(*r, sloRecord{publisherID, targetMs})
+}
+
+type sloPublisher struct{ targetMs int }
+
+func (p sloPublisher) GetSLOP95Ms() int { return p.targetMs }
+
+func TestAuctionHandler_RecordsSLO(t *testing.T) {
+	registry := adapters.NewRegistry()
+	ex := exchange.New(registry, &exchange.Config{
+		DefaultTimeout: 100 * time.Millisecond,
+	})
+	handler := NewAuctionHandler(ex)
+	records := &sloRecords{}
+	handler.SetSLORecorder(records)
+
+	body := `{"id":"slo-1","site":{"publisher":{"id":"pub-1"}},"imp":[{"id":"1","video":{"mimes":["video/mp4"]}}]}`
+	ctx := middleware.NewContextWithPublisher(context.Background(), sloPublisher{targetMs: 250})
+	req := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(body)).WithContext(ctx)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	// Invalid requests never reach the auction and aren't counted
+	req = httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(`{"id":"slo-2"}`))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if len(*records) != 1 || (*records)[0] != (sloRecord{"pub-1", 250}) {
+		t.Errorf("unexpected SLO records: %+v", *records)
+	}
+}
+
+// stubStoredRequests replaces stored request references with a fixed body
+type stubStoredRequests struct {
+	body []byte
+	err  error
+}
+
+func (s stubStoredRequests) Resolve(ctx context.Context, body []byte, publisherID string) ([]byte, error) {
+	return s.body, s.err
+}
+
+func TestAuctionHandler_StoredRequests(t *testing.T) {
+	registry := adapters.NewRegistry()
+	ex := exchange.New(registry, &exchange.Config{
+		DefaultTimeout: 100 * time.Millisecond,
+	})
+	body := `{"id":"stored-1","ext":{"prebid":{"storedrequest":{"id":"homepage"}}}}`
+
+	tests := []struct {
+		name     string
+		resolver stubStoredRequests
+		want     int
+	}{
+		{"expanded", stubStoredRequests{body: []byte(`{"id":"stored-1","site":{"publisher":{"id":"pub-1"}},"imp":[{"id":"1","banner":{"w":300,"h":250}}]}`)}, http.StatusOK},
+		{"invalid reference", stubStoredRequests{err: fmt.Errorf("%w: stored request homepage not found", storedrequest.ErrInvalidReference)}, http.StatusBadRequest},
+		{"store unavailable", stubStoredRequests{err: errors.New("connection refused")}, http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewAuctionHandler(ex)
+			handler.SetStoredRequests(tt.resolver)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(body)))
+			if w.Code != tt.want {
+				t.Errorf("expected %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
 func TestAuctionHandler_DebugMode(t *testing.T) {
 	registry := adapters.NewRegistry()
 	mock := &mockAdapter{bids: []*adapters.TypedBid{}}
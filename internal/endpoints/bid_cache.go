@@ -0,0 +1,193 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/bidcache"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+// maxCachePuts bounds the entries in a single /cache write
+const maxCachePuts = 100
+
+// BidCacheStore stores and returns cached bid markup; implemented by
+// bidcache.Cache
+type BidCacheStore interface {
+	Check(entry bidcache.Entry) error
+	Put(ctx context.Context, publisherID string, entry bidcache.Entry, ttl time.Duration) (string, error)
+	Get(ctx context.Context, id string) (*bidcache.Entry, error)
+}
+
+// CachePutRequest is a Prebid Cache compatible write:
+// {"puts": [{"type": "xml", "value": "<VAST ...>", "ttlseconds": 300}]}
+type CachePutRequest struct {
+	Puts []CachePut `json:"puts"`
+}
+
+// CachePut is one entry to store. Value is a JSON string for xml entries and
+// any JSON value for json entries.
+type CachePut struct {
+	Type       string          `json:"type"`
+	Value      json.RawMessage `json:"value"`
+	TTLSeconds int             `json:"ttlseconds,omitempty"`
+}
+
+// CachePutResponse returns the UUIDs of stored entries, in request order
+type CachePutResponse struct {
+	Responses []CachePutResult `json:"responses"`
+}
+
+// CachePutResult is the UUID of one stored entry
+type CachePutResult struct {
+	UUID string `json:"uuid"`
+}
+
+// BidCacheHandler serves /cache in the Prebid Cache format.
+//
+// POST stores entries for the publisher authenticated by its API key, within
+// the per-entry size limit and the publisher's storage quota. GET ?uuid=
+// returns an entry and is public so players can fetch markup.
+type BidCacheHandler struct {
+	store BidCacheStore
+}
+
+// NewBidCacheHandler creates a new bid cache handler
+func NewBidCacheHandler(store BidCacheStore) *BidCacheHandler {
+	return &BidCacheHandler{store: store}
+}
+
+// ServeHTTP handles /cache requests
+func (h *BidCacheHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		h.handleGet(w, r)
+	case http.MethodPost:
+		h.handlePut(w, r)
+	default:
+		writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *BidCacheHandler) handleGet(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("uuid")
+	if id == "" {
+		writeError(w, "uuid is required", http.StatusBadRequest)
+		return
+	}
+
+	entry, err := h.store.Get(r.Context(), id)
+	if err != nil {
+		logger.Log.Error().Err(err).Str("uuid", id).Msg("Failed to read cache entry")
+		writeError(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if entry == nil {
+		writeError(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	contentType := "application/json"
+	if entry.Type == bidcache.TypeXML {
+		contentType = "application/xml"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodGet {
+		_, _ = w.Write(entry.Value)
+	}
+}
+
+func (h *BidCacheHandler) handlePut(w http.ResponseWriter, r *http.Request) {
+	publisherID, ok := GetPublisherID(r.Context())
+	if !ok {
+		writeError(w, "Publisher API key required", http.StatusUnauthorized)
+		return
+	}
+
+	defer r.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestBodySize))
+	if err != nil {
+		writeError(w, "Failed to read request body", http.StatusBadRequest)
+		return
+	}
+
+	var req CachePutRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeError(w, "Invalid JSON in request body", http.StatusBadRequest)
+		return
+	}
+	if len(req.Puts) == 0 {
+		writeError(w, "puts must not be empty", http.StatusBadRequest)
+		return
+	}
+	if len(req.Puts) > maxCachePuts {
+		writeError(w, fmt.Sprintf("at most %d puts per request", maxCachePuts), http.StatusBadRequest)
+		return
+	}
+
+	// Check every entry before storing any so a bad batch uses no quota
+	entries := make([]bidcache.Entry, len(req.Puts))
+	for i, put := range req.Puts {
+		entry, err := cacheEntry(put)
+		if err != nil {
+			writeError(w, fmt.Sprintf("puts[%d]: %v", i, err), http.StatusBadRequest)
+			return
+		}
+		if err := h.store.Check(entry); err != nil {
+			writeCacheError(w, fmt.Errorf("puts[%d]: %w", i, err))
+			return
+		}
+		entries[i] = entry
+	}
+
+	resp := CachePutResponse{Responses: make([]CachePutResult, 0, len(entries))}
+	for i, entry := range entries {
+		id, err := h.store.Put(r.Context(), publisherID, entry, time.Duration(req.Puts[i].TTLSeconds)*time.Second)
+		if err != nil {
+			logger.Log.Warn().Err(err).Str("publisher_id", publisherID).Int("stored", len(resp.Responses)).Msg("Cache write rejected")
+			writeCacheError(w, fmt.Errorf("puts[%d]: %w", i, err))
+			return
+		}
+		resp.Responses = append(resp.Responses, CachePutResult{UUID: id})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		logger.Log.Error().Err(err).Msg("Failed to encode cache response")
+	}
+}
+
+// cacheEntry converts a put into a cache entry; xml values must be strings
+func cacheEntry(put CachePut) (bidcache.Entry, error) {
+	entry := bidcache.Entry{Type: put.Type, Value: put.Value}
+	if put.Type == bidcache.TypeXML {
+		var markup string
+		if err := json.Unmarshal(put.Value, &markup); err != nil {
+			return entry, errors.New("xml value must be a string")
+		}
+		entry.Value = []byte(markup)
+	}
+	return entry, nil
+}
+
+// writeCacheError maps cache errors to HTTP statuses
+func writeCacheError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, bidcache.ErrValueTooLarge):
+		writeError(w, err.Error(), http.StatusRequestEntityTooLarge)
+	case errors.Is(err, bidcache.ErrQuotaExceeded):
+		writeError(w, err.Error(), http.StatusTooManyRequests)
+	case errors.Is(err, bidcache.ErrInvalidType):
+		writeError(w, err.Error(), http.StatusBadRequest)
+	default:
+		logger.Log.Error().Err(err).Msg("Cache write failed")
+		writeError(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,99 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/thenexusengine/tne_springwire/internal/bidcache"
+)
+
+func newTestBidCacheHandler(t *testing.T, cfg bidcache.Config) *BidCacheHandler {
+	t.Helper()
+	client, _ := setupTestRedisForPublisher(t)
+	return NewBidCacheHandler(bidcache.New(client, cfg, nil))
+}
+
+func cachePut(h http.Handler, publisherID, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/cache", strings.NewReader(body))
+	if publisherID != "" {
+		req = req.WithContext(context.WithValue(req.Context(), "publisher_id", publisherID))
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestBidCacheHandler_PutAndGet(t *testing.T) {
+	h := newTestBidCacheHandler(t, bidcache.DefaultConfig())
+
+	w := cachePut(h, "pub-1", `{"puts":[{"type":"xml","value":"<VAST version=\"4.0\"/>"},{"type":"json","value":{"adm":"<div/>"},"ttlseconds":60}]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp CachePutResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || len(resp.Responses) != 2 {
+		t.Fatalf("unexpected response: %s", w.Body.String())
+	}
+
+	tests := []struct {
+		uuid        string
+		contentType string
+		body        string
+	}{
+		{resp.Responses[0].UUID, "application/xml", `<VAST version="4.0"/>`},
+		{resp.Responses[1].UUID, "application/json", `{"adm":"<div/>"}`},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/cache?uuid="+tt.uuid, nil))
+		if w.Code != http.StatusOK || w.Header().Get("Content-Type") != tt.contentType || w.Body.String() != tt.body {
+			t.Errorf("GET %s: got %d %q %q", tt.uuid, w.Code, w.Header().Get("Content-Type"), w.Body.String())
+		}
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/cache?uuid=missing", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for missing entry, got %d", w.Code)
+	}
+}
+
+func TestBidCacheHandler_Rejections(t *testing.T) {
+	cfg := bidcache.DefaultConfig()
+	cfg.MaxValueBytes = 10
+	cfg.PublisherQuotaBytes = 15
+
+	tests := []struct {
+		name        string
+		publisherID string
+		body        string
+		status      int
+	}{
+		{"no publisher key", "", `{"puts":[{"type":"xml","value":"<VAST/>"}]}`, http.StatusUnauthorized},
+		{"invalid json", "pub-1", `{"puts":`, http.StatusBadRequest},
+		{"no puts", "pub-1", `{"puts":[]}`, http.StatusBadRequest},
+		{"invalid type", "pub-1", `{"puts":[{"type":"html","value":"<div/>"}]}`, http.StatusBadRequest},
+		{"xml not a string", "pub-1", `{"puts":[{"type":"xml","value":{}}]}`, http.StatusBadRequest},
+		{"value too large", "pub-1", `{"puts":[{"type":"xml","value":"<VAST/>"},{"type":"xml","value":"<VAST></VAST>"}]}`, http.StatusRequestEntityTooLarge},
+		{"quota exceeded", "pub-1", `{"puts":[{"type":"xml","value":"<VAST/>"},{"type":"xml","value":"<VAST/>"},{"type":"xml","value":"<VAST/>"}]}`, http.StatusTooManyRequests},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestBidCacheHandler(t, cfg)
+			if w := cachePut(h, tt.publisherID, tt.body); w.Code != tt.status {
+				t.Errorf("expected %d, got %d: %s", tt.status, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	h := newTestBidCacheHandler(t, cfg)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/cache", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405, got %d", w.Code)
+	}
+}
@@ -0,0 +1,579 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+// bidderAdminPrefix is the path prefix for bidder administration
+const bidderAdminPrefix = "/admin/api/bidders"
+
+// maxBidderBodySize caps create and update request bodies
+const maxBidderBodySize = 64 * 1024
+
+// Bidder field limits, matching the bidders table constraints
+const (
+	maxBidderCodeLength  = 50
+	maxBidderNameLength  = 255
+	defaultBidderTimeout = 1000
+	minBidderTimeout     = 100
+	maxBidderTimeout     = 10000
+)
+
+// restoreResolveTimeout bounds the DNS lookup of a restored bidder's
+// endpoint
+const restoreResolveTimeout = 2 * time.Second
+
+// bidderCodePattern is what a bidder_code may look like, e.g. "rubicon" or
+// "33across"
+var bidderCodePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// BidderAdminStore is the subset of the bidder store used by the admin API
+type BidderAdminStore interface {
+	List(ctx context.Context) ([]*storage.Bidder, error)
+	ListPage(ctx context.Context, opts storage.ListOptions) ([]*storage.Bidder, int, error)
+	Create(ctx context.Context, b *storage.Bidder) error
+	Update(ctx context.Context, b *storage.Bidder) error
+	Delete(ctx context.Context, bidderCode string) error
+	SetEnabled(ctx context.Context, bidderCode string, enabled bool) error
+	SetGDPRScope(ctx context.Context, bidderCode, scope string) error
+	Restore(ctx context.Context, b *storage.Bidder) error
+}
+
+// GDPRScopeRequest is the body for setting a bidder's GDPR scope
+type GDPRScopeRequest struct {
+	GDPRScope string `json:"gdpr_scope"` // always, eea_only or never
+}
+
+// BidderRequest is the body for creating or replacing a bidder. Update
+// replaces every field, and Version must be the version the change was
+// based on; a stale version is rejected with 409.
+type BidderRequest struct {
+	BidderCode       string                 `json:"bidder_code"`
+	BidderName       string                 `json:"bidder_name"`
+	EndpointURL      string                 `json:"endpoint_url"`
+	TimeoutMs        int                    `json:"timeout_ms,omitempty"` // 0 = 1000
+	Enabled          bool                   `json:"enabled"`
+	Status           string                 `json:"status,omitempty"` // active (default), testing or disabled
+	SupportsBanner   bool                   `json:"supports_banner"`
+	SupportsVideo    bool                   `json:"supports_video"`
+	SupportsNative   bool                   `json:"supports_native"`
+	SupportsAudio    bool                   `json:"supports_audio"`
+	GVLVendorID      *int                   `json:"gvl_vendor_id,omitempty"`
+	HTTPHeaders      map[string]interface{} `json:"http_headers,omitempty"`
+	Description      string                 `json:"description,omitempty"`
+	DocumentationURL string                 `json:"documentation_url,omitempty"`
+	ContactEmail     string                 `json:"contact_email,omitempty"`
+	Version          int                    `json:"version,omitempty"` // Required on update
+}
+
+// validate checks the request against the bidders table constraints and
+// fills in defaults
+func (req *BidderRequest) validate() error {
+	if req.BidderCode == "" {
+		return errors.New("bidder_code is required")
+	}
+	if len(req.BidderCode) > maxBidderCodeLength || !bidderCodePattern.MatchString(req.BidderCode) {
+		return fmt.Errorf("bidder_code must be at most %d lowercase letters, digits, '_' or '-'", maxBidderCodeLength)
+	}
+	if req.BidderName == "" || len(req.BidderName) > maxBidderNameLength {
+		return fmt.Errorf("bidder_name is required and must be at most %d characters", maxBidderNameLength)
+	}
+	if u, err := url.Parse(req.EndpointURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("endpoint_url must be an absolute http or https URL")
+	}
+	if req.TimeoutMs == 0 {
+		req.TimeoutMs = defaultBidderTimeout
+	}
+	if req.TimeoutMs < minBidderTimeout || req.TimeoutMs > maxBidderTimeout {
+		return fmt.Errorf("timeout_ms must be between %d and %d", minBidderTimeout, maxBidderTimeout)
+	}
+	switch req.Status {
+	case "":
+		req.Status = "active"
+	case "active", "testing", "disabled":
+	default:
+		return errors.New("status must be active, testing or disabled; use DELETE to archive")
+	}
+	if req.GVLVendorID != nil && *req.GVLVendorID <= 0 {
+		return errors.New("gvl_vendor_id must be positive")
+	}
+	return nil
+}
+
+// bidder converts the request to a storage.Bidder
+func (req *BidderRequest) bidder() *storage.Bidder {
+	return &storage.Bidder{
+		BidderCode:       req.BidderCode,
+		BidderName:       req.BidderName,
+		EndpointURL:      req.EndpointURL,
+		TimeoutMs:        req.TimeoutMs,
+		Enabled:          req.Enabled,
+		Status:           req.Status,
+		SupportsBanner:   req.SupportsBanner,
+		SupportsVideo:    req.SupportsVideo,
+		SupportsNative:   req.SupportsNative,
+		SupportsAudio:    req.SupportsAudio,
+		GVLVendorID:      req.GVLVendorID,
+		HTTPHeaders:      req.HTTPHeaders,
+		Description:      req.Description,
+		DocumentationURL: req.DocumentationURL,
+		ContactEmail:     req.ContactEmail,
+		Version:          req.Version,
+	}
+}
+
+// restoreRequest re-creates the request a stored bidder would have been
+// saved with, so a restore is validated like a create
+func restoreRequest(b *storage.Bidder) *BidderRequest {
+	return &BidderRequest{
+		BidderCode:       b.BidderCode,
+		BidderName:       b.BidderName,
+		EndpointURL:      b.EndpointURL,
+		TimeoutMs:        b.TimeoutMs,
+		Enabled:          true,
+		Status:           "active",
+		SupportsBanner:   b.SupportsBanner,
+		SupportsVideo:    b.SupportsVideo,
+		SupportsNative:   b.SupportsNative,
+		SupportsAudio:    b.SupportsAudio,
+		GVLVendorID:      b.GVLVendorID,
+		HTTPHeaders:      b.HTTPHeaders,
+		Description:      b.Description,
+		DocumentationURL: b.DocumentationURL,
+		ContactEmail:     b.ContactEmail,
+		Version:          b.Version,
+	}
+}
+
+// BidderListResponse is the response for listing bidders
+type BidderListResponse struct {
+	Bidders    []*storage.Bidder `json:"bidders"`
+	Count      int               `json:"count"` // Bidders in this page
+	Total      int               `json:"total"` // Bidders matching the filter
+	NextCursor string            `json:"next_cursor,omitempty"`
+}
+
+// BidderAdminHandler handles bidder administration via API
+type BidderAdminHandler struct {
+	store       BidderAdminStore
+	invalidator Invalidator
+	lookupHost  func(ctx context.Context, host string) ([]string, error)
+}
+
+// NewBidderAdminHandler creates a new bidder admin handler
+func NewBidderAdminHandler(store BidderAdminStore) *BidderAdminHandler {
+	return &BidderAdminHandler{store: store, lookupHost: net.DefaultResolver.LookupHost}
+}
+
+// SetInvalidator makes every change reload the bidder policies (GDPR scopes,
+// ext passthrough, QPS caps) cached on each replica
+func (h *BidderAdminHandler) SetInvalidator(invalidator Invalidator) {
+	h.invalidator = invalidator
+}
+
+// ServeHTTP handles bidder API requests
+// Routes:
+//
+//	GET    /admin/api/bidders               - List bidders (?limit, ?offset, ?cursor, ?status, ?sort);
+//	                                          ?status=archived browses archived bidders
+//	POST   /admin/api/bidders               - Create bidder
+//	GET    /admin/api/bidders/:code         - Get specific bidder
+//	PUT    /admin/api/bidders/:code         - Replace bidder (requires the current version)
+//	DELETE /admin/api/bidders/:code         - Archive bidder
+//	POST   /admin/api/bidders/:code/enable  - Enable bidder
+//	POST   /admin/api/bidders/:code/disable - Disable bidder
+//	POST   /admin/api/bidders/:code/restore - Re-validate and re-enable an archived bidder
+//	PUT    /admin/api/bidders/:code/gdpr_scope - Set when GDPR vendor consent is enforced
+func (h *BidderAdminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.store == nil {
+		sendAdminError(w, http.StatusServiceUnavailable, "database_unavailable", "Bidder management requires a database connection")
+		return
+	}
+
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, bidderAdminPrefix), "/")
+	var parts []string
+	if path != "" {
+		parts = strings.Split(path, "/")
+	}
+
+	switch {
+	case len(parts) == 0 && r.Method == http.MethodGet:
+		h.listBidders(w, r)
+	case len(parts) == 0 && r.Method == http.MethodPost:
+		h.createBidder(w, r)
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		h.getBidder(w, r, parts[0])
+	case len(parts) == 1 && r.Method == http.MethodPut:
+		h.updateBidder(w, r, parts[0])
+	case len(parts) == 1 && r.Method == http.MethodDelete:
+		h.archiveBidder(w, r, parts[0])
+	case len(parts) == 2 && r.Method == http.MethodPost && (parts[1] == "enable" || parts[1] == "disable"):
+		h.setEnabled(w, r, parts[0], parts[1] == "enable")
+	case len(parts) == 2 && r.Method == http.MethodPost && parts[1] == "restore":
+		h.restoreBidder(w, r, parts[0])
+	case len(parts) == 2 && r.Method == http.MethodPut && parts[1] == "gdpr_scope":
+		h.setGDPRScope(w, r, parts[0])
+	case len(parts) > 2 || (len(parts) == 2 && parts[1] != "enable" && parts[1] != "disable" && parts[1] != "restore" && parts[1] != "gdpr_scope"):
+		sendAdminError(w, http.StatusNotFound, "not_found", "Unknown bidder admin route")
+	default:
+		sendAdminError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+	}
+}
+
+// listBidders returns a page of bidders, including disabled ones
+func (h *BidderAdminHandler) listBidders(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseListOptions(r)
+	if err != nil {
+		sendAdminError(w, http.StatusBadRequest, "invalid_list_options", err.Error())
+		return
+	}
+
+	bidders, total, err := h.store.ListPage(r.Context(), opts)
+	if errors.Is(err, storage.ErrInvalidListOptions) {
+		sendAdminError(w, http.StatusBadRequest, "invalid_list_options", err.Error())
+		return
+	}
+	if err != nil {
+		logger.Log.Error().Err(err).Msg("Failed to list bidders")
+		sendAdminError(w, http.StatusInternalServerError, "database_error", "Failed to retrieve bidders")
+		return
+	}
+	if bidders == nil {
+		bidders = []*storage.Bidder{}
+	}
+
+	var cursor string
+	if len(bidders) > 0 {
+		cursor = nextCursor(opts, "bidder_code", len(bidders), bidders[len(bidders)-1].BidderCode)
+	}
+	setListHeaders(w, total, cursor)
+	sendAdminJSON(w, http.StatusOK, BidderListResponse{Bidders: bidders, Count: len(bidders), Total: total, NextCursor: cursor})
+}
+
+// getBidder returns a bidder by code
+func (h *BidderAdminHandler) getBidder(w http.ResponseWriter, r *http.Request, bidderCode string) {
+	bidder, err := h.findBidder(r.Context(), bidderCode)
+	if err != nil {
+		if !sendStorageError(w, err) {
+			logger.Log.Error().Err(err).Str("bidder", bidderCode).Msg("Failed to get bidder")
+			sendAdminError(w, http.StatusInternalServerError, "database_error", "Failed to retrieve bidder")
+		}
+		return
+	}
+	sendAdminJSON(w, http.StatusOK, bidder)
+}
+
+// createBidder adds a bidder
+func (h *BidderAdminHandler) createBidder(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeBidderRequest(w, r)
+	if !ok {
+		return
+	}
+	if req.Version != 0 {
+		sendAdminError(w, http.StatusBadRequest, "invalid_request", "version is assigned on create")
+		return
+	}
+
+	bidder := req.bidder()
+	if err := h.store.Create(r.Context(), bidder); err != nil {
+		if !sendStorageError(w, err) {
+			logger.Log.Error().Err(err).Str("bidder", req.BidderCode).Msg("Failed to create bidder")
+			sendAdminError(w, http.StatusInternalServerError, "database_error", "Failed to create bidder")
+		}
+		return
+	}
+
+	logger.Log.Info().
+		Str("bidder", bidder.BidderCode).
+		Str("endpoint", bidder.EndpointURL).
+		Bool("enabled", bidder.Enabled).
+		Msg("Bidder created")
+
+	h.invalidate(r.Context(), bidder.BidderCode)
+	sendAdminJSON(w, http.StatusCreated, bidder)
+}
+
+// updateBidder replaces a bidder, failing with 409 if it changed since the
+// version the caller read
+func (h *BidderAdminHandler) updateBidder(w http.ResponseWriter, r *http.Request, bidderCode string) {
+	req, ok := decodeBidderRequest(w, r)
+	if !ok {
+		return
+	}
+	if req.BidderCode != bidderCode {
+		sendAdminError(w, http.StatusBadRequest, "invalid_request", "bidder_code does not match the URL")
+		return
+	}
+	if req.Version <= 0 {
+		sendAdminError(w, http.StatusBadRequest, "invalid_request", "version is required; read the bidder first")
+		return
+	}
+
+	ctx := r.Context()
+	bidder := req.bidder()
+	if err := h.store.Update(ctx, bidder); err != nil {
+		if !sendStorageError(w, err) {
+			logger.Log.Error().Err(err).Str("bidder", bidderCode).Msg("Failed to update bidder")
+			sendAdminError(w, http.StatusInternalServerError, "database_error", "Failed to update bidder")
+		}
+		return
+	}
+
+	logger.Log.Info().
+		Str("bidder", bidderCode).
+		Int("version", bidder.Version).
+		Msg("Bidder updated")
+
+	h.invalidate(ctx, bidderCode)
+
+	// Re-read for the stored id and timestamps; the update itself succeeded
+	if stored, err := h.findBidder(ctx, bidderCode); err == nil {
+		bidder = stored
+	}
+	sendAdminJSON(w, http.StatusOK, bidder)
+}
+
+// archiveBidder soft-deletes a bidder; it stays in the table, disabled, with
+// status archived
+func (h *BidderAdminHandler) archiveBidder(w http.ResponseWriter, r *http.Request, bidderCode string) {
+	ctx := r.Context()
+	if err := h.store.Delete(ctx, bidderCode); err != nil {
+		if !sendStorageError(w, err) {
+			logger.Log.Error().Err(err).Str("bidder", bidderCode).Msg("Failed to archive bidder")
+			sendAdminError(w, http.StatusInternalServerError, "database_error", "Failed to archive bidder")
+		}
+		return
+	}
+
+	logger.Log.Info().Str("bidder", bidderCode).Msg("Bidder archived")
+
+	h.invalidate(ctx, bidderCode)
+	sendAdminJSON(w, http.StatusOK, map[string]string{"bidder_code": bidderCode, "status": "archived"})
+}
+
+// restoreBidder brings an archived bidder back, enabled with status active.
+// Its stored configuration must still pass the checks a create would, and
+// its endpoint host must still resolve: a bidder whose endpoint has gone
+// away would only time out on every auction.
+func (h *BidderAdminHandler) restoreBidder(w http.ResponseWriter, r *http.Request, bidderCode string) {
+	ctx := r.Context()
+
+	bidder, err := h.findBidder(ctx, bidderCode)
+	if err != nil {
+		if !sendStorageError(w, err) {
+			logger.Log.Error().Err(err).Str("bidder", bidderCode).Msg("Failed to look up bidder")
+			sendAdminError(w, http.StatusInternalServerError, "database_error", "Failed to retrieve bidder")
+		}
+		return
+	}
+	if bidder.Status != "archived" {
+		sendAdminError(w, http.StatusConflict, "not_archived", fmt.Sprintf("Bidder is %s, not archived", bidder.Status))
+		return
+	}
+
+	req := restoreRequest(bidder)
+	if err := req.validate(); err != nil {
+		sendAdminError(w, http.StatusUnprocessableEntity, "invalid_configuration", "Archived configuration is no longer valid: "+err.Error())
+		return
+	}
+	if err := h.checkEndpointResolves(ctx, req.EndpointURL); err != nil {
+		sendAdminError(w, http.StatusUnprocessableEntity, "endpoint_unresolvable", err.Error())
+		return
+	}
+
+	restored := req.bidder()
+	if err := h.store.Restore(ctx, restored); err != nil {
+		if errors.Is(err, storage.ErrValidation) {
+			sendAdminError(w, http.StatusUnprocessableEntity, "invalid_configuration", "Archived configuration is no longer valid: "+err.Error())
+			return
+		}
+		if !sendStorageError(w, err) {
+			logger.Log.Error().Err(err).Str("bidder", bidderCode).Msg("Failed to restore bidder")
+			sendAdminError(w, http.StatusInternalServerError, "database_error", "Failed to restore bidder")
+		}
+		return
+	}
+
+	logger.Log.Info().
+		Str("bidder", bidderCode).
+		Str("endpoint", restored.EndpointURL).
+		Msg("Bidder restored")
+
+	h.invalidate(ctx, bidderCode)
+
+	// Re-read for the stored id and timestamps; the restore itself succeeded
+	if stored, err := h.findBidder(ctx, bidderCode); err == nil {
+		restored = stored
+	}
+	sendAdminJSON(w, http.StatusOK, restored)
+}
+
+// checkEndpointResolves fails when the endpoint's host no longer resolves
+func (h *BidderAdminHandler) checkEndpointResolves(ctx context.Context, endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return fmt.Errorf("endpoint_url is invalid: %w", err)
+	}
+	host := u.Hostname()
+	if net.ParseIP(host) != nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, restoreResolveTimeout)
+	defer cancel()
+	if addrs, err := h.lookupHost(ctx, host); err != nil || len(addrs) == 0 {
+		return fmt.Errorf("endpoint host %s no longer resolves; update endpoint_url before restoring", host)
+	}
+	return nil
+}
+
+// setEnabled enables or disables a bidder
+func (h *BidderAdminHandler) setEnabled(w http.ResponseWriter, r *http.Request, bidderCode string, enabled bool) {
+	ctx := r.Context()
+
+	bidder, err := h.findBidder(ctx, bidderCode)
+	if err != nil {
+		if !sendStorageError(w, err) {
+			logger.Log.Error().Err(err).Str("bidder", bidderCode).Msg("Failed to look up bidder")
+			sendAdminError(w, http.StatusInternalServerError, "database_error", "Failed to retrieve bidder")
+		}
+		return
+	}
+
+	if err := h.store.SetEnabled(ctx, bidderCode, enabled); err != nil {
+		if !sendStorageError(w, err) {
+			logger.Log.Error().Err(err).Str("bidder", bidderCode).Msg("Failed to update bidder")
+			sendAdminError(w, http.StatusInternalServerError, "database_error", "Failed to update bidder")
+		}
+		return
+	}
+
+	logger.Log.Info().
+		Str("bidder", bidderCode).
+		Bool("enabled", enabled).
+		Msg("Bidder enabled state changed")
+
+	h.invalidate(ctx, bidderCode)
+	bidder.Enabled = enabled
+	sendAdminJSON(w, http.StatusOK, bidder)
+}
+
+// setGDPRScope sets when GDPR vendor consent is enforced for a bidder
+func (h *BidderAdminHandler) setGDPRScope(w http.ResponseWriter, r *http.Request, bidderCode string) {
+	var req GDPRScopeRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBidderBodySize)).Decode(&req); err != nil {
+		sendAdminError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON in request body")
+		return
+	}
+	switch middleware.GDPRScope(req.GDPRScope) {
+	case middleware.GDPRScopeAlways, middleware.GDPRScopeEEAOnly, middleware.GDPRScopeNever:
+	default:
+		sendAdminError(w, http.StatusBadRequest, "invalid_request", "gdpr_scope must be always, eea_only or never")
+		return
+	}
+
+	ctx := r.Context()
+	if err := h.store.SetGDPRScope(ctx, bidderCode, req.GDPRScope); err != nil {
+		if !sendStorageError(w, err) {
+			logger.Log.Error().Err(err).Str("bidder", bidderCode).Msg("Failed to set bidder GDPR scope")
+			sendAdminError(w, http.StatusInternalServerError, "database_error", "Failed to update bidder")
+		}
+		return
+	}
+
+	logger.Log.Info().
+		Str("bidder", bidderCode).
+		Str("gdpr_scope", req.GDPRScope).
+		Msg("Bidder GDPR scope changed")
+
+	h.invalidate(ctx, bidderCode)
+	sendAdminJSON(w, http.StatusOK, map[string]string{"bidder_code": bidderCode, "gdpr_scope": req.GDPRScope})
+}
+
+// invalidate reloads cached bidder policies after a change; failures only
+// delay the change until the next reload
+func (h *BidderAdminHandler) invalidate(ctx context.Context, bidderCode string) {
+	if h.invalidator == nil {
+		return
+	}
+	if _, err := h.invalidator.Invalidate(ctx, CacheInvalidateRequest{Scope: "bidder", IDs: []string{bidderCode}}); err != nil {
+		logger.Log.Warn().Err(err).Str("bidder", bidderCode).Msg("Bidder policy cache not invalidated")
+	}
+}
+
+// decodeBidderRequest parses and validates a create or update body, sending
+// a 400 and returning false if it is unusable. Read-only fields such as id
+// and created_at are ignored, so a bidder from GET can be edited and sent
+// back as is.
+func decodeBidderRequest(w http.ResponseWriter, r *http.Request) (*BidderRequest, bool) {
+	var req BidderRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBidderBodySize)).Decode(&req); err != nil {
+		sendAdminError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON in request body")
+		return nil, false
+	}
+	if err := req.validate(); err != nil {
+		sendAdminError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return nil, false
+	}
+	return &req, true
+}
+
+// findBidder looks a bidder up by code among all bidders (GetByCode only
+// returns active ones), returning storage.ErrNotFound when it doesn't exist
+func (h *BidderAdminHandler) findBidder(ctx context.Context, bidderCode string) (*storage.Bidder, error) {
+	bidders, err := h.store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range bidders {
+		if b.BidderCode == bidderCode {
+			return b, nil
+		}
+	}
+	return nil, fmt.Errorf("bidder %w: %s", storage.ErrNotFound, bidderCode)
+}
+
+// sendAdminJSON sends a JSON admin API response
+func sendAdminJSON(w http.ResponseWriter, statusCode int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		logger.Log.Error().Err(err).Msg("Failed to encode JSON response")
+	}
+}
+
+// sendAdminError sends a JSON admin API error response
+func sendAdminError(w http.ResponseWriter, statusCode int, errorCode, message string) {
+	sendAdminJSON(w, statusCode, ErrorResponse{Error: errorCode, Message: message})
+}
+
+// sendStorageError responds to a store error the client caused: a missing
+// row is a 404, a lost update or duplicate a 409 and rejected input a 400.
+// It returns false for anything else, which the caller should log and
+// report as a 500.
+func sendStorageError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		sendAdminError(w, http.StatusNotFound, "not_found", err.Error())
+	case errors.Is(err, storage.ErrConflict):
+		sendAdminError(w, http.StatusConflict, "conflict", err.Error())
+	case errors.Is(err, storage.ErrValidation):
+		sendAdminError(w, http.StatusBadRequest, "invalid_request", err.Error())
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,511 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+)
+
+type mockBidderAdminStore struct {
+	bidders []*storage.Bidder
+	scopes  map[string]string
+}
+
+func (m *mockBidderAdminStore) List(ctx context.Context) ([]*storage.Bidder, error) {
+	return m.bidders, nil
+}
+
+func (m *mockBidderAdminStore) ListPage(ctx context.Context, opts storage.ListOptions) ([]*storage.Bidder, int, error) {
+	if opts.Sort != "" && opts.Sort != "bidder_code" {
+		return nil, 0, fmt.Errorf("%w: cannot sort by %q", storage.ErrInvalidListOptions, opts.Sort)
+	}
+	var page []*storage.Bidder
+	total := 0
+	for _, b := range m.bidders {
+		if opts.Status != "" && b.Status != opts.Status {
+			continue
+		}
+		total++
+		if b.BidderCode > opts.Cursor && len(page) < opts.PageLimit() {
+			page = append(page, b)
+		}
+	}
+	return page, total, nil
+}
+
+func (m *mockBidderAdminStore) find(bidderCode string) *storage.Bidder {
+	for _, b := range m.bidders {
+		if b.BidderCode == bidderCode {
+			return b
+		}
+	}
+	return nil
+}
+
+func (m *mockBidderAdminStore) Create(ctx context.Context, b *storage.Bidder) error {
+	if m.find(b.BidderCode) != nil {
+		return fmt.Errorf("%w: bidder %s already exists", storage.ErrConflict, b.BidderCode)
+	}
+	b.ID, b.Version = fmt.Sprint(len(m.bidders)+1), 1
+	stored := *b
+	m.bidders = append(m.bidders, &stored)
+	return nil
+}
+
+func (m *mockBidderAdminStore) Update(ctx context.Context, b *storage.Bidder) error {
+	existing := m.find(b.BidderCode)
+	if existing == nil {
+		return fmt.Errorf("bidder %w: %s", storage.ErrNotFound, b.BidderCode)
+	}
+	if existing.Version != b.Version {
+		return fmt.Errorf("%w: concurrent modification detected", storage.ErrConflict)
+	}
+	b.ID, b.Version = existing.ID, existing.Version+1
+	*existing = *b
+	return nil
+}
+
+func (m *mockBidderAdminStore) Delete(ctx context.Context, bidderCode string) error {
+	existing := m.find(bidderCode)
+	if existing == nil {
+		return fmt.Errorf("bidder %w: %s", storage.ErrNotFound, bidderCode)
+	}
+	existing.Status, existing.Enabled = "archived", false
+	return nil
+}
+
+func (m *mockBidderAdminStore) SetEnabled(ctx context.Context, bidderCode string, enabled bool) error {
+	for _, b := range m.bidders {
+		if b.BidderCode == bidderCode {
+			b.Enabled = enabled
+		}
+	}
+	return nil
+}
+
+func (m *mockBidderAdminStore) SetGDPRScope(ctx context.Context, bidderCode, scope string) error {
+	if m.find(bidderCode) == nil {
+		return fmt.Errorf("bidder %w: %s", storage.ErrNotFound, bidderCode)
+	}
+	if m.scopes == nil {
+		m.scopes = make(map[string]string)
+	}
+	m.scopes[bidderCode] = scope
+	return nil
+}
+
+func (m *mockBidderAdminStore) Restore(ctx context.Context, b *storage.Bidder) error {
+	existing := m.find(b.BidderCode)
+	if existing == nil || existing.Status != "archived" || existing.Version != b.Version {
+		return fmt.Errorf("%w: bidder %s is no longer archived or was modified", storage.ErrConflict, b.BidderCode)
+	}
+	b.ID, b.Version = existing.ID, existing.Version+1
+	*existing = *b
+	return nil
+}
+
+type mockPurger struct{ n int }
+
+func (m *mockPurger) PurgeCache() int { return m.n }
+
+func TestBidderAdminHandler(t *testing.T) {
+	store := &mockBidderAdminStore{bidders: []*storage.Bidder{
+		{BidderCode: "appnexus", Enabled: true},
+		{BidderCode: "rubicon", Enabled: false},
+	}}
+	h := NewBidderAdminHandler(store)
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/admin/api/bidders", nil))
+	var list BidderListResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &list); err != nil || list.Count != 2 {
+		t.Fatalf("expected 2 bidders including disabled, got %s", rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/admin/api/bidders/rubicon/enable", nil))
+	if rr.Code != http.StatusOK || !store.bidders[1].Enabled {
+		t.Errorf("expected rubicon enabled, got %d %s", rr.Code, rr.Body.String())
+	}
+
+	tests := []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodGet, "/admin/api/bidders/appnexus", http.StatusOK},
+		{http.MethodGet, "/admin/api/bidders/missing", http.StatusNotFound},
+		{http.MethodPost, "/admin/api/bidders/missing/disable", http.StatusNotFound},
+		{http.MethodPost, "/admin/api/bidders/appnexus/explode", http.StatusNotFound},
+		{http.MethodPatch, "/admin/api/bidders/appnexus", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(tt.method, tt.path, nil))
+		if rr.Code != tt.want {
+			t.Errorf("%s %s: expected %d, got %d", tt.method, tt.path, tt.want, rr.Code)
+		}
+	}
+
+	rr = httptest.NewRecorder()
+	NewBidderAdminHandler(nil).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/admin/api/bidders", nil))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 without store, got %d", rr.Code)
+	}
+}
+
+func TestBidderAdminHandler_GDPRScope(t *testing.T) {
+	store := &mockBidderAdminStore{bidders: []*storage.Bidder{{BidderCode: "appnexus", Enabled: true}}}
+	h := NewBidderAdminHandler(store)
+
+	tests := []struct {
+		path, body string
+		want       int
+	}{
+		{"/admin/api/bidders/appnexus/gdpr_scope", `{"gdpr_scope":"never"}`, http.StatusOK},
+		{"/admin/api/bidders/appnexus/gdpr_scope", `{"gdpr_scope":"sometimes"}`, http.StatusBadRequest},
+		{"/admin/api/bidders/appnexus/gdpr_scope", `not json`, http.StatusBadRequest},
+		{"/admin/api/bidders/missing/gdpr_scope", `{"gdpr_scope":"always"}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodPut, tt.path, strings.NewReader(tt.body)))
+		if rr.Code != tt.want {
+			t.Errorf("%s %s: expected %d, got %d %s", tt.path, tt.body, tt.want, rr.Code, rr.Body.String())
+		}
+	}
+	if store.scopes["appnexus"] != "never" {
+		t.Errorf("expected the scope stored, got %v", store.scopes)
+	}
+}
+
+func TestBidderAdminHandler_CRUD(t *testing.T) {
+	store := &mockBidderAdminStore{}
+	h := NewBidderAdminHandler(store)
+	var invalidated []string
+	cacheAdmin := NewCacheAdminHandler()
+	cacheAdmin.RegisterInvalidator("bidder", CacheInvalidatorFunc(func(ids ...string) int {
+		invalidated = append(invalidated, ids...)
+		return len(ids)
+	}))
+	h.SetInvalidator(cacheAdmin)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rr
+	}
+
+	create := `{"bidder_code":"acme","bidder_name":"Acme","endpoint_url":"https://bid.acme.test/ortb","enabled":true,"supports_video":true}`
+	rr := send(http.MethodPost, "/admin/api/bidders", create)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var created storage.Bidder
+	json.Unmarshal(rr.Body.Bytes(), &created)
+	if created.Version != 1 || created.TimeoutMs != 1000 || created.Status != "active" {
+		t.Errorf("expected defaults and version 1, got %+v", created)
+	}
+	if rr := send(http.MethodPost, "/admin/api/bidders", create); rr.Code != http.StatusConflict {
+		t.Errorf("expected 409 for a duplicate, got %d", rr.Code)
+	}
+
+	// A bidder read with GET can be edited and sent back as is
+	created.TimeoutMs = 250
+	body, _ := json.Marshal(created)
+	rr = send(http.MethodPut, "/admin/api/bidders/acme", string(body))
+	var updated storage.Bidder
+	json.Unmarshal(rr.Body.Bytes(), &updated)
+	if rr.Code != http.StatusOK || updated.Version != 2 || updated.TimeoutMs != 250 {
+		t.Fatalf("expected version 2 with the new timeout, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := send(http.MethodPut, "/admin/api/bidders/acme", string(body)); rr.Code != http.StatusConflict {
+		t.Errorf("expected 409 for a stale version, got %d", rr.Code)
+	}
+
+	rr = send(http.MethodDelete, "/admin/api/bidders/acme", "")
+	if rr.Code != http.StatusOK || store.bidders[0].Status != "archived" || store.bidders[0].Enabled {
+		t.Errorf("expected acme archived and disabled, got %d %+v", rr.Code, store.bidders[0])
+	}
+	if rr := send(http.MethodDelete, "/admin/api/bidders/missing", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("expected 404 archiving a missing bidder, got %d", rr.Code)
+	}
+	if strings.Join(invalidated, ",") != "acme,acme,acme" {
+		t.Errorf("expected each change to invalidate acme, got %v", invalidated)
+	}
+
+	for _, tt := range []struct {
+		method, path, body string
+	}{
+		{http.MethodPost, "/admin/api/bidders", `not json`},
+		{http.MethodPost, "/admin/api/bidders", `{"bidder_code":"Bad Code","bidder_name":"x","endpoint_url":"https://x.test"}`},
+		{http.MethodPost, "/admin/api/bidders", `{"bidder_code":"x","bidder_name":"x","endpoint_url":"ftp://x.test"}`},
+		{http.MethodPost, "/admin/api/bidders", `{"bidder_code":"x","bidder_name":"x","endpoint_url":"https://x.test","timeout_ms":50}`},
+		{http.MethodPost, "/admin/api/bidders", `{"bidder_code":"x","bidder_name":"x","endpoint_url":"https://x.test","status":"archived"}`},
+		{http.MethodPost, "/admin/api/bidders", `{"bidder_code":"x","bidder_name":"x","endpoint_url":"https://x.test","version":3}`},
+		{http.MethodPut, "/admin/api/bidders/acme", `{"bidder_code":"acme","bidder_name":"x","endpoint_url":"https://x.test"}`},
+		{http.MethodPut, "/admin/api/bidders/acme", `{"bidder_code":"other","bidder_name":"x","endpoint_url":"https://x.test","version":1}`},
+	} {
+		if rr := send(tt.method, tt.path, tt.body); rr.Code != http.StatusBadRequest {
+			t.Errorf("%s %s %s: expected 400, got %d", tt.method, tt.path, tt.body, rr.Code)
+		}
+	}
+}
+
+func TestBidderAdminHandler_Restore(t *testing.T) {
+	store := &mockBidderAdminStore{bidders: []*storage.Bidder{
+		{BidderCode: "acme", BidderName: "Acme", EndpointURL: "https://bid.acme.test/ortb", TimeoutMs: 500, Status: "archived", Version: 2},
+		{BidderCode: "appnexus", BidderName: "AppNexus", EndpointURL: "https://ib.adnxs.com/openrtb2", TimeoutMs: 500, Status: "active", Enabled: true, Version: 1},
+		{BidderCode: "gone", BidderName: "Gone", EndpointURL: "https://bid.gone.test/ortb", TimeoutMs: 500, Status: "archived", Version: 1},
+		{BidderCode: "stale", BidderName: "Stale", EndpointURL: "https://bid.stale.test/ortb", TimeoutMs: 50, Status: "archived", Version: 1},
+	}}
+	h := NewBidderAdminHandler(store)
+	h.lookupHost = func(ctx context.Context, host string) ([]string, error) {
+		if host == "bid.gone.test" {
+			return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+		}
+		return []string{"192.0.2.10"}, nil
+	}
+	var invalidated []string
+	cacheAdmin := NewCacheAdminHandler()
+	cacheAdmin.RegisterInvalidator("bidder", CacheInvalidatorFunc(func(ids ...string) int {
+		invalidated = append(invalidated, ids...)
+		return len(ids)
+	}))
+	h.SetInvalidator(cacheAdmin)
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/admin/api/bidders?status=archived", nil))
+	var list BidderListResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &list); err != nil || list.Total != 3 || list.Bidders[0].BidderCode != "acme" {
+		t.Fatalf("expected the three archived bidders, got %s", rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/admin/api/bidders/acme/restore", nil))
+	var restored storage.Bidder
+	json.Unmarshal(rr.Body.Bytes(), &restored)
+	if rr.Code != http.StatusOK || restored.Status != "active" || !restored.Enabled || restored.Version != 3 {
+		t.Fatalf("expected acme active and enabled at version 3, got %d %s", rr.Code, rr.Body.String())
+	}
+	if strings.Join(invalidated, ",") != "acme" {
+		t.Errorf("expected the restore to invalidate acme, got %v", invalidated)
+	}
+
+	tests := []struct {
+		path string
+		want int
+		code string
+	}{
+		{"/admin/api/bidders/acme/restore", http.StatusConflict, "not_archived"},
+		{"/admin/api/bidders/appnexus/restore", http.StatusConflict, "not_archived"},
+		{"/admin/api/bidders/gone/restore", http.StatusUnprocessableEntity, "endpoint_unresolvable"},
+		{"/admin/api/bidders/stale/restore", http.StatusUnprocessableEntity, "invalid_configuration"},
+		{"/admin/api/bidders/missing/restore", http.StatusNotFound, "not_found"},
+	}
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, tt.path, nil))
+		var resp ErrorResponse
+		json.Unmarshal(rr.Body.Bytes(), &resp)
+		if rr.Code != tt.want || resp.Error != tt.code {
+			t.Errorf("%s: expected %d %s, got %d %s", tt.path, tt.want, tt.code, rr.Code, rr.Body.String())
+		}
+	}
+	if store.bidders[2].Status != "archived" || store.bidders[3].Status != "archived" {
+		t.Error("expected rejected restores to leave the bidders archived")
+	}
+
+	// IP endpoints aren't looked up
+	store.bidders = append(store.bidders, &storage.Bidder{BidderCode: "direct", BidderName: "Direct", EndpointURL: "http://192.0.2.20:8080/bid", TimeoutMs: 500, Status: "archived"})
+	h.lookupHost = func(ctx context.Context, host string) ([]string, error) {
+		t.Errorf("unexpected lookup of %s", host)
+		return nil, nil
+	}
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/admin/api/bidders/direct/restore", nil))
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected an IP endpoint restored, got %d %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestBidderAdminHandler_Pagination(t *testing.T) {
+	store := &mockBidderAdminStore{bidders: []*storage.Bidder{
+		{BidderCode: "appnexus"}, {BidderCode: "pubmatic"}, {BidderCode: "rubicon"},
+	}}
+	h := NewBidderAdminHandler(store)
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/admin/api/bidders?limit=2", nil))
+	var page BidderListResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &page); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if page.Count != 2 || page.Total != 3 || page.NextCursor != "pubmatic" {
+		t.Errorf("unexpected first page: %+v", page)
+	}
+	if rr.Header().Get("X-Total-Count") != "3" || rr.Header().Get("X-Next-Cursor") != "pubmatic" {
+		t.Errorf("unexpected list headers: %v", rr.Header())
+	}
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/admin/api/bidders?limit=2&cursor=pubmatic", nil))
+	page = BidderListResponse{}
+	if err := json.Unmarshal(rr.Body.Bytes(), &page); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if page.Count != 1 || page.Bidders[0].BidderCode != "rubicon" || page.NextCursor != "" {
+		t.Errorf("unexpected last page: %+v", page)
+	}
+	if rr.Header().Get("X-Next-Cursor") != "" {
+		t.Error("expected no next cursor on the last page")
+	}
+
+	for _, query := range []string{"limit=0", "limit=abc", "offset=-1", "sort=endpoint_url"} {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/admin/api/bidders?"+query, nil))
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", query, rr.Code)
+		}
+	}
+}
+
+func TestCacheAdminHandler(t *testing.T) {
+	h := NewCacheAdminHandler()
+	h.Register("publisher_auth", &mockPurger{n: 3})
+	h.Register("other", &mockPurger{n: 1})
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/admin/cache/purge", nil))
+	var resp CachePurgeResponse
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if rr.Code != http.StatusOK || resp.Purged["publisher_auth"] != 3 || resp.Purged["other"] != 1 {
+		t.Errorf("expected both caches purged, got %d %s", rr.Code, rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/admin/cache/purge?cache=nope", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown cache, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/admin/cache/purge", nil))
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405 for GET, got %d", rr.Code)
+	}
+}
+
+type mockInvalidationPublisher struct {
+	channel  string
+	messages []string
+	degraded bool
+}
+
+func (m *mockInvalidationPublisher) Degraded() bool { return m.degraded }
+
+func (m *mockInvalidationPublisher) Publish(_ context.Context, channel, message string) error {
+	m.channel = channel
+	m.messages = append(m.messages, message)
+	return nil
+}
+
+func TestCacheAdminHandler_Invalidate(t *testing.T) {
+	var invalidated []string
+	h := NewCacheAdminHandler()
+	h.RegisterInvalidator("publisher", CacheInvalidatorFunc(func(ids ...string) int {
+		invalidated = append(invalidated, ids...)
+		return len(ids)
+	}))
+	pub := &mockInvalidationPublisher{}
+	h.SetPublisher(pub)
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/admin/cache/invalidate",
+		strings.NewReader(`{"scope":"publisher","ids":["pub-1","pub-2"]}`)))
+	var resp CacheInvalidateResponse
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if rr.Code != http.StatusOK || resp.Invalidated != 2 || !resp.Broadcast {
+		t.Errorf("expected 2 invalidated and broadcast, got %d %s", rr.Code, rr.Body.String())
+	}
+	if len(pub.messages) != 1 || pub.channel != CacheInvalidationChannel {
+		t.Fatalf("expected one broadcast on %s, got %v on %s", CacheInvalidationChannel, pub.messages, pub.channel)
+	}
+
+	for body, want := range map[string]int{
+		`{"scope":"stored_request","ids":["x"]}`: http.StatusBadRequest,
+		`{"scope":"publisher","ids":[]}`:         http.StatusBadRequest,
+		`not json`:                               http.StatusBadRequest,
+	} {
+		rr = httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/admin/cache/invalidate", strings.NewReader(body)))
+		if rr.Code != want {
+			t.Errorf("%s: expected %d, got %d", body, want, rr.Code)
+		}
+	}
+
+	// A replica skips its own broadcast but applies others'
+	invalidated = nil
+	messages := make(chan string, 3)
+	messages <- pub.messages[0]
+	messages <- `{"scope":"publisher","ids":["pub-3"],"origin":"other-replica"}`
+	messages <- `{"scope":"unknown","ids":["x"],"origin":"other-replica"}`
+	close(messages)
+	h.Listen(messages)
+	if len(invalidated) != 1 || invalidated[0] != "pub-3" {
+		t.Errorf("expected only pub-3 invalidated by listener, got %v", invalidated)
+	}
+}
+
+func TestCacheAdminHandler_InvalidateDegraded(t *testing.T) {
+	var invalidated []string
+	h := NewCacheAdminHandler()
+	h.RegisterInvalidator("publisher", CacheInvalidatorFunc(func(ids ...string) int {
+		invalidated = append(invalidated, ids...)
+		return len(ids)
+	}))
+	pub := &mockInvalidationPublisher{degraded: true}
+	h.SetPublisher(pub)
+
+	resp, err := h.Invalidate(context.Background(), CacheInvalidateRequest{Scope: "publisher", IDs: []string{"pub-1"}})
+	if err != nil || resp.Invalidated != 1 || resp.Broadcast {
+		t.Errorf("expected a local invalidation without broadcast, got %+v %v", resp, err)
+	}
+	if len(pub.messages) != 0 {
+		t.Errorf("expected nothing published while degraded, got %v", pub.messages)
+	}
+}
+
+func TestSendStorageError(t *testing.T) {
+	tests := []struct {
+		err     error
+		handled bool
+		status  int
+		code    string
+	}{
+		{fmt.Errorf("bidder %w: x", storage.ErrNotFound), true, http.StatusNotFound, "not_found"},
+		{fmt.Errorf("%w: version mismatch", storage.ErrConflict), true, http.StatusConflict, "conflict"},
+		{fmt.Errorf("%w http_headers: bad", storage.ErrValidation), true, http.StatusBadRequest, "invalid_request"},
+		{fmt.Errorf("failed to query: %w", context.DeadlineExceeded), false, 0, ""},
+	}
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		if handled := sendStorageError(rr, tt.err); handled != tt.handled {
+			t.Fatalf("%v: expected handled=%v", tt.err, tt.handled)
+		}
+		if !tt.handled {
+			continue
+		}
+		var resp ErrorResponse
+		json.Unmarshal(rr.Body.Bytes(), &resp)
+		if rr.Code != tt.status || resp.Error != tt.code || resp.Message != tt.err.Error() {
+			t.Errorf("%v: expected %d %s, got %d %+v", tt.err, tt.status, tt.code, rr.Code, resp)
+		}
+	}
+}
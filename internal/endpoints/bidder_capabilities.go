@@ -0,0 +1,224 @@
+package endpoints
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+const (
+	// defaultCapabilityPageSize is the page size when no limit is given
+	defaultCapabilityPageSize = 50
+	// maxCapabilityPageSize caps the limit query parameter
+	maxCapabilityPageSize = 500
+	// capabilityCacheMaxAge is how long clients may cache capability listings (seconds)
+	capabilityCacheMaxAge = 60
+)
+
+// capabilityQueryParams switch /info/bidders from the Prebid-compatible
+// name list to the filtered capability listing
+var capabilityQueryParams = []string{"supports", "enabled", "limit", "offset"}
+
+// BidderCapabilityStore lists bidders with their format capabilities
+type BidderCapabilityStore interface {
+	GetCapabilities(ctx context.Context, banner, video, native, audio bool) ([]*storage.Bidder, error)
+	List(ctx context.Context) ([]*storage.Bidder, error)
+}
+
+// BidderCapability is the public view of a bidder's capabilities.
+// Endpoint URLs, HTTP headers and contacts are deliberately not exposed.
+type BidderCapability struct {
+	BidderCode       string `json:"bidder_code"`
+	BidderName       string `json:"bidder_name"`
+	Enabled          bool   `json:"enabled"`
+	Status           string `json:"status"`
+	SupportsBanner   bool   `json:"supports_banner"`
+	SupportsVideo    bool   `json:"supports_video"`
+	SupportsNative   bool   `json:"supports_native"`
+	SupportsAudio    bool   `json:"supports_audio"`
+	GVLVendorID      *int   `json:"gvl_vendor_id,omitempty"`
+	DocumentationURL string `json:"documentation_url,omitempty"`
+}
+
+// BidderCapabilityResponse is a page of bidder capabilities
+type BidderCapabilityResponse struct {
+	Bidders []BidderCapability `json:"bidders"`
+	Total   int                `json:"total"`
+	Limit   int                `json:"limit"`
+	Offset  int                `json:"offset"`
+}
+
+// capabilityFilter holds the parsed /info/bidders query
+type capabilityFilter struct {
+	banner, video, native, audio bool
+	enabled                      bool
+	limit, offset                int
+}
+
+// SetCapabilityStore enables capability filtering on /info/bidders
+func (h *InfoBiddersHandler) SetCapabilityStore(store BidderCapabilityStore) {
+	h.capabilityStore = store
+}
+
+// isCapabilityQuery reports whether the request asks for the capability listing
+func isCapabilityQuery(r *http.Request) bool {
+	q := r.URL.Query()
+	for _, p := range capabilityQueryParams {
+		if q.Has(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseCapabilityFilter parses supports, enabled, limit and offset
+func parseCapabilityFilter(r *http.Request) (*capabilityFilter, error) {
+	q := r.URL.Query()
+	f := &capabilityFilter{enabled: true, limit: defaultCapabilityPageSize}
+
+	if supports := q.Get("supports"); supports != "" {
+		for _, format := range strings.Split(supports, ",") {
+			switch strings.TrimSpace(strings.ToLower(format)) {
+			case "banner":
+				f.banner = true
+			case "video":
+				f.video = true
+			case "native":
+				f.native = true
+			case "audio":
+				f.audio = true
+			default:
+				return nil, fmt.Errorf("unsupported format in supports: %q", format)
+			}
+		}
+	}
+
+	if v := q.Get("enabled"); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid enabled value: %q", v)
+		}
+		f.enabled = enabled
+	}
+
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			return nil, fmt.Errorf("invalid limit: %q", v)
+		}
+		if limit > maxCapabilityPageSize {
+			limit = maxCapabilityPageSize
+		}
+		f.limit = limit
+	}
+
+	if v := q.Get("offset"); v != "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			return nil, fmt.Errorf("invalid offset: %q", v)
+		}
+		f.offset = offset
+	}
+
+	return f, nil
+}
+
+// matches reports whether a bidder satisfies the format and enabled filters
+func (f *capabilityFilter) matches(b *storage.Bidder) bool {
+	if b.Enabled != f.enabled {
+		return false
+	}
+	return (!f.banner || b.SupportsBanner) &&
+		(!f.video || b.SupportsVideo) &&
+		(!f.native || b.SupportsNative) &&
+		(!f.audio || b.SupportsAudio)
+}
+
+// serveCapabilities handles /info/bidders?supports=...&enabled=...&limit=...&offset=...
+func (h *InfoBiddersHandler) serveCapabilities(w http.ResponseWriter, r *http.Request) {
+	if h.capabilityStore == nil {
+		writeError(w, "Bidder capability listing is not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	filter, err := parseCapabilityFilter(r)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Enabled bidders use the indexed capability query; disabled ones are
+	// filtered from the full list
+	var bidders []*storage.Bidder
+	if filter.enabled {
+		bidders, err = h.capabilityStore.GetCapabilities(r.Context(), filter.banner, filter.video, filter.native, filter.audio)
+	} else {
+		bidders, err = h.capabilityStore.List(r.Context())
+	}
+	if err != nil {
+		logger.Log.Error().Err(err).Msg("Failed to list bidder capabilities")
+		writeError(w, "Failed to list bidders", http.StatusInternalServerError)
+		return
+	}
+
+	matched := make([]BidderCapability, 0, len(bidders))
+	for _, b := range bidders {
+		if !filter.matches(b) {
+			continue
+		}
+		matched = append(matched, BidderCapability{
+			BidderCode:       b.BidderCode,
+			BidderName:       b.BidderName,
+			Enabled:          b.Enabled,
+			Status:           b.Status,
+			SupportsBanner:   b.SupportsBanner,
+			SupportsVideo:    b.SupportsVideo,
+			SupportsNative:   b.SupportsNative,
+			SupportsAudio:    b.SupportsAudio,
+			GVLVendorID:      b.GVLVendorID,
+			DocumentationURL: b.DocumentationURL,
+		})
+	}
+
+	resp := BidderCapabilityResponse{
+		Bidders: []BidderCapability{},
+		Total:   len(matched),
+		Limit:   filter.limit,
+		Offset:  filter.offset,
+	}
+	if filter.offset < len(matched) {
+		end := filter.offset + filter.limit
+		if end > len(matched) {
+			end = len(matched)
+		}
+		resp.Bidders = matched[filter.offset:end]
+	}
+
+	body, err := json.Marshal(resp)
+	if err != nil {
+		logger.Log.Error().Err(err).Msg("Failed to encode bidder capabilities")
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+
+	// ETag over the page body lets tooling poll cheaply with If-None-Match
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", capabilityCacheMaxAge))
+	if match := r.Header.Get("If-None-Match"); match != "" && match == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
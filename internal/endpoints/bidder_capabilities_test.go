@@ -0,0 +1,130 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+)
+
+type mockCapabilityStore struct {
+	bidders []*storage.Bidder
+}
+
+func (m *mockCapabilityStore) GetCapabilities(ctx context.Context, banner, video, native, audio bool) ([]*storage.Bidder, error) {
+	var out []*storage.Bidder
+	for _, b := range m.bidders {
+		if b.Enabled && (!banner || b.SupportsBanner) && (!video || b.SupportsVideo) &&
+			(!native || b.SupportsNative) && (!audio || b.SupportsAudio) {
+			out = append(out, b)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockCapabilityStore) List(ctx context.Context) ([]*storage.Bidder, error) {
+	return m.bidders, nil
+}
+
+func newCapabilityHandler() *InfoBiddersHandler {
+	h := NewDynamicInfoBiddersHandler(&staticBidderList{bidders: []string{"appnexus"}})
+	h.SetCapabilityStore(&mockCapabilityStore{bidders: []*storage.Bidder{
+		{BidderCode: "appnexus", Enabled: true, SupportsBanner: true, SupportsVideo: true, SupportsNative: true, EndpointURL: "https://secret.example.com"},
+		{BidderCode: "rubicon", Enabled: true, SupportsBanner: true, SupportsVideo: true},
+		{BidderCode: "pubmatic", Enabled: true, SupportsVideo: true, SupportsNative: true},
+		{BidderCode: "retired", Enabled: false, SupportsVideo: true},
+	}})
+	return h
+}
+
+func TestInfoBidders_CapabilityFilter(t *testing.T) {
+	h := newCapabilityHandler()
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/info/bidders?supports=video,native&enabled=true", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp BidderCapabilityResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if resp.Total != 2 || len(resp.Bidders) != 2 {
+		t.Fatalf("expected 2 video+native bidders, got %+v", resp)
+	}
+	if resp.Bidders[0].BidderCode != "appnexus" || resp.Bidders[1].BidderCode != "pubmatic" {
+		t.Errorf("unexpected bidders: %+v", resp.Bidders)
+	}
+	if strings.Contains(rr.Body.String(), "secret.example.com") {
+		t.Error("endpoint URLs must not be exposed")
+	}
+}
+
+func TestInfoBidders_CapabilityDisabledAndPagination(t *testing.T) {
+	h := newCapabilityHandler()
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/info/bidders?enabled=false", nil))
+	var disabled BidderCapabilityResponse
+	json.Unmarshal(rr.Body.Bytes(), &disabled)
+	if disabled.Total != 1 || disabled.Bidders[0].BidderCode != "retired" {
+		t.Errorf("expected only the disabled bidder, got %+v", disabled)
+	}
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/info/bidders?supports=video&limit=2&offset=2", nil))
+	var page BidderCapabilityResponse
+	json.Unmarshal(rr.Body.Bytes(), &page)
+	if page.Total != 3 || len(page.Bidders) != 1 || page.Bidders[0].BidderCode != "pubmatic" {
+		t.Errorf("expected last page with pubmatic, got %+v", page)
+	}
+}
+
+func TestInfoBidders_CapabilityETag(t *testing.T) {
+	h := newCapabilityHandler()
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/info/bidders?supports=banner", nil))
+	etag := rr.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("expected ETag header")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/info/bidders?supports=banner", nil)
+	req.Header.Set("If-None-Match", etag)
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if rr.Code != http.StatusNotModified {
+		t.Errorf("expected 304 for matching ETag, got %d", rr.Code)
+	}
+}
+
+func TestInfoBidders_CapabilityErrors(t *testing.T) {
+	h := newCapabilityHandler()
+	for _, q := range []string{"supports=hologram", "enabled=maybe", "limit=0", "offset=-1"} {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/info/bidders?"+q, nil))
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", q, rr.Code)
+		}
+	}
+
+	// Without a store, capability queries are unavailable but the plain list still works
+	plain := NewDynamicInfoBiddersHandler(&staticBidderList{bidders: []string{"appnexus"}})
+	rr := httptest.NewRecorder()
+	plain.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/info/bidders?supports=video", nil))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 without capability store, got %d", rr.Code)
+	}
+	rr = httptest.NewRecorder()
+	plain.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/info/bidders", nil))
+	var names []string
+	if err := json.Unmarshal(rr.Body.Bytes(), &names); err != nil || len(names) != 1 {
+		t.Errorf("expected plain bidder list, got %s", rr.Body.String())
+	}
+}
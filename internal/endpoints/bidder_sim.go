@@ -0,0 +1,185 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+// BidderSimPath is where the simulated DSP is served
+const BidderSimPath = "/bidder-sim"
+
+// maxBidderSimDelay bounds the delay scenario, which holds a connection open
+const maxBidderSimDelay = 10 * time.Second
+
+// maxBidderSimBodySize bounds simulated bid requests
+const maxBidderSimBodySize = 1024 * 1024
+
+// defaultBidderSimPrice is the CPM bid when the scenario omits price
+const defaultBidderSimPrice = 1.0
+
+// Invalid responses the simulated DSP can return (?invalid=)
+const (
+	BidderSimInvalidJSON   = "json"   // malformed response body
+	BidderSimInvalidStatus = "status" // HTTP 500
+	BidderSimInvalidImp    = "imp"    // bids on an impression not in the request
+	BidderSimInvalidPrice  = "price"  // negative bid price
+	BidderSimInvalidAdM    = "adm"    // bids without markup
+)
+
+// BidderSimHandler is a mock DSP for local development: it answers OpenRTB
+// bid requests according to scenario query params, so the server's own
+// adapters can be pointed at it (e.g. via ORTB_BIDDERS_FILE) instead of
+// external bidder stubs. It is only registered outside production.
+type BidderSimHandler struct {
+	// Numbers responses, so simulated bidders answering the same auction
+	// don't return duplicate bid IDs
+	responses atomic.Uint64
+}
+
+// NewBidderSimHandler creates a new simulated DSP handler
+func NewBidderSimHandler() *BidderSimHandler {
+	return &BidderSimHandler{}
+}
+
+// bidderSimScenario is a request's parsed scenario params
+type bidderSimScenario struct {
+	delay   time.Duration
+	price   float64
+	invalid string
+}
+
+// parseBidderSimScenario reads delay (ms), price (CPM, 0 = no bid) and
+// invalid from the query
+func parseBidderSimScenario(r *http.Request) (bidderSimScenario, error) {
+	q := r.URL.Query()
+	sc := bidderSimScenario{price: defaultBidderSimPrice, invalid: q.Get("invalid")}
+
+	if v := q.Get("delay"); v != "" {
+		ms, err := strconv.Atoi(v)
+		if err != nil || ms < 0 {
+			return sc, fmt.Errorf("delay must be a non-negative number of milliseconds")
+		}
+		sc.delay = time.Duration(ms) * time.Millisecond
+		if sc.delay > maxBidderSimDelay {
+			sc.delay = maxBidderSimDelay
+		}
+	}
+	if v := q.Get("price"); v != "" {
+		price, err := strconv.ParseFloat(v, 64)
+		if err != nil || price < 0 {
+			return sc, fmt.Errorf("price must be a non-negative CPM")
+		}
+		sc.price = price
+	}
+	switch sc.invalid {
+	case "", BidderSimInvalidJSON, BidderSimInvalidStatus, BidderSimInvalidImp, BidderSimInvalidPrice, BidderSimInvalidAdM:
+	default:
+		return sc, fmt.Errorf("unknown invalid scenario %q", sc.invalid)
+	}
+	return sc, nil
+}
+
+// ServeHTTP handles POST /bidder-sim?delay=ms&price=cpm&invalid=kind.
+// It bids price on every impression after delay, with VAST for video, and
+// answers 204 No Content when price is 0.
+func (h *BidderSimHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	sc, err := parseBidderSimScenario(r)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var req openrtb.BidRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxBidderSimBodySize)).Decode(&req); err != nil {
+		writeError(w, "Invalid bid request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if sc.delay > 0 {
+		timer := time.NewTimer(sc.delay)
+		defer timer.Stop()
+		select {
+		case <-r.Context().Done():
+			return
+		case <-timer.C:
+		}
+	}
+
+	switch {
+	case sc.invalid == BidderSimInvalidStatus:
+		writeError(w, "Simulated bidder error", http.StatusInternalServerError)
+		return
+	case sc.invalid == BidderSimInvalidJSON:
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"seatbid":[{"bid":[`))
+		return
+	case sc.price == 0 || len(req.Imp) == 0:
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	resp := openrtb.BidResponse{
+		ID:      req.ID,
+		Cur:     "USD",
+		SeatBid: []openrtb.SeatBid{{Seat: "bidder-sim", Bid: simulatedBids(&req, sc, h.responses.Add(1))}},
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// simulatedBids bids the scenario's price on every impression, corrupting
+// each bid as the invalid scenario asks. Bid IDs include the response
+// number n.
+func simulatedBids(req *openrtb.BidRequest, sc bidderSimScenario, n uint64) []openrtb.Bid {
+	bids := make([]openrtb.Bid, 0, len(req.Imp))
+	for i, imp := range req.Imp {
+		bid := openrtb.Bid{
+			ID:      fmt.Sprintf("%s-sim%d-%d", req.ID, n, i),
+			ImpID:   imp.ID,
+			Price:   sc.price,
+			CRID:    fmt.Sprintf("sim-creative-%d", i),
+			ADomain: []string{"bidder-sim.example"},
+		}
+		if imp.Video != nil {
+			bid.AdM = simulatedVAST(bid.ID)
+			bid.Dur = 15
+		} else {
+			bid.AdM = `<div style="width:300px;height:250px;background:#ccc">bidder-sim</div>`
+			if imp.Banner != nil {
+				bid.W, bid.H = imp.Banner.W, imp.Banner.H
+				if bid.W == 0 && len(imp.Banner.Format) > 0 {
+					bid.W, bid.H = imp.Banner.Format[0].W, imp.Banner.Format[0].H
+				}
+			}
+		}
+
+		switch sc.invalid {
+		case BidderSimInvalidImp:
+			bid.ImpID = imp.ID + "-unknown"
+		case BidderSimInvalidPrice:
+			bid.Price = -sc.price
+		case BidderSimInvalidAdM:
+			bid.AdM = ""
+		}
+		bids = append(bids, bid)
+	}
+	return bids
+}
+
+func simulatedVAST(id string) string {
+	return `<VAST version="4.0"><Ad id="` + id + `"><InLine><AdSystem>bidder-sim</AdSystem><AdTitle>bidder-sim</AdTitle>` +
+		`<Impression><![CDATA[https://bidder-sim.example/imp]]></Impression><Creatives><Creative><Linear>` +
+		`<Duration>00:00:15</Duration><MediaFiles><MediaFile delivery="progressive" type="video/mp4" width="1920" height="1080">` +
+		`<![CDATA[https://bidder-sim.example/ad.mp4]]></MediaFile></MediaFiles></Linear></Creative></Creatives></InLine></Ad></VAST>`
+}
@@ -0,0 +1,133 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+const bidderSimRequest = `{"id":"req-1","imp":[{"id":"imp-1","banner":{"format":[{"w":300,"h":250}]}},{"id":"imp-2","video":{"mimes":["video/mp4"]}}]}`
+
+func serveBidderSim(t *testing.T, query string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, BidderSimPath+query, strings.NewReader(bidderSimRequest))
+	w := httptest.NewRecorder()
+	NewBidderSimHandler().ServeHTTP(w, req)
+	return w
+}
+
+func TestBidderSim_Bids(t *testing.T) {
+	w := serveBidderSim(t, "?price=2.5")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp openrtb.BidResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if resp.ID != "req-1" || len(resp.SeatBid) != 1 || len(resp.SeatBid[0].Bid) != 2 {
+		t.Fatalf("expected a bid per imp, got %+v", resp)
+	}
+	banner, video := resp.SeatBid[0].Bid[0], resp.SeatBid[0].Bid[1]
+	if banner.ImpID != "imp-1" || banner.Price != 2.5 || banner.W != 300 || banner.H != 250 {
+		t.Errorf("unexpected banner bid %+v", banner)
+	}
+	if video.ImpID != "imp-2" || !strings.HasPrefix(video.AdM, "<VAST") {
+		t.Errorf("expected a VAST bid for the video imp, got %+v", video)
+	}
+}
+
+func TestBidderSim_Scenarios(t *testing.T) {
+	tests := []struct {
+		query    string
+		wantCode int
+		check    func(t *testing.T, body string)
+	}{
+		{"?price=0", http.StatusNoContent, nil},
+		{"?invalid=status", http.StatusInternalServerError, nil},
+		{"?invalid=json", http.StatusOK, func(t *testing.T, body string) {
+			if json.Valid([]byte(body)) {
+				t.Errorf("expected a malformed body, got %s", body)
+			}
+		}},
+		{"?invalid=imp", http.StatusOK, func(t *testing.T, body string) {
+			if !strings.Contains(body, `"impid":"imp-1-unknown"`) {
+				t.Errorf("expected a bid on an unknown imp, got %s", body)
+			}
+		}},
+		{"?invalid=price&price=3", http.StatusOK, func(t *testing.T, body string) {
+			if !strings.Contains(body, `"price":-3`) {
+				t.Errorf("expected a negative price, got %s", body)
+			}
+		}},
+		{"?invalid=bogus", http.StatusBadRequest, nil},
+		{"?delay=abc", http.StatusBadRequest, nil},
+		{"?price=-1", http.StatusBadRequest, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			w := serveBidderSim(t, tt.query)
+			if w.Code != tt.wantCode {
+				t.Fatalf("expected %d, got %d: %s", tt.wantCode, w.Code, w.Body.String())
+			}
+			if tt.check != nil {
+				tt.check(t, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestBidderSim_Delay(t *testing.T) {
+	start := time.Now()
+	if w := serveBidderSim(t, "?delay=50"); w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("expected the response delayed 50ms, took %v", elapsed)
+	}
+
+	// A caller that gives up ends the wait
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	req := httptest.NewRequest(http.MethodPost, BidderSimPath+"?delay=5000", strings.NewReader(bidderSimRequest)).WithContext(ctx)
+	start = time.Now()
+	NewBidderSimHandler().ServeHTTP(httptest.NewRecorder(), req)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the delay cut short by the caller's deadline, took %v", elapsed)
+	}
+}
+
+func TestBidderSim_DistinctBidIDs(t *testing.T) {
+	// Two simulated bidders answering the same auction
+	h := NewBidderSimHandler()
+	ids := make(map[string]bool)
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, BidderSimPath, strings.NewReader(bidderSimRequest)))
+		var resp openrtb.BidResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("invalid response: %v", err)
+		}
+		for _, bid := range resp.SeatBid[0].Bid {
+			if ids[bid.ID] {
+				t.Fatalf("duplicate bid ID %s", bid.ID)
+			}
+			ids[bid.ID] = true
+		}
+	}
+}
+
+func TestBidderSim_MethodNotAllowed(t *testing.T) {
+	w := httptest.NewRecorder()
+	NewBidderSimHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, BidderSimPath, nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405, got %d", w.Code)
+	}
+}
@@ -0,0 +1,229 @@
+package endpoints
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/pkg/kv"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+// CacheInvalidationChannel is the Redis pub/sub channel replicas use to
+// share invalidation commands
+const CacheInvalidationChannel = "tne_catalyst:cache_invalidate"
+
+// maxInvalidateBodySize bounds invalidation request bodies
+const maxInvalidateBodySize = 64 * 1024
+
+// CachePurger is an in-memory cache that can be dropped on demand
+type CachePurger interface {
+	PurgeCache() int
+}
+
+// CacheInvalidator is a cache whose entries can be dropped by ID
+type CacheInvalidator interface {
+	InvalidateCache(ids ...string) int
+}
+
+// CacheInvalidatorFunc adapts a function to CacheInvalidator
+type CacheInvalidatorFunc func(ids ...string) int
+
+// InvalidateCache calls f(ids...)
+func (f CacheInvalidatorFunc) InvalidateCache(ids ...string) int {
+	return f(ids...)
+}
+
+// InvalidationPublisher broadcasts invalidation commands to other replicas
+type InvalidationPublisher interface {
+	Publish(ctx context.Context, channel, message string) error
+}
+
+// CacheInvalidateRequest is a scoped invalidation command, e.g.
+// {"scope": "publisher", "ids": ["pub-123"]}
+type CacheInvalidateRequest struct {
+	Scope string   `json:"scope"`
+	IDs   []string `json:"ids"`
+}
+
+// CacheInvalidateResponse reports the result of an invalidation on this replica
+type CacheInvalidateResponse struct {
+	Scope       string `json:"scope"`
+	Invalidated int    `json:"invalidated"`
+	Broadcast   bool   `json:"broadcast"`
+}
+
+// invalidationMessage is the pub/sub payload; Origin lets a replica skip
+// commands it already applied
+type invalidationMessage struct {
+	CacheInvalidateRequest
+	Origin string `json:"origin"`
+}
+
+// CachePurgeResponse reports how many entries were dropped per cache
+type CachePurgeResponse struct {
+	Purged map[string]int `json:"purged"`
+}
+
+// CacheAdminHandler purges in-memory caches via POST /admin/cache/purge.
+// An optional ?cache=name limits the purge to one cache.
+//
+// POST /admin/cache/invalidate drops individual entries within a scope
+// (publisher, bidder, ...) and, when a publisher is set, broadcasts the
+// command so every replica applies it.
+type CacheAdminHandler struct {
+	caches       map[string]CachePurger
+	invalidators map[string]CacheInvalidator
+	publisher    InvalidationPublisher
+	instanceID   string
+}
+
+// NewCacheAdminHandler creates a new cache admin handler
+func NewCacheAdminHandler() *CacheAdminHandler {
+	return &CacheAdminHandler{
+		caches:       make(map[string]CachePurger),
+		invalidators: make(map[string]CacheInvalidator),
+		instanceID:   newInstanceID(),
+	}
+}
+
+// newInstanceID returns a random ID identifying this replica's broadcasts
+func newInstanceID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return time.Now().Format(time.RFC3339Nano)
+	}
+	return hex.EncodeToString(b)
+}
+
+// Register adds a purgeable cache under a name
+func (h *CacheAdminHandler) Register(name string, cache CachePurger) {
+	h.caches[name] = cache
+}
+
+// RegisterInvalidator adds a cache that accepts scoped invalidations
+func (h *CacheAdminHandler) RegisterInvalidator(scope string, cache CacheInvalidator) {
+	h.invalidators[scope] = cache
+}
+
+// SetPublisher enables broadcasting invalidations to other replicas
+func (h *CacheAdminHandler) SetPublisher(publisher InvalidationPublisher) {
+	h.publisher = publisher
+}
+
+// ServeHTTP handles POST /admin/cache/purge and POST /admin/cache/invalidate
+func (h *CacheAdminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		sendAdminError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+		return
+	}
+	if r.URL.Path == "/admin/cache/invalidate" {
+		h.handleInvalidate(w, r)
+		return
+	}
+
+	names := make([]string, 0, len(h.caches))
+	if name := r.URL.Query().Get("cache"); name != "" {
+		if _, ok := h.caches[name]; !ok {
+			sendAdminError(w, http.StatusNotFound, "unknown_cache", "Unknown cache: "+name)
+			return
+		}
+		names = append(names, name)
+	} else {
+		for name := range h.caches {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	resp := CachePurgeResponse{Purged: make(map[string]int, len(names))}
+	for _, name := range names {
+		resp.Purged[name] = h.caches[name].PurgeCache()
+	}
+
+	logger.Log.Info().Interface("purged", resp.Purged).Msg("Caches purged via admin API")
+	sendAdminJSON(w, http.StatusOK, resp)
+}
+
+// handleInvalidate applies an invalidation command locally and broadcasts it
+func (h *CacheAdminHandler) handleInvalidate(w http.ResponseWriter, r *http.Request) {
+	var req CacheInvalidateRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxInvalidateBodySize)).Decode(&req); err != nil {
+		sendAdminError(w, http.StatusBadRequest, "invalid_json", "Invalid request body: "+err.Error())
+		return
+	}
+	if _, ok := h.invalidators[req.Scope]; !ok {
+		sendAdminError(w, http.StatusBadRequest, "unknown_scope", "Unknown invalidation scope: "+req.Scope)
+		return
+	}
+	if len(req.IDs) == 0 {
+		sendAdminError(w, http.StatusBadRequest, "missing_ids", "At least one id is required")
+		return
+	}
+
+	resp, _ := h.Invalidate(r.Context(), req)
+	sendAdminJSON(w, http.StatusOK, resp)
+}
+
+// Invalidate applies an invalidation command on this replica and, when a
+// publisher is set, broadcasts it to the others. A failed or shed broadcast
+// is logged and reported as Broadcast false; unknown scopes return an error.
+func (h *CacheAdminHandler) Invalidate(ctx context.Context, req CacheInvalidateRequest) (CacheInvalidateResponse, error) {
+	if _, ok := h.invalidators[req.Scope]; !ok {
+		return CacheInvalidateResponse{}, fmt.Errorf("unknown invalidation scope %q", req.Scope)
+	}
+	resp := CacheInvalidateResponse{Scope: req.Scope, Invalidated: h.apply(req)}
+
+	if h.publisher != nil && kv.IsDegraded(h.publisher) {
+		// Shed the broadcast while Redis is over its error budget; the
+		// response reports Broadcast false so the caller can retry
+		logger.Log.Warn().Str("scope", req.Scope).Msg("Redis degraded, cache invalidation not broadcast")
+	} else if h.publisher != nil {
+		data, err := json.Marshal(invalidationMessage{CacheInvalidateRequest: req, Origin: h.instanceID})
+		if err == nil {
+			err = h.publisher.Publish(ctx, CacheInvalidationChannel, string(data))
+		}
+		if err != nil {
+			logger.Log.Warn().Err(err).Str("scope", req.Scope).Msg("Failed to broadcast cache invalidation")
+		} else {
+			resp.Broadcast = true
+		}
+	}
+	return resp, nil
+}
+
+// apply runs an invalidation command against the registered cache
+func (h *CacheAdminHandler) apply(req CacheInvalidateRequest) int {
+	n := h.invalidators[req.Scope].InvalidateCache(req.IDs...)
+	logger.Log.Info().
+		Str("scope", req.Scope).
+		Strs("ids", req.IDs).
+		Int("invalidated", n).
+		Msg("Cache invalidated")
+	return n
+}
+
+// Listen applies invalidation commands broadcast by other replicas until
+// messages is closed. Commands this replica published are skipped.
+func (h *CacheAdminHandler) Listen(messages <-chan string) {
+	for data := range messages {
+		var msg invalidationMessage
+		if err := json.Unmarshal([]byte(data), &msg); err != nil {
+			logger.Log.Warn().Err(err).Msg("Ignoring malformed cache invalidation message")
+			continue
+		}
+		if msg.Origin == h.instanceID {
+			continue
+		}
+		if _, ok := h.invalidators[msg.Scope]; !ok {
+			logger.Log.Warn().Str("scope", msg.Scope).Msg("Ignoring cache invalidation for unknown scope")
+			continue
+		}
+		h.apply(msg.CacheInvalidateRequest)
+	}
+}
@@ -0,0 +1,168 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/chaos"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+// defaultChaosFaultTTL applies when a PUT /admin/chaos omits ttl
+const defaultChaosFaultTTL = 5 * time.Minute
+
+// maxChaosBodySize bounds chaos fault request bodies
+const maxChaosBodySize = 4 * 1024
+
+// ChaosFaultRequest sets or clears the fault on one bidder or dependency,
+// e.g. {"bidder": "rubicon", "latency_ms": 300, "error_rate": 0.2, "ttl": "5m"}
+type ChaosFaultRequest struct {
+	Bidder      string  `json:"bidder,omitempty"`
+	Dependency  string  `json:"dependency,omitempty"`
+	LatencyMs   int     `json:"latency_ms,omitempty"`
+	LatencyRate float64 `json:"latency_rate,omitempty"`
+	ErrorRate   float64 `json:"error_rate,omitempty"`
+	DropRate    float64 `json:"drop_rate,omitempty"`
+	TTL         string  `json:"ttl,omitempty"`
+}
+
+// ChaosFault is the JSON form of an active fault
+type ChaosFault struct {
+	Scope       string    `json:"scope"`
+	ID          string    `json:"id"`
+	LatencyMs   int64     `json:"latency_ms"`
+	LatencyRate float64   `json:"latency_rate"`
+	ErrorRate   float64   `json:"error_rate"`
+	DropRate    float64   `json:"drop_rate"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// ChaosFaultsResponse lists the active faults
+type ChaosFaultsResponse struct {
+	Faults []ChaosFault `json:"faults"`
+}
+
+// ChaosHandler manages injected faults on bidder and dependency calls, so
+// circuit breakers and degradation paths can be rehearsed in staging. It is
+// only registered outside production.
+type ChaosHandler struct {
+	injector *chaos.Injector
+}
+
+// NewChaosHandler creates a new chaos admin handler
+func NewChaosHandler(injector *chaos.Injector) *ChaosHandler {
+	return &ChaosHandler{injector: injector}
+}
+
+// ServeHTTP handles chaos faults
+// Routes:
+//
+//	GET    /admin/chaos - list active faults
+//	PUT    /admin/chaos - set a fault; ttl defaults to 5m, max 1h
+//	DELETE /admin/chaos - clear a fault
+func (h *ChaosHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.list(w)
+	case http.MethodPut:
+		h.set(w, r)
+	case http.MethodDelete:
+		h.clear(w, r)
+	default:
+		sendAdminError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+	}
+}
+
+func (h *ChaosHandler) list(w http.ResponseWriter) {
+	active := h.injector.Faults()
+	resp := ChaosFaultsResponse{Faults: make([]ChaosFault, 0, len(active))}
+	for _, f := range active {
+		resp.Faults = append(resp.Faults, toChaosFault(f))
+	}
+	sendAdminJSON(w, http.StatusOK, resp)
+}
+
+func (h *ChaosHandler) set(w http.ResponseWriter, r *http.Request) {
+	req, scope, id, ok := decodeChaosFaultRequest(w, r)
+	if !ok {
+		return
+	}
+
+	ttl := defaultChaosFaultTTL
+	if req.TTL != "" {
+		var err error
+		ttl, err = time.ParseDuration(req.TTL)
+		if err != nil || ttl <= 0 || ttl > chaos.MaxFaultTTL {
+			sendAdminError(w, http.StatusBadRequest, "invalid_ttl", "ttl must be a duration between 1s and "+chaos.MaxFaultTTL.String())
+			return
+		}
+	}
+
+	f, err := h.injector.Set(chaos.Fault{
+		Scope:       scope,
+		ID:          id,
+		Latency:     time.Duration(req.LatencyMs) * time.Millisecond,
+		LatencyRate: req.LatencyRate,
+		ErrorRate:   req.ErrorRate,
+		DropRate:    req.DropRate,
+	}, ttl)
+	if err != nil {
+		sendAdminError(w, http.StatusBadRequest, "invalid_fault", err.Error())
+		return
+	}
+	logger.Log.Warn().
+		Str("scope", f.Scope).
+		Str("id", f.ID).
+		Dur("latency", f.Latency).
+		Float64("latency_rate", f.LatencyRate).
+		Float64("error_rate", f.ErrorRate).
+		Float64("drop_rate", f.DropRate).
+		Time("expires_at", f.ExpiresAt).
+		Msg("Chaos fault set")
+	sendAdminJSON(w, http.StatusOK, toChaosFault(f))
+}
+
+func (h *ChaosHandler) clear(w http.ResponseWriter, r *http.Request) {
+	_, scope, id, ok := decodeChaosFaultRequest(w, r)
+	if !ok {
+		return
+	}
+	if !h.injector.Clear(scope, id) {
+		sendAdminError(w, http.StatusNotFound, "not_found", "No active fault for "+scope+" "+id)
+		return
+	}
+	logger.Log.Info().Str("scope", scope).Str("id", id).Msg("Chaos fault cleared")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func toChaosFault(f chaos.Fault) ChaosFault {
+	return ChaosFault{
+		Scope:       f.Scope,
+		ID:          f.ID,
+		LatencyMs:   f.Latency.Milliseconds(),
+		LatencyRate: f.LatencyRate,
+		ErrorRate:   f.ErrorRate,
+		DropRate:    f.DropRate,
+		ExpiresAt:   f.ExpiresAt,
+	}
+}
+
+// decodeChaosFaultRequest reads the body and resolves exactly one of bidder
+// or dependency, writing an error response when it can't
+func decodeChaosFaultRequest(w http.ResponseWriter, r *http.Request) (ChaosFaultRequest, string, string, bool) {
+	var req ChaosFaultRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxChaosBodySize)).Decode(&req); err != nil {
+		sendAdminError(w, http.StatusBadRequest, "invalid_json", "Invalid request body: "+err.Error())
+		return req, "", "", false
+	}
+	switch {
+	case req.Bidder != "" && req.Dependency == "":
+		return req, chaos.ScopeBidder, req.Bidder, true
+	case req.Dependency != "" && req.Bidder == "":
+		return req, chaos.ScopeDependency, req.Dependency, true
+	default:
+		sendAdminError(w, http.StatusBadRequest, "invalid_target", "Exactly one of bidder or dependency is required")
+		return req, "", "", false
+	}
+}
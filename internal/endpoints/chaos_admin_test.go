@@ -0,0 +1,89 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/chaos"
+)
+
+func serveChaos(h *ChaosHandler, method, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(method, "/admin/chaos", strings.NewReader(body)))
+	return w
+}
+
+func TestChaosHandler_SetListClear(t *testing.T) {
+	h := NewChaosHandler(chaos.New())
+
+	w := serveChaos(h, http.MethodPut, `{"bidder":"rubicon","latency_ms":300,"error_rate":0.2}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var set ChaosFault
+	if err := json.Unmarshal(w.Body.Bytes(), &set); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if set.Scope != "bidder" || set.ID != "rubicon" || set.LatencyMs != 300 || set.LatencyRate != 1 || set.ErrorRate != 0.2 {
+		t.Errorf("unexpected fault: %+v", set)
+	}
+	if until := time.Until(set.ExpiresAt); until <= 4*time.Minute || until > 5*time.Minute {
+		t.Errorf("expected ~5m default expiry, got %v", until)
+	}
+
+	if w = serveChaos(h, http.MethodPut, `{"dependency":"idr","drop_rate":0.5,"ttl":"30m"}`); w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = serveChaos(h, http.MethodGet, "")
+	var list ChaosFaultsResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if len(list.Faults) != 2 || list.Faults[0].ID != "rubicon" || list.Faults[1].ID != "idr" {
+		t.Errorf("expected both faults listed, got %+v", list)
+	}
+
+	if w = serveChaos(h, http.MethodDelete, `{"bidder":"rubicon"}`); w.Code != http.StatusNoContent {
+		t.Errorf("expected 204, got %d", w.Code)
+	}
+	if w = serveChaos(h, http.MethodDelete, `{"bidder":"rubicon"}`); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for cleared fault, got %d", w.Code)
+	}
+}
+
+func TestChaosHandler_Validation(t *testing.T) {
+	h := NewChaosHandler(chaos.New())
+
+	tests := []struct {
+		name string
+		body string
+		code string
+	}{
+		{"invalid json", `{`, "invalid_json"},
+		{"no target", `{"error_rate":1}`, "invalid_target"},
+		{"both targets", `{"bidder":"rubicon","dependency":"idr","error_rate":1}`, "invalid_target"},
+		{"ttl too long", `{"bidder":"rubicon","error_rate":1,"ttl":"2h"}`, "invalid_ttl"},
+		{"rate out of range", `{"bidder":"rubicon","error_rate":2}`, "invalid_fault"},
+		{"nothing to inject", `{"bidder":"rubicon"}`, "invalid_fault"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serveChaos(h, http.MethodPut, tt.body)
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d", w.Code)
+			}
+			if !strings.Contains(w.Body.String(), tt.code) {
+				t.Errorf("expected %s, got %s", tt.code, w.Body.String())
+			}
+		})
+	}
+
+	if w := serveChaos(h, http.MethodPost, `{}`); w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405, got %d", w.Code)
+	}
+}
@@ -0,0 +1,138 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+// ConfigHistoryStore is implemented by storage.PublisherStore and
+// storage.BidderStore
+type ConfigHistoryStore interface {
+	History(ctx context.Context, id string) ([]*storage.ConfigVersion, error)
+	Rollback(ctx context.Context, id string, version int) (*storage.ConfigVersion, error)
+}
+
+// ConfigHistoryResponse lists the stored versions of one publisher or bidder
+type ConfigHistoryResponse struct {
+	ID       string                   `json:"id"`
+	Versions []*storage.ConfigVersion `json:"versions"`
+	Count    int                      `json:"count"`
+}
+
+// RollbackRequest is the optional body of a rollback request; the version
+// may also be given as ?version=
+type RollbackRequest struct {
+	Version int `json:"version"`
+}
+
+// ConfigHistoryHandler serves version history and rollback for publisher or
+// bidder configuration rows
+type ConfigHistoryHandler struct {
+	kind  string // "publishers" or "bidders"
+	store ConfigHistoryStore
+}
+
+// NewConfigHistoryHandler creates a history handler for /admin/{kind}/
+func NewConfigHistoryHandler(kind string, store ConfigHistoryStore) *ConfigHistoryHandler {
+	return &ConfigHistoryHandler{kind: kind, store: store}
+}
+
+// IsConfigHistoryPath reports whether a /admin/{kind}/{id}/... path is a
+// history or rollback route
+func IsConfigHistoryPath(path string) bool {
+	return strings.HasSuffix(path, "/history") || strings.HasSuffix(path, "/rollback")
+}
+
+// ServeHTTP handles history requests
+// Routes:
+//
+//	GET  /admin/{kind}/:id/history            - List stored versions, newest first
+//	POST /admin/{kind}/:id/rollback?version=N - Restore version N as a new version
+func (h *ConfigHistoryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.store == nil {
+		sendAdminError(w, http.StatusServiceUnavailable, "database_unavailable", "Configuration history requires a database connection")
+		return
+	}
+
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/admin/"+h.kind), "/")
+	parts := strings.Split(path, "/")
+	if len(parts) != 2 || parts[0] == "" {
+		sendAdminError(w, http.StatusNotFound, "not_found", "Unknown history route")
+		return
+	}
+	id, action := parts[0], parts[1]
+
+	switch {
+	case action == "history" && r.Method == http.MethodGet:
+		h.history(w, r, id)
+	case action == "rollback" && r.Method == http.MethodPost:
+		h.rollback(w, r, id)
+	case action == "history" || action == "rollback":
+		sendAdminError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+	default:
+		sendAdminError(w, http.StatusNotFound, "not_found", "Unknown history route")
+	}
+}
+
+// history returns every stored version
+func (h *ConfigHistoryHandler) history(w http.ResponseWriter, r *http.Request, id string) {
+	versions, err := h.store.History(r.Context(), id)
+	if err != nil {
+		logger.Log.Error().Err(err).Str("kind", h.kind).Str("id", id).Msg("Failed to load configuration history")
+		sendAdminError(w, http.StatusInternalServerError, "database_error", "Failed to retrieve history")
+		return
+	}
+	if len(versions) == 0 {
+		sendAdminError(w, http.StatusNotFound, "not_found", "No history found")
+		return
+	}
+	sendAdminJSON(w, http.StatusOK, ConfigHistoryResponse{ID: id, Versions: versions, Count: len(versions)})
+}
+
+// rollback restores a stored version
+func (h *ConfigHistoryHandler) rollback(w http.ResponseWriter, r *http.Request, id string) {
+	version := 0
+	if v := r.URL.Query().Get("version"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil {
+			sendAdminError(w, http.StatusBadRequest, "invalid_version", "version must be an integer")
+			return
+		}
+		version = parsed
+	} else if r.ContentLength != 0 {
+		var req RollbackRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096)).Decode(&req); err != nil {
+			sendAdminError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON in request body")
+			return
+		}
+		version = req.Version
+	}
+	if version <= 0 {
+		sendAdminError(w, http.StatusBadRequest, "invalid_version", "A positive version is required")
+		return
+	}
+
+	restored, err := h.store.Rollback(r.Context(), id, version)
+	if err != nil {
+		if !sendStorageError(w, err) {
+			logger.Log.Error().Err(err).Str("kind", h.kind).Str("id", id).Int("version", version).Msg("Failed to roll back configuration")
+			sendAdminError(w, http.StatusInternalServerError, "database_error", "Failed to roll back")
+		}
+		return
+	}
+
+	logger.Log.Info().
+		Str("kind", h.kind).
+		Str("id", id).
+		Int("from_version", version).
+		Int("new_version", restored.Version).
+		Msg("Configuration rolled back")
+
+	sendAdminJSON(w, http.StatusOK, restored)
+}
@@ -0,0 +1,151 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+)
+
+type fakeHistoryStore struct {
+	versions   map[string][]*storage.ConfigVersion
+	err        error
+	rolledBack []int
+}
+
+func (f *fakeHistoryStore) History(ctx context.Context, id string) ([]*storage.ConfigVersion, error) {
+	return f.versions[id], f.err
+}
+
+func (f *fakeHistoryStore) Rollback(ctx context.Context, id string, version int) (*storage.ConfigVersion, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	versions := f.versions[id]
+	for _, v := range versions {
+		if v.Version == version {
+			f.rolledBack = append(f.rolledBack, version)
+			restored := &storage.ConfigVersion{Version: versions[0].Version + 1, Snapshot: v.Snapshot, ChangedAt: time.Now()}
+			f.versions[id] = append([]*storage.ConfigVersion{restored}, versions...)
+			return restored, nil
+		}
+	}
+	return nil, storage.ErrNotFound
+}
+
+func newFakeHistoryStore() *fakeHistoryStore {
+	return &fakeHistoryStore{versions: map[string][]*storage.ConfigVersion{
+		"rubicon": {
+			{Version: 2, Snapshot: json.RawMessage(`{"timeout_ms":50}`)},
+			{Version: 1, Snapshot: json.RawMessage(`{"timeout_ms":200}`)},
+		},
+	}}
+}
+
+func TestConfigHistoryHandler_History(t *testing.T) {
+	h := NewConfigHistoryHandler("bidders", newFakeHistoryStore())
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/bidders/rubicon/history", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp ConfigHistoryResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.ID != "rubicon" || resp.Count != 2 || resp.Versions[0].Version != 2 {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/bidders/unknown/history", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown bidder, got %d", rec.Code)
+	}
+}
+
+func TestConfigHistoryHandler_Rollback(t *testing.T) {
+	store := newFakeHistoryStore()
+	h := NewConfigHistoryHandler("bidders", store)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/bidders/rubicon/rollback?version=1", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var restored storage.ConfigVersion
+	if err := json.Unmarshal(rec.Body.Bytes(), &restored); err != nil {
+		t.Fatal(err)
+	}
+	if restored.Version != 3 || string(restored.Snapshot) != `{"timeout_ms":200}` {
+		t.Errorf("expected version 1 restored as version 3, got %+v", restored)
+	}
+
+	// Version in the JSON body
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/bidders/rubicon/rollback", strings.NewReader(`{"version":2}`)))
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected 200 for body version, got %d", rec.Code)
+	}
+	if len(store.rolledBack) != 2 || store.rolledBack[1] != 2 {
+		t.Errorf("expected rollbacks to versions 1 and 2, got %v", store.rolledBack)
+	}
+}
+
+func TestConfigHistoryHandler_RollbackErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		store  ConfigHistoryStore
+		want   int
+	}{
+		{"missing version", http.MethodPost, "/admin/bidders/rubicon/rollback", "", newFakeHistoryStore(), http.StatusBadRequest},
+		{"non-numeric version", http.MethodPost, "/admin/bidders/rubicon/rollback?version=abc", "", newFakeHistoryStore(), http.StatusBadRequest},
+		{"invalid body", http.MethodPost, "/admin/bidders/rubicon/rollback", "{", newFakeHistoryStore(), http.StatusBadRequest},
+		{"unknown version", http.MethodPost, "/admin/bidders/rubicon/rollback?version=9", "", newFakeHistoryStore(), http.StatusNotFound},
+		{"store error", http.MethodPost, "/admin/bidders/rubicon/rollback?version=1", "", &fakeHistoryStore{err: errors.New("db down")}, http.StatusInternalServerError},
+		{"wrong method", http.MethodGet, "/admin/bidders/rubicon/rollback", "", newFakeHistoryStore(), http.StatusMethodNotAllowed},
+		{"unknown action", http.MethodGet, "/admin/bidders/rubicon/other", "", newFakeHistoryStore(), http.StatusNotFound},
+		{"no database", http.MethodGet, "/admin/bidders/rubicon/history", "", nil, http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewConfigHistoryHandler("bidders", tt.store)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+			if rec.Code != tt.want {
+				t.Errorf("expected %d, got %d: %s", tt.want, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestPublisherAdminHandler_DelegatesHistory(t *testing.T) {
+	store := &fakeHistoryStore{versions: map[string][]*storage.ConfigVersion{
+		"pub1": {{Version: 1, Snapshot: json.RawMessage(`{"publisher_id":"pub1"}`)}},
+	}}
+	h := NewPublisherAdminHandler(nil)
+	h.SetHistoryHandler(NewConfigHistoryHandler("publishers", store))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/publishers/pub1/history", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected history served without Redis, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/publishers/pub1", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected regular routes to still require Redis, got %d", rec.Code)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
 	"github.com/thenexusengine/tne_springwire/internal/usersync"
 	"github.com/thenexusengine/tne_springwire/pkg/logger"
 )
@@ -53,11 +55,53 @@ type BidderSyncStatus struct {
 	Error    string             `json:"error,omitempty"`
 }
 
+// Cookie sync request outcomes, used as metric labels
+const (
+	CookieSyncStatusOK          = "ok"
+	CookieSyncStatusOptOut      = "opt_out"
+	CookieSyncStatusGDPRBlocked = "gdpr_blocked"
+	CookieSyncStatusCCPABlocked = "ccpa_blocked"
+)
+
+// Per-bidder cookie sync outcomes, used as metric labels
+const (
+	BidderSyncStatusSynced           = "synced"
+	BidderSyncStatusAlreadySynced    = "already_synced"
+	BidderSyncStatusPrivacyBlocked   = "privacy_blocked"
+	BidderSyncStatusDisabled         = "disabled"
+	BidderSyncStatusUnsupported      = "unsupported"
+	BidderSyncStatusTypeNotSupported = "type_not_supported"
+)
+
+// unknownSyncBidder is the metric label for requested bidder codes that
+// aren't registered, so clients can't add label values
+const unknownSyncBidder = "unknown"
+
+// gdprPurposeStorage is TCF purpose 1, storing and accessing information on
+// a device, which a sync needs to set the bidder's cookie
+const gdprPurposeStorage = 1
+
+// CookieSyncMetrics records cookie sync outcomes
+type CookieSyncMetrics interface {
+	RecordCookieSync(status string)
+	RecordBidderSync(bidder, status string)
+}
+
+// SyncBidderRegistry looks up the bidders enabled for auctions, whose
+// BidderInfo.GVLVendorID is checked for vendor consent when GDPR applies
+type SyncBidderRegistry interface {
+	Get(bidderCode string) (adapters.AdapterWithInfo, bool)
+}
+
 // CookieSyncHandler handles cookie sync requests
 type CookieSyncHandler struct {
-	syncers  map[string]*usersync.Syncer
-	hostURL  string
-	maxSyncs int
+	syncers     map[string]*usersync.Syncer
+	hostURL     string
+	maxSyncs    int
+	bidders     SyncBidderRegistry
+	enforceGDPR bool
+	enforceCCPA bool
+	metrics     CookieSyncMetrics
 }
 
 // CookieSyncConfig holds configuration for the cookie sync handler
@@ -65,6 +109,12 @@ type CookieSyncConfig struct {
 	HostURL     string
 	MaxSyncs    int
 	SyncConfigs map[string]usersync.SyncerConfig
+	// Bidders limits syncs to bidders enabled for auctions (nil = every
+	// enabled syncer)
+	Bidders SyncBidderRegistry
+	// Privacy is the privacy middleware's configuration; its EnforceGDPR and
+	// EnforceCCPA apply to syncs too (nil = enforce both)
+	Privacy *middleware.PrivacyConfig
 }
 
 // DefaultCookieSyncConfig returns default configuration
@@ -84,10 +134,35 @@ func NewCookieSyncHandler(config *CookieSyncConfig) *CookieSyncHandler {
 		syncers[code] = usersync.NewSyncer(syncConfig, config.HostURL)
 	}
 
-	return &CookieSyncHandler{
-		syncers:  syncers,
-		hostURL:  config.HostURL,
-		maxSyncs: config.MaxSyncs,
+	h := &CookieSyncHandler{
+		syncers:     syncers,
+		hostURL:     config.HostURL,
+		maxSyncs:    config.MaxSyncs,
+		bidders:     config.Bidders,
+		enforceGDPR: true,
+		enforceCCPA: true,
+	}
+	if config.Privacy != nil {
+		h.enforceGDPR = config.Privacy.EnforceGDPR
+		h.enforceCCPA = config.Privacy.EnforceCCPA
+	}
+	return h
+}
+
+// SetMetrics sets the metrics interface for sync outcomes
+func (h *CookieSyncHandler) SetMetrics(metrics CookieSyncMetrics) {
+	h.metrics = metrics
+}
+
+func (h *CookieSyncHandler) recordSync(status string) {
+	if h.metrics != nil {
+		h.metrics.RecordCookieSync(status)
+	}
+}
+
+func (h *CookieSyncHandler) recordBidderSync(bidder, status string) {
+	if h.metrics != nil {
+		h.metrics.RecordBidderSync(bidder, status)
 	}
 }
 
@@ -113,22 +188,31 @@ func (h *CookieSyncHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	// GDPR FIX: Validate GDPR consent before processing cookie sync
 	// If GDPR=1 but no valid consent, do not return sync URLs
-	if req.GDPR == 1 {
+	var tcf *middleware.TCFv2Data
+	if req.GDPR == 1 && h.enforceGDPR {
 		if req.GDPRConsent == "" {
 			logger.Log.Warn().Msg("GDPR consent required but not provided for cookie sync")
-			h.respondJSON(w, CookieSyncResponse{
-				Status:       "ok",
-				BidderStatus: []BidderSyncStatus{},
-			})
+			h.respondBlocked(w, CookieSyncStatusGDPRBlocked)
 			return
 		}
-		// Validate consent string format (minimum length for TCF v2)
-		if len(req.GDPRConsent) < 20 {
-			logger.Log.Warn().Msg("Invalid GDPR consent string for cookie sync")
-			h.respondJSON(w, CookieSyncResponse{
-				Status:       "ok",
-				BidderStatus: []BidderSyncStatus{},
-			})
+		var err error
+		if tcf, err = middleware.ParseTCFv2(req.GDPRConsent); err != nil || tcf == nil {
+			logger.Log.Warn().Err(err).Msg("Invalid GDPR consent string for cookie sync")
+			h.respondBlocked(w, CookieSyncStatusGDPRBlocked)
+			return
+		}
+		// Syncing stores an ID on the device, so purpose 1 consent is required
+		if tcf.Version == 2 && !tcf.HasPurposeConsent(gdprPurposeStorage) {
+			logger.Log.Debug().Msg("No purpose 1 consent for cookie sync")
+			h.respondBlocked(w, CookieSyncStatusGDPRBlocked)
+			return
+		}
+	}
+
+	// A CCPA opt-out of sale means no bidder may be told the user's ID
+	if h.enforceCCPA && req.USPrivacy != "" {
+		if signal, err := middleware.ParseUSPrivacy(req.USPrivacy); err == nil && signal.OptedOut() {
+			h.respondBlocked(w, CookieSyncStatusCCPABlocked)
 			return
 		}
 	}
@@ -138,12 +222,14 @@ func (h *CookieSyncHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	// Check for opt-out
 	if cookie.IsOptOut() {
+		h.recordSync(CookieSyncStatusOptOut)
 		h.respondJSON(w, CookieSyncResponse{Status: "ok"})
 		return
 	}
 
-	// Determine which bidders to sync
-	biddersToSync := h.getBiddersToSync(req, cookie)
+	// Determine which bidders to sync; already-synced bidders are skipped
+	// (and counted) below
+	biddersToSync := h.getBiddersToSync(req, nil)
 
 	// Build response
 	response := CookieSyncResponse{
@@ -165,6 +251,7 @@ func (h *CookieSyncHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 		syncer, ok := h.syncers[strings.ToLower(bidderCode)]
 		if !ok {
+			h.recordBidderSync(h.bidderLabel(bidderCode), BidderSyncStatusUnsupported)
 			response.BidderStatus = append(response.BidderStatus, BidderSyncStatus{
 				Bidder: bidderCode,
 				Error:  "unsupported bidder",
@@ -172,19 +259,30 @@ func (h *CookieSyncHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			continue
 		}
 
-		if !syncer.IsEnabled() {
+		gvlID, enabled := h.bidderInfo(syncer.BidderCode())
+		if !syncer.IsEnabled() || !enabled {
+			h.recordBidderSync(syncer.BidderCode(), BidderSyncStatusDisabled)
 			continue
 		}
 
 		// Check if already synced
 		if cookie.HasUID(bidderCode) {
+			h.recordBidderSync(syncer.BidderCode(), BidderSyncStatusAlreadySynced)
+			continue
+		}
+
+		// Under GDPR each bidder needs the user's vendor consent for storage,
+		// subject to the publisher's restrictions
+		if tcf != nil && tcf.Version == 2 && gvlID > 0 && !tcf.VendorPurposeAllowed(gvlID, middleware.PurposeStorageAccess) {
+			h.recordBidderSync(syncer.BidderCode(), BidderSyncStatusPrivacyBlocked)
 			continue
 		}
 
 		// Determine sync type based on filterSettings
-		syncType := h.getSyncTypeForBidder(bidderCode, req.FilterSettings)
+		syncType := h.getSyncTypeForBidder(bidderCode, syncer, req.FilterSettings)
 		if syncType == usersync.SyncType("") {
-			// Bidder filtered out by filterSettings
+			// No sync type allowed by filterSettings is supported by the bidder
+			h.recordBidderSync(syncer.BidderCode(), BidderSyncStatusTypeNotSupported)
 			continue
 		}
 
@@ -204,8 +302,10 @@ func (h *CookieSyncHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			NoCookie: true,
 			UserSync: syncInfo,
 		})
+		h.recordBidderSync(syncer.BidderCode(), BidderSyncStatusSynced)
 		syncCount++
 	}
+	h.recordSync(CookieSyncStatusOK)
 
 	// Set cookie
 	if httpCookie, err := cookie.ToHTTPCookie(h.getCookieDomain(r)); err == nil {
@@ -216,30 +316,56 @@ func (h *CookieSyncHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 }
 
 // getSyncTypeForBidder determines the sync type for a bidder based on filterSettings
-// Returns empty string if the bidder should be filtered out
-func (h *CookieSyncHandler) getSyncTypeForBidder(bidderCode string, filterSettings *FilterSettings) usersync.SyncType {
-	if filterSettings == nil {
-		// No filter settings - default to redirect
-		return usersync.SyncTypeRedirect
+// and the sync URLs the bidder supports
+// Returns empty string if the bidder supports no sync type
+func (h *CookieSyncHandler) getSyncTypeForBidder(bidderCode string, syncer *usersync.Syncer, filterSettings *FilterSettings) usersync.SyncType {
+	var preferred []usersync.SyncType
+	if filterSettings != nil {
+		// Try iframe first (preferred for better sync rates)
+		if filterSettings.Iframe != nil && h.shouldIncludeBidder(bidderCode, filterSettings.Iframe) {
+			preferred = append(preferred, usersync.SyncTypeIframe)
+		}
+		// Try redirect as fallback
+		if filterSettings.Redirect != nil && h.shouldIncludeBidder(bidderCode, filterSettings.Redirect) {
+			preferred = append(preferred, usersync.SyncTypeRedirect)
+		}
 	}
 
-	// Try iframe first (preferred for better sync rates)
-	if filterSettings.Iframe != nil {
-		if h.shouldIncludeBidder(bidderCode, filterSettings.Iframe) {
-			return usersync.SyncTypeIframe
+	// If the bidder doesn't match any filter, default to redirect, then iframe
+	// This matches Prebid.js behavior where filterSettings is advisory, not restrictive
+	preferred = append(preferred, usersync.SyncTypeRedirect, usersync.SyncTypeIframe)
+
+	for _, syncType := range preferred {
+		if syncer.SupportsType(syncType) {
+			return syncType
 		}
 	}
+	return ""
+}
 
-	// Try redirect as fallback
-	if filterSettings.Redirect != nil {
-		if h.shouldIncludeBidder(bidderCode, filterSettings.Redirect) {
-			return usersync.SyncTypeRedirect
-		}
+// bidderInfo returns a bidder's GVL vendor ID and whether it is enabled for
+// auctions. Without a registry every bidder is enabled, with no GVL ID.
+func (h *CookieSyncHandler) bidderInfo(bidderCode string) (int, bool) {
+	if h.bidders == nil {
+		return 0, true
 	}
+	awi, ok := h.bidders.Get(bidderCode)
+	if !ok {
+		return 0, false
+	}
+	return awi.Info.GVLVendorID, awi.Info.Enabled
+}
 
-	// If filterSettings is provided but bidder doesn't match any filter, default to redirect
-	// This matches Prebid.js behavior where filterSettings is advisory, not restrictive
-	return usersync.SyncTypeRedirect
+// bidderLabel returns the metric label for a requested bidder code without a
+// syncer: the code when the registry knows it, otherwise "unknown"
+func (h *CookieSyncHandler) bidderLabel(bidderCode string) string {
+	if h.bidders == nil {
+		return unknownSyncBidder
+	}
+	if _, ok := h.bidders.Get(bidderCode); !ok {
+		return unknownSyncBidder
+	}
+	return bidderCode
 }
 
 // shouldIncludeBidder checks if a bidder passes the filter configuration
@@ -311,6 +437,16 @@ func (h *CookieSyncHandler) getCookieDomain(r *http.Request) string {
 	return host
 }
 
+// respondBlocked answers a sync the user's privacy signals don't allow with
+// no bidders
+func (h *CookieSyncHandler) respondBlocked(w http.ResponseWriter, status string) {
+	h.recordSync(status)
+	h.respondJSON(w, CookieSyncResponse{
+		Status:       "ok",
+		BidderStatus: []BidderSyncStatus{},
+	})
+}
+
 // respondJSON writes a JSON response
 func (h *CookieSyncHandler) respondJSON(w http.ResponseWriter, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
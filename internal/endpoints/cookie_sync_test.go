@@ -2,12 +2,16 @@ package endpoints
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
 
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
 	"github.com/thenexusengine/tne_springwire/internal/usersync"
 )
 
@@ -310,7 +314,7 @@ func TestCookieSyncHandler_GDPR(t *testing.T) {
 	reqBody := CookieSyncRequest{
 		Bidders:     []string{"appnexus"},
 		GDPR:        1,
-		GDPRConsent: "CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA",
+		GDPRConsent: tcfConsent([]int{1, 2}, nil),
 	}
 	body, _ := json.Marshal(reqBody)
 
@@ -671,3 +675,232 @@ func TestCookieSyncHandler_NoGDPR_Works(t *testing.T) {
 		t.Error("expected bidder status when GDPR=0")
 	}
 }
+
+// tcfConsent encodes a TCF v2 consent string granting the given purposes and
+// vendors (bitfield vendor encoding)
+func tcfConsent(purposes, vendors []int) string {
+	var bits []bool
+	write := func(v, n int) {
+		for i := n - 1; i >= 0; i-- {
+			bits = append(bits, v&(1<<i) != 0)
+		}
+	}
+	write(2, 6)           // version
+	write(0, 36)          // created
+	write(0, 36)          // last updated
+	write(0, 12+12+6)     // cmp id, cmp version, consent screen
+	write(0, 12)          // consent language
+	write(0, 12+6+1+1+12) // vendor list version, policy version, flags, special features
+	purposeBits := make([]bool, 24)
+	for _, p := range purposes {
+		purposeBits[p-1] = true
+	}
+	bits = append(bits, purposeBits...)
+	write(0, 24+1+12) // purpose LI transparency, purpose one treatment, publisher CC
+	maxVendor := 0
+	for _, v := range vendors {
+		if v > maxVendor {
+			maxVendor = v
+		}
+	}
+	write(maxVendor, 16)
+	write(0, 1) // bitfield encoding
+	vendorBits := make([]bool, maxVendor)
+	for _, v := range vendors {
+		vendorBits[v-1] = true
+	}
+	bits = append(bits, vendorBits...)
+
+	data := make([]byte, (len(bits)+7)/8)
+	for i, b := range bits {
+		if b {
+			data[i/8] |= 1 << (7 - i%8)
+		}
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// syncMetrics counts cookie sync outcomes
+type syncMetrics struct {
+	syncs   map[string]int
+	bidders map[string]int
+}
+
+func (m *syncMetrics) RecordCookieSync(status string) {
+	if m.syncs == nil {
+		m.syncs = make(map[string]int)
+	}
+	m.syncs[status]++
+}
+
+func (m *syncMetrics) RecordBidderSync(bidder, status string) {
+	if m.bidders == nil {
+		m.bidders = make(map[string]int)
+	}
+	m.bidders[bidder+"/"+status]++
+}
+
+func postCookieSync(t *testing.T, handler *CookieSyncHandler, reqBody CookieSyncRequest) CookieSyncResponse {
+	t.Helper()
+	body, _ := json.Marshal(reqBody)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("POST", "/cookie_sync", bytes.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	var resp CookieSyncResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	return resp
+}
+
+func syncedBidders(resp CookieSyncResponse) []string {
+	var bidders []string
+	for _, s := range resp.BidderStatus {
+		if s.UserSync != nil {
+			bidders = append(bidders, s.Bidder)
+		}
+	}
+	return bidders
+}
+
+func TestCookieSyncHandler_GDPRPurposeAndVendorConsent(t *testing.T) {
+	registry := adapters.NewRegistry()
+	registry.Register("appnexus", nil, adapters.BidderInfo{Enabled: true, GVLVendorID: 32})
+	registry.Register("rubicon", nil, adapters.BidderInfo{Enabled: true, GVLVendorID: 52})
+	config := DefaultCookieSyncConfig("https://test.example.com")
+	config.Bidders = registry
+	handler := NewCookieSyncHandler(config)
+	metrics := &syncMetrics{}
+	handler.SetMetrics(metrics)
+
+	// No purpose 1 consent: nothing may be stored on the device
+	resp := postCookieSync(t, handler, CookieSyncRequest{
+		Bidders:     []string{"appnexus", "rubicon"},
+		GDPR:        1,
+		GDPRConsent: tcfConsent([]int{2, 7}, []int{32, 52}),
+	})
+	if len(resp.BidderStatus) != 0 {
+		t.Errorf("expected no syncs without purpose 1 consent, got %+v", resp.BidderStatus)
+	}
+
+	// Purpose 1 but only appnexus has vendor consent
+	resp = postCookieSync(t, handler, CookieSyncRequest{
+		Bidders:     []string{"appnexus", "rubicon"},
+		GDPR:        1,
+		GDPRConsent: tcfConsent([]int{1}, []int{32}),
+	})
+	if got := syncedBidders(resp); len(got) != 1 || got[0] != "appnexus" {
+		t.Errorf("expected only appnexus synced, got %v", got)
+	}
+
+	if metrics.syncs[CookieSyncStatusGDPRBlocked] != 1 || metrics.syncs[CookieSyncStatusOK] != 1 {
+		t.Errorf("unexpected sync metrics %v", metrics.syncs)
+	}
+	if metrics.bidders["appnexus/"+BidderSyncStatusSynced] != 1 || metrics.bidders["rubicon/"+BidderSyncStatusPrivacyBlocked] != 1 {
+		t.Errorf("unexpected bidder metrics %v", metrics.bidders)
+	}
+}
+
+func TestCookieSyncHandler_GDPRNotEnforced(t *testing.T) {
+	config := DefaultCookieSyncConfig("https://test.example.com")
+	config.Privacy = &middleware.PrivacyConfig{EnforceGDPR: false, EnforceCCPA: true}
+	handler := NewCookieSyncHandler(config)
+
+	resp := postCookieSync(t, handler, CookieSyncRequest{Bidders: []string{"appnexus"}, GDPR: 1})
+	if got := syncedBidders(resp); len(got) != 1 {
+		t.Errorf("expected sync when GDPR enforcement is disabled, got %v", got)
+	}
+}
+
+func TestCookieSyncHandler_CCPAOptOut(t *testing.T) {
+	handler := createTestHandler()
+	metrics := &syncMetrics{}
+	handler.SetMetrics(metrics)
+
+	resp := postCookieSync(t, handler, CookieSyncRequest{Bidders: []string{"appnexus"}, USPrivacy: "1YYN"})
+	if len(resp.BidderStatus) != 0 {
+		t.Errorf("expected no syncs after CCPA opt-out, got %+v", resp.BidderStatus)
+	}
+	if metrics.syncs[CookieSyncStatusCCPABlocked] != 1 {
+		t.Errorf("expected ccpa_blocked recorded, got %v", metrics.syncs)
+	}
+
+	resp = postCookieSync(t, handler, CookieSyncRequest{Bidders: []string{"appnexus"}, USPrivacy: "1YNN"})
+	if got := syncedBidders(resp); len(got) != 1 {
+		t.Errorf("expected sync without opt-out, got %v", got)
+	}
+}
+
+func TestCookieSyncHandler_DisabledBidder(t *testing.T) {
+	registry := adapters.NewRegistry()
+	registry.Register("appnexus", nil, adapters.BidderInfo{Enabled: true})
+	registry.Register("rubicon", nil, adapters.BidderInfo{Enabled: false})
+	config := DefaultCookieSyncConfig("https://test.example.com")
+	config.Bidders = registry
+	handler := NewCookieSyncHandler(config)
+	metrics := &syncMetrics{}
+	handler.SetMetrics(metrics)
+
+	resp := postCookieSync(t, handler, CookieSyncRequest{Bidders: []string{"appnexus", "rubicon", "pubmatic"}})
+	if got := syncedBidders(resp); len(got) != 1 || got[0] != "appnexus" {
+		t.Errorf("expected only the enabled bidder synced, got %v", got)
+	}
+	if metrics.bidders["rubicon/"+BidderSyncStatusDisabled] != 1 || metrics.bidders["pubmatic/"+BidderSyncStatusDisabled] != 1 {
+		t.Errorf("expected disabled and unregistered bidders counted, got %v", metrics.bidders)
+	}
+}
+
+func TestCookieSyncHandler_SyncTypes(t *testing.T) {
+	handler := NewCookieSyncHandler(&CookieSyncConfig{
+		HostURL:  "https://test.example.com",
+		MaxSyncs: 8,
+		SyncConfigs: map[string]usersync.SyncerConfig{
+			"both":       {BidderCode: "both", IframeSyncURL: "https://both.example/iframe", RedirectSyncURL: "https://both.example/pixel", Enabled: true},
+			"iframeonly": {BidderCode: "iframeonly", IframeSyncURL: "https://iframe.example/sync", Enabled: true},
+		},
+	})
+
+	types := func(filter *FilterSettings) map[string]usersync.SyncType {
+		resp := postCookieSync(t, handler, CookieSyncRequest{Bidders: []string{"both", "iframeonly"}, FilterSettings: filter})
+		got := make(map[string]usersync.SyncType)
+		for _, s := range resp.BidderStatus {
+			if s.UserSync != nil {
+				got[s.Bidder] = s.UserSync.Type
+			}
+		}
+		return got
+	}
+
+	// Without filterSettings redirect is preferred, falling back to iframe
+	if got := types(nil); got["both"] != usersync.SyncTypeRedirect || got["iframeonly"] != usersync.SyncTypeIframe {
+		t.Errorf("unexpected default sync types %v", got)
+	}
+
+	// An iframe filter prefers iframes for the bidders it includes
+	iframe := &FilterSettings{Iframe: &FilterConfig{Bidders: "include", Filter: []string{"both"}}}
+	if got := types(iframe); got["both"] != usersync.SyncTypeIframe || got["iframeonly"] != usersync.SyncTypeIframe {
+		t.Errorf("unexpected filtered sync types %v", got)
+	}
+}
+
+func TestCookieSyncHandler_UnsupportedBidderLabel(t *testing.T) {
+	registry := adapters.NewRegistry()
+	registry.Register("nosync", nil, adapters.BidderInfo{Enabled: true})
+	config := DefaultCookieSyncConfig("https://test.example.com")
+	config.Bidders = registry
+	handler := NewCookieSyncHandler(config)
+	metrics := &syncMetrics{}
+	handler.SetMetrics(metrics)
+
+	postCookieSync(t, handler, CookieSyncRequest{Bidders: []string{"nosync", "made-up-1", "made-up-2"}})
+
+	want := map[string]int{
+		"nosync/" + BidderSyncStatusUnsupported:  1,
+		"unknown/" + BidderSyncStatusUnsupported: 2,
+	}
+	if !reflect.DeepEqual(metrics.bidders, want) {
+		t.Errorf("expected unregistered codes counted as unknown %v, got %v", want, metrics.bidders)
+	}
+}
@@ -0,0 +1,178 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+// creativeAdminPrefix is the path prefix for creative review
+const creativeAdminPrefix = "/admin/api/creatives"
+
+// maxReviewHashes caps the creatives reviewed by one bulk request, and
+// maxReviewBodySize its body
+const (
+	maxReviewHashes   = 1000
+	maxReviewBodySize = 128 * 1024
+)
+
+// creativeReviewActions maps review routes to the status they set
+var creativeReviewActions = map[string]string{
+	"approve": storage.CreativeStatusApproved,
+	"block":   storage.CreativeStatusBlocked,
+	"reset":   storage.CreativeStatusNew,
+}
+
+// CreativeReviewer lists registered creatives and sets their review status;
+// implemented by creatives.Registry
+type CreativeReviewer interface {
+	ListPage(ctx context.Context, opts storage.ListOptions) ([]*storage.Creative, int, error)
+	SetStatus(ctx context.Context, hashes []string, status string) error
+}
+
+// CreativeReviewRequest is the body for bulk review
+type CreativeReviewRequest struct {
+	Hashes []string `json:"hashes"`
+}
+
+// CreativeReviewResponse reports a bulk review
+type CreativeReviewResponse struct {
+	Status   string `json:"status"`
+	Reviewed int    `json:"reviewed"`
+}
+
+// CreativeListResponse is the response for listing creatives
+type CreativeListResponse struct {
+	Creatives  []*storage.Creative `json:"creatives"`
+	Count      int                 `json:"count"` // Creatives in this page
+	Total      int                 `json:"total"` // Creatives matching the filter
+	NextCursor string              `json:"next_cursor,omitempty"`
+}
+
+// CreativesAdminHandler serves manual creative review
+type CreativesAdminHandler struct {
+	reviewer CreativeReviewer
+}
+
+// NewCreativesAdminHandler creates a creative review handler; reviewer may be
+// nil when no database is configured
+func NewCreativesAdminHandler(reviewer CreativeReviewer) *CreativesAdminHandler {
+	return &CreativesAdminHandler{reviewer: reviewer}
+}
+
+// ServeHTTP handles creative review requests
+// Routes:
+//
+//	GET  /admin/api/creatives         - List creatives (?limit, ?offset, ?cursor, ?status=new|approved|blocked, ?sort)
+//	POST /admin/api/creatives/approve - Approve creatives by hash ({"hashes": [...]})
+//	POST /admin/api/creatives/block   - Block creatives by hash
+//	POST /admin/api/creatives/reset   - Return creatives to new
+func (h *CreativesAdminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.reviewer == nil {
+		sendAdminError(w, http.StatusServiceUnavailable, "database_unavailable", "Creative review requires a database connection")
+		return
+	}
+
+	action := strings.Trim(strings.TrimPrefix(r.URL.Path, creativeAdminPrefix), "/")
+	status, known := creativeReviewActions[action]
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		h.listCreatives(w, r)
+	case known && r.Method == http.MethodPost:
+		h.review(w, r, status)
+	case action != "" && !known:
+		sendAdminError(w, http.StatusNotFound, "not_found", "Unknown creative admin route")
+	default:
+		sendAdminError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+	}
+}
+
+// listCreatives returns a page of registered creatives
+func (h *CreativesAdminHandler) listCreatives(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseListOptions(r)
+	if err != nil {
+		sendAdminError(w, http.StatusBadRequest, "invalid_list_options", err.Error())
+		return
+	}
+
+	creatives, total, err := h.reviewer.ListPage(r.Context(), opts)
+	if errors.Is(err, storage.ErrInvalidListOptions) {
+		sendAdminError(w, http.StatusBadRequest, "invalid_list_options", err.Error())
+		return
+	}
+	if err != nil {
+		logger.Log.Error().Err(err).Msg("Failed to list creatives")
+		sendAdminError(w, http.StatusInternalServerError, "database_error", "Failed to retrieve creatives")
+		return
+	}
+	if creatives == nil {
+		creatives = []*storage.Creative{}
+	}
+
+	var cursor string
+	if len(creatives) > 0 {
+		cursor = nextCursor(opts, "hash", len(creatives), creatives[len(creatives)-1].Hash)
+	}
+	setListHeaders(w, total, cursor)
+	sendAdminJSON(w, http.StatusOK, CreativeListResponse{Creatives: creatives, Count: len(creatives), Total: total, NextCursor: cursor})
+}
+
+// review sets the status of every creative in the request body
+func (h *CreativesAdminHandler) review(w http.ResponseWriter, r *http.Request, status string) {
+	var req CreativeReviewRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxReviewBodySize)).Decode(&req); err != nil {
+		sendAdminError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON in request body")
+		return
+	}
+	hashes, err := normalizeCreativeHashes(req.Hashes)
+	if err != nil {
+		sendAdminError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	if err := h.reviewer.SetStatus(r.Context(), hashes, status); err != nil {
+		if !sendStorageError(w, err) {
+			logger.Log.Error().Err(err).Str("status", status).Int("creatives", len(hashes)).Msg("Failed to review creatives")
+			sendAdminError(w, http.StatusInternalServerError, "database_error", "Failed to review creatives")
+		}
+		return
+	}
+
+	logger.Log.Info().
+		Str("status", status).
+		Int("creatives", len(hashes)).
+		Msg("Creatives reviewed")
+	sendAdminJSON(w, http.StatusOK, CreativeReviewResponse{Status: status, Reviewed: len(hashes)})
+}
+
+// normalizeCreativeHashes lowercases and dedupes hashes, requiring 1 to
+// maxReviewHashes SHA-256 hex digests
+func normalizeCreativeHashes(hashes []string) ([]string, error) {
+	if len(hashes) == 0 {
+		return nil, errors.New("hashes is required")
+	}
+	if len(hashes) > maxReviewHashes {
+		return nil, fmt.Errorf("at most %d hashes may be reviewed at once", maxReviewHashes)
+	}
+	seen := make(map[string]struct{}, len(hashes))
+	normalized := make([]string, 0, len(hashes))
+	for _, hash := range hashes {
+		hash = strings.ToLower(strings.TrimSpace(hash))
+		if b, err := hex.DecodeString(hash); err != nil || len(b) != 32 {
+			return nil, fmt.Errorf("%q is not a creative hash (64 hex characters)", hash)
+		}
+		if _, dup := seen[hash]; dup {
+			continue
+		}
+		seen[hash] = struct{}{}
+		normalized = append(normalized, hash)
+	}
+	return normalized, nil
+}
@@ -0,0 +1,124 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+)
+
+type mockCreativeReviewer struct {
+	creatives []*storage.Creative
+	opts      storage.ListOptions
+	hashes    []string
+	status    string
+	err       error
+}
+
+func (m *mockCreativeReviewer) ListPage(_ context.Context, opts storage.ListOptions) ([]*storage.Creative, int, error) {
+	m.opts = opts
+	return m.creatives, len(m.creatives), m.err
+}
+
+func (m *mockCreativeReviewer) SetStatus(_ context.Context, hashes []string, status string) error {
+	m.hashes, m.status = hashes, status
+	return m.err
+}
+
+var (
+	testHashA = strings.Repeat("a", 64)
+	testHashB = strings.Repeat("b", 64)
+)
+
+func TestCreativesAdminHandler_List(t *testing.T) {
+	reviewer := &mockCreativeReviewer{creatives: []*storage.Creative{
+		{Hash: testHashA, Status: storage.CreativeStatusNew, BidderCode: "appnexus"},
+	}}
+	h := NewCreativesAdminHandler(reviewer)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/api/creatives?status=new&sort=-first_seen_at", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if reviewer.opts.Status != storage.CreativeStatusNew || reviewer.opts.Sort != "-first_seen_at" {
+		t.Errorf("unexpected list options: %+v", reviewer.opts)
+	}
+	var resp CreativeListResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if resp.Total != 1 || len(resp.Creatives) != 1 || resp.Creatives[0].Hash != testHashA {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+}
+
+func TestCreativesAdminHandler_Review(t *testing.T) {
+	tests := []struct {
+		action string
+		status string
+	}{
+		{"approve", storage.CreativeStatusApproved},
+		{"block", storage.CreativeStatusBlocked},
+		{"reset", storage.CreativeStatusNew},
+	}
+	for _, tt := range tests {
+		t.Run(tt.action, func(t *testing.T) {
+			reviewer := &mockCreativeReviewer{}
+			h := NewCreativesAdminHandler(reviewer)
+
+			body := `{"hashes": ["` + testHashA + `", "` + strings.ToUpper(testHashB) + `", "` + testHashA + `"]}`
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/api/creatives/"+tt.action, strings.NewReader(body)))
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+			}
+			if reviewer.status != tt.status || !reflect.DeepEqual(reviewer.hashes, []string{testHashA, testHashB}) {
+				t.Errorf("expected %s for deduped lowercase hashes, got %s %v", tt.status, reviewer.status, reviewer.hashes)
+			}
+			var resp CreativeReviewResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.Reviewed != 2 {
+				t.Errorf("unexpected response: %s", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestCreativesAdminHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		reviewer CreativeReviewer
+		method   string
+		url      string
+		body     string
+		want     int
+	}{
+		{"no database", nil, http.MethodGet, "/admin/api/creatives", "", http.StatusServiceUnavailable},
+		{"unknown route", &mockCreativeReviewer{}, http.MethodPost, "/admin/api/creatives/delete", `{}`, http.StatusNotFound},
+		{"wrong method", &mockCreativeReviewer{}, http.MethodGet, "/admin/api/creatives/approve", "", http.StatusMethodNotAllowed},
+		{"bad list options", &mockCreativeReviewer{}, http.MethodGet, "/admin/api/creatives?limit=0", "", http.StatusBadRequest},
+		{"invalid JSON", &mockCreativeReviewer{}, http.MethodPost, "/admin/api/creatives/approve", `{`, http.StatusBadRequest},
+		{"no hashes", &mockCreativeReviewer{}, http.MethodPost, "/admin/api/creatives/approve", `{"hashes": []}`, http.StatusBadRequest},
+		{"bad hash", &mockCreativeReviewer{}, http.MethodPost, "/admin/api/creatives/block", `{"hashes": ["abc"]}`, http.StatusBadRequest},
+		{
+			"store failure", &mockCreativeReviewer{err: errors.New("db down")}, http.MethodPost,
+			"/admin/api/creatives/block", `{"hashes": ["` + testHashA + `"]}`, http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewCreativesAdminHandler(tt.reviewer)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(tt.method, tt.url, strings.NewReader(tt.body)))
+			if w.Code != tt.want {
+				t.Errorf("expected %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+}
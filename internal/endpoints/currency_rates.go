@@ -0,0 +1,38 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/thenexusengine/tne_springwire/internal/currency"
+)
+
+// CurrencyRatesSource reports the exchange rates in use; implemented by
+// currency.Feed
+type CurrencyRatesSource interface {
+	Snapshot() currency.Snapshot
+}
+
+// CurrencyRatesHandler serves the exchange rates this replica converts
+// floors and bids with, their source and how old they are, for debugging
+// conversions
+type CurrencyRatesHandler struct {
+	source CurrencyRatesSource
+}
+
+// NewCurrencyRatesHandler creates a new currency rates handler
+func NewCurrencyRatesHandler(source CurrencyRatesSource) *CurrencyRatesHandler {
+	return &CurrencyRatesHandler{source: source}
+}
+
+// ServeHTTP handles GET /currency/rates
+func (h *CurrencyRatesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(h.source.Snapshot())
+}
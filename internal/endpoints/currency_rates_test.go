@@ -0,0 +1,39 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/thenexusengine/tne_springwire/internal/currency"
+)
+
+type mockRatesSource struct{}
+
+func (mockRatesSource) Snapshot() currency.Snapshot {
+	return currency.Snapshot{Base: "USD", Source: currency.SourceECB, Stale: true, Rates: map[string]float64{"USD": 1, "EUR": 1.08}}
+}
+
+func TestCurrencyRatesHandler(t *testing.T) {
+	h := NewCurrencyRatesHandler(mockRatesSource{})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/currency/rates", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	var snap currency.Snapshot
+	if err := json.Unmarshal(w.Body.Bytes(), &snap); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if snap.Base != "USD" || snap.Source != currency.SourceECB || !snap.Stale || snap.Rates["EUR"] != 1.08 {
+		t.Errorf("unexpected snapshot %+v", snap)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/currency/rates", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405, got %d", w.Code)
+	}
+}
@@ -0,0 +1,75 @@
+package endpoints
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/deals"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+// DealReporter reports deal delivery against booking; implemented by
+// deals.Job
+type DealReporter interface {
+	Report(ctx context.Context, day time.Time) ([]deals.DealStatus, error)
+}
+
+// DealReportResponse is the JSON form of /admin/deals
+type DealReportResponse struct {
+	Date  string             `json:"date"`
+	Deals []deals.DealStatus `json:"deals"`
+}
+
+// DealsAdminHandler serves programmatic guaranteed deal pacing
+type DealsAdminHandler struct {
+	reporter DealReporter
+	now      func() time.Time
+}
+
+// NewDealsAdminHandler creates a deals handler; reporter may be nil when no
+// database is configured
+func NewDealsAdminHandler(reporter DealReporter) *DealsAdminHandler {
+	return &DealsAdminHandler{reporter: reporter, now: time.Now}
+}
+
+// ServeHTTP handles deal pacing requests
+// Routes:
+//
+//	GET /admin/deals?date=YYYY-MM-DD
+//
+// date is a UTC day and defaults to today, which is reported live.
+func (h *DealsAdminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		sendAdminError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+		return
+	}
+	if h.reporter == nil {
+		sendAdminError(w, http.StatusServiceUnavailable, "database_unavailable", "Deal pacing requires a database connection")
+		return
+	}
+
+	day := h.now().UTC().Truncate(24 * time.Hour)
+	if v := r.URL.Query().Get("date"); v != "" {
+		t, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			sendAdminError(w, http.StatusBadRequest, "invalid_date", "date must be a YYYY-MM-DD date")
+			return
+		}
+		day = t
+	}
+
+	statuses, err := h.reporter.Report(r.Context(), day)
+	if err != nil {
+		logger.Log.Error().Err(err).Msg("Failed to report deal delivery")
+		sendAdminError(w, http.StatusInternalServerError, "query_failed", "Failed to query deal delivery")
+		return
+	}
+	if statuses == nil {
+		statuses = []deals.DealStatus{}
+	}
+	sendAdminJSON(w, http.StatusOK, DealReportResponse{
+		Date:  day.Format(time.DateOnly),
+		Deals: statuses,
+	})
+}
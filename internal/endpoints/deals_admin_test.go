@@ -0,0 +1,91 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/deals"
+)
+
+type mockDealReporter struct {
+	statuses []deals.DealStatus
+	err      error
+	day      time.Time
+}
+
+func (m *mockDealReporter) Report(_ context.Context, day time.Time) ([]deals.DealStatus, error) {
+	m.day = day
+	return m.statuses, m.err
+}
+
+func TestDealsAdminHandler_Report(t *testing.T) {
+	reporter := &mockDealReporter{statuses: []deals.DealStatus{
+		{DealID: "pg-1", Booked: 1000, Delivered: 400, Expected: 500, Pace: 0.8, Status: deals.StatusBehind},
+	}}
+	h := NewDealsAdminHandler(reporter)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/deals?date=2026-03-01", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if !reporter.day.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected day: %v", reporter.day)
+	}
+
+	var resp DealReportResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if resp.Date != "2026-03-01" || len(resp.Deals) != 1 || resp.Deals[0].Status != deals.StatusBehind {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+}
+
+func TestDealsAdminHandler_DefaultsToToday(t *testing.T) {
+	reporter := &mockDealReporter{}
+	h := NewDealsAdminHandler(reporter)
+	h.now = func() time.Time { return time.Date(2026, 3, 1, 15, 30, 0, 0, time.UTC) }
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/deals", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if !reporter.day.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected today, got %v", reporter.day)
+	}
+	var resp DealReportResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.Deals == nil {
+		t.Errorf("expected an empty deals array, got %s", w.Body.String())
+	}
+}
+
+func TestDealsAdminHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		reporter DealReporter
+		method   string
+		url      string
+		want     int
+	}{
+		{"no database", nil, http.MethodGet, "/admin/deals", http.StatusServiceUnavailable},
+		{"wrong method", &mockDealReporter{}, http.MethodPost, "/admin/deals", http.StatusMethodNotAllowed},
+		{"bad date", &mockDealReporter{}, http.MethodGet, "/admin/deals?date=yesterday", http.StatusBadRequest},
+		{"query failure", &mockDealReporter{err: errors.New("db down")}, http.MethodGet, "/admin/deals", http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			NewDealsAdminHandler(tt.reporter).ServeHTTP(w, httptest.NewRequest(tt.method, tt.url, nil))
+			if w.Code != tt.want {
+				t.Errorf("expected %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+	"github.com/thenexusengine/tne_springwire/pkg/kv"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+// maxIVTScoringBodySize bounds IVT scoring update request bodies
+const maxIVTScoringBodySize = 16 * 1024
+
+// IVTScoringKey is the KV key holding the scoring set through /admin/ivt
+const IVTScoringKey = "tne_catalyst:ivt_scoring"
+
+// IVTInvalidationScope is the cache invalidation scope that tells replicas
+// to reload the stored IVT scoring
+const IVTInvalidationScope = "ivt"
+
+// ivtStoreTimeout bounds KV reads and writes of the stored scoring
+const ivtStoreTimeout = 2 * time.Second
+
+// IVTConfigStore reads and replaces the live IVT configuration.
+// Implemented by *middleware.PublisherAuth.
+type IVTConfigStore interface {
+	GetIVTConfig() *middleware.IVTConfig
+	SetIVTConfig(config *middleware.IVTConfig)
+}
+
+// IVTScoring is the JSON form of the IVT score threshold, signal weights
+// and per-channel overrides, e.g.
+// {"score_threshold": 70, "channels": {"ctv": {"score_threshold": 85}}}
+type IVTScoring struct {
+	MonitoringEnabled bool                                   `json:"monitoring_enabled"`
+	BlockingEnabled   bool                                   `json:"blocking_enabled"`
+	ScoreThreshold    int                                    `json:"score_threshold"`
+	SignalWeights     map[string]int                         `json:"signal_weights,omitempty"`
+	Channels          map[string]middleware.IVTChannelConfig `json:"channels,omitempty"`
+}
+
+// IVTAdminHandler tunes IVT scoring per traffic channel at runtime. With a
+// KV store, changes are saved there and broadcast as an "ivt" invalidation
+// so every replica reloads them, and they survive restarts; otherwise they
+// live in this replica's memory.
+type IVTAdminHandler struct {
+	store       IVTConfigStore
+	kv          kv.Store
+	invalidator Invalidator
+}
+
+// NewIVTAdminHandler creates an IVT admin handler
+func NewIVTAdminHandler() *IVTAdminHandler {
+	return &IVTAdminHandler{}
+}
+
+// SetConfigStore sets the IVT configuration the handler manages
+func (h *IVTAdminHandler) SetConfigStore(store IVTConfigStore) {
+	h.store = store
+}
+
+// SetKVStore saves scoring changes to the shared KV store
+func (h *IVTAdminHandler) SetKVStore(store kv.Store) {
+	h.kv = store
+}
+
+// SetInvalidator broadcasts scoring changes to other replicas
+func (h *IVTAdminHandler) SetInvalidator(invalidator Invalidator) {
+	h.invalidator = invalidator
+}
+
+// InvalidateCache reloads the stored scoring into the live configuration,
+// returning 1 when a stored scoring was applied. It implements
+// CacheInvalidator for the "ivt" scope; ids are ignored.
+func (h *IVTAdminHandler) InvalidateCache(ids ...string) int {
+	if h.kv == nil || h.store == nil || h.store.GetIVTConfig() == nil {
+		return 0
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), ivtStoreTimeout)
+	defer cancel()
+	data, err := h.kv.Get(ctx, IVTScoringKey)
+	if err != nil {
+		logger.Log.Warn().Err(err).Msg("Failed to load stored IVT scoring")
+		return 0
+	}
+	if data == "" {
+		return 0
+	}
+	var scoring IVTScoring
+	if err := json.Unmarshal([]byte(data), &scoring); err != nil {
+		logger.Log.Warn().Err(err).Msg("Ignoring malformed stored IVT scoring")
+		return 0
+	}
+
+	config := *h.store.GetIVTConfig()
+	config.ScoreThreshold = scoring.ScoreThreshold
+	config.SignalWeights = scoring.SignalWeights
+	config.Channels = scoring.Channels
+	if err := config.ValidateScoring(); err != nil {
+		logger.Log.Warn().Err(err).Msg("Ignoring invalid stored IVT scoring")
+		return 0
+	}
+	h.store.SetIVTConfig(&config)
+	return 1
+}
+
+// ServeHTTP handles IVT scoring requests
+// Routes:
+//
+//	GET /admin/ivt - current scoring
+//	PUT /admin/ivt - replace the score threshold, signal weights and channel
+//	                 overrides; monitoring and blocking flags are read-only
+func (h *IVTAdminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.store == nil || h.store.GetIVTConfig() == nil {
+		sendAdminError(w, http.StatusServiceUnavailable, "ivt_unavailable", "IVT detection is not configured")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		sendAdminJSON(w, http.StatusOK, ivtScoring(h.store.GetIVTConfig()))
+	case http.MethodPut:
+		h.update(w, r)
+	default:
+		sendAdminError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+	}
+}
+
+func (h *IVTAdminHandler) update(w http.ResponseWriter, r *http.Request) {
+	var req IVTScoring
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxIVTScoringBodySize)).Decode(&req); err != nil {
+		sendAdminError(w, http.StatusBadRequest, "invalid_json", "Invalid request body: "+err.Error())
+		return
+	}
+
+	// Copy so in-flight requests keep scoring against a consistent config
+	config := *h.store.GetIVTConfig()
+	config.ScoreThreshold = req.ScoreThreshold
+	config.SignalWeights = req.SignalWeights
+	config.Channels = req.Channels
+	if err := config.ValidateScoring(); err != nil {
+		sendAdminError(w, http.StatusBadRequest, "invalid_scoring", err.Error())
+		return
+	}
+	if h.kv != nil {
+		data, err := json.Marshal(IVTScoring{ScoreThreshold: config.ScoreThreshold, SignalWeights: config.SignalWeights, Channels: config.Channels})
+		if err == nil {
+			err = h.kv.Set(r.Context(), IVTScoringKey, string(data), 0)
+		}
+		if err != nil {
+			logger.Log.Error().Err(err).Msg("Failed to save IVT scoring")
+			sendAdminError(w, http.StatusInternalServerError, "storage_error", "Failed to save IVT scoring")
+			return
+		}
+	}
+	h.store.SetIVTConfig(&config)
+	if h.kv != nil && h.invalidator != nil {
+		if _, err := h.invalidator.Invalidate(r.Context(), CacheInvalidateRequest{Scope: IVTInvalidationScope, IDs: []string{IVTScoringKey}}); err != nil {
+			logger.Log.Warn().Err(err).Msg("IVT scoring not broadcast to other replicas")
+		}
+	}
+
+	logger.Log.Info().
+		Int("score_threshold", config.ScoreThreshold).
+		Int("channel_overrides", len(config.Channels)).
+		Msg("IVT scoring updated")
+	sendAdminJSON(w, http.StatusOK, ivtScoring(&config))
+}
+
+func ivtScoring(config *middleware.IVTConfig) IVTScoring {
+	threshold := config.ScoreThreshold
+	if threshold <= 0 {
+		threshold = middleware.DefaultIVTScoreThreshold
+	}
+	return IVTScoring{
+		MonitoringEnabled: config.MonitoringEnabled,
+		BlockingEnabled:   config.BlockingEnabled,
+		ScoreThreshold:    threshold,
+		SignalWeights:     config.SignalWeights,
+		Channels:          config.Channels,
+	}
+}
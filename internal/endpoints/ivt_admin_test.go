@@ -0,0 +1,121 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+	"github.com/thenexusengine/tne_springwire/pkg/kv"
+)
+
+type mockIVTConfigStore struct {
+	config *middleware.IVTConfig
+}
+
+func (m *mockIVTConfigStore) GetIVTConfig() *middleware.IVTConfig       { return m.config }
+func (m *mockIVTConfigStore) SetIVTConfig(config *middleware.IVTConfig) { m.config = config }
+
+func TestIVTAdminHandler_Update(t *testing.T) {
+	store := &mockIVTConfigStore{config: &middleware.IVTConfig{MonitoringEnabled: true, ScoreThreshold: 70}}
+	h := NewIVTAdminHandler()
+	h.SetConfigStore(store)
+
+	body := `{"score_threshold": 60, "signal_weights": {"invalid_referer": 20}, "channels": {"ctv": {"score_threshold": 85}}}`
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/admin/ivt", strings.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if store.config.ScoreThreshold != 60 || store.config.SignalWeights["invalid_referer"] != 20 || store.config.Channels["ctv"].ScoreThreshold != 85 {
+		t.Errorf("expected the scoring to be applied, got %+v", store.config)
+	}
+	if !store.config.MonitoringEnabled {
+		t.Error("expected monitoring to be left enabled")
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/ivt", nil))
+	var scoring IVTScoring
+	if err := json.Unmarshal(w.Body.Bytes(), &scoring); err != nil || scoring.ScoreThreshold != 60 || scoring.Channels["ctv"].ScoreThreshold != 85 {
+		t.Errorf("expected the updated scoring, got %s", w.Body.String())
+	}
+}
+
+func TestIVTAdminHandler_Errors(t *testing.T) {
+	w := httptest.NewRecorder()
+	NewIVTAdminHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/ivt", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 without IVT detection, got %d", w.Code)
+	}
+
+	store := &mockIVTConfigStore{config: &middleware.IVTConfig{ScoreThreshold: 70}}
+	h := NewIVTAdminHandler()
+	h.SetConfigStore(store)
+
+	tests := []struct {
+		method, body string
+		want         int
+	}{
+		{http.MethodPut, `{"score_threshold": 70, "channels": {"web": {"score_threshold": 50}}}`, http.StatusBadRequest},
+		{http.MethodPut, `{"score_threshold": 0}`, http.StatusBadRequest},
+		{http.MethodPut, `not json`, http.StatusBadRequest},
+		{http.MethodDelete, ``, http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(tt.method, "/admin/ivt", strings.NewReader(tt.body)))
+		if w.Code != tt.want {
+			t.Errorf("%s %s: expected %d, got %d", tt.method, tt.body, tt.want, w.Code)
+		}
+	}
+	if store.config.ScoreThreshold != 70 || store.config.Channels != nil {
+		t.Errorf("expected rejected updates to leave the config alone, got %+v", store.config)
+	}
+}
+
+func TestIVTAdminHandler_SharedScoring(t *testing.T) {
+	shared := kv.NewMemory()
+	store := &mockIVTConfigStore{config: &middleware.IVTConfig{MonitoringEnabled: true, ScoreThreshold: 70}}
+	h := NewIVTAdminHandler()
+	h.SetConfigStore(store)
+	h.SetKVStore(shared)
+	cacheAdmin := NewCacheAdminHandler()
+	cacheAdmin.RegisterInvalidator(IVTInvalidationScope, h)
+	pub := &mockInvalidationPublisher{}
+	cacheAdmin.SetPublisher(pub)
+	h.SetInvalidator(cacheAdmin)
+
+	body := `{"score_threshold": 60, "channels": {"ctv": {"score_threshold": 85}}}`
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/admin/ivt", strings.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(pub.messages) != 1 || !strings.Contains(pub.messages[0], `"scope":"ivt"`) {
+		t.Errorf("expected the change broadcast, got %v", pub.messages)
+	}
+
+	// Another replica, or this one after a restart, reloads the saved scoring
+	other := &mockIVTConfigStore{config: &middleware.IVTConfig{BlockingEnabled: true, ScoreThreshold: 70}}
+	replica := NewIVTAdminHandler()
+	replica.SetConfigStore(other)
+	replica.SetKVStore(shared)
+	if n := replica.InvalidateCache(IVTScoringKey); n != 1 {
+		t.Fatalf("expected the saved scoring applied, got %d", n)
+	}
+	if other.config.ScoreThreshold != 60 || other.config.Channels["ctv"].ScoreThreshold != 85 || !other.config.BlockingEnabled {
+		t.Errorf("expected the saved scoring over the replica's flags, got %+v", other.config)
+	}
+
+	// Nothing saved leaves the configuration alone
+	fresh := &mockIVTConfigStore{config: &middleware.IVTConfig{ScoreThreshold: 70}}
+	h = NewIVTAdminHandler()
+	h.SetConfigStore(fresh)
+	h.SetKVStore(kv.NewMemory())
+	if n := h.InvalidateCache(); n != 0 || fresh.config.ScoreThreshold != 70 {
+		t.Errorf("expected no change without a saved scoring, got %d %+v", n, fresh.config)
+	}
+}
@@ -0,0 +1,59 @@
+package endpoints
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+)
+
+// List response headers
+const (
+	totalCountHeader = "X-Total-Count"
+	nextCursorHeader = "X-Next-Cursor"
+)
+
+// parseListOptions reads ?limit, ?offset, ?cursor, ?status and ?sort from an
+// admin list request
+func parseListOptions(r *http.Request) (storage.ListOptions, error) {
+	q := r.URL.Query()
+	opts := storage.ListOptions{
+		Cursor: q.Get("cursor"),
+		Status: q.Get("status"),
+		Sort:   q.Get("sort"),
+	}
+
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 {
+			return opts, fmt.Errorf("limit must be a positive integer")
+		}
+		opts.Limit = limit
+	}
+	if v := q.Get("offset"); v != "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			return opts, fmt.Errorf("offset must be a non-negative integer")
+		}
+		opts.Offset = offset
+	}
+	return opts, nil
+}
+
+// nextCursor returns the cursor for the page after one ending at lastKey, or
+// "" when the page wasn't full or results aren't in key order
+func nextCursor(opts storage.ListOptions, key string, count int, lastKey string) string {
+	if count < opts.PageLimit() || (opts.Sort != "" && opts.Sort != key && opts.Sort != "-"+key) {
+		return ""
+	}
+	return lastKey
+}
+
+// setListHeaders sets the total count and next-page cursor headers
+func setListHeaders(w http.ResponseWriter, total int, cursor string) {
+	w.Header().Set(totalCountHeader, strconv.Itoa(total))
+	if cursor != "" {
+		w.Header().Set(nextCursorHeader, cursor)
+	}
+}
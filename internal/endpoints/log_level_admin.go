@@ -0,0 +1,151 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+// defaultLogOverrideTTL applies when a PUT /admin/logging omits ttl
+const defaultLogOverrideTTL = 10 * time.Minute
+
+// maxLogOverrideBodySize bounds log level override request bodies
+const maxLogOverrideBodySize = 4 * 1024
+
+// LogLevelRequest sets or clears the log level for one publisher or bidder,
+// e.g. {"publisher": "pub-1", "level": "debug", "ttl": "10m"}
+type LogLevelRequest struct {
+	Publisher string `json:"publisher,omitempty"`
+	Bidder    string `json:"bidder,omitempty"`
+	Level     string `json:"level,omitempty"`
+	TTL       string `json:"ttl,omitempty"`
+}
+
+// LogLevelOverride is the JSON form of an active override
+type LogLevelOverride struct {
+	Scope     string    `json:"scope"`
+	ID        string    `json:"id"`
+	Level     string    `json:"level"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// LogLevelResponse lists the active overrides
+type LogLevelResponse struct {
+	GlobalLevel string             `json:"global_level"`
+	Overrides   []LogLevelOverride `json:"overrides"`
+}
+
+// LogLevelHandler changes log verbosity for a single publisher or bidder at
+// runtime, so an incident can be debugged without debug logging the whole
+// fleet. Overrides live in this replica's memory and expire on their own.
+type LogLevelHandler struct{}
+
+// NewLogLevelHandler creates a new log level admin handler
+func NewLogLevelHandler() *LogLevelHandler {
+	return &LogLevelHandler{}
+}
+
+// ServeHTTP handles log level overrides
+// Routes:
+//
+//	GET    /admin/logging - list active overrides
+//	PUT    /admin/logging - set an override; ttl defaults to 10m, max 24h
+//	DELETE /admin/logging - clear an override
+func (h *LogLevelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.list(w)
+	case http.MethodPut:
+		h.set(w, r)
+	case http.MethodDelete:
+		h.clear(w, r)
+	default:
+		sendAdminError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+	}
+}
+
+func (h *LogLevelHandler) list(w http.ResponseWriter) {
+	active := logger.Overrides()
+	resp := LogLevelResponse{
+		GlobalLevel: logger.Log.GetLevel().String(),
+		Overrides:   make([]LogLevelOverride, 0, len(active)),
+	}
+	for _, o := range active {
+		resp.Overrides = append(resp.Overrides, LogLevelOverride{
+			Scope:     o.Scope,
+			ID:        o.ID,
+			Level:     o.Level.String(),
+			ExpiresAt: o.ExpiresAt,
+		})
+	}
+	sendAdminJSON(w, http.StatusOK, resp)
+}
+
+func (h *LogLevelHandler) set(w http.ResponseWriter, r *http.Request) {
+	req, scope, id, ok := decodeLogLevelRequest(w, r)
+	if !ok {
+		return
+	}
+
+	level, err := zerolog.ParseLevel(req.Level)
+	if err != nil || req.Level == "" || level == zerolog.NoLevel || level == zerolog.Disabled {
+		sendAdminError(w, http.StatusBadRequest, "invalid_level", "level must be one of trace, debug, info, warn, error")
+		return
+	}
+
+	ttl := defaultLogOverrideTTL
+	if req.TTL != "" {
+		ttl, err = time.ParseDuration(req.TTL)
+		if err != nil || ttl <= 0 || ttl > logger.MaxOverrideTTL {
+			sendAdminError(w, http.StatusBadRequest, "invalid_ttl", "ttl must be a duration between 1s and "+logger.MaxOverrideTTL.String())
+			return
+		}
+	}
+
+	o, err := logger.SetOverride(scope, id, level, ttl)
+	if err != nil {
+		sendAdminError(w, http.StatusBadRequest, "invalid_override", err.Error())
+		return
+	}
+	sendAdminJSON(w, http.StatusOK, LogLevelOverride{
+		Scope:     o.Scope,
+		ID:        o.ID,
+		Level:     o.Level.String(),
+		ExpiresAt: o.ExpiresAt,
+	})
+}
+
+func (h *LogLevelHandler) clear(w http.ResponseWriter, r *http.Request) {
+	_, scope, id, ok := decodeLogLevelRequest(w, r)
+	if !ok {
+		return
+	}
+	if !logger.ClearOverride(scope, id) {
+		sendAdminError(w, http.StatusNotFound, "not_found", "No active override for "+scope+" "+id)
+		return
+	}
+	logger.Log.Info().Str("scope", scope).Str("id", id).Msg("Log level override cleared")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodeLogLevelRequest reads the body and resolves exactly one of
+// publisher or bidder, writing an error response when it can't
+func decodeLogLevelRequest(w http.ResponseWriter, r *http.Request) (LogLevelRequest, string, string, bool) {
+	var req LogLevelRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxLogOverrideBodySize)).Decode(&req); err != nil {
+		sendAdminError(w, http.StatusBadRequest, "invalid_json", "Invalid request body: "+err.Error())
+		return req, "", "", false
+	}
+	switch {
+	case req.Publisher != "" && req.Bidder == "":
+		return req, logger.ScopePublisher, req.Publisher, true
+	case req.Bidder != "" && req.Publisher == "":
+		return req, logger.ScopeBidder, req.Bidder, true
+	default:
+		sendAdminError(w, http.StatusBadRequest, "invalid_target", "Exactly one of publisher or bidder is required")
+		return req, "", "", false
+	}
+}
@@ -0,0 +1,100 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+func serveLogLevel(h *LogLevelHandler, method, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(method, "/admin/logging", strings.NewReader(body)))
+	return w
+}
+
+func TestLogLevelHandler_SetListClear(t *testing.T) {
+	h := NewLogLevelHandler()
+	t.Cleanup(func() { logger.ClearOverride(logger.ScopePublisher, "pub-log-1") })
+
+	w := serveLogLevel(h, http.MethodPut, `{"publisher":"pub-log-1","level":"debug","ttl":"10m"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var set LogLevelOverride
+	if err := json.Unmarshal(w.Body.Bytes(), &set); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if set.Scope != "publisher" || set.ID != "pub-log-1" || set.Level != "debug" {
+		t.Errorf("unexpected override: %+v", set)
+	}
+	if until := time.Until(set.ExpiresAt); until <= 9*time.Minute || until > 10*time.Minute {
+		t.Errorf("expected ~10m expiry, got %v", until)
+	}
+
+	w = serveLogLevel(h, http.MethodGet, "")
+	var list LogLevelResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	found := false
+	for _, o := range list.Overrides {
+		found = found || (o.Scope == "publisher" && o.ID == "pub-log-1")
+	}
+	if !found {
+		t.Errorf("expected override listed, got %+v", list)
+	}
+
+	if w = serveLogLevel(h, http.MethodDelete, `{"publisher":"pub-log-1"}`); w.Code != http.StatusNoContent {
+		t.Errorf("expected 204, got %d", w.Code)
+	}
+	if w = serveLogLevel(h, http.MethodDelete, `{"publisher":"pub-log-1"}`); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for cleared override, got %d", w.Code)
+	}
+}
+
+func TestLogLevelHandler_DefaultTTL(t *testing.T) {
+	h := NewLogLevelHandler()
+	t.Cleanup(func() { logger.ClearOverride(logger.ScopeBidder, "bidder-log-1") })
+
+	w := serveLogLevel(h, http.MethodPut, `{"bidder":"bidder-log-1","level":"trace"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var set LogLevelOverride
+	json.Unmarshal(w.Body.Bytes(), &set)
+	if set.Scope != "bidder" || time.Until(set.ExpiresAt) > defaultLogOverrideTTL {
+		t.Errorf("unexpected override: %+v", set)
+	}
+}
+
+func TestLogLevelHandler_Invalid(t *testing.T) {
+	h := NewLogLevelHandler()
+
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		want   int
+	}{
+		{"no target", http.MethodPut, `{"level":"debug"}`, http.StatusBadRequest},
+		{"both targets", http.MethodPut, `{"publisher":"p","bidder":"b","level":"debug"}`, http.StatusBadRequest},
+		{"bad level", http.MethodPut, `{"publisher":"p","level":"loud"}`, http.StatusBadRequest},
+		{"missing level", http.MethodPut, `{"publisher":"p"}`, http.StatusBadRequest},
+		{"bad ttl", http.MethodPut, `{"publisher":"p","level":"debug","ttl":"forever"}`, http.StatusBadRequest},
+		{"ttl too long", http.MethodPut, `{"publisher":"p","level":"debug","ttl":"48h"}`, http.StatusBadRequest},
+		{"bad json", http.MethodPut, `{`, http.StatusBadRequest},
+		{"wrong method", http.MethodPost, `{}`, http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := serveLogLevel(h, tt.method, tt.body); w.Code != tt.want {
+				t.Errorf("expected %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+}
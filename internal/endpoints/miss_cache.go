@@ -0,0 +1,58 @@
+package endpoints
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// lookupMissTTL is how long a publisher lookup that found nothing is
+	// remembered, so repeated requests for unknown IDs don't each hit the
+	// database
+	lookupMissTTL = 5 * time.Second
+	// maxLookupMisses bounds the remembered misses; unknown IDs are client
+	// supplied
+	maxLookupMisses = 10000
+)
+
+// missCache remembers lookups that found nothing for a short TTL
+type missCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]time.Time // key -> expiry
+}
+
+func newMissCache(ttl time.Duration) *missCache {
+	return &missCache{ttl: ttl, entries: make(map[string]time.Time)}
+}
+
+// has reports whether key missed within the TTL
+func (c *missCache) has(key string, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	expires, ok := c.entries[key]
+	if ok && !now.Before(expires) {
+		delete(c.entries, key)
+		return false
+	}
+	return ok
+}
+
+// add remembers a miss. When full, expired misses are dropped first and then,
+// if none had expired, all of them.
+func (c *missCache) add(key string, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= maxLookupMisses {
+		for k, expires := range c.entries {
+			if !now.Before(expires) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= maxLookupMisses {
+			c.entries = make(map[string]time.Time)
+		}
+	}
+	c.entries[key] = now.Add(c.ttl)
+}
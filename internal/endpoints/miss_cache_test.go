@@ -0,0 +1,27 @@
+package endpoints
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestMissCache(t *testing.T) {
+	c := newMissCache(time.Second)
+	now := time.Now()
+
+	c.add("pub-1", now)
+	if !c.has("pub-1", now.Add(500*time.Millisecond)) || c.has("pub-2", now) {
+		t.Error("expected only the added key to be remembered")
+	}
+	if c.has("pub-1", now.Add(time.Second)) {
+		t.Error("expected the miss to expire after the TTL")
+	}
+
+	for i := 0; i < maxLookupMisses+10; i++ {
+		c.add(strconv.Itoa(i), now)
+	}
+	if len(c.entries) > maxLookupMisses {
+		t.Errorf("expected at most %d misses, got %d", maxLookupMisses, len(c.entries))
+	}
+}
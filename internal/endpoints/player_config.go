@@ -0,0 +1,202 @@
+package endpoints
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+	"github.com/thenexusengine/tne_springwire/pkg/deadline"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+// PlayerConfigSignatureHeader carries "<key id>.<base64url Ed25519 signature>"
+// over the exact response body, so the SDK can verify settings with an
+// embedded public key before applying them
+const PlayerConfigSignatureHeader = "X-Config-Signature"
+
+const (
+	// playerConfigTTL is how long a signed config is valid; the SDK rejects
+	// expired configs so old responses can't be replayed
+	playerConfigTTL = 10 * time.Minute
+	// playerConfigCacheControl lets browsers and CDNs reuse a response
+	playerConfigCacheControl = "public, max-age=60"
+	// playerConfigCacheTTL bounds how stale publisher overrides can be
+	playerConfigCacheTTL = 30 * time.Second
+)
+
+// PublisherLookup fetches publishers by ID; implemented by storage.PublisherStore
+type PublisherLookup interface {
+	GetByPublisherID(ctx context.Context, publisherID string) (interface{}, error)
+}
+
+// PlayerConfig is the signed settings document returned by /video/config
+type PlayerConfig struct {
+	PublisherID          string `json:"publisher_id"`
+	TrackingBaseURL      string `json:"tracking_base_url"`
+	EventBatchIntervalMs int    `json:"event_batch_interval_ms"`
+	PauseAdsEnabled      bool   `json:"pause_ads_enabled"`
+	VASTVersion          string `json:"vast_version"`
+	IssuedAt             int64  `json:"issued_at"`  // Unix seconds
+	ExpiresAt            int64  `json:"expires_at"` // Unix seconds
+}
+
+type cachedPlayerConfig struct {
+	overrides *storage.PlayerConfig
+	expires   time.Time
+}
+
+// PlayerConfigHandler serves per-publisher player settings from
+// GET /video/config?pub=<publisher id>: server defaults overlaid with the
+// publisher's player_config, signed with the server's Ed25519 key
+type PlayerConfigHandler struct {
+	defaults   storage.PlayerConfig
+	publishers PublisherLookup
+	key        ed25519.PrivateKey
+	keyID      string
+	now        func() time.Time
+
+	mu     sync.Mutex
+	cache  map[string]cachedPlayerConfig
+	misses *missCache
+}
+
+// NewPlayerConfigHandler creates a player config handler. defaults must set
+// every field; publishers may be nil, in which case every publisher gets the
+// defaults.
+func NewPlayerConfigHandler(defaults storage.PlayerConfig, publishers PublisherLookup, key ed25519.PrivateKey, keyID string) *PlayerConfigHandler {
+	return &PlayerConfigHandler{
+		defaults:   defaults,
+		publishers: publishers,
+		key:        key,
+		keyID:      keyID,
+		now:        time.Now,
+		cache:      make(map[string]cachedPlayerConfig),
+		misses:     newMissCache(lookupMissTTL),
+	}
+}
+
+// ServeHTTP handles GET /video/config
+func (h *PlayerConfigHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Players load config cross-origin, like VAST
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Accept")
+	w.Header().Set("Access-Control-Expose-Headers", PlayerConfigSignatureHeader)
+
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	publisherID := r.URL.Query().Get("pub")
+	if publisherID == "" || len(publisherID) > 255 {
+		writeError(w, "pub is required", http.StatusBadRequest)
+		return
+	}
+
+	overrides, found := h.lookup(r.Context(), publisherID)
+	if !found {
+		writeError(w, "Unknown publisher", http.StatusNotFound)
+		return
+	}
+
+	cfg := h.resolve(publisherID, overrides)
+	body, err := json.Marshal(cfg)
+	if err != nil {
+		logger.Log.Error().Err(err).Msg("Failed to encode player config")
+		writeError(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", playerConfigCacheControl)
+	w.Header().Set(PlayerConfigSignatureHeader, h.sign(body))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+// lookup returns the publisher's overrides and whether the publisher exists.
+// Database errors fall back to the defaults so an outage doesn't break
+// players. Unknown publishers are remembered for lookupMissTTL.
+func (h *PlayerConfigHandler) lookup(ctx context.Context, publisherID string) (*storage.PlayerConfig, bool) {
+	if h.publishers == nil {
+		return nil, true
+	}
+
+	now := h.now()
+	h.mu.Lock()
+	if cached, ok := h.cache[publisherID]; ok && now.Before(cached.expires) {
+		h.mu.Unlock()
+		return cached.overrides, true
+	}
+	h.mu.Unlock()
+	if h.misses.has(publisherID, now) {
+		return nil, false
+	}
+
+	dbCtx, cancel := deadline.WithCap(ctx, deadline.DependencyPostgres)
+	defer cancel()
+	result, err := h.publishers.GetByPublisherID(dbCtx, publisherID)
+	if errors.Is(err, storage.ErrNotFound) {
+		h.misses.add(publisherID, now)
+		return nil, false
+	}
+	if err = deadline.Observe(dbCtx, deadline.DependencyPostgres, err); err != nil {
+		logger.Log.Warn().Err(err).Str("publisher_id", publisherID).Msg("Player config lookup failed, serving defaults")
+		return nil, true
+	}
+	pub, _ := result.(*storage.Publisher)
+	if pub == nil {
+		h.misses.add(publisherID, now)
+		return nil, false
+	}
+
+	h.mu.Lock()
+	h.cache[publisherID] = cachedPlayerConfig{overrides: pub.PlayerConfig, expires: now.Add(playerConfigCacheTTL)}
+	h.mu.Unlock()
+	return pub.PlayerConfig, true
+}
+
+// resolve overlays the publisher's overrides on the defaults
+func (h *PlayerConfigHandler) resolve(publisherID string, overrides *storage.PlayerConfig) PlayerConfig {
+	now := h.now()
+	cfg := PlayerConfig{
+		PublisherID:          publisherID,
+		TrackingBaseURL:      h.defaults.TrackingBaseURL,
+		EventBatchIntervalMs: h.defaults.EventBatchIntervalMs,
+		PauseAdsEnabled:      h.defaults.PauseAdsEnabled != nil && *h.defaults.PauseAdsEnabled,
+		VASTVersion:          h.defaults.VASTVersion,
+		IssuedAt:             now.Unix(),
+		ExpiresAt:            now.Add(playerConfigTTL).Unix(),
+	}
+	if overrides == nil {
+		return cfg
+	}
+	if overrides.TrackingBaseURL != "" {
+		cfg.TrackingBaseURL = overrides.TrackingBaseURL
+	}
+	if overrides.EventBatchIntervalMs > 0 {
+		cfg.EventBatchIntervalMs = overrides.EventBatchIntervalMs
+	}
+	if overrides.PauseAdsEnabled != nil {
+		cfg.PauseAdsEnabled = *overrides.PauseAdsEnabled
+	}
+	if overrides.VASTVersion != "" {
+		cfg.VASTVersion = overrides.VASTVersion
+	}
+	return cfg
+}
+
+func (h *PlayerConfigHandler) sign(body []byte) string {
+	return h.keyID + "." + base64.RawURLEncoding.EncodeToString(ed25519.Sign(h.key, body))
+}
@@ -0,0 +1,139 @@
+package endpoints
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+)
+
+type mockPublisherLookup struct {
+	publishers map[string]*storage.Publisher
+	err        error
+	calls      int
+}
+
+func (m *mockPublisherLookup) GetByPublisherID(_ context.Context, publisherID string) (interface{}, error) {
+	m.calls++
+	if m.err != nil {
+		return nil, m.err
+	}
+	pub, ok := m.publishers[publisherID]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	return pub, nil
+}
+
+func newTestPlayerConfigHandler(lookup PublisherLookup) (*PlayerConfigHandler, ed25519.PublicKey) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	pauseAds := false
+	defaults := storage.PlayerConfig{
+		TrackingBaseURL:      "https://ads.example.com",
+		EventBatchIntervalMs: 5000,
+		PauseAdsEnabled:      &pauseAds,
+		VASTVersion:          "4.0",
+	}
+	return NewPlayerConfigHandler(defaults, lookup, priv, "k1"), pub
+}
+
+func getPlayerConfig(t *testing.T, h http.Handler, pub ed25519.PublicKey, publisherID string) (*httptest.ResponseRecorder, PlayerConfig) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/video/config?pub="+publisherID, nil))
+
+	var cfg PlayerConfig
+	if w.Code != http.StatusOK {
+		return w, cfg
+	}
+
+	keyID, sig, ok := strings.Cut(w.Header().Get(PlayerConfigSignatureHeader), ".")
+	raw, err := base64.RawURLEncoding.DecodeString(sig)
+	if !ok || keyID != "k1" || err != nil || !ed25519.Verify(pub, w.Body.Bytes(), raw) {
+		t.Fatalf("signature did not verify: %q", w.Header().Get(PlayerConfigSignatureHeader))
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &cfg); err != nil {
+		t.Fatalf("invalid config body: %v", err)
+	}
+	return w, cfg
+}
+
+func TestPlayerConfigHandler_Overrides(t *testing.T) {
+	pauseAds := true
+	lookup := &mockPublisherLookup{publishers: map[string]*storage.Publisher{
+		"pub-defaults": {PublisherID: "pub-defaults"},
+		"pub-custom": {PublisherID: "pub-custom", PlayerConfig: &storage.PlayerConfig{
+			EventBatchIntervalMs: 2000,
+			PauseAdsEnabled:      &pauseAds,
+			VASTVersion:          "4.2",
+		}},
+	}}
+	h, pub := newTestPlayerConfigHandler(lookup)
+
+	_, cfg := getPlayerConfig(t, h, pub, "pub-defaults")
+	if cfg.TrackingBaseURL != "https://ads.example.com" || cfg.EventBatchIntervalMs != 5000 || cfg.PauseAdsEnabled || cfg.VASTVersion != "4.0" {
+		t.Errorf("expected defaults, got %+v", cfg)
+	}
+	if cfg.ExpiresAt-cfg.IssuedAt != int64(playerConfigTTL.Seconds()) {
+		t.Errorf("expected config valid for %v, got %+v", playerConfigTTL, cfg)
+	}
+
+	w, cfg := getPlayerConfig(t, h, pub, "pub-custom")
+	if cfg.PublisherID != "pub-custom" || cfg.TrackingBaseURL != "https://ads.example.com" ||
+		cfg.EventBatchIntervalMs != 2000 || !cfg.PauseAdsEnabled || cfg.VASTVersion != "4.2" {
+		t.Errorf("expected publisher overrides, got %+v", cfg)
+	}
+	if w.Header().Get("Access-Control-Allow-Origin") != "*" || w.Header().Get("Cache-Control") != playerConfigCacheControl {
+		t.Errorf("unexpected headers: %v", w.Header())
+	}
+
+	// Publishers are cached between requests
+	getPlayerConfig(t, h, pub, "pub-custom")
+	if lookup.calls != 2 {
+		t.Errorf("expected 2 lookups with caching, got %d", lookup.calls)
+	}
+}
+
+func TestPlayerConfigHandler_Errors(t *testing.T) {
+	lookup := &mockPublisherLookup{}
+	h, pub := newTestPlayerConfigHandler(lookup)
+
+	if w, _ := getPlayerConfig(t, h, pub, "unknown"); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown publisher, got %d", w.Code)
+	}
+	// Misses are remembered briefly
+	getPlayerConfig(t, h, pub, "unknown")
+	if lookup.calls != 1 {
+		t.Errorf("expected 1 lookup for a repeated unknown publisher, got %d", lookup.calls)
+	}
+	now := time.Now()
+	h.now = func() time.Time { return now.Add(lookupMissTTL) }
+	getPlayerConfig(t, h, pub, "unknown")
+	if lookup.calls != 2 {
+		t.Errorf("expected the miss to expire after %v, got %d lookups", lookupMissTTL, lookup.calls)
+	}
+	h.now = time.Now
+	if w, _ := getPlayerConfig(t, h, pub, ""); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 without pub, got %d", w.Code)
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/video/config?pub=x", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405, got %d", w.Code)
+	}
+
+	// A database outage serves the defaults rather than breaking players
+	h, pub = newTestPlayerConfigHandler(&mockPublisherLookup{err: errors.New("connection refused")})
+	if w, cfg := getPlayerConfig(t, h, pub, "pub-1"); w.Code != http.StatusOK || cfg.VASTVersion != "4.0" {
+		t.Errorf("expected defaults on lookup error, got %d %+v", w.Code, cfg)
+	}
+}
@@ -0,0 +1,197 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+	"github.com/thenexusengine/tne_springwire/pkg/deadline"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+const (
+	// s2sTimeoutOverhead is added to the slowest bidder's timeout to cover
+	// request parsing, IDR selection and response assembly
+	s2sTimeoutOverhead = 100 * time.Millisecond
+	// minS2STimeout keeps the recommendation usable for very fast bidders
+	minS2STimeout = 200 * time.Millisecond
+	// prebidSettingsCacheTTL bounds how stale a publisher's bidder list can be
+	prebidSettingsCacheTTL = 30 * time.Second
+	// prebidSettingsCacheControl lets browsers and CDNs reuse a response
+	prebidSettingsCacheControl = "public, max-age=300"
+)
+
+// PublisherBidderStore lists the bidders enabled for a publisher; implemented
+// by storage.BidderStore
+type PublisherBidderStore interface {
+	GetForPublisher(ctx context.Context, publisherID string) ([]*storage.PublisherBidder, error)
+}
+
+// PrebidSettings is passed straight to pbjs.setConfig()
+type PrebidSettings struct {
+	S2SConfig S2SConfig `json:"s2sConfig"`
+}
+
+// S2SConfig is a Prebid.js s2sConfig block
+type S2SConfig struct {
+	AccountID    string      `json:"accountId"`
+	Enabled      bool        `json:"enabled"`
+	Adapter      string      `json:"adapter"`
+	Bidders      []string    `json:"bidders"`
+	Timeout      int         `json:"timeout"` // milliseconds
+	Endpoint     S2SEndpoint `json:"endpoint"`
+	SyncEndpoint S2SEndpoint `json:"syncEndpoint"`
+}
+
+// S2SEndpoint is a Prebid.js endpoint pair; we use the same URL with and
+// without purpose 1 consent
+type S2SEndpoint struct {
+	P1Consent   string `json:"p1Consent"`
+	NoP1Consent string `json:"noP1Consent"`
+}
+
+type cachedPrebidBidders struct {
+	bidders []*storage.PublisherBidder
+	expires time.Time
+}
+
+// PrebidSettingsHandler serves GET /prebid/settings?pub=<publisher id>, a
+// Prebid.js s2sConfig generated from the publisher's bidders in the database
+// so pages don't carry their own copy of the bidder list and endpoints
+type PrebidSettingsHandler struct {
+	hostURL        string
+	defaultTimeout time.Duration
+	store          PublisherBidderStore
+	now            func() time.Time
+
+	mu     sync.Mutex
+	cache  map[string]cachedPrebidBidders
+	misses *missCache
+}
+
+// NewPrebidSettingsHandler creates a Prebid settings handler. defaultTimeout
+// is the server's auction timeout, which caps the recommended s2s timeout.
+func NewPrebidSettingsHandler(hostURL string, defaultTimeout time.Duration, store PublisherBidderStore) *PrebidSettingsHandler {
+	return &PrebidSettingsHandler{
+		hostURL:        strings.TrimSuffix(hostURL, "/"),
+		defaultTimeout: defaultTimeout,
+		store:          store,
+		now:            time.Now,
+		cache:          make(map[string]cachedPrebidBidders),
+		misses:         newMissCache(lookupMissTTL),
+	}
+}
+
+// ServeHTTP handles GET /prebid/settings
+func (h *PrebidSettingsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Prebid.js loads settings from publisher pages
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Accept")
+
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	publisherID := r.URL.Query().Get("pub")
+	if publisherID == "" || len(publisherID) > 255 {
+		writeError(w, "pub is required", http.StatusBadRequest)
+		return
+	}
+
+	bidders, err := h.publisherBidders(r.Context(), publisherID)
+	if err != nil {
+		logger.Log.Error().Err(err).Str("publisher_id", publisherID).Msg("Failed to load publisher bidders")
+		writeError(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if len(bidders) == 0 {
+		writeError(w, "No bidders configured for publisher", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", prebidSettingsCacheControl)
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(h.settings(publisherID, bidders)); err != nil {
+		logger.Log.Error().Err(err).Msg("Failed to encode Prebid settings")
+	}
+}
+
+func (h *PrebidSettingsHandler) publisherBidders(ctx context.Context, publisherID string) ([]*storage.PublisherBidder, error) {
+	now := h.now()
+	h.mu.Lock()
+	if cached, ok := h.cache[publisherID]; ok && now.Before(cached.expires) {
+		h.mu.Unlock()
+		return cached.bidders, nil
+	}
+	h.mu.Unlock()
+	if h.misses.has(publisherID, now) {
+		return nil, nil
+	}
+
+	dbCtx, cancel := deadline.WithCap(ctx, deadline.DependencyPostgres)
+	defer cancel()
+	bidders, err := h.store.GetForPublisher(dbCtx, publisherID)
+	if err = deadline.Observe(dbCtx, deadline.DependencyPostgres, err); err != nil {
+		return nil, err
+	}
+
+	// Unknown IDs go in the bounded miss cache so they can't grow the cache
+	if len(bidders) == 0 {
+		h.misses.add(publisherID, now)
+		return nil, nil
+	}
+	h.mu.Lock()
+	h.cache[publisherID] = cachedPrebidBidders{bidders: bidders, expires: now.Add(prebidSettingsCacheTTL)}
+	h.mu.Unlock()
+	return bidders, nil
+}
+
+func (h *PrebidSettingsHandler) settings(publisherID string, bidders []*storage.PublisherBidder) PrebidSettings {
+	codes := make([]string, 0, len(bidders))
+	for _, b := range bidders {
+		codes = append(codes, b.BidderCode)
+	}
+
+	auction := h.hostURL + "/openrtb2/auction"
+	cookieSync := h.hostURL + "/cookie_sync"
+	return PrebidSettings{S2SConfig: S2SConfig{
+		AccountID:    publisherID,
+		Enabled:      true,
+		Adapter:      "prebidServer",
+		Bidders:      codes,
+		Timeout:      int(h.recommendedTimeout(bidders).Milliseconds()),
+		Endpoint:     S2SEndpoint{P1Consent: auction, NoP1Consent: auction},
+		SyncEndpoint: S2SEndpoint{P1Consent: cookieSync, NoP1Consent: cookieSync},
+	}}
+}
+
+// recommendedTimeout gives the slowest bidder its configured timeout plus
+// server overhead, within the server's own auction timeout
+func (h *PrebidSettingsHandler) recommendedTimeout(bidders []*storage.PublisherBidder) time.Duration {
+	var slowest time.Duration
+	for _, b := range bidders {
+		if t := time.Duration(b.TimeoutMs) * time.Millisecond; t > slowest {
+			slowest = t
+		}
+	}
+	if slowest == 0 {
+		return h.defaultTimeout
+	}
+
+	timeout := max(slowest+s2sTimeoutOverhead, minS2STimeout)
+	if h.defaultTimeout > 0 && timeout > h.defaultTimeout {
+		timeout = h.defaultTimeout
+	}
+	return timeout
+}
@@ -0,0 +1,124 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+	"github.com/thenexusengine/tne_springwire/internal/testfixtures"
+)
+
+type mockPublisherBidderStore struct {
+	bidders map[string][]*storage.PublisherBidder
+	err     error
+	calls   int
+}
+
+func (m *mockPublisherBidderStore) GetForPublisher(_ context.Context, publisherID string) ([]*storage.PublisherBidder, error) {
+	m.calls++
+	return m.bidders[publisherID], m.err
+}
+
+func publisherBidder(code string, timeoutMs int) *storage.PublisherBidder {
+	return testfixtures.Bidder(code).Timeout(timeoutMs).ForPublisher("pub-1", nil)
+}
+
+func TestPrebidSettingsHandler(t *testing.T) {
+	store := &mockPublisherBidderStore{bidders: map[string][]*storage.PublisherBidder{
+		"pub-1": {publisherBidder("appnexus", 500), publisherBidder("rubicon", 700)},
+	}}
+	h := NewPrebidSettingsHandler("https://ads.example.com/", time.Second, store)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/prebid/settings?pub=pub-1", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var settings PrebidSettings
+	if err := json.Unmarshal(w.Body.Bytes(), &settings); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	s2s := settings.S2SConfig
+	if s2s.AccountID != "pub-1" || !s2s.Enabled || s2s.Adapter != "prebidServer" {
+		t.Errorf("unexpected s2sConfig: %+v", s2s)
+	}
+	if len(s2s.Bidders) != 2 || s2s.Bidders[0] != "appnexus" || s2s.Bidders[1] != "rubicon" {
+		t.Errorf("expected publisher bidders, got %v", s2s.Bidders)
+	}
+	if s2s.Timeout != 800 {
+		t.Errorf("expected slowest bidder plus overhead (800ms), got %d", s2s.Timeout)
+	}
+	if s2s.Endpoint.P1Consent != "https://ads.example.com/openrtb2/auction" ||
+		s2s.SyncEndpoint.NoP1Consent != "https://ads.example.com/cookie_sync" {
+		t.Errorf("unexpected endpoints: %+v %+v", s2s.Endpoint, s2s.SyncEndpoint)
+	}
+
+	// Bidder lists are cached between requests
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/prebid/settings?pub=pub-1", nil))
+	if store.calls != 1 {
+		t.Errorf("expected 1 lookup with caching, got %d", store.calls)
+	}
+}
+
+func TestPrebidSettingsHandler_RecommendedTimeout(t *testing.T) {
+	h := NewPrebidSettingsHandler("https://ads.example.com", time.Second, nil)
+
+	tests := []struct {
+		name    string
+		bidders []*storage.PublisherBidder
+		want    time.Duration
+	}{
+		{"no bidder timeouts", []*storage.PublisherBidder{publisherBidder("a", 0)}, time.Second},
+		{"fast bidders", []*storage.PublisherBidder{publisherBidder("a", 50)}, minS2STimeout},
+		{"capped at server timeout", []*storage.PublisherBidder{publisherBidder("a", 1500)}, time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := h.recommendedTimeout(tt.bidders); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestPrebidSettingsHandler_Errors(t *testing.T) {
+	store := &mockPublisherBidderStore{}
+	h := NewPrebidSettingsHandler("https://ads.example.com", time.Second, store)
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		status int
+	}{
+		{"missing pub", http.MethodGet, "/prebid/settings", http.StatusBadRequest},
+		{"unknown publisher", http.MethodGet, "/prebid/settings?pub=nope", http.StatusNotFound},
+		{"wrong method", http.MethodPost, "/prebid/settings?pub=nope", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(tt.method, tt.target, nil))
+			if w.Code != tt.status {
+				t.Errorf("expected %d, got %d", tt.status, w.Code)
+			}
+		})
+	}
+	// The unknown publisher's miss is remembered for the second request
+	if store.calls != 1 {
+		t.Errorf("expected 1 lookup for a repeated unknown publisher, got %d", store.calls)
+	}
+
+	h = NewPrebidSettingsHandler("https://ads.example.com", time.Second, &mockPublisherBidderStore{err: errors.New("connection refused")})
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/prebid/settings?pub=pub-1", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500 on store error, got %d", w.Code)
+	}
+}
@@ -2,37 +2,110 @@
 package endpoints
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"sort"
 	"strings"
 
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+	"github.com/thenexusengine/tne_springwire/pkg/domainmatch"
+	"github.com/thenexusengine/tne_springwire/pkg/kv"
 	"github.com/thenexusengine/tne_springwire/pkg/logger"
-	"github.com/thenexusengine/tne_springwire/pkg/redis"
 )
 
 // PublisherAdminHandler handles publisher CRUD operations via API
 type PublisherAdminHandler struct {
-	redisClient *redis.Client
+	redisClient kv.Store
+	history     http.Handler
+	billing     http.Handler
+	invalidator Invalidator
+	archive     PublisherArchiveStore
 }
 
-// NewPublisherAdminHandler creates a new publisher admin handler
-func NewPublisherAdminHandler(redisClient *redis.Client) *PublisherAdminHandler {
+// NewPublisherAdminHandler creates a new publisher admin handler backed by the shared KV store
+func NewPublisherAdminHandler(redisClient kv.Store) *PublisherAdminHandler {
 	return &PublisherAdminHandler{
 		redisClient: redisClient,
 	}
 }
 
+// SetHistoryHandler serves /admin/publishers/:id/history and /rollback,
+// which come from the database rather than Redis
+func (h *PublisherAdminHandler) SetHistoryHandler(history http.Handler) {
+	h.history = history
+}
+
+// SetBillingHandler serves /admin/publishers/:id/billing, which comes from
+// the database rather than Redis
+func (h *PublisherAdminHandler) SetBillingHandler(billing http.Handler) {
+	h.billing = billing
+}
+
+// PublisherArchiveStore holds publishers' database rows, where deleted
+// publishers are archived; implemented by storage.PublisherStore
+type PublisherArchiveStore interface {
+	ListPage(ctx context.Context, opts storage.ListOptions) ([]*storage.Publisher, int, error)
+	Delete(ctx context.Context, publisherID string) error
+	Restore(ctx context.Context, publisherID string, check func(*storage.Publisher) error) (*storage.Publisher, error)
+}
+
+// SetArchiveStore makes DELETE archive the publisher's database row too,
+// and enables browsing database rows by status (?status=archived) and
+// restoring archived publishers
+func (h *PublisherAdminHandler) SetArchiveStore(store PublisherArchiveStore) {
+	h.archive = store
+}
+
+// Invalidator drops cached entries on every replica; implemented by
+// CacheAdminHandler
+type Invalidator interface {
+	Invalidate(ctx context.Context, req CacheInvalidateRequest) (CacheInvalidateResponse, error)
+}
+
+// SetInvalidator lets flush-auth purge every replica's in-memory publisher
+// auth cache
+func (h *PublisherAdminHandler) SetInvalidator(invalidator Invalidator) {
+	h.invalidator = invalidator
+}
+
+// FlushAuthResponse reports what POST /admin/publishers/:id/flush-auth cleared
+type FlushAuthResponse struct {
+	PublisherID string `json:"publisher_id"`
+	Invalidated int    `json:"invalidated"` // Entries dropped from this replica's cache
+	Broadcast   bool   `json:"broadcast"`   // Other replicas were told to drop theirs
+}
+
+// flushAuthSuffix is the path suffix for force-expiring a publisher's auth
+const flushAuthSuffix = "/flush-auth"
+
+// restoreSuffix is the path suffix for restoring an archived publisher
+const restoreSuffix = "/restore"
+
 // Publisher represents a publisher configuration
 type Publisher struct {
 	ID             string   `json:"id"`
-	AllowedDomains string   `json:"allowed_domains"` // Pipe-separated: "domain1.com|*.domain2.com"
+	AllowedDomains string   `json:"allowed_domains"` // "|" or "," separated: "domain1.com|*.domain2.com"
 	DomainList     []string `json:"domain_list"`     // Parsed array for display
 }
 
 // PublisherListResponse is the response for listing publishers
 type PublisherListResponse struct {
 	Publishers []Publisher `json:"publishers"`
-	Count      int         `json:"count"`
+	Count      int         `json:"count"` // Publishers in this page
+	Total      int         `json:"total"` // All registered publishers
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+// PublisherRecordListResponse is the response for listing publishers'
+// database rows by status
+type PublisherRecordListResponse struct {
+	Publishers []*storage.Publisher `json:"publishers"`
+	Count      int                  `json:"count"` // Publishers in this page
+	Total      int                  `json:"total"` // Publishers with the status
+	NextCursor string               `json:"next_cursor,omitempty"`
 }
 
 // PublisherRequest is the request body for creating/updating publishers
@@ -52,12 +125,27 @@ const publishersHashKey = "tne_catalyst:publishers"
 // ServeHTTP handles publisher API requests
 // Routes:
 //
-//	GET    /admin/publishers       - List all publishers
+//	GET    /admin/publishers       - List publishers (?limit, ?offset, ?cursor, ?sort=id|-id)
+//	GET    /admin/publishers?status=archived - List database rows by status (?limit, ?offset, ?cursor, ?sort)
 //	GET    /admin/publishers/:id   - Get specific publisher
 //	POST   /admin/publishers       - Create publisher
 //	PUT    /admin/publishers/:id   - Update publisher
-//	DELETE /admin/publishers/:id   - Delete publisher
+//	DELETE /admin/publishers/:id   - Delete publisher, archiving its database row
+//	POST   /admin/publishers/:id/flush-auth - Drop cached auth state everywhere
+//	POST   /admin/publishers/:id/restore    - Re-validate and re-register an archived publisher
+//
+// History and rollback routes are delegated to the history handler, and
+// billing routes to the billing handler.
 func (h *PublisherAdminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.history != nil && IsConfigHistoryPath(r.URL.Path) {
+		h.history.ServeHTTP(w, r)
+		return
+	}
+	if h.billing != nil && IsBillingPath(r.URL.Path) {
+		h.billing.ServeHTTP(w, r)
+		return
+	}
+
 	// Check if Redis is available
 	if h.redisClient == nil {
 		h.sendError(w, http.StatusServiceUnavailable, "Redis not available", "Publisher management requires Redis connection")
@@ -72,6 +160,23 @@ func (h *PublisherAdminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request
 		publisherID = path
 	}
 
+	if id, ok := strings.CutSuffix(publisherID, flushAuthSuffix); ok && id != "" {
+		if r.Method != http.MethodPost {
+			h.sendError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+			return
+		}
+		h.flushAuth(w, r, id)
+		return
+	}
+	if id, ok := strings.CutSuffix(publisherID, restoreSuffix); ok && id != "" {
+		if r.Method != http.MethodPost {
+			h.sendError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+			return
+		}
+		h.restorePublisher(w, r, id)
+		return
+	}
+
 	switch r.Method {
 	case http.MethodGet:
 		if publisherID != "" {
@@ -98,10 +203,26 @@ func (h *PublisherAdminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request
 	}
 }
 
-// listPublishers returns all registered publishers
+// listPublishers returns a page of registered publishers in ID order
 func (h *PublisherAdminHandler) listPublishers(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
+	opts, err := parseListOptions(r)
+	if err == nil && opts.Status != "" {
+		if h.archive != nil {
+			h.listPublisherRecords(w, r, opts)
+			return
+		}
+		err = fmt.Errorf("status filter requires a database connection")
+	}
+	if err == nil && opts.Sort != "" && opts.Sort != "id" && opts.Sort != "-id" {
+		err = fmt.Errorf("publishers can only be sorted by id")
+	}
+	if err != nil {
+		h.sendError(w, http.StatusBadRequest, "invalid_list_options", err.Error())
+		return
+	}
+
 	// Get all publishers from Redis hash
 	publishers, err := h.redisClient.HGetAll(ctx, publishersHashKey)
 	if err != nil {
@@ -110,9 +231,41 @@ func (h *PublisherAdminHandler) listPublishers(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	ids := make([]string, 0, len(publishers))
+	for id := range publishers {
+		ids = append(ids, id)
+	}
+	desc := opts.Sort == "-id"
+	sort.Slice(ids, func(i, j int) bool {
+		if desc {
+			return ids[i] > ids[j]
+		}
+		return ids[i] < ids[j]
+	})
+
+	// Skip to the cursor (or offset), then take one page
+	start := 0
+	if opts.Cursor != "" {
+		start = sort.Search(len(ids), func(i int) bool {
+			if desc {
+				return ids[i] < opts.Cursor
+			}
+			return ids[i] > opts.Cursor
+		})
+	} else if opts.Offset < len(ids) {
+		start = opts.Offset
+	} else {
+		start = len(ids)
+	}
+	end := start + opts.PageLimit()
+	if end > len(ids) {
+		end = len(ids)
+	}
+
 	// Convert to response format
-	pubList := make([]Publisher, 0, len(publishers))
-	for id, domains := range publishers {
+	pubList := make([]Publisher, 0, end-start)
+	for _, id := range ids[start:end] {
+		domains := publishers[id]
 		pubList = append(pubList, Publisher{
 			ID:             id,
 			AllowedDomains: domains,
@@ -120,14 +273,47 @@ func (h *PublisherAdminHandler) listPublishers(w http.ResponseWriter, r *http.Re
 		})
 	}
 
+	var cursor string
+	if end < len(ids) {
+		cursor = ids[end-1]
+	}
+
 	response := PublisherListResponse{
 		Publishers: pubList,
 		Count:      len(pubList),
+		Total:      len(ids),
+		NextCursor: cursor,
 	}
 
+	setListHeaders(w, len(ids), cursor)
 	h.sendJSON(w, http.StatusOK, response)
 }
 
+// listPublisherRecords returns a page of publishers' database rows with
+// the requested status, e.g. archived ones
+func (h *PublisherAdminHandler) listPublisherRecords(w http.ResponseWriter, r *http.Request, opts storage.ListOptions) {
+	publishers, total, err := h.archive.ListPage(r.Context(), opts)
+	if errors.Is(err, storage.ErrInvalidListOptions) {
+		h.sendError(w, http.StatusBadRequest, "invalid_list_options", err.Error())
+		return
+	}
+	if err != nil {
+		logger.Log.Error().Err(err).Str("status", opts.Status).Msg("Failed to list publishers from the database")
+		h.sendError(w, http.StatusInternalServerError, "database_error", "Failed to retrieve publishers")
+		return
+	}
+	if publishers == nil {
+		publishers = []*storage.Publisher{}
+	}
+
+	var cursor string
+	if len(publishers) > 0 {
+		cursor = nextCursor(opts, "publisher_id", len(publishers), publishers[len(publishers)-1].PublisherID)
+	}
+	setListHeaders(w, total, cursor)
+	h.sendJSON(w, http.StatusOK, PublisherRecordListResponse{Publishers: publishers, Count: len(publishers), Total: total, NextCursor: cursor})
+}
+
 // getPublisher returns a specific publisher by ID
 func (h *PublisherAdminHandler) getPublisher(w http.ResponseWriter, r *http.Request, publisherID string) {
 	ctx := r.Context()
@@ -174,6 +360,10 @@ func (h *PublisherAdminHandler) createPublisher(w http.ResponseWriter, r *http.R
 		h.sendError(w, http.StatusBadRequest, "missing_domains", "Allowed domains are required")
 		return
 	}
+	if err := domainmatch.Validate(req.AllowedDomains); err != nil {
+		h.sendError(w, http.StatusBadRequest, "invalid_domains", err.Error())
+		return
+	}
 
 	// Check if publisher already exists
 	existing, err := h.redisClient.HGet(ctx, publishersHashKey, req.ID)
@@ -225,6 +415,10 @@ func (h *PublisherAdminHandler) updatePublisher(w http.ResponseWriter, r *http.R
 		h.sendError(w, http.StatusBadRequest, "missing_domains", "Allowed domains are required")
 		return
 	}
+	if err := domainmatch.Validate(req.AllowedDomains); err != nil {
+		h.sendError(w, http.StatusBadRequest, "invalid_domains", err.Error())
+		return
+	}
 
 	// Check if publisher exists
 	existing, err := h.redisClient.HGet(ctx, publishersHashKey, publisherID)
@@ -261,7 +455,8 @@ func (h *PublisherAdminHandler) updatePublisher(w http.ResponseWriter, r *http.R
 	h.sendJSON(w, http.StatusOK, publisher)
 }
 
-// deletePublisher deletes a publisher
+// deletePublisher removes a publisher from Redis and, with an archive
+// store, archives its database row so it can be restored later
 func (h *PublisherAdminHandler) deletePublisher(w http.ResponseWriter, r *http.Request, publisherID string) {
 	ctx := r.Context()
 
@@ -272,21 +467,44 @@ func (h *PublisherAdminHandler) deletePublisher(w http.ResponseWriter, r *http.R
 		h.sendError(w, http.StatusInternalServerError, "redis_error", "Failed to check existing publisher")
 		return
 	}
-	if existing == "" {
+	if existing == "" && h.archive == nil {
 		h.sendError(w, http.StatusNotFound, "not_found", "Publisher not found")
 		return
 	}
 
 	// Delete publisher from Redis
-	if err := h.redisClient.HDel(ctx, publishersHashKey, publisherID); err != nil {
-		logger.Log.Error().Err(err).Str("publisher_id", publisherID).Msg("Failed to delete publisher from Redis")
-		h.sendError(w, http.StatusInternalServerError, "redis_error", "Failed to delete publisher")
+	if existing != "" {
+		if err := h.redisClient.HDel(ctx, publishersHashKey, publisherID); err != nil {
+			logger.Log.Error().Err(err).Str("publisher_id", publisherID).Msg("Failed to delete publisher from Redis")
+			h.sendError(w, http.StatusInternalServerError, "redis_error", "Failed to delete publisher")
+			return
+		}
+	}
+
+	// Archive the database row, if there is one, so auth doesn't fall
+	// through to it
+	archived := false
+	if h.archive != nil {
+		err := h.archive.Delete(ctx, publisherID)
+		switch {
+		case err == nil:
+			archived = true
+		case errors.Is(err, storage.ErrNotFound):
+		default:
+			logger.Log.Error().Err(err).Str("publisher_id", publisherID).Msg("Failed to archive publisher")
+			h.sendError(w, http.StatusInternalServerError, "database_error", "Failed to archive publisher")
+			return
+		}
+	}
+	if existing == "" && !archived {
+		h.sendError(w, http.StatusNotFound, "not_found", "Publisher not found")
 		return
 	}
 
 	logger.Log.Info().
 		Str("publisher_id", publisherID).
 		Str("domains", existing).
+		Bool("archived", archived).
 		Msg("Publisher deleted")
 
 	// Return success with deleted info
@@ -294,25 +512,111 @@ func (h *PublisherAdminHandler) deletePublisher(w http.ResponseWriter, r *http.R
 		"success":         true,
 		"publisher_id":    publisherID,
 		"deleted_domains": existing,
+		"archived":        archived,
 	}
 
 	h.sendJSON(w, http.StatusOK, response)
 }
 
-// parseDomains splits pipe-separated domains into array
+// restorePublisher brings an archived publisher back: its database row
+// becomes active again and its allowed domains are registered in Redis.
+// The archived domains must still be valid, and a publisher re-created in
+// Redis since it was archived isn't overwritten.
+func (h *PublisherAdminHandler) restorePublisher(w http.ResponseWriter, r *http.Request, publisherID string) {
+	if h.archive == nil {
+		h.sendError(w, http.StatusServiceUnavailable, "database_unavailable", "Restoring publishers requires a database connection")
+		return
+	}
+	ctx := r.Context()
+
+	existing, err := h.redisClient.HGet(ctx, publishersHashKey, publisherID)
+	if err != nil {
+		logger.Log.Error().Err(err).Str("publisher_id", publisherID).Msg("Failed to check existing publisher")
+		h.sendError(w, http.StatusInternalServerError, "redis_error", "Failed to check existing publisher")
+		return
+	}
+	if existing != "" {
+		h.sendError(w, http.StatusConflict, "already_exists", "Publisher was re-created since it was archived. Use PUT to update it.")
+		return
+	}
+
+	restored, err := h.archive.Restore(ctx, publisherID, func(p *storage.Publisher) error {
+		if p.AllowedDomains == "" {
+			return fmt.Errorf("%w allowed_domains: none configured", storage.ErrValidation)
+		}
+		if err := domainmatch.Validate(p.AllowedDomains); err != nil {
+			return fmt.Errorf("%w allowed_domains: %v", storage.ErrValidation, err)
+		}
+		return nil
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrValidation):
+			h.sendError(w, http.StatusUnprocessableEntity, "invalid_configuration", "Archived configuration is no longer valid: "+err.Error())
+		case !sendStorageError(w, err):
+			logger.Log.Error().Err(err).Str("publisher_id", publisherID).Msg("Failed to restore publisher")
+			h.sendError(w, http.StatusInternalServerError, "database_error", "Failed to restore publisher")
+		}
+		return
+	}
+
+	if err := h.redisClient.HSet(ctx, publishersHashKey, publisherID, restored.AllowedDomains); err != nil {
+		logger.Log.Error().Err(err).Str("publisher_id", publisherID).Msg("Failed to register restored publisher in Redis")
+		h.sendError(w, http.StatusInternalServerError, "redis_error", "Publisher restored in the database but not registered in Redis")
+		return
+	}
+
+	// Replicas may have cached the publisher as unknown
+	if h.invalidator != nil {
+		if _, err := h.invalidator.Invalidate(ctx, CacheInvalidateRequest{Scope: "publisher", IDs: []string{publisherID}}); err != nil {
+			logger.Log.Warn().Err(err).Str("publisher_id", publisherID).Msg("Publisher auth cache not invalidated")
+		}
+	}
+
+	logger.Log.Info().
+		Str("publisher_id", publisherID).
+		Str("domains", restored.AllowedDomains).
+		Int("version", restored.Version).
+		Msg("Publisher restored")
+
+	h.sendJSON(w, http.StatusOK, Publisher{
+		ID:             publisherID,
+		AllowedDomains: restored.AllowedDomains,
+		DomainList:     parseDomains(restored.AllowedDomains),
+	})
+}
+
+// parseDomains splits "|" or "," separated domains into array
 func parseDomains(domains string) []string {
-	if domains == "" {
-		return []string{}
-	}
-	parts := strings.Split(domains, "|")
-	result := make([]string, 0, len(parts))
-	for _, part := range parts {
-		trimmed := strings.TrimSpace(part)
-		if trimmed != "" {
-			result = append(result, trimmed)
+	if list := domainmatch.Split(domains); list != nil {
+		return list
+	}
+	return []string{}
+}
+
+// flushAuth force-expires a publisher's cached auth state, e.g. after a
+// suspension, so it stops being admitted now rather than when caches expire.
+// Every replica drops its in-memory entry and re-reads the publisher's
+// current record; the Redis record itself is left in place.
+func (h *PublisherAdminHandler) flushAuth(w http.ResponseWriter, r *http.Request, publisherID string) {
+	ctx := r.Context()
+
+	resp := FlushAuthResponse{PublisherID: publisherID}
+	if h.invalidator != nil {
+		result, err := h.invalidator.Invalidate(ctx, CacheInvalidateRequest{Scope: "publisher", IDs: []string{publisherID}})
+		if err != nil {
+			logger.Log.Warn().Err(err).Str("publisher_id", publisherID).Msg("Publisher auth cache not invalidated")
 		}
+		resp.Invalidated, resp.Broadcast = result.Invalidated, result.Broadcast
 	}
-	return result
+
+	logger.Log.Info().
+		Str("publisher_id", publisherID).
+		Int("invalidated", resp.Invalidated).
+		Bool("broadcast", resp.Broadcast).
+		Msg("Publisher auth flushed")
+
+	h.sendJSON(w, http.StatusOK, resp)
 }
 
 // sendJSON sends a JSON response
@@ -2,13 +2,17 @@ package endpoints
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/alicebob/miniredis/v2"
 
+	"github.com/thenexusengine/tne_springwire/internal/storage"
 	"github.com/thenexusengine/tne_springwire/pkg/redis"
 )
 
@@ -145,6 +149,66 @@ func TestListPublishers_WithData(t *testing.T) {
 	}
 }
 
+// TestListPublishers_Pagination tests limit, cursor, offset and sort
+func TestListPublishers_Pagination(t *testing.T) {
+	client, mr := setupTestRedisForPublisher(t)
+	defer mr.Close()
+
+	for _, id := range []string{"pub3", "pub1", "pub4", "pub2"} {
+		mr.HSet(publishersHashKey, id, "example.com")
+	}
+	handler := NewPublisherAdminHandler(client)
+
+	list := func(query string) (PublisherListResponse, *httptest.ResponseRecorder) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/publishers?"+query, nil))
+		var resp PublisherListResponse
+		if w.Code == http.StatusOK {
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+		}
+		return resp, w
+	}
+	ids := func(resp PublisherListResponse) string {
+		out := make([]string, len(resp.Publishers))
+		for i, p := range resp.Publishers {
+			out[i] = p.ID
+		}
+		return strings.Join(out, ",")
+	}
+
+	resp, w := list("limit=3")
+	if ids(resp) != "pub1,pub2,pub3" || resp.Total != 4 || resp.NextCursor != "pub3" {
+		t.Errorf("Unexpected first page: %+v", resp)
+	}
+	if w.Header().Get("X-Total-Count") != "4" || w.Header().Get("X-Next-Cursor") != "pub3" {
+		t.Errorf("Unexpected list headers: %v", w.Header())
+	}
+
+	resp, _ = list("limit=3&cursor=pub3")
+	if ids(resp) != "pub4" || resp.NextCursor != "" {
+		t.Errorf("Unexpected second page: %+v", resp)
+	}
+
+	resp, _ = list("limit=2&offset=1&sort=-id")
+	if ids(resp) != "pub3,pub2" || resp.NextCursor != "pub2" {
+		t.Errorf("Unexpected descending page: %+v", resp)
+	}
+
+	resp, _ = list("offset=10")
+	if len(resp.Publishers) != 0 || resp.Total != 4 {
+		t.Errorf("Expected empty page past the end, got %+v", resp)
+	}
+
+	for _, query := range []string{"status=active", "sort=domains", "limit=-1"} {
+		if _, w := list(query); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", query, w.Code)
+		}
+	}
+}
+
 // TestGetPublisher_Success tests getting a specific publisher
 func TestGetPublisher_Success(t *testing.T) {
 	client, mr := setupTestRedisForPublisher(t)
@@ -767,3 +831,233 @@ func TestServeHTTP_PathParsing(t *testing.T) {
 		})
 	}
 }
+
+// TestFlushPublisherAuth tests that flush-auth broadcasts a cache purge and leaves Redis alone
+func TestFlushPublisherAuth(t *testing.T) {
+	client, mr := setupTestRedisForPublisher(t)
+	defer mr.Close()
+	mr.HSet(publishersHashKey, "pub-1", "example.com")
+	mr.HSet(publishersHashKey, "pub-2", "other.com")
+
+	var invalidated []string
+	cacheAdmin := NewCacheAdminHandler()
+	cacheAdmin.RegisterInvalidator("publisher", CacheInvalidatorFunc(func(ids ...string) int {
+		invalidated = append(invalidated, ids...)
+		return len(ids)
+	}))
+	pub := &mockInvalidationPublisher{}
+	cacheAdmin.SetPublisher(pub)
+
+	handler := NewPublisherAdminHandler(client)
+	handler.SetInvalidator(cacheAdmin)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/publishers/pub-1/flush-auth", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp FlushAuthResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if resp.PublisherID != "pub-1" || resp.Invalidated != 1 || !resp.Broadcast {
+		t.Errorf("Unexpected response: %+v", resp)
+	}
+	if mr.HGet(publishersHashKey, "pub-1") != "example.com" || mr.HGet(publishersHashKey, "pub-2") != "other.com" {
+		t.Error("Expected Redis publishers untouched")
+	}
+	if len(invalidated) != 1 || invalidated[0] != "pub-1" || len(pub.messages) != 1 {
+		t.Errorf("Expected pub-1 invalidated and broadcast, got %v %v", invalidated, pub.messages)
+	}
+
+	// Flushing a publisher that isn't in Redis still purges the caches
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/publishers/pub-9/flush-auth", nil))
+	if w.Code != http.StatusOK || len(pub.messages) != 2 {
+		t.Errorf("Expected flush of an unknown publisher to broadcast, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/publishers/pub-1/flush-auth", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status 405 for GET, got %d", w.Code)
+	}
+}
+
+// TestFlushPublisherAuth_KeepsPublisher tests that a flushed publisher can still be read
+func TestFlushPublisherAuth_KeepsPublisher(t *testing.T) {
+	client, mr := setupTestRedisForPublisher(t)
+	defer mr.Close()
+
+	handler := NewPublisherAdminHandler(client)
+
+	bodyBytes, _ := json.Marshal(PublisherRequest{ID: "newpub", AllowedDomains: "example.com"})
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/publishers", bytes.NewReader(bodyBytes)))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/publishers/newpub/flush-auth", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/publishers/newpub", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected flushed publisher to still be readable, got %d: %s", w.Code, w.Body.String())
+	}
+	var got Publisher
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if got.ID != "newpub" || got.AllowedDomains != "example.com" {
+		t.Errorf("Unexpected publisher after flush: %+v", got)
+	}
+}
+
+// mockPublisherArchive holds database rows by publisher ID
+type mockPublisherArchive struct {
+	rows map[string]*storage.Publisher
+}
+
+func (m *mockPublisherArchive) ListPage(ctx context.Context, opts storage.ListOptions) ([]*storage.Publisher, int, error) {
+	var page []*storage.Publisher
+	for _, id := range []string{"pub-1", "pub-2", "pub-3", "pub-4"} {
+		if p := m.rows[id]; p != nil && p.Status == opts.Status {
+			page = append(page, p)
+		}
+	}
+	return page, len(page), nil
+}
+
+func (m *mockPublisherArchive) Delete(ctx context.Context, publisherID string) error {
+	p := m.rows[publisherID]
+	if p == nil {
+		return fmt.Errorf("publisher %w: %s", storage.ErrNotFound, publisherID)
+	}
+	p.Status = "archived"
+	return nil
+}
+
+func (m *mockPublisherArchive) Restore(ctx context.Context, publisherID string, check func(*storage.Publisher) error) (*storage.Publisher, error) {
+	p := m.rows[publisherID]
+	if p == nil {
+		return nil, fmt.Errorf("publisher %w: %s", storage.ErrNotFound, publisherID)
+	}
+	if p.Status != "archived" {
+		return nil, fmt.Errorf("%w: publisher %s is %s, not archived", storage.ErrConflict, publisherID, p.Status)
+	}
+	if err := check(p); err != nil {
+		return nil, err
+	}
+	p.Status = "active"
+	p.Version++
+	return p, nil
+}
+
+func TestPublisherAdmin_ArchiveAndRestore(t *testing.T) {
+	client, mr := setupTestRedisForPublisher(t)
+	defer mr.Close()
+	mr.HSet(publishersHashKey, "pub-1", "example.com")
+	mr.HSet(publishersHashKey, "pub-2", "other.com")
+
+	archive := &mockPublisherArchive{rows: map[string]*storage.Publisher{
+		"pub-1": {PublisherID: "pub-1", AllowedDomains: "example.com", Status: "active", Version: 1},
+		"pub-3": {PublisherID: "pub-3", AllowedDomains: "", Status: "archived", Version: 2},
+		"pub-4": {PublisherID: "pub-4", AllowedDomains: "four.com", Status: "active", Version: 1},
+	}}
+	var invalidated []string
+	cacheAdmin := NewCacheAdminHandler()
+	cacheAdmin.RegisterInvalidator("publisher", CacheInvalidatorFunc(func(ids ...string) int {
+		invalidated = append(invalidated, ids...)
+		return len(ids)
+	}))
+	handler := NewPublisherAdminHandler(client)
+	handler.SetArchiveStore(archive)
+	handler.SetInvalidator(cacheAdmin)
+
+	send := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w
+	}
+
+	// Deleting archives the database row too; a Redis-only publisher is
+	// just removed
+	var deleted map[string]interface{}
+	w := send(http.MethodDelete, "/admin/publishers/pub-1")
+	json.NewDecoder(w.Body).Decode(&deleted)
+	if w.Code != http.StatusOK || deleted["archived"] != true || archive.rows["pub-1"].Status != "archived" {
+		t.Fatalf("Expected pub-1 archived, got %d %v", w.Code, deleted)
+	}
+	deleted = nil
+	w = send(http.MethodDelete, "/admin/publishers/pub-2")
+	json.NewDecoder(w.Body).Decode(&deleted)
+	if w.Code != http.StatusOK || deleted["archived"] != false {
+		t.Errorf("Expected pub-2 deleted without archiving, got %d %v", w.Code, deleted)
+	}
+	if w := send(http.MethodDelete, "/admin/publishers/missing"); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown publisher, got %d", w.Code)
+	}
+
+	w = send(http.MethodGet, "/admin/publishers?status=archived")
+	var list PublisherRecordListResponse
+	if err := json.NewDecoder(w.Body).Decode(&list); err != nil || list.Total != 2 || list.Publishers[0].PublisherID != "pub-1" {
+		t.Fatalf("Expected pub-1 and pub-3 archived, got %d %+v", w.Code, list)
+	}
+
+	w = send(http.MethodPost, "/admin/publishers/pub-1/restore")
+	var restored Publisher
+	json.NewDecoder(w.Body).Decode(&restored)
+	if w.Code != http.StatusOK || restored.AllowedDomains != "example.com" {
+		t.Fatalf("Expected pub-1 restored, got %d %s", w.Code, w.Body.String())
+	}
+	if mr.HGet(publishersHashKey, "pub-1") != "example.com" || archive.rows["pub-1"].Status != "active" {
+		t.Error("Expected pub-1 active and back in Redis")
+	}
+	if len(invalidated) != 1 || invalidated[0] != "pub-1" {
+		t.Errorf("Expected pub-1 invalidated, got %v", invalidated)
+	}
+
+	// pub-4 was re-created in Redis after being archived
+	archive.rows["pub-4"].Status = "archived"
+	mr.HSet(publishersHashKey, "pub-4", "new.four.com")
+
+	tests := []struct {
+		path string
+		want int
+		code string
+	}{
+		{"/admin/publishers/pub-1/restore", http.StatusConflict, "conflict"},
+		{"/admin/publishers/pub-3/restore", http.StatusUnprocessableEntity, "invalid_configuration"},
+		{"/admin/publishers/pub-4/restore", http.StatusConflict, "already_exists"},
+		{"/admin/publishers/missing/restore", http.StatusNotFound, "not_found"},
+	}
+	for _, tt := range tests {
+		if tt.path == "/admin/publishers/pub-1/restore" {
+			mr.HDel(publishersHashKey, "pub-1")
+		}
+		w := send(http.MethodPost, tt.path)
+		var resp ErrorResponse
+		json.NewDecoder(w.Body).Decode(&resp)
+		if w.Code != tt.want || resp.Error != tt.code {
+			t.Errorf("%s: expected %d %s, got %d %+v", tt.path, tt.want, tt.code, w.Code, resp)
+		}
+	}
+	if archive.rows["pub-3"].Status != "archived" || mr.HGet(publishersHashKey, "pub-4") != "new.four.com" {
+		t.Error("Expected rejected restores to change nothing")
+	}
+
+	if w := send(http.MethodGet, "/admin/publishers/pub-3/restore"); w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status 405 for GET, got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	NewPublisherAdminHandler(client).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/publishers/pub-3/restore", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503 without an archive store, got %d", w.Code)
+	}
+}
@@ -0,0 +1,124 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+// BillingStore reads and writes publishers' payment terms and invoice
+// contacts; implemented by storage.PublisherStore
+type BillingStore interface {
+	GetBilling(ctx context.Context, publisherID string) (*storage.Billing, error)
+	UpdateBilling(ctx context.Context, publisherID string, b storage.Billing) (*storage.Billing, error)
+}
+
+// BillingLister returns every publisher's billing details; implemented by
+// storage.PublisherStore
+type BillingLister interface {
+	ListBilling(ctx context.Context) (map[string]storage.Billing, error)
+}
+
+// PublisherBillingResponse is a publisher's billing details
+type PublisherBillingResponse struct {
+	PublisherID string `json:"publisher_id"`
+	storage.Billing
+}
+
+// billingSuffix is the path suffix for a publisher's billing details
+const billingSuffix = "/billing"
+
+// IsBillingPath reports whether a /admin/publishers/{id}/... path is the
+// billing route
+func IsBillingPath(path string) bool {
+	return strings.HasSuffix(path, billingSuffix)
+}
+
+// PublisherBillingHandler serves publishers' payment terms and invoice
+// contacts, which live in the database rather than Redis
+type PublisherBillingHandler struct {
+	store BillingStore
+}
+
+// NewPublisherBillingHandler creates a billing handler; store may be nil
+// when no database is configured
+func NewPublisherBillingHandler(store BillingStore) *PublisherBillingHandler {
+	return &PublisherBillingHandler{store: store}
+}
+
+// ServeHTTP handles billing requests
+// Routes:
+//
+//	GET /admin/publishers/:id/billing - Get payment terms and invoice contact
+//	PUT /admin/publishers/:id/billing - Replace them; unset payment_terms and
+//	                                    billing_currency default to net-30 and USD
+func (h *PublisherBillingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.store == nil {
+		sendAdminError(w, http.StatusServiceUnavailable, "database_unavailable", "Publisher billing requires a database connection")
+		return
+	}
+
+	id := strings.TrimSuffix(strings.Trim(strings.TrimPrefix(r.URL.Path, "/admin/publishers"), "/"), billingSuffix)
+	if id == "" || strings.Contains(id, "/") {
+		sendAdminError(w, http.StatusNotFound, "not_found", "Unknown billing route")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.get(w, r, id)
+	case http.MethodPut:
+		h.update(w, r, id)
+	default:
+		sendAdminError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+	}
+}
+
+// get returns a publisher's billing details
+func (h *PublisherBillingHandler) get(w http.ResponseWriter, r *http.Request, id string) {
+	b, err := h.store.GetBilling(r.Context(), id)
+	if err != nil {
+		if !sendStorageError(w, err) {
+			logger.Log.Error().Err(err).Str("publisher_id", id).Msg("Failed to load publisher billing")
+			sendAdminError(w, http.StatusInternalServerError, "database_error", "Failed to retrieve billing")
+		}
+		return
+	}
+	sendAdminJSON(w, http.StatusOK, PublisherBillingResponse{PublisherID: id, Billing: *b})
+}
+
+// update replaces a publisher's billing details
+func (h *PublisherBillingHandler) update(w http.ResponseWriter, r *http.Request, id string) {
+	var req storage.Billing
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		sendAdminError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON in request body")
+		return
+	}
+	if err := req.Validate(); err != nil {
+		sendAdminError(w, http.StatusBadRequest, "invalid_billing", err.Error())
+		return
+	}
+
+	b, err := h.store.UpdateBilling(r.Context(), id, req)
+	if err != nil {
+		if !sendStorageError(w, err) {
+			logger.Log.Error().Err(err).Str("publisher_id", id).Msg("Failed to update publisher billing")
+			sendAdminError(w, http.StatusInternalServerError, "database_error", "Failed to update billing")
+		}
+		return
+	}
+
+	logger.Log.Info().
+		Str("publisher_id", id).
+		Str("payment_terms", b.PaymentTerms).
+		Str("billing_currency", b.BillingCurrency).
+		Msg("Publisher billing updated")
+
+	sendAdminJSON(w, http.StatusOK, PublisherBillingResponse{PublisherID: id, Billing: *b})
+}
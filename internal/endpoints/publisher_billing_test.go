@@ -0,0 +1,103 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+)
+
+type mockBillingStore struct {
+	billing map[string]storage.Billing
+	err     error
+}
+
+func (m *mockBillingStore) ListBilling(context.Context) (map[string]storage.Billing, error) {
+	return m.billing, m.err
+}
+
+func (m *mockBillingStore) GetBilling(_ context.Context, publisherID string) (*storage.Billing, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	b, ok := m.billing[publisherID]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	return &b, nil
+}
+
+func (m *mockBillingStore) UpdateBilling(_ context.Context, publisherID string, b storage.Billing) (*storage.Billing, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	if _, ok := m.billing[publisherID]; !ok {
+		return nil, storage.ErrNotFound
+	}
+	if b.PaymentTerms == "" {
+		b.PaymentTerms = storage.PaymentTermsNet30
+	}
+	if b.BillingCurrency == "" {
+		b.BillingCurrency = "USD"
+	}
+	m.billing[publisherID] = b
+	return &b, nil
+}
+
+func TestPublisherBillingHandler(t *testing.T) {
+	store := &mockBillingStore{billing: map[string]storage.Billing{
+		"pub-1": {PaymentTerms: storage.PaymentTermsNet30, BillingCurrency: "USD"},
+	}}
+	admin := NewPublisherAdminHandler(nil)
+	admin.SetBillingHandler(NewPublisherBillingHandler(store))
+
+	w := httptest.NewRecorder()
+	body := `{"payment_terms":"net-60","billing_currency":"EUR","invoice_contact_name":"AP","invoice_contact_email":"ap@pub1.example"}`
+	admin.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/admin/publishers/pub-1/billing", strings.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	admin.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/publishers/pub-1/billing", nil))
+	var resp PublisherBillingResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if resp.PublisherID != "pub-1" || resp.PaymentTerms != storage.PaymentTermsNet60 || resp.BillingCurrency != "EUR" || resp.InvoiceContactEmail != "ap@pub1.example" {
+		t.Errorf("unexpected billing: %+v", resp)
+	}
+}
+
+func TestPublisherBillingHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		store  BillingStore
+		method string
+		target string
+		body   string
+		status int
+	}{
+		{"no database", nil, http.MethodGet, "/admin/publishers/pub-1/billing", "", http.StatusServiceUnavailable},
+		{"unknown publisher", &mockBillingStore{}, http.MethodGet, "/admin/publishers/pub-2/billing", "", http.StatusNotFound},
+		{"update unknown publisher", &mockBillingStore{}, http.MethodPut, "/admin/publishers/pub-2/billing", `{}`, http.StatusNotFound},
+		{"wrong method", &mockBillingStore{}, http.MethodDelete, "/admin/publishers/pub-1/billing", "", http.StatusMethodNotAllowed},
+		{"invalid terms", &mockBillingStore{}, http.MethodPut, "/admin/publishers/pub-1/billing", `{"payment_terms":"net-90"}`, http.StatusBadRequest},
+		{"unknown field", &mockBillingStore{}, http.MethodPut, "/admin/publishers/pub-1/billing", `{"terms":"net-30"}`, http.StatusBadRequest},
+		{"store error", &mockBillingStore{err: errors.New("connection refused")}, http.MethodGet, "/admin/publishers/pub-1/billing", "", http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			NewPublisherBillingHandler(tt.store).ServeHTTP(w, httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body)))
+			if w.Code != tt.status {
+				t.Errorf("expected %d, got %d: %s", tt.status, w.Code, w.Body.String())
+			}
+		})
+	}
+}
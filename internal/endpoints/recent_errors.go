@@ -0,0 +1,68 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/rs/zerolog"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+// defaultRecentErrorsLimit applies when GET /admin/errors omits limit
+const defaultRecentErrorsLimit = 50
+
+// RecentErrorsResponse lists this replica's latest warnings and errors
+type RecentErrorsResponse struct {
+	Entries []json.RawMessage `json:"entries"` // Log lines as written, newest first
+	Count   int               `json:"count"`
+}
+
+// RecentErrorsHandler serves the warnings and errors this replica logged
+// most recently, kept in memory by the logger, for the admin UI
+type RecentErrorsHandler struct{}
+
+// NewRecentErrorsHandler creates a new recent errors handler
+func NewRecentErrorsHandler() *RecentErrorsHandler {
+	return &RecentErrorsHandler{}
+}
+
+// ServeHTTP handles recent error requests
+// Routes:
+//
+//	GET /admin/errors?limit=50&level=warn|error
+//
+// limit is at most logger.RecentCapacity; level defaults to warn.
+func (h *RecentErrorsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		sendAdminError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+		return
+	}
+
+	q := r.URL.Query()
+	limit := defaultRecentErrorsLimit
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > logger.RecentCapacity {
+			sendAdminError(w, http.StatusBadRequest, "invalid_limit", "limit must be between 1 and "+strconv.Itoa(logger.RecentCapacity))
+			return
+		}
+		limit = n
+	}
+	level := zerolog.WarnLevel
+	switch v := q.Get("level"); v {
+	case "", "warn":
+	case "error":
+		level = zerolog.ErrorLevel
+	default:
+		sendAdminError(w, http.StatusBadRequest, "invalid_level", "level must be warn or error")
+		return
+	}
+
+	recent := logger.Recent(level, limit)
+	resp := RecentErrorsResponse{Entries: make([]json.RawMessage, 0, len(recent)), Count: len(recent)}
+	for _, e := range recent {
+		resp.Entries = append(resp.Entries, e.Entry)
+	}
+	sendAdminJSON(w, http.StatusOK, resp)
+}
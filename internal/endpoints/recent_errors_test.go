@@ -0,0 +1,74 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+func TestRecentErrorsHandler(t *testing.T) {
+	saved := logger.Log
+	t.Cleanup(func() { logger.Log = saved })
+	logger.Init(logger.Config{Level: "info", Format: "json"})
+
+	logger.Log.Warn().Str("bidder", "recent-test").Msg("recent errors test warning")
+	logger.Log.Error().Str("bidder", "recent-test").Msg("recent errors test error")
+
+	h := NewRecentErrorsHandler()
+	tests := []struct {
+		query  string
+		expect []string
+	}{
+		{"?limit=2", []string{"recent errors test error", "recent errors test warning"}},
+		{"?limit=1&level=error", []string{"recent errors test error"}},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/errors"+tt.query, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d: %s", tt.query, w.Code, w.Body.String())
+		}
+		var resp RecentErrorsResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("invalid response: %v", err)
+		}
+		var got []string
+		for _, e := range resp.Entries {
+			var entry struct {
+				Message string `json:"message"`
+			}
+			if err := json.Unmarshal(e, &entry); err != nil {
+				t.Fatalf("entry is not JSON: %s", e)
+			}
+			got = append(got, entry.Message)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.expect, ",") || resp.Count != len(tt.expect) {
+			t.Errorf("%s: expected %v, got %v", tt.query, tt.expect, got)
+		}
+	}
+}
+
+func TestRecentErrorsHandler_Errors(t *testing.T) {
+	h := NewRecentErrorsHandler()
+	tests := []struct {
+		method string
+		query  string
+		status int
+	}{
+		{http.MethodPost, "", http.StatusMethodNotAllowed},
+		{http.MethodGet, "?limit=0", http.StatusBadRequest},
+		{http.MethodGet, "?limit=100000", http.StatusBadRequest},
+		{http.MethodGet, "?level=info", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(tt.method, "/admin/errors"+tt.query, nil))
+		if w.Code != tt.status {
+			t.Errorf("%s %s: expected %d, got %d", tt.method, tt.query, tt.status, w.Code)
+		}
+	}
+}
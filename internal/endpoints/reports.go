@@ -0,0 +1,268 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/csv"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/slo"
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+// maxReportRange bounds one report query; the rollup keeps 13 months
+const maxReportRange = 400 * 24 * time.Hour
+
+// RollupReader queries the hourly metrics rollup; implemented by
+// storage.RollupStore
+type RollupReader interface {
+	QueryHourly(ctx context.Context, from, to time.Time, publisherID string) ([]*storage.HourlyMetrics, error)
+}
+
+// SLOReporter reports publishers' latency SLO status; implemented by
+// slo.Tracker
+type SLOReporter interface {
+	Status(publisherID string) (slo.PublisherStatus, bool)
+	Statuses() []slo.PublisherStatus
+}
+
+// PublisherTotals sums a publisher's hourly rows over a report's range
+type PublisherTotals struct {
+	PublisherID string  `json:"publisher_id"`
+	Requests    int64   `json:"requests"`
+	Bids        int64   `json:"bids"`
+	Wins        int64   `json:"wins"`
+	Impressions int64   `json:"impressions"`
+	FillRate    float64 `json:"fill_rate"` // wins / requests
+	Revenue     float64 `json:"revenue"`
+	Payout      float64 `json:"payout"`
+	Margin      float64 `json:"margin"`
+	// Billing is the publisher's payment terms and invoice contact, when
+	// the publisher is in the database
+	Billing *storage.Billing `json:"billing,omitempty"`
+}
+
+// HourlyReportResponse is the JSON form of /admin/reports/hourly
+type HourlyReportResponse struct {
+	From       time.Time                `json:"from"`
+	To         time.Time                `json:"to"`
+	Rows       []*storage.HourlyMetrics `json:"rows"`
+	Publishers []*PublisherTotals       `json:"publishers"`
+	// SLO is each publisher's current latency SLO status, worst burn first.
+	// It covers the last six hours whatever the report's range.
+	SLO []slo.PublisherStatus `json:"slo,omitempty"`
+}
+
+// ReportsHandler serves the long-term business metrics kept in Postgres
+type ReportsHandler struct {
+	store   RollupReader
+	slo     SLOReporter
+	billing BillingLister
+	now     func() time.Time
+}
+
+// NewReportsHandler creates a reports handler; store may be nil when no
+// database is configured
+func NewReportsHandler(store RollupReader) *ReportsHandler {
+	return &ReportsHandler{store: store, now: time.Now}
+}
+
+// SetSLOReporter adds publishers' latency SLO status to JSON reports
+func (h *ReportsHandler) SetSLOReporter(r SLOReporter) {
+	h.slo = r
+}
+
+// SetBillingLister adds publishers' payment terms and invoice contacts to
+// reports, so finance can pay from the export alone
+func (h *ReportsHandler) SetBillingLister(l BillingLister) {
+	h.billing = l
+}
+
+// ServeHTTP handles report requests
+// Routes:
+//
+//	GET /admin/reports/hourly?from=&to=&publisher_id=&format=json|csv
+//
+// from and to are RFC 3339 times or YYYY-MM-DD dates; to is exclusive and
+// defaults to now, from defaults to 24 hours before to.
+func (h *ReportsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		sendAdminError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+		return
+	}
+	if h.store == nil {
+		sendAdminError(w, http.StatusServiceUnavailable, "database_unavailable", "Reports require a database connection")
+		return
+	}
+
+	q := r.URL.Query()
+	from, to, ok := reportRange(w, q, h.now())
+	if !ok {
+		return
+	}
+
+	rows, err := h.store.QueryHourly(r.Context(), from, to, q.Get("publisher_id"))
+	if err != nil {
+		logger.Log.Error().Err(err).Msg("Failed to query metrics rollup")
+		sendAdminError(w, http.StatusInternalServerError, "query_failed", "Failed to query reports")
+		return
+	}
+	if rows == nil {
+		rows = []*storage.HourlyMetrics{}
+	}
+
+	billing := h.publisherBilling(r.Context())
+	if q.Get("format") == "csv" {
+		writeReportCSV(w, rows, billing)
+		return
+	}
+	totals := publisherTotals(rows)
+	for _, t := range totals {
+		if b, ok := billing[t.PublisherID]; ok {
+			t.Billing = &b
+		}
+	}
+	sendAdminJSON(w, http.StatusOK, HourlyReportResponse{
+		From:       from,
+		To:         to,
+		Rows:       rows,
+		Publishers: totals,
+		SLO:        h.sloStatuses(q.Get("publisher_id")),
+	})
+}
+
+// publisherBilling returns billing details by publisher ID. A failed lookup
+// is logged and the report served without them.
+func (h *ReportsHandler) publisherBilling(ctx context.Context) map[string]storage.Billing {
+	if h.billing == nil {
+		return nil
+	}
+	billing, err := h.billing.ListBilling(ctx)
+	if err != nil {
+		logger.Log.Warn().Err(err).Msg("Failed to load publisher billing for report")
+		return nil
+	}
+	return billing
+}
+
+// sloStatuses returns the SLO status of one publisher, or of all tracked
+// publishers when publisherID is empty
+func (h *ReportsHandler) sloStatuses(publisherID string) []slo.PublisherStatus {
+	if h.slo == nil {
+		return nil
+	}
+	if publisherID == "" {
+		return h.slo.Statuses()
+	}
+	if status, ok := h.slo.Status(publisherID); ok {
+		return []slo.PublisherStatus{status}
+	}
+	return nil
+}
+
+// reportRange parses a report's from and to parameters, writing a 400 and
+// returning false when they are invalid. to defaults to now and from to 24
+// hours before to.
+func reportRange(w http.ResponseWriter, q url.Values, now time.Time) (from, to time.Time, ok bool) {
+	to = now.UTC()
+	if v := q.Get("to"); v != "" {
+		t, ok := parseReportTime(v)
+		if !ok {
+			sendAdminError(w, http.StatusBadRequest, "invalid_to", "to must be an RFC 3339 time or YYYY-MM-DD date")
+			return from, to, false
+		}
+		to = t
+	}
+	from = to.Add(-24 * time.Hour)
+	if v := q.Get("from"); v != "" {
+		t, ok := parseReportTime(v)
+		if !ok {
+			sendAdminError(w, http.StatusBadRequest, "invalid_from", "from must be an RFC 3339 time or YYYY-MM-DD date")
+			return from, to, false
+		}
+		from = t
+	}
+	if !from.Before(to) || to.Sub(from) > maxReportRange {
+		sendAdminError(w, http.StatusBadRequest, "invalid_range", "from must be before to and the range at most 400 days")
+		return from, to, false
+	}
+	return from, to, true
+}
+
+// parseReportTime accepts RFC 3339 times and dates, both as UTC
+func parseReportTime(v string) (time.Time, bool) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t.UTC(), true
+	}
+	if t, err := time.Parse(time.DateOnly, v); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+// publisherTotals sums rows per publisher, in the rows' publisher order
+func publisherTotals(rows []*storage.HourlyMetrics) []*PublisherTotals {
+	byPublisher := make(map[string]*PublisherTotals)
+	var totals []*PublisherTotals
+	for _, row := range rows {
+		t, ok := byPublisher[row.PublisherID]
+		if !ok {
+			t = &PublisherTotals{PublisherID: row.PublisherID}
+			byPublisher[row.PublisherID] = t
+			totals = append(totals, t)
+		}
+		t.Requests += row.Requests
+		t.Bids += row.Bids
+		t.Wins += row.Wins
+		t.Impressions += row.Impressions
+		t.Revenue += row.Revenue
+		t.Payout += row.Payout
+		t.Margin += row.Margin
+	}
+	for _, t := range totals {
+		if t.Requests > 0 {
+			t.FillRate = float64(t.Wins) / float64(t.Requests)
+		}
+	}
+	return totals
+}
+
+// writeReportCSV writes one line per hourly row for finance exports, with
+// the publisher's billing details (empty when unknown)
+func writeReportCSV(w http.ResponseWriter, rows []*storage.HourlyMetrics, billing map[string]storage.Billing) {
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="metrics_hourly.csv"`)
+	w.WriteHeader(http.StatusOK)
+
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"hour", "publisher_id", "bidder", "media_type", "advertiser", "requests", "bids", "wins", "impressions", "revenue", "payout", "margin",
+		"payment_terms", "billing_currency", "invoice_contact_name", "invoice_contact_email"})
+	for _, row := range rows {
+		b := billing[row.PublisherID]
+		_ = cw.Write([]string{
+			row.Hour.UTC().Format(time.RFC3339),
+			row.PublisherID,
+			row.Bidder,
+			row.MediaType,
+			row.Advertiser,
+			strconv.FormatInt(row.Requests, 10),
+			strconv.FormatInt(row.Bids, 10),
+			strconv.FormatInt(row.Wins, 10),
+			strconv.FormatInt(row.Impressions, 10),
+			strconv.FormatFloat(row.Revenue, 'f', 6, 64),
+			strconv.FormatFloat(row.Payout, 'f', 6, 64),
+			strconv.FormatFloat(row.Margin, 'f', 6, 64),
+			b.PaymentTerms,
+			b.BillingCurrency,
+			b.InvoiceContactName,
+			b.InvoiceContactEmail,
+		})
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		logger.Log.Error().Err(err).Msg("Failed to write report CSV")
+	}
+}
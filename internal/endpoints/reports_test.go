@@ -0,0 +1,151 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/slo"
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+)
+
+type mockRollupReader struct {
+	rows        []*storage.HourlyMetrics
+	err         error
+	from, to    time.Time
+	publisherID string
+}
+
+func (m *mockRollupReader) QueryHourly(_ context.Context, from, to time.Time, publisherID string) ([]*storage.HourlyMetrics, error) {
+	m.from, m.to, m.publisherID = from, to, publisherID
+	return m.rows, m.err
+}
+
+func TestReportsHandler_JSON(t *testing.T) {
+	hour := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	store := &mockRollupReader{rows: []*storage.HourlyMetrics{
+		{Hour: hour, PublisherID: "pub-1", MediaType: "video", Requests: 100},
+		{Hour: hour, PublisherID: "pub-1", Bidder: "appnexus", MediaType: "video", Bids: 60, Wins: 25, Impressions: 20, Revenue: 0.1, Payout: 0.08, Margin: 0.02},
+	}}
+	h := NewReportsHandler(store)
+	h.SetBillingLister(&mockBillingStore{billing: map[string]storage.Billing{
+		"pub-1": {PaymentTerms: storage.PaymentTermsNet60, BillingCurrency: "EUR", InvoiceContactEmail: "ap@pub1.example"},
+	}})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/reports/hourly?from=2026-03-01&to=2026-03-02&publisher_id=pub-1", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if !store.from.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)) || !store.to.Equal(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)) || store.publisherID != "pub-1" {
+		t.Errorf("unexpected query: %v %v %q", store.from, store.to, store.publisherID)
+	}
+
+	var resp HourlyReportResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if len(resp.Rows) != 2 || len(resp.Publishers) != 1 {
+		t.Fatalf("expected 2 rows and 1 publisher, got %+v", resp)
+	}
+	totals := resp.Publishers[0]
+	if totals.Requests != 100 || totals.Wins != 25 || totals.FillRate != 0.25 || totals.Margin != 0.02 {
+		t.Errorf("unexpected publisher totals: %+v", totals)
+	}
+	if totals.Billing == nil || totals.Billing.PaymentTerms != storage.PaymentTermsNet60 || totals.Billing.BillingCurrency != "EUR" {
+		t.Errorf("expected net-60 EUR billing, got %+v", totals.Billing)
+	}
+}
+
+func TestReportsHandler_SLO(t *testing.T) {
+	tracker := slo.NewTracker(slo.Config{DefaultTarget: 200 * time.Millisecond}, nil)
+	for i := 0; i < 10; i++ {
+		tracker.Record("pub-1", 0, 50*time.Millisecond)
+		tracker.Record("pub-2", 0, time.Second)
+	}
+	h := NewReportsHandler(&mockRollupReader{})
+	h.SetSLOReporter(tracker)
+
+	tests := []struct {
+		query  string
+		expect []string
+	}{
+		{"", []string{"pub-2/" + slo.StatusBreaching, "pub-1/" + slo.StatusOK}},
+		{"?publisher_id=pub-1", []string{"pub-1/" + slo.StatusOK}},
+		{"?publisher_id=pub-3", nil},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/reports/hourly"+tt.query, nil))
+		var resp HourlyReportResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("invalid response: %v", err)
+		}
+		var got []string
+		for _, s := range resp.SLO {
+			got = append(got, s.PublisherID+"/"+s.Status)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.expect, ",") {
+			t.Errorf("%q: expected SLO %v, got %v", tt.query, tt.expect, got)
+		}
+	}
+}
+
+func TestReportsHandler_CSV(t *testing.T) {
+	hour := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	store := &mockRollupReader{rows: []*storage.HourlyMetrics{
+		{Hour: hour, PublisherID: "pub-1", Bidder: "appnexus", MediaType: "video", Advertiser: "Acme Motors", Wins: 25, Revenue: 0.1, Payout: 0.08, Margin: 0.02},
+		{Hour: hour, PublisherID: "pub-2", Bidder: "appnexus", MediaType: "banner", Wins: 1},
+	}}
+	h := NewReportsHandler(store)
+	h.SetBillingLister(&mockBillingStore{billing: map[string]storage.Billing{
+		"pub-1": {PaymentTerms: storage.PaymentTermsNet30, BillingCurrency: "USD", InvoiceContactName: "Pub One AP", InvoiceContactEmail: "ap@pub1.example"},
+	}})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/reports/hourly?format=csv", nil))
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "text/csv" {
+		t.Fatalf("expected CSV, got %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "hour,publisher_id,bidder") || !strings.HasSuffix(lines[0], ",payment_terms,billing_currency,invoice_contact_name,invoice_contact_email") {
+		t.Fatalf("unexpected CSV: %q", w.Body.String())
+	}
+	if lines[1] != "2026-03-01T10:00:00Z,pub-1,appnexus,video,Acme Motors,0,0,25,0,0.100000,0.080000,0.020000,net-30,USD,Pub One AP,ap@pub1.example" {
+		t.Errorf("unexpected CSV row: %q", lines[1])
+	}
+	if !strings.HasSuffix(lines[2], ",0.000000,,,,") {
+		t.Errorf("expected empty billing for a publisher without details, got %q", lines[2])
+	}
+}
+
+func TestReportsHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		store  RollupReader
+		method string
+		target string
+		status int
+	}{
+		{"no database", nil, http.MethodGet, "/admin/reports/hourly", http.StatusServiceUnavailable},
+		{"wrong method", &mockRollupReader{}, http.MethodPost, "/admin/reports/hourly", http.StatusMethodNotAllowed},
+		{"bad from", &mockRollupReader{}, http.MethodGet, "/admin/reports/hourly?from=yesterday", http.StatusBadRequest},
+		{"reversed range", &mockRollupReader{}, http.MethodGet, "/admin/reports/hourly?from=2026-03-02&to=2026-03-01", http.StatusBadRequest},
+		{"range too long", &mockRollupReader{}, http.MethodGet, "/admin/reports/hourly?from=2024-01-01&to=2026-03-01", http.StatusBadRequest},
+		{"store error", &mockRollupReader{err: errors.New("connection refused")}, http.MethodGet, "/admin/reports/hourly", http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			NewReportsHandler(tt.store).ServeHTTP(w, httptest.NewRequest(tt.method, tt.target, nil))
+			if w.Code != tt.status {
+				t.Errorf("expected %d, got %d", tt.status, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package endpoints
+
+import (
+	"net/http"
+
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+// StandbyHandler reports and ends warm standby during blue/green deploys
+type StandbyHandler struct {
+	standby *middleware.Standby
+}
+
+// NewStandbyHandler creates a standby admin handler
+func NewStandbyHandler(standby *middleware.Standby) *StandbyHandler {
+	return &StandbyHandler{standby: standby}
+}
+
+// ServeHTTP handles standby requests
+// Routes:
+//
+//	GET  /admin/standby          - current mode and shadow requests served
+//	POST /admin/standby/activate - start taking live traffic
+func (h *StandbyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == "/admin/standby" && r.Method == http.MethodGet:
+		sendAdminJSON(w, http.StatusOK, h.standby.Status())
+	case r.URL.Path == "/admin/standby/activate" && r.Method == http.MethodPost:
+		if h.standby.Activate() {
+			status := h.standby.Status()
+			logger.Log.Info().
+				Int64("shadow_requests", status.ShadowRequests).
+				Dur("standby_for", status.ActivatedAt.Sub(status.StartedAt)).
+				Msg("Standby ended, taking live traffic")
+		}
+		sendAdminJSON(w, http.StatusOK, h.standby.Status())
+	case r.URL.Path == "/admin/standby" || r.URL.Path == "/admin/standby/activate":
+		sendAdminError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+	default:
+		sendAdminError(w, http.StatusNotFound, "not_found", "Unknown standby admin route")
+	}
+}
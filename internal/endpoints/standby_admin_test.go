@@ -0,0 +1,54 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+)
+
+func TestStandbyHandler_Activate(t *testing.T) {
+	standby := middleware.NewStandby(true)
+	h := NewStandbyHandler(standby)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/standby", nil))
+	var status middleware.StandbyStatus
+	if err := json.Unmarshal(w.Body.Bytes(), &status); err != nil || status.Mode != "standby" {
+		t.Fatalf("expected standby mode, got %s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/standby/activate", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &status); err != nil || status.Mode != "active" {
+		t.Errorf("expected active mode, got %s", w.Body.String())
+	}
+	if !standby.Active() {
+		t.Error("expected the instance to be active")
+	}
+}
+
+func TestStandbyHandler_Errors(t *testing.T) {
+	h := NewStandbyHandler(middleware.NewStandby(true))
+
+	tests := []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodGet, "/admin/standby/activate", http.StatusMethodNotAllowed},
+		{http.MethodDelete, "/admin/standby", http.StatusMethodNotAllowed},
+		{http.MethodPost, "/admin/standby/other", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+		if w.Code != tt.want {
+			t.Errorf("%s %s: expected %d, got %d", tt.method, tt.path, tt.want, w.Code)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+// storedRequestAdminPrefix is the path prefix for stored request templates
+const storedRequestAdminPrefix = "/admin/api/stored-requests/"
+
+// StoredRequestInvalidationScope is the cache invalidation scope that tells
+// replicas to drop a cached template; IDs are "kind:id"
+const StoredRequestInvalidationScope = "stored_request"
+
+// maxStoredRequestIDLength matches stored_requests.id
+const maxStoredRequestIDLength = 255
+
+// StoredRequestEditor reads and writes templates, invalidating cached copies;
+// implemented by storedrequest.Resolver
+type StoredRequestEditor interface {
+	Get(ctx context.Context, kind, id string) (*storage.StoredRequest, error)
+	Put(ctx context.Context, sr *storage.StoredRequest) error
+	Delete(ctx context.Context, kind, id string) error
+}
+
+// StoredRequestsAdminHandler manages stored request and stored imp templates
+type StoredRequestsAdminHandler struct {
+	editor      StoredRequestEditor
+	invalidator Invalidator
+}
+
+// NewStoredRequestsAdminHandler creates a stored request admin handler; editor
+// may be nil when no database is configured
+func NewStoredRequestsAdminHandler(editor StoredRequestEditor) *StoredRequestsAdminHandler {
+	return &StoredRequestsAdminHandler{editor: editor}
+}
+
+// SetInvalidator drops an edited template from every replica's cache
+func (h *StoredRequestsAdminHandler) SetInvalidator(invalidator Invalidator) {
+	h.invalidator = invalidator
+}
+
+// broadcast tells other replicas to drop their cached copy of a template
+func (h *StoredRequestsAdminHandler) broadcast(ctx context.Context, kind, id string) {
+	if h.invalidator == nil {
+		return
+	}
+	req := CacheInvalidateRequest{Scope: StoredRequestInvalidationScope, IDs: []string{kind + ":" + id}}
+	if _, err := h.invalidator.Invalidate(ctx, req); err != nil {
+		logger.Log.Warn().Err(err).Str("kind", kind).Str("id", id).Msg("Stored request change not broadcast to other replicas")
+	}
+}
+
+// ServeHTTP handles stored request admin requests
+// Routes:
+//
+//	GET    /admin/api/stored-requests/{request|imp}/{id} - Get a template
+//	PUT    /admin/api/stored-requests/{request|imp}/{id} - Create or replace a template (body: the template; ?publisher_id restricts it)
+//	DELETE /admin/api/stored-requests/{request|imp}/{id} - Delete a template
+func (h *StoredRequestsAdminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.editor == nil {
+		sendAdminError(w, http.StatusServiceUnavailable, "database_unavailable", "Stored requests require a database connection")
+		return
+	}
+
+	kind, id, ok := strings.Cut(strings.TrimPrefix(r.URL.Path, storedRequestAdminPrefix), "/")
+	if !ok || (kind != storage.StoredKindRequest && kind != storage.StoredKindImp) ||
+		id == "" || strings.Contains(id, "/") || len(id) > maxStoredRequestIDLength {
+		sendAdminError(w, http.StatusNotFound, "not_found", "Expected /admin/api/stored-requests/{request|imp}/{id}")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.get(w, r, kind, id)
+	case http.MethodPut:
+		h.put(w, r, kind, id)
+	case http.MethodDelete:
+		h.delete(w, r, kind, id)
+	default:
+		sendAdminError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+	}
+}
+
+// get returns one template
+func (h *StoredRequestsAdminHandler) get(w http.ResponseWriter, r *http.Request, kind, id string) {
+	sr, err := h.editor.Get(r.Context(), kind, id)
+	if err != nil {
+		if !sendStorageError(w, err) {
+			logger.Log.Error().Err(err).Str("kind", kind).Str("id", id).Msg("Failed to get stored request")
+			sendAdminError(w, http.StatusInternalServerError, "database_error", "Failed to retrieve stored request")
+		}
+		return
+	}
+	sendAdminJSON(w, http.StatusOK, sr)
+}
+
+// put saves the request body as the template
+func (h *StoredRequestsAdminHandler) put(w http.ResponseWriter, r *http.Request, kind, id string) {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBodySize))
+	if err != nil {
+		sendAdminError(w, http.StatusBadRequest, "invalid_request", "Failed to read request body")
+		return
+	}
+	if !json.Valid(data) {
+		sendAdminError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON in request body")
+		return
+	}
+
+	sr := &storage.StoredRequest{
+		Kind:        kind,
+		ID:          id,
+		PublisherID: r.URL.Query().Get("publisher_id"),
+		Data:        data,
+	}
+	if err := h.editor.Put(r.Context(), sr); err != nil {
+		if !sendStorageError(w, err) {
+			logger.Log.Error().Err(err).Str("kind", kind).Str("id", id).Msg("Failed to save stored request")
+			sendAdminError(w, http.StatusInternalServerError, "database_error", "Failed to save stored request")
+		}
+		return
+	}
+	h.broadcast(r.Context(), kind, id)
+
+	logger.Log.Info().
+		Str("kind", kind).
+		Str("id", id).
+		Str("publisher_id", sr.PublisherID).
+		Msg("Stored request saved")
+	sendAdminJSON(w, http.StatusOK, sr)
+}
+
+// delete removes a template
+func (h *StoredRequestsAdminHandler) delete(w http.ResponseWriter, r *http.Request, kind, id string) {
+	if err := h.editor.Delete(r.Context(), kind, id); err != nil {
+		if !sendStorageError(w, err) {
+			logger.Log.Error().Err(err).Str("kind", kind).Str("id", id).Msg("Failed to delete stored request")
+			sendAdminError(w, http.StatusInternalServerError, "database_error", "Failed to delete stored request")
+		}
+		return
+	}
+	h.broadcast(r.Context(), kind, id)
+
+	logger.Log.Info().Str("kind", kind).Str("id", id).Msg("Stored request deleted")
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,139 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+)
+
+// mockStoredRequestEditor keeps templates by kind and ID
+type mockStoredRequestEditor struct {
+	templates map[string]*storage.StoredRequest
+}
+
+func (m *mockStoredRequestEditor) Get(_ context.Context, kind, id string) (*storage.StoredRequest, error) {
+	sr, ok := m.templates[kind+"/"+id]
+	if !ok {
+		return nil, fmt.Errorf("stored %s %w: %s", kind, storage.ErrNotFound, id)
+	}
+	return sr, nil
+}
+
+func (m *mockStoredRequestEditor) Put(_ context.Context, sr *storage.StoredRequest) error {
+	m.templates[sr.Kind+"/"+sr.ID] = sr
+	return nil
+}
+
+func (m *mockStoredRequestEditor) Delete(_ context.Context, kind, id string) error {
+	if _, ok := m.templates[kind+"/"+id]; !ok {
+		return fmt.Errorf("stored %s %w: %s", kind, storage.ErrNotFound, id)
+	}
+	delete(m.templates, kind+"/"+id)
+	return nil
+}
+
+func TestStoredRequestsAdminHandler_PutGetDelete(t *testing.T) {
+	editor := &mockStoredRequestEditor{templates: map[string]*storage.StoredRequest{}}
+	h := NewStoredRequestsAdminHandler(editor)
+	path := "/admin/api/stored-requests/imp/mrec"
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPut, path+"?publisher_id=pub-1", strings.NewReader(`{"banner":{"w":300,"h":250}}`)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if sr := editor.templates["imp/mrec"]; sr == nil || sr.PublisherID != "pub-1" {
+		t.Errorf("expected the template saved for pub-1, got %+v", sr)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	var sr storage.StoredRequest
+	if err := json.Unmarshal(w.Body.Bytes(), &sr); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("expected the template, got %d: %s", w.Code, w.Body.String())
+	}
+	if string(sr.Data) != `{"banner":{"w":300,"h":250}}` {
+		t.Errorf("unexpected template data: %s", sr.Data)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, path, nil))
+	if w.Code != http.StatusNoContent {
+		t.Errorf("expected 204, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 after delete, got %d", w.Code)
+	}
+}
+
+func TestStoredRequestsAdminHandler_BroadcastsInvalidation(t *testing.T) {
+	var invalidated []string
+	cacheAdmin := NewCacheAdminHandler()
+	cacheAdmin.RegisterInvalidator(StoredRequestInvalidationScope, CacheInvalidatorFunc(func(ids ...string) int {
+		invalidated = append(invalidated, ids...)
+		return len(ids)
+	}))
+	pub := &mockInvalidationPublisher{}
+	cacheAdmin.SetPublisher(pub)
+
+	h := NewStoredRequestsAdminHandler(&mockStoredRequestEditor{templates: map[string]*storage.StoredRequest{}})
+	h.SetInvalidator(cacheAdmin)
+	path := "/admin/api/stored-requests/request/homepage"
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPut, path, strings.NewReader(`{"tmax":800}`)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, path, nil))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", w.Code)
+	}
+
+	if len(invalidated) != 2 || invalidated[0] != "request:homepage" || len(pub.messages) != 2 {
+		t.Errorf("expected PUT and DELETE to invalidate request:homepage and broadcast, got %v %v", invalidated, pub.messages)
+	}
+
+	// A failed write is not broadcast
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, path, nil))
+	if w.Code != http.StatusNotFound || len(pub.messages) != 2 {
+		t.Errorf("expected a 404 without a broadcast, got %d and %d messages", w.Code, len(pub.messages))
+	}
+}
+
+func TestStoredRequestsAdminHandler_Errors(t *testing.T) {
+	w := httptest.NewRecorder()
+	NewStoredRequestsAdminHandler(nil).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/api/stored-requests/request/x", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 without a database, got %d", w.Code)
+	}
+
+	h := NewStoredRequestsAdminHandler(&mockStoredRequestEditor{templates: map[string]*storage.StoredRequest{}})
+	tests := []struct {
+		method, path, body string
+		want               int
+	}{
+		{http.MethodGet, "/admin/api/stored-requests/response/x", "", http.StatusNotFound},
+		{http.MethodGet, "/admin/api/stored-requests/request", "", http.StatusNotFound},
+		{http.MethodPost, "/admin/api/stored-requests/request/x", "{}", http.StatusMethodNotAllowed},
+		{http.MethodPut, "/admin/api/stored-requests/request/x", "{", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+		if w.Code != tt.want {
+			t.Errorf("%s %s: expected %d, got %d", tt.method, tt.path, tt.want, w.Code)
+		}
+	}
+}
@@ -1,15 +1,19 @@
 package endpoints
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/rs/zerolog/log"
+	"github.com/thenexusengine/tne_springwire/internal/analytics"
 	"github.com/thenexusengine/tne_springwire/internal/middleware"
+	"github.com/thenexusengine/tne_springwire/pkg/kv"
 	"github.com/thenexusengine/tne_springwire/pkg/vast"
 )
 
@@ -17,6 +21,7 @@ import (
 type VideoEventRequest struct {
 	Event        string `json:"event"`
 	BidID        string `json:"bid_id"`
+	RequestID    string `json:"request_id,omitempty"`
 	AccountID    string `json:"account_id"`
 	Bidder       string `json:"bidder,omitempty"`
 	Timestamp    int64  `json:"timestamp,omitempty"`
@@ -34,9 +39,39 @@ type VideoEventResponse struct {
 	Timestamp int64  `json:"timestamp"`
 }
 
+// videoEventDedupPrefix namespaces dedup keys in Redis
+const videoEventDedupPrefix = "video_event_dedup:"
+
 // VideoEventHandler handles video tracking events
 type VideoEventHandler struct {
 	analytics VideoAnalytics
+
+	// Players often double-fire pixels; only the first (bid_id, event) in
+	// dedupWindow is tracked
+	dedup       VideoEventDeduper
+	dedupWindow time.Duration
+	metrics     DuplicateVideoEventMetrics
+
+	// billing bills the bid when its video starts; nil leaves billing to
+	// the burl pixel
+	billing BillingNotifier
+}
+
+// BillingNotifier queues a bid's billing notice, reporting whether the bid
+// was live; implemented by WinNoticeHandler
+type BillingNotifier interface {
+	NotifyBilling(ctx context.Context, bidID string) bool
+}
+
+// VideoEventDeduper claims a key for a window, reporting whether it was
+// free; implemented by redis.Client (SETNX)
+type VideoEventDeduper interface {
+	SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error)
+}
+
+// DuplicateVideoEventMetrics records video events dropped as duplicates
+type DuplicateVideoEventMetrics interface {
+	RecordVideoEventDeduplicated(event string)
 }
 
 // VideoAnalytics is an interface for video analytics tracking
@@ -48,6 +83,7 @@ type VideoAnalytics interface {
 type VideoEvent struct {
 	EventType    vast.EventType
 	BidID        string
+	RequestID    string // Edge X-Request-ID of the originating auction
 	AccountID    string
 	Bidder       string
 	Timestamp    time.Time
@@ -61,6 +97,58 @@ type VideoEvent struct {
 	UserAgent    string
 }
 
+// AnalyticsTracker ships events to downstream analytics systems without
+// blocking; implemented by analytics.Pipeline
+type AnalyticsTracker interface {
+	Track(event analytics.Event)
+}
+
+// pipelineVideoAnalytics ships video events to the analytics pipeline
+type pipelineVideoAnalytics struct {
+	tracker AnalyticsTracker
+}
+
+// NewPipelineVideoAnalytics returns VideoAnalytics shipping events to the
+// analytics pipeline. The event name becomes the status; IP and user agent
+// stay out of analytics.
+func NewPipelineVideoAnalytics(tracker AnalyticsTracker) VideoAnalytics {
+	return &pipelineVideoAnalytics{tracker: tracker}
+}
+
+// TrackEvent implements VideoAnalytics
+func (a *pipelineVideoAnalytics) TrackEvent(event *VideoEvent) error {
+	fields := make(map[string]string)
+	for key, value := range map[string]string{
+		"error_code":    event.ErrorCode,
+		"error_message": event.ErrorMessage,
+		"click_url":     event.ClickURL,
+		"session_id":    event.SessionID,
+		"content_id":    event.ContentID,
+	} {
+		if value != "" {
+			fields[key] = value
+		}
+	}
+	if event.Progress > 0 {
+		fields["progress"] = strconv.FormatFloat(event.Progress, 'f', -1, 64)
+	}
+	if len(fields) == 0 {
+		fields = nil
+	}
+
+	a.tracker.Track(analytics.Event{
+		Type:        analytics.EventVideo,
+		Timestamp:   event.Timestamp,
+		RequestID:   event.RequestID,
+		PublisherID: event.AccountID,
+		Bidder:      event.Bidder,
+		BidID:       event.BidID,
+		Status:      string(event.EventType),
+		Fields:      fields,
+	})
+	return nil
+}
+
 // NewVideoEventHandler creates a new video event handler
 func NewVideoEventHandler(analytics VideoAnalytics) *VideoEventHandler {
 	return &VideoEventHandler{
@@ -68,6 +156,42 @@ func NewVideoEventHandler(analytics VideoAnalytics) *VideoEventHandler {
 	}
 }
 
+// SetDeduplication drops repeat (bid_id, event) pairs seen within window
+func (h *VideoEventHandler) SetDeduplication(dedup VideoEventDeduper, window time.Duration, metrics DuplicateVideoEventMetrics) {
+	h.dedup = dedup
+	h.dedupWindow = window
+	h.metrics = metrics
+}
+
+// SetBillingNotifier bills bids, firing their burl, when their video starts
+func (h *VideoEventHandler) SetBillingNotifier(n BillingNotifier) {
+	h.billing = n
+}
+
+// isDuplicate reports whether the event was already seen for the bid within
+// the dedup window. Dedup fails open: store errors let the event through,
+// and a store over its error budget isn't asked.
+func (h *VideoEventHandler) isDuplicate(ctx context.Context, bidID string, eventType vast.EventType) bool {
+	if h.dedup == nil || h.dedupWindow <= 0 || kv.IsDegraded(h.dedup) {
+		return false
+	}
+
+	first, err := h.dedup.SetNX(ctx, videoEventDedupPrefix+bidID+":"+string(eventType), 1, h.dedupWindow)
+	if err != nil {
+		log.Debug().Err(err).Str("bid_id", bidID).Str("event", string(eventType)).Msg("Video event dedup unavailable")
+		return false
+	}
+	if first {
+		return false
+	}
+
+	if h.metrics != nil {
+		h.metrics.RecordVideoEventDeduplicated(string(eventType))
+	}
+	log.Debug().Str("bid_id", bidID).Str("event", string(eventType)).Msg("Dropped duplicate video event")
+	return true
+}
+
 // HandleVideoEvent handles POST /api/v1/video/event
 func (h *VideoEventHandler) HandleVideoEvent(w http.ResponseWriter, r *http.Request) {
 	if r.Method == http.MethodGet {
@@ -106,6 +230,7 @@ func (h *VideoEventHandler) handleGETEvent(w http.ResponseWriter, r *http.Reques
 	req := &VideoEventRequest{
 		Event:     q.Get("event"),
 		BidID:     q.Get("bid_id"),
+		RequestID: q.Get("request_id"),
 		AccountID: q.Get("account_id"),
 		Bidder:    q.Get("bidder"),
 		SessionID: q.Get("session_id"),
@@ -134,6 +259,16 @@ func (h *VideoEventHandler) processEvent(req *VideoEventRequest, r *http.Request
 
 	eventType := vast.EventType(req.Event)
 
+	// Repeat pixels are acknowledged but not tracked again
+	if h.isDuplicate(r.Context(), req.BidID, eventType) {
+		return nil
+	}
+
+	// A start is the impression bidders bill on
+	if eventType == vast.EventTypeStart && h.billing != nil {
+		h.billing.NotifyBilling(r.Context(), req.BidID)
+	}
+
 	// GDPR FIX: Only collect IP/UA if consent allows
 	var ipAddress, userAgent string
 	if middleware.ShouldCollectPII(r.Context()) {
@@ -149,6 +284,7 @@ func (h *VideoEventHandler) processEvent(req *VideoEventRequest, r *http.Request
 	event := &VideoEvent{
 		EventType:    eventType,
 		BidID:        req.BidID,
+		RequestID:    req.RequestID,
 		AccountID:    req.AccountID,
 		Bidder:       req.Bidder,
 		Timestamp:    time.Now(),
@@ -174,6 +310,7 @@ func (h *VideoEventHandler) processEvent(req *VideoEventRequest, r *http.Request
 	log.Info().
 		Str("event", req.Event).
 		Str("bid_id", req.BidID).
+		Str("request_id", req.RequestID).
 		Str("account_id", req.AccountID).
 		Str("bidder", req.Bidder).
 		Msg("Video event tracked")
@@ -258,6 +395,7 @@ func (h *VideoEventHandler) handleSpecificEvent(w http.ResponseWriter, r *http.R
 		req := &VideoEventRequest{
 			Event:        string(eventType),
 			BidID:        q.Get("bid_id"),
+			RequestID:    q.Get("request_id"),
 			AccountID:    q.Get("account_id"),
 			Bidder:       q.Get("bidder"),
 			ErrorCode:    q.Get("error_code"),
@@ -2,6 +2,7 @@ package endpoints
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -9,6 +10,7 @@ import (
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/thenexusengine/tne_springwire/internal/middleware"
 	"github.com/thenexusengine/tne_springwire/pkg/vast"
@@ -954,3 +956,127 @@ func TestHandleSpecificEvent_POST_InvalidJSON(t *testing.T) {
 		t.Errorf("expected status 400, got %d", w.Code)
 	}
 }
+
+// mockVideoDeduper implements VideoEventDeduper with an in-memory key set
+type mockVideoDeduper struct {
+	keys       map[string]bool
+	shouldFail bool
+	degraded   bool
+	calls      int
+}
+
+func (m *mockVideoDeduper) Degraded() bool { return m.degraded }
+
+func (m *mockVideoDeduper) SetNX(_ context.Context, key string, _ interface{}, _ time.Duration) (bool, error) {
+	m.calls++
+	if m.shouldFail {
+		return false, errors.New("redis unavailable")
+	}
+	if m.keys == nil {
+		m.keys = make(map[string]bool)
+	}
+	if m.keys[key] {
+		return false, nil
+	}
+	m.keys[key] = true
+	return true, nil
+}
+
+// mockDuplicateMetrics counts deduplicated events by type
+type mockDuplicateMetrics struct {
+	deduped map[string]int
+}
+
+func (m *mockDuplicateMetrics) RecordVideoEventDeduplicated(event string) {
+	if m.deduped == nil {
+		m.deduped = make(map[string]int)
+	}
+	m.deduped[event]++
+}
+
+func TestHandleVideoEvent_Deduplication(t *testing.T) {
+	analytics := &mockVideoAnalytics{}
+	metrics := &mockDuplicateMetrics{}
+	handler := NewVideoEventHandler(analytics)
+	handler.SetDeduplication(&mockVideoDeduper{}, 30*time.Second, metrics)
+
+	fire := func(query string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/video/quartile?"+query, nil)
+		w := httptest.NewRecorder()
+		handler.HandleVideoQuartile(w, req)
+		return w.Code
+	}
+
+	// A double-fired quartile is acknowledged but only tracked once
+	for i := 0; i < 2; i++ {
+		if code := fire("quartile=25&bid_id=bid-1"); code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", code)
+		}
+	}
+	// Other events and other bids are tracked independently
+	fire("quartile=50&bid_id=bid-1")
+	fire("quartile=25&bid_id=bid-2")
+
+	if len(analytics.events) != 3 {
+		t.Errorf("expected 3 tracked events, got %d", len(analytics.events))
+	}
+	if metrics.deduped[string(vast.EventTypeFirstQuartile)] != 1 {
+		t.Errorf("expected 1 deduplicated firstQuartile, got %v", metrics.deduped)
+	}
+}
+
+func TestHandleVideoEvent_DeduplicationFailsOpen(t *testing.T) {
+	analytics := &mockVideoAnalytics{}
+	handler := NewVideoEventHandler(analytics)
+	handler.SetDeduplication(&mockVideoDeduper{shouldFail: true}, 30*time.Second, nil)
+
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/video/start?bid_id=bid-1", nil)
+		handler.HandleVideoStart(httptest.NewRecorder(), req)
+	}
+
+	if len(analytics.events) != 2 {
+		t.Errorf("expected both events tracked when dedup is unavailable, got %d", len(analytics.events))
+	}
+}
+
+func TestHandleVideoEvent_DeduplicationSkipsDegradedStore(t *testing.T) {
+	analytics := &mockVideoAnalytics{}
+	dedup := &mockVideoDeduper{degraded: true}
+	handler := NewVideoEventHandler(analytics)
+	handler.SetDeduplication(dedup, 30*time.Second, nil)
+
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/video/start?bid_id=bid-1", nil)
+		handler.HandleVideoStart(httptest.NewRecorder(), req)
+	}
+
+	if len(analytics.events) != 2 || dedup.calls != 0 {
+		t.Errorf("expected both events tracked without asking a degraded store, got %d events and %d calls", len(analytics.events), dedup.calls)
+	}
+}
+
+type mockBillingNotifier struct {
+	billed []string
+}
+
+func (m *mockBillingNotifier) NotifyBilling(_ context.Context, bidID string) bool {
+	m.billed = append(m.billed, bidID)
+	return true
+}
+
+func TestHandleVideoEvent_BillsOnStart(t *testing.T) {
+	billing := &mockBillingNotifier{}
+	handler := NewVideoEventHandler(&mockVideoAnalytics{})
+	handler.SetDeduplication(&mockVideoDeduper{}, 30*time.Second, nil)
+	handler.SetBillingNotifier(billing)
+
+	for i := 0; i < 2; i++ {
+		handler.HandleVideoStart(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/video/start?bid_id=bid-1", nil))
+	}
+	handler.HandleVideoComplete(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/video/complete?bid_id=bid-1", nil))
+
+	if len(billing.billed) != 1 || billing.billed[0] != "bid-1" {
+		t.Errorf("expected the bid billed once on its first start, got %v", billing.billed)
+	}
+}
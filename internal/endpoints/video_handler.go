@@ -11,7 +11,9 @@ import (
 	"github.com/rs/zerolog/log"
 	"github.com/thenexusengine/tne_springwire/internal/ctv"
 	"github.com/thenexusengine/tne_springwire/internal/exchange"
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
 	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/internal/scrub"
 	"github.com/thenexusengine/tne_springwire/pkg/vast"
 )
 
@@ -20,17 +22,32 @@ type VideoHandler struct {
 	exchange        *exchange.Exchange
 	vastBuilder     *exchange.VASTResponseBuilder
 	trackingBaseURL string
+	validation      string
 }
 
+// VAST validation modes for the documents the video handlers serve
+const (
+	VASTValidationOff    = "off"
+	VASTValidationDebug  = "debug"  // Log violations and serve the document anyway
+	VASTValidationStrict = "strict" // Log violations and serve a VAST error instead
+)
+
 // NewVideoHandler creates a new video handler
 func NewVideoHandler(ex *exchange.Exchange, trackingBaseURL string) *VideoHandler {
 	return &VideoHandler{
 		exchange:        ex,
 		vastBuilder:     exchange.NewVASTResponseBuilder(trackingBaseURL),
 		trackingBaseURL: trackingBaseURL,
+		validation:      VASTValidationOff,
 	}
 }
 
+// SetValidation validates outgoing VAST against the builder's target version
+// in debug or strict mode, catching builder regressions before players do
+func (h *VideoHandler) SetValidation(mode string) {
+	h.validation = mode
+}
+
 // HandleVASTRequest handles GET /video/vast requests
 // This endpoint accepts query parameters and returns a VAST XML response
 func (h *VideoHandler) HandleVASTRequest(w http.ResponseWriter, r *http.Request) {
@@ -58,10 +75,17 @@ func (h *VideoHandler) HandleVASTRequest(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
+	// Structured user agent from client hints, consent permitting
+	middleware.DeviceSUA(ctx, bidReq.Device)
+
+	// Fit the auction into the caller's latency budget
+	applyLatencyBudget(ctx, bidReq)
+
 	// Create auction request
 	auctionReq := &exchange.AuctionRequest{
 		BidRequest: bidReq,
 		Timeout:    time.Duration(bidReq.TMax) * time.Millisecond,
+		Shadow:     middleware.IsShadowRequest(ctx),
 	}
 
 	// Run auction through exchange
@@ -87,6 +111,10 @@ func (h *VideoHandler) HandleVASTRequest(w http.ResponseWriter, r *http.Request)
 		h.writeVASTError(w, "Failed to serialize response")
 		return
 	}
+	if !h.checkVAST(bidReq.ID, data, vastResp, auctionResp) {
+		h.writeVASTError(w, "Invalid VAST response")
+		return
+	}
 
 	// Set headers and write response
 	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
@@ -99,6 +127,7 @@ func (h *VideoHandler) HandleVASTRequest(w http.ResponseWriter, r *http.Request)
 	// See: IAB VAST 4.2 spec section on "Cross-Origin Resource Sharing"
 	h.setVASTCORSHeaders(w)
 	w.Header().Set("Cache-Control", "no-cache")
+	exchange.SetPodFillHeaders(w, exchange.BuildPodFill(bidReq, auctionResp.BidResponse))
 	w.WriteHeader(http.StatusOK)
 	w.Write(data)
 
@@ -127,23 +156,34 @@ func (h *VideoHandler) HandleOpenRTBVideo(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	// Validate that this is a video request
+	// Validate that this is a video or audio request
 	hasVideo := false
 	for _, imp := range bidReq.Imp {
-		if imp.Video != nil {
+		if imp.Video != nil || imp.Audio != nil {
 			hasVideo = true
 			break
 		}
 	}
 	if !hasVideo {
-		h.writeVASTError(w, "No video impressions in request")
+		log.Warn().RawJSON("request", scrub.JSON(&bidReq)).Msg("OpenRTB video request has no video or audio impressions")
+		h.writeVASTError(w, "No video or audio impressions in request")
 		return
 	}
 
+	// Fan pods described by poddur/maxseq out into one impression per slot
+	exchange.ExpandPods(&bidReq)
+
+	// Structured user agent from client hints, consent permitting
+	middleware.DeviceSUA(ctx, bidReq.Device)
+
+	// Fit the auction into the caller's latency budget
+	applyLatencyBudget(ctx, &bidReq)
+
 	// Run auction
 	auctionReq := &exchange.AuctionRequest{
 		BidRequest: &bidReq,
 		Timeout:    time.Duration(bidReq.TMax) * time.Millisecond,
+		Shadow:     middleware.IsShadowRequest(ctx),
 	}
 
 	auctionResp, err := h.exchange.RunAuction(ctx, auctionReq)
@@ -168,14 +208,66 @@ func (h *VideoHandler) HandleOpenRTBVideo(w http.ResponseWriter, r *http.Request
 		h.writeVASTError(w, "Failed to serialize response")
 		return
 	}
+	if !h.checkVAST(bidReq.ID, data, vastResp, auctionResp) {
+		h.writeVASTError(w, "Invalid VAST response")
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
 	// SECURITY NOTE: CORS wildcard intentional for VAST - see setVASTCORSHeaders
 	h.setVASTCORSHeaders(w)
+	exchange.SetPodFillHeaders(w, exchange.BuildPodFill(&bidReq, auctionResp.BidResponse))
 	w.WriteHeader(http.StatusOK)
 	w.Write(data)
 }
 
+// checkVAST validates an outgoing document in debug and strict modes, logging
+// each violation against the bidder whose ad it was found in. It reports
+// whether the document may be served.
+func (h *VideoHandler) checkVAST(requestID string, data []byte, v *vast.VAST, auctionResp *exchange.AuctionResponse) bool {
+	if h.validation != VASTValidationDebug && h.validation != VASTValidationStrict {
+		return true
+	}
+	result := vast.ValidateDocument(data, h.vastBuilder.Version())
+	if result.Valid {
+		return true
+	}
+
+	// Ads are keyed by bid ID; platform demand shares one seat, so the
+	// bidder results name the bidder behind each bid
+	bidders := make(map[string]string)
+	if auctionResp != nil && auctionResp.BidResponse != nil {
+		for _, seatBid := range auctionResp.BidResponse.SeatBid {
+			for _, bid := range seatBid.Bid {
+				bidders[bid.ID] = seatBid.Seat
+			}
+		}
+	}
+	if auctionResp != nil {
+		for code, result := range auctionResp.BidderResults {
+			for _, typed := range result.Bids {
+				if typed != nil && typed.Bid != nil {
+					if _, ok := bidders[typed.Bid.ID]; ok {
+						bidders[typed.Bid.ID] = code
+					}
+				}
+			}
+		}
+	}
+	for _, verr := range result.Errors {
+		event := log.Warn().
+			Str("request_id", requestID).
+			Str("mode", h.validation).
+			Str("field", verr.Field).
+			Str("violation", verr.Message)
+		if i := verr.AdIndex(); i >= 0 && i < len(v.Ads) {
+			event = event.Str("bid_id", v.Ads[i].ID).Str("bidder", bidders[v.Ads[i].ID])
+		}
+		event.Msg("VAST response failed validation")
+	}
+	return h.validation != VASTValidationStrict
+}
+
 // setVASTCORSHeaders sets CORS headers for VAST responses.
 //
 // SECURITY RATIONALE: VAST endpoints intentionally use permissive CORS (Access-Control-Allow-Origin: *)
@@ -217,6 +309,10 @@ func (h *VideoHandler) parseVASTRequest(r *http.Request) (*openrtb.BidRequest, e
 	// Placement type (1=in-stream, 3=in-article, 4=in-feed, 5=interstitial)
 	placement := parseInt(q.Get("placement"), 1)
 
+	// OpenRTB 2.6 placement subtype (1=instream, 2=accompanying content,
+	// 3=interstitial, 4=no content), used when the player sends it
+	plcmt := parseInt(q.Get("plcmt"), 0)
+
 	// Protocols (comma-separated)
 	protocols := parseIntArray(q.Get("protocols"), []int{2, 3, 5, 6})
 
@@ -239,6 +335,7 @@ func (h *VideoHandler) parseVASTRequest(r *http.Request) (*openrtb.BidRequest, e
 		W:           width,
 		H:           height,
 		Placement:   placement,
+		Plcmt:       plcmt,
 		Linearity:   1, // Linear/in-stream
 		MinBitrate:  minBitrate,
 		MaxBitrate:  maxBitrate,
@@ -291,6 +388,27 @@ func (h *VideoHandler) parseVASTRequest(r *http.Request) (*openrtb.BidRequest, e
 		}
 	}
 
+	// Players running the OM SDK pass its partner name and version
+	if omidpn := q.Get("omidpn"); omidpn != "" {
+		video.API = append(video.API, exchange.APIOMID)
+		ext, _ := json.Marshal(map[string]string{
+			"omidpn": omidpn,
+			"omidpv": q.Get("omidpv"),
+		})
+		bidReq.Source = &openrtb.Source{Ext: ext}
+	}
+
+	// Ad pod: total seconds, max ads and per-slot max durations
+	// (comma-separated), fanned out into one impression per slot
+	podDur := parseInt(q.Get("poddur"), 0)
+	maxAds := parseInt(q.Get("maxads"), 0)
+	slotDurs := parseIntArray(q.Get("slotdurs"), nil)
+	if podDur > 0 || maxAds > 0 || len(slotDurs) > 0 {
+		video.PodDur = podDur
+		video.MaxSeq = maxAds
+		bidReq.Imp = exchange.ExpandPod(bidReq.Imp[0], slotDurs)
+	}
+
 	return bidReq, nil
 }
 
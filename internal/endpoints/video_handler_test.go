@@ -1,6 +1,7 @@
 package endpoints
 
 import (
+	"bytes"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -9,29 +10,37 @@ import (
 	"testing"
 	"time"
 
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
 	"github.com/thenexusengine/tne_springwire/internal/adapters"
 	"github.com/thenexusengine/tne_springwire/internal/exchange"
 	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/pkg/vast"
 )
 
 // mockVideoBidder implements Bidder interface for video handler testing
 type mockVideoBidder struct {
 	bids []*adapters.TypedBid
 	err  error
+	uri  string // defaults to http://test.com
 }
 
 func (m *mockVideoBidder) MakeRequests(request *openrtb.BidRequest, reqInfo *adapters.ExtraRequestInfo) ([]*adapters.RequestData, []error) {
 	if m.err != nil {
 		return nil, []error{m.err}
 	}
-	return []*adapters.RequestData{{Method: "POST", URI: "http://test.com", Body: []byte("{}")}}, nil
+	uri := m.uri
+	if uri == "" {
+		uri = "http://test.com"
+	}
+	return []*adapters.RequestData{{Method: "POST", URI: uri, Body: []byte("{}")}}, nil
 }
 
 func (m *mockVideoBidder) MakeBids(request *openrtb.BidRequest, response *adapters.ResponseData) (*adapters.BidderResponse, []error) {
 	if m.err != nil {
 		return nil, []error{m.err}
 	}
-	return &adapters.BidderResponse{Bids: m.bids, Currency: "USD"}, nil
+	return &adapters.BidderResponse{Bids: m.bids, Currency: "USD", ResponseID: request.ID}, nil
 }
 
 // Helper function to create test exchange with video adapter
@@ -411,6 +420,61 @@ func TestHandleOpenRTBVideo_NoVideoImpressions(t *testing.T) {
 	}
 }
 
+func TestHandleOpenRTBVideo_Audio(t *testing.T) {
+	bidderServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("{}"))
+	}))
+	defer bidderServer.Close()
+
+	bid := &adapters.TypedBid{
+		Bid:     &openrtb.Bid{ID: "bid-1", ImpID: "1", Price: 2.50, AdM: "http://example.com/spot.mp3", AdID: "ad-123"},
+		BidType: adapters.BidTypeAudio,
+	}
+	registry := adapters.NewRegistry()
+	registry.Register("audiobidder", &mockVideoBidder{bids: []*adapters.TypedBid{bid}, uri: bidderServer.URL}, adapters.BidderInfo{
+		Enabled:      true,
+		Capabilities: &adapters.CapabilitiesInfo{Site: &adapters.PlatformInfo{MediaTypes: []adapters.BidType{adapters.BidTypeAudio}}},
+	})
+	// Outbids the audio bidder, but only takes video
+	videoBid := &adapters.TypedBid{
+		Bid:     &openrtb.Bid{ID: "bid-2", ImpID: "1", Price: 5.00, AdM: "http://example.com/video.mp4", AdID: "ad-456"},
+		BidType: adapters.BidTypeVideo,
+	}
+	registry.Register("videobidder", &mockVideoBidder{bids: []*adapters.TypedBid{videoBid}, uri: bidderServer.URL}, adapters.BidderInfo{
+		Enabled:      true,
+		Capabilities: &adapters.CapabilitiesInfo{Site: &adapters.PlatformInfo{MediaTypes: []adapters.BidType{adapters.BidTypeVideo}}},
+	})
+	ex := exchange.New(registry, &exchange.Config{DefaultTimeout: 100 * time.Millisecond})
+	handler := NewVideoHandler(ex, "https://track.example.com")
+
+	bidReq := &openrtb.BidRequest{
+		ID:   "test-audio",
+		Imp:  []openrtb.Imp{{ID: "1", Audio: &openrtb.Audio{Mimes: []string{"audio/mpeg"}, MaxDuration: 30}}},
+		Site: &openrtb.Site{ID: "site-1", Domain: "example.com"},
+		TMax: 1000,
+	}
+	body, err := json.Marshal(bidReq)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/video/openrtb", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	handler.HandleOpenRTBVideo(w, req)
+
+	v, err := vast.Parse(w.Body.Bytes())
+	if err != nil {
+		t.Fatalf("expected VAST, got %s", w.Body.String())
+	}
+	if len(v.Ads) != 1 || !v.IsAudio() {
+		t.Fatalf("expected one audio ad, got %s", w.Body.String())
+	}
+	if mf := v.GetMediaFiles()[0]; mf.Type != "audio/mpeg" || mf.Width != 0 || !strings.Contains(mf.Value, "spot.mp3") {
+		t.Errorf("unexpected media file: %+v", mf)
+	}
+}
+
 func TestHandleOpenRTBVideo_AuctionError(t *testing.T) {
 	ex := newEmptyTestVideoExchange()
 	handler := NewVideoHandler(ex, "https://track.example.com")
@@ -614,6 +678,81 @@ func TestParseVASTRequest_OnlyDomain(t *testing.T) {
 	}
 }
 
+func TestParseVASTRequest_OMID(t *testing.T) {
+	handler := &VideoHandler{
+		trackingBaseURL: "https://track.example.com",
+	}
+
+	queryParams := url.Values{
+		"omidpn": {"ExamplePlayer"},
+		"omidpv": {"1.4.2"},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/video/vast?"+queryParams.Encode(), nil)
+	bidReq, err := handler.parseVASTRequest(req)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	api := bidReq.Imp[0].Video.API
+	if api[len(api)-1] != exchange.APIOMID {
+		t.Errorf("expected OMID api framework to be signaled, got %v", api)
+	}
+
+	if bidReq.Source == nil {
+		t.Fatal("expected source to be set when omidpn is provided")
+	}
+
+	var ext struct {
+		OMIDPN string `json:"omidpn"`
+		OMIDPV string `json:"omidpv"`
+	}
+	if err := json.Unmarshal(bidReq.Source.Ext, &ext); err != nil {
+		t.Fatalf("invalid source.ext: %v", err)
+	}
+	if ext.OMIDPN != "ExamplePlayer" || ext.OMIDPV != "1.4.2" {
+		t.Errorf("unexpected source.ext: %s", bidReq.Source.Ext)
+	}
+}
+
+func TestParseVASTRequest_Pod(t *testing.T) {
+	handler := &VideoHandler{
+		trackingBaseURL: "https://track.example.com",
+	}
+
+	queryParams := url.Values{
+		"id":       {"pod-test"},
+		"poddur":   {"90"},
+		"maxads":   {"4"},
+		"slotdurs": {"30,15,15"},
+		"omidpn":   {"ExamplePlayer"},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/video/vast?"+queryParams.Encode(), nil)
+	bidReq, err := handler.parseVASTRequest(req)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(bidReq.Imp) != 3 {
+		t.Fatalf("expected one impression per slot, got %d", len(bidReq.Imp))
+	}
+	for i, imp := range bidReq.Imp {
+		v := imp.Video
+		if v.Sequence != i+1 || v.PodDur != 90 || v.MaxSeq != 4 || v.PodID != "1" {
+			t.Errorf("unexpected slot %d: %+v", i, v)
+		}
+		if v.API[len(v.API)-1] != exchange.APIOMID {
+			t.Errorf("expected OMID signaled on slot %d, got %v", i, v.API)
+		}
+	}
+	if bidReq.Imp[0].Video.MaxDuration != 30 || bidReq.Imp[1].Video.MaxDuration != 15 {
+		t.Errorf("expected slot durations applied, got %d and %d", bidReq.Imp[0].Video.MaxDuration, bidReq.Imp[1].Video.MaxDuration)
+	}
+}
+
 func TestWriteVASTError_URLInjectionPrevention(t *testing.T) {
 	handler := &VideoHandler{
 		trackingBaseURL: "https://track.example.com",
@@ -845,3 +984,64 @@ func TestGenerateRequestID(t *testing.T) {
 		t.Errorf("expected ID to start with 'video-', got %s", id1)
 	}
 }
+
+func TestHandleOpenRTBVideo_Validation(t *testing.T) {
+	bidderServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("{}"))
+	}))
+	defer bidderServer.Close()
+
+	// VAST markup in adm ends up as the MediaFile URL
+	bid := &adapters.TypedBid{
+		Bid:     &openrtb.Bid{ID: "bid-1", ImpID: "1", Price: 2.50, AdM: `<VAST version="4.0"></VAST>`, AdID: "ad-123"},
+		BidType: adapters.BidTypeVideo,
+	}
+	registry := adapters.NewRegistry()
+	registry.Register("vastbidder", &mockVideoBidder{bids: []*adapters.TypedBid{bid}, uri: bidderServer.URL}, adapters.BidderInfo{
+		Enabled:      true,
+		Capabilities: &adapters.CapabilitiesInfo{Site: &adapters.PlatformInfo{MediaTypes: []adapters.BidType{adapters.BidTypeVideo}}},
+	})
+	ex := exchange.New(registry, &exchange.Config{DefaultTimeout: 100 * time.Millisecond})
+
+	body, err := json.Marshal(&openrtb.BidRequest{
+		ID:   "test-validation",
+		Imp:  []openrtb.Imp{{ID: "1", Video: &openrtb.Video{Mimes: []string{"video/mp4"}, W: 640, H: 480, MaxDuration: 30}}},
+		Site: &openrtb.Site{ID: "site-1", Domain: "example.com"},
+		TMax: 1000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	serve := func(mode string) *vast.VAST {
+		handler := NewVideoHandler(ex, "https://track.example.com")
+		handler.SetValidation(mode)
+		w := httptest.NewRecorder()
+		handler.HandleOpenRTBVideo(w, httptest.NewRequest(http.MethodPost, "/video/openrtb", strings.NewReader(string(body))))
+		v, err := vast.Parse(w.Body.Bytes())
+		if err != nil {
+			t.Fatalf("expected VAST, got %s", w.Body.String())
+		}
+		return v
+	}
+
+	var logs bytes.Buffer
+	logger := log.Logger
+	log.Logger = zerolog.New(&logs)
+	defer func() { log.Logger = logger }()
+
+	if v := serve(VASTValidationDebug); len(v.Ads) != 1 {
+		t.Fatalf("expected debug mode to serve the ad, got %+v", v)
+	}
+	if !strings.Contains(logs.String(), `"bidder":"vastbidder"`) || !strings.Contains(logs.String(), "MediaFile") {
+		t.Errorf("expected the violation logged against the bidder, got %s", logs.String())
+	}
+
+	if v := serve(VASTValidationStrict); len(v.Ads) != 0 || !strings.Contains(v.Error, "Invalid+VAST+response") {
+		t.Errorf("expected strict mode to serve a VAST error, got %+v", v)
+	}
+
+	logs.Reset()
+	if v := serve(VASTValidationOff); len(v.Ads) != 1 || strings.Contains(logs.String(), "failed validation") {
+		t.Errorf("expected no validation when off, got %+v: %s", v, logs.String())
+	}
+}
@@ -0,0 +1,158 @@
+package endpoints
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/thenexusengine/tne_springwire/internal/exchange"
+	"github.com/thenexusengine/tne_springwire/internal/winqueue"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+// BidExpiryChecker reports whether a returned bid is still inside its billing window
+type BidExpiryChecker interface {
+	Check(bidID string) (string, exchange.BidExpiryStatus)
+}
+
+// BidNoticeLookup returns the details recorded for a returned bid;
+// implemented by exchange.BidExpiryRegistry
+type BidNoticeLookup interface {
+	Notice(bidID string) (exchange.BidNotice, exchange.BidExpiryStatus)
+}
+
+// WinEventQueue queues accepted notices for asynchronous processing
+type WinEventQueue interface {
+	Enqueue(ctx context.Context, event winqueue.Event) error
+}
+
+// BidNoticeMarker records which notices a bid has had, reporting false for
+// repeats; implemented by exchange.BidExpiryRegistry
+type BidNoticeMarker interface {
+	MarkNotified(bidID, noticeType string) bool
+}
+
+// ExpiredWinMetrics records win/billing notices that arrive after expiry
+type ExpiredWinMetrics interface {
+	RecordExpiredWin(bidder string)
+}
+
+// WinNoticeHandler handles win and billing (burl) notices for returned bids.
+// Notices for bids past their exp window return 410 Gone so delayed billing
+// calls cannot be replayed into revenue.
+type WinNoticeHandler struct {
+	expiry  BidExpiryChecker
+	metrics ExpiredWinMetrics
+	queue   WinEventQueue
+}
+
+// NewWinNoticeHandler creates a new win notice handler
+func NewWinNoticeHandler(expiry BidExpiryChecker, metrics ExpiredWinMetrics) *WinNoticeHandler {
+	return &WinNoticeHandler{
+		expiry:  expiry,
+		metrics: metrics,
+	}
+}
+
+// SetQueue enables asynchronous processing of accepted notices (firing the
+// bidder's nurl/burl, recording win analytics)
+func (h *WinNoticeHandler) SetQueue(queue WinEventQueue) {
+	h.queue = queue
+}
+
+// ServeHTTP handles GET /event/win?bid_id=...[&type=billing]
+func (h *WinNoticeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	bidID := r.URL.Query().Get("bid_id")
+	if bidID == "" {
+		writeError(w, "bid_id is required", http.StatusBadRequest)
+		return
+	}
+
+	bidder, status := h.expiry.Check(bidID)
+	switch status {
+	case exchange.BidLive:
+		logger.Log.Debug().
+			Str("bid_id", bidID).
+			Str("bidder", bidder).
+			Msg("Win notice accepted")
+		eventType := winqueue.EventWin
+		if r.URL.Query().Get("type") == winqueue.EventBilling {
+			eventType = winqueue.EventBilling
+		}
+		h.enqueue(r.Context(), eventType, bidID, bidder)
+		w.WriteHeader(http.StatusNoContent)
+	case exchange.BidExpired:
+		if h.metrics != nil {
+			h.metrics.RecordExpiredWin(bidder)
+		}
+		logger.Log.Warn().
+			Str("bid_id", bidID).
+			Str("bidder", bidder).
+			Msg("Rejected win notice for expired bid")
+		writeError(w, "Bid has expired", http.StatusGone)
+	default:
+		writeError(w, "Unknown bid", http.StatusNotFound)
+	}
+}
+
+// NotifyBilling queues a billing notice for a live bid, as a player's
+// video start does, reporting whether the bid was live. Implements
+// BillingNotifier.
+func (h *WinNoticeHandler) NotifyBilling(ctx context.Context, bidID string) bool {
+	bidder, status := h.expiry.Check(bidID)
+	if status != exchange.BidLive {
+		return false
+	}
+	h.enqueue(ctx, winqueue.EventBilling, bidID, bidder)
+	return true
+}
+
+// enqueue hands an accepted notice to the queue. Only the first notice of
+// each type for a bid is queued, so a bid is billed, and its bidder
+// notified, once however many times it's reported. Queue failures are
+// logged; the notice itself was still accepted.
+func (h *WinNoticeHandler) enqueue(ctx context.Context, eventType, bidID, bidder string) {
+	if h.queue == nil {
+		return
+	}
+
+	if marker, ok := h.expiry.(BidNoticeMarker); ok && !marker.MarkNotified(bidID, eventType) {
+		return
+	}
+
+	event := winqueue.Event{Type: eventType, BidID: bidID, Bidder: bidder}
+	if lookup, ok := h.expiry.(BidNoticeLookup); ok {
+		notice, _ := lookup.Notice(bidID)
+		event.AuctionID = notice.AuctionID
+		event.ImpID = notice.ImpID
+		event.PublisherID = notice.PublisherID
+		event.MediaType = notice.MediaType
+		event.DealID = notice.DealID
+		event.Country = notice.Country
+		event.DeviceType = notice.DeviceType
+		event.AdSize = notice.AdSize
+		event.Advertiser = notice.Advertiser
+		event.AdvertiserDomain = notice.AdvertiserDomain
+		event.CampaignID = notice.CampaignID
+		event.CreativeID = notice.CreativeID
+		event.Price = notice.Price
+		event.GrossPrice = notice.GrossPrice
+		event.Currency = notice.Currency
+		event.URL = notice.NURL
+		if event.Type == winqueue.EventBilling {
+			event.URL = notice.BURL
+		}
+	}
+
+	if err := h.queue.Enqueue(ctx, event); err != nil {
+		logger.Log.Warn().
+			Err(err).
+			Str("bid_id", bidID).
+			Str("type", event.Type).
+			Msg("Failed to queue win notice")
+	}
+}
@@ -0,0 +1,136 @@
+package endpoints
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/exchange"
+	"github.com/thenexusengine/tne_springwire/internal/winqueue"
+)
+
+type mockExpiryChecker map[string]exchange.BidExpiryStatus
+
+func (m mockExpiryChecker) Check(bidID string) (string, exchange.BidExpiryStatus) {
+	return "appnexus", m[bidID]
+}
+
+type mockExpiredWinMetrics struct {
+	expired map[string]int
+}
+
+func (m *mockExpiredWinMetrics) RecordExpiredWin(bidder string) {
+	m.expired[bidder]++
+}
+
+func TestWinNoticeHandler(t *testing.T) {
+	metrics := &mockExpiredWinMetrics{expired: map[string]int{}}
+	h := NewWinNoticeHandler(mockExpiryChecker{
+		"live":    exchange.BidLive,
+		"expired": exchange.BidExpired,
+	}, metrics)
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"bid_id=live", http.StatusNoContent},
+		{"bid_id=expired", http.StatusGone},
+		{"bid_id=unknown", http.StatusNotFound},
+		{"", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/event/win?"+tt.query, nil))
+		if rr.Code != tt.want {
+			t.Errorf("%q: expected %d, got %d", tt.query, tt.want, rr.Code)
+		}
+	}
+
+	if metrics.expired["appnexus"] != 1 {
+		t.Errorf("expected one expired win counted for appnexus, got %v", metrics.expired)
+	}
+}
+
+type noticeExpiry struct {
+	mockExpiryChecker
+}
+
+func (n noticeExpiry) Notice(bidID string) (exchange.BidNotice, exchange.BidExpiryStatus) {
+	return exchange.BidNotice{
+		BidID:       bidID,
+		Bidder:      "appnexus",
+		AuctionID:   "auction-1",
+		PublisherID: "pub-1",
+		Price:       2.5,
+		NURL:        "https://bidder.example/win?p=${AUCTION_PRICE}",
+		BURL:        "https://bidder.example/bill?p=${AUCTION_PRICE}",
+	}, n.mockExpiryChecker[bidID]
+}
+
+type recordingQueue struct {
+	events []winqueue.Event
+}
+
+func (q *recordingQueue) Enqueue(ctx context.Context, event winqueue.Event) error {
+	q.events = append(q.events, event)
+	return nil
+}
+
+func TestWinNoticeHandler_Enqueue(t *testing.T) {
+	queue := &recordingQueue{}
+	h := NewWinNoticeHandler(noticeExpiry{mockExpiryChecker{
+		"live":    exchange.BidLive,
+		"expired": exchange.BidExpired,
+	}}, &mockExpiredWinMetrics{expired: map[string]int{}})
+	h.SetQueue(queue)
+
+	for _, query := range []string{"bid_id=live", "bid_id=live&type=billing", "bid_id=expired"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/event/win?"+query, nil))
+	}
+
+	if len(queue.events) != 2 {
+		t.Fatalf("expected only live notices queued, got %+v", queue.events)
+	}
+	win, billing := queue.events[0], queue.events[1]
+	if win.Type != winqueue.EventWin || win.URL != "https://bidder.example/win?p=${AUCTION_PRICE}" {
+		t.Errorf("unexpected win event: %+v", win)
+	}
+	if win.Bidder != "appnexus" || win.AuctionID != "auction-1" || win.PublisherID != "pub-1" || win.Price != 2.5 {
+		t.Errorf("expected win event enriched from bid notice, got %+v", win)
+	}
+	if billing.Type != winqueue.EventBilling || billing.URL != "https://bidder.example/bill?p=${AUCTION_PRICE}" {
+		t.Errorf("unexpected billing event: %+v", billing)
+	}
+}
+
+func TestWinNoticeHandler_OncePerBid(t *testing.T) {
+	expiry := exchange.NewBidExpiryRegistry(time.Hour)
+	expiry.Track("bid-1", "appnexus", 30*time.Second)
+	queue := &recordingQueue{}
+	h := NewWinNoticeHandler(expiry, nil)
+	h.SetQueue(queue)
+
+	// The player's video start bills the bid; its burl pixel and repeat
+	// win notices don't count again
+	if !h.NotifyBilling(context.Background(), "bid-1") {
+		t.Fatal("expected a live bid billed")
+	}
+	for _, query := range []string{"bid_id=bid-1", "bid_id=bid-1", "bid_id=bid-1&type=billing"} {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/event/win?"+query, nil))
+		if rr.Code != http.StatusNoContent {
+			t.Errorf("%q: expected repeats acknowledged, got %d", query, rr.Code)
+		}
+	}
+
+	if len(queue.events) != 2 || queue.events[0].Type != winqueue.EventBilling || queue.events[1].Type != winqueue.EventWin {
+		t.Errorf("expected one billing and one win notice queued, got %+v", queue.events)
+	}
+	if h.NotifyBilling(context.Background(), "unknown") {
+		t.Error("expected an unknown bid not billed")
+	}
+}
@@ -0,0 +1,63 @@
+package exchange
+
+import (
+	"math/rand"
+	"runtime/metrics"
+)
+
+// Runtime metrics read around sampled auctions
+const (
+	allocBytesMetric   = "/gc/heap/allocs:bytes"
+	allocObjectsMetric = "/gc/heap/allocs:objects"
+	heapObjectsMetric  = "/memory/classes/heap/objects:bytes"
+)
+
+// allocSnapshot is one reading of the allocation counters and live heap
+type allocSnapshot struct {
+	bytes, objects, heap uint64
+}
+
+// readAllocSnapshot reads the allocation counters without stopping the world
+func readAllocSnapshot() allocSnapshot {
+	samples := []metrics.Sample{
+		{Name: allocBytesMetric},
+		{Name: allocObjectsMetric},
+		{Name: heapObjectsMetric},
+	}
+	metrics.Read(samples)
+
+	var s allocSnapshot
+	for i, dst := range []*uint64{&s.bytes, &s.objects, &s.heap} {
+		if samples[i].Value.Kind() == metrics.KindUint64 {
+			*dst = samples[i].Value.Uint64()
+		}
+	}
+	return s
+}
+
+// sampleAllocations starts measuring an auction when it falls in
+// AllocSampleRate, returning the function that records the measurement, or
+// nil. The counters are process-wide, so with concurrent auctions a sample
+// also includes whatever else allocated meanwhile; compare trends between
+// builds rather than reading single samples.
+func (e *Exchange) sampleAllocations() func() {
+	e.configMu.RLock()
+	m := e.metrics
+	e.configMu.RUnlock()
+	if m == nil || e.config.AllocSampleRate <= 0 {
+		return nil
+	}
+	if e.config.AllocSampleRate < 1 && rand.Float64() >= e.config.AllocSampleRate { // #nosec G404 -- sampling, not security
+		return nil
+	}
+
+	start := readAllocSnapshot()
+	return func() {
+		end := readAllocSnapshot()
+		peak := end.heap
+		if start.heap > peak {
+			peak = start.heap
+		}
+		m.RecordAuctionAllocations(end.bytes-start.bytes, end.objects-start.objects, peak)
+	}
+}
@@ -0,0 +1,60 @@
+package exchange
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+// allocMetrics records sampled auction allocations on top of mockMetrics
+type allocMetrics struct {
+	mockMetrics
+	samples        int
+	bytes, objects uint64
+	heap           uint64
+}
+
+func (m *allocMetrics) RecordAuctionAllocations(allocBytes, allocObjects, heapBytes uint64) {
+	m.samples++
+	m.bytes, m.objects, m.heap = allocBytes, allocObjects, heapBytes
+}
+
+func TestRunAuction_AllocSampling(t *testing.T) {
+	registry := adapters.NewRegistry()
+	registry.Register("bidder1", &mockAdapter{}, adapters.BidderInfo{Enabled: true})
+	run := func(ex *Exchange) {
+		_, err := ex.RunAuction(context.Background(), &AuctionRequest{
+			BidRequest: &openrtb.BidRequest{
+				ID:   "test-alloc",
+				Site: testSite(),
+				Imp:  []openrtb.Imp{{ID: "imp1", Banner: &openrtb.Banner{W: 300, H: 250}}},
+			},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	ex := New(registry, &Config{DefaultTimeout: 100 * time.Millisecond, AllocSampleRate: 1})
+	metrics := &allocMetrics{}
+	ex.SetMetrics(metrics)
+	run(ex)
+	if metrics.samples != 1 {
+		t.Fatalf("expected every auction sampled at rate 1, got %d samples", metrics.samples)
+	}
+	// Allocation counters are flushed per span, so a tiny auction may read 0
+	if metrics.heap == 0 {
+		t.Error("expected non-zero heap size")
+	}
+
+	ex = New(registry, &Config{DefaultTimeout: 100 * time.Millisecond})
+	metrics = &allocMetrics{}
+	ex.SetMetrics(metrics)
+	run(ex)
+	if metrics.samples != 0 {
+		t.Errorf("expected no samples when disabled, got %d", metrics.samples)
+	}
+}
@@ -0,0 +1,121 @@
+package exchange
+
+import (
+	"strconv"
+
+	"github.com/thenexusengine/tne_springwire/internal/analytics"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+// AnalyticsTracker ships events to downstream analytics systems without
+// blocking. Implemented by *analytics.Pipeline.
+type AnalyticsTracker interface {
+	Track(event analytics.Event)
+}
+
+// SetAnalytics sets where auction and bid events are shipped
+func (e *Exchange) SetAnalytics(t AnalyticsTracker) {
+	e.configMu.Lock()
+	defer e.configMu.Unlock()
+	e.analytics = t
+}
+
+// trackAuctionEvents ships one auction event and a bid event per bid each
+// bidder returned, marked won when it made the response. Bid prices are in
+// the exchange currency and the auction's, including its pod economics, in
+// the response currency; the response seat hides platform bidders, so
+// bidders come from the bidder results.
+func (e *Exchange) trackAuctionEvents(req *openrtb.BidRequest, response *AuctionResponse) {
+	e.configMu.RLock()
+	t := e.analytics
+	e.configMu.RUnlock()
+	if t == nil || req.ID == "" {
+		return
+	}
+
+	publisherID := requestPublisherID(req)
+	cur := e.exchangeCurrency()
+	timestamp := response.DebugInfo.RequestTime
+
+	won := make(map[string]bool)
+	var winner *openrtb.Bid
+	if response.BidResponse != nil {
+		for i := range response.BidResponse.SeatBid {
+			for j := range response.BidResponse.SeatBid[i].Bid {
+				bid := &response.BidResponse.SeatBid[i].Bid[j]
+				won[bid.ImpID+"/"+bid.ID] = true
+				if winner == nil || bid.Price > winner.Price {
+					winner = bid
+				}
+			}
+		}
+	}
+
+	bids := 0
+	for code, result := range response.BidderResults {
+		if result == nil {
+			continue
+		}
+		for _, tb := range result.Bids {
+			if tb == nil || tb.Bid == nil {
+				continue
+			}
+			bids++
+			status := "lost"
+			if won[tb.Bid.ImpID+"/"+tb.Bid.ID] {
+				status = "won"
+			}
+			t.Track(analytics.Event{
+				Type:        analytics.EventBid,
+				Timestamp:   timestamp,
+				AuctionID:   req.ID,
+				RequestID:   response.RequestID,
+				PublisherID: publisherID,
+				Bidder:      code,
+				BidID:       tb.Bid.ID,
+				ImpID:       tb.Bid.ImpID,
+				MediaType:   string(tb.BidType),
+				Status:      status,
+				Price:       tb.Bid.Price,
+				Currency:    cur,
+			})
+		}
+	}
+
+	auction := analytics.Event{
+		Type:        analytics.EventAuction,
+		Timestamp:   timestamp,
+		AuctionID:   req.ID,
+		RequestID:   response.RequestID,
+		PublisherID: publisherID,
+		MediaType:   requestMediaType(req),
+		Status:      "unfilled",
+		Currency:    cur,
+		Fields: map[string]string{
+			"imps":       strconv.Itoa(len(req.Imp)),
+			"bidders":    strconv.Itoa(len(response.BidderResults)),
+			"bids":       strconv.Itoa(bids),
+			"latency_ms": strconv.FormatInt(response.DebugInfo.TotalLatency.Milliseconds(), 10),
+		},
+	}
+	if pod := response.PodFill; pod != nil {
+		auction.Fields["pod_slots"] = strconv.Itoa(pod.Requested)
+		auction.Fields["pod_filled"] = strconv.Itoa(pod.Filled)
+		auction.Fields["pod_total_price"] = strconv.FormatFloat(pod.TotalPrice, 'f', -1, 64)
+		auction.Fields["pod_avg_cpm"] = strconv.FormatFloat(pod.AvgCPM, 'f', -1, 64)
+		if pod.Rejected != "" {
+			auction.Fields["pod_rejected"] = pod.Rejected
+		}
+	}
+	if winner != nil {
+		auction.Status = "filled"
+		auction.Bidder = winningBidder(response.BidderResults, winner)
+		auction.BidID = winner.ID
+		auction.ImpID = winner.ImpID
+		auction.Price = winner.Price // response currency
+		if response.BidResponse.Cur != "" {
+			auction.Currency = response.BidResponse.Cur
+		}
+	}
+	t.Track(auction)
+}
@@ -0,0 +1,97 @@
+package exchange
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/analytics"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/internal/testfixtures"
+	"github.com/thenexusengine/tne_springwire/pkg/idr"
+)
+
+type mockAnalyticsTracker struct {
+	events []analytics.Event
+}
+
+func (m *mockAnalyticsTracker) Track(event analytics.Event) {
+	m.events = append(m.events, event)
+}
+
+func TestRunAuction_TracksAnalyticsEvents(t *testing.T) {
+	registry := adapters.NewRegistry()
+	registry.Register("low", &mockAdapter{bids: []*adapters.TypedBid{
+		{Bid: &openrtb.Bid{ID: "b1", ImpID: "imp-1", Price: 1.5, AdM: "<div>low</div>"}, BidType: adapters.BidTypeBanner},
+	}}, adapters.BidderInfo{Enabled: true})
+	registry.Register("high", &mockAdapter{bids: []*adapters.TypedBid{
+		{Bid: &openrtb.Bid{ID: "b2", ImpID: "imp-1", Price: 3, AdM: "<div>high</div>"}, BidType: adapters.BidTypeBanner},
+	}}, adapters.BidderInfo{Enabled: true})
+	ex := New(registry, &Config{DefaultTimeout: 100 * time.Millisecond, DefaultCurrency: "USD"})
+	tracker := &mockAnalyticsTracker{}
+	ex.SetAnalytics(tracker)
+
+	req := testfixtures.Request("auction-1").Site("example.com", "pub-1").Imp(testfixtures.Banner("imp-1", 300, 250)).Build()
+	if _, err := ex.RunAuction(context.Background(), &AuctionRequest{BidRequest: req}); err != nil {
+		t.Fatalf("auction failed: %v", err)
+	}
+
+	bids := make(map[string]analytics.Event)
+	var auction *analytics.Event
+	for i, e := range tracker.events {
+		switch e.Type {
+		case analytics.EventBid:
+			bids[e.Bidder] = e
+		case analytics.EventAuction:
+			auction = &tracker.events[i]
+		}
+	}
+	if bids["high"].Status != "won" || bids["high"].BidID != "b2" || bids["low"].Status != "lost" || bids["low"].Price != 1.5 {
+		t.Errorf("expected the high bid won and the low bid lost, got %+v", bids)
+	}
+	if auction == nil {
+		t.Fatal("expected an auction event")
+	}
+	if auction.Status != "filled" || auction.Bidder != "high" || auction.Price != 3 || auction.Currency != "USD" ||
+		auction.PublisherID != "pub-1" || auction.Fields["bids"] != "2" {
+		t.Errorf("unexpected auction event %+v", auction)
+	}
+
+	// Shadow traffic is not tracked
+	tracker.events = nil
+	req = testfixtures.Request("auction-2").Site("example.com", "pub-1").Imp(testfixtures.Banner("imp-1", 300, 250)).Build()
+	if _, err := ex.RunAuction(context.Background(), &AuctionRequest{BidRequest: req, Shadow: true}); err != nil {
+		t.Fatalf("auction failed: %v", err)
+	}
+	if len(tracker.events) != 0 {
+		t.Errorf("expected shadow auctions not to be tracked, got %+v", tracker.events)
+	}
+}
+
+func TestRunAuction_ShadowNotRecordedToIDR(t *testing.T) {
+	registry := adapters.NewRegistry()
+	registry.Register("rubicon", &mockAdapter{bids: []*adapters.TypedBid{
+		{Bid: &openrtb.Bid{ID: "b1", ImpID: "imp-1", Price: 1.5, AdM: "<div>ad</div>"}, BidType: adapters.BidTypeBanner},
+	}}, adapters.BidderInfo{Enabled: true})
+	ex := New(registry, &Config{DefaultTimeout: 100 * time.Millisecond, DefaultCurrency: "USD"})
+	recorder := idr.NewEventRecorder("", 10)
+	defer recorder.Close()
+	ex.eventRecorder = recorder
+
+	req := testfixtures.Request("auction-1").Site("example.com", "pub-1").Imp(testfixtures.Banner("imp-1", 300, 250)).Build()
+	if _, err := ex.RunAuction(context.Background(), &AuctionRequest{BidRequest: req, Shadow: true}); err != nil {
+		t.Fatalf("auction failed: %v", err)
+	}
+	if got := recorder.Stats().TotalEvents; got != 0 {
+		t.Errorf("expected shadow auctions not to record IDR events, got %d", got)
+	}
+
+	req = testfixtures.Request("auction-2").Site("example.com", "pub-1").Imp(testfixtures.Banner("imp-1", 300, 250)).Build()
+	if _, err := ex.RunAuction(context.Background(), &AuctionRequest{BidRequest: req}); err != nil {
+		t.Fatalf("auction failed: %v", err)
+	}
+	if got := recorder.Stats().TotalEvents; got != 1 {
+		t.Errorf("expected the live auction to record 1 IDR event, got %d", got)
+	}
+}
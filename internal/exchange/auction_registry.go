@@ -0,0 +1,79 @@
+package exchange
+
+import (
+	"github.com/thenexusengine/tne_springwire/internal/auctionregistry"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+// AuctionRegistry publishes a compact record of each auction for
+// downstream joins. Implemented by *auctionregistry.Registry.
+type AuctionRegistry interface {
+	Publish(summary auctionregistry.Summary)
+}
+
+// SetAuctionRegistry sets where auction summaries are published
+func (e *Exchange) SetAuctionRegistry(r AuctionRegistry) {
+	e.configMu.Lock()
+	defer e.configMu.Unlock()
+	e.auctionRegistry = r
+}
+
+// publishAuctionSummary publishes each impression's winning bid, or an
+// empty winner for impressions without bids, priced in the response
+// currency. The response seat hides platform bidders, so the real bidder is
+// looked up in the bidder results.
+func (e *Exchange) publishAuctionSummary(req *openrtb.BidRequest, response *AuctionResponse) {
+	e.configMu.RLock()
+	r := e.auctionRegistry
+	e.configMu.RUnlock()
+	if r == nil || req.ID == "" {
+		return
+	}
+
+	currency := e.config.DefaultCurrency
+	winners := make(map[string]*openrtb.Bid, len(req.Imp))
+	if resp := response.BidResponse; resp != nil {
+		if resp.Cur != "" {
+			currency = resp.Cur
+		}
+		for i := range resp.SeatBid {
+			for j := range resp.SeatBid[i].Bid {
+				bid := &resp.SeatBid[i].Bid[j]
+				if winner := winners[bid.ImpID]; winner == nil || bid.Price > winner.Price {
+					winners[bid.ImpID] = bid
+				}
+			}
+		}
+	}
+
+	publisherID := requestPublisherID(req)
+	for i := range req.Imp {
+		summary := auctionregistry.Summary{
+			AuctionID:   req.ID,
+			ImpID:       req.Imp[i].ID,
+			PublisherID: publisherID,
+			Currency:    currency,
+			Timestamp:   response.DebugInfo.RequestTime,
+		}
+		if winner := winners[req.Imp[i].ID]; winner != nil {
+			summary.Bidder = winningBidder(response.BidderResults, winner)
+			summary.Price = winner.Price
+		}
+		r.Publish(summary)
+	}
+}
+
+// winningBidder returns the bidder whose results contain bid
+func winningBidder(results map[string]*BidderResult, bid *openrtb.Bid) string {
+	for code, result := range results {
+		if result == nil {
+			continue
+		}
+		for _, tb := range result.Bids {
+			if tb != nil && tb.Bid != nil && tb.Bid.ID == bid.ID && tb.Bid.ImpID == bid.ImpID {
+				return code
+			}
+		}
+	}
+	return ""
+}
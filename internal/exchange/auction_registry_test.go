@@ -0,0 +1,122 @@
+package exchange
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/auctionregistry"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/internal/testfixtures"
+)
+
+type mockAuctionRegistry struct {
+	summaries []auctionregistry.Summary
+}
+
+func (m *mockAuctionRegistry) Publish(summary auctionregistry.Summary) {
+	m.summaries = append(m.summaries, summary)
+}
+
+func TestRunAuction_PublishesAuctionSummary(t *testing.T) {
+	registry := adapters.NewRegistry()
+	registry.Register("low", &mockAdapter{bids: []*adapters.TypedBid{
+		{Bid: &openrtb.Bid{ID: "b1", ImpID: "imp-1", Price: 1.5, AdM: "<div>low</div>"}, BidType: adapters.BidTypeBanner},
+	}}, adapters.BidderInfo{Enabled: true})
+	registry.Register("high", &mockAdapter{bids: []*adapters.TypedBid{
+		{Bid: &openrtb.Bid{ID: "b2", ImpID: "imp-1", Price: 3, AdM: "<div>high</div>"}, BidType: adapters.BidTypeBanner},
+	}}, adapters.BidderInfo{Enabled: true})
+	ex := New(registry, &Config{DefaultTimeout: 100 * time.Millisecond, DefaultCurrency: "USD"})
+	reg := &mockAuctionRegistry{}
+	ex.SetAuctionRegistry(reg)
+
+	req := testfixtures.Request("auction-1").Site("example.com", "pub-1").Imp(testfixtures.Banner("imp-1", 300, 250)).Build()
+	resp, err := ex.RunAuction(context.Background(), &AuctionRequest{BidRequest: req})
+	if err != nil {
+		t.Fatalf("auction failed: %v", err)
+	}
+	if len(resp.BidResponse.SeatBid) != 1 || resp.BidResponse.SeatBid[0].Seat != adapters.PlatformSeatName {
+		t.Fatalf("expected the winner in the platform seat, got %+v", resp.BidResponse.SeatBid)
+	}
+
+	if len(reg.summaries) != 1 {
+		t.Fatalf("expected one summary, got %d", len(reg.summaries))
+	}
+	s := reg.summaries[0]
+	if s.AuctionID != "auction-1" || s.ImpID != "imp-1" || s.PublisherID != "pub-1" || s.Bidder != "high" || s.Price != 3 || s.Currency != "USD" {
+		t.Errorf("unexpected summary %+v", s)
+	}
+	if s.Timestamp.IsZero() {
+		t.Error("expected the auction start time")
+	}
+
+	// Shadow traffic is not registered
+	req = testfixtures.Request("auction-2").Site("example.com", "pub-1").Imp(testfixtures.Banner("imp-1", 300, 250)).Build()
+	if _, err := ex.RunAuction(context.Background(), &AuctionRequest{BidRequest: req, Shadow: true}); err != nil {
+		t.Fatalf("auction failed: %v", err)
+	}
+	if len(reg.summaries) != 1 {
+		t.Errorf("expected shadow auctions not to be registered, got %+v", reg.summaries)
+	}
+}
+
+func TestPublishAuctionSummary_NoBids(t *testing.T) {
+	ex := New(adapters.NewRegistry(), &Config{DefaultTimeout: 100 * time.Millisecond, DefaultCurrency: "EUR"})
+	reg := &mockAuctionRegistry{}
+	ex.SetAuctionRegistry(reg)
+
+	req := testfixtures.Request("auction-1").Site("example.com", "pub-1").Imp(testfixtures.Video("imp-1")).Build()
+	ex.publishAuctionSummary(req, &AuctionResponse{DebugInfo: &DebugInfo{RequestTime: time.Now()}})
+	if len(reg.summaries) != 1 || reg.summaries[0].Bidder != "" || reg.summaries[0].Price != 0 || reg.summaries[0].Currency != "EUR" {
+		t.Errorf("expected an empty winner, got %+v", reg.summaries)
+	}
+}
+
+func TestPublishAuctionSummary_PerImp(t *testing.T) {
+	ex := New(adapters.NewRegistry(), &Config{DefaultTimeout: 100 * time.Millisecond, DefaultCurrency: "USD"})
+	reg := &mockAuctionRegistry{}
+	ex.SetAuctionRegistry(reg)
+
+	req := testfixtures.Request("auction-1").Site("example.com", "pub-1").
+		Imp(testfixtures.Banner("imp-1", 300, 250)).
+		Imp(testfixtures.Banner("imp-2", 728, 90)).
+		Imp(testfixtures.Banner("imp-3", 320, 50)).
+		Build()
+	bids := []*openrtb.Bid{
+		{ID: "b1", ImpID: "imp-1", Price: 1.5},
+		{ID: "b2", ImpID: "imp-1", Price: 2.5},
+		{ID: "b3", ImpID: "imp-2", Price: 4},
+	}
+	response := &AuctionResponse{
+		BidResponse: &openrtb.BidResponse{ID: "auction-1", Cur: "EUR", SeatBid: []openrtb.SeatBid{
+			{Seat: "a", Bid: []openrtb.Bid{*bids[0], *bids[2]}},
+			{Seat: "b", Bid: []openrtb.Bid{*bids[1]}},
+		}},
+		BidderResults: map[string]*BidderResult{
+			"a": {Bids: []*adapters.TypedBid{{Bid: bids[0]}, {Bid: bids[2]}}},
+			"b": {Bids: []*adapters.TypedBid{{Bid: bids[1]}}},
+		},
+		DebugInfo: &DebugInfo{RequestTime: time.Now()},
+	}
+	ex.publishAuctionSummary(req, response)
+
+	if len(reg.summaries) != 3 {
+		t.Fatalf("expected a summary per imp, got %+v", reg.summaries)
+	}
+	want := []struct {
+		imp    string
+		bidder string
+		price  float64
+	}{
+		{"imp-1", "b", 2.5},
+		{"imp-2", "a", 4},
+		{"imp-3", "", 0},
+	}
+	for i, w := range want {
+		s := reg.summaries[i]
+		if s.ImpID != w.imp || s.Bidder != w.bidder || s.Price != w.price || s.Currency != "EUR" {
+			t.Errorf("summary %d: expected %s won by %q at %v EUR, got %+v", i, w.imp, w.bidder, w.price, s)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package exchange
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/auctiontrail"
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+// AuctionTrailRecorder stores each auction's decision trail for lookup by
+// auction ID. Implemented by *auctiontrail.Recorder.
+type AuctionTrailRecorder interface {
+	Record(trail *auctiontrail.Trail)
+}
+
+// SetAuctionTrail sets where auction decision trails are recorded
+func (e *Exchange) SetAuctionTrail(r AuctionTrailRecorder) {
+	e.configMu.Lock()
+	defer e.configMu.Unlock()
+	e.auctionTrail = r
+}
+
+// startAuctionTrail returns an empty trail for the auction, or nil when
+// trails aren't recorded
+func (e *Exchange) startAuctionTrail(ctx context.Context, req *openrtb.BidRequest, startTime time.Time) *auctiontrail.Trail {
+	e.configMu.RLock()
+	r := e.auctionTrail
+	e.configMu.RUnlock()
+	if r == nil {
+		return nil
+	}
+
+	auctionType := "first_price"
+	if e.config.AuctionType == SecondPriceAuction {
+		auctionType = "second_price"
+	}
+	trail := &auctiontrail.Trail{
+		AuctionID:   req.ID,
+		RequestID:   logger.RequestIDFromContext(ctx),
+		PublisherID: requestPublisherID(req),
+		Timestamp:   startTime,
+		AuctionType: auctionType,
+		TieBreak:    e.config.TieBreak,
+		Currency:    e.config.DefaultCurrency,
+	}
+	if e.config.TieBreak != "" && e.config.TieBreak != TieBreakResponseOrder {
+		trail.TieSeed = strconv.FormatUint(AuctionSeed(req.ID), 16)
+	}
+	return trail
+}
+
+// publisherBidMultiplier returns the publisher's bid multiplier, or 0 when
+// none is configured
+func publisherBidMultiplier(ctx context.Context) float64 {
+	if pub := middleware.PublisherFromContext(ctx); pub != nil {
+		if m, ok := extractBidMultiplier(pub); ok {
+			return m
+		}
+	}
+	return 0
+}
+
+// traceBids adds every bid received to the trail: rejected with the
+// validation reason, or provisionally lost until settleAuctionTrail. It must
+// run before auction rules and the bid multiplier reprice the valid bids.
+func traceBids(trail *auctiontrail.Trail, results map[string]*BidderResult, rejected map[*adapters.TypedBid]*BidValidationError) {
+	codes := make([]string, 0, len(results))
+	for code := range results {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	for _, code := range codes {
+		for _, tb := range results[code].Bids {
+			if tb == nil || tb.Bid == nil {
+				continue
+			}
+			bid := auctiontrail.Bid{
+				Bidder:     code,
+				BidID:      tb.Bid.ID,
+				ImpID:      tb.Bid.ImpID,
+				Price:      tb.Bid.Price,
+				DealID:     tb.Bid.DealID,
+				CreativeID: tb.Bid.CRID,
+				ADomain:    tb.Bid.ADomain,
+				Outcome:    auctiontrail.BidLost,
+			}
+			if bve, ok := rejected[tb]; ok {
+				bid.Outcome = auctiontrail.BidRejected
+				if bve != nil {
+					bid.Reason = bve.Reason
+				}
+			}
+			trail.Bids = append(trail.Bids, bid)
+		}
+	}
+}
+
+// settleAuctionTrail marks the bids returned to the publisher as won, with
+// their pricing, and explains why the other valid bids lost
+func settleAuctionTrail(trail *auctiontrail.Trail, auctionedBids map[string][]ValidatedBid, resp *openrtb.BidResponse) {
+	if trail == nil {
+		return
+	}
+
+	// Bid IDs are unique once deduplicated
+	auctioned := make(map[string]ValidatedBid)
+	for _, bids := range auctionedBids {
+		for _, vb := range bids {
+			auctioned[vb.Bid.Bid.ID] = vb
+		}
+	}
+	returned := make(map[string]bool)
+	if resp != nil {
+		for _, sb := range resp.SeatBid {
+			for _, bid := range sb.Bid {
+				returned[bid.ID] = true
+			}
+		}
+	}
+
+	// Platform bids compete for one slot per impression
+	platformWinners := make(map[string]*auctiontrail.Bid)
+	for i := range trail.Bids {
+		b := &trail.Bids[i]
+		vb, ok := auctioned[b.BidID]
+		if b.Outcome != auctiontrail.BidLost || !ok || !returned[b.BidID] {
+			continue
+		}
+		net := vb.Bid.Bid.Price
+		clear := net
+		if vb.GrossPrice > 0 {
+			clear = vb.GrossPrice
+		}
+		margin := roundToCents(clear - net)
+		b.Outcome = auctiontrail.BidWon
+		b.Reason = fmt.Sprintf("returned at %.2f net (cleared at %.2f, margin %.2f)", net, clear, margin)
+		trail.Winners = append(trail.Winners, auctiontrail.Winner{
+			ImpID:      b.ImpID,
+			Bidder:     b.Bidder,
+			BidID:      b.BidID,
+			BidPrice:   b.Price,
+			ClearPrice: clear,
+			NetPrice:   net,
+			Margin:     margin,
+		})
+		if vb.DemandType != adapters.DemandTypePublisher {
+			platformWinners[b.ImpID] = b
+		}
+	}
+
+	for i := range trail.Bids {
+		b := &trail.Bids[i]
+		if b.Outcome != auctiontrail.BidLost {
+			continue
+		}
+		w := platformWinners[b.ImpID]
+		switch {
+		case w == nil:
+			b.Reason = "no bid on the impression cleared the auction"
+		case w.DealID != "" && w.Price < b.Price:
+			b.Reason = fmt.Sprintf("paced deal %s from %s took priority at %.2f", w.DealID, w.Bidder, w.Price)
+		default:
+			b.Reason = fmt.Sprintf("outbid by %s at %.2f", w.Bidder, w.Price)
+		}
+	}
+}
+
+// recordAuctionTrail completes the trail from the response, however the
+// auction ended, and records it
+func (e *Exchange) recordAuctionTrail(trail *auctiontrail.Trail, response *AuctionResponse) {
+	if trail == nil {
+		return
+	}
+	e.configMu.RLock()
+	r := e.auctionTrail
+	e.configMu.RUnlock()
+	if r == nil {
+		return
+	}
+
+	trail.LatencyMS = time.Since(trail.Timestamp).Milliseconds()
+	trail.Outcome = auctiontrail.OutcomeUnfilled
+	if resp := response.BidResponse; resp != nil {
+		trail.NoBidReason = resp.NBR
+		for _, sb := range resp.SeatBid {
+			if len(sb.Bid) > 0 {
+				trail.Outcome = auctiontrail.OutcomeFilled
+				break
+			}
+		}
+	}
+
+	// Why IDR picked or dropped each bidder
+	reasons := make(map[string]string)
+	if idrResult := response.IDRResult; idrResult != nil {
+		for _, sb := range idrResult.SelectedBidders {
+			reasons[sb.BidderCode] = "selected by IDR: " + sb.Reason
+		}
+		for _, eb := range idrResult.ExcludedBidders {
+			reasons[eb.BidderCode] = "excluded by IDR: " + eb.Reason
+		}
+	}
+
+	debug := response.DebugInfo
+	called := make(map[string]bool, len(debug.SelectedBidders))
+	for _, code := range debug.SelectedBidders {
+		called[code] = true
+		b := auctiontrail.Bidder{Code: code, Called: true, Reason: reasons[code]}
+		if result := response.BidderResults[code]; result != nil {
+			b.LatencyMS = result.Latency.Milliseconds()
+			b.TimedOut = result.TimedOut
+			b.Bids = len(result.Bids)
+			for _, err := range result.Errors {
+				b.Errors = append(b.Errors, err.Error())
+			}
+		}
+		trail.Bidders = append(trail.Bidders, b)
+	}
+	for _, code := range debug.ExcludedBidders {
+		if called[code] {
+			continue
+		}
+		reason := reasons[code]
+		if reason == "" {
+			reason = "dropped by the fan-out cap"
+		}
+		trail.Bidders = append(trail.Bidders, auctiontrail.Bidder{Code: code, Reason: reason})
+	}
+
+	// Errors not attributed to a bidder, such as FPD processing
+	debug.errorsMu.Lock()
+	for key, errs := range debug.Errors {
+		if _, isBidder := response.BidderResults[key]; isBidder || called[key] || len(errs) == 0 {
+			continue
+		}
+		if trail.Errors == nil {
+			trail.Errors = make(map[string]string)
+		}
+		trail.Errors[key] = strings.Join(errs, "; ")
+	}
+	debug.errorsMu.Unlock()
+
+	r.Record(trail)
+}
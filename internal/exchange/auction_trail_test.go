@@ -0,0 +1,104 @@
+package exchange
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/auctiontrail"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/internal/testfixtures"
+)
+
+type mockAuctionTrail struct {
+	trails []*auctiontrail.Trail
+}
+
+func (m *mockAuctionTrail) Record(trail *auctiontrail.Trail) {
+	m.trails = append(m.trails, trail)
+}
+
+func TestRunAuction_RecordsAuctionTrail(t *testing.T) {
+	registry := adapters.NewRegistry()
+	registry.Register("low", &mockAdapter{bids: []*adapters.TypedBid{
+		{Bid: &openrtb.Bid{ID: "b1", ImpID: "imp-1", Price: 1.5, AdM: "<div>low</div>"}, BidType: adapters.BidTypeBanner},
+		{Bid: &openrtb.Bid{ID: "b3", ImpID: "imp-unknown", Price: 9, AdM: "<div>lost</div>"}, BidType: adapters.BidTypeBanner},
+	}}, adapters.BidderInfo{Enabled: true})
+	registry.Register("high", &mockAdapter{bids: []*adapters.TypedBid{
+		{Bid: &openrtb.Bid{ID: "b2", ImpID: "imp-1", Price: 3, AdM: "<div>high</div>"}, BidType: adapters.BidTypeBanner},
+	}}, adapters.BidderInfo{Enabled: true})
+	ex := New(registry, &Config{DefaultTimeout: 100 * time.Millisecond, DefaultCurrency: "USD"})
+	rec := &mockAuctionTrail{}
+	ex.SetAuctionTrail(rec)
+
+	req := testfixtures.Request("auction-1").Site("example.com", "pub-1").Imp(testfixtures.Banner("imp-1", 300, 250)).Build()
+	if _, err := ex.RunAuction(context.Background(), &AuctionRequest{BidRequest: req}); err != nil {
+		t.Fatalf("auction failed: %v", err)
+	}
+
+	if len(rec.trails) != 1 {
+		t.Fatalf("expected one trail, got %d", len(rec.trails))
+	}
+	trail := rec.trails[0]
+	if trail.AuctionID != "auction-1" || trail.PublisherID != "pub-1" || trail.Outcome != auctiontrail.OutcomeFilled {
+		t.Errorf("unexpected trail %+v", trail)
+	}
+	if len(trail.Bidders) != 2 || !trail.Bidders[0].Called || !trail.Bidders[1].Called {
+		t.Errorf("expected both bidders called, got %+v", trail.Bidders)
+	}
+
+	outcomes := make(map[string]auctiontrail.Bid)
+	for _, bid := range trail.Bids {
+		outcomes[bid.BidID] = bid
+	}
+	if b := outcomes["b2"]; b.Outcome != auctiontrail.BidWon || b.Bidder != "high" {
+		t.Errorf("expected high's bid to win, got %+v", b)
+	}
+	if b := outcomes["b1"]; b.Outcome != auctiontrail.BidLost || b.Reason != "outbid by high at 3.00" {
+		t.Errorf("expected low's bid outbid, got %+v", b)
+	}
+	if b := outcomes["b3"]; b.Outcome != auctiontrail.BidRejected || b.Reason == "" || b.Price != 9 {
+		t.Errorf("expected the bid on an unknown imp rejected with a reason, got %+v", b)
+	}
+	if len(trail.Winners) != 1 || trail.Winners[0].Bidder != "high" || trail.Winners[0].NetPrice != 3 || trail.Winners[0].Margin != 0 {
+		t.Errorf("unexpected winners %+v", trail.Winners)
+	}
+
+	// Shadow traffic is not recorded
+	req = testfixtures.Request("auction-2").Site("example.com", "pub-1").Imp(testfixtures.Banner("imp-1", 300, 250)).Build()
+	if _, err := ex.RunAuction(context.Background(), &AuctionRequest{BidRequest: req, Shadow: true}); err != nil {
+		t.Fatalf("auction failed: %v", err)
+	}
+	if len(rec.trails) != 1 {
+		t.Errorf("expected shadow auctions not to be recorded, got %d trails", len(rec.trails))
+	}
+}
+
+func TestSettleAuctionTrail_Margin(t *testing.T) {
+	trail := &auctiontrail.Trail{Bids: []auctiontrail.Bid{
+		{Bidder: "appnexus", BidID: "b1", ImpID: "imp-1", Price: 2.1, Outcome: auctiontrail.BidLost},
+		{Bidder: "rubicon", BidID: "b2", ImpID: "imp-2", Price: 1, Outcome: auctiontrail.BidLost},
+	}}
+	auctioned := map[string][]ValidatedBid{
+		"imp-1": {{
+			Bid:        &adapters.TypedBid{Bid: &openrtb.Bid{ID: "b1", ImpID: "imp-1", Price: 2}},
+			BidderCode: "appnexus",
+			GrossPrice: 2.1,
+		}},
+	}
+	resp := &openrtb.BidResponse{SeatBid: []openrtb.SeatBid{{Seat: adapters.PlatformSeatName, Bid: []openrtb.Bid{{ID: "b1", ImpID: "imp-1"}}}}}
+
+	settleAuctionTrail(trail, auctioned, resp)
+
+	if len(trail.Winners) != 1 {
+		t.Fatalf("expected one winner, got %+v", trail.Winners)
+	}
+	w := trail.Winners[0]
+	if w.ClearPrice != 2.1 || w.NetPrice != 2 || w.Margin != 0.1 {
+		t.Errorf("unexpected winner pricing %+v", w)
+	}
+	if b := trail.Bids[1]; b.Outcome != auctiontrail.BidLost || b.Reason != "no bid on the impression cleared the auction" {
+		t.Errorf("expected the bid on the unfilled imp lost, got %+v", b)
+	}
+}
@@ -0,0 +1,323 @@
+package exchange
+
+import (
+	"container/heap"
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/pkg/kv"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+const (
+	// defaultImpExpiry is the billing window when neither the bid nor the imp sets exp
+	defaultImpExpiry = 300 * time.Second
+	// defaultExpiryRetention is how long expired bids are remembered so late
+	// billing calls can be told apart from unknown bids
+	defaultExpiryRetention = time.Hour
+	// maxTrackedBids bounds the registry; past this size the bids closest to
+	// aging out are dropped first
+	maxTrackedBids = 500000
+	// bidExpiryKeyPrefix namespaces tracked bids in the KV store; the bid ID follows
+	bidExpiryKeyPrefix = "pbs:bid_expiry:"
+	// bidExpiryStoreTimeout bounds each KV read or write
+	bidExpiryStoreTimeout = 100 * time.Millisecond
+)
+
+// BidExpiryStatus is the billing state of a previously returned bid
+type BidExpiryStatus int
+
+const (
+	// BidUnknown means the bid was never returned or has aged out of the registry
+	BidUnknown BidExpiryStatus = iota
+	// BidLive means the bid is still inside its billing window
+	BidLive
+	// BidExpired means the bid's billing window has passed
+	BidExpired
+)
+
+// BidNotice is what the exchange remembers about a returned bid for
+// processing its win and billing notices
+type BidNotice struct {
+	BidID       string
+	Bidder      string
+	AuctionID   string
+	ImpID       string
+	PublisherID string
+	MediaType   string
+	DealID      string
+	// Auction dimensions recorded with the win
+	Country    string
+	DeviceType string
+	AdSize     string
+	// Buyer as reported by the bidder (see buyerMeta); Advertiser is its
+	// name, falling back to its domain
+	Advertiser       string
+	AdvertiserDomain string
+	CampaignID       string
+	CreativeID       string
+	Price            float64 // publisher-facing price, after the bid multiplier
+	GrossPrice       float64 // bidder's price before the bid multiplier
+	Currency         string  // exchange currency of the prices
+	NURL             string  // bidder win notice URL
+	BURL             string  // bidder billing notice URL
+}
+
+// bidExpiryEntry records a returned bid and when it stops being billable
+type bidExpiryEntry struct {
+	notice    BidNotice
+	expiresAt time.Time
+	notified  []string // notice types whose URL has been fired
+}
+
+// storedBidExpiry is a tracked bid as written to the KV store
+type storedBidExpiry struct {
+	Notice    BidNotice `json:"notice"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// BidExpiryStore shares tracked bids between replicas, so a notice is
+// accepted whichever instance ran the auction; kv.Store satisfies it
+type BidExpiryStore interface {
+	Get(ctx context.Context, key string) (string, error)
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
+	SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error)
+}
+
+// Every KV backend satisfies BidExpiryStore
+var _ BidExpiryStore = kv.Store(nil)
+
+// dropItem is a bid queued for removal once its retention has passed
+type dropItem struct {
+	bidID  string
+	dropAt time.Time
+}
+
+// dropQueue is a min-heap of bids ordered by when they age out, so pruning
+// only looks at entries that are due
+type dropQueue []dropItem
+
+func (q dropQueue) Len() int            { return len(q) }
+func (q dropQueue) Less(i, j int) bool  { return q[i].dropAt.Before(q[j].dropAt) }
+func (q dropQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *dropQueue) Push(x interface{}) { *q = append(*q, x.(dropItem)) }
+func (q *dropQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+// BidExpiryRegistry tracks the billing window of bids returned by the exchange.
+// Win and billing notices for bids past their window are rejected. Bids are
+// kept in memory and, with a store set, in the KV store for other replicas.
+type BidExpiryRegistry struct {
+	mu        sync.Mutex
+	entries   map[string]bidExpiryEntry
+	drops     dropQueue
+	retention time.Duration
+	store     BidExpiryStore
+	now       func() time.Time
+}
+
+// NewBidExpiryRegistry creates a registry that remembers expired bids for retention
+func NewBidExpiryRegistry(retention time.Duration) *BidExpiryRegistry {
+	if retention <= 0 {
+		retention = defaultExpiryRetention
+	}
+	return &BidExpiryRegistry{
+		entries:   make(map[string]bidExpiryEntry),
+		retention: retention,
+		now:       time.Now,
+	}
+}
+
+// SetStore shares tracked bids and sent notices through store
+func (r *BidExpiryRegistry) SetStore(store BidExpiryStore) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.store = store
+}
+
+// Track records a returned bid and its billing window
+func (r *BidExpiryRegistry) Track(bidID, bidder string, exp time.Duration) {
+	r.TrackNotice(BidNotice{BidID: bidID, Bidder: bidder}, exp)
+}
+
+// TrackNotice records a returned bid with its notice details. The KV write
+// happens in the background so auctions don't wait on it, and is skipped
+// while the store is degraded.
+func (r *BidExpiryRegistry) TrackNotice(notice BidNotice, exp time.Duration) {
+	if notice.BidID == "" || exp <= 0 {
+		return
+	}
+
+	r.mu.Lock()
+	expiresAt := r.now().Add(exp)
+	r.addLocked(bidExpiryEntry{notice: notice, expiresAt: expiresAt})
+	store := r.store
+	r.mu.Unlock()
+
+	if store == nil || kv.IsDegraded(store) {
+		return
+	}
+	data, err := json.Marshal(storedBidExpiry{Notice: notice, ExpiresAt: expiresAt})
+	if err != nil {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), bidExpiryStoreTimeout)
+		defer cancel()
+		if err := store.Set(ctx, bidExpiryKeyPrefix+notice.BidID, string(data), exp+r.retention); err != nil {
+			logger.Log.Debug().Err(err).Str("bid_id", notice.BidID).Msg("Failed to share tracked bid")
+		}
+	}()
+}
+
+// addLocked stores an entry, pruning bids past their retention first and
+// dropping the ones closest to aging out while still full. Caller must hold mu.
+func (r *BidExpiryRegistry) addLocked(entry bidExpiryEntry) {
+	r.pruneLocked(r.now())
+	for len(r.entries) >= maxTrackedBids && r.drops.Len() > 0 {
+		r.dropLocked(heap.Pop(&r.drops).(dropItem))
+	}
+	id := entry.notice.BidID
+	r.entries[id] = entry
+	heap.Push(&r.drops, dropItem{bidID: id, dropAt: entry.expiresAt.Add(r.retention)})
+}
+
+// Check returns the bidder that returned a bid and whether it is still billable
+func (r *BidExpiryRegistry) Check(bidID string) (string, BidExpiryStatus) {
+	notice, status := r.Notice(bidID)
+	return notice.Bidder, status
+}
+
+// Notice returns the recorded details of a bid and whether it is still
+// billable. Bids this instance didn't return are looked up in the store.
+func (r *BidExpiryRegistry) Notice(bidID string) (BidNotice, BidExpiryStatus) {
+	entry, ok := r.entry(bidID)
+	if !ok {
+		return BidNotice{}, BidUnknown
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+	if now.After(entry.expiresAt.Add(r.retention)) {
+		delete(r.entries, bidID)
+		return BidNotice{}, BidUnknown
+	}
+	if now.After(entry.expiresAt) {
+		return entry.notice, BidExpired
+	}
+	return entry.notice, BidLive
+}
+
+// entry returns a tracked bid, reading one tracked by another replica from
+// the store and keeping it in memory. The store is read without holding mu
+// so auctions never wait on it.
+func (r *BidExpiryRegistry) entry(bidID string) (bidExpiryEntry, bool) {
+	r.mu.Lock()
+	entry, ok := r.entries[bidID]
+	store := r.store
+	r.mu.Unlock()
+	if ok || store == nil {
+		return entry, ok
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), bidExpiryStoreTimeout)
+	defer cancel()
+	raw, err := store.Get(ctx, bidExpiryKeyPrefix+bidID)
+	if err != nil || raw == "" {
+		return bidExpiryEntry{}, false
+	}
+	var stored storedBidExpiry
+	if err := json.Unmarshal([]byte(raw), &stored); err != nil || stored.Notice.BidID != bidID {
+		return bidExpiryEntry{}, false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if entry, ok := r.entries[bidID]; ok {
+		return entry, true
+	}
+	entry = bidExpiryEntry{notice: stored.Notice, expiresAt: stored.ExpiresAt}
+	r.addLocked(entry)
+	return entry, true
+}
+
+// MarkNotified records a notice of noticeType (win or billing) for the bid,
+// reporting false if it already had one or isn't tracked, so each is
+// counted and fired once however many times the player reports it. With a
+// store the notice is also claimed there, so it is fired once across
+// replicas; only the in-memory record is used when the store fails.
+func (r *BidExpiryRegistry) MarkNotified(bidID, noticeType string) bool {
+	if _, ok := r.entry(bidID); !ok {
+		return false
+	}
+
+	r.mu.Lock()
+	entry, ok := r.entries[bidID]
+	if !ok {
+		r.mu.Unlock()
+		return false
+	}
+	for _, t := range entry.notified {
+		if t == noticeType {
+			r.mu.Unlock()
+			return false
+		}
+	}
+	entry.notified = append(entry.notified, noticeType)
+	r.entries[bidID] = entry
+	store := r.store
+	ttl := entry.expiresAt.Add(r.retention).Sub(r.now())
+	r.mu.Unlock()
+
+	if store == nil || ttl <= 0 {
+		return true
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), bidExpiryStoreTimeout)
+	defer cancel()
+	claimed, err := store.SetNX(ctx, bidExpiryKeyPrefix+bidID+":"+noticeType, 1, ttl)
+	if err != nil {
+		logger.Log.Debug().Err(err).Str("bid_id", bidID).Msg("Failed to claim bid notice, deduplicating on this instance only")
+		return true
+	}
+	return claimed
+}
+
+// pruneLocked drops entries past their retention, oldest first, stopping at
+// the first one still due. Caller must hold mu.
+func (r *BidExpiryRegistry) pruneLocked(now time.Time) {
+	for r.drops.Len() > 0 && now.After(r.drops[0].dropAt) {
+		r.dropLocked(heap.Pop(&r.drops).(dropItem))
+	}
+}
+
+// dropLocked removes a queued bid unless it was tracked again since with a
+// later drop time. Caller must hold mu.
+func (r *BidExpiryRegistry) dropLocked(item dropItem) {
+	if entry, ok := r.entries[item.bidID]; ok && !entry.expiresAt.Add(r.retention).After(item.dropAt) {
+		delete(r.entries, item.bidID)
+	}
+}
+
+// effectiveExpiry returns the billing window for a bid: the shorter of the
+// bid's and the imp's exp, falling back to the configured default
+func effectiveExpiry(bid *openrtb.Bid, imp *openrtb.Imp, defaultExp time.Duration) time.Duration {
+	exp := 0
+	if bid != nil && bid.Exp > 0 {
+		exp = bid.Exp
+	}
+	if imp != nil && imp.Exp > 0 && (exp == 0 || imp.Exp < exp) {
+		exp = imp.Exp
+	}
+	if exp == 0 {
+		return defaultExp
+	}
+	return time.Duration(exp) * time.Second
+}
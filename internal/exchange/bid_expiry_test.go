@@ -0,0 +1,159 @@
+package exchange
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/pkg/kv"
+)
+
+func TestBidExpiryRegistry_Lifecycle(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	r := NewBidExpiryRegistry(time.Hour)
+	r.now = func() time.Time { return now }
+
+	r.Track("bid-1", "appnexus", 30*time.Second)
+
+	if bidder, status := r.Check("bid-1"); status != BidLive || bidder != "appnexus" {
+		t.Errorf("expected live bid from appnexus, got %q/%v", bidder, status)
+	}
+
+	now = now.Add(31 * time.Second)
+	if bidder, status := r.Check("bid-1"); status != BidExpired || bidder != "appnexus" {
+		t.Errorf("expected expired bid, got %q/%v", bidder, status)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if _, status := r.Check("bid-1"); status != BidUnknown {
+		t.Errorf("expected bid to age out after retention, got %v", status)
+	}
+
+	if _, status := r.Check("missing"); status != BidUnknown {
+		t.Errorf("expected unknown bid, got %v", status)
+	}
+}
+
+func TestBidExpiryRegistry_MarkNotified(t *testing.T) {
+	r := NewBidExpiryRegistry(time.Hour)
+	r.Track("bid-1", "appnexus", 30*time.Second)
+
+	if !r.MarkNotified("bid-1", "billing") {
+		t.Error("expected the first billing notice marked")
+	}
+	if r.MarkNotified("bid-1", "billing") {
+		t.Error("expected a repeat billing notice refused")
+	}
+	if !r.MarkNotified("bid-1", "win") {
+		t.Error("expected a win notice marked independently of billing")
+	}
+	if r.MarkNotified("missing", "win") {
+		t.Error("expected an untracked bid refused")
+	}
+}
+
+func TestBidExpiryRegistry_PrunesDueBids(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	r := NewBidExpiryRegistry(time.Minute)
+	r.now = func() time.Time { return now }
+
+	r.Track("old", "appnexus", 30*time.Second)
+	r.Track("long", "appnexus", time.Hour)
+	now = now.Add(2 * time.Minute)
+	r.Track("new", "rubicon", 30*time.Second)
+
+	if _, ok := r.entries["old"]; ok {
+		t.Error("expected a bid past its retention pruned on the next insert")
+	}
+	if len(r.entries) != 2 || r.drops.Len() != 2 {
+		t.Errorf("expected 2 bids tracked, got %d entries and %d queued", len(r.entries), r.drops.Len())
+	}
+}
+
+func TestBidExpiryRegistry_SharedStore(t *testing.T) {
+	store := kv.NewMemory()
+	auction := NewBidExpiryRegistry(time.Hour)
+	auction.SetStore(store)
+	notices := NewBidExpiryRegistry(time.Hour)
+	notices.SetStore(store)
+
+	auction.TrackNotice(BidNotice{BidID: "bid-1", Bidder: "appnexus", AuctionID: "auction-1"}, 30*time.Second)
+
+	// The store write happens in the background
+	deadline := time.Now().Add(time.Second)
+	for {
+		if v, _ := store.Get(context.Background(), bidExpiryKeyPrefix+"bid-1"); v != "" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the bid written to the store")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	notice, status := notices.Notice("bid-1")
+	if status != BidLive || notice.Bidder != "appnexus" || notice.AuctionID != "auction-1" {
+		t.Errorf("expected another replica to see the live bid, got %+v/%v", notice, status)
+	}
+	if !notices.MarkNotified("bid-1", "win") {
+		t.Error("expected the first win notice claimed")
+	}
+	if auction.MarkNotified("bid-1", "win") {
+		t.Error("expected the win notice refused on the replica that ran the auction")
+	}
+}
+
+func TestEffectiveExpiry(t *testing.T) {
+	tests := []struct {
+		name   string
+		bidExp int
+		impExp int
+		want   time.Duration
+	}{
+		{"default", 0, 0, 300 * time.Second},
+		{"bid only", 60, 0, 60 * time.Second},
+		{"imp only", 0, 120, 120 * time.Second},
+		{"imp caps bid", 600, 120, 120 * time.Second},
+		{"bid shorter than imp", 30, 120, 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := effectiveExpiry(&openrtb.Bid{Exp: tt.bidExp}, &openrtb.Imp{Exp: tt.impExp}, defaultImpExpiry)
+			if got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTrackBidExpiry_StampsExp(t *testing.T) {
+	ex := New(adapters.NewRegistry(), &Config{DefaultTimeout: 100 * time.Millisecond, ImpExpiry: 90 * time.Second})
+	bid := &openrtb.Bid{ID: "bid-1", ImpID: "imp-1", Price: 2.5, BURL: "https://bidder.example/bill"}
+	req := &openrtb.BidRequest{
+		ID:     "auction-1",
+		Imp:    []openrtb.Imp{{ID: "imp-1", Banner: &openrtb.Banner{W: 300, H: 250}}},
+		Site:   &openrtb.Site{Publisher: &openrtb.Publisher{ID: "pub-1"}},
+		Device: &openrtb.Device{DeviceType: 1, Geo: &openrtb.Geo{Country: "gbr"}},
+	}
+
+	ex.trackBidExpiry(bid, "rubicon", &openrtb.ExtBidPrebidMeta{MediaType: "banner", AdvertiserDomains: []string{"acme.example"}, CreativeID: "cr-9"}, 2.75, req)
+
+	if bid.Exp != 90 {
+		t.Errorf("expected exp 90, got %d", bid.Exp)
+	}
+	if bidder, status := ex.BidExpiry().Check("bid-1"); status != BidLive || bidder != "rubicon" {
+		t.Errorf("expected tracked live bid, got %q/%v", bidder, status)
+	}
+
+	notice, _ := ex.BidExpiry().Notice("bid-1")
+	want := BidNotice{BidID: "bid-1", Bidder: "rubicon", AuctionID: "auction-1", ImpID: "imp-1", PublisherID: "pub-1", MediaType: "banner",
+		Country: "GBR", DeviceType: "mobile", AdSize: "300x250",
+		Advertiser: "acme.example", AdvertiserDomain: "acme.example", CreativeID: "cr-9", Price: 2.5, GrossPrice: 2.75, Currency: "USD",
+		BURL: "https://bidder.example/bill"}
+	if notice != want {
+		t.Errorf("expected notice %+v, got %+v", want, notice)
+	}
+}
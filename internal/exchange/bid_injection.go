@@ -0,0 +1,198 @@
+package exchange
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+// BidInjectionHeader carries a signed canned bid response for one bidder on
+// one auction, so staging rendering tests don't depend on a partner bidding.
+//
+// The value is "<key id>.<base64url payload>.<base64url HMAC-SHA256>", where
+// the MAC covers "<key id>.<base64url payload>" and the payload is a
+// JSON-encoded BidInjection.
+const BidInjectionHeader = "X-Bid-Injection"
+
+const (
+	// maxBidInjectionSize bounds the header value
+	maxBidInjectionSize = 16 * 1024
+	// maxBidInjectionTTL bounds how far ahead an injection may expire
+	maxBidInjectionTTL = time.Hour
+)
+
+var (
+	// ErrBidInjectionDisabled is returned when no signing keys are configured
+	ErrBidInjectionDisabled = errors.New("bid injection is not enabled")
+	// ErrBidInjectionInvalid is returned for malformed or badly signed injections
+	ErrBidInjectionInvalid = errors.New("invalid bid injection")
+)
+
+// BidInjection is a canned bid response for a single bidder on a single auction
+type BidInjection struct {
+	Bidder    string              `json:"bidder"`
+	AuctionID string              `json:"auction_id"`
+	Expires   int64               `json:"exp"` // Unix seconds
+	Response  openrtb.BidResponse `json:"response"`
+
+	// KeyID is the key that signed the injection (set by ParseBidInjection)
+	KeyID string `json:"-"`
+}
+
+// SignBidInjection encodes and signs an injection for the BidInjectionHeader
+func SignBidInjection(keyID string, secret []byte, inj *BidInjection) (string, error) {
+	payload, err := json.Marshal(inj)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode bid injection: %w", err)
+	}
+	signed := keyID + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(bidInjectionMAC(secret, signed)), nil
+}
+
+func bidInjectionMAC(secret []byte, signed string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signed))
+	return mac.Sum(nil)
+}
+
+// SetBidInjectionKeys sets the keys accepted for BidInjectionHeader, by key
+// ID. Nil or empty disables injection.
+func (e *Exchange) SetBidInjectionKeys(keys map[string][]byte) {
+	e.configMu.Lock()
+	defer e.configMu.Unlock()
+	e.bidInjectionKeys = keys
+}
+
+// ParseBidInjection verifies a BidInjectionHeader value and checks that it
+// targets auctionID and hasn't expired
+func (e *Exchange) ParseBidInjection(header, auctionID string) (*BidInjection, error) {
+	e.configMu.RLock()
+	keys := e.bidInjectionKeys
+	e.configMu.RUnlock()
+
+	if len(keys) == 0 {
+		return nil, ErrBidInjectionDisabled
+	}
+	if len(header) > maxBidInjectionSize {
+		return nil, fmt.Errorf("%w: header exceeds %d bytes", ErrBidInjectionInvalid, maxBidInjectionSize)
+	}
+
+	parts := strings.Split(header, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: expected key.payload.signature", ErrBidInjectionInvalid)
+	}
+	secret, ok := keys[parts[0]]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown key %q", ErrBidInjectionInvalid, parts[0])
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(sig, bidInjectionMAC(secret, parts[0]+"."+parts[1])) {
+		return nil, fmt.Errorf("%w: bad signature", ErrBidInjectionInvalid)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("%w: bad payload encoding", ErrBidInjectionInvalid)
+	}
+	var inj BidInjection
+	if err := json.Unmarshal(payload, &inj); err != nil {
+		return nil, fmt.Errorf("%w: bad payload: %v", ErrBidInjectionInvalid, err)
+	}
+	inj.KeyID = parts[0]
+
+	if inj.Bidder == "" {
+		return nil, fmt.Errorf("%w: bidder is required", ErrBidInjectionInvalid)
+	}
+	if inj.AuctionID == "" || inj.AuctionID != auctionID {
+		return nil, fmt.Errorf("%w: signed for auction %q, not %q", ErrBidInjectionInvalid, inj.AuctionID, auctionID)
+	}
+	expires := time.Unix(inj.Expires, 0)
+	now := time.Now()
+	if !expires.After(now) {
+		return nil, fmt.Errorf("%w: expired at %s", ErrBidInjectionInvalid, expires.UTC().Format(time.RFC3339))
+	}
+	if expires.Sub(now) > maxBidInjectionTTL {
+		return nil, fmt.Errorf("%w: expiry more than %s ahead", ErrBidInjectionInvalid, maxBidInjectionTTL)
+	}
+
+	return &inj, nil
+}
+
+type bidInjectionContextKey struct{}
+
+// withBidInjection carries the auction's injection to the bidder fan-out
+func withBidInjection(ctx context.Context, inj *BidInjection) context.Context {
+	if inj == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, bidInjectionContextKey{}, inj)
+}
+
+// injectedAdapterFor returns a canned adapter when the auction injects a
+// response for bidderCode, or nil
+func injectedAdapterFor(ctx context.Context, bidderCode string) adapters.Adapter {
+	inj, _ := ctx.Value(bidInjectionContextKey{}).(*BidInjection)
+	if inj == nil || inj.Bidder != bidderCode {
+		return nil
+	}
+	return &injectedAdapter{
+		SimpleAdapter: adapters.SimpleAdapter{BidderCode: bidderCode},
+		response:      inj.Response,
+	}
+}
+
+// injectedAdapter answers with the canned response instead of calling the
+// bidder. Bids still go through the normal response, currency and bid
+// validation so the test exercises the real auction path.
+type injectedAdapter struct {
+	adapters.SimpleAdapter
+	response openrtb.BidResponse
+}
+
+// MakeRequests returns the canned response as a MOCK request
+func (a *injectedAdapter) MakeRequests(request *openrtb.BidRequest, _ *adapters.ExtraRequestInfo) ([]*adapters.RequestData, []error) {
+	resp := a.response
+	if resp.ID == "" {
+		resp.ID = request.ID
+	}
+	body, err := json.Marshal(&resp)
+	if err != nil {
+		return nil, []error{adapters.NewMarshalError(a.BidderCode, err)}
+	}
+	return []*adapters.RequestData{{Method: "MOCK", Body: body}}, nil
+}
+
+// applyBidInjection makes sure the injected bidder takes part in the auction
+// even when IDR or the fan-out cap left it out
+func (e *Exchange) applyBidInjection(inj *BidInjection, selectedBidders []string, debugInfo *DebugInfo) []string {
+	if inj == nil {
+		return selectedBidders
+	}
+	if _, ok := e.registry.Get(inj.Bidder); !ok {
+		debugInfo.AddError("bid_injection", []string{fmt.Sprintf("unknown bidder %q", inj.Bidder)})
+		return selectedBidders
+	}
+
+	logger.Log.Info().
+		Str("bidder", inj.Bidder).
+		Str("auction_id", inj.AuctionID).
+		Str("key_id", inj.KeyID).
+		Msg("Injecting canned bid response")
+
+	for _, code := range selectedBidders {
+		if code == inj.Bidder {
+			return selectedBidders
+		}
+	}
+	return append(selectedBidders, inj.Bidder)
+}
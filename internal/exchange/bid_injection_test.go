@@ -0,0 +1,152 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+var testInjectionSecret = []byte("0123456789abcdef0123456789abcdef")
+
+func testInjection(auctionID string) *BidInjection {
+	return &BidInjection{
+		Bidder:    "appnexus",
+		AuctionID: auctionID,
+		Expires:   time.Now().Add(5 * time.Minute).Unix(),
+		Response: openrtb.BidResponse{
+			SeatBid: []openrtb.SeatBid{{Bid: []openrtb.Bid{
+				{ID: "canned", ImpID: "imp1", Price: 3.5, AdM: "<div>test creative</div>"},
+			}}},
+		},
+	}
+}
+
+func TestParseBidInjection(t *testing.T) {
+	ex := New(adapters.NewRegistry(), DefaultConfig())
+
+	header, err := SignBidInjection("staging", testInjectionSecret, testInjection("auction-1"))
+	if err != nil {
+		t.Fatalf("sign failed: %v", err)
+	}
+
+	if _, err := ex.ParseBidInjection(header, "auction-1"); !errors.Is(err, ErrBidInjectionDisabled) {
+		t.Errorf("expected ErrBidInjectionDisabled without keys, got %v", err)
+	}
+
+	ex.SetBidInjectionKeys(map[string][]byte{"staging": testInjectionSecret})
+	inj, err := ex.ParseBidInjection(header, "auction-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if inj.Bidder != "appnexus" || inj.KeyID != "staging" || len(inj.Response.SeatBid) != 1 {
+		t.Errorf("unexpected injection: %+v", inj)
+	}
+
+	expired := testInjection("auction-1")
+	expired.Expires = time.Now().Add(-time.Minute).Unix()
+	expiredHeader, _ := SignBidInjection("staging", testInjectionSecret, expired)
+
+	tooLong := testInjection("auction-1")
+	tooLong.Expires = time.Now().Add(2 * maxBidInjectionTTL).Unix()
+	tooLongHeader, _ := SignBidInjection("staging", testInjectionSecret, tooLong)
+
+	otherKey, _ := SignBidInjection("staging", []byte("another-secret-another-secret-xx"), testInjection("auction-1"))
+	unknownKey, _ := SignBidInjection("prod", testInjectionSecret, testInjection("auction-1"))
+
+	parts := strings.Split(header, ".")
+	tampered, _ := SignBidInjection("staging", testInjectionSecret, testInjection("auction-2"))
+	tampered = parts[0] + "." + strings.Split(tampered, ".")[1] + "." + parts[2]
+
+	tests := []struct {
+		name      string
+		header    string
+		auctionID string
+	}{
+		{"other auction", header, "auction-2"},
+		{"expired", expiredHeader, "auction-1"},
+		{"expiry too far ahead", tooLongHeader, "auction-1"},
+		{"wrong secret", otherKey, "auction-1"},
+		{"unknown key", unknownKey, "auction-1"},
+		{"tampered payload", tampered, "auction-2"},
+		{"malformed", "not-a-signed-header", "auction-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ex.ParseBidInjection(tt.header, tt.auctionID); !errors.Is(err, ErrBidInjectionInvalid) {
+				t.Errorf("expected ErrBidInjectionInvalid, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRunAuction_BidInjection(t *testing.T) {
+	registry := adapters.NewRegistry()
+	registry.Register("appnexus", &mockAdapter{}, adapters.BidderInfo{Enabled: true})
+	registry.Register("rubicon", &mockAdapter{bids: []*adapters.TypedBid{
+		{Bid: &openrtb.Bid{ID: "real", ImpID: "imp1", Price: 1, AdM: "<div/>"}, BidType: adapters.BidTypeBanner},
+	}}, adapters.BidderInfo{Enabled: true})
+
+	ex := New(registry, &Config{DefaultTimeout: 100 * time.Millisecond})
+	ex.SetBidInjectionKeys(map[string][]byte{"staging": testInjectionSecret})
+
+	newRequest := func() *openrtb.BidRequest {
+		return &openrtb.BidRequest{
+			ID:   "auction-1",
+			Site: testSite(),
+			Imp:  []openrtb.Imp{{ID: "imp1", Banner: &openrtb.Banner{W: 300, H: 250}}},
+		}
+	}
+
+	resp, err := ex.RunAuction(context.Background(), &AuctionRequest{
+		BidRequest:   newRequest(),
+		BidInjection: testInjection("auction-1"),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var winner string
+	for _, sb := range resp.BidResponse.SeatBid {
+		for _, bid := range sb.Bid {
+			winner = bid.ID
+		}
+	}
+	if winner != "canned" {
+		t.Fatalf("expected the canned bid to win, got %q", winner)
+	}
+	if result := resp.BidderResults["appnexus"]; result == nil || len(result.Bids) != 1 {
+		t.Errorf("expected appnexus to answer with the canned bid, got %+v", result)
+	}
+
+	// Injected bids are still validated like real ones
+	ex.config.MaxBidCPM = 2
+	resp, err = ex.RunAuction(context.Background(), &AuctionRequest{
+		BidRequest:   newRequest(),
+		BidInjection: testInjection("auction-1"),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, sb := range resp.BidResponse.SeatBid {
+		for _, bid := range sb.Bid {
+			if bid.ID == "canned" {
+				t.Error("expected canned bid above max CPM to be rejected")
+			}
+		}
+	}
+}
+
+func TestApplyBidInjection_UnknownBidder(t *testing.T) {
+	ex := New(adapters.NewRegistry(), DefaultConfig())
+	debug := &DebugInfo{Errors: make(map[string][]string)}
+
+	bidders := ex.applyBidInjection(&BidInjection{Bidder: "nope"}, []string{"rubicon"}, debug)
+	if len(bidders) != 1 || len(debug.Errors["bid_injection"]) != 1 {
+		t.Errorf("expected unknown bidder reported and selection unchanged, got %v %v", bidders, debug.Errors)
+	}
+}
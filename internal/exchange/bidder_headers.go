@@ -0,0 +1,30 @@
+package exchange
+
+import "net/http"
+
+// SetBidderHeaders replaces the per-bidder outbound headers. Values must have
+// their secret references resolved (storage.Bidder.ResolveHTTPHeaders).
+func (e *Exchange) SetBidderHeaders(headers map[string]http.Header) {
+	e.configMu.Lock()
+	defer e.configMu.Unlock()
+	e.bidderHeaders = headers
+}
+
+// withBidderHeaders adds the bidder's configured headers to an adapter's
+// request headers, overriding headers of the same name
+func (e *Exchange) withBidderHeaders(bidderCode string, headers http.Header) http.Header {
+	e.configMu.RLock()
+	extra := e.bidderHeaders[bidderCode]
+	e.configMu.RUnlock()
+	if len(extra) == 0 {
+		return headers
+	}
+
+	if headers == nil {
+		headers = make(http.Header, len(extra))
+	}
+	for name, values := range extra {
+		headers[name] = append([]string(nil), values...)
+	}
+	return headers
+}
@@ -0,0 +1,29 @@
+package exchange
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+)
+
+func TestWithBidderHeaders(t *testing.T) {
+	ex := New(adapters.NewRegistry(), &Config{DefaultTimeout: 100 * time.Millisecond})
+	ex.SetBidderHeaders(map[string]http.Header{
+		"appnexus": {"X-Api-Key": {"s3cret"}, "Accept": {"application/json+rtb"}},
+	})
+
+	adapterHeaders := http.Header{"Accept": {"application/json"}, "Content-Type": {"application/json"}}
+	headers := ex.withBidderHeaders("appnexus", adapterHeaders)
+	if headers.Get("X-Api-Key") != "s3cret" || headers.Get("Accept") != "application/json+rtb" || headers.Get("Content-Type") != "application/json" {
+		t.Errorf("expected configured headers over the adapter's, got %v", headers)
+	}
+
+	if headers := ex.withBidderHeaders("appnexus", nil); headers.Get("X-Api-Key") != "s3cret" {
+		t.Errorf("expected configured headers without adapter headers, got %v", headers)
+	}
+	if headers := ex.withBidderHeaders("rubicon", http.Header{"Accept": {"application/json"}}); len(headers) != 1 {
+		t.Errorf("expected other bidders untouched, got %v", headers)
+	}
+}
@@ -0,0 +1,36 @@
+package exchange
+
+import "context"
+
+// QPSLimiter hands out per-bidder outbound call budgets; implemented by
+// qpslimit.Limiter
+type QPSLimiter interface {
+	Allow(ctx context.Context, bidder string, qps int) bool
+}
+
+// SetBidderMaxQPS replaces the per-bidder outbound QPS caps
+func (e *Exchange) SetBidderMaxQPS(limits map[string]int) {
+	e.configMu.Lock()
+	defer e.configMu.Unlock()
+	e.bidderMaxQPS = limits
+}
+
+// SetQPSLimiter sets the limiter enforcing bidder QPS caps. Without one,
+// caps are not enforced.
+func (e *Exchange) SetQPSLimiter(limiter QPSLimiter) {
+	e.configMu.Lock()
+	defer e.configMu.Unlock()
+	e.qpsLimiter = limiter
+}
+
+// allowBidderCall reports whether a call to the bidder fits its QPS cap
+func (e *Exchange) allowBidderCall(ctx context.Context, bidderCode string) bool {
+	e.configMu.RLock()
+	limiter := e.qpsLimiter
+	qps := e.bidderMaxQPS[bidderCode]
+	e.configMu.RUnlock()
+	if limiter == nil || qps <= 0 {
+		return true
+	}
+	return limiter.Allow(ctx, bidderCode, qps)
+}
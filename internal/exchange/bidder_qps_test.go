@@ -0,0 +1,67 @@
+package exchange
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+// exhaustedLimiter denies every capped call and records what was asked
+type exhaustedLimiter struct {
+	asked map[string]int
+}
+
+func (l *exhaustedLimiter) Allow(ctx context.Context, bidder string, qps int) bool {
+	l.asked[bidder] = qps
+	return false
+}
+
+// suppressionMetrics records QPS suppressions on top of mockMetrics
+type suppressionMetrics struct {
+	mockMetrics
+	suppressed []string
+}
+
+func (m *suppressionMetrics) RecordBidderQPSSuppressed(bidder string) {
+	m.suppressed = append(m.suppressed, bidder)
+}
+
+func TestRunAuction_BidderMaxQPS(t *testing.T) {
+	registry := adapters.NewRegistry()
+	for _, code := range []string{"capped", "uncapped"} {
+		registry.Register(code, &mockAdapter{}, adapters.BidderInfo{Enabled: true})
+	}
+	ex := New(registry, &Config{DefaultTimeout: 100 * time.Millisecond})
+	metrics := &suppressionMetrics{}
+	ex.SetMetrics(metrics)
+	limiter := &exhaustedLimiter{asked: make(map[string]int)}
+	ex.SetQPSLimiter(limiter)
+	ex.SetBidderMaxQPS(map[string]int{"capped": 50})
+
+	resp, err := ex.RunAuction(context.Background(), &AuctionRequest{
+		BidRequest: &openrtb.BidRequest{
+			ID:   "test-qps",
+			Site: testSite(),
+			Imp:  []openrtb.Imp{{ID: "imp1", Banner: &openrtb.Banner{W: 300, H: 250}}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(limiter.asked) != 1 || limiter.asked["capped"] != 50 {
+		t.Errorf("expected only the capped bidder to draw on a budget, got %v", limiter.asked)
+	}
+	if len(metrics.suppressed) != 1 || metrics.suppressed[0] != "capped" {
+		t.Errorf("expected one suppression for capped, got %v", metrics.suppressed)
+	}
+	if r := resp.BidderResults["capped"]; r == nil || len(r.Errors) == 0 || r.TimedOut {
+		t.Errorf("expected capped to be skipped without a timeout, got %+v", r)
+	}
+	if r := resp.BidderResults["uncapped"]; r == nil || len(r.Errors) != 0 {
+		t.Errorf("expected uncapped to be called, got %+v", r)
+	}
+}
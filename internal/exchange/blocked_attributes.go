@@ -0,0 +1,90 @@
+package exchange
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+// extractBlockedAttributes safely extracts the publisher's default blocked
+// creative attributes (storage.Publisher.BlockedAttributes)
+func extractBlockedAttributes(v interface{}) []int {
+	type blockedAttributesGetter interface {
+		GetBlockedAttributes() []int
+	}
+	if getter, ok := v.(blockedAttributesGetter); ok {
+		return getter.GetBlockedAttributes()
+	}
+	return nil
+}
+
+// applyPublisherBlockedAttributes copies the publisher's default battr into
+// every banner, video, audio and native object that doesn't set its own.
+// Objects with an explicit battr are left alone so a request can override the
+// publisher default.
+func applyPublisherBlockedAttributes(ctx context.Context, req *openrtb.BidRequest) {
+	pub := middleware.PublisherFromContext(ctx)
+	if pub == nil {
+		return
+	}
+	defaults := extractBlockedAttributes(pub)
+	if len(defaults) == 0 {
+		return
+	}
+
+	merge := func(battr []int) []int {
+		if len(battr) > 0 {
+			return battr
+		}
+		return append([]int(nil), defaults...)
+	}
+	for i := range req.Imp {
+		imp := &req.Imp[i]
+		if imp.Banner != nil {
+			imp.Banner.BAttr = merge(imp.Banner.BAttr)
+		}
+		if imp.Video != nil {
+			imp.Video.BAttr = merge(imp.Video.BAttr)
+		}
+		if imp.Audio != nil {
+			imp.Audio.BAttr = merge(imp.Audio.BAttr)
+		}
+		if imp.Native != nil {
+			imp.Native.BAttr = merge(imp.Native.BAttr)
+		}
+	}
+}
+
+// validateBidAttributes rejects bids declaring a creative attribute blocked
+// by any media object of the impression
+func validateBidAttributes(bid *openrtb.Bid, imp *openrtb.Imp) error {
+	if len(bid.Attr) == 0 {
+		return nil
+	}
+	blocked := make(map[int]bool)
+	add := func(battr []int) {
+		for _, a := range battr {
+			blocked[a] = true
+		}
+	}
+	if imp.Banner != nil {
+		add(imp.Banner.BAttr)
+	}
+	if imp.Video != nil {
+		add(imp.Video.BAttr)
+	}
+	if imp.Audio != nil {
+		add(imp.Audio.BAttr)
+	}
+	if imp.Native != nil {
+		add(imp.Native.BAttr)
+	}
+	for _, a := range bid.Attr {
+		if blocked[a] {
+			return fmt.Errorf("blocked creative attribute: %d", a)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package exchange
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+)
+
+func TestApplyPublisherBlockedAttributes(t *testing.T) {
+	pub := &storage.Publisher{PublisherID: "pub1", BlockedAttributes: []int{1, 6}}
+	ctx := middleware.NewContextWithPublisher(context.Background(), pub)
+
+	req := &openrtb.BidRequest{Imp: []openrtb.Imp{
+		{ID: "1", Banner: &openrtb.Banner{}, Video: &openrtb.Video{BAttr: []int{8}}},
+		{ID: "2", Audio: &openrtb.Audio{}, Native: &openrtb.Native{}},
+	}}
+	applyPublisherBlockedAttributes(ctx, req)
+
+	if !reflect.DeepEqual(req.Imp[0].Banner.BAttr, []int{1, 6}) {
+		t.Errorf("expected defaults on banner, got %v", req.Imp[0].Banner.BAttr)
+	}
+	if !reflect.DeepEqual(req.Imp[0].Video.BAttr, []int{8}) {
+		t.Errorf("expected explicit video battr kept, got %v", req.Imp[0].Video.BAttr)
+	}
+	if !reflect.DeepEqual(req.Imp[1].Audio.BAttr, []int{1, 6}) || !reflect.DeepEqual(req.Imp[1].Native.BAttr, []int{1, 6}) {
+		t.Errorf("expected defaults on audio and native, got %v / %v", req.Imp[1].Audio.BAttr, req.Imp[1].Native.BAttr)
+	}
+
+	// Merged slices must not alias the publisher's defaults
+	req.Imp[0].Banner.BAttr[0] = 99
+	if pub.BlockedAttributes[0] != 1 {
+		t.Error("merging aliased the publisher's blocked attributes")
+	}
+
+	// No publisher: request untouched
+	plain := &openrtb.BidRequest{Imp: []openrtb.Imp{{ID: "1", Banner: &openrtb.Banner{}}}}
+	applyPublisherBlockedAttributes(context.Background(), plain)
+	if plain.Imp[0].Banner.BAttr != nil {
+		t.Errorf("expected no battr without publisher, got %v", plain.Imp[0].Banner.BAttr)
+	}
+}
+
+func TestValidateBidAttributes(t *testing.T) {
+	imp := &openrtb.Imp{ID: "1", Banner: &openrtb.Banner{BAttr: []int{1, 6}}}
+
+	if err := validateBidAttributes(&openrtb.Bid{Attr: []int{6}}, imp); err == nil {
+		t.Error("expected bid with blocked attribute 6 to be rejected")
+	}
+	if err := validateBidAttributes(&openrtb.Bid{Attr: []int{2, 4}}, imp); err != nil {
+		t.Errorf("expected allowed attributes to pass, got %v", err)
+	}
+	if err := validateBidAttributes(&openrtb.Bid{}, imp); err != nil {
+		t.Errorf("expected bid without attr to pass, got %v", err)
+	}
+}
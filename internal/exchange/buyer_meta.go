@@ -0,0 +1,138 @@
+package exchange
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+// maxBuyerMetaLen bounds each buyer-supplied meta string kept for reporting
+const maxBuyerMetaLen = 128
+
+// buyerExt is the subset of a bidder's bid.ext read for buyer metadata.
+// Bidders send the same facts under several spellings; the first non-empty
+// one wins.
+type buyerExt struct {
+	Prebid *struct {
+		Meta *openrtb.ExtBidPrebidMeta `json:"meta"`
+	} `json:"prebid"`
+	AdvertiserName      string `json:"advertiserName"`
+	AdvertiserNameSnake string `json:"advertiser_name"`
+	Advertiser          string `json:"advertiser"`
+	CampaignID          string `json:"campaignId"`
+	CampaignIDSnake     string `json:"campaign_id"`
+	CreativeID          string `json:"creativeId"`
+	CreativeIDSnake     string `json:"creative_id"`
+}
+
+// buyerMeta normalizes who bought an impression from the adapter's meta, the
+// bidder's bid.ext (prebid.meta or top-level advertiser/campaign/creative
+// keys) and the bid's adomain, cid and crid, in that order of preference, so
+// reporting can show which advertiser ran without parsing creative markup.
+// Returns nil when neither the adapter nor the bid says anything about the buyer.
+func buyerMeta(bid *openrtb.Bid, adapterMeta *openrtb.ExtBidPrebidMeta) *openrtb.ExtBidPrebidMeta {
+	if bid == nil {
+		return nil
+	}
+
+	meta := &openrtb.ExtBidPrebidMeta{}
+	if adapterMeta != nil {
+		*meta = *adapterMeta
+	}
+
+	var ext buyerExt
+	if len(bid.Ext) > 0 && json.Unmarshal(bid.Ext, &ext) == nil {
+		if ext.Prebid != nil && ext.Prebid.Meta != nil {
+			mergeBuyerMeta(meta, ext.Prebid.Meta)
+		}
+		mergeBuyerMeta(meta, &openrtb.ExtBidPrebidMeta{
+			AdvertiserName: firstNonEmpty(ext.AdvertiserName, ext.AdvertiserNameSnake, ext.Advertiser),
+			CampaignID:     firstNonEmpty(ext.CampaignID, ext.CampaignIDSnake),
+			CreativeID:     firstNonEmpty(ext.CreativeID, ext.CreativeIDSnake),
+		})
+	}
+	mergeBuyerMeta(meta, &openrtb.ExtBidPrebidMeta{
+		AdvertiserDomains: bid.ADomain,
+		CampaignID:        bid.CID,
+		CreativeID:        bid.CRID,
+	})
+
+	meta.AdvertiserName = truncateBuyerMeta(meta.AdvertiserName)
+	meta.CampaignID = truncateBuyerMeta(meta.CampaignID)
+	meta.CreativeID = truncateBuyerMeta(meta.CreativeID)
+	domains := make([]string, 0, len(meta.AdvertiserDomains))
+	for _, d := range meta.AdvertiserDomains {
+		if d = normalizeAdvertiserDomain(d); d != "" {
+			domains = append(domains, truncateBuyerMeta(d))
+		}
+	}
+	meta.AdvertiserDomains = nil
+	if len(domains) > 0 {
+		meta.AdvertiserDomains = domains
+	}
+
+	if adapterMeta == nil && meta.AdvertiserName == "" && meta.AdvertiserID == 0 && len(meta.AdvertiserDomains) == 0 &&
+		meta.CampaignID == "" && meta.CreativeID == "" && meta.BrandName == "" {
+		return nil
+	}
+	return meta
+}
+
+// advertiserOf returns the meta's advertiser for reporting: its name, falling
+// back to its first advertiser domain
+func advertiserOf(meta *openrtb.ExtBidPrebidMeta) string {
+	if meta == nil {
+		return ""
+	}
+	if meta.AdvertiserName != "" {
+		return meta.AdvertiserName
+	}
+	if len(meta.AdvertiserDomains) > 0 {
+		return meta.AdvertiserDomains[0]
+	}
+	return ""
+}
+
+// mergeBuyerMeta fills the buyer fields dst is missing from src
+func mergeBuyerMeta(dst, src *openrtb.ExtBidPrebidMeta) {
+	if dst.AdvertiserID == 0 {
+		dst.AdvertiserID = src.AdvertiserID
+	}
+	if dst.AdvertiserName == "" {
+		dst.AdvertiserName = strings.TrimSpace(src.AdvertiserName)
+	}
+	if len(dst.AdvertiserDomains) == 0 {
+		dst.AdvertiserDomains = src.AdvertiserDomains
+	}
+	if dst.BrandID == 0 {
+		dst.BrandID = src.BrandID
+	}
+	if dst.BrandName == "" {
+		dst.BrandName = strings.TrimSpace(src.BrandName)
+	}
+	if dst.CampaignID == "" {
+		dst.CampaignID = strings.TrimSpace(src.CampaignID)
+	}
+	if dst.CreativeID == "" {
+		dst.CreativeID = strings.TrimSpace(src.CreativeID)
+	}
+}
+
+// firstNonEmpty returns the first value that isn't blank, trimmed
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// truncateBuyerMeta keeps buyer strings short enough to store and label by
+func truncateBuyerMeta(s string) string {
+	if len(s) <= maxBuyerMetaLen {
+		return s
+	}
+	return strings.ToValidUTF8(s[:maxBuyerMetaLen], "")
+}
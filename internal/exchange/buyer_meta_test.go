@@ -0,0 +1,97 @@
+package exchange
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+func TestBuyerMeta(t *testing.T) {
+	tests := []struct {
+		name    string
+		bid     openrtb.Bid
+		adapter *openrtb.ExtBidPrebidMeta
+		want    *openrtb.ExtBidPrebidMeta
+	}{
+		{
+			name: "nothing about the buyer",
+			bid:  openrtb.Bid{ID: "b1", Ext: json.RawMessage(`{"foo":1}`)},
+			want: nil,
+		},
+		{
+			name: "openrtb fields",
+			bid:  openrtb.Bid{ADomain: []string{"WWW.Acme.example", ""}, CID: "camp-1", CRID: "cr-1"},
+			want: &openrtb.ExtBidPrebidMeta{AdvertiserDomains: []string{"acme.example"}, CampaignID: "camp-1", CreativeID: "cr-1"},
+		},
+		{
+			name: "prebid meta preferred over top-level keys and openrtb fields",
+			bid: openrtb.Bid{CID: "cid", CRID: "crid", Ext: json.RawMessage(
+				`{"prebid":{"meta":{"advertiserName":"Acme Motors","advertiserId":42}},"advertiser_name":"Other","campaign_id":"camp-ext"}`)},
+			want: &openrtb.ExtBidPrebidMeta{AdvertiserID: 42, AdvertiserName: "Acme Motors", CampaignID: "camp-ext", CreativeID: "crid"},
+		},
+		{
+			name:    "adapter meta preferred over ext",
+			bid:     openrtb.Bid{Ext: json.RawMessage(`{"advertiserName":"Ext Name","creativeId":" cr-ext "}`)},
+			adapter: &openrtb.ExtBidPrebidMeta{AdvertiserName: "Adapter Name", NetworkName: "net"},
+			want:    &openrtb.ExtBidPrebidMeta{AdvertiserName: "Adapter Name", NetworkName: "net", CreativeID: "cr-ext"},
+		},
+		{
+			name: "malformed ext falls back to openrtb fields",
+			bid:  openrtb.Bid{CRID: "cr-1", Ext: json.RawMessage(`{"advertiser":`)},
+			want: &openrtb.ExtBidPrebidMeta{CreativeID: "cr-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := buyerMeta(&tt.bid, tt.adapter); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestBuyerMeta_Truncates(t *testing.T) {
+	meta := buyerMeta(&openrtb.Bid{CRID: strings.Repeat("x", 500)}, nil)
+	if len(meta.CreativeID) != maxBuyerMetaLen {
+		t.Errorf("expected creative ID cut to %d bytes, got %d", maxBuyerMetaLen, len(meta.CreativeID))
+	}
+}
+
+func TestBuildBidExtension_BuyerMeta(t *testing.T) {
+	ex := New(adapters.NewRegistry(), nil)
+	vb := ValidatedBid{
+		Bid: &adapters.TypedBid{
+			Bid: &openrtb.Bid{
+				ID: "bid1", ImpID: "imp1", Price: 2.5, ADomain: []string{"acme.example"}, CRID: "cr-1",
+				Ext: json.RawMessage(`{"advertiser_name":"Acme Motors","campaign_id":"spring"}`),
+			},
+			BidType: adapters.BidTypeVideo,
+		},
+		BidderCode: "appnexus",
+		DemandType: adapters.DemandTypePlatform,
+	}
+
+	ext := ex.buildBidExtension(vb)
+
+	want := &openrtb.ExtBidPrebidMeta{
+		AdvertiserName:    "Acme Motors",
+		AdvertiserDomains: []string{"acme.example"},
+		CampaignID:        "spring",
+		CreativeID:        "cr-1",
+		MediaType:         "video",
+	}
+	if !reflect.DeepEqual(ext.Prebid.Meta, want) {
+		t.Errorf("expected meta %+v, got %+v", want, ext.Prebid.Meta)
+	}
+	if ext.Prebid.Targeting["hb_adomain"] != "acme.example" || ext.Prebid.Targeting["hb_adomain_thenexusengine"] != "acme.example" {
+		t.Errorf("expected hb_adomain targeting, got %v", ext.Prebid.Targeting)
+	}
+	if advertiserOf(ext.Prebid.Meta) != "Acme Motors" {
+		t.Errorf("expected advertiser name for reporting, got %q", advertiserOf(ext.Prebid.Meta))
+	}
+}
@@ -0,0 +1,29 @@
+package exchange
+
+import (
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+// identifierScrub returns the identifier fields to remove from a request
+// before it reaches bidders: the COPPA policy for child-directed traffic
+// and the LMT policy for devices with limited ad tracking, combined when
+// both flags are set
+func (e *Exchange) identifierScrub(req *openrtb.BidRequest) middleware.ScrubPolicy {
+	var policy middleware.ScrubPolicy
+	if middleware.IsCOPPA(req) {
+		policy = policy.Union(scrubPolicyOrDefault(e.config.COPPAScrub, middleware.DefaultCOPPAScrub))
+	}
+	if middleware.IsLMT(req) {
+		policy = policy.Union(scrubPolicyOrDefault(e.config.LMTScrub, middleware.DefaultLMTScrub))
+	}
+	return policy
+}
+
+// scrubPolicyOrDefault returns the configured policy, or def when unset
+func scrubPolicyOrDefault(configured *middleware.ScrubPolicy, def middleware.ScrubPolicy) middleware.ScrubPolicy {
+	if configured == nil {
+		return def
+	}
+	return *configured
+}
@@ -0,0 +1,87 @@
+package exchange
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/internal/testfixtures"
+)
+
+func TestRunAuction_COPPAAndLMTScrubbing(t *testing.T) {
+	ipOnly := middleware.ScrubPolicy{IP: true}
+	tests := []struct {
+		name         string
+		app          bool
+		coppa        bool
+		lmt          bool
+		lmtScrub     *middleware.ScrubPolicy
+		wantScrubbed bool // user IDs, buyeruid, IFA and precise geo removed
+		wantIPCut    bool
+	}{
+		{name: "site without flags"},
+		{name: "app without flags", app: true},
+		{name: "site COPPA", coppa: true, wantScrubbed: true, wantIPCut: true},
+		{name: "app COPPA", app: true, coppa: true, wantScrubbed: true, wantIPCut: true},
+		{name: "site LMT", lmt: true, wantScrubbed: true},
+		{name: "app LMT", app: true, lmt: true, wantScrubbed: true},
+		{name: "app LMT with an IP-only policy", app: true, lmt: true, lmtScrub: &ipOnly, wantIPCut: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := adapters.NewRegistry()
+			bidder := &requestCapturingAdapter{}
+			if err := registry.Register("bidder", bidder, adapters.BidderInfo{Enabled: true}); err != nil {
+				t.Fatal(err)
+			}
+			ex := New(registry, &Config{DefaultTimeout: 100 * time.Millisecond, LMTScrub: tt.lmtScrub})
+
+			builder := testfixtures.Request("auction-1").Imp(testfixtures.Video("imp-1")).
+				User("user-1", "buyer-1").Device("Mozilla/5.0", "203.0.113.42", 4)
+			if tt.app {
+				builder.App("com.example.app", "pub-1")
+			} else {
+				builder.Site("example.com", "pub-1")
+			}
+			consent := testfixtures.NoGDPR()
+			if tt.coppa {
+				consent.COPPA()
+			}
+			req := builder.Consent(consent).Build()
+			req.Device.IFA = "6D92078A-8246-4BA4-AE5B-76104861E7DC"
+			req.Device.Geo = &openrtb.Geo{Lat: 40.7, Lon: -74.0, Country: "USA"}
+			if tt.lmt {
+				lmt := 1
+				req.Device.Lmt = &lmt
+			}
+
+			if _, err := ex.RunAuction(context.Background(), &AuctionRequest{BidRequest: req}); err != nil {
+				t.Fatalf("auction failed: %v", err)
+			}
+			sent := bidder.lastRequest
+			if sent == nil {
+				t.Fatal("expected the bidder to be sent the request")
+			}
+			if (sent.App != nil) != tt.app {
+				t.Fatalf("expected app traffic=%v, got %+v", tt.app, sent.App)
+			}
+			scrubbed := sent.User.ID == "" && sent.User.BuyerUID == "" && sent.Device.IFA == "" && sent.Device.Geo.Lat == 0
+			kept := sent.User.ID == "user-1" && sent.User.BuyerUID == "buyer-1" && sent.Device.IFA != "" && sent.Device.Geo.Lat == 40.7
+			if tt.wantScrubbed && !scrubbed || !tt.wantScrubbed && !kept {
+				t.Errorf("expected scrubbed=%v, got user %+v device %+v", tt.wantScrubbed, sent.User, sent.Device)
+			}
+			if ipCut := sent.Device.IP != "203.0.113.42"; ipCut != tt.wantIPCut {
+				t.Errorf("expected IP truncated=%v, got %q", tt.wantIPCut, sent.Device.IP)
+			}
+			if sent.Device.Geo.Country != "USA" {
+				t.Errorf("expected the country kept, got %q", sent.Device.Geo.Country)
+			}
+			if req.User.ID != "user-1" || req.Device.IFA == "" {
+				t.Error("expected the original request left untouched")
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package exchange
+
+import (
+	"context"
+
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+// Unreviewed creative policies, set per publisher
+// (storage.Publisher.CreativeApproval)
+const (
+	// CreativeApprovalFlag serves unreviewed creatives and counts them as
+	// flagged for review
+	CreativeApprovalFlag = "flag"
+	// CreativeApprovalHold rejects unreviewed creatives until they're approved
+	CreativeApprovalHold = "hold"
+)
+
+// Creative approval outcomes, used as metric labels
+const (
+	CreativeOutcomeApproved   = "approved"
+	CreativeOutcomeUnreviewed = "unreviewed" // new creative, publisher has no policy
+	CreativeOutcomeFlagged    = "flagged"
+	CreativeOutcomeHeld       = "held"
+	CreativeOutcomeBlocked    = "blocked"
+)
+
+// Creative review statuses returned by CreativeRegistry (storage.CreativeStatus*)
+const (
+	creativeStatusApproved = "approved"
+	creativeStatusBlocked  = "blocked"
+)
+
+// CreativeRegistry tracks the review status of creatives by hash;
+// implemented by creatives.Registry
+type CreativeRegistry interface {
+	// Review returns the bid's creative hash and its status (new, approved
+	// or blocked), registering creatives it hasn't seen as new when register
+	// is set. Bids without a crid have no hash and are always new.
+	Review(bidderCode string, bid *openrtb.Bid, register bool) (hash, status string)
+}
+
+// SetCreativeRegistry enables creative approval: blocked creatives are
+// rejected, and unreviewed ones are handled per publisher policy
+func (e *Exchange) SetCreativeRegistry(registry CreativeRegistry) {
+	e.configMu.Lock()
+	defer e.configMu.Unlock()
+	e.creativeRegistry = registry
+}
+
+// extractCreativeApproval safely extracts the publisher's unreviewed creative
+// policy (storage.Publisher.CreativeApproval)
+func extractCreativeApproval(v interface{}) string {
+	type creativeApprovalGetter interface {
+		GetCreativeApproval() string
+	}
+	if getter, ok := v.(creativeApprovalGetter); ok {
+		return getter.GetCreativeApproval()
+	}
+	return ""
+}
+
+// creativeApproval returns the publisher's unreviewed creative policy, or ""
+// when unreviewed creatives are served unflagged. Unknown policies serve them.
+func (e *Exchange) creativeApproval(ctx context.Context) string {
+	pub := middleware.PublisherFromContext(ctx)
+	if pub == nil {
+		return ""
+	}
+	switch policy := extractCreativeApproval(pub); policy {
+	case CreativeApprovalFlag, CreativeApprovalHold:
+		return policy
+	}
+	return ""
+}
+
+// checkCreativeApproval rejects blocked creatives, and unreviewed ones when
+// policy is hold, counting every reviewed bid by outcome. Only publishers
+// with a policy register unseen creatives for review, so the registry holds
+// what reviewers need rather than every creative. Nothing is checked
+// without a registry.
+func (e *Exchange) checkCreativeApproval(registry CreativeRegistry, bid *openrtb.Bid, bidderCode, policy string) *BidValidationError {
+	if registry == nil {
+		return nil
+	}
+
+	hash, status := registry.Review(bidderCode, bid, policy != "")
+	var outcome, reason string
+	switch {
+	case status == creativeStatusApproved:
+		outcome = CreativeOutcomeApproved
+	case status == creativeStatusBlocked:
+		outcome, reason = CreativeOutcomeBlocked, "creative is blocked ("+hash+")"
+	case policy == CreativeApprovalHold && hash == "":
+		outcome, reason = CreativeOutcomeHeld, "creative has no crid to approve"
+	case policy == CreativeApprovalHold:
+		outcome, reason = CreativeOutcomeHeld, "creative is awaiting approval ("+hash+")"
+	case policy == CreativeApprovalFlag:
+		outcome = CreativeOutcomeFlagged
+	default:
+		outcome = CreativeOutcomeUnreviewed
+	}
+
+	if e.metrics != nil {
+		e.metrics.RecordCreativeApproval(bidderCode, outcome)
+	}
+	if outcome == CreativeOutcomeFlagged {
+		logger.Log.Debug().
+			Str("bidder", bidderCode).
+			Str("bidID", bid.ID).
+			Str("creative_hash", hash).
+			Msg("unreviewed creative served, flagged for review")
+	}
+	if reason == "" {
+		return nil
+	}
+
+	logger.Log.Debug().
+		Str("bidder", bidderCode).
+		Str("bidID", bid.ID).
+		Str("impID", bid.ImpID).
+		Str("creative_hash", hash).
+		Str("outcome", outcome).
+		Msg("bid rejected by creative approval")
+	return &BidValidationError{
+		BidID:      bid.ID,
+		ImpID:      bid.ImpID,
+		BidderCode: bidderCode,
+		Reason:     reason,
+		LossReason: LossCreativeDisapproved,
+	}
+}
@@ -0,0 +1,138 @@
+package exchange
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/internal/testfixtures"
+)
+
+// creativeMetrics counts creative approval outcomes on top of mockMetrics
+type creativeMetrics struct {
+	mockMetrics
+	outcomes map[string]int
+}
+
+func (m *creativeMetrics) RecordCreativeApproval(bidder, outcome string) {
+	if m.outcomes == nil {
+		m.outcomes = make(map[string]int)
+	}
+	m.outcomes[bidder+"/"+outcome]++
+}
+
+// staticRegistry reviews creatives by crid; unknown crids are new, and
+// crids Review was asked to register are recorded
+type staticRegistry struct {
+	statuses   map[string]string
+	registered []string
+}
+
+func (r *staticRegistry) Review(_ string, bid *openrtb.Bid, register bool) (string, string) {
+	if bid.CRID == "" {
+		return "", "new"
+	}
+	if status, ok := r.statuses[bid.CRID]; ok {
+		return "hash-" + bid.CRID, status
+	}
+	if register {
+		r.registered = append(r.registered, bid.CRID)
+	}
+	return "hash-" + bid.CRID, "new"
+}
+
+func TestCheckCreativeApproval(t *testing.T) {
+	tests := []struct {
+		name       string
+		crid       string
+		policy     string
+		rejected   bool
+		outcome    string
+		registered bool
+	}{
+		{"approved", "ok", CreativeApprovalHold, false, CreativeOutcomeApproved, false},
+		{"blocked without policy", "bad", "", true, CreativeOutcomeBlocked, false},
+		{"new without policy", "fresh", "", false, CreativeOutcomeUnreviewed, false},
+		{"new flagged", "fresh", CreativeApprovalFlag, false, CreativeOutcomeFlagged, true},
+		{"new held", "fresh", CreativeApprovalHold, true, CreativeOutcomeHeld, true},
+		{"no crid flagged", "", CreativeApprovalFlag, false, CreativeOutcomeFlagged, false},
+		{"no crid held", "", CreativeApprovalHold, true, CreativeOutcomeHeld, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := &staticRegistry{statuses: map[string]string{"ok": "approved", "bad": "blocked"}}
+			metrics := &creativeMetrics{}
+			ex := &Exchange{metrics: metrics}
+			bid := &openrtb.Bid{ID: "b1", ImpID: "imp1", CRID: tt.crid}
+			err := ex.checkCreativeApproval(registry, bid, "rubicon", tt.policy)
+			if (err != nil) != tt.rejected {
+				t.Errorf("rejected = %v, want %v (err %v)", err != nil, tt.rejected, err)
+			}
+			if metrics.outcomes["rubicon/"+tt.outcome] != 1 {
+				t.Errorf("expected %s counted once, got %v", tt.outcome, metrics.outcomes)
+			}
+			if got := len(registry.registered) > 0; got != tt.registered {
+				t.Errorf("registered = %v, want %v", got, tt.registered)
+			}
+		})
+	}
+
+	// Without a registry nothing is reviewed or counted
+	metrics := &creativeMetrics{}
+	ex := &Exchange{metrics: metrics}
+	if err := ex.checkCreativeApproval(nil, &openrtb.Bid{ID: "b1"}, "rubicon", CreativeApprovalHold); err != nil || metrics.outcomes != nil {
+		t.Errorf("expected no review without a registry, got %v / %v", err, metrics.outcomes)
+	}
+}
+
+func TestCreativeApprovalPolicy(t *testing.T) {
+	withPub := func(policy string) context.Context {
+		return middleware.NewContextWithPublisher(context.Background(), testfixtures.Publisher("pub1").CreativeApproval(policy).Build())
+	}
+
+	ex := &Exchange{}
+	if got := ex.creativeApproval(context.Background()); got != "" {
+		t.Errorf("expected no policy without a publisher, got %q", got)
+	}
+	if got := ex.creativeApproval(withPub(CreativeApprovalHold)); got != CreativeApprovalHold {
+		t.Errorf("expected publisher policy, got %q", got)
+	}
+	if got := ex.creativeApproval(withPub("quarantine")); got != "" {
+		t.Errorf("expected unknown policy to serve, got %q", got)
+	}
+}
+
+func TestRunAuction_HoldsUnreviewedCreatives(t *testing.T) {
+	registry := adapters.NewRegistry()
+	registry.Register("reviewed", &mockAdapter{bids: []*adapters.TypedBid{
+		{Bid: &openrtb.Bid{ID: "r1", ImpID: "imp1", Price: 4, AdM: "<div>ok</div>", CRID: "ok"}, BidType: adapters.BidTypeBanner},
+	}}, adapters.BidderInfo{Enabled: true})
+	registry.Register("unreviewed", &mockAdapter{bids: []*adapters.TypedBid{
+		{Bid: &openrtb.Bid{ID: "u1", ImpID: "imp1", Price: 9, AdM: "<div>new</div>", CRID: "fresh"}, BidType: adapters.BidTypeBanner},
+	}}, adapters.BidderInfo{Enabled: true})
+	ex := New(registry, &Config{DefaultTimeout: 100 * time.Millisecond})
+	metrics := &creativeMetrics{}
+	ex.SetMetrics(metrics)
+	ex.SetCreativeRegistry(&staticRegistry{statuses: map[string]string{"ok": "approved"}})
+
+	ctx := middleware.NewContextWithPublisher(context.Background(), testfixtures.Publisher("pub1").CreativeApproval(CreativeApprovalHold).Build())
+	resp, err := ex.RunAuction(ctx, &AuctionRequest{BidRequest: &openrtb.BidRequest{
+		ID:   "test-creative-approval",
+		Site: testSite(),
+		Imp:  []openrtb.Imp{{ID: "imp1", Banner: &openrtb.Banner{W: 300, H: 250}}},
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.BidResponse.SeatBid) != 1 || resp.BidResponse.SeatBid[0].Bid[0].ID != "r1" {
+		t.Fatalf("expected only the approved creative to win, got %+v", resp.BidResponse.SeatBid)
+	}
+	want := map[string]int{"reviewed/approved": 1, "unreviewed/held": 1}
+	if !reflect.DeepEqual(metrics.outcomes, want) {
+		t.Errorf("expected creative metrics %v, got %v", want, metrics.outcomes)
+	}
+}
@@ -0,0 +1,372 @@
+package exchange
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+// Banner markup sanitization levels, set per publisher
+// (storage.Publisher.CreativeSanitization) or exchange-wide
+// (Config.CreativeSanitization)
+const (
+	// SanitizeOff passes markup through untouched
+	SanitizeOff = "off"
+	// SanitizeStandard strips event handlers outside allowedHandlers,
+	// upgrades http resources on secure imps and wraps clicks
+	SanitizeStandard = "standard"
+	// SanitizeStrict strips every event handler and rejects creatives
+	// loading http resources on secure imps
+	SanitizeStrict = "strict"
+)
+
+// Sanitization actions, counted per bidder and used as metric labels
+const (
+	SanitizeActionHandlerStripped = "handler_stripped"
+	SanitizeActionHTTPSUpgraded   = "https_upgraded"
+	SanitizeActionClickWrapped    = "click_wrapped"
+	SanitizeActionRejected        = "rejected_insecure"
+)
+
+// allowedHandlers are the tag.handler pairs kept at the standard level:
+// load and error callbacks of pixel and viewability loaders, and anchor
+// clicks. Everything else (onmouseover redirects, onbeforeunload, ...) is
+// stripped.
+var allowedHandlers = map[string]bool{
+	"img.onload":     true,
+	"img.onerror":    true,
+	"iframe.onload":  true,
+	"script.onload":  true,
+	"script.onerror": true,
+	"a.onclick":      true,
+}
+
+// resourceAttrs hold URLs the browser loads with the page, so an http value
+// is mixed content on a secure page. href only loads a resource on link.
+var resourceAttrs = map[string]bool{
+	"src":        true,
+	"srcset":     true,
+	"poster":     true,
+	"data":       true,
+	"background": true,
+}
+
+// srcsetHTTP matches the http URLs of a srcset candidate list
+var srcsetHTTP = regexp.MustCompile(`(?i)(^|[\s,])http://`)
+
+// extractCreativeSanitization safely extracts the publisher's sanitization
+// level (storage.Publisher.CreativeSanitization)
+func extractCreativeSanitization(v interface{}) string {
+	type creativeSanitizationGetter interface {
+		GetCreativeSanitization() string
+	}
+	if getter, ok := v.(creativeSanitizationGetter); ok {
+		return getter.GetCreativeSanitization()
+	}
+	return ""
+}
+
+// creativeSanitization returns the sanitization level for the request: the
+// publisher's own level, or the exchange-wide one. Unknown levels are off.
+func (e *Exchange) creativeSanitization(ctx context.Context) string {
+	level := ""
+	if pub := middleware.PublisherFromContext(ctx); pub != nil {
+		level = extractCreativeSanitization(pub)
+	}
+	if level == "" && e.config != nil {
+		level = e.config.CreativeSanitization
+	}
+	switch level {
+	case SanitizeStandard, SanitizeStrict:
+		return level
+	}
+	return SanitizeOff
+}
+
+// sanitizeBannerBid sanitizes a banner bid's markup in place at level and
+// counts the actions taken. At the strict level a creative loading http
+// resources on a secure imp is rejected instead.
+func (e *Exchange) sanitizeBannerBid(tb *adapters.TypedBid, bidderCode string, imp *openrtb.Imp, level string) *BidValidationError {
+	if level == SanitizeOff || tb.BidType != adapters.BidTypeBanner || tb.Bid.AdM == "" {
+		return nil
+	}
+	secure := imp != nil && imp.Secure != nil && *imp.Secure == 1
+	clickMacro := ""
+	if e.config != nil {
+		clickMacro = e.config.CreativeClickMacro
+	}
+
+	adm, actions, insecure := sanitizeMarkup(tb.Bid.AdM, level, secure, clickMacro)
+	if level == SanitizeStrict && insecure > 0 {
+		e.recordSanitization(bidderCode, SanitizeActionRejected, 1)
+		return &BidValidationError{
+			BidID:      tb.Bid.ID,
+			ImpID:      tb.Bid.ImpID,
+			BidderCode: bidderCode,
+			Reason:     "creative loads http resources on a secure impression",
+			LossReason: LossCreativeNotSecure,
+		}
+	}
+	tb.Bid.AdM = adm
+	for action, n := range actions {
+		e.recordSanitization(bidderCode, action, n)
+	}
+	return nil
+}
+
+func (e *Exchange) recordSanitization(bidderCode, action string, n int) {
+	if e.metrics != nil {
+		e.metrics.RecordCreativeSanitization(bidderCode, action, n)
+	}
+}
+
+// sanitizeMarkup rewrites the start tags of banner markup. It returns the
+// markup, the actions taken and how many http resources it found on a
+// secure imp (upgraded at the standard level). Comments and the contents of
+// script and style elements are left alone.
+func sanitizeMarkup(adm, level string, secure bool, clickMacro string) (string, map[string]int, int) {
+	actions := make(map[string]int)
+	insecure := 0
+
+	var b strings.Builder
+	b.Grow(len(adm))
+	i := 0
+	for i < len(adm) {
+		lt := strings.IndexByte(adm[i:], '<')
+		if lt < 0 {
+			b.WriteString(adm[i:])
+			break
+		}
+		lt += i
+		b.WriteString(adm[i:lt])
+
+		if strings.HasPrefix(adm[lt:], "<!--") {
+			end := strings.Index(adm[lt+4:], "-->")
+			if end < 0 {
+				b.WriteString(adm[lt:])
+				break
+			}
+			end += lt + 4 + len("-->")
+			b.WriteString(adm[lt:end])
+			i = end
+			continue
+		}
+
+		t, ok := parseStartTag(adm, lt)
+		if !ok {
+			b.WriteByte('<')
+			i = lt + 1
+			continue
+		}
+		insecure += t.sanitize(level, secure, clickMacro, actions)
+		b.WriteString(t.String(adm[lt:t.end]))
+		i = t.end
+
+		// Script and style contents are code, not markup
+		if t.name == "script" || t.name == "style" {
+			closing := strings.Index(strings.ToLower(adm[i:]), "</"+t.name)
+			if closing < 0 {
+				b.WriteString(adm[i:])
+				break
+			}
+			b.WriteString(adm[i : i+closing])
+			i += closing
+		}
+	}
+	return b.String(), actions, insecure
+}
+
+// tagAttr is one attribute of a start tag
+type tagAttr struct {
+	space    string // whitespace before the attribute
+	name     string // as written
+	value    string
+	quote    byte // '"', '\'' or 0 when unquoted
+	hasValue bool
+	removed  bool
+	changed  bool
+}
+
+// startTag is a parsed start tag; tail is the whitespace and "/>" or ">"
+// after the last attribute
+type startTag struct {
+	name    string // lowercased
+	rawName string
+	attrs   []tagAttr
+	tail    string
+	end     int
+	changed bool
+}
+
+// parseStartTag parses the start tag at s[lt], which is '<'. It fails for
+// anything else that starts with '<', like end tags or stray text.
+func parseStartTag(s string, lt int) (*startTag, bool) {
+	i := lt + 1
+	if i >= len(s) || !isASCIILetter(s[i]) {
+		return nil, false
+	}
+	start := i
+	for i < len(s) && !isTagSpace(s[i]) && s[i] != '>' && s[i] != '/' {
+		i++
+	}
+	t := &startTag{rawName: s[start:i], name: strings.ToLower(s[start:i])}
+
+	for {
+		wsStart := i
+		for i < len(s) && isTagSpace(s[i]) {
+			i++
+		}
+		if i >= len(s) {
+			return nil, false
+		}
+		if s[i] == '>' || (s[i] == '/' && i+1 < len(s) && s[i+1] == '>') {
+			if s[i] == '/' {
+				i++
+			}
+			t.tail = s[wsStart : i+1]
+			t.end = i + 1
+			return t, true
+		}
+		if s[i] == '/' {
+			i++
+			continue
+		}
+
+		a := tagAttr{space: s[wsStart:i]}
+		nameStart := i
+		for i < len(s) && !isTagSpace(s[i]) && s[i] != '=' && s[i] != '>' && s[i] != '/' {
+			i++
+		}
+		a.name = s[nameStart:i]
+
+		j := i
+		for j < len(s) && isTagSpace(s[j]) {
+			j++
+		}
+		if j < len(s) && s[j] == '=' {
+			j++
+			for j < len(s) && isTagSpace(s[j]) {
+				j++
+			}
+			if j >= len(s) {
+				return nil, false
+			}
+			a.hasValue = true
+			if q := s[j]; q == '"' || q == '\'' {
+				end := strings.IndexByte(s[j+1:], q)
+				if end < 0 {
+					return nil, false
+				}
+				a.quote = q
+				a.value = s[j+1 : j+1+end]
+				i = j + 1 + end + 1
+			} else {
+				valStart := j
+				for j < len(s) && !isTagSpace(s[j]) && s[j] != '>' {
+					j++
+				}
+				a.value = s[valStart:j]
+				i = j
+			}
+		}
+		t.attrs = append(t.attrs, a)
+	}
+}
+
+// sanitize applies level to the tag's attributes, recording actions, and
+// returns the number of http resources found when secure
+func (t *startTag) sanitize(level string, secure bool, clickMacro string, actions map[string]int) int {
+	insecure := 0
+	for i := range t.attrs {
+		a := &t.attrs[i]
+		name := strings.ToLower(a.name)
+
+		if len(name) > 2 && strings.HasPrefix(name, "on") {
+			if level == SanitizeStrict || !allowedHandlers[t.name+"."+name] {
+				a.removed = true
+				t.changed = true
+				actions[SanitizeActionHandlerStripped]++
+			}
+			continue
+		}
+
+		if secure && a.hasValue && (resourceAttrs[name] || (name == "href" && t.name == "link")) {
+			upgraded := a.value
+			if name == "srcset" {
+				upgraded = srcsetHTTP.ReplaceAllString(a.value, "${1}https://")
+			} else if trimmed := strings.TrimSpace(a.value); hasPrefixFold(trimmed, "http://") {
+				upgraded = "https://" + trimmed[len("http://"):]
+			}
+			if upgraded != a.value {
+				insecure++
+				if level == SanitizeStandard {
+					a.value = upgraded
+					a.changed = true
+					t.changed = true
+					actions[SanitizeActionHTTPSUpgraded]++
+				}
+			}
+			continue
+		}
+
+		if clickMacro != "" && t.name == "a" && name == "href" && !strings.HasPrefix(a.value, clickMacro) {
+			if v := strings.TrimSpace(a.value); hasPrefixFold(v, "http://") || hasPrefixFold(v, "https://") {
+				a.value = clickMacro + v
+				a.changed = true
+				t.changed = true
+				actions[SanitizeActionClickWrapped]++
+			}
+		}
+	}
+	return insecure
+}
+
+// String returns the tag, or raw when nothing changed
+func (t *startTag) String(raw string) string {
+	if !t.changed {
+		return raw
+	}
+	var b strings.Builder
+	b.WriteByte('<')
+	b.WriteString(t.rawName)
+	for _, a := range t.attrs {
+		if a.removed {
+			continue
+		}
+		b.WriteString(a.space)
+		b.WriteString(a.name)
+		if !a.hasValue {
+			continue
+		}
+		b.WriteByte('=')
+		quote := a.quote
+		if quote == 0 && a.changed {
+			quote = '"'
+		}
+		if quote != 0 {
+			b.WriteByte(quote)
+		}
+		b.WriteString(a.value)
+		if quote != 0 {
+			b.WriteByte(quote)
+		}
+	}
+	b.WriteString(t.tail)
+	return b.String()
+}
+
+func isASCIILetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isTagSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
@@ -0,0 +1,190 @@
+package exchange
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/internal/testfixtures"
+)
+
+// sanitizationMetrics counts sanitization actions on top of mockMetrics
+type sanitizationMetrics struct {
+	mockMetrics
+	actions map[string]int
+}
+
+func (m *sanitizationMetrics) RecordCreativeSanitization(bidder, action string, count int) {
+	if m.actions == nil {
+		m.actions = make(map[string]int)
+	}
+	m.actions[bidder+"/"+action] += count
+}
+
+func TestSanitizeMarkup(t *testing.T) {
+	const macro = "%%CLICK_URL_UNESC%%"
+	tests := []struct {
+		name     string
+		adm      string
+		level    string
+		secure   bool
+		macro    string
+		want     string
+		actions  map[string]int
+		insecure int
+	}{
+		{
+			name:    "disallowed handlers stripped",
+			adm:     `<div onmouseover="top.location='https://x.example'" class=ad><img src="https://cdn.example/a.png" onload="track()" ONERROR='fallback()'></div>`,
+			level:   SanitizeStandard,
+			want:    `<div class=ad><img src="https://cdn.example/a.png" onload="track()" ONERROR='fallback()'></div>`,
+			actions: map[string]int{SanitizeActionHandlerStripped: 1},
+		},
+		{
+			name:    "strict strips every handler",
+			adm:     `<a href="#" onclick="go()"><img src=a.png onload=track()></a>`,
+			level:   SanitizeStrict,
+			want:    `<a href="#"><img src=a.png></a>`,
+			actions: map[string]int{SanitizeActionHandlerStripped: 2},
+		},
+		{
+			name:     "http resources upgraded on secure imps",
+			adm:      `<img src="http://cdn.example/a.png" srcset="http://cdn.example/a.png 1x, HTTP://cdn.example/b.png 2x"><link rel=stylesheet href=http://cdn.example/a.css><a href="http://land.example">`,
+			level:    SanitizeStandard,
+			secure:   true,
+			want:     `<img src="https://cdn.example/a.png" srcset="https://cdn.example/a.png 1x, https://cdn.example/b.png 2x"><link rel=stylesheet href="https://cdn.example/a.css"><a href="http://land.example">`,
+			actions:  map[string]int{SanitizeActionHTTPSUpgraded: 3},
+			insecure: 3,
+		},
+		{
+			name:  "http resources left alone on insecure imps",
+			adm:   `<img src="http://cdn.example/a.png">`,
+			level: SanitizeStandard,
+			want:  `<img src="http://cdn.example/a.png">`,
+		},
+		{
+			name:     "strict reports insecure resources without upgrading",
+			adm:      `<script src="http://cdn.example/a.js"></script>`,
+			level:    SanitizeStrict,
+			secure:   true,
+			want:     `<script src="http://cdn.example/a.js"></script>`,
+			insecure: 1,
+		},
+		{
+			name:    "clicks wrapped once",
+			adm:     `<a href="https://land.example/?a=1"><img src="x.png"></a><a href='` + macro + `https://land.example'>x</a><a href="#">y</a>`,
+			level:   SanitizeStandard,
+			macro:   macro,
+			want:    `<a href="` + macro + `https://land.example/?a=1"><img src="x.png"></a><a href='` + macro + `https://land.example'>x</a><a href="#">y</a>`,
+			actions: map[string]int{SanitizeActionClickWrapped: 1},
+		},
+		{
+			name:  "scripts, styles and comments untouched",
+			adm:   `<!-- <div onclick="x()"> --><script>document.write('<div onmouseover="x()">');</script><style>a{}</style><p>1 < 2</p>`,
+			level: SanitizeStrict,
+			want:  `<!-- <div onclick="x()"> --><script>document.write('<div onmouseover="x()">');</script><style>a{}</style><p>1 < 2</p>`,
+		},
+		{
+			name:  "malformed tag passed through",
+			adm:   `<div class="unterminated>`,
+			level: SanitizeStrict,
+			want:  `<div class="unterminated>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, actions, insecure := sanitizeMarkup(tt.adm, tt.level, tt.secure, tt.macro)
+			if got != tt.want {
+				t.Errorf("markup:\n got %s\nwant %s", got, tt.want)
+			}
+			if tt.actions == nil {
+				tt.actions = map[string]int{}
+			}
+			if !reflect.DeepEqual(actions, tt.actions) {
+				t.Errorf("expected actions %v, got %v", tt.actions, actions)
+			}
+			if insecure != tt.insecure {
+				t.Errorf("expected %d insecure resources, got %d", tt.insecure, insecure)
+			}
+		})
+	}
+}
+
+func TestCreativeSanitizationLevel(t *testing.T) {
+	withPub := func(level string) context.Context {
+		return middleware.NewContextWithPublisher(context.Background(), testfixtures.Publisher("pub1").CreativeSanitization(level).Build())
+	}
+
+	tests := []struct {
+		name   string
+		global string
+		ctx    context.Context
+		want   string
+	}{
+		{"default off", "", context.Background(), SanitizeOff},
+		{"exchange-wide", SanitizeStandard, context.Background(), SanitizeStandard},
+		{"publisher override", SanitizeStandard, withPub(SanitizeStrict), SanitizeStrict},
+		{"publisher opt out", SanitizeStrict, withPub(SanitizeOff), SanitizeOff},
+		{"publisher without level", SanitizeStrict, withPub(""), SanitizeStrict},
+		{"unknown level", "paranoid", context.Background(), SanitizeOff},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ex := &Exchange{config: &Config{CreativeSanitization: tt.global}}
+			if got := ex.creativeSanitization(tt.ctx); got != tt.want {
+				t.Errorf("creativeSanitization = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunAuction_SanitizesBannerMarkup(t *testing.T) {
+	secure := 1
+	newRequest := func() *AuctionRequest {
+		return &AuctionRequest{BidRequest: &openrtb.BidRequest{
+			ID:   "test-sanitize",
+			Site: testSite(),
+			Imp:  []openrtb.Imp{{ID: "imp1", Secure: &secure, Banner: &openrtb.Banner{W: 300, H: 250}}},
+		}}
+	}
+	newExchange := func(level string) (*Exchange, *sanitizationMetrics) {
+		registry := adapters.NewRegistry()
+		registry.Register("mixed", &mockAdapter{bids: []*adapters.TypedBid{
+			{Bid: &openrtb.Bid{ID: "b1", ImpID: "imp1", Price: 5, AdM: `<img src="http://cdn.example/a.png" onmouseover="go()">`}, BidType: adapters.BidTypeBanner},
+		}}, adapters.BidderInfo{Enabled: true})
+		ex := New(registry, &Config{DefaultTimeout: 100 * time.Millisecond, CreativeSanitization: level})
+		metrics := &sanitizationMetrics{}
+		ex.SetMetrics(metrics)
+		return ex, metrics
+	}
+
+	ex, metrics := newExchange(SanitizeStandard)
+	resp, err := ex.RunAuction(context.Background(), newRequest())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.BidResponse.SeatBid) != 1 || resp.BidResponse.SeatBid[0].Bid[0].AdM != `<img src="https://cdn.example/a.png">` {
+		t.Fatalf("expected the sanitized bid to win, got %+v", resp.BidResponse.SeatBid)
+	}
+	want := map[string]int{"mixed/" + SanitizeActionHTTPSUpgraded: 1, "mixed/" + SanitizeActionHandlerStripped: 1}
+	if !reflect.DeepEqual(metrics.actions, want) {
+		t.Errorf("expected actions %v, got %v", want, metrics.actions)
+	}
+
+	ex, metrics = newExchange(SanitizeStrict)
+	resp, err = ex.RunAuction(context.Background(), newRequest())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.BidResponse.SeatBid) != 0 {
+		t.Errorf("expected the insecure creative rejected, got %+v", resp.BidResponse.SeatBid)
+	}
+	if metrics.actions["mixed/"+SanitizeActionRejected] != 1 {
+		t.Errorf("expected one rejection, got %v", metrics.actions)
+	}
+}
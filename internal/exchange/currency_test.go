@@ -0,0 +1,165 @@
+package exchange
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/currency"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+// currencyAdapter returns a single bid priced in a fixed currency
+type currencyAdapter struct {
+	mockAdapter
+	currency string
+}
+
+func (m *currencyAdapter) MakeBids(internalRequest *openrtb.BidRequest, response *adapters.ResponseData) (*adapters.BidderResponse, []error) {
+	resp, errs := m.mockAdapter.MakeBids(internalRequest, response)
+	if resp != nil {
+		resp.Currency = m.currency
+	}
+	return resp, errs
+}
+
+func testConverter() *currency.Converter {
+	return currency.NewConverter("USD", map[string]float64{"EUR": 1.25})
+}
+
+func TestBuildImpFloorMap_ConvertsFloorCurrency(t *testing.T) {
+	ex := &Exchange{}
+	req := &openrtb.BidRequest{
+		Imp: []openrtb.Imp{
+			{ID: "eur", BidFloor: 2.00, BidFloorCur: "EUR"},
+			{ID: "usd", BidFloor: 1.00},
+			{ID: "jpy", BidFloor: 100, BidFloorCur: "JPY"},
+		},
+	}
+
+	// Without rates, floors can't be converted and are not enforced
+	floors := ex.buildImpFloorMap(context.Background(), req)
+	if floors["eur"] != 0 {
+		t.Errorf("expected unconvertible EUR floor skipped, got %f", floors["eur"])
+	}
+
+	ex.SetCurrencyConverter(testConverter())
+	floors = ex.buildImpFloorMap(context.Background(), req)
+	if floors["eur"] != 2.50 {
+		t.Errorf("expected EUR floor converted to 2.50 USD, got %f", floors["eur"])
+	}
+	if floors["usd"] != 1.00 {
+		t.Errorf("expected USD floor 1.00, got %f", floors["usd"])
+	}
+	if floors["jpy"] != 0 {
+		t.Errorf("expected unknown-currency floor skipped, got %f", floors["jpy"])
+	}
+}
+
+func TestCloneRequest_FloorInBidderCurrency(t *testing.T) {
+	ex := New(adapters.NewRegistry(), &Config{DefaultTimeout: 100 * time.Millisecond, DefaultCurrency: "USD"})
+	ex.SetCurrencyConverter(testConverter())
+	ex.SetBidderCurrencies(map[string]string{"eurbidder": "eur"})
+
+	req := &openrtb.BidRequest{
+		ID: "req",
+		Imp: []openrtb.Imp{
+			{ID: "usd", BidFloor: 2.50, BidFloorCur: "USD"},
+			{ID: "jpy", BidFloor: 100, BidFloorCur: "JPY"},
+			{ID: "none"},
+		},
+	}
+
+	clone := ex.cloneRequestWithFPD(req, "eurbidder", nil)
+	if len(clone.Cur) != 1 || clone.Cur[0] != "EUR" {
+		t.Errorf("expected Cur [EUR], got %v", clone.Cur)
+	}
+	if clone.Imp[0].BidFloor != 2.00 || clone.Imp[0].BidFloorCur != "EUR" {
+		t.Errorf("expected floor 2.00 EUR, got %.2f %s", clone.Imp[0].BidFloor, clone.Imp[0].BidFloorCur)
+	}
+	// Unknown currencies keep their original floor rather than being relabelled
+	if clone.Imp[1].BidFloor != 100 || clone.Imp[1].BidFloorCur != "JPY" {
+		t.Errorf("expected floor 100 JPY, got %.2f %s", clone.Imp[1].BidFloor, clone.Imp[1].BidFloorCur)
+	}
+	if clone.Imp[2].BidFloorCur != "EUR" {
+		t.Errorf("expected zero floor labelled EUR, got %s", clone.Imp[2].BidFloorCur)
+	}
+
+	// Bidders without a configured currency bid in the exchange currency
+	clone = ex.cloneRequestWithFPD(req, "other", nil)
+	if clone.Cur[0] != "USD" || clone.Imp[0].BidFloor != 2.50 {
+		t.Errorf("expected USD request with 2.50 floor, got %v %.2f", clone.Cur, clone.Imp[0].BidFloor)
+	}
+}
+
+func TestCallBidder_ConvertsBidCurrency(t *testing.T) {
+	ex := New(adapters.NewRegistry(), &Config{DefaultTimeout: 100 * time.Millisecond, DefaultCurrency: "USD"})
+	req := &openrtb.BidRequest{ID: "req", Cur: []string{"EUR"}, Imp: []openrtb.Imp{{ID: "imp1"}}}
+
+	newAdapter := func() *currencyAdapter {
+		return &currencyAdapter{
+			mockAdapter: mockAdapter{bids: []*adapters.TypedBid{
+				{Bid: &openrtb.Bid{ID: "b1", ImpID: "imp1", Price: 2.00}, BidType: adapters.BidTypeBanner},
+			}},
+			currency: "EUR",
+		}
+	}
+
+	// Without rates, off-currency bids are rejected
+	result := ex.callBidder(context.Background(), req, "eurbidder", newAdapter(), time.Second)
+	if len(result.Bids) != 0 || len(result.Errors) == 0 {
+		t.Errorf("expected EUR bids rejected without rates, got %d bids, %v", len(result.Bids), result.Errors)
+	}
+
+	ex.SetCurrencyConverter(testConverter())
+	result = ex.callBidder(context.Background(), req, "eurbidder", newAdapter(), time.Second)
+	if len(result.Bids) != 1 {
+		t.Fatalf("expected 1 converted bid, got %d (%v)", len(result.Bids), result.Errors)
+	}
+	if math.Abs(result.Bids[0].Bid.Price-2.50) > 1e-9 {
+		t.Errorf("expected bid converted to 2.50 USD, got %f", result.Bids[0].Bid.Price)
+	}
+}
+
+func TestRunAuction_RespondsInRequestCurrency(t *testing.T) {
+	registry := adapters.NewRegistry()
+	registry.Register("bidder1", &mockAdapter{bids: []*adapters.TypedBid{
+		{Bid: &openrtb.Bid{ID: "b1", ImpID: "imp1", Price: 2.50, AdM: "<div>ad</div>"}, BidType: adapters.BidTypeBanner},
+	}}, adapters.BidderInfo{Enabled: true})
+	ex := New(registry, &Config{DefaultTimeout: 100 * time.Millisecond, DefaultCurrency: "USD"})
+	ex.SetCurrencyConverter(testConverter())
+
+	run := func(cur ...string) *openrtb.BidResponse {
+		t.Helper()
+		req := &openrtb.BidRequest{
+			ID:   "cur-req",
+			Site: testSite(),
+			Cur:  cur,
+			Imp:  []openrtb.Imp{{ID: "imp1", Banner: &openrtb.Banner{W: 300, H: 250}}},
+		}
+		resp, err := ex.RunAuction(context.Background(), &AuctionRequest{BidRequest: req})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(resp.BidResponse.SeatBid) != 1 || len(resp.BidResponse.SeatBid[0].Bid) != 1 {
+			t.Fatalf("expected one bid, got %+v", resp.BidResponse.SeatBid)
+		}
+		return resp.BidResponse
+	}
+
+	resp := run("EUR")
+	if resp.Cur != "EUR" || math.Abs(resp.SeatBid[0].Bid[0].Price-2.00) > 1e-9 {
+		t.Errorf("expected the bid returned as 2.00 EUR, got %.4f %s", resp.SeatBid[0].Bid[0].Price, resp.Cur)
+	}
+
+	// The exchange currency is kept when the request allows it, and
+	// currencies without a rate fall back to it
+	for _, cur := range [][]string{{"EUR", "USD"}, {"JPY"}, nil} {
+		resp = run(cur...)
+		if resp.Cur != "USD" || resp.SeatBid[0].Bid[0].Price != 2.50 {
+			t.Errorf("cur %v: expected 2.50 USD, got %.4f %s", cur, resp.SeatBid[0].Bid[0].Price, resp.Cur)
+		}
+	}
+}
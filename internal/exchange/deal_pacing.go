@@ -0,0 +1,104 @@
+package exchange
+
+import (
+	"math"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+// DealPacer reports which guaranteed deals are behind their delivery goal;
+// implemented by deals.Pacer
+type DealPacer interface {
+	Behind(dealID string) bool
+	// Owner returns the bidder and publisher a deal is booked for
+	Owner(dealID string) (bidderCode, publisherID string, ok bool)
+}
+
+// SetDealPacer enables pacing: bids for deals behind their goal are ranked
+// ahead of other bids on the same impression
+func (e *Exchange) SetDealPacer(pacer DealPacer) {
+	e.configMu.Lock()
+	defer e.configMu.Unlock()
+	e.dealPacer = pacer
+}
+
+// dealOwner returns the bidder a booked deal belongs to, when it is booked
+// for publisherID or for any publisher
+func dealOwner(pacer DealPacer, dealID, publisherID string) (string, bool) {
+	if dealID == "" {
+		return "", false
+	}
+	bidder, publisher, ok := pacer.Owner(dealID)
+	if !ok || bidder == "" || (publisher != "" && publisher != publisherID) {
+		return "", false
+	}
+	return bidder, true
+}
+
+// markPacedDeals flags bids for deals behind their goal. A bid's deal ID is
+// only trusted when the deal is offered on the bid's impression and is
+// booked for the bidder and the request's publisher.
+func (e *Exchange) markPacedDeals(req *openrtb.BidRequest, bids []ValidatedBid) {
+	e.configMu.RLock()
+	pacer := e.dealPacer
+	e.configMu.RUnlock()
+	if pacer == nil || req == nil {
+		return
+	}
+
+	offered := make(map[string]map[string]bool)
+	for _, imp := range req.Imp {
+		if imp.PMP == nil {
+			continue
+		}
+		deals := make(map[string]bool, len(imp.PMP.Deals))
+		for _, deal := range imp.PMP.Deals {
+			deals[deal.ID] = true
+		}
+		offered[imp.ID] = deals
+	}
+	if len(offered) == 0 {
+		return
+	}
+
+	publisherID := requestPublisherID(req)
+	for i := range bids {
+		vb := &bids[i]
+		if vb.Bid == nil || vb.Bid.Bid == nil {
+			continue
+		}
+		dealID := vb.Bid.Bid.DealID
+		if !offered[vb.Bid.Bid.ImpID][dealID] {
+			continue
+		}
+		if bidder, ok := dealOwner(pacer, dealID, publisherID); !ok || bidder != vb.BidderCode {
+			continue
+		}
+		vb.PacedDeal = pacer.Behind(dealID)
+	}
+}
+
+// prioritizePacedDeals moves the highest bid flagged by markPacedDeals to the
+// front of bids, which must be sorted by price. Flagged bids with an invalid
+// price or below floor are not promoted. It reports whether the winner
+// changed, in which case the deal bid clears at its own price: guaranteed
+// deals are fixed price, and the bids it jumped are higher.
+func (e *Exchange) prioritizePacedDeals(bids []ValidatedBid, floor float64) bool {
+	for i, vb := range bids {
+		if !vb.PacedDeal || vb.Bid == nil || vb.Bid.Bid == nil {
+			continue
+		}
+		price := vb.Bid.Bid.Price
+		if price <= 0 || math.IsNaN(price) || math.IsInf(price, 0) || price < floor {
+			continue
+		}
+		if i == 0 {
+			return false
+		}
+		paced := bids[i]
+		copy(bids[1:i+1], bids[:i])
+		bids[0] = paced
+		return true
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+// bookedDeal is a deal known to testPacer
+type bookedDeal struct {
+	bidder, publisher string
+	behind            bool
+}
+
+// testPacer is a DealPacer over a fixed set of booked deals
+type testPacer map[string]bookedDeal
+
+func (p testPacer) Behind(dealID string) bool { return p[dealID].behind }
+
+func (p testPacer) Owner(dealID string) (string, string, bool) {
+	deal, ok := p[dealID]
+	return deal.bidder, deal.publisher, ok
+}
+
+// pacingRequest offers deal pg-1 on imp1 for publisher pub1
+func pacingRequest() *openrtb.BidRequest {
+	return &openrtb.BidRequest{
+		Site: &openrtb.Site{Publisher: &openrtb.Publisher{ID: "pub1"}},
+		Imp:  []openrtb.Imp{{ID: "imp1", PMP: &openrtb.PMP{Deals: []openrtb.Deal{{ID: "pg-1"}}}}},
+	}
+}
+
+func pacingBids() []ValidatedBid {
+	return []ValidatedBid{
+		{Bid: &adapters.TypedBid{Bid: &openrtb.Bid{ID: "open", ImpID: "imp1", Price: 8.00}}, BidderCode: "bidder1"},
+		{Bid: &adapters.TypedBid{Bid: &openrtb.Bid{ID: "pg", ImpID: "imp1", Price: 4.00, DealID: "pg-1"}}, BidderCode: "bidder2"},
+		{Bid: &adapters.TypedBid{Bid: &openrtb.Bid{ID: "other", ImpID: "imp1", Price: 6.00}}, BidderCode: "bidder3"},
+	}
+}
+
+// runPacedAuction marks paced deals and runs the auction over pacingBids
+func runPacedAuction(ex *Exchange, req *openrtb.BidRequest, floor float64) []ValidatedBid {
+	bids := pacingBids()
+	ex.markPacedDeals(req, bids)
+	return ex.runAuctionLogic(bids, map[string]float64{"imp1": floor})["imp1"]
+}
+
+func TestAuctionLogic_PacedDealWins(t *testing.T) {
+	ex := New(adapters.NewRegistry(), &Config{AuctionType: SecondPriceAuction, PriceIncrement: 0.01})
+	ex.SetDealPacer(testPacer{"pg-1": {bidder: "bidder2", publisher: "pub1", behind: true}})
+
+	bids := runPacedAuction(ex, pacingRequest(), 0)
+	if len(bids) != 3 || bids[0].Bid.Bid.ID != "pg" || bids[1].Bid.Bid.ID != "open" || bids[2].Bid.Bid.ID != "other" {
+		t.Fatalf("expected the paced deal first, then price order, got %v", bidIDs(bids))
+	}
+	if bids[0].Bid.Bid.Price != 4.00 {
+		t.Errorf("expected the paced deal to clear at its own price, got %f", bids[0].Bid.Bid.Price)
+	}
+}
+
+func TestAuctionLogic_DealOnPaceRanksByPrice(t *testing.T) {
+	ex := New(adapters.NewRegistry(), &Config{AuctionType: SecondPriceAuction, PriceIncrement: 0.01})
+	ex.SetDealPacer(testPacer{"pg-1": {bidder: "bidder2", publisher: "pub1"}})
+
+	bids := runPacedAuction(ex, pacingRequest(), 0)
+	if bids[0].Bid.Bid.ID != "open" || bids[0].Bid.Bid.Price != 6.01 {
+		t.Errorf("expected the open bid to win at second price 6.01, got %s at %f", bids[0].Bid.Bid.ID, bids[0].Bid.Bid.Price)
+	}
+}
+
+func TestAuctionLogic_UntrustedPacedDealRanksByPrice(t *testing.T) {
+	otherImp := pacingRequest()
+	otherImp.Imp[0].PMP.Deals[0].ID = "pg-2"
+	otherPublisher := pacingRequest()
+	otherPublisher.Site.Publisher.ID = "pub2"
+
+	tests := []struct {
+		name  string
+		deal  bookedDeal
+		req   *openrtb.BidRequest
+		floor float64
+	}{
+		{"deal not offered on the imp", bookedDeal{bidder: "bidder2", publisher: "pub1", behind: true}, otherImp, 0},
+		{"deal booked for another bidder", bookedDeal{bidder: "bidder3", publisher: "pub1", behind: true}, pacingRequest(), 0},
+		{"deal booked for another publisher", bookedDeal{bidder: "bidder2", publisher: "pub1", behind: true}, otherPublisher, 0},
+		{"deal bid below floor", bookedDeal{bidder: "bidder2", publisher: "pub1", behind: true}, pacingRequest(), 5.00},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ex := New(adapters.NewRegistry(), &Config{AuctionType: SecondPriceAuction, PriceIncrement: 0.01})
+			ex.SetDealPacer(testPacer{"pg-1": tt.deal})
+
+			bids := runPacedAuction(ex, tt.req, tt.floor)
+			if len(bids) == 0 || bids[0].Bid.Bid.ID != "open" {
+				t.Errorf("expected the open bid to win, got %v", bidIDs(bids))
+			}
+		})
+	}
+}
+
+func bidIDs(bids []ValidatedBid) []string {
+	ids := make([]string, len(bids))
+	for i, vb := range bids {
+		ids[i] = vb.Bid.Bid.ID
+	}
+	return ids
+}
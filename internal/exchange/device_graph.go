@@ -0,0 +1,127 @@
+package exchange
+
+import (
+	"context"
+
+	"github.com/thenexusengine/tne_springwire/internal/ctv"
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+// DeviceGraph resolves a viewer's IP and user agent to household and device
+// IDs; implemented by devicegraph.Client
+type DeviceGraph interface {
+	// Resolve returns the viewer's IDs, or ok false when the graph doesn't
+	// know the viewer or the lookup failed or timed out
+	Resolve(ctx context.Context, ip, ua string) (householdID, deviceID string, ok bool)
+}
+
+// DeviceGraphIdentity is the viewer's household and device as resolved by
+// the device graph
+type DeviceGraphIdentity struct {
+	HouseholdID string
+	DeviceID    string
+}
+
+// SetDeviceGraph enables device graph lookups for CTV requests, so house ad
+// frequency caps follow the household instead of the single device
+func (e *Exchange) SetDeviceGraph(graph DeviceGraph) {
+	e.configMu.Lock()
+	defer e.configMu.Unlock()
+	e.deviceGraph = graph
+}
+
+// deviceGraphLookup delivers the result of a lookup running alongside the
+// auction; a nil lookup was never started
+type deviceGraphLookup chan *DeviceGraphIdentity
+
+// wait returns the lookup's result, blocking until it completes. The
+// client's own timeout bounds the wait; a lookup nobody waits for is
+// abandoned when the auction's context is cancelled.
+func (l deviceGraphLookup) wait() *DeviceGraphIdentity {
+	if l == nil {
+		return nil
+	}
+	return <-l
+}
+
+// startDeviceGraphLookup starts resolving the request's viewer when a
+// device graph is set and the privacy gate allows it. ctx must outlive the
+// wait.
+func (e *Exchange) startDeviceGraphLookup(ctx context.Context, req *openrtb.BidRequest) deviceGraphLookup {
+	e.configMu.RLock()
+	graph := e.deviceGraph
+	e.configMu.RUnlock()
+	if graph == nil || !deviceGraphAllowed(ctx, req) {
+		return nil
+	}
+
+	ip := req.Device.IP
+	if ip == "" {
+		ip = req.Device.IPv6
+	}
+	ua := req.Device.UA
+	lookup := make(deviceGraphLookup, 1)
+	go func() {
+		householdID, deviceID, ok := graph.Resolve(ctx, ip, ua)
+		if !ok {
+			lookup <- nil
+			return
+		}
+		lookup <- &DeviceGraphIdentity{HouseholdID: householdID, DeviceID: deviceID}
+	}()
+	return lookup
+}
+
+// deviceGraphAllowed is the privacy gate for device graph lookups, which
+// send the viewer's IP and user agent to a third party. Only CTV requests
+// with both are looked up, never COPPA traffic, limited ad tracking or
+// users who opted out under US privacy laws, and under GDPR only with
+// consent to storage/access (purpose 1) and basic ad selection (purpose 2),
+// which covers frequency capping.
+func deviceGraphAllowed(ctx context.Context, req *openrtb.BidRequest) bool {
+	device := req.Device
+	if device == nil || device.UA == "" || (device.IP == "" && device.IPv6 == "") {
+		return false
+	}
+	if !ctv.IsCTV(device) {
+		return false
+	}
+	if device.Lmt != nil && *device.Lmt == 1 {
+		return false
+	}
+	if req.Regs != nil && req.Regs.COPPA == 1 {
+		return false
+	}
+	if !middleware.ShouldCollectPII(ctx) || middleware.ResolveUSPrivacy(req).OptedOut() || middleware.ResolveGPP(req).StripIdentifiers() {
+		return false
+	}
+
+	gdpr := middleware.GDPRApplies(ctx) || (req.Regs != nil && req.Regs.GDPR != nil && *req.Regs.GDPR == 1)
+	if !gdpr {
+		return true
+	}
+	consent := middleware.GetConsentString(ctx)
+	if consent == "" && req.User != nil {
+		consent = req.User.Consent
+	}
+	tcf, err := middleware.ParseTCFv2(consent)
+	if err != nil || tcf == nil {
+		return false
+	}
+	return tcf.HasPurposeConsent(middleware.PurposeStorageAccess) && tcf.HasPurposeConsent(middleware.PurposeBasicAds)
+}
+
+// frequencyCapKey identifies the viewer for house ad frequency caps: the
+// device graph's household, else its device, else houseAdUserKey
+func frequencyCapKey(req *openrtb.BidRequest, identity *DeviceGraphIdentity) string {
+	if identity != nil {
+		if identity.HouseholdID != "" {
+			return "household:" + identity.HouseholdID
+		}
+		if identity.DeviceID != "" {
+			return "device:" + identity.DeviceID
+		}
+	}
+	return houseAdUserKey(req)
+}
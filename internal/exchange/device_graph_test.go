@@ -0,0 +1,129 @@
+package exchange
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/internal/testfixtures"
+)
+
+// TCF v2 consent strings granting purposes 1 and 2, and purpose 1 only
+const (
+	tcfStorageAndBasicAds = "CAAAAAAAAAAAAAAAAAAAAAAAAMAAAAAAAAAAAAAAAAAAAAAAAAA"
+	tcfStorageOnly        = "CAAAAAAAAAAAAAAAAAAAAAAAAIAAAAAAAAAAAAAAAAAAAAAAAAA"
+)
+
+const rokuUA = "Roku/DVP-9.10 (519.10E04111A)"
+
+// mockDeviceGraph resolves every viewer to household-1
+type mockDeviceGraph struct {
+	mu      sync.Mutex
+	lookups []string
+}
+
+func (m *mockDeviceGraph) Resolve(ctx context.Context, ip, ua string) (string, string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lookups = append(m.lookups, ip+"|"+ua)
+	return "household-1", "device-1", true
+}
+
+func TestRunAuction_DeviceGraphCapsHouseAdsPerHousehold(t *testing.T) {
+	ex := New(adapters.NewRegistry(), &Config{DefaultTimeout: 100 * time.Millisecond})
+	houseAds := &mockHouseAds{}
+	ex.SetHouseAds(houseAds)
+	graph := &mockDeviceGraph{}
+	ex.SetDeviceGraph(graph)
+
+	req := testfixtures.Request("ctv-req").Site("pub1.example", "pub1").User("user-1", "").
+		Device(rokuUA, "192.0.2.1", 3).Imp(testfixtures.Banner("imp1", 300, 250)).Build()
+	resp, err := ex.RunAuction(context.Background(), &AuctionRequest{BidRequest: req})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(graph.lookups) != 1 || graph.lookups[0] != "192.0.2.1|"+rokuUA {
+		t.Fatalf("expected one lookup by IP and UA, got %v", graph.lookups)
+	}
+	if len(houseAds.userKeys) != 1 || houseAds.userKeys[0] != "household:household-1" {
+		t.Errorf("expected house ads capped per household, got %v", houseAds.userKeys)
+	}
+	if resp.DeviceGraph == nil || resp.DeviceGraph.DeviceID != "device-1" {
+		t.Errorf("expected the resolved identity on the response, got %+v", resp.DeviceGraph)
+	}
+
+	// Non-CTV traffic isn't looked up and keeps its per-user caps
+	houseAds.userKeys = nil
+	req = testfixtures.Request("web-req").Site("pub1.example", "pub1").User("user-1", "").
+		Device("Mozilla/5.0 (Windows NT 10.0; Win64; x64)", "192.0.2.1", 2).Imp(testfixtures.Banner("imp1", 300, 250)).Build()
+	if _, err := ex.RunAuction(context.Background(), &AuctionRequest{BidRequest: req}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(graph.lookups) != 1 || houseAds.userKeys[0] != "user:user-1" {
+		t.Errorf("expected no lookup for web traffic, got %v and keys %v", graph.lookups, houseAds.userKeys)
+	}
+}
+
+func TestDeviceGraphAllowed(t *testing.T) {
+	ctv := func(consent *testfixtures.ConsentBuilder) *openrtb.BidRequest {
+		b := testfixtures.Request("r1").App("com.example.ctv", "pub1").Device(rokuUA, "192.0.2.1", 3)
+		if consent != nil {
+			b = b.Consent(consent)
+		}
+		return b.Build()
+	}
+	lmt := ctv(nil)
+	one := 1
+	lmt.Device.Lmt = &one
+	noIP := ctv(nil)
+	noIP.Device.IP = ""
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		req  *openrtb.BidRequest
+		want bool
+	}{
+		{"ctv", context.Background(), ctv(nil), true},
+		{"no ip", context.Background(), noIP, false},
+		{"not ctv", context.Background(), testfixtures.Request("r1").Device("Mozilla/5.0", "192.0.2.1", 2).Build(), false},
+		{"limit ad tracking", context.Background(), lmt, false},
+		{"coppa", context.Background(), ctv(testfixtures.NoGDPR().COPPA()), false},
+		{"us privacy opt-out", context.Background(), ctv(testfixtures.NoGDPR().USPrivacy("1YYN")), false},
+		{"gpp california opt-out", context.Background(), ctv(testfixtures.NoGDPR().GPP(gppCaliforniaOptOut, 8)), false},
+		{"gpp section not applicable", context.Background(), ctv(testfixtures.NoGDPR().GPP(gppCaliforniaOptOut, 7)), true},
+		{"ccpa opt-out in context", middleware.SetPrivacyContext(context.Background(), false, true, true, ""), ctv(nil), false},
+		{"gdpr with consent", context.Background(), ctv(testfixtures.GDPR(tcfStorageAndBasicAds)), true},
+		{"gdpr without basic ads consent", context.Background(), ctv(testfixtures.GDPR(tcfStorageOnly)), false},
+		{"gdpr without consent string", context.Background(), ctv(testfixtures.GDPR("")), false},
+		{"gdpr consent not validated", middleware.SetPrivacyContext(context.Background(), true, false, false, tcfStorageAndBasicAds), ctv(nil), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := deviceGraphAllowed(tt.ctx, tt.req); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFrequencyCapKey(t *testing.T) {
+	req := &openrtb.BidRequest{User: &openrtb.User{ID: "u1"}}
+	tests := []struct {
+		identity *DeviceGraphIdentity
+		want     string
+	}{
+		{&DeviceGraphIdentity{HouseholdID: "h1", DeviceID: "d1"}, "household:h1"},
+		{&DeviceGraphIdentity{DeviceID: "d1"}, "device:d1"},
+		{nil, "user:u1"},
+	}
+	for _, tt := range tests {
+		if got := frequencyCapKey(req, tt.identity); got != tt.want {
+			t.Errorf("%+v: expected %q, got %q", tt.identity, tt.want, got)
+		}
+	}
+}
@@ -7,17 +7,23 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"net/http"
 	"net/url"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/auctiontrail"
+	"github.com/thenexusengine/tne_springwire/internal/currency"
 	"github.com/thenexusengine/tne_springwire/internal/fpd"
 	"github.com/thenexusengine/tne_springwire/internal/middleware"
 	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/pkg/deadline"
+	"github.com/thenexusengine/tne_springwire/pkg/featureflags"
 	"github.com/thenexusengine/tne_springwire/pkg/idr"
 	"github.com/thenexusengine/tne_springwire/pkg/logger"
+	"github.com/thenexusengine/tne_springwire/pkg/sessionhash"
 )
 
 // ValidationError represents a client validation error (results in 4xx response)
@@ -37,13 +43,24 @@ func NewValidationError(format string, args ...interface{}) *ValidationError {
 // MetricsRecorder interface for recording revenue/margin metrics and circuit breaker metrics
 type MetricsRecorder interface {
 	// Auction and bid metrics
-	RecordAuction(status, mediaType string, duration time.Duration, biddersSelected, biddersExcluded int)
-	RecordBid(bidder, mediaType string, cpm float64)
+	RecordAuction(status, mediaType, mediaSubtype string, duration time.Duration, biddersSelected, biddersExcluded int)
+	RecordBid(bidder, mediaType, mediaSubtype string, cpm float64)
 	RecordBidderRequest(bidder string, latency time.Duration, hasError, timedOut bool)
+	RecordBidPriceCapExceeded(bidder string)
+	RecordCreativeSanitization(bidder, action string, count int)
+	RecordBidLanguage(bidder, language, outcome string)
+	RecordCreativeApproval(bidder, outcome string)
+	RecordGeoRejection(country, reason string)
+	RecordAuctionFill(mediaType, source string)
+
+	// Yield metrics
+	RecordBidOutcome(bidder, mediaType, mediaSubtype, outcome string, cpm float64)
+	RecordBidsPerRequest(bidder, mediaType, mediaSubtype string, bids int)
 
 	// Revenue/margin metrics
 	RecordMargin(publisher, bidder, mediaType string, originalPrice, adjustedPrice, platformCut float64)
-	RecordFloorAdjustment(publisher string)
+	RecordFloorAdjustment(rule, action string)
+	RecordPodRuleEnforcement(rule string, count int)
 
 	// Circuit breaker metrics
 	SetBidderCircuitState(bidder, state string)
@@ -52,6 +69,23 @@ type MetricsRecorder interface {
 	RecordBidderCircuitSuccess(bidder string)
 	RecordBidderCircuitRejected(bidder string)
 	RecordBidderCircuitStateChange(bidder, fromState, toState string)
+
+	// QPS shaping metrics
+	RecordBidderQPSSuppressed(bidder string)
+
+	// Privacy metrics
+	RecordPrivacyFiltered(bidder, reason string)
+
+	// Payload size metrics
+	RecordBidderPayloadSize(bidder string, requestBytes, responseBytes int)
+	RecordBidderResponseOversized(bidder string)
+
+	// Fan-out metrics
+	RecordFanoutEarlyCompletion(saved time.Duration)
+	RecordFanoutTruncated(candidates, dropped int)
+
+	// Memory metrics of sampled auctions
+	RecordAuctionAllocations(allocBytes, allocObjects, heapBytes uint64)
 }
 
 // Exchange orchestrates the auction process
@@ -69,7 +103,103 @@ type Exchange struct {
 	bidderBreakers   map[string]*idr.CircuitBreaker
 	bidderBreakersMu sync.RWMutex
 
-	// configMu protects fpdProcessor, eidFilter, and config.FPD
+	// bidderGDPRScopes holds per-bidder GDPR scope overrides (bidders.gdpr_scope)
+	bidderGDPRScopes map[string]middleware.GDPRScope
+
+	// bidderExtPolicies holds per-bidder ext passthrough policies (bidders.ext_passthrough)
+	bidderExtPolicies map[string]ExtPassthroughPolicy
+
+	// bidderMediaTypes holds per-bidder media types (bidders.supports_*);
+	// bidders without an entry use their adapter's capabilities
+	bidderMediaTypes map[string][]adapters.BidType
+
+	// bidderMaxQPS holds per-bidder outbound QPS caps (bidders.max_qps),
+	// enforced by qpsLimiter; bidders without an entry are unlimited
+	bidderMaxQPS map[string]int
+	qpsLimiter   QPSLimiter
+
+	// bidderHeaders holds per-bidder outbound headers (bidders.http_headers)
+	// with secret references already resolved
+	bidderHeaders map[string]http.Header
+
+	// dealPacer ranks bids for under-delivering guaranteed deals first;
+	// nil disables pacing
+	dealPacer DealPacer
+
+	// bidExpiry tracks billing windows of returned bids for win/billing notices
+	bidExpiry *BidExpiryRegistry
+
+	// houseAds fills auctions without a valid bid; nil returns them empty
+	houseAds HouseAdSource
+
+	// deviceGraph resolves CTV households for cross-device frequency caps;
+	// nil caps per device
+	deviceGraph DeviceGraph
+
+	// identity adds identity provider IDs to user.eids; nil sends the
+	// request's own EIDs only
+	identity IdentityResolver
+
+	// uid2 validates publisher UID2 tokens; nil forwards them unchecked
+	uid2 UID2Checker
+
+	// uid2Bidders holds the bidders UID2 tokens go to (bidders.uid2_enabled);
+	// nil forwards them to no bidder
+	uid2Bidders map[string]bool
+
+	// floors raises impression floors by publisher rule; nil leaves them as sent
+	floors FloorSource
+
+	// featureFlags gates rollouts per publisher; nil uses flag defaults
+	featureFlags FeatureFlags
+
+	// sessions hashes session IDs for sticky flag and exploration buckets;
+	// nil hashes unsalted
+	sessions *sessionhash.Hasher
+
+	// bidderValues ranks bidders when MaxBidders truncates selection
+	bidderValues *bidderValues
+
+	// currency converts floors and bids between currencies; nil only allows
+	// same-currency comparisons
+	currency *currency.Converter
+
+	// bidderCurrencies holds each bidder's bidding currency; bidders without
+	// an entry bid in config.DefaultCurrency
+	bidderCurrencies map[string]string
+
+	// bidInjectionKeys verify BidInjectionHeader signatures by key ID;
+	// empty disables injection
+	bidInjectionKeys map[string][]byte
+
+	// rollup counts auctions for long-term reporting; nil disables
+	rollup RollupRecorder
+
+	// auctionRegistry publishes auction summaries for downstream joins;
+	// nil disables
+	auctionRegistry AuctionRegistry
+
+	// faultInjector injects chaos faults into bidder and IDR calls; nil
+	// disables
+	faultInjector FaultInjector
+
+	// creativeRegistry holds creative review statuses for approval; nil
+	// disables
+	creativeRegistry CreativeRegistry
+
+	// auctionTrail records each auction's decision trail for debugging;
+	// nil disables
+	auctionTrail AuctionTrailRecorder
+
+	// lossNotifier queues the lurl of bids that lost; nil disables
+	lossNotifier LossNotifier
+
+	// analytics ships auction and bid events downstream; nil disables
+	analytics AnalyticsTracker
+
+	// configMu protects fpdProcessor, eidFilter, config.FPD, bidderGDPRScopes,
+	// bidderExtPolicies, uid2, uid2Bidders, bidderMediaTypes, bidderMaxQPS, qpsLimiter, bidderHeaders, dealPacer, featureFlags, currency, bidderCurrencies,
+	// bidInjectionKeys, rollup, auctionRegistry, faultInjector, creativeRegistry, auctionTrail and analytics
 	// for safe concurrent access during runtime config updates
 	configMu sync.RWMutex
 }
@@ -125,6 +255,7 @@ type Config struct {
 	DefaultTimeout       time.Duration
 	MaxBidders           int
 	MaxConcurrentBidders int // P0-4: Limit concurrent bidder goroutines (0 = unlimited)
+	MaxResponseBytes     int // Bidder responses larger than this are rejected unread (0 = 1MB)
 	IDREnabled           bool
 	IDRServiceURL        string
 	IDRAPIKey            string // Internal API key for IDR service-to-service calls
@@ -138,6 +269,30 @@ type Config struct {
 	AuctionType    AuctionType
 	PriceIncrement float64 // For second-price auctions (typically 0.01)
 	MinBidPrice    float64 // Minimum valid bid price
+	MaxBidCPM      float64 // Bids above this are rejected as anomalous (0 = maxReasonableCPM)
+	// Tie-breaking for bids at the same price: response_order, weighted or
+	// deal_priority ("" = response_order), with per-bidder weights (default 1)
+	TieBreak        string
+	TieBreakWeights map[string]float64
+	// Banner markup sanitization
+	CreativeSanitization string // Level for publishers without their own: off, standard or strict ("" = off)
+	CreativeClickMacro   string // Ad server click macro prefixed to creative links ("" = no click wrapping)
+	// ISO 3166-1 alpha-3 countries no auction is run for, whatever the publisher
+	BlockedCountries []string
+	// Identifiers scrubbed before fan-out on COPPA (regs.coppa=1) and limited
+	// ad tracking (device.lmt=1) traffic (nil = middleware.DefaultCOPPAScrub
+	// and middleware.DefaultLMTScrub)
+	COPPAScrub *middleware.ScrubPolicy
+	LMTScrub   *middleware.ScrubPolicy
+	// Billing window configuration
+	ImpExpiry       time.Duration // Billing window when neither bid nor imp sets exp
+	ExpiryRetention time.Duration // How long expired bids are remembered for late billing calls
+	// Fraction of auctions whose allocations and heap size are recorded (0 = off)
+	AllocSampleRate float64
+	// Supply chain node the exchange appends to requests ("" ASI = none);
+	// SID defaults to the request's publisher ID
+	SChainASI string
+	SChainSID string
 }
 
 // DefaultConfig returns default configuration
@@ -157,6 +312,8 @@ func DefaultConfig() *Config {
 		AuctionType:           FirstPriceAuction,
 		PriceIncrement:        0.01,
 		MinBidPrice:           0.0,
+		ImpExpiry:             defaultImpExpiry,
+		ExpiryRetention:       defaultExpiryRetention,
 	}
 }
 
@@ -185,6 +342,11 @@ func validateConfig(config *Config) *Config {
 		config.AuctionType = FirstPriceAuction
 	}
 
+	// Unknown tie-breaking rules fall back to response order
+	if !ValidTieBreak(config.TieBreak) {
+		config.TieBreak = TieBreakResponseOrder
+	}
+
 	// PriceIncrement must be positive for second-price auctions
 	if config.AuctionType == SecondPriceAuction && config.PriceIncrement <= 0 {
 		config.PriceIncrement = defaults.PriceIncrement
@@ -195,6 +357,14 @@ func validateConfig(config *Config) *Config {
 		config.MinBidPrice = 0
 	}
 
+	// Billing window must be positive
+	if config.ImpExpiry <= 0 {
+		config.ImpExpiry = defaults.ImpExpiry
+	}
+	if config.ExpiryRetention <= 0 {
+		config.ExpiryRetention = defaults.ExpiryRetention
+	}
+
 	// EventBufferSize must be positive if event recording is enabled
 	if config.EventRecordEnabled && config.EventBufferSize <= 0 {
 		config.EventBufferSize = defaults.EventBufferSize
@@ -240,13 +410,18 @@ func New(registry *adapters.Registry, config *Config) *Exchange {
 		fpdConfig = fpd.DefaultConfig()
 	}
 
+	httpClient := adapters.NewHTTPClient(config.DefaultTimeout)
+	httpClient.SetMaxResponseBytes(config.MaxResponseBytes)
+
 	ex := &Exchange{
 		registry:       registry,
-		httpClient:     adapters.NewHTTPClient(config.DefaultTimeout),
+		httpClient:     httpClient,
 		config:         config,
 		fpdProcessor:   fpd.NewProcessor(fpdConfig),
 		eidFilter:      fpd.NewEIDFilter(fpdConfig),
 		bidderBreakers: make(map[string]*idr.CircuitBreaker),
+		bidExpiry:      NewBidExpiryRegistry(config.ExpiryRetention),
+		bidderValues:   newBidderValues(),
 	}
 
 	// Initialize circuit breaker for each registered bidder
@@ -265,6 +440,11 @@ func New(registry *adapters.Registry, config *Config) *Exchange {
 	return ex
 }
 
+// BidExpiry returns the registry of billing windows for returned bids
+func (e *Exchange) BidExpiry() *BidExpiryRegistry {
+	return e.bidExpiry
+}
+
 // SetMetrics sets the metrics recorder for tracking revenue/margins
 func (e *Exchange) SetMetrics(m MetricsRecorder) {
 	e.configMu.Lock()
@@ -272,14 +452,141 @@ func (e *Exchange) SetMetrics(m MetricsRecorder) {
 	e.metrics = m
 }
 
+// SetBidderGDPRScopes replaces the per-bidder GDPR scope overrides.
+// Bidders without an entry use middleware.GDPRScopeEEAOnly.
+func (e *Exchange) SetBidderGDPRScopes(scopes map[string]middleware.GDPRScope) {
+	e.configMu.Lock()
+	defer e.configMu.Unlock()
+	e.bidderGDPRScopes = scopes
+}
+
+// getBidderGDPRScope returns the GDPR scope for a bidder
+func (e *Exchange) getBidderGDPRScope(bidderCode string) middleware.GDPRScope {
+	e.configMu.RLock()
+	defer e.configMu.RUnlock()
+	if scope, ok := e.bidderGDPRScopes[bidderCode]; ok {
+		return scope
+	}
+	return middleware.GDPRScopeEEAOnly
+}
+
+// SetBidderExtPassthrough replaces the per-bidder ext passthrough policies.
+// Bidders without an entry forward all unknown ext fields.
+func (e *Exchange) SetBidderExtPassthrough(policies map[string]ExtPassthroughPolicy) {
+	e.configMu.Lock()
+	defer e.configMu.Unlock()
+	e.bidderExtPolicies = policies
+}
+
+// getBidderExtPassthrough returns the ext passthrough policy for a bidder
+func (e *Exchange) getBidderExtPassthrough(bidderCode string) ExtPassthroughPolicy {
+	e.configMu.RLock()
+	defer e.configMu.RUnlock()
+	if policy, ok := e.bidderExtPolicies[bidderCode]; ok {
+		return policy
+	}
+	return ExtPassthroughPolicy{Mode: ExtPassthroughAll}
+}
+
+// SetCurrencyConverter sets the rates used to convert floors and bids.
+// Without a converter, floors and bids in another currency can't be compared.
+func (e *Exchange) SetCurrencyConverter(c *currency.Converter) {
+	e.configMu.Lock()
+	defer e.configMu.Unlock()
+	e.currency = c
+}
+
+// SetBidderCurrencies replaces the per-bidder bidding currencies.
+// Bidders without an entry bid in the exchange's default currency.
+func (e *Exchange) SetBidderCurrencies(currencies map[string]string) {
+	e.configMu.Lock()
+	defer e.configMu.Unlock()
+	e.bidderCurrencies = currencies
+}
+
+// currencyConverter returns the current currency converter (may be nil)
+func (e *Exchange) currencyConverter() *currency.Converter {
+	e.configMu.RLock()
+	defer e.configMu.RUnlock()
+	return e.currency
+}
+
+// exchangeCurrency returns the currency floors are enforced and bids are
+// returned in
+func (e *Exchange) exchangeCurrency() string {
+	if e.config == nil {
+		return currency.DefaultCurrency
+	}
+	return currency.Normalize(e.config.DefaultCurrency)
+}
+
+// responseCurrency returns the currency bids are returned in and the rate
+// converting exchange currency prices into it: the exchange currency when
+// the request names none or allows it, otherwise the first currency in
+// req.cur with a known rate
+func (e *Exchange) responseCurrency(req *openrtb.BidRequest) (string, float64) {
+	exchangeCur := e.exchangeCurrency()
+	for _, cur := range req.Cur {
+		if currency.Normalize(cur) == exchangeCur {
+			return exchangeCur, 1
+		}
+	}
+	converter := e.currencyConverter()
+	for _, cur := range req.Cur {
+		if rate, err := converter.Rate(exchangeCur, cur); err == nil {
+			return currency.Normalize(cur), rate
+		}
+	}
+	return exchangeCur, 1
+}
+
+// getBidderCurrency returns the currency a bidder bids in
+func (e *Exchange) getBidderCurrency(bidderCode string) string {
+	e.configMu.RLock()
+	defer e.configMu.RUnlock()
+	if cur, ok := e.bidderCurrencies[bidderCode]; ok && cur != "" {
+		return currency.Normalize(cur)
+	}
+	return e.exchangeCurrency()
+}
+
+// SnapshotName implements warmcache.Provider
+func (e *Exchange) SnapshotName() string {
+	return "bidder_gdpr_scopes"
+}
+
+// Snapshot implements warmcache.Provider, exporting per-bidder GDPR scopes
+func (e *Exchange) Snapshot() (json.RawMessage, error) {
+	e.configMu.RLock()
+	defer e.configMu.RUnlock()
+	return json.Marshal(e.bidderGDPRScopes)
+}
+
+// Restore implements warmcache.Provider. Scopes are only restored when none
+// were loaded from PostgreSQL at startup (e.g. the database was unreachable).
+func (e *Exchange) Restore(data json.RawMessage) error {
+	var scopes map[string]middleware.GDPRScope
+	if err := json.Unmarshal(data, &scopes); err != nil {
+		return err
+	}
+
+	e.configMu.Lock()
+	defer e.configMu.Unlock()
+	if len(e.bidderGDPRScopes) == 0 {
+		e.bidderGDPRScopes = scopes
+	}
+	return nil
+}
+
+// EventRecorder returns the IDR analytics event recorder, nil when event
+// recording is disabled
+func (e *Exchange) EventRecorder() *idr.EventRecorder {
+	return e.eventRecorder
+}
+
 // Close shuts down the exchange and flushes pending events
 func (e *Exchange) Close() error {
-	// Close circuit breakers (wait for pending callbacks)
-	e.bidderBreakersMu.RLock()
-	for _, breaker := range e.bidderBreakers {
-		breaker.Close()
-	}
-	e.bidderBreakersMu.RUnlock()
+	e.CloseCircuitBreakers()
 
 	// Flush event recorder
 	if e.eventRecorder != nil {
@@ -288,6 +595,16 @@ func (e *Exchange) Close() error {
 	return nil
 }
 
+// CloseCircuitBreakers waits for the bidder circuit breakers' pending
+// state change callbacks
+func (e *Exchange) CloseCircuitBreakers() {
+	e.bidderBreakersMu.RLock()
+	defer e.bidderBreakersMu.RUnlock()
+	for _, breaker := range e.bidderBreakers {
+		breaker.Close()
+	}
+}
+
 // initBidderCircuitBreaker initializes a circuit breaker for a specific bidder
 func (e *Exchange) initBidderCircuitBreaker(bidderCode string) {
 	config := &idr.CircuitBreakerConfig{
@@ -345,6 +662,14 @@ type AuctionRequest struct {
 	Timeout    time.Duration
 	Account    string
 	Debug      bool
+
+	// BidInjection replaces one bidder's response with a canned one (see
+	// BidInjectionHeader)
+	BidInjection *BidInjection
+
+	// Shadow marks traffic mirrored to a warm standby instance: bidders are
+	// called in test mode and the auction isn't counted in reporting rollups
+	Shadow bool
 }
 
 // AuctionResponse contains auction results
@@ -353,6 +678,12 @@ type AuctionResponse struct {
 	BidderResults map[string]*BidderResult
 	IDRResult     *idr.SelectPartnersResponse
 	DebugInfo     *DebugInfo
+	RequestID     string   // Edge X-Request-ID, carried into tracking URLs
+	PodFill       *PodFill // Ad pod as assembled under the publisher's pod rules; nil for non-pod requests
+
+	// DeviceGraph is the viewer's household and device as resolved by the
+	// device graph; nil when not looked up or unresolved
+	DeviceGraph *DeviceGraphIdentity
 }
 
 // BidderResult contains results from a single bidder
@@ -374,6 +705,7 @@ type DebugInfo struct {
 	BidderLatencies map[string]time.Duration
 	SelectedBidders []string
 	ExcludedBidders []string
+	FanoutSaved     time.Duration // Timeout budget left when the last bidder answered
 	Errors          map[string][]string
 	errorsMu        sync.Mutex // Protects concurrent access to Errors map
 }
@@ -482,6 +814,9 @@ type BidValidationError struct {
 	ImpID      string
 	Reason     string
 	BidderCode string
+	// LossReason is the OpenRTB loss reason sent in the bid's lurl;
+	// 0 reports LossInvalidBidResponse
+	LossReason int
 }
 
 func (e *BidValidationError) Error() string {
@@ -528,6 +863,11 @@ func validateBidMediaType(bid *openrtb.Bid, imp *openrtb.Imp) error {
 	// Determine which media type the bid is for based on its properties
 	// Priority: Video (has w/h or protocol) > Native > Banner (default)
 
+	// Audio bids carry a VAST/DAAST protocol but no dimensions
+	if imp.Audio != nil && imp.Video == nil && bid.W == 0 && bid.H == 0 {
+		return nil
+	}
+
 	// Check if this is a video bid
 	isVideoBid := bid.Protocol > 0 || (bid.W > 0 && bid.H > 0 && imp.Video != nil)
 
@@ -676,6 +1016,7 @@ func (e *Exchange) validateBid(bid *openrtb.Bid, bidderCode string, req *openrtb
 			ImpID:      bid.ImpID,
 			BidderCode: bidderCode,
 			Reason:     fmt.Sprintf("price %.4f below floor %.4f", bid.Price, floor),
+			LossReason: LossBelowFloor,
 		}
 	}
 
@@ -714,6 +1055,7 @@ func (e *Exchange) validateBid(bid *openrtb.Bid, bidderCode string, req *openrtb
 						ImpID:      bid.ImpID,
 						BidderCode: bidderCode,
 						Reason:     fmt.Sprintf("blocked advertiser domain: %s", adomain),
+						LossReason: LossAdvertiserExclusions,
 					}
 				}
 			}
@@ -731,6 +1073,17 @@ func (e *Exchange) validateBid(bid *openrtb.Bid, bidderCode string, req *openrtb
 		}
 	}
 
+	// Reject creative attributes blocked by the imp (request battr or publisher defaults)
+	if err := validateBidAttributes(bid, imp); err != nil {
+		return &BidValidationError{
+			BidID:      bid.ID,
+			ImpID:      bid.ImpID,
+			BidderCode: bidderCode,
+			Reason:     err.Error(),
+			LossReason: LossCreativeAttributeExclusions,
+		}
+	}
+
 	// HIGH FIX #3: Validate bid dimensions for banner impressions
 	// OpenRTB 2.5: Banner bid dimensions must match one of the allowed formats
 	if imp.Banner != nil {
@@ -740,6 +1093,7 @@ func (e *Exchange) validateBid(bid *openrtb.Bid, bidderCode string, req *openrtb
 				ImpID:      bid.ImpID,
 				BidderCode: bidderCode,
 				Reason:     err.Error(),
+				LossReason: LossCreativeSizeNotAllowed,
 			}
 		}
 	}
@@ -766,6 +1120,9 @@ func (e *Exchange) buildImpFloorMap(ctx context.Context, req *openrtb.BidRequest
 		}
 	}
 
+	converter := e.currencyConverter()
+	exchangeCur := e.exchangeCurrency()
+
 	// Build floor map with multiplier applied
 	floorsAdjusted := 0
 	for _, imp := range req.Imp {
@@ -789,6 +1146,21 @@ func (e *Exchange) buildImpFloorMap(ctx context.Context, req *openrtb.BidRequest
 			baseFloor = 0
 		}
 
+		// Enforce floors in the currency bids are normalized to
+		if baseFloor > 0 {
+			converted, err := converter.Convert(baseFloor, imp.BidFloorCur, exchangeCur)
+			if err != nil {
+				logger.Log.Warn().
+					Str("impID", imp.ID).
+					Str("bidfloorcur", imp.BidFloorCur).
+					Err(err).
+					Msg("Cannot convert floor currency, not enforcing floor")
+				impFloors[imp.ID] = 0
+				continue
+			}
+			baseFloor = converted
+		}
+
 		if multiplier != 1.0 && baseFloor > 0 {
 			// Multiply floor so DSPs must bid higher to cover platform's cut
 			adjustedFloor := baseFloor * multiplier
@@ -835,7 +1207,7 @@ func (e *Exchange) buildImpFloorMap(ctx context.Context, req *openrtb.BidRequest
 		e.configMu.RLock()
 		if e.metrics != nil {
 			for i := 0; i < floorsAdjusted; i++ {
-				e.metrics.RecordFloorAdjustment(publisherID)
+				e.metrics.RecordFloorAdjustment(FloorRuleBidMultiplier, FloorActionRaised)
 			}
 		}
 		e.configMu.RUnlock()
@@ -849,6 +1221,8 @@ type ValidatedBid struct {
 	Bid        *adapters.TypedBid
 	BidderCode string
 	DemandType adapters.DemandType // platform (obfuscated) or publisher (transparent)
+	GrossPrice float64             // bidder's price before the bid multiplier; 0 if not adjusted
+	PacedDeal  bool                // bid for a booked deal behind its goal; see markPacedDeals
 }
 
 // runAuctionLogic applies auction rules (first-price or second-price) to validated bids
@@ -867,10 +1241,12 @@ func (e *Exchange) runAuctionLogic(validBids []ValidatedBid, impFloors map[strin
 			continue
 		}
 
-		// Sort by price descending
+		// Sort by price descending, then put deals behind their goal first
 		sortBidsByPrice(bids)
+		paced := e.prioritizePacedDeals(bids, impFloors[impID])
 
-		if e.config.AuctionType == SecondPriceAuction {
+		// A paced deal that jumped higher bids clears at its own price
+		if e.config.AuctionType == SecondPriceAuction && !paced {
 			var winningPrice float64
 			originalBidPrice := bids[0].Bid.Bid.Price
 
@@ -1178,6 +1554,7 @@ func (e *Exchange) applyBidMultiplier(ctx context.Context, bidsByImp map[string]
 					e.configMu.RUnlock()
 				}
 
+				bids[i].GrossPrice = originalPrice
 				bids[i].Bid.Bid.Price = adjustedPrice
 			}
 		}
@@ -1241,6 +1618,9 @@ func extractPublisherID(v interface{}) (string, bool) {
 // RunAuction executes the auction
 func (e *Exchange) RunAuction(ctx context.Context, req *AuctionRequest) (*AuctionResponse, error) {
 	startTime := time.Now()
+	if finish := e.sampleAllocations(); finish != nil {
+		defer finish()
+	}
 
 	// P0-7: Validate required BidRequest fields per OpenRTB 2.x spec
 	if req.BidRequest == nil {
@@ -1299,6 +1679,7 @@ func (e *Exchange) RunAuction(ctx context.Context, req *AuctionRequest) (*Auctio
 	}
 
 	response := &AuctionResponse{
+		RequestID:     logger.RequestIDFromContext(ctx),
 		BidderResults: make(map[string]*BidderResult),
 		DebugInfo: &DebugInfo{
 			RequestTime:     startTime,
@@ -1313,7 +1694,24 @@ func (e *Exchange) RunAuction(ctx context.Context, req *AuctionRequest) (*Auctio
 		return response, validationErr
 	}
 
-	// Get timeout from request or config
+	// Count the request for reporting rollups, the auction registry, the
+	// auction trail and analytics however the auction ends; shadow traffic is
+	// a copy of requests counted elsewhere
+	var trail *auctiontrail.Trail
+	if req.Shadow {
+		req.BidRequest.Test = 1
+	} else {
+		defer e.recordRollup(req.BidRequest, response)
+		defer e.publishAuctionSummary(req.BidRequest, response)
+		defer e.trackAuctionEvents(req.BidRequest, response)
+		trail = e.startAuctionTrail(ctx, req.BidRequest, startTime)
+		defer e.recordAuctionTrail(trail, response)
+	}
+
+	// Publisher overrides of the exchange-wide timeout and bidder selection
+	fanout := publisherFanoutFromContext(ctx)
+
+	// Get timeout from request, publisher or config
 	// P1-NEW-1: Validate TMax bounds to prevent abuse
 	timeout := req.Timeout
 	if timeout == 0 && req.BidRequest.TMax > 0 {
@@ -1324,6 +1722,9 @@ func (e *Exchange) RunAuction(ctx context.Context, req *AuctionRequest) (*Auctio
 		}
 		timeout = time.Duration(tmax) * time.Millisecond
 	}
+	if timeout == 0 {
+		timeout = fanout.timeout
+	}
 	if timeout == 0 {
 		timeout = e.config.DefaultTimeout
 	}
@@ -1332,8 +1733,39 @@ func (e *Exchange) RunAuction(ctx context.Context, req *AuctionRequest) (*Auctio
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	// Get available bidders from static registry
-	availableBidders := e.registry.ListEnabledBidders()
+	// Reject countries the exchange or publisher doesn't sell before any
+	// bidder QPS is spent on them
+	if reason := e.checkGeo(ctx, req.BidRequest); reason != "" {
+		response.DebugInfo.AddError("geo", []string{reason})
+		response.BidResponse = e.buildEmptyResponse(req.BidRequest, openrtb.NoBidGeoBlocked)
+		return response, nil
+	}
+
+	// Add our hop to the supply chain bidders see
+	if problem := e.applySupplyChain(req.BidRequest); problem != "" {
+		response.DebugInfo.AddError("schain", []string{problem})
+	}
+
+	// Fill auctions that end without a valid bid with house ads, however they
+	// end; blocked countries above get nothing. The device graph lookup runs
+	// alongside the auction so house ads can be capped per household.
+	if !req.Shadow {
+		defer e.serveHouseAds(req.BidRequest, response, e.startDeviceGraphLookup(ctx, req.BidRequest))
+	}
+
+	// Ask identity providers for the viewer's IDs, and the UID2 operator
+	// about the publisher's UID2 token, while bidders are selected
+	var identity identityLookup
+	var uid2 uid2Check
+	if !req.Shadow {
+		identity = e.startIdentityLookup(ctx, req.BidRequest)
+		uid2 = e.startUID2Check(ctx, req.BidRequest)
+	}
+
+	// Get available bidders from static registry, less those the publisher
+	// doesn't work with
+	availableBidders, excludedBidders := fanout.filterBidders(e.registry.ListEnabledBidders())
+	response.DebugInfo.ExcludedBidders = append(response.DebugInfo.ExcludedBidders, excludedBidders...)
 
 	// Snapshot config-protected fields under lock for consistent view during auction
 	e.configMu.RLock()
@@ -1348,12 +1780,21 @@ func (e *Exchange) RunAuction(ctx context.Context, req *AuctionRequest) (*Auctio
 
 	// Run IDR selection if enabled
 	selectedBidders := availableBidders
-	if e.idrClient != nil && e.config.IDREnabled {
+	if e.idrClient != nil && e.config.IDREnabled && e.featureEnabled(featureflags.IDRSelection, req.BidRequest) {
 		idrStart := time.Now()
 
 		// P1-15: Build minimal request to reduce payload size
 		minReq := e.buildMinimalIDRRequest(req.BidRequest)
-		idrResult, err := e.idrClient.SelectPartnersMinimal(ctx, minReq, availableBidders)
+		minReq.ExplorationSeed = e.explorationSeed(req.BidRequest)
+		// Cap IDR at its dependency deadline so selection can't eat the bidder budget
+		idrCtx, idrCancel := deadline.WithCap(ctx, deadline.DependencyIDR)
+		var idrResult *idr.SelectPartnersResponse
+		err := e.injectDependencyFault(idrCtx, deadline.DependencyIDR)
+		if err == nil {
+			idrResult, err = e.idrClient.SelectPartnersMinimal(idrCtx, minReq, availableBidders)
+		}
+		err = deadline.Observe(idrCtx, deadline.DependencyIDR, err)
+		idrCancel()
 
 		response.DebugInfo.IDRLatency = time.Since(idrStart)
 
@@ -1371,8 +1812,35 @@ func (e *Exchange) RunAuction(ctx context.Context, req *AuctionRequest) (*Auctio
 		// If IDR fails, fall back to all bidders
 	}
 
+	// Enforce the per-request fan-out cap (deals first, then historical value)
+	maxBidders := fanout.maxBiddersOr(e.config.MaxBidders)
+	selectedBidders, droppedBidders := e.capBidders(req.BidRequest, selectedBidders, response.IDRResult, maxBidders)
+	if len(droppedBidders) > 0 {
+		response.DebugInfo.ExcludedBidders = append(response.DebugInfo.ExcludedBidders, droppedBidders...)
+		if e.metrics != nil {
+			e.metrics.RecordFanoutTruncated(len(selectedBidders)+len(droppedBidders), len(droppedBidders))
+		}
+		logger.Log.Debug().
+			Str("request_id", req.BidRequest.ID).
+			Int("max_bidders", maxBidders).
+			Strs("dropped", droppedBidders).
+			Msg("Fan-out cap truncated bidder selection")
+	}
+
+	selectedBidders = e.applyBidInjection(req.BidInjection, selectedBidders, response.DebugInfo)
 	response.DebugInfo.SelectedBidders = selectedBidders
 
+	// Merge publisher default blocked creative attributes into imps without battr
+	applyPublisherBlockedAttributes(ctx, req.BidRequest)
+
+	// Raise imp floors to the publisher's floor rules so bidders see them
+	ruleFloors := e.applyFloorRules(req.BidRequest)
+
+	// Replace or drop the publisher's UID2 token as the operator says, then
+	// add identity provider IDs before EIDs are filtered by source
+	uid2.apply(req.BidRequest)
+	mergeEIDs(req.BidRequest, identity.wait())
+
 	// Process FPD and filter EIDs (using snapshotted processor/filter for consistency)
 	var bidderFPD fpd.BidderFPD
 	if fpdProcessor != nil {
@@ -1391,7 +1859,9 @@ func (e *Exchange) RunAuction(ctx context.Context, req *AuctionRequest) (*Auctio
 	}
 
 	// Call bidders in parallel
-	results := e.callBiddersWithFPD(ctx, req.BidRequest, selectedBidders, timeout, bidderFPD)
+	results := e.callBiddersWithFPD(withBidInjection(ctx, req.BidInjection), req.BidRequest, selectedBidders, timeout, bidderFPD)
+	e.recordFanoutCompletion(ctx, response.DebugInfo)
+	e.bidderValues.observeResults(results)
 
 	// Extract request context for event recording
 	var country, deviceType, mediaType, adSize, publisherID string
@@ -1399,16 +1869,7 @@ func (e *Exchange) RunAuction(ctx context.Context, req *AuctionRequest) (*Auctio
 		country = req.BidRequest.Device.Geo.Country
 	}
 	if req.BidRequest.Device != nil {
-		switch req.BidRequest.Device.DeviceType {
-		case 1:
-			deviceType = "mobile"
-		case 2:
-			deviceType = "desktop"
-		case 3:
-			deviceType = "ctv"
-		default:
-			deviceType = "unknown"
-		}
+		deviceType = eventDeviceType(req.BidRequest.Device)
 	}
 	if len(req.BidRequest.Imp) > 0 {
 		imp := req.BidRequest.Imp[0]
@@ -1421,11 +1882,14 @@ func (e *Exchange) RunAuction(ctx context.Context, req *AuctionRequest) (*Auctio
 			mediaType = "video"
 		} else if imp.Native != nil {
 			mediaType = "native"
+		} else if imp.Audio != nil {
+			mediaType = "audio"
 		}
 	}
 	if req.BidRequest.Site != nil && req.BidRequest.Site.Publisher != nil {
 		publisherID = req.BidRequest.Site.Publisher.ID
 	}
+	mediaSubtype := requestMediaSubtype(req.BidRequest)
 
 	// P1-2: Check context deadline before expensive validation work
 	// If we've already timed out, return early with whatever we have
@@ -1444,6 +1908,21 @@ func (e *Exchange) RunAuction(ctx context.Context, req *AuctionRequest) (*Auctio
 	// Build impression map for O(1) lookups during bid validation
 	impMap := adapters.BuildImpMap(req.BidRequest.Imp)
 
+	// Bids above this are rejected as anomalous (partner unit bugs etc.)
+	maxBidCPM := e.maxBidCPM(ctx)
+
+	// Banner markup sanitization level for this publisher
+	sanitizeLevel := e.creativeSanitization(ctx)
+
+	// Publishers can require ads in the content's language
+	languageMode, wantLanguage := e.languageFilter(ctx, req.BidRequest)
+
+	// Blocked creatives are rejected; unreviewed ones follow the publisher's policy
+	e.configMu.RLock()
+	creativeRegistry := e.creativeRegistry
+	e.configMu.RUnlock()
+	creativePolicy := e.creativeApproval(ctx)
+
 	// Track seen bid IDs for deduplication
 	seenBidIDs := make(map[string]struct{})
 
@@ -1465,6 +1944,7 @@ func (e *Exchange) RunAuction(ctx context.Context, req *AuctionRequest) (*Auctio
 		if e.metrics != nil {
 			hasError := len(result.Errors) > 0
 			e.metrics.RecordBidderRequest(bidderCode, result.Latency, hasError, result.TimedOut)
+			e.metrics.RecordBidsPerRequest(bidderCode, mediaType, mediaSubtype, len(result.Bids))
 		}
 
 		if len(result.Errors) > 0 {
@@ -1475,8 +1955,8 @@ func (e *Exchange) RunAuction(ctx context.Context, req *AuctionRequest) (*Auctio
 			response.DebugInfo.AddError(bidderCode, errStrs)
 		}
 
-		// Record event to IDR
-		if e.eventRecorder != nil {
+		// Record event to IDR; shadow traffic would double-count the live auction
+		if e.eventRecorder != nil && !req.Shadow {
 			hadBid := len(result.Bids) > 0
 			var bidCPM *float64
 			if hadBid && len(result.Bids) > 0 {
@@ -1498,22 +1978,23 @@ func (e *Exchange) RunAuction(ctx context.Context, req *AuctionRequest) (*Auctio
 				}
 			}
 
-			e.eventRecorder.RecordBidResponse(
-				req.BidRequest.ID,
-				bidderCode,
-				float64(result.Latency.Milliseconds()),
-				hadBid,
-				bidCPM,
-				nil, // floor price
-				country,
-				deviceType,
-				mediaType,
-				adSize,
-				publisherID,
-				result.TimedOut, // P2-2: use actual timeout status
-				hadError,
-				errorMsg,
-			)
+			e.eventRecorder.RecordEvent(idr.BidEvent{
+				AuctionID:   req.BidRequest.ID,
+				RequestID:   logger.RequestIDFromContext(ctx),
+				BidderCode:  bidderCode,
+				EventType:   "bid_response",
+				LatencyMs:   float64(result.Latency.Milliseconds()),
+				HadBid:      hadBid,
+				BidCPM:      bidCPM,
+				Country:     country,
+				DeviceType:  deviceType,
+				MediaType:   mediaType,
+				AdSize:      adSize,
+				PublisherID: publisherID,
+				TimedOut:    result.TimedOut, // P2-2: use actual timeout status
+				HadError:    hadError,
+				ErrorMsg:    errorMsg,
+			})
 		}
 
 		// Validate and deduplicate bids
@@ -1525,7 +2006,14 @@ func (e *Exchange) RunAuction(ctx context.Context, req *AuctionRequest) (*Auctio
 
 			// Record bid received metric
 			if e.metrics != nil {
-				e.metrics.RecordBid(bidderCode, mediaType, tb.Bid.Price)
+				e.metrics.RecordBid(bidderCode, mediaType, mediaSubtype, tb.Bid.Price)
+			}
+
+			// Reject anomalous prices before they can win
+			if capErr := e.checkMaxBidCPM(ctx, tb.Bid, bidderCode, req.BidRequest.ID, maxBidCPM); capErr != nil {
+				validationErrors = append(validationErrors, capErr) //nolint:staticcheck
+				response.DebugInfo.AppendError(bidderCode, capErr.Error())
+				continue
 			}
 
 			// Validate bid
@@ -1538,11 +2026,36 @@ func (e *Exchange) RunAuction(ctx context.Context, req *AuctionRequest) (*Auctio
 					Float64("price", tb.Bid.Price).
 					Err(validErr).
 					Msg("bid validation failed")
+				if floor := impFloors[tb.Bid.ImpID]; e.metrics != nil && floor > 0 && tb.Bid.Price < floor {
+					e.metrics.RecordBidOutcome(bidderCode, mediaType, mediaSubtype, BidOutcomeBelowFloor, tb.Bid.Price)
+					e.metrics.RecordFloorAdjustment(floorRuleOf(ruleFloors, tb.Bid.ImpID), FloorActionRejected)
+				}
 				validationErrors = append(validationErrors, validErr) //nolint:staticcheck
 				response.DebugInfo.AppendError(bidderCode, validErr.Error())
 				continue
 			}
 
+			// Reject creatives in another language than the publisher requires
+			if langErr := e.checkBidLanguage(tb.Bid, bidderCode, languageMode, wantLanguage); langErr != nil {
+				validationErrors = append(validationErrors, langErr) //nolint:staticcheck
+				response.DebugInfo.AppendError(bidderCode, langErr.Error())
+				continue
+			}
+
+			// Reject blocked creatives, and unreviewed ones for publishers holding them
+			if crErr := e.checkCreativeApproval(creativeRegistry, tb.Bid, bidderCode, creativePolicy); crErr != nil {
+				validationErrors = append(validationErrors, crErr) //nolint:staticcheck
+				response.DebugInfo.AppendError(bidderCode, crErr.Error())
+				continue
+			}
+
+			// Sanitize banner markup; strict publishers reject insecure creatives
+			if sanErr := e.sanitizeBannerBid(tb, bidderCode, impMap[tb.Bid.ImpID], sanitizeLevel); sanErr != nil {
+				validationErrors = append(validationErrors, sanErr) //nolint:staticcheck
+				response.DebugInfo.AppendError(bidderCode, sanErr.Error())
+				continue
+			}
+
 			// Check for duplicate bid IDs
 			if _, seen := seenBidIDs[tb.Bid.ID]; seen {
 				dupErr := &BidValidationError{
@@ -1566,8 +2079,35 @@ func (e *Exchange) RunAuction(ctx context.Context, req *AuctionRequest) (*Auctio
 		}
 	}
 
-	// Apply auction logic (first-price or second-price)
+	// Snapshot bids for the auction trail before auction rules reprice them.
+	// Rejections explain trail entries and losses.
+	e.configMu.RLock()
+	notifyLosses := e.lossNotifier != nil && !req.Shadow
+	e.configMu.RUnlock()
+	var rejected map[*adapters.TypedBid]*BidValidationError
+	if trail != nil || notifyLosses {
+		rejected = rejectedBids(results, validBids, validationErrors)
+	}
+	if trail != nil {
+		trail.Filters = auctiontrail.Filters{
+			MaxBidCPM:        maxBidCPM,
+			LanguageFilter:   languageMode,
+			ContentLanguage:  wantLanguage,
+			CreativeApproval: creativePolicy,
+			Sanitization:     sanitizeLevel,
+			BidMultiplier:    publisherBidMultiplier(ctx),
+		}
+		trail.Floors = impFloors
+		traceBids(trail, results, rejected)
+	}
+
+	// Apply auction logic (first-price or second-price), ordering bids so
+	// ties at the same price resolve by the configured rule
+	e.orderForTieBreak(req.BidRequest.ID, validBids)
+	prices := bidPrices(validBids)
+	e.markPacedDeals(req.BidRequest, validBids)
 	auctionedBids := e.runAuctionLogic(validBids, impFloors)
+	e.recordPriceLandscape(validBids, auctionedBids, prices, mediaType, mediaSubtype)
 
 	// Apply bid multiplier if publisher is configured with one
 	auctionedBids = e.applyBidMultiplier(ctx, auctionedBids)
@@ -1577,6 +2117,9 @@ func (e *Exchange) RunAuction(ctx context.Context, req *AuctionRequest) (*Auctio
 	// - Publisher demand: shown transparently with original bidder codes
 	seatBidMap := make(map[string]*openrtb.SeatBid)
 
+	// Bids are returned in the request's currency when it names one
+	responseCur, responseRate := e.responseCurrency(req.BidRequest)
+
 	for _, impBids := range auctionedBids {
 		// Separate platform and publisher bids for this impression
 		var platformBids []ValidatedBid
@@ -1593,13 +2136,9 @@ func (e *Exchange) RunAuction(ctx context.Context, req *AuctionRequest) (*Auctio
 
 		// Add highest platform bid to "thenexusengine" seat (obfuscated)
 		if len(platformBids) > 0 {
-			// Find highest CPM platform bid for this impression
+			// Bids are in auction order (price, with paced deals first), so
+			// the first platform bid is this impression's winner
 			highestPlatformBid := platformBids[0]
-			for _, vb := range platformBids[1:] {
-				if vb.Bid.Bid.Price > highestPlatformBid.Bid.Bid.Price {
-					highestPlatformBid = vb
-				}
-			}
 
 			// Get or create the thenexusengine seat
 			nexusSeat, ok := seatBidMap[adapters.PlatformSeatName]
@@ -1612,12 +2151,7 @@ func (e *Exchange) RunAuction(ctx context.Context, req *AuctionRequest) (*Auctio
 			}
 
 			// Create obfuscated bid with "thenexusengine" branding in targeting
-			bid := *highestPlatformBid.Bid.Bid
-			bidExt := e.buildBidExtension(highestPlatformBid)
-			if extBytes, err := json.Marshal(bidExt); err == nil {
-				bid.Ext = extBytes
-			}
-			nexusSeat.Bid = append(nexusSeat.Bid, bid)
+			nexusSeat.Bid = append(nexusSeat.Bid, e.responseBid(highestPlatformBid, responseRate, req.BidRequest))
 		}
 
 		// Add all publisher bids transparently
@@ -1632,12 +2166,7 @@ func (e *Exchange) RunAuction(ctx context.Context, req *AuctionRequest) (*Auctio
 			}
 
 			// Create bid copy with Prebid extension for targeting
-			bid := *vb.Bid.Bid
-			bidExt := e.buildBidExtension(vb)
-			if extBytes, err := json.Marshal(bidExt); err == nil {
-				bid.Ext = extBytes
-			}
-			sb.Bid = append(sb.Bid, bid)
+			sb.Bid = append(sb.Bid, e.responseBid(vb, responseRate, req.BidRequest))
 		}
 	}
 
@@ -1651,7 +2180,15 @@ func (e *Exchange) RunAuction(ctx context.Context, req *AuctionRequest) (*Auctio
 	response.BidResponse = &openrtb.BidResponse{
 		ID:      req.BidRequest.ID,
 		SeatBid: allBids,
-		Cur:     e.config.DefaultCurrency,
+		Cur:     responseCur,
+	}
+
+	// Report partial pod fill explicitly instead of returning a silently shorter pod
+	response.PodFill = e.assemblePod(ctx, req.BidRequest, response, responseRate)
+	attachPodFill(response.BidResponse, response.PodFill)
+	settleAuctionTrail(trail, auctionedBids, response.BidResponse)
+	if notifyLosses {
+		e.notifyLosses(req.BidRequest, results, rejected, auctionedBids, response.BidResponse)
 	}
 
 	response.DebugInfo.TotalLatency = time.Since(startTime)
@@ -1681,12 +2218,30 @@ func (e *Exchange) RunAuction(ctx context.Context, req *AuctionRequest) (*Auctio
 		// Use the mediaType variable from line 1018
 
 		// Record auction completion
-		e.metrics.RecordAuction(auctionStatus, mediaType, response.DebugInfo.TotalLatency, len(selectedBidders), 0)
+		e.metrics.RecordAuction(auctionStatus, mediaType, mediaSubtype, response.DebugInfo.TotalLatency, len(selectedBidders), 0)
 	}
 
 	return response, nil
 }
 
+// recordFanoutCompletion records how much of the auction deadline was left
+// when the fan-out finished. callBiddersWithFPD returns as soon as the last
+// bidder responds, so response assembly starts without waiting out tmax.
+func (e *Exchange) recordFanoutCompletion(ctx context.Context, debug *DebugInfo) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return
+	}
+	saved := time.Until(deadline)
+	if saved <= 0 {
+		return
+	}
+	debug.FanoutSaved = saved
+	if e.metrics != nil {
+		e.metrics.RecordFanoutEarlyCompletion(saved)
+	}
+}
+
 // callBiddersWithFPD calls all selected bidders in parallel with FPD support
 // P0-1: Uses sync.Map for thread-safe result collection
 // P0-4: Uses semaphore to limit concurrent bidder goroutines
@@ -1703,9 +2258,10 @@ func (e *Exchange) callBiddersWithFPD(ctx context.Context, req *openrtb.BidReque
 	// If maxConcurrent <= 0, sem remains nil (unlimited concurrency)
 
 	for _, bidderCode := range bidders {
-		// Check circuit breaker before calling bidder
+		// Check circuit breaker before calling bidder; injected responses
+		// never reach the bidder, so they bypass it
 		breaker := e.getBidderCircuitBreaker(bidderCode)
-		if breaker != nil && breaker.IsOpen() {
+		if breaker != nil && breaker.IsOpen() && injectedAdapterFor(ctx, bidderCode) == nil {
 			// Circuit breaker is open - skip this bidder
 			result := &BidderResult{
 				BidderCode: bidderCode,
@@ -1749,14 +2305,14 @@ func (e *Exchange) callBiddersWithFPD(ctx context.Context, req *openrtb.BidReque
 					}
 				}
 
-				// Check geo-aware consent filtering (GDPR, CCPA, etc.)
+				// Check geo-aware consent filtering: under GDPR the bidder's GVL
+				// vendor needs TCF consent (or legitimate interest) for the
+				// purposes in middleware.TCFVendorPurposes, and a bidder without
+				// a GVL ID is excluded unless its GDPR scope is never
 				gvlID := awi.Info.GVLVendorID
-				if middleware.ShouldFilterBidderByGeo(req, gvlID) {
-					// Detect which regulation applies
-					regulation := middleware.RegulationNone
-					if req.Device != nil && req.Device.Geo != nil {
-						regulation = middleware.DetectRegulationFromGeo(req.Device.Geo)
-					}
+				if middleware.ShouldFilterBidderByGeoScope(req, gvlID, e.getBidderGDPRScope(code)) {
+					// Only GDPR filters bidders; US privacy strips identifiers below
+					regulation := middleware.RegulationGDPR
 
 					logger.Log.Info().
 						Str("bidder", code).
@@ -1777,6 +2333,9 @@ func (e *Exchange) callBiddersWithFPD(ctx context.Context, req *openrtb.BidReque
 						}()).
 						Msg("Skipping bidder - no consent for user's geographic location")
 
+					if e.metrics != nil {
+						e.metrics.RecordPrivacyFiltered(code, middleware.PrivacyFilterReasonTCF)
+					}
 					results.Store(code, &BidderResult{
 						BidderCode: code,
 						Errors:     []error{fmt.Errorf("no %s consent for vendor %d", regulation, gvlID)},
@@ -1784,14 +2343,88 @@ func (e *Exchange) callBiddersWithFPD(ctx context.Context, req *openrtb.BidReque
 					return
 				}
 
+				// US Privacy opt-out: skip bidders that can't honor the signal,
+				// strip user IDs and precise geo for everyone else. GPP US
+				// sections count too, as does a known child's withheld
+				// consent. The privacy middleware also flags malformed signals
+				// as opt-outs under the no_consent policy.
+				gpp := middleware.ResolveGPP(req)
+				usPrivacyOptOut := middleware.ResolveUSPrivacy(req).OptedOut() || middleware.CCPAOptOut(ctx) || gpp.StripIdentifiers()
+				if usPrivacyOptOut && awi.Info.USPrivacyUnsupported {
+					logger.Log.Info().
+						Str("bidder", code).
+						Str("request_id", req.ID).
+						Msg("Skipping bidder - cannot honor US Privacy opt-out")
+
+					if e.metrics != nil {
+						e.metrics.RecordPrivacyFiltered(code, middleware.PrivacyFilterReasonUSPrivacy)
+					}
+					results.Store(code, &BidderResult{
+						BidderCode: code,
+						Errors:     []error{fmt.Errorf("bidder cannot honor us_privacy opt-out")},
+					})
+					return
+				}
+
 				// Clone request and apply bidder-specific FPD
 				bidderReq := e.cloneRequestWithFPD(req, code, bidderFPD)
 
-				result := e.callBidder(ctx, bidderReq, code, awi.Adapter, timeout)
+				// Only send the media types the bidder takes
+				if !filterImpsByMediaType(bidderReq, e.supportedMediaTypes(code, awi.Info, req.App != nil)) {
+					logger.Log.Debug().
+						Str("bidder", code).
+						Str("request_id", req.ID).
+						Msg("Skipping bidder - no impressions in supported media types")
+
+					results.Store(code, &BidderResult{
+						BidderCode: code,
+						Errors:     []error{fmt.Errorf("no impressions in supported media types")},
+					})
+					return
+				}
+				if usPrivacyOptOut {
+					middleware.StripUSPrivacyIdentifiers(bidderReq)
+				}
+				middleware.ScrubIdentifiers(bidderReq, e.identifierScrub(req))
+				if awi.Info.GPPUnsupported {
+					middleware.ApplyGPPUSPrivacy(bidderReq, gpp)
+				}
+				applyExtPassthrough(bidderReq, code, e.getBidderExtPassthrough(code))
+				if !e.uid2Allowed(code) {
+					stripUID2(bidderReq)
+				}
+				applyRequestID(bidderReq, logger.RequestIDFromContext(ctx))
+
+				adapter := awi.Adapter
+				injected := injectedAdapterFor(ctx, code)
+				if injected != nil {
+					adapter = injected
+				}
+
+				// Skip bidders over their contracted QPS rather than letting
+				// them answer 429; injected responses don't reach the bidder
+				if injected == nil && !e.allowBidderCall(ctx, code) {
+					if e.metrics != nil {
+						e.metrics.RecordBidderQPSSuppressed(code)
+					}
+					logger.Log.Debug().
+						Str("bidder", code).
+						Str("request_id", req.ID).
+						Msg("Skipping bidder - max QPS reached")
+
+					results.Store(code, &BidderResult{
+						BidderCode: code,
+						Errors:     []error{fmt.Errorf("max qps reached")},
+					})
+					return
+				}
+
+				result := e.callBidder(ctx, bidderReq, code, adapter, timeout)
 
-				// Record result in circuit breaker
+				// Record result in circuit breaker (canned responses say
+				// nothing about the bidder's health)
 				breaker := e.getBidderCircuitBreaker(code)
-				if breaker != nil {
+				if breaker != nil && injected == nil {
 					// Record request metric
 					if e.metrics != nil {
 						e.metrics.RecordBidderCircuitRequest(code)
@@ -1833,16 +2466,75 @@ func (e *Exchange) callBiddersWithFPD(ctx context.Context, req *openrtb.BidReque
 	return finalResults
 }
 
+// requestIDExtKey is the source.ext key carrying the edge request ID to bidders
+const requestIDExtKey = "tne_request_id"
+
+// applyRequestID forwards the edge X-Request-ID to a bidder request so it can
+// be traced from edge to bidder. The ID is used as source.tid when the
+// publisher didn't supply one, and is always added to source.ext.
+// Must only be called on a per-bidder clone (Source is deep-copied there).
+func applyRequestID(bidderReq *openrtb.BidRequest, requestID string) {
+	if requestID == "" {
+		return
+	}
+	if bidderReq.Source == nil {
+		bidderReq.Source = &openrtb.Source{}
+	}
+	if bidderReq.Source.TID == "" {
+		bidderReq.Source.TID = requestID
+	}
+
+	ext := make(map[string]json.RawMessage)
+	if len(bidderReq.Source.Ext) > 0 {
+		if err := json.Unmarshal(bidderReq.Source.Ext, &ext); err != nil {
+			// Leave malformed publisher ext untouched rather than dropping it
+			return
+		}
+	}
+	idJSON, err := json.Marshal(requestID)
+	if err != nil {
+		return
+	}
+	ext[requestIDExtKey] = idJSON
+	if extJSON, err := json.Marshal(ext); err == nil {
+		bidderReq.Source.Ext = extJSON
+	}
+}
+
+// convertImpFloor expresses an imp's floor in the bidder's currency. When no
+// rate is known the floor is left in its original currency rather than
+// relabelled, so bidders never see a floor in the wrong currency.
+func (e *Exchange) convertImpFloor(imp *openrtb.Imp, bidderCode, bidderCur string, converter *currency.Converter) {
+	if imp.BidFloor <= 0 {
+		imp.BidFloorCur = bidderCur
+		return
+	}
+	floor, err := converter.Convert(imp.BidFloor, imp.BidFloorCur, bidderCur)
+	if err != nil {
+		logger.Log.Debug().
+			Str("bidder", bidderCode).
+			Str("impID", imp.ID).
+			Err(err).
+			Msg("Cannot convert floor to bidder currency, sending original floor")
+		imp.BidFloorCur = currency.Normalize(imp.BidFloorCur)
+		return
+	}
+	imp.BidFloor = roundToCents(floor)
+	imp.BidFloorCur = bidderCur
+}
+
 // cloneRequestWithFPD creates a selective copy of the request with bidder-specific FPD applied
-// and enforces USD currency for all bid requests.
+// and requests bids (and expresses floors) in the bidder's currency.
 // PERF: Only clones fields that are modified (Cur, Imp, Site/App/User if FPD applies).
 // Deep copies Device, Regs, Source to prevent cross-bidder data races.
 func (e *Exchange) cloneRequestWithFPD(req *openrtb.BidRequest, bidderCode string, bidderFPD fpd.BidderFPD) *openrtb.BidRequest {
 	// Shallow copy of top-level struct
 	clone := *req
 
-	// Clone Cur slice (we overwrite it)
-	clone.Cur = []string{e.config.DefaultCurrency}
+	// Request bids in the bidder's currency (we overwrite Cur)
+	bidderCur := e.getBidderCurrency(bidderCode)
+	clone.Cur = []string{bidderCur}
+	converter := e.currencyConverter()
 
 	// Deep copy Device to prevent adapter mutations from affecting other bidders
 	if req.Device != nil {
@@ -1890,7 +2582,7 @@ func (e *Exchange) cloneRequestWithFPD(req *openrtb.BidRequest, bidderCode strin
 		clone.Imp = make([]openrtb.Imp, impCount)
 		for i := 0; i < impCount; i++ {
 			clone.Imp[i] = req.Imp[i] // Shallow copy of Imp struct
-			clone.Imp[i].BidFloorCur = e.config.DefaultCurrency
+			e.convertImpFloor(&clone.Imp[i], bidderCode, bidderCur, converter)
 
 			// Deep copy pointer fields to prevent data corruption (CVE-2026-XXXX)
 			if req.Imp[i].Banner != nil {
@@ -2147,6 +2839,7 @@ func deepCloneRequest(req *openrtb.BidRequest, limits *CloneLimits) *openrtb.Bid
 // callBidder calls a single bidder
 func (e *Exchange) callBidder(ctx context.Context, req *openrtb.BidRequest, bidderCode string, adapter adapters.Adapter, timeout time.Duration) *BidderResult {
 	start := time.Now()
+	log := logger.FromContext(logger.WithBidder(ctx, bidderCode))
 	result := &BidderResult{
 		BidderCode: bidderCode,
 		Selected:   true,
@@ -2166,8 +2859,7 @@ func (e *Exchange) callBidder(ctx context.Context, req *openrtb.BidRequest, bidd
 	select {
 	case <-ctx.Done():
 		// P3-1: Log bidder timeout after MakeRequests
-		logger.Log.Debug().
-			Str("bidder", bidderCode).
+		log.Debug().
 			Dur("elapsed", time.Since(start)).
 			Msg("bidder timed out after MakeRequests")
 		result.Errors = append(result.Errors, ctx.Err())
@@ -2206,13 +2898,31 @@ func (e *Exchange) callBidder(ctx context.Context, req *openrtb.BidRequest, bidd
 				Headers:    reqData.Headers,
 			}
 		} else {
-			var err error
-			resp, err = e.httpClient.Do(ctx, reqData, timeout)
+			reqData.Headers = e.withBidderHeaders(bidderCode, reqData.Headers)
+			// Forward the edge request ID so bidder-side logs can be correlated
+			if requestID := logger.RequestIDFromContext(ctx); requestID != "" {
+				if reqData.Headers == nil {
+					reqData.Headers = http.Header{}
+				}
+				if reqData.Headers.Get("X-Request-ID") == "" {
+					reqData.Headers.Set("X-Request-ID", requestID)
+				}
+			}
+			err := e.injectBidderFault(ctx, bidderCode)
+			if err == nil {
+				resp, err = e.httpClient.Do(ctx, reqData, timeout)
+			}
+			if e.metrics != nil {
+				if resp != nil {
+					e.metrics.RecordBidderPayloadSize(bidderCode, len(reqData.Body), len(resp.Body))
+				} else if errors.Is(err, adapters.ErrResponseTooLarge) {
+					e.metrics.RecordBidderResponseOversized(bidderCode)
+				}
+			}
 			if err != nil {
 				// P3-1: Log HTTP request failures with context
 				isTimeout := errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled)
-				logger.Log.Debug().
-					Str("bidder", bidderCode).
+				log.Debug().
 					Str("uri", reqData.URI).
 					Dur("elapsed", time.Since(start)).
 					Bool("timeout", isTimeout).
@@ -2278,19 +2988,26 @@ func (e *Exchange) callBidder(ctx context.Context, req *openrtb.BidRequest, bidd
 
 			// P1-NEW-4: Defensive check for exchange currency misconfiguration
 			// Normalize exchange currency to USD if empty to prevent silent validation bypass
-			exchangeCurrency := e.config.DefaultCurrency
-			if exchangeCurrency == "" {
-				exchangeCurrency = "USD" // Fallback if misconfigured
-			}
+			exchangeCurrency := e.exchangeCurrency()
 
-			if responseCurrency != exchangeCurrency {
+			// Convert bids into the exchange currency so they can be compared
+			// against floors and each other
+			rate, err := e.currencyConverter().Rate(responseCurrency, exchangeCurrency)
+			if err != nil {
 				result.Errors = append(result.Errors, fmt.Errorf(
-					"currency mismatch from %s: expected %s, got %s (bids rejected)",
-					bidderCode, exchangeCurrency, responseCurrency,
+					"currency mismatch from %s: expected %s, got %s (bids rejected): %w",
+					bidderCode, exchangeCurrency, responseCurrency, err,
 				))
 				// Skip bids with wrong currency - can't safely compare prices
 				continue
 			}
+			if rate != 1 {
+				for _, tb := range bidderResp.Bids {
+					if tb != nil && tb.Bid != nil {
+						tb.Bid.Price *= rate
+					}
+				}
+			}
 
 			allBids = append(allBids, bidderResp.Bids...)
 		}
@@ -2304,12 +3021,107 @@ func (e *Exchange) callBidder(ctx context.Context, req *openrtb.BidRequest, bidd
 // buildEmptyResponse creates an empty bid response with optional NBR code
 // P2-7: Using consolidated NoBidReason type from openrtb package
 func (e *Exchange) buildEmptyResponse(req *openrtb.BidRequest, nbr openrtb.NoBidReason) *openrtb.BidResponse {
-	return &openrtb.BidResponse{
+	resp := &openrtb.BidResponse{
 		ID:      req.ID,
 		SeatBid: []openrtb.SeatBid{},
 		Cur:     e.config.DefaultCurrency,
 		NBR:     int(nbr),
 	}
+	attachPodFill(resp, BuildPodFill(req, resp))
+	return resp
+}
+
+// eventDeviceType maps an OpenRTB device type to the device dimension of
+// analytics events
+func eventDeviceType(device *openrtb.Device) string {
+	switch device.DeviceType {
+	case 1:
+		return "mobile"
+	case 2:
+		return "desktop"
+	case 3:
+		return "ctv"
+	default:
+		return "unknown"
+	}
+}
+
+// bidAdSize returns a bid's WxH, falling back to its impression's banner
+// size, or "" when neither is known
+func bidAdSize(bid *openrtb.Bid, imp *openrtb.Imp) string {
+	if bid.W > 0 && bid.H > 0 {
+		return fmt.Sprintf("%dx%d", bid.W, bid.H)
+	}
+	if imp != nil && imp.Banner != nil && imp.Banner.W > 0 && imp.Banner.H > 0 {
+		return fmt.Sprintf("%dx%d", imp.Banner.W, imp.Banner.H)
+	}
+	return ""
+}
+
+// trackBidExpiry stamps the effective billing window on a returned bid and
+// registers it so late win/billing notices can be rejected and accepted
+// notices can be processed asynchronously. meta is the bid's normalized
+// prebid meta, carrying its media type and buyer. grossPrice is the bidder's
+// price before the bid multiplier, or 0 when the price wasn't adjusted.
+func (e *Exchange) trackBidExpiry(bid *openrtb.Bid, bidderCode string, meta *openrtb.ExtBidPrebidMeta, grossPrice float64, req *openrtb.BidRequest) {
+	exp := effectiveExpiry(bid, findImpression(req.Imp, bid.ImpID), e.config.ImpExpiry)
+	bid.Exp = int(exp / time.Second)
+	if grossPrice == 0 {
+		grossPrice = bid.Price
+	}
+	if e.bidExpiry != nil {
+		notice := BidNotice{
+			BidID:       bid.ID,
+			Bidder:      bidderCode,
+			AuctionID:   req.ID,
+			ImpID:       bid.ImpID,
+			PublisherID: requestPublisherID(req),
+			Country:     requestCountry(req),
+			AdSize:      bidAdSize(bid, findImpression(req.Imp, bid.ImpID)),
+			DealID:      bid.DealID,
+			Price:       bid.Price,
+			GrossPrice:  grossPrice,
+			Currency:    e.exchangeCurrency(),
+			NURL:        bid.NURL,
+			BURL:        bid.BURL,
+		}
+		if req.Device != nil {
+			notice.DeviceType = eventDeviceType(req.Device)
+		}
+		if meta != nil {
+			notice.MediaType = meta.MediaType
+			notice.Advertiser = advertiserOf(meta)
+			notice.CampaignID = meta.CampaignID
+			notice.CreativeID = meta.CreativeID
+			if len(meta.AdvertiserDomains) > 0 {
+				notice.AdvertiserDomain = meta.AdvertiserDomains[0]
+			}
+		}
+		e.bidExpiry.TrackNotice(notice, exp)
+	}
+}
+
+// responseBid copies an auctioned bid into the response with its Prebid
+// extension, its price and price bucket converted at rate into the response
+// currency. Win and billing notices keep the exchange currency price.
+func (e *Exchange) responseBid(vb ValidatedBid, rate float64, req *openrtb.BidRequest) openrtb.Bid {
+	shown := vb
+	if rate != 1 {
+		tb := *vb.Bid
+		converted := *tb.Bid
+		converted.Price *= rate
+		tb.Bid = &converted
+		shown.Bid = &tb
+	}
+
+	bid := *vb.Bid.Bid
+	bidExt := e.buildBidExtension(shown)
+	if extBytes, err := json.Marshal(bidExt); err == nil {
+		bid.Ext = extBytes
+	}
+	e.trackBidExpiry(&bid, vb.BidderCode, bidExt.Prebid.Meta, vb.GrossPrice, req)
+	bid.Price = shown.Bid.Bid.Price
+	return bid
 }
 
 // buildBidExtension creates the Prebid extension for a bid including targeting keys
@@ -2351,13 +3163,23 @@ func (e *Exchange) buildBidExtension(vb ValidatedBid) *openrtb.BidExt {
 		targeting["hb_deal_"+displayBidderCode] = bid.DealID
 	}
 
+	// Buyer metadata from the bidder's ext survives the ext being replaced,
+	// so publishers can see which advertiser ran
+	meta := buyerMeta(bid, vb.Bid.BidMeta)
+	if meta == nil {
+		meta = &openrtb.ExtBidPrebidMeta{}
+	}
+	meta.MediaType = bidType
+	if len(meta.AdvertiserDomains) > 0 {
+		targeting["hb_adomain"] = meta.AdvertiserDomains[0]
+		targeting["hb_adomain_"+displayBidderCode] = meta.AdvertiserDomains[0]
+	}
+
 	return &openrtb.BidExt{
 		Prebid: &openrtb.ExtBidPrebid{
 			Type:      bidType,
 			Targeting: targeting,
-			Meta: &openrtb.ExtBidPrebidMeta{
-				MediaType: bidType,
-			},
+			Meta:      meta,
 		},
 	}
 }
@@ -957,17 +957,33 @@ func TestApplyBidMultiplier_NilBid(t *testing.T) {
 
 type mockMetricsRecorder struct{}
 
-func (m *mockMetricsRecorder) RecordAuction(status, mediaType string, duration time.Duration, biddersSelected, biddersExcluded int) {
+func (m *mockMetricsRecorder) RecordAuction(status, mediaType, mediaSubtype string, duration time.Duration, biddersSelected, biddersExcluded int) {
 }
-func (m *mockMetricsRecorder) RecordBid(bidder, mediaType string, cpm float64) {}
+func (m *mockMetricsRecorder) RecordBid(bidder, mediaType, mediaSubtype string, cpm float64) {}
 func (m *mockMetricsRecorder) RecordBidderRequest(bidder string, latency time.Duration, hasError, timedOut bool) {
 }
 func (m *mockMetricsRecorder) RecordMargin(publisher, bidder, mediaType string, originalPrice, adjustedPrice, platformCut float64) {
 }
-func (m *mockMetricsRecorder) RecordFloorAdjustment(publisher string)                   {}
+func (m *mockMetricsRecorder) RecordFloorAdjustment(rule, action string)               {}
+func (m *mockMetricsRecorder) RecordPodRuleEnforcement(rule string, count int) {}
 func (m *mockMetricsRecorder) SetBidderCircuitState(bidder, state string)               {}
 func (m *mockMetricsRecorder) RecordBidderCircuitRequest(bidder string)                 {}
 func (m *mockMetricsRecorder) RecordBidderCircuitFailure(bidder string)                 {}
 func (m *mockMetricsRecorder) RecordBidderCircuitSuccess(bidder string)                 {}
 func (m *mockMetricsRecorder) RecordBidderCircuitRejected(bidder string)                {}
 func (m *mockMetricsRecorder) RecordBidderCircuitStateChange(bidder, from, to string) {}
+func (m *mockMetricsRecorder) RecordPrivacyFiltered(bidder, reason string) {}
+func (m *mockMetricsRecorder) RecordFanoutEarlyCompletion(saved time.Duration) {}
+func (m *mockMetricsRecorder) RecordFanoutTruncated(candidates, dropped int) {}
+func (m *mockMetricsRecorder) RecordAuctionAllocations(allocBytes, allocObjects, heapBytes uint64) {}
+func (m *mockMetricsRecorder) RecordBidderPayloadSize(bidder string, requestBytes, responseBytes int) {}
+func (m *mockMetricsRecorder) RecordBidderResponseOversized(bidder string) {}
+func (m *mockMetricsRecorder) RecordBidderQPSSuppressed(bidder string) {}
+func (m *mockMetricsRecorder) RecordBidPriceCapExceeded(bidder string) {}
+func (m *mockMetricsRecorder) RecordCreativeSanitization(bidder, action string, count int) {}
+func (m *mockMetricsRecorder) RecordBidLanguage(bidder, language, outcome string) {}
+func (m *mockMetricsRecorder) RecordCreativeApproval(bidder, outcome string) {}
+func (m *mockMetricsRecorder) RecordGeoRejection(country, reason string) {}
+func (m *mockMetricsRecorder) RecordAuctionFill(mediaType, source string) {}
+func (m *mockMetricsRecorder) RecordBidOutcome(bidder, mediaType, mediaSubtype, outcome string, cpm float64) {}
+func (m *mockMetricsRecorder) RecordBidsPerRequest(bidder, mediaType, mediaSubtype string, bids int)         {}
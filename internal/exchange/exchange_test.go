@@ -3,12 +3,16 @@ package exchange
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/currency"
 	"github.com/thenexusengine/tne_springwire/internal/fpd"
 	"github.com/thenexusengine/tne_springwire/internal/middleware"
 	"github.com/thenexusengine/tne_springwire/internal/openrtb"
@@ -1711,6 +1715,50 @@ func TestRunAuction_TMaxCapping(t *testing.T) {
 	}
 }
 
+// fanoutMetrics records fan-out completions on top of mockMetrics
+type fanoutMetrics struct {
+	mockMetrics
+	saved []time.Duration
+}
+
+func (m *fanoutMetrics) RecordFanoutEarlyCompletion(saved time.Duration) {
+	m.saved = append(m.saved, saved)
+}
+
+func TestRunAuction_EarlyFanoutCompletion(t *testing.T) {
+	registry := adapters.NewRegistry()
+	registry.Register("bidder1", &mockAdapter{}, adapters.BidderInfo{Enabled: true})
+	registry.Register("bidder2", &mockAdapter{}, adapters.BidderInfo{Enabled: true})
+
+	ex := New(registry, &Config{DefaultTimeout: 2 * time.Second})
+	metrics := &fanoutMetrics{}
+	ex.SetMetrics(metrics)
+
+	req := &AuctionRequest{
+		BidRequest: &openrtb.BidRequest{
+			ID:   "test-early",
+			Site: testSite(),
+			Imp:  []openrtb.Imp{{ID: "imp1", Banner: &openrtb.Banner{W: 300, H: 250}}},
+		},
+	}
+
+	start := time.Now()
+	resp, err := ex.RunAuction(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("auction waited for the timeout window: %v", elapsed)
+	}
+
+	if len(metrics.saved) != 1 || metrics.saved[0] < time.Second {
+		t.Errorf("expected one completion with most of the timeout saved, got %v", metrics.saved)
+	}
+	if resp.DebugInfo.FanoutSaved < time.Second {
+		t.Errorf("expected FanoutSaved in debug info, got %v", resp.DebugInfo.FanoutSaved)
+	}
+}
+
 func TestSortBidsByPrice_NilBids(t *testing.T) {
 	// Test with nil bids in the slice - should handle gracefully
 	bids := []ValidatedBid{
@@ -1920,14 +1968,15 @@ func TestSelectiveClone_OriginalNotMutated(t *testing.T) {
 	origDeviceUA := original.Device.UA
 
 	// Clone with FPD (no FPD data, so Site/App/User won't be cloned)
+	ex.SetCurrencyConverter(currency.NewConverter("USD", map[string]float64{"EUR": 1.2}))
 	clone := ex.cloneRequestWithFPD(original, "bidder1", nil)
 
 	// Verify clone has modified values
 	if clone.Cur[0] != "USD" {
 		t.Errorf("expected clone Cur = USD, got %s", clone.Cur[0])
 	}
-	if clone.Imp[0].BidFloorCur != "USD" {
-		t.Errorf("expected clone BidFloorCur = USD, got %s", clone.Imp[0].BidFloorCur)
+	if clone.Imp[0].BidFloorCur != "USD" || clone.Imp[0].BidFloor != 1.80 {
+		t.Errorf("expected clone floor 1.80 USD, got %.2f %s", clone.Imp[0].BidFloor, clone.Imp[0].BidFloorCur)
 	}
 
 	// Verify original is NOT mutated
@@ -2001,6 +2050,45 @@ func TestSelectiveClone_WithFPD(t *testing.T) {
 	}
 }
 
+func TestApplyRequestID(t *testing.T) {
+	ex := New(adapters.NewRegistry(), &Config{DefaultTimeout: 100 * time.Millisecond})
+	original := &openrtb.BidRequest{
+		ID:     "req-1",
+		Source: &openrtb.Source{Ext: json.RawMessage(`{"omidpn":"tne"}`)},
+	}
+
+	clone := ex.cloneRequestWithFPD(original, "bidder1", nil)
+	applyRequestID(clone, "edge-req-1")
+
+	if clone.Source.TID != "edge-req-1" {
+		t.Errorf("expected source.tid to be the request ID, got %q", clone.Source.TID)
+	}
+	var ext map[string]string
+	if err := json.Unmarshal(clone.Source.Ext, &ext); err != nil {
+		t.Fatalf("invalid source.ext: %v", err)
+	}
+	if ext[requestIDExtKey] != "edge-req-1" || ext["omidpn"] != "tne" {
+		t.Errorf("expected request ID merged into source.ext, got %v", ext)
+	}
+	if original.Source.TID != "" || string(original.Source.Ext) != `{"omidpn":"tne"}` {
+		t.Error("original Source was mutated")
+	}
+
+	// A publisher-supplied tid is preserved
+	withTID := &openrtb.BidRequest{ID: "req-2", Source: &openrtb.Source{TID: "pub-tid"}}
+	applyRequestID(withTID, "edge-req-2")
+	if withTID.Source.TID != "pub-tid" {
+		t.Errorf("expected publisher tid to be kept, got %q", withTID.Source.TID)
+	}
+
+	// No request ID leaves the request untouched
+	empty := &openrtb.BidRequest{ID: "req-3"}
+	applyRequestID(empty, "")
+	if empty.Source != nil {
+		t.Error("expected no source to be added without a request ID")
+	}
+}
+
 // BenchmarkSelectiveClone benchmarks the new selective clone vs deep clone
 func BenchmarkSelectiveClone(b *testing.B) {
 	registry := adapters.NewRegistry()
@@ -2128,17 +2216,95 @@ func TestExchange_buildImpFloorMap_NoPublisherContext(t *testing.T) {
 // mockMetrics for testing
 type mockMetrics struct{}
 
-func (m *mockMetrics) RecordAuction(status, mediaType string, duration time.Duration, biddersSelected, biddersExcluded int) {
+func (m *mockMetrics) RecordAuction(status, mediaType, mediaSubtype string, duration time.Duration, biddersSelected, biddersExcluded int) {
 }
-func (m *mockMetrics) RecordBid(bidder, mediaType string, cpm float64) {}
+func (m *mockMetrics) RecordBid(bidder, mediaType, mediaSubtype string, cpm float64) {}
 func (m *mockMetrics) RecordBidderRequest(bidder string, latency time.Duration, hasError, timedOut bool) {
 }
 func (m *mockMetrics) RecordMargin(publisher, bidder, mediaType string, originalPrice, adjustedPrice, platformCut float64) {
 }
-func (m *mockMetrics) RecordFloorAdjustment(publisher string) {}
+func (m *mockMetrics) RecordFloorAdjustment(rule, action string) {}
+func (m *mockMetrics) RecordPodRuleEnforcement(rule string, count int) {}
 func (m *mockMetrics) SetBidderCircuitState(bidder, state string) {}
 func (m *mockMetrics) RecordBidderCircuitRequest(bidder string)   {}
 func (m *mockMetrics) RecordBidderCircuitFailure(bidder string)   {}
 func (m *mockMetrics) RecordBidderCircuitSuccess(bidder string)   {}
 func (m *mockMetrics) RecordBidderCircuitRejected(bidder string)  {}
 func (m *mockMetrics) RecordBidderCircuitStateChange(bidder, fromState, toState string) {}
+func (m *mockMetrics) RecordPrivacyFiltered(bidder, reason string) {}
+func (m *mockMetrics) RecordFanoutEarlyCompletion(saved time.Duration) {}
+func (m *mockMetrics) RecordFanoutTruncated(candidates, dropped int) {}
+func (m *mockMetrics) RecordAuctionAllocations(allocBytes, allocObjects, heapBytes uint64) {}
+func (m *mockMetrics) RecordBidderPayloadSize(bidder string, requestBytes, responseBytes int) {}
+func (m *mockMetrics) RecordBidderResponseOversized(bidder string) {}
+func (m *mockMetrics) RecordBidderQPSSuppressed(bidder string) {}
+func (m *mockMetrics) RecordBidPriceCapExceeded(bidder string) {}
+func (m *mockMetrics) RecordCreativeSanitization(bidder, action string, count int) {}
+func (m *mockMetrics) RecordBidLanguage(bidder, language, outcome string) {}
+func (m *mockMetrics) RecordCreativeApproval(bidder, outcome string) {}
+func (m *mockMetrics) RecordGeoRejection(country, reason string) {}
+func (m *mockMetrics) RecordAuctionFill(mediaType, source string) {}
+func (m *mockMetrics) RecordBidOutcome(bidder, mediaType, mediaSubtype, outcome string, cpm float64) {}
+func (m *mockMetrics) RecordBidsPerRequest(bidder, mediaType, mediaSubtype string, bids int)         {}
+
+// payloadMetrics records bidder payload sizes on top of mockMetrics
+type payloadMetrics struct {
+	mockMetrics
+	mu        sync.Mutex
+	sizes     map[string][2]int
+	oversized map[string]int
+}
+
+func (m *payloadMetrics) RecordBidderPayloadSize(bidder string, requestBytes, responseBytes int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sizes[bidder] = [2]int{requestBytes, responseBytes}
+}
+
+func (m *payloadMetrics) RecordBidderResponseOversized(bidder string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.oversized[bidder]++
+}
+
+func TestCallBidder_PayloadSizeLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/huge" {
+			w.Write([]byte(strings.Repeat(" ", 4096)))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	registry := adapters.NewRegistry()
+	body := []byte(`{"id":"test-size"}`)
+	registry.Register("huge", &mockAdapter{requests: []*adapters.RequestData{{Method: "POST", URI: server.URL + "/huge", Body: body}}}, adapters.BidderInfo{Enabled: true})
+	registry.Register("small", &mockAdapter{requests: []*adapters.RequestData{{Method: "POST", URI: server.URL + "/small", Body: body}}}, adapters.BidderInfo{Enabled: true})
+
+	config := DefaultConfig()
+	config.MaxResponseBytes = 1024
+	ex := New(registry, config)
+	metrics := &payloadMetrics{sizes: map[string][2]int{}, oversized: map[string]int{}}
+	ex.SetMetrics(metrics)
+
+	bidReq := &openrtb.BidRequest{
+		ID:   "test-size",
+		Imp:  []openrtb.Imp{{ID: "imp1", Banner: &openrtb.Banner{}}},
+		Site: &openrtb.Site{Domain: "example.com"},
+	}
+	results := ex.callBiddersWithFPD(context.Background(), bidReq, []string{"huge", "small"}, time.Second, fpd.BidderFPD{})
+
+	if r := results["huge"]; r == nil || len(r.Errors) == 0 || !errors.Is(r.Errors[0], adapters.ErrResponseTooLarge) {
+		t.Errorf("expected oversized response to be rejected, got %+v", r)
+	}
+	if metrics.oversized["huge"] != 1 {
+		t.Errorf("expected 1 oversized response recorded, got %d", metrics.oversized["huge"])
+	}
+	if _, ok := metrics.sizes["huge"]; ok {
+		t.Error("expected no payload size for a rejected response")
+	}
+	if got := metrics.sizes["small"]; got != [2]int{len(body), 0} {
+		t.Errorf("expected request/response sizes [%d 0], got %v", len(body), got)
+	}
+}
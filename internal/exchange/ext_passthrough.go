@@ -0,0 +1,149 @@
+package exchange
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+// ExtPassthroughMode controls forwarding of unknown ext fields to a bidder
+type ExtPassthroughMode string
+
+const (
+	// ExtPassthroughAll forwards every unknown ext subtree (default)
+	ExtPassthroughAll ExtPassthroughMode = "all"
+	// ExtPassthroughAllowlist forwards only allowlisted unknown ext keys
+	ExtPassthroughAllowlist ExtPassthroughMode = "allowlist"
+	// ExtPassthroughNone strips every unknown ext subtree
+	ExtPassthroughNone ExtPassthroughMode = "none"
+)
+
+// Ext object names used to qualify allowlist entries (e.g. "imp.gpid_extra")
+const (
+	extObjectRequest = "request"
+	extObjectImp     = "imp"
+	extObjectSite    = "site"
+	extObjectApp     = "app"
+	extObjectUser    = "user"
+)
+
+// knownExtKeys are ext keys the exchange or adapters consume; they are always
+// forwarded regardless of policy. The bidder's own params key in imp.ext is
+// also always kept.
+var knownExtKeys = map[string]map[string]bool{
+	extObjectRequest: {"prebid": true, "schain": true},
+	extObjectImp:     {"prebid": true, "bidder": true, "data": true, "gpid": true, "tid": true, "skadn": true, "omid": true},
+	extObjectSite:    {"data": true, "amp": true},
+	extObjectApp:     {"data": true},
+	extObjectUser:    {"data": true, "consent": true, "eids": true, "prebid": true},
+}
+
+// ExtPassthroughPolicy is a bidder's passthrough policy for unknown ext fields
+type ExtPassthroughPolicy struct {
+	Mode      ExtPassthroughMode
+	Allowlist map[string]bool // "key" (any ext object) or "object.key"
+}
+
+// ParseExtPassthroughMode converts a stored mode to an ExtPassthroughMode,
+// defaulting to ExtPassthroughAll for unknown values
+func ParseExtPassthroughMode(s string) ExtPassthroughMode {
+	switch ExtPassthroughMode(s) {
+	case ExtPassthroughAllowlist, ExtPassthroughNone:
+		return ExtPassthroughMode(s)
+	default:
+		return ExtPassthroughAll
+	}
+}
+
+// NewExtPassthroughPolicy builds a policy from a mode and allowlist entries
+func NewExtPassthroughPolicy(mode string, allowlist []string) ExtPassthroughPolicy {
+	p := ExtPassthroughPolicy{Mode: ParseExtPassthroughMode(mode)}
+	if len(allowlist) > 0 {
+		p.Allowlist = make(map[string]bool, len(allowlist))
+		for _, key := range allowlist {
+			if key = strings.TrimSpace(key); key != "" {
+				p.Allowlist[key] = true
+			}
+		}
+	}
+	return p
+}
+
+// allows reports whether an unknown key in the named ext object may be forwarded
+func (p ExtPassthroughPolicy) allows(object, key string) bool {
+	switch p.Mode {
+	case ExtPassthroughNone:
+		return false
+	case ExtPassthroughAllowlist:
+		return p.Allowlist[key] || p.Allowlist[object+"."+key]
+	default:
+		return true
+	}
+}
+
+// filterExt applies the policy to one ext object, returning the original
+// bytes when nothing was removed. Malformed ext is left untouched.
+func (p ExtPassthroughPolicy) filterExt(ext json.RawMessage, object, bidderCode string) json.RawMessage {
+	if len(ext) == 0 {
+		return ext
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(ext, &fields); err != nil {
+		return ext
+	}
+
+	known := knownExtKeys[object]
+	removed := false
+	for key := range fields {
+		if known[key] || (object == extObjectImp && key == bidderCode) || p.allows(object, key) {
+			continue
+		}
+		delete(fields, key)
+		removed = true
+	}
+	if !removed {
+		return ext
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+
+	filtered, err := json.Marshal(fields)
+	if err != nil {
+		return ext
+	}
+	return filtered
+}
+
+// applyExtPassthrough strips unknown ext fields from a per-bidder request
+// according to the bidder's policy. Site, App and User are copied before being
+// modified since cloneRequestWithFPD may share them with the original request.
+func applyExtPassthrough(bidderReq *openrtb.BidRequest, bidderCode string, policy ExtPassthroughPolicy) {
+	if policy.Mode == ExtPassthroughAll {
+		return
+	}
+
+	bidderReq.Ext = policy.filterExt(bidderReq.Ext, extObjectRequest, bidderCode)
+
+	for i := range bidderReq.Imp {
+		bidderReq.Imp[i].Ext = policy.filterExt(bidderReq.Imp[i].Ext, extObjectImp, bidderCode)
+	}
+
+	if bidderReq.Site != nil && len(bidderReq.Site.Ext) > 0 {
+		siteCopy := *bidderReq.Site
+		siteCopy.Ext = policy.filterExt(siteCopy.Ext, extObjectSite, bidderCode)
+		bidderReq.Site = &siteCopy
+	}
+	if bidderReq.App != nil && len(bidderReq.App.Ext) > 0 {
+		appCopy := *bidderReq.App
+		appCopy.Ext = policy.filterExt(appCopy.Ext, extObjectApp, bidderCode)
+		bidderReq.App = &appCopy
+	}
+	if bidderReq.User != nil && len(bidderReq.User.Ext) > 0 {
+		userCopy := *bidderReq.User
+		userCopy.Ext = policy.filterExt(userCopy.Ext, extObjectUser, bidderCode)
+		bidderReq.User = &userCopy
+	}
+}
@@ -0,0 +1,100 @@
+package exchange
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+func extPassthroughRequest() *openrtb.BidRequest {
+	return &openrtb.BidRequest{
+		ID:  "ext-req",
+		Ext: json.RawMessage(`{"prebid":{"debug":true},"partner_trace":"abc","custom":1}`),
+		Imp: []openrtb.Imp{{
+			ID:  "1",
+			Ext: json.RawMessage(`{"appnexus":{"placementId":1},"gpid":"/slot","omid":{"version":"1.0"},"partner_slot":"x"}`),
+		}},
+		Site: &openrtb.Site{ID: "s1", Ext: json.RawMessage(`{"data":{"cat":"news"},"segments":[1,2]}`)},
+		User: &openrtb.User{ID: "u1", Ext: json.RawMessage(`{"consent":"CO","segments":[3]}`)},
+	}
+}
+
+func extKeys(t *testing.T, raw json.RawMessage) map[string]bool {
+	t.Helper()
+	keys := make(map[string]bool)
+	if len(raw) == 0 {
+		return keys
+	}
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &m); err != nil {
+		t.Fatalf("invalid ext %s: %v", raw, err)
+	}
+	for k := range m {
+		keys[k] = true
+	}
+	return keys
+}
+
+func TestApplyExtPassthrough_None(t *testing.T) {
+	original := extPassthroughRequest()
+	req := *original
+	req.Imp = append([]openrtb.Imp(nil), original.Imp...)
+
+	applyExtPassthrough(&req, "appnexus", NewExtPassthroughPolicy("none", nil))
+
+	if k := extKeys(t, req.Ext); !k["prebid"] || k["partner_trace"] || k["custom"] {
+		t.Errorf("expected only known request ext keys, got %v", k)
+	}
+	if k := extKeys(t, req.Imp[0].Ext); !k["appnexus"] || !k["gpid"] || !k["omid"] || k["partner_slot"] {
+		t.Errorf("expected bidder params and known imp keys only, got %v", k)
+	}
+	if k := extKeys(t, req.Site.Ext); !k["data"] || k["segments"] {
+		t.Errorf("expected site.ext.data only, got %v", k)
+	}
+	if k := extKeys(t, req.User.Ext); !k["consent"] || k["segments"] {
+		t.Errorf("expected user.ext.consent only, got %v", k)
+	}
+
+	// Shared Site/User must not be modified
+	if !extKeys(t, original.Site.Ext)["segments"] || !extKeys(t, original.User.Ext)["segments"] {
+		t.Error("original site/user ext was mutated")
+	}
+}
+
+func TestApplyExtPassthrough_Allowlist(t *testing.T) {
+	req := extPassthroughRequest()
+	applyExtPassthrough(req, "rubicon", NewExtPassthroughPolicy("allowlist", []string{"partner_trace", "user.segments"}))
+
+	if k := extKeys(t, req.Ext); !k["partner_trace"] || k["custom"] {
+		t.Errorf("expected allowlisted request key only, got %v", k)
+	}
+	if k := extKeys(t, req.User.Ext); !k["segments"] {
+		t.Errorf("expected object-qualified allowlist entry to apply, got %v", k)
+	}
+	if k := extKeys(t, req.Site.Ext); k["segments"] {
+		t.Errorf("expected user-only allowlist entry not to apply to site, got %v", k)
+	}
+	if k := extKeys(t, req.Imp[0].Ext); k["appnexus"] || k["partner_slot"] {
+		t.Errorf("expected other bidders' params stripped, got %v", k)
+	}
+}
+
+func TestApplyExtPassthrough_AllIsDefault(t *testing.T) {
+	ex := New(adapters.NewRegistry(), &Config{DefaultTimeout: 100 * time.Millisecond})
+	if ex.getBidderExtPassthrough("unknown").Mode != ExtPassthroughAll {
+		t.Error("expected all mode by default")
+	}
+	if ParseExtPassthroughMode("bogus") != ExtPassthroughAll {
+		t.Error("expected unknown modes to fall back to all")
+	}
+
+	req := extPassthroughRequest()
+	before := string(req.Ext)
+	applyExtPassthrough(req, "appnexus", ExtPassthroughPolicy{Mode: ExtPassthroughAll})
+	if string(req.Ext) != before {
+		t.Error("expected all mode to leave ext untouched")
+	}
+}
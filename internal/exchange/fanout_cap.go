@@ -0,0 +1,167 @@
+package exchange
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/pkg/idr"
+)
+
+// bidderValueWeight is the weight of the newest auction in a bidder's
+// historical value; roughly the last 20 auctions dominate the average
+const bidderValueWeight = 0.1
+
+// bidderValues tracks an exponentially weighted average of each bidder's top
+// CPM per auction (no-bids and timeouts count as zero). It ranks bidders when
+// the fan-out cap truncates selection and IDR scores are not available.
+//
+// Bidders never called start at the prior, the average value across observed
+// bidders, so new bidders get called. A bidder the cap drops drifts back
+// toward the prior each time, so a low value is eventually measured again
+// instead of keeping the bidder out for good.
+type bidderValues struct {
+	mu     sync.RWMutex
+	values map[string]float64
+	sum    float64 // sum of values, for the prior
+}
+
+func newBidderValues() *bidderValues {
+	return &bidderValues{values: make(map[string]float64)}
+}
+
+// observe folds one auction result into the bidder's historical value
+func (v *bidderValues) observe(bidder string, topCPM float64) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	current, ok := v.values[bidder]
+	if !ok {
+		v.set(bidder, topCPM)
+		return
+	}
+	v.set(bidder, current+bidderValueWeight*(topCPM-current))
+}
+
+// set replaces a bidder's value, keeping the sum current. Caller must hold mu.
+func (v *bidderValues) set(bidder string, value float64) {
+	v.sum += value - v.values[bidder]
+	v.values[bidder] = value
+}
+
+// priorLocked is the value of a bidder never observed. Caller must hold mu.
+func (v *bidderValues) priorLocked() float64 {
+	if len(v.values) == 0 {
+		return 0
+	}
+	return v.sum / float64(len(v.values))
+}
+
+// get returns the bidder's historical value, the prior when never observed
+func (v *bidderValues) get(bidder string) float64 {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	if value, ok := v.values[bidder]; ok {
+		return value
+	}
+	return v.priorLocked()
+}
+
+// explore moves bidders dropped by the cap toward the prior, as if they had
+// bid the average, so bidders below it are tried again after enough drops
+func (v *bidderValues) explore(dropped []string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	prior := v.priorLocked()
+	for _, bidder := range dropped {
+		if current, ok := v.values[bidder]; ok && current < prior {
+			v.set(bidder, current+bidderValueWeight*(prior-current))
+		}
+	}
+}
+
+// observeResults records the top CPM of every bidder that was called
+func (v *bidderValues) observeResults(results map[string]*BidderResult) {
+	for code, result := range results {
+		if result == nil || !result.Selected {
+			continue
+		}
+		top := 0.0
+		for _, tb := range result.Bids {
+			if tb != nil && tb.Bid != nil && tb.Bid.Price > top {
+				top = tb.Bid.Price
+			}
+		}
+		v.observe(code, top)
+	}
+}
+
+// capBidders enforces the fan-out cap limit (Config.MaxBidders, or the
+// publisher's max_bidders) on the selected bidders. When the cap truncates
+// selection, bidders are kept in priority order:
+//
+//  1. bidders a deal on any impression is booked for
+//  2. higher IDR score, when IDR selected the bidders
+//  3. higher historical value (see bidderValues)
+//
+// Ties keep their selection order. It returns the kept and the dropped bidders.
+func (e *Exchange) capBidders(req *openrtb.BidRequest, bidders []string, idrResult *idr.SelectPartnersResponse, limit int) ([]string, []string) {
+	if limit <= 0 || len(bidders) <= limit {
+		return bidders, nil
+	}
+
+	dealBidders := e.dealBidderSet(req)
+	var scores map[string]float64
+	if idrResult != nil {
+		scores = make(map[string]float64, len(idrResult.SelectedBidders))
+		for _, sb := range idrResult.SelectedBidders {
+			scores[sb.BidderCode] = sb.Score
+		}
+	}
+	value := func(code string) float64 {
+		if scores != nil {
+			return scores[code]
+		}
+		return e.bidderValues.get(code)
+	}
+
+	ranked := make([]string, len(bidders))
+	copy(ranked, bidders)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		di, dj := dealBidders[ranked[i]], dealBidders[ranked[j]]
+		if di != dj {
+			return di
+		}
+		return value(ranked[i]) > value(ranked[j])
+	})
+
+	kept, dropped := ranked[:limit], ranked[limit:]
+	if scores == nil {
+		e.bidderValues.explore(dropped)
+	}
+	return kept, dropped
+}
+
+// dealBidderSet returns the bidders the deals on any impression are booked
+// for. A deal's wseat lists buyer seats, not bidders, so only deals the pacer
+// knows the bidder of count.
+func (e *Exchange) dealBidderSet(req *openrtb.BidRequest) map[string]bool {
+	bidders := make(map[string]bool)
+	e.configMu.RLock()
+	pacer := e.dealPacer
+	e.configMu.RUnlock()
+	if req == nil || pacer == nil {
+		return bidders
+	}
+	publisherID := requestPublisherID(req)
+	for _, imp := range req.Imp {
+		if imp.PMP == nil {
+			continue
+		}
+		for _, deal := range imp.PMP.Deals {
+			if bidder, ok := dealOwner(pacer, deal.ID, publisherID); ok {
+				bidders[bidder] = true
+			}
+		}
+	}
+	return bidders
+}
@@ -0,0 +1,120 @@
+package exchange
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/pkg/idr"
+)
+
+func TestCapBidders(t *testing.T) {
+	plain := &openrtb.BidRequest{Imp: []openrtb.Imp{{ID: "1"}}}
+	withDeal := &openrtb.BidRequest{Imp: []openrtb.Imp{{
+		ID:  "1",
+		PMP: &openrtb.PMP{Deals: []openrtb.Deal{{ID: "d1", WSeat: []string{"b"}}}},
+	}}}
+
+	tests := []struct {
+		name        string
+		req         *openrtb.BidRequest
+		bidders     []string
+		idr         *idr.SelectPartnersResponse
+		wantKept    []string
+		wantDropped []string
+	}{
+		{"under cap", plain, []string{"a", "b"}, nil, []string{"a", "b"}, nil},
+		{"historical value", plain, []string{"b", "c", "d"}, nil, []string{"c", "d"}, []string{"b"}},
+		{"unseen bidder at prior", plain, []string{"a", "b", "c"}, nil, []string{"c", "a"}, []string{"b"}},
+		{"deals first", withDeal, []string{"b", "c", "d"}, nil, []string{"d", "c"}, []string{"b"}},
+		{"idr score", plain, []string{"a", "b", "c"}, &idr.SelectPartnersResponse{SelectedBidders: []idr.SelectedBidder{
+			{BidderCode: "a", Score: 0.9}, {BidderCode: "b", Score: 0.5}, {BidderCode: "c", Score: 0.1},
+		}}, []string{"a", "b"}, []string{"c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ex := New(adapters.NewRegistry(), &Config{DefaultTimeout: 100 * time.Millisecond, MaxBidders: 2})
+			ex.SetDealPacer(testPacer{"d1": {bidder: "d"}})
+			ex.bidderValues.observe("c", 3.0)
+			ex.bidderValues.observe("d", 2.0)
+			ex.bidderValues.observe("b", 1.0)
+
+			kept, dropped := ex.capBidders(tt.req, tt.bidders, tt.idr, ex.config.MaxBidders)
+			if !reflect.DeepEqual(kept, tt.wantKept) || !reflect.DeepEqual(dropped, tt.wantDropped) {
+				t.Errorf("got kept=%v dropped=%v, want kept=%v dropped=%v", kept, dropped, tt.wantKept, tt.wantDropped)
+			}
+		})
+	}
+}
+
+func TestCapBidders_DroppedBidderExplored(t *testing.T) {
+	ex := New(adapters.NewRegistry(), &Config{DefaultTimeout: 100 * time.Millisecond, MaxBidders: 2})
+	ex.bidderValues.observe("a", 3.0)
+	ex.bidderValues.observe("b", 2.0)
+	ex.bidderValues.observe("c", 0.5)
+	req := &openrtb.BidRequest{Imp: []openrtb.Imp{{ID: "1"}}}
+
+	for i := 0; i < 50; i++ {
+		if _, dropped := ex.capBidders(req, []string{"a", "b", "c"}, nil, 2); len(dropped) == 1 && dropped[0] != "c" {
+			return
+		}
+	}
+	t.Errorf("expected the dropped bidder to be called again, value now %f", ex.bidderValues.get("c"))
+}
+
+func TestBidderValues_Observe(t *testing.T) {
+	v := newBidderValues()
+	v.observe("a", 2.0)
+	v.observe("a", 0)
+	if got := v.get("a"); got < 1.79 || got > 1.81 {
+		t.Errorf("expected decayed value 1.8, got %f", got)
+	}
+
+	v.observeResults(map[string]*BidderResult{
+		"b": {Selected: true, Bids: []*adapters.TypedBid{{Bid: &openrtb.Bid{Price: 1}}, {Bid: &openrtb.Bid{Price: 4}}}},
+		"c": {Selected: false, Bids: []*adapters.TypedBid{{Bid: &openrtb.Bid{Price: 9}}}},
+	})
+	if _, seen := v.values["c"]; v.get("b") != 4 || seen {
+		t.Errorf("expected top CPM for called bidders only, got b=%f c=%f", v.get("b"), v.get("c"))
+	}
+}
+
+// truncationMetrics records fan-out truncations on top of mockMetrics
+type truncationMetrics struct {
+	mockMetrics
+	candidates, dropped int
+}
+
+func (m *truncationMetrics) RecordFanoutTruncated(candidates, dropped int) {
+	m.candidates, m.dropped = candidates, dropped
+}
+
+func TestRunAuction_FanoutCap(t *testing.T) {
+	registry := adapters.NewRegistry()
+	for _, code := range []string{"bidder1", "bidder2", "bidder3"} {
+		registry.Register(code, &mockAdapter{}, adapters.BidderInfo{Enabled: true})
+	}
+	ex := New(registry, &Config{DefaultTimeout: 100 * time.Millisecond, MaxBidders: 2})
+	metrics := &truncationMetrics{}
+	ex.SetMetrics(metrics)
+
+	resp, err := ex.RunAuction(context.Background(), &AuctionRequest{
+		BidRequest: &openrtb.BidRequest{
+			ID:   "test-cap",
+			Site: testSite(),
+			Imp:  []openrtb.Imp{{ID: "imp1", Banner: &openrtb.Banner{W: 300, H: 250}}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.DebugInfo.SelectedBidders) != 2 || len(resp.DebugInfo.ExcludedBidders) != 1 {
+		t.Errorf("expected 2 selected and 1 excluded, got %v / %v", resp.DebugInfo.SelectedBidders, resp.DebugInfo.ExcludedBidders)
+	}
+	if metrics.candidates != 3 || metrics.dropped != 1 {
+		t.Errorf("expected truncation metric 3 candidates / 1 dropped, got %d / %d", metrics.candidates, metrics.dropped)
+	}
+}
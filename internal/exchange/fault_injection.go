@@ -0,0 +1,41 @@
+package exchange
+
+import "context"
+
+// FaultInjector delays, drops or fails calls to bidders and dependencies for
+// resilience rehearsals; implemented by chaos.Injector. A nil error means the
+// call should proceed.
+type FaultInjector interface {
+	InjectBidder(ctx context.Context, bidder string) error
+	InjectDependency(ctx context.Context, dependency string) error
+}
+
+// SetFaultInjector sets the injector consulted before bidder and IDR calls.
+// Nil (the default) disables fault injection.
+func (e *Exchange) SetFaultInjector(injector FaultInjector) {
+	e.configMu.Lock()
+	defer e.configMu.Unlock()
+	e.faultInjector = injector
+}
+
+// injectBidderFault applies any configured fault before a bidder HTTP call
+func (e *Exchange) injectBidderFault(ctx context.Context, bidderCode string) error {
+	e.configMu.RLock()
+	injector := e.faultInjector
+	e.configMu.RUnlock()
+	if injector == nil {
+		return nil
+	}
+	return injector.InjectBidder(ctx, bidderCode)
+}
+
+// injectDependencyFault applies any configured fault before a dependency call
+func (e *Exchange) injectDependencyFault(ctx context.Context, dependency string) error {
+	e.configMu.RLock()
+	injector := e.faultInjector
+	e.configMu.RUnlock()
+	if injector == nil {
+		return nil
+	}
+	return injector.InjectDependency(ctx, dependency)
+}
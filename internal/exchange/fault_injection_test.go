@@ -0,0 +1,79 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/fpd"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/pkg/idr"
+)
+
+var errTestFault = errors.New("injected")
+
+// stubFaultInjector fails calls to the listed bidders
+type stubFaultInjector struct {
+	bidders map[string]bool
+}
+
+func (s *stubFaultInjector) InjectBidder(ctx context.Context, bidder string) error {
+	if s.bidders[bidder] {
+		return errTestFault
+	}
+	return nil
+}
+
+func (s *stubFaultInjector) InjectDependency(ctx context.Context, dependency string) error {
+	return nil
+}
+
+// okHTTPClient answers every bidder call with an empty 200
+type okHTTPClient struct{ calls int }
+
+func (c *okHTTPClient) Do(ctx context.Context, req *adapters.RequestData, timeout time.Duration) (*adapters.ResponseData, error) {
+	c.calls++
+	return &adapters.ResponseData{StatusCode: 200, Body: []byte(`{}`)}, nil
+}
+
+func TestCallBidder_FaultInjection(t *testing.T) {
+	registry := adapters.NewRegistry()
+	adapter := &mockAdapter{requests: []*adapters.RequestData{{Method: "POST", URI: "http://bidder.example/bid"}}}
+	registry.Register("flaky", adapter, adapters.BidderInfo{Enabled: true})
+
+	ex := New(registry, DefaultConfig())
+	client := &okHTTPClient{}
+	ex.httpClient = client
+	injector := &stubFaultInjector{bidders: map[string]bool{"flaky": true}}
+	ex.SetFaultInjector(injector)
+
+	bidReq := &openrtb.BidRequest{
+		ID:   "test-chaos",
+		Imp:  []openrtb.Imp{{ID: "imp1", Banner: &openrtb.Banner{}}},
+		Site: &openrtb.Site{Domain: "example.com"},
+	}
+
+	// Injected failures count against the bidder like real ones
+	for i := 0; i < 5; i++ {
+		results := ex.callBiddersWithFPD(context.Background(), bidReq, []string{"flaky"}, 100*time.Millisecond, fpd.BidderFPD{})
+		if r := results["flaky"]; r == nil || len(r.Errors) == 0 || !errors.Is(r.Errors[0], errTestFault) {
+			t.Fatalf("expected injected error in bidder result, got %+v", r)
+		}
+	}
+	if client.calls != 0 {
+		t.Errorf("expected failed calls never to reach the bidder, got %d", client.calls)
+	}
+	if state := ex.getBidderCircuitBreaker("flaky").State(); state != idr.StateOpen {
+		t.Errorf("expected circuit to open after injected failures, got %s", state)
+	}
+
+	// Clearing the injector restores normal calls
+	ex.SetFaultInjector(nil)
+	ex.getBidderCircuitBreaker("flaky").Reset()
+	ex.callBiddersWithFPD(context.Background(), bidReq, []string{"flaky"}, 100*time.Millisecond, fpd.BidderFPD{})
+	if client.calls != 1 {
+		t.Errorf("expected bidder to be called once injection is off, got %d calls", client.calls)
+	}
+}
@@ -0,0 +1,46 @@
+package exchange
+
+import (
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/pkg/featureflags"
+)
+
+// FeatureFlags evaluates runtime feature flags for a publisher's session.
+// Implemented by *featureflags.Service.
+type FeatureFlags interface {
+	EnabledFor(name, publisherID, sessionID string) bool
+}
+
+// SetFeatureFlags sets the feature flag source consulted for gated features
+func (e *Exchange) SetFeatureFlags(flags FeatureFlags) {
+	e.configMu.Lock()
+	defer e.configMu.Unlock()
+	e.featureFlags = flags
+}
+
+// featureEnabled evaluates a flag for the request's publisher and session, falling back
+// to the flag's default when no flag source is configured
+func (e *Exchange) featureEnabled(name string, req *openrtb.BidRequest) bool {
+	e.configMu.RLock()
+	flags := e.featureFlags
+	e.configMu.RUnlock()
+
+	if flags == nil {
+		return featureflags.Defaults[name]
+	}
+	return flags.EnabledFor(name, requestPublisherID(req), requestSessionID(req))
+}
+
+// requestPublisherID returns the site or app publisher ID of a request
+func requestPublisherID(req *openrtb.BidRequest) string {
+	if req == nil {
+		return ""
+	}
+	if req.Site != nil && req.Site.Publisher != nil {
+		return req.Site.Publisher.ID
+	}
+	if req.App != nil && req.App.Publisher != nil {
+		return req.App.Publisher.ID
+	}
+	return ""
+}
@@ -0,0 +1,77 @@
+package exchange
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/pkg/featureflags"
+)
+
+type staticFlags map[string]map[string]bool
+
+func (f staticFlags) EnabledFor(name, publisherID, sessionID string) bool {
+	return f[name][publisherID]
+}
+
+func TestFeatureFlags_IDRSelectionPerPublisher(t *testing.T) {
+	var idrCalls int32
+	idrServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&idrCalls, 1)
+		w.Write([]byte(`{"selected_bidders": [], "excluded_bidders": []}`))
+	}))
+	defer idrServer.Close()
+
+	registry := adapters.NewRegistry()
+	registry.Register("bidder1", &mockAdapter{}, adapters.BidderInfo{Enabled: true})
+
+	ex := New(registry, &Config{
+		DefaultTimeout: 100 * time.Millisecond,
+		IDREnabled:     true,
+		IDRServiceURL:  idrServer.URL,
+	})
+	ex.SetFeatureFlags(staticFlags{featureflags.IDRSelection: {"pub-on": true}})
+
+	run := func(pubID string) {
+		_, err := ex.RunAuction(context.Background(), &AuctionRequest{
+			BidRequest: &openrtb.BidRequest{
+				ID:   "req-" + pubID,
+				Site: &openrtb.Site{ID: "site", Publisher: &openrtb.Publisher{ID: pubID}},
+				Imp:  []openrtb.Imp{{ID: "imp1", Banner: &openrtb.Banner{W: 300, H: 250}}},
+			},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	run("pub-off")
+	if got := atomic.LoadInt32(&idrCalls); got != 0 {
+		t.Fatalf("expected IDR skipped for flagged-off publisher, got %d calls", got)
+	}
+
+	run("pub-on")
+	if got := atomic.LoadInt32(&idrCalls); got != 1 {
+		t.Errorf("expected IDR called for flagged-on publisher, got %d calls", got)
+	}
+}
+
+func TestFeatureEnabled_DefaultsWithoutFlags(t *testing.T) {
+	ex := New(adapters.NewRegistry(), &Config{DefaultTimeout: 100 * time.Millisecond})
+	req := &openrtb.BidRequest{App: &openrtb.App{Publisher: &openrtb.Publisher{ID: "pub-1"}}}
+
+	if !ex.featureEnabled(featureflags.IDRSelection, req) {
+		t.Error("expected IDR selection on by default")
+	}
+	if !ex.featureEnabled(featureflags.FloorEngine, req) {
+		t.Error("expected floor engine on by default")
+	}
+	if got := requestPublisherID(req); got != "pub-1" {
+		t.Errorf("expected app publisher ID, got %q", got)
+	}
+}
@@ -0,0 +1,99 @@
+package exchange
+
+import (
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/pkg/featureflags"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+// Floor adjustment metric labels: the rule is a floor rule ID or one of
+// these, the action what happened to the floor
+const (
+	// FloorRuleRequest is a floor the request set itself
+	FloorRuleRequest = "request"
+	// FloorRuleBidMultiplier is a floor raised by the publisher's bid_multiplier
+	FloorRuleBidMultiplier = "bid_multiplier"
+
+	FloorActionRaised   = "raised"   // the floor was raised
+	FloorActionRejected = "rejected" // a bid below the floor was rejected
+)
+
+// FloorSource supplies publishers' floor rules; implemented by floors.Engine
+type FloorSource interface {
+	// Floor returns the floor CPM, in the exchange currency, and ID of the
+	// publisher's rule for imp, if one matches
+	Floor(publisherID string, req *openrtb.BidRequest, imp *openrtb.Imp) (floor float64, ruleID string, ok bool)
+}
+
+// SetFloors enables publisher floor rules: before bidders are called, each
+// impression's bidfloor is raised to its matching rule's floor
+func (e *Exchange) SetFloors(source FloorSource) {
+	e.configMu.Lock()
+	defer e.configMu.Unlock()
+	e.floors = source
+}
+
+// applyFloorRules merges the publisher's floor rules into imp.bidfloor
+// wherever the rule's floor is higher, so bidders see it and bids below it
+// are rejected. It returns the rule that set each raised impression's floor.
+// The floor_engine_v2 flag turns rules off for a publisher or a rollout.
+func (e *Exchange) applyFloorRules(req *openrtb.BidRequest) map[string]string {
+	e.configMu.RLock()
+	source := e.floors
+	m := e.metrics
+	e.configMu.RUnlock()
+	publisherID := requestPublisherID(req)
+	if source == nil || publisherID == "" || !e.featureEnabled(featureflags.FloorEngine, req) {
+		return nil
+	}
+
+	converter := e.currencyConverter()
+	exchangeCur := e.exchangeCurrency()
+
+	var ruleFloors map[string]string
+	for i := range req.Imp {
+		imp := &req.Imp[i]
+		floor, ruleID, ok := source.Floor(publisherID, req, imp)
+		if !ok || floor <= 0 {
+			continue
+		}
+
+		current := 0.0
+		if imp.BidFloor > 0 {
+			converted, err := converter.Convert(imp.BidFloor, imp.BidFloorCur, exchangeCur)
+			if err != nil {
+				// Can't tell which floor is higher; keep the publisher's own
+				logger.Log.Debug().
+					Err(err).
+					Str("imp_id", imp.ID).
+					Str("bidfloorcur", imp.BidFloorCur).
+					Str("rule", ruleID).
+					Msg("Cannot convert floor currency, floor rule not applied")
+				continue
+			}
+			current = converted
+		}
+		if floor <= current {
+			continue
+		}
+
+		imp.BidFloor = floor
+		imp.BidFloorCur = exchangeCur
+		if ruleFloors == nil {
+			ruleFloors = make(map[string]string)
+		}
+		ruleFloors[imp.ID] = ruleID
+		if m != nil {
+			m.RecordFloorAdjustment(ruleID, FloorActionRaised)
+		}
+	}
+	return ruleFloors
+}
+
+// floorRuleOf returns the floor adjustment label for the floor of impID
+func floorRuleOf(ruleFloors map[string]string, impID string) string {
+	if ruleID, ok := ruleFloors[impID]; ok {
+		return ruleID
+	}
+	return FloorRuleRequest
+}
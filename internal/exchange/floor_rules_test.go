@@ -0,0 +1,112 @@
+package exchange
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/internal/testfixtures"
+	"github.com/thenexusengine/tne_springwire/pkg/featureflags"
+)
+
+// floorMetrics counts floor adjustments on top of mockMetrics
+type floorMetrics struct {
+	mockMetrics
+	adjustments map[string]int
+}
+
+func (m *floorMetrics) RecordFloorAdjustment(rule, action string) {
+	if m.adjustments == nil {
+		m.adjustments = make(map[string]int)
+	}
+	m.adjustments[rule+"/"+action]++
+}
+
+// mockFloors sets a fixed floor on every pub1 impression
+type mockFloors struct {
+	floor float64
+}
+
+func (m *mockFloors) Floor(publisherID string, req *openrtb.BidRequest, imp *openrtb.Imp) (float64, string, bool) {
+	if publisherID != "pub1" {
+		return 0, "", false
+	}
+	return m.floor, "rule-1", true
+}
+
+func TestApplyFloorRules(t *testing.T) {
+	ex := New(adapters.NewRegistry(), &Config{DefaultCurrency: "USD"})
+	ex.SetFloors(&mockFloors{floor: 2})
+	metrics := &floorMetrics{}
+	ex.SetMetrics(metrics)
+
+	req := testfixtures.Request("req").Site("pub1.example", "pub1").Imp(
+		testfixtures.Banner("low", 300, 250).FloorCur(1, "USD"),
+		testfixtures.Banner("high", 300, 250).FloorCur(3, "USD"),
+		testfixtures.Banner("none", 300, 250),
+		testfixtures.Banner("unknown-cur", 300, 250).FloorCur(1, "XXX"),
+	).Build()
+	ruleFloors := ex.applyFloorRules(req)
+
+	want := map[string]float64{"low": 2, "high": 3, "none": 2, "unknown-cur": 1}
+	for _, imp := range req.Imp {
+		if imp.BidFloor != want[imp.ID] {
+			t.Errorf("imp %s: expected floor %.2f, got %.2f", imp.ID, want[imp.ID], imp.BidFloor)
+		}
+	}
+	if req.Imp[2].BidFloorCur != "USD" {
+		t.Errorf("expected a raised floor in the exchange currency, got %q", req.Imp[2].BidFloorCur)
+	}
+	if len(ruleFloors) != 2 || ruleFloors["low"] != "rule-1" || ruleFloors["none"] != "rule-1" {
+		t.Errorf("expected the raised imps mapped to the rule, got %v", ruleFloors)
+	}
+	if metrics.adjustments["rule-1/"+FloorActionRaised] != 2 {
+		t.Errorf("expected two raises recorded, got %v", metrics.adjustments)
+	}
+
+	if floorRuleOf(ruleFloors, "high") != FloorRuleRequest || floorRuleOf(nil, "low") != FloorRuleRequest {
+		t.Error("expected imps without a rule floor labelled as request floors")
+	}
+
+	other := testfixtures.Request("req").Site("pub2.example", "pub2").Imp(testfixtures.Banner("imp", 300, 250)).Build()
+	if ex.applyFloorRules(other) != nil || other.Imp[0].BidFloor != 0 {
+		t.Error("expected publishers without rules untouched")
+	}
+}
+
+func TestApplyFloorRules_FlaggedOff(t *testing.T) {
+	ex := New(adapters.NewRegistry(), &Config{DefaultCurrency: "USD"})
+	ex.SetFloors(&mockFloors{floor: 2})
+	ex.SetFeatureFlags(staticFlags{featureflags.FloorEngine: {"pub2": true}})
+
+	req := testfixtures.Request("req").Site("pub1.example", "pub1").Imp(testfixtures.Banner("imp", 300, 250)).Build()
+	if ex.applyFloorRules(req) != nil || req.Imp[0].BidFloor != 0 {
+		t.Error("expected floor rules skipped for a publisher without floor_engine_v2")
+	}
+}
+
+func TestRunAuction_RejectsBidsBelowRuleFloor(t *testing.T) {
+	registry := adapters.NewRegistry()
+	registry.Register("bidder1", &mockAdapter{bids: []*adapters.TypedBid{
+		{Bid: &openrtb.Bid{ID: "b1", ImpID: "imp1", Price: 1.5, AdM: "<div>low</div>"}, BidType: adapters.BidTypeBanner},
+	}}, adapters.BidderInfo{Enabled: true})
+	ex := New(registry, &Config{DefaultTimeout: 100 * time.Millisecond, DefaultCurrency: "USD"})
+	ex.SetFloors(&mockFloors{floor: 2})
+	metrics := &floorMetrics{}
+	ex.SetMetrics(metrics)
+
+	req := testfixtures.Request("floor-req").Site("pub1.example", "pub1").Imp(testfixtures.Banner("imp1", 300, 250)).Build()
+	resp, err := ex.RunAuction(context.Background(), &AuctionRequest{BidRequest: req})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(resp.BidResponse.SeatBid) != 0 {
+		t.Errorf("expected the bid below the rule floor rejected, got %+v", resp.BidResponse.SeatBid)
+	}
+	if metrics.adjustments["rule-1/"+FloorActionRejected] != 1 {
+		t.Errorf("expected the rejection counted against the rule, got %v", metrics.adjustments)
+	}
+}
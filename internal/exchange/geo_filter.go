@@ -0,0 +1,174 @@
+package exchange
+
+import (
+	"context"
+	"strings"
+
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+// Geo rejection reasons, used as metric labels
+const (
+	// GeoRejectGlobal is a country on the exchange-wide deny list
+	// (Config.BlockedCountries), e.g. a sanctioned country
+	GeoRejectGlobal = "global_blocked"
+	// GeoRejectPublisherBlocked is a country on the publisher's deny list
+	GeoRejectPublisherBlocked = "publisher_blocked"
+	// GeoRejectPublisherNotAllowed is a country, or a request without one,
+	// outside the publisher's allow list
+	GeoRejectPublisherNotAllowed = "publisher_not_allowed"
+)
+
+// Geo rejection country metric labels for requests without a usable country
+const (
+	countryUnknown = "unknown" // the request has no country
+	countryOther   = "other"   // not an ISO 3166-1 alpha-3 code or a listed one
+)
+
+// isoCountries holds the assigned ISO 3166-1 alpha-3 codes, bounding the
+// country metric label
+var isoCountries = func() map[string]bool {
+	codes := strings.Fields(`
+ABW AFG AGO AIA ALA ALB AND ARE ARG ARM ASM ATA ATF ATG AUS AUT AZE BDI
+BEL BEN BES BFA BGD BGR BHR BHS BIH BLM BLR BLZ BMU BOL BRA BRB BRN BTN
+BVT BWA CAF CAN CCK CHE CHL CHN CIV CMR COD COG COK COL COM CPV CRI CUB
+CUW CXR CYM CYP CZE DEU DJI DMA DNK DOM DZA ECU EGY ERI ESH ESP EST ETH
+FIN FJI FLK FRA FRO FSM GAB GBR GEO GGY GHA GIB GIN GLP GMB GNB GNQ GRC
+GRD GRL GTM GUF GUM GUY HKG HMD HND HRV HTI HUN IDN IMN IND IOT IRL IRN
+IRQ ISL ISR ITA JAM JEY JOR JPN KAZ KEN KGZ KHM KIR KNA KOR KWT LAO LBN
+LBR LBY LCA LIE LKA LSO LTU LUX LVA MAC MAF MAR MCO MDA MDG MDV MEX MHL
+MKD MLI MLT MMR MNE MNG MNP MOZ MRT MSR MTQ MUS MWI MYS MYT NAM NCL NER
+NFK NGA NIC NIU NLD NOR NPL NRU NZL OMN PAK PAN PCN PER PHL PLW PNG POL
+PRI PRK PRT PRY PSE PYF QAT REU ROU RUS RWA SAU SDN SEN SGP SGS SHN SJM
+SLB SLE SLV SMR SOM SPM SRB SSD STP SUR SVK SVN SWE SWZ SXM SYC SYR TCA
+TCD TGO THA TJK TKL TKM TLS TON TTO TUN TUR TUV TWN TZA UGA UKR UMI URY
+USA UZB VAT VCT VEN VGB VIR VNM VUT WLF WSM YEM ZAF ZMB ZWE
+`)
+	set := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		set[code] = true
+	}
+	return set
+}()
+
+// extractCountryLists safely extracts the publisher's allowed and blocked
+// countries (storage.Publisher.AllowedCountries and BlockedCountries)
+func extractCountryLists(v interface{}) (allowed, blocked string) {
+	type countryListsGetter interface {
+		GetAllowedCountries() string
+		GetBlockedCountries() string
+	}
+	if getter, ok := v.(countryListsGetter); ok {
+		return getter.GetAllowedCountries(), getter.GetBlockedCountries()
+	}
+	return "", ""
+}
+
+// normalizeCountry upper-cases a country code and trims surrounding space
+func normalizeCountry(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// countryListContains reports whether a comma-separated country list
+// contains country, ignoring case and whitespace
+func countryListContains(list, country string) bool {
+	for _, code := range strings.Split(list, ",") {
+		if normalizeCountry(code) == country {
+			return true
+		}
+	}
+	return false
+}
+
+// requestCountry returns the request's normalized ISO 3166-1 alpha-3
+// country: device.geo, falling back to user.geo. Returns "" when neither
+// sets one.
+func requestCountry(req *openrtb.BidRequest) string {
+	if req.Device != nil && req.Device.Geo != nil && req.Device.Geo.Country != "" {
+		return normalizeCountry(req.Device.Geo.Country)
+	}
+	if req.User != nil && req.User.Geo != nil {
+		return normalizeCountry(req.User.Geo.Country)
+	}
+	return ""
+}
+
+// geoRejection returns why the request's country may not be auctioned, or
+// "" when it may. The exchange-wide deny list applies to every publisher;
+// requests without a country are only rejected by a publisher allow list.
+func (e *Exchange) geoRejection(ctx context.Context, country string) string {
+	if country != "" {
+		for _, code := range e.config.BlockedCountries {
+			if normalizeCountry(code) == country {
+				return GeoRejectGlobal
+			}
+		}
+	}
+
+	pub := middleware.PublisherFromContext(ctx)
+	if pub == nil {
+		return ""
+	}
+	allowed, blocked := extractCountryLists(pub)
+	if country != "" && countryListContains(blocked, country) {
+		return GeoRejectPublisherBlocked
+	}
+	if strings.TrimSpace(allowed) != "" && (country == "" || !countryListContains(allowed, country)) {
+		return GeoRejectPublisherNotAllowed
+	}
+	return ""
+}
+
+// geoCountryLabel is the metric label for a normalized request country: the
+// country itself when it's an ISO 3166-1 alpha-3 code or on a configured
+// country list, so labels stay bounded however the country was spoofed
+func (e *Exchange) geoCountryLabel(ctx context.Context, country string) string {
+	if country == "" {
+		return countryUnknown
+	}
+	if isoCountries[country] {
+		return country
+	}
+	for _, code := range e.config.BlockedCountries {
+		if normalizeCountry(code) == country {
+			return country
+		}
+	}
+	if pub := middleware.PublisherFromContext(ctx); pub != nil {
+		allowed, blocked := extractCountryLists(pub)
+		if countryListContains(allowed, country) || countryListContains(blocked, country) {
+			return country
+		}
+	}
+	return countryOther
+}
+
+// checkGeo rejects a request from a blocked country before any bidder is
+// called, counting the rejection. Returns the rejection reason or "".
+func (e *Exchange) checkGeo(ctx context.Context, req *openrtb.BidRequest) string {
+	country := requestCountry(req)
+	reason := e.geoRejection(ctx, country)
+	if reason == "" {
+		return ""
+	}
+
+	publisherID := ""
+	if pub := middleware.PublisherFromContext(ctx); pub != nil {
+		publisherID, _ = extractPublisherID(pub)
+	}
+	e.configMu.RLock()
+	m := e.metrics
+	e.configMu.RUnlock()
+	if m != nil {
+		m.RecordGeoRejection(e.geoCountryLabel(ctx, country), reason)
+	}
+	logger.Log.Debug().
+		Str("requestID", req.ID).
+		Str("publisher", publisherID).
+		Str("country", country).
+		Str("reason", reason).
+		Msg("request rejected for country")
+	return reason
+}
@@ -0,0 +1,142 @@
+package exchange
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/internal/testfixtures"
+)
+
+// geoMetrics counts geo rejections on top of mockMetrics
+type geoMetrics struct {
+	mockMetrics
+	rejections map[string]int
+}
+
+func (m *geoMetrics) RecordGeoRejection(country, reason string) {
+	if m.rejections == nil {
+		m.rejections = make(map[string]int)
+	}
+	m.rejections[country+"/"+reason]++
+}
+
+func TestGeoRejection(t *testing.T) {
+	ex := New(adapters.NewRegistry(), &Config{BlockedCountries: []string{"prk"}})
+	withPub := func(allowed, blocked string) context.Context {
+		return middleware.NewContextWithPublisher(context.Background(),
+			testfixtures.Publisher("pub1").AllowedCountries(allowed).BlockedCountries(blocked).Build())
+	}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		country string
+		want    string
+	}{
+		{"no publisher", context.Background(), "USA", ""},
+		{"globally blocked without publisher", context.Background(), "PRK", GeoRejectGlobal},
+		{"globally blocked despite allow list", withPub("PRK", ""), "PRK", GeoRejectGlobal},
+		{"publisher blocked", withPub("", "RUS, BLR"), "BLR", GeoRejectPublisherBlocked},
+		{"allowed", withPub("usa,CAN", ""), "CAN", ""},
+		{"not allowed", withPub("USA,CAN", ""), "DEU", GeoRejectPublisherNotAllowed},
+		{"unknown country with allow list", withPub("USA", ""), "", GeoRejectPublisherNotAllowed},
+		{"unknown country without allow list", withPub("", "RUS"), "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ex.geoRejection(tt.ctx, tt.country); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestGeoCountryLabel(t *testing.T) {
+	ex := New(adapters.NewRegistry(), &Config{BlockedCountries: []string{"xkx"}})
+	ctx := middleware.NewContextWithPublisher(context.Background(),
+		testfixtures.Publisher("pub1").AllowedCountries("USA, XAA").BlockedCountries("XBB").Build())
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		country string
+		want    string
+	}{
+		{"no country", ctx, "", countryUnknown},
+		{"iso code", context.Background(), "DEU", "DEU"},
+		{"globally blocked", context.Background(), "XKX", "XKX"},
+		{"publisher allowed", ctx, "XAA", "XAA"},
+		{"publisher blocked", ctx, "XBB", "XBB"},
+		{"unassigned code", ctx, "QQQ", countryOther},
+		{"not a code", context.Background(), "GERMANY", countryOther},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ex.geoCountryLabel(tt.ctx, tt.country); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRequestCountry(t *testing.T) {
+	req := &openrtb.BidRequest{User: &openrtb.User{Geo: &openrtb.Geo{Country: " deu"}}}
+	if got := requestCountry(req); got != "DEU" {
+		t.Errorf("expected user.geo fallback, got %q", got)
+	}
+	req.Device = &openrtb.Device{Geo: &openrtb.Geo{Country: "fra"}}
+	if got := requestCountry(req); got != "FRA" {
+		t.Errorf("expected device.geo first, got %q", got)
+	}
+	if got := requestCountry(&openrtb.BidRequest{}); got != "" {
+		t.Errorf("expected no country, got %q", got)
+	}
+}
+
+func TestRunAuction_RejectsBlockedCountry(t *testing.T) {
+	registry := adapters.NewRegistry()
+	registry.Register("bidder", &mockAdapter{bids: []*adapters.TypedBid{
+		{Bid: &openrtb.Bid{ID: "b1", ImpID: "imp1", Price: 2, AdM: "<div>ad</div>"}, BidType: adapters.BidTypeBanner},
+	}}, adapters.BidderInfo{Enabled: true})
+	ex := New(registry, &Config{DefaultTimeout: 100 * time.Millisecond})
+	metrics := &geoMetrics{}
+	ex.SetMetrics(metrics)
+
+	ctx := middleware.NewContextWithPublisher(context.Background(), testfixtures.Publisher("pub1").AllowedCountries("USA").Build())
+	auction := func(country string) *AuctionResponse {
+		resp, err := ex.RunAuction(ctx, &AuctionRequest{BidRequest: &openrtb.BidRequest{
+			ID:     "test-geo-" + country,
+			Site:   testSite(),
+			Device: &openrtb.Device{Geo: &openrtb.Geo{Country: country}},
+			Imp:    []openrtb.Imp{{ID: "imp1", Banner: &openrtb.Banner{W: 300, H: 250}}},
+		}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return resp
+	}
+
+	resp := auction("DEU")
+	if resp.BidResponse.NBR != int(openrtb.NoBidGeoBlocked) || len(resp.BidResponse.SeatBid) != 0 {
+		t.Fatalf("expected a geo no-bid, got %+v", resp.BidResponse)
+	}
+	if len(resp.BidderResults) != 0 {
+		t.Errorf("expected no bidders called, got %d results", len(resp.BidderResults))
+	}
+	if metrics.rejections["DEU/"+GeoRejectPublisherNotAllowed] != 1 {
+		t.Errorf("expected the rejection counted, got %v", metrics.rejections)
+	}
+
+	auction("QQQ")
+	if metrics.rejections[countryOther+"/"+GeoRejectPublisherNotAllowed] != 1 {
+		t.Errorf("expected an unlisted country counted as other, got %v", metrics.rejections)
+	}
+
+	if resp := auction("USA"); len(resp.BidResponse.SeatBid) != 1 {
+		t.Errorf("expected an allowed country to be auctioned, got %+v", resp.BidResponse)
+	}
+}
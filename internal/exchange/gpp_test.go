@@ -0,0 +1,54 @@
+package exchange
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/testfixtures"
+)
+
+// gppCaliforniaOptOut is a GPP string whose usca section (8) opts out of sale
+const gppCaliforniaOptOut = "DBABBg~BVYAAACA"
+
+func TestRunAuction_GPPRules(t *testing.T) {
+	registry := adapters.NewRegistry()
+	reader := &requestCapturingAdapter{}
+	legacy := &requestCapturingAdapter{}
+	if err := registry.Register("reader", reader, adapters.BidderInfo{Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Register("legacy", legacy, adapters.BidderInfo{Enabled: true, GPPUnsupported: true}); err != nil {
+		t.Fatal(err)
+	}
+	ex := New(registry, &Config{DefaultTimeout: 100 * time.Millisecond})
+
+	req := testfixtures.Request("auction-1").Site("example.com", "pub-1").Imp(testfixtures.Video("imp-1")).
+		User("user-1", "buyer-1").Consent(testfixtures.NoGDPR().GPP(gppCaliforniaOptOut, 8)).Build()
+	if _, err := ex.RunAuction(context.Background(), &AuctionRequest{BidRequest: req}); err != nil {
+		t.Fatalf("auction failed: %v", err)
+	}
+
+	for name, adapter := range map[string]*requestCapturingAdapter{"reader": reader, "legacy": legacy} {
+		sent := adapter.lastRequest
+		if sent == nil {
+			t.Fatalf("expected %s to be sent the request", name)
+		}
+		if sent.User != nil && (sent.User.ID != "" || sent.User.BuyerUID != "") {
+			t.Errorf("expected %s sent no user identifiers, got %+v", name, sent.User)
+		}
+		if sent.Regs == nil || sent.Regs.GPP != gppCaliforniaOptOut {
+			t.Errorf("expected %s sent the GPP string, got %+v", name, sent.Regs)
+		}
+	}
+	if got := legacy.lastRequest.Regs.USPrivacy; got != "1YYN" {
+		t.Errorf("expected the bidder without GPP support sent us_privacy 1YYN, got %q", got)
+	}
+	if got := reader.lastRequest.Regs.USPrivacy; got != "" {
+		t.Errorf("expected no us_privacy added for a GPP reader, got %q", got)
+	}
+	if req.Regs.USPrivacy != "" || req.User.ID != "user-1" {
+		t.Error("expected the original request left untouched")
+	}
+}
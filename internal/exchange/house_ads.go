@@ -0,0 +1,126 @@
+package exchange
+
+import (
+	"encoding/json"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+// HouseSeatName is the seat house ads are returned under
+const HouseSeatName = "house"
+
+// Auction fill sources, used as metric labels
+const (
+	FillSourcePaid  = "paid"  // at least one bidder's bid won
+	FillSourceHouse = "house" // no valid bid; a house ad was served
+	FillSourceNone  = "none"  // no valid bid and no house ad
+)
+
+// HouseAdSource supplies publishers' house ads; implemented by
+// houseads.Library
+type HouseAdSource interface {
+	// HouseBid returns a bid for imp carrying the publisher's house ad and
+	// its media type (banner or video), if one fits and userKey hasn't
+	// reached its frequency cap
+	HouseBid(publisherID string, imp *openrtb.Imp, userKey string) (bid *openrtb.Bid, mediaType string, ok bool)
+}
+
+// SetHouseAds enables house ads: auctions that end without a valid bid are
+// filled with the publisher's house ads instead of an empty response
+func (e *Exchange) SetHouseAds(source HouseAdSource) {
+	e.configMu.Lock()
+	defer e.configMu.Unlock()
+	e.houseAds = source
+}
+
+// houseAdUserKey identifies the user for house ad frequency caps: user.id,
+// else device.ifa, else device.ip
+func houseAdUserKey(req *openrtb.BidRequest) string {
+	if req.User != nil && req.User.ID != "" {
+		return "user:" + req.User.ID
+	}
+	if req.Device != nil {
+		if req.Device.IFA != "" {
+			return "ifa:" + req.Device.IFA
+		}
+		if req.Device.IP != "" {
+			return "ip:" + req.Device.IP
+		}
+	}
+	return ""
+}
+
+// serveHouseAds fills an auction that ended without a valid bid with the
+// publisher's house ads, one per impression that has one, and counts the
+// auction's fill by source. Unfilled auctions wait for the device graph
+// lookup, whose household caps the house ads; filled ones don't need it.
+func (e *Exchange) serveHouseAds(req *openrtb.BidRequest, response *AuctionResponse, graph deviceGraphLookup) {
+	if response == nil || response.BidResponse == nil {
+		return
+	}
+	publisherID := requestPublisherID(req)
+	mediaType := requestMediaType(req)
+
+	source := FillSourcePaid
+	if !hasBids(response.BidResponse) {
+		source = FillSourceNone
+		response.DeviceGraph = graph.wait()
+		if bids := e.houseBids(req, publisherID, frequencyCapKey(req, response.DeviceGraph)); len(bids) > 0 {
+			resp := response.BidResponse
+			resp.NBR = 0
+			resp.SeatBid = []openrtb.SeatBid{{Seat: HouseSeatName, Bid: bids}}
+			attachPodFill(resp, BuildPodFill(req, resp))
+			source = FillSourceHouse
+		}
+	}
+
+	if e.metrics != nil {
+		e.metrics.RecordAuctionFill(mediaType, source)
+	}
+}
+
+// houseBids returns a house ad bid for every impression the publisher has a
+// fitting house ad for, capped per userKey
+func (e *Exchange) houseBids(req *openrtb.BidRequest, publisherID, userKey string) []openrtb.Bid {
+	e.configMu.RLock()
+	source := e.houseAds
+	e.configMu.RUnlock()
+	if source == nil || publisherID == "" {
+		return nil
+	}
+
+	var bids []openrtb.Bid
+	for i := range req.Imp {
+		imp := &req.Imp[i]
+		bid, mediaType, ok := source.HouseBid(publisherID, imp, userKey)
+		if !ok {
+			continue
+		}
+		bid.ID = HouseSeatName + "-" + req.ID + "-" + imp.ID
+		bid.ImpID = imp.ID
+		bid.Price = 0
+
+		ext := e.buildBidExtension(ValidatedBid{
+			Bid:        &adapters.TypedBid{Bid: bid, BidType: adapters.BidType(mediaType)},
+			BidderCode: HouseSeatName,
+			DemandType: adapters.DemandTypePublisher,
+		})
+		ext.Prebid.Meta.DemandSource = HouseSeatName
+		if extBytes, err := json.Marshal(ext); err == nil {
+			bid.Ext = extBytes
+		}
+		bids = append(bids, *bid)
+	}
+	return bids
+}
+
+// hasBids reports whether the response carries any bid
+func hasBids(resp *openrtb.BidResponse) bool {
+	for _, sb := range resp.SeatBid {
+		if len(sb.Bid) > 0 {
+			return true
+		}
+	}
+	return false
+}
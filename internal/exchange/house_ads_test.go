@@ -0,0 +1,132 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/internal/testfixtures"
+)
+
+// fillMetrics counts auction fills on top of mockMetrics
+type fillMetrics struct {
+	mockMetrics
+	fills map[string]int
+}
+
+func (m *fillMetrics) RecordAuctionFill(mediaType, source string) {
+	if m.fills == nil {
+		m.fills = make(map[string]int)
+	}
+	m.fills[mediaType+"/"+source]++
+}
+
+// mockHouseAds serves one banner house ad per impression for pub1
+type mockHouseAds struct {
+	userKeys []string
+}
+
+func (m *mockHouseAds) HouseBid(publisherID string, imp *openrtb.Imp, userKey string) (*openrtb.Bid, string, bool) {
+	m.userKeys = append(m.userKeys, userKey)
+	if publisherID != "pub1" || imp.Banner == nil {
+		return nil, "", false
+	}
+	return &openrtb.Bid{AdM: "<div>house</div>", CRID: "house-1", ADomain: []string{"pub1.example"}, W: 300, H: 250}, "banner", true
+}
+
+func TestRunAuction_HouseAds(t *testing.T) {
+	ex := New(adapters.NewRegistry(), &Config{DefaultTimeout: 100 * time.Millisecond})
+	houseAds := &mockHouseAds{}
+	ex.SetHouseAds(houseAds)
+	metrics := &fillMetrics{}
+	ex.SetMetrics(metrics)
+
+	req := testfixtures.Request("house-req").Site("pub1.example", "pub1").User("user-1", "").Imp(
+		testfixtures.Banner("imp1", 300, 250),
+		testfixtures.Video("imp2"),
+	).Build()
+	resp, err := ex.RunAuction(context.Background(), &AuctionRequest{BidRequest: req})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	br := resp.BidResponse
+	if br.NBR != 0 || len(br.SeatBid) != 1 || br.SeatBid[0].Seat != HouseSeatName || len(br.SeatBid[0].Bid) != 1 {
+		t.Fatalf("expected one house ad in the house seat, got %+v", br)
+	}
+	bid := br.SeatBid[0].Bid[0]
+	if bid.ImpID != "imp1" || bid.ID != "house-house-req-imp1" || bid.Price != 0 || bid.AdM != "<div>house</div>" {
+		t.Errorf("unexpected house bid: %+v", bid)
+	}
+	var ext openrtb.BidExt
+	if err := json.Unmarshal(bid.Ext, &ext); err != nil {
+		t.Fatalf("invalid bid ext: %v", err)
+	}
+	if ext.Prebid.Meta.DemandSource != HouseSeatName || ext.Prebid.Targeting["hb_bidder"] != HouseSeatName {
+		t.Errorf("expected the bid marked as house demand, got %+v", ext.Prebid)
+	}
+	if houseAds.userKeys[0] != "user:user-1" {
+		t.Errorf("expected caps keyed by user.id, got %q", houseAds.userKeys[0])
+	}
+	if metrics.fills["banner/"+FillSourceHouse] != 1 {
+		t.Errorf("expected a house fill counted, got %v", metrics.fills)
+	}
+
+	// Another publisher without house ads stays empty
+	req = testfixtures.Request("empty-req").Site("pub2.example", "pub2").Imp(testfixtures.Banner("imp1", 300, 250)).Build()
+	resp, _ = ex.RunAuction(context.Background(), &AuctionRequest{BidRequest: req})
+	if len(resp.BidResponse.SeatBid) != 0 || resp.BidResponse.NBR == 0 {
+		t.Errorf("expected an empty response, got %+v", resp.BidResponse)
+	}
+	if metrics.fills["banner/"+FillSourceNone] != 1 {
+		t.Errorf("expected an unfilled auction counted, got %v", metrics.fills)
+	}
+}
+
+func TestRunAuction_HouseAdsNotServedOverPaidBids(t *testing.T) {
+	registry := adapters.NewRegistry()
+	registry.Register("bidder", &mockAdapter{bids: []*adapters.TypedBid{
+		{Bid: &openrtb.Bid{ID: "b1", ImpID: "imp1", Price: 2, AdM: "<div>ad</div>"}, BidType: adapters.BidTypeBanner},
+	}}, adapters.BidderInfo{Enabled: true})
+	ex := New(registry, &Config{DefaultTimeout: 100 * time.Millisecond})
+	ex.SetHouseAds(&mockHouseAds{})
+	metrics := &fillMetrics{}
+	ex.SetMetrics(metrics)
+
+	req := testfixtures.Request("paid-req").Site("pub1.example", "pub1").Imp(testfixtures.Banner("imp1", 300, 250)).Build()
+	resp, err := ex.RunAuction(context.Background(), &AuctionRequest{BidRequest: req})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, sb := range resp.BidResponse.SeatBid {
+		if sb.Seat == HouseSeatName {
+			t.Fatalf("expected no house ad over a paid bid, got %+v", sb)
+		}
+	}
+	if metrics.fills["banner/"+FillSourcePaid] != 1 {
+		t.Errorf("expected a paid fill counted, got %v", metrics.fills)
+	}
+}
+
+func TestHouseAdUserKey(t *testing.T) {
+	tests := []struct {
+		name string
+		req  *openrtb.BidRequest
+		want string
+	}{
+		{"user id", &openrtb.BidRequest{User: &openrtb.User{ID: "u1"}, Device: &openrtb.Device{IFA: "ifa-1"}}, "user:u1"},
+		{"ifa", &openrtb.BidRequest{Device: &openrtb.Device{IFA: "ifa-1", IP: "192.0.2.1"}}, "ifa:ifa-1"},
+		{"ip", &openrtb.BidRequest{User: &openrtb.User{}, Device: &openrtb.Device{IP: "192.0.2.1"}}, "ip:192.0.2.1"},
+		{"unknown", &openrtb.BidRequest{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := houseAdUserKey(tt.req); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package exchange
+
+import (
+	"context"
+
+	"github.com/thenexusengine/tne_springwire/internal/idmodules"
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+// IdentityResolver asks identity providers for the viewer's IDs;
+// implemented by idmodules.Enricher
+type IdentityResolver interface {
+	// Resolve returns the EIDs the providers found, waiting at most for
+	// the slowest provider's timeout
+	Resolve(ctx context.Context, lookup idmodules.Lookup) []openrtb.EID
+}
+
+// SetIdentityResolver enables identity provider lookups, whose IDs are
+// added to user.eids before bidders are called
+func (e *Exchange) SetIdentityResolver(r IdentityResolver) {
+	e.configMu.Lock()
+	defer e.configMu.Unlock()
+	e.identity = r
+}
+
+// identityLookup delivers the EIDs of a lookup running alongside bidder
+// selection; a nil lookup was never started
+type identityLookup chan []openrtb.EID
+
+// wait returns the lookup's EIDs, blocking until every provider has
+// answered or timed out
+func (l identityLookup) wait() []openrtb.EID {
+	if l == nil {
+		return nil
+	}
+	return <-l
+}
+
+// startIdentityLookup starts asking identity providers about the request's
+// viewer when a resolver is set and the privacy gate allows it
+func (e *Exchange) startIdentityLookup(ctx context.Context, req *openrtb.BidRequest) identityLookup {
+	e.configMu.RLock()
+	resolver := e.identity
+	e.configMu.RUnlock()
+	if resolver == nil {
+		return nil
+	}
+	vendorAllowed, ok := identityLookupAllowed(ctx, req)
+	if !ok {
+		return nil
+	}
+
+	lookup := idmodules.NewLookup(requestPublisherID(req), req)
+	lookup.VendorAllowed = vendorAllowed
+	result := make(identityLookup, 1)
+	go func() {
+		result <- resolver.Resolve(ctx, lookup)
+	}()
+	return result
+}
+
+// identityLookupAllowed is the privacy gate for identity lookups, which
+// send the viewer's identifiers to third parties. COPPA traffic, limited ad
+// tracking and users who opted out under US privacy laws are never looked
+// up. Under GDPR the TCF string must consent to storage/access (purpose 1)
+// and basic ad selection (purpose 2), and the returned check holds each
+// provider to its own vendor consent.
+func identityLookupAllowed(ctx context.Context, req *openrtb.BidRequest) (vendorAllowed func(gvlID int) bool, ok bool) {
+	if middleware.IsCOPPA(req) || middleware.IsLMT(req) {
+		return nil, false
+	}
+	if !middleware.ShouldCollectPII(ctx) || middleware.ResolveUSPrivacy(req).OptedOut() ||
+		middleware.CCPAOptOut(ctx) || middleware.ResolveGPP(req).StripIdentifiers() {
+		return nil, false
+	}
+
+	gdpr := middleware.GDPRApplies(ctx) || (req.Regs != nil && req.Regs.GDPR != nil && *req.Regs.GDPR == 1)
+	if !gdpr {
+		return nil, true
+	}
+	consent := middleware.GetConsentString(ctx)
+	if consent == "" && req.User != nil {
+		consent = req.User.Consent
+	}
+	tcf, err := middleware.ParseTCFv2(consent)
+	if err != nil || tcf == nil ||
+		!tcf.HasPurposeConsent(middleware.PurposeStorageAccess) || !tcf.HasPurposeConsent(middleware.PurposeBasicAds) {
+		return nil, false
+	}
+	return tcf.VendorAllowed, true
+}
+
+// mergeEIDs adds identity provider EIDs to the request's user. Sources the
+// publisher already sent are kept as sent. User is copied before
+// modification.
+func mergeEIDs(req *openrtb.BidRequest, eids []openrtb.EID) {
+	if len(eids) == 0 {
+		return
+	}
+	var user openrtb.User
+	if req.User != nil {
+		user = *req.User
+	}
+	present := make(map[string]bool, len(user.EIDs))
+	for _, eid := range user.EIDs {
+		present[eid.Source] = true
+	}
+	merged := append([]openrtb.EID(nil), user.EIDs...)
+	for _, eid := range eids {
+		if !present[eid.Source] {
+			present[eid.Source] = true
+			merged = append(merged, eid)
+		}
+	}
+	user.EIDs = merged
+	req.User = &user
+}
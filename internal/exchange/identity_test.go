@@ -0,0 +1,144 @@
+package exchange
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/idmodules"
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/internal/testfixtures"
+)
+
+// mockIdentityResolver finds a UID2 and a RampID for every viewer
+type mockIdentityResolver struct {
+	mu      sync.Mutex
+	lookups []idmodules.Lookup
+}
+
+func (m *mockIdentityResolver) Resolve(ctx context.Context, lookup idmodules.Lookup) []openrtb.EID {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lookups = append(m.lookups, lookup)
+	return []openrtb.EID{
+		{Source: "uidapi.com", UIDs: []openrtb.UID{{ID: "uid2-token", AType: 3}}},
+		{Source: "liveramp.com", UIDs: []openrtb.UID{{ID: "ramp-1", AType: 3}}},
+	}
+}
+
+func TestRunAuction_IdentityModulesAddEIDs(t *testing.T) {
+	registry := adapters.NewRegistry()
+	adapter := &requestCapturingAdapter{}
+	if err := registry.Register("test", adapter, adapters.BidderInfo{Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+	ex := New(registry, &Config{DefaultTimeout: 100 * time.Millisecond})
+	ex.SetBidderUID2(map[string]bool{"test": true})
+	resolver := &mockIdentityResolver{}
+	ex.SetIdentityResolver(resolver)
+
+	req := testfixtures.Request("id-req").App("com.example.ctv", "pub1").User("user-1", "").
+		Device(rokuUA, "192.0.2.1", 3).Imp(testfixtures.Video("imp1")).Build()
+	publisherRamp := openrtb.EID{Source: "liveramp.com", UIDs: []openrtb.UID{{ID: "ramp-from-publisher", AType: 3}}}
+	req.User.EIDs = []openrtb.EID{publisherRamp}
+	if _, err := ex.RunAuction(context.Background(), &AuctionRequest{BidRequest: req}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(resolver.lookups) != 1 {
+		t.Fatalf("expected one lookup, got %d", len(resolver.lookups))
+	}
+	if lookup := resolver.lookups[0]; lookup.PublisherID != "pub1" || lookup.IP != "192.0.2.1" || lookup.UserID != "user-1" || lookup.VendorAllowed != nil {
+		t.Errorf("unexpected lookup %+v", lookup)
+	}
+
+	sent := adapter.lastRequest
+	if sent == nil || sent.User == nil {
+		t.Fatal("expected the bidder called with a user")
+	}
+	ids := make(map[string]string)
+	for _, eid := range sent.User.EIDs {
+		ids[eid.Source] = eid.UIDs[0].ID
+	}
+	if len(sent.User.EIDs) != 2 || ids["uidapi.com"] != "uid2-token" || ids["liveramp.com"] != "ramp-from-publisher" {
+		t.Errorf("expected the UID2 added and the publisher's RampID kept, got %+v", sent.User.EIDs)
+	}
+
+	// Shadow auctions don't look up
+	shadow := testfixtures.Request("id-shadow").App("com.example.ctv", "pub1").User("user-1", "").
+		Device(rokuUA, "192.0.2.1", 3).Imp(testfixtures.Video("imp1")).Build()
+	if _, err := ex.RunAuction(context.Background(), &AuctionRequest{BidRequest: shadow, Shadow: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resolver.lookups) != 1 {
+		t.Errorf("expected no lookup for a shadow auction, got %d", len(resolver.lookups))
+	}
+}
+
+func TestIdentityLookupAllowed(t *testing.T) {
+	app := func(consent *testfixtures.ConsentBuilder) *openrtb.BidRequest {
+		b := testfixtures.Request("r1").App("com.example.ctv", "pub1").Device(rokuUA, "192.0.2.1", 3)
+		if consent != nil {
+			b = b.Consent(consent)
+		}
+		return b.Build()
+	}
+	lmt := app(nil)
+	one := 1
+	lmt.Device.Lmt = &one
+
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		req        *openrtb.BidRequest
+		want       bool
+		wantVendor bool
+	}{
+		{"no privacy signals", context.Background(), app(nil), true, false},
+		{"limit ad tracking", context.Background(), lmt, false, false},
+		{"coppa", context.Background(), app(testfixtures.NoGDPR().COPPA()), false, false},
+		{"us privacy opt-out", context.Background(), app(testfixtures.NoGDPR().USPrivacy("1YYN")), false, false},
+		{"gpp california opt-out", context.Background(), app(testfixtures.NoGDPR().GPP(gppCaliforniaOptOut, 8)), false, false},
+		{"ccpa opt-out in context", middleware.SetPrivacyContext(context.Background(), false, true, true, ""), app(nil), false, false},
+		{"gdpr with consent", context.Background(), app(testfixtures.GDPR(tcfStorageAndBasicAds)), true, true},
+		{"gdpr without basic ads consent", context.Background(), app(testfixtures.GDPR(tcfStorageOnly)), false, false},
+		{"gdpr without consent string", context.Background(), app(testfixtures.GDPR("")), false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vendorAllowed, ok := identityLookupAllowed(tt.ctx, tt.req)
+			if ok != tt.want {
+				t.Fatalf("expected %v, got %v", tt.want, ok)
+			}
+			if (vendorAllowed != nil) != tt.wantVendor {
+				t.Errorf("expected a vendor check %v, got %v", tt.wantVendor, vendorAllowed != nil)
+			}
+		})
+	}
+}
+
+func TestMergeEIDs(t *testing.T) {
+	user := &openrtb.User{ID: "u1", EIDs: []openrtb.EID{{Source: "id5-sync.com", UIDs: []openrtb.UID{{ID: "id5"}}}}}
+	req := &openrtb.BidRequest{User: user}
+
+	mergeEIDs(req, []openrtb.EID{{Source: "uidapi.com", UIDs: []openrtb.UID{{ID: "uid2"}}}})
+	if len(req.User.EIDs) != 2 || req.User.EIDs[1].Source != "uidapi.com" || req.User.ID != "u1" {
+		t.Errorf("expected the UID2 appended, got %+v", req.User)
+	}
+	if len(user.EIDs) != 1 {
+		t.Error("expected the caller's user left untouched")
+	}
+
+	req = &openrtb.BidRequest{}
+	mergeEIDs(req, nil)
+	if req.User != nil {
+		t.Error("expected no user created without EIDs")
+	}
+	mergeEIDs(req, []openrtb.EID{{Source: "uidapi.com", UIDs: []openrtb.UID{{ID: "uid2"}}}})
+	if req.User == nil || len(req.User.EIDs) != 1 {
+		t.Errorf("expected a user created for the EIDs, got %+v", req.User)
+	}
+}
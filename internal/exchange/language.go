@@ -0,0 +1,174 @@
+package exchange
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+// Ad language filter modes, set per publisher (storage.Publisher.LanguageFilter)
+const (
+	// LanguageFilterMatch rejects bids whose bid.language isn't the
+	// request's language, including unlabeled bids
+	LanguageFilterMatch = "match"
+	// LanguageFilterMatchOrUnlabeled rejects bids labeled with another
+	// language but accepts unlabeled ones
+	LanguageFilterMatchOrUnlabeled = "match_or_unlabeled"
+)
+
+// Language filter outcomes, used as metric labels
+const (
+	LanguageOutcomeAccepted = "accepted"
+	LanguageOutcomeRejected = "rejected"
+)
+
+// Bid language metric labels for bids without a usable language code
+const (
+	languageUnlabeled = "unlabeled"
+	languageOther     = "other"
+)
+
+// extractLanguageFilter safely extracts the publisher's ad language filter
+// mode (storage.Publisher.LanguageFilter)
+func extractLanguageFilter(v interface{}) string {
+	type languageFilterGetter interface {
+		GetLanguageFilter() string
+	}
+	if getter, ok := v.(languageFilterGetter); ok {
+		return getter.GetLanguageFilter()
+	}
+	return ""
+}
+
+// extractRequiredLanguage safely extracts the language every bid must be in
+// (storage.Publisher.RequiredLanguage)
+func extractRequiredLanguage(v interface{}) string {
+	type requiredLanguageGetter interface {
+		GetRequiredLanguage() string
+	}
+	if getter, ok := v.(requiredLanguageGetter); ok {
+		return getter.GetRequiredLanguage()
+	}
+	return ""
+}
+
+// languageFilter returns the publisher's ad language filter mode and the
+// language bids must be in. A publisher's required language replaces the
+// request's and filters with match unless the publisher set a mode. Mode is
+// "" when bids aren't filtered by language; unknown modes don't filter.
+func (e *Exchange) languageFilter(ctx context.Context, req *openrtb.BidRequest) (mode, want string) {
+	pub := middleware.PublisherFromContext(ctx)
+	if pub == nil {
+		return "", requestLanguage(req)
+	}
+	switch mode = extractLanguageFilter(pub); mode {
+	case LanguageFilterMatch, LanguageFilterMatchOrUnlabeled:
+	default:
+		mode = ""
+	}
+	if required := normalizeLanguage(extractRequiredLanguage(pub)); required != "" {
+		if mode == "" {
+			mode = LanguageFilterMatch
+		}
+		return mode, required
+	}
+	return mode, requestLanguage(req)
+}
+
+// normalizeLanguage reduces a language tag to its lowercase ISO 639-1
+// primary subtag ("fr-CA" and "FR" become "fr"), or "" when it has none
+func normalizeLanguage(tag string) string {
+	primary, _, _ := strings.Cut(strings.TrimSpace(tag), "-")
+	if len(primary) != 2 {
+		return ""
+	}
+	primary = strings.ToLower(primary)
+	for i := 0; i < len(primary); i++ {
+		if primary[i] < 'a' || primary[i] > 'z' {
+			return ""
+		}
+	}
+	return primary
+}
+
+// requestLanguage returns the language ads for the request should be in:
+// the site or app content language, falling back to the device language.
+// Returns "" when the request sets neither.
+func requestLanguage(req *openrtb.BidRequest) string {
+	if req == nil {
+		return ""
+	}
+	var content *openrtb.Content
+	if req.Site != nil {
+		content = req.Site.Content
+	} else if req.App != nil {
+		content = req.App.Content
+	}
+	if content != nil {
+		if lang := normalizeLanguage(content.Language); lang != "" {
+			return lang
+		}
+	}
+	if req.Device != nil {
+		return normalizeLanguage(req.Device.Language)
+	}
+	return ""
+}
+
+// bidLanguageLabel is the metric label for a bid's language
+func bidLanguageLabel(lang string) string {
+	if strings.TrimSpace(lang) == "" {
+		return languageUnlabeled
+	}
+	if normalized := normalizeLanguage(lang); normalized != "" {
+		return normalized
+	}
+	return languageOther
+}
+
+// checkBidLanguage rejects a bid whose creative language doesn't match want
+// under mode, and counts every bid by language and outcome. Nothing is
+// rejected when the publisher doesn't filter or the request has no language.
+func (e *Exchange) checkBidLanguage(bid *openrtb.Bid, bidderCode, mode, want string) *BidValidationError {
+	var reason string
+	if mode != "" && want != "" {
+		switch got := normalizeLanguage(bid.Language); {
+		case strings.TrimSpace(bid.Language) == "":
+			if mode == LanguageFilterMatch {
+				reason = fmt.Sprintf("unlabeled bid language, %q required", want)
+			}
+		case got != want:
+			reason = fmt.Sprintf("bid language %q does not match %q", bid.Language, want)
+		}
+	}
+
+	outcome := LanguageOutcomeAccepted
+	if reason != "" {
+		outcome = LanguageOutcomeRejected
+	}
+	if e.metrics != nil {
+		e.metrics.RecordBidLanguage(bidderCode, bidLanguageLabel(bid.Language), outcome)
+	}
+	if reason == "" {
+		return nil
+	}
+
+	logger.Log.Debug().
+		Str("bidder", bidderCode).
+		Str("bidID", bid.ID).
+		Str("impID", bid.ImpID).
+		Str("language", bid.Language).
+		Str("required", want).
+		Msg("bid rejected for language mismatch")
+	return &BidValidationError{
+		BidID:      bid.ID,
+		ImpID:      bid.ImpID,
+		BidderCode: bidderCode,
+		Reason:     reason,
+		LossReason: LossLanguageExclusions,
+	}
+}
@@ -0,0 +1,180 @@
+package exchange
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/internal/testfixtures"
+)
+
+// languageMetrics counts bids by language on top of mockMetrics
+type languageMetrics struct {
+	mockMetrics
+	bids map[string]int
+}
+
+func (m *languageMetrics) RecordBidLanguage(bidder, language, outcome string) {
+	if m.bids == nil {
+		m.bids = make(map[string]int)
+	}
+	m.bids[bidder+"/"+language+"/"+outcome]++
+}
+
+func TestRequestLanguage(t *testing.T) {
+	tests := []struct {
+		name string
+		req  *openrtb.BidRequest
+		want string
+	}{
+		{"none", &openrtb.BidRequest{Site: &openrtb.Site{}}, ""},
+		{"site content", &openrtb.BidRequest{Site: &openrtb.Site{Content: &openrtb.Content{Language: "fr-CA"}}}, "fr"},
+		{"app content", &openrtb.BidRequest{App: &openrtb.App{Content: &openrtb.Content{Language: "FR"}}}, "fr"},
+		{"device fallback", &openrtb.BidRequest{Site: &openrtb.Site{}, Device: &openrtb.Device{Language: "en"}}, "en"},
+		{
+			"content over device",
+			&openrtb.BidRequest{Site: &openrtb.Site{Content: &openrtb.Content{Language: "fr"}}, Device: &openrtb.Device{Language: "en"}},
+			"fr",
+		},
+		{
+			"malformed content falls back",
+			&openrtb.BidRequest{Site: &openrtb.Site{Content: &openrtb.Content{Language: "french"}}, Device: &openrtb.Device{Language: "fr"}},
+			"fr",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := requestLanguage(tt.req); got != tt.want {
+				t.Errorf("requestLanguage = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckBidLanguage(t *testing.T) {
+	tests := []struct {
+		name      string
+		mode      string
+		want      string
+		language  string
+		rejected  bool
+		metricKey string
+	}{
+		{"no filter", "", "fr", "en", false, "rubicon/en/accepted"},
+		{"no request language", LanguageFilterMatch, "", "en", false, "rubicon/en/accepted"},
+		{"match", LanguageFilterMatch, "fr", "fr", false, "rubicon/fr/accepted"},
+		{"match with region", LanguageFilterMatch, "fr", "fr-CA", false, "rubicon/fr/accepted"},
+		{"mismatch", LanguageFilterMatch, "fr", "en", true, "rubicon/en/rejected"},
+		{"unlabeled under match", LanguageFilterMatch, "fr", "", true, "rubicon/unlabeled/rejected"},
+		{"unlabeled allowed", LanguageFilterMatchOrUnlabeled, "fr", "", false, "rubicon/unlabeled/accepted"},
+		{"mismatch with unlabeled allowed", LanguageFilterMatchOrUnlabeled, "fr", "en", true, "rubicon/en/rejected"},
+		{"malformed label", LanguageFilterMatchOrUnlabeled, "fr", "francais", true, "rubicon/other/rejected"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics := &languageMetrics{}
+			ex := &Exchange{metrics: metrics}
+			bid := &openrtb.Bid{ID: "b1", ImpID: "imp1", Language: tt.language}
+			err := ex.checkBidLanguage(bid, "rubicon", tt.mode, tt.want)
+			if (err != nil) != tt.rejected {
+				t.Errorf("rejected = %v, want %v (err %v)", err != nil, tt.rejected, err)
+			}
+			if metrics.bids[tt.metricKey] != 1 {
+				t.Errorf("expected %s counted once, got %v", tt.metricKey, metrics.bids)
+			}
+		})
+	}
+}
+
+func TestLanguageFilterMode(t *testing.T) {
+	withPub := func(pub *testfixtures.PublisherBuilder) context.Context {
+		return middleware.NewContextWithPublisher(context.Background(), pub.Build())
+	}
+	req := &openrtb.BidRequest{Site: &openrtb.Site{Content: &openrtb.Content{Language: "en-US"}}}
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		wantMode string
+		wantLang string
+	}{
+		{"no publisher", context.Background(), "", "en"},
+		{"publisher mode", withPub(testfixtures.Publisher("pub1").LanguageFilter(LanguageFilterMatchOrUnlabeled)), LanguageFilterMatchOrUnlabeled, "en"},
+		{"unknown mode", withPub(testfixtures.Publisher("pub1").LanguageFilter("loose")), "", "en"},
+		{"required language", withPub(testfixtures.Publisher("pub1").RequiredLanguage("fr")), LanguageFilterMatch, "fr"},
+		{"required language with mode", withPub(testfixtures.Publisher("pub1").RequiredLanguage("FR-ca").LanguageFilter(LanguageFilterMatchOrUnlabeled)), LanguageFilterMatchOrUnlabeled, "fr"},
+		{"invalid required language", withPub(testfixtures.Publisher("pub1").RequiredLanguage("french")), "", "en"},
+	}
+	ex := &Exchange{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mode, lang := ex.languageFilter(tt.ctx, req)
+			if mode != tt.wantMode || lang != tt.wantLang {
+				t.Errorf("expected %q requiring %q, got %q requiring %q", tt.wantMode, tt.wantLang, mode, lang)
+			}
+		})
+	}
+}
+
+func TestRunAuction_FiltersBidLanguage(t *testing.T) {
+	registry := adapters.NewRegistry()
+	registry.Register("english", &mockAdapter{bids: []*adapters.TypedBid{
+		{Bid: &openrtb.Bid{ID: "en1", ImpID: "imp1", Price: 9, AdM: "<div>ad</div>", Language: "en"}, BidType: adapters.BidTypeBanner},
+	}}, adapters.BidderInfo{Enabled: true})
+	registry.Register("french", &mockAdapter{bids: []*adapters.TypedBid{
+		{Bid: &openrtb.Bid{ID: "fr1", ImpID: "imp1", Price: 4, AdM: "<div>pub</div>", Language: "fr"}, BidType: adapters.BidTypeBanner},
+	}}, adapters.BidderInfo{Enabled: true})
+	ex := New(registry, &Config{DefaultTimeout: 100 * time.Millisecond})
+	metrics := &languageMetrics{}
+	ex.SetMetrics(metrics)
+
+	site := testSite()
+	site.Content = &openrtb.Content{Language: "fr"}
+	ctx := middleware.NewContextWithPublisher(context.Background(), testfixtures.Publisher("pub1").LanguageFilter(LanguageFilterMatch).Build())
+	resp, err := ex.RunAuction(ctx, &AuctionRequest{BidRequest: &openrtb.BidRequest{
+		ID:   "test-language",
+		Site: site,
+		Imp:  []openrtb.Imp{{ID: "imp1", Banner: &openrtb.Banner{W: 300, H: 250}}},
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.BidResponse.SeatBid) != 1 || resp.BidResponse.SeatBid[0].Bid[0].ID != "fr1" {
+		t.Fatalf("expected only the French bid to win, got %+v", resp.BidResponse.SeatBid)
+	}
+	want := map[string]int{"english/en/rejected": 1, "french/fr/accepted": 1}
+	if !reflect.DeepEqual(metrics.bids, want) {
+		t.Errorf("expected language metrics %v, got %v", want, metrics.bids)
+	}
+}
+
+func TestRunAuction_RequiredLanguage(t *testing.T) {
+	registry := adapters.NewRegistry()
+	registry.Register("english", &mockAdapter{bids: []*adapters.TypedBid{
+		{Bid: &openrtb.Bid{ID: "en1", ImpID: "imp1", Price: 9, AdM: "<div>ad</div>", Language: "en"}, BidType: adapters.BidTypeBanner},
+	}}, adapters.BidderInfo{Enabled: true})
+	registry.Register("french", &mockAdapter{bids: []*adapters.TypedBid{
+		{Bid: &openrtb.Bid{ID: "fr1", ImpID: "imp1", Price: 4, AdM: "<div>pub</div>", Language: "fr"}, BidType: adapters.BidTypeBanner},
+	}}, adapters.BidderInfo{Enabled: true})
+	ex := New(registry, &Config{DefaultTimeout: 100 * time.Millisecond})
+
+	// The request is English, but the publisher must show French ads
+	site := testSite()
+	site.Content = &openrtb.Content{Language: "en"}
+	ctx := middleware.NewContextWithPublisher(context.Background(), testfixtures.Publisher("pub1").RequiredLanguage("fr").Build())
+	resp, err := ex.RunAuction(ctx, &AuctionRequest{BidRequest: &openrtb.BidRequest{
+		ID:   "test-required-language",
+		Site: site,
+		Imp:  []openrtb.Imp{{ID: "imp1", Banner: &openrtb.Banner{W: 300, H: 250}}},
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.BidResponse.SeatBid) != 1 || resp.BidResponse.SeatBid[0].Bid[0].ID != "fr1" {
+		t.Fatalf("expected only the French bid to win, got %+v", resp.BidResponse.SeatBid)
+	}
+}
@@ -0,0 +1,181 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/internal/winqueue"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+// Loss reason codes (OpenRTB 2.5 section 5.25) sent to bidders in the
+// ${AUCTION_LOSS} macro of their lurl
+const (
+	LossInternalError               = 1
+	LossInvalidBidResponse          = 3
+	LossBelowFloor                  = 100
+	LossLostToHigherBid             = 102
+	LossLostToDeal                  = 103
+	LossCreativeDisapproved         = 202
+	LossCreativeSizeNotAllowed      = 203
+	LossAdvertiserExclusions        = 205
+	LossCreativeNotSecure           = 207
+	LossLanguageExclusions          = 208
+	LossCreativeAttributeExclusions = 210
+)
+
+// lossNoticeTimeout bounds queueing one auction's loss notices
+const lossNoticeTimeout = 5 * time.Second
+
+// LossNotifier queues bidder loss notices for asynchronous firing;
+// implemented by *winqueue.Queue
+type LossNotifier interface {
+	Enqueue(ctx context.Context, event winqueue.Event) error
+}
+
+// SetLossNotifier sets the queue that fires the lurl of bids that lost
+func (e *Exchange) SetLossNotifier(n LossNotifier) {
+	e.configMu.Lock()
+	defer e.configMu.Unlock()
+	e.lossNotifier = n
+}
+
+// rejectedBids maps each bid that failed validation to its error. Each
+// bidder's bids are validated in order, so its rejections line up with its
+// rejected bids.
+func rejectedBids(results map[string]*BidderResult, validBids []ValidatedBid, validationErrors []error) map[*adapters.TypedBid]*BidValidationError {
+	accepted := make(map[*adapters.TypedBid]bool, len(validBids))
+	for _, vb := range validBids {
+		accepted[vb.Bid] = true
+	}
+	rejections := make(map[string][]*BidValidationError)
+	for _, err := range validationErrors {
+		var bve *BidValidationError
+		if errors.As(err, &bve) {
+			rejections[bve.BidderCode] = append(rejections[bve.BidderCode], bve)
+		}
+	}
+
+	rejected := make(map[*adapters.TypedBid]*BidValidationError)
+	for code, result := range results {
+		for _, tb := range result.Bids {
+			if tb == nil || tb.Bid == nil || accepted[tb] {
+				continue
+			}
+			var bve *BidValidationError
+			if rs := rejections[code]; len(rs) > 0 {
+				bve = rs[0]
+				rejections[code] = rs[1:]
+			}
+			rejected[tb] = bve
+		}
+	}
+	return rejected
+}
+
+// notifyLosses queues a loss notice for every bid with an lurl that wasn't
+// returned: the rejection's reason for bids that failed validation, else
+// the bid that beat it. Notices carry the price the impression's winner
+// cleared at, for ${AUCTION_MIN_TO_WIN}. They are queued off the request
+// path.
+func (e *Exchange) notifyLosses(req *openrtb.BidRequest, results map[string]*BidderResult, rejected map[*adapters.TypedBid]*BidValidationError, auctionedBids map[string][]ValidatedBid, resp *openrtb.BidResponse) {
+	e.configMu.RLock()
+	n := e.lossNotifier
+	e.configMu.RUnlock()
+	if n == nil {
+		return
+	}
+
+	returned := make(map[string]bool)
+	if resp != nil {
+		for _, sb := range resp.SeatBid {
+			for _, bid := range sb.Bid {
+				returned[bid.ID] = true
+			}
+		}
+	}
+	// Auctioned bids are in auction order, so the first returned bid of an
+	// impression won it
+	winners := make(map[string]ValidatedBid)
+	for impID, bids := range auctionedBids {
+		for _, vb := range bids {
+			if returned[vb.Bid.Bid.ID] {
+				winners[impID] = vb
+				break
+			}
+		}
+	}
+
+	publisherID := requestPublisherID(req)
+	cur := e.exchangeCurrency()
+	var events []winqueue.Event
+	for code, result := range results {
+		for _, tb := range result.Bids {
+			if tb == nil || tb.Bid == nil || tb.Bid.LURL == "" || returned[tb.Bid.ID] {
+				continue
+			}
+			event := winqueue.Event{
+				Type:        winqueue.EventLoss,
+				BidID:       tb.Bid.ID,
+				Bidder:      code,
+				AuctionID:   req.ID,
+				ImpID:       tb.Bid.ImpID,
+				PublisherID: publisherID,
+				DealID:      tb.Bid.DealID,
+				CreativeID:  tb.Bid.CRID,
+				Currency:    cur,
+				URL:         tb.Bid.LURL,
+			}
+			winner, won := winners[tb.Bid.ImpID]
+			if won {
+				event.Price = winner.Bid.Bid.Price
+				if winner.GrossPrice > 0 {
+					event.Price = winner.GrossPrice
+				}
+			}
+			if bve, ok := rejected[tb]; ok {
+				event.LossReason = LossInvalidBidResponse
+				if bve != nil && bve.LossReason != 0 {
+					event.LossReason = bve.LossReason
+				}
+			} else {
+				event.LossReason = lossToWinner(tb.Bid, winner, won)
+			}
+			events = append(events, event)
+		}
+	}
+	if len(events) == 0 {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), lossNoticeTimeout)
+		defer cancel()
+		for _, event := range events {
+			if err := n.Enqueue(ctx, event); err != nil {
+				logger.Log.Warn().
+					Err(err).
+					Str("bid_id", event.BidID).
+					Str("bidder", event.Bidder).
+					Msg("Failed to queue loss notice")
+			}
+		}
+	}()
+}
+
+// lossToWinner is why a valid bid lost its impression: to a deal, to a
+// higher bid, or to nothing when the impression went unfilled after the
+// auction (pod assembly dropping it)
+func lossToWinner(bid *openrtb.Bid, winner ValidatedBid, won bool) int {
+	switch {
+	case !won:
+		return LossInternalError
+	case winner.Bid.Bid.DealID != "" && bid.DealID == "":
+		return LossLostToDeal
+	default:
+		return LossLostToHigherBid
+	}
+}
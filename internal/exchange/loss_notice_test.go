@@ -0,0 +1,100 @@
+package exchange
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/internal/testfixtures"
+	"github.com/thenexusengine/tne_springwire/internal/winqueue"
+)
+
+type mockLossNotifier struct {
+	mu     sync.Mutex
+	events map[string]winqueue.Event
+	done   chan struct{}
+	want   int
+}
+
+func (m *mockLossNotifier) Enqueue(_ context.Context, event winqueue.Event) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events[event.BidID] = event
+	if len(m.events) == m.want {
+		close(m.done)
+	}
+	return nil
+}
+
+func TestNotifyLosses(t *testing.T) {
+	ex := New(adapters.NewRegistry(), &Config{DefaultTimeout: 100 * time.Millisecond})
+	n := &mockLossNotifier{events: make(map[string]winqueue.Event), done: make(chan struct{}), want: 4}
+	ex.SetLossNotifier(n)
+
+	winner := &adapters.TypedBid{Bid: &openrtb.Bid{ID: "win", ImpID: "imp-1", Price: 3, DealID: "deal-1", LURL: "https://a.example/loss"}}
+	outbid := &adapters.TypedBid{Bid: &openrtb.Bid{ID: "outbid", ImpID: "imp-1", Price: 2, LURL: "https://b.example/loss"}}
+	dealOutbid := &adapters.TypedBid{Bid: &openrtb.Bid{ID: "deal-outbid", ImpID: "imp-1", Price: 1, DealID: "deal-2", LURL: "https://b.example/loss"}}
+	floored := &adapters.TypedBid{Bid: &openrtb.Bid{ID: "floored", ImpID: "imp-1", Price: 0.1, LURL: "https://c.example/loss"}}
+	malformed := &adapters.TypedBid{Bid: &openrtb.Bid{ID: "malformed", ImpID: "imp-9", LURL: "https://c.example/loss"}}
+	noLURL := &adapters.TypedBid{Bid: &openrtb.Bid{ID: "no-lurl", ImpID: "imp-1", Price: 1.5}}
+
+	results := map[string]*BidderResult{
+		"appnexus": {BidderCode: "appnexus", Bids: []*adapters.TypedBid{winner}},
+		"rubicon":  {BidderCode: "rubicon", Bids: []*adapters.TypedBid{outbid, dealOutbid, noLURL}},
+		"pubmatic": {BidderCode: "pubmatic", Bids: []*adapters.TypedBid{floored, malformed}},
+	}
+	validBids := []ValidatedBid{
+		{Bid: winner, BidderCode: "appnexus", GrossPrice: 3.5},
+		{Bid: outbid, BidderCode: "rubicon"},
+		{Bid: dealOutbid, BidderCode: "rubicon"},
+		{Bid: noLURL, BidderCode: "rubicon"},
+	}
+	validationErrors := []error{
+		&BidValidationError{BidID: "floored", BidderCode: "pubmatic", LossReason: LossBelowFloor},
+		&BidValidationError{BidID: "malformed", BidderCode: "pubmatic"},
+	}
+	rejected := rejectedBids(results, validBids, validationErrors)
+	if len(rejected) != 2 || rejected[floored].BidID != "floored" || rejected[malformed].BidID != "malformed" {
+		t.Fatalf("expected both rejected bids matched to their errors, got %v", rejected)
+	}
+
+	auctioned := map[string][]ValidatedBid{"imp-1": validBids}
+	resp := &openrtb.BidResponse{SeatBid: []openrtb.SeatBid{{Seat: "appnexus", Bid: []openrtb.Bid{*winner.Bid}}}}
+	req := testfixtures.Request("auction-1").Site("example.com", "pub-1").Imp(testfixtures.Video("imp-1")).Build()
+	ex.notifyLosses(req, results, rejected, auctioned, resp)
+
+	select {
+	case <-n.done:
+	case <-time.After(time.Second):
+		t.Fatalf("expected 4 loss notices, got %v", n.events)
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for bidID, want := range map[string]int{
+		"outbid":      LossLostToDeal,
+		"deal-outbid": LossLostToHigherBid,
+		"floored":     LossBelowFloor,
+		"malformed":   LossInvalidBidResponse,
+	} {
+		if got := n.events[bidID].LossReason; got != want {
+			t.Errorf("expected %s to lose with reason %d, got %d", bidID, want, got)
+		}
+	}
+	if _, ok := n.events["win"]; ok {
+		t.Error("expected no loss notice for the winning bid")
+	}
+	if _, ok := n.events["no-lurl"]; ok {
+		t.Error("expected no loss notice for a bid without an lurl")
+	}
+	if e := n.events["outbid"]; e.Type != winqueue.EventLoss || e.Bidder != "rubicon" || e.AuctionID != "auction-1" ||
+		e.PublisherID != "pub-1" || e.Price != 3.5 || e.URL != "https://b.example/loss" {
+		t.Errorf("unexpected loss notice: %+v", e)
+	}
+	if e := n.events["malformed"]; e.Price != 0 {
+		t.Errorf("expected no clearing price for an unfilled impression, got %v", e.Price)
+	}
+}
@@ -0,0 +1,75 @@
+package exchange
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+// extractMaxBidCPM returns the publisher's bid price cap, or 0 when it has none
+func extractMaxBidCPM(v interface{}) float64 {
+	type maxBidCPMGetter interface {
+		GetMaxBidCPM() float64
+	}
+	if getter, ok := v.(maxBidCPMGetter); ok {
+		return getter.GetMaxBidCPM()
+	}
+	return 0
+}
+
+// maxBidCPM returns the highest bid price accepted for the request: the
+// exchange-wide cap, lowered by the publisher's own cap when it sets one.
+// A publisher cap never raises the exchange-wide cap.
+func (e *Exchange) maxBidCPM(ctx context.Context) float64 {
+	limit := maxReasonableCPM
+	if e.config != nil && e.config.MaxBidCPM > 0 && e.config.MaxBidCPM < limit {
+		limit = e.config.MaxBidCPM
+	}
+	if pub := middleware.PublisherFromContext(ctx); pub != nil {
+		if pubLimit := extractMaxBidCPM(pub); pubLimit > 0 && pubLimit < limit {
+			limit = pubLimit
+		}
+	}
+	return limit
+}
+
+// checkMaxBidCPM rejects a bid priced above the cap. Rejections are logged
+// with the full bid context for reconciliation and counted per bidder so a
+// partner repeatedly sending anomalous prices raises an alert.
+func (e *Exchange) checkMaxBidCPM(ctx context.Context, bid *openrtb.Bid, bidderCode, auctionID string, limit float64) *BidValidationError {
+	if bid.Price <= limit {
+		return nil
+	}
+
+	var publisherID string
+	if pub := middleware.PublisherFromContext(ctx); pub != nil {
+		publisherID, _ = extractPublisherID(pub)
+	}
+	logger.Log.Warn().
+		Str("bidder", bidderCode).
+		Str("publisher_id", publisherID).
+		Str("auction_id", auctionID).
+		Str("request_id", logger.RequestIDFromContext(ctx)).
+		Str("bid_id", bid.ID).
+		Str("imp_id", bid.ImpID).
+		Str("crid", bid.CRID).
+		Str("deal_id", bid.DealID).
+		Strs("adomain", bid.ADomain).
+		Float64("price", bid.Price).
+		Float64("max_cpm", limit).
+		Msg("Rejected bid above max CPM")
+
+	if e.metrics != nil {
+		e.metrics.RecordBidPriceCapExceeded(bidderCode)
+	}
+
+	return &BidValidationError{
+		BidID:      bid.ID,
+		ImpID:      bid.ImpID,
+		BidderCode: bidderCode,
+		Reason:     fmt.Sprintf("price %.4f exceeds max CPM %.4f", bid.Price, limit),
+	}
+}
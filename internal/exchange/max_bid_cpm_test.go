@@ -0,0 +1,91 @@
+package exchange
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/internal/testfixtures"
+)
+
+// priceCapMetrics counts capped bids on top of mockMetrics
+type priceCapMetrics struct {
+	mockMetrics
+	capped map[string]int
+}
+
+func (m *priceCapMetrics) RecordBidPriceCapExceeded(bidder string) {
+	if m.capped == nil {
+		m.capped = make(map[string]int)
+	}
+	m.capped[bidder]++
+}
+
+func TestMaxBidCPM(t *testing.T) {
+	withPub := func(maxCPM float64) context.Context {
+		return middleware.NewContextWithPublisher(context.Background(), testfixtures.Publisher("pub1").MaxBidCPM(maxCPM).Build())
+	}
+
+	tests := []struct {
+		name   string
+		global float64
+		ctx    context.Context
+		want   float64
+	}{
+		{"default", 0, context.Background(), maxReasonableCPM},
+		{"global cap", 50, context.Background(), 50},
+		{"global above hard ceiling", 5000, context.Background(), maxReasonableCPM},
+		{"publisher lowers cap", 50, withPub(20), 20},
+		{"publisher can't raise cap", 50, withPub(80), 50},
+		{"publisher without cap", 50, withPub(0), 50},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ex := &Exchange{config: &Config{MaxBidCPM: tt.global}}
+			if got := ex.maxBidCPM(tt.ctx); got != tt.want {
+				t.Errorf("maxBidCPM = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunAuction_RejectsBidsAboveMaxCPM(t *testing.T) {
+	registry := adapters.NewRegistry()
+	registry.Register("buggy", &mockAdapter{bids: []*adapters.TypedBid{
+		{Bid: &openrtb.Bid{ID: "b1", ImpID: "imp1", Price: 120, AdM: "<div/>"}, BidType: adapters.BidTypeBanner},
+	}}, adapters.BidderInfo{Enabled: true})
+	registry.Register("sane", &mockAdapter{bids: []*adapters.TypedBid{
+		{Bid: &openrtb.Bid{ID: "b2", ImpID: "imp1", Price: 4, AdM: "<div/>"}, BidType: adapters.BidTypeBanner},
+	}}, adapters.BidderInfo{Enabled: true})
+
+	ex := New(registry, &Config{DefaultTimeout: 100 * time.Millisecond, MaxBidCPM: 100})
+	metrics := &priceCapMetrics{}
+	ex.SetMetrics(metrics)
+
+	resp, err := ex.RunAuction(context.Background(), &AuctionRequest{
+		BidRequest: &openrtb.BidRequest{
+			ID:   "test-max-cpm",
+			Site: testSite(),
+			Imp:  []openrtb.Imp{{ID: "imp1", Banner: &openrtb.Banner{W: 300, H: 250}}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var winners []string
+	for _, sb := range resp.BidResponse.SeatBid {
+		for _, bid := range sb.Bid {
+			winners = append(winners, bid.ID)
+		}
+	}
+	if len(winners) != 1 || winners[0] != "b2" {
+		t.Errorf("expected only the sane bid to win, got %v", winners)
+	}
+	if metrics.capped["buggy"] != 1 || metrics.capped["sane"] != 0 {
+		t.Errorf("expected one capped bid from buggy, got %v", metrics.capped)
+	}
+}
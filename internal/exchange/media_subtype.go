@@ -0,0 +1,69 @@
+package exchange
+
+import "github.com/thenexusengine/tne_springwire/internal/openrtb"
+
+// Media subtypes split the media type in auction and bid metrics: outstream
+// video and CTV pods share the "video" media type but perform nothing alike
+const (
+	MediaSubtypeInstream  = "instream"
+	MediaSubtypeOutstream = "outstream"
+	MediaSubtypePod       = "pod"   // a slot in an ad pod
+	MediaSubtypePause     = "pause" // a non-linear ad shown while CTV playback is paused
+	MediaSubtypeNone      = "none"  // banner, native and audio
+)
+
+// OpenRTB video placement values
+const (
+	plcmtInstream         = 1 // video.plcmt
+	plcmtNoContent        = 4
+	placementInStream     = 1 // video.placement (deprecated)
+	placementInterstitial = 5
+	linearityNonLinear    = 2
+)
+
+// OpenRTB device types of connected TVs
+const (
+	deviceTypeCTV       = 3
+	deviceTypeSetTopBox = 7
+)
+
+// requestMediaSubtype classifies the request's first impression, as the
+// media type is: pod slots first (including requests with several video
+// impressions, which are auctioned as a pod), then CTV pause ads, then the
+// placement
+func requestMediaSubtype(req *openrtb.BidRequest) string {
+	if req == nil || len(req.Imp) == 0 {
+		return MediaSubtypeNone
+	}
+	imp := req.Imp[0]
+	if imp.Banner != nil || imp.Video == nil {
+		return MediaSubtypeNone
+	}
+	videoImps := 0
+	for i := range req.Imp {
+		if req.Imp[i].Video != nil {
+			videoImps++
+		}
+	}
+	if videoImps >= minPodSlots {
+		return MediaSubtypePod
+	}
+	return videoSubtype(imp.Video, req.Device)
+}
+
+// videoSubtype classifies a video impression
+func videoSubtype(v *openrtb.Video, device *openrtb.Device) string {
+	switch {
+	case v.PodID != "" || v.PodDur > 0 || v.MaxSeq > 0:
+		return MediaSubtypePod
+	case v.Linearity == linearityNonLinear && device != nil &&
+		(device.DeviceType == deviceTypeCTV || device.DeviceType == deviceTypeSetTopBox):
+		return MediaSubtypePause
+	case v.Plcmt > plcmtInstream && v.Plcmt <= plcmtNoContent:
+		return MediaSubtypeOutstream
+	case v.Plcmt == 0 && v.Placement > placementInStream && v.Placement <= placementInterstitial:
+		return MediaSubtypeOutstream
+	default:
+		return MediaSubtypeInstream
+	}
+}
@@ -0,0 +1,87 @@
+package exchange
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/internal/testfixtures"
+)
+
+func TestRequestMediaSubtype(t *testing.T) {
+	video := func(mutate func(v *openrtb.Video)) *openrtb.BidRequest {
+		req := testfixtures.Request("r1").Site("example.com", "pub-1").Imp(testfixtures.Video("imp-1")).Build()
+		mutate(req.Imp[0].Video)
+		return req
+	}
+	ctvPause := video(func(v *openrtb.Video) { v.Linearity = linearityNonLinear })
+	ctvPause.Device = &openrtb.Device{DeviceType: deviceTypeCTV}
+
+	tests := []struct {
+		name string
+		req  *openrtb.BidRequest
+		want string
+	}{
+		{"banner", testfixtures.Request("r1").Imp(testfixtures.Banner("imp-1", 300, 250)).Build(), MediaSubtypeNone},
+		{"no impressions", &openrtb.BidRequest{}, MediaSubtypeNone},
+		{"instream", video(func(v *openrtb.Video) { v.Placement = 1 }), MediaSubtypeInstream},
+		{"no placement", video(func(v *openrtb.Video) {}), MediaSubtypeInstream},
+		{"in-article placement", video(func(v *openrtb.Video) { v.Placement = 3 }), MediaSubtypeOutstream},
+		{"accompanying content plcmt", video(func(v *openrtb.Video) { v.Plcmt = 2 }), MediaSubtypeOutstream},
+		{"plcmt overrides placement", video(func(v *openrtb.Video) { v.Placement = 3; v.Plcmt = 1 }), MediaSubtypeInstream},
+		{"pod slot", video(func(v *openrtb.Video) { v.PodID = "pod-1" }), MediaSubtypePod},
+		{"dynamic pod", testfixtures.Request("r1").Imp(testfixtures.Video("imp-1").DynamicPod(120, 4)).Build(), MediaSubtypePod},
+		{"structured pod", testfixtures.Request("r1").Imp(testfixtures.Pod("slot", 3)...).Build(), MediaSubtypePod},
+		{"non-linear on CTV", ctvPause, MediaSubtypePause},
+		{"non-linear on desktop", video(func(v *openrtb.Video) { v.Linearity = linearityNonLinear }), MediaSubtypeInstream},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := requestMediaSubtype(tt.req); got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+// subtypeMetrics records the media subtypes auctions and bids are labelled with
+type subtypeMetrics struct {
+	mockMetrics
+	auction string
+	bids    []string
+}
+
+func (m *subtypeMetrics) RecordAuction(status, mediaType, mediaSubtype string, duration time.Duration, biddersSelected, biddersExcluded int) {
+	m.auction = mediaType + "/" + mediaSubtype
+}
+
+func (m *subtypeMetrics) RecordBid(bidder, mediaType, mediaSubtype string, cpm float64) {
+	m.bids = append(m.bids, mediaType+"/"+mediaSubtype)
+}
+
+func TestRunAuction_MediaSubtypeLabels(t *testing.T) {
+	registry := adapters.NewRegistry()
+	bidder := &mockAdapter{bids: []*adapters.TypedBid{
+		{Bid: &openrtb.Bid{ID: "b1", ImpID: "imp-1", Price: 2.00, AdM: "<VAST/>"}, BidType: adapters.BidTypeVideo},
+	}}
+	if err := registry.Register("bidder", bidder, adapters.BidderInfo{Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+	ex := New(registry, &Config{DefaultTimeout: 100 * time.Millisecond})
+	metrics := &subtypeMetrics{}
+	ex.SetMetrics(metrics)
+
+	req := testfixtures.Request("auction-1").Site("example.com", "pub-1").Imp(testfixtures.Video("imp-1")).Build()
+	req.Imp[0].Video.Plcmt = 2
+	if _, err := ex.RunAuction(context.Background(), &AuctionRequest{BidRequest: req}); err != nil {
+		t.Fatalf("auction failed: %v", err)
+	}
+	if metrics.auction != "video/outstream" {
+		t.Errorf("expected the auction labelled video/outstream, got %q", metrics.auction)
+	}
+	if len(metrics.bids) != 1 || metrics.bids[0] != "video/outstream" {
+		t.Errorf("expected the bid labelled video/outstream, got %v", metrics.bids)
+	}
+}
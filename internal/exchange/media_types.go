@@ -0,0 +1,81 @@
+package exchange
+
+import (
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+// SetBidderMediaTypes replaces the per-bidder media types declared in the
+// database (bidders.supports_*), which take precedence over the adapter's
+// declared capabilities
+func (e *Exchange) SetBidderMediaTypes(mediaTypes map[string][]adapters.BidType) {
+	e.configMu.Lock()
+	defer e.configMu.Unlock()
+	e.bidderMediaTypes = mediaTypes
+}
+
+// supportedMediaTypes returns the media types a bidder takes on the
+// request's platform, or nil when it declares none (all are sent). Bidders
+// that declare only the other platform are held to that declaration.
+func (e *Exchange) supportedMediaTypes(bidderCode string, info adapters.BidderInfo, isApp bool) []adapters.BidType {
+	e.configMu.RLock()
+	declared, ok := e.bidderMediaTypes[bidderCode]
+	e.configMu.RUnlock()
+	if ok {
+		return declared
+	}
+
+	caps := info.Capabilities
+	if caps == nil {
+		return nil
+	}
+	platform := caps.Site
+	if isApp {
+		platform = caps.App
+	}
+	if platform == nil {
+		platform = caps.Site
+		if platform == nil {
+			platform = caps.App
+		}
+	}
+	if platform == nil {
+		return nil
+	}
+	return platform.MediaTypes
+}
+
+// filterImpsByMediaType removes the media objects of a bidder's request
+// clone that the bidder doesn't support, dropping impressions left without
+// any. It reports false if no impressions remain. supported == nil keeps
+// everything.
+func filterImpsByMediaType(req *openrtb.BidRequest, supported []adapters.BidType) bool {
+	if len(supported) == 0 {
+		return len(req.Imp) > 0
+	}
+	allowed := make(map[adapters.BidType]bool, len(supported))
+	for _, mt := range supported {
+		allowed[mt] = true
+	}
+
+	imps := req.Imp[:0]
+	for _, imp := range req.Imp {
+		if !allowed[adapters.BidTypeBanner] {
+			imp.Banner = nil
+		}
+		if !allowed[adapters.BidTypeVideo] {
+			imp.Video = nil
+		}
+		if !allowed[adapters.BidTypeAudio] {
+			imp.Audio = nil
+		}
+		if !allowed[adapters.BidTypeNative] {
+			imp.Native = nil
+		}
+		if imp.Banner != nil || imp.Video != nil || imp.Audio != nil || imp.Native != nil {
+			imps = append(imps, imp)
+		}
+	}
+	req.Imp = imps
+	return len(imps) > 0
+}
@@ -0,0 +1,105 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+func TestFilterImpsByMediaType(t *testing.T) {
+	req := &openrtb.BidRequest{Imp: []openrtb.Imp{
+		{ID: "banner-audio", Banner: &openrtb.Banner{W: 300, H: 250}, Audio: &openrtb.Audio{Mimes: []string{"audio/mpeg"}}},
+		{ID: "video", Video: &openrtb.Video{W: 640, H: 480}},
+		{ID: "audio", Audio: &openrtb.Audio{Mimes: []string{"audio/mpeg"}}},
+	}}
+
+	if !filterImpsByMediaType(req, []adapters.BidType{adapters.BidTypeAudio}) {
+		t.Fatal("expected audio impressions to remain")
+	}
+	if len(req.Imp) != 2 || req.Imp[0].ID != "banner-audio" || req.Imp[1].ID != "audio" {
+		t.Fatalf("expected the video impression dropped, got %+v", req.Imp)
+	}
+	if req.Imp[0].Banner != nil || req.Imp[0].Audio == nil {
+		t.Errorf("expected only the audio object kept, got %+v", req.Imp[0])
+	}
+
+	if filterImpsByMediaType(req, []adapters.BidType{adapters.BidTypeNative}) {
+		t.Error("expected no impressions for a native-only bidder")
+	}
+
+	unfiltered := &openrtb.BidRequest{Imp: []openrtb.Imp{{ID: "1", Audio: &openrtb.Audio{}}}}
+	if !filterImpsByMediaType(unfiltered, nil) || unfiltered.Imp[0].Audio == nil {
+		t.Error("expected bidders without declared media types to get every impression")
+	}
+}
+
+func TestSupportedMediaTypes(t *testing.T) {
+	e := New(adapters.NewRegistry(), nil)
+	info := adapters.BidderInfo{Capabilities: &adapters.CapabilitiesInfo{
+		Site: &adapters.PlatformInfo{MediaTypes: []adapters.BidType{adapters.BidTypeBanner}},
+		App:  &adapters.PlatformInfo{MediaTypes: []adapters.BidType{adapters.BidTypeAudio}},
+	}}
+
+	if got := e.supportedMediaTypes("b", info, false); len(got) != 1 || got[0] != adapters.BidTypeBanner {
+		t.Errorf("expected site media types, got %v", got)
+	}
+	if got := e.supportedMediaTypes("b", info, true); len(got) != 1 || got[0] != adapters.BidTypeAudio {
+		t.Errorf("expected app media types, got %v", got)
+	}
+
+	siteOnly := adapters.BidderInfo{Capabilities: &adapters.CapabilitiesInfo{
+		Site: &adapters.PlatformInfo{MediaTypes: []adapters.BidType{adapters.BidTypeVideo}},
+	}}
+	if got := e.supportedMediaTypes("b", siteOnly, true); len(got) != 1 || got[0] != adapters.BidTypeVideo {
+		t.Errorf("expected the declared platform's media types, got %v", got)
+	}
+	if got := e.supportedMediaTypes("b", adapters.BidderInfo{}, false); got != nil {
+		t.Errorf("expected nil without capabilities, got %v", got)
+	}
+
+	e.SetBidderMediaTypes(map[string][]adapters.BidType{"b": {adapters.BidTypeAudio, adapters.BidTypeVideo}})
+	if got := e.supportedMediaTypes("b", info, false); len(got) != 2 {
+		t.Errorf("expected database media types to take precedence, got %v", got)
+	}
+}
+
+func TestValidateBidMediaType_Audio(t *testing.T) {
+	imp := &openrtb.Imp{ID: "1", Audio: &openrtb.Audio{Protocols: []int{9}}}
+	if err := validateBidMediaType(&openrtb.Bid{ImpID: "1", Protocol: 9}, imp); err != nil {
+		t.Errorf("expected a DAAST bid on an audio impression to be valid, got %v", err)
+	}
+	if err := validateBidMediaType(&openrtb.Bid{ImpID: "1", W: 640, H: 480, Protocol: 2}, imp); err == nil {
+		t.Error("expected a sized video bid on an audio impression to be rejected")
+	}
+}
+
+func TestBuildVASTFromAuction_Audio(t *testing.T) {
+	req := &openrtb.BidRequest{
+		ID:  "audio-req",
+		Imp: []openrtb.Imp{{ID: "1", Audio: &openrtb.Audio{Mimes: []string{"audio/aac"}, MaxDuration: 15, MaxBitrate: 64}}},
+	}
+	auctionResp := &AuctionResponse{
+		BidResponse: &openrtb.BidResponse{
+			ID: "audio-req",
+			SeatBid: []openrtb.SeatBid{{Seat: "bidder1", Bid: []openrtb.Bid{{
+				ID: "bid-1", ImpID: "1", Price: 1.0, AdM: "https://cdn.example.com/spot.aac",
+			}}}},
+		},
+	}
+
+	v, err := NewVASTResponseBuilder("https://track.example.com").BuildVASTFromAuction(req, auctionResp)
+	if err != nil {
+		t.Fatalf("BuildVASTFromAuction failed: %v", err)
+	}
+	if !v.IsAudio() {
+		t.Fatalf("expected an audio ad, got %+v", v.GetMediaFiles())
+	}
+	linear := v.GetLinearCreative()
+	if linear.Duration != "00:00:15" || linear.MediaFiles.MediaFile[0].Type != "audio/aac" || linear.MediaFiles.MediaFile[0].Bitrate != 64 {
+		t.Errorf("unexpected linear creative: %+v", linear)
+	}
+	if len(linear.TrackingEvents.Tracking) == 0 {
+		t.Error("expected quartile tracking on audio ads")
+	}
+}
@@ -0,0 +1,72 @@
+package exchange
+
+import (
+	"encoding/json"
+	"net/url"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/pkg/vast"
+)
+
+// APIOMID is the OpenRTB API framework value for Open Measurement (OMID-1).
+// Requests signal OM support with it in imp.video.api and with the OM SDK
+// partner name and version in source.ext.omidpn/omidpv.
+const APIOMID = 7
+
+// BidVerification is an Open Measurement verification resource a buyer
+// returns in bid.ext.omid.verifications
+type BidVerification struct {
+	Vendor         string `json:"vendor"`
+	JS             string `json:"js"`               // Verification script URL
+	Params         string `json:"params,omitempty"` // Passed to the script as VerificationParameters
+	NotExecutedURL string `json:"not_executed_url,omitempty"`
+}
+
+// bidVerifications returns the bid's verification resources as VAST
+// Verification elements. Resources without a vendor or an https script URL
+// are dropped, since players won't load them.
+func bidVerifications(bid *openrtb.Bid) []vast.Verification {
+	if len(bid.Ext) == 0 {
+		return nil
+	}
+	var ext struct {
+		OMID struct {
+			Verifications []BidVerification `json:"verifications"`
+		} `json:"omid"`
+	}
+	if err := json.Unmarshal(bid.Ext, &ext); err != nil {
+		return nil
+	}
+
+	var verifications []vast.Verification
+	for _, v := range ext.OMID.Verifications {
+		if v.Vendor == "" || !isHTTPS(v.JS) {
+			continue
+		}
+		verification := vast.Verification{
+			Vendor: v.Vendor,
+			JavaScriptResource: []vast.JavaScriptResource{{
+				APIFramework:    vast.APIFrameworkOMID,
+				BrowserOptional: true,
+				Value:           v.JS,
+			}},
+		}
+		if v.Params != "" {
+			verification.VerificationParameters = &vast.VerificationParameters{Value: v.Params}
+		}
+		if isHTTPS(v.NotExecutedURL) {
+			verification.TrackingEvents = &vast.TrackingEvents{Tracking: []vast.Tracking{{
+				Event: vast.EventVerificationNotExecuted,
+				Value: v.NotExecutedURL,
+			}}}
+		}
+		verifications = append(verifications, verification)
+	}
+	return verifications
+}
+
+// isHTTPS reports whether raw is an absolute https URL
+func isHTTPS(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && u.Scheme == "https" && u.Host != ""
+}
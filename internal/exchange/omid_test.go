@@ -0,0 +1,61 @@
+package exchange
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/pkg/vast"
+)
+
+func TestBidVerifications(t *testing.T) {
+	bid := &openrtb.Bid{Ext: json.RawMessage(`{"omid":{"verifications":[
+		{"vendor":"vendor-a","js":"https://a.example.com/omid.js","params":"p=1","not_executed_url":"https://a.example.com/ne"},
+		{"vendor":"vendor-b","js":"http://b.example.com/omid.js"},
+		{"js":"https://c.example.com/omid.js"}
+	]}}`)}
+
+	got := bidVerifications(bid)
+	if len(got) != 1 {
+		t.Fatalf("expected only the https resource with a vendor, got %d", len(got))
+	}
+	v := got[0]
+	if v.Vendor != "vendor-a" || v.JavaScriptResource[0].Value != "https://a.example.com/omid.js" {
+		t.Errorf("unexpected verification: %+v", v)
+	}
+	if v.VerificationParameters == nil || v.VerificationParameters.Value != "p=1" {
+		t.Errorf("expected params to be carried, got %+v", v.VerificationParameters)
+	}
+	if v.TrackingEvents == nil || v.TrackingEvents.Tracking[0].Event != vast.EventVerificationNotExecuted {
+		t.Errorf("expected verificationNotExecuted tracking, got %+v", v.TrackingEvents)
+	}
+
+	if got := bidVerifications(&openrtb.Bid{Ext: json.RawMessage(`{"omid":"bad"}`)}); got != nil {
+		t.Errorf("expected no verifications for malformed ext, got %+v", got)
+	}
+}
+
+func TestBuildVASTFromAuction_AdVerifications(t *testing.T) {
+	req := &openrtb.BidRequest{
+		ID:  "omid-req",
+		Imp: []openrtb.Imp{{ID: "1", Video: &openrtb.Video{MaxDuration: 30, API: []int{APIOMID}}}},
+	}
+	auctionResp := &AuctionResponse{
+		BidResponse: &openrtb.BidResponse{
+			ID: "omid-req",
+			SeatBid: []openrtb.SeatBid{{Seat: "bidder1", Bid: []openrtb.Bid{{
+				ID: "bid-1", ImpID: "1", Price: 1.0, AdM: "https://cdn.example.com/a.mp4",
+				Ext: json.RawMessage(`{"omid":{"verifications":[{"vendor":"vendor-a","js":"https://a.example.com/omid.js"}]}}`),
+			}}}},
+		},
+	}
+
+	v, err := NewVASTResponseBuilder("https://track.example.com").BuildVASTFromAuction(req, auctionResp)
+	if err != nil {
+		t.Fatalf("BuildVASTFromAuction failed: %v", err)
+	}
+	av := v.Ads[0].InLine.AdVerifications
+	if av == nil || len(av.Verification) != 1 || av.Verification[0].Vendor != "vendor-a" {
+		t.Errorf("expected the buyer's verification in the ad, got %+v", av)
+	}
+}
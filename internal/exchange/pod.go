@@ -0,0 +1,374 @@
+package exchange
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+// podExtKey is the BidResponse.ext key carrying pod fill information
+const podExtKey = "pod"
+
+// minPodSlots is the number of video impressions that makes a request a pod
+const minPodSlots = 2
+
+// maxPodSlots caps the impressions a single pod fans out into
+const maxPodSlots = 10
+
+// defaultPodSlotDuration sizes pods whose template sets no maxduration
+const defaultPodSlotDuration = 30
+
+// Slot positions in a pod (OpenRTB 2.6 video.slotinpod)
+const (
+	SlotInPodAny   = 0
+	SlotInPodFirst = 1
+	SlotInPodLast  = -1
+)
+
+// PodSlot describes one slot of an ad pod after the auction
+type PodSlot struct {
+	ImpID    string `json:"impid"`
+	Sequence int    `json:"sequence"`
+	Filled   bool   `json:"filled"`
+	BidID    string `json:"bidid,omitempty"`
+	Seat     string `json:"seat,omitempty"`
+	Duration int    `json:"duration,omitempty"` // Winning creative's seconds
+}
+
+// PodFill reports how much of a requested ad pod was filled. Unfilled slots
+// are kept (Filled=false) so SSAI callers can decide between slate and collapse.
+type PodFill struct {
+	Requested    int       `json:"requested"`
+	Filled       int       `json:"filled"`
+	FillRate     float64   `json:"fill_rate"`
+	Duration     int       `json:"duration,omitempty"`      // Filled seconds
+	TotalPrice   float64   `json:"total_price,omitempty"`   // Sum of the filled slots' prices, in the response currency
+	AvgCPM       float64   `json:"avg_cpm,omitempty"`       // TotalPrice per filled slot
+	Separated    int       `json:"separated,omitempty"`     // Bids passed over for an advertiser already in the pod
+	BidderCapped int       `json:"bidder_capped,omitempty"` // Bids passed over for a bidder at its share of the pod
+	Rejected     string    `json:"rejected,omitempty"`      // Why the whole pod went unfilled
+	Slots        []PodSlot `json:"slots"`
+}
+
+// PodRejectedBelowMinCPM rejects a pod whose filled slots total less than
+// the publisher's pod floor
+const PodRejectedBelowMinCPM = "below_min_cpm"
+
+// PodRules are a publisher's limits on a whole pod, on top of each slot's
+// own floor. Zero values don't limit.
+type PodRules struct {
+	MinCPM         float64 // Least the filled slots must total, in the response currency
+	MaxBidderShare float64 // Share of the pod's slots one bidder may fill
+}
+
+// active reports whether the rules limit anything
+func (r PodRules) active() bool {
+	return r.MinCPM > 0 || r.MaxBidderShare > 0
+}
+
+// maxSlotsPerBidder returns how many of a pod's slots one bidder may fill,
+// always at least one, or 0 when uncapped
+func (r PodRules) maxSlotsPerBidder(slots int) int {
+	if r.MaxBidderShare <= 0 || r.MaxBidderShare >= 1 {
+		return 0
+	}
+	return max(1, int(math.Floor(r.MaxBidderShare*float64(slots)+1e-9)))
+}
+
+// Partial returns true when some, but not all, slots were filled
+func (p *PodFill) Partial() bool {
+	return p.Filled > 0 && p.Filled < p.Requested
+}
+
+// Unfilled returns the slots that received no bid
+func (p *PodFill) Unfilled() []PodSlot {
+	var unfilled []PodSlot
+	for _, s := range p.Slots {
+		if !s.Filled {
+			unfilled = append(unfilled, s)
+		}
+	}
+	return unfilled
+}
+
+// ExpandPod fans a pod template impression out into one impression per slot,
+// each with its sequence and position. The slot count is the number of
+// slotDurations (each a slot's maxduration) when given, otherwise video.maxseq,
+// otherwise as many maxduration slots as fit in video.poddur. No slot is
+// longer than the pod.
+func ExpandPod(template openrtb.Imp, slotDurations []int) []openrtb.Imp {
+	v := template.Video
+	if v == nil {
+		return []openrtb.Imp{template}
+	}
+
+	n := len(slotDurations)
+	if n == 0 {
+		n = v.MaxSeq
+	}
+	if n == 0 {
+		slotDur := v.MaxDuration
+		if slotDur <= 0 {
+			slotDur = defaultPodSlotDuration
+		}
+		n = v.PodDur / slotDur
+	}
+	if v.MaxSeq > 0 && n > v.MaxSeq {
+		n = v.MaxSeq
+	}
+	n = max(1, min(n, maxPodSlots))
+
+	podID := v.PodID
+	if podID == "" {
+		podID = template.ID
+	}
+
+	imps := make([]openrtb.Imp, n)
+	for i := range imps {
+		video := *v
+		video.Sequence = i + 1
+		video.PodID = podID
+		video.SlotInPod = SlotInPodAny
+		if n > 1 {
+			switch i {
+			case 0:
+				video.SlotInPod = SlotInPodFirst
+			case n - 1:
+				video.SlotInPod = SlotInPodLast
+			}
+		}
+		if i < len(slotDurations) && slotDurations[i] > 0 {
+			video.MaxDuration = slotDurations[i]
+		}
+		if video.PodDur > 0 && (video.MaxDuration <= 0 || video.MaxDuration > video.PodDur) {
+			video.MaxDuration = video.PodDur
+		}
+		if video.MinDuration > video.MaxDuration {
+			video.MinDuration = video.MaxDuration
+		}
+
+		imps[i] = template
+		imps[i].ID = fmt.Sprintf("%s-%d", template.ID, i+1)
+		imps[i].Video = &video
+	}
+	return imps
+}
+
+// ExpandPods replaces each video impression describing a whole pod (poddur or
+// maxseq set, no sequence) with one impression per slot
+func ExpandPods(req *openrtb.BidRequest) {
+	if req == nil {
+		return
+	}
+	imps := make([]openrtb.Imp, 0, len(req.Imp))
+	for _, imp := range req.Imp {
+		if v := imp.Video; v != nil && v.Sequence == 0 && (v.PodDur > 0 || v.MaxSeq > 0) {
+			imps = append(imps, ExpandPod(imp, nil)...)
+			continue
+		}
+		imps = append(imps, imp)
+	}
+	req.Imp = imps
+}
+
+// podCandidate is a bid competing for a pod slot
+type podCandidate struct {
+	slot   int
+	bid    *openrtb.Bid
+	seat   string
+	bidder string
+	dur    int
+	price  float64
+}
+
+// BuildPodFill computes pod fill for a request with multiple video impressions.
+// Bids are placed highest price first into their slot, skipping bids whose
+// advertiser domain already won a slot (competitive separation) and bids
+// that would overrun the pod's poddur. Returns nil for non-pod requests.
+func BuildPodFill(req *openrtb.BidRequest, resp *openrtb.BidResponse) *PodFill {
+	return buildPodFill(req, resp, PodRules{}, nil)
+}
+
+// buildPodFill is BuildPodFill under a publisher's pod rules: bids from a
+// bidder already at its share of the pod are passed over, and a pod whose
+// filled slots total less than the pod floor is left unfilled. bidderOf
+// names the bidder behind a seat's bid; nil uses the seat.
+func buildPodFill(req *openrtb.BidRequest, resp *openrtb.BidResponse, rules PodRules, bidderOf func(seat string, bid *openrtb.Bid) string) *PodFill {
+	if req == nil {
+		return nil
+	}
+
+	slots := make([]PodSlot, 0, len(req.Imp))
+	slotImps := make([]*openrtb.Imp, 0, len(req.Imp))
+	slotIndex := make(map[string]int, len(req.Imp))
+	podDur := 0
+	for i := range req.Imp {
+		imp := &req.Imp[i]
+		if imp.Video == nil {
+			continue
+		}
+		seq := imp.Video.Sequence
+		if seq <= 0 {
+			seq = len(slots) + 1
+		}
+		if podDur == 0 {
+			podDur = imp.Video.PodDur
+		}
+		slotIndex[imp.ID] = len(slots)
+		slots = append(slots, PodSlot{ImpID: imp.ID, Sequence: seq})
+		slotImps = append(slotImps, imp)
+	}
+	if len(slots) < minPodSlots {
+		return nil
+	}
+
+	var candidates []podCandidate
+	if resp != nil {
+		for _, sb := range resp.SeatBid {
+			for j := range sb.Bid {
+				bid := &sb.Bid[j]
+				i, ok := slotIndex[bid.ImpID]
+				if !ok {
+					continue
+				}
+				dur := bid.Dur
+				if dur <= 0 {
+					dur = slotImps[i].Video.MaxDuration
+				}
+				bidder := sb.Seat
+				if bidderOf != nil {
+					bidder = bidderOf(sb.Seat, bid)
+				}
+				candidates = append(candidates, podCandidate{slot: i, bid: bid, seat: sb.Seat, bidder: bidder, dur: dur, price: bid.Price})
+			}
+		}
+	}
+	// Stable so the first bid received wins a tie, as before separation
+	sort.SliceStable(candidates, func(a, b int) bool { return candidates[a].price > candidates[b].price })
+
+	fill := &PodFill{Requested: len(slots), Slots: slots}
+	advertisers := make(map[string]bool)
+	bidderCap := rules.maxSlotsPerBidder(len(slots))
+	bidderSlots := make(map[string]int)
+	for _, c := range candidates {
+		slot := &slots[c.slot]
+		if slot.Filled {
+			continue
+		}
+		if sharesAdvertiser(c.bid.ADomain, advertisers) {
+			fill.Separated++
+			continue
+		}
+		if bidderCap > 0 && bidderSlots[c.bidder] >= bidderCap {
+			fill.BidderCapped++
+			continue
+		}
+		if podDur > 0 && fill.Duration+c.dur > podDur {
+			continue
+		}
+		slot.Filled = true
+		slot.BidID = c.bid.ID
+		slot.Seat = c.seat
+		slot.Duration = c.dur
+		fill.Filled++
+		fill.Duration += c.dur
+		fill.TotalPrice += c.price
+		bidderSlots[c.bidder]++
+		for _, domain := range c.bid.ADomain {
+			if d := normalizeAdvertiserDomain(domain); d != "" {
+				advertisers[d] = true
+			}
+		}
+	}
+
+	if rules.MinCPM > 0 && fill.Filled > 0 && fill.TotalPrice < rules.MinCPM {
+		for i := range slots {
+			slots[i] = PodSlot{ImpID: slots[i].ImpID, Sequence: slots[i].Sequence}
+		}
+		fill.Filled = 0
+		fill.Duration = 0
+		fill.TotalPrice = 0
+		fill.Rejected = PodRejectedBelowMinCPM
+	}
+
+	fill.FillRate = math.Round(float64(fill.Filled)/float64(fill.Requested)*100) / 100
+	if fill.Filled > 0 {
+		fill.TotalPrice = math.Round(fill.TotalPrice*1e6) / 1e6
+		fill.AvgCPM = math.Round(fill.TotalPrice/float64(fill.Filled)*1e6) / 1e6
+	}
+	return fill
+}
+
+// removeUnplacedPodBids drops the bids on a pod's slots that didn't win
+// them, leaving the response holding exactly the assembled pod
+func removeUnplacedPodBids(resp *openrtb.BidResponse, fill *PodFill) {
+	if resp == nil || fill == nil {
+		return
+	}
+	podImps := make(map[string]bool, len(fill.Slots))
+	placed := make(map[string]bool, fill.Filled)
+	for _, slot := range fill.Slots {
+		podImps[slot.ImpID] = true
+		if slot.Filled {
+			placed[slot.ImpID+"/"+slot.BidID] = true
+		}
+	}
+
+	seatBids := resp.SeatBid[:0]
+	for _, sb := range resp.SeatBid {
+		bids := sb.Bid[:0]
+		for _, bid := range sb.Bid {
+			if !podImps[bid.ImpID] || placed[bid.ImpID+"/"+bid.ID] {
+				bids = append(bids, bid)
+			}
+		}
+		if len(bids) == 0 {
+			continue
+		}
+		sb.Bid = bids
+		seatBids = append(seatBids, sb)
+	}
+	resp.SeatBid = seatBids
+}
+
+// normalizeAdvertiserDomain lower-cases an adomain entry and drops "www."
+func normalizeAdvertiserDomain(domain string) string {
+	return strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), "www.")
+}
+
+// sharesAdvertiser reports whether any of a bid's advertiser domains already
+// won a slot. Bids without adomain never conflict.
+func sharesAdvertiser(domains []string, won map[string]bool) bool {
+	for _, domain := range domains {
+		if d := normalizeAdvertiserDomain(domain); d != "" && won[d] {
+			return true
+		}
+	}
+	return false
+}
+
+// attachPodFill adds pod fill information to the response ext, preserving other keys
+func attachPodFill(resp *openrtb.BidResponse, fill *PodFill) {
+	if resp == nil || fill == nil {
+		return
+	}
+
+	ext := make(map[string]json.RawMessage)
+	if len(resp.Ext) > 0 {
+		if err := json.Unmarshal(resp.Ext, &ext); err != nil {
+			return
+		}
+	}
+	podJSON, err := json.Marshal(fill)
+	if err != nil {
+		return
+	}
+	ext[podExtKey] = podJSON
+	if extJSON, err := json.Marshal(ext); err == nil {
+		resp.Ext = extJSON
+	}
+}
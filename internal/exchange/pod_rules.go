@@ -0,0 +1,77 @@
+package exchange
+
+import (
+	"context"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+// Pod rule metric labels
+const (
+	PodRuleMinCPM      = "min_cpm"      // a pod was left unfilled below the pod floor
+	PodRuleBidderShare = "bidder_share" // a bid was passed over for its bidder's share of the pod
+)
+
+// extractPodRules safely extracts the publisher's pod rules
+// (storage.Publisher.PodMinCPM and PodMaxBidderShare)
+func extractPodRules(v interface{}) PodRules {
+	var rules PodRules
+	if getter, ok := v.(interface{ GetPodMinCPM() float64 }); ok && getter.GetPodMinCPM() > 0 {
+		rules.MinCPM = getter.GetPodMinCPM()
+	}
+	if getter, ok := v.(interface{ GetPodMaxBidderShare() float64 }); ok && getter.GetPodMaxBidderShare() > 0 {
+		rules.MaxBidderShare = getter.GetPodMaxBidderShare()
+	}
+	return rules
+}
+
+// assemblePod fills the request's ad pod from the response under the
+// publisher's pod rules. Response prices are in the response currency, so
+// the pod floor, set in the exchange currency, is converted with
+// responseRate; the response seat hides platform bidders, so the bidder
+// share is counted per real bidder. Under rules, bids that didn't make the
+// pod are removed from the response, so the VAST builder and fill headers,
+// which reassemble the pod from the response, serve the same pod. Returns
+// nil for non-pod requests.
+func (e *Exchange) assemblePod(ctx context.Context, req *openrtb.BidRequest, response *AuctionResponse, responseRate float64) *PodFill {
+	var rules PodRules
+	if pub := middleware.PublisherFromContext(ctx); pub != nil {
+		rules = extractPodRules(pub)
+		rules.MinCPM *= responseRate
+	}
+
+	fill := buildPodFill(req, response.BidResponse, rules, func(seat string, bid *openrtb.Bid) string {
+		if seat == adapters.PlatformSeatName {
+			if code := winningBidder(response.BidderResults, bid); code != "" {
+				return code
+			}
+		}
+		return seat
+	})
+	if fill == nil || !rules.active() {
+		return fill
+	}
+	removeUnplacedPodBids(response.BidResponse, fill)
+
+	e.configMu.RLock()
+	m := e.metrics
+	e.configMu.RUnlock()
+	publisherID := requestPublisherID(req)
+	if fill.Rejected != "" {
+		logger.Log.Debug().
+			Str("auction_id", req.ID).
+			Str("publisher_id", publisherID).
+			Float64("pod_min_cpm", rules.MinCPM).
+			Msg("Ad pod below the publisher's pod floor left unfilled")
+		if m != nil {
+			m.RecordPodRuleEnforcement(PodRuleMinCPM, 1)
+		}
+	}
+	if fill.BidderCapped > 0 && m != nil {
+		m.RecordPodRuleEnforcement(PodRuleBidderShare, fill.BidderCapped)
+	}
+	return fill
+}
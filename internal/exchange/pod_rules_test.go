@@ -0,0 +1,168 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/analytics"
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/internal/testfixtures"
+)
+
+// podRuleMetrics counts pod rule enforcements on top of mockMetrics
+type podRuleMetrics struct {
+	mockMetrics
+	enforcements map[string]int
+}
+
+func (m *podRuleMetrics) RecordPodRuleEnforcement(rule string, count int) {
+	if m.enforcements == nil {
+		m.enforcements = make(map[string]int)
+	}
+	m.enforcements[rule] += count
+}
+
+func TestBuildPodFill_PodRules(t *testing.T) {
+	req := podRequest(4)
+	resp := &openrtb.BidResponse{
+		SeatBid: []openrtb.SeatBid{
+			{Seat: "bidder1", Bid: []openrtb.Bid{
+				{ID: "a1", ImpID: "slot-1", Price: 5},
+				{ID: "a2", ImpID: "slot-2", Price: 4},
+				{ID: "a3", ImpID: "slot-3", Price: 3},
+			}},
+			{Seat: "bidder2", Bid: []openrtb.Bid{
+				{ID: "b2", ImpID: "slot-2", Price: 2},
+				{ID: "b4", ImpID: "slot-4", Price: 1},
+			}},
+		},
+	}
+
+	fill := BuildPodFill(req, resp)
+	if fill.Filled != 4 || fill.TotalPrice != 13 || fill.AvgCPM != 3.25 {
+		t.Errorf("expected every slot filled for 13 at 3.25 average, got %+v", fill)
+	}
+
+	// Half the pod per bidder: bidder1 keeps its two best slots and bidder2
+	// takes slot 4; slot 3 has no other bid
+	fill = buildPodFill(req, resp, PodRules{MaxBidderShare: 0.5}, nil)
+	if fill.Filled != 3 || fill.BidderCapped != 1 || fill.Slots[2].Filled || fill.Slots[1].BidID != "a2" {
+		t.Errorf("expected bidder1 capped at two slots, got %+v", fill)
+	}
+	if fill.TotalPrice != 10 || fill.AvgCPM != 3.333333 {
+		t.Errorf("expected 10 total, got %v (avg %v)", fill.TotalPrice, fill.AvgCPM)
+	}
+
+	// Bids are counted against the real bidder, not the seat
+	fill = buildPodFill(req, resp, PodRules{MaxBidderShare: 0.25}, func(string, *openrtb.Bid) string { return "same" })
+	if fill.Filled != 1 || fill.Slots[0].BidID != "a1" {
+		t.Errorf("expected one slot for the single bidder, got %+v", fill)
+	}
+
+	fill = buildPodFill(req, resp, PodRules{MinCPM: 14}, nil)
+	if fill.Filled != 0 || fill.Rejected != PodRejectedBelowMinCPM || fill.TotalPrice != 0 || len(fill.Unfilled()) != 4 {
+		t.Errorf("expected the pod rejected below its floor, got %+v", fill)
+	}
+	if fill = buildPodFill(req, resp, PodRules{MinCPM: 13}, nil); fill.Filled != 4 || fill.Rejected != "" {
+		t.Errorf("expected a pod at its floor served, got %+v", fill)
+	}
+}
+
+func TestPodRules_MaxSlotsPerBidder(t *testing.T) {
+	tests := []struct {
+		share float64
+		slots int
+		want  int
+	}{
+		{0, 4, 0},
+		{1, 4, 0},
+		{0.5, 4, 2},
+		{0.5, 3, 1},
+		{0.1, 4, 1}, // always at least one slot
+		{0.3, 10, 3},
+	}
+	for _, tt := range tests {
+		if got := (PodRules{MaxBidderShare: tt.share}).maxSlotsPerBidder(tt.slots); got != tt.want {
+			t.Errorf("share %v of %d slots: expected %d, got %d", tt.share, tt.slots, tt.want, got)
+		}
+	}
+}
+
+func TestRunAuction_PodRules(t *testing.T) {
+	registry := adapters.NewRegistry()
+	registry.Register("bidder1", &mockAdapter{bids: []*adapters.TypedBid{
+		{Bid: &openrtb.Bid{ID: "a1", ImpID: "slot-1", Price: 5, AdM: "<VAST/>"}, BidType: adapters.BidTypeVideo},
+		{Bid: &openrtb.Bid{ID: "a2", ImpID: "slot-2", Price: 4, AdM: "<VAST/>"}, BidType: adapters.BidTypeVideo},
+		{Bid: &openrtb.Bid{ID: "a3", ImpID: "slot-3", Price: 3, AdM: "<VAST/>"}, BidType: adapters.BidTypeVideo},
+	}}, adapters.BidderInfo{Enabled: true})
+	ex := New(registry, &Config{DefaultTimeout: 100 * time.Millisecond, DefaultCurrency: "USD"})
+	metrics := &podRuleMetrics{}
+	ex.SetMetrics(metrics)
+	tracker := &mockAnalyticsTracker{}
+	ex.SetAnalytics(tracker)
+
+	run := func(pub interface{}) *AuctionResponse {
+		t.Helper()
+		ctx := middleware.NewContextWithPublisher(context.Background(), pub)
+		req := testfixtures.Request("pod-req").Site("pub1.example", "pub1").Imp(testfixtures.Pod("slot", 3)...).Build()
+		resp, err := ex.RunAuction(ctx, &AuctionRequest{BidRequest: req})
+		if err != nil {
+			t.Fatalf("auction failed: %v", err)
+		}
+		return resp
+	}
+	countBids := func(resp *openrtb.BidResponse) int {
+		n := 0
+		for _, sb := range resp.SeatBid {
+			n += len(sb.Bid)
+		}
+		return n
+	}
+
+	resp := run(testfixtures.Publisher("pub1").Build())
+	if resp.PodFill.Filled != 3 || countBids(resp.BidResponse) != 3 || resp.PodFill.TotalPrice != 12 {
+		t.Errorf("expected the whole pod filled without rules, got %+v", resp.PodFill)
+	}
+
+	// A bidder held to a third of the pod keeps only its best slot, and the
+	// response carries exactly the assembled pod
+	resp = run(testfixtures.Publisher("pub1").PodMaxBidderShare(0.34).Build())
+	if resp.PodFill.Filled != 1 || resp.PodFill.BidderCapped != 2 || countBids(resp.BidResponse) != 1 {
+		t.Errorf("expected one slot filled, got %+v with %d bids", resp.PodFill, countBids(resp.BidResponse))
+	}
+	if refill := BuildPodFill(testfixtures.Request("pod-req").Imp(testfixtures.Pod("slot", 3)...).Build(), resp.BidResponse); refill.Filled != 1 || refill.Slots[0].BidID != "a1" {
+		t.Errorf("expected the response to reassemble into the same pod, got %+v", refill)
+	}
+	if metrics.enforcements[PodRuleBidderShare] != 2 {
+		t.Errorf("expected two capped bids recorded, got %v", metrics.enforcements)
+	}
+
+	// Below the pod floor nothing is served
+	tracker.events = nil
+	resp = run(testfixtures.Publisher("pub1").PodMinCPM(20).Build())
+	if resp.PodFill.Rejected != PodRejectedBelowMinCPM || countBids(resp.BidResponse) != 0 {
+		t.Errorf("expected the pod rejected, got %+v", resp.PodFill)
+	}
+	var ext struct {
+		Pod PodFill `json:"pod"`
+	}
+	if err := json.Unmarshal(resp.BidResponse.Ext, &ext); err != nil || ext.Pod.Rejected != PodRejectedBelowMinCPM {
+		t.Errorf("expected the rejection in ext.pod, got %s", resp.BidResponse.Ext)
+	}
+	if metrics.enforcements[PodRuleMinCPM] != 1 {
+		t.Errorf("expected the rejection recorded, got %v", metrics.enforcements)
+	}
+	var auction *analytics.Event
+	for i := range tracker.events {
+		if tracker.events[i].Type == analytics.EventAuction {
+			auction = &tracker.events[i]
+		}
+	}
+	if auction == nil || auction.Fields["pod_slots"] != "3" || auction.Fields["pod_filled"] != "0" || auction.Fields["pod_rejected"] != PodRejectedBelowMinCPM {
+		t.Errorf("expected pod economics on the auction event, got %+v", auction)
+	}
+}
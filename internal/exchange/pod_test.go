@@ -0,0 +1,220 @@
+package exchange
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/internal/testfixtures"
+)
+
+func podRequest(slots int) *openrtb.BidRequest {
+	return testfixtures.Request("pod-req").Imp(testfixtures.Pod("slot", slots)...).Build()
+}
+
+func TestBuildPodFill_Partial(t *testing.T) {
+	req := podRequest(3)
+	resp := &openrtb.BidResponse{
+		ID: "pod-req",
+		SeatBid: []openrtb.SeatBid{
+			{Seat: "bidder1", Bid: []openrtb.Bid{{ID: "b1", ImpID: "slot-1", Price: 2.0}}},
+			{Seat: "bidder2", Bid: []openrtb.Bid{
+				{ID: "b2", ImpID: "slot-1", Price: 3.0},
+				{ID: "b3", ImpID: "slot-3", Price: 1.0},
+			}},
+		},
+	}
+
+	fill := BuildPodFill(req, resp)
+	if fill == nil {
+		t.Fatal("expected pod fill for multi-slot video request")
+	}
+	if fill.Requested != 3 || fill.Filled != 2 {
+		t.Errorf("expected 2/3 filled, got %d/%d", fill.Filled, fill.Requested)
+	}
+	if fill.FillRate != 0.67 {
+		t.Errorf("expected fill rate 0.67, got %v", fill.FillRate)
+	}
+	if !fill.Partial() {
+		t.Error("expected partial fill")
+	}
+	if fill.Slots[0].BidID != "b2" || fill.Slots[0].Seat != "bidder2" {
+		t.Errorf("expected highest bid to win slot 1, got %+v", fill.Slots[0])
+	}
+	unfilled := fill.Unfilled()
+	if len(unfilled) != 1 || unfilled[0].ImpID != "slot-2" || unfilled[0].Sequence != 2 {
+		t.Errorf("expected slot 2 unfilled, got %+v", unfilled)
+	}
+}
+
+func TestBuildPodFill_NotAPod(t *testing.T) {
+	if BuildPodFill(podRequest(1), nil) != nil {
+		t.Error("expected nil pod fill for single video impression")
+	}
+	banner := &openrtb.BidRequest{Imp: []openrtb.Imp{{ID: "1", Banner: &openrtb.Banner{}}, {ID: "2", Banner: &openrtb.Banner{}}}}
+	if BuildPodFill(banner, nil) != nil {
+		t.Error("expected nil pod fill for banner request")
+	}
+}
+
+func TestAttachPodFill_PreservesExt(t *testing.T) {
+	resp := &openrtb.BidResponse{ID: "pod-req", Ext: json.RawMessage(`{"debug":true}`)}
+	attachPodFill(resp, BuildPodFill(podRequest(2), resp))
+
+	var ext struct {
+		Debug bool     `json:"debug"`
+		Pod   *PodFill `json:"pod"`
+	}
+	if err := json.Unmarshal(resp.Ext, &ext); err != nil {
+		t.Fatalf("invalid ext: %v", err)
+	}
+	if !ext.Debug {
+		t.Error("expected existing ext keys to be preserved")
+	}
+	if ext.Pod == nil || ext.Pod.Filled != 0 || ext.Pod.FillRate != 0 || len(ext.Pod.Slots) != 2 {
+		t.Errorf("expected empty pod with 2 unfilled slots, got %+v", ext.Pod)
+	}
+}
+
+func TestBuildVASTFromAuction_PodSequence(t *testing.T) {
+	req := podRequest(3)
+	auctionResp := &AuctionResponse{
+		BidResponse: &openrtb.BidResponse{
+			ID: "pod-req",
+			SeatBid: []openrtb.SeatBid{{Seat: "bidder1", Bid: []openrtb.Bid{
+				{ID: "third", ImpID: "slot-3", Price: 1.0, AdM: "https://cdn.example.com/c.mp4"},
+				{ID: "first", ImpID: "slot-1", Price: 1.0, AdM: "https://cdn.example.com/a.mp4"},
+				{ID: "first-loser", ImpID: "slot-1", Price: 0.5, AdM: "https://cdn.example.com/a2.mp4"},
+			}}},
+		},
+	}
+
+	v, err := NewVASTResponseBuilder("https://track.example.com").BuildVASTFromAuction(req, auctionResp)
+	if err != nil {
+		t.Fatalf("BuildVASTFromAuction failed: %v", err)
+	}
+	if len(v.Ads) != 2 {
+		t.Fatalf("expected one ad per filled slot, got %d", len(v.Ads))
+	}
+	if v.Ads[0].ID != "first" || v.Ads[0].Sequence != 1 || v.Ads[1].ID != "third" || v.Ads[1].Sequence != 3 {
+		t.Errorf("expected ads ordered by slot sequence, got %s/%d, %s/%d",
+			v.Ads[0].ID, v.Ads[0].Sequence, v.Ads[1].ID, v.Ads[1].Sequence)
+	}
+
+	rr := httptest.NewRecorder()
+	SetPodFillHeaders(rr, BuildPodFill(req, auctionResp.BidResponse))
+	if rr.Header().Get(HeaderPodFillRate) != "0.67" || rr.Header().Get(HeaderPodUnfilled) != "2" {
+		t.Errorf("unexpected pod headers: %v", rr.Header())
+	}
+	if rr.Header().Get(HeaderPodTotal) != "2" || rr.Header().Get(HeaderPodAvgCPM) != "1" {
+		t.Errorf("expected the pod's total and average CPM, got %v", rr.Header())
+	}
+}
+
+func TestExpandPod(t *testing.T) {
+	template := testfixtures.Video("pod").Duration(15, 30).DynamicPod(90, 0).Build()
+
+	imps := ExpandPod(template, nil)
+	if len(imps) != 3 {
+		t.Fatalf("expected 90s of 30s slots to make 3 slots, got %d", len(imps))
+	}
+	for i, imp := range imps {
+		if imp.ID != fmt.Sprintf("pod-%d", i+1) || imp.Video.Sequence != i+1 || imp.Video.PodID != "pod" {
+			t.Errorf("unexpected slot %d: id %s, %+v", i, imp.ID, imp.Video)
+		}
+	}
+	if imps[0].Video.SlotInPod != SlotInPodFirst || imps[1].Video.SlotInPod != SlotInPodAny || imps[2].Video.SlotInPod != SlotInPodLast {
+		t.Errorf("unexpected slot positions: %d, %d, %d", imps[0].Video.SlotInPod, imps[1].Video.SlotInPod, imps[2].Video.SlotInPod)
+	}
+	if template.Video.Sequence != 0 {
+		t.Error("expected the template left untouched")
+	}
+
+	// Slot durations decide the slot count and are capped by the pod
+	imps = ExpandPod(template, []int{60, 120, 10})
+	if len(imps) != 3 || imps[0].Video.MaxDuration != 60 || imps[1].Video.MaxDuration != 90 {
+		t.Fatalf("unexpected slot durations: %+v", imps)
+	}
+	if imps[2].Video.MaxDuration != 10 || imps[2].Video.MinDuration != 10 {
+		t.Errorf("expected min duration clamped to a short slot, got %d-%d", imps[2].Video.MinDuration, imps[2].Video.MaxDuration)
+	}
+
+	// maxseq caps the count and fan-out is bounded
+	if imps := ExpandPod(testfixtures.Video("p").DynamicPod(600, 2).Build(), nil); len(imps) != 2 {
+		t.Errorf("expected maxseq to cap slots at 2, got %d", len(imps))
+	}
+	if imps := ExpandPod(testfixtures.Video("p").Duration(5, 5).DynamicPod(600, 0).Build(), nil); len(imps) != maxPodSlots {
+		t.Errorf("expected at most %d slots, got %d", maxPodSlots, len(imps))
+	}
+}
+
+func TestExpandPods(t *testing.T) {
+	req := testfixtures.Request("r").Imp(
+		testfixtures.Video("pod").DynamicPod(60, 2),
+		testfixtures.Video("single"),
+		testfixtures.Banner("banner", 300, 250),
+	).Build()
+
+	ExpandPods(req)
+	ids := make([]string, len(req.Imp))
+	for i, imp := range req.Imp {
+		ids[i] = imp.ID
+	}
+	if got := strings.Join(ids, ","); got != "pod-1,pod-2,single,banner" {
+		t.Errorf("expected only the pod expanded, got %s", got)
+	}
+
+	// Already expanded slots are left alone
+	ExpandPods(req)
+	if len(req.Imp) != 4 {
+		t.Errorf("expected expansion to be idempotent, got %d impressions", len(req.Imp))
+	}
+}
+
+func TestBuildPodFill_CompetitiveSeparation(t *testing.T) {
+	req := podRequest(3)
+	resp := &openrtb.BidResponse{
+		ID: "pod-req",
+		SeatBid: []openrtb.SeatBid{
+			{Seat: "bidder1", Bid: []openrtb.Bid{
+				{ID: "car-1", ImpID: "slot-1", Price: 5.0, ADomain: []string{"cars.example"}},
+				{ID: "car-2", ImpID: "slot-2", Price: 4.0, ADomain: []string{"WWW.Cars.example"}},
+			}},
+			{Seat: "bidder2", Bid: []openrtb.Bid{
+				{ID: "soda", ImpID: "slot-2", Price: 2.0, ADomain: []string{"soda.example"}},
+				{ID: "unlabeled", ImpID: "slot-3", Price: 1.0},
+			}},
+		},
+	}
+
+	fill := BuildPodFill(req, resp)
+	if fill.Filled != 3 || fill.Separated != 1 {
+		t.Fatalf("expected 3 slots filled and 1 bid separated, got %+v", fill)
+	}
+	if fill.Slots[1].BidID != "soda" {
+		t.Errorf("expected the second car ad passed over for slot 2, got %s", fill.Slots[1].BidID)
+	}
+}
+
+func TestBuildPodFill_PodDuration(t *testing.T) {
+	req := testfixtures.Request("pod-req").Imp(
+		testfixtures.Video("slot-1").Sequence(1).DynamicPod(45, 0),
+		testfixtures.Video("slot-2").Sequence(2).DynamicPod(45, 0),
+	).Build()
+	resp := &openrtb.BidResponse{
+		ID: "pod-req",
+		SeatBid: []openrtb.SeatBid{{Seat: "bidder1", Bid: []openrtb.Bid{
+			{ID: "long", ImpID: "slot-1", Price: 5.0, Dur: 30},
+			{ID: "too-long", ImpID: "slot-2", Price: 4.0, Dur: 30},
+			{ID: "short", ImpID: "slot-2", Price: 1.0, Dur: 15},
+		}}},
+	}
+
+	fill := BuildPodFill(req, resp)
+	if fill.Filled != 2 || fill.Duration != 45 || fill.Slots[1].BidID != "short" {
+		t.Errorf("expected the pod filled to 45s with the short ad, got %+v", fill)
+	}
+}
@@ -0,0 +1,42 @@
+package exchange
+
+// Bid outcomes for the price landscape histogram
+const (
+	BidOutcomeWon        = "won"
+	BidOutcomeLost       = "lost"
+	BidOutcomeBelowFloor = "below_floor"
+)
+
+// bidPrices snapshots each valid bid's price before auction logic and the bid
+// multiplier rewrite it
+func bidPrices(validBids []ValidatedBid) map[string]float64 {
+	prices := make(map[string]float64, len(validBids))
+	for _, vb := range validBids {
+		prices[vb.Bid.Bid.ID] = vb.Bid.Bid.Price
+	}
+	return prices
+}
+
+// recordPriceLandscape records every valid bid's original price as won or
+// lost. The highest bid left for an impression after auction logic wins;
+// impressions whose bids were all rejected count every bid as lost.
+func (e *Exchange) recordPriceLandscape(validBids []ValidatedBid, auctionedBids map[string][]ValidatedBid, prices map[string]float64, mediaType, mediaSubtype string) {
+	if e.metrics == nil {
+		return
+	}
+
+	winners := make(map[string]struct{}, len(auctionedBids))
+	for _, bids := range auctionedBids {
+		if len(bids) > 0 && bids[0].Bid != nil && bids[0].Bid.Bid != nil {
+			winners[bids[0].Bid.Bid.ID] = struct{}{}
+		}
+	}
+
+	for _, vb := range validBids {
+		outcome := BidOutcomeLost
+		if _, won := winners[vb.Bid.Bid.ID]; won {
+			outcome = BidOutcomeWon
+		}
+		e.metrics.RecordBidOutcome(vb.BidderCode, mediaType, mediaSubtype, outcome, prices[vb.Bid.Bid.ID])
+	}
+}
@@ -0,0 +1,53 @@
+package exchange
+
+import (
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+// landscapeMetrics records bid outcomes on top of mockMetrics
+type landscapeMetrics struct {
+	mockMetrics
+	outcomes map[string]string  // bidder -> outcome
+	prices   map[string]float64 // bidder -> cpm
+}
+
+func (m *landscapeMetrics) RecordBidOutcome(bidder, mediaType, mediaSubtype, outcome string, cpm float64) {
+	if m.outcomes == nil {
+		m.outcomes = make(map[string]string)
+		m.prices = make(map[string]float64)
+	}
+	m.outcomes[bidder] = outcome
+	m.prices[bidder] = cpm
+}
+
+func TestRecordPriceLandscape(t *testing.T) {
+	ex := New(adapters.NewRegistry(), &Config{DefaultTimeout: 100 * time.Millisecond, AuctionType: SecondPriceAuction, PriceIncrement: 0.01})
+	metrics := &landscapeMetrics{}
+	ex.SetMetrics(metrics)
+
+	validBids := []ValidatedBid{
+		{BidderCode: "appnexus", Bid: &adapters.TypedBid{Bid: &openrtb.Bid{ID: "b1", ImpID: "imp1", Price: 5}}},
+		{BidderCode: "rubicon", Bid: &adapters.TypedBid{Bid: &openrtb.Bid{ID: "b2", ImpID: "imp1", Price: 3}}},
+		{BidderCode: "pubmatic", Bid: &adapters.TypedBid{Bid: &openrtb.Bid{ID: "b3", ImpID: "imp2", Price: 1}}},
+	}
+	impFloors := map[string]float64{"imp2": 2} // clearing price above the only bid
+
+	prices := bidPrices(validBids)
+	auctioned := ex.runAuctionLogic(validBids, impFloors)
+	ex.recordPriceLandscape(validBids, auctioned, prices, "video", MediaSubtypeInstream)
+
+	want := map[string]string{"appnexus": BidOutcomeWon, "rubicon": BidOutcomeLost, "pubmatic": BidOutcomeLost}
+	for bidder, outcome := range want {
+		if metrics.outcomes[bidder] != outcome {
+			t.Errorf("%s: expected %s, got %s", bidder, outcome, metrics.outcomes[bidder])
+		}
+	}
+	// The winner is recorded at its bid, not the second-price clearing price
+	if metrics.prices["appnexus"] != 5 {
+		t.Errorf("expected original winning bid 5, got %v", metrics.prices["appnexus"])
+	}
+}
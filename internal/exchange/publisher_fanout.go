@@ -0,0 +1,97 @@
+package exchange
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+)
+
+// publisherFanout holds a publisher's overrides of the exchange-wide fan-out
+// settings (storage.Publisher.TimeoutMs, MaxBidders, AllowedBidders and
+// BlockedBidders). Zero values use the exchange config.
+type publisherFanout struct {
+	timeout    time.Duration
+	maxBidders int
+	allowed    map[string]bool // nil = every enabled bidder
+	blocked    map[string]bool
+}
+
+// extractPublisherFanout safely extracts the publisher's fan-out overrides
+func extractPublisherFanout(v interface{}) publisherFanout {
+	var fanout publisherFanout
+	if getter, ok := v.(interface{ GetTimeoutMs() int }); ok && getter.GetTimeoutMs() > 0 {
+		ms := getter.GetTimeoutMs()
+		// Same bound as a request's tmax
+		if ms > maxAllowedTMax {
+			ms = maxAllowedTMax
+		}
+		fanout.timeout = time.Duration(ms) * time.Millisecond
+	}
+	if getter, ok := v.(interface{ GetMaxBidders() int }); ok && getter.GetMaxBidders() > 0 {
+		fanout.maxBidders = getter.GetMaxBidders()
+	}
+	if getter, ok := v.(interface{ GetAllowedBidders() string }); ok {
+		fanout.allowed = bidderCodeSet(getter.GetAllowedBidders())
+	}
+	if getter, ok := v.(interface{ GetBlockedBidders() string }); ok {
+		fanout.blocked = bidderCodeSet(getter.GetBlockedBidders())
+	}
+	return fanout
+}
+
+// publisherFanoutFromContext returns the fan-out overrides of the publisher
+// PublisherAuth stored in ctx, or none
+func publisherFanoutFromContext(ctx context.Context) publisherFanout {
+	pub := middleware.PublisherFromContext(ctx)
+	if pub == nil {
+		return publisherFanout{}
+	}
+	return extractPublisherFanout(pub)
+}
+
+// bidderCodeSet parses a comma-separated bidder code list, ignoring case and
+// whitespace. Returns nil for an empty list.
+func bidderCodeSet(list string) map[string]bool {
+	var codes map[string]bool
+	for _, code := range strings.Split(list, ",") {
+		code = strings.ToLower(strings.TrimSpace(code))
+		if code == "" {
+			continue
+		}
+		if codes == nil {
+			codes = make(map[string]bool)
+		}
+		codes[code] = true
+	}
+	return codes
+}
+
+// filterBidders drops bidders outside the publisher's allow list or on its
+// block list, returning the kept and the dropped bidders in selection order
+func (f publisherFanout) filterBidders(bidders []string) ([]string, []string) {
+	if f.allowed == nil && f.blocked == nil {
+		return bidders, nil
+	}
+	kept := make([]string, 0, len(bidders))
+	var dropped []string
+	for _, code := range bidders {
+		key := strings.ToLower(code)
+		if (f.allowed != nil && !f.allowed[key]) || f.blocked[key] {
+			dropped = append(dropped, code)
+			continue
+		}
+		kept = append(kept, code)
+	}
+	return kept, dropped
+}
+
+// maxBiddersOr returns the publisher's fan-out cap, or limit when it has
+// none. A publisher can lower the exchange-wide limit but never raise it.
+func (f publisherFanout) maxBiddersOr(limit int) int {
+	if f.maxBidders > 0 && (limit <= 0 || f.maxBidders < limit) {
+		return f.maxBidders
+	}
+	return limit
+}
@@ -0,0 +1,97 @@
+package exchange
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+	"github.com/thenexusengine/tne_springwire/internal/testfixtures"
+)
+
+func TestExtractPublisherFanout(t *testing.T) {
+	fanout := extractPublisherFanout(testfixtures.Publisher("pub1").TimeoutMs(400).MaxBidders(3).Build())
+	if fanout.timeout != 400*time.Millisecond || fanout.maxBiddersOr(50) != 3 {
+		t.Errorf("expected 400ms and 3 bidders, got %v and %d", fanout.timeout, fanout.maxBiddersOr(50))
+	}
+
+	fanout = extractPublisherFanout(testfixtures.Publisher("pub1").MaxBidders(80).Build())
+	if got := fanout.maxBiddersOr(50); got != 50 {
+		t.Errorf("expected the publisher cap limited to the exchange's, got %d", got)
+	}
+
+	fanout = extractPublisherFanout(testfixtures.Publisher("pub1").TimeoutMs(60000).Build())
+	if fanout.timeout != maxAllowedTMax*time.Millisecond {
+		t.Errorf("expected the timeout capped like tmax, got %v", fanout.timeout)
+	}
+
+	fanout = extractPublisherFanout(testfixtures.Publisher("pub1").Build())
+	if fanout.timeout != 0 || fanout.maxBiddersOr(50) != 50 || fanout.allowed != nil || fanout.blocked != nil {
+		t.Errorf("expected no overrides, got %+v", fanout)
+	}
+}
+
+func TestPublisherFanout_FilterBidders(t *testing.T) {
+	bidders := []string{"appnexus", "rubicon", "pubmatic", "ix"}
+	tests := []struct {
+		name        string
+		allowed     string
+		blocked     string
+		wantKept    []string
+		wantDropped []string
+	}{
+		{"no lists", "", "", bidders, nil},
+		{"allow list", "Rubicon, ix", "", []string{"rubicon", "ix"}, []string{"appnexus", "pubmatic"}},
+		{"block list", "", "pubmatic", []string{"appnexus", "rubicon", "ix"}, []string{"pubmatic"}},
+		{"block list wins", "appnexus,rubicon", "rubicon", []string{"appnexus"}, []string{"rubicon", "pubmatic", "ix"}},
+		{"blank entries ignored", " , ", "", bidders, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fanout := extractPublisherFanout(testfixtures.Publisher("pub1").AllowedBidders(tt.allowed).BlockedBidders(tt.blocked).Build())
+			kept, dropped := fanout.filterBidders(bidders)
+			if !reflect.DeepEqual(kept, tt.wantKept) || !reflect.DeepEqual(dropped, tt.wantDropped) {
+				t.Errorf("got kept=%v dropped=%v, want kept=%v dropped=%v", kept, dropped, tt.wantKept, tt.wantDropped)
+			}
+		})
+	}
+}
+
+func TestRunAuction_PublisherFanout(t *testing.T) {
+	registry := adapters.NewRegistry()
+	for _, code := range []string{"appnexus", "rubicon", "pubmatic", "ix"} {
+		registry.Register(code, &mockAdapter{}, adapters.BidderInfo{Enabled: true})
+	}
+	ex := New(registry, &Config{DefaultTimeout: 100 * time.Millisecond, MaxBidders: 10})
+	ex.bidderValues.observe("ix", 2.0)
+	ex.bidderValues.observe("rubicon", 1.0)
+	ex.bidderValues.observe("pubmatic", 0.5)
+
+	pub := testfixtures.Publisher("pub1").BlockedBidders("appnexus").MaxBidders(2).Build()
+	ctx := middleware.NewContextWithPublisher(context.Background(), pub)
+	req := testfixtures.Request("fanout-req").Site("pub1.example", "pub1").Imp(testfixtures.Banner("imp1", 300, 250)).Build()
+
+	resp, err := ex.RunAuction(ctx, &AuctionRequest{BidRequest: req})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.DebugInfo.SelectedBidders) != 2 || resp.DebugInfo.SelectedBidders[0] != "ix" {
+		t.Errorf("expected the publisher's cap of 2 led by ix, got %v", resp.DebugInfo.SelectedBidders)
+	}
+	excluded := append([]string(nil), resp.DebugInfo.ExcludedBidders...)
+	sort.Strings(excluded)
+	if len(excluded) != 2 || excluded[0] != "appnexus" {
+		t.Errorf("expected appnexus blocked and one bidder capped, got %v", excluded)
+	}
+
+	// Allow list leaving no bidder
+	pub = testfixtures.Publisher("pub1").AllowedBidders("openx").Build()
+	ctx = middleware.NewContextWithPublisher(context.Background(), pub)
+	resp, _ = ex.RunAuction(ctx, &AuctionRequest{BidRequest: testfixtures.Request("none-req").Site("pub1.example", "pub1").Imp(testfixtures.Banner("imp1", 300, 250)).Build()})
+	if len(resp.DebugInfo.SelectedBidders) != 0 || len(resp.BidResponse.SeatBid) != 0 {
+		t.Errorf("expected no bidder called, got %v", resp.DebugInfo.SelectedBidders)
+	}
+}
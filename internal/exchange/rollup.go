@@ -0,0 +1,100 @@
+package exchange
+
+import "github.com/thenexusengine/tne_springwire/internal/openrtb"
+
+// RollupRecorder accumulates per-publisher business metrics for long-term
+// reporting. Implemented by *rollup.Aggregator.
+type RollupRecorder interface {
+	// RecordAuction counts one auction request and the bids each bidder returned
+	RecordAuction(publisherID, mediaType string, bidsByBidder map[string]int)
+	// RecordAdvertiserWins counts the advertiser domains that won an auction
+	// for a viewer, identified by a hashed session key ("" when unknown)
+	RecordAdvertiserWins(publisherID, sessionKey string, domains []string)
+}
+
+// advertiserFrequencyNamespace keys viewers in advertiser frequency reports
+const advertiserFrequencyNamespace = "advertiser_frequency"
+
+// maxAdvertiserDomainLen is the width of the rollup's advertiser_domain column
+const maxAdvertiserDomainLen = 128
+
+// SetRollup sets the recorder that counts auctions for reporting rollups
+func (e *Exchange) SetRollup(r RollupRecorder) {
+	e.configMu.Lock()
+	defer e.configMu.Unlock()
+	e.rollup = r
+}
+
+// recordRollup counts a finished auction, including ones that ended early on
+// timeout, so fill rates in reports reflect every request
+func (e *Exchange) recordRollup(req *openrtb.BidRequest, response *AuctionResponse) {
+	e.configMu.RLock()
+	r := e.rollup
+	e.configMu.RUnlock()
+	publisherID := requestPublisherID(req)
+	if r == nil || publisherID == "" {
+		return
+	}
+
+	bids := make(map[string]int, len(response.BidderResults))
+	for code, result := range response.BidderResults {
+		if result != nil && len(result.Bids) > 0 {
+			bids[code] = len(result.Bids)
+		}
+	}
+	r.RecordAuction(publisherID, requestMediaType(req), bids)
+
+	if domains := winningAdvertisers(response); len(domains) > 0 {
+		var sessionKey string
+		if sessionID := requestSessionID(req); sessionID != "" {
+			e.configMu.RLock()
+			sessions := e.sessions
+			e.configMu.RUnlock()
+			sessionKey = sessions.Key(advertiserFrequencyNamespace, sessionID)
+		}
+		r.RecordAdvertiserWins(publisherID, sessionKey, domains)
+	}
+}
+
+// winningAdvertisers returns the first advertiser domain of each bid placed
+// in the response, normalized. House ads are the publisher's own, and bids
+// without adomain, or with one too long to be a domain, are skipped.
+func winningAdvertisers(response *AuctionResponse) []string {
+	if response.BidResponse == nil {
+		return nil
+	}
+	var domains []string
+	for _, sb := range response.BidResponse.SeatBid {
+		if sb.Seat == HouseSeatName {
+			continue
+		}
+		for _, bid := range sb.Bid {
+			for _, domain := range bid.ADomain {
+				if d := normalizeAdvertiserDomain(domain); d != "" && len(d) <= maxAdvertiserDomainLen {
+					domains = append(domains, d)
+					break
+				}
+			}
+		}
+	}
+	return domains
+}
+
+// requestMediaType returns the media type of the request's first impression
+func requestMediaType(req *openrtb.BidRequest) string {
+	if len(req.Imp) == 0 {
+		return ""
+	}
+	switch imp := req.Imp[0]; {
+	case imp.Banner != nil:
+		return "banner"
+	case imp.Video != nil:
+		return "video"
+	case imp.Native != nil:
+		return "native"
+	case imp.Audio != nil:
+		return "audio"
+	default:
+		return ""
+	}
+}
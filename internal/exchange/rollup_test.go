@@ -0,0 +1,104 @@
+package exchange
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/internal/testfixtures"
+)
+
+type mockRollupRecorder struct {
+	publisherID, mediaType string
+	bids                   map[string]int
+	calls                  int
+	sessionKey             string
+	domains                []string
+}
+
+func (m *mockRollupRecorder) RecordAuction(publisherID, mediaType string, bidsByBidder map[string]int) {
+	m.publisherID, m.mediaType, m.bids = publisherID, mediaType, bidsByBidder
+	m.calls++
+}
+
+func (m *mockRollupRecorder) RecordAdvertiserWins(publisherID, sessionKey string, domains []string) {
+	m.sessionKey, m.domains = sessionKey, domains
+}
+
+func TestRecordRollup(t *testing.T) {
+	ex := New(adapters.NewRegistry(), &Config{DefaultTimeout: 100 * time.Millisecond})
+	rec := &mockRollupRecorder{}
+	ex.SetRollup(rec)
+
+	req := testfixtures.Request("auction-1").Site("example.com", "pub-1").Imp(testfixtures.Video("imp-1")).Build()
+	resp := &AuctionResponse{BidderResults: map[string]*BidderResult{
+		"appnexus": {BidderCode: "appnexus", Bids: []*adapters.TypedBid{{}, {}}},
+		"rubicon":  {BidderCode: "rubicon", TimedOut: true},
+	}}
+
+	ex.recordRollup(req, resp)
+	if rec.calls != 1 || rec.publisherID != "pub-1" || rec.mediaType != "video" {
+		t.Fatalf("unexpected rollup record: %+v", rec)
+	}
+	if len(rec.bids) != 1 || rec.bids["appnexus"] != 2 {
+		t.Errorf("expected only bidders with bids counted, got %v", rec.bids)
+	}
+
+	// Requests without a publisher can't be attributed
+	req.Site.Publisher = nil
+	ex.recordRollup(req, resp)
+	if rec.calls != 1 {
+		t.Errorf("expected no record without a publisher, got %d calls", rec.calls)
+	}
+}
+
+func TestRecordRollup_AdvertiserWins(t *testing.T) {
+	ex := New(adapters.NewRegistry(), &Config{DefaultTimeout: 100 * time.Millisecond})
+	rec := &mockRollupRecorder{}
+	ex.SetRollup(rec)
+
+	req := testfixtures.Request("auction-1").Site("example.com", "pub-1").Imp(testfixtures.Video("imp-1")).Build()
+	req.User = &openrtb.User{ID: "viewer-1"}
+	resp := &AuctionResponse{BidResponse: &openrtb.BidResponse{SeatBid: []openrtb.SeatBid{
+		{Seat: "appnexus", Bid: []openrtb.Bid{
+			{ID: "b1", ADomain: []string{"WWW.Acme.example", "acme-cars.example"}},
+			{ID: "b2"},
+		}},
+		{Seat: "rubicon", Bid: []openrtb.Bid{{ID: "b3", ADomain: []string{"", "soda.example"}}}},
+		{Seat: HouseSeatName, Bid: []openrtb.Bid{{ID: "h1", ADomain: []string{"publisher.example"}}}},
+	}}}
+
+	ex.recordRollup(req, resp)
+	if len(rec.domains) != 2 || rec.domains[0] != "acme.example" || rec.domains[1] != "soda.example" {
+		t.Errorf("expected each bid's first domain, normalized, got %v", rec.domains)
+	}
+	if rec.sessionKey == "" || rec.sessionKey == "viewer-1" {
+		t.Errorf("expected a hashed session key, got %q", rec.sessionKey)
+	}
+
+	// Anonymous viewers count without a session
+	req.User = nil
+	ex.recordRollup(req, resp)
+	if rec.sessionKey != "" {
+		t.Errorf("expected no session key, got %q", rec.sessionKey)
+	}
+}
+
+func TestRunAuction_ShadowNotRolledUp(t *testing.T) {
+	ex := New(adapters.NewRegistry(), &Config{DefaultTimeout: 100 * time.Millisecond})
+	rec := &mockRollupRecorder{}
+	ex.SetRollup(rec)
+
+	req := testfixtures.Request("auction-1").Site("example.com", "pub-1").Imp(testfixtures.Video("imp-1")).Build()
+	if _, err := ex.RunAuction(context.Background(), &AuctionRequest{BidRequest: req, Shadow: true}); err != nil {
+		t.Fatalf("auction failed: %v", err)
+	}
+	if rec.calls != 0 {
+		t.Errorf("expected shadow auctions not to be rolled up, got %d calls", rec.calls)
+	}
+	if req.Test != 1 {
+		t.Error("expected shadow auctions to be sent to bidders as test requests")
+	}
+}
@@ -0,0 +1,90 @@
+package exchange
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+// supplyChainVersion is the SupplyChain object version of chains the
+// exchange starts
+const supplyChainVersion = "1.0"
+
+// applySupplyChain appends the exchange's own node to the request's supply
+// chain, starting a complete chain when the publisher sent none, so buyers
+// can verify the path back to the publisher. A chain that doesn't validate,
+// or has too many nodes to forward, is marked incomplete; the problem is
+// returned for debug output. Requests are left alone when no host node is
+// configured or its sid can't be resolved.
+func (e *Exchange) applySupplyChain(req *openrtb.BidRequest) string {
+	asi := e.config.SChainASI
+	if asi == "" {
+		return ""
+	}
+	sid := e.config.SChainSID
+	if sid == "" {
+		sid = requestPublisherID(req)
+	}
+	if sid == "" {
+		return ""
+	}
+
+	chain := openrtb.SupplyChain{Complete: 1, Ver: supplyChainVersion}
+	var problem string
+	if req.Source != nil && req.Source.SChain != nil {
+		chain = *req.Source.SChain
+		if n := len(chain.Nodes); n > 0 && strings.EqualFold(chain.Nodes[n-1].ASI, asi) && chain.Nodes[n-1].SID == sid {
+			// Already ours, as when a request is re-auctioned
+			return ""
+		}
+		if chain.Ver == "" {
+			chain.Ver = supplyChainVersion
+		}
+
+		// Leave room for our node under the clone limit, which would
+		// otherwise cut it off
+		maxNodes := defaultMaxSChainNodes
+		if e.config.CloneLimits != nil && e.config.CloneLimits.MaxSChainNodes > 0 {
+			maxNodes = e.config.CloneLimits.MaxSChainNodes
+		}
+		problem = validateSupplyChain(&chain)
+		if len(chain.Nodes) >= maxNodes {
+			problem = fmt.Sprintf("schain has %d nodes, more than the %d forwarded", len(chain.Nodes), maxNodes-1)
+			chain.Nodes = chain.Nodes[:maxNodes-1]
+		}
+		if problem != "" {
+			chain.Complete = 0
+		}
+	}
+
+	nodes := make([]openrtb.SupplyChainNode, len(chain.Nodes), len(chain.Nodes)+1)
+	copy(nodes, chain.Nodes)
+	chain.Nodes = append(nodes, openrtb.SupplyChainNode{ASI: asi, SID: sid, RID: req.ID, HP: 1})
+
+	source := openrtb.Source{}
+	if req.Source != nil {
+		source = *req.Source
+	}
+	source.SChain = &chain
+	req.Source = &source
+	return problem
+}
+
+// validateSupplyChain reports why a publisher's chain can't be vouched for
+// as complete: a complete flag other than 0 or 1, or a node without the
+// asi, sid and hp=1 the spec requires
+func validateSupplyChain(chain *openrtb.SupplyChain) string {
+	if chain.Complete != 0 && chain.Complete != 1 {
+		return fmt.Sprintf("schain complete must be 0 or 1, got %d", chain.Complete)
+	}
+	for i, node := range chain.Nodes {
+		if node.ASI == "" || node.SID == "" {
+			return fmt.Sprintf("schain node %d is missing asi or sid", i)
+		}
+		if node.HP != 1 {
+			return fmt.Sprintf("schain node %d must set hp to 1", i)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,131 @@
+package exchange
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/internal/testfixtures"
+)
+
+// requestCapturingAdapter records the request the exchange sent it
+type requestCapturingAdapter struct {
+	mockAdapter
+	lastRequest *openrtb.BidRequest
+}
+
+func (a *requestCapturingAdapter) MakeRequests(request *openrtb.BidRequest, reqInfo *adapters.ExtraRequestInfo) ([]*adapters.RequestData, []error) {
+	a.lastRequest = request
+	return a.mockAdapter.MakeRequests(request, reqInfo)
+}
+
+func newSupplyChainExchange(sid string) *Exchange {
+	return New(adapters.NewRegistry(), &Config{DefaultTimeout: 100 * time.Millisecond, SChainASI: "springwire.ai", SChainSID: sid})
+}
+
+func TestApplySupplyChain(t *testing.T) {
+	reseller := openrtb.SupplyChainNode{ASI: "reseller.example", SID: "42", HP: 1}
+	tests := []struct {
+		name         string
+		chain        *openrtb.SupplyChain
+		wantComplete int
+		wantNodes    int
+		wantProblem  bool
+	}{
+		{"no chain", nil, 1, 1, false},
+		{"complete chain", &openrtb.SupplyChain{Complete: 1, Ver: "1.0", Nodes: []openrtb.SupplyChainNode{reseller}}, 1, 2, false},
+		{"incomplete chain", &openrtb.SupplyChain{Complete: 0, Nodes: []openrtb.SupplyChainNode{reseller}}, 0, 2, false},
+		{"node without sid", &openrtb.SupplyChain{Complete: 1, Nodes: []openrtb.SupplyChainNode{{ASI: "reseller.example", HP: 1}}}, 0, 2, true},
+		{"node without hp", &openrtb.SupplyChain{Complete: 1, Nodes: []openrtb.SupplyChainNode{{ASI: "reseller.example", SID: "42"}}}, 0, 2, true},
+		{"bad complete flag", &openrtb.SupplyChain{Complete: 2, Nodes: []openrtb.SupplyChainNode{reseller}}, 0, 2, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ex := newSupplyChainExchange("")
+			req := testfixtures.Request("auction-1").Site("example.com", "pub-1").Imp(testfixtures.Video("imp-1")).Build()
+			if tt.chain != nil {
+				req.Source = &openrtb.Source{TID: "t1", SChain: tt.chain}
+			}
+
+			problem := ex.applySupplyChain(req)
+			if (problem != "") != tt.wantProblem {
+				t.Errorf("unexpected problem %q", problem)
+			}
+			chain := req.Source.SChain
+			if chain.Complete != tt.wantComplete || len(chain.Nodes) != tt.wantNodes || chain.Ver == "" {
+				t.Fatalf("unexpected chain %+v", chain)
+			}
+			want := openrtb.SupplyChainNode{ASI: "springwire.ai", SID: "pub-1", RID: "auction-1", HP: 1}
+			if last := chain.Nodes[len(chain.Nodes)-1]; last.ASI != want.ASI || last.SID != want.SID || last.RID != want.RID || last.HP != want.HP {
+				t.Errorf("expected our node last, got %+v", last)
+			}
+			if tt.chain != nil && len(tt.chain.Nodes) != 1 {
+				t.Error("expected the publisher's nodes left untouched")
+			}
+		})
+	}
+}
+
+func TestApplySupplyChain_Hops(t *testing.T) {
+	ex := newSupplyChainExchange("seat-9")
+	ex.config.CloneLimits = &CloneLimits{MaxSChainNodes: 3}
+	req := testfixtures.Request("auction-1").Site("example.com", "pub-1").Imp(testfixtures.Video("imp-1")).Build()
+	req.Source = &openrtb.Source{SChain: &openrtb.SupplyChain{Complete: 1, Nodes: []openrtb.SupplyChainNode{
+		{ASI: "a.example", SID: "1", HP: 1},
+		{ASI: "b.example", SID: "2", HP: 1},
+		{ASI: "c.example", SID: "3", HP: 1},
+	}}}
+
+	if problem := ex.applySupplyChain(req); problem == "" {
+		t.Error("expected an over-long chain reported")
+	}
+	chain := req.Source.SChain
+	if chain.Complete != 0 || len(chain.Nodes) != 3 || chain.Nodes[1].ASI != "b.example" || chain.Nodes[2].SID != "seat-9" {
+		t.Fatalf("expected the chain cut to make room for our node and marked incomplete, got %+v", chain)
+	}
+
+	// A request that already carries our hop isn't extended again
+	if problem := ex.applySupplyChain(req); problem != "" || len(req.Source.SChain.Nodes) != 3 {
+		t.Errorf("expected our node not appended twice, got %+v (%q)", req.Source.SChain, problem)
+	}
+}
+
+func TestApplySupplyChain_Disabled(t *testing.T) {
+	ex := New(adapters.NewRegistry(), &Config{DefaultTimeout: 100 * time.Millisecond})
+	req := testfixtures.Request("auction-1").Site("example.com", "pub-1").Imp(testfixtures.Video("imp-1")).Build()
+	ex.applySupplyChain(req)
+	if req.Source != nil && req.Source.SChain != nil {
+		t.Errorf("expected no chain without a host node, got %+v", req.Source.SChain)
+	}
+
+	// Without a publisher there's no seller ID to vouch for
+	ex = newSupplyChainExchange("")
+	req.Site.Publisher = nil
+	ex.applySupplyChain(req)
+	if req.Source != nil && req.Source.SChain != nil {
+		t.Errorf("expected no chain without a sid, got %+v", req.Source.SChain)
+	}
+}
+
+func TestRunAuction_ForwardsSupplyChain(t *testing.T) {
+	registry := adapters.NewRegistry()
+	adapter := &requestCapturingAdapter{}
+	if err := registry.Register("test", adapter, adapters.BidderInfo{Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+	ex := New(registry, &Config{DefaultTimeout: 100 * time.Millisecond, SChainASI: "springwire.ai"})
+
+	req := testfixtures.Request("auction-1").Site("example.com", "pub-1").Imp(testfixtures.Video("imp-1")).Build()
+	if _, err := ex.RunAuction(context.Background(), &AuctionRequest{BidRequest: req}); err != nil {
+		t.Fatalf("auction failed: %v", err)
+	}
+	sent := adapter.lastRequest
+	if sent == nil || sent.Source == nil || sent.Source.SChain == nil || len(sent.Source.SChain.Nodes) != 1 {
+		t.Fatalf("expected the bidder sent our supply chain, got %+v", sent)
+	}
+	if node := sent.Source.SChain.Nodes[0]; node.ASI != "springwire.ai" || node.SID != "pub-1" {
+		t.Errorf("unexpected node %+v", node)
+	}
+}
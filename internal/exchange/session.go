@@ -0,0 +1,44 @@
+package exchange
+
+import (
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/pkg/sessionhash"
+)
+
+// idrExplorationNamespace keys the exploration seeds sent to IDR
+const idrExplorationNamespace = "idr_exploration"
+
+// SetSessionHasher sets the salted hasher for session buckets
+func (e *Exchange) SetSessionHasher(h *sessionhash.Hasher) {
+	e.configMu.Lock()
+	defer e.configMu.Unlock()
+	e.sessions = h
+}
+
+// requestSessionID identifies the viewer across requests: the exchange user
+// ID, else the device advertising ID. "" when the request carries neither.
+func requestSessionID(req *openrtb.BidRequest) string {
+	if req == nil {
+		return ""
+	}
+	if req.User != nil && req.User.ID != "" {
+		return req.User.ID
+	}
+	if req.Device != nil && req.Device.IFA != "" {
+		return req.Device.IFA
+	}
+	return ""
+}
+
+// explorationSeed returns the session's IDR exploration seed, or "" for
+// requests without a session, which IDR explores at random
+func (e *Exchange) explorationSeed(req *openrtb.BidRequest) string {
+	sessionID := requestSessionID(req)
+	if sessionID == "" {
+		return ""
+	}
+	e.configMu.RLock()
+	sessions := e.sessions
+	e.configMu.RUnlock()
+	return sessions.Key(idrExplorationNamespace, sessionID)
+}
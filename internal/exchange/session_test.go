@@ -0,0 +1,103 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/pkg/sessionhash"
+)
+
+type sessionFlags struct {
+	sessions []string
+}
+
+func (f *sessionFlags) EnabledFor(name, publisherID, sessionID string) bool {
+	f.sessions = append(f.sessions, sessionID)
+	return true
+}
+
+func TestRequestSessionID(t *testing.T) {
+	tests := []struct {
+		name string
+		req  *openrtb.BidRequest
+		want string
+	}{
+		{"nil", nil, ""},
+		{"user", &openrtb.BidRequest{User: &openrtb.User{ID: "u-1"}, Device: &openrtb.Device{IFA: "ifa-1"}}, "u-1"},
+		{"ifa", &openrtb.BidRequest{User: &openrtb.User{}, Device: &openrtb.Device{IFA: "ifa-1"}}, "ifa-1"},
+		{"none", &openrtb.BidRequest{ID: "req-1"}, ""},
+	}
+	for _, tt := range tests {
+		if got := requestSessionID(tt.req); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestExplorationSeed_StickyAcrossReplicas(t *testing.T) {
+	var mu sync.Mutex
+	var seeds []string
+	idrServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Request struct {
+				ExplorationSeed string `json:"exploration_seed"`
+			} `json:"request"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		seeds = append(seeds, body.Request.ExplorationSeed)
+		mu.Unlock()
+		w.Write([]byte(`{"selected_bidders": [], "excluded_bidders": []}`))
+	}))
+	defer idrServer.Close()
+
+	newReplica := func() (*Exchange, *sessionFlags) {
+		registry := adapters.NewRegistry()
+		registry.Register("bidder1", &mockAdapter{}, adapters.BidderInfo{Enabled: true})
+		ex := New(registry, &Config{
+			DefaultTimeout: 100 * time.Millisecond,
+			IDREnabled:     true,
+			IDRServiceURL:  idrServer.URL,
+		})
+		flags := &sessionFlags{}
+		ex.SetFeatureFlags(flags)
+		ex.SetSessionHasher(sessionhash.New(sessionhash.Config{Salt: "test"}))
+		return ex, flags
+	}
+
+	run := func(ex *Exchange, user string) {
+		_, err := ex.RunAuction(context.Background(), &AuctionRequest{
+			BidRequest: &openrtb.BidRequest{
+				ID:   "req-" + user,
+				Site: &openrtb.Site{ID: "site", Publisher: &openrtb.Publisher{ID: "pub-1"}},
+				User: &openrtb.User{ID: user},
+				Imp:  []openrtb.Imp{{ID: "imp1", Banner: &openrtb.Banner{W: 300, H: 250}}},
+			},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	a, flags := newReplica()
+	b, _ := newReplica()
+	run(a, "user-1")
+	run(b, "user-1")
+	run(a, "user-2")
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(seeds) != 3 || seeds[0] == "" || seeds[0] != seeds[1] || seeds[0] == seeds[2] {
+		t.Errorf("expected one seed per session on every replica, got %v", seeds)
+	}
+	if len(flags.sessions) == 0 || flags.sessions[0] != "user-1" {
+		t.Errorf("expected flags evaluated for the session, got %v", flags.sessions)
+	}
+}
@@ -0,0 +1,64 @@
+package exchange
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+	"github.com/thenexusengine/tne_springwire/internal/testfixtures"
+)
+
+// tcfVendor52 is a TCF v2.2 string consenting to purposes 1 and 2 and to
+// GVL vendor 52 only
+const tcfVendor52 = "CAAAAAAAAAAAAAHABBENEsEAAMAAAAAAAAYgAaAAAAAAAABAAAAA"
+
+// privacyFilterMetrics records PrivacyFiltered calls
+type privacyFilterMetrics struct {
+	mockMetrics
+	filtered map[string]string
+}
+
+func (m *privacyFilterMetrics) RecordPrivacyFiltered(bidder, reason string) {
+	m.filtered[bidder] = reason
+}
+
+func TestRunAuction_TCFVendorConsent(t *testing.T) {
+	registry := adapters.NewRegistry()
+	consented := &requestCapturingAdapter{}
+	refused := &requestCapturingAdapter{}
+	if err := registry.Register("consented", consented, adapters.BidderInfo{Enabled: true, GVLVendorID: 52}); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Register("refused", refused, adapters.BidderInfo{Enabled: true, GVLVendorID: 53}); err != nil {
+		t.Fatal(err)
+	}
+	ex := New(registry, &Config{DefaultTimeout: 100 * time.Millisecond})
+	metrics := &privacyFilterMetrics{filtered: make(map[string]string)}
+	ex.SetMetrics(metrics)
+
+	// regs.gdpr=1 without geo still enforces vendor consent
+	req := testfixtures.Request("auction-1").Site("example.com", "pub-1").Imp(testfixtures.Video("imp-1")).
+		Consent(testfixtures.GDPR(tcfVendor52)).Build()
+	result, err := ex.RunAuction(context.Background(), &AuctionRequest{BidRequest: req})
+	if err != nil {
+		t.Fatalf("auction failed: %v", err)
+	}
+
+	if consented.lastRequest == nil {
+		t.Error("expected the consented vendor sent the request")
+	}
+	if refused.lastRequest != nil {
+		t.Error("expected the vendor without consent skipped")
+	}
+	if got := metrics.filtered["refused"]; got != middleware.PrivacyFilterReasonTCF {
+		t.Errorf("expected refused filtered for TCF, got %v", metrics.filtered)
+	}
+	if _, ok := metrics.filtered["consented"]; ok {
+		t.Error("expected the consented vendor not filtered")
+	}
+	if r := result.BidderResults["refused"]; r == nil || len(r.Errors) == 0 {
+		t.Errorf("expected an error result for the skipped bidder, got %+v", r)
+	}
+}
@@ -0,0 +1,134 @@
+package exchange
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Tie-breaking rules for bids on the same impression at the same price
+const (
+	TieBreakResponseOrder = "response_order" // the first bid received wins
+	TieBreakWeighted      = "weighted"       // weighted random per bidder, seeded by the auction ID
+	TieBreakDealPriority  = "deal_priority"  // deal bids first, then weighted random
+)
+
+// ValidTieBreak reports whether rule is a known tie-breaking rule; ""
+// is response order
+func ValidTieBreak(rule string) bool {
+	switch rule {
+	case "", TieBreakResponseOrder, TieBreakWeighted, TieBreakDealPriority:
+		return true
+	}
+	return false
+}
+
+// ParseTieBreakWeights parses comma-separated "bidder:weight" pairs.
+// Bidders without a weight get 1.
+func ParseTieBreakWeights(s string) (map[string]float64, error) {
+	weights := make(map[string]float64)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		bidder, value, ok := strings.Cut(entry, ":")
+		bidder = strings.TrimSpace(bidder)
+		weight, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if !ok || bidder == "" || err != nil || weight <= 0 || math.IsInf(weight, 0) {
+			return nil, fmt.Errorf("invalid tie-break weight %q: expected bidder:weight with a positive weight", entry)
+		}
+		weights[bidder] = weight
+	}
+	return weights, nil
+}
+
+// AuctionSeed is the tie-breaking seed of an auction, derived from its ID.
+// Replaying a request with the same ID breaks ties the same way, whatever
+// order the bids arrive in.
+func AuctionSeed(auctionID string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(auctionID))
+	return h.Sum64()
+}
+
+// tieBreakKey draws a bidder's key for an impression from the auction seed.
+// Ranking by u^(1/weight) picks each tied bidder with probability
+// proportional to its weight.
+func tieBreakKey(seed uint64, impID, bidder string, weight float64) float64 {
+	h := fnv.New64a()
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], seed)
+	h.Write(buf[:])
+	h.Write([]byte(impID))
+	h.Write([]byte{0})
+	h.Write([]byte(bidder))
+	u := (float64(mix64(h.Sum64())>>11) + 0.5) / (1 << 53) // uniform in (0, 1)
+	return math.Pow(u, 1/weight)
+}
+
+// mix64 is the splitmix64 finalizer. FNV leaves the high bits of inputs that
+// differ only in their last bytes correlated, which would bias the draws.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	return x ^ x>>31
+}
+
+// tieBreakWeight returns a bidder's tie-breaking weight
+func (e *Exchange) tieBreakWeight(bidder string) float64 {
+	if w, ok := e.config.TieBreakWeights[bidder]; ok && w > 0 {
+		return w
+	}
+	return 1
+}
+
+// orderForTieBreak orders bids by the configured tie-breaking rule. The
+// auction then sorts each impression's bids stably by price, so bids at the
+// same price keep this order.
+func (e *Exchange) orderForTieBreak(auctionID string, bids []ValidatedBid) {
+	rule := e.config.TieBreak
+	if rule == "" || rule == TieBreakResponseOrder || len(bids) < 2 {
+		return
+	}
+	for _, vb := range bids {
+		if vb.Bid == nil || vb.Bid.Bid == nil {
+			return
+		}
+	}
+
+	seed := AuctionSeed(auctionID)
+	keys := make([]float64, len(bids))
+	for i, vb := range bids {
+		keys[i] = tieBreakKey(seed, vb.Bid.Bid.ImpID, vb.BidderCode, e.tieBreakWeight(vb.BidderCode))
+	}
+	order := make([]int, len(bids))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		x, y := bids[order[a]], bids[order[b]]
+		if rule == TieBreakDealPriority {
+			if xDeal, yDeal := x.Bid.Bid.DealID != "", y.Bid.Bid.DealID != ""; xDeal != yDeal {
+				return xDeal
+			}
+		}
+		if keys[order[a]] != keys[order[b]] {
+			return keys[order[a]] > keys[order[b]]
+		}
+		// Several bids from one bidder: fall back to bid IDs
+		return x.Bid.Bid.ID < y.Bid.Bid.ID
+	})
+
+	sorted := make([]ValidatedBid, len(bids))
+	for i, idx := range order {
+		sorted[i] = bids[idx]
+	}
+	copy(bids, sorted)
+}
@@ -0,0 +1,109 @@
+package exchange
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+// tiedBids returns a bid per bidder on imp1, all at 2.00
+func tiedBids(bidders ...string) []ValidatedBid {
+	bids := make([]ValidatedBid, len(bidders))
+	for i, bidder := range bidders {
+		bids[i] = ValidatedBid{
+			Bid:        &adapters.TypedBid{Bid: &openrtb.Bid{ID: bidder + "-bid", ImpID: "imp1", Price: 2.00}},
+			BidderCode: bidder,
+		}
+	}
+	return bids
+}
+
+// tieWinner runs the auction logic on bids for an auction ID and returns
+// the winning bidder
+func tieWinner(ex *Exchange, auctionID string, bids []ValidatedBid) string {
+	ex.orderForTieBreak(auctionID, bids)
+	return ex.runAuctionLogic(bids, map[string]float64{})["imp1"][0].BidderCode
+}
+
+func TestTieBreak_ResponseOrder(t *testing.T) {
+	ex := New(adapters.NewRegistry(), &Config{})
+	for _, auctionID := range []string{"a1", "a2", "a3"} {
+		if got := tieWinner(ex, auctionID, tiedBids("first", "second")); got != "first" {
+			t.Errorf("expected the first response to win, got %s", got)
+		}
+	}
+}
+
+func TestTieBreak_WeightedIsReproducible(t *testing.T) {
+	ex := New(adapters.NewRegistry(), &Config{TieBreak: TieBreakWeighted})
+	for i := 0; i < 50; i++ {
+		auctionID := fmt.Sprintf("auction-%d", i)
+		forward := tieWinner(ex, auctionID, tiedBids("a", "b", "c"))
+		reversed := tieWinner(ex, auctionID, tiedBids("c", "b", "a"))
+		if forward != reversed {
+			t.Fatalf("%s: expected the same winner whatever the response order, got %s and %s", auctionID, forward, reversed)
+		}
+	}
+}
+
+func TestTieBreak_WeightedShares(t *testing.T) {
+	tests := []struct {
+		name    string
+		weights map[string]float64
+		wantA   float64 // expected share of wins for bidder a
+	}{
+		{"equal weights", nil, 0.5},
+		{"a weighted 3:1", map[string]float64{"a": 3}, 0.75},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ex := New(adapters.NewRegistry(), &Config{TieBreak: TieBreakWeighted, TieBreakWeights: tt.weights})
+			const auctions = 4000
+			wins := 0
+			for i := 0; i < auctions; i++ {
+				if tieWinner(ex, fmt.Sprintf("auction-%d", i), tiedBids("a", "b")) == "a" {
+					wins++
+				}
+			}
+			if share := float64(wins) / auctions; share < tt.wantA-0.04 || share > tt.wantA+0.04 {
+				t.Errorf("expected a to win about %.2f of ties, got %.3f", tt.wantA, share)
+			}
+		})
+	}
+}
+
+func TestTieBreak_DealPriority(t *testing.T) {
+	ex := New(adapters.NewRegistry(), &Config{TieBreak: TieBreakDealPriority})
+	for i := 0; i < 20; i++ {
+		bids := tiedBids("open", "deal")
+		bids[1].Bid.Bid.DealID = "deal-1"
+		if got := tieWinner(ex, fmt.Sprintf("auction-%d", i), bids); got != "deal" {
+			t.Fatalf("expected the deal bid to win the tie, got %s", got)
+		}
+	}
+
+	// Price still comes first
+	bids := tiedBids("open", "deal")
+	bids[0].Bid.Bid.Price = 2.50
+	bids[1].Bid.Bid.DealID = "deal-1"
+	if got := tieWinner(ex, "auction-x", bids); got != "open" {
+		t.Errorf("expected the higher open bid to win, got %s", got)
+	}
+}
+
+func TestParseTieBreakWeights(t *testing.T) {
+	weights, err := ParseTieBreakWeights(" appnexus:2 ,rubicon:0.5,")
+	if err != nil || len(weights) != 2 || weights["appnexus"] != 2 || weights["rubicon"] != 0.5 {
+		t.Errorf("unexpected weights %v (%v)", weights, err)
+	}
+	for _, bad := range []string{"appnexus", "appnexus:0", "appnexus:-1", ":2", "appnexus:x"} {
+		if _, err := ParseTieBreakWeights(bad); err == nil {
+			t.Errorf("expected %q rejected", bad)
+		}
+	}
+	if AuctionSeed("auction-1") != AuctionSeed("auction-1") || AuctionSeed("auction-1") == AuctionSeed("auction-2") {
+		t.Error("expected seeds fixed per auction ID")
+	}
+}
@@ -0,0 +1,222 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/thenexusengine/tne_springwire/internal/idmodules"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+// UID2Checker validates the UID2 token a publisher sends; implemented by
+// idmodules.UID2Validator
+type UID2Checker interface {
+	// Check returns the token to forward: the one sent, a refreshed one, or
+	// "" to drop it. An empty token records a request without one.
+	Check(ctx context.Context, token string) string
+}
+
+// SetUID2Checker enables UID2 token validation before bidders are called
+func (e *Exchange) SetUID2Checker(c UID2Checker) {
+	e.configMu.Lock()
+	defer e.configMu.Unlock()
+	e.uid2 = c
+}
+
+// SetBidderUID2 replaces the bidders UID2 tokens are forwarded to; every
+// other bidder gets requests without them. Until it is set, UID2 tokens go
+// to no bidder.
+func (e *Exchange) SetBidderUID2(bidders map[string]bool) {
+	e.configMu.Lock()
+	defer e.configMu.Unlock()
+	e.uid2Bidders = bidders
+}
+
+// uid2Allowed reports whether UID2 tokens may be forwarded to a bidder
+func (e *Exchange) uid2Allowed(bidderCode string) bool {
+	e.configMu.RLock()
+	defer e.configMu.RUnlock()
+	return e.uid2Bidders[bidderCode]
+}
+
+// uid2Check delivers the UID2 token to forward; a nil check was never
+// started and leaves the request as sent
+type uid2Check chan string
+
+// startUID2Check starts validating the request's UID2 token when a checker
+// is set. Requests without a token are only counted.
+func (e *Exchange) startUID2Check(ctx context.Context, req *openrtb.BidRequest) uid2Check {
+	e.configMu.RLock()
+	checker := e.uid2
+	e.configMu.RUnlock()
+	if checker == nil {
+		return nil
+	}
+
+	token := uid2Token(req.User)
+	if token == "" {
+		checker.Check(ctx, "")
+		return nil
+	}
+	result := make(uid2Check, 1)
+	go func() {
+		result <- checker.Check(ctx, token)
+	}()
+	return result
+}
+
+// apply waits for the check and puts the token to forward in user.eids,
+// moving a token sent in the legacy user.ext.eids there too. A dropped
+// token is removed from both.
+func (c uid2Check) apply(req *openrtb.BidRequest) {
+	if c == nil {
+		return
+	}
+	token := <-c
+	sent := uid2Token(req.User)
+	if token == sent && !hasExtUID2(req.User) {
+		return
+	}
+
+	eid, ok := uid2EID(req.User)
+	if !ok {
+		eid = openrtb.EID{Source: idmodules.UID2Source, UIDs: []openrtb.UID{{AType: idmodules.ATypePerson}}}
+	}
+
+	stripUID2(req)
+	if token == "" {
+		return
+	}
+	uids := append([]openrtb.UID(nil), eid.UIDs...)
+	uids[0].ID = token
+	eid.UIDs = uids
+	user := *req.User
+	user.EIDs = append(append([]openrtb.EID(nil), user.EIDs...), eid)
+	req.User = &user
+}
+
+// uid2EID returns the first UID2 EID with a token in user.eids, or failing
+// that in the legacy user.ext.eids
+func uid2EID(user *openrtb.User) (openrtb.EID, bool) {
+	if user == nil {
+		return openrtb.EID{}, false
+	}
+	for _, eids := range [][]openrtb.EID{user.EIDs, extEIDs(user.Ext)} {
+		for _, eid := range eids {
+			if eid.Source == idmodules.UID2Source && len(eid.UIDs) > 0 && eid.UIDs[0].ID != "" {
+				return eid, true
+			}
+		}
+	}
+	return openrtb.EID{}, false
+}
+
+// uid2Token returns the request's UID2 token, "" when it has none
+func uid2Token(user *openrtb.User) string {
+	eid, _ := uid2EID(user)
+	if len(eid.UIDs) == 0 {
+		return ""
+	}
+	return eid.UIDs[0].ID
+}
+
+// extEIDs parses the legacy user.ext.eids
+func extEIDs(ext json.RawMessage) []openrtb.EID {
+	if len(ext) == 0 {
+		return nil
+	}
+	var parsed struct {
+		EIDs []openrtb.EID `json:"eids"`
+	}
+	if err := json.Unmarshal(ext, &parsed); err != nil {
+		return nil
+	}
+	return parsed.EIDs
+}
+
+// hasExtUID2 reports whether user.ext.eids carries a UID2 EID
+func hasExtUID2(user *openrtb.User) bool {
+	if user == nil {
+		return false
+	}
+	for _, eid := range extEIDs(user.Ext) {
+		if eid.Source == idmodules.UID2Source {
+			return true
+		}
+	}
+	return false
+}
+
+// stripUID2 removes UID2 EIDs from user.eids and user.ext.eids, keeping
+// every other ID. User is copied before modification.
+func stripUID2(req *openrtb.BidRequest) {
+	if req.User == nil {
+		return
+	}
+	user := *req.User
+	changed := false
+
+	for i, eid := range user.EIDs {
+		if eid.Source != idmodules.UID2Source {
+			continue
+		}
+		kept := make([]openrtb.EID, 0, len(user.EIDs)-1)
+		kept = append(kept, user.EIDs[:i]...)
+		for _, rest := range user.EIDs[i+1:] {
+			if rest.Source != idmodules.UID2Source {
+				kept = append(kept, rest)
+			}
+		}
+		user.EIDs = kept
+		changed = true
+		break
+	}
+
+	if hasExtUID2(&user) {
+		if ext, ok := stripExtUID2(user.Ext); ok {
+			user.Ext = ext
+			changed = true
+		}
+	}
+
+	if changed {
+		req.User = &user
+	}
+}
+
+// stripExtUID2 removes UID2 EIDs from user.ext.eids, preserving other ext
+// keys and EIDs
+func stripExtUID2(ext json.RawMessage) (json.RawMessage, bool) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(ext, &fields); err != nil {
+		return ext, false
+	}
+	var eids []json.RawMessage
+	if err := json.Unmarshal(fields["eids"], &eids); err != nil {
+		return ext, false
+	}
+	kept := make([]json.RawMessage, 0, len(eids))
+	for _, raw := range eids {
+		var eid struct {
+			Source string `json:"source"`
+		}
+		if json.Unmarshal(raw, &eid) == nil && eid.Source == idmodules.UID2Source {
+			continue
+		}
+		kept = append(kept, raw)
+	}
+	if len(kept) == 0 {
+		delete(fields, "eids")
+	} else {
+		encoded, err := json.Marshal(kept)
+		if err != nil {
+			return ext, false
+		}
+		fields["eids"] = encoded
+	}
+	stripped, err := json.Marshal(fields)
+	if err != nil {
+		return ext, false
+	}
+	return stripped, true
+}
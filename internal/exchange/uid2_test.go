@@ -0,0 +1,138 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/internal/testfixtures"
+)
+
+// mockUID2Checker refreshes "stale", drops "expired" and keeps the rest
+type mockUID2Checker struct {
+	mu     sync.Mutex
+	tokens []string
+}
+
+func (m *mockUID2Checker) Check(ctx context.Context, token string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tokens = append(m.tokens, token)
+	switch token {
+	case "stale":
+		return "fresh"
+	case "expired":
+		return ""
+	}
+	return token
+}
+
+func TestRunAuction_UID2Passthrough(t *testing.T) {
+	registry := adapters.NewRegistry()
+	enabled, other := &requestCapturingAdapter{}, &requestCapturingAdapter{}
+	if err := registry.Register("uid2bidder", enabled, adapters.BidderInfo{Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Register("other", other, adapters.BidderInfo{Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+	ex := New(registry, &Config{DefaultTimeout: 100 * time.Millisecond})
+	checker := &mockUID2Checker{}
+	ex.SetUID2Checker(checker)
+	ex.SetBidderUID2(map[string]bool{"uid2bidder": true})
+
+	// A stale token in the legacy user.ext.eids, next to a RampID
+	req := testfixtures.Request("uid2-req").App("com.example.ctv", "pub1").User("user-1", "").
+		Device(rokuUA, "192.0.2.1", 3).Imp(testfixtures.Video("imp1")).Build()
+	req.User.EIDs = []openrtb.EID{{Source: "liveramp.com", UIDs: []openrtb.UID{{ID: "ramp-1", AType: 3}}}}
+	req.User.Ext = json.RawMessage(`{"eids":[{"source":"uidapi.com","uids":[{"id":"stale","atype":3}]}],"segments":["a"]}`)
+	if _, err := ex.RunAuction(context.Background(), &AuctionRequest{BidRequest: req}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(checker.tokens) != 1 || checker.tokens[0] != "stale" {
+		t.Fatalf("expected the stale token checked, got %v", checker.tokens)
+	}
+	if enabled.lastRequest == nil || other.lastRequest == nil {
+		t.Fatal("expected both bidders called")
+	}
+	if token := uid2Token(enabled.lastRequest.User); token != "fresh" || hasExtUID2(enabled.lastRequest.User) {
+		t.Errorf("expected the refreshed token in user.eids for the UID2 bidder, got %+v", enabled.lastRequest.User)
+	}
+	if token := uid2Token(other.lastRequest.User); token != "" {
+		t.Errorf("expected no UID2 token for the other bidder, got %q", token)
+	}
+	if len(other.lastRequest.User.EIDs) != 1 || other.lastRequest.User.EIDs[0].Source != "liveramp.com" {
+		t.Errorf("expected the other bidder to keep the RampID, got %+v", other.lastRequest.User.EIDs)
+	}
+	if string(other.lastRequest.User.Ext) != `{"segments":["a"]}` {
+		t.Errorf("expected other user.ext keys kept, got %s", other.lastRequest.User.Ext)
+	}
+
+	// An expired token goes to nobody; a request without one is only counted
+	expired := testfixtures.Request("uid2-expired").App("com.example.ctv", "pub1").
+		Device(rokuUA, "192.0.2.1", 3).Imp(testfixtures.Video("imp1")).Build()
+	expired.User = &openrtb.User{EIDs: []openrtb.EID{{Source: "uidapi.com", UIDs: []openrtb.UID{{ID: "expired"}}}}}
+	if _, err := ex.RunAuction(context.Background(), &AuctionRequest{BidRequest: expired}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token := uid2Token(enabled.lastRequest.User); token != "" {
+		t.Errorf("expected the expired token dropped, got %q", token)
+	}
+	none := testfixtures.Request("uid2-none").App("com.example.ctv", "pub1").
+		Device(rokuUA, "192.0.2.1", 3).Imp(testfixtures.Video("imp1")).Build()
+	if _, err := ex.RunAuction(context.Background(), &AuctionRequest{BidRequest: none}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(checker.tokens) != 3 || checker.tokens[2] != "" {
+		t.Errorf("expected a request without a token counted, got %v", checker.tokens)
+	}
+}
+
+func TestUID2Allowed(t *testing.T) {
+	ex := New(adapters.NewRegistry(), &Config{DefaultTimeout: 100 * time.Millisecond})
+	if ex.uid2Allowed("any") {
+		t.Error("expected UID2 withheld from every bidder until the list is loaded")
+	}
+	ex.SetBidderUID2(map[string]bool{"uid2bidder": true})
+	if ex.uid2Allowed("any") {
+		t.Error("expected UID2 withheld from bidders not flagged uid2_enabled")
+	}
+	if !ex.uid2Allowed("uid2bidder") {
+		t.Error("expected UID2 forwarded to bidders flagged uid2_enabled")
+	}
+}
+
+func TestStripUID2(t *testing.T) {
+	user := &openrtb.User{
+		EIDs: []openrtb.EID{
+			{Source: "uidapi.com", UIDs: []openrtb.UID{{ID: "uid2"}}},
+			{Source: "id5-sync.com", UIDs: []openrtb.UID{{ID: "id5"}}},
+		},
+		Ext: json.RawMessage(`{"eids":[{"source":"uidapi.com","uids":[{"id":"uid2"}]},{"source":"criteo.com","uids":[{"id":"c"}]}]}`),
+	}
+	req := &openrtb.BidRequest{User: user}
+	stripUID2(req)
+
+	if len(req.User.EIDs) != 1 || req.User.EIDs[0].Source != "id5-sync.com" {
+		t.Errorf("expected only the ID5 EID kept, got %+v", req.User.EIDs)
+	}
+	if eids := extEIDs(req.User.Ext); len(eids) != 1 || eids[0].Source != "criteo.com" {
+		t.Errorf("expected only the Criteo ext EID kept, got %s", req.User.Ext)
+	}
+	if len(user.EIDs) != 2 || !hasExtUID2(user) {
+		t.Error("expected the caller's user left untouched")
+	}
+
+	// Nothing to strip: the user is shared as is
+	plain := &openrtb.User{ID: "u1"}
+	req = &openrtb.BidRequest{User: plain}
+	stripUID2(req)
+	if req.User != plain {
+		t.Error("expected a user without UID2 left as is")
+	}
+}
@@ -3,6 +3,10 @@ package exchange
 import (
 	"fmt"
 	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/thenexusengine/tne_springwire/internal/ctv"
@@ -24,6 +28,11 @@ func NewVASTResponseBuilder(trackingBaseURL string) *VASTResponseBuilder {
 	}
 }
 
+// Version returns the VAST version the builder produces
+func (b *VASTResponseBuilder) Version() string {
+	return b.version
+}
+
 // BuildVASTFromAuction creates a VAST response from an auction response
 func (b *VASTResponseBuilder) BuildVASTFromAuction(bidReq *openrtb.BidRequest, auctionResp *AuctionResponse) (*vast.VAST, error) {
 	if auctionResp == nil || auctionResp.BidResponse == nil || len(auctionResp.BidResponse.SeatBid) == 0 {
@@ -32,27 +41,50 @@ func (b *VASTResponseBuilder) BuildVASTFromAuction(bidReq *openrtb.BidRequest, a
 
 	builder := vast.NewBuilder(b.version)
 
+	// For ad pods, only each slot's winning bid is served, tagged with its sequence
+	podFill := BuildPodFill(bidReq, auctionResp.BidResponse)
+	var podSequence map[string]int
+	if podFill != nil {
+		podSequence = make(map[string]int, len(podFill.Slots))
+		for _, slot := range podFill.Slots {
+			if slot.Filled {
+				podSequence[slot.BidID] = slot.Sequence
+			}
+		}
+	}
+
+	// Carry the edge request ID into beacons so events join back to the auction
+	trackingSuffix := ""
+	if auctionResp.RequestID != "" {
+		trackingSuffix = "&request_id=" + url.QueryEscape(auctionResp.RequestID)
+	}
+
 	for _, seatBid := range auctionResp.BidResponse.SeatBid {
 		for _, bid := range seatBid.Bid {
-			// Extract video impression
+			// Extract video or audio impression
 			imp := findImpression(bidReq.Imp, bid.ImpID)
-			if imp == nil || imp.Video == nil {
+			if imp == nil || (imp.Video == nil && imp.Audio == nil) {
 				continue
 			}
 
+			sequence := 0
+			if podSequence != nil {
+				seq, ok := podSequence[bid.ID]
+				if !ok {
+					continue // Lost the slot to a higher bid
+				}
+				sequence = seq
+			}
+
 			// Build ad
 			builder.AddAd(bid.ID).
+				WithSequence(sequence).
 				WithInLine("TNEVideo", bid.AdID).
-				WithImpression(fmt.Sprintf("%s/video/impression?bid_id=%s&bidder=%s", b.trackingBaseURL, bid.ID, seatBid.Seat)).
-				WithError(fmt.Sprintf("%s/video/error?bid_id=%s&bidder=%s", b.trackingBaseURL, bid.ID, seatBid.Seat))
+				WithImpression(fmt.Sprintf("%s/video/impression?bid_id=%s&bidder=%s%s", b.trackingBaseURL, bid.ID, seatBid.Seat, trackingSuffix)).
+				WithError(fmt.Sprintf("%s/video/error?bid_id=%s&bidder=%s%s", b.trackingBaseURL, bid.ID, seatBid.Seat, trackingSuffix))
 
-			// Add linear creative
-			duration := time.Duration(imp.Video.MaxDuration) * time.Second
-			if duration == 0 {
-				duration = 30 * time.Second
-			}
-
-			linearBuilder := builder.WithLinearCreative(bid.ID+"-creative", duration)
+			// Carry the buyer's Open Measurement verification scripts
+			builder.WithVerification(bidVerifications(&bid)...)
 
 			// Add media file from NURL or ADM
 			mediaURL := bid.NURL
@@ -60,6 +92,35 @@ func (b *VASTResponseBuilder) BuildVASTFromAuction(bidReq *openrtb.BidRequest, a
 				mediaURL = bid.AdM
 			}
 
+			// Audio-only impressions get an audio media file without dimensions
+			if imp.Video == nil {
+				duration := time.Duration(imp.Audio.MaxDuration) * time.Second
+				if duration == 0 {
+					duration = 30 * time.Second
+				}
+				mimeType := vast.DefaultAudioMIMEType
+				if len(imp.Audio.Mimes) > 0 {
+					mimeType = imp.Audio.Mimes[0]
+				}
+				builder.WithLinearCreative(bid.ID+"-creative", duration).
+					WithAudioMediaFile(mediaURL, mimeType, vast.WithBitrate(imp.Audio.MaxBitrate)).
+					WithAllQuartileTracking(fmt.Sprintf("%s/video/event?bid_id=%s&bidder=%s%s", b.trackingBaseURL, bid.ID, seatBid.Seat, trackingSuffix)).
+					EndLinear().Done()
+				continue
+			}
+
+			// Add linear creative, as long as the bid says it runs so pods
+			// stitch to their real length
+			duration := time.Duration(imp.Video.MaxDuration) * time.Second
+			if bid.Dur > 0 {
+				duration = time.Duration(bid.Dur) * time.Second
+			}
+			if duration == 0 {
+				duration = 30 * time.Second
+			}
+
+			linearBuilder := builder.WithLinearCreative(bid.ID+"-creative", duration)
+
 			// Determine video format
 			mimeType := "video/mp4"
 			if len(imp.Video.Mimes) > 0 {
@@ -75,13 +136,13 @@ func (b *VASTResponseBuilder) BuildVASTFromAuction(bidReq *openrtb.BidRequest, a
 			)
 
 			// Add tracking events
-			linearBuilder.WithAllQuartileTracking(fmt.Sprintf("%s/video/event?bid_id=%s&bidder=%s", b.trackingBaseURL, bid.ID, seatBid.Seat))
+			linearBuilder.WithAllQuartileTracking(fmt.Sprintf("%s/video/event?bid_id=%s&bidder=%s%s", b.trackingBaseURL, bid.ID, seatBid.Seat, trackingSuffix))
 
 			// Add skip offset for skippable ads
 			if imp.Video.Skip != nil && *imp.Video.Skip == 1 {
 				offset := "00:00:05"
 				if imp.Video.SkipAfter > 0 {
-					offset = fmt.Sprintf("00:00:%02d", imp.Video.SkipAfter)
+					offset = vast.FormatDuration(time.Duration(imp.Video.SkipAfter) * time.Second)
 				}
 				linearBuilder.WithSkipOffset(offset)
 			}
@@ -90,7 +151,50 @@ func (b *VASTResponseBuilder) BuildVASTFromAuction(bidReq *openrtb.BidRequest, a
 		}
 	}
 
-	return builder.Build()
+	v, err := builder.Build()
+	if err != nil {
+		return nil, err
+	}
+	if podSequence != nil {
+		sort.SliceStable(v.Ads, func(i, j int) bool { return v.Ads[i].Sequence < v.Ads[j].Sequence })
+	}
+	return v, nil
+}
+
+// Pod fill headers on VAST responses, mirroring the pod object in BidResponse.ext
+const (
+	HeaderPodRequested = "X-Pod-Requested"
+	HeaderPodFilled    = "X-Pod-Filled"
+	HeaderPodFillRate  = "X-Pod-Fill-Rate"
+	HeaderPodUnfilled  = "X-Pod-Unfilled" // Comma-separated sequences of unfilled slots
+	HeaderPodTotal     = "X-Pod-Total-Price"
+	HeaderPodAvgCPM    = "X-Pod-Avg-CPM"
+)
+
+// SetPodFillHeaders reports pod fill on a VAST response so SSAI callers can
+// choose slate or collapse for unfilled slots. No-op for non-pod requests.
+func SetPodFillHeaders(w http.ResponseWriter, fill *PodFill) {
+	if fill == nil {
+		return
+	}
+	h := w.Header()
+	h.Set(HeaderPodRequested, strconv.Itoa(fill.Requested))
+	h.Set(HeaderPodFilled, strconv.Itoa(fill.Filled))
+	h.Set(HeaderPodFillRate, strconv.FormatFloat(fill.FillRate, 'f', 2, 64))
+	if fill.Filled > 0 {
+		h.Set(HeaderPodTotal, strconv.FormatFloat(fill.TotalPrice, 'f', -1, 64))
+		h.Set(HeaderPodAvgCPM, strconv.FormatFloat(fill.AvgCPM, 'f', -1, 64))
+	}
+
+	unfilled := fill.Unfilled()
+	if len(unfilled) == 0 {
+		return
+	}
+	seqs := make([]string, len(unfilled))
+	for i, slot := range unfilled {
+		seqs[i] = strconv.Itoa(slot.Sequence)
+	}
+	h.Set(HeaderPodUnfilled, strings.Join(seqs, ","))
 }
 
 // findImpression finds an impression by ID
@@ -166,6 +270,7 @@ func (h *VASTHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/xml")
 	w.Header().Set("Access-Control-Allow-Origin", "*")
+	SetPodFillHeaders(w, BuildPodFill(bidReq, auctionResp.BidResponse))
 	w.Write(data)
 }
 
@@ -0,0 +1,252 @@
+// Package floors applies publishers' price floor rules (the floor_rules
+// table) to impressions by media type, size, country and device.
+package floors
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+// DefaultInterval reloads floor rules every minute, which bounds how long an
+// edit takes to reach every instance
+const DefaultInterval = time.Minute
+
+// Device types a rule can match (floor_rules.device_type)
+const (
+	DeviceMobile  = "mobile"
+	DeviceDesktop = "desktop"
+	DeviceCTV     = "ctv"
+)
+
+// Store loads floor rules; implemented by storage.FloorRuleStore
+type Store interface {
+	LoadActive(ctx context.Context) ([]*storage.FloorRule, error)
+}
+
+// Config controls how often floor rules are reloaded
+type Config struct {
+	Interval time.Duration // 0 uses DefaultInterval
+}
+
+// Engine holds every publisher's active floor rules. It implements
+// exchange.FloorSource.
+type Engine struct {
+	store Store
+	cfg   Config
+
+	mu    sync.RWMutex
+	byPub map[string][]*storage.FloorRule
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewEngine creates an engine for store. No floor is applied until Start
+// loads the rules.
+func NewEngine(store Store, cfg Config) *Engine {
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultInterval
+	}
+	return &Engine{
+		store:  store,
+		cfg:    cfg,
+		byPub:  make(map[string][]*storage.FloorRule),
+		stopCh: make(chan struct{}),
+	}
+}
+
+// Start loads floor rules, then reloads them every interval until Stop
+func (e *Engine) Start() {
+	e.reloadLogged()
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		ticker := time.NewTicker(e.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-e.stopCh:
+				return
+			case <-ticker.C:
+				e.reloadLogged()
+			}
+		}
+	}()
+}
+
+// Stop ends periodic reloads
+func (e *Engine) Stop() {
+	e.stopOnce.Do(func() { close(e.stopCh) })
+	e.wg.Wait()
+}
+
+// reloadLogged runs one reload, logging failures
+func (e *Engine) reloadLogged() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := e.Reload(ctx); err != nil {
+		logger.Log.Warn().Err(err).Msg("Floor rule reload failed, keeping the previous rules")
+	}
+}
+
+// Reload replaces the floor rules with the store's active ones. On error the
+// previous rules are kept.
+func (e *Engine) Reload(ctx context.Context) error {
+	rules, err := e.store.LoadActive(ctx)
+	if err != nil {
+		return err
+	}
+	byPub := make(map[string][]*storage.FloorRule)
+	for _, r := range rules {
+		byPub[r.PublisherID] = append(byPub[r.PublisherID], r)
+	}
+
+	e.mu.Lock()
+	e.byPub = byPub
+	e.mu.Unlock()
+	return nil
+}
+
+// Floor implements exchange.FloorSource. It returns the floor CPM and ID of
+// the most specific of the publisher's rules matching imp, the higher floor
+// breaking ties between equally specific rules.
+func (e *Engine) Floor(publisherID string, req *openrtb.BidRequest, imp *openrtb.Imp) (float64, string, bool) {
+	e.mu.RLock()
+	rules := e.byPub[publisherID]
+	e.mu.RUnlock()
+	if len(rules) == 0 {
+		return 0, "", false
+	}
+
+	country := requestCountry(req)
+	device := deviceClass(req)
+	sizes := impSizes(imp)
+
+	var best *storage.FloorRule
+	bestSpecificity := -1
+	for _, r := range rules {
+		if !matches(r, imp, sizes, country, device) {
+			continue
+		}
+		s := specificity(r)
+		if s > bestSpecificity || (s == bestSpecificity && r.FloorCPM > best.FloorCPM) {
+			best, bestSpecificity = r, s
+		}
+	}
+	if best == nil {
+		return 0, "", false
+	}
+	return best.FloorCPM, best.ID, true
+}
+
+// matches reports whether every dimension the rule sets matches the
+// impression
+func matches(r *storage.FloorRule, imp *openrtb.Imp, sizes []string, country, device string) bool {
+	if r.MediaType != "" && !hasMediaType(imp, r.MediaType) {
+		return false
+	}
+	if r.Size != "" && !containsFold(sizes, r.Size) {
+		return false
+	}
+	if r.Country != "" && !strings.EqualFold(strings.TrimSpace(r.Country), country) {
+		return false
+	}
+	if r.DeviceType != "" && r.DeviceType != device {
+		return false
+	}
+	return true
+}
+
+// specificity counts the dimensions a rule sets
+func specificity(r *storage.FloorRule) int {
+	n := 0
+	for _, d := range []string{r.MediaType, r.Size, r.Country, r.DeviceType} {
+		if d != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// hasMediaType reports whether imp offers the media type
+func hasMediaType(imp *openrtb.Imp, mediaType string) bool {
+	switch mediaType {
+	case "banner":
+		return imp.Banner != nil
+	case "video":
+		return imp.Video != nil
+	case "native":
+		return imp.Native != nil
+	case "audio":
+		return imp.Audio != nil
+	}
+	return false
+}
+
+// impSizes returns the impression's WxH sizes: the banner's size and
+// formats, and the video player size
+func impSizes(imp *openrtb.Imp) []string {
+	var sizes []string
+	add := func(w, h int) {
+		if w > 0 && h > 0 {
+			sizes = append(sizes, fmt.Sprintf("%dx%d", w, h))
+		}
+	}
+	if imp.Banner != nil {
+		add(imp.Banner.W, imp.Banner.H)
+		for _, f := range imp.Banner.Format {
+			add(f.W, f.H)
+		}
+	}
+	if imp.Video != nil {
+		add(imp.Video.W, imp.Video.H)
+	}
+	return sizes
+}
+
+func containsFold(values []string, want string) bool {
+	want = strings.TrimSpace(want)
+	for _, v := range values {
+		if strings.EqualFold(v, want) {
+			return true
+		}
+	}
+	return false
+}
+
+// requestCountry returns the upper-cased device.geo country, falling back
+// to user.geo
+func requestCountry(req *openrtb.BidRequest) string {
+	if req.Device != nil && req.Device.Geo != nil && req.Device.Geo.Country != "" {
+		return strings.ToUpper(strings.TrimSpace(req.Device.Geo.Country))
+	}
+	if req.User != nil && req.User.Geo != nil {
+		return strings.ToUpper(strings.TrimSpace(req.User.Geo.Country))
+	}
+	return ""
+}
+
+// deviceClass maps OpenRTB device.devicetype to a rule device type, or ""
+// when it is unknown
+func deviceClass(req *openrtb.BidRequest) string {
+	if req.Device == nil {
+		return ""
+	}
+	switch req.Device.DeviceType {
+	case 1, 4, 5: // mobile/tablet, phone, tablet
+		return DeviceMobile
+	case 2: // personal computer
+		return DeviceDesktop
+	case 3, 7: // connected TV, set top box
+		return DeviceCTV
+	}
+	return ""
+}
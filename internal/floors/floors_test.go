@@ -0,0 +1,88 @@
+package floors
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+)
+
+type mockStore struct {
+	rules []*storage.FloorRule
+	err   error
+}
+
+func (m *mockStore) LoadActive(ctx context.Context) ([]*storage.FloorRule, error) {
+	return m.rules, m.err
+}
+
+func newTestEngine(t *testing.T, rules ...*storage.FloorRule) *Engine {
+	t.Helper()
+	e := NewEngine(&mockStore{rules: rules}, Config{})
+	if err := e.Reload(context.Background()); err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	return e
+}
+
+func TestEngine_Floor(t *testing.T) {
+	e := newTestEngine(t,
+		&storage.FloorRule{ID: "pub-default", PublisherID: "pub-1", FloorCPM: 0.5},
+		&storage.FloorRule{ID: "video", PublisherID: "pub-1", MediaType: "video", FloorCPM: 5},
+		&storage.FloorRule{ID: "ctv-usa", PublisherID: "pub-1", MediaType: "video", Country: "usa", DeviceType: DeviceCTV, FloorCPM: 12},
+		&storage.FloorRule{ID: "mrec", PublisherID: "pub-1", MediaType: "banner", Size: "300x250", FloorCPM: 1.2},
+		&storage.FloorRule{ID: "mrec-high", PublisherID: "pub-1", MediaType: "banner", Size: "300X250", FloorCPM: 1.5},
+		&storage.FloorRule{ID: "other", PublisherID: "pub-2", FloorCPM: 9},
+	)
+
+	ctvUSA := &openrtb.BidRequest{Device: &openrtb.Device{DeviceType: 3, Geo: &openrtb.Geo{Country: "USA"}}}
+	mobileDEU := &openrtb.BidRequest{Device: &openrtb.Device{DeviceType: 4}, User: &openrtb.User{Geo: &openrtb.Geo{Country: "deu"}}}
+	video := &openrtb.Imp{ID: "v", Video: &openrtb.Video{W: 1920, H: 1080}}
+	mrec := &openrtb.Imp{ID: "b", Banner: &openrtb.Banner{Format: []openrtb.Format{{W: 728, H: 90}, {W: 300, H: 250}}}}
+	leaderboard := &openrtb.Imp{ID: "b", Banner: &openrtb.Banner{W: 728, H: 90}}
+
+	tests := []struct {
+		name      string
+		publisher string
+		req       *openrtb.BidRequest
+		imp       *openrtb.Imp
+		wantRule  string
+		wantFloor float64
+	}{
+		{"most specific rule", "pub-1", ctvUSA, video, "ctv-usa", 12},
+		{"country mismatch falls back", "pub-1", mobileDEU, video, "video", 5},
+		{"higher floor breaks ties", "pub-1", mobileDEU, mrec, "mrec-high", 1.5},
+		{"catch-all rule", "pub-1", mobileDEU, leaderboard, "pub-default", 0.5},
+		{"other publisher", "pub-2", ctvUSA, video, "other", 9},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			floor, rule, ok := e.Floor(tt.publisher, tt.req, tt.imp)
+			if !ok || rule != tt.wantRule || floor != tt.wantFloor {
+				t.Errorf("expected %s at %.2f, got %s at %.2f (ok=%v)", tt.wantRule, tt.wantFloor, rule, floor, ok)
+			}
+		})
+	}
+
+	if _, _, ok := e.Floor("pub-3", ctvUSA, video); ok {
+		t.Error("expected no floor for a publisher without rules")
+	}
+}
+
+func TestEngine_ReloadErrorKeepsRules(t *testing.T) {
+	store := &mockStore{rules: []*storage.FloorRule{{ID: "r", PublisherID: "pub-1", FloorCPM: 1}}}
+	e := NewEngine(store, Config{})
+	if err := e.Reload(context.Background()); err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+
+	store.err = errors.New("connection refused")
+	if err := e.Reload(context.Background()); err == nil {
+		t.Fatal("expected reload error")
+	}
+	if _, _, ok := e.Floor("pub-1", &openrtb.BidRequest{}, &openrtb.Imp{ID: "1"}); !ok {
+		t.Error("expected the previous rules to be kept")
+	}
+}
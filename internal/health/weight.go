@@ -0,0 +1,294 @@
+// Package health scores how well an instance is serving auctions as a
+// 0–100 weight for upstream load balancers. Envoy or HAProxy weighting
+// instances by it moves traffic off one that is getting slow, failing
+// requests or running degraded, before it fails readiness and drops out of
+// rotation altogether.
+//
+// The weight is 100 scaled down by three factors:
+//
+//   - latency: the share of auction requests slower than the target. Up to
+//     5% slow (the p95 is within the target) costs nothing; the factor
+//     then falls linearly to 0 at half the requests slow.
+//   - errors: the share of auction requests answered with a 5xx. Up to 1%
+//     costs nothing; the factor falls linearly to 0 at 25%.
+//   - degradation: 1 when dependencies are healthy, 0.75 when minor ones
+//     are degraded, 0.4 when major ones are and 0 when the instance must
+//     not serve at all (e.g. a standby that isn't active).
+//
+// A serving instance never scores below 1, so it keeps a trickle of traffic
+// to show it has recovered.
+package health
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+// Defaults
+const (
+	DefaultWindow        = time.Minute
+	DefaultLatencyTarget = 500 * time.Millisecond
+)
+
+// Scoring thresholds
+const (
+	slowBudget  = 0.05 // slow share costing nothing (p95 within target)
+	slowMax     = 0.50 // slow share scoring 0
+	errorBudget = 0.01 // error share costing nothing
+	errorMax    = 0.25 // error share scoring 0
+	minRequests = 20   // fewer requests in the window score as healthy
+	minWeight   = 1    // lowest weight of an instance that may serve
+	maxWeight   = 100
+)
+
+// Level is how degraded the instance's dependencies are
+type Level int
+
+// Degradation levels
+const (
+	LevelNone    Level = iota // every dependency healthy
+	LevelMinor                // optional dependencies degraded, e.g. Redis over its error budget
+	LevelMajor                // auctions visibly affected, e.g. most bidder circuits open
+	LevelOffline              // must not serve, e.g. an inactive standby
+)
+
+// String returns the level's name
+func (l Level) String() string {
+	switch l {
+	case LevelNone:
+		return "none"
+	case LevelMinor:
+		return "minor"
+	case LevelMajor:
+		return "major"
+	default:
+		return "offline"
+	}
+}
+
+// factor is the weight multiplier of a level
+func (l Level) factor() float64 {
+	switch l {
+	case LevelNone:
+		return 1
+	case LevelMinor:
+		return 0.75
+	case LevelMajor:
+		return 0.4
+	default:
+		return 0
+	}
+}
+
+// DegradationFunc reports the current degradation level and its reasons
+type DegradationFunc func() (Level, []string)
+
+// Config controls scoring
+type Config struct {
+	Window        time.Duration // How far back requests count (0 = 1m)
+	LatencyTarget time.Duration // Requests slower than this are slow (0 = 500ms)
+}
+
+// bucket counts one second of requests
+type bucket struct {
+	second int64
+	total  int64
+	slow   int64
+	errors int64
+}
+
+// Scorer counts auction requests in one-second buckets and computes the
+// serving weight
+type Scorer struct {
+	cfg         Config
+	degradation DegradationFunc
+
+	mu      sync.Mutex
+	buckets []bucket
+
+	now func() time.Time
+}
+
+// NewScorer creates a scorer; degradation may be nil
+func NewScorer(cfg Config, degradation DegradationFunc) *Scorer {
+	if cfg.Window <= 0 {
+		cfg.Window = DefaultWindow
+	}
+	if cfg.LatencyTarget <= 0 {
+		cfg.LatencyTarget = DefaultLatencyTarget
+	}
+	n := int(cfg.Window / time.Second)
+	if n < 1 {
+		n = 1
+	}
+	return &Scorer{
+		cfg:         cfg,
+		degradation: degradation,
+		buckets:     make([]bucket, n),
+		now:         time.Now,
+	}
+}
+
+// Record counts one auction request
+func (s *Scorer) Record(latency time.Duration, failed bool) {
+	second := s.now().Unix()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := &s.buckets[second%int64(len(s.buckets))]
+	if b.second != second {
+		*b = bucket{second: second}
+	}
+	b.total++
+	if latency > s.cfg.LatencyTarget {
+		b.slow++
+	}
+	if failed {
+		b.errors++
+	}
+}
+
+// Score is the serving weight and what it was computed from
+type Score struct {
+	Weight      int      `json:"weight"`
+	Requests    int64    `json:"requests"`    // Auction requests in the window
+	SlowRate    float64  `json:"slow_rate"`   // Share slower than the latency target
+	ErrorRate   float64  `json:"error_rate"`  // Share answered with a 5xx
+	Degradation string   `json:"degradation"` // none, minor, major or offline
+	Reasons     []string `json:"reasons,omitempty"`
+	TargetMs    int64    `json:"latency_target_ms"`
+	WindowSecs  int64    `json:"window_seconds"`
+}
+
+// Score computes the current serving weight
+func (s *Scorer) Score() Score {
+	second := s.now().Unix()
+	oldest := second - int64(len(s.buckets)) + 1
+
+	var total, slow, errors int64
+	s.mu.Lock()
+	for _, b := range s.buckets {
+		if b.second >= oldest && b.second <= second {
+			total += b.total
+			slow += b.slow
+			errors += b.errors
+		}
+	}
+	s.mu.Unlock()
+
+	score := Score{
+		Requests:    total,
+		Degradation: LevelNone.String(),
+		TargetMs:    s.cfg.LatencyTarget.Milliseconds(),
+		WindowSecs:  int64(s.cfg.Window / time.Second),
+	}
+	latencyFactor, errorFactor := 1.0, 1.0
+	if total > 0 {
+		score.SlowRate = round(float64(slow) / float64(total))
+		score.ErrorRate = round(float64(errors) / float64(total))
+	}
+	if total >= minRequests {
+		latencyFactor = falloff(float64(slow)/float64(total), slowBudget, slowMax)
+		errorFactor = falloff(float64(errors)/float64(total), errorBudget, errorMax)
+	}
+
+	level := LevelNone
+	if s.degradation != nil {
+		level, score.Reasons = s.degradation()
+		score.Degradation = level.String()
+	}
+	if level >= LevelOffline {
+		return score
+	}
+
+	weight := int(math.Round(maxWeight * latencyFactor * errorFactor * level.factor()))
+	if weight < minWeight {
+		weight = minWeight
+	}
+	score.Weight = weight
+	return score
+}
+
+// falloff is 1 up to budget, falling linearly to 0 at max
+func falloff(rate, budget, max float64) float64 {
+	switch {
+	case rate <= budget:
+		return 1
+	case rate >= max:
+		return 0
+	default:
+		return 1 - (rate-budget)/(max-budget)
+	}
+}
+
+// round keeps four decimals
+func round(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
+
+// auctionPaths are the requests the weight is scored on
+var auctionPaths = map[string]bool{
+	"/openrtb2/auction": true,
+	"/video/vast":       true,
+	"/video/openrtb":    true,
+}
+
+// statusRecorder captures the response status
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.status = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// Middleware records the latency and outcome of auction requests
+func (s *Scorer) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !auctionPaths[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		s.Record(time.Since(start), rec.status >= 500)
+	})
+}
+
+// Handler serves the weight: as JSON, or as "NN%" for agents that set
+// weights from plain text with ?format=text (e.g. HAProxy agent checks
+// through a small relay). The status is 200 while the instance may serve
+// and 503 when it scores 0, so it also works as a plain health check.
+func (s *Scorer) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		score := s.Score()
+		status := http.StatusOK
+		if score.Weight == 0 {
+			status = http.StatusServiceUnavailable
+		}
+		w.Header().Set("Cache-Control", "no-store")
+
+		if strings.EqualFold(r.URL.Query().Get("format"), "text") {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			w.WriteHeader(status)
+			fmt.Fprintf(w, "%d%%\n", score.Weight)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		if err := json.NewEncoder(w).Encode(score); err != nil {
+			logger.Log.Error().Err(err).Msg("failed to encode health weight response")
+		}
+	})
+}
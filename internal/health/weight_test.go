@@ -0,0 +1,140 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newTestScorer returns a scorer on a clock the test moves
+func newTestScorer(degradation DegradationFunc) (*Scorer, *time.Time) {
+	now := time.Unix(1_700_000_000, 0)
+	s := NewScorer(Config{Window: 10 * time.Second, LatencyTarget: 100 * time.Millisecond}, degradation)
+	s.now = func() time.Time { return now }
+	return s, &now
+}
+
+// record counts n requests of one kind
+func record(s *Scorer, n int, latency time.Duration, failed bool) {
+	for i := 0; i < n; i++ {
+		s.Record(latency, failed)
+	}
+}
+
+func TestScorer_Weight(t *testing.T) {
+	tests := []struct {
+		name              string
+		fast, slow, fails int
+		level             Level
+		want              int
+	}{
+		{"no traffic", 0, 0, 0, LevelNone, 100},
+		{"too few requests to judge", 5, 10, 0, LevelNone, 100},
+		{"healthy", 100, 0, 0, LevelNone, 100},
+		{"p95 within target", 95, 5, 0, LevelNone, 100},
+		{"a fifth slow", 80, 20, 0, LevelNone, 67},
+		{"half slow", 50, 50, 0, LevelNone, 1},
+		{"errors within budget", 99, 0, 1, LevelNone, 100},
+		{"a tenth failing", 90, 0, 10, LevelNone, 63},
+		{"minor degradation", 100, 0, 0, LevelMinor, 75},
+		{"major degradation", 100, 0, 0, LevelMajor, 40},
+		{"slow and degraded", 80, 20, 0, LevelMajor, 27},
+		{"offline", 100, 0, 0, LevelOffline, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestScorer(func() (Level, []string) { return tt.level, nil })
+			record(s, tt.fast, 10*time.Millisecond, false)
+			record(s, tt.slow, 200*time.Millisecond, false)
+			record(s, tt.fails, 10*time.Millisecond, true)
+			if got := s.Score().Weight; got != tt.want {
+				t.Errorf("expected weight %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestScorer_Window(t *testing.T) {
+	s, now := newTestScorer(nil)
+	record(s, 100, 200*time.Millisecond, false)
+	if score := s.Score(); score.Weight != 1 || score.Requests != 100 || score.SlowRate != 1 {
+		t.Fatalf("expected an all-slow window, got %+v", score)
+	}
+
+	*now = now.Add(5 * time.Second)
+	record(s, 100, 10*time.Millisecond, false)
+	if score := s.Score(); score.Requests != 200 || score.SlowRate != 0.5 {
+		t.Errorf("expected both seconds counted, got %+v", score)
+	}
+
+	// The slow second leaves the window
+	*now = now.Add(6 * time.Second)
+	if score := s.Score(); score.Weight != 100 || score.Requests != 100 {
+		t.Errorf("expected only the fast second counted, got %+v", score)
+	}
+	*now = now.Add(time.Minute)
+	if score := s.Score(); score.Requests != 0 {
+		t.Errorf("expected an empty window, got %+v", score)
+	}
+}
+
+func TestScorer_Handler(t *testing.T) {
+	level := LevelMinor
+	s, _ := newTestScorer(func() (Level, []string) { return level, []string{"redis"} })
+
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health/weight", nil))
+	var score Score
+	if err := json.NewDecoder(rec.Body).Decode(&score); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusOK || score.Weight != 75 || score.Degradation != "minor" || len(score.Reasons) != 1 || score.TargetMs != 100 || score.WindowSecs != 10 {
+		t.Errorf("unexpected response %d %+v", rec.Code, score)
+	}
+	if rec.Header().Get("Cache-Control") != "no-store" {
+		t.Error("expected the weight not cached")
+	}
+
+	rec = httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health/weight?format=text", nil))
+	if rec.Body.String() != "75%\n" {
+		t.Errorf("expected a plain text weight, got %q", rec.Body.String())
+	}
+
+	level = LevelOffline
+	rec = httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health/weight?format=text", nil))
+	if rec.Code != http.StatusServiceUnavailable || rec.Body.String() != "0%\n" {
+		t.Errorf("expected 503 and 0%% offline, got %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestScorer_Middleware(t *testing.T) {
+	s, _ := newTestScorer(nil)
+	status := http.StatusOK
+	handler := s.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	serve := func(path string) {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, path, nil))
+	}
+
+	serve("/openrtb2/auction")
+	serve("/video/vast")
+	serve("/health/ready")
+	serve("/admin/publishers")
+	status = http.StatusBadRequest
+	serve("/openrtb2/auction")
+	status = http.StatusServiceUnavailable
+	serve("/video/openrtb")
+
+	score := s.Score()
+	if score.Requests != 4 {
+		t.Errorf("expected only auction requests counted, got %d", score.Requests)
+	}
+	if score.ErrorRate != 0.25 {
+		t.Errorf("expected only the 5xx counted as an error, got %v", score.ErrorRate)
+	}
+}
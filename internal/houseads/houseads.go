@@ -0,0 +1,233 @@
+// Package houseads serves publishers' house ads (static banner markup or
+// VAST from the stored_creatives table) for impressions an auction left
+// unfilled, with per-user frequency caps.
+package houseads
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+	"github.com/thenexusengine/tne_springwire/pkg/lru"
+)
+
+// DefaultInterval reloads house ads every minute, which bounds how long an
+// edit takes to reach every instance
+const DefaultInterval = time.Minute
+
+// Media types a house ad can fill (stored_creatives.media_type)
+const (
+	MediaTypeBanner = "banner"
+	MediaTypeVideo  = "video"
+)
+
+// Frequency cap tracker bounds; users past them are evicted least recently
+// seen first, which only ever lets a house ad show more often
+const (
+	capMaxUsers = 200000
+	capMaxBytes = 32 << 20
+)
+
+// Store loads house ads; implemented by storage.StoredCreativeStore
+type Store interface {
+	LoadActive(ctx context.Context) ([]*storage.StoredCreative, error)
+}
+
+// Config controls how often house ads are reloaded
+type Config struct {
+	Interval time.Duration // 0 uses DefaultInterval
+}
+
+// Library holds every publisher's active house ads and the impressions each
+// user has seen of them. It implements exchange.HouseAdSource.
+type Library struct {
+	store Store
+	cfg   Config
+
+	mu    sync.RWMutex
+	byPub map[string][]*storage.StoredCreative
+
+	// seen holds impression times per house ad and user for frequency caps
+	seen *lru.Cache[string, []time.Time]
+	now  func() time.Time
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewLibrary creates a library for store. No house ad is served until Start
+// loads them.
+func NewLibrary(store Store, cfg Config) *Library {
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultInterval
+	}
+	return &Library{
+		store: store,
+		cfg:   cfg,
+		byPub: make(map[string][]*storage.StoredCreative),
+		seen: lru.New(lru.Config{
+			Name:       "house_ad_caps",
+			MaxEntries: capMaxUsers,
+			MaxBytes:   capMaxBytes,
+		}, func(key string, imps []time.Time) int64 {
+			return int64(len(key)+24*cap(imps)) + 64
+		}),
+		now:    time.Now,
+		stopCh: make(chan struct{}),
+	}
+}
+
+// Start loads house ads, then reloads them every interval until Stop
+func (l *Library) Start() {
+	l.reloadLogged()
+	l.wg.Add(1)
+	go func() {
+		defer l.wg.Done()
+		ticker := time.NewTicker(l.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-l.stopCh:
+				return
+			case <-ticker.C:
+				l.reloadLogged()
+			}
+		}
+	}()
+}
+
+// Stop ends periodic reloads
+func (l *Library) Stop() {
+	l.stopOnce.Do(func() { close(l.stopCh) })
+	l.wg.Wait()
+}
+
+// reloadLogged runs one reload, logging failures
+func (l *Library) reloadLogged() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := l.Reload(ctx); err != nil {
+		logger.Log.Warn().Err(err).Msg("House ad reload failed, keeping the previous set")
+	}
+}
+
+// Reload replaces the house ads with the store's active ones. On error the
+// previous set is kept.
+func (l *Library) Reload(ctx context.Context) error {
+	creatives, err := l.store.LoadActive(ctx)
+	if err != nil {
+		return err
+	}
+	byPub := make(map[string][]*storage.StoredCreative)
+	for _, c := range creatives {
+		byPub[c.PublisherID] = append(byPub[c.PublisherID], c)
+	}
+
+	l.mu.Lock()
+	l.byPub = byPub
+	l.mu.Unlock()
+	return nil
+}
+
+// HouseBid implements exchange.HouseAdSource. It returns a bid carrying the
+// publisher's first house ad that fits imp and that userKey hasn't reached
+// the frequency cap of, and counts the impression against the cap. An empty
+// userKey leaves capped house ads uncapped.
+func (l *Library) HouseBid(publisherID string, imp *openrtb.Imp, userKey string) (*openrtb.Bid, string, bool) {
+	l.mu.RLock()
+	candidates := l.byPub[publisherID]
+	l.mu.RUnlock()
+
+	for _, c := range candidates {
+		w, h, ok := fits(c, imp)
+		if !ok || !l.allow(c, userKey) {
+			continue
+		}
+		bid := &openrtb.Bid{
+			ImpID: imp.ID,
+			AdM:   c.Markup,
+			AdID:  c.ID,
+			CRID:  c.ID,
+			W:     w,
+			H:     h,
+			Dur:   c.Duration,
+		}
+		if c.ADomain != "" {
+			for _, d := range strings.Split(c.ADomain, ",") {
+				if d = strings.TrimSpace(d); d != "" {
+					bid.ADomain = append(bid.ADomain, d)
+				}
+			}
+		}
+		return bid, c.MediaType, true
+	}
+	return nil, "", false
+}
+
+// fits reports whether the house ad can fill imp, and the size to serve it at
+func fits(c *storage.StoredCreative, imp *openrtb.Imp) (w, h int, ok bool) {
+	switch c.MediaType {
+	case MediaTypeVideo:
+		if imp.Video == nil {
+			return 0, 0, false
+		}
+		if imp.Video.MaxDuration > 0 && c.Duration > imp.Video.MaxDuration {
+			return 0, 0, false
+		}
+		return imp.Video.W, imp.Video.H, true
+	case MediaTypeBanner:
+		if imp.Banner == nil {
+			return 0, 0, false
+		}
+		// Unsized house ads fill any banner at its first size
+		if c.W == 0 || c.H == 0 {
+			if imp.Banner.W > 0 && imp.Banner.H > 0 {
+				return imp.Banner.W, imp.Banner.H, true
+			}
+			if len(imp.Banner.Format) > 0 {
+				return imp.Banner.Format[0].W, imp.Banner.Format[0].H, true
+			}
+			return 0, 0, true
+		}
+		if imp.Banner.W == c.W && imp.Banner.H == c.H {
+			return c.W, c.H, true
+		}
+		for _, f := range imp.Banner.Format {
+			if f.W == c.W && f.H == c.H {
+				return c.W, c.H, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// allow checks the house ad's frequency cap for userKey and, when it allows
+// another impression, records it
+func (l *Library) allow(c *storage.StoredCreative, userKey string) bool {
+	if c.MaxImpressions <= 0 || c.CapWindowSeconds <= 0 || userKey == "" {
+		return true
+	}
+
+	now := l.now()
+	cutoff := now.Add(-time.Duration(c.CapWindowSeconds) * time.Second)
+	allowed := false
+	l.seen.Update(c.ID+"\x00"+userKey, func(imps []time.Time, _ bool) []time.Time {
+		recent := imps[:0:0]
+		for _, t := range imps {
+			if t.After(cutoff) {
+				recent = append(recent, t)
+			}
+		}
+		if len(recent) >= c.MaxImpressions {
+			return recent
+		}
+		allowed = true
+		return append(recent, now)
+	})
+	return allowed
+}
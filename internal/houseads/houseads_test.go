@@ -0,0 +1,103 @@
+package houseads
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+)
+
+type mockStore struct {
+	creatives []*storage.StoredCreative
+	err       error
+}
+
+func (m *mockStore) LoadActive(ctx context.Context) ([]*storage.StoredCreative, error) {
+	return m.creatives, m.err
+}
+
+func newTestLibrary(t *testing.T, creatives ...*storage.StoredCreative) *Library {
+	t.Helper()
+	l := NewLibrary(&mockStore{creatives: creatives}, Config{})
+	if err := l.Reload(context.Background()); err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	return l
+}
+
+func TestLibrary_HouseBid_MediaTypeAndSize(t *testing.T) {
+	l := newTestLibrary(t,
+		&storage.StoredCreative{ID: "vast", PublisherID: "pub-1", MediaType: MediaTypeVideo, Markup: "<VAST/>", Duration: 30, ADomain: "pub1.example, house.example"},
+		&storage.StoredCreative{ID: "mrec", PublisherID: "pub-1", MediaType: MediaTypeBanner, Markup: "<div/>", W: 300, H: 250},
+		&storage.StoredCreative{ID: "other", PublisherID: "pub-2", MediaType: MediaTypeBanner, Markup: "<div/>"},
+	)
+
+	video := &openrtb.Imp{ID: "v", Video: &openrtb.Video{W: 1920, H: 1080, MaxDuration: 30}}
+	bid, mediaType, ok := l.HouseBid("pub-1", video, "")
+	if !ok || mediaType != MediaTypeVideo || bid.CRID != "vast" || bid.ImpID != "v" || bid.Dur != 30 || bid.W != 1920 || len(bid.ADomain) != 2 {
+		t.Fatalf("expected the VAST house ad, got %+v", bid)
+	}
+	if _, _, ok := l.HouseBid("pub-1", &openrtb.Imp{ID: "v", Video: &openrtb.Video{MaxDuration: 15}}, ""); ok {
+		t.Error("expected a house ad longer than maxduration to be skipped")
+	}
+
+	banner := &openrtb.Imp{ID: "b", Banner: &openrtb.Banner{Format: []openrtb.Format{{W: 728, H: 90}, {W: 300, H: 250}}}}
+	if bid, _, ok := l.HouseBid("pub-1", banner, ""); !ok || bid.CRID != "mrec" || bid.W != 300 {
+		t.Errorf("expected the 300x250 house ad, got %+v", bid)
+	}
+	if _, _, ok := l.HouseBid("pub-1", &openrtb.Imp{ID: "b", Banner: &openrtb.Banner{W: 728, H: 90}}, ""); ok {
+		t.Error("expected no house ad for a size the publisher has none of")
+	}
+	if bid, _, ok := l.HouseBid("pub-2", &openrtb.Imp{ID: "b", Banner: &openrtb.Banner{W: 728, H: 90}}, ""); !ok || bid.W != 728 {
+		t.Errorf("expected an unsized house ad to fill any banner, got %+v", bid)
+	}
+	if _, _, ok := l.HouseBid("pub-3", banner, ""); ok {
+		t.Error("expected no house ad for a publisher without any")
+	}
+}
+
+func TestLibrary_HouseBid_FrequencyCap(t *testing.T) {
+	l := newTestLibrary(t,
+		&storage.StoredCreative{ID: "capped", PublisherID: "pub-1", MediaType: MediaTypeBanner, Markup: "<div/>", MaxImpressions: 2, CapWindowSeconds: 3600},
+		&storage.StoredCreative{ID: "fallback", PublisherID: "pub-1", MediaType: MediaTypeBanner, Markup: "<p/>"},
+	)
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { return now }
+	imp := &openrtb.Imp{ID: "b", Banner: &openrtb.Banner{W: 300, H: 250}}
+
+	for i := 0; i < 2; i++ {
+		if bid, _, _ := l.HouseBid("pub-1", imp, "user-1"); bid.CRID != "capped" {
+			t.Fatalf("impression %d: expected the capped house ad, got %s", i+1, bid.CRID)
+		}
+	}
+	if bid, _, _ := l.HouseBid("pub-1", imp, "user-1"); bid.CRID != "fallback" {
+		t.Errorf("expected the next house ad once capped, got %s", bid.CRID)
+	}
+	if bid, _, _ := l.HouseBid("pub-1", imp, "user-2"); bid.CRID != "capped" {
+		t.Errorf("expected caps to be per user, got %s", bid.CRID)
+	}
+
+	now = now.Add(time.Hour + time.Second)
+	if bid, _, _ := l.HouseBid("pub-1", imp, "user-1"); bid.CRID != "capped" {
+		t.Errorf("expected the cap to reset after its window, got %s", bid.CRID)
+	}
+}
+
+func TestLibrary_ReloadErrorKeepsHouseAds(t *testing.T) {
+	store := &mockStore{creatives: []*storage.StoredCreative{{ID: "h", PublisherID: "pub-1", MediaType: MediaTypeBanner, Markup: "<div/>"}}}
+	l := NewLibrary(store, Config{})
+	if err := l.Reload(context.Background()); err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+
+	store.err = errors.New("connection refused")
+	if err := l.Reload(context.Background()); err == nil {
+		t.Fatal("expected reload error")
+	}
+	if _, _, ok := l.HouseBid("pub-1", &openrtb.Imp{ID: "b", Banner: &openrtb.Banner{W: 300, H: 250}}, ""); !ok {
+		t.Error("expected the previous house ads to be kept")
+	}
+}
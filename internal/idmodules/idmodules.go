@@ -0,0 +1,406 @@
+// Package idmodules enriches bid requests with the viewer's IDs from
+// identity providers. Each configured module (the IDR service, UID2, RampID
+// or a custom HTTP identity service) is asked alongside the auction, with its own timeout,
+// circuit breaker and publisher list, and the IDs it returns are added to
+// user.eids under the module's source. A failed, slow or open-circuit
+// module only ever means bidders see fewer IDs.
+package idmodules
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/pkg/idr"
+)
+
+// Module types
+const (
+	TypeUID2   = "uid2"   // Unified ID 2.0 resolver
+	TypeRampID = "rampid" // LiveRamp RampID resolver
+	TypeHTTP   = "http"   // any service speaking the lookup contract
+	TypeIDR    = "idr"    // the IDR service's identity endpoint
+)
+
+// IDRSource is the EID source of IDs from the IDR service
+const IDRSource = "thenexusengine.com"
+
+// idrIdentityPath is the IDR service's identity endpoint, under the same
+// base URL and internal API key as partner selection
+const idrIdentityPath = "/internal/identity"
+
+// Lookup results reported to Metrics
+const (
+	ResultResolved    = "resolved"     // the module returned IDs
+	ResultNotFound    = "not_found"    // the module doesn't know the viewer
+	ResultError       = "error"        // the call failed or timed out
+	ResultCircuitOpen = "circuit_open" // skipped while the module's circuit is open
+)
+
+// Agent types of EID uids (AdCOM)
+const (
+	ATypeDevice = 1 // device or cookie ID
+	ATypePerson = 3 // person-based ID, e.g. from a hashed email
+)
+
+// providerDefaults are the EID source and agent type of each provider type
+var providerDefaults = map[string]struct {
+	source string
+	atype  int
+}{
+	TypeUID2:   {UID2Source, ATypePerson},
+	TypeRampID: {"liveramp.com", ATypePerson},
+	TypeHTTP:   {"", ATypeDevice},
+	TypeIDR:    {IDRSource, ATypeDevice},
+}
+
+// Default module limits
+const (
+	DefaultTimeout          = 50 * time.Millisecond
+	DefaultFailureThreshold = 5
+	DefaultCooldown         = 30 * time.Second
+)
+
+// maxResponseBytes bounds a module's answer
+const maxResponseBytes = 64 * 1024
+
+// Metrics records lookups by module and result
+type Metrics interface {
+	RecordIDModuleLookup(module, result string)
+}
+
+// Config defines one module, as an entry of the ID_MODULES_FILE JSON array
+type Config struct {
+	Name             string   `json:"name"`
+	Type             string   `json:"type"`                        // uid2, rampid, http or idr
+	URL              string   `json:"url"`                         // Lookup endpoint (the service's base URL for idr)
+	APIKey           string   `json:"api_key,omitempty"`           // Sent as a bearer token (idr: internal API key) when set
+	Source           string   `json:"source,omitempty"`            // EID source ("" = the provider's; required for http)
+	AType            int      `json:"atype,omitempty"`             // Agent type of returned IDs (0 = the provider's)
+	GVLVendorID      int      `json:"gvl_id,omitempty"`            // Under GDPR, the TCF vendor that must be allowed
+	TimeoutMS        int      `json:"timeout_ms,omitempty"`        // Longest a lookup may take (0 = 50ms)
+	FailureThreshold int      `json:"failure_threshold,omitempty"` // Consecutive failures that open the circuit (0 = 5)
+	CooldownSeconds  int      `json:"cooldown_seconds,omitempty"`  // How long the circuit stays open (0 = 30s)
+	Publishers       []string `json:"publishers,omitempty"`        // Publishers the module runs for (empty = all)
+}
+
+// IDRConfig returns the module asking the IDR service at baseURL for the
+// viewer's IDs
+func IDRConfig(baseURL, apiKey string) Config {
+	return Config{Name: TypeIDR, Type: TypeIDR, URL: baseURL, APIKey: apiKey}
+}
+
+// HasType reports whether any module is of a type
+func HasType(configs []Config, moduleType string) bool {
+	for _, cfg := range configs {
+		if cfg.Type == moduleType {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadFile reads module definitions from a JSON file
+func LoadFile(path string) ([]Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ID module definitions: %w", err)
+	}
+	var configs []Config
+	if err := json.Unmarshal(data, &configs); err != nil {
+		return nil, fmt.Errorf("failed to parse ID module definitions %s: %w", path, err)
+	}
+	if err := ValidateConfigs(configs); err != nil {
+		return nil, err
+	}
+	return configs, nil
+}
+
+// ValidateConfigs checks module definitions
+func ValidateConfigs(configs []Config) error {
+	seen := make(map[string]bool, len(configs))
+	for i, cfg := range configs {
+		if cfg.Name == "" || cfg.URL == "" {
+			return fmt.Errorf("ID module %d needs a name and url", i)
+		}
+		if seen[cfg.Name] {
+			return fmt.Errorf("ID module %q is defined twice", cfg.Name)
+		}
+		seen[cfg.Name] = true
+		if _, ok := providerDefaults[cfg.Type]; !ok {
+			return fmt.Errorf("ID module %q type must be %q, %q, %q or %q, got %q", cfg.Name, TypeUID2, TypeRampID, TypeHTTP, TypeIDR, cfg.Type)
+		}
+		if cfg.Type == TypeHTTP && cfg.Source == "" {
+			return fmt.Errorf("ID module %q needs an EID source", cfg.Name)
+		}
+		if cfg.TimeoutMS < 0 || cfg.FailureThreshold < 0 || cfg.CooldownSeconds < 0 || cfg.AType < 0 {
+			return fmt.Errorf("ID module %q limits must not be negative", cfg.Name)
+		}
+	}
+	return nil
+}
+
+// Lookup is what modules are told about the viewer. Only identifiers the
+// request already carries are sent.
+type Lookup struct {
+	PublisherID string `json:"publisher_id,omitempty"`
+	IP          string `json:"ip,omitempty"`
+	UA          string `json:"ua,omitempty"`
+	IFA         string `json:"ifa,omitempty"`
+	UserID      string `json:"user_id,omitempty"`
+	BuyerUID    string `json:"buyeruid,omitempty"`
+
+	// VendorAllowed reports whether a TCF vendor may process the viewer's
+	// data; nil when GDPR doesn't apply
+	VendorAllowed func(gvlID int) bool `json:"-"`
+}
+
+// NewLookup collects the viewer's identifiers from a request
+func NewLookup(publisherID string, req *openrtb.BidRequest) Lookup {
+	lookup := Lookup{PublisherID: publisherID}
+	if req == nil {
+		return lookup
+	}
+	if d := req.Device; d != nil {
+		lookup.IP = d.IP
+		if lookup.IP == "" {
+			lookup.IP = d.IPv6
+		}
+		lookup.UA = d.UA
+		lookup.IFA = d.IFA
+	}
+	if u := req.User; u != nil {
+		lookup.UserID = u.ID
+		lookup.BuyerUID = u.BuyerUID
+	}
+	return lookup
+}
+
+// empty reports whether the lookup has nothing to identify the viewer by
+func (l Lookup) empty() bool {
+	return l.IP == "" && l.IFA == "" && l.UserID == "" && l.BuyerUID == ""
+}
+
+// response is a module's answer
+type response struct {
+	UIDs []openrtb.UID `json:"uids"`
+}
+
+// Module asks one identity provider for the viewer's IDs
+type Module struct {
+	cfg        Config
+	endpoint   string
+	source     string
+	atype      int
+	timeout    time.Duration
+	publishers map[string]bool
+	http       *http.Client
+	breaker    *idr.CircuitBreaker
+	metrics    Metrics
+}
+
+// newModule creates a module from a validated config
+func newModule(cfg Config, metrics Metrics) *Module {
+	defaults := providerDefaults[cfg.Type]
+	m := &Module{
+		cfg:      cfg,
+		endpoint: cfg.URL,
+		source:   cfg.Source,
+		atype:    cfg.AType,
+		timeout:  time.Duration(cfg.TimeoutMS) * time.Millisecond,
+		metrics:  metrics,
+	}
+	if cfg.Type == TypeIDR {
+		m.endpoint = strings.TrimRight(cfg.URL, "/") + idrIdentityPath
+	}
+	if m.source == "" {
+		m.source = defaults.source
+	}
+	if m.atype == 0 {
+		m.atype = defaults.atype
+	}
+	if m.timeout <= 0 {
+		m.timeout = DefaultTimeout
+	}
+	if len(cfg.Publishers) > 0 {
+		m.publishers = make(map[string]bool, len(cfg.Publishers))
+		for _, pub := range cfg.Publishers {
+			m.publishers[strings.TrimSpace(pub)] = true
+		}
+	}
+
+	breaker := idr.DefaultCircuitBreakerConfig()
+	if cfg.FailureThreshold > 0 {
+		breaker.FailureThreshold = cfg.FailureThreshold
+	}
+	breaker.Timeout = DefaultCooldown
+	if cfg.CooldownSeconds > 0 {
+		breaker.Timeout = time.Duration(cfg.CooldownSeconds) * time.Second
+	}
+	m.breaker = idr.NewCircuitBreaker(breaker)
+	m.http = &http.Client{Timeout: m.timeout}
+	return m
+}
+
+// Name returns the module's configured name
+func (m *Module) Name() string {
+	return m.cfg.Name
+}
+
+// enabledFor reports whether the module runs for a publisher
+func (m *Module) enabledFor(publisherID string) bool {
+	return m.publishers == nil || m.publishers[publisherID]
+}
+
+// Resolve asks the provider for the viewer's IDs. It returns nil when the
+// provider doesn't know the viewer, the call failed or the circuit is open.
+func (m *Module) Resolve(ctx context.Context, lookup Lookup) *openrtb.EID {
+	var uids []openrtb.UID
+	err := m.breaker.Execute(func() error {
+		ctx, cancel := context.WithTimeout(ctx, m.timeout)
+		defer cancel()
+		var err error
+		uids, err = m.call(ctx, lookup)
+		return err
+	})
+	switch {
+	case errors.Is(err, idr.ErrCircuitOpen):
+		m.record(ResultCircuitOpen)
+		return nil
+	case err != nil:
+		m.record(ResultError)
+		return nil
+	case len(uids) == 0:
+		m.record(ResultNotFound)
+		return nil
+	}
+	m.record(ResultResolved)
+
+	eid := &openrtb.EID{Source: m.source, UIDs: make([]openrtb.UID, 0, len(uids))}
+	for _, uid := range uids {
+		if uid.ID == "" {
+			continue
+		}
+		if uid.AType == 0 {
+			uid.AType = m.atype
+		}
+		eid.UIDs = append(eid.UIDs, uid)
+	}
+	if len(eid.UIDs) == 0 {
+		return nil
+	}
+	return eid
+}
+
+// call sends one lookup. A 404 is a valid "not found" answer; any other
+// non-2xx status is an error.
+func (m *Module) call(ctx context.Context, lookup Lookup) ([]openrtb.UID, error) {
+	body, err := json.Marshal(lookup)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode ID module request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ID module request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	switch {
+	case m.cfg.APIKey == "":
+	case m.cfg.Type == TypeIDR:
+		req.Header.Set("X-Internal-API-Key", m.cfg.APIKey)
+	default:
+		req.Header.Set("Authorization", "Bearer "+m.cfg.APIKey)
+	}
+
+	resp, err := m.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("ID module %s request failed: %w", m.cfg.Name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusNoContent {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBytes))
+		return nil, nil
+	}
+	if resp.StatusCode/100 != 2 {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBytes))
+		return nil, fmt.Errorf("ID module %s returned status %d", m.cfg.Name, resp.StatusCode)
+	}
+
+	var answer response
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(&answer); err != nil {
+		return nil, fmt.Errorf("failed to decode ID module %s response: %w", m.cfg.Name, err)
+	}
+	return answer.UIDs, nil
+}
+
+func (m *Module) record(result string) {
+	if m.metrics != nil {
+		m.metrics.RecordIDModuleLookup(m.cfg.Name, result)
+	}
+}
+
+// Enricher runs the configured modules for a request. It implements
+// exchange.IdentityResolver.
+type Enricher struct {
+	modules []*Module
+}
+
+// New creates an enricher for validated module configs; metrics may be nil
+func New(configs []Config, metrics Metrics) *Enricher {
+	e := &Enricher{modules: make([]*Module, 0, len(configs))}
+	for _, cfg := range configs {
+		e.modules = append(e.modules, newModule(cfg, metrics))
+	}
+	return e
+}
+
+// Modules returns the configured modules
+func (e *Enricher) Modules() []*Module {
+	if e == nil {
+		return nil
+	}
+	return e.modules
+}
+
+// Resolve asks every module enabled for the publisher, concurrently, and
+// returns the EIDs they found in module order. It returns once every module
+// has answered or given up, so the slowest module timeout bounds the wait.
+func (e *Enricher) Resolve(ctx context.Context, lookup Lookup) []openrtb.EID {
+	if e == nil || len(e.modules) == 0 || lookup.empty() {
+		return nil
+	}
+
+	found := make([]*openrtb.EID, len(e.modules))
+	var wg sync.WaitGroup
+	for i, m := range e.modules {
+		if !m.enabledFor(lookup.PublisherID) {
+			continue
+		}
+		if lookup.VendorAllowed != nil && m.cfg.GVLVendorID > 0 && !lookup.VendorAllowed(m.cfg.GVLVendorID) {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, m *Module) {
+			defer wg.Done()
+			found[i] = m.Resolve(ctx, lookup)
+		}(i, m)
+	}
+	wg.Wait()
+
+	var eids []openrtb.EID
+	for _, eid := range found {
+		if eid != nil {
+			eids = append(eids, *eid)
+		}
+	}
+	return eids
+}
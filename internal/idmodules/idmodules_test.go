@@ -0,0 +1,280 @@
+package idmodules
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+type mockMetrics struct {
+	mu      sync.Mutex
+	results map[string]int
+}
+
+func (m *mockMetrics) RecordIDModuleLookup(module, result string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.results == nil {
+		m.results = make(map[string]int)
+	}
+	m.results[module+"/"+result]++
+}
+
+func (m *mockMetrics) count(key string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.results[key]
+}
+
+// idServer knows one viewer, 192.0.2.1
+type idServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests int
+	auth     string
+	status   int
+	delay    time.Duration
+}
+
+func newIDServer(t *testing.T, uid string) *idServer {
+	s := &idServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests++
+		s.auth = r.Header.Get("Authorization")
+		status, delay := s.status, s.delay
+		s.mu.Unlock()
+		time.Sleep(delay)
+		if status != 0 {
+			w.WriteHeader(status)
+			return
+		}
+		var lookup Lookup
+		json.NewDecoder(r.Body).Decode(&lookup)
+		if lookup.IP != "192.0.2.1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"uids":[{"id":"` + uid + `"}]}`))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *idServer) set(status int, delay time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status, s.delay = status, delay
+}
+
+func (s *idServer) calls() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+var viewer = Lookup{PublisherID: "pub1", IP: "192.0.2.1", UA: "Roku"}
+
+func TestEnricher_Resolve(t *testing.T) {
+	uid2 := newIDServer(t, "uid2-token")
+	ramp := newIDServer(t, "ramp-1")
+	custom := newIDServer(t, "custom-1")
+	metrics := &mockMetrics{}
+	e := New([]Config{
+		{Name: "uid2", Type: TypeUID2, URL: uid2.URL, APIKey: "secret"},
+		{Name: "ramp", Type: TypeRampID, URL: ramp.URL},
+		{Name: "custom", Type: TypeHTTP, URL: custom.URL, Source: "ids.example.com"},
+	}, metrics)
+
+	eids := e.Resolve(context.Background(), viewer)
+	if len(eids) != 3 {
+		t.Fatalf("expected three EIDs, got %+v", eids)
+	}
+	want := []struct {
+		source, id string
+		atype      int
+	}{
+		{"uidapi.com", "uid2-token", ATypePerson},
+		{"liveramp.com", "ramp-1", ATypePerson},
+		{"ids.example.com", "custom-1", ATypeDevice},
+	}
+	for i, w := range want {
+		if eids[i].Source != w.source || len(eids[i].UIDs) != 1 || eids[i].UIDs[0].ID != w.id || eids[i].UIDs[0].AType != w.atype {
+			t.Errorf("EID %d: expected %s %s atype %d, got %+v", i, w.source, w.id, w.atype, eids[i])
+		}
+	}
+	if uid2.auth != "Bearer secret" || ramp.auth != "" {
+		t.Errorf("expected the API key sent only where set, got %q and %q", uid2.auth, ramp.auth)
+	}
+	if metrics.count("uid2/"+ResultResolved) != 1 {
+		t.Errorf("expected a resolved lookup recorded, got %v", metrics.results)
+	}
+
+	// An unknown viewer resolves nothing
+	if eids := e.Resolve(context.Background(), Lookup{PublisherID: "pub1", IP: "192.0.2.9"}); len(eids) != 0 {
+		t.Errorf("expected no EIDs for an unknown viewer, got %+v", eids)
+	}
+	if metrics.count("ramp/"+ResultNotFound) != 1 {
+		t.Errorf("expected a not found lookup recorded, got %v", metrics.results)
+	}
+
+	// Nothing to identify the viewer by: no call at all
+	if eids := e.Resolve(context.Background(), Lookup{PublisherID: "pub1", UA: "Roku"}); len(eids) != 0 || uid2.calls() != 2 {
+		t.Errorf("expected no lookup without identifiers, got %+v after %d calls", eids, uid2.calls())
+	}
+}
+
+func TestEnricher_IDRModule(t *testing.T) {
+	var path, key string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, key = r.URL.Path, r.Header.Get("X-Internal-API-Key")
+		w.Write([]byte(`{"uids":[{"id":"idr-1"}]}`))
+	}))
+	defer server.Close()
+
+	configs := []Config{IDRConfig(server.URL+"/", "internal-key")}
+	if err := ValidateConfigs(configs); err != nil {
+		t.Fatalf("expected the IDR module valid, got %v", err)
+	}
+	eids := New(configs, nil).Resolve(context.Background(), viewer)
+	if len(eids) != 1 || eids[0].Source != IDRSource || eids[0].UIDs[0].ID != "idr-1" || eids[0].UIDs[0].AType != ATypeDevice {
+		t.Fatalf("expected the IDR EID, got %+v", eids)
+	}
+	if path != "/internal/identity" || key != "internal-key" {
+		t.Errorf("expected the identity endpoint with the internal API key, got %s with %q", path, key)
+	}
+	if !HasType(configs, TypeIDR) || HasType(configs, TypeUID2) {
+		t.Error("expected HasType to find only the IDR module")
+	}
+}
+
+func TestEnricher_SlowAndFailingModules(t *testing.T) {
+	fast := newIDServer(t, "fast-1")
+	slow := newIDServer(t, "slow-1")
+	slow.set(0, 200*time.Millisecond)
+	broken := newIDServer(t, "broken-1")
+	broken.set(http.StatusInternalServerError, 0)
+	metrics := &mockMetrics{}
+	e := New([]Config{
+		{Name: "slow", Type: TypeHTTP, URL: slow.URL, Source: "slow.example.com", TimeoutMS: 20},
+		{Name: "broken", Type: TypeHTTP, URL: broken.URL, Source: "broken.example.com"},
+		{Name: "fast", Type: TypeHTTP, URL: fast.URL, Source: "fast.example.com"},
+	}, metrics)
+
+	start := time.Now()
+	eids := e.Resolve(context.Background(), viewer)
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Errorf("expected the slow module abandoned at its timeout, waited %v", elapsed)
+	}
+	if len(eids) != 1 || eids[0].Source != "fast.example.com" {
+		t.Errorf("expected only the fast module's EID, got %+v", eids)
+	}
+	if metrics.count("slow/"+ResultError) != 1 || metrics.count("broken/"+ResultError) != 1 {
+		t.Errorf("expected errors recorded, got %v", metrics.results)
+	}
+}
+
+func TestModule_CircuitBreaker(t *testing.T) {
+	server := newIDServer(t, "uid2-token")
+	server.set(http.StatusServiceUnavailable, 0)
+	metrics := &mockMetrics{}
+	e := New([]Config{{Name: "uid2", Type: TypeUID2, URL: server.URL, FailureThreshold: 2, CooldownSeconds: 60}}, metrics)
+
+	for i := 0; i < 4; i++ {
+		e.Resolve(context.Background(), viewer)
+	}
+	if server.calls() != 2 {
+		t.Errorf("expected the circuit open after two failures, got %d calls", server.calls())
+	}
+	if metrics.count("uid2/"+ResultCircuitOpen) != 2 {
+		t.Errorf("expected skipped lookups recorded, got %v", metrics.results)
+	}
+
+	// The service recovering doesn't matter until the cooldown ends
+	server.set(0, 0)
+	if eids := e.Resolve(context.Background(), viewer); len(eids) != 0 {
+		t.Errorf("expected no EIDs while the circuit is open, got %+v", eids)
+	}
+}
+
+func TestEnricher_PublishersAndVendors(t *testing.T) {
+	uid2 := newIDServer(t, "uid2-token")
+	ramp := newIDServer(t, "ramp-1")
+	e := New([]Config{
+		{Name: "uid2", Type: TypeUID2, URL: uid2.URL, GVLVendorID: 21},
+		{Name: "ramp", Type: TypeRampID, URL: ramp.URL, Publishers: []string{"pub2"}},
+	}, nil)
+
+	if eids := e.Resolve(context.Background(), viewer); len(eids) != 1 || eids[0].Source != "uidapi.com" {
+		t.Errorf("expected only UID2 for pub1, got %+v", eids)
+	}
+	if ramp.calls() != 0 {
+		t.Error("expected RampID not called for pub1")
+	}
+
+	gdpr := viewer
+	gdpr.PublisherID = "pub2"
+	gdpr.VendorAllowed = func(gvlID int) bool { return gvlID != 21 }
+	if eids := e.Resolve(context.Background(), gdpr); len(eids) != 1 || eids[0].Source != "liveramp.com" {
+		t.Errorf("expected UID2 skipped without vendor consent, got %+v", eids)
+	}
+	if uid2.calls() != 1 {
+		t.Errorf("expected UID2 not called without vendor consent, got %d calls", uid2.calls())
+	}
+}
+
+func TestValidateConfigs(t *testing.T) {
+	tests := []struct {
+		name    string
+		configs []Config
+		wantErr bool
+	}{
+		{"valid", []Config{{Name: "uid2", Type: TypeUID2, URL: "http://uid2"}, {Name: "custom", Type: TypeHTTP, URL: "http://ids", Source: "ids.example.com"}}, false},
+		{"no name", []Config{{Type: TypeUID2, URL: "http://uid2"}}, true},
+		{"no url", []Config{{Name: "uid2", Type: TypeUID2}}, true},
+		{"duplicate", []Config{{Name: "uid2", Type: TypeUID2, URL: "http://a"}, {Name: "uid2", Type: TypeUID2, URL: "http://b"}}, true},
+		{"unknown type", []Config{{Name: "id5", Type: "id5", URL: "http://id5"}}, true},
+		{"http without source", []Config{{Name: "custom", Type: TypeHTTP, URL: "http://ids"}}, true},
+		{"negative timeout", []Config{{Name: "uid2", Type: TypeUID2, URL: "http://uid2", TimeoutMS: -1}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateConfigs(tt.configs); (err != nil) != tt.wantErr {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "modules.json")
+	data := `[{"name": "uid2", "type": "uid2", "url": "http://uid2", "gvl_id": 21, "timeout_ms": 40, "publishers": ["pub1"]}]`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	configs, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(configs) != 1 || configs[0].GVLVendorID != 21 || configs[0].TimeoutMS != 40 || configs[0].Publishers[0] != "pub1" {
+		t.Errorf("unexpected configs %+v", configs)
+	}
+
+	invalid := filepath.Join(dir, "invalid.json")
+	if err := os.WriteFile(invalid, []byte(`[{"name": "x", "type": "http", "url": "http://x"}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFile(invalid); err == nil {
+		t.Error("expected an invalid module rejected")
+	}
+	if _, err := LoadFile(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("expected a missing file rejected")
+	}
+}
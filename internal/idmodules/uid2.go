@@ -0,0 +1,203 @@
+package idmodules
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/pkg/idr"
+	"github.com/thenexusengine/tne_springwire/pkg/lru"
+)
+
+// UID2Source is the EID source of Unified ID 2.0 tokens
+const UID2Source = "uidapi.com"
+
+// UID2 token statuses reported to UID2Metrics
+const (
+	UID2Absent      = "absent"      // the request carries no UID2 token
+	UID2Unvalidated = "unvalidated" // forwarded as sent, no operator configured
+	UID2Valid       = "valid"       // the operator confirmed the token
+	UID2Refreshed   = "refreshed"   // the operator returned a newer token, forwarded instead
+	UID2Expired     = "expired"     // the operator reported the token expired; dropped
+	UID2Invalid     = "invalid"     // the operator couldn't decrypt the token; dropped
+	UID2Error       = "error"       // the operator call failed; forwarded as sent
+)
+
+// UID2 validation defaults
+const (
+	DefaultUID2Timeout = 50 * time.Millisecond
+	uid2CacheTTL       = 5 * time.Minute
+	uid2CacheEntries   = 50000
+	uid2CacheBytes     = 32 << 20
+)
+
+// UID2Metrics records UID2 tokens by status
+type UID2Metrics interface {
+	RecordUID2Token(status string)
+}
+
+// UID2Config controls UID2 token validation
+type UID2Config struct {
+	OperatorURL string        // Validation endpoint ("" = forward tokens unvalidated)
+	APIKey      string        // Sent as a bearer token when set
+	Timeout     time.Duration // Longest a validation may take (0 = 50ms)
+}
+
+// uid2Answer is the operator's verdict on a token
+type uid2Answer struct {
+	Status           string `json:"status"`                      // valid, refreshed, expired or invalid
+	AdvertisingToken string `json:"advertising_token,omitempty"` // the newer token when refreshed
+	ExpiresAt        int64  `json:"expires_at,omitempty"`        // Unix ms the token stops being valid
+}
+
+// uid2Verdict is a cached answer
+type uid2Verdict struct {
+	status  string
+	token   string
+	expires time.Time
+}
+
+// UID2Validator checks UID2 tokens publishers send in user.eids against the
+// UID2 operator, which decrypts them, before they reach bidders. Verdicts
+// are cached per token, so a viewer's token is checked once every few
+// minutes rather than on every auction. It implements exchange.UID2Checker.
+type UID2Validator struct {
+	cfg     UID2Config
+	http    *http.Client
+	breaker *idr.CircuitBreaker
+	cache   *lru.Cache[string, uid2Verdict]
+	metrics UID2Metrics
+
+	now func() time.Time
+}
+
+// NewUID2Validator creates a validator; metrics may be nil
+func NewUID2Validator(cfg UID2Config, metrics UID2Metrics) *UID2Validator {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultUID2Timeout
+	}
+	breaker := idr.DefaultCircuitBreakerConfig()
+	breaker.Timeout = DefaultCooldown
+	return &UID2Validator{
+		cfg:     cfg,
+		http:    &http.Client{Timeout: cfg.Timeout},
+		breaker: idr.NewCircuitBreaker(breaker),
+		cache: lru.New(lru.Config{
+			Name:       "uid2_tokens",
+			MaxEntries: uid2CacheEntries,
+			MaxBytes:   uid2CacheBytes,
+		}, func(token string, v uid2Verdict) int64 {
+			return int64(len(token)+len(v.token)) + 64
+		}),
+		metrics: metrics,
+		now:     time.Now,
+	}
+}
+
+// Validates reports whether tokens are checked against an operator
+func (v *UID2Validator) Validates() bool {
+	return v.cfg.OperatorURL != ""
+}
+
+// Check returns the token to forward to bidders: the one sent, a refreshed
+// one, or "" to drop an expired or invalid token. An empty token records a
+// request without one. A failed or open-circuit operator never costs the
+// token; it is forwarded as sent.
+func (v *UID2Validator) Check(ctx context.Context, token string) string {
+	if token == "" {
+		v.record(UID2Absent)
+		return ""
+	}
+	if !v.Validates() {
+		v.record(UID2Unvalidated)
+		return token
+	}
+
+	now := v.now()
+	if verdict, ok := v.cache.Get(token); ok && now.Before(verdict.expires) {
+		v.record(verdict.status)
+		return verdict.token
+	}
+
+	var answer *uid2Answer
+	err := v.breaker.Execute(func() error {
+		ctx, cancel := context.WithTimeout(ctx, v.cfg.Timeout)
+		defer cancel()
+		var err error
+		answer, err = v.call(ctx, token)
+		return err
+	})
+	if err != nil {
+		v.record(UID2Error)
+		return token
+	}
+
+	verdict := uid2Verdict{status: answer.Status, expires: now.Add(uid2CacheTTL)}
+	switch answer.Status {
+	case UID2Valid:
+		verdict.token = token
+	case UID2Refreshed:
+		verdict.token = answer.AdvertisingToken
+	}
+	if answer.ExpiresAt > 0 && verdict.token != "" {
+		if expires := time.UnixMilli(answer.ExpiresAt); expires.Before(verdict.expires) {
+			verdict.expires = expires
+		}
+	}
+	v.cache.Set(token, verdict)
+	v.record(verdict.status)
+	return verdict.token
+}
+
+// call asks the operator about one token. Unknown statuses and a refresh
+// without a token are errors, so the token is kept.
+func (v *UID2Validator) call(ctx context.Context, token string) (*uid2Answer, error) {
+	body, err := json.Marshal(map[string]string{"token": token})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode UID2 validation request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.cfg.OperatorURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create UID2 validation request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if v.cfg.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+v.cfg.APIKey)
+	}
+
+	resp, err := v.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("UID2 validation request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBytes))
+		return nil, fmt.Errorf("UID2 operator returned status %d", resp.StatusCode)
+	}
+
+	var answer uid2Answer
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(&answer); err != nil {
+		return nil, fmt.Errorf("failed to decode UID2 validation response: %w", err)
+	}
+	switch answer.Status {
+	case UID2Valid, UID2Expired, UID2Invalid:
+		return &answer, nil
+	case UID2Refreshed:
+		if answer.AdvertisingToken == "" {
+			return nil, errors.New("UID2 operator refreshed a token without returning it")
+		}
+		return &answer, nil
+	}
+	return nil, fmt.Errorf("UID2 operator returned unknown status %q", answer.Status)
+}
+
+func (v *UID2Validator) record(status string) {
+	if v.metrics != nil {
+		v.metrics.RecordUID2Token(status)
+	}
+}
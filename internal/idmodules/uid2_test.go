@@ -0,0 +1,163 @@
+package idmodules
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+type mockUID2Metrics struct {
+	mu       sync.Mutex
+	statuses map[string]int
+}
+
+func (m *mockUID2Metrics) RecordUID2Token(status string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.statuses == nil {
+		m.statuses = make(map[string]int)
+	}
+	m.statuses[status]++
+}
+
+func (m *mockUID2Metrics) count(status string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.statuses[status]
+}
+
+// newUID2Operator answers for the tokens it is given, counting calls
+func newUID2Operator(t *testing.T, answers map[string]string) (*httptest.Server, func() int) {
+	var mu sync.Mutex
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+		if r.Header.Get("Authorization") != "Bearer uid2-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var req struct {
+			Token string `json:"token"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		answer, ok := answers[req.Token]
+		if !ok {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(answer))
+	}))
+	t.Cleanup(server.Close)
+	return server, func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return calls
+	}
+}
+
+func TestUID2Validator_Check(t *testing.T) {
+	operator, calls := newUID2Operator(t, map[string]string{
+		"good":    `{"status": "valid"}`,
+		"stale":   `{"status": "refreshed", "advertising_token": "fresh"}`,
+		"old":     `{"status": "expired"}`,
+		"garbage": `{"status": "invalid"}`,
+		"odd":     `{"status": "maybe"}`,
+	})
+	metrics := &mockUID2Metrics{}
+	v := NewUID2Validator(UID2Config{OperatorURL: operator.URL, APIKey: "uid2-key"}, metrics)
+
+	tests := []struct {
+		token, want, status string
+	}{
+		{"", "", UID2Absent},
+		{"good", "good", UID2Valid},
+		{"stale", "fresh", UID2Refreshed},
+		{"old", "", UID2Expired},
+		{"garbage", "", UID2Invalid},
+		{"odd", "odd", UID2Error},
+		{"unknown", "unknown", UID2Error},
+	}
+	for _, tt := range tests {
+		if got := v.Check(context.Background(), tt.token); got != tt.want {
+			t.Errorf("token %q: expected %q forwarded, got %q", tt.token, tt.want, got)
+		}
+		if metrics.count(tt.status) == 0 {
+			t.Errorf("token %q: expected status %s recorded, got %v", tt.token, tt.status, metrics.statuses)
+		}
+	}
+
+	// Verdicts are cached; errors are not
+	before := calls()
+	v.Check(context.Background(), "good")
+	v.Check(context.Background(), "stale")
+	if calls() != before {
+		t.Errorf("expected cached verdicts, got %d more calls", calls()-before)
+	}
+	v.Check(context.Background(), "unknown")
+	if calls() != before+1 {
+		t.Errorf("expected a failed check retried, got %d more calls", calls()-before)
+	}
+}
+
+func TestUID2Validator_CacheExpiry(t *testing.T) {
+	expires := time.Now().Add(time.Minute).UnixMilli()
+	operator, calls := newUID2Operator(t, map[string]string{
+		"good": `{"status": "valid", "expires_at": ` + strconv.FormatInt(expires, 10) + `}`,
+	})
+	v := NewUID2Validator(UID2Config{OperatorURL: operator.URL, APIKey: "uid2-key"}, nil)
+	now := time.Now()
+	v.now = func() time.Time { return now }
+
+	v.Check(context.Background(), "good")
+	now = now.Add(30 * time.Second)
+	v.Check(context.Background(), "good")
+	if calls() != 1 {
+		t.Fatalf("expected the verdict cached, got %d calls", calls())
+	}
+
+	// The token's own expiry ends the cached verdict before the cache TTL
+	now = now.Add(time.Minute)
+	v.Check(context.Background(), "good")
+	if calls() != 2 {
+		t.Errorf("expected the token checked again after it expired, got %d calls", calls())
+	}
+}
+
+func TestUID2Validator_Unvalidated(t *testing.T) {
+	metrics := &mockUID2Metrics{}
+	v := NewUID2Validator(UID2Config{}, metrics)
+	if v.Validates() {
+		t.Error("expected no validation without an operator")
+	}
+	if got := v.Check(context.Background(), "token"); got != "token" || metrics.count(UID2Unvalidated) != 1 {
+		t.Errorf("expected the token forwarded unvalidated, got %q and %v", got, metrics.statuses)
+	}
+}
+
+func TestUID2Validator_SlowOperator(t *testing.T) {
+	operator := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte(`{"status": "expired"}`))
+	}))
+	t.Cleanup(operator.Close)
+	metrics := &mockUID2Metrics{}
+	v := NewUID2Validator(UID2Config{OperatorURL: operator.URL, Timeout: 20 * time.Millisecond}, metrics)
+
+	start := time.Now()
+	if got := v.Check(context.Background(), "token"); got != "token" {
+		t.Errorf("expected the token kept when the operator is slow, got %q", got)
+	}
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Errorf("expected the check abandoned at its timeout, waited %v", elapsed)
+	}
+	if metrics.count(UID2Error) != 1 {
+		t.Errorf("expected an error recorded, got %v", metrics.statuses)
+	}
+}
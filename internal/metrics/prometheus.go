@@ -17,6 +17,8 @@ type Metrics struct {
 	RequestsTotal    *prometheus.CounterVec
 	RequestDuration  *prometheus.HistogramVec
 	RequestsInFlight prometheus.Gauge
+	LegacyUpgrades   *prometheus.CounterVec // Legacy OpenRTB constructs upgraded, per rule
+	PublisherSLOBurn *prometheus.GaugeVec   // p95 latency error budget burn rate, per publisher and window
 
 	// Auction metrics
 	AuctionsTotal   *prometheus.CounterVec
@@ -26,11 +28,45 @@ type Metrics struct {
 	BiddersSelected *prometheus.HistogramVec
 	BiddersExcluded *prometheus.HistogramVec
 
+	// Yield metrics
+	BidPriceLandscape *prometheus.HistogramVec // Bid CPMs by auction outcome
+	BidsPerRequest    *prometheus.HistogramVec // Bids each called bidder returned per auction
+
 	// Bidder metrics
-	BidderRequests *prometheus.CounterVec
-	BidderLatency  *prometheus.HistogramVec
-	BidderErrors   *prometheus.CounterVec
-	BidderTimeouts *prometheus.CounterVec
+	BidderRequests      *prometheus.CounterVec
+	BidderLatency       *prometheus.HistogramVec
+	BidderErrors        *prometheus.CounterVec
+	BidderTimeouts      *prometheus.CounterVec
+	BidderQPSSuppressed *prometheus.CounterVec // Calls skipped because the bidder's QPS budget was used up
+
+	// Bidder payload size metrics
+	BidderRequestBytes       *prometheus.HistogramVec // Request body bytes sent to each bidder
+	BidderResponseBytes      *prometheus.HistogramVec // Response body bytes read from each bidder
+	BidderResponsesOversized *prometheus.CounterVec   // Responses rejected for exceeding the size limit
+
+	// Fan-out metrics
+	FanoutSavedMillis *prometheus.HistogramVec // Timeout budget left when all bidders had answered
+	FanoutTruncations *prometheus.CounterVec   // Auctions where MaxBidders dropped bidders
+	FanoutDropped     *prometheus.HistogramVec // Bidders dropped per truncated auction
+	FanoutCandidates  *prometheus.HistogramVec // Bidders eligible before the cap, per truncated auction
+
+	// Bids rejected for exceeding the max CPM cap, per bidder
+	BidsOverPriceCap *prometheus.CounterVec
+
+	// Banner markup sanitization actions, per bidder and action
+	CreativeSanitizations *prometheus.CounterVec
+
+	// Bids by creative language and language filter outcome, per bidder
+	BidsByLanguage *prometheus.CounterVec
+
+	// Bids by creative approval outcome, per bidder
+	CreativeApprovals *prometheus.CounterVec
+
+	// Auctions rejected before fan-out for the device country
+	GeoRejections *prometheus.CounterVec
+
+	// Auctions by what filled them: paid bids, house ads or nothing
+	Fills *prometheus.CounterVec
 
 	// Bidder Circuit Breaker metrics
 	BidderCircuitState        *prometheus.GaugeVec   // Current state per bidder (0=closed, 1=open, 2=half-open)
@@ -48,20 +84,96 @@ type Metrics struct {
 	// Privacy metrics
 	PrivacyFiltered *prometheus.CounterVec
 	ConsentSignals  *prometheus.CounterVec
+	ConsentStrings  *prometheus.CounterVec
+
+	// Dependency metrics
+	DependencyTimeouts  *prometheus.CounterVec
+	RedisCommandLatency *prometheus.HistogramVec
+	RedisCommandErrors  *prometheus.CounterVec
+	RedisDegraded       prometheus.Gauge
+
+	// Currency rate feed metrics
+	CurrencyRateFetches *prometheus.CounterVec
+	CurrencyRatesAge    prometheus.Gauge
+	CurrencyRatesStale  prometheus.Gauge
+
+	// Analytics pipeline metrics
+	AnalyticsEvents     *prometheus.CounterVec
+	AnalyticsQueueDepth *prometheus.GaugeVec
+	KafkaDeliveries     *prometheus.CounterVec
+
+	// Device graph metrics
+	DeviceGraphLookups *prometheus.CounterVec
+
+	// Identity module metrics
+	IDModuleLookups *prometheus.CounterVec
+	UID2Tokens      *prometheus.CounterVec
+
+	// Latency budget metrics (SSAI callers)
+	LatencyBudgetRequests    *prometheus.CounterVec
+	LatencyBudgetUtilization *prometheus.HistogramVec
+
+	// Billing window metrics
+	ExpiredWinAttempts *prometheus.CounterVec
+	WinQueueEvents     *prometheus.CounterVec
+	BidderNotices      *prometheus.CounterVec
+
+	// Auction registry metrics
+	AuctionRegistryRecords *prometheus.CounterVec
+	AuctionTrailRecords    *prometheus.CounterVec
+
+	// Video tracking metrics
+	VideoEventsDeduplicated *prometheus.CounterVec
+
+	// Cookie sync metrics
+	CookieSyncRequests *prometheus.CounterVec
+	CookieSyncBidders  *prometheus.CounterVec
+
+	// Bid cache metrics
+	BidCacheBytesWritten *prometheus.CounterVec
+	BidCacheStorageBytes *prometheus.GaugeVec
+	BidCacheRejected     *prometheus.CounterVec
+
+	// Bounded in-memory map metrics
+	LRUEvictions *prometheus.CounterVec
+	LRUEntries   *prometheus.GaugeVec
+	LRUBytes     *prometheus.GaugeVec
+
+	// Feature flag metrics
+	FeatureFlagEvaluations *prometheus.CounterVec
+	FeatureFlagRefreshes   *prometheus.CounterVec
+
+	// Memory metrics of sampled auctions (AUCTION_ALLOC_SAMPLE_RATE)
+	AuctionAllocBytes   prometheus.Histogram // Heap bytes allocated while the auction ran
+	AuctionAllocObjects prometheus.Histogram // Heap objects allocated while the auction ran
+	AuctionHeapBytes    prometheus.Histogram // Larger live heap size seen at the auction's start or end
 
 	// System metrics
 	ActiveConnections prometheus.Gauge
 	RateLimitRejected prometheus.Counter
 	AuthFailures      prometheus.Counter
 
+	// Response compression metrics
+	CompressedResponses      *prometheus.CounterVec // Responses compressed, by encoding
+	CompressionOriginalBytes *prometheus.CounterVec // Response bytes before compression
+	CompressionBytesSaved    *prometheus.CounterVec // Response bytes saved by compression
+
 	// Revenue/Margin metrics
 	RevenueTotal         *prometheus.CounterVec   // Total bid value (before multiplier)
 	PublisherPayoutTotal *prometheus.CounterVec   // Amount paid to publishers (after multiplier)
 	PlatformMarginTotal  *prometheus.CounterVec   // Platform revenue (difference)
 	MarginPercentage     *prometheus.HistogramVec // Margin % distribution
-	FloorAdjustments     *prometheus.CounterVec   // Floor price adjustments
+	FloorAdjustments     *prometheus.CounterVec   // Floors raised and bids rejected, by floor rule
+	PodRuleEnforcements  *prometheus.CounterVec   // Ad pods rejected and bids passed over under publisher pod rules
 }
 
+// payloadSizeBuckets cover bidder payloads from 256B to 16MiB
+var payloadSizeBuckets = prometheus.ExponentialBuckets(256, 4, 9)
+
+// bidCPMBuckets are shared by the bid CPM histograms so price landscapes
+// line up with the overall distribution
+var bidCPMBuckets = []float64{0.1, 0.5, 1, 2, 3, 5, 10, 20, 50}
+
 // NewMetrics creates and registers all Prometheus metrics
 func NewMetrics(namespace string) *Metrics {
 	if namespace == "" {
@@ -94,6 +206,22 @@ func NewMetrics(namespace string) *Metrics {
 				Help:      "Number of HTTP requests currently being served",
 			},
 		),
+		LegacyUpgrades: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "openrtb_legacy_upgrades_total",
+				Help:      "Bid requests with legacy OpenRTB constructs upgraded, by rule",
+			},
+			[]string{"rule"},
+		),
+		PublisherSLOBurn: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "publisher_slo_burn_rate",
+				Help:      "Auction p95 latency SLO error budget burn rate, by publisher and window (1 = spending the budget exactly)",
+			},
+			[]string{"publisher", "window"},
+		),
 
 		// Auction metrics
 		AuctionsTotal: prometheus.NewCounterVec(
@@ -102,7 +230,7 @@ func NewMetrics(namespace string) *Metrics {
 				Name:      "auctions_total",
 				Help:      "Total number of auctions",
 			},
-			[]string{"status", "media_type"},
+			[]string{"status", "media_type", "media_subtype"},
 		),
 		AuctionDuration: prometheus.NewHistogramVec(
 			prometheus.HistogramOpts{
@@ -111,7 +239,7 @@ func NewMetrics(namespace string) *Metrics {
 				Help:      "Auction duration in seconds",
 				Buckets:   []float64{.01, .025, .05, .1, .25, .5, .75, 1, 1.5, 2},
 			},
-			[]string{"media_type"},
+			[]string{"media_type", "media_subtype"},
 		),
 		BidsReceived: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
@@ -119,16 +247,34 @@ func NewMetrics(namespace string) *Metrics {
 				Name:      "bids_received_total",
 				Help:      "Total number of bids received",
 			},
-			[]string{"bidder", "media_type"},
+			[]string{"bidder", "media_type", "media_subtype"},
 		),
 		BidCPM: prometheus.NewHistogramVec(
 			prometheus.HistogramOpts{
 				Namespace: namespace,
 				Name:      "bid_cpm",
 				Help:      "Bid CPM distribution",
-				Buckets:   []float64{0.1, 0.5, 1, 2, 3, 5, 10, 20, 50},
+				Buckets:   bidCPMBuckets,
 			},
-			[]string{"bidder", "media_type"},
+			[]string{"bidder", "media_type", "media_subtype"},
+		),
+		BidPriceLandscape: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "bid_price_landscape",
+				Help:      "Bid CPM distribution by auction outcome (won, lost, below_floor)",
+				Buckets:   bidCPMBuckets,
+			},
+			[]string{"bidder", "media_type", "media_subtype", "outcome"},
+		),
+		BidsPerRequest: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "bids_per_request",
+				Help:      "Number of bids a called bidder returned per auction (0 = no bid)",
+				Buckets:   []float64{0, 1, 2, 3, 5, 10, 20},
+			},
+			[]string{"bidder", "media_type", "media_subtype"},
 		),
 		BiddersSelected: prometheus.NewHistogramVec(
 			prometheus.HistogramOpts{
@@ -224,6 +370,40 @@ func NewMetrics(namespace string) *Metrics {
 			},
 			[]string{"bidder"},
 		),
+		BidderQPSSuppressed: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "bidder_qps_suppressed_total",
+				Help:      "Outbound bidder calls skipped because the bidder's max QPS was reached",
+			},
+			[]string{"bidder"},
+		),
+		BidderRequestBytes: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "bidder_request_bytes",
+				Help:      "OpenRTB request body size sent to each bidder",
+				Buckets:   payloadSizeBuckets,
+			},
+			[]string{"bidder"},
+		),
+		BidderResponseBytes: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "bidder_response_bytes",
+				Help:      "Response body size read from each bidder",
+				Buckets:   payloadSizeBuckets,
+			},
+			[]string{"bidder"},
+		),
+		BidderResponsesOversized: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "bidder_responses_oversized_total",
+				Help:      "Bidder responses rejected for exceeding the response size limit",
+			},
+			[]string{"bidder"},
+		),
 		BidderCircuitStateChanges: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace: namespace,
@@ -277,6 +457,401 @@ func NewMetrics(namespace string) *Metrics {
 			},
 			[]string{"type", "has_consent"},
 		),
+		ConsentStrings: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "consent_strings_total",
+				Help:      "Consent strings by publisher, type and outcome (valid, or the malformed consent policy applied)",
+			},
+			[]string{"publisher", "type", "outcome"},
+		),
+
+		// Dependency metrics
+		DependencyTimeouts: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "dependency_timeouts_total",
+				Help:      "Dependency calls (postgres, redis, idr) that hit their deadline",
+			},
+			[]string{"dependency"},
+		),
+		RedisCommandLatency: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "redis_command_duration_seconds",
+				Help:      "Redis command latency by command",
+				Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+			},
+			[]string{"command"},
+		),
+		RedisCommandErrors: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "redis_command_errors_total",
+				Help:      "Redis commands that failed or timed out, by command",
+			},
+			[]string{"command"},
+		),
+		RedisDegraded: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "redis_degraded",
+				Help:      "1 while Redis is over its error budget of failed or slow commands",
+			},
+		),
+
+		// Currency rate feed metrics
+		CurrencyRateFetches: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "currency_rate_fetches_total",
+				Help:      "Currency rate refreshes by source and result (success, cached, error)",
+			},
+			[]string{"source", "result"},
+		),
+		CurrencyRatesAge: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "currency_rates_age_seconds",
+				Help:      "Age of the live currency rates in use",
+			},
+		),
+		CurrencyRatesStale: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "currency_rates_stale",
+				Help:      "1 while the currency rates in use are older than their max age, or none were fetched",
+			},
+		),
+
+		// Latency budget metrics
+		LatencyBudgetRequests: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "latency_budget_requests_total",
+				Help:      "Requests carrying a latency budget, by partner and whether the budget was met",
+			},
+			[]string{"partner", "outcome"},
+		),
+		LatencyBudgetUtilization: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "latency_budget_utilization_ratio",
+				Help:      "Fraction of the caller's latency budget spent serving the request",
+				Buckets:   []float64{0.1, 0.25, 0.5, 0.75, 0.9, 1, 1.25, 1.5, 2},
+			},
+			[]string{"partner"},
+		),
+
+		// Billing window metrics
+		ExpiredWinAttempts: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "expired_win_attempts_total",
+				Help:      "Win/billing notices received after the bid's expiry window, by bidder",
+			},
+			[]string{"bidder"},
+		),
+
+		BidderNotices: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "bidder_notices_total",
+				Help:      "Win (nurl), billing (burl) and loss (lurl) notices fired to bidders, by outcome",
+			},
+			[]string{"bidder", "type", "status"},
+		),
+
+		WinQueueEvents: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "win_queue_events_total",
+				Help:      "Win/billing queue events by type and status (enqueued, processed, retried, dropped)",
+			},
+			[]string{"type", "status"},
+		),
+
+		AuctionRegistryRecords: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "auction_registry_records_total",
+				Help:      "Auction summaries published to the registry stream by status (written, dropped, failed)",
+			},
+			[]string{"status"},
+		),
+
+		AuctionTrailRecords: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "auction_trail_records_total",
+				Help:      "Auction decision trails recorded by status (written, dropped, failed, shed)",
+			},
+			[]string{"status"},
+		),
+
+		AnalyticsEvents: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "analytics_events_total",
+				Help:      "Analytics events by sink and status (written, retried, dropped, failed)",
+			},
+			[]string{"sink", "status"},
+		),
+
+		AnalyticsQueueDepth: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "analytics_queue_depth",
+				Help:      "Analytics events buffered per sink after the last batch write",
+			},
+			[]string{"sink"},
+		),
+
+		KafkaDeliveries: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "kafka_messages_total",
+				Help:      "Analytics messages produced to Kafka brokers by topic and status (delivered, failed)",
+			},
+			[]string{"topic", "status"},
+		),
+
+		DeviceGraphLookups: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "device_graph_lookups_total",
+				Help:      "Device graph lookups by result (cached, resolved, not_found, error)",
+			},
+			[]string{"result"},
+		),
+
+		// Identity module metrics
+		IDModuleLookups: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "id_module_lookups_total",
+				Help:      "Identity module lookups by module and result (resolved, not_found, error, circuit_open)",
+			},
+			[]string{"module", "result"},
+		),
+		UID2Tokens: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "uid2_tokens_total",
+				Help:      "Auctions by UID2 token status (absent, unvalidated, valid, refreshed, expired, invalid, error)",
+			},
+			[]string{"status"},
+		),
+
+		// Video tracking metrics
+		VideoEventsDeduplicated: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "video_events_deduplicated_total",
+				Help:      "Video tracking events dropped as repeats of the same (bid_id, event) within the dedup window",
+			},
+			[]string{"event"},
+		),
+
+		// Cookie sync metrics
+		CookieSyncRequests: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "cookie_sync_requests_total",
+				Help:      "/cookie_sync requests by status (ok, opt_out, gdpr_blocked, ccpa_blocked)",
+			},
+			[]string{"status"},
+		),
+		CookieSyncBidders: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "cookie_sync_bidders_total",
+				Help:      "Bidders considered for cookie sync by status (synced, already_synced, privacy_blocked, disabled, unsupported, type_not_supported)",
+			},
+			[]string{"bidder", "status"},
+		),
+
+		// Bid cache metrics
+		BidCacheBytesWritten: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "bid_cache_bytes_written_total",
+				Help:      "Bytes of markup written to /cache by publisher",
+			},
+			[]string{"publisher"},
+		),
+		BidCacheStorageBytes: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "bid_cache_storage_bytes",
+				Help:      "Bytes counted against each publisher's /cache quota as of its last write",
+			},
+			[]string{"publisher"},
+		),
+		BidCacheRejected: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "bid_cache_rejected_total",
+				Help:      "Rejected /cache writes by publisher and reason",
+			},
+			[]string{"publisher", "reason"},
+		),
+
+		// Bounded in-memory map metrics
+		LRUEvictions: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "lru_evictions_total",
+				Help:      "Entries evicted from bounded in-memory maps by cache and reason (max_entries, max_bytes, expired)",
+			},
+			[]string{"cache", "reason"},
+		),
+		LRUEntries: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "lru_entries",
+				Help:      "Entries held by each bounded in-memory map",
+			},
+			[]string{"cache"},
+		),
+		LRUBytes: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "lru_estimated_bytes",
+				Help:      "Estimated memory held by each bounded in-memory map",
+			},
+			[]string{"cache"},
+		),
+
+		BidsOverPriceCap: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "bids_over_price_cap_total",
+				Help:      "Bids rejected for exceeding the global or publisher max CPM",
+			},
+			[]string{"bidder"},
+		),
+		CreativeSanitizations: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "creative_sanitization_actions_total",
+				Help:      "Banner markup sanitization actions (handler_stripped, https_upgraded, click_wrapped, rejected_insecure), by bidder",
+			},
+			[]string{"bidder", "action"},
+		),
+		BidsByLanguage: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "bids_by_language_total",
+				Help:      "Bids by creative language (ISO 639-1, unlabeled or other) and language filter outcome (accepted, rejected), by bidder",
+			},
+			[]string{"bidder", "language", "outcome"},
+		),
+		CreativeApprovals: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "creative_approvals_total",
+				Help:      "Bids by creative review outcome (approved, unreviewed, flagged, held, blocked), by bidder",
+			},
+			[]string{"bidder", "outcome"},
+		),
+		GeoRejections: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "geo_rejections_total",
+				Help:      "Auctions rejected for the device country before bidders were called, by country and reason (global_blocked, publisher_blocked, publisher_not_allowed)",
+			},
+			[]string{"country", "reason"},
+		),
+		Fills: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "fills_total",
+				Help:      "Auctions by media type and fill source (paid, house, none)",
+			},
+			[]string{"media_type", "source"},
+		),
+
+		// Fan-out metrics
+		FanoutSavedMillis: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "fanout_saved_milliseconds",
+				Help:      "Milliseconds of the auction timeout saved by completing the fan-out once every bidder responded",
+				Buckets:   []float64{10, 25, 50, 100, 200, 300, 500, 750, 1000, 1500, 2000, 3000},
+			},
+			[]string{},
+		),
+		FanoutTruncations: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "fanout_truncations_total",
+				Help:      "Auctions where the max bidders cap dropped selected bidders",
+			},
+			[]string{},
+		),
+		FanoutDropped: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "fanout_dropped_bidders",
+				Help:      "Bidders dropped by the max bidders cap per truncated auction",
+				Buckets:   []float64{1, 2, 3, 5, 10, 20, 50},
+			},
+			[]string{},
+		),
+		FanoutCandidates: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "fanout_candidate_bidders",
+				Help:      "Bidders eligible before the max bidders cap per truncated auction",
+				Buckets:   []float64{5, 10, 20, 30, 50, 75, 100, 150},
+			},
+			[]string{},
+		),
+
+		// Feature flag metrics
+		FeatureFlagEvaluations: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "feature_flag_evaluations_total",
+				Help:      "Feature flag evaluations by flag and result",
+			},
+			[]string{"flag", "result"},
+		),
+		FeatureFlagRefreshes: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "feature_flag_refreshes_total",
+				Help:      "Feature flag provider refreshes by provider and status",
+			},
+			[]string{"provider", "status"},
+		),
+
+		// Memory metrics of sampled auctions
+		AuctionAllocBytes: prometheus.NewHistogram(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "auction_alloc_bytes",
+				Help:      "Heap bytes allocated process-wide while a sampled auction ran",
+				Buckets:   prometheus.ExponentialBuckets(64*1024, 2, 12), // 64KiB to 128MiB
+			},
+		),
+		AuctionAllocObjects: prometheus.NewHistogram(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "auction_alloc_objects",
+				Help:      "Heap objects allocated process-wide while a sampled auction ran",
+				Buckets:   prometheus.ExponentialBuckets(1000, 2, 12), // 1k to 2M
+			},
+		),
+		AuctionHeapBytes: prometheus.NewHistogram(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "auction_heap_bytes",
+				Help:      "Live heap bytes at the start or end of a sampled auction, whichever was larger",
+				Buckets:   prometheus.ExponentialBuckets(16*1024*1024, 2, 10), // 16MiB to 8GiB
+			},
+		),
 
 		// System metrics
 		ActiveConnections: prometheus.NewGauge(
@@ -301,6 +876,32 @@ func NewMetrics(namespace string) *Metrics {
 			},
 		),
 
+		// Response compression metrics
+		CompressedResponses: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "compressed_responses_total",
+				Help:      "HTTP responses compressed, by encoding",
+			},
+			[]string{"encoding"},
+		),
+		CompressionOriginalBytes: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "compression_original_bytes_total",
+				Help:      "HTTP response bytes before compression, by encoding",
+			},
+			[]string{"encoding"},
+		),
+		CompressionBytesSaved: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "compression_bytes_saved_total",
+				Help:      "HTTP response bytes saved by compression, by encoding",
+			},
+			[]string{"encoding"},
+		),
+
 		// Revenue/Margin metrics
 		// NOTE: Publisher label removed to prevent cardinality explosion
 		// Use external analytics for per-publisher metrics
@@ -341,9 +942,17 @@ func NewMetrics(namespace string) *Metrics {
 			prometheus.CounterOpts{
 				Namespace: namespace,
 				Name:      "floor_adjustments_total",
-				Help:      "Number of floor price adjustments applied (count)",
+				Help:      "Floors raised and bids rejected below floor, by floor rule (rule ID, request or bid_multiplier)",
 			},
-			[]string{},
+			[]string{"rule", "action"},
+		),
+		PodRuleEnforcements: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "pod_rule_enforcements_total",
+				Help:      "Ad pods left unfilled below the publisher's pod floor (min_cpm) and bids passed over for a bidder at its share of the pod (bidder_share)",
+			},
+			[]string{"rule"},
 		),
 	}
 
@@ -352,10 +961,14 @@ func NewMetrics(namespace string) *Metrics {
 		m.RequestsTotal,
 		m.RequestDuration,
 		m.RequestsInFlight,
+		m.LegacyUpgrades,
+		m.PublisherSLOBurn,
 		m.AuctionsTotal,
 		m.AuctionDuration,
 		m.BidsReceived,
 		m.BidCPM,
+		m.BidPriceLandscape,
+		m.BidsPerRequest,
 		m.BiddersSelected,
 		m.BiddersExcluded,
 		m.BidderRequests,
@@ -367,13 +980,65 @@ func NewMetrics(namespace string) *Metrics {
 		m.BidderCircuitFailures,
 		m.BidderCircuitSuccesses,
 		m.BidderCircuitRejected,
+		m.BidderQPSSuppressed,
+		m.BidderRequestBytes,
+		m.BidderResponseBytes,
+		m.BidderResponsesOversized,
 		m.BidderCircuitStateChanges,
 		m.IDRRequests,
 		m.IDRLatency,
 		m.IDRCircuitState,
 		m.PrivacyFiltered,
 		m.ConsentSignals,
+		m.ConsentStrings,
+		m.DependencyTimeouts,
+		m.RedisCommandLatency,
+		m.RedisCommandErrors,
+		m.RedisDegraded,
+		m.CurrencyRateFetches,
+		m.CurrencyRatesAge,
+		m.CurrencyRatesStale,
+		m.AnalyticsEvents,
+		m.AnalyticsQueueDepth,
+		m.KafkaDeliveries,
+		m.DeviceGraphLookups,
+		m.IDModuleLookups,
+		m.UID2Tokens,
+		m.LatencyBudgetRequests,
+		m.LatencyBudgetUtilization,
+		m.ExpiredWinAttempts,
+		m.BidderNotices,
+		m.WinQueueEvents,
+		m.AuctionRegistryRecords,
+		m.AuctionTrailRecords,
+		m.VideoEventsDeduplicated,
+		m.CookieSyncRequests,
+		m.CookieSyncBidders,
+		m.BidCacheBytesWritten,
+		m.BidCacheStorageBytes,
+		m.BidCacheRejected,
+		m.LRUEvictions,
+		m.LRUEntries,
+		m.LRUBytes,
+		m.BidsOverPriceCap,
+		m.CreativeSanitizations,
+		m.BidsByLanguage,
+		m.CreativeApprovals,
+		m.GeoRejections,
+		m.Fills,
+		m.FanoutSavedMillis,
+		m.FanoutTruncations,
+		m.FanoutDropped,
+		m.FanoutCandidates,
+		m.FeatureFlagEvaluations,
+		m.FeatureFlagRefreshes,
+		m.AuctionAllocBytes,
+		m.AuctionAllocObjects,
+		m.AuctionHeapBytes,
 		m.ActiveConnections,
+		m.CompressedResponses,
+		m.CompressionOriginalBytes,
+		m.CompressionBytesSaved,
 		m.RateLimitRejected,
 		m.AuthFailures,
 		m.RevenueTotal,
@@ -381,6 +1046,7 @@ func NewMetrics(namespace string) *Metrics {
 		m.PlatformMarginTotal,
 		m.MarginPercentage,
 		m.FloorAdjustments,
+		m.PodRuleEnforcements,
 	)
 
 	return m
@@ -444,9 +1110,23 @@ func normalizePath(path string) string {
 	return "/other"
 }
 
+// streamingPaths are long-lived server-sent event streams. They're left out
+// of the in-flight gauge, where they'd look like requests blocking shutdown,
+// and of request durations, which they'd skew by minutes.
+var streamingPaths = map[string]bool{
+	"/admin/debug/tail": true,
+}
+
 // Middleware returns HTTP middleware that records request metrics
 func (m *Metrics) Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if streamingPaths[r.URL.Path] {
+			wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+			next.ServeHTTP(wrapped, r)
+			m.RequestsTotal.WithLabelValues(r.Method, normalizePath(r.URL.Path), strconv.Itoa(wrapped.statusCode)).Inc()
+			return
+		}
+
 		start := time.Now()
 		m.RequestsInFlight.Inc()
 		defer m.RequestsInFlight.Dec()
@@ -478,17 +1158,23 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
-// RecordAuction records auction metrics
-func (m *Metrics) RecordAuction(status, mediaType string, duration time.Duration, biddersSelected, biddersExcluded int) {
-	m.AuctionsTotal.WithLabelValues(status, mediaType).Inc()
-	m.AuctionDuration.WithLabelValues(mediaType).Observe(duration.Seconds())
+// Unwrap exposes the underlying writer to http.ResponseController
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// RecordAuction records auction metrics. The media subtype (instream,
+// outstream, pod, pause or none) splits the media type further.
+func (m *Metrics) RecordAuction(status, mediaType, mediaSubtype string, duration time.Duration, biddersSelected, biddersExcluded int) {
+	m.AuctionsTotal.WithLabelValues(status, mediaType, mediaSubtype).Inc()
+	m.AuctionDuration.WithLabelValues(mediaType, mediaSubtype).Observe(duration.Seconds())
 	m.BiddersSelected.WithLabelValues(mediaType).Observe(float64(biddersSelected))
 }
 
 // RecordBid records a bid received from a bidder
-func (m *Metrics) RecordBid(bidder, mediaType string, cpm float64) {
-	m.BidsReceived.WithLabelValues(bidder, mediaType).Inc()
-	m.BidCPM.WithLabelValues(bidder, mediaType).Observe(cpm)
+func (m *Metrics) RecordBid(bidder, mediaType, mediaSubtype string, cpm float64) {
+	m.BidsReceived.WithLabelValues(bidder, mediaType, mediaSubtype).Inc()
+	m.BidCPM.WithLabelValues(bidder, mediaType, mediaSubtype).Observe(cpm)
 }
 
 // RecordBidderRequest records a request to a bidder
@@ -538,6 +1224,292 @@ func (m *Metrics) RecordConsentSignal(signalType string, hasConsent bool) {
 	m.ConsentSignals.WithLabelValues(signalType, consent).Inc()
 }
 
+// RecordLegacyUpgrade records a bid request upgraded by a legacy rule
+// Implements endpoints.UpgradeRecorder interface
+func (m *Metrics) RecordLegacyUpgrade(rule string) {
+	m.LegacyUpgrades.WithLabelValues(rule).Inc()
+}
+
+// SetPublisherSLOBurnRate sets a publisher's burn rate over one window
+// Implements slo.GaugeRecorder interface
+func (m *Metrics) SetPublisherSLOBurnRate(publisherID, window string, rate float64) {
+	m.PublisherSLOBurn.WithLabelValues(publisherID, window).Set(rate)
+}
+
+// DeletePublisherSLO removes the burn rate gauges of a publisher no longer tracked
+// Implements slo.GaugeRecorder interface
+func (m *Metrics) DeletePublisherSLO(publisherID string) {
+	m.PublisherSLOBurn.DeletePartialMatch(prometheus.Labels{"publisher": publisherID})
+}
+
+// RecordConsentString records a consent string and whether it parsed
+// Implements middleware.PrivacyMetrics interface
+func (m *Metrics) RecordConsentString(publisherID, signal, outcome string) {
+	m.ConsentStrings.WithLabelValues(publisherID, signal, outcome).Inc()
+}
+
+// RecordDependencyTimeout records a dependency call that hit its deadline
+// Implements deadline.TimeoutRecorder interface
+func (m *Metrics) RecordDependencyTimeout(dependency string) {
+	m.DependencyTimeouts.WithLabelValues(dependency).Inc()
+}
+
+// RecordRedisCommand records a Redis command's latency and failure
+// Implements redis.Recorder interface
+func (m *Metrics) RecordRedisCommand(command string, duration time.Duration, failed bool) {
+	m.RedisCommandLatency.WithLabelValues(command).Observe(duration.Seconds())
+	if failed {
+		m.RedisCommandErrors.WithLabelValues(command).Inc()
+	}
+}
+
+// SetRedisDegraded records whether Redis is over its error budget
+// Implements redis.Recorder interface
+func (m *Metrics) SetRedisDegraded(degraded bool) {
+	if degraded {
+		m.RedisDegraded.Set(1)
+	} else {
+		m.RedisDegraded.Set(0)
+	}
+}
+
+// RecordCurrencyRateFetch records a currency rate refresh
+// Implements currency.FeedRecorder interface
+func (m *Metrics) RecordCurrencyRateFetch(source, result string) {
+	m.CurrencyRateFetches.WithLabelValues(source, result).Inc()
+}
+
+// SetCurrencyRatesAge records how old the currency rates in use are
+// Implements currency.FeedRecorder interface
+func (m *Metrics) SetCurrencyRatesAge(age time.Duration, stale bool) {
+	m.CurrencyRatesAge.Set(age.Seconds())
+	if stale {
+		m.CurrencyRatesStale.Set(1)
+	} else {
+		m.CurrencyRatesStale.Set(0)
+	}
+}
+
+// RecordAnalyticsEvents records analytics events handled by a sink
+// Implements analytics.Metrics interface
+func (m *Metrics) RecordAnalyticsEvents(sink, status string, count int) {
+	m.AnalyticsEvents.WithLabelValues(sink, status).Add(float64(count))
+}
+
+// SetAnalyticsQueueDepth records the events buffered for a sink
+// Implements analytics.Metrics interface
+func (m *Metrics) SetAnalyticsQueueDepth(sink string, depth int) {
+	m.AnalyticsQueueDepth.WithLabelValues(sink).Set(float64(depth))
+}
+
+// RecordKafkaDelivery records analytics messages produced to a Kafka topic
+// Implements kafka.Metrics interface (internal/analytics/kafka)
+func (m *Metrics) RecordKafkaDelivery(topic, status string, count int) {
+	m.KafkaDeliveries.WithLabelValues(topic, status).Add(float64(count))
+}
+
+// RecordDeviceGraphLookup records a device graph lookup by result
+// Implements devicegraph.Metrics interface
+func (m *Metrics) RecordDeviceGraphLookup(result string) {
+	m.DeviceGraphLookups.WithLabelValues(result).Inc()
+}
+
+// RecordIDModuleLookup records an identity module lookup by result
+// Implements idmodules.Metrics interface
+func (m *Metrics) RecordIDModuleLookup(module, result string) {
+	m.IDModuleLookups.WithLabelValues(module, result).Inc()
+}
+
+// RecordUID2Token records an auction's UID2 token by status
+// Implements idmodules.UID2Metrics interface
+func (m *Metrics) RecordUID2Token(status string) {
+	m.UID2Tokens.WithLabelValues(status).Inc()
+}
+
+// RecordLatencyBudget records how much of a caller's latency budget was spent
+// Implements middleware.LatencyBudgetMetrics interface
+func (m *Metrics) RecordLatencyBudget(partner string, budget, spent time.Duration) {
+	if budget <= 0 {
+		return
+	}
+	outcome := "within"
+	if spent > budget {
+		outcome = "exceeded"
+	}
+	m.LatencyBudgetRequests.WithLabelValues(partner, outcome).Inc()
+	m.LatencyBudgetUtilization.WithLabelValues(partner).Observe(spent.Seconds() / budget.Seconds())
+}
+
+// RecordBidderNotice records a notice URL fired to a bidder
+// Implements winqueue.NoticeMetrics interface
+func (m *Metrics) RecordBidderNotice(bidder, noticeType, status string) {
+	m.BidderNotices.WithLabelValues(bidder, noticeType, status).Inc()
+}
+
+// RecordExpiredWin records a win/billing notice for an expired bid
+// Implements endpoints.ExpiredWinMetrics interface
+func (m *Metrics) RecordExpiredWin(bidder string) {
+	m.ExpiredWinAttempts.WithLabelValues(bidder).Inc()
+}
+
+// RecordWinQueueEvent records a win queue event transition
+// Implements winqueue.Metrics interface
+func (m *Metrics) RecordWinQueueEvent(eventType, status string) {
+	m.WinQueueEvents.WithLabelValues(eventType, status).Inc()
+}
+
+// RecordAuctionRegistry records an auction summary write
+// Implements auctionregistry.Metrics interface
+func (m *Metrics) RecordAuctionRegistry(status string) {
+	m.AuctionRegistryRecords.WithLabelValues(status).Inc()
+}
+
+// RecordAuctionTrail records an auction trail write
+// Implements auctiontrail.Metrics interface
+func (m *Metrics) RecordAuctionTrail(status string) {
+	m.AuctionTrailRecords.WithLabelValues(status).Inc()
+}
+
+// RecordVideoEventDeduplicated records a duplicate video tracking event
+// Implements endpoints.DuplicateVideoEventMetrics interface
+func (m *Metrics) RecordVideoEventDeduplicated(event string) {
+	m.VideoEventsDeduplicated.WithLabelValues(event).Inc()
+}
+
+// RecordCookieSync records a /cookie_sync request outcome
+// Implements endpoints.CookieSyncMetrics interface
+func (m *Metrics) RecordCookieSync(status string) {
+	m.CookieSyncRequests.WithLabelValues(status).Inc()
+}
+
+// RecordBidderSync records the sync outcome for one bidder
+// Implements endpoints.CookieSyncMetrics interface
+func (m *Metrics) RecordBidderSync(bidder, status string) {
+	m.CookieSyncBidders.WithLabelValues(bidder, status).Inc()
+}
+
+// RecordBidCacheWrite records a /cache write and the publisher's quota usage
+// Implements bidcache.Metrics interface
+func (m *Metrics) RecordBidCacheWrite(publisherID string, bytes int, usage int64) {
+	m.BidCacheBytesWritten.WithLabelValues(publisherID).Add(float64(bytes))
+	m.BidCacheStorageBytes.WithLabelValues(publisherID).Set(float64(usage))
+}
+
+// RecordBidCacheRejected records a rejected /cache write
+// Implements bidcache.Metrics interface
+func (m *Metrics) RecordBidCacheRejected(publisherID, reason string) {
+	m.BidCacheRejected.WithLabelValues(publisherID, reason).Inc()
+}
+
+// RecordLRUEviction records an entry evicted from a bounded map
+// Implements lru.Recorder interface
+func (m *Metrics) RecordLRUEviction(cache, reason string) {
+	m.LRUEvictions.WithLabelValues(cache, reason).Inc()
+}
+
+// SetLRUSize records a bounded map's occupancy
+// Implements lru.Recorder interface
+func (m *Metrics) SetLRUSize(cache string, entries int, bytes int64) {
+	m.LRUEntries.WithLabelValues(cache).Set(float64(entries))
+	m.LRUBytes.WithLabelValues(cache).Set(float64(bytes))
+}
+
+// RecordFlagEvaluation records one feature flag evaluation
+// Implements featureflags.Metrics interface
+func (m *Metrics) RecordFlagEvaluation(flag string, enabled bool) {
+	result := "off"
+	if enabled {
+		result = "on"
+	}
+	m.FeatureFlagEvaluations.WithLabelValues(flag, result).Inc()
+}
+
+// RecordFlagRefresh records a feature flag provider refresh
+// Implements featureflags.Metrics interface
+func (m *Metrics) RecordFlagRefresh(provider string, success bool) {
+	status := "success"
+	if !success {
+		status = "error"
+	}
+	m.FeatureFlagRefreshes.WithLabelValues(provider, status).Inc()
+}
+
+// RecordAuctionAllocations records the allocations and heap size measured
+// around a sampled auction
+// Implements exchange.MetricsRecorder interface
+func (m *Metrics) RecordAuctionAllocations(allocBytes, allocObjects, heapBytes uint64) {
+	m.AuctionAllocBytes.Observe(float64(allocBytes))
+	m.AuctionAllocObjects.Observe(float64(allocObjects))
+	m.AuctionHeapBytes.Observe(float64(heapBytes))
+}
+
+// RecordBidPriceCapExceeded records a bid rejected for exceeding the max CPM
+// Implements exchange.MetricsRecorder interface
+func (m *Metrics) RecordBidPriceCapExceeded(bidder string) {
+	m.BidsOverPriceCap.WithLabelValues(bidder).Inc()
+}
+
+// RecordCreativeSanitization records count sanitization actions on a bidder's banner markup
+// Implements exchange.MetricsRecorder interface
+func (m *Metrics) RecordCreativeSanitization(bidder, action string, count int) {
+	m.CreativeSanitizations.WithLabelValues(bidder, action).Add(float64(count))
+}
+
+// RecordBidLanguage records a bid under its creative language and whether the
+// publisher's language filter accepted it
+// Implements exchange.MetricsRecorder interface
+func (m *Metrics) RecordBidLanguage(bidder, language, outcome string) {
+	m.BidsByLanguage.WithLabelValues(bidder, language, outcome).Inc()
+}
+
+// RecordCreativeApproval records a bid's creative review outcome
+// Implements exchange.MetricsRecorder interface
+func (m *Metrics) RecordCreativeApproval(bidder, outcome string) {
+	m.CreativeApprovals.WithLabelValues(bidder, outcome).Inc()
+}
+
+// RecordGeoRejection records an auction rejected for its device country
+// Implements exchange.MetricsRecorder interface
+func (m *Metrics) RecordGeoRejection(country, reason string) {
+	m.GeoRejections.WithLabelValues(country, reason).Inc()
+}
+
+// RecordAuctionFill records what filled an auction: paid bids, house ads or
+// nothing
+// Implements exchange.MetricsRecorder interface
+func (m *Metrics) RecordAuctionFill(mediaType, source string) {
+	m.Fills.WithLabelValues(mediaType, source).Inc()
+}
+
+// RecordBidOutcome records a bid's original CPM under its auction outcome:
+// won, lost or below_floor
+// Implements exchange.MetricsRecorder interface
+func (m *Metrics) RecordBidOutcome(bidder, mediaType, mediaSubtype, outcome string, cpm float64) {
+	m.BidPriceLandscape.WithLabelValues(bidder, mediaType, mediaSubtype, outcome).Observe(cpm)
+}
+
+// RecordBidsPerRequest records how many bids a called bidder returned
+// Implements exchange.MetricsRecorder interface
+func (m *Metrics) RecordBidsPerRequest(bidder, mediaType, mediaSubtype string, bids int) {
+	m.BidsPerRequest.WithLabelValues(bidder, mediaType, mediaSubtype).Observe(float64(bids))
+}
+
+// RecordFanoutEarlyCompletion records the timeout budget left when the
+// bidder fan-out completed
+// Implements exchange.MetricsRecorder interface
+func (m *Metrics) RecordFanoutEarlyCompletion(saved time.Duration) {
+	m.FanoutSavedMillis.WithLabelValues().Observe(float64(saved.Milliseconds()))
+}
+
+// RecordFanoutTruncated records an auction where the max bidders cap dropped
+// bidders from selection
+// Implements exchange.MetricsRecorder interface
+func (m *Metrics) RecordFanoutTruncated(candidates, dropped int) {
+	m.FanoutTruncations.WithLabelValues().Inc()
+	m.FanoutDropped.WithLabelValues().Observe(float64(dropped))
+	m.FanoutCandidates.WithLabelValues().Observe(float64(candidates))
+}
+
 // IncRateLimitRejected increments the rate limit rejected counter
 // Implements middleware.RateLimitMetrics interface
 func (m *Metrics) IncRateLimitRejected() {
@@ -550,6 +1522,16 @@ func (m *Metrics) IncAuthFailures() {
 	m.AuthFailures.Inc()
 }
 
+// RecordCompression records a compressed response and the bytes it saved
+// Implements middleware.CompressionMetrics interface
+func (m *Metrics) RecordCompression(encoding string, originalBytes, compressedBytes int) {
+	m.CompressedResponses.WithLabelValues(encoding).Inc()
+	m.CompressionOriginalBytes.WithLabelValues(encoding).Add(float64(originalBytes))
+	if saved := originalBytes - compressedBytes; saved > 0 {
+		m.CompressionBytesSaved.WithLabelValues(encoding).Add(float64(saved))
+	}
+}
+
 // RecordMargin records platform revenue margins from bid multiplier adjustments
 // originalPrice: the actual bid price from DSP
 // adjustedPrice: the price returned to publisher (after dividing by multiplier)
@@ -573,10 +1555,18 @@ func (m *Metrics) RecordMargin(publisher, bidder, mediaType string, originalPric
 	}
 }
 
-// RecordFloorAdjustment records when a floor price is adjusted via multiplier
-// NOTE: publisher parameter removed to prevent cardinality explosion
-func (m *Metrics) RecordFloorAdjustment(publisher string) {
-	m.FloorAdjustments.WithLabelValues().Inc()
+// RecordFloorAdjustment records a floor raised by a floor rule or the bid
+// multiplier, or a bid rejected below a floor (action raised or rejected).
+// Rules are floor_rules IDs, bounded by the table, not publishers.
+func (m *Metrics) RecordFloorAdjustment(rule, action string) {
+	m.FloorAdjustments.WithLabelValues(rule, action).Inc()
+}
+
+// RecordPodRuleEnforcement records ad pods rejected or bids passed over
+// under a publisher's pod rules (rule min_cpm or bidder_share)
+// Implements exchange.MetricsRecorder interface
+func (m *Metrics) RecordPodRuleEnforcement(rule string, count int) {
+	m.PodRuleEnforcements.WithLabelValues(rule).Add(float64(count))
 }
 
 // SetBidderCircuitState sets the circuit breaker state for a bidder
@@ -613,6 +1603,26 @@ func (m *Metrics) RecordBidderCircuitRejected(bidder string) {
 	m.BidderCircuitRejected.WithLabelValues(bidder).Inc()
 }
 
+// RecordBidderQPSSuppressed records a bidder call skipped by QPS shaping
+// Implements exchange.MetricsRecorder interface
+func (m *Metrics) RecordBidderQPSSuppressed(bidder string) {
+	m.BidderQPSSuppressed.WithLabelValues(bidder).Inc()
+}
+
+// RecordBidderPayloadSize records the request and response body sizes of a
+// completed bidder call
+// Implements exchange.MetricsRecorder interface
+func (m *Metrics) RecordBidderPayloadSize(bidder string, requestBytes, responseBytes int) {
+	m.BidderRequestBytes.WithLabelValues(bidder).Observe(float64(requestBytes))
+	m.BidderResponseBytes.WithLabelValues(bidder).Observe(float64(responseBytes))
+}
+
+// RecordBidderResponseOversized records a bidder response rejected for its size
+// Implements exchange.MetricsRecorder interface
+func (m *Metrics) RecordBidderResponseOversized(bidder string) {
+	m.BidderResponsesOversized.WithLabelValues(bidder).Inc()
+}
+
 // RecordBidderCircuitStateChange records a state change in the circuit breaker
 func (m *Metrics) RecordBidderCircuitStateChange(bidder, fromState, toState string) {
 	m.BidderCircuitStateChanges.WithLabelValues(bidder, fromState, toState).Inc()
@@ -17,7 +17,7 @@ func BenchmarkMetrics_RecordBid(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		m.RecordBid("rubicon", "banner", 1.50)
+		m.RecordBid("rubicon", "banner", "none", 1.50)
 	}
 }
 
@@ -27,7 +27,7 @@ func BenchmarkMetrics_RecordAuction(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		m.RecordAuction("success", "banner", 150*time.Millisecond, 5, 2)
+		m.RecordAuction("success", "banner", "none", 150*time.Millisecond, 5, 2)
 	}
 }
 
@@ -148,11 +148,11 @@ func BenchmarkMetrics_RealisticAuctionScenario(b *testing.B) {
 			m.RecordBidderCircuitSuccess(bidder)
 
 			// Record bid
-			m.RecordBid(bidder, "banner", 1.50)
+			m.RecordBid(bidder, "banner", "none", 1.50)
 		}
 
 		// Record auction completion
-		m.RecordAuction("success", "banner", 150*time.Millisecond, 5, 0)
+		m.RecordAuction("success", "banner", "none", 150*time.Millisecond, 5, 0)
 	}
 }
 
@@ -300,7 +300,7 @@ func BenchmarkMetrics_Concurrent_AuctionRecording(b *testing.B) {
 	b.ResetTimer()
 	b.RunParallel(func(pb *testing.PB) {
 		for pb.Next() {
-			m.RecordAuction("success", "banner", 100*time.Millisecond, 5, 2)
+			m.RecordAuction("success", "banner", "none", 100*time.Millisecond, 5, 2)
 		}
 	})
 }
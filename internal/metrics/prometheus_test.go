@@ -101,7 +101,7 @@ func createTestMetrics() *Metrics {
 				Name:      "floor_adjustments_total",
 				Help:      "Total floor adjustments",
 			},
-			[]string{},
+			[]string{"rule", "action"},
 		),
 	}
 
@@ -162,18 +162,33 @@ func TestRecordMargin_ZeroPrice(t *testing.T) {
 func TestRecordFloorAdjustment(t *testing.T) {
 	m := testMetrics
 
-	publisher := "pub_test"
-	// Note: publisher label removed to prevent cardinality explosion
-	initialValue := testutil.ToFloat64(m.FloorAdjustments.WithLabelValues())
+	initialValue := testutil.ToFloat64(m.FloorAdjustments.WithLabelValues("ctv-usa", "rejected"))
 
-	m.RecordFloorAdjustment(publisher)
+	m.RecordFloorAdjustment("ctv-usa", "rejected")
 
-	newValue := testutil.ToFloat64(m.FloorAdjustments.WithLabelValues())
+	newValue := testutil.ToFloat64(m.FloorAdjustments.WithLabelValues("ctv-usa", "rejected"))
 	if newValue != initialValue+1 {
 		t.Errorf("Expected floor adjustments to be %f, got %f", initialValue+1, newValue)
 	}
 }
 
+func TestRecordPodRuleEnforcement(t *testing.T) {
+	m := &Metrics{
+		PodRuleEnforcements: prometheus.NewCounterVec(
+			prometheus.CounterOpts{Namespace: "test_pbs", Name: "pod_rule_enforcements_total"},
+			[]string{"rule"},
+		),
+	}
+
+	m.RecordPodRuleEnforcement("min_cpm", 1)
+	m.RecordPodRuleEnforcement("bidder_share", 2)
+	m.RecordPodRuleEnforcement("bidder_share", 1)
+
+	if v := testutil.ToFloat64(m.PodRuleEnforcements.WithLabelValues("bidder_share")); v != 3 {
+		t.Errorf("expected 3 bids passed over, got %v", v)
+	}
+}
+
 func TestMiddleware(t *testing.T) {
 	m := testMetrics
 	
@@ -220,6 +235,23 @@ func TestMiddleware_InFlight(t *testing.T) {
 	}
 }
 
+func TestMiddleware_StreamsNotInFlight(t *testing.T) {
+	m := testMetrics
+	initialInFlight := testutil.ToFloat64(m.RequestsInFlight)
+	initialTotal := testutil.ToFloat64(m.RequestsTotal.WithLabelValues("GET", "/other", "200"))
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if inFlight := testutil.ToFloat64(m.RequestsInFlight); inFlight != initialInFlight {
+			t.Errorf("Expected streams not counted in flight, got %f", inFlight)
+		}
+	})
+	m.Middleware(handler).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/admin/debug/tail?publisher=pub-1", nil))
+
+	if total := testutil.ToFloat64(m.RequestsTotal.WithLabelValues("GET", "/other", "200")); total != initialTotal+1 {
+		t.Errorf("Expected the stream still counted as a request, got %f", total)
+	}
+}
+
 func TestHandler(t *testing.T) {
 	handler := Handler()
 
@@ -275,7 +307,7 @@ func createTestMetricsWithAll(namespace string) *Metrics {
 				Name:      "auctions_total",
 				Help:      "Total number of auctions",
 			},
-			[]string{"status", "media_type"},
+			[]string{"status", "media_type", "media_subtype"},
 		),
 		AuctionDuration: prometheus.NewHistogramVec(
 			prometheus.HistogramOpts{
@@ -284,7 +316,7 @@ func createTestMetricsWithAll(namespace string) *Metrics {
 				Help:      "Auction duration in seconds",
 				Buckets:   []float64{.01, .025, .05, .1, .25, .5, .75, 1, 1.5, 2},
 			},
-			[]string{"media_type"},
+			[]string{"media_type", "media_subtype"},
 		),
 		BidsReceived: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
@@ -292,7 +324,7 @@ func createTestMetricsWithAll(namespace string) *Metrics {
 				Name:      "bids_received_total",
 				Help:      "Total number of bids received",
 			},
-			[]string{"bidder", "media_type"},
+			[]string{"bidder", "media_type", "media_subtype"},
 		),
 		BidCPM: prometheus.NewHistogramVec(
 			prometheus.HistogramOpts{
@@ -301,7 +333,7 @@ func createTestMetricsWithAll(namespace string) *Metrics {
 				Help:      "Bid CPM distribution",
 				Buckets:   []float64{0.1, 0.5, 1, 2, 3, 5, 10, 20, 50},
 			},
-			[]string{"bidder", "media_type"},
+			[]string{"bidder", "media_type", "media_subtype"},
 		),
 		BiddersSelected: prometheus.NewHistogramVec(
 			prometheus.HistogramOpts{
@@ -513,22 +545,27 @@ func TestRecordAuction(t *testing.T) {
 	m := createTestMetricsWithAll("test_auction")
 
 	duration := 100 * time.Millisecond
-	m.RecordAuction("success", "banner", duration, 5, 2)
+	m.RecordAuction("success", "banner", "none", duration, 5, 2)
+	m.RecordAuction("success", "video", "outstream", duration, 5, 2)
+	m.RecordAuction("success", "video", "pod", duration, 5, 2)
 
 	// Verify auction total
-	count := testutil.ToFloat64(m.AuctionsTotal.WithLabelValues("success", "banner"))
+	count := testutil.ToFloat64(m.AuctionsTotal.WithLabelValues("success", "banner", "none"))
 	if count != 1 {
 		t.Errorf("Expected 1 auction, got %v", count)
 	}
+	if n := testutil.CollectAndCount(m.AuctionsTotal); n != 3 {
+		t.Errorf("Expected a series per media subtype, got %d", n)
+	}
 }
 
 func TestRecordBid(t *testing.T) {
 	m := createTestMetricsWithAll("test_bid")
 
-	m.RecordBid("appnexus", "banner", 2.5)
-	m.RecordBid("appnexus", "banner", 3.0)
+	m.RecordBid("appnexus", "banner", "none", 2.5)
+	m.RecordBid("appnexus", "banner", "none", 3.0)
 
-	count := testutil.ToFloat64(m.BidsReceived.WithLabelValues("appnexus", "banner"))
+	count := testutil.ToFloat64(m.BidsReceived.WithLabelValues("appnexus", "banner", "none"))
 	if count != 2 {
 		t.Errorf("Expected 2 bids, got %v", count)
 	}
@@ -665,6 +702,68 @@ func TestRecordConsentSignal(t *testing.T) {
 	}
 }
 
+func TestRecordLegacyUpgrade(t *testing.T) {
+	m := &Metrics{
+		LegacyUpgrades: prometheus.NewCounterVec(
+			prometheus.CounterOpts{Namespace: "test_pbs", Name: "openrtb_legacy_upgrades_total"},
+			[]string{"rule"},
+		),
+	}
+
+	m.RecordLegacyUpgrade("protocols")
+	m.RecordLegacyUpgrade("protocols")
+	m.RecordLegacyUpgrade("gender")
+
+	if v := testutil.ToFloat64(m.LegacyUpgrades.WithLabelValues("protocols")); v != 2 {
+		t.Errorf("expected 2 protocol upgrades, got %v", v)
+	}
+	if v := testutil.ToFloat64(m.LegacyUpgrades.WithLabelValues("gender")); v != 1 {
+		t.Errorf("expected 1 gender upgrade, got %v", v)
+	}
+}
+
+func TestPublisherSLOBurnRate(t *testing.T) {
+	m := &Metrics{
+		PublisherSLOBurn: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Namespace: "test_pbs", Name: "publisher_slo_burn_rate"},
+			[]string{"publisher", "window"},
+		),
+	}
+
+	m.SetPublisherSLOBurnRate("pub-1", "1h", 2.5)
+	m.SetPublisherSLOBurnRate("pub-1", "5m", 14.4)
+	m.SetPublisherSLOBurnRate("pub-2", "1h", 0.5)
+
+	if v := testutil.ToFloat64(m.PublisherSLOBurn.WithLabelValues("pub-1", "5m")); v != 14.4 {
+		t.Errorf("expected burn rate 14.4, got %v", v)
+	}
+
+	m.DeletePublisherSLO("pub-1")
+	if n := testutil.CollectAndCount(m.PublisherSLOBurn); n != 1 {
+		t.Errorf("expected only pub-2's gauge left, got %d series", n)
+	}
+}
+
+func TestRecordConsentString(t *testing.T) {
+	m := &Metrics{
+		ConsentStrings: prometheus.NewCounterVec(
+			prometheus.CounterOpts{Namespace: "test_pbs", Name: "consent_strings_total"},
+			[]string{"publisher", "type", "outcome"},
+		),
+	}
+
+	m.RecordConsentString("pub-1", "tcf", "valid")
+	m.RecordConsentString("pub-1", "tcf", "no_consent")
+	m.RecordConsentString("pub-1", "tcf", "no_consent")
+
+	if v := testutil.ToFloat64(m.ConsentStrings.WithLabelValues("pub-1", "tcf", "no_consent")); v != 2 {
+		t.Errorf("expected 2 malformed strings, got %v", v)
+	}
+	if v := testutil.ToFloat64(m.ConsentStrings.WithLabelValues("pub-1", "tcf", "valid")); v != 1 {
+		t.Errorf("expected 1 valid string, got %v", v)
+	}
+}
+
 func TestSetBidderCircuitState(t *testing.T) {
 	m := createTestMetricsWithAll("test_circuit_state")
 
@@ -752,6 +851,22 @@ func TestRecordBidderCircuitRejected(t *testing.T) {
 	}
 }
 
+func TestRecordBidderQPSSuppressed(t *testing.T) {
+	m := &Metrics{
+		BidderQPSSuppressed: prometheus.NewCounterVec(
+			prometheus.CounterOpts{Namespace: "test_qps", Name: "bidder_qps_suppressed_total"},
+			[]string{"bidder"},
+		),
+	}
+
+	m.RecordBidderQPSSuppressed("bidderA")
+	m.RecordBidderQPSSuppressed("bidderA")
+
+	if got := testutil.ToFloat64(m.BidderQPSSuppressed.WithLabelValues("bidderA")); got != 2 {
+		t.Errorf("Expected 2 suppressed calls for bidderA, got %v", got)
+	}
+}
+
 func TestRecordBidderCircuitStateChange(t *testing.T) {
 	m := createTestMetricsWithAll("test_circuit_state_change")
 
@@ -896,3 +1011,553 @@ func TestBidderCircuitMetrics_FullScenario(t *testing.T) {
 		t.Errorf("Expected 3 total state transitions, got %d", totalTransitions)
 	}
 }
+
+func TestRecordFanoutEarlyCompletion(t *testing.T) {
+	m := &Metrics{
+		FanoutSavedMillis: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{Namespace: "test_pbs", Name: "fanout_saved_milliseconds"},
+			[]string{},
+		),
+	}
+
+	m.RecordFanoutEarlyCompletion(750 * time.Millisecond)
+
+	if count := testutil.CollectAndCount(m.FanoutSavedMillis); count != 1 {
+		t.Errorf("expected 1 fanout series, got %d", count)
+	}
+}
+
+func TestRecordWinQueueEvent(t *testing.T) {
+	m := &Metrics{
+		WinQueueEvents: prometheus.NewCounterVec(
+			prometheus.CounterOpts{Namespace: "test_pbs", Name: "win_queue_events_total"},
+			[]string{"type", "status"},
+		),
+	}
+
+	m.RecordWinQueueEvent("win", "enqueued")
+	m.RecordWinQueueEvent("win", "processed")
+	m.RecordWinQueueEvent("win", "processed")
+
+	if v := testutil.ToFloat64(m.WinQueueEvents.WithLabelValues("win", "processed")); v != 2 {
+		t.Errorf("expected 2 processed win events, got %v", v)
+	}
+}
+
+func TestRecordBidderNotice(t *testing.T) {
+	m := &Metrics{
+		BidderNotices: prometheus.NewCounterVec(
+			prometheus.CounterOpts{Namespace: "test_pbs", Name: "bidder_notices_total"},
+			[]string{"bidder", "type", "status"},
+		),
+	}
+
+	m.RecordBidderNotice("rubicon", "loss", "sent")
+	m.RecordBidderNotice("rubicon", "loss", "failed")
+	m.RecordBidderNotice("rubicon", "loss", "sent")
+
+	if v := testutil.ToFloat64(m.BidderNotices.WithLabelValues("rubicon", "loss", "sent")); v != 2 {
+		t.Errorf("expected 2 sent loss notices, got %v", v)
+	}
+}
+
+func TestRecordAuctionRegistry(t *testing.T) {
+	m := &Metrics{
+		AuctionRegistryRecords: prometheus.NewCounterVec(
+			prometheus.CounterOpts{Namespace: "test_pbs", Name: "auction_registry_records_total"},
+			[]string{"status"},
+		),
+	}
+
+	m.RecordAuctionRegistry("written")
+	m.RecordAuctionRegistry("written")
+	m.RecordAuctionRegistry("dropped")
+
+	if v := testutil.ToFloat64(m.AuctionRegistryRecords.WithLabelValues("written")); v != 2 {
+		t.Errorf("expected 2 written summaries, got %v", v)
+	}
+}
+
+func TestRecordAuctionTrail(t *testing.T) {
+	m := &Metrics{
+		AuctionTrailRecords: prometheus.NewCounterVec(
+			prometheus.CounterOpts{Namespace: "test_pbs", Name: "auction_trail_records_total"},
+			[]string{"status"},
+		),
+	}
+
+	m.RecordAuctionTrail("written")
+	m.RecordAuctionTrail("failed")
+
+	if v := testutil.ToFloat64(m.AuctionTrailRecords.WithLabelValues("failed")); v != 1 {
+		t.Errorf("expected 1 failed trail, got %v", v)
+	}
+}
+
+func TestRecordCookieSync(t *testing.T) {
+	m := &Metrics{
+		CookieSyncRequests: prometheus.NewCounterVec(
+			prometheus.CounterOpts{Namespace: "test_pbs", Name: "cookie_sync_requests_total"},
+			[]string{"status"},
+		),
+		CookieSyncBidders: prometheus.NewCounterVec(
+			prometheus.CounterOpts{Namespace: "test_pbs", Name: "cookie_sync_bidders_total"},
+			[]string{"bidder", "status"},
+		),
+	}
+
+	m.RecordCookieSync("ok")
+	m.RecordBidderSync("appnexus", "synced")
+	m.RecordBidderSync("rubicon", "privacy_blocked")
+
+	if v := testutil.ToFloat64(m.CookieSyncRequests.WithLabelValues("ok")); v != 1 {
+		t.Errorf("expected 1 ok sync, got %v", v)
+	}
+	if v := testutil.ToFloat64(m.CookieSyncBidders.WithLabelValues("rubicon", "privacy_blocked")); v != 1 {
+		t.Errorf("expected 1 privacy-blocked rubicon sync, got %v", v)
+	}
+}
+
+func TestRecordVideoEventDeduplicated(t *testing.T) {
+	m := &Metrics{
+		VideoEventsDeduplicated: prometheus.NewCounterVec(
+			prometheus.CounterOpts{Namespace: "test_pbs", Name: "video_events_deduplicated_total"},
+			[]string{"event"},
+		),
+	}
+
+	m.RecordVideoEventDeduplicated("firstQuartile")
+	m.RecordVideoEventDeduplicated("firstQuartile")
+	m.RecordVideoEventDeduplicated("complete")
+
+	if v := testutil.ToFloat64(m.VideoEventsDeduplicated.WithLabelValues("firstQuartile")); v != 2 {
+		t.Errorf("expected 2 deduplicated firstQuartile events, got %v", v)
+	}
+}
+
+func TestRecordBidCache(t *testing.T) {
+	m := &Metrics{
+		BidCacheBytesWritten: prometheus.NewCounterVec(
+			prometheus.CounterOpts{Namespace: "test_pbs", Name: "bid_cache_bytes_written_total"},
+			[]string{"publisher"},
+		),
+		BidCacheStorageBytes: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Namespace: "test_pbs", Name: "bid_cache_storage_bytes"},
+			[]string{"publisher"},
+		),
+		BidCacheRejected: prometheus.NewCounterVec(
+			prometheus.CounterOpts{Namespace: "test_pbs", Name: "bid_cache_rejected_total"},
+			[]string{"publisher", "reason"},
+		),
+	}
+
+	m.RecordBidCacheWrite("pub-1", 100, 100)
+	m.RecordBidCacheWrite("pub-1", 50, 150)
+	m.RecordBidCacheRejected("pub-1", "quota_exceeded")
+
+	if v := testutil.ToFloat64(m.BidCacheBytesWritten.WithLabelValues("pub-1")); v != 150 {
+		t.Errorf("expected 150 bytes written, got %v", v)
+	}
+	if v := testutil.ToFloat64(m.BidCacheStorageBytes.WithLabelValues("pub-1")); v != 150 {
+		t.Errorf("expected 150 bytes stored, got %v", v)
+	}
+	if v := testutil.ToFloat64(m.BidCacheRejected.WithLabelValues("pub-1", "quota_exceeded")); v != 1 {
+		t.Errorf("expected 1 rejection, got %v", v)
+	}
+}
+
+func TestRecordLRU(t *testing.T) {
+	m := &Metrics{
+		LRUEvictions: prometheus.NewCounterVec(
+			prometheus.CounterOpts{Namespace: "test_pbs", Name: "lru_evictions_total"},
+			[]string{"cache", "reason"},
+		),
+		LRUEntries: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Namespace: "test_pbs", Name: "lru_entries"},
+			[]string{"cache"},
+		),
+		LRUBytes: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Namespace: "test_pbs", Name: "lru_estimated_bytes"},
+			[]string{"cache"},
+		),
+	}
+
+	m.RecordLRUEviction("pause_ad_sessions", "max_entries")
+	m.SetLRUSize("pause_ad_sessions", 10, 2048)
+
+	if v := testutil.ToFloat64(m.LRUEvictions.WithLabelValues("pause_ad_sessions", "max_entries")); v != 1 {
+		t.Errorf("expected 1 eviction, got %v", v)
+	}
+	if v := testutil.ToFloat64(m.LRUEntries.WithLabelValues("pause_ad_sessions")); v != 10 {
+		t.Errorf("expected 10 entries, got %v", v)
+	}
+	if v := testutil.ToFloat64(m.LRUBytes.WithLabelValues("pause_ad_sessions")); v != 2048 {
+		t.Errorf("expected 2048 bytes, got %v", v)
+	}
+}
+
+func TestRecordAuctionAllocations(t *testing.T) {
+	m := &Metrics{
+		AuctionAllocBytes:   prometheus.NewHistogram(prometheus.HistogramOpts{Namespace: "test_pbs", Name: "auction_alloc_bytes"}),
+		AuctionAllocObjects: prometheus.NewHistogram(prometheus.HistogramOpts{Namespace: "test_pbs", Name: "auction_alloc_objects"}),
+		AuctionHeapBytes:    prometheus.NewHistogram(prometheus.HistogramOpts{Namespace: "test_pbs", Name: "auction_heap_bytes"}),
+	}
+
+	m.RecordAuctionAllocations(4096, 32, 1<<20)
+	m.RecordAuctionAllocations(8192, 64, 2<<20)
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(m.AuctionAllocBytes, m.AuctionAllocObjects, m.AuctionHeapBytes)
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+	if len(families) != 3 {
+		t.Fatalf("expected 3 histograms, got %d", len(families))
+	}
+	for _, mf := range families {
+		if c := mf.GetMetric()[0].GetHistogram().GetSampleCount(); c != 2 {
+			t.Errorf("%s: expected 2 observations, got %d", mf.GetName(), c)
+		}
+	}
+	sum := func(name string) float64 {
+		for _, mf := range families {
+			if mf.GetName() == name {
+				return mf.GetMetric()[0].GetHistogram().GetSampleSum()
+			}
+		}
+		return 0
+	}
+	if v := sum("test_pbs_auction_alloc_bytes"); v != 12288 {
+		t.Errorf("expected 12288 allocated bytes, got %v", v)
+	}
+}
+
+func TestRecordBidderPayloadSize(t *testing.T) {
+	m := &Metrics{
+		BidderRequestBytes: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{Namespace: "test_pbs", Name: "bidder_request_bytes", Buckets: payloadSizeBuckets},
+			[]string{"bidder"},
+		),
+		BidderResponseBytes: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{Namespace: "test_pbs", Name: "bidder_response_bytes", Buckets: payloadSizeBuckets},
+			[]string{"bidder"},
+		),
+		BidderResponsesOversized: prometheus.NewCounterVec(
+			prometheus.CounterOpts{Namespace: "test_pbs", Name: "bidder_responses_oversized_total"},
+			[]string{"bidder"},
+		),
+	}
+
+	m.RecordBidderPayloadSize("rubicon", 2048, 4096)
+	m.RecordBidderResponseOversized("rubicon")
+
+	if c := testutil.CollectAndCount(m.BidderRequestBytes); c != 1 {
+		t.Errorf("expected 1 request size series, got %d", c)
+	}
+	if c := testutil.CollectAndCount(m.BidderResponseBytes); c != 1 {
+		t.Errorf("expected 1 response size series, got %d", c)
+	}
+	if v := testutil.ToFloat64(m.BidderResponsesOversized.WithLabelValues("rubicon")); v != 1 {
+		t.Errorf("expected 1 oversized response, got %v", v)
+	}
+}
+
+func TestRecordCompression(t *testing.T) {
+	m := &Metrics{
+		CompressedResponses: prometheus.NewCounterVec(
+			prometheus.CounterOpts{Namespace: "test_pbs", Name: "compressed_responses_total"},
+			[]string{"encoding"},
+		),
+		CompressionOriginalBytes: prometheus.NewCounterVec(
+			prometheus.CounterOpts{Namespace: "test_pbs", Name: "compression_original_bytes_total"},
+			[]string{"encoding"},
+		),
+		CompressionBytesSaved: prometheus.NewCounterVec(
+			prometheus.CounterOpts{Namespace: "test_pbs", Name: "compression_bytes_saved_total"},
+			[]string{"encoding"},
+		),
+	}
+
+	m.RecordCompression("br", 10000, 2500)
+	m.RecordCompression("br", 300, 310) // Incompressible: nothing saved
+
+	if v := testutil.ToFloat64(m.CompressedResponses.WithLabelValues("br")); v != 2 {
+		t.Errorf("expected 2 compressed responses, got %v", v)
+	}
+	if v := testutil.ToFloat64(m.CompressionOriginalBytes.WithLabelValues("br")); v != 10300 {
+		t.Errorf("expected 10300 original bytes, got %v", v)
+	}
+	if v := testutil.ToFloat64(m.CompressionBytesSaved.WithLabelValues("br")); v != 7500 {
+		t.Errorf("expected 7500 bytes saved, got %v", v)
+	}
+}
+
+func TestRecordBidPriceCapExceeded(t *testing.T) {
+	m := &Metrics{
+		BidsOverPriceCap: prometheus.NewCounterVec(
+			prometheus.CounterOpts{Namespace: "test_pbs", Name: "bids_over_price_cap_total"},
+			[]string{"bidder"},
+		),
+	}
+
+	m.RecordBidPriceCapExceeded("rubicon")
+	m.RecordBidPriceCapExceeded("rubicon")
+
+	if v := testutil.ToFloat64(m.BidsOverPriceCap.WithLabelValues("rubicon")); v != 2 {
+		t.Errorf("expected 2 capped bids, got %v", v)
+	}
+}
+
+func TestRecordCreativeSanitization(t *testing.T) {
+	m := &Metrics{
+		CreativeSanitizations: prometheus.NewCounterVec(
+			prometheus.CounterOpts{Namespace: "test_pbs", Name: "creative_sanitization_actions_total"},
+			[]string{"bidder", "action"},
+		),
+	}
+
+	m.RecordCreativeSanitization("rubicon", "handler_stripped", 3)
+	m.RecordCreativeSanitization("rubicon", "handler_stripped", 1)
+
+	if v := testutil.ToFloat64(m.CreativeSanitizations.WithLabelValues("rubicon", "handler_stripped")); v != 4 {
+		t.Errorf("expected 4 stripped handlers, got %v", v)
+	}
+}
+
+func TestRecordBidLanguage(t *testing.T) {
+	m := &Metrics{
+		BidsByLanguage: prometheus.NewCounterVec(
+			prometheus.CounterOpts{Namespace: "test_pbs", Name: "bids_by_language_total"},
+			[]string{"bidder", "language", "outcome"},
+		),
+	}
+
+	m.RecordBidLanguage("rubicon", "fr", "accepted")
+	m.RecordBidLanguage("rubicon", "en", "rejected")
+	m.RecordBidLanguage("rubicon", "en", "rejected")
+
+	if v := testutil.ToFloat64(m.BidsByLanguage.WithLabelValues("rubicon", "fr", "accepted")); v != 1 {
+		t.Errorf("expected 1 accepted French bid, got %v", v)
+	}
+	if v := testutil.ToFloat64(m.BidsByLanguage.WithLabelValues("rubicon", "en", "rejected")); v != 2 {
+		t.Errorf("expected 2 rejected English bids, got %v", v)
+	}
+}
+
+func TestRecordCreativeApproval(t *testing.T) {
+	m := &Metrics{
+		CreativeApprovals: prometheus.NewCounterVec(
+			prometheus.CounterOpts{Namespace: "test_pbs", Name: "creative_approvals_total"},
+			[]string{"bidder", "outcome"},
+		),
+	}
+
+	m.RecordCreativeApproval("rubicon", "held")
+	m.RecordCreativeApproval("rubicon", "held")
+	m.RecordCreativeApproval("rubicon", "approved")
+
+	if v := testutil.ToFloat64(m.CreativeApprovals.WithLabelValues("rubicon", "held")); v != 2 {
+		t.Errorf("expected 2 held bids, got %v", v)
+	}
+}
+
+func TestRecordGeoRejection(t *testing.T) {
+	m := &Metrics{
+		GeoRejections: prometheus.NewCounterVec(
+			prometheus.CounterOpts{Namespace: "test_pbs", Name: "geo_rejections_total"},
+			[]string{"country", "reason"},
+		),
+	}
+
+	m.RecordGeoRejection("PRK", "global_blocked")
+	m.RecordGeoRejection("PRK", "global_blocked")
+	m.RecordGeoRejection("DEU", "publisher_not_allowed")
+
+	if v := testutil.ToFloat64(m.GeoRejections.WithLabelValues("PRK", "global_blocked")); v != 2 {
+		t.Errorf("expected 2 global rejections, got %v", v)
+	}
+}
+
+func TestRecordAuctionFill(t *testing.T) {
+	m := &Metrics{
+		Fills: prometheus.NewCounterVec(
+			prometheus.CounterOpts{Namespace: "test_pbs", Name: "fills_total"},
+			[]string{"media_type", "source"},
+		),
+	}
+
+	m.RecordAuctionFill("video", "paid")
+	m.RecordAuctionFill("video", "house")
+	m.RecordAuctionFill("video", "house")
+
+	if v := testutil.ToFloat64(m.Fills.WithLabelValues("video", "house")); v != 2 {
+		t.Errorf("expected 2 house fills, got %v", v)
+	}
+	if v := testutil.ToFloat64(m.Fills.WithLabelValues("video", "paid")); v != 1 {
+		t.Errorf("expected 1 paid fill, got %v", v)
+	}
+}
+
+func TestRecordBidOutcome(t *testing.T) {
+	m := &Metrics{
+		BidPriceLandscape: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{Namespace: "test_pbs", Name: "bid_price_landscape", Buckets: bidCPMBuckets},
+			[]string{"bidder", "media_type", "media_subtype", "outcome"},
+		),
+		BidsPerRequest: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{Namespace: "test_pbs", Name: "bids_per_request", Buckets: []float64{0, 1, 2, 3, 5, 10, 20}},
+			[]string{"bidder", "media_type", "media_subtype"},
+		),
+	}
+
+	m.RecordBidOutcome("appnexus", "video", "pod", "won", 4.5)
+	m.RecordBidOutcome("appnexus", "video", "pod", "lost", 2.0)
+	m.RecordBidOutcome("rubicon", "video", "pod", "below_floor", 0.4)
+	m.RecordBidsPerRequest("appnexus", "video", "pod", 2)
+	m.RecordBidsPerRequest("rubicon", "video", "pod", 0)
+
+	if count := testutil.CollectAndCount(m.BidPriceLandscape); count != 3 {
+		t.Errorf("expected 3 outcome series, got %d", count)
+	}
+	if count := testutil.CollectAndCount(m.BidsPerRequest); count != 2 {
+		t.Errorf("expected 2 bids-per-request series, got %d", count)
+	}
+}
+
+func TestRecordRedisCommand(t *testing.T) {
+	m := &Metrics{
+		RedisCommandLatency: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{Namespace: "test_pbs", Name: "redis_command_duration_seconds"},
+			[]string{"command"},
+		),
+		RedisCommandErrors: prometheus.NewCounterVec(
+			prometheus.CounterOpts{Namespace: "test_pbs", Name: "redis_command_errors_total"},
+			[]string{"command"},
+		),
+		RedisDegraded: prometheus.NewGauge(prometheus.GaugeOpts{Namespace: "test_pbs", Name: "redis_degraded"}),
+	}
+
+	m.RecordRedisCommand("get", 2*time.Millisecond, false)
+	m.RecordRedisCommand("get", 80*time.Millisecond, true)
+
+	if n := testutil.CollectAndCount(m.RedisCommandLatency); n != 1 {
+		t.Errorf("expected one latency series, got %d", n)
+	}
+	if v := testutil.ToFloat64(m.RedisCommandErrors.WithLabelValues("get")); v != 1 {
+		t.Errorf("expected 1 get error, got %v", v)
+	}
+
+	m.SetRedisDegraded(true)
+	if v := testutil.ToFloat64(m.RedisDegraded); v != 1 {
+		t.Errorf("expected degraded gauge 1, got %v", v)
+	}
+	m.SetRedisDegraded(false)
+	if v := testutil.ToFloat64(m.RedisDegraded); v != 0 {
+		t.Errorf("expected degraded gauge 0, got %v", v)
+	}
+}
+
+func TestCurrencyRateMetrics(t *testing.T) {
+	m := &Metrics{
+		CurrencyRateFetches: prometheus.NewCounterVec(
+			prometheus.CounterOpts{Namespace: "test_pbs", Name: "currency_rate_fetches_total"},
+			[]string{"source", "result"},
+		),
+		CurrencyRatesAge:   prometheus.NewGauge(prometheus.GaugeOpts{Namespace: "test_pbs", Name: "currency_rates_age_seconds"}),
+		CurrencyRatesStale: prometheus.NewGauge(prometheus.GaugeOpts{Namespace: "test_pbs", Name: "currency_rates_stale"}),
+	}
+
+	m.RecordCurrencyRateFetch("ecb", "success")
+	m.RecordCurrencyRateFetch("ecb", "error")
+	if v := testutil.ToFloat64(m.CurrencyRateFetches.WithLabelValues("ecb", "error")); v != 1 {
+		t.Errorf("expected 1 failed fetch, got %v", v)
+	}
+
+	m.SetCurrencyRatesAge(90*time.Second, true)
+	if v := testutil.ToFloat64(m.CurrencyRatesAge); v != 90 {
+		t.Errorf("expected age 90s, got %v", v)
+	}
+	if v := testutil.ToFloat64(m.CurrencyRatesStale); v != 1 {
+		t.Errorf("expected stale gauge 1, got %v", v)
+	}
+}
+
+func TestAnalyticsMetrics(t *testing.T) {
+	m := &Metrics{
+		AnalyticsEvents: prometheus.NewCounterVec(
+			prometheus.CounterOpts{Namespace: "test_pbs", Name: "analytics_events_total"},
+			[]string{"sink", "status"},
+		),
+		AnalyticsQueueDepth: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Namespace: "test_pbs", Name: "analytics_queue_depth"},
+			[]string{"sink"},
+		),
+		KafkaDeliveries: prometheus.NewCounterVec(
+			prometheus.CounterOpts{Namespace: "test_pbs", Name: "kafka_messages_total"},
+			[]string{"topic", "status"},
+		),
+	}
+
+	m.RecordAnalyticsEvents("kafka", "written", 500)
+	m.RecordAnalyticsEvents("kafka", "written", 20)
+	if v := testutil.ToFloat64(m.AnalyticsEvents.WithLabelValues("kafka", "written")); v != 520 {
+		t.Errorf("expected 520 written events, got %v", v)
+	}
+
+	m.SetAnalyticsQueueDepth("kafka", 42)
+	if v := testutil.ToFloat64(m.AnalyticsQueueDepth.WithLabelValues("kafka")); v != 42 {
+		t.Errorf("expected queue depth 42, got %v", v)
+	}
+
+	m.RecordKafkaDelivery("pbs-wins", "delivered", 3)
+	if v := testutil.ToFloat64(m.KafkaDeliveries.WithLabelValues("pbs-wins", "delivered")); v != 3 {
+		t.Errorf("expected 3 delivered messages, got %v", v)
+	}
+}
+
+func TestDeviceGraphMetrics(t *testing.T) {
+	m := &Metrics{
+		DeviceGraphLookups: prometheus.NewCounterVec(
+			prometheus.CounterOpts{Namespace: "test_pbs", Name: "device_graph_lookups_total"},
+			[]string{"result"},
+		),
+	}
+
+	m.RecordDeviceGraphLookup("cached")
+	m.RecordDeviceGraphLookup("cached")
+	if v := testutil.ToFloat64(m.DeviceGraphLookups.WithLabelValues("cached")); v != 2 {
+		t.Errorf("expected 2 cached lookups, got %v", v)
+	}
+}
+
+func TestIDModuleMetrics(t *testing.T) {
+	m := &Metrics{
+		IDModuleLookups: prometheus.NewCounterVec(
+			prometheus.CounterOpts{Namespace: "test_pbs", Name: "id_module_lookups_total"},
+			[]string{"module", "result"},
+		),
+	}
+
+	m.RecordIDModuleLookup("uid2", "resolved")
+	m.RecordIDModuleLookup("rampid", "circuit_open")
+	if v := testutil.ToFloat64(m.IDModuleLookups.WithLabelValues("uid2", "resolved")); v != 1 {
+		t.Errorf("expected 1 resolved uid2 lookup, got %v", v)
+	}
+}
+
+func TestUID2TokenMetrics(t *testing.T) {
+	m := &Metrics{
+		UID2Tokens: prometheus.NewCounterVec(
+			prometheus.CounterOpts{Namespace: "test_pbs", Name: "uid2_tokens_total"},
+			[]string{"status"},
+		),
+	}
+
+	m.RecordUID2Token("valid")
+	m.RecordUID2Token("valid")
+	m.RecordUID2Token("expired")
+	if v := testutil.ToFloat64(m.UID2Tokens.WithLabelValues("valid")); v != 2 {
+		t.Errorf("expected 2 valid tokens, got %v", v)
+	}
+}
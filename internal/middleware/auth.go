@@ -36,6 +36,7 @@ type AuthConfig struct {
 	APIKeys     map[string]string // key -> publisher ID mapping (local fallback)
 	HeaderName  string            // Header to check for API key (default: X-API-Key)
 	BypassPaths []string          // Paths that don't require auth (e.g., /health, /status)
+	PublicReads []string          // Paths whose GET/HEAD requests don't require auth (e.g., /cache)
 	RedisURL    string            // Redis URL for shared API keys
 	UseRedis    bool              // Whether to use Redis for API key validation
 }
@@ -52,6 +53,9 @@ func DefaultAuthConfig() *AuthConfig {
 		// SECURITY: /metrics and /admin/* endpoints now require authentication
 		// Removed /metrics, /admin/dashboard, /admin/metrics from bypass list (CVE-2026-XXXX)
 		BypassPaths: []string{"/health", "/status", "/info/bidders", "/cookie_sync", "/setuid", "/optout"},
+		// Players fetch cached markup by UUID and their signed config, and
+		// Prebid.js its s2sConfig; writes still need a publisher key
+		PublicReads: []string{"/cache", "/video/config", "/prebid/settings"},
 		// Note: /openrtb2/auction is conditionally added to bypass list in cmd/server/main.go
 		// based on whether PublisherAuth is enabled (primary auth) or disabled (fallback to API key)
 		RedisURL: redisURL,
@@ -194,6 +198,7 @@ func (a *Auth) Middleware(next http.Handler) http.Handler {
 		a.mu.RLock()
 		enabled := a.config.Enabled
 		bypassPaths := a.config.BypassPaths
+		publicReads := a.config.PublicReads
 		headerName := a.config.HeaderName
 		a.mu.RUnlock()
 
@@ -216,6 +221,15 @@ func (a *Auth) Middleware(next http.Handler) http.Handler {
 			}
 		}
 
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			for _, path := range publicReads {
+				if r.URL.Path == path {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+		}
+
 		// Get API key from header
 		apiKey := r.Header.Get(headerName)
 		if apiKey == "" {
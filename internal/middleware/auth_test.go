@@ -121,6 +121,39 @@ func TestAuthMiddlewareBearerToken(t *testing.T) {
 	}
 }
 
+func TestAuthMiddlewarePublicReads(t *testing.T) {
+	auth := NewAuth(&AuthConfig{
+		Enabled:     true,
+		APIKeys:     map[string]string{"key1": "pub1"},
+		HeaderName:  "X-API-Key",
+		PublicReads: []string{"/cache"},
+	})
+	defer auth.Shutdown()
+
+	handler := auth.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		method string
+		path   string
+		want   int
+	}{
+		{http.MethodGet, "/cache", http.StatusOK},
+		{http.MethodHead, "/cache", http.StatusOK},
+		{http.MethodPost, "/cache", http.StatusUnauthorized},
+		{http.MethodGet, "/cache/other", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		if rr.Code != tt.want {
+			t.Errorf("%s %s: expected %d, got %d", tt.method, tt.path, tt.want, rr.Code)
+		}
+	}
+}
+
 func TestAuthMiddlewareBypassPaths(t *testing.T) {
 	auth := NewAuth(&AuthConfig{
 		Enabled:     true,
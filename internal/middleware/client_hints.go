@@ -0,0 +1,251 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+// User-Agent Client Hints request headers. The first three are low entropy
+// and sent by Chromium browsers by default; the rest only after Accept-CH.
+const (
+	HeaderSecCHUA                = "Sec-CH-UA"
+	HeaderSecCHUAMobile          = "Sec-CH-UA-Mobile"
+	HeaderSecCHUAPlatform        = "Sec-CH-UA-Platform"
+	HeaderSecCHUAFullVersionList = "Sec-CH-UA-Full-Version-List"
+	HeaderSecCHUAPlatformVersion = "Sec-CH-UA-Platform-Version"
+	HeaderSecCHUAArch            = "Sec-CH-UA-Arch"
+	HeaderSecCHUABitness         = "Sec-CH-UA-Bitness"
+	HeaderSecCHUAModel           = "Sec-CH-UA-Model"
+)
+
+// highEntropyHints are requested with Accept-CH when high entropy
+// harvesting is enabled
+var highEntropyHints = []string{
+	HeaderSecCHUAFullVersionList,
+	HeaderSecCHUAPlatformVersion,
+	HeaderSecCHUAArch,
+	HeaderSecCHUABitness,
+	HeaderSecCHUAModel,
+}
+
+// ClientHintsConfig controls User-Agent Client Hints harvesting
+type ClientHintsConfig struct {
+	Enabled     bool // Parse Sec-CH-UA headers into device.sua
+	HighEntropy bool // Request and forward high-entropy hints (with consent)
+}
+
+// DefaultClientHintsConfig reads UA_CLIENT_HINTS_ENABLED and
+// UA_CLIENT_HINTS_HIGH_ENTROPY, both on by default
+func DefaultClientHintsConfig() ClientHintsConfig {
+	return ClientHintsConfig{
+		Enabled:     getEnvBool("UA_CLIENT_HINTS_ENABLED", true),
+		HighEntropy: getEnvBool("UA_CLIENT_HINTS_HIGH_ENTROPY", true),
+	}
+}
+
+type clientHintsContextKey struct{}
+
+// ClientHints asks browsers for User-Agent Client Hints and parses them for
+// the auction and video handlers, since Chrome's frozen User-Agent string no
+// longer carries full browser and platform versions
+type ClientHints struct {
+	config   ClientHintsConfig
+	acceptCH string
+}
+
+// NewClientHints creates the client hints middleware
+func NewClientHints(config ClientHintsConfig) *ClientHints {
+	hints := []string{HeaderSecCHUA, HeaderSecCHUAMobile, HeaderSecCHUAPlatform}
+	if config.HighEntropy {
+		hints = append(hints, highEntropyHints...)
+	}
+	return &ClientHints{config: config, acceptCH: strings.Join(hints, ", ")}
+}
+
+// Middleware advertises Accept-CH on document responses and stores the
+// request's parsed hints in the context; see DeviceSUA
+func (c *ClientHints) Middleware(next http.Handler) http.Handler {
+	if !c.config.Enabled {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", strings.Join([]string{HeaderSecCHUA, HeaderSecCHUAMobile, HeaderSecCHUAPlatform}, ", "))
+
+		if sua := ParseClientHints(r.Header, c.config.HighEntropy); sua != nil {
+			r = r.WithContext(context.WithValue(r.Context(), clientHintsContextKey{}, sua))
+		}
+		next.ServeHTTP(&clientHintsWriter{ResponseWriter: w, acceptCH: c.acceptCH}, r)
+	})
+}
+
+// isDocumentResponse reports whether a Content-Type is a document a browser
+// loads as a page or frame (ad tag HTML, VAST XML). Browsers ignore
+// Accept-CH on fetch and XHR responses, so JSON APIs don't need it.
+func isDocumentResponse(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	switch mediaType {
+	case "text/html", "application/xml", "text/xml":
+		return true
+	}
+	return strings.HasSuffix(mediaType, "+xml")
+}
+
+// clientHintsWriter adds Accept-CH just before the header is written, once
+// the handler has set the response's Content-Type
+type clientHintsWriter struct {
+	http.ResponseWriter
+	acceptCH    string
+	wroteHeader bool
+}
+
+func (w *clientHintsWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		h := w.ResponseWriter.Header()
+		if isDocumentResponse(h.Get("Content-Type")) {
+			h.Set("Accept-CH", w.acceptCH)
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *clientHintsWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher when the underlying writer supports it
+func (w *clientHintsWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (w *clientHintsWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// DeviceSUA fills device.sua from the request's client hints unless the
+// request already carries one, then drops high-entropy hints when PII may
+// not be collected (see ShouldCollectPII). Call it after the privacy
+// middleware has set the consent context.
+func DeviceSUA(ctx context.Context, device *openrtb.Device) {
+	if device == nil {
+		return
+	}
+	if device.SUA == nil {
+		sua, _ := ctx.Value(clientHintsContextKey{}).(*openrtb.UserAgent)
+		if sua == nil {
+			return
+		}
+		copied := *sua
+		device.SUA = &copied
+	}
+	if device.SUA.Source == openrtb.UASourceHighEntropy && !ShouldCollectPII(ctx) {
+		device.SUA = device.SUA.LowEntropy()
+	}
+}
+
+// ParseClientHints builds a structured user agent from Sec-CH-UA headers,
+// or returns nil when the browser sent none. High-entropy headers are
+// ignored unless highEntropy is set.
+func ParseClientHints(h http.Header, highEntropy bool) *openrtb.UserAgent {
+	brands := h.Get(HeaderSecCHUA)
+	if brands == "" {
+		return nil
+	}
+
+	sua := &openrtb.UserAgent{
+		Browsers: parseBrandList(brands),
+		Source:   openrtb.UASourceLowEntropy,
+	}
+	if platform := unquoteHint(h.Get(HeaderSecCHUAPlatform)); platform != "" {
+		sua.Platform = &openrtb.BrandVersion{Brand: platform}
+	}
+	switch h.Get(HeaderSecCHUAMobile) {
+	case "?1":
+		mobile := 1
+		sua.Mobile = &mobile
+	case "?0":
+		mobile := 0
+		sua.Mobile = &mobile
+	}
+	if !highEntropy {
+		return sua
+	}
+
+	high := false
+	if list := h.Get(HeaderSecCHUAFullVersionList); list != "" {
+		sua.Browsers = parseBrandList(list)
+		high = true
+	}
+	if version := unquoteHint(h.Get(HeaderSecCHUAPlatformVersion)); version != "" && sua.Platform != nil {
+		sua.Platform.Version = strings.Split(version, ".")
+		high = true
+	}
+	for header, field := range map[string]*string{
+		HeaderSecCHUAArch:    &sua.Architecture,
+		HeaderSecCHUABitness: &sua.Bitness,
+		HeaderSecCHUAModel:   &sua.Model,
+	} {
+		if v := unquoteHint(h.Get(header)); v != "" {
+			*field = v
+			high = true
+		}
+	}
+	if high {
+		sua.Source = openrtb.UASourceHighEntropy
+	}
+	return sua
+}
+
+// parseBrandList parses a structured header brand list such as
+// `"Chromium";v="124", "Not;A=Brand";v="99"`, dropping GREASE brands
+func parseBrandList(list string) []openrtb.BrandVersion {
+	var brands []openrtb.BrandVersion
+	for _, item := range strings.Split(list, ",") {
+		// Brands are quoted and may contain ';', so cut the brand first
+		item = strings.TrimSpace(item)
+		if !strings.HasPrefix(item, `"`) {
+			continue
+		}
+		end := strings.Index(item[1:], `"`)
+		if end < 0 {
+			continue
+		}
+		brand, params := item[1:end+1], item[end+2:]
+		if brand == "" || isGreaseBrand(brand) {
+			continue
+		}
+		bv := openrtb.BrandVersion{Brand: brand}
+		for _, param := range strings.Split(params, ";") {
+			key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if ok && key == "v" {
+				if v := unquoteHint(value); v != "" {
+					bv.Version = strings.Split(v, ".")
+				}
+			}
+		}
+		brands = append(brands, bv)
+	}
+	return brands
+}
+
+// isGreaseBrand reports whether a brand is one of the randomized entries
+// browsers add so servers don't depend on the list's exact contents, e.g.
+// "Not-A.Brand" or "Not A(Brand"
+func isGreaseBrand(brand string) bool {
+	return strings.HasPrefix(brand, "Not") && strings.Contains(brand, "Brand")
+}
+
+// unquoteHint trims a structured header string value
+func unquoteHint(v string) string {
+	return strings.Trim(strings.TrimSpace(v), `"`)
+}
@@ -0,0 +1,156 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+func chromeHints() http.Header {
+	h := http.Header{}
+	h.Set(HeaderSecCHUA, `"Chromium";v="124", "Google Chrome";v="124", "Not-A.Brand";v="99"`)
+	h.Set(HeaderSecCHUAMobile, "?0")
+	h.Set(HeaderSecCHUAPlatform, `"Windows"`)
+	h.Set(HeaderSecCHUAFullVersionList, `"Chromium";v="124.0.6367.91", "Google Chrome";v="124.0.6367.91", "Not;A=Brand";v="99.0.0.0"`)
+	h.Set(HeaderSecCHUAPlatformVersion, `"15.0.0"`)
+	h.Set(HeaderSecCHUAArch, `"x86"`)
+	h.Set(HeaderSecCHUABitness, `"64"`)
+	h.Set(HeaderSecCHUAModel, `""`)
+	return h
+}
+
+func TestParseClientHints(t *testing.T) {
+	sua := ParseClientHints(chromeHints(), true)
+	if sua == nil {
+		t.Fatal("expected client hints to parse")
+	}
+
+	wantBrowsers := []openrtb.BrandVersion{
+		{Brand: "Chromium", Version: []string{"124", "0", "6367", "91"}},
+		{Brand: "Google Chrome", Version: []string{"124", "0", "6367", "91"}},
+	}
+	if !reflect.DeepEqual(sua.Browsers, wantBrowsers) {
+		t.Errorf("unexpected browsers: %+v", sua.Browsers)
+	}
+	if sua.Platform == nil || sua.Platform.Brand != "Windows" || !reflect.DeepEqual(sua.Platform.Version, []string{"15", "0", "0"}) {
+		t.Errorf("unexpected platform: %+v", sua.Platform)
+	}
+	if sua.Mobile == nil || *sua.Mobile != 0 || sua.Architecture != "x86" || sua.Bitness != "64" || sua.Model != "" {
+		t.Errorf("unexpected device hints: %+v", sua)
+	}
+	if sua.Source != openrtb.UASourceHighEntropy {
+		t.Errorf("expected high entropy source, got %d", sua.Source)
+	}
+}
+
+func TestParseClientHints_LowEntropyOnly(t *testing.T) {
+	sua := ParseClientHints(chromeHints(), false)
+	if sua.Source != openrtb.UASourceLowEntropy || sua.Architecture != "" || len(sua.Platform.Version) != 0 {
+		t.Errorf("expected high entropy hints ignored, got %+v", sua)
+	}
+	if !reflect.DeepEqual(sua.Browsers[0].Version, []string{"124"}) {
+		t.Errorf("expected major version only, got %v", sua.Browsers[0].Version)
+	}
+
+	if ParseClientHints(http.Header{}, true) != nil {
+		t.Error("expected nil without Sec-CH-UA")
+	}
+}
+
+func TestClientHints_Middleware(t *testing.T) {
+	var device openrtb.Device
+	handler := NewClientHints(ClientHintsConfig{Enabled: true, HighEntropy: true}).Middleware(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			DeviceSUA(r.Context(), &device)
+		}))
+
+	req := httptest.NewRequest(http.MethodPost, "/openrtb2/auction", nil)
+	req.Header = chromeHints()
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if got := w.Header().Get("Accept-CH"); got != "" {
+		t.Errorf("expected no Accept-CH on a JSON API response, got %q", got)
+	}
+	if device.SUA == nil || device.SUA.Source != openrtb.UASourceHighEntropy {
+		t.Errorf("expected device.sua from hints, got %+v", device.SUA)
+	}
+}
+
+func TestClientHints_AcceptCHOnDocuments(t *testing.T) {
+	tests := []struct {
+		contentType string
+		want        bool
+	}{
+		{"application/xml; charset=utf-8", true},
+		{"text/html", true},
+		{"application/vast+xml", true},
+		{"application/json", false},
+		{"image/gif", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			handler := NewClientHints(ClientHintsConfig{Enabled: true, HighEntropy: true}).Middleware(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Content-Type", tt.contentType)
+					w.Write([]byte("ok"))
+				}))
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/video/vast", nil))
+
+			got := w.Header().Get("Accept-CH")
+			if tt.want && !strings.Contains(got, HeaderSecCHUAFullVersionList) {
+				t.Errorf("expected Accept-CH to request high entropy hints, got %q", got)
+			}
+			if !tt.want && got != "" {
+				t.Errorf("expected no Accept-CH, got %q", got)
+			}
+		})
+	}
+}
+
+func TestDeviceSUA_RespectsConsent(t *testing.T) {
+	sua := ParseClientHints(chromeHints(), true)
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want int
+	}{
+		{"no privacy context", context.Background(), openrtb.UASourceHighEntropy},
+		{"gdpr consented", SetPrivacyContext(context.Background(), true, true, false, "consent"), openrtb.UASourceHighEntropy},
+		{"gdpr without consent", SetPrivacyContext(context.Background(), true, false, false, ""), openrtb.UASourceLowEntropy},
+		{"ccpa opt out", SetPrivacyContext(context.Background(), false, true, true, ""), openrtb.UASourceLowEntropy},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.WithValue(tt.ctx, clientHintsContextKey{}, sua)
+			device := &openrtb.Device{}
+			DeviceSUA(ctx, device)
+			if device.SUA == nil || device.SUA.Source != tt.want {
+				t.Fatalf("expected source %d, got %+v", tt.want, device.SUA)
+			}
+			if tt.want == openrtb.UASourceLowEntropy {
+				if device.SUA.Architecture != "" || device.SUA.Platform.Version != nil || len(device.SUA.Browsers[0].Version) != 1 {
+					t.Errorf("expected high entropy hints dropped, got %+v", device.SUA)
+				}
+			}
+		})
+	}
+}
+
+func TestDeviceSUA_PublisherSuppliedStripped(t *testing.T) {
+	device := &openrtb.Device{SUA: &openrtb.UserAgent{
+		Browsers: []openrtb.BrandVersion{{Brand: "Chromium", Version: []string{"124", "0", "1"}}},
+		Model:    "Pixel 7",
+		Source:   openrtb.UASourceHighEntropy,
+	}}
+	DeviceSUA(SetPrivacyContext(context.Background(), true, false, false, ""), device)
+	if device.SUA.Model != "" || device.SUA.Source != openrtb.UASourceLowEntropy {
+		t.Errorf("expected the request's own sua reduced without consent, got %+v", device.SUA)
+	}
+}
@@ -0,0 +1,344 @@
+// Package middleware provides HTTP middleware for PBS
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+)
+
+// Supported response encodings
+const (
+	EncodingBrotli = "br"
+	EncodingGzip   = "gzip"
+)
+
+// CompressionConfig holds response compression configuration
+type CompressionConfig struct {
+	Enabled       bool
+	MinLength     int      // Minimum response size to compress (bytes)
+	GzipLevel     int      // gzip level (1-9, default 6)
+	BrotliLevel   int      // Brotli quality (0-11, default 4; higher costs much more CPU)
+	Encodings     []string // Encodings in server preference order, used to break client ties
+	ContentTypes  []string // Content types to compress
+	ExcludedPaths []string // Paths to exclude from compression
+}
+
+// DefaultCompressionConfig returns default compression configuration.
+// COMPRESSION_ENABLED, COMPRESSION_MIN_LENGTH and COMPRESSION_CONTENT_TYPES
+// override the defaults.
+func DefaultCompressionConfig() *CompressionConfig {
+	minLength, err := strconv.Atoi(os.Getenv("COMPRESSION_MIN_LENGTH"))
+	if err != nil || minLength < 0 {
+		minLength = 256 // Don't compress responses smaller than 256 bytes
+	}
+
+	contentTypes := parseCommaSeparated(os.Getenv("COMPRESSION_CONTENT_TYPES"))
+	if len(contentTypes) == 0 {
+		contentTypes = []string{
+			"application/json",
+			"application/xml", // VAST
+			"text/xml",
+			"text/plain",
+			"text/html",
+		}
+	}
+
+	return &CompressionConfig{
+		Enabled:      os.Getenv("COMPRESSION_ENABLED") != "false", // Enabled by default
+		MinLength:    minLength,
+		GzipLevel:    6, // Balanced compression level
+		BrotliLevel:  4, // Smaller than gzip -6 at similar cost
+		Encodings:    []string{EncodingBrotli, EncodingGzip},
+		ContentTypes: contentTypes,
+		ExcludedPaths: []string{
+			"/metrics",          // Prometheus metrics are already efficient
+			"/health",           // Health checks should be fast
+			"/status",           // Status checks should be fast
+			"/admin/debug/tail", // Server-sent events must be flushed as they're written
+		},
+	}
+}
+
+// CompressionMetrics records how much compression saves
+type CompressionMetrics interface {
+	RecordCompression(encoding string, originalBytes, compressedBytes int)
+}
+
+// encoder compresses into a writer it is reset onto
+type encoder interface {
+	io.WriteCloser
+	Reset(w io.Writer)
+}
+
+// Compression provides gzip and Brotli response compression middleware
+type Compression struct {
+	config *CompressionConfig
+	pools  map[string]*sync.Pool // by encoding
+
+	mu      sync.RWMutex
+	metrics CompressionMetrics
+}
+
+// NewCompression creates a new compression middleware
+func NewCompression(config *CompressionConfig) *Compression {
+	if config == nil {
+		config = DefaultCompressionConfig()
+	}
+
+	if len(config.Encodings) == 0 {
+		config.Encodings = []string{EncodingBrotli, EncodingGzip}
+	}
+	gzipLevel := config.GzipLevel
+	if gzipLevel < 1 || gzipLevel > 9 {
+		gzipLevel = 6
+	}
+	brotliLevel := config.BrotliLevel
+	if brotliLevel < brotli.BestSpeed || brotliLevel > brotli.BestCompression {
+		brotliLevel = 4
+	}
+
+	return &Compression{
+		config: config,
+		pools: map[string]*sync.Pool{
+			EncodingGzip: {
+				New: func() interface{} {
+					w, err := gzip.NewWriterLevel(io.Discard, gzipLevel)
+					if err != nil {
+						return nil
+					}
+					return w
+				},
+			},
+			EncodingBrotli: {
+				New: func() interface{} {
+					return brotli.NewWriterLevel(io.Discard, brotliLevel)
+				},
+			},
+		},
+	}
+}
+
+// SetMetrics sets the metrics interface for compression savings
+func (c *Compression) SetMetrics(m CompressionMetrics) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.metrics = m
+}
+
+// negotiate picks the encoding for an Accept-Encoding header: the highest
+// q-value wins, ties go to the earliest entry in config.Encodings, and "*"
+// matches any encoding not listed explicitly. Returns "" when nothing
+// acceptable is supported.
+func (c *Compression) negotiate(acceptEncoding string) string {
+	if acceptEncoding == "" {
+		return ""
+	}
+
+	qualities := make(map[string]float64)
+	wildcard := -1.0
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if name == "*" {
+			wildcard = q
+		} else {
+			qualities[name] = q
+		}
+	}
+
+	best, bestQ := "", 0.0
+	for _, enc := range c.config.Encodings {
+		if c.pools[enc] == nil {
+			continue
+		}
+		q, ok := qualities[enc]
+		if !ok {
+			q = wildcard
+		}
+		if q > bestQ {
+			best, bestQ = enc, q
+		}
+	}
+	return best
+}
+
+// compressResponseWriter wraps http.ResponseWriter for compression
+// It buffers the response to decide whether to compress based on size
+type compressResponseWriter struct {
+	http.ResponseWriter
+	encoding    string
+	config      *CompressionConfig
+	pool        *sync.Pool
+	metrics     CompressionMetrics
+	buffer      bytes.Buffer
+	wroteHeader bool
+	headerCode  int
+}
+
+// Header returns the header map
+func (crw *compressResponseWriter) Header() http.Header {
+	return crw.ResponseWriter.Header()
+}
+
+// WriteHeader captures status code but defers actual header write
+func (crw *compressResponseWriter) WriteHeader(code int) {
+	if crw.wroteHeader {
+		return
+	}
+	crw.headerCode = code
+}
+
+// Write buffers data and decides on compression
+func (crw *compressResponseWriter) Write(b []byte) (int, error) {
+	// Buffer the data
+	n, err := crw.buffer.Write(b)
+	return n, err
+}
+
+// shouldCompress checks if content type should be compressed
+func (crw *compressResponseWriter) shouldCompress(contentType string) bool {
+	if contentType == "" {
+		return false
+	}
+
+	// Extract base content type (without charset, etc.)
+	if idx := strings.Index(contentType, ";"); idx != -1 {
+		contentType = strings.TrimSpace(contentType[:idx])
+	}
+
+	for _, ct := range crw.config.ContentTypes {
+		if strings.EqualFold(ct, contentType) {
+			return true
+		}
+	}
+	return false
+}
+
+// Flush writes buffered content to the underlying writer, compressing if appropriate
+func (crw *compressResponseWriter) Flush() error {
+	if crw.wroteHeader {
+		return nil // Already flushed
+	}
+	crw.wroteHeader = true
+
+	data := crw.buffer.Bytes()
+	contentType := crw.Header().Get("Content-Type")
+	if crw.headerCode == 0 {
+		crw.headerCode = http.StatusOK
+	}
+
+	// Responses the handler already encoded, or without a body, pass through
+	compress := len(data) >= crw.config.MinLength &&
+		crw.shouldCompress(contentType) &&
+		crw.Header().Get("Content-Encoding") == "" &&
+		crw.headerCode != http.StatusNoContent &&
+		crw.headerCode != http.StatusNotModified
+
+	if !compress {
+		crw.ResponseWriter.WriteHeader(crw.headerCode)
+		_, err := crw.ResponseWriter.Write(data)
+		return err
+	}
+
+	enc, ok := crw.pool.Get().(encoder)
+	if !ok || enc == nil {
+		crw.ResponseWriter.WriteHeader(crw.headerCode)
+		_, err := crw.ResponseWriter.Write(data)
+		return err
+	}
+	defer func() {
+		enc.Reset(io.Discard)
+		crw.pool.Put(enc)
+	}()
+
+	// Set compression headers
+	crw.Header().Set("Content-Encoding", crw.encoding)
+	crw.Header().Del("Content-Length") // Length changes after compression
+	crw.Header().Add("Vary", "Accept-Encoding")
+	crw.ResponseWriter.WriteHeader(crw.headerCode)
+
+	// Compress and write data, counting what goes on the wire
+	out := &countingWriter{w: crw.ResponseWriter}
+	enc.Reset(out)
+	if _, err := enc.Write(data); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	if crw.metrics != nil {
+		crw.metrics.RecordCompression(crw.encoding, len(data), out.n)
+	}
+	return nil
+}
+
+// countingWriter counts bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n int
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += n
+	return n, err
+}
+
+// Middleware returns the compression middleware handler
+func (c *Compression) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Skip if disabled
+		if !c.config.Enabled {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Skip excluded paths
+		for _, path := range c.config.ExcludedPaths {
+			if strings.HasPrefix(r.URL.Path, path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		// Check which encoding, if any, the client accepts
+		encoding := c.negotiate(r.Header.Get("Accept-Encoding"))
+		if encoding == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		c.mu.RLock()
+		metrics := c.metrics
+		c.mu.RUnlock()
+
+		crw := &compressResponseWriter{
+			ResponseWriter: w,
+			encoding:       encoding,
+			config:         c.config,
+			pool:           c.pools[encoding],
+			metrics:        metrics,
+		}
+		// The response is already committed if the flush fails, so there is
+		// nothing left to report to the client
+		defer func() { _ = crw.Flush() }()
+
+		next.ServeHTTP(crw, r)
+	})
+}
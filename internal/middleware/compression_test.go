@@ -8,10 +8,12 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+
+	"github.com/andybalholm/brotli"
 )
 
-func TestGzipMiddleware_CompressesJSON(t *testing.T) {
-	gz := NewGzip(DefaultGzipConfig())
+func TestCompressionMiddleware_CompressesJSON(t *testing.T) {
+	gz := NewCompression(DefaultCompressionConfig())
 
 	// Create a handler that returns JSON larger than MinLength (256 bytes)
 	// This response is ~350 bytes
@@ -58,8 +60,8 @@ func TestGzipMiddleware_CompressesJSON(t *testing.T) {
 	}
 }
 
-func TestGzipMiddleware_SkipsWithoutAcceptEncoding(t *testing.T) {
-	gz := NewGzip(DefaultGzipConfig())
+func TestCompressionMiddleware_SkipsWithoutAcceptEncoding(t *testing.T) {
+	gz := NewCompression(DefaultCompressionConfig())
 
 	// Large enough response to normally be compressed (>256 bytes)
 	jsonResponse := `{"id":"test-auction-123","cur":"USD","seatbid":[{"bid":[{"id":"bid-1","impid":"imp-1","price":2.50,"adm":"<html><body>This is a test ad creative with enough content to exceed the minimum compression threshold of 256 bytes</body></html>","adomain":["example.com"],"crid":"creative-123"}]}]}`
@@ -87,8 +89,8 @@ func TestGzipMiddleware_SkipsWithoutAcceptEncoding(t *testing.T) {
 	}
 }
 
-func TestGzipMiddleware_SkipsExcludedPaths(t *testing.T) {
-	gz := NewGzip(DefaultGzipConfig())
+func TestCompressionMiddleware_SkipsExcludedPaths(t *testing.T) {
+	gz := NewCompression(DefaultCompressionConfig())
 
 	response := `{"status":"healthy"}`
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -112,10 +114,10 @@ func TestGzipMiddleware_SkipsExcludedPaths(t *testing.T) {
 	}
 }
 
-func TestGzipMiddleware_SkipsSmallResponses(t *testing.T) {
-	config := DefaultGzipConfig()
+func TestCompressionMiddleware_SkipsSmallResponses(t *testing.T) {
+	config := DefaultCompressionConfig()
 	config.MinLength = 256
-	gz := NewGzip(config)
+	gz := NewCompression(config)
 
 	// Response smaller than MinLength
 	smallResponse := `{"ok":true}`
@@ -140,10 +142,10 @@ func TestGzipMiddleware_SkipsSmallResponses(t *testing.T) {
 	}
 }
 
-func TestGzipMiddleware_Disabled(t *testing.T) {
-	config := DefaultGzipConfig()
+func TestCompressionMiddleware_Disabled(t *testing.T) {
+	config := DefaultCompressionConfig()
 	config.Enabled = false
-	gz := NewGzip(config)
+	gz := NewCompression(config)
 
 	// Large enough response to normally be compressed (>256 bytes)
 	jsonResponse := `{"id":"test-auction-123","cur":"USD","seatbid":[{"bid":[{"id":"bid-1","impid":"imp-1","price":2.50,"adm":"<html><body>This is a test ad creative with enough content to exceed the minimum compression threshold of 256 bytes</body></html>","adomain":["example.com"],"crid":"creative-123"}]}]}`
@@ -169,8 +171,8 @@ func TestGzipMiddleware_Disabled(t *testing.T) {
 	}
 }
 
-func TestGzipMiddleware_SkipsNonCompressibleTypes(t *testing.T) {
-	gz := NewGzip(DefaultGzipConfig())
+func TestCompressionMiddleware_SkipsNonCompressibleTypes(t *testing.T) {
+	gz := NewCompression(DefaultCompressionConfig())
 
 	// Image-like content type should not be compressed
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -191,11 +193,11 @@ func TestGzipMiddleware_SkipsNonCompressibleTypes(t *testing.T) {
 	}
 }
 
-func TestGzipMiddleware_CompressionLevel(t *testing.T) {
+func TestCompressionMiddleware_CompressionLevel(t *testing.T) {
 	// Test with best compression
-	config := DefaultGzipConfig()
-	config.Level = 9
-	gz := NewGzip(config)
+	config := DefaultCompressionConfig()
+	config.GzipLevel = 9
+	gz := NewCompression(config)
 
 	// Large response to see compression effect
 	largeResponse := strings.Repeat(`{"id":"test","value":12345},`, 100)
@@ -218,15 +220,15 @@ func TestGzipMiddleware_CompressionLevel(t *testing.T) {
 	}
 }
 
-func TestGzipMiddleware_InvalidLevel(t *testing.T) {
+func TestCompressionMiddleware_InvalidLevel(t *testing.T) {
 	// Invalid level should default to 6
-	config := &GzipConfig{
+	config := &CompressionConfig{
 		Enabled:      true,
 		MinLength:    256,
-		Level:        15, // Invalid - should default to 6
+		GzipLevel:    15, // Invalid - should default to 6
 		ContentTypes: []string{"application/json"},
 	}
-	gz := NewGzip(config)
+	gz := NewCompression(config)
 
 	// Just verify it doesn't panic
 	largeResponse := strings.Repeat(`{"test":true},`, 50)
@@ -248,8 +250,8 @@ func TestGzipMiddleware_InvalidLevel(t *testing.T) {
 	}
 }
 
-func TestDefaultGzipConfig(t *testing.T) {
-	config := DefaultGzipConfig()
+func TestDefaultCompressionConfig(t *testing.T) {
+	config := DefaultCompressionConfig()
 
 	if !config.Enabled {
 		t.Error("Default config should be enabled")
@@ -257,20 +259,20 @@ func TestDefaultGzipConfig(t *testing.T) {
 	if config.MinLength != 256 {
 		t.Errorf("Expected MinLength 256, got %d", config.MinLength)
 	}
-	if config.Level != 6 {
-		t.Errorf("Expected Level 6, got %d", config.Level)
+	if config.GzipLevel != 6 {
+		t.Errorf("Expected GzipLevel 6, got %d", config.GzipLevel)
 	}
-	if len(config.ContentTypes) != 3 {
-		t.Errorf("Expected 3 content types, got %d", len(config.ContentTypes))
+	if len(config.ContentTypes) != 5 {
+		t.Errorf("Expected 5 content types, got %d", len(config.ContentTypes))
 	}
-	if len(config.ExcludedPaths) != 3 {
-		t.Errorf("Expected 3 excluded paths, got %d", len(config.ExcludedPaths))
+	if len(config.ExcludedPaths) != 4 {
+		t.Errorf("Expected 4 excluded paths, got %d", len(config.ExcludedPaths))
 	}
 }
 
-func TestGzipMiddleware_NilConfig(t *testing.T) {
+func TestCompressionMiddleware_NilConfig(t *testing.T) {
 	// Should use defaults when nil config passed
-	gz := NewGzip(nil)
+	gz := NewCompression(nil)
 
 	largeResponse := strings.Repeat(`{"test":true},`, 50)
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -291,8 +293,8 @@ func TestGzipMiddleware_NilConfig(t *testing.T) {
 	}
 }
 
-func TestGzipMiddleware_EmptyContentType(t *testing.T) {
-	gz := NewGzip(nil)
+func TestCompressionMiddleware_EmptyContentType(t *testing.T) {
+	gz := NewCompression(nil)
 
 	// Handler that doesn't set Content-Type
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -312,3 +314,103 @@ func TestGzipMiddleware_EmptyContentType(t *testing.T) {
 		t.Error("Should not compress without content type")
 	}
 }
+
+type recordedCompression struct {
+	encoding             string
+	original, compressed int
+}
+
+type mockCompressionMetrics struct {
+	records []recordedCompression
+}
+
+func (m *mockCompressionMetrics) RecordCompression(encoding string, originalBytes, compressedBytes int) {
+	m.records = append(m.records, recordedCompression{encoding, originalBytes, compressedBytes})
+}
+
+func TestCompressionMiddleware_Brotli(t *testing.T) {
+	c := NewCompression(DefaultCompressionConfig())
+	metrics := &mockCompressionMetrics{}
+	c.SetMetrics(metrics)
+
+	vast := `<?xml version="1.0" encoding="UTF-8"?><VAST version="4.0">` + strings.Repeat(`<Ad id="1"><InLine><AdSystem>TNE</AdSystem></InLine></Ad>`, 20) + `</VAST>`
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+		w.Write([]byte(vast))
+	})
+
+	req := httptest.NewRequest("GET", "/video/vast", nil)
+	req.Header.Set("Accept-Encoding", "gzip, deflate, br")
+	rec := httptest.NewRecorder()
+	c.Middleware(handler).ServeHTTP(rec, req)
+
+	if rec.Header().Get("Content-Encoding") != "br" {
+		t.Fatalf("Expected Content-Encoding: br, got: %q", rec.Header().Get("Content-Encoding"))
+	}
+	compressed := rec.Body.Len()
+	decompressed, err := io.ReadAll(brotli.NewReader(rec.Body))
+	if err != nil {
+		t.Fatalf("Failed to decompress: %v", err)
+	}
+	if string(decompressed) != vast {
+		t.Error("Decompressed content mismatch")
+	}
+
+	if len(metrics.records) != 1 {
+		t.Fatalf("Expected 1 compression recorded, got %d", len(metrics.records))
+	}
+	if got := metrics.records[0]; got != (recordedCompression{"br", len(vast), compressed}) {
+		t.Errorf("Expected br %d -> %d bytes recorded, got %+v", len(vast), compressed, got)
+	}
+}
+
+func TestCompressionMiddleware_Negotiate(t *testing.T) {
+	c := NewCompression(DefaultCompressionConfig())
+
+	tests := []struct {
+		acceptEncoding string
+		want           string
+	}{
+		{"", ""},
+		{"gzip", "gzip"},
+		{"br", "br"},
+		{"gzip, br", "br"},                 // Tie goes to server preference
+		{"br;q=0.5, gzip;q=0.8", "gzip"},   // Highest q-value wins
+		{"br;q=0, gzip", "gzip"},           // q=0 means not acceptable
+		{"*", "br"},                        // Wildcard matches any supported
+		{"*;q=0.1, gzip;q=0.5", "gzip"},    // Explicit beats wildcard
+		{"identity", ""},                   // Nothing supported
+		{"GZIP", "gzip"},                   // Case-insensitive
+		{"br;q=bogus, gzip;q=0.2", "gzip"}, // Malformed entries are ignored
+	}
+	for _, tt := range tests {
+		if got := c.negotiate(tt.acceptEncoding); got != tt.want {
+			t.Errorf("negotiate(%q) = %q, want %q", tt.acceptEncoding, got, tt.want)
+		}
+	}
+}
+
+func TestCompressionMiddleware_SkipsEncodedResponses(t *testing.T) {
+	c := NewCompression(DefaultCompressionConfig())
+	metrics := &mockCompressionMetrics{}
+	c.SetMetrics(metrics)
+
+	body := strings.Repeat(`{"test":true},`, 50)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "zstd")
+		w.Write([]byte(body))
+	})
+
+	req := httptest.NewRequest("GET", "/cache", nil)
+	req.Header.Set("Accept-Encoding", "br, gzip")
+	rec := httptest.NewRecorder()
+	c.Middleware(handler).ServeHTTP(rec, req)
+
+	if rec.Header().Get("Content-Encoding") != "zstd" || rec.Body.String() != body {
+		t.Error("Already encoded responses should pass through untouched")
+	}
+	if len(metrics.records) != 0 {
+		t.Errorf("Expected no compression recorded, got %+v", metrics.records)
+	}
+}
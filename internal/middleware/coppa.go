@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+// Identifier fields a scrub policy can remove before requests reach bidders
+const (
+	ScrubFieldUserIDs  = "user_ids" // user.id, user.eids and user.ext.eids
+	ScrubFieldBuyerUID = "buyeruid" // user.buyeruid
+	ScrubFieldGeo      = "geo"      // precise lat/lon on device and user geo
+	ScrubFieldIFA      = "ifa"      // device.ifa and hashed device IDs
+	ScrubFieldIP       = "ip"       // truncates device.ip and device.ipv6
+)
+
+// ScrubPolicy selects the identifier fields removed from a request
+type ScrubPolicy struct {
+	UserIDs  bool
+	BuyerUID bool
+	Geo      bool
+	IFA      bool
+	IP       bool
+}
+
+// ScrubAll scrubs every identifier field
+var ScrubAll = ScrubPolicy{UserIDs: true, BuyerUID: true, Geo: true, IFA: true, IP: true}
+
+// Default scrub policies. COPPA traffic loses every identifier; limited ad
+// tracking keeps the full IP, which bidders use for fraud detection.
+var (
+	DefaultCOPPAScrub = ScrubAll
+	DefaultLMTScrub   = ScrubPolicy{UserIDs: true, BuyerUID: true, Geo: true, IFA: true}
+)
+
+// MinimumCOPPAScrub is the least a COPPA scrub policy may remove: persistent
+// identifiers can never reach bidders on child-directed traffic
+var MinimumCOPPAScrub = ScrubPolicy{UserIDs: true, IFA: true}
+
+// ParseScrubPolicy parses a comma-separated list of scrub fields; "none"
+// scrubs nothing and "all" every field
+func ParseScrubPolicy(s string) (ScrubPolicy, error) {
+	var p ScrubPolicy
+	for _, field := range strings.Split(s, ",") {
+		switch strings.ToLower(strings.TrimSpace(field)) {
+		case "", "none":
+		case "all":
+			p = ScrubAll
+		case ScrubFieldUserIDs:
+			p.UserIDs = true
+		case ScrubFieldBuyerUID:
+			p.BuyerUID = true
+		case ScrubFieldGeo:
+			p.Geo = true
+		case ScrubFieldIFA:
+			p.IFA = true
+		case ScrubFieldIP:
+			p.IP = true
+		default:
+			return ScrubPolicy{}, fmt.Errorf("unknown scrub field %q: expected %s, %s, %s, %s, %s, all or none",
+				strings.TrimSpace(field), ScrubFieldUserIDs, ScrubFieldBuyerUID, ScrubFieldGeo, ScrubFieldIFA, ScrubFieldIP)
+		}
+	}
+	return p, nil
+}
+
+// Union returns a policy scrubbing every field either policy scrubs
+func (p ScrubPolicy) Union(o ScrubPolicy) ScrubPolicy {
+	return ScrubPolicy{
+		UserIDs:  p.UserIDs || o.UserIDs,
+		BuyerUID: p.BuyerUID || o.BuyerUID,
+		Geo:      p.Geo || o.Geo,
+		IFA:      p.IFA || o.IFA,
+		IP:       p.IP || o.IP,
+	}
+}
+
+// Covers reports whether the policy scrubs every field o scrubs
+func (p ScrubPolicy) Covers(o ScrubPolicy) bool {
+	return p.Union(o) == p
+}
+
+// Empty reports whether the policy scrubs nothing
+func (p ScrubPolicy) Empty() bool {
+	return p == ScrubPolicy{}
+}
+
+// IsCOPPA reports whether a request is flagged as child-directed (regs.coppa=1)
+func IsCOPPA(req *openrtb.BidRequest) bool {
+	return req != nil && req.Regs != nil && req.Regs.COPPA == 1
+}
+
+// IsLMT reports whether the device has limited ad tracking on (device.lmt=1)
+func IsLMT(req *openrtb.BidRequest) bool {
+	return req != nil && req.Device != nil && req.Device.Lmt != nil && *req.Device.Lmt == 1
+}
+
+// ScrubIdentifiers removes the fields selected by policy from a request. User
+// and Device are copied before modification so requests sharing those objects
+// are not affected.
+func ScrubIdentifiers(req *openrtb.BidRequest, policy ScrubPolicy) {
+	if req == nil || policy.Empty() {
+		return
+	}
+
+	if req.User != nil && (policy.UserIDs || policy.BuyerUID || policy.Geo) {
+		userCopy := *req.User
+		if policy.UserIDs {
+			userCopy.ID = ""
+			userCopy.EIDs = nil
+			userCopy.Ext = stripExtEIDs(userCopy.Ext)
+		}
+		if policy.BuyerUID {
+			userCopy.BuyerUID = ""
+		}
+		if policy.Geo {
+			userCopy.Geo = coarsenGeo(userCopy.Geo)
+		}
+		req.User = &userCopy
+	}
+
+	if req.Device != nil && (policy.IFA || policy.IP || policy.Geo) {
+		deviceCopy := *req.Device
+		if policy.IFA {
+			deviceCopy.IFA = ""
+			deviceCopy.IDSHA1 = ""
+			deviceCopy.IDMD5 = ""
+			deviceCopy.DPIDSHA1 = ""
+			deviceCopy.DPIDMD5 = ""
+			deviceCopy.MacSHA1 = ""
+			deviceCopy.MacMD5 = ""
+		}
+		if policy.IP {
+			deviceCopy.IP = AnonymizeIP(deviceCopy.IP)
+			deviceCopy.IPv6 = AnonymizeIP(deviceCopy.IPv6)
+		}
+		if policy.Geo {
+			deviceCopy.Geo = coarsenGeo(deviceCopy.Geo)
+		}
+		req.Device = &deviceCopy
+	}
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+func TestParseScrubPolicy(t *testing.T) {
+	tests := []struct {
+		in   string
+		want ScrubPolicy
+	}{
+		{"", ScrubPolicy{}},
+		{"none", ScrubPolicy{}},
+		{"all", ScrubAll},
+		{" IFA, geo ", ScrubPolicy{IFA: true, Geo: true}},
+		{"user_ids,buyeruid,ip", ScrubPolicy{UserIDs: true, BuyerUID: true, IP: true}},
+	}
+	for _, tt := range tests {
+		got, err := ParseScrubPolicy(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseScrubPolicy(%q) = %+v (%v), want %+v", tt.in, got, err, tt.want)
+		}
+	}
+	if _, err := ParseScrubPolicy("ifa,email"); err == nil {
+		t.Error("expected an unknown field rejected")
+	}
+}
+
+func TestScrubPolicyCovers(t *testing.T) {
+	if !ScrubAll.Covers(MinimumCOPPAScrub) || !DefaultCOPPAScrub.Covers(MinimumCOPPAScrub) {
+		t.Error("expected the full policy to cover the COPPA minimum")
+	}
+	if (ScrubPolicy{UserIDs: true, Geo: true}).Covers(MinimumCOPPAScrub) || (ScrubPolicy{}).Covers(MinimumCOPPAScrub) {
+		t.Error("expected a policy keeping ifa not to cover the COPPA minimum")
+	}
+}
+
+func TestScrubIdentifiers(t *testing.T) {
+	lmt := 1
+	newRequest := func() *openrtb.BidRequest {
+		return &openrtb.BidRequest{
+			User: &openrtb.User{
+				ID:       "user-1",
+				BuyerUID: "buyer-1",
+				EIDs:     []openrtb.EID{{Source: "id5-sync.com"}},
+				Ext:      json.RawMessage(`{"eids":[{"source":"id5-sync.com"}],"consent":"x"}`),
+				Geo:      &openrtb.Geo{Lat: 51.5, Lon: -0.12, Country: "GBR"},
+			},
+			Device: &openrtb.Device{
+				IFA:    "6D92078A-8246-4BA4-AE5B-76104861E7DC",
+				IDSHA1: "sha1",
+				IP:     "203.0.113.42",
+				Lmt:    &lmt,
+				Geo:    &openrtb.Geo{Lat: 51.5, Lon: -0.12, Country: "GBR"},
+			},
+		}
+	}
+
+	req := newRequest()
+	original := req.User
+	ScrubIdentifiers(req, DefaultLMTScrub)
+	if req.User.ID != "" || req.User.BuyerUID != "" || req.User.EIDs != nil || string(req.User.Ext) != `{"consent":"x"}` {
+		t.Errorf("expected user identifiers scrubbed, got %+v", req.User)
+	}
+	if req.Device.IFA != "" || req.Device.IDSHA1 != "" || req.Device.Geo.Lat != 0 || req.Device.Geo.Country != "GBR" {
+		t.Errorf("expected device identifiers and precise geo scrubbed, got %+v", req.Device)
+	}
+	if req.Device.IP != "203.0.113.42" {
+		t.Errorf("expected the LMT default to keep the IP, got %q", req.Device.IP)
+	}
+	if original.ID != "user-1" {
+		t.Error("expected the shared user object left untouched")
+	}
+
+	req = newRequest()
+	ScrubIdentifiers(req, ScrubPolicy{IFA: true})
+	if req.Device.IFA != "" || req.User.ID != "user-1" || req.User.BuyerUID != "buyer-1" || req.Device.Geo.Lat != 51.5 {
+		t.Errorf("expected only the IFA scrubbed, got %+v %+v", req.User, req.Device)
+	}
+
+	req = newRequest()
+	ScrubIdentifiers(req, DefaultCOPPAScrub)
+	if req.Device.IP != AnonymizeIP("203.0.113.42") {
+		t.Errorf("expected the COPPA default to truncate the IP, got %q", req.Device.IP)
+	}
+}
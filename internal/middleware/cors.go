@@ -48,10 +48,18 @@ func DefaultCORSConfig() *CORSConfig {
 			"Accept",
 			"Origin",
 			"X-Prebid", // Prebid.js header
+			"X-Latency-Budget",
+			"X-Bid-Injection", // Signed canned bids for staging rendering tests
 		},
 		ExposedHeaders: []string{
 			"X-Request-ID",
 			"X-Prebid-Server-Version",
+			"X-Latency-Spent",
+			"X-Latency-Remaining",
+			"X-Pod-Requested",
+			"X-Pod-Filled",
+			"X-Pod-Fill-Rate",
+			"X-Pod-Unfilled",
 		},
 		AllowCredentials: os.Getenv("CORS_ALLOW_CREDENTIALS") == "true",
 		MaxAge:           config.CORSMaxAge, // P2-6: use named constant
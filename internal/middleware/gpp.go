@@ -0,0 +1,451 @@
+// Package middleware provides HTTP middleware components
+package middleware
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+// GPP section IDs for the US national and state sections
+const (
+	GPPSectionUSNat = 7
+	GPPSectionUSCA  = 8
+	GPPSectionUSVA  = 9
+	GPPSectionUSCO  = 10
+	GPPSectionUSUT  = 11
+	GPPSectionUSCT  = 12
+)
+
+// ConsentSignalGPP prefixes the ConsentSignals metric type for GPP US
+// sections, e.g. "gpp_usnat"
+const ConsentSignalGPP = "gpp"
+
+// gppMaxSectionID bounds header section IDs, so a crafted range can't
+// expand into millions of IDs
+const gppMaxSectionID = 64
+
+// GPP US section field values. Notices and MSPA flags use 1 for yes and 2
+// for no; opt-outs use 1 for opted out and 2 for did not opt out; consents
+// use 1 for no consent and 2 for consent. 0 is not applicable.
+const (
+	GPPNotApplicable = 0
+	GPPYes           = 1
+	GPPNo            = 2
+)
+
+// GPP parsing errors beyond the structural ones in ValidateGPP
+var (
+	ErrGPPTruncated  = errors.New("gpp section is shorter than its fields")
+	ErrGPPSectionIDs = errors.New("gpp header section IDs don't match the sections")
+	ErrGPPVersion    = errors.New("unsupported gpp section version")
+)
+
+// GPPString is a parsed GPP string: its section IDs and the encoded
+// section for each, plus the US sections this parser understands
+type GPPString struct {
+	Raw        string
+	SectionIDs []int
+	Sections   map[int]string
+	US         map[int]*GPPUSSection
+}
+
+// GPPUSSection is a US national or state GPP section, normalized across
+// the section layouts. Fields a state's section doesn't carry are 0.
+type GPPUSSection struct {
+	ID                         int
+	Name                       string
+	SaleOptOutNotice           int
+	SaleOptOut                 int
+	SharingOptOut              int
+	TargetedAdvertisingOptOut  int
+	SensitiveDataProcessing    []int
+	KnownChildSensitiveConsent []int
+	MSPACoveredTransaction     int
+	GPC                        bool
+}
+
+// OptedOut reports whether the user opted out of sale, sharing or targeted
+// advertising, or sent a Global Privacy Control signal. The MSPA covered
+// transaction flag is ignored on purpose: state laws give an opt-out effect
+// whether or not the transaction is MSPA covered, so it only feeds the LSPA
+// flag of the US Privacy string (see GPPSignal.USPrivacy).
+func (s *GPPUSSection) OptedOut() bool {
+	return s.SaleOptOut == GPPYes || s.SharingOptOut == GPPYes || s.TargetedAdvertisingOptOut == GPPYes || s.GPC
+}
+
+// ChildConsentWithheld reports whether the section says the user is a known
+// child whose data may not be processed
+func (s *GPPUSSection) ChildConsentWithheld() bool {
+	for _, v := range s.KnownChildSensitiveConsent {
+		if v == GPPYes {
+			return true
+		}
+	}
+	return false
+}
+
+// gppUSField is a field of a US section; each value is 2 bits
+type gppUSField int
+
+const (
+	gppSkip gppUSField = iota // notices the exchange doesn't act on
+	gppSaleOptOutNotice
+	gppSaleOptOut
+	gppSharingOptOut
+	gppTargetedOptOut
+	gppSensitive
+	gppChild
+	gppMSPACovered
+)
+
+// gppUSLayout describes the core segment of a US section after its version
+type gppUSLayout struct {
+	name   string
+	fields []gppUSField
+	counts map[gppUSField]int // values in list fields
+	v2     map[gppUSField]int // list lengths in version 2, if the section has one
+	gpc    bool               // has a GPC subsection
+}
+
+// gppStateFields is the usva/usco/usct core layout; the states differ only
+// in list lengths
+var gppStateFields = []gppUSField{
+	gppSkip, gppSaleOptOutNotice, gppSkip, gppSaleOptOut, gppTargetedOptOut,
+	gppSensitive, gppChild, gppMSPACovered, gppSkip, gppSkip,
+}
+
+// gppUSLayouts maps section IDs to their layouts
+var gppUSLayouts = map[int]gppUSLayout{
+	GPPSectionUSNat: {
+		name: "usnat",
+		fields: []gppUSField{
+			gppSkip, gppSaleOptOutNotice, gppSkip, gppSkip, gppSkip, gppSkip,
+			gppSaleOptOut, gppSharingOptOut, gppTargetedOptOut,
+			gppSensitive, gppChild, gppSkip, gppMSPACovered, gppSkip, gppSkip,
+		},
+		counts: map[gppUSField]int{gppSensitive: 12, gppChild: 2},
+		v2:     map[gppUSField]int{gppSensitive: 16, gppChild: 3},
+		gpc:    true,
+	},
+	GPPSectionUSCA: {
+		name: "usca",
+		fields: []gppUSField{
+			gppSaleOptOutNotice, gppSkip, gppSkip, gppSaleOptOut, gppSharingOptOut,
+			gppSensitive, gppChild, gppSkip, gppMSPACovered, gppSkip, gppSkip,
+		},
+		counts: map[gppUSField]int{gppSensitive: 9, gppChild: 2},
+		gpc:    true,
+	},
+	GPPSectionUSVA: {name: "usva", fields: gppStateFields, counts: map[gppUSField]int{gppSensitive: 8, gppChild: 1}},
+	GPPSectionUSCO: {name: "usco", fields: gppStateFields, counts: map[gppUSField]int{gppSensitive: 7, gppChild: 1}, gpc: true},
+	GPPSectionUSUT: {
+		name: "usut",
+		fields: []gppUSField{
+			gppSkip, gppSaleOptOutNotice, gppSkip, gppSkip, gppSaleOptOut, gppTargetedOptOut,
+			gppSensitive, gppChild, gppMSPACovered, gppSkip, gppSkip,
+		},
+		counts: map[gppUSField]int{gppSensitive: 8, gppChild: 1},
+	},
+	GPPSectionUSCT: {name: "usct", fields: gppStateFields, counts: map[gppUSField]int{gppSensitive: 8, gppChild: 3}, gpc: true},
+}
+
+// ParseGPP parses a GPP string's header and its US national and state
+// sections. Other sections (TCF, uspv1 and so on) are kept encoded.
+func ParseGPP(gpp string) (*GPPString, error) {
+	if err := ValidateGPP(gpp); err != nil {
+		return nil, err
+	}
+	parts := strings.Split(gpp, "~")
+	ids, err := parseGPPHeader(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) != len(parts)-1 {
+		return nil, ErrGPPSectionIDs
+	}
+
+	result := &GPPString{
+		Raw:        gpp,
+		SectionIDs: ids,
+		Sections:   make(map[int]string, len(ids)),
+		US:         make(map[int]*GPPUSSection),
+	}
+	for i, id := range ids {
+		result.Sections[id] = parts[i+1]
+		if layout, ok := gppUSLayouts[id]; ok {
+			section, err := parseGPPUSSection(id, layout, parts[i+1])
+			if err != nil {
+				return nil, err
+			}
+			result.US[id] = section
+		}
+	}
+	return result, nil
+}
+
+// parseGPPHeader decodes the section ID list of a GPP header: a count, then
+// Fibonacci-coded IDs or ranges, each an offset from the previous ID
+func parseGPPHeader(header string) ([]int, error) {
+	r := newGPPBitReader(strings.SplitN(header, ".", 2)[0])
+	r.read(6) // type, checked by ValidateGPP
+	r.read(6) // version
+	entries := r.read(12)
+	var ids []int
+	last := 0
+	for i := 0; i < entries && r.err == nil; i++ {
+		isRange := r.read(1) == 1
+		start := last + r.fibonacci()
+		end := start
+		if isRange {
+			end = start + r.fibonacci()
+		}
+		if r.err != nil {
+			break
+		}
+		if start <= last || end > gppMaxSectionID {
+			return nil, ErrGPPSectionIDs
+		}
+		for id := start; id <= end; id++ {
+			ids = append(ids, id)
+		}
+		last = end
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return ids, nil
+}
+
+// parseGPPUSSection decodes a US section's core segment and, for sections
+// that have one, the GPC subsection
+func parseGPPUSSection(id int, layout gppUSLayout, encoded string) (*GPPUSSection, error) {
+	segments := strings.Split(encoded, ".")
+	r := newGPPBitReader(segments[0])
+	counts := layout.counts
+	switch version := r.read(6); {
+	case version == 2 && layout.v2 != nil:
+		counts = layout.v2
+	case version != 1:
+		return nil, ErrGPPVersion
+	}
+
+	section := &GPPUSSection{ID: id, Name: layout.name}
+	for _, field := range layout.fields {
+		switch field {
+		case gppSensitive:
+			section.SensitiveDataProcessing = r.readList(counts[field])
+		case gppChild:
+			section.KnownChildSensitiveConsent = r.readList(counts[field])
+		default:
+			v := r.read(2)
+			switch field {
+			case gppSaleOptOutNotice:
+				section.SaleOptOutNotice = v
+			case gppSaleOptOut:
+				section.SaleOptOut = v
+			case gppSharingOptOut:
+				section.SharingOptOut = v
+			case gppTargetedOptOut:
+				section.TargetedAdvertisingOptOut = v
+			case gppMSPACovered:
+				section.MSPACoveredTransaction = v
+			}
+		}
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	// The GPC subsection is type 1 followed by the flag
+	if layout.gpc {
+		for _, segment := range segments[1:] {
+			sub := newGPPBitReader(segment)
+			if sub.read(2) == 1 && sub.read(1) == 1 && sub.err == nil {
+				section.GPC = true
+			}
+		}
+	}
+	return section, nil
+}
+
+// GPPSignal is the US privacy state carried by a request's GPP string: the
+// US sections gpp_sid says apply
+type GPPSignal struct {
+	Sections []*GPPUSSection
+}
+
+// OptedOut reports whether any applicable section opts the user out
+func (s *GPPSignal) OptedOut() bool {
+	if s == nil {
+		return false
+	}
+	for _, section := range s.Sections {
+		if section.OptedOut() {
+			return true
+		}
+	}
+	return false
+}
+
+// ChildConsentWithheld reports whether any applicable section withholds
+// consent for a known child's data
+func (s *GPPSignal) ChildConsentWithheld() bool {
+	if s == nil {
+		return false
+	}
+	for _, section := range s.Sections {
+		if section.ChildConsentWithheld() {
+			return true
+		}
+	}
+	return false
+}
+
+// StripIdentifiers reports whether bidders may only receive the request
+// without user identifiers and precise geo
+func (s *GPPSignal) StripIdentifiers() bool {
+	return s.OptedOut() || s.ChildConsentWithheld()
+}
+
+// USPrivacy returns the US Privacy string equivalent to the signal, for
+// bidders that read us_privacy but not GPP
+func (s *GPPSignal) USPrivacy() string {
+	if s == nil || len(s.Sections) == 0 {
+		return ""
+	}
+	// The first applicable section supplies the notice and LSPA flags;
+	// an opt-out in any section opts the user out
+	first := s.Sections[0]
+	optOut := byte('N')
+	if s.OptedOut() {
+		optOut = 'Y'
+	}
+	return string([]byte{'1', gppFlag(first.SaleOptOutNotice), optOut, gppFlag(first.MSPACoveredTransaction)})
+}
+
+// gppFlag maps a GPP yes/no value to a US Privacy flag
+func gppFlag(v int) byte {
+	switch v {
+	case GPPYes:
+		return 'Y'
+	case GPPNo:
+		return 'N'
+	}
+	return '-'
+}
+
+// ResolveGPP finds the US sections of a request's GPP string that gpp_sid
+// says apply. Returns nil when there are none or the string doesn't parse;
+// malformed strings are handled by the malformed consent policy.
+func ResolveGPP(req *openrtb.BidRequest) *GPPSignal {
+	if req == nil || req.Regs == nil || req.Regs.GPP == "" {
+		return nil
+	}
+	gpp, err := ParseGPP(req.Regs.GPP)
+	if err != nil {
+		return nil
+	}
+	var signal GPPSignal
+	for _, id := range gpp.SectionIDs {
+		if section, ok := gpp.US[id]; ok && containsInt(req.Regs.GPPSID, id) {
+			signal.Sections = append(signal.Sections, section)
+		}
+	}
+	if len(signal.Sections) == 0 {
+		return nil
+	}
+	return &signal
+}
+
+// ApplyGPPUSPrivacy gives a bidder that doesn't read GPP the equivalent US
+// Privacy string, unless the request already carries one. Regs is copied
+// before modification.
+func ApplyGPPUSPrivacy(req *openrtb.BidRequest, signal *GPPSignal) {
+	usPrivacy := signal.USPrivacy()
+	if req == nil || req.Regs == nil || usPrivacy == "" || ResolveUSPrivacy(req) != nil {
+		return
+	}
+	regsCopy := *req.Regs
+	regsCopy.USPrivacy = usPrivacy
+	req.Regs = &regsCopy
+}
+
+// gppBitReader reads big-endian bit fields from a base64url segment
+type gppBitReader struct {
+	data []byte // 6-bit values
+	pos  int    // bit position
+	err  error
+}
+
+func newGPPBitReader(segment string) *gppBitReader {
+	r := &gppBitReader{data: make([]byte, len(segment))}
+	for i := 0; i < len(segment); i++ {
+		c := segment[i]
+		switch {
+		case c >= 'A' && c <= 'Z':
+			r.data[i] = c - 'A'
+		case c >= 'a' && c <= 'z':
+			r.data[i] = c - 'a' + 26
+		case c >= '0' && c <= '9':
+			r.data[i] = c - '0' + 52
+		case c == '-':
+			r.data[i] = 62
+		case c == '_':
+			r.data[i] = 63
+		default:
+			r.err = ErrGPPEncoding
+		}
+	}
+	return r
+}
+
+// read returns the next n bits; past the end it records ErrGPPTruncated
+func (r *gppBitReader) read(n int) int {
+	v := 0
+	for i := 0; i < n; i++ {
+		v = v<<1 | r.bit()
+	}
+	return v
+}
+
+// readList reads n 2-bit values
+func (r *gppBitReader) readList(n int) []int {
+	values := make([]int, n)
+	for i := range values {
+		values[i] = r.read(2)
+	}
+	return values
+}
+
+// fibonacci reads a Fibonacci-coded integer, terminated by two 1 bits
+func (r *gppBitReader) fibonacci() int {
+	a, b := 1, 2
+	v, prev := 0, 0
+	for r.err == nil {
+		bit := r.bit()
+		if bit == 1 && prev == 1 {
+			return v
+		}
+		if bit == 1 {
+			v += a
+		}
+		prev = bit
+		a, b = b, a+b
+	}
+	return 0
+}
+
+func (r *gppBitReader) bit() int {
+	if r.err != nil {
+		return 0
+	}
+	if r.pos >= len(r.data)*6 {
+		r.err = ErrGPPTruncated
+		return 0
+	}
+	b := int(r.data[r.pos/6]>>(5-r.pos%6)) & 1
+	r.pos++
+	return b
+}
@@ -0,0 +1,293 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+const gppTestAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+
+// gppTestWriter encodes bit fields as base64url, the way GPP sections are
+type gppTestWriter struct{ bits []int }
+
+func (w *gppTestWriter) write(v, n int) *gppTestWriter {
+	for i := n - 1; i >= 0; i-- {
+		w.bits = append(w.bits, v>>i&1)
+	}
+	return w
+}
+
+// fibonacci writes v in Zeckendorf form, lowest term first, then the
+// terminating 1
+func (w *gppTestWriter) fibonacci(v int) *gppTestWriter {
+	fibs := []int{1, 2}
+	for fibs[len(fibs)-1] <= v {
+		fibs = append(fibs, fibs[len(fibs)-1]+fibs[len(fibs)-2])
+	}
+	used := make([]int, len(fibs))
+	for i := len(fibs) - 1; i >= 0; i-- {
+		if fibs[i] <= v {
+			used[i] = 1
+			v -= fibs[i]
+		}
+	}
+	last := 0
+	for i, u := range used {
+		if u == 1 {
+			last = i
+		}
+	}
+	w.bits = append(w.bits, used[:last+1]...)
+	w.bits = append(w.bits, 1)
+	return w
+}
+
+func (w *gppTestWriter) String() string {
+	var out []byte
+	for i := 0; i < len(w.bits); i += 6 {
+		v := 0
+		for j := 0; j < 6; j++ {
+			v <<= 1
+			if i+j < len(w.bits) {
+				v |= w.bits[i+j]
+			}
+		}
+		out = append(out, gppTestAlphabet[v])
+	}
+	return string(out)
+}
+
+// gppTestHeader encodes a header listing ids individually
+func gppTestHeader(ids ...int) string {
+	w := (&gppTestWriter{}).write(3, 6).write(1, 6).write(len(ids), 12)
+	last := 0
+	for _, id := range ids {
+		w.write(0, 1).fibonacci(id - last)
+		last = id
+	}
+	return w.String()
+}
+
+// gppTestUSCA encodes a usca section with the given opt-outs and child
+// consents and every notice given
+func gppTestUSCA(saleOptOut, sharingOptOut, child int, gpc bool) string {
+	w := (&gppTestWriter{}).write(1, 6)
+	w.write(GPPYes, 2).write(GPPYes, 2).write(GPPYes, 2)
+	w.write(saleOptOut, 2).write(sharingOptOut, 2)
+	for i := 0; i < 9; i++ {
+		w.write(0, 2)
+	}
+	w.write(child, 2).write(child, 2)
+	w.write(0, 2).write(GPPNo, 2).write(0, 2).write(0, 2)
+	section := w.String()
+	if gpc {
+		section += "." + (&gppTestWriter{}).write(1, 2).write(1, 1).String()
+	}
+	return section
+}
+
+func TestParseGPP_Header(t *testing.T) {
+	tests := []struct {
+		gpp  string
+		want []int
+	}{
+		{"DBABTA~1YYN", []int{6}},
+		{"DBACNYA~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA~1YNN", []int{2, 6}},
+		{gppTestHeader(7, 8, 12) + "~BVQqAAAAAgA.QA~" + gppTestUSCA(GPPNo, GPPNo, 0, false) + "~BAAAAAAA", []int{7, 8, 12}},
+	}
+	for _, tt := range tests {
+		gpp, err := ParseGPP(tt.gpp)
+		if err != nil {
+			t.Errorf("ParseGPP(%q): %v", tt.gpp, err)
+			continue
+		}
+		if len(gpp.SectionIDs) != len(tt.want) {
+			t.Errorf("ParseGPP(%q) IDs = %v, want %v", tt.gpp, gpp.SectionIDs, tt.want)
+			continue
+		}
+		for i, id := range tt.want {
+			if gpp.SectionIDs[i] != id {
+				t.Errorf("ParseGPP(%q) IDs = %v, want %v", tt.gpp, gpp.SectionIDs, tt.want)
+			}
+		}
+	}
+
+	// A range entry covers every ID from start to end
+	ranged := (&gppTestWriter{}).write(3, 6).write(1, 6).write(1, 12).write(1, 1).fibonacci(7).fibonacci(2).String()
+	gpp, err := ParseGPP(ranged + "~BVQqAAAAAgA~" + gppTestUSCA(GPPNo, GPPNo, 0, false) + "~BAAAAAAA")
+	if err != nil || len(gpp.US) != 3 || gpp.US[GPPSectionUSVA] == nil {
+		t.Errorf("expected sections 7-9 from a range, got %+v (%v)", gpp, err)
+	}
+}
+
+func TestParseGPP_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		gpp  string
+		want error
+	}{
+		{"structure", "XYZ~1YYN", ErrGPPHeader},
+		{"section count", "DBABTA~1YYN~1YNN", ErrGPPSectionIDs},
+		{"truncated header", "DBAB", ErrGPPTruncated},
+		{"truncated section", gppTestHeader(7) + "~BVQ", ErrGPPTruncated},
+		{"section version", gppTestHeader(8) + "~DVQqAAAAAgA", ErrGPPVersion},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseGPP(tt.gpp)
+			if err != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestParseGPP_USNat(t *testing.T) {
+	// The usnat example from the GPP documentation: notices given, no
+	// opt-outs, not an MSPA covered transaction, no GPC
+	gpp, err := ParseGPP("DBABLA~BVQqAAAAAgA.QA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	section := gpp.US[GPPSectionUSNat]
+	if section == nil || section.Name != "usnat" {
+		t.Fatalf("expected a usnat section, got %+v", gpp.US)
+	}
+	if section.SaleOptOutNotice != GPPYes || section.SaleOptOut != GPPNo || section.SharingOptOut != GPPNo ||
+		section.TargetedAdvertisingOptOut != GPPNo || section.MSPACoveredTransaction != GPPNo || section.GPC {
+		t.Errorf("unexpected section %+v", section)
+	}
+	if len(section.SensitiveDataProcessing) != 12 || len(section.KnownChildSensitiveConsent) != 2 {
+		t.Errorf("unexpected list lengths in %+v", section)
+	}
+	if section.OptedOut() {
+		t.Error("expected no opt-out")
+	}
+}
+
+func TestParseGPP_USStates(t *testing.T) {
+	tests := []struct {
+		name      string
+		section   string
+		optOut    bool
+		child     bool
+		gpc       bool
+		stateName string
+	}{
+		{"no opt-out", gppTestUSCA(GPPNo, GPPNo, 0, false), false, false, false, "usca"},
+		{"sale opt-out", gppTestUSCA(GPPYes, GPPNo, 0, false), true, false, false, "usca"},
+		{"sharing opt-out", gppTestUSCA(GPPNo, GPPYes, 0, false), true, false, false, "usca"},
+		{"gpc", gppTestUSCA(GPPNo, GPPNo, 0, true), true, false, true, "usca"},
+		{"known child", gppTestUSCA(GPPNo, GPPNo, GPPYes, false), false, true, false, "usca"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gpp, err := ParseGPP(gppTestHeader(GPPSectionUSCA) + "~" + tt.section)
+			if err != nil {
+				t.Fatal(err)
+			}
+			section := gpp.US[GPPSectionUSCA]
+			if section.Name != tt.stateName || section.OptedOut() != tt.optOut || section.ChildConsentWithheld() != tt.child || section.GPC != tt.gpc {
+				t.Errorf("unexpected section %+v", section)
+			}
+		})
+	}
+
+	// Virginia has no sharing opt-out but does have targeted advertising
+	va := (&gppTestWriter{}).write(1, 6).write(GPPYes, 2).write(GPPYes, 2).write(GPPYes, 2).write(GPPNo, 2).write(GPPYes, 2)
+	va.write(0, 16).write(0, 2).write(GPPYes, 2).write(0, 2).write(0, 2)
+	gpp, err := ParseGPP(gppTestHeader(GPPSectionUSVA) + "~" + va.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if section := gpp.US[GPPSectionUSVA]; section.TargetedAdvertisingOptOut != GPPYes || !section.OptedOut() || section.MSPACoveredTransaction != GPPYes {
+		t.Errorf("unexpected usva section %+v", section)
+	}
+}
+
+func TestResolveGPP(t *testing.T) {
+	optedOut := gppTestHeader(GPPSectionUSNat, GPPSectionUSCA) + "~BVQqAAAAAgA.QA~" + gppTestUSCA(GPPYes, GPPNo, 0, false)
+
+	// Only sections listed in gpp_sid apply
+	req := &openrtb.BidRequest{Regs: &openrtb.Regs{GPP: optedOut, GPPSID: []int{GPPSectionUSNat}}}
+	signal := ResolveGPP(req)
+	if signal == nil || len(signal.Sections) != 1 || signal.OptedOut() {
+		t.Fatalf("expected only usnat to apply, got %+v", signal)
+	}
+	if got := signal.USPrivacy(); got != "1YNN" {
+		t.Errorf("expected us_privacy 1YNN, got %q", got)
+	}
+
+	req.Regs.GPPSID = []int{GPPSectionUSNat, GPPSectionUSCA}
+	signal = ResolveGPP(req)
+	if !signal.OptedOut() || !signal.StripIdentifiers() || signal.USPrivacy() != "1YYN" {
+		t.Errorf("expected the California opt-out to apply, got %+v", signal)
+	}
+
+	for _, regs := range []*openrtb.Regs{nil, {GPP: "DBABTA~1YYN", GPPSID: []int{6}}, {GPP: "DBAB", GPPSID: []int{7}}} {
+		if signal := ResolveGPP(&openrtb.BidRequest{Regs: regs}); signal != nil {
+			t.Errorf("expected no US section signal for %+v, got %+v", regs, signal)
+		}
+	}
+}
+
+func TestApplyGPPUSPrivacy(t *testing.T) {
+	gpp := gppTestHeader(GPPSectionUSCA) + "~" + gppTestUSCA(GPPYes, GPPNo, 0, false)
+	regs := &openrtb.Regs{GPP: gpp, GPPSID: []int{GPPSectionUSCA}}
+	req := &openrtb.BidRequest{Regs: regs}
+
+	ApplyGPPUSPrivacy(req, ResolveGPP(req))
+	if req.Regs.USPrivacy != "1YYN" || req.Regs.GPP != gpp {
+		t.Errorf("expected us_privacy added alongside gpp, got %+v", req.Regs)
+	}
+	if regs.USPrivacy != "" {
+		t.Error("expected the shared regs left untouched")
+	}
+
+	// An explicit us_privacy string wins
+	req = &openrtb.BidRequest{Regs: &openrtb.Regs{USPrivacy: "1YNN", GPP: gpp, GPPSID: []int{GPPSectionUSCA}}}
+	ApplyGPPUSPrivacy(req, ResolveGPP(req))
+	if req.Regs.USPrivacy != "1YNN" {
+		t.Errorf("expected us_privacy kept, got %q", req.Regs.USPrivacy)
+	}
+}
+
+func TestPrivacyMiddleware_RecordsGPPSignals(t *testing.T) {
+	m := &mockPrivacyMetrics{}
+	mw := NewPrivacyMiddlewareWithMetrics(DefaultPrivacyConfig(), m)
+	var optOut bool
+	handler := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		optOut = CCPAOptOut(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := &openrtb.BidRequest{
+		ID:  "test-gpp-metrics",
+		Imp: []openrtb.Imp{{ID: "imp1", Banner: &openrtb.Banner{}}},
+		Regs: &openrtb.Regs{
+			GPP:    gppTestHeader(GPPSectionUSNat, GPPSectionUSCA) + "~BVQqAAAAAgA.QA~" + gppTestUSCA(GPPNo, GPPNo, 0, true),
+			GPPSID: []int{GPPSectionUSNat, GPPSectionUSCA},
+		},
+	}
+	body, _ := json.Marshal(req)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/openrtb2/auction", bytes.NewReader(body)))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if got := m.signals["gpp_usnat"]; len(got) != 1 || !got[0] {
+		t.Errorf("expected one usnat signal with consent, got %v", got)
+	}
+	if got := m.signals["gpp_usca"]; len(got) != 1 || got[0] {
+		t.Errorf("expected one usca signal without consent, got %v", got)
+	}
+	if !optOut {
+		t.Error("expected the GPC opt-out in the privacy context")
+	}
+}
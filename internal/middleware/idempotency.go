@@ -0,0 +1,301 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+// IdempotencyKeyHeader carries the client-chosen key that makes an admin
+// mutation safe to retry
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayedHeader is set on responses replayed from an earlier
+// request with the same key
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+// DefaultIdempotencyTTL is how long results are kept for replay
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+const (
+	// idempotencyKeyPrefix namespaces results in the KV store
+	idempotencyKeyPrefix = "pbs:idempotency:"
+
+	// maxIdempotencyKeyLen bounds client keys; UUIDs are 36 characters
+	maxIdempotencyKeyLen = 255
+
+	// idempotencyPendingTTL bounds how long a request that never finished
+	// (e.g. the instance died) blocks its key
+	idempotencyPendingTTL = time.Minute
+
+	// maxIdempotentBody bounds the response bodies stored for replay; larger
+	// responses are not replayed
+	maxIdempotentBody = 1 << 20
+)
+
+// idempotentMethods are the mutations keys apply to
+var idempotentMethods = map[string]bool{
+	http.MethodPost:  true,
+	http.MethodPut:   true,
+	http.MethodPatch: true,
+}
+
+// IdempotencyStore is the subset of the KV store holding results
+type IdempotencyStore interface {
+	Get(ctx context.Context, key string) (string, error)
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
+	SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error)
+	Del(ctx context.Context, keys ...string) error
+}
+
+// idempotentResult is the stored outcome of a keyed request. Hash identifies
+// the request the key was first used with.
+type idempotentResult struct {
+	Hash    string      `json:"hash"`
+	Pending bool        `json:"pending,omitempty"`
+	Status  int         `json:"status,omitempty"`
+	Header  http.Header `json:"header,omitempty"`
+	Body    []byte      `json:"body,omitempty"`
+}
+
+// Idempotency replays the result of admin mutations retried with the same
+// Idempotency-Key, so a script retrying after a network blip doesn't create
+// a publisher twice or toggle a bidder back. Keys are scoped to the caller's
+// API key; reusing one for a different request is rejected with 422, and a
+// retry while the first attempt still runs with 409.
+type Idempotency struct {
+	store    IdempotencyStore
+	ttl      time.Duration
+	prefixes []string
+
+	mu       sync.Mutex
+	inflight map[string]bool
+}
+
+// NewIdempotency creates the middleware for /admin/ paths; ttl <= 0 uses
+// DefaultIdempotencyTTL
+func NewIdempotency(store IdempotencyStore, ttl time.Duration) *Idempotency {
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyTTL
+	}
+	return &Idempotency{
+		store:    store,
+		ttl:      ttl,
+		prefixes: []string{"/admin/"},
+		inflight: make(map[string]bool),
+	}
+}
+
+// Middleware applies keys to POST, PUT and PATCH requests under the admin
+// paths. Requests without a key, and every request while the store is
+// unreachable, pass through unchanged.
+func (i *Idempotency) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" || !idempotentMethods[r.Method] || !i.applies(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLen {
+			http.Error(w, `{"error":"Idempotency-Key is too long"}`, http.StatusBadRequest)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, `{"error":"failed to read request body"}`, http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		storeKey := idempotencyKeyPrefix + scopedIdempotencyKey(r, key)
+		hash := requestHash(r, body)
+
+		if !i.begin(storeKey) {
+			writeIdempotencyConflict(w)
+			return
+		}
+		defer i.end(storeKey)
+
+		ctx := r.Context()
+		log := logger.Log
+		// Claim the key with a pending record atomically, so concurrent
+		// retries on different instances can't both run the request
+		claimed, err := i.claim(ctx, storeKey, hash)
+		if err != nil {
+			log.Warn().Err(err).Msg("Idempotency store unavailable, serving request without replay protection")
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !claimed {
+			i.answerExisting(ctx, w, storeKey, hash)
+			return
+		}
+
+		rec := &idempotencyRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		// Server errors and unreplayable responses leave the key free so the
+		// retry runs the request again
+		if rec.status >= 500 || rec.overflow {
+			if err := i.store.Del(context.WithoutCancel(ctx), storeKey); err != nil {
+				log.Warn().Err(err).Msg("Failed to release idempotency key")
+			}
+			return
+		}
+		result := &idempotentResult{
+			Hash:   hash,
+			Status: rec.status,
+			Header: rec.Header().Clone(),
+			Body:   rec.body.Bytes(),
+		}
+		if err := i.save(context.WithoutCancel(ctx), storeKey, result, i.ttl); err != nil {
+			log.Warn().Err(err).Msg("Failed to store idempotent result")
+		}
+	})
+}
+
+// applies reports whether path is under one of the keyed prefixes
+func (i *Idempotency) applies(path string) bool {
+	for _, p := range i.prefixes {
+		if strings.HasPrefix(path, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// begin claims key on this instance, reporting false if a request with it is
+// already running here. The pending record claimed with SetNX covers other
+// instances.
+func (i *Idempotency) begin(key string) bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.inflight[key] {
+		return false
+	}
+	i.inflight[key] = true
+	return true
+}
+
+// end releases a key claimed by begin
+func (i *Idempotency) end(key string) {
+	i.mu.Lock()
+	delete(i.inflight, key)
+	i.mu.Unlock()
+}
+
+// claim stores a pending record under key unless one already exists,
+// reporting whether this request owns the key
+func (i *Idempotency) claim(ctx context.Context, key, hash string) (bool, error) {
+	data, err := json.Marshal(&idempotentResult{Hash: hash, Pending: true})
+	if err != nil {
+		return false, err
+	}
+	return i.store.SetNX(ctx, key, string(data), idempotencyPendingTTL)
+}
+
+// answerExisting answers a request whose key is already claimed: 422 when
+// the key was used for another request, 409 while the first attempt runs,
+// and the stored result once it finished
+func (i *Idempotency) answerExisting(ctx context.Context, w http.ResponseWriter, key, hash string) {
+	raw, err := i.store.Get(ctx, key)
+	var prev idempotentResult
+	if err != nil || raw == "" || json.Unmarshal([]byte(raw), &prev) != nil {
+		// The record expired or can't be read; the retry claims it afresh
+		writeIdempotencyConflict(w)
+		return
+	}
+	switch {
+	case prev.Hash != hash:
+		http.Error(w, `{"error":"Idempotency-Key was already used for a different request"}`, http.StatusUnprocessableEntity)
+	case prev.Pending:
+		writeIdempotencyConflict(w)
+	default:
+		replay(w, &prev)
+	}
+}
+
+// save stores a result under key
+func (i *Idempotency) save(ctx context.Context, key string, result *idempotentResult, ttl time.Duration) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	return i.store.Set(ctx, key, string(data), ttl)
+}
+
+// scopedIdempotencyKey hashes the client key with the caller's credential, so
+// callers can't see each other's results and credentials aren't stored
+func scopedIdempotencyKey(r *http.Request, key string) string {
+	credential := r.Header.Get("X-API-Key")
+	if credential == "" {
+		credential = r.Header.Get("Authorization")
+	}
+	sum := sha256.Sum256([]byte(credential + "\x00" + key))
+	return hex.EncodeToString(sum[:])
+}
+
+// requestHash identifies a request by method, URL and body
+func requestHash(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// replay writes a stored result
+func replay(w http.ResponseWriter, result *idempotentResult) {
+	for name, values := range result.Header {
+		w.Header()[name] = values
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(result.Status)
+	_, _ = w.Write(result.Body)
+}
+
+// writeIdempotencyConflict answers a retry whose first attempt still runs
+func writeIdempotencyConflict(w http.ResponseWriter) {
+	w.Header().Set("Retry-After", "1")
+	http.Error(w, `{"error":"a request with this Idempotency-Key is in progress"}`, http.StatusConflict)
+}
+
+// idempotencyRecorder passes a response through while keeping a copy for
+// replay
+type idempotencyRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+	overflow    bool
+}
+
+func (r *idempotencyRecorder) WriteHeader(code int) {
+	if !r.wroteHeader {
+		r.wroteHeader = true
+		r.status = code
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *idempotencyRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	if !r.overflow {
+		if r.body.Len()+len(b) > maxIdempotentBody {
+			r.overflow = true
+			r.body.Reset()
+		} else {
+			r.body.Write(b)
+		}
+	}
+	return r.ResponseWriter.Write(b)
+}
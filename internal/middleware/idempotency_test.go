@@ -0,0 +1,177 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/thenexusengine/tne_springwire/pkg/kv"
+)
+
+func TestIdempotency_ReplaysResult(t *testing.T) {
+	var calls atomic.Int32
+	handler := NewIdempotency(kv.NewMemory(), 0).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":"pub-` + string(rune('0'+n)) + `"}`))
+	}))
+
+	send := func(key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/admin/publishers", strings.NewReader(body))
+		req.Header.Set("X-API-Key", "admin-key")
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := send("create-1", `{"name":"a"}`)
+	retry := send("create-1", `{"name":"a"}`)
+	if calls.Load() != 1 {
+		t.Fatalf("Expected the handler to run once, ran %d times", calls.Load())
+	}
+	if retry.Code != http.StatusCreated || retry.Body.String() != first.Body.String() {
+		t.Errorf("Expected the first result replayed, got %d %s", retry.Code, retry.Body.String())
+	}
+	if retry.Header().Get(IdempotentReplayedHeader) != "true" || retry.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("Expected replayed headers, got %v", retry.Header())
+	}
+	if first.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Error("Expected the first response not to be marked replayed")
+	}
+
+	if rec := send("create-1", `{"name":"b"}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected 422 for a reused key, got %d", rec.Code)
+	}
+
+	send("", `{"name":"a"}`)
+	send("", `{"name":"a"}`)
+	if calls.Load() != 3 {
+		t.Errorf("Expected requests without a key to always run, ran %d times", calls.Load())
+	}
+}
+
+func TestIdempotency_ScopeAndErrors(t *testing.T) {
+	var calls atomic.Int32
+	status := http.StatusInternalServerError
+	handler := NewIdempotency(kv.NewMemory(), 0).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(status)
+	}))
+
+	send := func(method, path, apiKey string) int {
+		req := httptest.NewRequest(method, path, strings.NewReader(`{}`))
+		req.Header.Set("X-API-Key", apiKey)
+		req.Header.Set(IdempotencyKeyHeader, "k")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// Server errors aren't stored, so the retry runs again
+	send(http.MethodPut, "/admin/api/bidders/appnexus", "a")
+	status = http.StatusOK
+	send(http.MethodPut, "/admin/api/bidders/appnexus", "a")
+	send(http.MethodPut, "/admin/api/bidders/appnexus", "a")
+	if calls.Load() != 2 {
+		t.Errorf("Expected the request to rerun after a server error only, ran %d times", calls.Load())
+	}
+
+	// Another caller's key is separate
+	send(http.MethodPut, "/admin/api/bidders/appnexus", "b")
+	if calls.Load() != 3 {
+		t.Errorf("Expected keys to be scoped per caller, ran %d times", calls.Load())
+	}
+
+	// Reads and non-admin paths are untouched
+	send(http.MethodGet, "/admin/api/bidders/appnexus", "a")
+	send(http.MethodPost, "/cache", "a")
+	send(http.MethodPost, "/cache", "a")
+	if calls.Load() != 6 {
+		t.Errorf("Expected reads and non-admin paths to bypass keys, ran %d times", calls.Load())
+	}
+}
+
+func TestIdempotency_InProgress(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := NewIdempotency(kv.NewMemory(), 0).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	}))
+
+	newReq := func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/admin/deals", strings.NewReader(`{}`))
+		req.Header.Set(IdempotencyKeyHeader, "deal-1")
+		return req
+	}
+
+	done := make(chan struct{})
+	go func() {
+		handler.ServeHTTP(httptest.NewRecorder(), newReq())
+		close(done)
+	}()
+	<-started
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, newReq())
+	if rec.Code != http.StatusConflict || rec.Header().Get("Retry-After") == "" {
+		t.Errorf("Expected 409 with Retry-After while in progress, got %d", rec.Code)
+	}
+	close(release)
+	<-done
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, newReq())
+	if rec.Code != http.StatusOK || rec.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Errorf("Expected the finished result replayed, got %d", rec.Code)
+	}
+}
+
+func TestIdempotency_SharedAcrossInstances(t *testing.T) {
+	store := kv.NewMemory()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var calls atomic.Int32
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			close(started)
+			<-release
+		}
+	}
+	first := NewIdempotency(store, 0).Middleware(http.HandlerFunc(handler))
+	second := NewIdempotency(store, 0).Middleware(http.HandlerFunc(handler))
+
+	newReq := func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/admin/deals", strings.NewReader(`{}`))
+		req.Header.Set(IdempotencyKeyHeader, "deal-1")
+		return req
+	}
+
+	done := make(chan struct{})
+	go func() {
+		first.ServeHTTP(httptest.NewRecorder(), newReq())
+		close(done)
+	}()
+	<-started
+
+	// The other instance sees the claim made through the shared store
+	rec := httptest.NewRecorder()
+	second.ServeHTTP(rec, newReq())
+	if rec.Code != http.StatusConflict {
+		t.Errorf("Expected 409 from the other instance while in progress, got %d", rec.Code)
+	}
+	close(release)
+	<-done
+
+	rec = httptest.NewRecorder()
+	second.ServeHTTP(rec, newReq())
+	if rec.Code != http.StatusOK || rec.Header().Get(IdempotentReplayedHeader) != "true" || calls.Load() != 1 {
+		t.Errorf("Expected the other instance to replay the result, got %d after %d calls", rec.Code, calls.Load())
+	}
+}
@@ -3,6 +3,7 @@ package middleware
 
 import (
 	"context"
+	"fmt"
 	"net"
 	"net/http"
 	"os"
@@ -32,6 +33,97 @@ type IVTConfig struct {
 	SuspiciousUAPatterns []string // Regex patterns for suspicious user agents
 	RequireReferer       bool     // Require referer header (strict mode)
 	GeoIPDBPath          string   // Path to MaxMind GeoIP2/GeoLite2 database file
+
+	// Scoring: ScoreThreshold and SignalWeights apply to every channel
+	// without its own values in Channels
+	ScoreThreshold int                         // Score at which traffic is flagged (and blocked)
+	SignalWeights  map[string]int              // Points per signal type; unweighted signals score by severity
+	Channels       map[string]IVTChannelConfig // Per-channel overrides keyed by IVTChannel*
+}
+
+// Traffic channels with their own IVT scoring. CTV, app and web traffic
+// have very different base rates, so one cutoff over-blocks one of them.
+const (
+	IVTChannelSite = "site"
+	IVTChannelApp  = "app"
+	IVTChannelCTV  = "ctv"
+	IVTChannelDOOH = "dooh"
+)
+
+// IVTChannels lists the channels that accept scoring overrides
+var IVTChannels = []string{IVTChannelSite, IVTChannelApp, IVTChannelCTV, IVTChannelDOOH}
+
+// IVT signal types that accept weights
+var IVTSignalTypes = []string{"suspicious_ua", "invalid_referer", "geo_restricted", "geo_blocked"}
+
+// DefaultIVTScoreThreshold is the score at which traffic is flagged
+const DefaultIVTScoreThreshold = 70
+
+// IVTChannelConfig overrides IVT scoring for one traffic channel
+type IVTChannelConfig struct {
+	ScoreThreshold int            `json:"score_threshold,omitempty"` // 0 = global threshold
+	SignalWeights  map[string]int `json:"signal_weights,omitempty"`  // Merged over the global weights
+}
+
+// ValidateScoring checks the score threshold, signal weights and channel
+// overrides, which can be changed at runtime through the admin API
+func (c *IVTConfig) ValidateScoring() error {
+	if c.ScoreThreshold < 1 || c.ScoreThreshold > 100 {
+		return fmt.Errorf("score_threshold must be between 1 and 100, got %d", c.ScoreThreshold)
+	}
+	if err := validateSignalWeights(c.SignalWeights); err != nil {
+		return err
+	}
+	for channel, cc := range c.Channels {
+		if !contains(IVTChannels, channel) {
+			return fmt.Errorf("unknown channel %q, must be one of %s", channel, strings.Join(IVTChannels, ", "))
+		}
+		if cc.ScoreThreshold < 0 || cc.ScoreThreshold > 100 {
+			return fmt.Errorf("%s score_threshold must be between 0 and 100, got %d", channel, cc.ScoreThreshold)
+		}
+		if err := validateSignalWeights(cc.SignalWeights); err != nil {
+			return fmt.Errorf("%s: %w", channel, err)
+		}
+	}
+	return nil
+}
+
+func validateSignalWeights(weights map[string]int) error {
+	for signal, weight := range weights {
+		if !contains(IVTSignalTypes, signal) {
+			return fmt.Errorf("unknown signal %q, must be one of %s", signal, strings.Join(IVTSignalTypes, ", "))
+		}
+		if weight < 0 || weight > 100 {
+			return fmt.Errorf("signal %s weight must be between 0 and 100, got %d", signal, weight)
+		}
+	}
+	return nil
+}
+
+// scoring returns the threshold and signal weights for a channel
+func (c *IVTConfig) scoring(channel string) (int, map[string]int) {
+	threshold := c.ScoreThreshold
+	if threshold <= 0 {
+		threshold = DefaultIVTScoreThreshold
+	}
+	cc, ok := c.Channels[channel]
+	if !ok {
+		return threshold, c.SignalWeights
+	}
+	if cc.ScoreThreshold > 0 {
+		threshold = cc.ScoreThreshold
+	}
+	if len(cc.SignalWeights) == 0 {
+		return threshold, c.SignalWeights
+	}
+	weights := make(map[string]int, len(c.SignalWeights)+len(cc.SignalWeights))
+	for signal, weight := range c.SignalWeights {
+		weights[signal] = weight
+	}
+	for signal, weight := range cc.SignalWeights {
+		weights[signal] = weight
+	}
+	return threshold, weights
 }
 
 // DefaultIVTConfig returns production-safe defaults with environment variable overrides
@@ -61,6 +153,29 @@ func DefaultIVTConfig() *IVTConfig {
 		return []string{}
 	}
 
+	// Helper to parse "channel:threshold" pairs (comma-separated)
+	parseChannelThresholds := func(envKey string) map[string]IVTChannelConfig {
+		channels := make(map[string]IVTChannelConfig)
+		for _, pair := range parseStringSlice(envKey) {
+			channel, value, _ := strings.Cut(pair, ":")
+			threshold, err := strconv.Atoi(strings.TrimSpace(value))
+			channel = strings.ToLower(strings.TrimSpace(channel))
+			if err != nil || !contains(IVTChannels, channel) {
+				log.Warn().Str("value", pair).Msgf("Ignoring invalid %s entry", envKey)
+				continue
+			}
+			channels[channel] = IVTChannelConfig{ScoreThreshold: threshold}
+		}
+		return channels
+	}
+
+	scoreThreshold := DefaultIVTScoreThreshold
+	if val := os.Getenv("IVT_SCORE_THRESHOLD"); val != "" {
+		if parsed, err := strconv.Atoi(val); err == nil && parsed >= 1 && parsed <= 100 {
+			scoreThreshold = parsed
+		}
+	}
+
 	// Parse monitoring and blocking flags
 	monitoringEnabled := parseBool("IVT_MONITORING_ENABLED", true)
 	blockingEnabled := parseBool("IVT_BLOCKING_ENABLED", false)
@@ -118,6 +233,12 @@ func DefaultIVTConfig() *IVTConfig {
 		// GEOIP_DB_PATH: Path to MaxMind GeoIP2/GeoLite2 database file
 		// Example: "/usr/share/GeoIP/GeoLite2-Country.mmdb"
 		GeoIPDBPath: os.Getenv("GEOIP_DB_PATH"),
+
+		// IVT_SCORE_THRESHOLD: Score at which traffic is flagged (default: 70)
+		ScoreThreshold: scoreThreshold,
+
+		// IVT_CHANNEL_THRESHOLDS: Per-channel thresholds (e.g., "ctv:85,site:60")
+		Channels: parseChannelThresholds("IVT_CHANNEL_THRESHOLDS"),
 	}
 
 	return config
@@ -138,6 +259,8 @@ type IVTResult struct {
 	Score         int           // IVT score (0-100, higher = more suspicious)
 	BlockReason   string        // Reason for blocking (if blocked)
 	ShouldBlock   bool          // Whether to block this request
+	Channel       string        // Traffic channel scored against (empty = global scoring)
+	Threshold     int           // Score threshold applied
 	PublisherID   string        // Publisher ID from request
 	Domain        string        // Domain from request
 	IPAddress     string        // Client IP
@@ -334,8 +457,14 @@ func (d *IVTDetector) getCompiledPatterns() []*regexp.Regexp {
 	return d.uaPatterns
 }
 
-// Validate performs IVT detection on a request
+// Validate performs IVT detection on a request using the global scoring
 func (d *IVTDetector) Validate(ctx context.Context, r *http.Request, publisherID, domain string) *IVTResult {
+	return d.ValidateChannel(ctx, r, publisherID, domain, "")
+}
+
+// ValidateChannel performs IVT detection on a request, scoring it with the
+// threshold and signal weights of its traffic channel
+func (d *IVTDetector) ValidateChannel(ctx context.Context, r *http.Request, publisherID, domain, channel string) *IVTResult {
 	startTime := time.Now()
 
 	// Snapshot entire config once to reduce lock contention
@@ -347,6 +476,7 @@ func (d *IVTDetector) Validate(ctx context.Context, r *http.Request, publisherID
 		IsValid:     true,
 		Signals:     []IVTSignal{},
 		Score:       0,
+		Channel:     channel,
 		PublisherID: publisherID,
 		Domain:      domain,
 		IPAddress:   getClientIP(r),
@@ -365,9 +495,11 @@ func (d *IVTDetector) Validate(ctx context.Context, r *http.Request, publisherID
 	d.checkGeoWithConfig(r, result, &cfg)
 
 	// Calculate final score and decision
-	result.Score = d.calculateScore(result.Signals)
-	result.ShouldBlock = cfg.BlockingEnabled && result.Score >= 70 // Block at 70+ score
-	result.IsValid = result.Score < 70                             // Valid if score < 70
+	threshold, weights := cfg.scoring(channel)
+	result.Threshold = threshold
+	result.Score = d.calculateScore(result.Signals, weights)
+	result.ShouldBlock = cfg.BlockingEnabled && result.Score >= threshold
+	result.IsValid = result.Score < threshold
 
 	if result.ShouldBlock && len(result.Signals) > 0 {
 		result.BlockReason = result.Signals[0].Description // Use first signal as reason
@@ -515,10 +647,15 @@ func (d *IVTDetector) checkGeoWithConfig(r *http.Request, result *IVTResult, cfg
 	}
 }
 
-// calculateScore computes IVT score from signals
-func (d *IVTDetector) calculateScore(signals []IVTSignal) int {
+// calculateScore computes IVT score from signals, using the weight for each
+// signal type when one is set and its severity otherwise
+func (d *IVTDetector) calculateScore(signals []IVTSignal, weights map[string]int) int {
 	score := 0
 	for _, signal := range signals {
+		if weight, ok := weights[signal.Type]; ok {
+			score += weight
+			continue
+		}
 		switch signal.Severity {
 		case "low":
 			score += 15
@@ -149,6 +149,75 @@ func TestIVTDetector_Scoring(t *testing.T) {
 	}
 }
 
+func TestIVTDetector_ChannelScoring(t *testing.T) {
+	config := DefaultIVTConfig()
+	config.BlockingEnabled = true
+	config.ScoreThreshold = 40
+	config.Channels = map[string]IVTChannelConfig{
+		IVTChannelCTV:  {ScoreThreshold: 90},
+		IVTChannelSite: {SignalWeights: map[string]int{"suspicious_ua": 20}},
+	}
+	detector := NewIVTDetector(config)
+
+	tests := []struct {
+		channel         string
+		expectScore     int
+		expectBlock     bool
+		expectThreshold int
+	}{
+		{"", 50, true, 40},              // global threshold
+		{IVTChannelApp, 50, true, 40},   // no override
+		{IVTChannelCTV, 50, false, 90},  // higher CTV threshold
+		{IVTChannelSite, 20, false, 40}, // lighter UA weight
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("POST", "/openrtb2/auction", nil)
+		req.Header.Set("User-Agent", "Googlebot/2.1")
+
+		result := detector.ValidateChannel(context.Background(), req, "test-pub", "", tt.channel)
+		if result.Score != tt.expectScore || result.ShouldBlock != tt.expectBlock || result.Threshold != tt.expectThreshold {
+			t.Errorf("channel %q: expected score=%d block=%v threshold=%d, got score=%d block=%v threshold=%d",
+				tt.channel, tt.expectScore, tt.expectBlock, tt.expectThreshold, result.Score, result.ShouldBlock, result.Threshold)
+		}
+	}
+}
+
+func TestIVTConfig_ValidateScoring(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  IVTConfig
+		wantErr bool
+	}{
+		{"valid", IVTConfig{ScoreThreshold: 70, Channels: map[string]IVTChannelConfig{IVTChannelCTV: {ScoreThreshold: 85}}}, false},
+		{"missing threshold", IVTConfig{}, true},
+		{"threshold above 100", IVTConfig{ScoreThreshold: 101}, true},
+		{"unknown signal", IVTConfig{ScoreThreshold: 70, SignalWeights: map[string]int{"bogus": 10}}, true},
+		{"negative weight", IVTConfig{ScoreThreshold: 70, SignalWeights: map[string]int{"suspicious_ua": -1}}, true},
+		{"unknown channel", IVTConfig{ScoreThreshold: 70, Channels: map[string]IVTChannelConfig{"web": {}}}, true},
+		{"channel threshold above 100", IVTConfig{ScoreThreshold: 70, Channels: map[string]IVTChannelConfig{IVTChannelApp: {ScoreThreshold: 150}}}, true},
+	}
+
+	for _, tt := range tests {
+		if err := tt.config.ValidateScoring(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: expected error=%v, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+}
+
+func TestDefaultIVTConfig_ChannelThresholds(t *testing.T) {
+	t.Setenv("IVT_SCORE_THRESHOLD", "60")
+	t.Setenv("IVT_CHANNEL_THRESHOLDS", "ctv:85, DOOH:90, web:50")
+
+	config := DefaultIVTConfig()
+	if config.ScoreThreshold != 60 {
+		t.Errorf("expected threshold 60, got %d", config.ScoreThreshold)
+	}
+	if len(config.Channels) != 2 || config.Channels[IVTChannelCTV].ScoreThreshold != 85 || config.Channels[IVTChannelDOOH].ScoreThreshold != 90 {
+		t.Errorf("expected ctv and dooh thresholds, got %+v", config.Channels)
+	}
+}
+
 func TestIVTDetector_Disabled(t *testing.T) {
 	config := DefaultIVTConfig()
 	config.MonitoringEnabled = false
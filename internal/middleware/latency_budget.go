@@ -0,0 +1,201 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Latency budget headers exchanged with SSAI callers
+const (
+	// HeaderLatencyBudget is the caller's total budget for this request, in milliseconds
+	HeaderLatencyBudget = "X-Latency-Budget"
+	// HeaderLatencySpent is the time spent serving the request, in milliseconds
+	HeaderLatencySpent = "X-Latency-Spent"
+	// HeaderLatencyRemaining is the budget left for the caller, in milliseconds
+	HeaderLatencyRemaining = "X-Latency-Remaining"
+)
+
+const (
+	// maxLatencyBudgetMs bounds caller-supplied budgets (matches the tmax upper bound)
+	maxLatencyBudgetMs = 30000
+	// minAuctionTMaxMs is the smallest tmax accepted by request validation
+	minAuctionTMaxMs = 10
+	// defaultLatencyReserve is kept back from the budget for response encoding and network
+	defaultLatencyReserve = 20 * time.Millisecond
+	// unknownPartner labels budget metrics when the caller can't be identified
+	unknownPartner = "unknown"
+	// otherPartner labels budget metrics for callers not in the partner list,
+	// keeping the label's cardinality bounded
+	otherPartner = "other"
+)
+
+type latencyBudgetContextKey struct{}
+
+// LatencyBudgetMetrics records latency budget adherence per partner
+type LatencyBudgetMetrics interface {
+	RecordLatencyBudget(partner string, budget, spent time.Duration)
+}
+
+// LatencyBudget tracks a caller's end-to-end latency budget for one request.
+// It is owned by the request goroutine and must not be shared across goroutines.
+type LatencyBudget struct {
+	start   time.Time
+	budget  time.Duration
+	reserve time.Duration
+	partner string
+	known   map[string]bool
+}
+
+// LatencyBudgetFromContext returns the request's latency budget, or nil when
+// the latency budget middleware is not installed
+func LatencyBudgetFromContext(ctx context.Context) *LatencyBudget {
+	b, _ := ctx.Value(latencyBudgetContextKey{}).(*LatencyBudget)
+	return b
+}
+
+// Budget returns the caller's total budget (0 when none was supplied)
+func (b *LatencyBudget) Budget() time.Duration {
+	return b.budget
+}
+
+// Spent returns the time spent since the request was received
+func (b *LatencyBudget) Spent() time.Duration {
+	return time.Since(b.start)
+}
+
+// Remaining returns the budget left, never negative (0 when no budget is set)
+func (b *LatencyBudget) Remaining() time.Duration {
+	if b.budget <= 0 {
+		return 0
+	}
+	if r := b.budget - b.Spent(); r > 0 {
+		return r
+	}
+	return 0
+}
+
+// SetPartner sets the partner label used for budget metrics. Partners not in
+// the middleware's partner list are labelled "other".
+func (b *LatencyBudget) SetPartner(partner string) {
+	switch {
+	case partner == "":
+	case b.known[partner]:
+		b.partner = partner
+	default:
+		b.partner = otherPartner
+	}
+}
+
+// ApplyTMax returns the auction tmax (ms) to use given the caller's budget.
+// Without an X-Latency-Budget header the request's tmax becomes the budget.
+// With one, tmax is lowered to the remaining budget minus a response reserve.
+func (b *LatencyBudget) ApplyTMax(tmax int) int {
+	if b.budget <= 0 {
+		if tmax > 0 {
+			b.budget = time.Duration(tmax) * time.Millisecond
+		}
+		return tmax
+	}
+
+	available := int((b.Remaining() - b.reserve) / time.Millisecond)
+	if available < minAuctionTMaxMs {
+		available = minAuctionTMaxMs
+	}
+	if tmax <= 0 || available < tmax {
+		return available
+	}
+	return tmax
+}
+
+// ParseLatencyBudget parses an X-Latency-Budget header value in milliseconds.
+// Invalid, non-positive or out-of-range values return 0 (no budget).
+func ParseLatencyBudget(value string) time.Duration {
+	ms, err := strconv.Atoi(value)
+	if err != nil || ms <= 0 || ms > maxLatencyBudgetMs {
+		return 0
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// LatencyBudgetMiddleware accepts X-Latency-Budget from callers, reports
+// X-Latency-Spent / X-Latency-Remaining on responses and records budget adherence
+type LatencyBudgetMiddleware struct {
+	metrics  LatencyBudgetMetrics
+	reserve  time.Duration
+	partners map[string]bool
+}
+
+// NewLatencyBudgetMiddleware creates a latency budget middleware (metrics may
+// be nil). Budget metrics are labelled with the partners listed; any other
+// caller is recorded as "other".
+func NewLatencyBudgetMiddleware(metrics LatencyBudgetMetrics, partners []string) *LatencyBudgetMiddleware {
+	known := make(map[string]bool, len(partners))
+	for _, p := range partners {
+		known[p] = true
+	}
+	return &LatencyBudgetMiddleware{
+		metrics:  metrics,
+		reserve:  defaultLatencyReserve,
+		partners: known,
+	}
+}
+
+// Middleware returns the latency budget middleware handler
+func (m *LatencyBudgetMiddleware) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		budget := &LatencyBudget{
+			start:   time.Now(),
+			budget:  ParseLatencyBudget(r.Header.Get(HeaderLatencyBudget)),
+			reserve: m.reserve,
+			partner: unknownPartner,
+			known:   m.partners,
+		}
+
+		wrapped := &latencyBudgetWriter{ResponseWriter: w, budget: budget}
+		next.ServeHTTP(wrapped, r.WithContext(context.WithValue(r.Context(), latencyBudgetContextKey{}, budget)))
+
+		if m.metrics != nil && budget.budget > 0 {
+			m.metrics.RecordLatencyBudget(budget.partner, budget.budget, budget.Spent())
+		}
+	})
+}
+
+// latencyBudgetWriter stamps latency headers just before the response header is written
+type latencyBudgetWriter struct {
+	http.ResponseWriter
+	budget      *LatencyBudget
+	wroteHeader bool
+}
+
+func (w *latencyBudgetWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		h := w.ResponseWriter.Header()
+		h.Set(HeaderLatencySpent, strconv.FormatInt(w.budget.Spent().Milliseconds(), 10))
+		if w.budget.budget > 0 {
+			h.Set(HeaderLatencyRemaining, strconv.FormatInt(w.budget.Remaining().Milliseconds(), 10))
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *latencyBudgetWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher when the underlying writer supports it
+func (w *latencyBudgetWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (w *latencyBudgetWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
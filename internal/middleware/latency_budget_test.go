@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type mockLatencyBudgetMetrics struct {
+	partner string
+	budget  time.Duration
+	calls   int
+}
+
+func (m *mockLatencyBudgetMetrics) RecordLatencyBudget(partner string, budget, spent time.Duration) {
+	m.partner = partner
+	m.budget = budget
+	m.calls++
+}
+
+func TestParseLatencyBudget(t *testing.T) {
+	tests := []struct {
+		input string
+		want  time.Duration
+	}{
+		{"250", 250 * time.Millisecond},
+		{"", 0},
+		{"abc", 0},
+		{"-5", 0},
+		{"0", 0},
+		{"30001", 0},
+	}
+
+	for _, tt := range tests {
+		if got := ParseLatencyBudget(tt.input); got != tt.want {
+			t.Errorf("ParseLatencyBudget(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestLatencyBudget_ApplyTMax(t *testing.T) {
+	// Header budget lowers a larger tmax
+	b := &LatencyBudget{start: time.Now(), budget: 300 * time.Millisecond, reserve: 20 * time.Millisecond}
+	if got := b.ApplyTMax(1000); got > 280 || got < 270 {
+		t.Errorf("expected tmax near 280ms, got %d", got)
+	}
+	// A smaller tmax is kept
+	if got := b.ApplyTMax(100); got != 100 {
+		t.Errorf("expected tmax 100 to be kept, got %d", got)
+	}
+
+	// An exhausted budget still yields the minimum valid tmax
+	spent := &LatencyBudget{start: time.Now().Add(-time.Second), budget: 100 * time.Millisecond}
+	if got := spent.ApplyTMax(500); got != minAuctionTMaxMs {
+		t.Errorf("expected minimum tmax %d, got %d", minAuctionTMaxMs, got)
+	}
+
+	// Without a header, tmax becomes the budget
+	noHeader := &LatencyBudget{start: time.Now()}
+	if got := noHeader.ApplyTMax(500); got != 500 {
+		t.Errorf("expected tmax unchanged, got %d", got)
+	}
+	if noHeader.Budget() != 500*time.Millisecond {
+		t.Errorf("expected tmax to become the budget, got %v", noHeader.Budget())
+	}
+}
+
+func TestLatencyBudgetMiddleware_Headers(t *testing.T) {
+	m := &mockLatencyBudgetMetrics{}
+	mw := NewLatencyBudgetMiddleware(m, []string{"pub-123"})
+
+	var budget *LatencyBudget
+	handler := mw.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		budget = LatencyBudgetFromContext(r.Context())
+		budget.SetPartner("pub-123")
+		w.Write([]byte("ok"))
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/openrtb2/auction", nil)
+	req.Header.Set(HeaderLatencyBudget, "500")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if budget == nil {
+		t.Fatal("expected latency budget in context")
+	}
+	if _, err := strconv.Atoi(rr.Header().Get(HeaderLatencySpent)); err != nil {
+		t.Errorf("expected numeric %s header, got %q", HeaderLatencySpent, rr.Header().Get(HeaderLatencySpent))
+	}
+	remaining, err := strconv.Atoi(rr.Header().Get(HeaderLatencyRemaining))
+	if err != nil || remaining <= 0 || remaining > 500 {
+		t.Errorf("expected remaining budget in (0, 500], got %q", rr.Header().Get(HeaderLatencyRemaining))
+	}
+	if m.calls != 1 || m.partner != "pub-123" || m.budget != 500*time.Millisecond {
+		t.Errorf("expected budget recorded for pub-123, got %+v", m)
+	}
+}
+
+func TestLatencyBudgetMiddleware_UnlistedPartner(t *testing.T) {
+	m := &mockLatencyBudgetMetrics{}
+	handler := NewLatencyBudgetMiddleware(m, []string{"pub-123"}).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		LatencyBudgetFromContext(r.Context()).SetPartner("pub-attacker-42")
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/openrtb2/auction", nil)
+	req.Header.Set(HeaderLatencyBudget, "500")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if m.partner != otherPartner {
+		t.Errorf("expected unlisted partner recorded as %q, got %q", otherPartner, m.partner)
+	}
+}
+
+func TestLatencyBudgetMiddleware_NoBudget(t *testing.T) {
+	m := &mockLatencyBudgetMetrics{}
+	handler := NewLatencyBudgetMiddleware(m, nil).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/status", nil))
+
+	if rr.Header().Get(HeaderLatencySpent) == "" {
+		t.Error("expected spent header even without a budget")
+	}
+	if rr.Header().Get(HeaderLatencyRemaining) != "" {
+		t.Error("expected no remaining header without a budget")
+	}
+	if m.calls != 0 {
+		t.Errorf("expected no budget metric without a budget, got %d", m.calls)
+	}
+}
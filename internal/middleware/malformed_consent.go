@@ -0,0 +1,189 @@
+// Package middleware provides HTTP middleware components
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+// MalformedConsentPolicy is how a request whose consent string can't be
+// parsed is handled
+type MalformedConsentPolicy string
+
+const (
+	// MalformedConsentReject rejects the request with a 400
+	MalformedConsentReject MalformedConsentPolicy = "reject"
+	// MalformedConsentNoConsent auctions the request as though the user
+	// refused consent (GDPR) or opted out of sale (US privacy)
+	MalformedConsentNoConsent MalformedConsentPolicy = "no_consent"
+	// MalformedConsentOutOfScope ignores the string: the regulation it
+	// belongs to isn't enforced for the request and the string is passed to
+	// bidders unchanged
+	MalformedConsentOutOfScope MalformedConsentPolicy = "out_of_scope"
+)
+
+// Consent string types, used as metrics labels
+const (
+	ConsentStringTCF       = "tcf"
+	ConsentStringGPP       = "gpp"
+	ConsentStringUSPrivacy = "us_privacy"
+)
+
+// ConsentOutcomeValid is the consent string metric outcome for parseable
+// strings; malformed strings are recorded with the policy applied
+const ConsentOutcomeValid = "valid"
+
+// GPP parsing errors
+var (
+	ErrGPPHeader   = errors.New("gpp string must start with a GPP header section")
+	ErrGPPSection  = errors.New("gpp string has an empty section")
+	ErrGPPEncoding = errors.New("gpp sections must be base64url encoded")
+)
+
+// ParseMalformedConsentPolicy parses a policy name, reporting whether it is
+// known
+func ParseMalformedConsentPolicy(value string) (MalformedConsentPolicy, bool) {
+	switch p := MalformedConsentPolicy(strings.ToLower(strings.TrimSpace(value))); p {
+	case MalformedConsentReject, MalformedConsentNoConsent, MalformedConsentOutOfScope:
+		return p, true
+	}
+	return "", false
+}
+
+// getEnvPolicy reads a malformed consent policy from an environment variable,
+// falling back to defaultVal when it is unset or unknown
+func getEnvPolicy(key string, defaultVal MalformedConsentPolicy) MalformedConsentPolicy {
+	val := strings.TrimSpace(os.Getenv(key))
+	if val == "" {
+		return defaultVal
+	}
+	policy, ok := ParseMalformedConsentPolicy(val)
+	if !ok {
+		logger.Log.Warn().
+			Str("variable", key).
+			Str("value", val).
+			Str("default", string(defaultVal)).
+			Msg("Unknown malformed consent policy, using default")
+		return defaultVal
+	}
+	return policy
+}
+
+// ValidateGPP checks the structure of a GPP string: a header section
+// followed by "~"-separated sections, each base64url encoded (with "."
+// separating optional segments). Legacy uspv1 sections such as "1YNN" are
+// valid base64url, so they pass unchanged.
+func ValidateGPP(gpp string) error {
+	sections := strings.Split(gpp, "~")
+	// The header's first 6 bits are the section type, 3, which encodes as "D"
+	if sections[0] == "" || sections[0][0] != 'D' {
+		return ErrGPPHeader
+	}
+	for _, section := range sections {
+		if section == "" {
+			return ErrGPPSection
+		}
+		for i := 0; i < len(section); i++ {
+			c := section[i]
+			if !(c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.') {
+				return ErrGPPEncoding
+			}
+		}
+	}
+	return nil
+}
+
+// malformedConsent records which consent strings on a request failed to parse
+type malformedConsent struct {
+	tcf error // user.consent
+	us  error // regs.us_privacy, regs.ext.us_privacy or regs.gpp
+}
+
+// inspectConsentStrings parses the request's consent strings and records
+// the outcome for each one present, per publisher
+func (m *PrivacyMiddleware) inspectConsentStrings(ctx context.Context, req *openrtb.BidRequest) malformedConsent {
+	var result malformedConsent
+	publisherID := consentPublisherID(ctx)
+
+	if req.User != nil && req.User.Consent != "" {
+		_, result.tcf = m.parseTCFv2String(req.User.Consent)
+		m.recordConsentString(req.ID, publisherID, ConsentStringTCF, result.tcf, m.config.MalformedTCF)
+	}
+
+	if req.Regs == nil {
+		return result
+	}
+	usPrivacy := req.Regs.USPrivacy
+	if usPrivacy == "" && len(req.Regs.Ext) > 0 {
+		var ext struct {
+			USPrivacy string `json:"us_privacy"`
+		}
+		if json.Unmarshal(req.Regs.Ext, &ext) == nil {
+			usPrivacy = ext.USPrivacy
+		}
+	}
+	if usPrivacy != "" {
+		_, err := ParseUSPrivacy(usPrivacy)
+		m.recordConsentString(req.ID, publisherID, ConsentStringUSPrivacy, err, m.config.MalformedGPP)
+		result.us = err
+	}
+	if req.Regs.GPP != "" {
+		err := ValidateGPP(req.Regs.GPP)
+		m.recordConsentString(req.ID, publisherID, ConsentStringGPP, err, m.config.MalformedGPP)
+		if result.us == nil {
+			result.us = err
+		}
+	}
+	return result
+}
+
+// recordConsentString logs malformed strings and counts every string by
+// outcome, so the malformed rate per publisher can be derived
+func (m *PrivacyMiddleware) recordConsentString(requestID, publisherID, signal string, err error, policy MalformedConsentPolicy) {
+	outcome := ConsentOutcomeValid
+	if err != nil {
+		outcome = string(policy)
+		logger.Log.Info().
+			Str("request_id", requestID).
+			Str("publisher_id", publisherID).
+			Str("signal", signal).
+			Str("policy", outcome).
+			Err(err).
+			Msg("Malformed consent string")
+	}
+	if m.metrics != nil {
+		m.metrics.RecordConsentString(publisherID, signal, outcome)
+	}
+}
+
+// checkMalformedUSConsent rejects requests with malformed US privacy or GPP
+// strings under the reject policy
+func (m *PrivacyMiddleware) checkMalformedUSConsent(malformed malformedConsent) *PrivacyViolation {
+	if malformed.us == nil || !m.config.EnforceCCPA || m.config.MalformedGPP != MalformedConsentReject {
+		return nil
+	}
+	return &PrivacyViolation{
+		Regulation:  "CCPA",
+		Reason:      "Invalid US privacy or GPP string: " + malformed.us.Error(),
+		NoBidReason: openrtb.NoBidInvalidRequest,
+	}
+}
+
+// consentPublisherUnknown is the publisher label of unauthenticated requests
+const consentPublisherUnknown = "unknown"
+
+// consentPublisherID returns the publisher authenticated by PublisherAuth.
+// The request's own site/app publisher isn't trusted: any caller could mint
+// new metric series with it.
+func consentPublisherID(ctx context.Context) string {
+	if id := PublisherIDFromContext(ctx); id != "" {
+		return id
+	}
+	return consentPublisherUnknown
+}
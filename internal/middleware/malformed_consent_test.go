@@ -0,0 +1,161 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+func TestValidateGPP(t *testing.T) {
+	tests := []struct {
+		gpp  string
+		want error
+	}{
+		{"DBABMA~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA", nil},
+		{"DBABTA~1YNN", nil},
+		{"DBACNYA~CPXxRfA.YAAAAAAAAAA~1YN-", nil},
+		{"", ErrGPPHeader},
+		{"CPXxRfAPXxRfAAfKABENB", ErrGPPHeader},
+		{"DBABMA~", ErrGPPSection},
+		{"DBABMA~~1YNN", ErrGPPSection},
+		{"DBABMA~not base64!", ErrGPPEncoding},
+	}
+	for _, tt := range tests {
+		if err := ValidateGPP(tt.gpp); !errors.Is(err, tt.want) {
+			t.Errorf("ValidateGPP(%q) = %v, want %v", tt.gpp, err, tt.want)
+		}
+	}
+}
+
+func TestParseMalformedConsentPolicy(t *testing.T) {
+	if p, ok := ParseMalformedConsentPolicy(" No_Consent "); !ok || p != MalformedConsentNoConsent {
+		t.Errorf("expected no_consent, got %q %v", p, ok)
+	}
+	if _, ok := ParseMalformedConsentPolicy("strip"); ok {
+		t.Error("expected unknown policy to be rejected")
+	}
+
+	t.Setenv("PBS_MALFORMED_TCF_POLICY", "out_of_scope")
+	t.Setenv("PBS_MALFORMED_GPP_POLICY", "bogus")
+	cfg := DefaultPrivacyConfig()
+	if cfg.MalformedTCF != MalformedConsentOutOfScope || cfg.MalformedGPP != MalformedConsentOutOfScope {
+		t.Errorf("unexpected policies from env: %q %q", cfg.MalformedTCF, cfg.MalformedGPP)
+	}
+}
+
+// serveMalformedConsent runs req through the privacy middleware and returns
+// the response status and the privacy context the handler saw
+func serveMalformedConsent(t *testing.T, cfg PrivacyConfig, metrics *mockPrivacyMetrics, req *openrtb.BidRequest) (int, context.Context) {
+	t.Helper()
+	var seen context.Context
+	handler := NewPrivacyMiddlewareWithMetrics(cfg, metrics)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = r.Context()
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	body, _ := json.Marshal(req)
+	httpReq := httptest.NewRequest(http.MethodPost, "/openrtb2/auction", bytes.NewReader(body))
+	httpReq = httpReq.WithContext(NewContextWithPublisherID(httpReq.Context(), "pub-1"))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httpReq)
+	return rr.Code, seen
+}
+
+func TestPrivacyMiddleware_MalformedTCF(t *testing.T) {
+	gdpr := 1
+	req := &openrtb.BidRequest{
+		ID:   "malformed-tcf",
+		Imp:  []openrtb.Imp{{ID: "imp1", Banner: &openrtb.Banner{}}},
+		Regs: &openrtb.Regs{GDPR: &gdpr},
+		User: &openrtb.User{Consent: "!!!not-a-consent-string!!!"},
+	}
+
+	tests := []struct {
+		policy        MalformedConsentPolicy
+		wantStatus    int
+		wantApplies   bool
+		wantConsented bool
+	}{
+		{MalformedConsentReject, http.StatusBadRequest, false, false},
+		{MalformedConsentNoConsent, http.StatusOK, true, false},
+		{MalformedConsentOutOfScope, http.StatusOK, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			cfg := DefaultPrivacyConfig()
+			cfg.GeoEnforcement = false
+			cfg.MalformedTCF = tt.policy
+			metrics := &mockPrivacyMetrics{}
+
+			status, ctx := serveMalformedConsent(t, cfg, metrics, req)
+			if status != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, status)
+			}
+			if ctx != nil && (GDPRApplies(ctx) != tt.wantApplies || GDPRConsentValidated(ctx) != tt.wantConsented) {
+				t.Errorf("expected applies=%v consented=%v, got %v %v",
+					tt.wantApplies, tt.wantConsented, GDPRApplies(ctx), GDPRConsentValidated(ctx))
+			}
+			if n := metrics.strings["pub-1/tcf/"+string(tt.policy)]; n != 1 {
+				t.Errorf("expected one malformed tcf string recorded, got %v", metrics.strings)
+			}
+		})
+	}
+}
+
+func TestConsentPublisherID(t *testing.T) {
+	ctx := NewContextWithPublisherID(context.Background(), "pub-1")
+	if got := consentPublisherID(ctx); got != "pub-1" {
+		t.Errorf("expected the authenticated publisher, got %q", got)
+	}
+	if got := consentPublisherID(context.Background()); got != consentPublisherUnknown {
+		t.Errorf("expected %q without authentication, got %q", consentPublisherUnknown, got)
+	}
+}
+
+func TestPrivacyMiddleware_MalformedUSConsent(t *testing.T) {
+	tests := []struct {
+		name       string
+		regs       *openrtb.Regs
+		policy     MalformedConsentPolicy
+		wantStatus int
+		wantOptOut bool
+		wantMetric string
+	}{
+		{"bad us_privacy rejected", &openrtb.Regs{USPrivacy: "2YYY"}, MalformedConsentReject, http.StatusBadRequest, false, "pub-1/us_privacy/reject"},
+		{"bad us_privacy as opt-out", &openrtb.Regs{USPrivacy: "1XYZ"}, MalformedConsentNoConsent, http.StatusOK, true, "pub-1/us_privacy/no_consent"},
+		{"bad gpp ignored", &openrtb.Regs{GPP: "garbage~"}, MalformedConsentOutOfScope, http.StatusOK, false, "pub-1/gpp/out_of_scope"},
+		{"valid gpp", &openrtb.Regs{GPP: "DBABTA~1YNN", GPPSID: []int{6}}, MalformedConsentReject, http.StatusOK, false, "pub-1/gpp/valid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultPrivacyConfig()
+			cfg.GeoEnforcement = false
+			cfg.MalformedGPP = tt.policy
+			metrics := &mockPrivacyMetrics{}
+			req := &openrtb.BidRequest{
+				ID:   "malformed-us",
+				Imp:  []openrtb.Imp{{ID: "imp1", Banner: &openrtb.Banner{}}},
+				Regs: tt.regs,
+			}
+
+			status, ctx := serveMalformedConsent(t, cfg, metrics, req)
+			if status != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, status)
+			}
+			if ctx != nil && CCPAOptOut(ctx) != tt.wantOptOut {
+				t.Errorf("expected opt-out %v, got %v", tt.wantOptOut, CCPAOptOut(ctx))
+			}
+			if metrics.strings[tt.wantMetric] != 1 {
+				t.Errorf("expected %s recorded, got %v", tt.wantMetric, metrics.strings)
+			}
+		})
+	}
+}
@@ -101,7 +101,7 @@ type PrivacyConfig struct {
 	EnforceGDPR bool
 	// EnforceCOPPA blocks requests with COPPA=1 (child-directed)
 	EnforceCOPPA bool
-	// EnforceCCPA blocks/strips data when user opts out
+	// EnforceCCPA strips user IDs and precise geo when user opts out
 	EnforceCCPA bool
 	// GeoEnforcement validates consent strings match user's geographic location
 	// When enabled, verifies EU users have GDPR consent, CA users have CCPA, etc.
@@ -112,6 +112,10 @@ type PrivacyConfig struct {
 	StrictMode bool
 	// AnonymizeIP - P2-2: if true, anonymize IP addresses when GDPR applies
 	AnonymizeIP bool
+	// MalformedTCF is how unparseable TCF strings are handled when GDPR applies
+	MalformedTCF MalformedConsentPolicy
+	// MalformedGPP is how unparseable GPP and US Privacy strings are handled
+	MalformedGPP MalformedConsentPolicy
 }
 
 // DefaultPrivacyConfig returns a sensible default config
@@ -122,6 +126,8 @@ type PrivacyConfig struct {
 //   - PBS_GEO_ENFORCEMENT: "true" or "false" (default: true)
 //   - PBS_PRIVACY_STRICT_MODE: "true" or "false" (default: true)
 //   - PBS_ANONYMIZE_IP: "true" or "false" (default: true)
+//   - PBS_MALFORMED_TCF_POLICY: reject, no_consent or out_of_scope (default: reject)
+//   - PBS_MALFORMED_GPP_POLICY: reject, no_consent or out_of_scope (default: out_of_scope)
 func DefaultPrivacyConfig() PrivacyConfig {
 	return PrivacyConfig{
 		EnforceGDPR:      getEnvBool("PBS_ENFORCE_GDPR", true),
@@ -131,6 +137,8 @@ func DefaultPrivacyConfig() PrivacyConfig {
 		RequiredPurposes: RequiredPurposes,
 		StrictMode:       getEnvBool("PBS_PRIVACY_STRICT_MODE", true),
 		AnonymizeIP:      getEnvBool("PBS_ANONYMIZE_IP", true),
+		MalformedTCF:     getEnvPolicy("PBS_MALFORMED_TCF_POLICY", MalformedConsentReject),
+		MalformedGPP:     getEnvPolicy("PBS_MALFORMED_GPP_POLICY", MalformedConsentOutOfScope),
 	}
 }
 
@@ -143,18 +151,39 @@ func getEnvBool(key string, defaultVal bool) bool {
 	return strings.ToLower(val) == "true" || val == "1"
 }
 
+// PrivacyMetrics defines the metrics interface for privacy enforcement
+type PrivacyMetrics interface {
+	RecordConsentSignal(signalType string, hasConsent bool)
+	// RecordConsentString counts a consent string by publisher, type and
+	// outcome: ConsentOutcomeValid or the malformed consent policy applied
+	RecordConsentString(publisherID, signal, outcome string)
+}
+
 // PrivacyMiddleware enforces privacy regulations before auction execution
 type PrivacyMiddleware struct {
-	config PrivacyConfig
-	next   http.Handler
+	config  PrivacyConfig
+	next    http.Handler
+	metrics PrivacyMetrics
 }
 
 // NewPrivacyMiddleware creates a new privacy enforcement middleware
 func NewPrivacyMiddleware(config PrivacyConfig) func(http.Handler) http.Handler {
+	return NewPrivacyMiddlewareWithMetrics(config, nil)
+}
+
+// NewPrivacyMiddlewareWithMetrics creates a privacy middleware that records consent signal metrics
+func NewPrivacyMiddlewareWithMetrics(config PrivacyConfig, metrics PrivacyMetrics) func(http.Handler) http.Handler {
+	if config.MalformedTCF == "" {
+		config.MalformedTCF = MalformedConsentReject
+	}
+	if config.MalformedGPP == "" {
+		config.MalformedGPP = MalformedConsentOutOfScope
+	}
 	return func(next http.Handler) http.Handler {
 		return &PrivacyMiddleware{
-			config: config,
-			next:   next,
+			config:  config,
+			next:    next,
+			metrics: metrics,
 		}
 	}
 }
@@ -197,7 +226,11 @@ func (m *PrivacyMiddleware) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Check privacy compliance
-	violation := m.checkPrivacyCompliance(&bidRequest)
+	malformed := m.inspectConsentStrings(r.Context(), &bidRequest)
+	violation := m.checkMalformedUSConsent(malformed)
+	if violation == nil {
+		violation = m.checkPrivacyCompliance(&bidRequest)
+	}
 	if violation != nil {
 		logger.Log.Warn().
 			Str("request_id", bidRequest.ID).
@@ -246,13 +279,35 @@ func (m *PrivacyMiddleware) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// GDPR FIX: Set privacy context for downstream handlers
 	gdprApplies := m.isGDPRApplicable(&bidRequest)
 	gdprConsented := true // If we got here, consent was validated (or GDPR doesn't apply)
-	ccpaOptOut := false
 	consentString := ""
 	if bidRequest.User != nil {
 		consentString = bidRequest.User.Consent
 	}
-	if bidRequest.Regs != nil && len(bidRequest.Regs.USPrivacy) >= 3 {
-		ccpaOptOut = bidRequest.Regs.USPrivacy[2] == 'Y'
+	usPrivacy := ResolveUSPrivacy(&bidRequest)
+	ccpaOptOut := usPrivacy.OptedOut()
+	if usPrivacy != nil && m.metrics != nil {
+		m.metrics.RecordConsentSignal(ConsentSignalUSPrivacy, !ccpaOptOut)
+	}
+	if gpp := ResolveGPP(&bidRequest); gpp != nil {
+		if m.metrics != nil {
+			for _, section := range gpp.Sections {
+				m.metrics.RecordConsentSignal(ConsentSignalGPP+"_"+section.Name, !section.OptedOut())
+			}
+		}
+		ccpaOptOut = ccpaOptOut || gpp.OptedOut()
+	}
+
+	// Malformed strings that weren't rejected apply their policy here
+	if malformed.tcf != nil && gdprApplies {
+		switch m.config.MalformedTCF {
+		case MalformedConsentNoConsent:
+			gdprConsented = false
+		case MalformedConsentOutOfScope:
+			gdprApplies = false
+		}
+	}
+	if malformed.us != nil && m.config.EnforceCCPA && m.config.MalformedGPP == MalformedConsentNoConsent {
+		ccpaOptOut = true
 	}
 	ctx := SetPrivacyContext(r.Context(), gdprApplies, gdprConsented, ccpaOptOut, consentString)
 	r = r.WithContext(ctx)
@@ -349,8 +404,9 @@ func (m *PrivacyMiddleware) validateGeoConsent(req *openrtb.BidRequest) *Privacy
 		}
 
 	case RegulationCCPA, RegulationVCDPA, RegulationCPA, RegulationCTDPA, RegulationUCPA:
-		// US state with privacy law should have US Privacy String
-		if req.Regs == nil || req.Regs.USPrivacy == "" {
+		// US state with privacy law should have a US Privacy String or an
+		// applicable GPP US section
+		if req.Regs == nil || (req.Regs.USPrivacy == "" && ResolveUSPrivacy(req) == nil && ResolveGPP(req) == nil) {
 			logger.Log.Warn().
 				Str("request_id", req.ID).
 				Str("country", geoCountry).
@@ -359,7 +415,7 @@ func (m *PrivacyMiddleware) validateGeoConsent(req *openrtb.BidRequest) *Privacy
 				Msg("US privacy state detected but no US Privacy String provided")
 			return &PrivacyViolation{
 				Regulation:  string(detectedReg),
-				Reason:      "User in US privacy state but consent string not provided (regs.us_privacy required)",
+				Reason:      "User in US privacy state but consent string not provided (regs.us_privacy or regs.gpp required)",
 				NoBidReason: openrtb.NoBidAdsNotAllowed,
 			}
 		}
@@ -401,7 +457,8 @@ func (m *PrivacyMiddleware) checkPrivacyCompliance(req *openrtb.BidRequest) *Pri
 		}
 	}
 
-	// Check US Privacy (CCPA) - P0: Enforce opt-out
+	// Check US Privacy (CCPA) - opt-outs are enforced downstream by stripping
+	// identifiers per bidder, so this only validates and logs the signal
 	if req.Regs != nil && req.Regs.USPrivacy != "" {
 		violation := m.checkCCPACompliance(req.ID, req.Regs.USPrivacy)
 		if violation != nil {
@@ -438,9 +495,13 @@ func (m *PrivacyMiddleware) validateGDPRConsent(req *openrtb.BidRequest) *Privac
 		}
 	}
 
-	// Parse and validate the consent string
+	// Parse and validate the consent string; unless the host rejects
+	// malformed strings, ServeHTTP applies the malformed consent policy
 	tcfData, err := m.parseTCFv2String(consentString)
 	if err != nil {
+		if m.config.MalformedTCF != "" && m.config.MalformedTCF != MalformedConsentReject {
+			return nil
+		}
 		return &PrivacyViolation{
 			Regulation:  "GDPR",
 			Reason:      "Invalid TCF v2 consent string: " + err.Error(),
@@ -467,18 +528,131 @@ func (m *PrivacyMiddleware) validateGDPRConsent(req *openrtb.BidRequest) *Privac
 	return nil
 }
 
-// TCFv2Data holds parsed TCF v2 consent data
+// TCFv2Data holds parsed TCF v2 consent data from the core segment
 type TCFv2Data struct {
-	Version           int
-	Created           int64
-	LastUpdated       int64
-	CmpID             int
-	CmpVersion        int
-	ConsentScreen     int
-	ConsentLanguage   string
-	VendorListVersion int
-	PurposeConsents   []bool // Indexed by purpose ID (1-based in spec, 0-based here)
-	VendorConsents    map[int]bool
+	Version                   int
+	Created                   int64
+	LastUpdated               int64
+	CmpID                     int
+	CmpVersion                int
+	ConsentScreen             int
+	ConsentLanguage           string
+	VendorListVersion         int
+	TCFPolicyVersion          int
+	IsServiceSpecific         bool
+	SpecialFeatureOptIns      []bool // Indexed by special feature ID - 1
+	PurposeConsents           []bool // Indexed by purpose ID (1-based in spec, 0-based here)
+	PurposeLITransparency     []bool // Legitimate interest established, indexed like PurposeConsents
+	PurposeOneTreatment       bool
+	PublisherCC               string
+	VendorConsents            map[int]bool
+	VendorLegitimateInterests map[int]bool
+	PublisherRestrictions     []TCFPublisherRestriction
+}
+
+// TCF publisher restriction types
+const (
+	TCFRestrictionNotAllowed     = 0 // Purpose flatly not allowed for the vendors
+	TCFRestrictionRequireConsent = 1 // Vendors may only use consent as the legal basis
+	TCFRestrictionRequireLI      = 2 // Vendors may only use legitimate interest
+)
+
+// TCFPublisherRestriction is a publisher's restriction on how vendors may
+// process data for a purpose
+type TCFPublisherRestriction struct {
+	PurposeID    int
+	Type         int
+	VendorRanges [][2]int // Inclusive vendor ID ranges
+}
+
+// AppliesTo reports whether the restriction covers a vendor
+func (r TCFPublisherRestriction) AppliesTo(gvlID int) bool {
+	for _, rng := range r.VendorRanges {
+		if gvlID >= rng[0] && gvlID <= rng[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// HasPurposeConsent reports whether the user consented to a TCF purpose (1-based)
+func (d *TCFv2Data) HasPurposeConsent(purpose int) bool {
+	return d != nil && purpose >= 1 && purpose <= len(d.PurposeConsents) && d.PurposeConsents[purpose-1]
+}
+
+// HasPurposeLI reports whether legitimate interest was established for a
+// TCF purpose (1-based)
+func (d *TCFv2Data) HasPurposeLI(purpose int) bool {
+	return d != nil && purpose >= 1 && purpose <= len(d.PurposeLITransparency) && d.PurposeLITransparency[purpose-1]
+}
+
+// HasVendorConsent reports whether the user consented to a vendor (GVL ID)
+func (d *TCFv2Data) HasVendorConsent(gvlID int) bool {
+	return d != nil && d.VendorConsents[gvlID]
+}
+
+// HasVendorLI reports whether a vendor's (GVL ID) legitimate interest was
+// established
+func (d *TCFv2Data) HasVendorLI(gvlID int) bool {
+	return d != nil && d.VendorLegitimateInterests[gvlID]
+}
+
+// restriction returns the publisher's restriction type for a vendor and
+// purpose, or -1 when there is none
+func (d *TCFv2Data) restriction(gvlID, purpose int) int {
+	for _, r := range d.PublisherRestrictions {
+		if r.PurposeID == purpose && r.AppliesTo(gvlID) {
+			return r.Type
+		}
+	}
+	return -1
+}
+
+// VendorPurposeAllowed reports whether a vendor may process data for a
+// purpose: with the user's consent to both the purpose and the vendor, or,
+// for purposes TCF 2.2 allows it for, with legitimate interest established
+// for both. Publisher restrictions narrow the legal bases.
+func (d *TCFv2Data) VendorPurposeAllowed(gvlID, purpose int) bool {
+	if d == nil || gvlID <= 0 {
+		return false
+	}
+	restriction := d.restriction(gvlID, purpose)
+	if restriction == TCFRestrictionNotAllowed {
+		return false
+	}
+	consent := d.HasPurposeConsent(purpose) && d.HasVendorConsent(gvlID)
+	// TCF 2.2 doesn't allow legitimate interest for storage (1) or the
+	// personalisation purposes (3 to 6)
+	liAllowed := purpose == PurposeBasicAds || purpose >= PurposeMeasureAdPerformance
+	li := liAllowed && d.HasPurposeLI(purpose) && d.HasVendorLI(gvlID)
+	switch restriction {
+	case TCFRestrictionRequireConsent:
+		return consent
+	case TCFRestrictionRequireLI:
+		return li
+	}
+	return consent || li
+}
+
+// TCFVendorPurposes are the purposes a bidder needs before it may receive a
+// request under GDPR: storage on the device and basic ad selection
+var TCFVendorPurposes = []int{PurposeStorageAccess, PurposeBasicAds}
+
+// VendorAllowed reports whether a vendor (GVL ID) may receive a request:
+// it must be allowed every purpose in TCFVendorPurposes
+func (d *TCFv2Data) VendorAllowed(gvlID int) bool {
+	for _, purpose := range TCFVendorPurposes {
+		if !d.VendorPurposeAllowed(gvlID, purpose) {
+			return false
+		}
+	}
+	return d != nil && gvlID > 0
+}
+
+// ParseTCFv2 parses a TCF consent string outside the auction path, e.g. for
+// cookie sync. Returns nil data for an empty string.
+func ParseTCFv2(consent string) (*TCFv2Data, error) {
+	return parseTCFv2StringStatic(consent)
 }
 
 // parseTCFv2StringStatic is a standalone function for parsing TCF v2 consent strings
@@ -493,10 +667,14 @@ func parseTCFv2StringStatic(consent string) (*TCFv2Data, error) {
 		return nil, errInvalidTCFLength
 	}
 
+	// Only the core segment is parsed; disclosed vendor and publisher TC
+	// segments follow it after "."
+	core, _, _ := strings.Cut(consent, ".")
+
 	// Try base64url decoding first, then standard base64
-	decoded, err := base64.RawURLEncoding.DecodeString(consent)
+	decoded, err := base64.RawURLEncoding.DecodeString(core)
 	if err != nil {
-		decoded, err = base64.StdEncoding.DecodeString(consent)
+		decoded, err = base64.StdEncoding.DecodeString(core)
 		if err != nil {
 			return nil, errInvalidTCFEncoding
 		}
@@ -508,8 +686,8 @@ func parseTCFv2StringStatic(consent string) (*TCFv2Data, error) {
 	}
 
 	data := &TCFv2Data{
-		PurposeConsents: make([]bool, 24), // 24 purposes in TCF v2
-		VendorConsents:  make(map[int]bool),
+		VendorConsents:            make(map[int]bool),
+		VendorLegitimateInterests: make(map[int]bool),
 	}
 
 	// Parse using bit reader
@@ -524,10 +702,11 @@ func parseTCFv2StringStatic(consent string) (*TCFv2Data, error) {
 	if data.Version == 1 {
 		logger.Log.Warn().Msg("TCF v1 consent string - consider upgrading to v2")
 		// For v1, we can't parse purposes the same way, so just accept it
+		data.PurposeConsents = make([]bool, 24)
 		return data, nil
 	}
 
-	// For TCF v2, continue parsing
+	// For TCF v2, continue parsing the core segment
 	// Created (36 bits - deciseconds since Jan 1, 2000)
 	data.Created = int64(reader.readInt(36))
 	// LastUpdated (36 bits)
@@ -539,75 +718,48 @@ func parseTCFv2StringStatic(consent string) (*TCFv2Data, error) {
 	// ConsentScreen (6 bits)
 	data.ConsentScreen = reader.readInt(6)
 	// ConsentLanguage (12 bits - 2 chars)
-	lang1 := byte(reader.readInt(6)) + 'a'
-	lang2 := byte(reader.readInt(6)) + 'a'
-	data.ConsentLanguage = string([]byte{lang1, lang2})
+	data.ConsentLanguage = reader.readLetters(2)
 	// VendorListVersion (12 bits)
 	data.VendorListVersion = reader.readInt(12)
-	// TcfPolicyVersion (6 bits) - skip
-	reader.readInt(6)
-	// IsServiceSpecific (1 bit) - skip
-	reader.readInt(1)
-	// UseNonStandardStacks (1 bit) - skip
-	reader.readInt(1)
-	// SpecialFeatureOptIns (12 bits) - skip
-	reader.readInt(12)
-
-	// Purpose consents (24 bits - one for each purpose)
-	for i := 0; i < 24; i++ {
-		data.PurposeConsents[i] = reader.readBool()
-	}
-
-	// Purpose legitimate interests (24 bits) - skip for now
-	reader.readInt(24)
-
-	// Special purposes (12 bits) - skip
-	reader.readInt(12)
-
-	// Features (24 bits) - skip
-	reader.readInt(24)
-
-	// Special features (12 bits) - skip
-	reader.readInt(12)
-
-	// Purpose legitimate interest (24 bits) - skip
-	reader.readInt(24)
-
-	// NumEntries for publisher purposes (12 bits) - skip
-	reader.readInt(12)
-
-	// Parse MaxVendorId for the consent section (16 bits)
-	maxVendorID := reader.readInt(16)
-
-	// IsRangeEncoding (1 bit)
-	isRangeEncoding := reader.readBool()
-
-	if isRangeEncoding {
-		// Range encoding: parse vendor consent ranges
+	// TcfPolicyVersion (6 bits)
+	data.TCFPolicyVersion = reader.readInt(6)
+	// IsServiceSpecific (1 bit)
+	data.IsServiceSpecific = reader.readBool()
+	// UseNonStandardTexts (1 bit) - skip
+	reader.readBool()
+	// SpecialFeatureOptIns (12 bits)
+	data.SpecialFeatureOptIns = reader.readBools(12)
+	// PurposesConsent (24 bits - one for each purpose)
+	data.PurposeConsents = reader.readBools(24)
+	// PurposesLITransparency (24 bits)
+	data.PurposeLITransparency = reader.readBools(24)
+	// PurposeOneTreatment (1 bit)
+	data.PurposeOneTreatment = reader.readBool()
+	// PublisherCC (12 bits - 2 chars)
+	data.PublisherCC = strings.ToUpper(reader.readLetters(2))
+
+	// Vendor consent and vendor legitimate interest sections
+	data.VendorConsents = reader.readVendorSection()
+	data.VendorLegitimateInterests = reader.readVendorSection()
+
+	// Publisher restrictions
+	numRestrictions := reader.readInt(12)
+	for i := 0; i < numRestrictions && !reader.exhausted(); i++ {
+		restriction := TCFPublisherRestriction{
+			PurposeID: reader.readInt(6),
+			Type:      reader.readInt(2),
+		}
 		numEntries := reader.readInt(12)
-		for i := 0; i < numEntries; i++ {
+		for j := 0; j < numEntries && !reader.exhausted(); j++ {
 			isRange := reader.readBool()
+			start := reader.readInt(16)
+			end := start
 			if isRange {
-				// Range: start and end vendor IDs
-				startVendorID := reader.readInt(16)
-				endVendorID := reader.readInt(16)
-				// Mark all vendors in range as consented
-				for vendorID := startVendorID; vendorID <= endVendorID; vendorID++ {
-					data.VendorConsents[vendorID] = true
-				}
-			} else {
-				// Single vendor ID
-				vendorID := reader.readInt(16)
-				data.VendorConsents[vendorID] = true
-			}
-		}
-	} else {
-		// BitField encoding: one bit per vendor up to MaxVendorId
-		for vendorID := 1; vendorID <= maxVendorID; vendorID++ {
-			if reader.readBool() {
-				data.VendorConsents[vendorID] = true
+				end = reader.readInt(16)
 			}
+			restriction.VendorRanges = append(restriction.VendorRanges, [2]int{start, end})
 		}
+		data.PublisherRestrictions = append(data.PublisherRestrictions, restriction)
 	}
 
 	return data, nil
@@ -725,6 +877,20 @@ func CheckVendorConsentStatic(consentString string, gvlID int) bool {
 	return exists && hasConsent
 }
 
+// PrivacyFilterReasonTCF is the PrivacyFiltered metric reason when a
+// bidder's GVL vendor lacks TCF consent under GDPR
+const PrivacyFilterReasonTCF = "tcf_vendor_consent"
+
+// CheckVendorAllowed reports whether a TCF consent string allows a vendor
+// (GVL ID) to receive a request; see TCFv2Data.VendorAllowed
+func CheckVendorAllowed(consentString string, gvlID int) bool {
+	tcfData, err := parseTCFv2StringStatic(consentString)
+	if err != nil {
+		return false
+	}
+	return tcfData.VendorAllowed(gvlID)
+}
+
 // DetectRegulationFromGeo determines which privacy regulation applies based on geo
 // This is a standalone function for use in the exchange during auction
 // Pass either device.geo or user.geo
@@ -759,14 +925,54 @@ func DetectRegulationFromGeo(geo *openrtb.Geo) PrivacyRegulation {
 	return RegulationNone
 }
 
+// GDPRScope controls when GDPR vendor consent is enforced for a bidder
+type GDPRScope string
+
+const (
+	// GDPRScopeAlways checks vendor consent on every request regardless of geo
+	GDPRScopeAlways GDPRScope = "always"
+	// GDPRScopeEEAOnly checks vendor consent only for EEA/UK traffic with regs.gdpr=1 (default)
+	GDPRScopeEEAOnly GDPRScope = "eea_only"
+	// GDPRScopeNever skips GDPR vendor filtering for the bidder
+	GDPRScopeNever GDPRScope = "never"
+)
+
+// ParseGDPRScope converts a stored scope value, defaulting to GDPRScopeEEAOnly
+func ParseGDPRScope(value string) GDPRScope {
+	switch GDPRScope(value) {
+	case GDPRScopeAlways, GDPRScopeNever:
+		return GDPRScope(value)
+	default:
+		return GDPRScopeEEAOnly
+	}
+}
+
 // ShouldFilterBidderByGeo checks if a bidder should be filtered based on geo and consent
 // Returns true if bidder should be SKIPPED (filtered out)
 // Checks both device.geo and user.geo per OpenRTB spec
 func ShouldFilterBidderByGeo(req *openrtb.BidRequest, gvlID int) bool {
+	return ShouldFilterBidderByGeoScope(req, gvlID, GDPRScopeEEAOnly)
+}
+
+// ShouldFilterBidderByGeoScope is ShouldFilterBidderByGeo with a per-bidder GDPR scope.
+// Where GDPR applies, a bidder without a GVL ID can't be matched against the
+// consent string and is filtered unless its scope is GDPRScopeNever.
+func ShouldFilterBidderByGeoScope(req *openrtb.BidRequest, gvlID int, scope GDPRScope) bool {
 	if req == nil {
 		return false
 	}
 
+	consentString := ""
+	if req.User != nil {
+		consentString = req.User.Consent
+	}
+	gdprFlag := req.Regs != nil && req.Regs.GDPR != nil && *req.Regs.GDPR == 1
+
+	if scope == GDPRScopeAlways {
+		// GDPR treatment on all traffic: vendor must be allowed by user.consent
+		return !gdprVendorAllowed(consentString, gvlID)
+	}
+
 	// Try device.geo first (current location), then user.geo (home location)
 	var geo *openrtb.Geo
 	if req.Device != nil && req.Device.Geo != nil {
@@ -776,35 +982,27 @@ func ShouldFilterBidderByGeo(req *openrtb.BidRequest, gvlID int) bool {
 	}
 
 	if geo == nil {
-		return false // No geo data, can't filter
+		// Without geo, the publisher's regs.gdpr flag says whether GDPR applies
+		return gdprFlag && scope != GDPRScopeNever && !gdprVendorAllowed(consentString, gvlID)
 	}
 
 	regulation := DetectRegulationFromGeo(geo)
 
 	switch regulation {
 	case RegulationGDPR:
-		// For GDPR, check if regs.gdpr is set and if bidder has consent
-		if req.Regs != nil && req.Regs.GDPR != nil && *req.Regs.GDPR == 1 {
-			// GDPR applies - check vendor consent
-			if gvlID > 0 {
-				consentString := ""
-				if req.User != nil {
-					consentString = req.User.Consent
-				}
-				// Filter out (return true) if no consent
-				return !CheckVendorConsentStatic(consentString, gvlID)
-			}
+		if scope == GDPRScopeNever {
+			return false
+		}
+		// For GDPR, check if regs.gdpr is set and if the bidder is allowed
+		if gdprFlag {
+			// Filter out (return true) without consent or legitimate interest
+			return !gdprVendorAllowed(consentString, gvlID)
 		}
 
 	case RegulationCCPA, RegulationVCDPA, RegulationCPA, RegulationCTDPA, RegulationUCPA:
-		// For US privacy states, check if user has opted out
-		if req.Regs != nil && len(req.Regs.USPrivacy) >= 3 {
-			// Position 2 in US Privacy String indicates opt-out
-			// 'Y' means user HAS opted out (filter the bidder)
-			// 'N' means user has NOT opted out (allow the bidder)
-			optOut := req.Regs.USPrivacy[2]
-			return optOut == 'Y' // Filter if opted out
-		}
+		// US privacy opt-outs don't filter by geo: the exchange strips identifiers
+		// per bidder instead (see StripUSPrivacyIdentifiers)
+		return false
 
 	case RegulationLGPD, RegulationPIPEDA, RegulationPDPA:
 		// Other regulations not yet fully implemented
@@ -819,6 +1017,12 @@ func ShouldFilterBidderByGeo(req *openrtb.BidRequest, gvlID int) bool {
 	return false
 }
 
+// gdprVendorAllowed reports whether a bidder may receive a GDPR request: it
+// needs a GVL ID whose vendor is allowed by the consent string
+func gdprVendorAllowed(consentString string, gvlID int) bool {
+	return gvlID > 0 && CheckVendorAllowed(consentString, gvlID)
+}
+
 // TCF parsing errors
 var (
 	errInvalidTCFLength   = &tcfError{"consent string too short"}
@@ -850,6 +1054,60 @@ func (r *bitReader) readBool() bool {
 	return (r.data[bytePos] >> bitOffset & 1) == 1
 }
 
+// exhausted reports whether every bit has been read
+func (r *bitReader) exhausted() bool {
+	return r.bitPos/8 >= len(r.data)
+}
+
+// readBools reads n single-bit flags
+func (r *bitReader) readBools(n int) []bool {
+	flags := make([]bool, n)
+	for i := range flags {
+		flags[i] = r.readBool()
+	}
+	return flags
+}
+
+// readLetters reads n 6-bit letters, where 0 is "a"
+func (r *bitReader) readLetters(n int) string {
+	letters := make([]byte, n)
+	for i := range letters {
+		letters[i] = byte(r.readInt(6)) + 'a'
+	}
+	return string(letters)
+}
+
+// readVendorSection reads a TCF vendor bitfield or range section: a max
+// vendor ID, an encoding flag, then one bit per vendor or a list of IDs and
+// ranges. Ranges are capped at the max vendor ID.
+func (r *bitReader) readVendorSection() map[int]bool {
+	vendors := make(map[int]bool)
+	maxVendorID := r.readInt(16)
+	if !r.readBool() {
+		for vendorID := 1; vendorID <= maxVendorID && !r.exhausted(); vendorID++ {
+			if r.readBool() {
+				vendors[vendorID] = true
+			}
+		}
+		return vendors
+	}
+	numEntries := r.readInt(12)
+	for i := 0; i < numEntries && !r.exhausted(); i++ {
+		isRange := r.readBool()
+		start := r.readInt(16)
+		end := start
+		if isRange {
+			end = r.readInt(16)
+		}
+		for vendorID := start; vendorID <= end && vendorID <= maxVendorID; vendorID++ {
+			if vendorID > 0 {
+				vendors[vendorID] = true
+			}
+		}
+	}
+	return vendors
+}
+
 func (r *bitReader) readInt(bits int) int {
 	result := 0
 	for i := 0; i < bits; i++ {
@@ -948,13 +1206,12 @@ func (m *PrivacyMiddleware) checkCCPACompliance(requestID, usPrivacy string) *Pr
 			Str("us_privacy", usPrivacy).
 			Msg("CCPA opt-out signal received")
 
-		// P0: Enforce CCPA opt-out if configured
+		// Opted-out traffic is still auctioned: the exchange strips user IDs and
+		// precise geo for each bidder and filters bidders that can't honor the signal
 		if m.config.EnforceCCPA {
-			return &PrivacyViolation{
-				Regulation:  "CCPA",
-				Reason:      "User has opted out of data sale under CCPA",
-				NoBidReason: openrtb.NoBidAdsNotAllowed,
-			}
+			logger.Log.Debug().
+				Str("request_id", requestID).
+				Msg("CCPA opt-out: identifiers will be stripped before bidder calls")
 		}
 	}
 
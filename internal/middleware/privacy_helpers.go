@@ -3,8 +3,9 @@ package middleware
 
 import (
 	"context"
-	"net"
 	"strings"
+
+	"github.com/thenexusengine/tne_springwire/internal/scrub"
 )
 
 // PrivacyContextKey is the key used to store privacy information in context
@@ -29,48 +30,21 @@ func AnonymizeIPForLogging(ipStr string) string {
 	if ipStr == "" {
 		return "[no-ip]"
 	}
-	
-	ip := net.ParseIP(ipStr)
-	if ip == nil {
-		return "[invalid-ip]"
-	}
-	
-	// Check if it's IPv4
-	if ipv4 := ip.To4(); ipv4 != nil {
-		// Mask last octet
-		ipv4[3] = 0
-		return ipv4.String()
-	}
-	
-	// IPv6 - mask last 80 bits (keep first 48 bits / 6 bytes)
-	ipv6 := ip.To16()
-	if ipv6 == nil {
-		return "[invalid-ip]"
-	}
-	for i := 6; i < 16; i++ {
-		ipv6[i] = 0
+	if masked := scrub.IP(ipStr); masked != "" {
+		return masked
 	}
-	return ipv6.String()
+	return "[invalid-ip]"
 }
 
 // AnonymizeUserAgentForLogging returns a truncated/anonymized UA for logging
 // Only keeps the first 50 characters and browser family identification
 // This reduces PII while maintaining debugging utility
 func AnonymizeUserAgentForLogging(ua string) string {
+	ua = strings.TrimSpace(ua)
 	if ua == "" {
 		return "[no-ua]"
 	}
-	
-	// Extract just the browser family (first significant part)
-	// This provides debugging value without full fingerprinting
-	ua = strings.TrimSpace(ua)
-	
-	// Truncate to reasonable length
-	if len(ua) > 50 {
-		ua = ua[:50] + "..."
-	}
-	
-	return ua
+	return scrub.UserAgent(ua)
 }
 
 // GDPRConsentValidated checks if GDPR consent was validated in the middleware
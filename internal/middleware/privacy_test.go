@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -284,14 +285,16 @@ func TestIsValidTCFv2String(t *testing.T) {
 }
 
 func TestPrivacyMiddleware_CCPAOptOut(t *testing.T) {
-	// When CCPA enforcement is enabled and user opts out, request should be blocked
+	// Opted-out requests are auctioned; identifiers are stripped per bidder by the exchange
 	config := DefaultPrivacyConfig()
 	config.EnforceCCPA = true
 	mw := NewPrivacyMiddleware(config)
 
 	called := false
+	optOutInContext := false
 	handler := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		called = true
+		optOutInContext, _ = r.Context().Value(ContextKeyCCPAOptOut).(bool)
 		w.WriteHeader(http.StatusOK)
 	}))
 
@@ -309,17 +312,14 @@ func TestPrivacyMiddleware_CCPAOptOut(t *testing.T) {
 
 	handler.ServeHTTP(rr, httpReq)
 
-	if called {
-		t.Error("Handler should NOT have been called when user opts out under CCPA")
+	if !called {
+		t.Error("Handler should have been called for CCPA opt-out")
 	}
-	if rr.Code != http.StatusBadRequest {
-		t.Errorf("Expected status 400, got %d", rr.Code)
+	if rr.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", rr.Code)
 	}
-
-	var resp map[string]interface{}
-	json.Unmarshal(rr.Body.Bytes(), &resp)
-	if resp["regulation"] != "CCPA" {
-		t.Errorf("Expected regulation=CCPA, got %v", resp["regulation"])
+	if !optOutInContext {
+		t.Error("Expected CCPA opt-out to be set in request context")
 	}
 }
 
@@ -994,8 +994,8 @@ func TestShouldFilterBidderByGeo_GDPR(t *testing.T) {
 				User: &openrtb.User{Consent: validConsent},
 			},
 			0,
-			false,
-			"zero GVL ID should not filter even in GDPR",
+			true,
+			"zero GVL ID can't be checked against consent so should filter in GDPR",
 		},
 	}
 
@@ -1010,6 +1010,255 @@ func TestShouldFilterBidderByGeo_GDPR(t *testing.T) {
 	}
 }
 
+func TestShouldFilterBidderByGeoScope(t *testing.T) {
+	gdpr := 1
+	euReq := &openrtb.BidRequest{
+		ID:     "test",
+		Device: &openrtb.Device{Geo: &openrtb.Geo{Country: "DEU"}},
+		Regs:   &openrtb.Regs{GDPR: &gdpr},
+	}
+	usReq := &openrtb.BidRequest{
+		ID:     "test",
+		Device: &openrtb.Device{Geo: &openrtb.Geo{Country: "USA", Region: "NY"}},
+	}
+
+	tests := []struct {
+		name         string
+		req          *openrtb.BidRequest
+		scope        GDPRScope
+		shouldFilter bool
+	}{
+		{"eea_only filters EU without consent", euReq, GDPRScopeEEAOnly, true},
+		{"eea_only ignores non-EU traffic", usReq, GDPRScopeEEAOnly, false},
+		{"always filters non-EU traffic without consent", usReq, GDPRScopeAlways, true},
+		{"always filters EU traffic without consent", euReq, GDPRScopeAlways, true},
+		{"never skips EU filtering", euReq, GDPRScopeNever, false},
+		{"eea_only filters regs.gdpr without geo", &openrtb.BidRequest{ID: "test", Regs: &openrtb.Regs{GDPR: &gdpr}}, GDPRScopeEEAOnly, true},
+		{"eea_only ignores no geo without regs.gdpr", &openrtb.BidRequest{ID: "test"}, GDPRScopeEEAOnly, false},
+		{"never skips regs.gdpr without geo", &openrtb.BidRequest{ID: "test", Regs: &openrtb.Regs{GDPR: &gdpr}}, GDPRScopeNever, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ShouldFilterBidderByGeoScope(tt.req, 123, tt.scope); got != tt.shouldFilter {
+				t.Errorf("ShouldFilterBidderByGeoScope() = %v, want %v", got, tt.shouldFilter)
+			}
+		})
+	}
+}
+
+func TestShouldFilterBidderByGeoScope_NoGVLID(t *testing.T) {
+	gdpr := 1
+	consent := tcfTestConsent{purposes: []int{1, 2}, vendors: []int{123}}.encode()
+	euReq := &openrtb.BidRequest{
+		ID:     "test",
+		Device: &openrtb.Device{Geo: &openrtb.Geo{Country: "DEU"}},
+		Regs:   &openrtb.Regs{GDPR: &gdpr},
+		User:   &openrtb.User{Consent: consent},
+	}
+	usReq := &openrtb.BidRequest{
+		ID:     "test",
+		Device: &openrtb.Device{Geo: &openrtb.Geo{Country: "USA", Region: "NY"}},
+	}
+	noGeoReq := &openrtb.BidRequest{ID: "test", Regs: &openrtb.Regs{GDPR: &gdpr}, User: &openrtb.User{Consent: consent}}
+
+	tests := []struct {
+		name         string
+		req          *openrtb.BidRequest
+		scope        GDPRScope
+		shouldFilter bool
+	}{
+		{"eea_only filters EU traffic", euReq, GDPRScopeEEAOnly, true},
+		{"eea_only filters regs.gdpr without geo", noGeoReq, GDPRScopeEEAOnly, true},
+		{"eea_only ignores non-EU traffic", usReq, GDPRScopeEEAOnly, false},
+		{"always filters non-EU traffic", usReq, GDPRScopeAlways, true},
+		{"never skips EU filtering", euReq, GDPRScopeNever, false},
+		{"never skips regs.gdpr without geo", noGeoReq, GDPRScopeNever, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ShouldFilterBidderByGeoScope(tt.req, 0, tt.scope); got != tt.shouldFilter {
+				t.Errorf("ShouldFilterBidderByGeoScope() = %v, want %v", got, tt.shouldFilter)
+			}
+		})
+	}
+}
+
+// tcfTestConsent describes a TCF v2 consent string for the tests below
+type tcfTestConsent struct {
+	purposes     []int
+	purposeLI    []int
+	vendors      []int    // bitfield encoded
+	vendorRanges [][2]int // range encoded, used when vendors is empty
+	vendorLI     []int
+	restrictions []TCFPublisherRestriction
+}
+
+// encode writes the core segment per the TCF v2.2 layout, then a
+// disclosed vendors segment the parser should ignore
+func (c tcfTestConsent) encode() string {
+	var bits []bool
+	write := func(v, n int) {
+		for i := n - 1; i >= 0; i-- {
+			bits = append(bits, v&(1<<i) != 0)
+		}
+	}
+	flags := func(ids []int, n int) {
+		f := make([]bool, n)
+		for _, id := range ids {
+			f[id-1] = true
+		}
+		bits = append(bits, f...)
+	}
+	bitfield := func(ids []int) {
+		maxID := 0
+		for _, id := range ids {
+			if id > maxID {
+				maxID = id
+			}
+		}
+		write(maxID, 16)
+		write(0, 1)
+		flags(ids, maxID)
+	}
+
+	write(2, 6)            // version
+	write(0, 36+36)        // created, last updated
+	write(7, 12)           // cmp id
+	write(1, 12+6)         // cmp version, consent screen
+	write(4, 6)            // "e"
+	write(13, 6)           // "n"
+	write(300, 12)         // vendor list version
+	write(4, 6)            // policy version
+	write(0, 1+1+12)       // flags, special features
+	flags(c.purposes, 24)  // purpose consents
+	flags(c.purposeLI, 24) // purpose LI transparency
+	write(0, 1)            // purpose one treatment
+	write(3, 6)            // "D"
+	write(4, 6)            // "E"
+	if len(c.vendors) > 0 || len(c.vendorRanges) == 0 {
+		bitfield(c.vendors)
+	} else {
+		write(c.vendorRanges[len(c.vendorRanges)-1][1], 16)
+		write(1, 1)
+		write(len(c.vendorRanges), 12)
+		for _, r := range c.vendorRanges {
+			write(1, 1)
+			write(r[0], 16)
+			write(r[1], 16)
+		}
+	}
+	bitfield(c.vendorLI)
+	write(len(c.restrictions), 12)
+	for _, r := range c.restrictions {
+		write(r.PurposeID, 6)
+		write(r.Type, 2)
+		write(len(r.VendorRanges), 12)
+		for _, rng := range r.VendorRanges {
+			write(1, 1)
+			write(rng[0], 16)
+			write(rng[1], 16)
+		}
+	}
+
+	data := make([]byte, (len(bits)+7)/8)
+	for i, b := range bits {
+		if b {
+			data[i/8] |= 1 << (7 - i%8)
+		}
+	}
+	return base64.RawURLEncoding.EncodeToString(data) + ".IFoEUQQgAIQwgIwQABAEAAAAOIAACAIAAAAQAIAgEAACEAAAAAgAQBAAAAAAAGBAAgA"
+}
+
+func TestParseTCFv2_CoreSegment(t *testing.T) {
+	consent := tcfTestConsent{
+		purposes:     []int{1, 2, 4},
+		purposeLI:    []int{2, 7},
+		vendors:      []int{52, 755},
+		vendorLI:     []int{52, 91},
+		restrictions: []TCFPublisherRestriction{{PurposeID: 2, Type: TCFRestrictionRequireConsent, VendorRanges: [][2]int{{90, 100}}}},
+	}.encode()
+
+	data, err := ParseTCFv2(consent)
+	if err != nil {
+		t.Fatalf("ParseTCFv2: %v", err)
+	}
+	if data.CmpID != 7 || data.ConsentLanguage != "en" || data.VendorListVersion != 300 || data.TCFPolicyVersion != 4 || data.PublisherCC != "DE" {
+		t.Errorf("unexpected header fields %+v", data)
+	}
+	if !data.HasPurposeConsent(4) || data.HasPurposeConsent(3) || !data.HasPurposeLI(7) || data.HasPurposeLI(1) {
+		t.Error("unexpected purpose signals")
+	}
+	if !data.HasVendorConsent(755) || data.HasVendorConsent(91) || !data.HasVendorLI(91) || data.HasVendorLI(755) {
+		t.Errorf("unexpected vendor signals: consents %v, LI %v", data.VendorConsents, data.VendorLegitimateInterests)
+	}
+	if len(data.PublisherRestrictions) != 1 || !data.PublisherRestrictions[0].AppliesTo(91) || data.PublisherRestrictions[0].AppliesTo(52) {
+		t.Errorf("unexpected publisher restrictions %+v", data.PublisherRestrictions)
+	}
+
+	// Range encoded vendors are capped at the max vendor ID
+	data, err = ParseTCFv2(tcfTestConsent{vendorRanges: [][2]int{{10, 12}, {20, 21}}}.encode())
+	if err != nil {
+		t.Fatalf("ParseTCFv2: %v", err)
+	}
+	if len(data.VendorConsents) != 5 || !data.HasVendorConsent(11) || data.HasVendorConsent(13) {
+		t.Errorf("unexpected range vendors %v", data.VendorConsents)
+	}
+}
+
+func TestTCFv2Data_VendorAllowed(t *testing.T) {
+	tests := []struct {
+		name    string
+		consent tcfTestConsent
+		gvlID   int
+		allowed bool
+	}{
+		{"consent to purposes and vendor", tcfTestConsent{purposes: []int{1, 2}, vendors: []int{52}}, 52, true},
+		{"no vendor consent", tcfTestConsent{purposes: []int{1, 2}, vendors: []int{53}}, 52, false},
+		{"no storage consent", tcfTestConsent{purposes: []int{2}, vendors: []int{52}}, 52, false},
+		{"basic ads on legitimate interest", tcfTestConsent{purposes: []int{1}, purposeLI: []int{2}, vendors: []int{52}, vendorLI: []int{52}}, 52, true},
+		{"basic ads LI without vendor LI", tcfTestConsent{purposes: []int{1}, purposeLI: []int{2}, vendors: []int{52}}, 52, false},
+		{"storage can't rest on LI", tcfTestConsent{purposes: []int{2}, purposeLI: []int{1}, vendors: []int{52}, vendorLI: []int{52}}, 52, false},
+		{"publisher disallows basic ads", tcfTestConsent{
+			purposes: []int{1, 2}, vendors: []int{52},
+			restrictions: []TCFPublisherRestriction{{PurposeID: 2, Type: TCFRestrictionNotAllowed, VendorRanges: [][2]int{{50, 60}}}},
+		}, 52, false},
+		{"publisher requires consent over LI", tcfTestConsent{
+			purposes: []int{1}, purposeLI: []int{2}, vendors: []int{52}, vendorLI: []int{52},
+			restrictions: []TCFPublisherRestriction{{PurposeID: 2, Type: TCFRestrictionRequireConsent, VendorRanges: [][2]int{{52, 52}}}},
+		}, 52, false},
+		{"restriction for other vendors", tcfTestConsent{
+			purposes: []int{1, 2}, vendors: []int{52},
+			restrictions: []TCFPublisherRestriction{{PurposeID: 2, Type: TCFRestrictionNotAllowed, VendorRanges: [][2]int{{53, 60}}}},
+		}, 52, true},
+		{"no GVL ID", tcfTestConsent{purposes: []int{1, 2}, vendors: []int{52}}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			consent := tt.consent.encode()
+			if got := CheckVendorAllowed(consent, tt.gvlID); got != tt.allowed {
+				t.Errorf("CheckVendorAllowed() = %v, want %v", got, tt.allowed)
+			}
+		})
+	}
+	if CheckVendorAllowed("", 52) || CheckVendorAllowed("not-a-consent-string", 52) {
+		t.Error("expected no vendor allowed without a valid consent string")
+	}
+}
+
+func TestParseGDPRScope(t *testing.T) {
+	if ParseGDPRScope("always") != GDPRScopeAlways {
+		t.Error("expected always scope")
+	}
+	if ParseGDPRScope("never") != GDPRScopeNever {
+		t.Error("expected never scope")
+	}
+	if ParseGDPRScope("") != GDPRScopeEEAOnly || ParseGDPRScope("bogus") != GDPRScopeEEAOnly {
+		t.Error("expected unknown scopes to default to eea_only")
+	}
+}
+
 func TestShouldFilterBidderByGeo_CCPA(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -1030,8 +1279,8 @@ func TestShouldFilterBidderByGeo_CCPA(t *testing.T) {
 				},
 			},
 			123,
-			true,
-			"CA with opt-out should filter",
+			false,
+			"CA with opt-out should not filter (identifiers are stripped instead)",
 		},
 		{
 			"California without opt-out",
@@ -1060,8 +1309,8 @@ func TestShouldFilterBidderByGeo_CCPA(t *testing.T) {
 				},
 			},
 			123,
-			true,
-			"VA with opt-out should filter",
+			false,
+			"VA with opt-out should not filter (identifiers are stripped instead)",
 		},
 		{
 			"Colorado with opt-out",
@@ -1075,8 +1324,8 @@ func TestShouldFilterBidderByGeo_CCPA(t *testing.T) {
 				},
 			},
 			123,
-			true,
-			"CO with opt-out should filter",
+			false,
+			"CO with opt-out should not filter (identifiers are stripped instead)",
 		},
 		{
 			"Privacy state but no USPrivacy string",
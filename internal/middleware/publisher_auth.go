@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"os"
@@ -14,6 +15,10 @@ import (
 	"time"
 
 	"github.com/rs/zerolog/log"
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+	"github.com/thenexusengine/tne_springwire/pkg/deadline"
+	"github.com/thenexusengine/tne_springwire/pkg/domainmatch"
+	"github.com/thenexusengine/tne_springwire/pkg/lru"
 )
 
 // PublisherAuthConfig holds publisher authentication configuration
@@ -82,9 +87,20 @@ type minimalBidRequest struct {
 			ID string `json:"id"`
 		} `json:"publisher"`
 	} `json:"app"`
+	Dooh   *struct{} `json:"dooh"`
+	Device *struct {
+		DeviceType int `json:"devicetype"`
+	} `json:"device"`
 }
 
-// PublisherStore interface for database operations
+// OpenRTB device types scored as CTV traffic
+const (
+	deviceTypeConnectedTV = 3
+	deviceTypeSetTopBox   = 7
+)
+
+// PublisherStore interface for database operations; a missing publisher is
+// an error wrapping storage.ErrNotFound
 type PublisherStore interface {
 	GetByPublisherID(ctx context.Context, publisherID string) (publisher interface{}, err error)
 }
@@ -93,8 +109,8 @@ type PublisherStore interface {
 //
 // LOCK ORDERING: To prevent deadlocks, locks MUST be acquired in this order:
 //   1. mu (config lock) - protects config, redisClient, publisherStore
-//   2. publisherCacheMu - protects publisherCache
-//   3. rateLimitsMu - protects rateLimits
+//   2. publisherCache's internal lock (leaf, taken by its methods)
+//   3. rateLimits' internal lock (leaf, taken by its methods)
 //
 // RULES:
 //   - Never acquire locks in reverse order
@@ -107,22 +123,19 @@ type PublisherStore interface {
 //   config := p.config
 //   mu.RUnlock()
 //   // Now safe to take other locks without holding mu
-//   publisherCacheMu.Lock()
-//   // ... work ...
-//   publisherCacheMu.Unlock()
+//   p.publisherCache.Get(publisherID)
 type PublisherAuth struct {
 	config         *PublisherAuthConfig
 	redisClient    RedisClient
 	publisherStore PublisherStore
 	mu             sync.RWMutex // Level 1: Config/client access
 
-	// Rate limiting per publisher
-	rateLimits   map[string]*rateLimitEntry
-	rateLimitsMu sync.RWMutex // Level 3: Rate limit state
+	// Rate limiting per publisher, bounded so unique publisher IDs can't
+	// grow it without limit (Level 3)
+	rateLimits *lru.Cache[string, rateLimitEntry]
 
-	// In-memory fallback cache (for Redis/PostgreSQL failures)
-	publisherCache   map[string]*publisherCacheEntry
-	publisherCacheMu sync.RWMutex // Level 2: Publisher cache
+	// In-memory fallback cache (for Redis/PostgreSQL failures) (Level 2)
+	publisherCache *lru.Cache[string, publisherCacheEntry]
 
 	// IVT detection
 	ivtDetector *IVTDetector
@@ -139,6 +152,40 @@ type publisherCacheEntry struct {
 	expiresAt      time.Time
 }
 
+// publisherCacheTTL is how long a PostgreSQL result is kept in the memory cache
+const publisherCacheTTL = 30 * time.Second
+
+// Bounds for the per-publisher maps. Publisher IDs come from requests, so
+// these hold unregistered IDs too until they are evicted.
+const (
+	publisherCacheMaxEntries = 10000
+	publisherCacheMaxBytes   = 8 << 20
+	rateLimitMaxEntries      = 10000
+	rateLimitMaxBytes        = 4 << 20
+)
+
+// newPublisherCache creates the bounded publisher cache
+func newPublisherCache() *lru.Cache[string, publisherCacheEntry] {
+	return lru.New(lru.Config{
+		Name:       "publisher_auth_cache",
+		MaxEntries: publisherCacheMaxEntries,
+		MaxBytes:   publisherCacheMaxBytes,
+	}, func(pubID string, e publisherCacheEntry) int64 {
+		return int64(len(pubID)+len(e.allowedDomains)) + 64
+	})
+}
+
+// newRateLimits creates the bounded per-publisher token buckets
+func newRateLimits() *lru.Cache[string, rateLimitEntry] {
+	return lru.New(lru.Config{
+		Name:       "publisher_rate_limits",
+		MaxEntries: rateLimitMaxEntries,
+		MaxBytes:   rateLimitMaxBytes,
+	}, func(pubID string, _ rateLimitEntry) int64 {
+		return int64(len(pubID)) + 64
+	})
+}
+
 // Redis key for registered publishers
 const RedisPublishersHash = "tne_catalyst:publishers" // hash: publisher_id -> allowed_domains
 
@@ -154,9 +201,10 @@ func NewPublisherAuth(config *PublisherAuthConfig) *PublisherAuth {
 		config = DefaultPublisherAuthConfig()
 	}
 	return &PublisherAuth{
-		config:      config,
-		rateLimits:  make(map[string]*rateLimitEntry),
-		ivtDetector: NewIVTDetector(DefaultIVTConfig()),
+		config:         config,
+		rateLimits:     newRateLimits(),
+		publisherCache: newPublisherCache(),
+		ivtDetector:    NewIVTDetector(DefaultIVTConfig()),
 	}
 }
 
@@ -230,7 +278,7 @@ func (p *PublisherAuth) Middleware(next http.Handler) http.Handler {
 
 		// IVT detection (Invalid Traffic)
 		if p.ivtDetector != nil {
-			ivtResult := p.ivtDetector.Validate(r.Context(), r, publisherID, domain)
+			ivtResult := p.ivtDetector.ValidateChannel(r.Context(), r, publisherID, domain, ivtChannel(&minReq))
 
 			// Log IVT detection
 			if !ivtResult.IsValid {
@@ -240,7 +288,9 @@ func (p *PublisherAuth) Middleware(next http.Handler) http.Handler {
 					Str("domain", domain).
 					Str("ip", AnonymizeIPForLogging(ivtResult.IPAddress)).
 					Str("ua", AnonymizeUserAgentForLogging(ivtResult.UserAgent)).
+					Str("channel", ivtResult.Channel).
 					Int("ivt_score", ivtResult.Score).
+					Int("threshold", ivtResult.Threshold).
 					Int("signal_count", len(ivtResult.Signals)).
 					Bool("blocked", ivtResult.ShouldBlock).
 					Msg("IVT detected")
@@ -279,7 +329,7 @@ func (p *PublisherAuth) Middleware(next http.Handler) http.Handler {
 
 		// Retrieve and store full publisher object in context for downstream use
 		if publisherID != "" && p.publisherStore != nil {
-			pub, err := p.publisherStore.GetByPublisherID(ctx, publisherID)
+			pub, err := p.lookupPublisher(ctx, p.publisherStore, publisherID)
 			if err == nil && pub != nil {
 				// Store publisher in context for exchange to access bid_multiplier
 				ctx = context.WithValue(ctx, publisherContextKey, pub)
@@ -294,6 +344,15 @@ func (p *PublisherAuth) Middleware(next http.Handler) http.Handler {
 	})
 }
 
+// lookupPublisher fetches a publisher from PostgreSQL capped at the postgres
+// dependency deadline so a slow query can't consume the auction's tmax
+func (p *PublisherAuth) lookupPublisher(ctx context.Context, store PublisherStore, publisherID string) (interface{}, error) {
+	dbCtx, cancel := deadline.WithCap(ctx, deadline.DependencyPostgres)
+	defer cancel()
+	pub, err := store.GetByPublisherID(dbCtx, publisherID)
+	return pub, deadline.Observe(dbCtx, deadline.DependencyPostgres, err)
+}
+
 // extractPublisherInfo extracts publisher ID and domain from request
 func (p *PublisherAuth) extractPublisherInfo(req *minimalBidRequest) (publisherID, domain string) {
 	if req.Site != nil {
@@ -310,6 +369,20 @@ func (p *PublisherAuth) extractPublisherInfo(req *minimalBidRequest) (publisherI
 	return
 }
 
+// ivtChannel returns the traffic channel a request is scored as for IVT
+func ivtChannel(req *minimalBidRequest) string {
+	switch {
+	case req.Dooh != nil:
+		return IVTChannelDOOH
+	case req.Device != nil && (req.Device.DeviceType == deviceTypeConnectedTV || req.Device.DeviceType == deviceTypeSetTopBox):
+		return IVTChannelCTV
+	case req.App != nil:
+		return IVTChannelApp
+	default:
+		return IVTChannelSite
+	}
+}
+
 // validatePublisher validates the publisher ID and domain
 // Fallback chain: Redis → PostgreSQL → Memory cache → RegisteredPubs
 //
@@ -345,7 +418,9 @@ func (p *PublisherAuth) validatePublisher(ctx context.Context, publisherID, doma
 
 	// 1. Try Redis FIRST (fastest if configured)
 	if useRedis && redisClient != nil {
-		allowedDomains, err := redisClient.HGet(ctx, RedisPublishersHash, publisherID)
+		redisCtx, cancel := deadline.WithCap(ctx, deadline.DependencyRedis)
+		allowedDomains, err := redisClient.HGet(redisCtx, RedisPublishersHash, publisherID)
+		cancel()
 		if err == nil && allowedDomains != "" {
 			// Publisher found in Redis - validate domain and return
 			if validateDomain && allowedDomains != "" && allowedDomains != "*" {
@@ -364,7 +439,7 @@ func (p *PublisherAuth) validatePublisher(ctx context.Context, publisherID, doma
 
 	// 2. Fall back to PostgreSQL database
 	if publisherStore != nil {
-		pub, err := publisherStore.GetByPublisherID(ctx, publisherID)
+		pub, err := p.lookupPublisher(ctx, publisherStore, publisherID)
 		if err == nil && pub != nil {
 			// Publisher found in PostgreSQL - extract allowed domains
 			type domainProvider interface {
@@ -384,7 +459,7 @@ func (p *PublisherAuth) validatePublisher(ctx context.Context, publisherID, doma
 			}
 
 			// Cache result in memory for 30s
-			p.cachePublisher(publisherID, allowedDomains, 30*time.Second)
+			p.cachePublisher(publisherID, allowedDomains, publisherCacheTTL)
 
 			// Validate domain if required
 			if validateDomain && allowedDomains != "" && allowedDomains != "*" {
@@ -394,8 +469,8 @@ func (p *PublisherAuth) validatePublisher(ctx context.Context, publisherID, doma
 			}
 			return nil
 		}
-		// PostgreSQL error or not found - log and fall through to memory cache
-		if err != nil {
+		// PostgreSQL error or not found - log failures and fall through to memory cache
+		if err != nil && !errors.Is(err, storage.ErrNotFound) {
 			p.logDatabaseFallback(err, publisherID)
 		}
 		// Continue to memory cache fallback
@@ -441,35 +516,17 @@ func (p *PublisherAuth) validatePublisher(ctx context.Context, publisherID, doma
 	}
 }
 
-// domainMatches checks if domain matches allowed domains (comma-separated)
+// domainMatches checks if domain matches allowed domains ("|" or "," separated).
+// Matching rules (wildcards, regex, IDN, ports) live in pkg/domainmatch.
 func (p *PublisherAuth) domainMatches(domain, allowedDomains string) bool {
-	if domain == "" {
-		return false
-	}
-
-	for _, allowed := range strings.Split(allowedDomains, "|") {
-		allowed = strings.TrimSpace(allowed)
-		if allowed == "" {
-			continue
-		}
-		// Support wildcard subdomains
-		if strings.HasPrefix(allowed, "*.") {
-			suffix := allowed[1:] // ".example.com"
-			if strings.HasSuffix(domain, suffix) || domain == allowed[2:] {
-				return true
-			}
-		} else if domain == allowed {
-			return true
-		}
-	}
-	return false
+	return domainmatch.MatchList(domain, allowedDomains)
 }
 
 // checkRateLimit implements token bucket rate limiting per publisher
 //
-// LOCK ORDERING: mu → rateLimitsMu
-// This method acquires mu.RLock() first, then rateLimitsMu.Lock()
-// Releases mu before acquiring rateLimitsMu to minimize lock holding time
+// LOCK ORDERING: mu → rateLimits
+// This method acquires mu.RLock() first, then the rateLimits lock
+// Releases mu before updating rateLimits to minimize lock holding time
 func (p *PublisherAuth) checkRateLimit(publisherID string) bool {
 	// Lock ordering: Level 1 (mu) first
 	p.mu.RLock()
@@ -481,54 +538,31 @@ func (p *PublisherAuth) checkRateLimit(publisherID string) bool {
 		return true // Unlimited
 	}
 
-	// Lock ordering: Level 3 (rateLimitsMu)
-	p.rateLimitsMu.Lock()
-	defer p.rateLimitsMu.Unlock()
-
-	entry, exists := p.rateLimits[publisherID]
+	// Lock ordering: Level 3 (rateLimits)
+	allowed := false
 	now := time.Now()
-
-	if !exists {
-		p.rateLimits[publisherID] = &rateLimitEntry{
-			tokens:    float64(rateLimit) - 1,
-			lastCheck: now,
+	p.rateLimits.Update(publisherID, func(entry rateLimitEntry, exists bool) rateLimitEntry {
+		if !exists {
+			allowed = true
+			return rateLimitEntry{tokens: float64(rateLimit) - 1, lastCheck: now}
 		}
-		return true
-	}
 
-	// Refill tokens based on time elapsed
-	elapsed := now.Sub(entry.lastCheck).Seconds()
-	entry.tokens += elapsed * float64(rateLimit)
-	if entry.tokens > float64(rateLimit) {
-		entry.tokens = float64(rateLimit)
-	}
-	entry.lastCheck = now
-
-	// Try to consume a token
-	if entry.tokens >= 1 {
-		entry.tokens--
-		// Opportunistic cleanup to prevent unbounded memory growth
-		// Remove stale entries if map is getting too large (>1000 entries)
-		if len(p.rateLimits) > 1000 {
-			p.cleanupStaleRateLimits(now)
+		// Refill tokens based on time elapsed
+		elapsed := now.Sub(entry.lastCheck).Seconds()
+		entry.tokens += elapsed * float64(rateLimit)
+		if entry.tokens > float64(rateLimit) {
+			entry.tokens = float64(rateLimit)
 		}
-		return true
-	}
-
-	return false
-}
+		entry.lastCheck = now
 
-// cleanupStaleRateLimits removes rate limit entries that haven't been accessed recently
-// This prevents unbounded memory growth from unique publisher IDs (DoS vector)
-// CALLER MUST HOLD rateLimitsMu.Lock()
-func (p *PublisherAuth) cleanupStaleRateLimits(now time.Time) {
-	// Remove entries not accessed in the last hour
-	staleThreshold := now.Add(-1 * time.Hour)
-	for pubID, entry := range p.rateLimits {
-		if entry.lastCheck.Before(staleThreshold) {
-			delete(p.rateLimits, pubID)
+		// Try to consume a token
+		if entry.tokens >= 1 {
+			entry.tokens--
+			allowed = true
 		}
-	}
+		return entry
+	})
+	return allowed
 }
 
 // Rate-limited logging state (shared across all PublisherAuth instances)
@@ -571,36 +605,25 @@ func (p *PublisherAuth) logDatabaseFallback(err error, pubID string) {
 
 // cachePublisher caches a publisher in memory with TTL
 //
-// LOCK ORDERING: publisherCacheMu only (Level 2)
+// LOCK ORDERING: publisherCache only (Level 2)
 // Safe to call from any context - does not acquire other locks
 func (p *PublisherAuth) cachePublisher(publisherID, allowedDomains string, ttl time.Duration) {
-	p.publisherCacheMu.Lock()
-	defer p.publisherCacheMu.Unlock()
-
-	if p.publisherCache == nil {
-		p.publisherCache = make(map[string]*publisherCacheEntry)
+	// Drop expired entries before the LRU has to evict live ones
+	if p.publisherCache.Len() >= publisherCacheMaxEntries {
+		p.cleanupExpiredCache()
 	}
-
-	p.publisherCache[publisherID] = &publisherCacheEntry{
+	p.publisherCache.Set(publisherID, publisherCacheEntry{
 		allowedDomains: allowedDomains,
 		expiresAt:      time.Now().Add(ttl),
-	}
-
-	// Cleanup old entries (keep cache bounded to prevent memory issues)
-	if len(p.publisherCache) > 1000 {
-		p.cleanupExpiredCache()
-	}
+	})
 }
 
 // getCachedPublisher retrieves a cached publisher if it exists and hasn't expired
 //
-// LOCK ORDERING: publisherCacheMu only (Level 2)
+// LOCK ORDERING: publisherCache only (Level 2)
 // Safe to call from any context - does not acquire other locks
 func (p *PublisherAuth) getCachedPublisher(publisherID string) string {
-	p.publisherCacheMu.RLock()
-	defer p.publisherCacheMu.RUnlock()
-
-	entry, ok := p.publisherCache[publisherID]
+	entry, ok := p.publisherCache.Get(publisherID)
 	if !ok {
 		return ""
 	}
@@ -612,15 +635,89 @@ func (p *PublisherAuth) getCachedPublisher(publisherID string) string {
 	return entry.allowedDomains
 }
 
+// SnapshotName implements warmcache.Provider
+func (p *PublisherAuth) SnapshotName() string {
+	return "publisher_auth"
+}
+
+// publisherSnapshotEntry is a cached publisher as persisted in a warm cache
+// snapshot
+type publisherSnapshotEntry struct {
+	AllowedDomains string    `json:"allowed_domains"`
+	ExpiresAt      time.Time `json:"expires_at"`
+}
+
+// Snapshot implements warmcache.Provider, exporting unexpired cache entries
+// with their expiry
+//
+// LOCK ORDERING: publisherCache only (Level 2)
+func (p *PublisherAuth) Snapshot() (json.RawMessage, error) {
+	entries := make(map[string]publisherSnapshotEntry, p.publisherCache.Len())
+	now := time.Now()
+	p.publisherCache.Range(func(pubID string, entry publisherCacheEntry) bool {
+		if now.Before(entry.expiresAt) {
+			entries[pubID] = publisherSnapshotEntry{
+				AllowedDomains: entry.allowedDomains,
+				ExpiresAt:      entry.expiresAt,
+			}
+		}
+		return true
+	})
+
+	return json.Marshal(entries)
+}
+
+// Restore implements warmcache.Provider. Entries keep the expiry they had
+// when snapshotted; ones that expired in the meantime are skipped.
+//
+// LOCK ORDERING: publisherCache only (Level 2)
+func (p *PublisherAuth) Restore(data json.RawMessage) error {
+	var entries map[string]publisherSnapshotEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for pubID, entry := range entries {
+		if !now.Before(entry.ExpiresAt) {
+			continue
+		}
+		// Never overwrite fresher data
+		p.publisherCache.Add(pubID, publisherCacheEntry{
+			allowedDomains: entry.AllowedDomains,
+			expiresAt:      entry.ExpiresAt,
+		})
+	}
+	return nil
+}
+
 // cleanupExpiredCache removes expired cache entries
-// CALLER MUST HOLD publisherCacheMu.Lock()
 func (p *PublisherAuth) cleanupExpiredCache() {
 	now := time.Now()
-	for pubID, entry := range p.publisherCache {
-		if now.After(entry.expiresAt) {
-			delete(p.publisherCache, pubID)
+	p.publisherCache.RemoveFunc(func(_ string, entry publisherCacheEntry) bool {
+		return now.After(entry.expiresAt)
+	})
+}
+
+// PurgeCache drops all cached publisher lookups and returns how many were removed
+//
+// LOCK ORDERING: publisherCache only (Level 2)
+func (p *PublisherAuth) PurgeCache() int {
+	return p.publisherCache.Purge()
+}
+
+// InvalidateCache drops cached lookups for the given publisher IDs and
+// returns how many were removed
+//
+// LOCK ORDERING: publisherCache only (Level 2)
+func (p *PublisherAuth) InvalidateCache(publisherIDs ...string) int {
+	n := 0
+	for _, pubID := range publisherIDs {
+		if p.publisherCache.Delete(pubID) {
+			n++
 		}
 	}
+	return n
 }
 
 // IsEnabled returns whether publisher auth is enabled
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"sync"
@@ -203,11 +204,10 @@ func TestValidatePublisher_MemoryCacheExpiration(t *testing.T) {
 	}
 
 	// Manually expire the cache entry
-	auth.publisherCacheMu.Lock()
-	if entry, ok := auth.publisherCache["pub123"]; ok {
+	auth.publisherCache.Update("pub123", func(entry publisherCacheEntry, _ bool) publisherCacheEntry {
 		entry.expiresAt = time.Now().Add(-1 * time.Second) // Expire it
-	}
-	auth.publisherCacheMu.Unlock()
+		return entry
+	})
 
 	// Make PostgreSQL fail
 	mockStore.setError(true, errors.New("database unavailable"))
@@ -328,9 +328,7 @@ func TestCachePublisher_BoundedSize(t *testing.T) {
 	}
 
 	// Verify cache is bounded
-	auth.publisherCacheMu.RLock()
-	cacheSize := len(auth.publisherCache)
-	auth.publisherCacheMu.RUnlock()
+	cacheSize := auth.publisherCache.Len()
 
 	if cacheSize > 1001 {
 		t.Errorf("Expected cache size <= 1001, got %d (cleanup should have run)", cacheSize)
@@ -406,32 +404,25 @@ func TestCleanupExpiredCache(t *testing.T) {
 	})
 
 	// Add entries with different expiration times
-	auth.publisherCacheMu.Lock()
-	auth.publisherCache = map[string]*publisherCacheEntry{
-		"pub1": {allowedDomains: "example.com", expiresAt: time.Now().Add(10 * time.Second)},  // Valid
-		"pub2": {allowedDomains: "test.com", expiresAt: time.Now().Add(-1 * time.Second)},     // Expired
-		"pub3": {allowedDomains: "demo.com", expiresAt: time.Now().Add(-10 * time.Second)},    // Expired
-		"pub4": {allowedDomains: "another.com", expiresAt: time.Now().Add(5 * time.Second)},   // Valid
-	}
-	auth.publisherCacheMu.Unlock()
+	auth.publisherCache.Set("pub1", publisherCacheEntry{allowedDomains: "example.com", expiresAt: time.Now().Add(10 * time.Second)}) // Valid
+	auth.publisherCache.Set("pub2", publisherCacheEntry{allowedDomains: "test.com", expiresAt: time.Now().Add(-1 * time.Second)})    // Expired
+	auth.publisherCache.Set("pub3", publisherCacheEntry{allowedDomains: "demo.com", expiresAt: time.Now().Add(-10 * time.Second)})   // Expired
+	auth.publisherCache.Set("pub4", publisherCacheEntry{allowedDomains: "another.com", expiresAt: time.Now().Add(5 * time.Second)})  // Valid
 
 	// Run cleanup
 	auth.cleanupExpiredCache()
 
 	// Verify expired entries removed
-	auth.publisherCacheMu.RLock()
-	defer auth.publisherCacheMu.RUnlock()
-
-	if _, exists := auth.publisherCache["pub2"]; exists {
+	if auth.publisherCache.Contains("pub2") {
 		t.Error("Expected expired pub2 to be removed")
 	}
-	if _, exists := auth.publisherCache["pub3"]; exists {
+	if auth.publisherCache.Contains("pub3") {
 		t.Error("Expected expired pub3 to be removed")
 	}
-	if _, exists := auth.publisherCache["pub1"]; !exists {
+	if !auth.publisherCache.Contains("pub1") {
 		t.Error("Expected valid pub1 to remain")
 	}
-	if _, exists := auth.publisherCache["pub4"]; !exists {
+	if !auth.publisherCache.Contains("pub4") {
 		t.Error("Expected valid pub4 to remain")
 	}
 }
@@ -480,54 +471,100 @@ func TestValidatePublisher_ConcurrentAccess(t *testing.T) {
 	}
 }
 
-// TestCheckRateLimit_CleanupStaleEntries tests that stale rate limit entries are cleaned up
-func TestCheckRateLimit_CleanupStaleEntries(t *testing.T) {
+// TestCheckRateLimit_BoundedEntries tests that unique publisher IDs evict
+// the least recently used rate limit entries instead of growing the map
+func TestCheckRateLimit_BoundedEntries(t *testing.T) {
 	auth := NewPublisherAuth(&PublisherAuthConfig{
 		Enabled:         true,
 		RateLimitPerPub: 10,
 	})
 
-	// Add 1001 publishers to trigger cleanup threshold
-	for i := 0; i < 1001; i++ {
-		pubID := fmt.Sprintf("pub%d", i)
-		auth.checkRateLimit(pubID)
+	for i := 0; i < rateLimitMaxEntries+100; i++ {
+		auth.checkRateLimit(fmt.Sprintf("pub%d", i))
+	}
+
+	if size := auth.rateLimits.Len(); size != rateLimitMaxEntries {
+		t.Errorf("Rate limit map size: %d (expected %d)", size, rateLimitMaxEntries)
+	}
+	if auth.rateLimits.Contains("pub0") {
+		t.Error("Expected least recently used entry pub0 to be evicted")
+	}
+	if !auth.rateLimits.Contains(fmt.Sprintf("pub%d", rateLimitMaxEntries+99)) {
+		t.Error("Expected most recent entry to remain")
+	}
+}
+
+// TestPublisherCache_SnapshotRestore tests warm cache export/import of the memory cache
+func TestPublisherCache_SnapshotRestore(t *testing.T) {
+	source := NewPublisherAuth(&PublisherAuthConfig{Enabled: true})
+	source.cachePublisher("pub1", "example.com", 30*time.Second)
+	source.cachePublisher("expired", "old.com", -time.Second)
+
+	data, err := source.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+
+	target := NewPublisherAuth(&PublisherAuthConfig{Enabled: true})
+	target.cachePublisher("pub1", "fresh.com", 30*time.Second)
+	if err := target.Restore(data); err != nil {
+		t.Fatalf("Restore failed: %v", err)
 	}
 
-	// Manually age some entries to be stale (>1 hour old)
-	auth.rateLimitsMu.Lock()
-	staleTime := time.Now().Add(-2 * time.Hour)
-	for i := 0; i < 500; i++ {
-		pubID := fmt.Sprintf("pub%d", i)
-		if entry, ok := auth.rateLimits[pubID]; ok {
-			entry.lastCheck = staleTime
-		}
+	if got := target.getCachedPublisher("pub1"); got != "fresh.com" {
+		t.Errorf("Expected existing entry to be kept, got %q", got)
+	}
+	if got := target.getCachedPublisher("expired"); got != "" {
+		t.Errorf("Expected expired entry not to be snapshotted, got %q", got)
 	}
-	auth.rateLimitsMu.Unlock()
 
-	// Trigger cleanup by checking rate limit on an EXISTING publisher (not a new one)
-	// This will pass the token check and trigger cleanup since len > 1000
-	auth.checkRateLimit("pub1000") // Existing publisher from loop above
+	empty := NewPublisherAuth(&PublisherAuthConfig{Enabled: true})
+	if err := empty.Restore(data); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if got := empty.getCachedPublisher("pub1"); got != "example.com" {
+		t.Errorf("Expected pub1 to be restored, got %q", got)
+	}
+}
 
-	// Verify cleanup ran (stale entries should be removed)
-	auth.rateLimitsMu.RLock()
-	finalSize := len(auth.rateLimits)
-	auth.rateLimitsMu.RUnlock()
+// TestPublisherCache_RestoreKeepsExpiry tests that restored entries keep
+// their original expiry instead of getting a fresh TTL
+func TestPublisherCache_RestoreKeepsExpiry(t *testing.T) {
+	expiresAt := time.Now().Add(5 * time.Second)
+	data := json.RawMessage(fmt.Sprintf(`{
+		"pub1": {"allowed_domains": "example.com", "expires_at": %q},
+		"stale": {"allowed_domains": "old.com", "expires_at": %q}
+	}`, expiresAt.Format(time.RFC3339Nano), time.Now().Add(-time.Second).Format(time.RFC3339Nano)))
 
-	// Should have removed the 500 stale entries
-	// Expected: 1001 - 500 = 501 remaining
-	if finalSize > 600 { // Allow some margin
-		t.Errorf("Rate limit map size after cleanup: %d (expected ~501)", finalSize)
+	auth := NewPublisherAuth(&PublisherAuthConfig{Enabled: true})
+	if err := auth.Restore(data); err != nil {
+		t.Fatalf("Restore failed: %v", err)
 	}
-	if finalSize < 450 {
-		t.Errorf("Rate limit map size too small: %d (expected ~501)", finalSize)
+
+	entry, ok := auth.publisherCache.Get("pub1")
+	if !ok {
+		t.Fatal("Expected pub1 to be restored")
+	}
+	if !entry.expiresAt.Equal(expiresAt) {
+		t.Errorf("Expected expiry %v, got %v", expiresAt, entry.expiresAt)
+	}
+	if auth.publisherCache.Contains("stale") {
+		t.Error("Expected entry that expired since the snapshot to be skipped")
 	}
+}
 
-	// Verify one of the stale entries was actually removed
-	auth.rateLimitsMu.RLock()
-	_, exists := auth.rateLimits["pub0"] // Should be stale and removed
-	auth.rateLimitsMu.RUnlock()
+func TestPublisherCache_Invalidate(t *testing.T) {
+	auth := NewPublisherAuth(&PublisherAuthConfig{Enabled: true})
+	auth.cachePublisher("pub1", "one.com", 30*time.Second)
+	auth.cachePublisher("pub2", "two.com", 30*time.Second)
 
-	if exists {
-		t.Error("Expected stale entry pub0 to be removed")
+	if n := auth.InvalidateCache("pub1", "missing"); n != 1 {
+		t.Errorf("Expected 1 entry invalidated, got %d", n)
+	}
+	if got := auth.getCachedPublisher("pub1"); got != "" {
+		t.Errorf("Expected pub1 to be invalidated, got %q", got)
+	}
+	if got := auth.getCachedPublisher("pub2"); got != "two.com" {
+		t.Errorf("Expected pub2 to be kept, got %q", got)
 	}
 }
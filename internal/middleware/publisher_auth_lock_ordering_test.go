@@ -48,13 +48,13 @@ func TestLockOrdering_NoDeadlock(t *testing.T) {
 						// Read config (mu.RLock)
 						_ = auth.IsEnabled()
 					case 1:
-						// Check rate limit (mu.RLock → rateLimitsMu.Lock)
+						// Check rate limit (mu.RLock → rateLimits lock)
 						_ = auth.checkRateLimit("pub1")
 					case 2:
-						// Cache publisher (publisherCacheMu.Lock)
+						// Cache publisher (publisherCache lock)
 						auth.cachePublisher("pub2", "test.com", 10*time.Second)
 					case 3:
-						// Get cached publisher (publisherCacheMu.RLock)
+						// Get cached publisher (publisherCache lock)
 						_ = auth.getCachedPublisher("pub1")
 					case 4:
 						// Validate publisher (mu.RLock → calls getCachedPublisher)
@@ -77,7 +77,7 @@ func TestLockOrdering_NoDeadlock(t *testing.T) {
 }
 
 // TestLockOrdering_ConcurrentConfigAndRateLimit tests the specific
-// lock ordering: mu → rateLimitsMu
+// lock ordering: mu → rateLimits
 func TestLockOrdering_ConcurrentConfigAndRateLimit(t *testing.T) {
 	auth := NewPublisherAuth(&PublisherAuthConfig{
 		Enabled:         true,
@@ -87,7 +87,7 @@ func TestLockOrdering_ConcurrentConfigAndRateLimit(t *testing.T) {
 	done := make(chan struct{})
 	var operations int64
 
-	// Goroutine 1: Constantly check rate limits (mu.RLock → rateLimitsMu.Lock)
+	// Goroutine 1: Constantly check rate limits (mu.RLock → rateLimits lock)
 	go func() {
 		for {
 			select {
@@ -245,12 +245,12 @@ func TestLockOrdering_StressTest(t *testing.T) {
 		func() { _ = auth.IsEnabled() },
 		func() { auth.RegisterPublisher("pub3", "new.com") },
 		func() { auth.UnregisterPublisher("pub3") },
-		// Cache operations (publisherCacheMu)
+		// Cache operations (publisherCache)
 		func() { auth.cachePublisher("pub1", "example.com", 30*time.Second) },
 		func() { auth.cachePublisher("pub2", "test.com", 30*time.Second) },
 		func() { _ = auth.getCachedPublisher("pub1") },
 		func() { _ = auth.getCachedPublisher("pub2") },
-		// Rate limit operations (mu.RLock → rateLimitsMu.Lock)
+		// Rate limit operations (mu.RLock → rateLimits lock)
 		func() { _ = auth.checkRateLimit("pub1") },
 		func() { _ = auth.checkRateLimit("pub2") },
 		// Validation (mu.RLock → getCachedPublisher)
@@ -414,26 +414,21 @@ func TestLockOrdering_DocumentedBehavior(t *testing.T) {
 	_ = auth.config
 	auth.mu.RUnlock()
 
-	// Test 2: publisherCacheMu can be acquired alone
-	auth.publisherCacheMu.Lock()
-	auth.publisherCache = make(map[string]*publisherCacheEntry)
-	auth.publisherCacheMu.Unlock()
+	// Test 2: publisherCache can be used alone
+	auth.publisherCache.Purge()
 
-	// Test 3: rateLimitsMu can be acquired alone
-	auth.rateLimitsMu.Lock()
-	auth.rateLimits = make(map[string]*rateLimitEntry)
-	auth.rateLimitsMu.Unlock()
+	// Test 3: rateLimits can be used alone
+	auth.rateLimits.Purge()
 
-	// Test 4: mu → publisherCacheMu (allowed)
+	// Test 4: mu → publisherCache (allowed)
 	auth.mu.RLock()
 	config := auth.config
 	auth.mu.RUnlock()
 	if config != nil {
-		auth.publisherCacheMu.Lock()
-		auth.publisherCacheMu.Unlock()
+		auth.getCachedPublisher("pub1")
 	}
 
-	// Test 5: mu → rateLimitsMu (allowed via checkRateLimit)
+	// Test 5: mu → rateLimits (allowed via checkRateLimit)
 	_ = auth.checkRateLimit("pub1")
 
 	// Test 6: Ensure we don't hold multiple locks simultaneously
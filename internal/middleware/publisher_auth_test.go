@@ -341,6 +341,30 @@ func TestPublisherAuth_AppPublisher(t *testing.T) {
 	}
 }
 
+func TestIVTChannel(t *testing.T) {
+	tests := []struct {
+		body string
+		want string
+	}{
+		{`{"site":{"domain":"example.com"}}`, IVTChannelSite},
+		{`{"app":{"bundle":"com.example"}}`, IVTChannelApp},
+		{`{"app":{"bundle":"com.example"},"device":{"devicetype":3}}`, IVTChannelCTV},
+		{`{"app":{"bundle":"com.example"},"device":{"devicetype":7}}`, IVTChannelCTV},
+		{`{"dooh":{"id":"screen-1"},"device":{"devicetype":3}}`, IVTChannelDOOH},
+		{`{}`, IVTChannelSite},
+	}
+
+	for _, tt := range tests {
+		var req minimalBidRequest
+		if err := json.Unmarshal([]byte(tt.body), &req); err != nil {
+			t.Fatalf("invalid body %s: %v", tt.body, err)
+		}
+		if got := ivtChannel(&req); got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.body, tt.want, got)
+		}
+	}
+}
+
 func TestParsePublishers(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -740,6 +764,29 @@ func TestDomainMatches_Whitespace(t *testing.T) {
 	}
 }
 
+func TestDomainMatches_SharedMatcher(t *testing.T) {
+	auth := NewPublisherAuth(nil)
+
+	testCases := []struct {
+		domain  string
+		allowed string
+		match   bool
+	}{
+		{"test.com", "example.com,test.com", true},          // comma delimiter as stored in Postgres
+		{"example.com:8080", "example.com", true},           // port is ignored
+		{"Sub.Example.COM", "*.example.com", true},          // case-insensitive
+		{"bbc.co.uk", "*.co.uk", false},                     // wildcard over a public suffix never matches
+		{"xn--bcher-kva.de", "bücher.de", true},             // IDN and punycode compare equal
+		{"cdn7.example.com", `~cdn\d+\.example\.com`, true}, // regex entry
+	}
+
+	for _, tc := range testCases {
+		if got := auth.domainMatches(tc.domain, tc.allowed); got != tc.match {
+			t.Errorf("domainMatches(%q, %q) = %v, expected %v", tc.domain, tc.allowed, got, tc.match)
+		}
+	}
+}
+
 func TestPublisherAuthError_Error(t *testing.T) {
 	err := &PublisherAuthError{
 		Code:    "test_error",
@@ -8,6 +8,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/thenexusengine/tne_springwire/pkg/lru"
 )
 
 // RateLimitConfig holds rate limiting configuration
@@ -84,11 +86,18 @@ type RateLimitMetrics interface {
 	IncRateLimitRejected()
 }
 
+// Bounds for per-client state. Client IPs are attacker controlled, so the
+// least recently seen clients are evicted rather than growing the map.
+const (
+	rateLimitClientsMaxEntries = 100000
+	rateLimitClientsMaxBytes   = 16 << 20
+)
+
 // RateLimiter provides rate limiting middleware using token bucket algorithm
 type RateLimiter struct {
 	config  *RateLimitConfig
-	clients map[string]*clientState
-	mu      sync.Mutex
+	clients *lru.Cache[string, clientState]
+	mu      sync.Mutex // protects config and metrics
 	stopCh  chan struct{}
 	metrics RateLimitMetrics
 }
@@ -100,9 +109,15 @@ func NewRateLimiter(config *RateLimitConfig) *RateLimiter {
 	}
 
 	rl := &RateLimiter{
-		config:  config,
-		clients: make(map[string]*clientState),
-		stopCh:  make(chan struct{}),
+		config: config,
+		clients: lru.New(lru.Config{
+			Name:       "rate_limit_clients",
+			MaxEntries: rateLimitClientsMaxEntries,
+			MaxBytes:   rateLimitClientsMaxBytes,
+		}, func(clientID string, _ clientState) int64 {
+			return int64(len(clientID)) + 64
+		}),
+		stopCh: make(chan struct{}),
 	}
 
 	// Start cleanup goroutine only if cleanup interval is positive
@@ -121,15 +136,11 @@ func (rl *RateLimiter) cleanup() {
 	for {
 		select {
 		case <-ticker.C:
-			rl.mu.Lock()
 			now := time.Now()
-			for key, state := range rl.clients {
-				// Remove entries not seen in the last minute
-				if now.Sub(state.lastCheck) > time.Minute {
-					delete(rl.clients, key)
-				}
-			}
-			rl.mu.Unlock()
+			// Remove entries not seen in the last minute
+			rl.clients.RemoveFunc(func(_ string, state clientState) bool {
+				return now.Sub(state.lastCheck) > time.Minute
+			})
 		case <-rl.stopCh:
 			return
 		}
@@ -178,38 +189,40 @@ func (rl *RateLimiter) Middleware(next http.Handler) http.Handler {
 // allow checks if a request from the given client should be allowed
 func (rl *RateLimiter) allow(clientID string) bool {
 	rl.mu.Lock()
-	defer rl.mu.Unlock()
+	rps, burst := rl.config.RequestsPerSecond, rl.config.BurstSize
+	rl.mu.Unlock()
 
 	now := time.Now()
-	state, exists := rl.clients[clientID]
-
-	if !exists {
-		// New client, start with burst size tokens
-		rl.clients[clientID] = &clientState{
-			tokens:    float64(rl.config.BurstSize - 1), // -1 for current request
-			lastCheck: now,
+	allowed := false
+	rl.clients.Update(clientID, func(state clientState, exists bool) clientState {
+		if !exists {
+			// New client, start with burst size tokens
+			allowed = true
+			return clientState{
+				tokens:    float64(burst - 1), // -1 for current request
+				lastCheck: now,
+			}
 		}
-		return true
-	}
-
-	// Calculate tokens to add based on time elapsed
-	elapsed := now.Sub(state.lastCheck).Seconds()
-	state.tokens += elapsed * float64(rl.config.RequestsPerSecond)
 
-	// Cap at burst size
-	if state.tokens > float64(rl.config.BurstSize) {
-		state.tokens = float64(rl.config.BurstSize)
-	}
+		// Calculate tokens to add based on time elapsed
+		elapsed := now.Sub(state.lastCheck).Seconds()
+		state.tokens += elapsed * float64(rps)
 
-	state.lastCheck = now
+		// Cap at burst size
+		if state.tokens > float64(burst) {
+			state.tokens = float64(burst)
+		}
 
-	// Check if we have tokens available
-	if state.tokens < 1 {
-		return false
-	}
+		state.lastCheck = now
 
-	state.tokens--
-	return true
+		// Check if we have tokens available
+		if state.tokens >= 1 {
+			state.tokens--
+			allowed = true
+		}
+		return state
+	})
+	return allowed
 }
 
 // getClientIP extracts the client IP from the request with secure XFF handling
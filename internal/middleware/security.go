@@ -136,6 +136,9 @@ func (s *Security) Middleware(next http.Handler) http.Handler {
 			// Dashboard needs inline scripts and styles
 			if isDashboardPath(r.URL.Path) {
 				h.Set("Content-Security-Policy", "default-src 'self'; script-src 'unsafe-inline'; style-src 'unsafe-inline'")
+			} else if isAdminUIPath(r.URL.Path) {
+				// The admin UI loads its own script and styles and calls the admin APIs
+				h.Set("Content-Security-Policy", "default-src 'self'; frame-ancestors 'none'")
 			} else {
 				h.Set("Content-Security-Policy", csp)
 			}
@@ -181,6 +184,11 @@ func isDashboardPath(path string) bool {
 	return path == "/admin/dashboard"
 }
 
+// isAdminUIPath checks if path is part of the embedded admin UI
+func isAdminUIPath(path string) bool {
+	return path == "/admin/ui" || strings.HasPrefix(path, "/admin/ui/")
+}
+
 // SetEnabled enables or disables security headers
 func (s *Security) SetEnabled(enabled bool) {
 	s.mu.Lock()
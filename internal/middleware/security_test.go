@@ -127,6 +127,31 @@ func TestSecurityMiddleware_MetricsPathNoCacheControl(t *testing.T) {
 	}
 }
 
+func TestSecurityMiddleware_AdminUICSP(t *testing.T) {
+	security := NewSecurity(&SecurityConfig{
+		Enabled:               true,
+		ContentSecurityPolicy: "default-src 'none'",
+	})
+
+	handler := security.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := map[string]string{
+		"/admin/ui/":       "default-src 'self'; frame-ancestors 'none'",
+		"/admin/ui/app.js": "default-src 'self'; frame-ancestors 'none'",
+		"/admin/uiother":   "default-src 'none'",
+		"/admin/errors":    "default-src 'none'",
+	}
+	for path, want := range tests {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest("GET", path, nil))
+		if got := rr.Header().Get("Content-Security-Policy"); got != want {
+			t.Errorf("%s: CSP = %q, want %q", path, got, want)
+		}
+	}
+}
+
 func TestSecurityMiddleware_SetHSTS(t *testing.T) {
 	security := NewSecurity(&SecurityConfig{
 		Enabled: true,
@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ShadowTrafficHeader marks requests mirrored to a standby instance. Any
+// non-empty value marks the request as shadow traffic.
+const ShadowTrafficHeader = "X-Shadow-Traffic"
+
+// standbyPaths are the bid paths refused while in standby; health, metrics
+// and admin paths keep working so the instance can be checked and activated
+var standbyPaths = []string{"/openrtb2/auction", "/video/vast", "/video/openrtb"}
+
+type shadowContextKey struct{}
+
+// IsShadowRequest reports whether the request is mirrored shadow traffic
+// whose response is discarded
+func IsShadowRequest(ctx context.Context) bool {
+	shadow, _ := ctx.Value(shadowContextKey{}).(bool)
+	return shadow
+}
+
+// StandbyStatus describes an instance's standby state
+type StandbyStatus struct {
+	Mode           string     `json:"mode"` // "standby" or "active"
+	StartedAt      time.Time  `json:"started_at"`
+	ActivatedAt    *time.Time `json:"activated_at,omitempty"`
+	ShadowRequests int64      `json:"shadow_requests"`
+}
+
+// Standby holds a new blue/green instance out of rotation until it is
+// activated. Meanwhile mirrored shadow traffic is served with the response
+// discarded, which warms caches, connection pools and bidder circuits.
+type Standby struct {
+	active         atomic.Bool
+	shadowRequests atomic.Int64
+
+	mu          sync.Mutex
+	startedAt   time.Time
+	activatedAt time.Time
+}
+
+// NewStandby creates the standby state; standby false starts active, in which
+// case the middleware only drops shadow traffic
+func NewStandby(standby bool) *Standby {
+	s := &Standby{startedAt: time.Now()}
+	if !standby {
+		s.active.Store(true)
+		s.activatedAt = s.startedAt
+	}
+	return s
+}
+
+// Active reports whether the instance takes live traffic
+func (s *Standby) Active() bool {
+	return s.active.Load()
+}
+
+// Activate switches the instance to live traffic. It reports false if the
+// instance was already active.
+func (s *Standby) Activate() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.active.Load() {
+		return false
+	}
+	s.activatedAt = time.Now()
+	s.active.Store(true)
+	return true
+}
+
+// Status returns the current mode and how much shadow traffic was served
+func (s *Standby) Status() StandbyStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := StandbyStatus{
+		Mode:           "standby",
+		StartedAt:      s.startedAt,
+		ShadowRequests: s.shadowRequests.Load(),
+	}
+	if s.active.Load() {
+		status.Mode = "active"
+		activatedAt := s.activatedAt
+		status.ActivatedAt = &activatedAt
+	}
+	return status
+}
+
+// Middleware serves shadow traffic while in standby and drops it once
+// active, answering mirrors with 204 either way. Live bid requests get 503
+// until the instance is activated.
+func (s *Standby) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(ShadowTrafficHeader) != "" {
+			if !s.active.Load() {
+				s.shadowRequests.Add(1)
+				ctx := context.WithValue(r.Context(), shadowContextKey{}, true)
+				next.ServeHTTP(&discardResponseWriter{header: make(http.Header)}, r.WithContext(ctx))
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		if !s.active.Load() && isStandbyPath(r.URL.Path) {
+			w.Header().Set("Retry-After", "5")
+			http.Error(w, "Instance is in standby", http.StatusServiceUnavailable)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isStandbyPath reports whether path is a bid path refused in standby
+func isStandbyPath(path string) bool {
+	for _, p := range standbyPaths {
+		if path == p || strings.HasPrefix(path, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// discardResponseWriter drops the response to a shadow request
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (d *discardResponseWriter) Header() http.Header         { return d.header }
+func (d *discardResponseWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (d *discardResponseWriter) WriteHeader(int)             {}
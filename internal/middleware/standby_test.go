@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStandby_ShadowTrafficServedAndDiscarded(t *testing.T) {
+	standby := NewStandby(true)
+	var sawShadow bool
+	handler := standby.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sawShadow = IsShadowRequest(r.Context())
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"id":"auction-1"}`))
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/openrtb2/auction", nil)
+	req.Header.Set(ShadowTrafficHeader, "1")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if !sawShadow {
+		t.Error("expected the handler to see a shadow request")
+	}
+	if w.Code != http.StatusNoContent || w.Body.Len() != 0 {
+		t.Errorf("expected an empty 204, got %d %q", w.Code, w.Body.String())
+	}
+	if got := standby.Status().ShadowRequests; got != 1 {
+		t.Errorf("expected 1 shadow request, got %d", got)
+	}
+}
+
+func TestStandby_LiveTrafficRefusedUntilActivated(t *testing.T) {
+	standby := NewStandby(true)
+	handler := standby.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/openrtb2/auction", http.StatusServiceUnavailable},
+		{"/video/vast", http.StatusServiceUnavailable},
+		{"/health/ready", http.StatusOK},
+		{"/admin/standby", http.StatusOK},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if w.Code != tt.want {
+			t.Errorf("%s in standby: expected %d, got %d", tt.path, tt.want, w.Code)
+		}
+	}
+
+	if !standby.Activate() {
+		t.Fatal("expected the first activation to switch modes")
+	}
+	if standby.Activate() {
+		t.Error("expected a repeated activation to be a no-op")
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/openrtb2/auction", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("expected live traffic once active, got %d", w.Code)
+	}
+	if status := standby.Status(); status.Mode != "active" || status.ActivatedAt == nil {
+		t.Errorf("unexpected status: %+v", status)
+	}
+}
+
+func TestStandby_ActiveDropsShadowTraffic(t *testing.T) {
+	standby := NewStandby(false)
+	called := false
+	handler := standby.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/openrtb2/auction", nil)
+	req.Header.Set(ShadowTrafficHeader, "1")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if called || w.Code != http.StatusNoContent {
+		t.Errorf("expected shadow traffic dropped with 204 when active, got %d (called %v)", w.Code, called)
+	}
+}
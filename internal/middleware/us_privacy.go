@@ -0,0 +1,166 @@
+// Package middleware provides HTTP middleware components
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+// US Privacy signal sources, used for logging and metrics labels
+const (
+	USPrivacySourceRegs    = "regs.us_privacy"
+	USPrivacySourceRegsExt = "regs.ext.us_privacy"
+	USPrivacySourceGPP     = "regs.gpp"
+)
+
+// gppSectionUSPV1 is the GPP section ID for the legacy US Privacy string
+const gppSectionUSPV1 = 6
+
+// ConsentSignalUSPrivacy is the ConsentSignals metric type for US Privacy signals
+const ConsentSignalUSPrivacy = "us_privacy"
+
+// PrivacyFilterReasonUSPrivacy is the PrivacyFiltered metric reason when a bidder
+// can't honor a US Privacy opt-out
+const PrivacyFilterReasonUSPrivacy = "us_privacy_opt_out"
+
+// US Privacy parsing errors
+var (
+	ErrUSPrivacyLength  = errors.New("us_privacy string must be 4 characters")
+	ErrUSPrivacyVersion = errors.New("unsupported us_privacy version")
+	ErrUSPrivacyValue   = errors.New("us_privacy flags must be Y, N or -")
+)
+
+// USPrivacySignal is a parsed IAB US Privacy (CCPA) string
+// Format: VNOS (Version, explicit Notice, Opt-out of sale, LSPA covered)
+type USPrivacySignal struct {
+	Raw    string
+	Notice byte
+	OptOut byte
+	LSPA   byte
+	Source string
+}
+
+// OptedOut returns true when the user has opted out of the sale of personal information
+func (s *USPrivacySignal) OptedOut() bool {
+	return s != nil && s.OptOut == 'Y'
+}
+
+// ParseUSPrivacy parses a US Privacy string such as "1YYN"
+func ParseUSPrivacy(usPrivacy string) (*USPrivacySignal, error) {
+	if len(usPrivacy) != 4 {
+		return nil, ErrUSPrivacyLength
+	}
+	if usPrivacy[0] != '1' {
+		return nil, ErrUSPrivacyVersion
+	}
+	for i := 1; i < 4; i++ {
+		switch usPrivacy[i] {
+		case 'Y', 'N', '-':
+		default:
+			return nil, ErrUSPrivacyValue
+		}
+	}
+	return &USPrivacySignal{
+		Raw:    usPrivacy,
+		Notice: usPrivacy[1],
+		OptOut: usPrivacy[2],
+		LSPA:   usPrivacy[3],
+	}, nil
+}
+
+// ResolveUSPrivacy finds the US Privacy signal for a request.
+// Precedence: regs.us_privacy (OpenRTB 2.6), regs.ext.us_privacy (2.5),
+// then the uspv1 section of a GPP string when gpp_sid includes it.
+// Returns nil when no valid signal is present.
+func ResolveUSPrivacy(req *openrtb.BidRequest) *USPrivacySignal {
+	if req == nil || req.Regs == nil {
+		return nil
+	}
+	regs := req.Regs
+
+	if regs.USPrivacy != "" {
+		if sig, err := ParseUSPrivacy(regs.USPrivacy); err == nil {
+			sig.Source = USPrivacySourceRegs
+			return sig
+		}
+	}
+
+	if len(regs.Ext) > 0 {
+		var ext struct {
+			USPrivacy string `json:"us_privacy"`
+		}
+		if err := json.Unmarshal(regs.Ext, &ext); err == nil && ext.USPrivacy != "" {
+			if sig, err := ParseUSPrivacy(ext.USPrivacy); err == nil {
+				sig.Source = USPrivacySourceRegsExt
+				return sig
+			}
+		}
+	}
+
+	if regs.GPP != "" && containsInt(regs.GPPSID, gppSectionUSPV1) {
+		// The uspv1 GPP section carries the plain US Privacy string, so scan
+		// the sections following the header for one that parses.
+		sections := strings.Split(regs.GPP, "~")
+		for _, section := range sections[1:] {
+			if sig, err := ParseUSPrivacy(section); err == nil {
+				sig.Source = USPrivacySourceGPP
+				return sig
+			}
+		}
+	}
+
+	return nil
+}
+
+// StripUSPrivacyIdentifiers removes user identifiers and precise geolocation
+// from a request after a US Privacy opt-out. User and Device are copied before
+// modification so requests sharing those objects are not affected.
+func StripUSPrivacyIdentifiers(req *openrtb.BidRequest) {
+	ScrubIdentifiers(req, ScrubAll)
+}
+
+// coarsenGeo returns a copy of geo without lat/lon and fix metadata
+func coarsenGeo(geo *openrtb.Geo) *openrtb.Geo {
+	if geo == nil {
+		return nil
+	}
+	geoCopy := *geo
+	geoCopy.Lat = 0
+	geoCopy.Lon = 0
+	geoCopy.Accuracy = 0
+	geoCopy.LastFix = 0
+	return &geoCopy
+}
+
+// stripExtEIDs removes the legacy user.ext.eids field, preserving other ext keys
+func stripExtEIDs(ext json.RawMessage) json.RawMessage {
+	if len(ext) == 0 {
+		return ext
+	}
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(ext, &m); err != nil {
+		return ext
+	}
+	if _, ok := m["eids"]; !ok {
+		return ext
+	}
+	delete(m, "eids")
+	stripped, err := json.Marshal(m)
+	if err != nil {
+		return ext
+	}
+	return stripped
+}
+
+// containsInt reports whether values contains v
+func containsInt(values []int, v int) bool {
+	for _, val := range values {
+		if val == v {
+			return true
+		}
+	}
+	return false
+}
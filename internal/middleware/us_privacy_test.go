@@ -0,0 +1,170 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+func TestParseUSPrivacy(t *testing.T) {
+	tests := []struct {
+		input   string
+		wantErr error
+		optOut  bool
+	}{
+		{"1YYN", nil, true},
+		{"1YNN", nil, false},
+		{"1---", nil, false},
+		{"1YY", ErrUSPrivacyLength, false},
+		{"2YYN", ErrUSPrivacyVersion, false},
+		{"1YXN", ErrUSPrivacyValue, false},
+	}
+
+	for _, tt := range tests {
+		sig, err := ParseUSPrivacy(tt.input)
+		if err != tt.wantErr {
+			t.Errorf("ParseUSPrivacy(%q) error = %v, want %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if sig.OptedOut() != tt.optOut {
+			t.Errorf("ParseUSPrivacy(%q).OptedOut() = %v, want %v", tt.input, sig.OptedOut(), tt.optOut)
+		}
+	}
+}
+
+func TestResolveUSPrivacy_Sources(t *testing.T) {
+	tests := []struct {
+		name   string
+		regs   *openrtb.Regs
+		source string
+		optOut bool
+	}{
+		{"regs.us_privacy", &openrtb.Regs{USPrivacy: "1YYN"}, USPrivacySourceRegs, true},
+		{"regs.ext.us_privacy", &openrtb.Regs{Ext: json.RawMessage(`{"us_privacy":"1YYN"}`)}, USPrivacySourceRegsExt, true},
+		{"gpp uspv1 section", &openrtb.Regs{GPP: "DBABTA~1YYN", GPPSID: []int{6}}, USPrivacySourceGPP, true},
+		{"regs takes precedence", &openrtb.Regs{USPrivacy: "1YNN", Ext: json.RawMessage(`{"us_privacy":"1YYN"}`)}, USPrivacySourceRegs, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sig := ResolveUSPrivacy(&openrtb.BidRequest{Regs: tt.regs})
+			if sig == nil {
+				t.Fatal("expected signal to be resolved")
+			}
+			if sig.Source != tt.source {
+				t.Errorf("expected source %s, got %s", tt.source, sig.Source)
+			}
+			if sig.OptedOut() != tt.optOut {
+				t.Errorf("expected opt-out %v, got %v", tt.optOut, sig.OptedOut())
+			}
+		})
+	}
+}
+
+func TestResolveUSPrivacy_GPPWithoutSection(t *testing.T) {
+	// uspv1 section not listed in gpp_sid - must not be used
+	req := &openrtb.BidRequest{Regs: &openrtb.Regs{GPP: "DBABTA~1YYN", GPPSID: []int{7}}}
+	if sig := ResolveUSPrivacy(req); sig != nil {
+		t.Errorf("expected no signal, got %+v", sig)
+	}
+	if ResolveUSPrivacy(&openrtb.BidRequest{}) != nil {
+		t.Error("expected nil signal without regs")
+	}
+}
+
+func TestStripUSPrivacyIdentifiers(t *testing.T) {
+	user := &openrtb.User{
+		ID:       "user-1",
+		BuyerUID: "buyer-1",
+		Keywords: "sports",
+		EIDs:     []openrtb.EID{{Source: "uidapi.com"}},
+		Ext:      json.RawMessage(`{"eids":[{"source":"x"}],"consent":"abc"}`),
+	}
+	device := &openrtb.Device{
+		IP:  "192.168.1.100",
+		IFA: "ifa-1",
+		Geo: &openrtb.Geo{Lat: 37.77, Lon: -122.41, Country: "USA", Region: "CA"},
+	}
+	req := &openrtb.BidRequest{ID: "r1", User: user, Device: device}
+
+	StripUSPrivacyIdentifiers(req)
+
+	if req.User.ID != "" || req.User.BuyerUID != "" || req.User.EIDs != nil {
+		t.Errorf("expected user IDs to be stripped, got %+v", req.User)
+	}
+	if req.User.Keywords != "sports" {
+		t.Error("expected non-identifying user fields to be kept")
+	}
+	var ext map[string]interface{}
+	if err := json.Unmarshal(req.User.Ext, &ext); err != nil {
+		t.Fatalf("invalid user.ext: %v", err)
+	}
+	if _, ok := ext["eids"]; ok {
+		t.Error("expected user.ext.eids to be stripped")
+	}
+	if ext["consent"] != "abc" {
+		t.Error("expected other user.ext keys to be preserved")
+	}
+	if req.Device.IFA != "" {
+		t.Error("expected device IFA to be stripped")
+	}
+	if req.Device.IP != "192.168.1.0" {
+		t.Errorf("expected anonymized IP, got %s", req.Device.IP)
+	}
+	if req.Device.Geo.Lat != 0 || req.Device.Geo.Lon != 0 {
+		t.Error("expected precise geo to be stripped")
+	}
+	if req.Device.Geo.Region != "CA" {
+		t.Error("expected coarse geo to be kept")
+	}
+
+	// Original objects must not be mutated
+	if user.ID != "user-1" || device.IFA != "ifa-1" || device.Geo.Lat != 37.77 {
+		t.Error("expected original user/device to be untouched")
+	}
+}
+
+type mockPrivacyMetrics struct {
+	signals map[string][]bool
+	strings map[string]int
+}
+
+func (m *mockPrivacyMetrics) RecordConsentString(publisherID, signal, outcome string) {
+	if m.strings == nil {
+		m.strings = make(map[string]int)
+	}
+	m.strings[publisherID+"/"+signal+"/"+outcome]++
+}
+
+func (m *mockPrivacyMetrics) RecordConsentSignal(signalType string, hasConsent bool) {
+	if m.signals == nil {
+		m.signals = make(map[string][]bool)
+	}
+	m.signals[signalType] = append(m.signals[signalType], hasConsent)
+}
+
+func TestPrivacyMiddleware_RecordsUSPrivacySignal(t *testing.T) {
+	m := &mockPrivacyMetrics{}
+	mw := NewPrivacyMiddlewareWithMetrics(DefaultPrivacyConfig(), m)
+	handler := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := &openrtb.BidRequest{
+		ID:   "test-usp-metrics",
+		Imp:  []openrtb.Imp{{ID: "imp1", Banner: &openrtb.Banner{}}},
+		Regs: &openrtb.Regs{Ext: json.RawMessage(`{"us_privacy":"1YYN"}`)},
+	}
+	body, _ := json.Marshal(req)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/openrtb2/auction", bytes.NewReader(body)))
+
+	got := m.signals[ConsentSignalUSPrivacy]
+	if len(got) != 1 || got[0] {
+		t.Errorf("expected one us_privacy signal without consent, got %v", got)
+	}
+}
@@ -0,0 +1,193 @@
+package openrtb
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+)
+
+// Legacy upgrade rules, reported by ParseBidRequest and used as metric labels
+const (
+	// UpgradeProtocols turns video.protocol and scalar video/audio
+	// protocols into the protocols array
+	UpgradeProtocols = "protocols"
+	// UpgradeBannerFormat adds a format entry for banner.w/h
+	UpgradeBannerFormat = "banner_format"
+	// UpgradeGender maps numeric and spelled out genders to M, F or O and
+	// drops values that can't be mapped
+	UpgradeGender = "gender"
+	// UpgradeSChain moves the OpenRTB 2.5 source.ext.schain to source.schain
+	UpgradeSChain = "schain"
+)
+
+// ParseBidRequest unmarshals a bid request and upgrades legacy constructs
+// that older integrations still send into the canonical model. It returns
+// the rules applied, sorted; the unmarshal error is returned unchanged when
+// no upgrade makes the body parse.
+func ParseBidRequest(body []byte, req *BidRequest) ([]string, error) {
+	applied := make(map[string]bool)
+
+	err := json.Unmarshal(body, req)
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		// Legacy scalars where arrays or strings are expected fail the
+		// typed unmarshal; fix them in the raw JSON and try once more
+		upgraded, rules := upgradeLegacyJSON(body)
+		if len(rules) > 0 {
+			var retry BidRequest
+			if json.Unmarshal(upgraded, &retry) == nil {
+				*req = retry
+				err = nil
+				for _, rule := range rules {
+					applied[rule] = true
+				}
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rule := range UpgradeLegacy(req) {
+		applied[rule] = true
+	}
+	rules := make([]string, 0, len(applied))
+	for rule := range applied {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+	return rules, nil
+}
+
+// UpgradeLegacy upgrades legacy constructs in a parsed request in place and
+// returns the rules applied, once each
+func UpgradeLegacy(req *BidRequest) []string {
+	var rules []string
+	add := func(rule string) {
+		for _, r := range rules {
+			if r == rule {
+				return
+			}
+		}
+		rules = append(rules, rule)
+	}
+
+	for i := range req.Imp {
+		imp := &req.Imp[i]
+		if v := imp.Video; v != nil && v.Protocol != 0 {
+			if len(v.Protocols) == 0 {
+				v.Protocols = []int{v.Protocol}
+			}
+			v.Protocol = 0
+			add(UpgradeProtocols)
+		}
+		if b := imp.Banner; b != nil && len(b.Format) == 0 && b.W > 0 && b.H > 0 {
+			b.Format = []Format{{W: b.W, H: b.H}}
+			add(UpgradeBannerFormat)
+		}
+	}
+
+	if req.User != nil && req.User.Gender != "" {
+		if gender := canonicalGender(req.User.Gender); gender != req.User.Gender {
+			req.User.Gender = gender
+			add(UpgradeGender)
+		}
+	}
+
+	if req.Source != nil && upgradeSChain(req.Source) {
+		add(UpgradeSChain)
+	}
+	return rules
+}
+
+// upgradeSChain moves source.ext.schain to source.schain, reporting whether
+// it did. A chain already in source.schain wins; the ext copy is dropped
+// either way so bidders see one chain.
+func upgradeSChain(source *Source) bool {
+	if len(source.Ext) == 0 {
+		return false
+	}
+	var ext map[string]json.RawMessage
+	if err := json.Unmarshal(source.Ext, &ext); err != nil {
+		return false
+	}
+	raw, ok := ext["schain"]
+	if !ok {
+		return false
+	}
+	if source.SChain == nil {
+		var chain SupplyChain
+		if err := json.Unmarshal(raw, &chain); err != nil {
+			return false
+		}
+		source.SChain = &chain
+	}
+
+	delete(ext, "schain")
+	source.Ext = nil
+	if len(ext) > 0 {
+		if extJSON, err := json.Marshal(ext); err == nil {
+			source.Ext = extJSON
+		}
+	}
+	return true
+}
+
+// canonicalGender maps a gender to M, F or O, or "" when it is unknown
+func canonicalGender(gender string) string {
+	switch strings.ToLower(strings.TrimSpace(gender)) {
+	case "m", "male", "1":
+		return "M"
+	case "f", "female", "2":
+		return "F"
+	case "o", "other", "3":
+		return "O"
+	}
+	return ""
+}
+
+// upgradeLegacyJSON rewrites scalar protocols and numeric genders in a raw
+// request. It returns the body unchanged with no rules if none were found.
+func upgradeLegacyJSON(body []byte) ([]byte, []string) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var raw map[string]interface{}
+	if err := dec.Decode(&raw); err != nil {
+		return body, nil
+	}
+
+	var rules []string
+	protocols := false
+	imps, _ := raw["imp"].([]interface{})
+	for _, imp := range imps {
+		impObj, _ := imp.(map[string]interface{})
+		for _, media := range []string{"video", "audio"} {
+			obj, _ := impObj[media].(map[string]interface{})
+			if n, ok := obj["protocols"].(json.Number); ok {
+				obj["protocols"] = []interface{}{n}
+				protocols = true
+			}
+		}
+	}
+	if protocols {
+		rules = append(rules, UpgradeProtocols)
+	}
+
+	if user, ok := raw["user"].(map[string]interface{}); ok {
+		if n, ok := user["gender"].(json.Number); ok {
+			user["gender"] = n.String()
+			rules = append(rules, UpgradeGender)
+		}
+	}
+
+	if len(rules) == 0 {
+		return body, nil
+	}
+	upgraded, err := json.Marshal(raw)
+	if err != nil {
+		return body, nil
+	}
+	return upgraded, rules
+}
@@ -0,0 +1,147 @@
+package openrtb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseBidRequest_Legacy(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		rules []string
+		check func(t *testing.T, req *BidRequest)
+	}{
+		{
+			name:  "canonical request",
+			body:  `{"id":"r1","imp":[{"id":"1","video":{"mimes":["video/mp4"],"protocols":[2,3]},"banner":{"format":[{"w":300,"h":250}]}}],"user":{"gender":"F"}}`,
+			rules: []string{},
+			check: func(t *testing.T, req *BidRequest) {
+				if !reflect.DeepEqual(req.Imp[0].Video.Protocols, []int{2, 3}) {
+					t.Errorf("protocols changed: %v", req.Imp[0].Video.Protocols)
+				}
+			},
+		},
+		{
+			name:  "deprecated video protocol",
+			body:  `{"id":"r1","imp":[{"id":"1","video":{"mimes":["video/mp4"],"protocol":3}}]}`,
+			rules: []string{UpgradeProtocols},
+			check: func(t *testing.T, req *BidRequest) {
+				v := req.Imp[0].Video
+				if !reflect.DeepEqual(v.Protocols, []int{3}) || v.Protocol != 0 {
+					t.Errorf("expected protocols [3], got %v (protocol %d)", v.Protocols, v.Protocol)
+				}
+			},
+		},
+		{
+			name:  "scalar protocols",
+			body:  `{"id":"r1","imp":[{"id":"1","video":{"mimes":["video/mp4"],"protocols":2},"ext":{"prebid":{"bidder":{"appnexus":{"placementId":123}}}}}]}`,
+			rules: []string{UpgradeProtocols},
+			check: func(t *testing.T, req *BidRequest) {
+				if !reflect.DeepEqual(req.Imp[0].Video.Protocols, []int{2}) {
+					t.Errorf("expected protocols [2], got %v", req.Imp[0].Video.Protocols)
+				}
+				if string(req.Imp[0].Ext) != `{"prebid":{"bidder":{"appnexus":{"placementId":123}}}}` {
+					t.Errorf("imp ext not preserved: %s", req.Imp[0].Ext)
+				}
+			},
+		},
+		{
+			name:  "banner size without format",
+			body:  `{"id":"r1","imp":[{"id":"1","banner":{"w":728,"h":90}}]}`,
+			rules: []string{UpgradeBannerFormat},
+			check: func(t *testing.T, req *BidRequest) {
+				b := req.Imp[0].Banner
+				if !reflect.DeepEqual(b.Format, []Format{{W: 728, H: 90}}) || b.W != 728 || b.H != 90 {
+					t.Errorf("unexpected banner: %+v", b)
+				}
+			},
+		},
+		{
+			name:  "numeric gender",
+			body:  `{"id":"r1","imp":[{"id":"1"}],"user":{"gender":2}}`,
+			rules: []string{UpgradeGender},
+			check: func(t *testing.T, req *BidRequest) {
+				if req.User.Gender != "F" {
+					t.Errorf("expected F, got %q", req.User.Gender)
+				}
+			},
+		},
+		{
+			name:  "spelled out gender and unknown value",
+			body:  `{"id":"r1","imp":[{"id":"1","video":{"protocols":3},"banner":{"w":300,"h":250}}],"user":{"gender":"Male"}}`,
+			rules: []string{UpgradeBannerFormat, UpgradeGender, UpgradeProtocols},
+			check: func(t *testing.T, req *BidRequest) {
+				if req.User.Gender != "M" {
+					t.Errorf("expected M, got %q", req.User.Gender)
+				}
+			},
+		},
+		{
+			name:  "schain in source.ext",
+			body:  `{"id":"r1","imp":[{"id":"1"}],"source":{"tid":"t1","ext":{"schain":{"complete":1,"ver":"1.0","nodes":[{"asi":"reseller.example","sid":"42","hp":1}]},"omidpn":"acme"}}}`,
+			rules: []string{UpgradeSChain},
+			check: func(t *testing.T, req *BidRequest) {
+				chain := req.Source.SChain
+				if chain == nil || chain.Complete != 1 || len(chain.Nodes) != 1 || chain.Nodes[0].ASI != "reseller.example" {
+					t.Fatalf("expected the chain moved to source.schain, got %+v", chain)
+				}
+				if string(req.Source.Ext) != `{"omidpn":"acme"}` {
+					t.Errorf("expected only schain dropped from source.ext, got %s", req.Source.Ext)
+				}
+			},
+		},
+		{
+			name:  "schain in both places",
+			body:  `{"id":"r1","imp":[{"id":"1"}],"source":{"schain":{"complete":1,"nodes":[{"asi":"a.example","sid":"1","hp":1}]},"ext":{"schain":{"complete":0}}}}`,
+			rules: []string{UpgradeSChain},
+			check: func(t *testing.T, req *BidRequest) {
+				if req.Source.SChain.Complete != 1 || len(req.Source.SChain.Nodes) != 1 {
+					t.Errorf("expected source.schain kept, got %+v", req.Source.SChain)
+				}
+				if req.Source.Ext != nil {
+					t.Errorf("expected the ext copy dropped, got %s", req.Source.Ext)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req BidRequest
+			rules, err := ParseBidRequest([]byte(tt.body), &req)
+			if err != nil {
+				t.Fatalf("parse failed: %v", err)
+			}
+			if !reflect.DeepEqual(rules, tt.rules) {
+				t.Errorf("expected rules %v, got %v", tt.rules, rules)
+			}
+			tt.check(t, &req)
+		})
+	}
+}
+
+func TestParseBidRequest_UnknownGenderDropped(t *testing.T) {
+	var req BidRequest
+	rules, err := ParseBidRequest([]byte(`{"id":"r1","imp":[{"id":"1"}],"user":{"gender":"unknown"}}`), &req)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if req.User.Gender != "" || !reflect.DeepEqual(rules, []string{UpgradeGender}) {
+		t.Errorf("expected the gender dropped, got %q with rules %v", req.User.Gender, rules)
+	}
+}
+
+func TestParseBidRequest_Invalid(t *testing.T) {
+	tests := []string{
+		`{"id":`,
+		`{"id":"r1","imp":[{"id":1}]}`,
+		`{"id":"r1","imp":[{"id":"1","video":{"protocols":"vast"}}]}`,
+	}
+	for _, body := range tests {
+		var req BidRequest
+		if _, err := ParseBidRequest([]byte(body), &req); err == nil {
+			t.Errorf("expected an error for %s", body)
+		}
+	}
+}
@@ -90,12 +90,17 @@ type Video struct {
 	W              int             `json:"w,omitempty"`
 	H              int             `json:"h,omitempty"`
 	StartDelay     *int            `json:"startdelay,omitempty"`
-	Placement      int             `json:"placement,omitempty"`
+	Placement      int             `json:"placement,omitempty"` // Deprecated in favour of plcmt
+	Plcmt          int             `json:"plcmt,omitempty"`     // OpenRTB 2.6: 1 = instream, 2 = accompanying content, 3 = interstitial, 4 = no content
 	Linearity      int             `json:"linearity,omitempty"`
 	Skip           *int            `json:"skip,omitempty"`
 	SkipMin        int             `json:"skipmin,omitempty"`
 	SkipAfter      int             `json:"skipafter,omitempty"`
 	Sequence       int             `json:"sequence,omitempty"`
+	PodDur         int             `json:"poddur,omitempty"`    // OpenRTB 2.6: total pod seconds
+	MaxSeq         int             `json:"maxseq,omitempty"`    // OpenRTB 2.6: max ads in the pod
+	PodID          string          `json:"podid,omitempty"`     // OpenRTB 2.6: pod this slot belongs to
+	SlotInPod      int             `json:"slotinpod,omitempty"` // OpenRTB 2.6: 1 = first, -1 = last, 0 = any
 	BAttr          []int           `json:"battr,omitempty"`
 	MaxExtended    int             `json:"maxextended,omitempty"`
 	MinBitrate     int             `json:"minbitrate,omitempty"`
@@ -258,6 +263,7 @@ type Producer struct {
 // Device represents a user device
 type Device struct {
 	UA             string          `json:"ua,omitempty"`
+	SUA            *UserAgent      `json:"sua,omitempty"` // Structured user agent (OpenRTB 2.6)
 	Geo            *Geo            `json:"geo,omitempty"`
 	DNT            *int            `json:"dnt,omitempty"`
 	Lmt            *int            `json:"lmt,omitempty"`
@@ -290,6 +296,55 @@ type Device struct {
 	Ext            json.RawMessage `json:"ext,omitempty"`
 }
 
+// UserAgent source values (OpenRTB 2.6 / AdCOM)
+const (
+	UASourceUnknown     = 0
+	UASourceLowEntropy  = 1 // Sec-CH-UA, Sec-CH-UA-Mobile and Sec-CH-UA-Platform only
+	UASourceHighEntropy = 2 // includes high-entropy client hints
+	UASourceParsed      = 3 // parsed from the User-Agent string
+)
+
+// UserAgent is the structured user agent from User-Agent Client Hints
+type UserAgent struct {
+	Browsers     []BrandVersion  `json:"browsers,omitempty"`
+	Platform     *BrandVersion   `json:"platform,omitempty"`
+	Mobile       *int            `json:"mobile,omitempty"`
+	Architecture string          `json:"architecture,omitempty"`
+	Bitness      string          `json:"bitness,omitempty"`
+	Model        string          `json:"model,omitempty"`
+	Source       int             `json:"source,omitempty"`
+	Ext          json.RawMessage `json:"ext,omitempty"`
+}
+
+// BrandVersion is a browser or platform brand with version components,
+// e.g. {"brand": "Chromium", "version": ["124", "0", "6367", "91"]}
+type BrandVersion struct {
+	Brand   string          `json:"brand"`
+	Version []string        `json:"version,omitempty"`
+	Ext     json.RawMessage `json:"ext,omitempty"`
+}
+
+// LowEntropy returns a copy keeping only what browsers send without being
+// asked: brands with major versions, the platform brand and the mobile flag
+func (ua *UserAgent) LowEntropy() *UserAgent {
+	low := &UserAgent{Mobile: ua.Mobile, Source: UASourceLowEntropy}
+	for _, b := range ua.Browsers {
+		low.Browsers = append(low.Browsers, BrandVersion{Brand: b.Brand, Version: firstVersion(b.Version)})
+	}
+	if ua.Platform != nil {
+		low.Platform = &BrandVersion{Brand: ua.Platform.Brand}
+	}
+	return low
+}
+
+// firstVersion returns the major version component, if any
+func firstVersion(version []string) []string {
+	if len(version) == 0 {
+		return nil
+	}
+	return []string{version[0]}
+}
+
 // Geo represents geographic location
 type Geo struct {
 	Lat           float64         `json:"lat,omitempty"`
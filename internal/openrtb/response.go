@@ -49,6 +49,7 @@ type Bid struct {
 	WRatio         int             `json:"wratio,omitempty"`
 	HRatio         int             `json:"hratio,omitempty"`
 	Exp            int             `json:"exp,omitempty"`
+	Dur            int             `json:"dur,omitempty"` // OpenRTB 2.6: creative duration in seconds
 	Ext            json.RawMessage `json:"ext,omitempty"`
 }
 
@@ -76,6 +77,7 @@ const (
 	// Exchange-specific codes (500+)
 	NoBidNoBiddersAvailable NoBidReason = 500 // No bidders configured or available
 	NoBidTimeout            NoBidReason = 501 // Request processing timed out
+	NoBidGeoBlocked         NoBidReason = 502 // Device country not allowed for the publisher or exchange
 )
 
 // BidResponseExt represents PBS-specific response extensions
@@ -142,20 +144,23 @@ type ExtBidPrebidEvents struct {
 
 // ExtBidPrebidMeta represents bid metadata
 type ExtBidPrebidMeta struct {
-	AdvertiserID    int             `json:"advertiserId,omitempty"`
-	AdvertiserName  string          `json:"advertiserName,omitempty"`
-	AgencyID        int             `json:"agencyId,omitempty"`
-	AgencyName      string          `json:"agencyName,omitempty"`
-	BrandID         int             `json:"brandId,omitempty"`
-	BrandName       string          `json:"brandName,omitempty"`
-	DChain          json.RawMessage `json:"dchain,omitempty"`
-	DemandSource    string          `json:"demandSource,omitempty"`
-	MediaType       string          `json:"mediaType,omitempty"`
-	NetworkID       int             `json:"networkId,omitempty"`
-	NetworkName     string          `json:"networkName,omitempty"`
-	PrimaryCatID    string          `json:"primaryCatId,omitempty"`
-	SecondaryCatIDs []string        `json:"secondaryCatIds,omitempty"`
-	RendererName    string          `json:"rendererName,omitempty"`
-	RendererVersion string          `json:"rendererVersion,omitempty"`
-	RendererURL     string          `json:"rendererUrl,omitempty"`
+	AdvertiserID      int             `json:"advertiserId,omitempty"`
+	AdvertiserName    string          `json:"advertiserName,omitempty"`
+	AdvertiserDomains []string        `json:"advertiserDomains,omitempty"`
+	AgencyID          int             `json:"agencyId,omitempty"`
+	AgencyName        string          `json:"agencyName,omitempty"`
+	BrandID           int             `json:"brandId,omitempty"`
+	BrandName         string          `json:"brandName,omitempty"`
+	CampaignID        string          `json:"campaignId,omitempty"`
+	CreativeID        string          `json:"creativeId,omitempty"`
+	DChain            json.RawMessage `json:"dchain,omitempty"`
+	DemandSource      string          `json:"demandSource,omitempty"`
+	MediaType         string          `json:"mediaType,omitempty"`
+	NetworkID         int             `json:"networkId,omitempty"`
+	NetworkName       string          `json:"networkName,omitempty"`
+	PrimaryCatID      string          `json:"primaryCatId,omitempty"`
+	SecondaryCatIDs   []string        `json:"secondaryCatIds,omitempty"`
+	RendererName      string          `json:"rendererName,omitempty"`
+	RendererVersion   string          `json:"rendererVersion,omitempty"`
+	RendererURL       string          `json:"rendererUrl,omitempty"`
 }
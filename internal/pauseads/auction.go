@@ -0,0 +1,191 @@
+package pauseads
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/currency"
+	"github.com/thenexusengine/tne_springwire/internal/exchange"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/pkg/vast"
+)
+
+// pauseAdTimeout bounds the auction behind a pause ad. Playback is already
+// paused, so there is no player deadline to fit into.
+const pauseAdTimeout = 500 * time.Millisecond
+
+// linearityNonLinear is the OpenRTB video linearity of overlay ads
+const linearityNonLinear = 2
+
+// Auctioneer runs auctions; implemented by exchange.Exchange
+type Auctioneer interface {
+	RunAuction(ctx context.Context, req *exchange.AuctionRequest) (*exchange.AuctionResponse, error)
+}
+
+// ExchangeRequester requests pause ads by auctioning a non-linear video
+// impression through the exchange
+type ExchangeRequester struct {
+	auctioneer Auctioneer
+	config     PauseAdConfig
+}
+
+// NewExchangeRequester creates a requester that sizes impressions and
+// picks creatives by config
+func NewExchangeRequester(auctioneer Auctioneer, config PauseAdConfig) *ExchangeRequester {
+	return &ExchangeRequester{auctioneer: auctioneer, config: config}
+}
+
+// RequestPauseAd auctions one pause ad impression and returns the highest
+// bid with a static non-linear creative in an allowed format
+func (r *ExchangeRequester) RequestPauseAd(ctx context.Context, req *PauseAdRequest) (*PauseAdResponse, error) {
+	auctionResp, err := r.auctioneer.RunAuction(ctx, &exchange.AuctionRequest{
+		BidRequest: r.bidRequest(req),
+		Timeout:    pauseAdTimeout,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if auctionResp == nil || auctionResp.BidResponse == nil {
+		return &PauseAdResponse{NoBid: true}, nil
+	}
+
+	resp := auctionResp.BidResponse
+	var best *PauseAd
+	for i := range resp.SeatBid {
+		for j := range resp.SeatBid[i].Bid {
+			bid := &resp.SeatBid[i].Bid[j]
+			if best != nil && bid.Price <= best.Price {
+				continue
+			}
+			if ad := r.adFromBid(bid, resp.Cur); ad != nil {
+				best = ad
+			}
+		}
+	}
+	if best == nil {
+		return &PauseAdResponse{NoBid: true}, nil
+	}
+	return &PauseAdResponse{Ad: best}, nil
+}
+
+// bidRequest builds the OpenRTB request for a pause: one non-linear video
+// impression no larger than the configured creative size
+func (r *ExchangeRequester) bidRequest(req *PauseAdRequest) *openrtb.BidRequest {
+	bidReq := &openrtb.BidRequest{
+		ID: fmt.Sprintf("pause-%d", time.Now().UnixNano()),
+		Imp: []openrtb.Imp{{
+			ID: "1",
+			Video: &openrtb.Video{
+				Mimes:       r.config.Formats,
+				W:           r.config.MaxWidth,
+				H:           r.config.MaxHeight,
+				Linearity:   linearityNonLinear,
+				MaxDuration: r.config.MaxDisplayDuration,
+			},
+		}},
+		Device: req.Device,
+		User:   req.User,
+		Site:   req.Site,
+		App:    req.App,
+		TMax:   int(pauseAdTimeout / time.Millisecond),
+	}
+	if req.PublisherID != "" {
+		publisher := &openrtb.Publisher{ID: req.PublisherID}
+		switch {
+		case bidReq.App != nil:
+			app := *bidReq.App
+			app.Publisher = publisher
+			bidReq.App = &app
+		case bidReq.Site != nil:
+			site := *bidReq.Site
+			site.Publisher = publisher
+			bidReq.Site = &site
+		default:
+			bidReq.App = &openrtb.App{Publisher: publisher}
+		}
+	}
+	return bidReq
+}
+
+// adFromBid returns the pause ad in a bid's VAST markup, or nil when it has
+// no static non-linear creative in an allowed format and size
+func (r *ExchangeRequester) adFromBid(bid *openrtb.Bid, cur string) *PauseAd {
+	doc, err := vast.Parse([]byte(bid.AdM))
+	if err != nil {
+		return nil
+	}
+	for _, ad := range doc.Ads {
+		if ad.InLine == nil {
+			continue
+		}
+		for _, creative := range ad.InLine.Creatives.Creative {
+			if creative.NonLinearAds == nil {
+				continue
+			}
+			for _, nl := range creative.NonLinearAds.NonLinear {
+				if !r.allowed(nl) {
+					continue
+				}
+				return r.pauseAd(bid, cur, ad.InLine, nl)
+			}
+		}
+	}
+	return nil
+}
+
+// allowed reports whether a non-linear creative is a static image that fits
+// the configured formats and size
+func (r *ExchangeRequester) allowed(nl vast.NonLinear) bool {
+	if nl.StaticResource == nil || strings.TrimSpace(nl.StaticResource.Value) == "" {
+		return false
+	}
+	if (r.config.MaxWidth > 0 && nl.Width > r.config.MaxWidth) ||
+		(r.config.MaxHeight > 0 && nl.Height > r.config.MaxHeight) {
+		return false
+	}
+	if len(r.config.Formats) == 0 {
+		return true
+	}
+	for _, format := range r.config.Formats {
+		if format == nl.StaticResource.CreativeType {
+			return true
+		}
+	}
+	return false
+}
+
+// pauseAd converts a winning non-linear creative to a pause ad
+func (r *ExchangeRequester) pauseAd(bid *openrtb.Bid, cur string, inline *vast.InLine, nl vast.NonLinear) *PauseAd {
+	tracking := &PauseAdTracking{Click: nl.NonLinearClickTracking}
+	for _, imp := range inline.Impressions {
+		if url := strings.TrimSpace(imp.Value); url != "" {
+			tracking.Impression = append(tracking.Impression, url)
+		}
+	}
+	if bid.BURL != "" {
+		tracking.Impression = append(tracking.Impression, bid.BURL)
+	}
+
+	advertiser := inline.Advertiser
+	if advertiser == "" && len(bid.ADomain) > 0 {
+		advertiser = bid.ADomain[0]
+	}
+	if cur == "" {
+		cur = currency.DefaultCurrency
+	}
+	return &PauseAd{
+		ID:              bid.ID,
+		CreativeURL:     strings.TrimSpace(nl.StaticResource.Value),
+		ClickURL:        nl.NonLinearClickThrough,
+		Width:           nl.Width,
+		Height:          nl.Height,
+		Format:          nl.StaticResource.CreativeType,
+		DisplayDuration: r.config.MaxDisplayDuration,
+		TrackingURLs:    tracking,
+		Price:           bid.Price,
+		Currency:        cur,
+		Advertiser:      advertiser,
+	}
+}
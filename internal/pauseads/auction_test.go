@@ -0,0 +1,90 @@
+package pauseads
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/thenexusengine/tne_springwire/internal/exchange"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+// staticAuctioneer returns a canned bid response and keeps the request
+type staticAuctioneer struct {
+	resp *openrtb.BidResponse
+	err  error
+	req  *exchange.AuctionRequest
+}
+
+func (a *staticAuctioneer) RunAuction(_ context.Context, req *exchange.AuctionRequest) (*exchange.AuctionResponse, error) {
+	a.req = req
+	if a.err != nil {
+		return nil, a.err
+	}
+	return &exchange.AuctionResponse{BidResponse: a.resp}, nil
+}
+
+// nonLinearVAST returns VAST with one static non-linear creative
+func nonLinearVAST(creativeType, url string, w, h int) string {
+	return `<VAST version="4.0"><Ad id="a1"><InLine><AdSystem>test</AdSystem><AdTitle>pause</AdTitle>` +
+		`<Advertiser>Acme</Advertiser><Impression><![CDATA[https://track.example.com/imp]]></Impression>` +
+		`<Creatives><Creative><NonLinearAds><NonLinear width="` + strconv.Itoa(w) + `" height="` + strconv.Itoa(h) + `">` +
+		`<StaticResource creativeType="` + creativeType + `"><![CDATA[` + url + `]]></StaticResource>` +
+		`<NonLinearClickThrough><![CDATA[https://acme.example.com]]></NonLinearClickThrough>` +
+		`</NonLinear></NonLinearAds></Creative></Creatives></InLine></Ad></VAST>`
+}
+
+func TestExchangeRequester_RequestPauseAd(t *testing.T) {
+	auctioneer := &staticAuctioneer{resp: &openrtb.BidResponse{
+		Cur: "EUR",
+		SeatBid: []openrtb.SeatBid{{Bid: []openrtb.Bid{
+			{ID: "video", Price: 9, AdM: `<VAST version="4.0"></VAST>`},
+			{ID: "too-big", Price: 8, AdM: nonLinearVAST("image/png", "https://cdn.example.com/big.png", 3840, 2160)},
+			{ID: "pause", Price: 4, AdM: nonLinearVAST("image/png", "https://cdn.example.com/pause.png", 1280, 720), BURL: "https://bill.example.com"},
+			{ID: "cheaper", Price: 2, AdM: nonLinearVAST("image/jpeg", "https://cdn.example.com/cheap.jpg", 640, 360)},
+		}}},
+	}}
+	r := NewExchangeRequester(auctioneer, DefaultConfig())
+
+	resp, err := r.RequestPauseAd(context.Background(), &PauseAdRequest{
+		SessionID:   "s1",
+		PublisherID: "pub-1",
+		Device:      &openrtb.Device{DeviceType: 3},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ad := resp.Ad
+	if ad == nil || ad.ID != "pause" {
+		t.Fatalf("expected the highest bid with an allowed creative, got %+v", resp)
+	}
+	if ad.CreativeURL != "https://cdn.example.com/pause.png" || ad.Format != "image/png" || ad.Width != 1280 ||
+		ad.Currency != "EUR" || ad.Advertiser != "Acme" || ad.ClickURL != "https://acme.example.com" {
+		t.Errorf("unexpected ad: %+v", ad)
+	}
+	if got := ad.TrackingURLs.Impression; len(got) != 2 || got[1] != "https://bill.example.com" {
+		t.Errorf("expected VAST and billing impression trackers, got %v", got)
+	}
+
+	bidReq := auctioneer.req.BidRequest
+	if len(bidReq.Imp) != 1 || bidReq.Imp[0].Video == nil || bidReq.Imp[0].Video.Linearity != linearityNonLinear {
+		t.Errorf("expected one non-linear video imp, got %+v", bidReq.Imp)
+	}
+	if bidReq.App == nil || bidReq.App.Publisher == nil || bidReq.App.Publisher.ID != "pub-1" {
+		t.Errorf("expected the publisher on the request, got %+v", bidReq.App)
+	}
+}
+
+func TestExchangeRequester_NoAd(t *testing.T) {
+	r := NewExchangeRequester(&staticAuctioneer{resp: &openrtb.BidResponse{}}, DefaultConfig())
+	resp, err := r.RequestPauseAd(context.Background(), &PauseAdRequest{SessionID: "s1"})
+	if err != nil || !resp.NoBid {
+		t.Errorf("expected no bid, got %+v %v", resp, err)
+	}
+
+	r = NewExchangeRequester(&staticAuctioneer{err: errors.New("boom")}, DefaultConfig())
+	if _, err := r.RequestPauseAd(context.Background(), &PauseAdRequest{SessionID: "s1"}); err == nil {
+		t.Error("expected the auction error")
+	}
+}
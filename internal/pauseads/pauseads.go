@@ -10,6 +10,10 @@ import (
 	"time"
 
 	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/pkg/kv"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+	"github.com/thenexusengine/tne_springwire/pkg/lru"
+	"github.com/thenexusengine/tne_springwire/pkg/sessionhash"
 	"github.com/thenexusengine/tne_springwire/pkg/vast"
 )
 
@@ -152,8 +156,16 @@ type PauseAdService struct {
 	config      PauseAdConfig
 	adRequester AdRequester
 	tracker     *PauseAdTracker
+	sessions    *sessionhash.Hasher
+	store       kv.Store
 }
 
+// frequencyCapNamespace keys hashed session IDs in the tracker
+const frequencyCapNamespace = "pause_ad_cap"
+
+// frequencyCapKeyPrefix namespaces per-session impression times in the KV store
+const frequencyCapKeyPrefix = "pbs:pause_ad_cap:"
+
 // AdRequester is an interface for requesting ads
 type AdRequester interface {
 	RequestPauseAd(ctx context.Context, req *PauseAdRequest) (*PauseAdResponse, error)
@@ -168,6 +180,86 @@ func NewPauseAdService(config PauseAdConfig, requester AdRequester) *PauseAdServ
 	}
 }
 
+// SetSessionHasher keeps frequency caps under hashed session IDs instead of
+// the raw IDs players send. Rotating the salt starts every session's cap
+// afresh.
+func (s *PauseAdService) SetSessionHasher(h *sessionhash.Hasher) {
+	s.sessions = h
+}
+
+// SetKVStore keeps frequency caps in store, so sessions that move between
+// replicas are capped once. Without one, or when the store fails, each
+// replica caps from its own memory.
+func (s *PauseAdService) SetKVStore(store kv.Store) {
+	s.store = store
+}
+
+// canShowAd checks the session's frequency cap
+func (s *PauseAdService) canShowAd(ctx context.Context, capKey string) bool {
+	cap := s.config.FrequencyCap
+	if s.store != nil {
+		if impressions, err := s.storedImpressions(ctx, capKey); err == nil {
+			cutoff := time.Now().Add(-time.Duration(cap.TimeWindowSeconds) * time.Second)
+			return countAfter(impressions, cutoff) < cap.MaxImpressions
+		}
+	}
+	return s.tracker.CanShowAd(capKey, cap)
+}
+
+// recordImpression counts a served pause ad toward the session's cap. The
+// shared list is read and rewritten, so concurrent pauses in one session can
+// each miss the other's impression; caps are advisory, not billing.
+func (s *PauseAdService) recordImpression(ctx context.Context, capKey string) {
+	if s.store != nil {
+		now := time.Now()
+		impressions, err := s.storedImpressions(ctx, capKey)
+		if err == nil {
+			impressions = append(pruneImpressions(impressions, now.Add(-impressionWindow)), now)
+			unix := make([]int64, len(impressions))
+			for i, imp := range impressions {
+				unix[i] = imp.Unix()
+			}
+			var data []byte
+			if data, err = json.Marshal(unix); err == nil {
+				err = s.store.Set(ctx, frequencyCapKeyPrefix+capKey, string(data), impressionWindow)
+			}
+		}
+		if err == nil {
+			return
+		}
+		logger.Log.Debug().Err(err).Msg("Pause ad cap not shared, counting in memory")
+	}
+	s.tracker.RecordImpression(capKey)
+}
+
+// storedImpressions reads a session's impression times from the KV store
+func (s *PauseAdService) storedImpressions(ctx context.Context, capKey string) ([]time.Time, error) {
+	raw, err := s.store.Get(ctx, frequencyCapKeyPrefix+capKey)
+	if err != nil || raw == "" {
+		return nil, err
+	}
+	var unix []int64
+	if err := json.Unmarshal([]byte(raw), &unix); err != nil {
+		return nil, err
+	}
+	impressions := make([]time.Time, len(unix))
+	for i, sec := range unix {
+		impressions[i] = time.Unix(sec, 0)
+	}
+	return impressions, nil
+}
+
+// countAfter counts impressions after cutoff
+func countAfter(impressions []time.Time, cutoff time.Time) int {
+	count := 0
+	for _, imp := range impressions {
+		if imp.After(cutoff) {
+			count++
+		}
+	}
+	return count
+}
+
 // HandlePauseAdRequest processes a pause ad request
 func (s *PauseAdService) HandlePauseAdRequest(ctx context.Context, req *PauseAdRequest) (*PauseAdResponse, error) {
 	if !s.config.Enabled {
@@ -178,8 +270,12 @@ func (s *PauseAdService) HandlePauseAdRequest(ctx context.Context, req *PauseAdR
 	}
 
 	// Check frequency cap
+	capKey := req.SessionID
+	if s.sessions != nil {
+		capKey = s.sessions.Key(frequencyCapNamespace, req.SessionID)
+	}
 	if s.config.FrequencyCap != nil {
-		if !s.tracker.CanShowAd(req.SessionID, s.config.FrequencyCap) {
+		if !s.canShowAd(ctx, capKey) {
 			return &PauseAdResponse{
 				NoBid: true,
 				Error: "frequency cap reached",
@@ -195,7 +291,7 @@ func (s *PauseAdService) HandlePauseAdRequest(ctx context.Context, req *PauseAdR
 
 	// Track impression if ad was returned
 	if resp.Ad != nil {
-		s.tracker.RecordImpression(req.SessionID)
+		s.recordImpression(ctx, capKey)
 	}
 
 	return resp, nil
@@ -208,10 +304,20 @@ func (s *PauseAdService) Shutdown() {
 	}
 }
 
+// Bounds for tracked sessions. Session IDs come from players, so a client
+// sending random IDs evicts the least recently seen sessions instead of
+// growing the tracker.
+const (
+	trackerMaxSessions = 100000
+	trackerMaxBytes    = 32 << 20
+)
+
+// impressionWindow is how long impressions are kept for frequency capping
+const impressionWindow = 24 * time.Hour
+
 // PauseAdTracker tracks pause ad impressions for frequency capping
 type PauseAdTracker struct {
-	mu          sync.RWMutex
-	impressions map[string][]time.Time
+	impressions *lru.Cache[string, []time.Time]
 	stopCleanup chan struct{}
 	cleanupDone chan struct{}
 	shutdown    bool
@@ -221,7 +327,13 @@ type PauseAdTracker struct {
 // NewPauseAdTracker creates a new pause ad tracker
 func NewPauseAdTracker() *PauseAdTracker {
 	t := &PauseAdTracker{
-		impressions: make(map[string][]time.Time),
+		impressions: lru.New(lru.Config{
+			Name:       "pause_ad_sessions",
+			MaxEntries: trackerMaxSessions,
+			MaxBytes:   trackerMaxBytes,
+		}, func(sessionID string, imps []time.Time) int64 {
+			return int64(len(sessionID)+24*cap(imps)) + 64
+		}),
 		stopCleanup: make(chan struct{}),
 		cleanupDone: make(chan struct{}),
 	}
@@ -250,28 +362,10 @@ func (t *PauseAdTracker) periodicCleanup() {
 
 // cleanupExpiredSessions removes all expired sessions from the tracker
 func (t *PauseAdTracker) cleanupExpiredSessions() {
-	t.mu.Lock()
-	defer t.mu.Unlock()
-
-	cutoff := time.Now().Add(-24 * time.Hour)
-
-	// Iterate through all sessions and clean up expired ones
-	for sessionID := range t.impressions {
-		impressions := t.impressions[sessionID]
-		var cleaned []time.Time
-
-		for _, imp := range impressions {
-			if imp.After(cutoff) {
-				cleaned = append(cleaned, imp)
-			}
-		}
-
-		if len(cleaned) > 0 {
-			t.impressions[sessionID] = cleaned
-		} else {
-			delete(t.impressions, sessionID)
-		}
-	}
+	cutoff := time.Now().Add(-impressionWindow)
+	t.impressions.RemoveFunc(func(_ string, impressions []time.Time) bool {
+		return len(pruneImpressions(impressions, cutoff)) == 0
+	})
 }
 
 // Shutdown stops the periodic cleanup goroutine and waits for it to finish
@@ -301,54 +395,33 @@ func (t *PauseAdTracker) CanShowAd(sessionID string, cap *FrequencyCap) bool {
 	cutoff := now.Add(-time.Duration(cap.TimeWindowSeconds) * time.Second)
 
 	// Get impressions for this session
-	t.mu.RLock()
-	impressions, ok := t.impressions[sessionID]
-	t.mu.RUnlock()
+	impressions, ok := t.impressions.Get(sessionID)
 
 	if !ok {
 		return true
 	}
 
-	// Count recent impressions
-	count := 0
-	for _, imp := range impressions {
-		if imp.After(cutoff) {
-			count++
-		}
-	}
-
-	return count < cap.MaxImpressions
+	return countAfter(impressions, cutoff) < cap.MaxImpressions
 }
 
 // RecordImpression records a pause ad impression
 func (t *PauseAdTracker) RecordImpression(sessionID string) {
-	t.mu.Lock()
-	defer t.mu.Unlock()
-
-	t.impressions[sessionID] = append(t.impressions[sessionID], time.Now())
-
-	// Clean up old impressions
-	t.cleanupOldImpressionsLocked(sessionID)
+	now := time.Now()
+	t.impressions.Update(sessionID, func(impressions []time.Time, _ bool) []time.Time {
+		// Drop impressions that no longer count toward any cap
+		return append(pruneImpressions(impressions, now.Add(-impressionWindow)), now)
+	})
 }
 
-// cleanupOldImpressionsLocked removes impressions older than 24 hours
-// Caller must hold t.mu lock
-func (t *PauseAdTracker) cleanupOldImpressionsLocked(sessionID string) {
-	cutoff := time.Now().Add(-24 * time.Hour)
-	impressions := t.impressions[sessionID]
-
+// pruneImpressions returns the impressions after cutoff in a new slice
+func pruneImpressions(impressions []time.Time, cutoff time.Time) []time.Time {
 	var cleaned []time.Time
 	for _, imp := range impressions {
 		if imp.After(cutoff) {
 			cleaned = append(cleaned, imp)
 		}
 	}
-
-	if len(cleaned) > 0 {
-		t.impressions[sessionID] = cleaned
-	} else {
-		delete(t.impressions, sessionID)
-	}
+	return cleaned
 }
 
 // PauseAdHandler is an HTTP handler for pause ad requests
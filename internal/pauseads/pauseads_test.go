@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -13,6 +14,8 @@ import (
 	"time"
 
 	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/pkg/kv"
+	"github.com/thenexusengine/tne_springwire/pkg/sessionhash"
 )
 
 // MockAdRequester is a mock implementation of AdRequester for testing
@@ -127,35 +130,28 @@ func TestPauseAdTrackerPeriodicCleanup(t *testing.T) {
 	tracker.RecordImpression("session2")
 
 	// Verify they exist
-	tracker.mu.RLock()
-	if len(tracker.impressions) != 2 {
-		t.Errorf("expected 2 sessions, got %d", len(tracker.impressions))
+	if n := tracker.impressions.Len(); n != 2 {
+		t.Errorf("expected 2 sessions, got %d", n)
 	}
-	tracker.mu.RUnlock()
 
 	// Manually add an old impression
-	tracker.mu.Lock()
-	tracker.impressions["session3"] = []time.Time{
+	tracker.impressions.Set("session3", []time.Time{
 		time.Now().Add(-25 * time.Hour), // Older than 24 hours
-	}
-	tracker.mu.Unlock()
+	})
 
 	// Trigger cleanup
 	tracker.cleanupExpiredSessions()
 
 	// Old session should be removed, recent ones should remain
-	tracker.mu.RLock()
-	defer tracker.mu.RUnlock()
-
-	if _, exists := tracker.impressions["session3"]; exists {
+	if tracker.impressions.Contains("session3") {
 		t.Error("expected expired session to be removed")
 	}
 
-	if _, exists := tracker.impressions["session1"]; !exists {
+	if !tracker.impressions.Contains("session1") {
 		t.Error("expected recent session to remain")
 	}
 
-	if _, exists := tracker.impressions["session2"]; !exists {
+	if !tracker.impressions.Contains("session2") {
 		t.Error("expected recent session to remain")
 	}
 }
@@ -199,10 +195,9 @@ func TestPauseAdTrackerFrequencyCap(t *testing.T) {
 	}
 
 	// Add an old impression that's outside the time window
-	tracker.mu.Lock()
-	tracker.impressions[sessionID] = append(tracker.impressions[sessionID],
-		time.Now().Add(-2*time.Hour))
-	tracker.mu.Unlock()
+	tracker.impressions.Update(sessionID, func(impressions []time.Time, _ bool) []time.Time {
+		return append(impressions, time.Now().Add(-2*time.Hour))
+	})
 
 	// Old impression shouldn't count toward cap
 	// We have 3 recent + 1 old, cap is 3, so should still be blocked
@@ -258,30 +253,41 @@ func TestPauseAdTrackerFrequencyCapEmptySession(t *testing.T) {
 	}
 }
 
-// TestPauseAdTrackerCleanupOldImpressionsLocked verifies per-session cleanup
-func TestPauseAdTrackerCleanupOldImpressionsLocked(t *testing.T) {
-	tracker := NewPauseAdTracker()
-	defer tracker.Shutdown()
-
-	sessionID := "test-session"
-
-	// Add some recent and old impressions
-	tracker.mu.Lock()
-	tracker.impressions[sessionID] = []time.Time{
+// TestPruneImpressions verifies per-session cleanup
+func TestPruneImpressions(t *testing.T) {
+	impressions := []time.Time{
 		time.Now(),                      // Recent
 		time.Now().Add(-1 * time.Hour),  // Recent
 		time.Now().Add(-25 * time.Hour), // Old
 		time.Now().Add(-26 * time.Hour), // Old
 	}
 
-	// Cleanup this session
-	tracker.cleanupOldImpressionsLocked(sessionID)
-
 	// Should have 2 recent impressions
-	if len(tracker.impressions[sessionID]) != 2 {
-		t.Errorf("expected 2 recent impressions, got %d", len(tracker.impressions[sessionID]))
+	if cleaned := pruneImpressions(impressions, time.Now().Add(-impressionWindow)); len(cleaned) != 2 {
+		t.Errorf("expected 2 recent impressions, got %d", len(cleaned))
+	}
+}
+
+// TestPauseAdTrackerBoundedSessions verifies random session IDs evict the
+// least recently seen sessions instead of growing the tracker
+func TestPauseAdTrackerBoundedSessions(t *testing.T) {
+	tracker := NewPauseAdTracker()
+	defer tracker.Shutdown()
+
+	tracker.RecordImpression("first")
+	for i := 0; i < trackerMaxSessions; i++ {
+		tracker.RecordImpression(fmt.Sprintf("bot-%d", i))
+	}
+
+	if n := tracker.impressions.Len(); n > trackerMaxSessions {
+		t.Errorf("expected at most %d sessions, got %d", trackerMaxSessions, n)
+	}
+	if b := tracker.impressions.Bytes(); b > trackerMaxBytes {
+		t.Errorf("expected at most %d bytes, got %d", trackerMaxBytes, b)
+	}
+	if tracker.impressions.Contains("first") {
+		t.Error("expected oldest session to be evicted")
 	}
-	tracker.mu.Unlock()
 }
 
 // TestPauseAdTrackerCleanupAllOld tests cleanup when all impressions are old
@@ -292,21 +298,16 @@ func TestPauseAdTrackerCleanupAllOld(t *testing.T) {
 	sessionID := "test-session"
 
 	// Add only old impressions
-	tracker.mu.Lock()
-	tracker.impressions[sessionID] = []time.Time{
+	tracker.impressions.Set(sessionID, []time.Time{
 		time.Now().Add(-25 * time.Hour),
 		time.Now().Add(-26 * time.Hour),
-	}
-	tracker.mu.Unlock()
+	})
 
 	// Cleanup this session
 	tracker.cleanupExpiredSessions()
 
 	// Session should be deleted
-	tracker.mu.RLock()
-	defer tracker.mu.RUnlock()
-
-	if _, exists := tracker.impressions[sessionID]; exists {
+	if tracker.impressions.Contains(sessionID) {
 		t.Error("expected session with all old impressions to be deleted")
 	}
 }
@@ -356,11 +357,8 @@ func TestPauseAdTrackerConcurrentAccess(t *testing.T) {
 	wg.Wait()
 
 	// Verify tracker state is consistent
-	tracker.mu.RLock()
-	defer tracker.mu.RUnlock()
-
 	for _, sessionID := range sessions {
-		if impressions, ok := tracker.impressions[sessionID]; ok {
+		if impressions, ok := tracker.impressions.Peek(sessionID); ok {
 			if len(impressions) == 0 {
 				t.Errorf("session %s has empty impressions slice", sessionID)
 			}
@@ -467,6 +465,55 @@ func TestPauseAdServiceHandleRequestFrequencyCapReached(t *testing.T) {
 	}
 }
 
+// TestPauseAdServiceFrequencyCapHashesSessions tests caps are kept under
+// hashed session IDs
+func TestPauseAdServiceFrequencyCapHashesSessions(t *testing.T) {
+	config := DefaultConfig()
+	config.FrequencyCap = &FrequencyCap{MaxImpressions: 1, TimeWindowSeconds: 3600}
+
+	service := NewPauseAdService(config, &MockAdRequester{returnAd: true})
+	defer service.Shutdown()
+	hasher := sessionhash.New(sessionhash.Config{Salt: "test"})
+	service.SetSessionHasher(hasher)
+
+	req := &PauseAdRequest{SessionID: "raw-session", PausedAt: time.Now()}
+	if _, err := service.HandlePauseAdRequest(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, ok := service.tracker.impressions.Get("raw-session"); ok {
+		t.Error("expected the raw session ID not to be kept")
+	}
+	if service.tracker.CanShowAd(hasher.Key(frequencyCapNamespace, "raw-session"), config.FrequencyCap) {
+		t.Error("expected the impression counted under the hashed session")
+	}
+}
+
+// TestPauseAdServiceSharedFrequencyCap tests that replicas sharing a KV store
+// cap a session once
+func TestPauseAdServiceSharedFrequencyCap(t *testing.T) {
+	config := DefaultConfig()
+	config.FrequencyCap = &FrequencyCap{MaxImpressions: 1, TimeWindowSeconds: 3600}
+	store := kv.NewMemory()
+
+	first := NewPauseAdService(config, &MockAdRequester{returnAd: true})
+	defer first.Shutdown()
+	first.SetKVStore(store)
+	second := NewPauseAdService(config, &MockAdRequester{returnAd: true})
+	defer second.Shutdown()
+	second.SetKVStore(store)
+
+	req := &PauseAdRequest{SessionID: "session-1", PausedAt: time.Now()}
+	resp, err := first.HandlePauseAdRequest(context.Background(), req)
+	if err != nil || resp.Ad == nil {
+		t.Fatalf("expected an ad from the first replica, got %+v %v", resp, err)
+	}
+	resp, err = second.HandlePauseAdRequest(context.Background(), req)
+	if err != nil || !resp.NoBid || resp.Error != "frequency cap reached" {
+		t.Errorf("expected the second replica to enforce the shared cap, got %+v %v", resp, err)
+	}
+}
+
 // TestPauseAdServiceHandleRequestNoFrequencyCap tests behavior without frequency cap
 func TestPauseAdServiceHandleRequestNoFrequencyCap(t *testing.T) {
 	config := DefaultConfig()
@@ -582,9 +629,7 @@ func TestPauseAdServiceHandleRequestWithAd(t *testing.T) {
 
 	// Verify impression was recorded
 	tracker := service.tracker
-	tracker.mu.RLock()
-	impressions := tracker.impressions[req.SessionID]
-	tracker.mu.RUnlock()
+	impressions, _ := tracker.impressions.Peek(req.SessionID)
 
 	if len(impressions) != 1 {
 		t.Errorf("expected 1 impression, got %d", len(impressions))
@@ -799,15 +844,12 @@ func TestPauseAdTrackerRecordImpressionMultipleSessions(t *testing.T) {
 		}
 	}
 
-	tracker.mu.RLock()
-	defer tracker.mu.RUnlock()
-
-	if len(tracker.impressions) != len(sessions) {
-		t.Errorf("expected %d sessions, got %d", len(sessions), len(tracker.impressions))
+	if n := tracker.impressions.Len(); n != len(sessions) {
+		t.Errorf("expected %d sessions, got %d", len(sessions), n)
 	}
 
 	for _, session := range sessions {
-		if impressions, ok := tracker.impressions[session]; !ok {
+		if impressions, ok := tracker.impressions.Peek(session); !ok {
 			t.Errorf("session %s not found", session)
 		} else if len(impressions) != 3 {
 			t.Errorf("expected 3 impressions for %s, got %d", session, len(impressions))
@@ -825,21 +867,16 @@ func TestPauseAdTrackerPeriodicCleanupRuns(t *testing.T) {
 	defer tracker.Shutdown()
 
 	// Add old impressions
-	tracker.mu.Lock()
-	tracker.impressions["old-session"] = []time.Time{
+	tracker.impressions.Set("old-session", []time.Time{
 		time.Now().Add(-25 * time.Hour),
-	}
-	tracker.mu.Unlock()
+	})
 
 	// Wait a short time for cleanup to potentially run
 	// Note: Default cleanup runs every 10 minutes, so we manually trigger it
 	time.Sleep(100 * time.Millisecond)
 	tracker.cleanupExpiredSessions()
 
-	tracker.mu.RLock()
-	defer tracker.mu.RUnlock()
-
-	if _, exists := tracker.impressions["old-session"]; exists {
+	if tracker.impressions.Contains("old-session") {
 		t.Error("expected old session to be cleaned up")
 	}
 }
@@ -0,0 +1,118 @@
+// Package qpslimit shapes outbound calls to bidders whose contracts cap the
+// QPS we may send. Budgets are token buckets kept in Redis so every replica
+// draws from the same one; without Redis, while it is unreachable, or while
+// it is over its error budget, each replica keeps its own bucket.
+package qpslimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/pkg/deadline"
+	"github.com/thenexusengine/tne_springwire/pkg/kv"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+// bucketKeyPrefix namespaces per-bidder token buckets
+const bucketKeyPrefix = "tne_catalyst:bidder_qps:"
+
+// takeScript refills the bucket in KEYS[1] at ARGV[1] tokens per second up to
+// a one second burst, then takes a token if one is available. ARGV[2] is the
+// caller's clock in milliseconds. Returns 1 when a token was taken, else 0.
+const takeScript = `
+local rate = tonumber(ARGV[1])
+local now = tonumber(ARGV[2])
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(bucket[1]) or rate
+local ts = tonumber(bucket[2]) or now
+if now > ts then
+  tokens = math.min(rate, tokens + (now - ts) * rate / 1000)
+  ts = now
+end
+local taken = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  taken = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(ts))
+redis.call('PEXPIRE', KEYS[1], 2000)
+return taken
+`
+
+// Backend is the Redis subset the limiter needs; implemented by redis.Client
+type Backend interface {
+	Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error)
+}
+
+// Limiter hands out per-bidder call budgets
+type Limiter struct {
+	backend Backend // nil keeps buckets in-process only
+
+	mu    sync.Mutex
+	local map[string]*bucket
+
+	now func() time.Time
+}
+
+// bucket is an in-process token bucket, used without Redis or when a Redis
+// call fails
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// New creates a limiter; a nil backend keeps budgets per replica
+func New(backend Backend) *Limiter {
+	return &Limiter{
+		backend: backend,
+		local:   make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Allow takes one call from the bidder's budget of qps calls per second,
+// reporting whether the call may go out. A qps of zero or less is unlimited.
+// Redis errors fall back to this replica's own bucket, so an outage can't
+// stop traffic to the bidder; a degraded Redis isn't called at all.
+func (l *Limiter) Allow(ctx context.Context, bidder string, qps int) bool {
+	if qps <= 0 {
+		return true
+	}
+	now := l.now()
+	if l.backend != nil && !kv.IsDegraded(l.backend) {
+		redisCtx, cancel := deadline.WithCap(ctx, deadline.DependencyRedis)
+		result, err := l.backend.Eval(redisCtx, takeScript, []string{bucketKeyPrefix + bidder}, qps, now.UnixMilli())
+		cancel()
+		if taken, ok := result.(int64); err == nil && ok {
+			return taken == 1
+		}
+		logger.Log.Debug().
+			Err(err).
+			Str("bidder", bidder).
+			Msg("Shared QPS budget unavailable, using local bucket")
+	}
+	return l.allowLocal(bidder, qps, now)
+}
+
+// allowLocal takes a token from the in-process bucket for bidder
+func (l *Limiter) allowLocal(bidder string, qps int, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	rate := float64(qps)
+	b, ok := l.local[bidder]
+	if !ok {
+		b = &bucket{tokens: rate, last: now}
+		l.local[bidder] = b
+	}
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = min(rate, b.tokens+elapsed*rate)
+		b.last = now
+	}
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
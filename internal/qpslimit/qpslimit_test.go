@@ -0,0 +1,115 @@
+package qpslimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"github.com/thenexusengine/tne_springwire/pkg/redis"
+)
+
+func newTestBackend(t *testing.T) (*redis.Client, *miniredis.Miniredis) {
+	t.Helper()
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("failed to start miniredis: %v", err)
+	}
+	t.Cleanup(mr.Close)
+
+	client, err := redis.New("redis://" + mr.Addr())
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client, mr
+}
+
+// fixedClock freezes the limiter's clock and returns a function that advances it
+func fixedClock(l *Limiter) func(time.Duration) {
+	now := time.Unix(1760000000, 0)
+	l.now = func() time.Time { return now }
+	return func(d time.Duration) { now = now.Add(d) }
+}
+
+func allowed(l *Limiter, bidder string, qps, calls int) int {
+	n := 0
+	for i := 0; i < calls; i++ {
+		if l.Allow(context.Background(), bidder, qps) {
+			n++
+		}
+	}
+	return n
+}
+
+func TestLimiter_SharedAcrossReplicas(t *testing.T) {
+	client, _ := newTestBackend(t)
+	a, b := New(client), New(client)
+	fixedClock(a)
+	fixedClock(b)
+
+	if n := allowed(a, "bidderA", 5, 3) + allowed(b, "bidderA", 5, 3); n != 5 {
+		t.Errorf("expected replicas to share a budget of 5, got %d calls", n)
+	}
+	if n := allowed(b, "bidderB", 5, 1); n != 1 {
+		t.Errorf("expected bidders to have separate budgets, got %d calls", n)
+	}
+}
+
+func TestLimiter_Refill(t *testing.T) {
+	client, _ := newTestBackend(t)
+	l := New(client)
+	advance := fixedClock(l)
+
+	if n := allowed(l, "bidderA", 10, 20); n != 10 {
+		t.Fatalf("expected a one second burst of 10, got %d", n)
+	}
+	advance(200 * time.Millisecond)
+	if n := allowed(l, "bidderA", 10, 20); n != 2 {
+		t.Errorf("expected 2 tokens after 200ms at 10 QPS, got %d", n)
+	}
+}
+
+func TestLimiter_LocalFallback(t *testing.T) {
+	client, mr := newTestBackend(t)
+	l := New(client)
+	fixedClock(l)
+	mr.Close()
+
+	if n := allowed(l, "bidderA", 3, 5); n != 3 {
+		t.Errorf("expected the local bucket to allow 3 calls, got %d", n)
+	}
+}
+
+// degradedBackend is a backend over its error budget that counts calls
+type degradedBackend struct {
+	calls int
+}
+
+func (b *degradedBackend) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+	b.calls++
+	return int64(1), nil
+}
+
+func (b *degradedBackend) Degraded() bool { return true }
+
+func TestLimiter_SkipsDegradedBackend(t *testing.T) {
+	backend := &degradedBackend{}
+	l := New(backend)
+	fixedClock(l)
+
+	if n := allowed(l, "bidderA", 3, 5); n != 3 {
+		t.Errorf("expected the local bucket to allow 3 calls, got %d", n)
+	}
+	if backend.calls != 0 {
+		t.Errorf("expected a degraded backend not to be called, got %d calls", backend.calls)
+	}
+}
+
+func TestLimiter_Unlimited(t *testing.T) {
+	l := New(nil)
+	if n := allowed(l, "bidderA", 0, 100); n != 100 {
+		t.Errorf("expected zero QPS to be unlimited, got %d calls", n)
+	}
+}
@@ -0,0 +1,92 @@
+package rollup
+
+import (
+	"sort"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+)
+
+// maxViewersPerHour bounds the viewers tracked per hour for session counts.
+// Past it, wins for new viewers still count but not as sessions.
+const maxViewersPerHour = 1_000_000
+
+// advertiserKey identifies one advertiser frequency row
+type advertiserKey struct {
+	hour        time.Time
+	publisherID string
+	domain      string
+}
+
+// viewerKey identifies one viewer's wins for an advertiser domain
+type viewerKey struct {
+	publisherID string
+	domain      string
+	sessionKey  string
+}
+
+// RecordAdvertiserWins counts one win per domain, a domain once per winning
+// bid. sessionKey identifies the viewer, hashed by the caller; "" counts the
+// wins without a session.
+func (a *Aggregator) RecordAdvertiserWins(publisherID, sessionKey string, domains []string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	hour := a.currentHour()
+	viewers := a.viewers[hour]
+	if viewers == nil && sessionKey != "" {
+		viewers = make(map[viewerKey]int64)
+		a.viewers[hour] = viewers
+	}
+	for _, domain := range domains {
+		k := advertiserKey{hour: hour, publisherID: publisherID, domain: domain}
+		f, ok := a.advertisers[k]
+		if !ok {
+			f = &storage.AdvertiserFrequency{Hour: hour, PublisherID: publisherID, AdvertiserDomain: domain}
+			a.advertisers[k] = f
+		}
+		f.Wins++
+		if sessionKey == "" {
+			continue
+		}
+
+		vk := viewerKey{publisherID: publisherID, domain: domain, sessionKey: sessionKey}
+		n, seen := viewers[vk]
+		if !seen {
+			if len(viewers) >= maxViewersPerHour {
+				continue
+			}
+			f.Sessions++
+		}
+		n++
+		viewers[vk] = n
+		f.SessionWins++
+		if n > f.MaxSessionWins {
+			f.MaxSessionWins = n
+		}
+	}
+}
+
+// AdvertiserSnapshot returns a copy of every running advertiser count,
+// ordered by hour. Taken after Snapshot, it holds every count of the hours
+// Snapshot reported complete.
+func (a *Aggregator) AdvertiserSnapshot() []*storage.AdvertiserFrequency {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	rows := make([]*storage.AdvertiserFrequency, 0, len(a.advertisers))
+	for _, f := range a.advertisers {
+		row := *f
+		rows = append(rows, &row)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if !rows[i].Hour.Equal(rows[j].Hour) {
+			return rows[i].Hour.Before(rows[j].Hour)
+		}
+		if rows[i].PublisherID != rows[j].PublisherID {
+			return rows[i].PublisherID < rows[j].PublisherID
+		}
+		return rows[i].AdvertiserDomain < rows[j].AdvertiserDomain
+	})
+	return rows
+}
@@ -0,0 +1,181 @@
+// Package rollup aggregates business metrics per hour, publisher, bidder,
+// media type and advertiser, and how often each advertiser domain wins a
+// publisher's auctions, and persists them to Postgres for long-term
+// reporting
+package rollup
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+	"github.com/thenexusengine/tne_springwire/internal/winqueue"
+)
+
+// key identifies one rollup row. Request counts use an empty bidder, and
+// only wins and impressions know their advertiser.
+type key struct {
+	hour        time.Time
+	publisherID string
+	bidder      string
+	mediaType   string
+	advertiser  string
+}
+
+// Aggregator accumulates this instance's running totals for each hour until
+// they are flushed. It implements exchange.RollupRecorder for auction counts
+// and winning advertiser domains, and winqueue.Processor for wins and billed
+// impressions.
+type Aggregator struct {
+	mu     sync.Mutex
+	totals map[key]*storage.HourlyMetrics
+	// seen holds the notices counted per hour so redelivered events
+	// aren't counted twice
+	seen map[time.Time]map[string]struct{}
+	// advertisers counts winning advertiser domains; viewers holds each
+	// hour's wins per viewer for its session counts
+	advertisers map[advertiserKey]*storage.AdvertiserFrequency
+	viewers     map[time.Time]map[viewerKey]int64
+	now         func() time.Time
+}
+
+// NewAggregator creates an empty aggregator
+func NewAggregator() *Aggregator {
+	return &Aggregator{
+		totals: make(map[key]*storage.HourlyMetrics),
+		seen:   make(map[time.Time]map[string]struct{}),
+
+		advertisers: make(map[advertiserKey]*storage.AdvertiserFrequency),
+		viewers:     make(map[time.Time]map[viewerKey]int64),
+		now:         time.Now,
+	}
+}
+
+// currentHour must be called with mu held, so nothing is recorded into an
+// hour after Snapshot has seen it complete
+func (a *Aggregator) currentHour() time.Time {
+	return a.now().UTC().Truncate(time.Hour)
+}
+
+// row returns the totals for k, creating them; mu must be held
+func (a *Aggregator) row(k key) *storage.HourlyMetrics {
+	m, ok := a.totals[k]
+	if !ok {
+		m = &storage.HourlyMetrics{Hour: k.hour, PublisherID: k.publisherID, Bidder: k.bidder, MediaType: k.mediaType, Advertiser: k.advertiser}
+		a.totals[k] = m
+	}
+	return m
+}
+
+// RecordAuction counts one auction request and the bids each bidder returned
+func (a *Aggregator) RecordAuction(publisherID, mediaType string, bidsByBidder map[string]int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	hour := a.currentHour()
+	a.row(key{hour: hour, publisherID: publisherID, mediaType: mediaType}).Requests++
+	for bidder, n := range bidsByBidder {
+		a.row(key{hour: hour, publisherID: publisherID, bidder: bidder, mediaType: mediaType}).Bids += int64(n)
+	}
+}
+
+// Process implements winqueue.Processor. Wins book revenue and payout;
+// billing notices count rendered impressions.
+func (a *Aggregator) Process(_ context.Context, event winqueue.Event) error {
+	if event.PublisherID == "" || event.Bidder == "" {
+		return nil // notice for a bid we no longer remember
+	}
+	if event.Type != winqueue.EventWin && event.Type != winqueue.EventBilling {
+		return nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	hour := a.currentHour()
+	seen := a.seen[hour]
+	if seen == nil {
+		seen = make(map[string]struct{})
+		a.seen[hour] = seen
+	}
+	id := event.Type + ":" + event.BidID
+	if _, dup := seen[id]; dup {
+		return nil
+	}
+	seen[id] = struct{}{}
+
+	m := a.row(key{hour: hour, publisherID: event.PublisherID, bidder: event.Bidder, mediaType: event.MediaType, advertiser: event.Advertiser})
+	switch event.Type {
+	case winqueue.EventWin:
+		gross := event.GrossPrice
+		if gross == 0 {
+			gross = event.Price
+		}
+		// Prices are CPMs; each win is one impression
+		m.Wins++
+		m.Revenue += gross / 1000
+		m.Payout += event.Price / 1000
+		m.Margin = m.Revenue - m.Payout
+	case winqueue.EventBilling:
+		m.Impressions++
+	}
+	return nil
+}
+
+// Snapshot returns a copy of every running total, ordered by hour, and the
+// start of the current hour. Hours before it can no longer change.
+func (a *Aggregator) Snapshot() ([]*storage.HourlyMetrics, time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	rows := make([]*storage.HourlyMetrics, 0, len(a.totals))
+	for _, m := range a.totals {
+		row := *m
+		rows = append(rows, &row)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if !rows[i].Hour.Equal(rows[j].Hour) {
+			return rows[i].Hour.Before(rows[j].Hour)
+		}
+		if rows[i].PublisherID != rows[j].PublisherID {
+			return rows[i].PublisherID < rows[j].PublisherID
+		}
+		if rows[i].Bidder != rows[j].Bidder {
+			return rows[i].Bidder < rows[j].Bidder
+		}
+		if rows[i].MediaType != rows[j].MediaType {
+			return rows[i].MediaType < rows[j].MediaType
+		}
+		return rows[i].Advertiser < rows[j].Advertiser
+	})
+	return rows, a.currentHour()
+}
+
+// Prune drops hours before cutoff once they have been persisted
+func (a *Aggregator) Prune(cutoff time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for k := range a.totals {
+		if k.hour.Before(cutoff) {
+			delete(a.totals, k)
+		}
+	}
+	for hour := range a.seen {
+		if hour.Before(cutoff) {
+			delete(a.seen, hour)
+		}
+	}
+	for k := range a.advertisers {
+		if k.hour.Before(cutoff) {
+			delete(a.advertisers, k)
+		}
+	}
+	for hour := range a.viewers {
+		if hour.Before(cutoff) {
+			delete(a.viewers, hour)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package rollup
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+const (
+	// DefaultInterval flushes running totals hourly
+	DefaultInterval = time.Hour
+	// DefaultRetentionMonths keeps 13 months so finance can compare a month
+	// with the same month a year earlier
+	DefaultRetentionMonths = 13
+)
+
+// Store persists rollup rows; implemented by storage.RollupStore
+type Store interface {
+	UpsertHourly(ctx context.Context, instanceID string, rows []*storage.HourlyMetrics) error
+	DeleteHourlyBefore(ctx context.Context, cutoff time.Time) (int64, error)
+	UpsertAdvertiserFrequency(ctx context.Context, instanceID string, rows []*storage.AdvertiserFrequency) error
+	DeleteAdvertiserFrequencyBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// Config controls how often totals are flushed and how long they are kept
+type Config struct {
+	Interval        time.Duration // 0 uses DefaultInterval
+	RetentionMonths int           // 0 uses DefaultRetentionMonths
+}
+
+// Job periodically writes an Aggregator's totals to the store
+type Job struct {
+	agg        *Aggregator
+	store      Store
+	cfg        Config
+	instanceID string
+	now        func() time.Time
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewJob creates a flush job. Each process gets its own instance ID, so a
+// restarted instance starts new rows instead of overwriting the totals its
+// previous run wrote for the current hour.
+func NewJob(agg *Aggregator, store Store, cfg Config) *Job {
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultInterval
+	}
+	if cfg.RetentionMonths <= 0 {
+		cfg.RetentionMonths = DefaultRetentionMonths
+	}
+	return &Job{
+		agg:        agg,
+		store:      store,
+		cfg:        cfg,
+		instanceID: newInstanceID(),
+		now:        time.Now,
+		stopCh:     make(chan struct{}),
+	}
+}
+
+// newInstanceID returns the hostname with a random suffix
+func newInstanceID() string {
+	host, _ := os.Hostname()
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	id := host + "-" + hex.EncodeToString(b)
+	if len(id) > 100 {
+		id = id[len(id)-100:]
+	}
+	return id
+}
+
+// Start flushes every interval until Stop
+func (j *Job) Start() {
+	j.wg.Add(1)
+	go func() {
+		defer j.wg.Done()
+		ticker := time.NewTicker(j.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-j.stopCh:
+				return
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+				if err := j.Flush(ctx); err != nil {
+					logger.Log.Warn().Err(err).Msg("Metrics rollup flush failed, will retry next interval")
+				}
+				cancel()
+			}
+		}
+	}()
+}
+
+// Stop ends periodic flushes and writes the final totals
+func (j *Job) Stop(ctx context.Context) error {
+	j.stopOnce.Do(func() { close(j.stopCh) })
+	j.wg.Wait()
+	return j.Flush(ctx)
+}
+
+// Flush writes the running totals and, once written, forgets completed hours
+// and deletes rows past the retention period. Totals are kept in memory if
+// the write fails, so the next flush retries them.
+func (j *Job) Flush(ctx context.Context) error {
+	rows, currentHour := j.agg.Snapshot()
+	advertisers := j.agg.AdvertiserSnapshot()
+	if err := j.store.UpsertHourly(ctx, j.instanceID, rows); err != nil {
+		return err
+	}
+	if err := j.store.UpsertAdvertiserFrequency(ctx, j.instanceID, advertisers); err != nil {
+		return err
+	}
+	j.agg.Prune(currentHour)
+
+	cutoff := j.now().UTC().AddDate(0, -j.cfg.RetentionMonths, 0).Truncate(time.Hour)
+	deleted, err := j.store.DeleteHourlyBefore(ctx, cutoff)
+	if err != nil {
+		return fmt.Errorf("failed to apply rollup retention: %w", err)
+	}
+	expiredAdvertisers, err := j.store.DeleteAdvertiserFrequencyBefore(ctx, cutoff)
+	if err != nil {
+		return fmt.Errorf("failed to apply advertiser frequency retention: %w", err)
+	}
+
+	logger.Log.Debug().
+		Int("rows", len(rows)).
+		Int("advertiser_rows", len(advertisers)).
+		Int64("expired", deleted+expiredAdvertisers).
+		Str("instance_id", j.instanceID).
+		Msg("Metrics rollup flushed")
+	return nil
+}
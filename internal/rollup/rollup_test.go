@@ -0,0 +1,213 @@
+package rollup
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+	"github.com/thenexusengine/tne_springwire/internal/winqueue"
+)
+
+type fakeStore struct {
+	upserts     [][]*storage.HourlyMetrics
+	advertisers [][]*storage.AdvertiserFrequency
+	cutoff      time.Time
+	err         error
+}
+
+func (f *fakeStore) UpsertHourly(_ context.Context, _ string, rows []*storage.HourlyMetrics) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.upserts = append(f.upserts, rows)
+	return nil
+}
+
+func (f *fakeStore) DeleteHourlyBefore(_ context.Context, cutoff time.Time) (int64, error) {
+	f.cutoff = cutoff
+	return 0, nil
+}
+
+func (f *fakeStore) UpsertAdvertiserFrequency(_ context.Context, _ string, rows []*storage.AdvertiserFrequency) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.advertisers = append(f.advertisers, rows)
+	return nil
+}
+
+func (f *fakeStore) DeleteAdvertiserFrequencyBefore(_ context.Context, cutoff time.Time) (int64, error) {
+	return 0, nil
+}
+
+func newTestAggregator(now *time.Time) *Aggregator {
+	a := NewAggregator()
+	a.now = func() time.Time { return *now }
+	return a
+}
+
+func findRow(rows []*storage.HourlyMetrics, bidder string) *storage.HourlyMetrics {
+	for _, r := range rows {
+		if r.Bidder == bidder {
+			return r
+		}
+	}
+	return nil
+}
+
+func TestAggregator_RecordsAuctionsAndWins(t *testing.T) {
+	now := time.Date(2026, 3, 1, 10, 15, 0, 0, time.UTC)
+	a := newTestAggregator(&now)
+
+	a.RecordAuction("pub-1", "video", map[string]int{"appnexus": 2})
+	a.RecordAuction("pub-1", "video", nil)
+
+	win := winqueue.Event{Type: winqueue.EventWin, BidID: "b1", Bidder: "appnexus", PublisherID: "pub-1", MediaType: "video", Price: 4, GrossPrice: 5}
+	_ = a.Process(context.Background(), win)
+	_ = a.Process(context.Background(), win) // redelivered
+	_ = a.Process(context.Background(), winqueue.Event{Type: winqueue.EventBilling, BidID: "b1", Bidder: "appnexus", PublisherID: "pub-1", MediaType: "video", Price: 4})
+	_ = a.Process(context.Background(), winqueue.Event{Type: winqueue.EventWin, BidID: "b2"}) // unknown bid
+
+	rows, current := a.Snapshot()
+	if !current.Equal(time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected current hour 10:00, got %v", current)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("expected request and bidder rows, got %d", len(rows))
+	}
+
+	requests := findRow(rows, "")
+	if requests == nil || requests.Requests != 2 || requests.MediaType != "video" {
+		t.Errorf("unexpected request row: %+v", requests)
+	}
+	bidder := findRow(rows, "appnexus")
+	if bidder == nil || bidder.Bids != 2 || bidder.Wins != 1 || bidder.Impressions != 1 {
+		t.Fatalf("unexpected bidder row: %+v", bidder)
+	}
+	if math.Abs(bidder.Revenue-0.005) > 1e-9 || math.Abs(bidder.Payout-0.004) > 1e-9 || math.Abs(bidder.Margin-0.001) > 1e-9 {
+		t.Errorf("expected per-impression revenue 0.005, payout 0.004, margin 0.001, got %+v", bidder)
+	}
+}
+
+func TestAggregator_WinsByAdvertiser(t *testing.T) {
+	now := time.Date(2026, 3, 1, 10, 15, 0, 0, time.UTC)
+	a := newTestAggregator(&now)
+
+	_ = a.Process(context.Background(), winqueue.Event{Type: winqueue.EventWin, BidID: "b1", Bidder: "appnexus", PublisherID: "pub-1", MediaType: "video", Advertiser: "Acme Motors", Price: 4})
+	_ = a.Process(context.Background(), winqueue.Event{Type: winqueue.EventBilling, BidID: "b1", Bidder: "appnexus", PublisherID: "pub-1", MediaType: "video", Advertiser: "Acme Motors", Price: 4})
+	_ = a.Process(context.Background(), winqueue.Event{Type: winqueue.EventWin, BidID: "b2", Bidder: "appnexus", PublisherID: "pub-1", MediaType: "video", Advertiser: "soda.example", Price: 2})
+
+	rows, _ := a.Snapshot()
+	if len(rows) != 2 {
+		t.Fatalf("expected one row per advertiser, got %d", len(rows))
+	}
+	if rows[0].Advertiser != "Acme Motors" || rows[0].Wins != 1 || rows[0].Impressions != 1 {
+		t.Errorf("unexpected Acme row: %+v", rows[0])
+	}
+	if rows[1].Advertiser != "soda.example" || rows[1].Wins != 1 {
+		t.Errorf("unexpected soda row: %+v", rows[1])
+	}
+}
+
+func TestJob_FlushPrunesCompletedHours(t *testing.T) {
+	now := time.Date(2026, 3, 1, 10, 59, 0, 0, time.UTC)
+	a := newTestAggregator(&now)
+	store := &fakeStore{}
+	j := NewJob(a, store, Config{})
+	j.now = func() time.Time { return now }
+
+	a.RecordAuction("pub-1", "banner", nil)
+	now = now.Add(2 * time.Minute)
+	a.RecordAuction("pub-1", "banner", nil)
+
+	if err := j.Flush(context.Background()); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+	if len(store.upserts) != 1 || len(store.upserts[0]) != 2 {
+		t.Fatalf("expected both hours written, got %+v", store.upserts)
+	}
+	if want := time.Date(2025, 2, 1, 11, 0, 0, 0, time.UTC); !store.cutoff.Equal(want) {
+		t.Errorf("expected 13-month retention cutoff %v, got %v", want, store.cutoff)
+	}
+
+	// Only the open hour is kept and rewritten with its running total
+	a.RecordAuction("pub-1", "banner", nil)
+	if err := j.Flush(context.Background()); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+	rows := store.upserts[1]
+	if len(rows) != 1 || rows[0].Hour.Hour() != 11 || rows[0].Requests != 2 {
+		t.Errorf("expected running total for 11:00 only, got %+v", rows)
+	}
+}
+
+func TestJob_FlushFailureKeepsTotals(t *testing.T) {
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	a := newTestAggregator(&now)
+	store := &fakeStore{err: errors.New("connection refused")}
+	j := NewJob(a, store, Config{})
+
+	a.RecordAuction("pub-1", "banner", nil)
+	now = now.Add(time.Hour)
+	if err := j.Flush(context.Background()); err == nil {
+		t.Fatal("expected flush error")
+	}
+
+	store.err = nil
+	if err := j.Stop(context.Background()); err != nil {
+		t.Fatalf("final flush failed: %v", err)
+	}
+	if len(store.upserts) != 1 || len(store.upserts[0]) != 1 || store.upserts[0][0].Requests != 1 {
+		t.Errorf("expected failed totals written on retry, got %+v", store.upserts)
+	}
+}
+
+func TestAggregator_AdvertiserFrequency(t *testing.T) {
+	now := time.Date(2026, 3, 1, 10, 15, 0, 0, time.UTC)
+	a := newTestAggregator(&now)
+
+	a.RecordAdvertiserWins("pub-1", "viewer-a", []string{"acme.example", "soda.example"})
+	a.RecordAdvertiserWins("pub-1", "viewer-a", []string{"acme.example"})
+	a.RecordAdvertiserWins("pub-1", "viewer-a", []string{"acme.example"})
+	a.RecordAdvertiserWins("pub-1", "viewer-b", []string{"acme.example"})
+	a.RecordAdvertiserWins("pub-1", "", []string{"acme.example"})
+
+	rows := a.AdvertiserSnapshot()
+	if len(rows) != 2 {
+		t.Fatalf("expected one row per domain, got %d", len(rows))
+	}
+	acme := rows[0]
+	if acme.AdvertiserDomain != "acme.example" || acme.Wins != 5 || acme.Sessions != 2 || acme.SessionWins != 4 || acme.MaxSessionWins != 3 {
+		t.Errorf("unexpected acme row: %+v", acme)
+	}
+	if soda := rows[1]; soda.Wins != 1 || soda.Sessions != 1 || soda.MaxSessionWins != 1 {
+		t.Errorf("unexpected soda row: %+v", soda)
+	}
+
+	// A new hour counts viewers afresh
+	now = now.Add(time.Hour)
+	a.RecordAdvertiserWins("pub-1", "viewer-a", []string{"acme.example"})
+	a.Prune(now.Truncate(time.Hour))
+	rows = a.AdvertiserSnapshot()
+	if len(rows) != 1 || rows[0].Sessions != 1 || rows[0].MaxSessionWins != 1 {
+		t.Errorf("expected only the 11:00 row, got %+v", rows)
+	}
+}
+
+func TestJob_FlushWritesAdvertisers(t *testing.T) {
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	a := newTestAggregator(&now)
+	store := &fakeStore{}
+	j := NewJob(a, store, Config{})
+
+	a.RecordAdvertiserWins("pub-1", "viewer-a", []string{"acme.example"})
+	if err := j.Flush(context.Background()); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+	if len(store.advertisers) != 1 || len(store.advertisers[0]) != 1 || store.advertisers[0][0].Wins != 1 {
+		t.Errorf("expected the advertiser counts written, got %+v", store.advertisers)
+	}
+}
@@ -0,0 +1,446 @@
+// Package scrub produces privacy-safe projections of bid requests for logs,
+// debug captures and anything else that persists a request outside the
+// auction path. User and device identifiers are hashed, IPs truncated,
+// consent strings and precise location removed.
+//
+// Types that can carry personal data are scrubbed field by field according
+// to fieldActions. A field missing from the table is dropped, so a new field
+// added to the OpenRTB model never reaches a log until it is classified.
+package scrub
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"net/url"
+	"os"
+	"reflect"
+	"sync"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+// maxUALength is how much of a user agent survives scrubbing
+const maxUALength = 50
+
+// hashLength is the number of hex characters kept from a hashed ID
+const hashLength = 16
+
+// action says what happens to one field of a scrubbed type
+type action int
+
+const (
+	// drop zeroes the field
+	drop action = iota
+	// keep copies the field, scrubbing nested structs
+	keep
+	// hashID replaces a string identifier with a salted hash
+	hashID
+	// truncateIP masks the host part of an IP address
+	truncateIP
+	// truncateUA shortens a user agent
+	truncateUA
+	// stripQuery removes the query string and fragment from a URL
+	stripQuery
+	// filterRegsExt keeps only the regs.ext keys in regsExtKeys
+	filterRegsExt
+)
+
+// safeTypes describe inventory rather than people and are copied as-is
+var safeTypes = map[string]bool{
+	"Imp":             true,
+	"Metric":          true,
+	"Banner":          true,
+	"Format":          true,
+	"Video":           true,
+	"Audio":           true,
+	"Native":          true,
+	"PMP":             true,
+	"Deal":            true,
+	"Publisher":       true,
+	"Producer":        true,
+	"SupplyChain":     true,
+	"SupplyChainNode": true,
+	"BrandVersion":    true,
+}
+
+// fieldActions classifies every field of the types that can identify a user,
+// keyed by "Type.Field"
+var fieldActions = map[string]action{
+	"BidRequest.ID":     keep,
+	"BidRequest.Imp":    keep,
+	"BidRequest.Site":   keep,
+	"BidRequest.App":    keep,
+	"BidRequest.Device": keep,
+	"BidRequest.User":   keep,
+	"BidRequest.Test":   keep,
+	"BidRequest.AT":     keep,
+	"BidRequest.TMax":   keep,
+	"BidRequest.WSeat":  keep,
+	"BidRequest.BSeat":  keep,
+	"BidRequest.AllImp": keep,
+	"BidRequest.Cur":    keep,
+	"BidRequest.WLang":  keep,
+	"BidRequest.BCat":   keep,
+	"BidRequest.BAdv":   keep,
+	"BidRequest.BApp":   keep,
+	"BidRequest.Source": keep,
+	"BidRequest.Regs":   keep,
+	"BidRequest.Ext":    keep,
+
+	"Site.ID":            keep,
+	"Site.Name":          keep,
+	"Site.Domain":        keep,
+	"Site.Cat":           keep,
+	"Site.SectionCat":    keep,
+	"Site.PageCat":       keep,
+	"Site.Page":          stripQuery,
+	"Site.Ref":           stripQuery,
+	"Site.Search":        drop,
+	"Site.Mobile":        keep,
+	"Site.PrivacyPolicy": keep,
+	"Site.Publisher":     keep,
+	"Site.Content":       keep,
+	"Site.Keywords":      keep,
+	"Site.Ext":           keep,
+
+	"App.ID":            keep,
+	"App.Name":          keep,
+	"App.Bundle":        keep,
+	"App.Domain":        keep,
+	"App.StoreURL":      keep,
+	"App.Cat":           keep,
+	"App.SectionCat":    keep,
+	"App.PageCat":       keep,
+	"App.Ver":           keep,
+	"App.PrivacyPolicy": keep,
+	"App.Paid":          keep,
+	"App.Publisher":     keep,
+	"App.Content":       keep,
+	"App.Keywords":      keep,
+	"App.Ext":           keep,
+
+	"Content.ID":                 keep,
+	"Content.Episode":            keep,
+	"Content.Title":              keep,
+	"Content.Series":             keep,
+	"Content.Season":             keep,
+	"Content.Artist":             keep,
+	"Content.Genre":              keep,
+	"Content.Album":              keep,
+	"Content.ISRC":               keep,
+	"Content.Producer":           keep,
+	"Content.URL":                stripQuery,
+	"Content.Cat":                keep,
+	"Content.ProdQ":              keep,
+	"Content.VideoQuality":       keep,
+	"Content.Context":            keep,
+	"Content.ContentRating":      keep,
+	"Content.UserRating":         keep,
+	"Content.QAGMediaRating":     keep,
+	"Content.Keywords":           keep,
+	"Content.LiveStream":         keep,
+	"Content.SourceRelationship": keep,
+	"Content.Len":                keep,
+	"Content.Language":           keep,
+	"Content.Embeddable":         keep,
+	"Content.Data":               keep,
+	"Content.Ext":                keep,
+
+	"Device.UA":             truncateUA,
+	"Device.SUA":            keep,
+	"Device.Geo":            keep,
+	"Device.DNT":            keep,
+	"Device.Lmt":            keep,
+	"Device.IP":             truncateIP,
+	"Device.IPv6":           truncateIP,
+	"Device.DeviceType":     keep,
+	"Device.Make":           keep,
+	"Device.Model":          keep,
+	"Device.OS":             keep,
+	"Device.OSV":            keep,
+	"Device.HWV":            keep,
+	"Device.H":              keep,
+	"Device.W":              keep,
+	"Device.PPI":            keep,
+	"Device.PxRatio":        keep,
+	"Device.JS":             keep,
+	"Device.GeoFetch":       keep,
+	"Device.FlashVer":       keep,
+	"Device.Language":       keep,
+	"Device.Carrier":        keep,
+	"Device.MCCMNC":         keep,
+	"Device.ConnectionType": keep,
+	"Device.IFA":            hashID,
+	"Device.IDSHA1":         hashID,
+	"Device.IDMD5":          hashID,
+	"Device.DPIDSHA1":       hashID,
+	"Device.DPIDMD5":        hashID,
+	"Device.MacSHA1":        hashID,
+	"Device.MacMD5":         hashID,
+	"Device.Ext":            drop,
+
+	"UserAgent.Browsers":     keep,
+	"UserAgent.Platform":     keep,
+	"UserAgent.Mobile":       keep,
+	"UserAgent.Architecture": keep,
+	"UserAgent.Bitness":      keep,
+	"UserAgent.Model":        keep,
+	"UserAgent.Source":       keep,
+	"UserAgent.Ext":          drop,
+
+	"Geo.Lat":           drop,
+	"Geo.Lon":           drop,
+	"Geo.Type":          keep,
+	"Geo.Accuracy":      keep,
+	"Geo.LastFix":       keep,
+	"Geo.IPService":     keep,
+	"Geo.Country":       keep,
+	"Geo.Region":        keep,
+	"Geo.RegionFIPS104": keep,
+	"Geo.Metro":         keep,
+	"Geo.City":          drop,
+	"Geo.ZIP":           drop,
+	"Geo.UTCOffset":     keep,
+	"Geo.Ext":           drop,
+
+	"User.ID":         hashID,
+	"User.BuyerUID":   hashID,
+	"User.YOB":        drop,
+	"User.Gender":     drop,
+	"User.Keywords":   drop,
+	"User.CustomData": drop,
+	"User.Geo":        keep,
+	"User.Data":       keep,
+	"User.Consent":    drop,
+	"User.EIDs":       keep,
+	"User.Ext":        drop,
+
+	"Data.ID":      keep,
+	"Data.Name":    keep,
+	"Data.Segment": keep,
+	"Data.Ext":     keep,
+
+	"Segment.ID":    keep,
+	"Segment.Name":  keep,
+	"Segment.Value": drop,
+	"Segment.Ext":   keep,
+
+	"EID.Source": keep,
+	"EID.UIDs":   keep,
+	"EID.Ext":    keep,
+
+	"UID.ID":    hashID,
+	"UID.AType": keep,
+	"UID.Ext":   keep,
+
+	"Source.FD":     keep,
+	"Source.TID":    keep,
+	"Source.PChain": keep,
+	"Source.SChain": keep,
+	"Source.Ext":    keep,
+
+	"Regs.COPPA":     keep,
+	"Regs.GDPR":      keep,
+	"Regs.USPrivacy": keep,
+	"Regs.GPP":       drop,
+	"Regs.GPPSID":    keep,
+	"Regs.Ext":       filterRegsExt,
+}
+
+// regsExtKeys are the regs.ext keys kept in a scrubbed request. Extension
+// copies of regs fields are treated like the fields themselves, so the GPP
+// string is dropped here too; unknown keys are dropped.
+var regsExtKeys = map[string]bool{
+	"coppa":      true,
+	"gdpr":       true,
+	"us_privacy": true,
+	"gpp_sid":    true,
+	"dsa":        true,
+}
+
+var (
+	saltMu sync.RWMutex
+	salt   = initialSalt()
+)
+
+// initialSalt reads LOG_SCRUB_SALT so hashed IDs correlate across instances,
+// falling back to a random per-process salt
+func initialSalt() []byte {
+	if s := os.Getenv("LOG_SCRUB_SALT"); s != "" {
+		return []byte(s)
+	}
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return b
+}
+
+// SetSalt replaces the salt used when hashing identifiers
+func SetSalt(s string) {
+	saltMu.Lock()
+	salt = []byte(s)
+	saltMu.Unlock()
+}
+
+// Request returns a scrubbed deep copy of req. The original is not modified.
+func Request(req *openrtb.BidRequest) *openrtb.BidRequest {
+	if req == nil {
+		return nil
+	}
+	// A JSON round trip gives a deep copy that shares no slices or ext bytes
+	raw, err := json.Marshal(req)
+	if err != nil {
+		return &openrtb.BidRequest{ID: req.ID}
+	}
+	var out openrtb.BidRequest
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return &openrtb.BidRequest{ID: req.ID}
+	}
+	scrubValue(reflect.ValueOf(&out).Elem())
+	return &out
+}
+
+// JSON returns the scrubbed request encoded for structured logging
+func JSON(req *openrtb.BidRequest) json.RawMessage {
+	raw, err := json.Marshal(Request(req))
+	if err != nil {
+		return json.RawMessage("null")
+	}
+	return raw
+}
+
+// ID returns a salted, truncated SHA-256 of an identifier. Equal inputs hash
+// equally under the same salt so requests can still be correlated.
+func ID(id string) string {
+	if id == "" {
+		return ""
+	}
+	saltMu.RLock()
+	h := sha256.New()
+	h.Write(salt)
+	saltMu.RUnlock()
+	h.Write([]byte(id))
+	return hex.EncodeToString(h.Sum(nil))[:hashLength]
+}
+
+// IP masks an address for logging: IPv4 keeps the first 24 bits and IPv6 the
+// first 48. Unparseable input returns "".
+func IP(ipStr string) string {
+	ip := net.ParseIP(ipStr)
+	if ip == nil {
+		return ""
+	}
+	if ipv4 := ip.To4(); ipv4 != nil {
+		return net.IPv4(ipv4[0], ipv4[1], ipv4[2], 0).String()
+	}
+	masked := make(net.IP, net.IPv6len)
+	copy(masked, ip.To16()[:6])
+	return masked.String()
+}
+
+// UserAgent shortens a user agent to limit fingerprinting while keeping the
+// browser family readable
+func UserAgent(ua string) string {
+	if len(ua) > maxUALength {
+		return ua[:maxUALength] + "..."
+	}
+	return ua
+}
+
+// URL removes the query string, fragment and userinfo, which are where
+// emails and session tokens end up. Unparseable input returns "".
+func URL(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	u.RawQuery = ""
+	u.Fragment = ""
+	u.User = nil
+	return u.String()
+}
+
+// scrubValue walks pointers and slices down to structs
+func scrubValue(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			scrubValue(v.Elem())
+		}
+	case reflect.Slice:
+		if k := v.Type().Elem().Kind(); k == reflect.Struct || k == reflect.Ptr {
+			for i := 0; i < v.Len(); i++ {
+				scrubValue(v.Index(i))
+			}
+		}
+	case reflect.Struct:
+		scrubStruct(v)
+	}
+}
+
+// scrubStruct applies fieldActions to each field of a struct
+func scrubStruct(v reflect.Value) {
+	t := v.Type()
+	if safeTypes[t.Name()] {
+		return
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := v.Field(i)
+		if !field.CanSet() {
+			continue
+		}
+		act := fieldActions[t.Name()+"."+t.Field(i).Name]
+
+		if act == keep {
+			scrubValue(field)
+			continue
+		}
+		if act == filterRegsExt {
+			if ext, ok := field.Interface().(json.RawMessage); ok {
+				field.Set(reflect.ValueOf(regsExt(ext)))
+				continue
+			}
+		}
+		if act == drop || field.Kind() != reflect.String {
+			field.Set(reflect.Zero(field.Type()))
+			continue
+		}
+		switch act {
+		case hashID:
+			field.SetString(ID(field.String()))
+		case truncateIP:
+			field.SetString(IP(field.String()))
+		case truncateUA:
+			field.SetString(UserAgent(field.String()))
+		case stripQuery:
+			field.SetString(URL(field.String()))
+		}
+	}
+}
+
+// regsExt returns the regs.ext keys listed in regsExtKeys, or nil when none
+// are left or ext isn't a JSON object
+func regsExt(ext json.RawMessage) json.RawMessage {
+	var fields map[string]json.RawMessage
+	if len(ext) == 0 || json.Unmarshal(ext, &fields) != nil {
+		return nil
+	}
+	for key := range fields {
+		if !regsExtKeys[key] {
+			delete(fields, key)
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	out, err := json.Marshal(fields)
+	if err != nil {
+		return nil
+	}
+	return out
+}
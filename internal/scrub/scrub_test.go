@@ -0,0 +1,251 @@
+package scrub
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+// fieldOverrides give realistic values where a placeholder wouldn't exercise
+// the scrubbing action
+var fieldOverrides = map[string]string{
+	"Device.IP":   "203.0.113.77",
+	"Device.IPv6": "2001:db8:85a3:1234::8a2e:370:7334",
+	"Device.UA":   "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Safari/537.36",
+	"Site.Page":   "https://news.example.com/article?email=jane@example.com#top",
+	"Site.Ref":    "https://search.example.org/?q=jane+doe",
+	"Content.URL": "https://video.example.com/watch?session=abc123",
+}
+
+// fill populates every field reachable from v so the golden output shows
+// what happens to each one, including fields added to the model later
+func fill(v reflect.Value, path string) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		v.Set(reflect.New(v.Type().Elem()))
+		fill(v.Elem(), path)
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).IsExported() {
+				fill(v.Field(i), t.Name()+"."+t.Field(i).Name)
+			}
+		}
+	case reflect.Slice:
+		if v.Type() == reflect.TypeOf(json.RawMessage(nil)) {
+			v.SetBytes([]byte(`{"field":"` + path + `"}`))
+			return
+		}
+		s := reflect.MakeSlice(v.Type(), 1, 1)
+		fill(s.Index(0), path)
+		v.Set(s)
+	case reflect.String:
+		if o, ok := fieldOverrides[path]; ok {
+			v.SetString(o)
+		} else {
+			v.SetString(path)
+		}
+	case reflect.Int, reflect.Int64:
+		v.SetInt(1)
+	case reflect.Float64:
+		v.SetFloat(1.5)
+	}
+}
+
+func fullRequest() *openrtb.BidRequest {
+	req := &openrtb.BidRequest{}
+	fill(reflect.ValueOf(req).Elem(), "BidRequest")
+	return req
+}
+
+// TestFieldsClassified fails when the OpenRTB model gains a field on a type
+// that can carry personal data without a scrubbing decision for it
+func TestFieldsClassified(t *testing.T) {
+	seen := map[reflect.Type]bool{}
+	var walk func(t reflect.Type)
+	walk = func(typ reflect.Type) {
+		for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice {
+			typ = typ.Elem()
+		}
+		if typ.Kind() != reflect.Struct || seen[typ] || safeTypes[typ.Name()] {
+			return
+		}
+		seen[typ] = true
+		for i := 0; i < typ.NumField(); i++ {
+			f := typ.Field(i)
+			key := typ.Name() + "." + f.Name
+			act, ok := fieldActions[key]
+			if !ok {
+				t.Errorf("%s has no scrub action; classify it in fieldActions", key)
+				continue
+			}
+			switch {
+			case act == filterRegsExt:
+				if f.Type != reflect.TypeOf(json.RawMessage(nil)) {
+					t.Errorf("%s: ext action on %s field", key, f.Type)
+				}
+			case act != keep && act != drop && f.Type.Kind() != reflect.String:
+				t.Errorf("%s: string action on %s field", key, f.Type)
+			}
+			walk(f.Type)
+		}
+	}
+	walk(reflect.TypeOf(openrtb.BidRequest{}))
+
+	for key := range fieldActions {
+		typeName, fieldName, _ := strings.Cut(key, ".")
+		found := false
+		for typ := range seen {
+			if typ.Name() == typeName {
+				_, found = typ.FieldByName(fieldName)
+			}
+		}
+		if !found {
+			t.Errorf("fieldActions has stale entry %s", key)
+		}
+	}
+}
+
+func TestRequest_Golden(t *testing.T) {
+	SetSalt("golden")
+
+	got, err := json.MarshalIndent(Request(fullRequest()), "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = append(got, '\n')
+
+	golden := filepath.Join("testdata", "full_request.golden.json")
+	if *update {
+		if err := os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("read golden (run with -update to create): %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("scrubbed request differs from %s; review the diff and rerun with -update\n%s", golden, got)
+	}
+}
+
+func TestRequest_NoPIIValues(t *testing.T) {
+	req := fullRequest()
+	out := string(JSON(req))
+
+	for _, pii := range []string{
+		"203.0.113.77", "8a2e", "jane", "abc123",
+		"User.ID", "User.BuyerUID", "User.Consent", "User.YOB", "User.Gender",
+		"User.Keywords", "User.CustomData", "UID.ID", "Device.IFA", "Device.MacSHA1",
+		"Geo.City", "Geo.ZIP", "Regs.GPP", "Segment.Value", "Site.Search",
+	} {
+		if strings.Contains(out, pii) {
+			t.Errorf("scrubbed output leaks %q", pii)
+		}
+	}
+	if !strings.Contains(out, "203.0.113.0") {
+		t.Error("expected truncated IPv4 in output")
+	}
+}
+
+func TestRequest_DoesNotModifyOriginal(t *testing.T) {
+	req := &openrtb.BidRequest{
+		ID:     "req-1",
+		Device: &openrtb.Device{IP: "198.51.100.10", IFA: "ifa-1"},
+		User:   &openrtb.User{ID: "user-1", Consent: "CONSENT", EIDs: []openrtb.EID{{Source: "id5", UIDs: []openrtb.UID{{ID: "uid-1"}}}}},
+	}
+
+	out := Request(req)
+
+	if req.Device.IP != "198.51.100.10" || req.User.ID != "user-1" || req.User.Consent != "CONSENT" || req.User.EIDs[0].UIDs[0].ID != "uid-1" {
+		t.Fatal("original request was modified")
+	}
+	if out.ID != "req-1" {
+		t.Errorf("expected request ID kept, got %q", out.ID)
+	}
+	if out.User.Consent != "" || out.User.ID == "user-1" || out.User.EIDs[0].UIDs[0].ID == "uid-1" {
+		t.Errorf("expected user identifiers scrubbed, got %+v", out.User)
+	}
+	if Request(nil) != nil {
+		t.Error("expected nil for nil request")
+	}
+}
+
+func TestRequest_RegsExt(t *testing.T) {
+	req := &openrtb.BidRequest{
+		ID:   "req-1",
+		Regs: &openrtb.Regs{Ext: json.RawMessage(`{"gdpr":1,"us_privacy":"1YNN","gpp":"DBABMA~CPXxRfAPXxRfA","gpp_sid":[7],"custom":"x"}`)},
+	}
+
+	var ext map[string]interface{}
+	if err := json.Unmarshal(Request(req).Regs.Ext, &ext); err != nil {
+		t.Fatalf("expected regs.ext kept as JSON: %v", err)
+	}
+	if _, ok := ext["gpp"]; ok {
+		t.Error("expected the GPP string copy in regs.ext dropped")
+	}
+	if _, ok := ext["custom"]; ok {
+		t.Error("expected unknown regs.ext keys dropped")
+	}
+	if ext["us_privacy"] != "1YNN" || ext["gdpr"] != float64(1) {
+		t.Errorf("expected regs.ext copies of kept regs fields kept, got %v", ext)
+	}
+
+	req.Regs.Ext = json.RawMessage(`{"gpp":"DBABMA"}`)
+	if out := Request(req); out.Regs.Ext != nil {
+		t.Errorf("expected regs.ext removed when nothing is left, got %s", out.Regs.Ext)
+	}
+}
+
+func TestID(t *testing.T) {
+	SetSalt("a")
+	h1 := ID("user-1")
+	if h1 != ID("user-1") || len(h1) != hashLength {
+		t.Errorf("expected stable %d-char hash, got %q", hashLength, h1)
+	}
+	SetSalt("b")
+	if ID("user-1") == h1 {
+		t.Error("expected hash to depend on salt")
+	}
+	if ID("") != "" {
+		t.Error("expected empty ID to stay empty")
+	}
+}
+
+func TestIP(t *testing.T) {
+	tests := map[string]string{
+		"192.168.1.100":    "192.168.1.0",
+		"2001:db8:85a3::1": "2001:db8:85a3::",
+		"::ffff:10.1.2.3":  "10.1.2.0",
+		"not-an-ip":        "",
+		"":                 "",
+	}
+	for in, want := range tests {
+		if got := IP(in); got != want {
+			t.Errorf("IP(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestURL(t *testing.T) {
+	tests := map[string]string{
+		"https://u:p@example.com/a/b?x=1#f": "https://example.com/a/b",
+		"https://example.com":               "https://example.com",
+		"":                                  "",
+	}
+	for in, want := range tests {
+		if got := URL(in); got != want {
+			t.Errorf("URL(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
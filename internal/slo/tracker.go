@@ -0,0 +1,290 @@
+// Package slo tracks auction response times per publisher against a p95
+// latency target and computes multi-window error budget burn rates, so
+// publishers we are failing show up before they complain.
+//
+// A p95 target of T is met while at most 5% of requests take longer than T,
+// so each request is counted as fast or slow and the burn rate of a window is
+// its slow fraction divided by that 5% budget: 1 spends the budget exactly,
+// 14.4 would spend 30 days of budget in about two days.
+package slo
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+// Objective is the fraction of requests that must finish within the target
+const Objective = 0.95
+
+// Burn rate thresholds, from the multi-window alerting in the Google SRE
+// workbook: a fast burn must show in both its long and short window, which
+// keeps alerts from firing on a single spike or lingering after recovery
+const (
+	FastBurnRate = 14.4 // 1h and 5m windows
+	SlowBurnRate = 6.0  // 6h and 30m windows
+)
+
+// Publisher SLO statuses
+const (
+	StatusOK        = "ok"
+	StatusWarning   = "warning"   // slow burn: 6h and 30m over SlowBurnRate
+	StatusBreaching = "breaching" // fast burn: 1h and 5m over FastBurnRate
+	StatusNoData    = "no_data"   // no requests in the longest window
+)
+
+// Window is one burn rate window; Name is used as the metric label
+type Window struct {
+	Name     string
+	Duration time.Duration
+}
+
+// Windows are the burn rate windows computed for every publisher
+var Windows = []Window{
+	{Name: "5m", Duration: 5 * time.Minute},
+	{Name: "30m", Duration: 30 * time.Minute},
+	{Name: "1h", Duration: time.Hour},
+	{Name: "6h", Duration: 6 * time.Hour},
+}
+
+// DefaultRefreshInterval is how often Start recomputes the burn rate gauges
+const DefaultRefreshInterval = 30 * time.Second
+
+// bucketCount covers the longest window at one bucket per minute
+const bucketCount = 6 * 60
+
+// Config controls which publishers are tracked
+type Config struct {
+	// DefaultTarget applies to publishers without their own slo_p95_ms
+	// (0 = only track publishers with a target)
+	DefaultTarget   time.Duration
+	RefreshInterval time.Duration // 0 uses DefaultRefreshInterval
+}
+
+// GaugeRecorder exposes burn rates as gauges; implemented by metrics.Metrics
+type GaugeRecorder interface {
+	SetPublisherSLOBurnRate(publisherID, window string, rate float64)
+	DeletePublisherSLO(publisherID string)
+}
+
+// PublisherStatus is a publisher's latency against its target
+type PublisherStatus struct {
+	PublisherID string             `json:"publisher_id"`
+	TargetMs    int64              `json:"target_p95_ms"`
+	Requests    int64              `json:"requests"` // over the longest window
+	Slow        int64              `json:"slow"`     // requests over the target
+	BurnRates   map[string]float64 `json:"burn_rates"`
+	Status      string             `json:"status"`
+}
+
+// bucket counts one minute of requests
+type bucket struct {
+	minute int64
+	total  int64
+	slow   int64
+}
+
+// series is one publisher's last six hours of requests
+type series struct {
+	target  time.Duration
+	buckets [bucketCount]bucket
+}
+
+// Tracker counts requests per publisher in one-minute buckets. Each
+// instance tracks its own traffic; behind a load balancer every instance
+// sees a similar share of each publisher, so alerts take the max across
+// instances.
+type Tracker struct {
+	cfg    Config
+	gauges GaugeRecorder
+
+	mu     sync.Mutex
+	series map[string]*series
+
+	now func() time.Time
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewTracker creates a tracker; gauges may be nil
+func NewTracker(cfg Config, gauges GaugeRecorder) *Tracker {
+	if cfg.RefreshInterval <= 0 {
+		cfg.RefreshInterval = DefaultRefreshInterval
+	}
+	return &Tracker{
+		cfg:    cfg,
+		gauges: gauges,
+		series: make(map[string]*series),
+		now:    time.Now,
+		stopCh: make(chan struct{}),
+	}
+}
+
+// Record counts one request. targetMs is the publisher's own target; 0
+// falls back to the default, and requests without either aren't tracked.
+func (t *Tracker) Record(publisherID string, targetMs int, latency time.Duration) {
+	target := time.Duration(targetMs) * time.Millisecond
+	if target <= 0 {
+		target = t.cfg.DefaultTarget
+	}
+	if publisherID == "" || target <= 0 {
+		return
+	}
+
+	minute := t.now().Unix() / 60
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s, ok := t.series[publisherID]
+	if !ok {
+		s = &series{}
+		t.series[publisherID] = s
+	}
+	s.target = target
+
+	b := &s.buckets[minute%bucketCount]
+	if b.minute != minute {
+		*b = bucket{minute: minute}
+	}
+	b.total++
+	if latency > target {
+		b.slow++
+	}
+}
+
+// Status returns a publisher's SLO status; ok is false for publishers that
+// aren't tracked
+func (t *Tracker) Status(publisherID string) (PublisherStatus, bool) {
+	minute := t.now().Unix() / 60
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s, ok := t.series[publisherID]
+	if !ok {
+		return PublisherStatus{}, false
+	}
+	return s.status(publisherID, minute), true
+}
+
+// Statuses returns every tracked publisher's status, worst burn first
+func (t *Tracker) Statuses() []PublisherStatus {
+	minute := t.now().Unix() / 60
+	t.mu.Lock()
+	statuses := make([]PublisherStatus, 0, len(t.series))
+	for id, s := range t.series {
+		statuses = append(statuses, s.status(id, minute))
+	}
+	t.mu.Unlock()
+
+	sort.Slice(statuses, func(i, j int) bool {
+		a, b := statuses[i].BurnRates["1h"], statuses[j].BurnRates["1h"]
+		if a != b {
+			return a > b
+		}
+		return statuses[i].PublisherID < statuses[j].PublisherID
+	})
+	return statuses
+}
+
+// Refresh updates the burn rate gauges and forgets publishers without
+// requests in the longest window
+func (t *Tracker) Refresh() {
+	minute := t.now().Unix() / 60
+	t.mu.Lock()
+	var expired []string
+	statuses := make([]PublisherStatus, 0, len(t.series))
+	for id, s := range t.series {
+		status := s.status(id, minute)
+		if status.Requests == 0 {
+			expired = append(expired, id)
+			delete(t.series, id)
+			continue
+		}
+		statuses = append(statuses, status)
+	}
+	t.mu.Unlock()
+
+	if t.gauges == nil {
+		return
+	}
+	for _, id := range expired {
+		t.gauges.DeletePublisherSLO(id)
+	}
+	for _, status := range statuses {
+		for _, w := range Windows {
+			t.gauges.SetPublisherSLOBurnRate(status.PublisherID, w.Name, status.BurnRates[w.Name])
+		}
+	}
+}
+
+// Start refreshes the gauges every refresh interval until Stop
+func (t *Tracker) Start() {
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		ticker := time.NewTicker(t.cfg.RefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				t.Refresh()
+			case <-t.stopCh:
+				return
+			}
+		}
+	}()
+	logger.Log.Debug().Dur("interval", t.cfg.RefreshInterval).Msg("SLO burn rate refresh started")
+}
+
+// Stop ends the refresh loop
+func (t *Tracker) Stop() {
+	t.stopOnce.Do(func() { close(t.stopCh) })
+	t.wg.Wait()
+}
+
+// status computes the burn rates ending at minute; the caller holds mu
+func (s *series) status(publisherID string, minute int64) PublisherStatus {
+	status := PublisherStatus{
+		PublisherID: publisherID,
+		TargetMs:    s.target.Milliseconds(),
+		BurnRates:   make(map[string]float64, len(Windows)),
+	}
+	for _, w := range Windows {
+		total, slow := s.sum(minute, int64(w.Duration/time.Minute))
+		if total > 0 {
+			status.BurnRates[w.Name] = float64(slow) / float64(total) / (1 - Objective)
+		} else {
+			status.BurnRates[w.Name] = 0
+		}
+		status.Requests, status.Slow = total, slow // the longest window is last
+	}
+
+	rate := status.BurnRates
+	switch {
+	case status.Requests == 0:
+		status.Status = StatusNoData
+	case rate["1h"] >= FastBurnRate && rate["5m"] >= FastBurnRate:
+		status.Status = StatusBreaching
+	case rate["6h"] >= SlowBurnRate && rate["30m"] >= SlowBurnRate:
+		status.Status = StatusWarning
+	default:
+		status.Status = StatusOK
+	}
+	return status
+}
+
+// sum adds the buckets of the last minutes minutes, including the current one
+func (s *series) sum(minute, minutes int64) (total, slow int64) {
+	for i := range s.buckets {
+		b := &s.buckets[i]
+		if b.minute > minute-minutes && b.minute <= minute {
+			total += b.total
+			slow += b.slow
+		}
+	}
+	return total, slow
+}
@@ -0,0 +1,171 @@
+package slo
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+type fakeGauges struct {
+	rates   map[string]map[string]float64
+	deleted []string
+}
+
+func (f *fakeGauges) SetPublisherSLOBurnRate(publisherID, window string, rate float64) {
+	if f.rates[publisherID] == nil {
+		f.rates[publisherID] = make(map[string]float64)
+	}
+	f.rates[publisherID][window] = rate
+}
+
+func (f *fakeGauges) DeletePublisherSLO(publisherID string) {
+	delete(f.rates, publisherID)
+	f.deleted = append(f.deleted, publisherID)
+}
+
+func newTestTracker(defaultTarget time.Duration) (*Tracker, *fakeGauges, *time.Time) {
+	gauges := &fakeGauges{rates: make(map[string]map[string]float64)}
+	tr := NewTracker(Config{DefaultTarget: defaultTarget}, gauges)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tr.now = func() time.Time { return now }
+	return tr, gauges, &now
+}
+
+func record(tr *Tracker, publisherID string, targetMs, fast, slow int) {
+	for i := 0; i < fast; i++ {
+		tr.Record(publisherID, targetMs, 50*time.Millisecond)
+	}
+	for i := 0; i < slow; i++ {
+		tr.Record(publisherID, targetMs, 2*time.Second)
+	}
+}
+
+func TestTracker_RecordTargets(t *testing.T) {
+	tr, _, _ := newTestTracker(0)
+	record(tr, "no-target", 0, 10, 0)
+	record(tr, "", 200, 10, 0)
+	if statuses := tr.Statuses(); len(statuses) != 0 {
+		t.Fatalf("expected untracked publishers without a target, got %+v", statuses)
+	}
+
+	tr, _, _ = newTestTracker(300 * time.Millisecond)
+	record(tr, "default", 0, 10, 0)
+	record(tr, "own", 100, 10, 0)
+	if s, ok := tr.Status("default"); !ok || s.TargetMs != 300 {
+		t.Errorf("expected the default target, got %+v", s)
+	}
+	if s, ok := tr.Status("own"); !ok || s.TargetMs != 100 {
+		t.Errorf("expected the publisher's target, got %+v", s)
+	}
+}
+
+func TestTracker_BurnRatesAndStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		setup  func(tr *Tracker, now *time.Time)
+		status string
+		rate1h float64
+	}{
+		{
+			name:   "within budget",
+			setup:  func(tr *Tracker, now *time.Time) { record(tr, "pub", 200, 97, 3) },
+			status: StatusOK,
+			rate1h: 0.6,
+		},
+		{
+			name:   "fast burn",
+			setup:  func(tr *Tracker, now *time.Time) { record(tr, "pub", 200, 20, 80) },
+			status: StatusBreaching,
+			rate1h: 16,
+		},
+		{
+			name: "slow burn over hours",
+			setup: func(tr *Tracker, now *time.Time) {
+				// 40% slow for five hours, then 35% in the last hour:
+				// over 6x everywhere but short of a fast burn
+				for h := 0; h < 6; h++ {
+					if h == 5 {
+						record(tr, "pub", 200, 65, 35)
+					} else {
+						record(tr, "pub", 200, 60, 40)
+					}
+					*now = now.Add(time.Hour)
+				}
+				*now = now.Add(-50 * time.Minute)
+			},
+			status: StatusWarning,
+			rate1h: 7,
+		},
+		{
+			name: "spike already over",
+			setup: func(tr *Tracker, now *time.Time) {
+				record(tr, "pub", 200, 0, 100)
+				*now = now.Add(10 * time.Minute)
+				record(tr, "pub", 200, 1000, 0)
+			},
+			status: StatusOK, // the 5m window has recovered
+			rate1h: 100.0 / 1100 / 0.05,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr, _, now := newTestTracker(0)
+			tt.setup(tr, now)
+			s, ok := tr.Status("pub")
+			if !ok {
+				t.Fatal("expected the publisher to be tracked")
+			}
+			if s.Status != tt.status {
+				t.Errorf("expected status %s, got %s (rates %v)", tt.status, s.Status, s.BurnRates)
+			}
+			if math.Abs(s.BurnRates["1h"]-tt.rate1h) > 1e-9 {
+				t.Errorf("expected 1h burn rate %v, got %v", tt.rate1h, s.BurnRates["1h"])
+			}
+		})
+	}
+}
+
+func TestTracker_RefreshGaugesAndExpiry(t *testing.T) {
+	tr, gauges, now := newTestTracker(200 * time.Millisecond)
+	record(tr, "old", 0, 10, 10)
+	*now = now.Add(5 * time.Hour)
+	record(tr, "new", 0, 10, 0)
+
+	tr.Refresh()
+	if got := gauges.rates["old"]["6h"]; got != 10 {
+		t.Errorf("expected old 6h burn rate 10, got %v", got)
+	}
+	if got := gauges.rates["old"]["5m"]; got != 0 {
+		t.Errorf("expected old 5m burn rate 0, got %v", got)
+	}
+	if len(gauges.rates["new"]) != len(Windows) {
+		t.Errorf("expected a gauge per window, got %v", gauges.rates["new"])
+	}
+
+	// Six hours after its last request a publisher is forgotten
+	*now = now.Add(time.Hour + time.Minute)
+	record(tr, "new", 0, 1, 0)
+	tr.Refresh()
+	if _, ok := tr.Status("old"); ok {
+		t.Error("expected the idle publisher to be forgotten")
+	}
+	if len(gauges.deleted) != 1 || gauges.deleted[0] != "old" {
+		t.Errorf("expected old's gauges deleted, got %v", gauges.deleted)
+	}
+	if statuses := tr.Statuses(); len(statuses) != 1 || statuses[0].PublisherID != "new" {
+		t.Errorf("unexpected statuses %+v", statuses)
+	}
+}
+
+func TestTracker_StatusesWorstFirst(t *testing.T) {
+	tr, _, _ := newTestTracker(200 * time.Millisecond)
+	record(tr, "a", 0, 100, 0)
+	record(tr, "b", 0, 50, 50)
+	record(tr, "c", 0, 90, 10)
+
+	statuses := tr.Statuses()
+	if len(statuses) != 3 || statuses[0].PublisherID != "b" || statuses[1].PublisherID != "c" || statuses[2].PublisherID != "a" {
+		t.Errorf("expected b, c, a, got %+v", statuses)
+	}
+}
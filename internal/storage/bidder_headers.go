@@ -0,0 +1,212 @@
+package storage
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/thenexusengine/tne_springwire/pkg/domainmatch"
+)
+
+// Headers a bidder config may never set: they control routing, framing or
+// session state of the outbound request rather than describing it
+var forbiddenBidderHeaders = map[string]bool{
+	"Host":                true,
+	"Content-Length":      true,
+	"Transfer-Encoding":   true,
+	"Connection":          true,
+	"Upgrade":             true,
+	"Te":                  true,
+	"Trailer":             true,
+	"Keep-Alive":          true,
+	"Proxy-Authorization": true,
+	"Proxy-Connection":    true,
+	"Cookie":              true,
+}
+
+// Headers permitted in strict mode, in addition to any X- prefixed header
+var allowedBidderHeaders = map[string]bool{
+	"Authorization":   true,
+	"Accept":          true,
+	"Accept-Encoding": true,
+	"Accept-Language": true,
+	"User-Agent":      true,
+	"Referer":         true,
+}
+
+// secretRefPattern matches ${env:NAME} and ${file:/path} references in
+// header values
+var secretRefPattern = regexp.MustCompile(`\$\{(env|file):([^}]+)\}`)
+
+// headerNamePattern is the RFC 7230 token grammar for header names
+var headerNamePattern = regexp.MustCompile("^[!#$%&'*+.^_`|~0-9A-Za-z-]+$")
+
+// HeaderPolicy controls which outbound headers a bidder's http_headers may set
+type HeaderPolicy struct {
+	// Strict rejects headers outside the allowlist and requires credential
+	// headers (Authorization, *key*, *token*, *secret*) to use secret
+	// references instead of plaintext values
+	Strict bool
+
+	// AuthorizationHosts is a domainmatch allow list of endpoint hosts that
+	// may receive an Authorization header
+	AuthorizationHosts string
+
+	// AllowAuthorizationAnyHost permits Authorization to any endpoint
+	AllowAuthorizationAnyHost bool
+
+	// SecretsDir is the directory ${file:} references must point into;
+	// empty refuses file references
+	SecretsDir string
+}
+
+// Validate checks a bidder's http_headers against the policy. Values must be
+// strings; they may embed ${env:NAME} or ${file:/path} secret references.
+func (p HeaderPolicy) Validate(headers map[string]interface{}, endpointURL string) error {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value, ok := headers[name].(string)
+		if !ok {
+			return fmt.Errorf("%w http_headers: %s must be a string", ErrValidation, name)
+		}
+		if !headerNamePattern.MatchString(name) {
+			return fmt.Errorf("%w http_headers: %q is not a valid header name", ErrValidation, name)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("%w http_headers: %s contains a line break", ErrValidation, name)
+		}
+
+		canonical := http.CanonicalHeaderKey(name)
+		if forbiddenBidderHeaders[canonical] {
+			return fmt.Errorf("%w http_headers: %s may not be overridden", ErrValidation, canonical)
+		}
+		if p.Strict && !allowedBidderHeaders[canonical] && !strings.HasPrefix(canonical, "X-") {
+			return fmt.Errorf("%w http_headers: %s is not in the header allowlist", ErrValidation, canonical)
+		}
+
+		if canonical == "Authorization" && !p.AllowAuthorizationAnyHost {
+			if p.AuthorizationHosts == "" || !domainmatch.MatchList(endpointURL, p.AuthorizationHosts) {
+				return fmt.Errorf("%w http_headers: Authorization may not be sent to %q", ErrValidation, endpointURL)
+			}
+		}
+
+		if p.Strict && isCredentialHeader(canonical) && !secretRefPattern.MatchString(value) {
+			return fmt.Errorf("%w http_headers: %s must use a ${env:NAME} or ${file:/path} secret reference", ErrValidation, canonical)
+		}
+
+		for _, m := range secretRefPattern.FindAllStringSubmatch(value, -1) {
+			if m[1] != "file" {
+				continue
+			}
+			if _, err := secretFilePath(p.SecretsDir, m[2]); err != nil {
+				return fmt.Errorf("%w http_headers: %s: %v", ErrValidation, canonical, err)
+			}
+		}
+	}
+	return nil
+}
+
+// secretFilePath resolves a ${file:} reference inside dir. Relative names are
+// joined to dir; absolute paths must already be inside it. Names containing
+// ".." are refused outright.
+func secretFilePath(dir, name string) (string, error) {
+	if dir == "" {
+		return "", fmt.Errorf("file secret references need BIDDER_SECRETS_DIR")
+	}
+	if strings.Contains(name, "..") {
+		return "", fmt.Errorf("file secret reference %q may not contain \"..\"", name)
+	}
+	dir = filepath.Clean(dir)
+	path := name
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	path = filepath.Clean(path)
+	if rel, err := filepath.Rel(dir, path); err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("file secret reference %q is outside %s", name, dir)
+	}
+	return path, nil
+}
+
+// isCredentialHeader reports whether a header typically carries a secret
+func isCredentialHeader(canonical string) bool {
+	if canonical == "Authorization" {
+		return true
+	}
+	lower := strings.ToLower(canonical)
+	return strings.Contains(lower, "key") || strings.Contains(lower, "token") || strings.Contains(lower, "secret")
+}
+
+// SecretResolver looks up the value behind a secret reference
+type SecretResolver interface {
+	Resolve(source, name string) (string, error)
+}
+
+// EnvSecretResolver resolves env: references from the process environment
+// and file: references from secret files mounted under SecretsDir
+type EnvSecretResolver struct {
+	SecretsDir string
+}
+
+// Resolve implements SecretResolver
+func (r EnvSecretResolver) Resolve(source, name string) (string, error) {
+	switch source {
+	case "env":
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return value, nil
+	case "file":
+		path, err := secretFilePath(r.SecretsDir, name)
+		if err != nil {
+			return "", err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read secret file: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	default:
+		return "", fmt.Errorf("unknown secret source %q", source)
+	}
+}
+
+// ResolveHTTPHeaders builds the outbound headers for a bidder, substituting
+// secret references. Headers are expected to have passed HeaderPolicy.Validate.
+func (b *Bidder) ResolveHTTPHeaders(resolver SecretResolver) (http.Header, error) {
+	headers := make(http.Header, len(b.HTTPHeaders))
+	for name, raw := range b.HTTPHeaders {
+		value, ok := raw.(string)
+		if !ok {
+			return nil, fmt.Errorf("http_headers %s must be a string", name)
+		}
+
+		var resolveErr error
+		resolved := secretRefPattern.ReplaceAllStringFunc(value, func(ref string) string {
+			m := secretRefPattern.FindStringSubmatch(ref)
+			secret, err := resolver.Resolve(m[1], m[2])
+			if err != nil && resolveErr == nil {
+				resolveErr = fmt.Errorf("failed to resolve %s for bidder %s: %w", name, b.BidderCode, err)
+			}
+			return secret
+		})
+		if resolveErr != nil {
+			return nil, resolveErr
+		}
+		if strings.ContainsAny(resolved, "\r\n") {
+			return nil, fmt.Errorf("resolved http_headers %s for bidder %s contains a line break", name, b.BidderCode)
+		}
+		headers.Set(name, resolved)
+	}
+	return headers, nil
+}
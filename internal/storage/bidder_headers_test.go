@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestHeaderPolicy_Validate(t *testing.T) {
+	endpoint := "https://ib.adnxs.com/openrtb2"
+
+	tests := []struct {
+		name    string
+		policy  HeaderPolicy
+		headers map[string]interface{}
+		wantErr string
+	}{
+		{"empty", HeaderPolicy{}, nil, ""},
+		{"custom header", HeaderPolicy{}, map[string]interface{}{"X-Partner-ID": "123"}, ""},
+		{"non-allowlisted header outside strict mode", HeaderPolicy{}, map[string]interface{}{"Origin": "https://a.com"}, ""},
+		{"host override", HeaderPolicy{}, map[string]interface{}{"host": "evil.example.com"}, "Host may not be overridden"},
+		{"cookie", HeaderPolicy{}, map[string]interface{}{"Cookie": "uid=1"}, "Cookie may not be overridden"},
+		{"non-string value", HeaderPolicy{}, map[string]interface{}{"X-Count": 3}, "must be a string"},
+		{"invalid name", HeaderPolicy{}, map[string]interface{}{"X Bad": "1"}, "not a valid header name"},
+		{"header injection", HeaderPolicy{}, map[string]interface{}{"X-Id": "1\r\nHost: evil"}, "line break"},
+		{"authorization without host list", HeaderPolicy{}, map[string]interface{}{"Authorization": "Bearer abc"}, "Authorization may not be sent"},
+		{"authorization to listed host", HeaderPolicy{AuthorizationHosts: "*.adnxs.com"}, map[string]interface{}{"Authorization": "Bearer abc"}, ""},
+		{"authorization to unlisted host", HeaderPolicy{AuthorizationHosts: "*.rubiconproject.com"}, map[string]interface{}{"Authorization": "Bearer abc"}, "Authorization may not be sent"},
+		{"authorization any host flag", HeaderPolicy{AllowAuthorizationAnyHost: true}, map[string]interface{}{"Authorization": "Bearer abc"}, ""},
+		{"strict rejects non-allowlisted", HeaderPolicy{Strict: true}, map[string]interface{}{"Origin": "https://a.com"}, "not in the header allowlist"},
+		{"strict allows X- headers", HeaderPolicy{Strict: true}, map[string]interface{}{"X-Partner-ID": "123"}, ""},
+		{"strict rejects plaintext key", HeaderPolicy{Strict: true}, map[string]interface{}{"X-API-Key": "abc123"}, "secret reference"},
+		{"strict accepts env reference", HeaderPolicy{Strict: true}, map[string]interface{}{"X-API-Key": "${env:APPNEXUS_KEY}"}, ""},
+		{"strict rejects plaintext authorization", HeaderPolicy{Strict: true, AllowAuthorizationAnyHost: true}, map[string]interface{}{"Authorization": "Bearer abc"}, "secret reference"},
+		{"strict accepts file reference", HeaderPolicy{Strict: true, AllowAuthorizationAnyHost: true, SecretsDir: "/run/secrets"}, map[string]interface{}{"Authorization": "Bearer ${file:/run/secrets/an}"}, ""},
+		{"file reference relative to secrets dir", HeaderPolicy{SecretsDir: "/run/secrets"}, map[string]interface{}{"X-API-Key": "${file:an}"}, ""},
+		{"file reference traversal", HeaderPolicy{SecretsDir: "/run/secrets"}, map[string]interface{}{"X-API-Key": "${file:../../etc/passwd}"}, "may not contain"},
+		{"file reference outside secrets dir", HeaderPolicy{SecretsDir: "/run/secrets"}, map[string]interface{}{"X-API-Key": "${file:/etc/passwd}"}, "outside /run/secrets"},
+		{"file reference without secrets dir", HeaderPolicy{}, map[string]interface{}{"X-API-Key": "${file:/run/secrets/an}"}, "need BIDDER_SECRETS_DIR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate(tt.headers, endpoint)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected valid, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestBidder_ResolveHTTPHeaders(t *testing.T) {
+	t.Setenv("TEST_BIDDER_KEY", "s3cret")
+	secretsDir := t.TempDir()
+	secretFile := filepath.Join(secretsDir, "token")
+	if err := os.WriteFile(secretFile, []byte("tok-123\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	resolver := EnvSecretResolver{SecretsDir: secretsDir}
+
+	b := &Bidder{BidderCode: "appnexus", HTTPHeaders: map[string]interface{}{
+		"X-API-Key":     "${env:TEST_BIDDER_KEY}",
+		"Authorization": "Bearer ${file:" + secretFile + "}",
+		"X-Token":       "${file:token}",
+		"X-Partner-ID":  "123",
+	}}
+
+	headers, err := b.ResolveHTTPHeaders(resolver)
+	if err != nil {
+		t.Fatalf("ResolveHTTPHeaders failed: %v", err)
+	}
+	if got := headers.Get("X-API-Key"); got != "s3cret" {
+		t.Errorf("expected env secret, got %q", got)
+	}
+	if got := headers.Get("Authorization"); got != "Bearer tok-123" {
+		t.Errorf("expected file secret, got %q", got)
+	}
+	if got := headers.Get("X-Token"); got != "tok-123" {
+		t.Errorf("expected file secret relative to the secrets dir, got %q", got)
+	}
+	if got := headers.Get("X-Partner-ID"); got != "123" {
+		t.Errorf("expected literal value, got %q", got)
+	}
+
+	missing := &Bidder{BidderCode: "rubicon", HTTPHeaders: map[string]interface{}{"X-API-Key": "${env:TEST_BIDDER_MISSING}"}}
+	if _, err := missing.ResolveHTTPHeaders(resolver); err == nil {
+		t.Error("expected error for unset environment variable")
+	}
+
+	for _, ref := range []string{"${file:../token}", "${file:/etc/passwd}", "${file:" + secretsDir + "/../token}"} {
+		outside := &Bidder{BidderCode: "rubicon", HTTPHeaders: map[string]interface{}{"X-API-Key": ref}}
+		if _, err := outside.ResolveHTTPHeaders(resolver); err == nil {
+			t.Errorf("expected %s outside the secrets dir to be refused", ref)
+		}
+	}
+	if _, err := b.ResolveHTTPHeaders(EnvSecretResolver{}); err == nil {
+		t.Error("expected file references to be refused without a secrets dir")
+	}
+}
+
+func TestBidderStore_Create_RejectsInvalidHeaders(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewBidderStore(db)
+	store.SetHeaderPolicy(HeaderPolicy{Strict: true})
+
+	bidder := createTestBidder("appnexus") // plaintext X-API-Key
+	if err := store.Create(context.Background(), bidder); !errors.Is(err, ErrValidation) {
+		t.Fatalf("expected plaintext API key to be rejected in strict mode, got %v", err)
+	}
+	if err := store.Update(context.Background(), bidder); err == nil {
+		t.Fatal("expected update with plaintext API key to be rejected in strict mode")
+	}
+
+	// Rejected before reaching the database
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unexpected database calls: %v", err)
+	}
+}
@@ -42,7 +42,8 @@ type PublisherBidder struct {
 
 // BidderStore provides database operations for bidders
 type BidderStore struct {
-	db *sql.DB
+	db           *sql.DB
+	headerPolicy HeaderPolicy
 }
 
 // NewBidderStore creates a new bidder store
@@ -50,24 +51,24 @@ func NewBidderStore(db *sql.DB) *BidderStore {
 	return &BidderStore{db: db}
 }
 
-// GetByCode retrieves a bidder by their bidder_code
-func (s *BidderStore) GetByCode(ctx context.Context, bidderCode string) (*Bidder, error) {
-	ctx, cancel := withTimeout(ctx, DefaultDBTimeout)
-	defer cancel()
+// SetHeaderPolicy sets the policy http_headers must satisfy on create/update
+func (s *BidderStore) SetHeaderPolicy(policy HeaderPolicy) {
+	s.headerPolicy = policy
+}
 
-	query := `
-		SELECT id, bidder_code, bidder_name, endpoint_url, timeout_ms,
-		       enabled, status, supports_banner, supports_video, supports_native, supports_audio,
-		       gvl_vendor_id, http_headers, description, documentation_url, contact_email,
-		       version, created_at, updated_at
-		FROM bidders
-		WHERE bidder_code = $1 AND enabled = true AND status = 'active'
-	`
+// bidderColumns are the columns scanBidder reads, in order
+const bidderColumns = `id, bidder_code, bidder_name, endpoint_url, timeout_ms,
+		enabled, status, supports_banner, supports_video, supports_native, supports_audio,
+		gvl_vendor_id, http_headers, description, documentation_url, contact_email,
+		version, created_at, updated_at`
 
+// scanBidder reads a row selected with bidderColumns, parsing http_headers.
+// Scan errors, including sql.ErrNoRows, are returned as is.
+func scanBidder(row rowScanner) (*Bidder, error) {
 	var b Bidder
 	var httpHeadersJSON []byte
 
-	err := s.db.QueryRowContext(ctx, query, bidderCode).Scan(
+	err := row.Scan(
 		&b.ID,
 		&b.BidderCode,
 		&b.BidderName,
@@ -88,12 +89,8 @@ func (s *BidderStore) GetByCode(ctx context.Context, bidderCode string) (*Bidder
 		&b.CreatedAt,
 		&b.UpdatedAt,
 	)
-
-	if err == sql.ErrNoRows {
-		return nil, nil // Bidder not found
-	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to query bidder: %w", err)
+		return nil, err
 	}
 
 	// Parse JSONB http_headers
@@ -102,20 +99,37 @@ func (s *BidderStore) GetByCode(ctx context.Context, bidderCode string) (*Bidder
 			return nil, fmt.Errorf("failed to parse http_headers: %w", err)
 		}
 	}
-
 	return &b, nil
 }
 
+// GetByCode retrieves an active bidder by their bidder_code, returning
+// ErrNotFound if there is none
+func (s *BidderStore) GetByCode(ctx context.Context, bidderCode string) (*Bidder, error) {
+	ctx, cancel := withTimeout(ctx, DefaultDBTimeout)
+	defer cancel()
+
+	query := `SELECT ` + bidderColumns + `
+		FROM bidders
+		WHERE bidder_code = $1 AND enabled = true AND status = 'active'
+	`
+
+	b, err := scanBidder(s.db.QueryRowContext(ctx, query, bidderCode))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("bidder %w: %s", ErrNotFound, bidderCode)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query bidder: %w", err)
+	}
+
+	return b, nil
+}
+
 // ListActive retrieves all active bidders
 func (s *BidderStore) ListActive(ctx context.Context) ([]*Bidder, error) {
 	ctx, cancel := withTimeout(ctx, DefaultDBTimeout)
 	defer cancel()
 
-	query := `
-		SELECT id, bidder_code, bidder_name, endpoint_url, timeout_ms,
-		       enabled, status, supports_banner, supports_video, supports_native, supports_audio,
-		       gvl_vendor_id, http_headers, description, documentation_url, contact_email,
-		       version, created_at, updated_at
+	query := `SELECT ` + bidderColumns + `
 		FROM bidders
 		WHERE enabled = true AND status = 'active'
 		ORDER BY bidder_code
@@ -129,42 +143,11 @@ func (s *BidderStore) ListActive(ctx context.Context) ([]*Bidder, error) {
 
 	bidders := make([]*Bidder, 0, 100)
 	for rows.Next() {
-		var b Bidder
-		var httpHeadersJSON []byte
-
-		err := rows.Scan(
-			&b.ID,
-			&b.BidderCode,
-			&b.BidderName,
-			&b.EndpointURL,
-			&b.TimeoutMs,
-			&b.Enabled,
-			&b.Status,
-			&b.SupportsBanner,
-			&b.SupportsVideo,
-			&b.SupportsNative,
-			&b.SupportsAudio,
-			&b.GVLVendorID,
-			&httpHeadersJSON,
-			&b.Description,
-			&b.DocumentationURL,
-			&b.ContactEmail,
-			&b.Version,
-			&b.CreatedAt,
-			&b.UpdatedAt,
-		)
+		b, err := scanBidder(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan bidder row: %w", err)
 		}
-
-		// Parse JSONB http_headers
-		if len(httpHeadersJSON) > 0 {
-			if err := json.Unmarshal(httpHeadersJSON, &b.HTTPHeaders); err != nil {
-				return nil, fmt.Errorf("failed to parse http_headers: %w", err)
-			}
-		}
-
-		bidders = append(bidders, &b)
+		bidders = append(bidders, b)
 	}
 
 	return bidders, rows.Err()
@@ -275,11 +258,7 @@ func (s *BidderStore) List(ctx context.Context) ([]*Bidder, error) {
 	ctx, cancel := withTimeout(ctx, DefaultDBTimeout)
 	defer cancel()
 
-	query := `
-		SELECT id, bidder_code, bidder_name, endpoint_url, timeout_ms,
-		       enabled, status, supports_banner, supports_video, supports_native, supports_audio,
-		       gvl_vendor_id, http_headers, description, documentation_url, contact_email,
-		       version, created_at, updated_at
+	query := `SELECT ` + bidderColumns + `
 		FROM bidders
 		ORDER BY bidder_code
 	`
@@ -292,49 +271,59 @@ func (s *BidderStore) List(ctx context.Context) ([]*Bidder, error) {
 
 	bidders := make([]*Bidder, 0, 10)
 	for rows.Next() {
-		var b Bidder
-		var httpHeadersJSON []byte
-
-		err := rows.Scan(
-			&b.ID,
-			&b.BidderCode,
-			&b.BidderName,
-			&b.EndpointURL,
-			&b.TimeoutMs,
-			&b.Enabled,
-			&b.Status,
-			&b.SupportsBanner,
-			&b.SupportsVideo,
-			&b.SupportsNative,
-			&b.SupportsAudio,
-			&b.GVLVendorID,
-			&httpHeadersJSON,
-			&b.Description,
-			&b.DocumentationURL,
-			&b.ContactEmail,
-			&b.Version,
-			&b.CreatedAt,
-			&b.UpdatedAt,
-		)
+		b, err := scanBidder(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan bidder row: %w", err)
 		}
+		bidders = append(bidders, b)
+	}
 
-		// Parse JSONB http_headers
-		if len(httpHeadersJSON) > 0 {
-			if err := json.Unmarshal(httpHeadersJSON, &b.HTTPHeaders); err != nil {
-				return nil, fmt.Errorf("failed to parse http_headers: %w", err)
-			}
-		}
+	return bidders, rows.Err()
+}
 
-		bidders = append(bidders, &b)
+// bidderSortFields are the columns ListPage can sort bidders by
+var bidderSortFields = []string{"bidder_code", "bidder_name", "status", "created_at", "updated_at"}
+
+// ListPage returns one page of bidders matching opts along with the total
+// number of bidders matching its status filter
+func (s *BidderStore) ListPage(ctx context.Context, opts ListOptions) ([]*Bidder, int, error) {
+	lq, err := opts.buildListQuery(bidderColumns, "bidders", "bidder_code", bidderSortFields)
+	if err != nil {
+		return nil, 0, err
 	}
 
-	return bidders, rows.Err()
+	ctx, cancel := withTimeout(ctx, DefaultDBTimeout)
+	defer cancel()
+
+	var total int
+	if err := s.db.QueryRowContext(ctx, lq.countQuery, lq.countArgs...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count bidders: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, lq.query, lq.args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query bidders: %w", err)
+	}
+	defer rows.Close()
+
+	bidders := make([]*Bidder, 0, opts.PageLimit())
+	for rows.Next() {
+		b, err := scanBidder(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan bidder row: %w", err)
+		}
+		bidders = append(bidders, b)
+	}
+
+	return bidders, total, rows.Err()
 }
 
 // Create adds a new bidder
 func (s *BidderStore) Create(ctx context.Context, b *Bidder) error {
+	if err := s.headerPolicy.Validate(b.HTTPHeaders, b.EndpointURL); err != nil {
+		return err
+	}
+
 	ctx, cancel := withTimeout(ctx, DefaultDBTimeout)
 	defer cancel()
 
@@ -376,6 +365,9 @@ func (s *BidderStore) Create(ctx context.Context, b *Bidder) error {
 		b.ContactEmail,
 	).Scan(&b.ID, &b.Version, &b.CreatedAt, &b.UpdatedAt)
 
+	if isUniqueViolation(err) {
+		return fmt.Errorf("%w: bidder %s already exists", ErrConflict, b.BidderCode)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to create bidder: %w", err)
 	}
@@ -385,6 +377,10 @@ func (s *BidderStore) Create(ctx context.Context, b *Bidder) error {
 
 // Update modifies an existing bidder using optimistic locking
 func (s *BidderStore) Update(ctx context.Context, b *Bidder) error {
+	if err := s.headerPolicy.Validate(b.HTTPHeaders, b.EndpointURL); err != nil {
+		return err
+	}
+
 	ctx, cancel := withTimeout(ctx, DefaultDBTimeout)
 	defer cancel()
 
@@ -399,7 +395,7 @@ func (s *BidderStore) Update(ctx context.Context, b *Bidder) error {
 	var currentVersion int
 	err = tx.QueryRowContext(ctx, "SELECT version FROM bidders WHERE bidder_code = $1", b.BidderCode).Scan(&currentVersion)
 	if err == sql.ErrNoRows {
-		return fmt.Errorf("bidder not found: %s", b.BidderCode)
+		return fmt.Errorf("bidder %w: %s", ErrNotFound, b.BidderCode)
 	}
 	if err != nil {
 		return fmt.Errorf("failed to check version: %w", err)
@@ -407,7 +403,7 @@ func (s *BidderStore) Update(ctx context.Context, b *Bidder) error {
 
 	// Verify version matches (optimistic lock check)
 	if currentVersion != b.Version {
-		return fmt.Errorf("concurrent modification detected: bidder %s was updated by another process", b.BidderCode)
+		return fmt.Errorf("%w: concurrent modification detected: bidder %s was updated by another process", ErrConflict, b.BidderCode)
 	}
 
 	query := `
@@ -453,7 +449,7 @@ func (s *BidderStore) Update(ctx context.Context, b *Bidder) error {
 	}
 
 	if rows == 0 {
-		return fmt.Errorf("concurrent modification detected: bidder %s version mismatch", b.BidderCode)
+		return fmt.Errorf("%w: concurrent modification detected: bidder %s version mismatch", ErrConflict, b.BidderCode)
 	}
 
 	// Commit transaction
@@ -489,12 +485,50 @@ func (s *BidderStore) Delete(ctx context.Context, bidderCode string) error {
 	}
 
 	if rows == 0 {
-		return fmt.Errorf("bidder not found: %s", bidderCode)
+		return fmt.Errorf("bidder %w: %s", ErrNotFound, bidderCode)
 	}
 
 	return nil
 }
 
+// Restore re-activates an archived bidder as read by the caller: it is
+// enabled with status active. Its stored headers are checked against the
+// header policy again, and a bidder that is no longer archived or changed
+// since b.Version fails with ErrConflict.
+func (s *BidderStore) Restore(ctx context.Context, b *Bidder) error {
+	if err := s.headerPolicy.Validate(b.HTTPHeaders, b.EndpointURL); err != nil {
+		return err
+	}
+
+	ctx, cancel := withTimeout(ctx, DefaultDBTimeout)
+	defer cancel()
+
+	query := `
+		UPDATE bidders
+		SET status = 'active', enabled = true
+		WHERE bidder_code = $1 AND status = 'archived' AND version = $2
+	`
+
+	result, err := s.db.ExecContext(ctx, query, b.BidderCode, b.Version)
+	if err != nil {
+		return fmt.Errorf("failed to restore bidder: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("%w: bidder %s is no longer archived or was modified", ErrConflict, b.BidderCode)
+	}
+
+	// The version trigger bumped the version
+	b.Status, b.Enabled = "active", true
+	b.Version++
+	return nil
+}
+
 // SetEnabled enables or disables a bidder
 func (s *BidderStore) SetEnabled(ctx context.Context, bidderCode string, enabled bool) error {
 	ctx, cancel := withTimeout(ctx, DefaultDBTimeout)
@@ -517,7 +551,7 @@ func (s *BidderStore) SetEnabled(ctx context.Context, bidderCode string, enabled
 	}
 
 	if rows == 0 {
-		return fmt.Errorf("bidder not found: %s", bidderCode)
+		return fmt.Errorf("bidder %w: %s", ErrNotFound, bidderCode)
 	}
 
 	return nil
@@ -528,11 +562,7 @@ func (s *BidderStore) GetCapabilities(ctx context.Context, banner, video, native
 	ctx, cancel := withTimeout(ctx, DefaultDBTimeout)
 	defer cancel()
 
-	query := `
-		SELECT id, bidder_code, bidder_name, endpoint_url, timeout_ms,
-		       enabled, status, supports_banner, supports_video, supports_native, supports_audio,
-		       gvl_vendor_id, http_headers, description, documentation_url, contact_email,
-		       version, created_at, updated_at
+	query := `SELECT ` + bidderColumns + `
 		FROM bidders
 		WHERE enabled = true
 		  AND status = 'active'
@@ -551,43 +581,173 @@ func (s *BidderStore) GetCapabilities(ctx context.Context, banner, video, native
 
 	bidders := make([]*Bidder, 0, 100)
 	for rows.Next() {
-		var b Bidder
-		var httpHeadersJSON []byte
-
-		err := rows.Scan(
-			&b.ID,
-			&b.BidderCode,
-			&b.BidderName,
-			&b.EndpointURL,
-			&b.TimeoutMs,
-			&b.Enabled,
-			&b.Status,
-			&b.SupportsBanner,
-			&b.SupportsVideo,
-			&b.SupportsNative,
-			&b.SupportsAudio,
-			&b.GVLVendorID,
-			&httpHeadersJSON,
-			&b.Description,
-			&b.DocumentationURL,
-			&b.ContactEmail,
-			&b.Version,
-			&b.CreatedAt,
-			&b.UpdatedAt,
-		)
+		b, err := scanBidder(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan bidder row: %w", err)
 		}
+		bidders = append(bidders, b)
+	}
 
-		// Parse JSONB http_headers
-		if len(httpHeadersJSON) > 0 {
-			if err := json.Unmarshal(httpHeadersJSON, &b.HTTPHeaders); err != nil {
-				return nil, fmt.Errorf("failed to parse http_headers: %w", err)
+	return bidders, rows.Err()
+}
+
+// GetGDPRScopes returns the gdpr_scope of every active bidder keyed by bidder_code
+func (s *BidderStore) GetGDPRScopes(ctx context.Context) (map[string]string, error) {
+	ctx, cancel := withTimeout(ctx, DefaultDBTimeout)
+	defer cancel()
+
+	query := `
+		SELECT bidder_code, gdpr_scope
+		FROM bidders
+		WHERE enabled = true AND status = 'active'
+	`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query bidder gdpr scopes: %w", err)
+	}
+	defer rows.Close()
+
+	scopes := make(map[string]string)
+	for rows.Next() {
+		var code, scope string
+		if err := rows.Scan(&code, &scope); err != nil {
+			return nil, fmt.Errorf("failed to scan bidder gdpr scope: %w", err)
+		}
+		scopes[code] = scope
+	}
+
+	return scopes, rows.Err()
+}
+
+// SetGDPRScope updates the GDPR enforcement scope of a bidder
+func (s *BidderStore) SetGDPRScope(ctx context.Context, bidderCode, scope string) error {
+	ctx, cancel := withTimeout(ctx, DefaultDBTimeout)
+	defer cancel()
+
+	query := `
+		UPDATE bidders
+		SET gdpr_scope = $1
+		WHERE bidder_code = $2
+	`
+
+	result, err := s.db.ExecContext(ctx, query, scope, bidderCode)
+	if err != nil {
+		return fmt.Errorf("failed to set bidder gdpr scope: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("bidder %w: %s", ErrNotFound, bidderCode)
+	}
+
+	return nil
+}
+
+// ExtPassthrough is a bidder's unknown ext field passthrough setting
+type ExtPassthrough struct {
+	Mode      string   `json:"mode"`
+	Allowlist []string `json:"allowlist,omitempty"`
+}
+
+// GetExtPassthroughPolicies returns the ext passthrough setting of every active
+// bidder keyed by bidder_code
+func (s *BidderStore) GetExtPassthroughPolicies(ctx context.Context) (map[string]ExtPassthrough, error) {
+	ctx, cancel := withTimeout(ctx, DefaultDBTimeout)
+	defer cancel()
+
+	query := `
+		SELECT bidder_code, ext_passthrough, ext_passthrough_allowlist
+		FROM bidders
+		WHERE enabled = true AND status = 'active'
+	`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query bidder ext passthrough: %w", err)
+	}
+	defer rows.Close()
+
+	policies := make(map[string]ExtPassthrough)
+	for rows.Next() {
+		var code string
+		var p ExtPassthrough
+		var allowlistJSON []byte
+		if err := rows.Scan(&code, &p.Mode, &allowlistJSON); err != nil {
+			return nil, fmt.Errorf("failed to scan bidder ext passthrough: %w", err)
+		}
+		if len(allowlistJSON) > 0 {
+			if err := json.Unmarshal(allowlistJSON, &p.Allowlist); err != nil {
+				return nil, fmt.Errorf("failed to parse ext passthrough allowlist for %s: %w", code, err)
 			}
 		}
+		policies[code] = p
+	}
+
+	return policies, rows.Err()
+}
+
+// GetUID2Bidders returns the bidder_code of every active bidder flagged
+// uid2_enabled
+func (s *BidderStore) GetUID2Bidders(ctx context.Context) (map[string]bool, error) {
+	ctx, cancel := withTimeout(ctx, DefaultDBTimeout)
+	defer cancel()
+
+	query := `
+		SELECT bidder_code
+		FROM bidders
+		WHERE enabled = true AND status = 'active' AND uid2_enabled = true
+	`
 
-		bidders = append(bidders, &b)
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query uid2 bidders: %w", err)
+	}
+	defer rows.Close()
+
+	bidders := make(map[string]bool)
+	for rows.Next() {
+		var code string
+		if err := rows.Scan(&code); err != nil {
+			return nil, fmt.Errorf("failed to scan uid2 bidder: %w", err)
+		}
+		bidders[code] = true
 	}
 
 	return bidders, rows.Err()
 }
+
+// GetMaxQPS returns the outbound QPS cap of every active bidder that has one,
+// keyed by bidder_code
+func (s *BidderStore) GetMaxQPS(ctx context.Context) (map[string]int, error) {
+	ctx, cancel := withTimeout(ctx, DefaultDBTimeout)
+	defer cancel()
+
+	query := `
+		SELECT bidder_code, max_qps
+		FROM bidders
+		WHERE enabled = true AND status = 'active' AND max_qps > 0
+	`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query bidder max qps: %w", err)
+	}
+	defer rows.Close()
+
+	limits := make(map[string]int)
+	for rows.Next() {
+		var code string
+		var qps int
+		if err := rows.Scan(&code, &qps); err != nil {
+			return nil, fmt.Errorf("failed to scan bidder max qps: %w", err)
+		}
+		limits[code] = qps
+	}
+
+	return limits, rows.Err()
+}
@@ -106,7 +106,7 @@ func TestBidderStore_Update_OptimisticLocking_VersionMismatch(t *testing.T) {
 		t.Fatal("Expected error for version mismatch, got nil")
 	}
 
-	if !contains(err.Error(), "concurrent modification detected") {
+	if !errors.Is(err, ErrConflict) || !contains(err.Error(), "concurrent modification detected") {
 		t.Errorf("Expected concurrent modification error, got: %v", err)
 	}
 
@@ -145,7 +145,7 @@ func TestBidderStore_Update_OptimisticLocking_NotFound(t *testing.T) {
 		t.Fatal("Expected error for non-existent bidder, got nil")
 	}
 
-	if !contains(err.Error(), "not found") {
+	if !errors.Is(err, ErrNotFound) {
 		t.Errorf("Expected not found error, got: %v", err)
 	}
 
@@ -189,7 +189,7 @@ func TestBidderStore_Update_OptimisticLocking_ZeroRowsAffected(t *testing.T) {
 		t.Fatal("Expected error for zero rows affected, got nil")
 	}
 
-	if !contains(err.Error(), "concurrent modification detected") {
+	if !errors.Is(err, ErrConflict) || !contains(err.Error(), "concurrent modification detected") {
 		t.Errorf("Expected concurrent modification error, got: %v", err)
 	}
 
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
 )
 
 func TestNewBidderStore(t *testing.T) {
@@ -110,8 +111,8 @@ func TestBidderStore_GetByCode_NotFound(t *testing.T) {
 		WillReturnError(sql.ErrNoRows)
 
 	bidder, err := store.GetByCode(ctx, "nonexistent")
-	if err != nil {
-		t.Fatalf("Expected no error for not found, got %v", err)
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound, got %v", err)
 	}
 
 	if bidder != nil {
@@ -694,6 +695,30 @@ func TestBidderStore_Create_Error(t *testing.T) {
 	}
 }
 
+// TestBidderStore_Create_Duplicate tests that a duplicate bidder_code is
+// reported as a conflict
+func TestBidderStore_Create_Duplicate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewBidderStore(db)
+
+	mock.ExpectQuery("INSERT INTO bidders").
+		WillReturnError(&pq.Error{Code: "23505", Message: "duplicate key value violates unique constraint"})
+
+	err = store.Create(context.Background(), createTestBidder("appnexus"))
+	if !errors.Is(err, ErrConflict) {
+		t.Errorf("Expected ErrConflict, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
 // TestBidderStore_Update_Success tests updating a bidder
 func TestBidderStore_Update_Success(t *testing.T) {
 	db, mock, err := sqlmock.New()
@@ -830,6 +855,48 @@ func TestBidderStore_Delete_NotFound(t *testing.T) {
 	}
 }
 
+// TestBidderStore_Restore tests restoring an archived bidder
+func TestBidderStore_Restore(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewBidderStore(db)
+	ctx := context.Background()
+
+	mock.ExpectExec("UPDATE bidders SET status = 'active', enabled = true WHERE bidder_code = \\$1 AND status = 'archived' AND version = \\$2").
+		WithArgs("appnexus", 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	b := &Bidder{BidderCode: "appnexus", EndpointURL: "https://ib.adnxs.com/openrtb2", Status: "archived", Version: 3}
+	if err := store.Restore(ctx, b); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if b.Status != "active" || !b.Enabled || b.Version != 4 {
+		t.Errorf("expected the bidder active and enabled at version 4, got %+v", b)
+	}
+
+	// Restored or changed meanwhile
+	mock.ExpectExec("UPDATE bidders SET status = 'active', enabled = true").
+		WithArgs("appnexus", 3).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	stale := &Bidder{BidderCode: "appnexus", EndpointURL: "https://ib.adnxs.com/openrtb2", Status: "archived", Version: 3}
+	if err := store.Restore(ctx, stale); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrConflict, got %v", err)
+	}
+
+	// Headers the policy now forbids are rejected before the update
+	forbidden := &Bidder{BidderCode: "appnexus", EndpointURL: "https://ib.adnxs.com/openrtb2", HTTPHeaders: map[string]interface{}{"Cookie": "uid=1"}}
+	if err := store.Restore(ctx, forbidden); !errors.Is(err, ErrValidation) {
+		t.Errorf("expected ErrValidation, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
 // TestBidderStore_SetEnabled_Success tests enabling a bidder
 func TestBidderStore_SetEnabled_Success(t *testing.T) {
 	db, mock, err := sqlmock.New()
@@ -1029,3 +1096,160 @@ func TestBidderStore_GetCapabilities_AllFormats(t *testing.T) {
 		t.Errorf("Unfulfilled expectations: %v", err)
 	}
 }
+
+// TestBidderStore_GetGDPRScopes tests loading per-bidder GDPR scopes
+func TestBidderStore_GetGDPRScopes(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewBidderStore(db)
+	ctx := context.Background()
+
+	rows := sqlmock.NewRows([]string{"bidder_code", "gdpr_scope"}).
+		AddRow("appnexus", "always").
+		AddRow("rubicon", "eea_only")
+
+	mock.ExpectQuery("SELECT bidder_code, gdpr_scope FROM bidders").
+		WillReturnRows(rows)
+
+	scopes, err := store.GetGDPRScopes(ctx)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(scopes) != 2 {
+		t.Fatalf("Expected 2 scopes, got %d", len(scopes))
+	}
+	if scopes["appnexus"] != "always" {
+		t.Errorf("Expected appnexus scope 'always', got %q", scopes["appnexus"])
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+// TestBidderStore_GetMaxQPS tests loading per-bidder outbound QPS caps
+func TestBidderStore_GetMaxQPS(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewBidderStore(db)
+	ctx := context.Background()
+
+	rows := sqlmock.NewRows([]string{"bidder_code", "max_qps"}).
+		AddRow("appnexus", 500)
+
+	mock.ExpectQuery("SELECT bidder_code, max_qps FROM bidders").
+		WillReturnRows(rows)
+
+	limits, err := store.GetMaxQPS(ctx)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(limits) != 1 || limits["appnexus"] != 500 {
+		t.Errorf("Expected appnexus capped at 500 QPS, got %v", limits)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+// TestBidderStore_GetUID2Bidders tests loading the bidders UID2 tokens go to
+func TestBidderStore_GetUID2Bidders(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewBidderStore(db)
+	ctx := context.Background()
+
+	rows := sqlmock.NewRows([]string{"bidder_code"}).
+		AddRow("appnexus").
+		AddRow("rubicon")
+
+	mock.ExpectQuery("SELECT bidder_code FROM bidders WHERE .* uid2_enabled = true").
+		WillReturnRows(rows)
+
+	bidders, err := store.GetUID2Bidders(ctx)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(bidders) != 2 || !bidders["appnexus"] || !bidders["rubicon"] {
+		t.Errorf("Expected appnexus and rubicon, got %v", bidders)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+// TestBidderStore_GetExtPassthroughPolicies tests loading ext passthrough policies
+func TestBidderStore_GetExtPassthroughPolicies(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewBidderStore(db)
+	ctx := context.Background()
+
+	rows := sqlmock.NewRows([]string{"bidder_code", "ext_passthrough", "ext_passthrough_allowlist"}).
+		AddRow("appnexus", "allowlist", []byte(`["custom","imp.partner_slot"]`)).
+		AddRow("rubicon", "none", []byte(`[]`))
+
+	mock.ExpectQuery("SELECT bidder_code, ext_passthrough, ext_passthrough_allowlist FROM bidders").
+		WillReturnRows(rows)
+
+	policies, err := store.GetExtPassthroughPolicies(ctx)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(policies) != 2 {
+		t.Fatalf("Expected 2 policies, got %d", len(policies))
+	}
+	if policies["appnexus"].Mode != "allowlist" || len(policies["appnexus"].Allowlist) != 2 {
+		t.Errorf("Unexpected appnexus policy: %+v", policies["appnexus"])
+	}
+	if policies["rubicon"].Mode != "none" {
+		t.Errorf("Expected rubicon mode 'none', got %q", policies["rubicon"].Mode)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+// TestBidderStore_SetGDPRScope_NotFound tests setting scope on non-existent bidder
+func TestBidderStore_SetGDPRScope_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewBidderStore(db)
+	ctx := context.Background()
+
+	mock.ExpectExec("UPDATE bidders SET gdpr_scope = (.+) WHERE bidder_code").
+		WithArgs("never", "nonexistent").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = store.SetGDPRScope(ctx, "nonexistent", "never")
+	if err == nil {
+		t.Error("Expected error for non-existent bidder, got nil")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/mail"
+)
+
+// Billing holds a publisher's payment terms and invoice contact
+type Billing struct {
+	PaymentTerms        string `json:"payment_terms"`    // net-30 or net-60
+	BillingCurrency     string `json:"billing_currency"` // ISO 4217, e.g. USD
+	InvoiceContactName  string `json:"invoice_contact_name,omitempty"`
+	InvoiceContactEmail string `json:"invoice_contact_email,omitempty"`
+}
+
+// Payment terms a publisher can be on
+const (
+	PaymentTermsNet30 = "net-30"
+	PaymentTermsNet60 = "net-60"
+)
+
+// withDefaults fills in net-30 and USD when the terms or currency are unset
+func (b Billing) withDefaults() Billing {
+	if b.PaymentTerms == "" {
+		b.PaymentTerms = PaymentTermsNet30
+	}
+	if b.BillingCurrency == "" {
+		b.BillingCurrency = "USD"
+	}
+	return b
+}
+
+// Validate checks the fields against the publishers table constraints;
+// unset terms and currency are valid and default to net-30 and USD
+func (b Billing) Validate() error {
+	switch b.PaymentTerms {
+	case "", PaymentTermsNet30, PaymentTermsNet60:
+	default:
+		return fmt.Errorf("%w payment_terms: must be %q or %q, got %q", ErrValidation, PaymentTermsNet30, PaymentTermsNet60, b.PaymentTerms)
+	}
+	if b.BillingCurrency != "" && !isCurrencyCode(b.BillingCurrency) {
+		return fmt.Errorf("%w billing_currency: must be an ISO 4217 code such as USD, got %q", ErrValidation, b.BillingCurrency)
+	}
+	if len(b.InvoiceContactName) > 255 || len(b.InvoiceContactEmail) > 255 {
+		return fmt.Errorf("%w invoice contact: fields must be at most 255 characters", ErrValidation)
+	}
+	if b.InvoiceContactEmail != "" {
+		if addr, err := mail.ParseAddress(b.InvoiceContactEmail); err != nil || addr.Address != b.InvoiceContactEmail {
+			return fmt.Errorf("%w invoice_contact_email: must be a plain email address, got %q", ErrValidation, b.InvoiceContactEmail)
+		}
+	}
+	return nil
+}
+
+// isCurrencyCode reports whether s is three uppercase letters
+func isCurrencyCode(s string) bool {
+	if len(s) != 3 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < 'A' || s[i] > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// GetBilling returns a publisher's billing details whatever its status.
+// Returns ErrNotFound if the publisher doesn't exist.
+func (s *PublisherStore) GetBilling(ctx context.Context, publisherID string) (*Billing, error) {
+	ctx, cancel := withTimeout(ctx, DefaultDBTimeout)
+	defer cancel()
+
+	var b Billing
+	err := s.db.QueryRowContext(ctx, `
+		SELECT payment_terms, billing_currency, invoice_contact_name, invoice_contact_email
+		FROM publishers
+		WHERE publisher_id = $1
+	`, publisherID).Scan(&b.PaymentTerms, &b.BillingCurrency, &b.InvoiceContactName, &b.InvoiceContactEmail)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("publisher %w: %s", ErrNotFound, publisherID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query publisher billing: %w", err)
+	}
+	return &b, nil
+}
+
+// UpdateBilling replaces a publisher's billing details, leaving the rest of
+// the row alone; the change is versioned and recorded in publisher_history
+// like any other update. Returns ErrNotFound if the publisher doesn't exist.
+func (s *PublisherStore) UpdateBilling(ctx context.Context, publisherID string, b Billing) (*Billing, error) {
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+	b = b.withDefaults()
+
+	ctx, cancel := withTimeout(ctx, DefaultDBTimeout)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE publishers
+		SET payment_terms = $1, billing_currency = $2, invoice_contact_name = $3, invoice_contact_email = $4
+		WHERE publisher_id = $5
+	`, b.PaymentTerms, b.BillingCurrency, b.InvoiceContactName, b.InvoiceContactEmail, publisherID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update publisher billing: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return nil, fmt.Errorf("publisher %w: %s", ErrNotFound, publisherID)
+	}
+	return &b, nil
+}
+
+// ListBilling returns every publisher's billing details by publisher ID,
+// including paused and archived publishers that may still be owed payouts
+func (s *PublisherStore) ListBilling(ctx context.Context) (map[string]Billing, error) {
+	ctx, cancel := withTimeout(ctx, DefaultDBTimeout)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT publisher_id, payment_terms, billing_currency, invoice_contact_name, invoice_contact_email
+		FROM publishers
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query publisher billing: %w", err)
+	}
+	defer rows.Close()
+
+	billing := make(map[string]Billing)
+	for rows.Next() {
+		var id string
+		var b Billing
+		if err := rows.Scan(&id, &b.PaymentTerms, &b.BillingCurrency, &b.InvoiceContactName, &b.InvoiceContactEmail); err != nil {
+			return nil, fmt.Errorf("failed to scan publisher billing: %w", err)
+		}
+		billing[id] = b
+	}
+	return billing, rows.Err()
+}
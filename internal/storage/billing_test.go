@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// TestBilling_Validate tests the payment terms, currency and email checks
+func TestBilling_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		billing Billing
+		wantErr bool
+	}{
+		{"empty uses defaults", Billing{}, false},
+		{"net-60 in EUR", Billing{PaymentTerms: PaymentTermsNet60, BillingCurrency: "EUR", InvoiceContactName: "AP", InvoiceContactEmail: "ap@example.com"}, false},
+		{"unknown terms", Billing{PaymentTerms: "net-90"}, true},
+		{"lowercase currency", Billing{BillingCurrency: "usd"}, true},
+		{"long currency", Billing{BillingCurrency: "USDT"}, true},
+		{"invalid email", Billing{InvoiceContactEmail: "accounts payable"}, true},
+		{"email with display name", Billing{InvoiceContactEmail: "AP <ap@example.com>"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.billing.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestPublisherStore_UpdateBilling tests defaulting and not-found handling
+func TestPublisherStore_UpdateBilling(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewPublisherStore(db)
+
+	mock.ExpectExec("UPDATE publishers SET payment_terms").
+		WithArgs("net-30", "USD", "AP", "ap@example.com", "pub1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE publishers SET payment_terms").
+		WithArgs("net-60", "GBP", "", "", "missing").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	b, err := store.UpdateBilling(context.Background(), "pub1", Billing{InvoiceContactName: "AP", InvoiceContactEmail: "ap@example.com"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if b == nil || b.PaymentTerms != PaymentTermsNet30 || b.BillingCurrency != "USD" {
+		t.Errorf("Expected net-30 USD defaults, got %+v", b)
+	}
+
+	b, err = store.UpdateBilling(context.Background(), "missing", Billing{PaymentTerms: PaymentTermsNet60, BillingCurrency: "GBP"})
+	if !errors.Is(err, ErrNotFound) || b != nil {
+		t.Errorf("Expected ErrNotFound for a missing publisher, got %+v, %v", b, err)
+	}
+
+	if _, err := store.UpdateBilling(context.Background(), "pub1", Billing{PaymentTerms: "net-90"}); !errors.Is(err, ErrValidation) {
+		t.Errorf("Expected invalid payment terms to be rejected before querying, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+// TestPublisherStore_ListBilling tests loading billing for every publisher
+func TestPublisherStore_ListBilling(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewPublisherStore(db)
+
+	mock.ExpectQuery("SELECT publisher_id, payment_terms, billing_currency, invoice_contact_name, invoice_contact_email FROM publishers").
+		WillReturnRows(sqlmock.NewRows([]string{"publisher_id", "payment_terms", "billing_currency", "invoice_contact_name", "invoice_contact_email"}).
+			AddRow("pub1", "net-30", "USD", "", "").
+			AddRow("pub2", "net-60", "EUR", "AP", "ap@example.com"))
+
+	billing, err := store.ListBilling(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(billing) != 2 || billing["pub2"].PaymentTerms != PaymentTermsNet60 || billing["pub2"].InvoiceContactEmail != "ap@example.com" {
+		t.Errorf("Unexpected billing: %+v", billing)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
@@ -0,0 +1,189 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Creative review statuses (creatives.status)
+const (
+	CreativeStatusNew      = "new"
+	CreativeStatusApproved = "approved"
+	CreativeStatusBlocked  = "blocked"
+)
+
+// Creative is a creative returned by a bidder and its review status (see
+// migration 018)
+type Creative struct {
+	Hash        string    `json:"hash"`
+	Status      string    `json:"status"`
+	BidderCode  string    `json:"bidder_code,omitempty"`
+	CreativeID  string    `json:"creative_id,omitempty"`
+	ADomain     string    `json:"adomain,omitempty"` // comma-separated advertiser domains
+	Markup      string    `json:"markup,omitempty"`  // markup of the first bid seen, for review
+	FirstSeenAt time.Time `json:"first_seen_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// creativeSortFields are the columns ListPage can sort creatives by
+var creativeSortFields = []string{"hash", "status", "bidder_code", "first_seen_at", "updated_at"}
+
+// CreativeStore reads and writes the creative registry
+type CreativeStore struct {
+	db *sql.DB
+}
+
+// NewCreativeStore creates a new creative store
+func NewCreativeStore(db *sql.DB) *CreativeStore {
+	return &CreativeStore{db: db}
+}
+
+// LoadStatuses returns the status of every reviewed (approved or blocked)
+// creative by hash. Creatives still new aren't returned.
+func (s *CreativeStore) LoadStatuses(ctx context.Context) (map[string]string, error) {
+	ctx, cancel := withTimeout(ctx, DefaultDBTimeout)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `SELECT hash, status FROM creatives WHERE status IN ($1, $2)`,
+		CreativeStatusApproved, CreativeStatusBlocked)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query creatives: %w", err)
+	}
+	defer rows.Close()
+
+	statuses := make(map[string]string)
+	for rows.Next() {
+		var hash, status string
+		if err := rows.Scan(&hash, &status); err != nil {
+			return nil, fmt.Errorf("failed to scan creative status: %w", err)
+		}
+		statuses[hash] = status
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating creatives: %w", err)
+	}
+	return statuses, nil
+}
+
+// InsertNew registers newly seen creatives with status new. Creatives already
+// registered, possibly by another instance, keep their row.
+func (s *CreativeStore) InsertNew(ctx context.Context, creatives []*Creative) error {
+	if len(creatives) == 0 {
+		return nil
+	}
+
+	ctx, cancel := withTimeout(ctx, DefaultDBTimeout)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO creatives (hash, status, bidder_code, creative_id, adomain, markup)
+		VALUES ($1, 'new', $2, $3, $4, $5)
+		ON CONFLICT (hash) DO NOTHING
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare creative insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, c := range creatives {
+		if _, err := stmt.ExecContext(ctx, c.Hash, c.BidderCode, c.CreativeID, c.ADomain, c.Markup); err != nil {
+			return fmt.Errorf("failed to insert creative: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit creatives: %w", err)
+	}
+	return nil
+}
+
+// SetStatus sets the review status of every creative in hashes. Hashes that
+// aren't registered yet are added, so creatives can be approved or blocked
+// before they're first bid.
+func (s *CreativeStore) SetStatus(ctx context.Context, hashes []string, status string) error {
+	switch status {
+	case CreativeStatusNew, CreativeStatusApproved, CreativeStatusBlocked:
+	default:
+		return fmt.Errorf("%w creative status: %q", ErrValidation, status)
+	}
+	if len(hashes) == 0 {
+		return nil
+	}
+
+	ctx, cancel := withTimeout(ctx, DefaultDBTimeout)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO creatives (hash, status)
+		VALUES ($1, $2)
+		ON CONFLICT (hash) DO UPDATE SET
+			status = EXCLUDED.status,
+			updated_at = CURRENT_TIMESTAMP
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare creative status update: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, hash := range hashes {
+		if _, err := stmt.ExecContext(ctx, hash, status); err != nil {
+			return fmt.Errorf("failed to update creative status: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit creative statuses: %w", err)
+	}
+	return nil
+}
+
+// ListPage returns one page of creatives matching opts along with the total
+// number of creatives matching its status filter
+func (s *CreativeStore) ListPage(ctx context.Context, opts ListOptions) ([]*Creative, int, error) {
+	lq, err := opts.buildListQuery(`hash, status, bidder_code, creative_id, adomain, markup, first_seen_at, updated_at`,
+		"creatives", "hash", creativeSortFields)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ctx, cancel := withTimeout(ctx, DefaultDBTimeout)
+	defer cancel()
+
+	var total int
+	if err := s.db.QueryRowContext(ctx, lq.countQuery, lq.countArgs...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count creatives: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, lq.query, lq.args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query creatives: %w", err)
+	}
+	defer rows.Close()
+
+	creatives := make([]*Creative, 0, opts.PageLimit())
+	for rows.Next() {
+		c := &Creative{}
+		if err := rows.Scan(&c.Hash, &c.Status, &c.BidderCode, &c.CreativeID, &c.ADomain, &c.Markup, &c.FirstSeenAt, &c.UpdatedAt); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan creative row: %w", err)
+		}
+		creatives = append(creatives, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating creatives: %w", err)
+	}
+	return creatives, total, nil
+}
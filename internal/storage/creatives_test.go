@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// TestCreativeStore_LoadStatuses tests loading reviewed creatives' statuses
+func TestCreativeStore_LoadStatuses(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewCreativeStore(db)
+	mock.ExpectQuery("SELECT hash, status FROM creatives WHERE status IN").
+		WithArgs(CreativeStatusApproved, CreativeStatusBlocked).
+		WillReturnRows(sqlmock.NewRows([]string{"hash", "status"}).
+			AddRow("aaa", CreativeStatusApproved).
+			AddRow("bbb", CreativeStatusBlocked))
+
+	statuses, err := store.LoadStatuses(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(statuses) != 2 || statuses["aaa"] != CreativeStatusApproved || statuses["bbb"] != CreativeStatusBlocked {
+		t.Errorf("Unexpected statuses: %v", statuses)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+// TestCreativeStore_InsertNew tests registering newly seen creatives without
+// overwriting reviewed ones
+func TestCreativeStore_InsertNew(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewCreativeStore(db)
+	mock.ExpectBegin()
+	mock.ExpectPrepare("INSERT INTO creatives .+ ON CONFLICT \\(hash\\) DO NOTHING").
+		ExpectExec().
+		WithArgs("aaa", "appnexus", "cr-1", "example.com", "<div>ad</div>").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	creatives := []*Creative{{Hash: "aaa", BidderCode: "appnexus", CreativeID: "cr-1", ADomain: "example.com", Markup: "<div>ad</div>"}}
+	if err := store.InsertNew(context.Background(), creatives); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+// TestCreativeStore_SetStatus tests bulk status changes, including hashes
+// not registered yet
+func TestCreativeStore_SetStatus(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewCreativeStore(db)
+	mock.ExpectBegin()
+	prep := mock.ExpectPrepare("INSERT INTO creatives .+ ON CONFLICT \\(hash\\) DO UPDATE")
+	prep.ExpectExec().WithArgs("aaa", CreativeStatusBlocked).WillReturnResult(sqlmock.NewResult(0, 1))
+	prep.ExpectExec().WithArgs("bbb", CreativeStatusBlocked).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err := store.SetStatus(context.Background(), []string{"aaa", "bbb"}, CreativeStatusBlocked); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+
+	if err := store.SetStatus(context.Background(), []string{"aaa"}, "pending"); !errors.Is(err, ErrValidation) {
+		t.Errorf("Expected a validation error for an unknown status, got %v", err)
+	}
+}
+
+// TestCreativeStore_ListPage tests listing creatives by status
+func TestCreativeStore_ListPage(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewCreativeStore(db)
+	seen := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM creatives WHERE status = $1")).
+		WithArgs(CreativeStatusNew).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(7))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM creatives WHERE status = $1 ORDER BY first_seen_at DESC, hash DESC LIMIT $2")).
+		WithArgs(CreativeStatusNew, 1).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"hash", "status", "bidder_code", "creative_id", "adomain", "markup", "first_seen_at", "updated_at",
+		}).AddRow("aaa", CreativeStatusNew, "appnexus", "cr-1", "example.com", "<div>ad</div>", seen, seen))
+
+	creatives, total, err := store.ListPage(context.Background(), ListOptions{Limit: 1, Status: CreativeStatusNew, Sort: "-first_seen_at"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if total != 7 || len(creatives) != 1 || creatives[0].Hash != "aaa" || creatives[0].BidderCode != "appnexus" {
+		t.Errorf("Unexpected page: total %d, %+v", total, creatives)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
@@ -0,0 +1,138 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Deal is a programmatic guaranteed deal booking (see migration 013)
+type Deal struct {
+	DealID           string    `json:"deal_id"`
+	PublisherID      string    `json:"publisher_id,omitempty"`
+	BidderCode       string    `json:"bidder_code,omitempty"`
+	DailyImpressions int64     `json:"daily_impressions"` // booked per UTC day
+	StartDate        time.Time `json:"start_date"`
+	EndDate          time.Time `json:"end_date"` // inclusive
+}
+
+// DealDelivery is the number of impressions delivered for a deal on a UTC day
+type DealDelivery struct {
+	Day         time.Time `json:"day"`
+	DealID      string    `json:"deal_id"`
+	Impressions int64     `json:"impressions"`
+}
+
+// DealStore reads deal bookings and writes their delivery
+type DealStore struct {
+	db *sql.DB
+}
+
+// NewDealStore creates a new deal store
+func NewDealStore(db *sql.DB) *DealStore {
+	return &DealStore{db: db}
+}
+
+// ListActive returns the active deals booked on day
+func (s *DealStore) ListActive(ctx context.Context, day time.Time) ([]*Deal, error) {
+	ctx, cancel := withTimeout(ctx, DefaultDBTimeout)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT deal_id, publisher_id, bidder_code, daily_impressions, start_date, end_date
+		FROM deals
+		WHERE status = 'active' AND start_date <= $1 AND end_date >= $1
+		ORDER BY deal_id
+	`, day.UTC().Format(time.DateOnly))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query deals: %w", err)
+	}
+	defer rows.Close()
+
+	var deals []*Deal
+	for rows.Next() {
+		d := &Deal{}
+		if err := rows.Scan(&d.DealID, &d.PublisherID, &d.BidderCode, &d.DailyImpressions, &d.StartDate, &d.EndDate); err != nil {
+			return nil, fmt.Errorf("failed to scan deal row: %w", err)
+		}
+		deals = append(deals, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating deals: %w", err)
+	}
+	return deals, nil
+}
+
+// UpsertDelivery writes an instance's running delivery totals. Each row
+// replaces the instance's previous total for its day, so repeating a flush
+// is harmless.
+func (s *DealStore) UpsertDelivery(ctx context.Context, instanceID string, rows []*DealDelivery) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	ctx, cancel := withTimeout(ctx, DefaultDBTimeout)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO deal_delivery (day, deal_id, instance_id, impressions)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (day, deal_id, instance_id) DO UPDATE SET
+			impressions = EXCLUDED.impressions,
+			updated_at = CURRENT_TIMESTAMP
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare deal delivery upsert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, r := range rows {
+		if _, err := stmt.ExecContext(ctx, r.Day.UTC().Format(time.DateOnly), r.DealID, instanceID, r.Impressions); err != nil {
+			return fmt.Errorf("failed to upsert deal delivery: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit deal delivery: %w", err)
+	}
+	return nil
+}
+
+// QueryDelivery returns the impressions delivered per deal on day, summed
+// across instances
+func (s *DealStore) QueryDelivery(ctx context.Context, day time.Time) (map[string]int64, error) {
+	ctx, cancel := withTimeout(ctx, DefaultDBTimeout)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT deal_id, SUM(impressions)
+		FROM deal_delivery
+		WHERE day = $1
+		GROUP BY deal_id
+	`, day.UTC().Format(time.DateOnly))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query deal delivery: %w", err)
+	}
+	defer rows.Close()
+
+	delivered := make(map[string]int64)
+	for rows.Next() {
+		var dealID string
+		var impressions int64
+		if err := rows.Scan(&dealID, &impressions); err != nil {
+			return nil, fmt.Errorf("failed to scan deal delivery: %w", err)
+		}
+		delivered[dealID] = impressions
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating deal delivery: %w", err)
+	}
+	return delivered, nil
+}
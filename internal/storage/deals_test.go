@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// TestDealStore_ListActive tests loading the deals booked on a day
+func TestDealStore_ListActive(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewDealStore(db)
+	day := time.Date(2026, 3, 1, 15, 0, 0, 0, time.UTC)
+	start := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT deal_id, publisher_id, bidder_code, daily_impressions, start_date, end_date FROM deals").
+		WithArgs("2026-03-01").
+		WillReturnRows(sqlmock.NewRows([]string{"deal_id", "publisher_id", "bidder_code", "daily_impressions", "start_date", "end_date"}).
+			AddRow("pg-1", "pub1", "appnexus", int64(10000), start, end))
+
+	deals, err := store.ListActive(context.Background(), day)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(deals) != 1 || deals[0].DealID != "pg-1" || deals[0].DailyImpressions != 10000 {
+		t.Errorf("Unexpected deals: %+v", deals)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+// TestDealStore_UpsertDelivery tests writing an instance's running totals
+func TestDealStore_UpsertDelivery(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewDealStore(db)
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare("INSERT INTO deal_delivery .+ ON CONFLICT \\(day, deal_id, instance_id\\) DO UPDATE").
+		ExpectExec().
+		WithArgs("2026-03-01", "pg-1", "host-1", int64(42)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	rows := []*DealDelivery{{Day: day, DealID: "pg-1", Impressions: 42}}
+	if err := store.UpsertDelivery(context.Background(), "host-1", rows); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+// TestDealStore_QueryDelivery tests reading delivery summed across instances
+func TestDealStore_QueryDelivery(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewDealStore(db)
+
+	mock.ExpectQuery("SELECT deal_id, SUM\\(impressions\\) FROM deal_delivery").
+		WithArgs("2026-03-01").
+		WillReturnRows(sqlmock.NewRows([]string{"deal_id", "sum"}).AddRow("pg-1", int64(1500)))
+
+	delivered, err := store.QueryDelivery(context.Background(), time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if delivered["pg-1"] != 1500 {
+		t.Errorf("Expected 1500 impressions for pg-1, got %v", delivered)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+// Sentinel errors wrapped by every store, so callers can tell a missing row,
+// a lost update and bad input from a database failure with errors.Is. The
+// texts read naturally inside the wrapping message, e.g.
+// "bidder not found: rubicon" or "invalid http_headers: ...".
+var (
+	// ErrNotFound is wrapped when the requested row does not exist (or is
+	// not visible, e.g. archived rows for active-only lookups)
+	ErrNotFound = errors.New("not found")
+
+	// ErrConflict is wrapped when a write loses an optimistic-locking race or
+	// would duplicate a unique key
+	ErrConflict = errors.New("conflict")
+
+	// ErrValidation is wrapped when input is rejected before reaching the
+	// database
+	ErrValidation = errors.New("invalid")
+)
+
+// pqUniqueViolation is the PostgreSQL error code for a unique constraint
+// violation
+const pqUniqueViolation = "23505"
+
+// isUniqueViolation reports whether err is a PostgreSQL unique violation
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == pqUniqueViolation
+}
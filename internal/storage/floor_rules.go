@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// FloorRule is a publisher price floor for the impressions matching every
+// dimension it sets; empty dimensions match anything (see migration 024)
+type FloorRule struct {
+	ID          string    `json:"id"`
+	PublisherID string    `json:"publisher_id"`
+	MediaType   string    `json:"media_type,omitempty"`  // banner, video, native or audio
+	Size        string    `json:"size,omitempty"`        // WxH
+	Country     string    `json:"country,omitempty"`     // ISO 3166-1 alpha-3
+	DeviceType  string    `json:"device_type,omitempty"` // mobile, desktop or ctv
+	FloorCPM    float64   `json:"floor_cpm"`             // exchange currency
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// FloorRuleStore reads publisher floor rules
+type FloorRuleStore struct {
+	db *sql.DB
+}
+
+// NewFloorRuleStore creates a new floor rule store
+func NewFloorRuleStore(db *sql.DB) *FloorRuleStore {
+	return &FloorRuleStore{db: db}
+}
+
+// LoadActive returns every active floor rule, grouped by publisher
+func (s *FloorRuleStore) LoadActive(ctx context.Context) ([]*FloorRule, error) {
+	ctx, cancel := withTimeout(ctx, DefaultDBTimeout)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, publisher_id, media_type, size, country, device_type, floor_cpm,
+		       active, created_at, updated_at
+		FROM floor_rules
+		WHERE active
+		ORDER BY publisher_id, id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query floor rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []*FloorRule
+	for rows.Next() {
+		r := &FloorRule{}
+		if err := rows.Scan(
+			&r.ID, &r.PublisherID, &r.MediaType, &r.Size, &r.Country, &r.DeviceType, &r.FloorCPM,
+			&r.Active, &r.CreatedAt, &r.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan floor rule row: %w", err)
+		}
+		rules = append(rules, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating floor rules: %w", err)
+	}
+	return rules, nil
+}
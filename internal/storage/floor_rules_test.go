@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// TestFloorRuleStore_LoadActive tests loading active floor rules
+func TestFloorRuleStore_LoadActive(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewFloorRuleStore(db)
+	now := time.Now()
+	mock.ExpectQuery("SELECT id, publisher_id, media_type, size, country, device_type, floor_cpm.+FROM floor_rules.+WHERE active.+ORDER BY publisher_id, id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "publisher_id", "media_type", "size", "country", "device_type", "floor_cpm",
+			"active", "created_at", "updated_at"}).
+			AddRow("ctv-usa", "pub1", "video", "", "USA", "ctv", 12.5, true, now, now).
+			AddRow("mrec", "pub1", "banner", "300x250", "", "", 0.8, true, now, now))
+
+	rules, err := store.LoadActive(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(rules) != 2 {
+		t.Fatalf("Expected 2 floor rules, got %d", len(rules))
+	}
+	if r := rules[0]; r.ID != "ctv-usa" || r.MediaType != "video" || r.Country != "USA" || r.DeviceType != "ctv" || r.FloorCPM != 12.5 {
+		t.Errorf("Unexpected floor rule: %+v", r)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+// TestFloorRuleStore_LoadActive_Error tests query failures are wrapped
+func TestFloorRuleStore_LoadActive_Error(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewFloorRuleStore(db)
+	mock.ExpectQuery("SELECT id, publisher_id").WillReturnError(errors.New("connection refused"))
+
+	if _, err := store.LoadActive(context.Background()); err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+}
@@ -0,0 +1,183 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ConfigVersion is one stored version of a publisher or bidder row.
+// Snapshot holds the full row as written (see migration 007).
+type ConfigVersion struct {
+	Version   int             `json:"version"`
+	Snapshot  json.RawMessage `json:"snapshot"`
+	ChangedAt time.Time       `json:"changed_at"`
+}
+
+// publisherRollbackQuery restores a publisher's editable columns from a
+// history snapshot. Columns missing from older snapshots keep their current
+// value; the version trigger bumps the version and the history trigger
+// records the result as a new entry.
+const publisherRollbackQuery = `
+	UPDATE publishers p
+	SET name = COALESCE(s.name, p.name),
+	    allowed_domains = COALESCE(s.allowed_domains, p.allowed_domains),
+	    bidder_params = COALESCE(s.bidder_params, p.bidder_params),
+	    bid_multiplier = COALESCE(s.bid_multiplier, p.bid_multiplier),
+	    status = COALESCE(s.status, p.status),
+	    notes = s.notes,
+	    contact_email = s.contact_email,
+	    blocked_attributes = COALESCE(s.blocked_attributes, p.blocked_attributes),
+	    max_bid_cpm = COALESCE(s.max_bid_cpm, p.max_bid_cpm),
+	    player_config = COALESCE(s.player_config, p.player_config),
+	    slo_p95_ms = COALESCE(s.slo_p95_ms, p.slo_p95_ms),
+	    creative_sanitization = COALESCE(s.creative_sanitization, p.creative_sanitization),
+	    language_filter = COALESCE(s.language_filter, p.language_filter),
+	    required_language = COALESCE(s.required_language, p.required_language),
+	    creative_approval = COALESCE(s.creative_approval, p.creative_approval),
+	    allowed_countries = COALESCE(s.allowed_countries, p.allowed_countries),
+	    blocked_countries = COALESCE(s.blocked_countries, p.blocked_countries),
+	    timeout_ms = COALESCE(s.timeout_ms, p.timeout_ms),
+	    max_bidders = COALESCE(s.max_bidders, p.max_bidders),
+	    allowed_bidders = COALESCE(s.allowed_bidders, p.allowed_bidders),
+	    blocked_bidders = COALESCE(s.blocked_bidders, p.blocked_bidders),
+	    pod_min_cpm = COALESCE(s.pod_min_cpm, p.pod_min_cpm),
+	    pod_max_bidder_share = COALESCE(s.pod_max_bidder_share, p.pod_max_bidder_share),
+	    payment_terms = COALESCE(s.payment_terms, p.payment_terms),
+	    billing_currency = COALESCE(s.billing_currency, p.billing_currency),
+	    invoice_contact_name = COALESCE(s.invoice_contact_name, p.invoice_contact_name),
+	    invoice_contact_email = COALESCE(s.invoice_contact_email, p.invoice_contact_email)
+	FROM publisher_history h, jsonb_populate_record(NULL::publishers, h.snapshot) s
+	WHERE h.publisher_id = $1 AND h.version = $2 AND p.publisher_id = $1
+	RETURNING p.version
+`
+
+// bidderRollbackQuery restores a bidder's editable columns from a history
+// snapshot, like publisherRollbackQuery
+const bidderRollbackQuery = `
+	UPDATE bidders b
+	SET bidder_name = COALESCE(s.bidder_name, b.bidder_name),
+	    endpoint_url = COALESCE(s.endpoint_url, b.endpoint_url),
+	    timeout_ms = COALESCE(s.timeout_ms, b.timeout_ms),
+	    enabled = COALESCE(s.enabled, b.enabled),
+	    status = COALESCE(s.status, b.status),
+	    supports_banner = COALESCE(s.supports_banner, b.supports_banner),
+	    supports_video = COALESCE(s.supports_video, b.supports_video),
+	    supports_native = COALESCE(s.supports_native, b.supports_native),
+	    supports_audio = COALESCE(s.supports_audio, b.supports_audio),
+	    gvl_vendor_id = s.gvl_vendor_id,
+	    http_headers = COALESCE(s.http_headers, b.http_headers),
+	    description = s.description,
+	    documentation_url = s.documentation_url,
+	    contact_email = s.contact_email,
+	    gdpr_scope = COALESCE(s.gdpr_scope, b.gdpr_scope),
+	    ext_passthrough = COALESCE(s.ext_passthrough, b.ext_passthrough),
+	    ext_passthrough_allowlist = COALESCE(s.ext_passthrough_allowlist, b.ext_passthrough_allowlist),
+	    max_qps = COALESCE(s.max_qps, b.max_qps),
+	    uid2_enabled = COALESCE(s.uid2_enabled, b.uid2_enabled)
+	FROM bidder_history h, jsonb_populate_record(NULL::bidders, h.snapshot) s
+	WHERE h.bidder_code = $1 AND h.version = $2 AND b.bidder_code = $1
+	RETURNING b.version
+`
+
+// History returns every stored version of a publisher, newest first
+func (s *PublisherStore) History(ctx context.Context, publisherID string) ([]*ConfigVersion, error) {
+	return queryHistory(ctx, s.db, `
+		SELECT version, snapshot, changed_at
+		FROM publisher_history
+		WHERE publisher_id = $1
+		ORDER BY version DESC
+	`, publisherID)
+}
+
+// Rollback restores a publisher to a stored version by writing it as a new
+// version. Returns ErrNotFound if the publisher or version doesn't exist.
+func (s *PublisherStore) Rollback(ctx context.Context, publisherID string, version int) (*ConfigVersion, error) {
+	return rollback(ctx, s.db, publisherRollbackQuery, `
+		SELECT version, snapshot, changed_at
+		FROM publisher_history
+		WHERE publisher_id = $1 AND version = $2
+	`, publisherID, version)
+}
+
+// History returns every stored version of a bidder, newest first
+func (s *BidderStore) History(ctx context.Context, bidderCode string) ([]*ConfigVersion, error) {
+	return queryHistory(ctx, s.db, `
+		SELECT version, snapshot, changed_at
+		FROM bidder_history
+		WHERE bidder_code = $1
+		ORDER BY version DESC
+	`, bidderCode)
+}
+
+// Rollback restores a bidder to a stored version by writing it as a new
+// version. Returns ErrNotFound if the bidder or version doesn't exist.
+func (s *BidderStore) Rollback(ctx context.Context, bidderCode string, version int) (*ConfigVersion, error) {
+	return rollback(ctx, s.db, bidderRollbackQuery, `
+		SELECT version, snapshot, changed_at
+		FROM bidder_history
+		WHERE bidder_code = $1 AND version = $2
+	`, bidderCode, version)
+}
+
+// queryHistory loads history rows for one publisher or bidder
+func queryHistory(ctx context.Context, db *sql.DB, query, id string) ([]*ConfigVersion, error) {
+	ctx, cancel := withTimeout(ctx, DefaultDBTimeout)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query history: %w", err)
+	}
+	defer rows.Close()
+
+	versions := make([]*ConfigVersion, 0)
+	for rows.Next() {
+		var v ConfigVersion
+		var snapshot []byte
+		if err := rows.Scan(&v.Version, &snapshot, &v.ChangedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan history row: %w", err)
+		}
+		v.Snapshot = json.RawMessage(snapshot)
+		versions = append(versions, &v)
+	}
+
+	return versions, rows.Err()
+}
+
+// rollback applies a rollback update and returns the history entry it created
+func rollback(ctx context.Context, db *sql.DB, updateQuery, selectQuery, id string, version int) (*ConfigVersion, error) {
+	ctx, cancel := withTimeout(ctx, DefaultDBTimeout)
+	defer cancel()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var newVersion int
+	err = tx.QueryRowContext(ctx, updateQuery, id, version).Scan(&newVersion)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("version %d of %s %w", version, id, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to roll back to version %d: %w", version, err)
+	}
+
+	var v ConfigVersion
+	var snapshot []byte
+	err = tx.QueryRowContext(ctx, selectQuery, id, newVersion).Scan(&v.Version, &snapshot, &v.ChangedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rolled back version: %w", err)
+	}
+	v.Snapshot = json.RawMessage(snapshot)
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &v, nil
+}
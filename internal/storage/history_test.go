@@ -0,0 +1,166 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// TestPublisherStore_History tests listing publisher versions
+func TestPublisherStore_History(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewPublisherStore(db)
+	now := time.Now()
+
+	rows := sqlmock.NewRows([]string{"version", "snapshot", "changed_at"}).
+		AddRow(2, []byte(`{"publisher_id":"pub1","bidder_params":{"rubicon":{"zoneId":2}}}`), now).
+		AddRow(1, []byte(`{"publisher_id":"pub1","bidder_params":{"rubicon":{"zoneId":1}}}`), now.Add(-time.Hour))
+
+	mock.ExpectQuery("SELECT version, snapshot, changed_at FROM publisher_history WHERE publisher_id = (.+) ORDER BY version DESC").
+		WithArgs("pub1").
+		WillReturnRows(rows)
+
+	versions, err := store.History(context.Background(), "pub1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(versions) != 2 {
+		t.Fatalf("Expected 2 versions, got %d", len(versions))
+	}
+	if versions[0].Version != 2 || versions[1].Version != 1 {
+		t.Errorf("Expected newest first, got %d, %d", versions[0].Version, versions[1].Version)
+	}
+	if string(versions[1].Snapshot) != `{"publisher_id":"pub1","bidder_params":{"rubicon":{"zoneId":1}}}` {
+		t.Errorf("Unexpected snapshot: %s", versions[1].Snapshot)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+// TestPublisherStore_Rollback tests restoring a publisher version
+func TestPublisherStore_Rollback(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewPublisherStore(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE publishers p SET (.+) FROM publisher_history h, jsonb_populate_record(.+) RETURNING p.version").
+		WithArgs("pub1", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(3))
+	mock.ExpectQuery("SELECT version, snapshot, changed_at FROM publisher_history WHERE publisher_id = (.+) AND version = (.+)").
+		WithArgs("pub1", 3).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "snapshot", "changed_at"}).
+			AddRow(3, []byte(`{"publisher_id":"pub1","version":3}`), time.Now()))
+	mock.ExpectCommit()
+
+	v, err := store.Rollback(context.Background(), "pub1", 1)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if v == nil || v.Version != 3 {
+		t.Fatalf("Expected new version 3, got %+v", v)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+// TestPublisherStore_Rollback_VersionNotFound tests rollback to a missing version
+func TestPublisherStore_Rollback_VersionNotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewPublisherStore(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE publishers p").
+		WithArgs("pub1", 42).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}))
+	mock.ExpectRollback()
+
+	v, err := store.Rollback(context.Background(), "pub1", 42)
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound, got %v", err)
+	}
+	if v != nil {
+		t.Errorf("Expected nil for missing version, got %+v", v)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+// TestBidderStore_Rollback tests restoring a bidder version
+func TestBidderStore_Rollback(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewBidderStore(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE bidders b SET (.+) FROM bidder_history h, jsonb_populate_record(.+) RETURNING b.version").
+		WithArgs("rubicon", 4).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(6))
+	mock.ExpectQuery("SELECT version, snapshot, changed_at FROM bidder_history WHERE bidder_code = (.+) AND version = (.+)").
+		WithArgs("rubicon", 6).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "snapshot", "changed_at"}).
+			AddRow(6, []byte(`{"bidder_code":"rubicon","version":6}`), time.Now()))
+	mock.ExpectCommit()
+
+	v, err := store.Rollback(context.Background(), "rubicon", 4)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if v == nil || v.Version != 6 {
+		t.Fatalf("Expected new version 6, got %+v", v)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+// TestBidderStore_History_Error tests history query failure
+func TestBidderStore_History_Error(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewBidderStore(db)
+
+	mock.ExpectQuery("SELECT version, snapshot, changed_at FROM bidder_history").
+		WithArgs("rubicon").
+		WillReturnError(errors.New("connection refused"))
+
+	if _, err := store.History(context.Background(), "rubicon"); err == nil {
+		t.Error("Expected error, got nil")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
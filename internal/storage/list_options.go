@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+)
+
+// List page size limits
+const (
+	DefaultListLimit = 100
+	MaxListLimit     = 1000
+)
+
+// ErrInvalidListOptions is wrapped by errors for unusable list options, so
+// callers can report them as bad requests rather than database failures. It
+// wraps ErrValidation.
+var ErrInvalidListOptions = fmt.Errorf("%w list options", ErrValidation)
+
+// ListOptions controls pagination, filtering and sorting for ListPage methods
+type ListOptions struct {
+	Limit  int    // Rows per page (0 = DefaultListLimit, capped at MaxListLimit)
+	Offset int    // Rows to skip; ignored when Cursor is set
+	Cursor string // Resume after this key (bidder_code / publisher_id); requires the default sort
+	Status string // Only rows with this status ("" = all)
+	Sort   string // Sort field, "-" prefix for descending (default: the key, ascending)
+}
+
+// PageLimit returns the effective page size
+func (o ListOptions) PageLimit() int {
+	switch {
+	case o.Limit <= 0:
+		return DefaultListLimit
+	case o.Limit > MaxListLimit:
+		return MaxListLimit
+	default:
+		return o.Limit
+	}
+}
+
+// listQuery is a paginated SELECT plus the COUNT over the same filter
+type listQuery struct {
+	query      string
+	args       []interface{}
+	countQuery string
+	countArgs  []interface{}
+}
+
+// buildListQuery builds the page and count queries for a table. key is the
+// unique column cursors resume from; sortable lists the columns callers may
+// sort by. Column names never come from the caller, only from sortable.
+func (o ListOptions) buildListQuery(columns, table, key string, sortable []string) (*listQuery, error) {
+	if o.Offset < 0 {
+		return nil, fmt.Errorf("%w: offset must not be negative", ErrInvalidListOptions)
+	}
+
+	field, desc := strings.TrimPrefix(o.Sort, "-"), strings.HasPrefix(o.Sort, "-")
+	if field == "" {
+		field = key
+	}
+	allowed := false
+	for _, col := range sortable {
+		if col == field {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return nil, fmt.Errorf("%w: cannot sort by %q (allowed: %s)", ErrInvalidListOptions, field, strings.Join(sortable, ", "))
+	}
+	if o.Cursor != "" && field != key {
+		return nil, fmt.Errorf("%w: cursor requires sorting by %s", ErrInvalidListOptions, key)
+	}
+
+	dir := "ASC"
+	if desc {
+		dir = "DESC"
+	}
+
+	var where []string
+	var args []interface{}
+	if o.Status != "" {
+		args = append(args, o.Status)
+		where = append(where, fmt.Sprintf("status = $%d", len(args)))
+	}
+
+	lq := &listQuery{countQuery: "SELECT COUNT(*) FROM " + table}
+	if len(where) > 0 {
+		lq.countQuery += " WHERE " + strings.Join(where, " AND ")
+	}
+	lq.countArgs = append(lq.countArgs, args...)
+
+	if o.Cursor != "" {
+		op := ">"
+		if desc {
+			op = "<"
+		}
+		args = append(args, o.Cursor)
+		where = append(where, fmt.Sprintf("%s %s $%d", key, op, len(args)))
+	}
+
+	query := "SELECT " + columns + " FROM " + table
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += fmt.Sprintf(" ORDER BY %s %s", field, dir)
+	if field != key {
+		query += fmt.Sprintf(", %s %s", key, dir)
+	}
+
+	args = append(args, o.PageLimit())
+	query += fmt.Sprintf(" LIMIT $%d", len(args))
+	if o.Cursor == "" && o.Offset > 0 {
+		args = append(args, o.Offset)
+		query += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
+
+	lq.query = query
+	lq.args = args
+	return lq, nil
+}
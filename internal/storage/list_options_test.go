@@ -0,0 +1,199 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestListOptions_PageLimit(t *testing.T) {
+	tests := []struct {
+		limit, want int
+	}{
+		{0, DefaultListLimit},
+		{-5, DefaultListLimit},
+		{25, 25},
+		{MaxListLimit + 1, MaxListLimit},
+	}
+	for _, tt := range tests {
+		if got := (ListOptions{Limit: tt.limit}).PageLimit(); got != tt.want {
+			t.Errorf("PageLimit(%d) = %d, want %d", tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestListOptions_BuildListQuery(t *testing.T) {
+	sortable := []string{"code", "name"}
+
+	tests := []struct {
+		name      string
+		opts      ListOptions
+		query     string
+		args      []interface{}
+		count     string
+		countArgs []interface{}
+	}{
+		{
+			name:  "defaults",
+			opts:  ListOptions{},
+			query: "SELECT a FROM t ORDER BY code ASC LIMIT $1",
+			args:  []interface{}{DefaultListLimit},
+			count: "SELECT COUNT(*) FROM t",
+		},
+		{
+			name:      "status, sort and offset",
+			opts:      ListOptions{Limit: 10, Offset: 20, Status: "active", Sort: "-name"},
+			query:     "SELECT a FROM t WHERE status = $1 ORDER BY name DESC, code DESC LIMIT $2 OFFSET $3",
+			args:      []interface{}{"active", 10, 20},
+			count:     "SELECT COUNT(*) FROM t WHERE status = $1",
+			countArgs: []interface{}{"active"},
+		},
+		{
+			name:      "cursor ignores offset",
+			opts:      ListOptions{Limit: 10, Offset: 20, Status: "active", Cursor: "m"},
+			query:     "SELECT a FROM t WHERE status = $1 AND code > $2 ORDER BY code ASC LIMIT $3",
+			args:      []interface{}{"active", "m", 10},
+			count:     "SELECT COUNT(*) FROM t WHERE status = $1",
+			countArgs: []interface{}{"active"},
+		},
+		{
+			name:  "descending cursor",
+			opts:  ListOptions{Limit: 5, Cursor: "m", Sort: "-code"},
+			query: "SELECT a FROM t WHERE code < $1 ORDER BY code DESC LIMIT $2",
+			args:  []interface{}{"m", 5},
+			count: "SELECT COUNT(*) FROM t",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lq, err := tt.opts.buildListQuery("a", "t", "code", sortable)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if lq.query != tt.query {
+				t.Errorf("query:\n got %s\nwant %s", lq.query, tt.query)
+			}
+			if !reflect.DeepEqual(lq.args, tt.args) {
+				t.Errorf("args: got %v, want %v", lq.args, tt.args)
+			}
+			if lq.countQuery != tt.count {
+				t.Errorf("count query: got %s, want %s", lq.countQuery, tt.count)
+			}
+			if len(lq.countArgs) != len(tt.countArgs) || (len(tt.countArgs) > 0 && !reflect.DeepEqual(lq.countArgs, tt.countArgs)) {
+				t.Errorf("count args: got %v, want %v", lq.countArgs, tt.countArgs)
+			}
+		})
+	}
+}
+
+func TestListOptions_BuildListQuery_Invalid(t *testing.T) {
+	sortable := []string{"code", "name"}
+
+	for _, opts := range []ListOptions{
+		{Sort: "password"},
+		{Sort: "name; DROP TABLE t"},
+		{Sort: "name", Cursor: "m"},
+		{Offset: -1},
+	} {
+		if _, err := opts.buildListQuery("a", "t", "code", sortable); !errors.Is(err, ErrInvalidListOptions) {
+			t.Errorf("%+v: expected ErrInvalidListOptions, got %v", opts, err)
+		}
+	}
+}
+
+func TestBidderStore_ListPage(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewBidderStore(db)
+	b := createTestBidder("appnexus")
+	httpHeadersJSON, _ := json.Marshal(b.HTTPHeaders)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM bidders WHERE status = $1")).
+		WithArgs("active").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(42))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM bidders WHERE status = $1 AND bidder_code > $2 ORDER BY bidder_code ASC LIMIT $3")).
+		WithArgs("active", "adform", 1).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "bidder_code", "bidder_name", "endpoint_url", "timeout_ms",
+			"enabled", "status", "supports_banner", "supports_video", "supports_native", "supports_audio",
+			"gvl_vendor_id", "http_headers", "description", "documentation_url", "contact_email",
+			"version", "created_at", "updated_at",
+		}).AddRow(
+			b.ID, b.BidderCode, b.BidderName, b.EndpointURL, b.TimeoutMs,
+			b.Enabled, b.Status, b.SupportsBanner, b.SupportsVideo, b.SupportsNative, b.SupportsAudio,
+			b.GVLVendorID, httpHeadersJSON, b.Description, b.DocumentationURL, b.ContactEmail,
+			1, b.CreatedAt, b.UpdatedAt,
+		))
+
+	bidders, total, err := store.ListPage(context.Background(), ListOptions{Limit: 1, Status: "active", Cursor: "adform"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if total != 42 {
+		t.Errorf("Expected total 42, got %d", total)
+	}
+	if len(bidders) != 1 || bidders[0].BidderCode != "appnexus" || bidders[0].HTTPHeaders["X-API-Key"] != "test" {
+		t.Errorf("Unexpected bidders: %+v", bidders)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestPublisherStore_ListPage(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewPublisherStore(db)
+	p := createTestPublisher("pub-1")
+	bidderParamsJSON, _ := json.Marshal(p.BidderParams)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM publishers")).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM publishers ORDER BY updated_at DESC, publisher_id DESC LIMIT $1 OFFSET $2")).
+		WithArgs(2, 2).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "publisher_id", "name", "allowed_domains", "bidder_params",
+			"bid_multiplier", "status", "version", "created_at", "updated_at", "notes", "contact_email",
+			"blocked_attributes", "max_bid_cpm", "player_config", "slo_p95_ms", "creative_sanitization",
+			"language_filter", "required_language", "creative_approval", "allowed_countries", "blocked_countries", "timeout_ms",
+			"max_bidders", "allowed_bidders", "blocked_bidders", "pod_min_cpm", "pod_max_bidder_share",
+			"payment_terms", "billing_currency", "invoice_contact_name", "invoice_contact_email",
+		}).AddRow(
+			p.ID, p.PublisherID, p.Name, p.AllowedDomains, bidderParamsJSON,
+			p.BidMultiplier, "paused", 1, p.CreatedAt, p.UpdatedAt, p.Notes, p.ContactEmail, []byte("[6]"), 0.0, []byte("{}"), 0, "", "", "", "", "", "",
+			0, 0, "", "", 0.0, 0.0, "net-30", "USD", "", "",
+		))
+
+	publishers, total, err := store.ListPage(context.Background(), ListOptions{Limit: 2, Offset: 2, Sort: "-updated_at"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if total != 3 {
+		t.Errorf("Expected total 3, got %d", total)
+	}
+	if len(publishers) != 1 || publishers[0].Status != "paused" || !reflect.DeepEqual(publishers[0].BlockedAttributes, []int{6}) {
+		t.Errorf("Unexpected publishers: %+v", publishers)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+
+	// Invalid options never reach the database
+	if _, _, err := store.ListPage(context.Background(), ListOptions{Sort: "allowed_domains"}); !errors.Is(err, ErrInvalidListOptions) {
+		t.Errorf("Expected ErrInvalidListOptions, got %v", err)
+	}
+}
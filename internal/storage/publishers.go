@@ -25,6 +25,68 @@ type Publisher struct {
 	UpdatedAt      time.Time              `json:"updated_at"`
 	Notes          string                 `json:"notes,omitempty"`
 	ContactEmail   string                 `json:"contact_email,omitempty"`
+	// BlockedAttributes are default battr values merged into media objects
+	// that don't set their own (e.g. 1 = audio auto-play)
+	BlockedAttributes []int `json:"blocked_attributes,omitempty"`
+	// MaxBidCPM rejects bids priced above it (0 = use the exchange-wide cap)
+	MaxBidCPM float64 `json:"max_bid_cpm,omitempty"`
+	// PlayerConfig overrides the server defaults served to the player SDK
+	// from /video/config
+	PlayerConfig *PlayerConfig `json:"player_config,omitempty"`
+	// SLOP95Ms is the p95 auction response time target in milliseconds
+	// (0 = use the exchange-wide SLO_P95_TARGET_MS)
+	SLOP95Ms int `json:"slo_p95_ms,omitempty"`
+	// CreativeSanitization is the banner markup sanitization level: off,
+	// standard or strict ("" = use the exchange-wide CREATIVE_SANITIZATION)
+	CreativeSanitization string `json:"creative_sanitization,omitempty"`
+	// LanguageFilter rejects bids whose bid.language doesn't match the
+	// request's content or device language: match or match_or_unlabeled
+	// ("" = no filtering)
+	LanguageFilter string `json:"language_filter,omitempty"`
+	// RequiredLanguage is the ISO 639-1 code every bid must be in, whatever
+	// the request's language ("" = the request's language under
+	// LanguageFilter)
+	RequiredLanguage string `json:"required_language,omitempty"`
+	// CreativeApproval is what happens to bids whose creative hasn't been
+	// reviewed yet: flag (serve and queue for review) or hold (reject until
+	// approved). "" serves them unflagged; blocked creatives are always
+	// rejected.
+	CreativeApproval string `json:"creative_approval,omitempty"`
+	// AllowedCountries limits auctions to requests from these comma-separated
+	// ISO 3166-1 alpha-3 device.geo.country codes ("" = any country)
+	AllowedCountries string `json:"allowed_countries,omitempty"`
+	// BlockedCountries rejects requests from these comma-separated ISO 3166-1
+	// alpha-3 codes before bidders are called
+	BlockedCountries string `json:"blocked_countries,omitempty"`
+	// TimeoutMs is the auction timeout in milliseconds for requests without
+	// their own tmax (0 = use the server's -timeout)
+	TimeoutMs int `json:"timeout_ms,omitempty"`
+	// MaxBidders caps how many bidders one auction fans out to (0 = use the
+	// exchange-wide PBS_MAX_BIDDERS)
+	MaxBidders int `json:"max_bidders,omitempty"`
+	// AllowedBidders limits auctions to these comma-separated bidder codes
+	// ("" = every enabled bidder)
+	AllowedBidders string `json:"allowed_bidders,omitempty"`
+	// BlockedBidders are comma-separated bidder codes never called for the
+	// publisher
+	BlockedBidders string `json:"blocked_bidders,omitempty"`
+	// PodMinCPM is the least an ad pod's filled slots must total, in the
+	// exchange currency, for the pod to be served (0 = no pod floor)
+	PodMinCPM float64 `json:"pod_min_cpm,omitempty"`
+	// PodMaxBidderShare caps the share of a pod's slots one bidder can fill,
+	// between 0 and 1 (0 = no cap)
+	PodMaxBidderShare float64 `json:"pod_max_bidder_share,omitempty"`
+	// Billing is what finance needs to pay the publisher
+	Billing
+}
+
+// PlayerConfig holds per-publisher player settings; zero fields fall back to
+// the server defaults
+type PlayerConfig struct {
+	TrackingBaseURL      string `json:"tracking_base_url,omitempty"`
+	EventBatchIntervalMs int    `json:"event_batch_interval_ms,omitempty"`
+	PauseAdsEnabled      *bool  `json:"pause_ads_enabled,omitempty"`
+	VASTVersion          string `json:"vast_version,omitempty"`
 }
 
 // GetAllowedDomains returns the allowed domains string (for middleware interface)
@@ -37,6 +99,81 @@ func (p *Publisher) GetBidMultiplier() float64 {
 	return p.BidMultiplier
 }
 
+// GetBlockedAttributes returns the default blocked creative attributes (for exchange interface)
+func (p *Publisher) GetBlockedAttributes() []int {
+	return p.BlockedAttributes
+}
+
+// GetMaxBidCPM returns the publisher's bid price cap (for exchange interface)
+func (p *Publisher) GetMaxBidCPM() float64 {
+	return p.MaxBidCPM
+}
+
+// GetSLOP95Ms returns the publisher's p95 latency target (for endpoints interface)
+func (p *Publisher) GetSLOP95Ms() int {
+	return p.SLOP95Ms
+}
+
+// GetCreativeSanitization returns the banner markup sanitization level (for exchange interface)
+func (p *Publisher) GetCreativeSanitization() string {
+	return p.CreativeSanitization
+}
+
+// GetLanguageFilter returns the ad language filter mode (for exchange interface)
+func (p *Publisher) GetLanguageFilter() string {
+	return p.LanguageFilter
+}
+
+// GetRequiredLanguage returns the language every bid must be in (for exchange interface)
+func (p *Publisher) GetRequiredLanguage() string {
+	return p.RequiredLanguage
+}
+
+// GetCreativeApproval returns the unreviewed creative policy (for exchange interface)
+func (p *Publisher) GetCreativeApproval() string {
+	return p.CreativeApproval
+}
+
+// GetAllowedCountries returns the countries auctions are limited to (for exchange interface)
+func (p *Publisher) GetAllowedCountries() string {
+	return p.AllowedCountries
+}
+
+// GetBlockedCountries returns the countries auctions are rejected for (for exchange interface)
+func (p *Publisher) GetBlockedCountries() string {
+	return p.BlockedCountries
+}
+
+// GetTimeoutMs returns the publisher's auction timeout (for exchange interface)
+func (p *Publisher) GetTimeoutMs() int {
+	return p.TimeoutMs
+}
+
+// GetMaxBidders returns the publisher's fan-out cap (for exchange interface)
+func (p *Publisher) GetMaxBidders() int {
+	return p.MaxBidders
+}
+
+// GetAllowedBidders returns the bidders auctions are limited to (for exchange interface)
+func (p *Publisher) GetAllowedBidders() string {
+	return p.AllowedBidders
+}
+
+// GetBlockedBidders returns the bidders never called for the publisher (for exchange interface)
+func (p *Publisher) GetBlockedBidders() string {
+	return p.BlockedBidders
+}
+
+// GetPodMinCPM returns the publisher's minimum total pod CPM (for exchange interface)
+func (p *Publisher) GetPodMinCPM() float64 {
+	return p.PodMinCPM
+}
+
+// GetPodMaxBidderShare returns the share of a pod one bidder may fill (for exchange interface)
+func (p *Publisher) GetPodMaxBidderShare() float64 {
+	return p.PodMaxBidderShare
+}
+
 // GetPublisherID returns the publisher ID (for exchange interface)
 func (p *Publisher) GetPublisherID() string {
 	return p.PublisherID
@@ -44,7 +181,8 @@ func (p *Publisher) GetPublisherID() string {
 
 // PublisherStore provides database operations for publishers
 type PublisherStore struct {
-	db *sql.DB
+	db         *sql.DB
+	maxBidders int
 }
 
 // NewPublisherStore creates a new publisher store
@@ -52,6 +190,24 @@ func NewPublisherStore(db *sql.DB) *PublisherStore {
 	return &PublisherStore{db: db}
 }
 
+// SetMaxBidders sets the exchange-wide fan-out cap (PBS_MAX_BIDDERS) that a
+// publisher's max_bidders may not exceed on create/update (0 = uncapped)
+func (s *PublisherStore) SetMaxBidders(limit int) {
+	s.maxBidders = limit
+}
+
+// validateMaxBidders rejects a publisher fan-out cap above the exchange's;
+// the exchange would never honor it
+func (s *PublisherStore) validateMaxBidders(p *Publisher) error {
+	if p.MaxBidders < 0 {
+		return fmt.Errorf("%w max_bidders: must not be negative, got %d", ErrValidation, p.MaxBidders)
+	}
+	if s.maxBidders > 0 && p.MaxBidders > s.maxBidders {
+		return fmt.Errorf("%w max_bidders: must be at most PBS_MAX_BIDDERS (%d), got %d", ErrValidation, s.maxBidders, p.MaxBidders)
+	}
+	return nil
+}
+
 // Ping checks if the database connection is alive
 func (s *PublisherStore) Ping(ctx context.Context) error {
 	if s.db == nil {
@@ -60,28 +216,37 @@ func (s *PublisherStore) Ping(ctx context.Context) error {
 	return s.db.PingContext(ctx)
 }
 
-// GetByPublisherID retrieves a publisher by their publisher_id
+// GetByPublisherID retrieves an active publisher by their publisher_id,
+// returning ErrNotFound if there is none
 // Returns interface{} for middleware compatibility while maintaining concrete type internally
 func (s *PublisherStore) GetByPublisherID(ctx context.Context, publisherID string) (interface{}, error) {
-	return s.getByPublisherIDConcrete(ctx, publisherID)
+	p, err := s.getByPublisherIDConcrete(ctx, publisherID)
+	if err != nil {
+		return nil, err // A nil *Publisher would make a non-nil interface
+	}
+	return p, nil
 }
 
-// getByPublisherIDConcrete is the internal implementation returning concrete type
-func (s *PublisherStore) getByPublisherIDConcrete(ctx context.Context, publisherID string) (*Publisher, error) {
-	ctx, cancel := withTimeout(ctx, DefaultDBTimeout)
-	defer cancel()
-
-	query := `
-		SELECT id, publisher_id, name, allowed_domains, bidder_params, bid_multiplier,
-		       status, version, created_at, updated_at, notes, contact_email
-		FROM publishers
-		WHERE publisher_id = $1 AND status = 'active'
-	`
+// publisherColumns are the columns scanPublisher reads, in order
+const publisherColumns = `id, publisher_id, name, allowed_domains, bidder_params, bid_multiplier,
+		status, version, created_at, updated_at, notes, contact_email, blocked_attributes, max_bid_cpm,
+		player_config, slo_p95_ms, creative_sanitization, language_filter, required_language,
+		creative_approval, allowed_countries, blocked_countries, timeout_ms, max_bidders, allowed_bidders,
+		blocked_bidders, pod_min_cpm, pod_max_bidder_share, payment_terms, billing_currency,
+		invoice_contact_name, invoice_contact_email`
+
+// rowScanner is a *sql.Row or *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
 
+// scanPublisher reads a row selected with publisherColumns, parsing its JSONB
+// columns. Scan errors, including sql.ErrNoRows, are returned as is.
+func scanPublisher(row rowScanner) (*Publisher, error) {
 	var p Publisher
-	var bidderParamsJSON []byte
+	var bidderParamsJSON, blockedAttrsJSON, playerConfigJSON []byte
 
-	err := s.db.QueryRowContext(ctx, query, publisherID).Scan(
+	err := row.Scan(
 		&p.ID,
 		&p.PublisherID,
 		&p.Name,
@@ -94,13 +259,29 @@ func (s *PublisherStore) getByPublisherIDConcrete(ctx context.Context, publisher
 		&p.UpdatedAt,
 		&p.Notes,
 		&p.ContactEmail,
+		&blockedAttrsJSON,
+		&p.MaxBidCPM,
+		&playerConfigJSON,
+		&p.SLOP95Ms,
+		&p.CreativeSanitization,
+		&p.LanguageFilter,
+		&p.RequiredLanguage,
+		&p.CreativeApproval,
+		&p.AllowedCountries,
+		&p.BlockedCountries,
+		&p.TimeoutMs,
+		&p.MaxBidders,
+		&p.AllowedBidders,
+		&p.BlockedBidders,
+		&p.PodMinCPM,
+		&p.PodMaxBidderShare,
+		&p.PaymentTerms,
+		&p.BillingCurrency,
+		&p.InvoiceContactName,
+		&p.InvoiceContactEmail,
 	)
-
-	if err == sql.ErrNoRows {
-		return nil, nil // Publisher not found
-	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to query publisher: %w", err)
+		return nil, err
 	}
 
 	// Parse JSONB bidder_params
@@ -109,18 +290,44 @@ func (s *PublisherStore) getByPublisherIDConcrete(ctx context.Context, publisher
 			return nil, fmt.Errorf("failed to parse bidder_params: %w", err)
 		}
 	}
-
+	if len(blockedAttrsJSON) > 0 {
+		if err := json.Unmarshal(blockedAttrsJSON, &p.BlockedAttributes); err != nil {
+			return nil, fmt.Errorf("failed to parse blocked_attributes: %w", err)
+		}
+	}
+	if p.PlayerConfig, err = parsePlayerConfig(playerConfigJSON); err != nil {
+		return nil, err
+	}
 	return &p, nil
 }
 
+// getByPublisherIDConcrete is the internal implementation returning concrete type
+func (s *PublisherStore) getByPublisherIDConcrete(ctx context.Context, publisherID string) (*Publisher, error) {
+	ctx, cancel := withTimeout(ctx, DefaultDBTimeout)
+	defer cancel()
+
+	query := `SELECT ` + publisherColumns + `
+		FROM publishers
+		WHERE publisher_id = $1 AND status = 'active'
+	`
+
+	p, err := scanPublisher(s.db.QueryRowContext(ctx, query, publisherID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("publisher %w: %s", ErrNotFound, publisherID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query publisher: %w", err)
+	}
+
+	return p, nil
+}
+
 // List retrieves all active publishers
 func (s *PublisherStore) List(ctx context.Context) ([]*Publisher, error) {
 	ctx, cancel := withTimeout(ctx, DefaultDBTimeout)
 	defer cancel()
 
-	query := `
-		SELECT id, publisher_id, name, allowed_domains, bidder_params, bid_multiplier,
-		       status, version, created_at, updated_at, notes, contact_email
+	query := `SELECT ` + publisherColumns + `
 		FROM publishers
 		WHERE status = 'active'
 		ORDER BY publisher_id
@@ -134,42 +341,60 @@ func (s *PublisherStore) List(ctx context.Context) ([]*Publisher, error) {
 
 	publishers := make([]*Publisher, 0, 100)
 	for rows.Next() {
-		var p Publisher
-		var bidderParamsJSON []byte
-
-		err := rows.Scan(
-			&p.ID,
-			&p.PublisherID,
-			&p.Name,
-			&p.AllowedDomains,
-			&bidderParamsJSON,
-			&p.BidMultiplier,
-			&p.Status,
-			&p.Version,
-			&p.CreatedAt,
-			&p.UpdatedAt,
-			&p.Notes,
-			&p.ContactEmail,
-		)
+		p, err := scanPublisher(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan publisher row: %w", err)
 		}
+		publishers = append(publishers, p)
+	}
 
-		// Parse JSONB bidder_params
-		if len(bidderParamsJSON) > 0 {
-			if err := json.Unmarshal(bidderParamsJSON, &p.BidderParams); err != nil {
-				return nil, fmt.Errorf("failed to parse bidder_params: %w", err)
-			}
-		}
+	return publishers, rows.Err()
+}
+
+// publisherSortFields are the columns ListPage can sort publishers by
+var publisherSortFields = []string{"publisher_id", "name", "status", "created_at", "updated_at"}
 
-		publishers = append(publishers, &p)
+// ListPage returns one page of publishers matching opts along with the total
+// number of publishers matching its status filter. Unlike List it includes
+// paused and archived publishers unless opts.Status filters them out.
+func (s *PublisherStore) ListPage(ctx context.Context, opts ListOptions) ([]*Publisher, int, error) {
+	lq, err := opts.buildListQuery(publisherColumns, "publishers", "publisher_id", publisherSortFields)
+	if err != nil {
+		return nil, 0, err
 	}
 
-	return publishers, rows.Err()
+	ctx, cancel := withTimeout(ctx, DefaultDBTimeout)
+	defer cancel()
+
+	var total int
+	if err := s.db.QueryRowContext(ctx, lq.countQuery, lq.countArgs...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count publishers: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, lq.query, lq.args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query publishers: %w", err)
+	}
+	defer rows.Close()
+
+	publishers := make([]*Publisher, 0, opts.PageLimit())
+	for rows.Next() {
+		p, err := scanPublisher(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan publisher row: %w", err)
+		}
+		publishers = append(publishers, p)
+	}
+
+	return publishers, total, rows.Err()
 }
 
 // Create adds a new publisher
 func (s *PublisherStore) Create(ctx context.Context, p *Publisher) error {
+	if err := s.validateMaxBidders(p); err != nil {
+		return err
+	}
+
 	ctx, cancel := withTimeout(ctx, DefaultDBTimeout)
 	defer cancel()
 
@@ -186,8 +411,13 @@ func (s *PublisherStore) Create(ctx context.Context, p *Publisher) error {
 
 	query := `
 		INSERT INTO publishers (
-			publisher_id, name, allowed_domains, bidder_params, bid_multiplier, status, notes, contact_email
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			publisher_id, name, allowed_domains, bidder_params, bid_multiplier, status, notes, contact_email,
+			blocked_attributes, max_bid_cpm, player_config, slo_p95_ms, creative_sanitization, language_filter,
+			required_language, creative_approval, allowed_countries, blocked_countries, timeout_ms, max_bidders,
+			allowed_bidders, blocked_bidders, pod_min_cpm, pod_max_bidder_share, payment_terms, billing_currency,
+			invoice_contact_name, invoice_contact_email
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21,
+			$22, $23, $24, $25, $26, $27, $28)
 		RETURNING id, version, created_at, updated_at
 	`
 
@@ -195,6 +425,9 @@ func (s *PublisherStore) Create(ctx context.Context, p *Publisher) error {
 	if err != nil {
 		return fmt.Errorf("failed to marshal bidder_params: %w", err)
 	}
+	blockedAttrsJSON := marshalBlockedAttributes(p.BlockedAttributes)
+	playerConfigJSON := marshalPlayerConfig(p.PlayerConfig)
+	billing := p.Billing.withDefaults()
 
 	err = s.db.QueryRowContext(ctx, query,
 		p.PublisherID,
@@ -205,8 +438,31 @@ func (s *PublisherStore) Create(ctx context.Context, p *Publisher) error {
 		status,
 		p.Notes,
 		p.ContactEmail,
+		blockedAttrsJSON,
+		p.MaxBidCPM,
+		playerConfigJSON,
+		p.SLOP95Ms,
+		p.CreativeSanitization,
+		p.LanguageFilter,
+		p.RequiredLanguage,
+		p.CreativeApproval,
+		p.AllowedCountries,
+		p.BlockedCountries,
+		p.TimeoutMs,
+		p.MaxBidders,
+		p.AllowedBidders,
+		p.BlockedBidders,
+		p.PodMinCPM,
+		p.PodMaxBidderShare,
+		billing.PaymentTerms,
+		billing.BillingCurrency,
+		billing.InvoiceContactName,
+		billing.InvoiceContactEmail,
 	).Scan(&p.ID, &p.Version, &p.CreatedAt, &p.UpdatedAt)
 
+	if isUniqueViolation(err) {
+		return fmt.Errorf("%w: publisher %s already exists", ErrConflict, p.PublisherID)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to create publisher: %w", err)
 	}
@@ -216,6 +472,10 @@ func (s *PublisherStore) Create(ctx context.Context, p *Publisher) error {
 
 // Update modifies an existing publisher using optimistic locking
 func (s *PublisherStore) Update(ctx context.Context, p *Publisher) error {
+	if err := s.validateMaxBidders(p); err != nil {
+		return err
+	}
+
 	ctx, cancel := withTimeout(ctx, DefaultDBTimeout)
 	defer cancel()
 
@@ -230,7 +490,7 @@ func (s *PublisherStore) Update(ctx context.Context, p *Publisher) error {
 	var currentVersion int
 	err = tx.QueryRowContext(ctx, "SELECT version FROM publishers WHERE publisher_id = $1", p.PublisherID).Scan(&currentVersion)
 	if err == sql.ErrNoRows {
-		return fmt.Errorf("publisher not found: %s", p.PublisherID)
+		return fmt.Errorf("publisher %w: %s", ErrNotFound, p.PublisherID)
 	}
 	if err != nil {
 		return fmt.Errorf("failed to check version: %w", err)
@@ -238,20 +498,29 @@ func (s *PublisherStore) Update(ctx context.Context, p *Publisher) error {
 
 	// Verify version matches (optimistic lock check)
 	if currentVersion != p.Version {
-		return fmt.Errorf("concurrent modification detected: publisher %s was updated by another process", p.PublisherID)
+		return fmt.Errorf("%w: concurrent modification detected: publisher %s was updated by another process", ErrConflict, p.PublisherID)
 	}
 
 	query := `
 		UPDATE publishers
 		SET name = $1, allowed_domains = $2, bidder_params = $3,
-		    bid_multiplier = $4, status = $5, notes = $6, contact_email = $7
-		WHERE publisher_id = $8 AND version = $9
+		    bid_multiplier = $4, status = $5, notes = $6, contact_email = $7,
+		    blocked_attributes = $8, max_bid_cpm = $9, player_config = $10,
+		    slo_p95_ms = $11, creative_sanitization = $12, language_filter = $13,
+		    required_language = $14, creative_approval = $15, allowed_countries = $16,
+		    blocked_countries = $17, timeout_ms = $18, max_bidders = $19, allowed_bidders = $20,
+		    blocked_bidders = $21, pod_min_cpm = $22, pod_max_bidder_share = $23, payment_terms = $24,
+		    billing_currency = $25, invoice_contact_name = $26, invoice_contact_email = $27
+		WHERE publisher_id = $28 AND version = $29
 	`
 
 	bidderParamsJSON, err := json.Marshal(p.BidderParams)
 	if err != nil {
 		return fmt.Errorf("failed to marshal bidder_params: %w", err)
 	}
+	blockedAttrsJSON := marshalBlockedAttributes(p.BlockedAttributes)
+	playerConfigJSON := marshalPlayerConfig(p.PlayerConfig)
+	billing := p.Billing.withDefaults()
 
 	result, err := tx.ExecContext(ctx, query,
 		p.Name,
@@ -261,6 +530,26 @@ func (s *PublisherStore) Update(ctx context.Context, p *Publisher) error {
 		p.Status,
 		p.Notes,
 		p.ContactEmail,
+		blockedAttrsJSON,
+		p.MaxBidCPM,
+		playerConfigJSON,
+		p.SLOP95Ms,
+		p.CreativeSanitization,
+		p.LanguageFilter,
+		p.RequiredLanguage,
+		p.CreativeApproval,
+		p.AllowedCountries,
+		p.BlockedCountries,
+		p.TimeoutMs,
+		p.MaxBidders,
+		p.AllowedBidders,
+		p.BlockedBidders,
+		p.PodMinCPM,
+		p.PodMaxBidderShare,
+		billing.PaymentTerms,
+		billing.BillingCurrency,
+		billing.InvoiceContactName,
+		billing.InvoiceContactEmail,
 		p.PublisherID,
 		p.Version,
 	)
@@ -275,7 +564,7 @@ func (s *PublisherStore) Update(ctx context.Context, p *Publisher) error {
 	}
 
 	if rows == 0 {
-		return fmt.Errorf("concurrent modification detected: publisher %s version mismatch", p.PublisherID)
+		return fmt.Errorf("%w: concurrent modification detected: publisher %s version mismatch", ErrConflict, p.PublisherID)
 	}
 
 	// Commit transaction
@@ -311,13 +600,69 @@ func (s *PublisherStore) Delete(ctx context.Context, publisherID string) error {
 	}
 
 	if rows == 0 {
-		return fmt.Errorf("publisher not found: %s", publisherID)
+		return fmt.Errorf("publisher %w: %s", ErrNotFound, publisherID)
 	}
 
 	return nil
 }
 
-// GetBidderParams retrieves bidder parameters for a specific bidder
+// Restore re-activates an archived publisher. check, when set, vets the
+// archived configuration first and its error aborts the restore. A
+// publisher that isn't archived, or is changed while being checked, fails
+// with ErrConflict. The returned publisher carries only the fields check
+// sees: publisher_id, name, allowed_domains, status and version.
+func (s *PublisherStore) Restore(ctx context.Context, publisherID string, check func(*Publisher) error) (*Publisher, error) {
+	ctx, cancel := withTimeout(ctx, DefaultDBTimeout)
+	defer cancel()
+
+	p := Publisher{PublisherID: publisherID}
+	err := s.db.QueryRowContext(ctx,
+		"SELECT name, allowed_domains, status, version FROM publishers WHERE publisher_id = $1", publisherID,
+	).Scan(&p.Name, &p.AllowedDomains, &p.Status, &p.Version)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("publisher %w: %s", ErrNotFound, publisherID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query publisher: %w", err)
+	}
+	if p.Status != "archived" {
+		return nil, fmt.Errorf("%w: publisher %s is %s, not archived", ErrConflict, publisherID, p.Status)
+	}
+	if check != nil {
+		if err := check(&p); err != nil {
+			return nil, err
+		}
+	}
+
+	query := `
+		UPDATE publishers
+		SET status = 'active'
+		WHERE publisher_id = $1 AND status = 'archived' AND version = $2
+	`
+
+	result, err := s.db.ExecContext(ctx, query, publisherID, p.Version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore publisher: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return nil, fmt.Errorf("%w: publisher %s was modified during restore", ErrConflict, publisherID)
+	}
+
+	// The version trigger bumped the version
+	p.Status = "active"
+	p.Version++
+	return &p, nil
+}
+
+// GetBidderParams retrieves bidder parameters for a specific bidder. It
+// returns ErrNotFound for an unknown publisher and nil for a bidder without
+// params.
 func (s *PublisherStore) GetBidderParams(ctx context.Context, publisherID, bidderCode string) (map[string]interface{}, error) {
 	ctx, cancel := withTimeout(ctx, DefaultDBTimeout)
 	defer cancel()
@@ -332,7 +677,7 @@ func (s *PublisherStore) GetBidderParams(ctx context.Context, publisherID, bidde
 	err := s.db.QueryRowContext(ctx, query, publisherID, bidderCode).Scan(&paramsJSON)
 
 	if err == sql.ErrNoRows {
-		return nil, nil // No params for this bidder
+		return nil, fmt.Errorf("publisher %w: %s", ErrNotFound, publisherID)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to query bidder params: %w", err)
@@ -383,3 +728,37 @@ func NewDBConnection(ctx context.Context, host, port, user, password, dbname, ss
 
 	return db, nil
 }
+
+// marshalBlockedAttributes encodes blocked attributes for the JSONB column;
+// nil is stored as an empty list to satisfy the NOT NULL constraint
+func marshalBlockedAttributes(attrs []int) []byte {
+	if len(attrs) == 0 {
+		return []byte("[]")
+	}
+	data, _ := json.Marshal(attrs) // []int always marshals
+	return data
+}
+
+func marshalPlayerConfig(cfg *PlayerConfig) []byte {
+	if cfg == nil {
+		return []byte("{}")
+	}
+	data, _ := json.Marshal(cfg) // PlayerConfig always marshals
+	return data
+}
+
+// parsePlayerConfig returns nil for an empty player_config so callers only
+// see publishers that override something
+func parsePlayerConfig(data []byte) (*PlayerConfig, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var cfg PlayerConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse player_config: %w", err)
+	}
+	if cfg == (PlayerConfig{}) {
+		return nil, nil
+	}
+	return &cfg, nil
+}
@@ -44,6 +44,26 @@ func TestPublisherStore_Update_OptimisticLocking_Success(t *testing.T) {
 			publisher.Status,
 			publisher.Notes,
 			publisher.ContactEmail,
+			[]byte("[]"), // blocked_attributes
+			0.0,          // max_bid_cpm
+			[]byte("{}"), // player_config
+			0,            // slo_p95_ms
+			"",           // creative_sanitization
+			"",           // language_filter
+			"",           // required_language
+			"",           // creative_approval
+			"",           // allowed_countries
+			"",           // blocked_countries
+			0,            // timeout_ms
+			0,            // max_bidders
+			"",           // allowed_bidders
+			"",           // blocked_bidders
+			0.0,          // pod_min_cpm
+			0.0,          // pod_max_bidder_share
+			"net-30",     // payment_terms
+			"USD",        // billing_currency
+			"",           // invoice_contact_name
+			"",           // invoice_contact_email
 			publisher.PublisherID,
 			1, // version
 		).
@@ -98,7 +118,7 @@ func TestPublisherStore_Update_OptimisticLocking_VersionMismatch(t *testing.T) {
 		t.Fatal("Expected error for version mismatch, got nil")
 	}
 
-	if !contains(err.Error(), "concurrent modification detected") {
+	if !errors.Is(err, ErrConflict) || !contains(err.Error(), "concurrent modification detected") {
 		t.Errorf("Expected concurrent modification error, got: %v", err)
 	}
 
@@ -137,7 +157,7 @@ func TestPublisherStore_Update_OptimisticLocking_NotFound(t *testing.T) {
 		t.Fatal("Expected error for non-existent publisher, got nil")
 	}
 
-	if !contains(err.Error(), "not found") {
+	if !errors.Is(err, ErrNotFound) {
 		t.Errorf("Expected not found error, got: %v", err)
 	}
 
@@ -181,7 +201,7 @@ func TestPublisherStore_Update_OptimisticLocking_ZeroRowsAffected(t *testing.T)
 		t.Fatal("Expected error for zero rows affected, got nil")
 	}
 
-	if !contains(err.Error(), "concurrent modification detected") {
+	if !errors.Is(err, ErrConflict) || !contains(err.Error(), "concurrent modification detected") {
 		t.Errorf("Expected concurrent modification error, got: %v", err)
 	}
 
@@ -278,6 +298,10 @@ func TestPublisherStore_GetByPublisherID_IncludesVersion(t *testing.T) {
 	rows := sqlmock.NewRows([]string{
 		"id", "publisher_id", "name", "allowed_domains", "bidder_params",
 		"bid_multiplier", "status", "version", "created_at", "updated_at", "notes", "contact_email",
+		"blocked_attributes", "max_bid_cpm", "player_config", "slo_p95_ms", "creative_sanitization",
+		"language_filter", "required_language", "creative_approval", "allowed_countries", "blocked_countries", "timeout_ms",
+		"max_bidders", "allowed_bidders", "blocked_bidders", "pod_min_cpm", "pod_max_bidder_share",
+		"payment_terms", "billing_currency", "invoice_contact_name", "invoice_contact_email",
 	}).AddRow(
 		expectedPublisher.ID,
 		expectedPublisher.PublisherID,
@@ -291,6 +315,26 @@ func TestPublisherStore_GetByPublisherID_IncludesVersion(t *testing.T) {
 		expectedPublisher.UpdatedAt,
 		expectedPublisher.Notes,
 		expectedPublisher.ContactEmail,
+		[]byte("[6]"),
+		25.0,                                 // max_bid_cpm
+		[]byte(`{"pause_ads_enabled":true}`), // player_config
+		250,                                  // slo_p95_ms
+		"strict",                             // creative_sanitization
+		"match",                              // language_filter
+		"fr",                                 // required_language
+		"hold",                               // creative_approval
+		"USA,CAN",                            // allowed_countries
+		"PRK",                                // blocked_countries
+		400,                                  // timeout_ms
+		3,                                    // max_bidders
+		"appnexus,rubicon,pubmatic",          // allowed_bidders
+		"rubicon",                            // blocked_bidders
+		12.5,                                 // pod_min_cpm
+		0.5,                                  // pod_max_bidder_share
+		"net-60",                             // payment_terms
+		"EUR",                                // billing_currency
+		"Accounts Payable",                   // invoice_contact_name
+		"ap@example.com",                     // invoice_contact_email
 	)
 
 	mock.ExpectQuery("SELECT (.+) FROM publishers WHERE publisher_id").
@@ -3,12 +3,17 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"regexp"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
 )
 
 // createTestPublisher creates a test publisher for use in tests
@@ -67,6 +72,10 @@ func TestPublisherStore_GetByPublisherID_Success(t *testing.T) {
 	rows := sqlmock.NewRows([]string{
 		"id", "publisher_id", "name", "allowed_domains", "bidder_params",
 		"bid_multiplier", "status", "version", "created_at", "updated_at", "notes", "contact_email",
+		"blocked_attributes", "max_bid_cpm", "player_config", "slo_p95_ms", "creative_sanitization",
+		"language_filter", "required_language", "creative_approval", "allowed_countries", "blocked_countries", "timeout_ms",
+		"max_bidders", "allowed_bidders", "blocked_bidders", "pod_min_cpm", "pod_max_bidder_share",
+		"payment_terms", "billing_currency", "invoice_contact_name", "invoice_contact_email",
 	}).AddRow(
 		expectedPublisher.ID,
 		expectedPublisher.PublisherID,
@@ -80,6 +89,26 @@ func TestPublisherStore_GetByPublisherID_Success(t *testing.T) {
 		expectedPublisher.UpdatedAt,
 		expectedPublisher.Notes,
 		expectedPublisher.ContactEmail,
+		[]byte("[6]"),
+		25.0,                                 // max_bid_cpm
+		[]byte(`{"pause_ads_enabled":true}`), // player_config
+		250,                                  // slo_p95_ms
+		"strict",                             // creative_sanitization
+		"match",                              // language_filter
+		"fr",                                 // required_language
+		"hold",                               // creative_approval
+		"USA,CAN",                            // allowed_countries
+		"PRK",                                // blocked_countries
+		400,                                  // timeout_ms
+		3,                                    // max_bidders
+		"appnexus,rubicon,pubmatic",          // allowed_bidders
+		"rubicon",                            // blocked_bidders
+		12.5,                                 // pod_min_cpm
+		0.5,                                  // pod_max_bidder_share
+		"net-60",                             // payment_terms
+		"EUR",                                // billing_currency
+		"Accounts Payable",                   // invoice_contact_name
+		"ap@example.com",                     // invoice_contact_email
 	)
 
 	mock.ExpectQuery("SELECT (.+) FROM publishers WHERE publisher_id").
@@ -105,6 +134,40 @@ func TestPublisherStore_GetByPublisherID_Success(t *testing.T) {
 	if publisher.BidMultiplier != 1.05 {
 		t.Errorf("Expected 1.05, got %f", publisher.BidMultiplier)
 	}
+	if len(publisher.BlockedAttributes) != 1 || publisher.BlockedAttributes[0] != 6 {
+		t.Errorf("Expected blocked attributes [6], got %v", publisher.BlockedAttributes)
+	}
+	if publisher.MaxBidCPM != 25.0 {
+		t.Errorf("Expected max bid CPM 25, got %f", publisher.MaxBidCPM)
+	}
+	if publisher.SLOP95Ms != 250 {
+		t.Errorf("Expected SLO p95 target 250ms, got %d", publisher.SLOP95Ms)
+	}
+	if publisher.CreativeSanitization != "strict" {
+		t.Errorf("Expected strict creative sanitization, got %q", publisher.CreativeSanitization)
+	}
+	if publisher.LanguageFilter != "match" || publisher.RequiredLanguage != "fr" {
+		t.Errorf("Expected match language filter requiring fr, got %q %q", publisher.LanguageFilter, publisher.RequiredLanguage)
+	}
+	if publisher.CreativeApproval != "hold" {
+		t.Errorf("Expected hold creative approval, got %q", publisher.CreativeApproval)
+	}
+	if publisher.AllowedCountries != "USA,CAN" || publisher.BlockedCountries != "PRK" {
+		t.Errorf("Expected USA,CAN allowed and PRK blocked, got %q and %q", publisher.AllowedCountries, publisher.BlockedCountries)
+	}
+	if publisher.TimeoutMs != 400 || publisher.MaxBidders != 3 || publisher.AllowedBidders != "appnexus,rubicon,pubmatic" || publisher.BlockedBidders != "rubicon" {
+		t.Errorf("Expected fan-out overrides to be loaded, got %d ms, %d bidders, %q allowed, %q blocked",
+			publisher.TimeoutMs, publisher.MaxBidders, publisher.AllowedBidders, publisher.BlockedBidders)
+	}
+	if publisher.PodMinCPM != 12.5 || publisher.PodMaxBidderShare != 0.5 {
+		t.Errorf("Expected pod rules to be loaded, got %v min CPM and %v bidder share", publisher.PodMinCPM, publisher.PodMaxBidderShare)
+	}
+	if publisher.PaymentTerms != PaymentTermsNet60 || publisher.BillingCurrency != "EUR" || publisher.InvoiceContactEmail != "ap@example.com" {
+		t.Errorf("Expected net-60 EUR billing to ap@example.com, got %+v", publisher.Billing)
+	}
+	if cfg := publisher.PlayerConfig; cfg == nil || cfg.PauseAdsEnabled == nil || !*cfg.PauseAdsEnabled {
+		t.Errorf("Expected pause ads enabled in player config, got %+v", cfg)
+	}
 
 	if err := mock.ExpectationsWereMet(); err != nil {
 		t.Errorf("Unfulfilled expectations: %v", err)
@@ -126,14 +189,11 @@ func TestPublisherStore_GetByPublisherID_NotFound(t *testing.T) {
 		WillReturnError(sql.ErrNoRows)
 
 	result, err := store.GetByPublisherID(ctx, "nonexistent")
-	if err != nil {
-		t.Errorf("Expected no error for non-existent publisher, got: %v", err)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for non-existent publisher, got: %v", err)
 	}
-	// Handle typed nil - interface{} containing (*Publisher)(nil)
 	if result != nil {
-		if pub, ok := result.(*Publisher); ok && pub != nil {
-			t.Error("Expected nil publisher")
-		}
+		t.Errorf("Expected a nil interface, got %#v", result)
 	}
 
 	if err := mock.ExpectationsWereMet(); err != nil {
@@ -184,6 +244,10 @@ func TestPublisherStore_GetByPublisherID_InvalidJSON(t *testing.T) {
 	rows := sqlmock.NewRows([]string{
 		"id", "publisher_id", "name", "allowed_domains", "bidder_params",
 		"bid_multiplier", "status", "version", "created_at", "updated_at", "notes", "contact_email",
+		"blocked_attributes", "max_bid_cpm", "player_config", "slo_p95_ms", "creative_sanitization",
+		"language_filter", "required_language", "creative_approval", "allowed_countries", "blocked_countries", "timeout_ms",
+		"max_bidders", "allowed_bidders", "blocked_bidders", "pod_min_cpm", "pod_max_bidder_share",
+		"payment_terms", "billing_currency", "invoice_contact_name", "invoice_contact_email",
 	}).AddRow(
 		"1",
 		"pub-123",
@@ -197,6 +261,26 @@ func TestPublisherStore_GetByPublisherID_InvalidJSON(t *testing.T) {
 		time.Now(),
 		"notes",
 		"test@example.com",
+		[]byte("[]"),
+		0.0,          // max_bid_cpm
+		[]byte("{}"), // player_config
+		0,            // slo_p95_ms
+		"",           // creative_sanitization
+		"",           // language_filter
+		"",           // required_language
+		"",           // creative_approval
+		"",           // allowed_countries
+		"",           // blocked_countries
+		0,            // timeout_ms
+		0,            // max_bidders
+		"",           // allowed_bidders
+		"",           // blocked_bidders
+		0.0,          // pod_min_cpm
+		0.0,          // pod_max_bidder_share
+		"net-30",     // payment_terms
+		"USD",        // billing_currency
+		"",           // invoice_contact_name
+		"",           // invoice_contact_email
 	)
 
 	mock.ExpectQuery("SELECT (.+) FROM publishers WHERE publisher_id").
@@ -237,12 +321,18 @@ func TestPublisherStore_List_Success(t *testing.T) {
 	rows := sqlmock.NewRows([]string{
 		"id", "publisher_id", "name", "allowed_domains", "bidder_params",
 		"bid_multiplier", "status", "version", "created_at", "updated_at", "notes", "contact_email",
+		"blocked_attributes", "max_bid_cpm", "player_config", "slo_p95_ms", "creative_sanitization",
+		"language_filter", "required_language", "creative_approval", "allowed_countries", "blocked_countries", "timeout_ms",
+		"max_bidders", "allowed_bidders", "blocked_bidders", "pod_min_cpm", "pod_max_bidder_share",
+		"payment_terms", "billing_currency", "invoice_contact_name", "invoice_contact_email",
 	}).AddRow(
 		pub1.ID, pub1.PublisherID, pub1.Name, pub1.AllowedDomains, bidderParamsJSON1,
-		pub1.BidMultiplier, pub1.Status, 1, pub1.CreatedAt, pub1.UpdatedAt, pub1.Notes, pub1.ContactEmail,
+		pub1.BidMultiplier, pub1.Status, 1, pub1.CreatedAt, pub1.UpdatedAt, pub1.Notes, pub1.ContactEmail, []byte("[]"), 0.0, []byte("{}"), 0, "", "", "", "", "", "",
+		0, 0, "", "", 0.0, 0.0, "net-30", "USD", "", "",
 	).AddRow(
 		pub2.ID, pub2.PublisherID, pub2.Name, pub2.AllowedDomains, bidderParamsJSON2,
-		pub2.BidMultiplier, pub2.Status, 1, pub2.CreatedAt, pub2.UpdatedAt, pub2.Notes, pub2.ContactEmail,
+		pub2.BidMultiplier, pub2.Status, 1, pub2.CreatedAt, pub2.UpdatedAt, pub2.Notes, pub2.ContactEmail, []byte("[]"), 0.0, []byte("{}"), 0, "", "", "", "", "", "",
+		0, 0, "", "", 0.0, 0.0, "net-30", "USD", "", "",
 	)
 
 	mock.ExpectQuery("SELECT (.+) FROM publishers WHERE status").
@@ -282,6 +372,10 @@ func TestPublisherStore_List_Empty(t *testing.T) {
 	rows := sqlmock.NewRows([]string{
 		"id", "publisher_id", "name", "allowed_domains", "bidder_params",
 		"bid_multiplier", "status", "version", "created_at", "updated_at", "notes", "contact_email",
+		"blocked_attributes", "max_bid_cpm", "player_config", "slo_p95_ms", "creative_sanitization",
+		"language_filter", "required_language", "creative_approval", "allowed_countries", "blocked_countries", "timeout_ms",
+		"max_bidders", "allowed_bidders", "blocked_bidders", "pod_min_cpm", "pod_max_bidder_share",
+		"payment_terms", "billing_currency", "invoice_contact_name", "invoice_contact_email",
 	})
 
 	mock.ExpectQuery("SELECT (.+) FROM publishers WHERE status").
@@ -340,9 +434,14 @@ func TestPublisherStore_List_ScanError(t *testing.T) {
 	rows := sqlmock.NewRows([]string{
 		"id", "publisher_id", "name", "allowed_domains", "bidder_params",
 		"bid_multiplier", "status", "version", "created_at", "updated_at", "notes", "contact_email",
+		"blocked_attributes", "max_bid_cpm", "player_config", "slo_p95_ms", "creative_sanitization",
+		"language_filter", "required_language", "creative_approval", "allowed_countries", "blocked_countries", "timeout_ms",
+		"max_bidders", "allowed_bidders", "blocked_bidders", "pod_min_cpm", "pod_max_bidder_share",
+		"payment_terms", "billing_currency", "invoice_contact_name", "invoice_contact_email",
 	}).AddRow(
 		"1", "pub-1", "Test", "example.com", []byte("{invalid}"),
-		1.05, "active", 1, time.Now(), time.Now(), "notes", "test@example.com",
+		1.05, "active", 1, time.Now(), time.Now(), "notes", "test@example.com", []byte("[]"), 0.0, []byte("{}"), 0, "", "", "", "", "", "",
+		0, 0, "", "", 0.0, 0.0, "net-30", "USD", "", "",
 	)
 
 	mock.ExpectQuery("SELECT (.+) FROM publishers WHERE status").
@@ -387,6 +486,26 @@ func TestPublisherStore_Create_Success(t *testing.T) {
 			publisher.Status,
 			publisher.Notes,
 			publisher.ContactEmail,
+			[]byte("[]"), // blocked_attributes
+			0.0,          // max_bid_cpm
+			[]byte("{}"), // player_config
+			0,            // slo_p95_ms
+			"",           // creative_sanitization
+			"",           // language_filter
+			"",           // required_language
+			"",           // creative_approval
+			"",           // allowed_countries
+			"",           // blocked_countries
+			0,            // timeout_ms
+			0,            // max_bidders
+			"",           // allowed_bidders
+			"",           // blocked_bidders
+			0.0,          // pod_min_cpm
+			0.0,          // pod_max_bidder_share
+			"net-30",     // payment_terms
+			"USD",        // billing_currency
+			"",           // invoice_contact_name
+			"",           // invoice_contact_email
 		).
 		WillReturnRows(rows)
 
@@ -404,6 +523,53 @@ func TestPublisherStore_Create_Success(t *testing.T) {
 	}
 }
 
+// insertShape counts the columns, placeholders and bound arguments of an
+// INSERT, so a column added to one list but not the others fails in tests
+// rather than on every create in production
+func insertShape(t *testing.T, run func(db *sql.DB) error, args int) (columns, placeholders int) {
+	t.Helper()
+	var query string
+	matcher := sqlmock.QueryMatcherFunc(func(_, actual string) error {
+		query = actual
+		return nil
+	})
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(matcher))
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	anyArgs := make([]driver.Value, args)
+	for i := range anyArgs {
+		anyArgs[i] = sqlmock.AnyArg()
+	}
+	mock.ExpectQuery("INSERT").WithArgs(anyArgs...).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "version", "created_at", "updated_at"}).
+			AddRow("1", 1, time.Now(), time.Now()))
+	if err := run(db); err != nil {
+		t.Fatalf("expected %d bound arguments: %v", args, err)
+	}
+
+	open, values := strings.Index(query, "("), strings.Index(query, "VALUES")
+	if open < 0 || values < open {
+		t.Fatalf("unexpected insert: %s", query)
+	}
+	columns = len(strings.Split(query[open+1:strings.LastIndex(query[:values], ")")], ","))
+	placeholders = len(regexp.MustCompile(`\$\d+`).FindAllString(query[values:], -1))
+	return columns, placeholders
+}
+
+func TestPublisherStore_Create_ColumnsMatchPlaceholders(t *testing.T) {
+	const columns = 28
+	gotColumns, placeholders := insertShape(t, func(db *sql.DB) error {
+		return NewPublisherStore(db).Create(context.Background(), createTestPublisher("pub-new"))
+	}, columns)
+	if gotColumns != columns || placeholders != columns {
+		t.Errorf("expected %d columns and placeholders for %d arguments, got %d columns and %d placeholders",
+			columns, columns, gotColumns, placeholders)
+	}
+}
+
 func TestPublisherStore_Create_DefaultBidMultiplier(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -430,6 +596,26 @@ func TestPublisherStore_Create_DefaultBidMultiplier(t *testing.T) {
 			publisher.Status,
 			publisher.Notes,
 			publisher.ContactEmail,
+			[]byte("[]"), // blocked_attributes
+			0.0,          // max_bid_cpm
+			[]byte("{}"), // player_config
+			0,            // slo_p95_ms
+			"",           // creative_sanitization
+			"",           // language_filter
+			"",           // required_language
+			"",           // creative_approval
+			"",           // allowed_countries
+			"",           // blocked_countries
+			0,            // timeout_ms
+			0,            // max_bidders
+			"",           // allowed_bidders
+			"",           // blocked_bidders
+			0.0,          // pod_min_cpm
+			0.0,          // pod_max_bidder_share
+			"net-30",     // payment_terms
+			"USD",        // billing_currency
+			"",           // invoice_contact_name
+			"",           // invoice_contact_email
 		).
 		WillReturnRows(rows)
 
@@ -485,6 +671,11 @@ func TestPublisherStore_Create_QueryError(t *testing.T) {
 		WithArgs(
 			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
 			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
 		).
 		WillReturnError(errors.New("database error"))
 
@@ -498,6 +689,55 @@ func TestPublisherStore_Create_QueryError(t *testing.T) {
 	}
 }
 
+// TestPublisherStore_Create_Duplicate tests that a duplicate publisher_id is
+// reported as a conflict
+func TestPublisherStore_Create_Duplicate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewPublisherStore(db)
+
+	mock.ExpectQuery("INSERT INTO publishers").
+		WillReturnError(&pq.Error{Code: "23505", Message: "duplicate key value violates unique constraint"})
+
+	err = store.Create(context.Background(), createTestPublisher("pub-dup"))
+	if !errors.Is(err, ErrConflict) {
+		t.Errorf("Expected ErrConflict, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestPublisherStore_MaxBiddersAboveCap(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewPublisherStore(db)
+	store.SetMaxBidders(50)
+
+	publisher := createTestPublisher("pub-wide")
+	publisher.MaxBidders = 80
+	if err := store.Create(context.Background(), publisher); !errors.Is(err, ErrValidation) {
+		t.Errorf("Expected ErrValidation on create, got %v", err)
+	}
+	if err := store.Update(context.Background(), publisher); !errors.Is(err, ErrValidation) {
+		t.Errorf("Expected ErrValidation on update, got %v", err)
+	}
+
+	// Nothing reaches the database
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
 func TestPublisherStore_Update_Success(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -531,6 +771,26 @@ func TestPublisherStore_Update_Success(t *testing.T) {
 			publisher.Status,
 			publisher.Notes,
 			publisher.ContactEmail,
+			[]byte("[]"), // blocked_attributes
+			0.0,          // max_bid_cpm
+			[]byte("{}"), // player_config
+			0,            // slo_p95_ms
+			"",           // creative_sanitization
+			"",           // language_filter
+			"",           // required_language
+			"",           // creative_approval
+			"",           // allowed_countries
+			"",           // blocked_countries
+			0,            // timeout_ms
+			0,            // max_bidders
+			"",           // allowed_bidders
+			"",           // blocked_bidders
+			0.0,          // pod_min_cpm
+			0.0,          // pod_max_bidder_share
+			"net-30",     // payment_terms
+			"USD",        // billing_currency
+			"",           // invoice_contact_name
+			"",           // invoice_contact_email
 			publisher.PublisherID,
 			1, // version
 		).
@@ -692,6 +952,82 @@ func TestPublisherStore_Delete_NotFound(t *testing.T) {
 	}
 }
 
+func TestPublisherStore_Restore(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewPublisherStore(db)
+	ctx := context.Background()
+
+	mock.ExpectQuery("SELECT name, allowed_domains, status, version FROM publishers WHERE publisher_id").
+		WithArgs("pub-123").
+		WillReturnRows(sqlmock.NewRows([]string{"name", "allowed_domains", "status", "version"}).
+			AddRow("Publisher", "example.com", "archived", 4))
+	mock.ExpectExec("UPDATE publishers SET status = 'active' WHERE publisher_id = \\$1 AND status = 'archived' AND version = \\$2").
+		WithArgs("pub-123", 4).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	var checked string
+	p, err := store.Restore(ctx, "pub-123", func(p *Publisher) error {
+		checked = p.AllowedDomains
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if checked != "example.com" || p.Status != "active" || p.Version != 5 {
+		t.Errorf("expected the archived row checked and restored, got %q and %+v", checked, p)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestPublisherStore_Restore_Rejected(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewPublisherStore(db)
+	ctx := context.Background()
+	columns := []string{"name", "allowed_domains", "status", "version"}
+
+	// Not archived
+	mock.ExpectQuery("SELECT name, allowed_domains, status, version FROM publishers").
+		WithArgs("pub-123").
+		WillReturnRows(sqlmock.NewRows(columns).AddRow("Publisher", "example.com", "active", 2))
+	if _, err := store.Restore(ctx, "pub-123", nil); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrConflict for an active publisher, got %v", err)
+	}
+
+	// Failing the check leaves the row archived
+	mock.ExpectQuery("SELECT name, allowed_domains, status, version FROM publishers").
+		WithArgs("pub-123").
+		WillReturnRows(sqlmock.NewRows(columns).AddRow("Publisher", "", "archived", 2))
+	invalid := fmt.Errorf("%w: allowed_domains is empty", ErrValidation)
+	if _, err := store.Restore(ctx, "pub-123", func(*Publisher) error { return invalid }); !errors.Is(err, ErrValidation) {
+		t.Errorf("expected the check's error, got %v", err)
+	}
+
+	// Unknown publisher
+	mock.ExpectQuery("SELECT name, allowed_domains, status, version FROM publishers").
+		WithArgs("nonexistent").
+		WillReturnError(sql.ErrNoRows)
+	if _, err := store.Restore(ctx, "nonexistent", nil); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
 func TestPublisherStore_Delete_QueryError(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -766,9 +1102,10 @@ func TestPublisherStore_GetBidderParams_NotFound(t *testing.T) {
 		WithArgs("pub-123", "nonexistent").
 		WillReturnError(sql.ErrNoRows)
 
+	// No row means the publisher itself doesn't exist
 	params, err := store.GetBidderParams(ctx, "pub-123", "nonexistent")
-	if err != nil {
-		t.Errorf("Expected no error for non-existent bidder params, got: %v", err)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for non-existent publisher, got: %v", err)
 	}
 	if params != nil {
 		t.Error("Expected nil params")
@@ -0,0 +1,251 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// HourlyMetrics is one hour of business metrics for a publisher, bidder and
+// media type (see migration 011). Request counts are per auction, so they are
+// stored with an empty Bidder; bid, win and money counters are per bidder.
+type HourlyMetrics struct {
+	Hour        time.Time `json:"hour"`
+	PublisherID string    `json:"publisher_id"`
+	Bidder      string    `json:"bidder"`
+	MediaType   string    `json:"media_type"`
+	Advertiser  string    `json:"advertiser,omitempty"` // set on win and impression rows
+	Requests    int64     `json:"requests"`
+	Bids        int64     `json:"bids"`
+	Wins        int64     `json:"wins"`
+	Impressions int64     `json:"impressions"`
+	Revenue     float64   `json:"revenue"` // gross, what bidders pay
+	Payout      float64   `json:"payout"`  // what publishers earn
+	Margin      float64   `json:"margin"`  // revenue - payout
+}
+
+// RollupStore reads and writes the hourly metrics rollup
+type RollupStore struct {
+	db *sql.DB
+}
+
+// NewRollupStore creates a new rollup store
+func NewRollupStore(db *sql.DB) *RollupStore {
+	return &RollupStore{db: db}
+}
+
+// UpsertHourly writes an instance's running totals. Each row replaces the
+// instance's previous totals for its hour, so repeating a flush is harmless.
+func (s *RollupStore) UpsertHourly(ctx context.Context, instanceID string, rows []*HourlyMetrics) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	ctx, cancel := withTimeout(ctx, DefaultDBTimeout)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO metrics_hourly (
+			hour, publisher_id, bidder, media_type, advertiser, instance_id,
+			requests, bids, wins, impressions, revenue, payout, margin
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (hour, publisher_id, bidder, media_type, advertiser, instance_id) DO UPDATE SET
+			requests = EXCLUDED.requests,
+			bids = EXCLUDED.bids,
+			wins = EXCLUDED.wins,
+			impressions = EXCLUDED.impressions,
+			revenue = EXCLUDED.revenue,
+			payout = EXCLUDED.payout,
+			margin = EXCLUDED.margin,
+			updated_at = CURRENT_TIMESTAMP
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare rollup upsert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, r := range rows {
+		if _, err := stmt.ExecContext(ctx,
+			r.Hour.UTC(), r.PublisherID, r.Bidder, r.MediaType, r.Advertiser, instanceID,
+			r.Requests, r.Bids, r.Wins, r.Impressions, r.Revenue, r.Payout, r.Margin,
+		); err != nil {
+			return fmt.Errorf("failed to upsert rollup row: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit rollup: %w", err)
+	}
+	return nil
+}
+
+// DeleteHourlyBefore removes rollup rows for hours before cutoff and returns
+// how many were deleted
+func (s *RollupStore) DeleteHourlyBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	ctx, cancel := withTimeout(ctx, DefaultDBTimeout)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, "DELETE FROM metrics_hourly WHERE hour < $1", cutoff.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete old rollups: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// QueryHourly returns metrics for hours in [from, to), summed across
+// instances. An empty publisherID returns every publisher.
+func (s *RollupStore) QueryHourly(ctx context.Context, from, to time.Time, publisherID string) ([]*HourlyMetrics, error) {
+	ctx, cancel := withTimeout(ctx, DefaultDBTimeout)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT hour, publisher_id, bidder, media_type, advertiser,
+		       SUM(requests), SUM(bids), SUM(wins), SUM(impressions),
+		       SUM(revenue), SUM(payout), SUM(margin)
+		FROM metrics_hourly
+		WHERE hour >= $1 AND hour < $2 AND ($3 = '' OR publisher_id = $3)
+		GROUP BY hour, publisher_id, bidder, media_type, advertiser
+		ORDER BY hour, publisher_id, bidder, media_type, advertiser
+	`, from.UTC(), to.UTC(), publisherID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rollups: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*HourlyMetrics
+	for rows.Next() {
+		m := &HourlyMetrics{}
+		if err := rows.Scan(
+			&m.Hour, &m.PublisherID, &m.Bidder, &m.MediaType, &m.Advertiser,
+			&m.Requests, &m.Bids, &m.Wins, &m.Impressions,
+			&m.Revenue, &m.Payout, &m.Margin,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan rollup row: %w", err)
+		}
+		result = append(result, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rollups: %w", err)
+	}
+	return result, nil
+}
+
+// AdvertiserFrequency counts how often an advertiser domain won a publisher's
+// auctions in an hour (see migration 027). Sessions are distinct viewers and
+// SessionWins the wins for requests that identified one.
+type AdvertiserFrequency struct {
+	Hour             time.Time `json:"hour"`
+	PublisherID      string    `json:"publisher_id"`
+	AdvertiserDomain string    `json:"advertiser_domain"`
+	Wins             int64     `json:"wins"`
+	Sessions         int64     `json:"sessions"`
+	SessionWins      int64     `json:"session_wins"`
+	MaxSessionWins   int64     `json:"max_session_wins"` // most wins by one viewer
+}
+
+// UpsertAdvertiserFrequency writes an instance's running advertiser counts,
+// replacing its previous counts for each hour like UpsertHourly
+func (s *RollupStore) UpsertAdvertiserFrequency(ctx context.Context, instanceID string, rows []*AdvertiserFrequency) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	ctx, cancel := withTimeout(ctx, DefaultDBTimeout)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO advertiser_frequency_hourly (
+			hour, publisher_id, advertiser_domain, instance_id,
+			wins, sessions, session_wins, max_session_wins
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (hour, publisher_id, advertiser_domain, instance_id) DO UPDATE SET
+			wins = EXCLUDED.wins,
+			sessions = EXCLUDED.sessions,
+			session_wins = EXCLUDED.session_wins,
+			max_session_wins = EXCLUDED.max_session_wins,
+			updated_at = CURRENT_TIMESTAMP
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare advertiser frequency upsert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, r := range rows {
+		if _, err := stmt.ExecContext(ctx,
+			r.Hour.UTC(), r.PublisherID, r.AdvertiserDomain, instanceID,
+			r.Wins, r.Sessions, r.SessionWins, r.MaxSessionWins,
+		); err != nil {
+			return fmt.Errorf("failed to upsert advertiser frequency row: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit advertiser frequency: %w", err)
+	}
+	return nil
+}
+
+// DeleteAdvertiserFrequencyBefore removes advertiser counts for hours before
+// cutoff and returns how many rows were deleted
+func (s *RollupStore) DeleteAdvertiserFrequencyBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	ctx, cancel := withTimeout(ctx, DefaultDBTimeout)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, "DELETE FROM advertiser_frequency_hourly WHERE hour < $1", cutoff.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete old advertiser frequency: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// QueryAdvertiserFrequency returns each publisher's advertiser domains over
+// hours in [from, to), summed across hours and instances, most wins first.
+// Hour is left zero. A viewer active in several hours or on several
+// instances counts once in each. An empty publisherID returns every
+// publisher.
+func (s *RollupStore) QueryAdvertiserFrequency(ctx context.Context, from, to time.Time, publisherID string) ([]*AdvertiserFrequency, error) {
+	ctx, cancel := withTimeout(ctx, DefaultDBTimeout)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT publisher_id, advertiser_domain,
+		       SUM(wins), SUM(sessions), SUM(session_wins), MAX(max_session_wins)
+		FROM advertiser_frequency_hourly
+		WHERE hour >= $1 AND hour < $2 AND ($3 = '' OR publisher_id = $3)
+		GROUP BY publisher_id, advertiser_domain
+		ORDER BY publisher_id, SUM(wins) DESC, advertiser_domain
+	`, from.UTC(), to.UTC(), publisherID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query advertiser frequency: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*AdvertiserFrequency
+	for rows.Next() {
+		f := &AdvertiserFrequency{}
+		if err := rows.Scan(
+			&f.PublisherID, &f.AdvertiserDomain,
+			&f.Wins, &f.Sessions, &f.SessionWins, &f.MaxSessionWins,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan advertiser frequency row: %w", err)
+		}
+		result = append(result, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating advertiser frequency: %w", err)
+	}
+	return result, nil
+}
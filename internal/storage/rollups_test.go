@@ -0,0 +1,192 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// TestRollupStore_UpsertHourly tests writing an instance's running totals
+func TestRollupStore_UpsertHourly(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewRollupStore(db)
+	hour := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	rows := []*HourlyMetrics{
+		{Hour: hour, PublisherID: "pub1", MediaType: "video", Requests: 10},
+		{Hour: hour, PublisherID: "pub1", Bidder: "appnexus", MediaType: "video", Advertiser: "Acme Motors", Bids: 8, Wins: 2, Revenue: 0.01, Payout: 0.008, Margin: 0.002},
+	}
+
+	mock.ExpectBegin()
+	prep := mock.ExpectPrepare("INSERT INTO metrics_hourly .+ ON CONFLICT \\(hour, publisher_id, bidder, media_type, advertiser, instance_id\\) DO UPDATE")
+	prep.ExpectExec().
+		WithArgs(hour, "pub1", "", "video", "", "host-1", int64(10), int64(0), int64(0), int64(0), 0.0, 0.0, 0.0).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	prep.ExpectExec().
+		WithArgs(hour, "pub1", "appnexus", "video", "Acme Motors", "host-1", int64(0), int64(8), int64(2), int64(0), 0.01, 0.008, 0.002).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err := store.UpsertHourly(context.Background(), "host-1", rows); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+// TestRollupStore_UpsertHourly_Error tests that a failed row rolls back the flush
+func TestRollupStore_UpsertHourly_Error(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewRollupStore(db)
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare("INSERT INTO metrics_hourly").
+		ExpectExec().
+		WillReturnError(errors.New("deadlock detected"))
+	mock.ExpectRollback()
+
+	err = store.UpsertHourly(context.Background(), "host-1", []*HourlyMetrics{{Hour: time.Now(), PublisherID: "pub1"}})
+	if err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+// TestRollupStore_QueryHourly tests reading totals summed across instances
+func TestRollupStore_QueryHourly(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewRollupStore(db)
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+
+	rows := sqlmock.NewRows([]string{"hour", "publisher_id", "bidder", "media_type", "advertiser", "requests", "bids", "wins", "impressions", "revenue", "payout", "margin"}).
+		AddRow(from, "pub1", "", "banner", "", 100, 0, 0, 0, 0.0, 0.0, 0.0).
+		AddRow(from, "pub1", "rubicon", "banner", "acme.example", 0, 60, 20, 18, 0.05, 0.04, 0.01)
+
+	mock.ExpectQuery("SELECT hour, publisher_id, bidder, media_type, advertiser, SUM\\(requests\\).+FROM metrics_hourly.+GROUP BY hour, publisher_id, bidder, media_type, advertiser").
+		WithArgs(from, to, "pub1").
+		WillReturnRows(rows)
+
+	result, err := store.QueryHourly(context.Background(), from, to, "pub1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(result) != 2 {
+		t.Fatalf("Expected 2 rows, got %d", len(result))
+	}
+	if result[1].Bidder != "rubicon" || result[1].Advertiser != "acme.example" || result[1].Wins != 20 || result[1].Margin != 0.01 {
+		t.Errorf("Unexpected row: %+v", result[1])
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+// TestRollupStore_DeleteHourlyBefore tests retention cleanup
+func TestRollupStore_DeleteHourlyBefore(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewRollupStore(db)
+	cutoff := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectExec("DELETE FROM metrics_hourly WHERE hour < ").
+		WithArgs(cutoff).
+		WillReturnResult(sqlmock.NewResult(0, 42))
+
+	deleted, err := store.DeleteHourlyBefore(context.Background(), cutoff)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if deleted != 42 {
+		t.Errorf("Expected 42 deleted rows, got %d", deleted)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+// TestRollupStore_UpsertAdvertiserFrequency tests writing an instance's advertiser counts
+func TestRollupStore_UpsertAdvertiserFrequency(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewRollupStore(db)
+	hour := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare("INSERT INTO advertiser_frequency_hourly .+ ON CONFLICT \\(hour, publisher_id, advertiser_domain, instance_id\\) DO UPDATE").
+		ExpectExec().
+		WithArgs(hour, "pub1", "acme.example", "host-1", int64(12), int64(4), int64(10), int64(5)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	rows := []*AdvertiserFrequency{{Hour: hour, PublisherID: "pub1", AdvertiserDomain: "acme.example", Wins: 12, Sessions: 4, SessionWins: 10, MaxSessionWins: 5}}
+	if err := store.UpsertAdvertiserFrequency(context.Background(), "host-1", rows); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+// TestRollupStore_QueryAdvertiserFrequency tests reading counts summed over a range
+func TestRollupStore_QueryAdvertiserFrequency(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewRollupStore(db)
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+
+	rows := sqlmock.NewRows([]string{"publisher_id", "advertiser_domain", "wins", "sessions", "session_wins", "max_session_wins"}).
+		AddRow("pub1", "acme.example", 30, 8, 24, 6).
+		AddRow("pub1", "soda.example", 5, 5, 5, 1)
+
+	mock.ExpectQuery("SELECT publisher_id, advertiser_domain, SUM\\(wins\\).+MAX\\(max_session_wins\\).+FROM advertiser_frequency_hourly.+GROUP BY publisher_id, advertiser_domain").
+		WithArgs(from, to, "pub1").
+		WillReturnRows(rows)
+
+	result, err := store.QueryAdvertiserFrequency(context.Background(), from, to, "pub1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(result) != 2 {
+		t.Fatalf("Expected 2 rows, got %d", len(result))
+	}
+	if result[0].AdvertiserDomain != "acme.example" || result[0].Wins != 30 || result[0].Sessions != 8 || result[0].MaxSessionWins != 6 {
+		t.Errorf("Unexpected row: %+v", result[0])
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
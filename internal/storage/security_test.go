@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"strings"
 	"testing"
 
@@ -78,13 +79,11 @@ func TestSQLInjection_PublisherID(t *testing.T) {
 			result, err := store.GetByPublisherID(ctx, tc.publisherID)
 
 			// Should return safely without executing malicious SQL
-			if err != nil {
+			if !errors.Is(err, ErrNotFound) {
 				t.Errorf("Unexpected error: %v", err)
 			}
 			if result != nil {
-				if pub, ok := result.(*Publisher); ok && pub != nil {
-					t.Error("Should not return publisher for SQL injection attempt")
-				}
+				t.Error("Should not return publisher for SQL injection attempt")
 			}
 
 			// Verify expectations - this ensures parameterized query was used
@@ -142,7 +141,7 @@ func TestSQLInjection_GetBidderParams(t *testing.T) {
 			params, err := store.GetBidderParams(ctx, tc.publisherID, tc.bidderCode)
 
 			// Should handle safely
-			if err != nil {
+			if !errors.Is(err, ErrNotFound) {
 				t.Errorf("Unexpected error: %v", err)
 			}
 			if params != nil {
@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// StoredCreative is a publisher's house ad, served when an auction has no
+// valid bids (see migration 022)
+type StoredCreative struct {
+	ID               string    `json:"id"`
+	PublisherID      string    `json:"publisher_id"`
+	MediaType        string    `json:"media_type"` // banner or video
+	Markup           string    `json:"markup"`     // banner HTML or VAST
+	W                int       `json:"w,omitempty"`
+	H                int       `json:"h,omitempty"`
+	Duration         int       `json:"duration,omitempty"` // video seconds
+	ADomain          string    `json:"adomain,omitempty"`  // comma-separated advertiser domains
+	Priority         int       `json:"priority"`
+	MaxImpressions   int       `json:"max_impressions"`    // per user per cap window; 0 = uncapped
+	CapWindowSeconds int       `json:"cap_window_seconds"` // frequency cap window
+	Active           bool      `json:"active"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// StoredCreativeStore reads publisher house ads
+type StoredCreativeStore struct {
+	db *sql.DB
+}
+
+// NewStoredCreativeStore creates a new stored creative store
+func NewStoredCreativeStore(db *sql.DB) *StoredCreativeStore {
+	return &StoredCreativeStore{db: db}
+}
+
+// LoadActive returns every active house ad, highest priority first within
+// each publisher
+func (s *StoredCreativeStore) LoadActive(ctx context.Context) ([]*StoredCreative, error) {
+	ctx, cancel := withTimeout(ctx, DefaultDBTimeout)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, publisher_id, media_type, markup, w, h, duration, adomain,
+		       priority, max_impressions, cap_window_seconds, active, created_at, updated_at
+		FROM stored_creatives
+		WHERE active
+		ORDER BY publisher_id, priority DESC, id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query stored creatives: %w", err)
+	}
+	defer rows.Close()
+
+	var creatives []*StoredCreative
+	for rows.Next() {
+		c := &StoredCreative{}
+		if err := rows.Scan(
+			&c.ID, &c.PublisherID, &c.MediaType, &c.Markup, &c.W, &c.H, &c.Duration, &c.ADomain,
+			&c.Priority, &c.MaxImpressions, &c.CapWindowSeconds, &c.Active, &c.CreatedAt, &c.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan stored creative row: %w", err)
+		}
+		creatives = append(creatives, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating stored creatives: %w", err)
+	}
+	return creatives, nil
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// TestStoredCreativeStore_LoadActive tests loading active house ads in priority order
+func TestStoredCreativeStore_LoadActive(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewStoredCreativeStore(db)
+	now := time.Now()
+	mock.ExpectQuery("SELECT id, publisher_id, media_type, markup.+FROM stored_creatives.+WHERE active.+ORDER BY publisher_id, priority DESC, id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "publisher_id", "media_type", "markup", "w", "h", "duration", "adomain",
+			"priority", "max_impressions", "cap_window_seconds", "active", "created_at", "updated_at"}).
+			AddRow("house-video", "pub1", "video", "<VAST/>", 0, 0, 15, "pub1.example", 10, 3, 3600, true, now, now).
+			AddRow("house-banner", "pub1", "banner", "<div/>", 300, 250, 0, "", 0, 0, 0, true, now, now))
+
+	creatives, err := store.LoadActive(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(creatives) != 2 {
+		t.Fatalf("Expected 2 house ads, got %d", len(creatives))
+	}
+	if c := creatives[0]; c.ID != "house-video" || c.Duration != 15 || c.MaxImpressions != 3 || c.CapWindowSeconds != 3600 {
+		t.Errorf("Unexpected house ad: %+v", c)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+// TestStoredCreativeStore_LoadActive_Error tests query failures are wrapped
+func TestStoredCreativeStore_LoadActive_Error(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewStoredCreativeStore(db)
+	mock.ExpectQuery("SELECT id, publisher_id").WillReturnError(errors.New("connection refused"))
+
+	if _, err := store.LoadActive(context.Background()); err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+}
@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Stored request kinds (stored_requests.kind)
+const (
+	StoredKindRequest = "request" // referenced by ext.prebid.storedrequest.id
+	StoredKindImp     = "imp"     // referenced by imp[].ext.prebid.storedrequest.id
+)
+
+// StoredRequest is a server-side request or impression template (see
+// migration 019)
+type StoredRequest struct {
+	Kind        string          `json:"kind"`
+	ID          string          `json:"id"`
+	PublisherID string          `json:"publisher_id,omitempty"` // only this publisher may reference it; "" = any
+	Data        json.RawMessage `json:"data"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// validateStoredKind rejects unknown stored request kinds
+func validateStoredKind(kind string) error {
+	switch kind {
+	case StoredKindRequest, StoredKindImp:
+		return nil
+	}
+	return fmt.Errorf("%w stored request kind: %q", ErrValidation, kind)
+}
+
+// StoredRequestStore reads and writes stored request templates
+type StoredRequestStore struct {
+	db *sql.DB
+}
+
+// NewStoredRequestStore creates a new stored request store
+func NewStoredRequestStore(db *sql.DB) *StoredRequestStore {
+	return &StoredRequestStore{db: db}
+}
+
+// GetMany returns the templates of kind with the given IDs, by ID. IDs
+// without a template are left out.
+func (s *StoredRequestStore) GetMany(ctx context.Context, kind string, ids []string) (map[string]*StoredRequest, error) {
+	found := make(map[string]*StoredRequest, len(ids))
+	if len(ids) == 0 {
+		return found, nil
+	}
+
+	ctx, cancel := withTimeout(ctx, DefaultDBTimeout)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT kind, id, publisher_id, data, created_at, updated_at
+		FROM stored_requests
+		WHERE kind = $1 AND id = ANY($2)
+	`, kind, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query stored requests: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		sr := &StoredRequest{}
+		var data []byte
+		if err := rows.Scan(&sr.Kind, &sr.ID, &sr.PublisherID, &data, &sr.CreatedAt, &sr.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan stored request row: %w", err)
+		}
+		sr.Data = data
+		found[sr.ID] = sr
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating stored requests: %w", err)
+	}
+	return found, nil
+}
+
+// Get returns one template, or ErrNotFound
+func (s *StoredRequestStore) Get(ctx context.Context, kind, id string) (*StoredRequest, error) {
+	found, err := s.GetMany(ctx, kind, []string{id})
+	if err != nil {
+		return nil, err
+	}
+	sr, ok := found[id]
+	if !ok {
+		return nil, fmt.Errorf("stored %s %w: %s", kind, ErrNotFound, id)
+	}
+	return sr, nil
+}
+
+// Put creates or replaces a template. Data must be a JSON object.
+func (s *StoredRequestStore) Put(ctx context.Context, sr *StoredRequest) error {
+	if err := validateStoredKind(sr.Kind); err != nil {
+		return err
+	}
+	if sr.ID == "" {
+		return fmt.Errorf("%w stored request: id is required", ErrValidation)
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(sr.Data, &obj); err != nil || obj == nil {
+		return fmt.Errorf("%w stored request: data must be a JSON object", ErrValidation)
+	}
+
+	ctx, cancel := withTimeout(ctx, DefaultDBTimeout)
+	defer cancel()
+
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO stored_requests (kind, id, publisher_id, data)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (kind, id) DO UPDATE SET
+			publisher_id = EXCLUDED.publisher_id,
+			data = EXCLUDED.data,
+			updated_at = CURRENT_TIMESTAMP
+		RETURNING created_at, updated_at
+	`, sr.Kind, sr.ID, sr.PublisherID, []byte(sr.Data)).Scan(&sr.CreatedAt, &sr.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save stored request: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a template, or returns ErrNotFound
+func (s *StoredRequestStore) Delete(ctx context.Context, kind, id string) error {
+	ctx, cancel := withTimeout(ctx, DefaultDBTimeout)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, `DELETE FROM stored_requests WHERE kind = $1 AND id = $2`, kind, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete stored request: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("stored %s %w: %s", kind, ErrNotFound, id)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// TestStoredRequestStore_GetMany tests fetching templates by ID
+func TestStoredRequestStore_GetMany(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewStoredRequestStore(db)
+	now := time.Now()
+	mock.ExpectQuery("SELECT kind, id, publisher_id, data, created_at, updated_at FROM stored_requests").
+		WithArgs(StoredKindImp, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"kind", "id", "publisher_id", "data", "created_at", "updated_at"}).
+			AddRow(StoredKindImp, "imp-300x250", "pub-1", []byte(`{"banner":{"w":300,"h":250}}`), now, now))
+
+	found, err := store.GetMany(context.Background(), StoredKindImp, []string{"imp-300x250", "missing"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	sr := found["imp-300x250"]
+	if len(found) != 1 || sr == nil || sr.PublisherID != "pub-1" || string(sr.Data) != `{"banner":{"w":300,"h":250}}` {
+		t.Errorf("Unexpected templates: %+v", found)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+// TestStoredRequestStore_Put tests saving and validating templates
+func TestStoredRequestStore_Put(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewStoredRequestStore(db)
+	now := time.Now()
+	mock.ExpectQuery("INSERT INTO stored_requests .+ ON CONFLICT \\(kind, id\\) DO UPDATE").
+		WithArgs(StoredKindRequest, "homepage", "", []byte(`{"tmax":800}`)).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at"}).AddRow(now, now))
+
+	sr := &StoredRequest{Kind: StoredKindRequest, ID: "homepage", Data: json.RawMessage(`{"tmax":800}`)}
+	if err := store.Put(context.Background(), sr); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !sr.UpdatedAt.Equal(now) {
+		t.Errorf("Expected timestamps to be returned, got %v", sr.UpdatedAt)
+	}
+
+	invalid := []*StoredRequest{
+		{Kind: "response", ID: "x", Data: json.RawMessage(`{}`)},
+		{Kind: StoredKindRequest, Data: json.RawMessage(`{}`)},
+		{Kind: StoredKindRequest, ID: "x", Data: json.RawMessage(`[1]`)},
+	}
+	for _, sr := range invalid {
+		if err := store.Put(context.Background(), sr); !errors.Is(err, ErrValidation) {
+			t.Errorf("Expected a validation error for %+v, got %v", sr, err)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+// TestStoredRequestStore_Delete tests deleting templates
+func TestStoredRequestStore_Delete(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewStoredRequestStore(db)
+	mock.ExpectExec("DELETE FROM stored_requests").
+		WithArgs(StoredKindRequest, "homepage").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM stored_requests").
+		WithArgs(StoredKindRequest, "missing").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := store.Delete(context.Background(), StoredKindRequest, "homepage"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := store.Delete(context.Background(), StoredKindRequest, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
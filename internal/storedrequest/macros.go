@@ -0,0 +1,218 @@
+// Package storedrequest provides stored request template handling
+package storedrequest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+// Supported built-in macro names
+const (
+	MacroPageURL  = "PAGE_URL"
+	MacroDomain   = "DOMAIN"
+	MacroBundle   = "BUNDLE"
+	MacroKeywords = "KEYWORDS"
+	MacroRef      = "REF"
+
+	// customKVPrefix namespaces publisher-defined key-values, e.g. {{CUSTOM_KV.genre}}
+	customKVPrefix = "CUSTOM_KV."
+
+	// queryKVPrefix is the query param prefix mapped onto CUSTOM_KV, e.g. kv_genre=drama
+	queryKVPrefix = "kv_"
+
+	// maxMacroValueLength caps a single resolved value to keep templates bounded
+	maxMacroValueLength = 2048
+)
+
+var (
+	// macroPattern matches {{NAME}} and {{CUSTOM_KV.key}}
+	macroPattern = regexp.MustCompile(`\{\{([A-Z][A-Z0-9_]*(?:\.[A-Za-z0-9_\-]+)?)\}\}`)
+
+	// ErrMacroOutsideString is returned when a macro is not inside a JSON string literal
+	ErrMacroOutsideString = errors.New("macro must appear inside a JSON string")
+
+	// ErrInvalidTemplate is returned when the expanded template is not valid JSON
+	ErrInvalidTemplate = errors.New("expanded stored request is not valid JSON")
+)
+
+// builtinMacros lists the non-namespaced macro names that may be resolved
+var builtinMacros = map[string]bool{
+	MacroPageURL:  true,
+	MacroDomain:   true,
+	MacroBundle:   true,
+	MacroKeywords: true,
+	MacroRef:      true,
+}
+
+// MacroValues maps macro names to their raw (unescaped) values
+type MacroValues map[string]string
+
+// Merge copies values from other that are not already set
+func (v MacroValues) Merge(other MacroValues) {
+	for k, val := range other {
+		if _, exists := v[k]; !exists {
+			v[k] = val
+		}
+	}
+}
+
+// ValuesFromQuery builds macro values from request query parameters.
+// Built-in macros use their lower-case name (page_url, domain, ...) and
+// custom key-values use the kv_ prefix (kv_genre -> CUSTOM_KV.genre).
+func ValuesFromQuery(q url.Values) MacroValues {
+	values := make(MacroValues)
+	for key := range q {
+		val := q.Get(key)
+		if strings.HasPrefix(key, queryKVPrefix) {
+			name := strings.TrimPrefix(key, queryKVPrefix)
+			if name != "" {
+				values[customKVPrefix+name] = val
+			}
+			continue
+		}
+		upper := strings.ToUpper(key)
+		if builtinMacros[upper] {
+			values[upper] = val
+		}
+	}
+	return values
+}
+
+// ValuesFromRequest builds macro values from first-party data on the bid request.
+// Custom key-values are read from site.ext.data / app.ext.data; only scalar
+// values are used so nested objects can't be smuggled into a template.
+func ValuesFromRequest(req *openrtb.BidRequest) MacroValues {
+	values := make(MacroValues)
+	if req == nil {
+		return values
+	}
+
+	var ext json.RawMessage
+	switch {
+	case req.Site != nil:
+		setIfPresent(values, MacroPageURL, req.Site.Page)
+		setIfPresent(values, MacroDomain, req.Site.Domain)
+		setIfPresent(values, MacroKeywords, req.Site.Keywords)
+		setIfPresent(values, MacroRef, req.Site.Ref)
+		ext = req.Site.Ext
+	case req.App != nil:
+		setIfPresent(values, MacroDomain, req.App.Domain)
+		setIfPresent(values, MacroBundle, req.App.Bundle)
+		setIfPresent(values, MacroKeywords, req.App.Keywords)
+		ext = req.App.Ext
+	}
+
+	if len(ext) == 0 {
+		return values
+	}
+
+	var parsed struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(ext, &parsed); err != nil {
+		return values
+	}
+	for key, raw := range parsed.Data {
+		switch v := raw.(type) {
+		case string:
+			values[customKVPrefix+key] = v
+		case float64, bool:
+			values[customKVPrefix+key] = fmt.Sprint(v)
+		}
+	}
+	return values
+}
+
+// setIfPresent stores val under name when it is non-empty
+func setIfPresent(values MacroValues, name, val string) {
+	if val != "" {
+		values[name] = val
+	}
+}
+
+// ExpandMacros replaces {{NAME}} macros in a stored request template.
+// Macros are only permitted inside JSON string literals and every value is
+// JSON-string escaped, so a resolved value can never break out of its string
+// or alter the structure of the request. Unknown macro names are rejected;
+// known macros without a value resolve to an empty string.
+func ExpandMacros(template []byte, values MacroValues) ([]byte, error) {
+	matches := macroPattern.FindAllSubmatchIndex(template, -1)
+	if len(matches) == 0 {
+		return template, nil
+	}
+
+	inString := stringRegions(template)
+
+	var out bytes.Buffer
+	out.Grow(len(template))
+	last := 0
+	for _, m := range matches {
+		start, end := m[0], m[1]
+		name := string(template[m[2]:m[3]])
+
+		if !inString[start] {
+			return nil, fmt.Errorf("%w: {{%s}}", ErrMacroOutsideString, name)
+		}
+		if !strings.HasPrefix(name, customKVPrefix) && !builtinMacros[name] {
+			return nil, fmt.Errorf("unknown macro: {{%s}}", name)
+		}
+
+		val := values[name]
+		if len(val) > maxMacroValueLength {
+			return nil, fmt.Errorf("value for macro {{%s}} exceeds %d bytes", name, maxMacroValueLength)
+		}
+
+		out.Write(template[last:start])
+		out.Write(escapeJSONString(val))
+		last = end
+	}
+	out.Write(template[last:])
+
+	result := out.Bytes()
+	if !json.Valid(result) {
+		return nil, ErrInvalidTemplate
+	}
+	return result, nil
+}
+
+// escapeJSONString returns val encoded as JSON string contents (without quotes)
+func escapeJSONString(val string) []byte {
+	encoded, err := json.Marshal(val)
+	if err != nil || len(encoded) < 2 {
+		return nil
+	}
+	return encoded[1 : len(encoded)-1]
+}
+
+// stringRegions marks which byte offsets of data fall inside a JSON string literal
+func stringRegions(data []byte) []bool {
+	regions := make([]bool, len(data))
+	inString := false
+	escaped := false
+	for i, c := range data {
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+				continue
+			}
+			regions[i] = true
+			continue
+		}
+		if c == '"' {
+			inString = true
+		}
+	}
+	return regions
+}
@@ -0,0 +1,159 @@
+package storedrequest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+func TestExpandMacros_Builtin(t *testing.T) {
+	tmpl := []byte(`{"site":{"page":"{{PAGE_URL}}","domain":"{{DOMAIN}}"}}`)
+	values := MacroValues{MacroPageURL: "https://example.com/a", MacroDomain: "example.com"}
+
+	out, err := ExpandMacros(tmpl, values)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var parsed struct {
+		Site struct {
+			Page   string `json:"page"`
+			Domain string `json:"domain"`
+		} `json:"site"`
+	}
+	if err := json.Unmarshal(out, &parsed); err != nil {
+		t.Fatalf("output not valid JSON: %v", err)
+	}
+	if parsed.Site.Page != "https://example.com/a" {
+		t.Errorf("expected page to be expanded, got %q", parsed.Site.Page)
+	}
+	if parsed.Site.Domain != "example.com" {
+		t.Errorf("expected domain to be expanded, got %q", parsed.Site.Domain)
+	}
+}
+
+func TestExpandMacros_CustomKVAndMissing(t *testing.T) {
+	tmpl := []byte(`{"keywords":"genre={{CUSTOM_KV.genre}},tier={{CUSTOM_KV.tier}}"}`)
+	out, err := ExpandMacros(tmpl, MacroValues{"CUSTOM_KV.genre": "drama"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(out) != `{"keywords":"genre=drama,tier="}` {
+		t.Errorf("unexpected output: %s", out)
+	}
+}
+
+func TestExpandMacros_EscapesValues(t *testing.T) {
+	tmpl := []byte(`{"page":"{{PAGE_URL}}","id":"x"}`)
+	malicious := `a","id":"injected`
+
+	out, err := ExpandMacros(tmpl, MacroValues{MacroPageURL: malicious})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var parsed map[string]string
+	if err := json.Unmarshal(out, &parsed); err != nil {
+		t.Fatalf("output not valid JSON: %v", err)
+	}
+	if parsed["page"] != malicious {
+		t.Errorf("expected value to round-trip, got %q", parsed["page"])
+	}
+	if parsed["id"] != "x" {
+		t.Errorf("value escaped its string: id=%q", parsed["id"])
+	}
+}
+
+func TestExpandMacros_RejectsMacroOutsideString(t *testing.T) {
+	tmpl := []byte(`{"w":{{CUSTOM_KV.width}}}`)
+	_, err := ExpandMacros(tmpl, MacroValues{"CUSTOM_KV.width": "300"})
+	if !errors.Is(err, ErrMacroOutsideString) {
+		t.Errorf("expected ErrMacroOutsideString, got %v", err)
+	}
+}
+
+func TestExpandMacros_RejectsUnknownMacro(t *testing.T) {
+	_, err := ExpandMacros([]byte(`{"a":"{{SECRET}}"}`), MacroValues{"SECRET": "x"})
+	if err == nil {
+		t.Error("expected error for unknown macro")
+	}
+}
+
+func TestExpandMacros_RejectsOversizedValue(t *testing.T) {
+	big := make([]byte, maxMacroValueLength+1)
+	for i := range big {
+		big[i] = 'a'
+	}
+	_, err := ExpandMacros([]byte(`{"a":"{{PAGE_URL}}"}`), MacroValues{MacroPageURL: string(big)})
+	if err == nil {
+		t.Error("expected error for oversized value")
+	}
+}
+
+func TestExpandMacros_NoMacros(t *testing.T) {
+	tmpl := []byte(`{"id":"static"}`)
+	out, err := ExpandMacros(tmpl, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(out) != string(tmpl) {
+		t.Errorf("expected template unchanged, got %s", out)
+	}
+}
+
+func TestValuesFromQuery(t *testing.T) {
+	q := url.Values{}
+	q.Set("page_url", "https://example.com")
+	q.Set("kv_genre", "comedy")
+	q.Set("ignored", "x")
+
+	values := ValuesFromQuery(q)
+	if values[MacroPageURL] != "https://example.com" {
+		t.Errorf("expected PAGE_URL from query, got %q", values[MacroPageURL])
+	}
+	if values["CUSTOM_KV.genre"] != "comedy" {
+		t.Errorf("expected CUSTOM_KV.genre from query, got %q", values["CUSTOM_KV.genre"])
+	}
+	if _, ok := values["IGNORED"]; ok {
+		t.Error("expected unknown query param to be ignored")
+	}
+}
+
+func TestValuesFromRequest(t *testing.T) {
+	req := &openrtb.BidRequest{
+		Site: &openrtb.Site{
+			Page:   "https://example.com/article",
+			Domain: "example.com",
+			Ext:    json.RawMessage(`{"data":{"genre":"news","score":5,"nested":{"a":1}}}`),
+		},
+	}
+
+	values := ValuesFromRequest(req)
+	if values[MacroPageURL] != "https://example.com/article" {
+		t.Errorf("expected PAGE_URL from site.page, got %q", values[MacroPageURL])
+	}
+	if values["CUSTOM_KV.genre"] != "news" {
+		t.Errorf("expected CUSTOM_KV.genre from site.ext.data, got %q", values["CUSTOM_KV.genre"])
+	}
+	if values["CUSTOM_KV.score"] != "5" {
+		t.Errorf("expected numeric FPD to be stringified, got %q", values["CUSTOM_KV.score"])
+	}
+	if _, ok := values["CUSTOM_KV.nested"]; ok {
+		t.Error("expected nested FPD objects to be skipped")
+	}
+}
+
+func TestMacroValues_Merge(t *testing.T) {
+	values := MacroValues{MacroDomain: "query.com"}
+	values.Merge(MacroValues{MacroDomain: "fpd.com", MacroPageURL: "https://fpd.com"})
+
+	if values[MacroDomain] != "query.com" {
+		t.Errorf("expected existing value to win, got %q", values[MacroDomain])
+	}
+	if values[MacroPageURL] != "https://fpd.com" {
+		t.Errorf("expected missing value to be merged, got %q", values[MacroPageURL])
+	}
+}
@@ -0,0 +1,109 @@
+package storedrequest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+)
+
+// queryKey is the context key of the incoming request's query parameters
+type queryKey struct{}
+
+// WithQuery returns ctx carrying the query parameters of the incoming HTTP
+// request, which fill macros the bid request itself leaves unset
+func WithQuery(ctx context.Context, q url.Values) context.Context {
+	return context.WithValue(ctx, queryKey{}, q)
+}
+
+// macroSource resolves macro values from the incoming request on first use,
+// so requests whose templates have no macros skip the extra decode
+type macroSource struct {
+	body   []byte
+	query  url.Values
+	values MacroValues
+}
+
+func newMacroSource(ctx context.Context, body []byte) *macroSource {
+	q, _ := ctx.Value(queryKey{}).(url.Values)
+	return &macroSource{body: body, query: q}
+}
+
+// get returns the macro values of the incoming request, taken from the bid
+// request first and its query parameters second
+func (m *macroSource) get() MacroValues {
+	if m.values == nil {
+		var req openrtb.BidRequest
+		if err := json.Unmarshal(m.body, &req); err != nil {
+			m.values = make(MacroValues)
+		} else {
+			m.values = ValuesFromRequest(&req)
+		}
+		m.values.Merge(ValuesFromQuery(m.query))
+	}
+	return m.values
+}
+
+// mergeTemplate expands the macros of sr and applies patch to it as a JSON
+// merge patch (RFC 7386): objects merge recursively, anything else in patch
+// replaces the template's value, and null removes it
+func mergeTemplate(sr *storage.StoredRequest, patch []byte, macros *macroSource) ([]byte, error) {
+	data := []byte(sr.Data)
+	if bytes.Contains(data, []byte("{{")) {
+		expanded, err := ExpandMacros(data, macros.get())
+		if err != nil {
+			return nil, fmt.Errorf("%w: stored %s %s: %v", ErrInvalidReference, sr.Kind, sr.ID, err)
+		}
+		data = expanded
+	}
+
+	base, err := decodeJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: stored %s %s: %v", ErrInvalidReference, sr.Kind, sr.ID, err)
+	}
+	overrides, err := decodeJSON(patch)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidReference, err)
+	}
+	merged, err := json.Marshal(mergePatch(base, overrides))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode merged request: %w", err)
+	}
+	return merged, nil
+}
+
+// decodeJSON decodes data keeping numbers exact, so IDs and prices survive
+// the round trip
+func decodeJSON(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// mergePatch applies patch to target per RFC 7386
+func mergePatch(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = make(map[string]interface{}, len(p))
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+			continue
+		}
+		t[k] = mergePatch(t[k], v)
+	}
+	return t
+}
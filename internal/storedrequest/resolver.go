@@ -0,0 +1,358 @@
+package storedrequest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+// DefaultCacheTTL is how long templates are cached, which bounds how long an
+// edit made outside the admin API takes to reach every instance
+const DefaultCacheTTL = 5 * time.Minute
+
+// cacheKeyPrefix namespaces templates in the KV store; kind and ID follow
+const cacheKeyPrefix = "pbs:stored_request:"
+
+// cachedMissing marks IDs without a template, so unknown references don't
+// reach the database on every request
+const cachedMissing = "-"
+
+// ErrInvalidReference is wrapped by Resolve errors caused by the request:
+// unknown IDs, templates of another publisher and malformed references
+var ErrInvalidReference = errors.New("invalid stored request reference")
+
+// Store persists templates; implemented by storage.StoredRequestStore
+type Store interface {
+	GetMany(ctx context.Context, kind string, ids []string) (map[string]*storage.StoredRequest, error)
+	Put(ctx context.Context, sr *storage.StoredRequest) error
+	Delete(ctx context.Context, kind, id string) error
+}
+
+// Cache is the subset of the KV store used to cache templates
+type Cache interface {
+	Get(ctx context.Context, key string) (string, error)
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
+	Del(ctx context.Context, keys ...string) error
+}
+
+// Resolver expands stored request and stored impression references in bid
+// requests, reading templates through the KV cache
+type Resolver struct {
+	store Store
+	cache Cache
+	ttl   time.Duration
+}
+
+// NewResolver creates a resolver for store. cache may be nil, in which case
+// every reference is read from the store; ttl <= 0 uses DefaultCacheTTL.
+func NewResolver(store Store, cache Cache, ttl time.Duration) *Resolver {
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+	return &Resolver{store: store, cache: cache, ttl: ttl}
+}
+
+// storedRequestRef is the ext.prebid.storedrequest of a request or imp
+type storedRequestRef struct {
+	Prebid *struct {
+		StoredRequest *struct {
+			ID string `json:"id"`
+		} `json:"storedrequest"`
+	} `json:"prebid"`
+}
+
+// refID returns the stored request ID referenced by ext, or ""
+func refID(ext json.RawMessage) (string, error) {
+	if len(ext) == 0 {
+		return "", nil
+	}
+	var ref storedRequestRef
+	if err := json.Unmarshal(ext, &ref); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidReference, err)
+	}
+	if ref.Prebid == nil || ref.Prebid.StoredRequest == nil {
+		return "", nil
+	}
+	if ref.Prebid.StoredRequest.ID == "" {
+		return "", fmt.Errorf("%w: storedrequest.id is empty", ErrInvalidReference)
+	}
+	return ref.Prebid.StoredRequest.ID, nil
+}
+
+// Resolve merges the templates referenced by body into it and returns the
+// expanded request. The request template is merged first, then each imp's,
+// with the request's own fields overriding the templates' (JSON merge
+// patch). Macros in templates are expanded from the incoming request and
+// the query parameters WithQuery put in ctx.
+//
+// publisherID is the authenticated publisher, or "" to check templates
+// restricted to a publisher against the expanded request's publisher.
+// Bodies without references are returned unchanged.
+func (r *Resolver) Resolve(ctx context.Context, body []byte, publisherID string) ([]byte, error) {
+	if !bytes.Contains(body, []byte(`"storedrequest"`)) {
+		return body, nil
+	}
+	var req map[string]json.RawMessage
+	if err := json.Unmarshal(body, &req); err != nil {
+		// Leave malformed bodies for the request parser to report
+		return body, nil
+	}
+
+	macros := newMacroSource(ctx, body)
+	var used []*storage.StoredRequest
+
+	id, err := refID(req["ext"])
+	if err != nil {
+		return nil, err
+	}
+	if id != "" {
+		templates, err := r.fetch(ctx, storage.StoredKindRequest, []string{id})
+		if err != nil {
+			return nil, err
+		}
+		sr := templates[id]
+		merged, err := mergeTemplate(sr, body, macros)
+		if err != nil {
+			return nil, err
+		}
+		req = nil
+		if err := json.Unmarshal(merged, &req); err != nil {
+			return nil, fmt.Errorf("%w: request %s: %v", ErrInvalidReference, id, err)
+		}
+		used = append(used, sr)
+	}
+
+	if rawImps, ok := req["imp"]; ok {
+		var imps []json.RawMessage
+		if err := json.Unmarshal(rawImps, &imps); err != nil {
+			return nil, fmt.Errorf("%w: imp must be an array", ErrInvalidReference)
+		}
+		impIDs := make([]string, len(imps))
+		var ids []string
+		for i, imp := range imps {
+			var fields struct {
+				Ext json.RawMessage `json:"ext"`
+			}
+			if err := json.Unmarshal(imp, &fields); err != nil {
+				return nil, fmt.Errorf("%w: imp[%d] must be an object", ErrInvalidReference, i)
+			}
+			if impIDs[i], err = refID(fields.Ext); err != nil {
+				return nil, err
+			}
+			if impIDs[i] != "" {
+				ids = append(ids, impIDs[i])
+			}
+		}
+
+		if len(ids) > 0 {
+			templates, err := r.fetch(ctx, storage.StoredKindImp, ids)
+			if err != nil {
+				return nil, err
+			}
+			for i, id := range impIDs {
+				if id == "" {
+					continue
+				}
+				if imps[i], err = mergeTemplate(templates[id], imps[i], macros); err != nil {
+					return nil, err
+				}
+				used = append(used, templates[id])
+			}
+			if req["imp"], err = json.Marshal(imps); err != nil {
+				return nil, fmt.Errorf("failed to encode imps: %w", err)
+			}
+		}
+	}
+
+	expanded, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode expanded request: %w", err)
+	}
+	if err := checkPublisher(used, publisherID, expanded); err != nil {
+		return nil, err
+	}
+	return expanded, nil
+}
+
+// checkPublisher rejects templates restricted to another publisher than the
+// request's
+func checkPublisher(used []*storage.StoredRequest, publisherID string, expanded []byte) error {
+	for _, sr := range used {
+		if sr.PublisherID == "" {
+			continue
+		}
+		if publisherID == "" {
+			var req openrtb.BidRequest
+			if err := json.Unmarshal(expanded, &req); err == nil {
+				publisherID = bidRequestPublisherID(&req)
+			}
+		}
+		if sr.PublisherID != publisherID {
+			return fmt.Errorf("%w: stored %s %s belongs to another publisher", ErrInvalidReference, sr.Kind, sr.ID)
+		}
+	}
+	return nil
+}
+
+// bidRequestPublisherID returns the publisher of the site or app
+func bidRequestPublisherID(req *openrtb.BidRequest) string {
+	if req.Site != nil && req.Site.Publisher != nil {
+		return req.Site.Publisher.ID
+	}
+	if req.App != nil && req.App.Publisher != nil {
+		return req.App.Publisher.ID
+	}
+	return ""
+}
+
+// fetch returns the templates of kind for ids, failing on any unknown ID
+func (r *Resolver) fetch(ctx context.Context, kind string, ids []string) (map[string]*storage.StoredRequest, error) {
+	found := make(map[string]*storage.StoredRequest, len(ids))
+	var misses []string
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		sr, cached := r.cached(ctx, kind, id)
+		switch {
+		case !cached:
+			misses = append(misses, id)
+		case sr == nil:
+			return nil, fmt.Errorf("%w: stored %s %s not found", ErrInvalidReference, kind, id)
+		default:
+			found[id] = sr
+		}
+	}
+	if len(misses) == 0 {
+		return found, nil
+	}
+
+	loaded, err := r.store.GetMany(ctx, kind, misses)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range misses {
+		sr := loaded[id]
+		r.setCached(ctx, kind, id, sr)
+		if sr == nil {
+			return nil, fmt.Errorf("%w: stored %s %s not found", ErrInvalidReference, kind, id)
+		}
+		found[id] = sr
+	}
+	return found, nil
+}
+
+// cacheKey returns the KV key of a template
+func cacheKey(kind, id string) string {
+	return cacheKeyPrefix + kind + ":" + id
+}
+
+// cached returns a template from the cache. cached is false when the cache
+// has no entry; sr is nil for IDs cached as missing.
+func (r *Resolver) cached(ctx context.Context, kind, id string) (sr *storage.StoredRequest, cached bool) {
+	if r.cache == nil {
+		return nil, false
+	}
+	raw, err := r.cache.Get(ctx, cacheKey(kind, id))
+	if err != nil {
+		logger.Log.Debug().Err(err).Str("kind", kind).Str("id", id).Msg("Stored request cache read failed")
+		return nil, false
+	}
+	switch raw {
+	case "":
+		return nil, false
+	case cachedMissing:
+		return nil, true
+	}
+	sr = &storage.StoredRequest{}
+	if err := json.Unmarshal([]byte(raw), sr); err != nil {
+		return nil, false
+	}
+	return sr, true
+}
+
+// setCached caches a template, or marks the ID missing when sr is nil
+func (r *Resolver) setCached(ctx context.Context, kind, id string, sr *storage.StoredRequest) {
+	if r.cache == nil {
+		return
+	}
+	value := cachedMissing
+	if sr != nil {
+		data, err := json.Marshal(sr)
+		if err != nil {
+			return
+		}
+		value = string(data)
+	}
+	if err := r.cache.Set(ctx, cacheKey(kind, id), value, r.ttl); err != nil {
+		logger.Log.Debug().Err(err).Str("kind", kind).Str("id", id).Msg("Stored request cache write failed")
+	}
+}
+
+// invalidate drops a template from the cache
+func (r *Resolver) invalidate(ctx context.Context, kind, id string) {
+	if r.cache == nil {
+		return
+	}
+	if err := r.cache.Del(ctx, cacheKey(kind, id)); err != nil {
+		logger.Log.Warn().Err(err).Str("kind", kind).Str("id", id).Msg("Failed to invalidate cached stored request")
+	}
+}
+
+// InvalidateCache drops cached templates by "kind:id" and returns how many IDs
+// were valid. It is the CacheInvalidator for the "stored_request" scope, so
+// edits on one instance reach instances with their own KV store.
+func (r *Resolver) InvalidateCache(ids ...string) int {
+	n := 0
+	for _, ref := range ids {
+		kind, id, ok := strings.Cut(ref, ":")
+		if !ok || id == "" {
+			continue
+		}
+		r.invalidate(context.Background(), kind, id)
+		n++
+	}
+	return n
+}
+
+// Get returns a template, or an error wrapping storage.ErrNotFound
+func (r *Resolver) Get(ctx context.Context, kind, id string) (*storage.StoredRequest, error) {
+	found, err := r.store.GetMany(ctx, kind, []string{id})
+	if err != nil {
+		return nil, err
+	}
+	sr, ok := found[id]
+	if !ok {
+		return nil, fmt.Errorf("stored %s %w: %s", kind, storage.ErrNotFound, id)
+	}
+	return sr, nil
+}
+
+// Put saves a template and drops the cached copy on this instance's KV store,
+// which every instance shares unless it runs the memory backend
+func (r *Resolver) Put(ctx context.Context, sr *storage.StoredRequest) error {
+	if err := r.store.Put(ctx, sr); err != nil {
+		return err
+	}
+	r.invalidate(ctx, sr.Kind, sr.ID)
+	return nil
+}
+
+// Delete removes a template and its cached copy
+func (r *Resolver) Delete(ctx context.Context, kind, id string) error {
+	if err := r.store.Delete(ctx, kind, id); err != nil {
+		return err
+	}
+	r.invalidate(ctx, kind, id)
+	return nil
+}
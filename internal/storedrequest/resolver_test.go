@@ -0,0 +1,229 @@
+package storedrequest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+	"github.com/thenexusengine/tne_springwire/pkg/kv"
+)
+
+// memStore is an in-memory Store that counts lookups
+type memStore struct {
+	templates map[string]*storage.StoredRequest
+	lookups   int
+}
+
+func newMemStore(templates ...*storage.StoredRequest) *memStore {
+	s := &memStore{templates: make(map[string]*storage.StoredRequest)}
+	for _, sr := range templates {
+		s.templates[sr.Kind+"/"+sr.ID] = sr
+	}
+	return s
+}
+
+func (s *memStore) GetMany(ctx context.Context, kind string, ids []string) (map[string]*storage.StoredRequest, error) {
+	s.lookups++
+	found := make(map[string]*storage.StoredRequest)
+	for _, id := range ids {
+		if sr, ok := s.templates[kind+"/"+id]; ok {
+			found[id] = sr
+		}
+	}
+	return found, nil
+}
+
+func (s *memStore) Put(ctx context.Context, sr *storage.StoredRequest) error {
+	s.templates[sr.Kind+"/"+sr.ID] = sr
+	return nil
+}
+
+func (s *memStore) Delete(ctx context.Context, kind, id string) error {
+	delete(s.templates, kind+"/"+id)
+	return nil
+}
+
+// decode parses JSON for comparison
+func decode(t *testing.T, data []byte) interface{} {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		t.Fatalf("invalid JSON %s: %v", data, err)
+	}
+	return v
+}
+
+func TestResolve_MergesRequestAndImpTemplates(t *testing.T) {
+	store := newMemStore(
+		&storage.StoredRequest{Kind: storage.StoredKindRequest, ID: "homepage", Data: json.RawMessage(
+			`{"tmax":800,"cur":["USD"],"site":{"domain":"example.com","page":"{{PAGE_URL}}"},"imp":[{"id":"1","ext":{"prebid":{"storedrequest":{"id":"mrec"}}}}]}`)},
+		&storage.StoredRequest{Kind: storage.StoredKindImp, ID: "mrec", Data: json.RawMessage(
+			`{"banner":{"w":300,"h":250},"bidfloor":0.5}`)},
+	)
+	r := NewResolver(store, nil, 0)
+
+	body := []byte(`{"id":"req-1","tmax":500,"cur":null,"site":{"page":"https://example.com/a"},"ext":{"prebid":{"storedrequest":{"id":"homepage"}}}}`)
+	out, err := r.Resolve(context.Background(), body, "")
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+
+	want := decode(t, []byte(`{
+		"id":"req-1","tmax":500,
+		"site":{"domain":"example.com","page":"https://example.com/a"},
+		"imp":[{"id":"1","banner":{"w":300,"h":250},"bidfloor":0.5,"ext":{"prebid":{"storedrequest":{"id":"mrec"}}}}],
+		"ext":{"prebid":{"storedrequest":{"id":"homepage"}}}
+	}`))
+	if got := decode(t, out); !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected merge:\n got %s", out)
+	}
+}
+
+func TestResolve_QueryMacros(t *testing.T) {
+	store := newMemStore(&storage.StoredRequest{Kind: storage.StoredKindRequest, ID: "ctv", Data: json.RawMessage(
+		`{"app":{"bundle":"{{BUNDLE}}","keywords":"{{CUSTOM_KV.genre}}"}}`)})
+	r := NewResolver(store, nil, 0)
+
+	// The bid request's own values win over query parameters
+	body := []byte(`{"id":"req-1","app":{"bundle":"com.example.tv"},"ext":{"prebid":{"storedrequest":{"id":"ctv"}}}}`)
+	ctx := WithQuery(context.Background(), url.Values{"bundle": {"com.other"}, "kv_genre": {"drama"}})
+	out, err := r.Resolve(ctx, body, "")
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	var req struct {
+		App map[string]string `json:"app"`
+	}
+	if err := json.Unmarshal(out, &req); err != nil {
+		t.Fatal(err)
+	}
+	if req.App["bundle"] != "com.example.tv" || req.App["keywords"] != "drama" {
+		t.Errorf("expected the bundle from the request and the genre from the query, got %s", out)
+	}
+}
+
+func TestResolve_ImpOverridesAndPassThrough(t *testing.T) {
+	store := newMemStore(&storage.StoredRequest{Kind: storage.StoredKindImp, ID: "video", Data: json.RawMessage(
+		`{"video":{"mimes":["video/mp4"],"w":640,"h":480}}`)})
+	r := NewResolver(store, nil, 0)
+
+	plain := []byte(`{"id":"req-1","imp":[{"id":"1"}]}`)
+	if out, err := r.Resolve(context.Background(), plain, ""); err != nil || string(out) != string(plain) {
+		t.Errorf("expected bodies without references unchanged, got %s, %v", out, err)
+	}
+
+	body := []byte(`{"id":"req-1","imp":[{"id":"1","video":{"w":1920,"h":1080},"ext":{"prebid":{"storedrequest":{"id":"video"}}}},{"id":"2"}]}`)
+	out, err := r.Resolve(context.Background(), body, "")
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	var req struct {
+		Imp []map[string]json.RawMessage `json:"imp"`
+	}
+	if err := json.Unmarshal(out, &req); err != nil {
+		t.Fatal(err)
+	}
+	if len(req.Imp) != 2 || string(req.Imp[0]["video"]) != `{"h":1080,"mimes":["video/mp4"],"w":1920}` {
+		t.Errorf("expected incoming video fields to override the template, got %s", out)
+	}
+	if _, ok := req.Imp[1]["video"]; ok {
+		t.Errorf("expected imps without a reference untouched, got %s", out)
+	}
+}
+
+func TestResolve_Errors(t *testing.T) {
+	store := newMemStore(&storage.StoredRequest{Kind: storage.StoredKindRequest, ID: "private", PublisherID: "pub-1", Data: json.RawMessage(`{"tmax":800}`)})
+	r := NewResolver(store, nil, 0)
+
+	tests := []struct {
+		name        string
+		body        string
+		publisherID string
+	}{
+		{"unknown request", `{"ext":{"prebid":{"storedrequest":{"id":"absent"}}}}`, ""},
+		{"unknown imp", `{"imp":[{"id":"1","ext":{"prebid":{"storedrequest":{"id":"absent"}}}}]}`, ""},
+		{"empty id", `{"ext":{"prebid":{"storedrequest":{}}}}`, ""},
+		{"other authenticated publisher", `{"ext":{"prebid":{"storedrequest":{"id":"private"}}}}`, "pub-2"},
+		{"other request publisher", `{"site":{"publisher":{"id":"pub-2"}},"ext":{"prebid":{"storedrequest":{"id":"private"}}}}`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := r.Resolve(context.Background(), []byte(tt.body), tt.publisherID); !errors.Is(err, ErrInvalidReference) {
+				t.Errorf("expected ErrInvalidReference, got %v", err)
+			}
+		})
+	}
+
+	ok := []byte(`{"site":{"publisher":{"id":"pub-1"}},"ext":{"prebid":{"storedrequest":{"id":"private"}}}}`)
+	if _, err := r.Resolve(context.Background(), ok, ""); err != nil {
+		t.Errorf("expected the owning publisher to be allowed, got %v", err)
+	}
+}
+
+func TestResolve_CachesTemplates(t *testing.T) {
+	store := newMemStore(&storage.StoredRequest{Kind: storage.StoredKindRequest, ID: "homepage", Data: json.RawMessage(`{"tmax":800}`)})
+	r := NewResolver(store, kv.NewMemory(), 0)
+	ctx := context.Background()
+
+	body := []byte(`{"ext":{"prebid":{"storedrequest":{"id":"homepage"}}}}`)
+	missing := []byte(`{"ext":{"prebid":{"storedrequest":{"id":"absent"}}}}`)
+	for i := 0; i < 3; i++ {
+		if _, err := r.Resolve(ctx, body, ""); err != nil {
+			t.Fatalf("Resolve failed: %v", err)
+		}
+		if _, err := r.Resolve(ctx, missing, ""); !errors.Is(err, ErrInvalidReference) {
+			t.Fatalf("expected ErrInvalidReference, got %v", err)
+		}
+	}
+	if store.lookups != 2 {
+		t.Errorf("expected one store lookup per ID, got %d", store.lookups)
+	}
+
+	if err := r.Put(ctx, &storage.StoredRequest{Kind: storage.StoredKindRequest, ID: "homepage", Data: json.RawMessage(`{"tmax":300}`)}); err != nil {
+		t.Fatal(err)
+	}
+	out, err := r.Resolve(ctx, body, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := decode(t, out).(map[string]interface{})["tmax"]; got != float64(300) {
+		t.Errorf("expected Put to invalidate the cached template, got tmax %v", got)
+	}
+}
+
+func TestResolver_InvalidateCache(t *testing.T) {
+	store := newMemStore(&storage.StoredRequest{Kind: storage.StoredKindRequest, ID: "homepage", Data: json.RawMessage(`{"tmax":800}`)})
+	r := NewResolver(store, kv.NewMemory(), 0)
+	ctx := context.Background()
+
+	body := []byte(`{"ext":{"prebid":{"storedrequest":{"id":"homepage"}}}}`)
+	if _, err := r.Resolve(ctx, body, ""); err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+
+	// Another instance edited the template; only the broadcast reaches this one
+	store.templates[storage.StoredKindRequest+"/homepage"].Data = json.RawMessage(`{"tmax":300}`)
+	if n := r.InvalidateCache("request:homepage", "malformed", "imp:"); n != 1 {
+		t.Errorf("expected one valid ID, got %d", n)
+	}
+	out, err := r.Resolve(ctx, body, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := decode(t, out).(map[string]interface{})["tmax"]; got != float64(300) {
+		t.Errorf("expected the invalidated template to be reloaded, got tmax %v", got)
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	target := decode(t, []byte(`{"a":{"b":1,"c":[1,2]},"d":"x"}`))
+	patch := decode(t, []byte(`{"a":{"b":null,"c":[3]},"e":true}`))
+	want := decode(t, []byte(`{"a":{"c":[3]},"d":"x","e":true}`))
+	if got := mergePatch(target, patch); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,75 @@
+package testfixtures
+
+import "github.com/thenexusengine/tne_springwire/internal/openrtb"
+
+// ConsentBuilder describes the privacy signals on a request, using the
+// OpenRTB 2.6 regs and user fields the privacy middleware reads
+type ConsentBuilder struct {
+	gdpr      *int
+	tcf       string
+	usPrivacy string
+	gpp       string
+	gppSIDs   []int
+	coppa     bool
+}
+
+// GDPR is a request in GDPR scope (regs.gdpr=1) carrying a TCF consent string;
+// an empty string means consent is missing
+func GDPR(tcf string) *ConsentBuilder {
+	applies := 1
+	return &ConsentBuilder{gdpr: &applies, tcf: tcf}
+}
+
+// NoGDPR is a request explicitly outside GDPR scope (regs.gdpr=0)
+func NoGDPR() *ConsentBuilder {
+	applies := 0
+	return &ConsentBuilder{gdpr: &applies}
+}
+
+// NoConsentSignals is a request with no privacy signals at all
+func NoConsentSignals() *ConsentBuilder {
+	return &ConsentBuilder{}
+}
+
+// USPrivacy sets the CCPA string, e.g. "1YNN" (notice given, no opt-out) or
+// "1YYN" (opted out)
+func (c *ConsentBuilder) USPrivacy(s string) *ConsentBuilder {
+	c.usPrivacy = s
+	return c
+}
+
+// GPP sets the GPP string and the section IDs that apply
+func (c *ConsentBuilder) GPP(s string, sids ...int) *ConsentBuilder {
+	c.gpp = s
+	c.gppSIDs = sids
+	return c
+}
+
+// COPPA marks the request as subject to COPPA
+func (c *ConsentBuilder) COPPA() *ConsentBuilder {
+	c.coppa = true
+	return c
+}
+
+// apply writes the signals onto req, replacing any regs it had
+func (c *ConsentBuilder) apply(req *openrtb.BidRequest) {
+	regs := &openrtb.Regs{USPrivacy: c.usPrivacy, GPP: c.gpp}
+	if c.gdpr != nil {
+		gdpr := *c.gdpr
+		regs.GDPR = &gdpr
+	}
+	if len(c.gppSIDs) > 0 {
+		regs.GPPSID = append([]int(nil), c.gppSIDs...)
+	}
+	if c.coppa {
+		regs.COPPA = 1
+	}
+	req.Regs = regs
+
+	if c.tcf != "" {
+		if req.User == nil {
+			req.User = &openrtb.User{}
+		}
+		req.User.Consent = c.tcf
+	}
+}
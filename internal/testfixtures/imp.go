@@ -0,0 +1,148 @@
+package testfixtures
+
+import (
+	"fmt"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+// ImpBuilder builds an openrtb.Imp
+type ImpBuilder struct {
+	imp openrtb.Imp
+}
+
+// Banner starts a banner impression of the given size
+func Banner(id string, w, h int) *ImpBuilder {
+	return &ImpBuilder{imp: openrtb.Imp{
+		ID:     id,
+		Banner: &openrtb.Banner{W: w, H: h, Format: []openrtb.Format{{W: w, H: h}}},
+	}}
+}
+
+// Video starts a 640x480 instream video impression accepting 5-30s MP4
+// creatives over VAST 2.0-4.0 wrapper and inline protocols
+func Video(id string) *ImpBuilder {
+	return &ImpBuilder{imp: openrtb.Imp{
+		ID: id,
+		Video: &openrtb.Video{
+			Mimes:       []string{"video/mp4"},
+			MinDuration: 5,
+			MaxDuration: 30,
+			Protocols:   []int{2, 3, 5, 6, 7, 8},
+			W:           640,
+			H:           480,
+			Placement:   1,
+			Linearity:   1,
+		},
+	}}
+}
+
+// Native starts a native impression with an empty request payload
+func Native(id string) *ImpBuilder {
+	return &ImpBuilder{imp: openrtb.Imp{
+		ID:     id,
+		Native: &openrtb.Native{Request: "{}", Ver: "1.2"},
+	}}
+}
+
+// Pod returns slots video impressions "<prefix>-1" to "<prefix>-n" with
+// sequences 1 to n, forming an ad pod
+func Pod(prefix string, slots int) []*ImpBuilder {
+	imps := make([]*ImpBuilder, 0, slots)
+	for i := 1; i <= slots; i++ {
+		imps = append(imps, Video(fmt.Sprintf("%s-%d", prefix, i)).Sequence(i))
+	}
+	return imps
+}
+
+// Floor sets the bid floor in USD
+func (b *ImpBuilder) Floor(price float64) *ImpBuilder {
+	return b.FloorCur(price, "USD")
+}
+
+// FloorCur sets the bid floor in a currency
+func (b *ImpBuilder) FloorCur(price float64, currency string) *ImpBuilder {
+	b.imp.BidFloor = price
+	b.imp.BidFloorCur = currency
+	return b
+}
+
+// TagID sets the publisher's ad unit identifier
+func (b *ImpBuilder) TagID(tagID string) *ImpBuilder {
+	b.imp.TagID = tagID
+	return b
+}
+
+// Exp sets the billing window in seconds
+func (b *ImpBuilder) Exp(seconds int) *ImpBuilder {
+	b.imp.Exp = seconds
+	return b
+}
+
+// Duration sets a video impression's accepted creative duration
+func (b *ImpBuilder) Duration(minSeconds, maxSeconds int) *ImpBuilder {
+	if b.imp.Video != nil {
+		b.imp.Video.MinDuration = minSeconds
+		b.imp.Video.MaxDuration = maxSeconds
+	}
+	return b
+}
+
+// Sequence sets a video impression's position in its pod
+func (b *ImpBuilder) Sequence(seq int) *ImpBuilder {
+	if b.imp.Video != nil {
+		b.imp.Video.Sequence = seq
+	}
+	return b
+}
+
+// DynamicPod makes a video impression describe a whole pod of up to maxAds
+// ads lasting podSeconds in total, to be fanned out into slots
+func (b *ImpBuilder) DynamicPod(podSeconds, maxAds int) *ImpBuilder {
+	if b.imp.Video != nil {
+		b.imp.Video.PodDur = podSeconds
+		b.imp.Video.MaxSeq = maxAds
+	}
+	return b
+}
+
+// BlockedAttrs sets battr on the impression's banner or video object
+func (b *ImpBuilder) BlockedAttrs(attrs ...int) *ImpBuilder {
+	if b.imp.Banner != nil {
+		b.imp.Banner.BAttr = attrs
+	}
+	if b.imp.Video != nil {
+		b.imp.Video.BAttr = attrs
+	}
+	return b
+}
+
+// Ext sets the impression extension from any JSON-marshalable value, e.g.
+// map[string]interface{}{"prebid": map[string]interface{}{"bidder": ...}}
+func (b *ImpBuilder) Ext(ext interface{}) *ImpBuilder {
+	b.imp.Ext = mustJSON(ext)
+	return b
+}
+
+// Build returns the impression
+func (b *ImpBuilder) Build() openrtb.Imp {
+	imp := b.imp
+	if b.imp.Banner != nil {
+		banner := *b.imp.Banner
+		banner.Format = append([]openrtb.Format(nil), b.imp.Banner.Format...)
+		banner.BAttr = append([]int(nil), b.imp.Banner.BAttr...)
+		imp.Banner = &banner
+	}
+	if b.imp.Video != nil {
+		video := *b.imp.Video
+		video.Mimes = append([]string(nil), b.imp.Video.Mimes...)
+		video.Protocols = append([]int(nil), b.imp.Video.Protocols...)
+		video.BAttr = append([]int(nil), b.imp.Video.BAttr...)
+		imp.Video = &video
+	}
+	if b.imp.Native != nil {
+		native := *b.imp.Native
+		imp.Native = &native
+	}
+	return imp
+}
@@ -0,0 +1,259 @@
+package testfixtures
+
+import (
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+)
+
+// fixtureTime is the created/updated time of every row, so rows compare equal
+// across builds
+var fixtureTime = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// PublisherBuilder builds a storage.Publisher row
+type PublisherBuilder struct {
+	pub storage.Publisher
+}
+
+// Publisher starts an active publisher on example.com with no bidders and no
+// bid multiplier
+func Publisher(publisherID string) *PublisherBuilder {
+	return &PublisherBuilder{pub: storage.Publisher{
+		ID:             "1",
+		PublisherID:    publisherID,
+		Name:           "Publisher " + publisherID,
+		AllowedDomains: "example.com",
+		BidderParams:   map[string]interface{}{},
+		BidMultiplier:  1.0,
+		Status:         "active",
+		Version:        1,
+		CreatedAt:      fixtureTime,
+		UpdatedAt:      fixtureTime,
+	}}
+}
+
+// Name sets the display name
+func (b *PublisherBuilder) Name(name string) *PublisherBuilder {
+	b.pub.Name = name
+	return b
+}
+
+// Domains sets the comma-separated allowed domains
+func (b *PublisherBuilder) Domains(domains string) *PublisherBuilder {
+	b.pub.AllowedDomains = domains
+	return b
+}
+
+// Bidder enables a bidder for the publisher with its params
+func (b *PublisherBuilder) Bidder(code string, params map[string]interface{}) *PublisherBuilder {
+	if params == nil {
+		params = map[string]interface{}{}
+	}
+	b.pub.BidderParams[code] = params
+	return b
+}
+
+// Multiplier sets the revenue share bid multiplier (1.05 = ~5% platform cut)
+func (b *PublisherBuilder) Multiplier(m float64) *PublisherBuilder {
+	b.pub.BidMultiplier = m
+	return b
+}
+
+// MaxBidCPM sets the publisher's bid price cap
+func (b *PublisherBuilder) MaxBidCPM(cpm float64) *PublisherBuilder {
+	b.pub.MaxBidCPM = cpm
+	return b
+}
+
+// BlockedAttrs sets the default battr merged into media objects
+func (b *PublisherBuilder) BlockedAttrs(attrs ...int) *PublisherBuilder {
+	b.pub.BlockedAttributes = attrs
+	return b
+}
+
+// PlayerConfig sets the publisher's player SDK overrides
+func (b *PublisherBuilder) PlayerConfig(cfg storage.PlayerConfig) *PublisherBuilder {
+	b.pub.PlayerConfig = &cfg
+	return b
+}
+
+// CreativeSanitization sets the banner markup sanitization level
+func (b *PublisherBuilder) CreativeSanitization(level string) *PublisherBuilder {
+	b.pub.CreativeSanitization = level
+	return b
+}
+
+// LanguageFilter sets the ad language filter mode
+func (b *PublisherBuilder) LanguageFilter(mode string) *PublisherBuilder {
+	b.pub.LanguageFilter = mode
+	return b
+}
+
+// RequiredLanguage sets the language every bid must be in
+func (b *PublisherBuilder) RequiredLanguage(lang string) *PublisherBuilder {
+	b.pub.RequiredLanguage = lang
+	return b
+}
+
+// CreativeApproval sets the unreviewed creative policy
+func (b *PublisherBuilder) CreativeApproval(policy string) *PublisherBuilder {
+	b.pub.CreativeApproval = policy
+	return b
+}
+
+// AllowedCountries sets the countries auctions are limited to
+func (b *PublisherBuilder) AllowedCountries(countries string) *PublisherBuilder {
+	b.pub.AllowedCountries = countries
+	return b
+}
+
+// BlockedCountries sets the countries auctions are rejected for
+func (b *PublisherBuilder) BlockedCountries(countries string) *PublisherBuilder {
+	b.pub.BlockedCountries = countries
+	return b
+}
+
+// TimeoutMs sets the auction timeout for requests without tmax
+func (b *PublisherBuilder) TimeoutMs(ms int) *PublisherBuilder {
+	b.pub.TimeoutMs = ms
+	return b
+}
+
+// MaxBidders sets the fan-out cap
+func (b *PublisherBuilder) MaxBidders(n int) *PublisherBuilder {
+	b.pub.MaxBidders = n
+	return b
+}
+
+// AllowedBidders sets the bidders auctions are limited to
+func (b *PublisherBuilder) AllowedBidders(bidders string) *PublisherBuilder {
+	b.pub.AllowedBidders = bidders
+	return b
+}
+
+// BlockedBidders sets the bidders never called for the publisher
+func (b *PublisherBuilder) BlockedBidders(bidders string) *PublisherBuilder {
+	b.pub.BlockedBidders = bidders
+	return b
+}
+
+// PodMinCPM sets the minimum total CPM of an ad pod
+func (b *PublisherBuilder) PodMinCPM(cpm float64) *PublisherBuilder {
+	b.pub.PodMinCPM = cpm
+	return b
+}
+
+// PodMaxBidderShare sets the share of an ad pod one bidder may fill
+func (b *PublisherBuilder) PodMaxBidderShare(share float64) *PublisherBuilder {
+	b.pub.PodMaxBidderShare = share
+	return b
+}
+
+// Status sets the status ("active", "paused" or "archived")
+func (b *PublisherBuilder) Status(status string) *PublisherBuilder {
+	b.pub.Status = status
+	return b
+}
+
+// Build returns the publisher
+func (b *PublisherBuilder) Build() *storage.Publisher {
+	pub := b.pub
+	pub.BidderParams = make(map[string]interface{}, len(b.pub.BidderParams))
+	for code, params := range b.pub.BidderParams {
+		pub.BidderParams[code] = params
+	}
+	pub.BlockedAttributes = append([]int(nil), b.pub.BlockedAttributes...)
+	if b.pub.PlayerConfig != nil {
+		cfg := *b.pub.PlayerConfig
+		pub.PlayerConfig = &cfg
+	}
+	return &pub
+}
+
+// BidderBuilder builds a storage.Bidder row
+type BidderBuilder struct {
+	bidder storage.Bidder
+}
+
+// Bidder starts an enabled, active banner and video bidder with a 500ms
+// timeout at https://<code>.example.com/openrtb2
+func Bidder(code string) *BidderBuilder {
+	return &BidderBuilder{bidder: storage.Bidder{
+		ID:             "1",
+		BidderCode:     code,
+		BidderName:     code,
+		EndpointURL:    "https://" + code + ".example.com/openrtb2",
+		TimeoutMs:      500,
+		Enabled:        true,
+		Status:         "active",
+		SupportsBanner: true,
+		SupportsVideo:  true,
+		HTTPHeaders:    map[string]interface{}{},
+		Version:        1,
+		CreatedAt:      fixtureTime,
+		UpdatedAt:      fixtureTime,
+	}}
+}
+
+// Endpoint sets the bidder's OpenRTB endpoint
+func (b *BidderBuilder) Endpoint(url string) *BidderBuilder {
+	b.bidder.EndpointURL = url
+	return b
+}
+
+// Timeout sets the bidder timeout in milliseconds
+func (b *BidderBuilder) Timeout(ms int) *BidderBuilder {
+	b.bidder.TimeoutMs = ms
+	return b
+}
+
+// Disabled turns the bidder off
+func (b *BidderBuilder) Disabled() *BidderBuilder {
+	b.bidder.Enabled = false
+	return b
+}
+
+// Media sets the supported media types
+func (b *BidderBuilder) Media(banner, video, native, audio bool) *BidderBuilder {
+	b.bidder.SupportsBanner = banner
+	b.bidder.SupportsVideo = video
+	b.bidder.SupportsNative = native
+	b.bidder.SupportsAudio = audio
+	return b
+}
+
+// GVLVendorID sets the bidder's IAB Global Vendor List ID
+func (b *BidderBuilder) GVLVendorID(id int) *BidderBuilder {
+	b.bidder.GVLVendorID = &id
+	return b
+}
+
+// Header adds an outbound HTTP header
+func (b *BidderBuilder) Header(name, value string) *BidderBuilder {
+	b.bidder.HTTPHeaders[name] = value
+	return b
+}
+
+// Build returns the bidder
+func (b *BidderBuilder) Build() *storage.Bidder {
+	bidder := b.bidder
+	bidder.HTTPHeaders = make(map[string]interface{}, len(b.bidder.HTTPHeaders))
+	for name, value := range b.bidder.HTTPHeaders {
+		bidder.HTTPHeaders[name] = value
+	}
+	if b.bidder.GVLVendorID != nil {
+		id := *b.bidder.GVLVendorID
+		bidder.GVLVendorID = &id
+	}
+	return &bidder
+}
+
+// ForPublisher returns the bidder as enabled for a publisher with its params,
+// as storage.BidderStore.GetForPublisher does
+func (b *BidderBuilder) ForPublisher(publisherID string, params map[string]interface{}) *storage.PublisherBidder {
+	return &storage.PublisherBidder{
+		Bidder:       *b.Build(),
+		PublisherID:  publisherID,
+		BidderConfig: params,
+	}
+}
@@ -0,0 +1,92 @@
+package testfixtures
+
+import "testing"
+
+func TestRequestBuilder_Defaults(t *testing.T) {
+	req := Request("r1").Imp(Video("v1"), Banner("b1", 300, 250).Floor(1.5)).Build()
+
+	if req.ID != "r1" || req.Site == nil || req.Site.Publisher.ID != "pub-1" {
+		t.Fatalf("unexpected request defaults: %+v", req)
+	}
+	if len(req.Imp) != 2 {
+		t.Fatalf("expected 2 imps, got %d", len(req.Imp))
+	}
+	if req.Imp[0].Video == nil || req.Imp[0].Video.MaxDuration != 30 {
+		t.Errorf("expected default video imp, got %+v", req.Imp[0].Video)
+	}
+	if req.Imp[1].BidFloor != 1.5 || req.Imp[1].BidFloorCur != "USD" {
+		t.Errorf("expected USD floor 1.5, got %v %s", req.Imp[1].BidFloor, req.Imp[1].BidFloorCur)
+	}
+	if req.Regs != nil {
+		t.Errorf("expected no regs without consent, got %+v", req.Regs)
+	}
+}
+
+func TestRequestBuilder_BuildReturnsFreshValues(t *testing.T) {
+	b := Request("r1").Imp(Video("v1").BlockedAttrs(1, 2)).Cur("USD")
+
+	first := b.Build()
+	first.Site.Publisher.ID = "mutated"
+	first.Imp[0].Video.BAttr[0] = 99
+	first.Cur[0] = "EUR"
+
+	second := b.Build()
+	if second.Site.Publisher.ID != "pub-1" {
+		t.Errorf("publisher shared between builds: %s", second.Site.Publisher.ID)
+	}
+	if second.Imp[0].Video.BAttr[0] != 1 {
+		t.Errorf("battr shared between builds: %v", second.Imp[0].Video.BAttr)
+	}
+	if second.Cur[0] != "USD" {
+		t.Errorf("cur shared between builds: %v", second.Cur)
+	}
+}
+
+func TestPod(t *testing.T) {
+	imps := Pod("slot", 3)
+	if len(imps) != 3 {
+		t.Fatalf("expected 3 slots, got %d", len(imps))
+	}
+	for i, b := range imps {
+		imp := b.Build()
+		if want := i + 1; imp.Video.Sequence != want {
+			t.Errorf("slot %d: expected sequence %d, got %d", i, want, imp.Video.Sequence)
+		}
+	}
+	if id := imps[2].Build().ID; id != "slot-3" {
+		t.Errorf("expected id slot-3, got %s", id)
+	}
+}
+
+func TestConsent(t *testing.T) {
+	req := Request("r1").Consent(GDPR("tcf-string").USPrivacy("1YYN").COPPA()).Build()
+
+	if req.Regs.GDPR == nil || *req.Regs.GDPR != 1 {
+		t.Errorf("expected gdpr=1, got %v", req.Regs.GDPR)
+	}
+	if req.User == nil || req.User.Consent != "tcf-string" {
+		t.Errorf("expected consent on user, got %+v", req.User)
+	}
+	if req.Regs.USPrivacy != "1YYN" || req.Regs.COPPA != 1 {
+		t.Errorf("unexpected regs: %+v", req.Regs)
+	}
+
+	req = Request("r2").Consent(NoGDPR()).Build()
+	if req.Regs.GDPR == nil || *req.Regs.GDPR != 0 || req.User != nil {
+		t.Errorf("expected gdpr=0 without user, got regs %+v user %+v", req.Regs, req.User)
+	}
+}
+
+func TestPublisherAndBidderRows(t *testing.T) {
+	pb := Publisher("pub-1").Bidder("appnexus", map[string]interface{}{"placementId": 1})
+	pub := pb.Build()
+	pub.BidderParams["rubicon"] = nil
+	if _, ok := pb.Build().BidderParams["rubicon"]; ok {
+		t.Error("bidder params shared between builds")
+	}
+
+	row := Bidder("appnexus").Timeout(200).Disabled().ForPublisher("pub-1", nil)
+	if row.PublisherID != "pub-1" || row.TimeoutMs != 200 || row.Enabled {
+		t.Errorf("unexpected publisher bidder row: %+v", row)
+	}
+}
@@ -104,6 +104,17 @@ func (s *Syncer) BidderCode() string {
 	return s.config.BidderCode
 }
 
+// SupportsType returns true if the bidder has a sync URL of the given type
+func (s *Syncer) SupportsType(syncType SyncType) bool {
+	switch syncType {
+	case SyncTypeIframe:
+		return s.config.IframeSyncURL != ""
+	case SyncTypeRedirect:
+		return s.config.RedirectSyncURL != ""
+	}
+	return false
+}
+
 // IsEnabled returns true if syncing is enabled
 func (s *Syncer) IsEnabled() bool {
 	return s.config.Enabled
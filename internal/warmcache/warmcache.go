@@ -0,0 +1,229 @@
+// Package warmcache persists hot in-memory caches across restarts so freshly
+// deployed instances don't start with cold caches. The server snapshots the
+// publisher cache, bidder GDPR scopes and live currency rates; the bidder
+// list itself is reloaded from PostgreSQL at startup.
+package warmcache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+// snapshotVersion is bumped when the snapshot envelope format changes
+const snapshotVersion = 1
+
+// Provider is a cache that can be snapshotted and restored
+type Provider interface {
+	// SnapshotName identifies the cache within a snapshot
+	SnapshotName() string
+	// Snapshot serializes the cache contents
+	Snapshot() (json.RawMessage, error)
+	// Restore loads previously snapshotted contents
+	Restore(data json.RawMessage) error
+}
+
+// Backend stores and loads a serialized snapshot
+type Backend interface {
+	Save(ctx context.Context, data []byte) error
+	Load(ctx context.Context) ([]byte, error)
+}
+
+// Config holds warm cache persistence configuration
+type Config struct {
+	Enabled  bool
+	FilePath string        // Local snapshot file (used when Redis is not selected)
+	UseRedis bool          // Store the snapshot in Redis instead of a file
+	RedisKey string        // Redis key for the snapshot
+	MaxAge   time.Duration // Snapshots older than this are ignored on load
+}
+
+// DefaultConfig returns warm cache configuration from environment variables
+// Environment variables:
+//   - PBS_WARM_CACHE_ENABLED: "true" or "false" (default: false)
+//   - PBS_WARM_CACHE_FILE: snapshot path (default: /tmp/pbs-warm-cache.json)
+//   - PBS_WARM_CACHE_REDIS: "true" to store the snapshot in Redis (default: false)
+//   - PBS_WARM_CACHE_MAX_AGE_SECONDS: max snapshot age to load (default: 600)
+func DefaultConfig() *Config {
+	maxAge := 10 * time.Minute
+	if v, err := strconv.Atoi(os.Getenv("PBS_WARM_CACHE_MAX_AGE_SECONDS")); err == nil && v > 0 {
+		maxAge = time.Duration(v) * time.Second
+	}
+
+	filePath := os.Getenv("PBS_WARM_CACHE_FILE")
+	if filePath == "" {
+		filePath = filepath.Join(os.TempDir(), "pbs-warm-cache.json")
+	}
+
+	return &Config{
+		Enabled:  os.Getenv("PBS_WARM_CACHE_ENABLED") == "true",
+		FilePath: filePath,
+		UseRedis: os.Getenv("PBS_WARM_CACHE_REDIS") == "true",
+		RedisKey: "tne_catalyst:warm_cache",
+		MaxAge:   maxAge,
+	}
+}
+
+// snapshot is the persisted envelope
+type snapshot struct {
+	Version int                        `json:"version"`
+	SavedAt time.Time                  `json:"saved_at"`
+	Caches  map[string]json.RawMessage `json:"caches"`
+}
+
+// Manager snapshots registered providers to a backend
+type Manager struct {
+	backend   Backend
+	maxAge    time.Duration
+	mu        sync.Mutex
+	providers []Provider
+}
+
+// NewManager creates a warm cache manager
+func NewManager(backend Backend, maxAge time.Duration) *Manager {
+	return &Manager{
+		backend: backend,
+		maxAge:  maxAge,
+	}
+}
+
+// Register adds a cache to be persisted
+func (m *Manager) Register(p Provider) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.providers = append(m.providers, p)
+}
+
+// Save snapshots every registered cache. A cache that fails to snapshot is
+// skipped so one bad cache doesn't prevent the others from being persisted.
+func (m *Manager) Save(ctx context.Context) error {
+	m.mu.Lock()
+	providers := append([]Provider(nil), m.providers...)
+	m.mu.Unlock()
+
+	snap := snapshot{
+		Version: snapshotVersion,
+		SavedAt: time.Now(),
+		Caches:  make(map[string]json.RawMessage, len(providers)),
+	}
+	for _, p := range providers {
+		data, err := p.Snapshot()
+		if err != nil {
+			logger.Log.Warn().Err(err).Str("cache", p.SnapshotName()).Msg("Failed to snapshot cache")
+			continue
+		}
+		snap.Caches[p.SnapshotName()] = data
+	}
+
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return fmt.Errorf("failed to marshal warm cache snapshot: %w", err)
+	}
+	if err := m.backend.Save(ctx, data); err != nil {
+		return fmt.Errorf("failed to save warm cache snapshot: %w", err)
+	}
+	return nil
+}
+
+// Load restores registered caches from the last snapshot and returns the
+// number of caches restored. Missing or stale snapshots restore nothing.
+func (m *Manager) Load(ctx context.Context) (int, error) {
+	data, err := m.backend.Load(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load warm cache snapshot: %w", err)
+	}
+	if len(data) == 0 {
+		return 0, nil
+	}
+
+	var snap snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return 0, fmt.Errorf("failed to parse warm cache snapshot: %w", err)
+	}
+	if snap.Version != snapshotVersion {
+		return 0, fmt.Errorf("unsupported warm cache snapshot version: %d", snap.Version)
+	}
+	if m.maxAge > 0 && time.Since(snap.SavedAt) > m.maxAge {
+		logger.Log.Info().
+			Time("saved_at", snap.SavedAt).
+			Dur("max_age", m.maxAge).
+			Msg("Warm cache snapshot too old, starting cold")
+		return 0, nil
+	}
+
+	m.mu.Lock()
+	providers := append([]Provider(nil), m.providers...)
+	m.mu.Unlock()
+
+	restored := 0
+	for _, p := range providers {
+		cacheData, ok := snap.Caches[p.SnapshotName()]
+		if !ok {
+			continue
+		}
+		if err := p.Restore(cacheData); err != nil {
+			logger.Log.Warn().Err(err).Str("cache", p.SnapshotName()).Msg("Failed to restore cache")
+			continue
+		}
+		restored++
+	}
+	return restored, nil
+}
+
+// FileBackend stores the snapshot in a local file
+type FileBackend struct {
+	Path string
+}
+
+// Save writes the snapshot atomically (temp file + rename)
+func (b *FileBackend) Save(_ context.Context, data []byte) error {
+	tmp := b.Path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, b.Path)
+}
+
+// Load reads the snapshot, returning nil data when the file doesn't exist
+func (b *FileBackend) Load(_ context.Context) ([]byte, error) {
+	data, err := os.ReadFile(b.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// RedisClient is the subset of the Redis client used by RedisBackend
+type RedisClient interface {
+	Get(ctx context.Context, key string) (string, error)
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
+}
+
+// RedisBackend stores the snapshot under a Redis key, shared by all instances
+type RedisBackend struct {
+	Client RedisClient
+	Key    string
+	TTL    time.Duration
+}
+
+// Save writes the snapshot to Redis
+func (b *RedisBackend) Save(ctx context.Context, data []byte) error {
+	return b.Client.Set(ctx, b.Key, data, b.TTL)
+}
+
+// Load reads the snapshot from Redis, returning nil data when the key doesn't exist
+func (b *RedisBackend) Load(ctx context.Context) ([]byte, error) {
+	data, err := b.Client.Get(ctx, b.Key)
+	if err != nil || data == "" {
+		return nil, err
+	}
+	return []byte(data), nil
+}
@@ -0,0 +1,133 @@
+package warmcache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/thenexusengine/tne_springwire/pkg/redis"
+)
+
+// mapProvider is a simple map-backed cache for testing
+type mapProvider struct {
+	name string
+	data map[string]string
+	fail bool
+}
+
+func (p *mapProvider) SnapshotName() string { return p.name }
+
+func (p *mapProvider) Snapshot() (json.RawMessage, error) {
+	if p.fail {
+		return nil, errors.New("snapshot failed")
+	}
+	return json.Marshal(p.data)
+}
+
+func (p *mapProvider) Restore(data json.RawMessage) error {
+	return json.Unmarshal(data, &p.data)
+}
+
+func TestManager_FileRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	ctx := context.Background()
+
+	saver := NewManager(&FileBackend{Path: path}, time.Minute)
+	saver.Register(&mapProvider{name: "pubs", data: map[string]string{"pub1": "example.com"}})
+	saver.Register(&mapProvider{name: "broken", fail: true})
+	if err := saver.Save(ctx); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	restoredPubs := &mapProvider{name: "pubs"}
+	loader := NewManager(&FileBackend{Path: path}, time.Minute)
+	loader.Register(restoredPubs)
+	loader.Register(&mapProvider{name: "broken"})
+
+	restored, err := loader.Load(ctx)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if restored != 1 {
+		t.Errorf("expected 1 cache restored, got %d", restored)
+	}
+	if restoredPubs.data["pub1"] != "example.com" {
+		t.Errorf("expected pub1 to be restored, got %v", restoredPubs.data)
+	}
+}
+
+func TestManager_MissingSnapshot(t *testing.T) {
+	m := NewManager(&FileBackend{Path: filepath.Join(t.TempDir(), "missing.json")}, time.Minute)
+	m.Register(&mapProvider{name: "pubs"})
+
+	restored, err := m.Load(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error for missing snapshot, got %v", err)
+	}
+	if restored != 0 {
+		t.Errorf("expected nothing restored, got %d", restored)
+	}
+}
+
+func TestManager_StaleSnapshotIgnored(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	stale := snapshot{
+		Version: snapshotVersion,
+		SavedAt: time.Now().Add(-time.Hour),
+		Caches:  map[string]json.RawMessage{"pubs": json.RawMessage(`{"pub1":"example.com"}`)},
+	}
+	data, _ := json.Marshal(stale)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	p := &mapProvider{name: "pubs"}
+	m := NewManager(&FileBackend{Path: path}, time.Minute)
+	m.Register(p)
+
+	restored, err := m.Load(context.Background())
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if restored != 0 || p.data != nil {
+		t.Errorf("expected stale snapshot to be ignored, restored=%d data=%v", restored, p.data)
+	}
+}
+
+func TestManager_RedisRoundTrip(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("Failed to start miniredis: %v", err)
+	}
+	defer mr.Close()
+
+	client, err := redis.New("redis://" + mr.Addr())
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	backend := &RedisBackend{Client: client, Key: "test:warm_cache", TTL: time.Minute}
+	ctx := context.Background()
+
+	saver := NewManager(backend, time.Minute)
+	saver.Register(&mapProvider{name: "pubs", data: map[string]string{"pub1": "example.com"}})
+	if err := saver.Save(ctx); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	p := &mapProvider{name: "pubs"}
+	loader := NewManager(backend, time.Minute)
+	loader.Register(p)
+	if _, err := loader.Load(ctx); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if p.data["pub1"] != "example.com" {
+		t.Errorf("expected pub1 to be restored from Redis, got %v", p.data)
+	}
+}
@@ -0,0 +1,193 @@
+package winqueue
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/analytics"
+)
+
+// Notice statuses reported to NoticeMetrics
+const (
+	NoticeSent     = "sent"
+	NoticeRejected = "rejected" // the bidder answered with a client error
+	NoticeFailed   = "failed"   // unreachable or a server error; retried
+	NoticeInvalid  = "invalid"  // malformed URL
+)
+
+// NoticeMetrics records fired notice URLs per bidder
+type NoticeMetrics interface {
+	RecordBidderNotice(bidder, noticeType, status string)
+}
+
+// NoticeFirer calls the bidder's notice URL: nurl for wins, burl for billing
+// and lurl for losses
+type NoticeFirer struct {
+	client  *http.Client
+	metrics NoticeMetrics
+}
+
+// NewNoticeFirer creates a notice firer with the given per-call timeout;
+// metrics may be nil
+func NewNoticeFirer(timeout time.Duration, metrics NoticeMetrics) *NoticeFirer {
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	return &NoticeFirer{client: &http.Client{Timeout: timeout}, metrics: metrics}
+}
+
+// Process implements Processor. Server errors are retried; client errors are
+// the bidder rejecting the notice and are not.
+func (f *NoticeFirer) Process(ctx context.Context, event Event) error {
+	if event.URL == "" {
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, expandMacros(event.URL, event), nil)
+	if err != nil {
+		f.record(event, NoticeInvalid)
+		return nil // malformed bidder URL; retrying won't help
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		f.record(event, NoticeFailed)
+		return fmt.Errorf("failed to fire %s notice: %w", event.Type, err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	switch {
+	case resp.StatusCode >= 500:
+		f.record(event, NoticeFailed)
+		return fmt.Errorf("%s notice returned status %d", event.Type, resp.StatusCode)
+	case resp.StatusCode >= 400:
+		f.record(event, NoticeRejected)
+	default:
+		f.record(event, NoticeSent)
+	}
+	return nil
+}
+
+func (f *NoticeFirer) record(event Event, status string) {
+	if f.metrics != nil {
+		f.metrics.RecordBidderNotice(event.Bidder, event.Type, status)
+	}
+}
+
+// expandMacros substitutes the OpenRTB substitution macros in a notice URL,
+// query-escaped. ${AUCTION_LOSS} and ${AUCTION_MIN_TO_WIN} are only known
+// for losses and are left empty otherwise.
+func expandMacros(url string, event Event) string {
+	if !strings.Contains(url, "${") {
+		return url
+	}
+	price := strconv.FormatFloat(event.Price, 'f', -1, 64)
+	var loss, minToWin string
+	if event.Type == EventLoss {
+		loss = strconv.Itoa(event.LossReason)
+		minToWin = price
+	}
+	return strings.NewReplacer(
+		"${AUCTION_ID}", neturl.QueryEscape(event.AuctionID),
+		"${AUCTION_BID_ID}", neturl.QueryEscape(event.BidID),
+		"${AUCTION_IMP_ID}", neturl.QueryEscape(event.ImpID),
+		"${AUCTION_SEAT_ID}", neturl.QueryEscape(event.Bidder),
+		"${AUCTION_AD_ID}", neturl.QueryEscape(event.CreativeID),
+		"${AUCTION_PRICE}", price,
+		"${AUCTION_CURRENCY}", neturl.QueryEscape(event.Currency),
+		"${AUCTION_LOSS}", loss,
+		"${AUCTION_MIN_TO_WIN}", minToWin,
+	).Replace(url)
+}
+
+// WinRecorder records win analytics; implemented by idr.EventRecorder
+type WinRecorder interface {
+	RecordWin(auctionID, bidderCode string, winCPM float64, country, deviceType, mediaType, adSize, publisherID string)
+}
+
+// WinAnalytics records win events, enriched with the auction details kept
+// for the bid, to the analytics pipeline
+type WinAnalytics struct {
+	recorder WinRecorder
+}
+
+// NewWinAnalytics creates a win analytics processor
+func NewWinAnalytics(recorder WinRecorder) *WinAnalytics {
+	return &WinAnalytics{recorder: recorder}
+}
+
+// Process implements Processor
+func (a *WinAnalytics) Process(ctx context.Context, event Event) error {
+	if a.recorder == nil || event.Type != EventWin {
+		return nil
+	}
+	a.recorder.RecordWin(event.AuctionID, event.Bidder, event.Price, event.Country, event.DeviceType, event.MediaType, event.AdSize, event.PublisherID)
+	return nil
+}
+
+// EventTracker ships events to downstream analytics systems; implemented by
+// analytics.Pipeline
+type EventTracker interface {
+	Track(event analytics.Event)
+}
+
+// EventAnalytics ships win and billing notices to the analytics pipeline
+type EventAnalytics struct {
+	tracker EventTracker
+}
+
+// NewEventAnalytics creates an analytics pipeline processor
+func NewEventAnalytics(tracker EventTracker) *EventAnalytics {
+	return &EventAnalytics{tracker: tracker}
+}
+
+// Process implements Processor. It never fails, so as the last processor
+// each notice is shipped once: events failing an earlier processor are
+// retried before reaching it. Losses are already shipped as lost bid events
+// by the exchange.
+func (a *EventAnalytics) Process(ctx context.Context, event Event) error {
+	if event.Type == EventLoss {
+		return nil
+	}
+	eventType := analytics.EventWin
+	if event.Type == EventBilling {
+		eventType = analytics.EventBilling
+	}
+	fields := make(map[string]string)
+	for key, value := range map[string]string{
+		"deal_id":           event.DealID,
+		"advertiser":        event.Advertiser,
+		"advertiser_domain": event.AdvertiserDomain,
+		"campaign_id":       event.CampaignID,
+		"creative_id":       event.CreativeID,
+	} {
+		if value != "" {
+			fields[key] = value
+		}
+	}
+	if event.GrossPrice > 0 {
+		fields["gross_price"] = strconv.FormatFloat(event.GrossPrice, 'f', -1, 64)
+	}
+	if len(fields) == 0 {
+		fields = nil
+	}
+	a.tracker.Track(analytics.Event{
+		Type:        eventType,
+		Timestamp:   event.ReceivedAt,
+		AuctionID:   event.AuctionID,
+		PublisherID: event.PublisherID,
+		Bidder:      event.Bidder,
+		BidID:       event.BidID,
+		MediaType:   event.MediaType,
+		Price:       event.Price,
+		Fields:      fields,
+	})
+	return nil
+}
@@ -0,0 +1,387 @@
+// Package winqueue moves win and billing notice processing (firing bidder
+// notice URLs, recording win analytics) off the request path. Events are
+// appended to a Redis Stream and consumed by a worker pool in the same binary
+// through a consumer group, so any instance can pick up work enqueued by
+// another. Without Redis, or while Redis is over its error budget, events go
+// to an in-process buffer instead.
+package winqueue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/pkg/kv"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+	"github.com/thenexusengine/tne_springwire/pkg/redis"
+)
+
+// Event types
+const (
+	EventWin     = "win"
+	EventBilling = "billing"
+	EventLoss    = "loss" // a bid that lost the auction, to fire its lurl
+)
+
+// Event is one accepted win or billing notice, or a bid's loss
+type Event struct {
+	Type             string    `json:"type"`
+	BidID            string    `json:"bid_id"`
+	Bidder           string    `json:"bidder"`
+	AuctionID        string    `json:"auction_id,omitempty"`
+	ImpID            string    `json:"imp_id,omitempty"`
+	PublisherID      string    `json:"publisher_id,omitempty"`
+	MediaType        string    `json:"media_type,omitempty"`
+	DealID           string    `json:"deal_id,omitempty"`
+	Country          string    `json:"country,omitempty"`
+	DeviceType       string    `json:"device_type,omitempty"`
+	AdSize           string    `json:"ad_size,omitempty"`
+	Advertiser       string    `json:"advertiser,omitempty"` // advertiser name, or its domain
+	AdvertiserDomain string    `json:"advertiser_domain,omitempty"`
+	CampaignID       string    `json:"campaign_id,omitempty"`
+	CreativeID       string    `json:"creative_id,omitempty"`
+	Price            float64   `json:"price"`                 // publisher-facing CPM; for losses, the price the winner cleared at
+	GrossPrice       float64   `json:"gross_price,omitempty"` // bidder CPM before the bid multiplier
+	Currency         string    `json:"currency,omitempty"`    // of Price and GrossPrice
+	LossReason       int       `json:"loss_reason,omitempty"` // OpenRTB loss reason code of loss events
+	URL              string    `json:"url,omitempty"`         // bidder notice URL to fire (nurl, burl or lurl)
+	ReceivedAt       time.Time `json:"received_at"`
+
+	attempts int // in-process retries; streams use the entry's delivery count
+}
+
+// Processor handles one event. Returning an error retries the event with
+// every processor, so processors should tolerate repeats.
+type Processor interface {
+	Process(ctx context.Context, event Event) error
+}
+
+// ProcessorFunc adapts a function to Processor
+type ProcessorFunc func(ctx context.Context, event Event) error
+
+// Process implements Processor
+func (f ProcessorFunc) Process(ctx context.Context, event Event) error {
+	return f(ctx, event)
+}
+
+// Streams is the subset of the Redis client used by the queue
+type Streams interface {
+	XAdd(ctx context.Context, stream string, maxLen int64, values map[string]interface{}) (string, error)
+	XGroupCreate(ctx context.Context, stream, group string) error
+	XReadGroup(ctx context.Context, stream, group, consumer string, count int64, block time.Duration) ([]redis.StreamMessage, error)
+	XAck(ctx context.Context, stream, group string, ids ...string) error
+	XAutoClaim(ctx context.Context, stream, group, consumer string, minIdle time.Duration, count int64) ([]redis.StreamMessage, error)
+	XDeliveries(ctx context.Context, stream, group, id string) (int64, error)
+}
+
+// Redis client satisfies Streams directly
+var _ Streams = (*redis.Client)(nil)
+
+// Metrics records queue activity
+type Metrics interface {
+	RecordWinQueueEvent(eventType, status string)
+}
+
+// Event statuses reported to Metrics
+const (
+	StatusEnqueued  = "enqueued"
+	StatusProcessed = "processed"
+	StatusRetried   = "retried"
+	StatusDropped   = "dropped"
+)
+
+// Config configures the queue
+type Config struct {
+	Workers     int           // Worker goroutines per instance
+	Stream      string        // Redis stream key
+	Group       string        // Consumer group shared by all instances
+	MaxLen      int64         // Approximate stream length cap
+	MaxAttempts int           // Attempts before an event is dropped
+	ClaimIdle   time.Duration // Failed or orphaned entries idle this long are retried
+	Block       time.Duration // Longest a worker blocks waiting for entries
+	BufferSize  int           // In-process buffer used without Redis or while it is degraded
+}
+
+// DefaultConfig returns the default queue configuration
+func DefaultConfig() Config {
+	return Config{
+		Workers:     4,
+		Stream:      "pbs:win-events",
+		Group:       "pbs-win-workers",
+		MaxLen:      100000,
+		MaxAttempts: 5,
+		ClaimIdle:   time.Minute,
+		Block:       2 * time.Second,
+		BufferSize:  10000,
+	}
+}
+
+// Queue is a win/billing event queue with a local worker pool
+type Queue struct {
+	cfg        Config
+	streams    Streams // nil = in-process buffer only
+	local      chan Event
+	processors []Processor
+	metrics    Metrics
+	consumer   string
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// New creates a queue. With nil streams events are buffered in process and
+// lost on restart; with streams, only events enqueued while Redis is
+// degraded are.
+func New(streams Streams, cfg Config, metrics Metrics, processors ...Processor) *Queue {
+	defaults := DefaultConfig()
+	if cfg.Workers <= 0 {
+		cfg.Workers = defaults.Workers
+	}
+	if cfg.Stream == "" {
+		cfg.Stream = defaults.Stream
+	}
+	if cfg.Group == "" {
+		cfg.Group = defaults.Group
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaults.MaxAttempts
+	}
+	if cfg.ClaimIdle <= 0 {
+		cfg.ClaimIdle = defaults.ClaimIdle
+	}
+	if cfg.Block <= 0 {
+		cfg.Block = defaults.Block
+	}
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = defaults.BufferSize
+	}
+
+	hostname, _ := os.Hostname()
+	q := &Queue{
+		cfg:        cfg,
+		streams:    streams,
+		processors: processors,
+		metrics:    metrics,
+		consumer:   fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		local:      make(chan Event, cfg.BufferSize),
+		stop:       make(chan struct{}),
+	}
+	return q
+}
+
+// Enqueue adds an event for asynchronous processing
+func (q *Queue) Enqueue(ctx context.Context, event Event) error {
+	if event.ReceivedAt.IsZero() {
+		event.ReceivedAt = time.Now()
+	}
+
+	// Shed the stream write while Redis is over its error budget
+	if q.streams == nil || kv.IsDegraded(q.streams) {
+		select {
+		case q.local <- event:
+		default:
+			q.record(event.Type, StatusDropped)
+			return fmt.Errorf("win queue buffer full")
+		}
+		q.record(event.Type, StatusEnqueued)
+		return nil
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal win event: %w", err)
+	}
+	if _, err := q.streams.XAdd(ctx, q.cfg.Stream, q.cfg.MaxLen, map[string]interface{}{"event": string(data)}); err != nil {
+		return fmt.Errorf("failed to enqueue win event: %w", err)
+	}
+	q.record(event.Type, StatusEnqueued)
+	return nil
+}
+
+// Start creates the consumer group and launches the workers
+func (q *Queue) Start(ctx context.Context) error {
+	if q.streams != nil {
+		if err := q.streams.XGroupCreate(ctx, q.cfg.Stream, q.cfg.Group); err != nil {
+			return fmt.Errorf("failed to create win queue consumer group: %w", err)
+		}
+	}
+
+	for i := 0; i < q.cfg.Workers; i++ {
+		q.wg.Add(1)
+		consumer := q.consumer + "-" + strconv.Itoa(i)
+		go q.worker(consumer, i == 0)
+	}
+	if q.streams != nil {
+		// Drains events buffered while Redis was degraded
+		q.wg.Add(1)
+		go q.localWorker()
+	}
+
+	logger.Log.Info().
+		Int("workers", q.cfg.Workers).
+		Bool("redis", q.streams != nil).
+		Str("stream", q.cfg.Stream).
+		Msg("Win queue workers started")
+	return nil
+}
+
+// Stop stops the workers after their current batch
+func (q *Queue) Stop() {
+	q.stopOnce.Do(func() {
+		close(q.stop)
+	})
+	q.wg.Wait()
+}
+
+// worker consumes events until Stop. The first worker also reclaims entries
+// left pending by failures or by consumers that died.
+func (q *Queue) worker(consumer string, reclaim bool) {
+	if q.streams == nil {
+		q.localWorker()
+		return
+	}
+	defer q.wg.Done()
+
+	lastClaim := time.Now()
+	for {
+		select {
+		case <-q.stop:
+			return
+		default:
+		}
+
+		ctx := context.Background()
+		if reclaim && time.Since(lastClaim) >= q.cfg.ClaimIdle {
+			lastClaim = time.Now()
+			claimed, err := q.streams.XAutoClaim(ctx, q.cfg.Stream, q.cfg.Group, consumer, q.cfg.ClaimIdle, 100)
+			if err != nil {
+				logger.Log.Warn().Err(err).Msg("Failed to reclaim pending win events")
+			}
+			q.handleMessages(ctx, claimed, true)
+		}
+
+		msgs, err := q.streams.XReadGroup(ctx, q.cfg.Stream, q.cfg.Group, consumer, 10, q.cfg.Block)
+		if err != nil {
+			logger.Log.Warn().Err(err).Msg("Failed to read win events")
+			select {
+			case <-q.stop:
+				return
+			case <-time.After(q.cfg.Block):
+			}
+			continue
+		}
+		q.handleMessages(ctx, msgs, false)
+	}
+}
+
+// localWorker processes in-process events until Stop
+func (q *Queue) localWorker() {
+	defer q.wg.Done()
+	for {
+		select {
+		case <-q.stop:
+			return
+		case event := <-q.local:
+			q.handleLocal(event)
+		}
+	}
+}
+
+// handleMessages processes stream entries. Successful, malformed and
+// exhausted entries are acknowledged; failed ones stay pending and are
+// retried by the reclaim loop once idle for ClaimIdle.
+func (q *Queue) handleMessages(ctx context.Context, msgs []redis.StreamMessage, claimed bool) {
+	for _, msg := range msgs {
+		raw, _ := msg.Values["event"].(string)
+		var event Event
+		if err := json.Unmarshal([]byte(raw), &event); err != nil {
+			logger.Log.Warn().Err(err).Str("id", msg.ID).Msg("Dropping malformed win event")
+			q.record("unknown", StatusDropped)
+			q.ack(ctx, msg.ID)
+			continue
+		}
+
+		err := q.process(event)
+		if err == nil {
+			q.record(event.Type, StatusProcessed)
+			q.ack(ctx, msg.ID)
+			continue
+		}
+
+		attempts := int64(1)
+		if claimed {
+			if n, derr := q.streams.XDeliveries(ctx, q.cfg.Stream, q.cfg.Group, msg.ID); derr == nil && n > 0 {
+				attempts = n
+			}
+		}
+		if attempts >= int64(q.cfg.MaxAttempts) {
+			q.drop(event, int(attempts), err)
+			q.ack(ctx, msg.ID)
+			continue
+		}
+		q.record(event.Type, StatusRetried)
+	}
+}
+
+// handleLocal processes an in-process event, retrying with backoff
+func (q *Queue) handleLocal(event Event) {
+	err := q.process(event)
+	if err == nil {
+		q.record(event.Type, StatusProcessed)
+		return
+	}
+
+	event.attempts++
+	if event.attempts >= q.cfg.MaxAttempts {
+		q.drop(event, event.attempts, err)
+		return
+	}
+	q.record(event.Type, StatusRetried)
+	time.AfterFunc(time.Duration(event.attempts)*time.Second, func() {
+		select {
+		case q.local <- event:
+		default:
+			q.record(event.Type, StatusDropped)
+		}
+	})
+}
+
+// process runs every processor, stopping at the first error
+func (q *Queue) process(event Event) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	for _, p := range q.processors {
+		if err := p.Process(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (q *Queue) drop(event Event, attempts int, err error) {
+	logger.Log.Warn().
+		Err(err).
+		Str("type", event.Type).
+		Str("bid_id", event.BidID).
+		Int("attempts", attempts).
+		Msg("Dropping win event after repeated failures")
+	q.record(event.Type, StatusDropped)
+}
+
+func (q *Queue) ack(ctx context.Context, id string) {
+	if err := q.streams.XAck(ctx, q.cfg.Stream, q.cfg.Group, id); err != nil {
+		logger.Log.Warn().Err(err).Str("id", id).Msg("Failed to acknowledge win event")
+	}
+}
+
+func (q *Queue) record(eventType, status string) {
+	if q.metrics != nil {
+		q.metrics.RecordWinQueueEvent(eventType, status)
+	}
+}
@@ -0,0 +1,344 @@
+package winqueue
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/thenexusengine/tne_springwire/pkg/redis"
+)
+
+type mockMetrics struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (m *mockMetrics) RecordWinQueueEvent(eventType, status string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.counts[eventType+":"+status]++
+}
+
+func (m *mockMetrics) get(key string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.counts[key]
+}
+
+// collector records processed events, failing the first failures calls
+type collector struct {
+	mu       sync.Mutex
+	failures int
+	calls    int
+	events   []Event
+}
+
+func (c *collector) Process(ctx context.Context, event Event) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls++
+	if c.calls <= c.failures {
+		return errors.New("temporary failure")
+	}
+	c.events = append(c.events, event)
+	return nil
+}
+
+func (c *collector) processed() []Event {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Event(nil), c.events...)
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("condition not met before timeout")
+}
+
+func newStreamQueue(t *testing.T, cfg Config, metrics Metrics, processors ...Processor) *Queue {
+	t.Helper()
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("Failed to start miniredis: %v", err)
+	}
+	t.Cleanup(mr.Close)
+
+	client, err := redis.New("redis://" + mr.Addr())
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	q := New(client, cfg, metrics, processors...)
+	if err := q.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	t.Cleanup(q.Stop)
+	return q
+}
+
+func TestQueue_Stream(t *testing.T) {
+	metrics := &mockMetrics{counts: map[string]int{}}
+	proc := &collector{}
+	q := newStreamQueue(t, Config{Workers: 2, Block: 20 * time.Millisecond}, metrics, proc)
+
+	event := Event{Type: EventWin, BidID: "bid-1", Bidder: "appnexus", AuctionID: "auction-1", Price: 1.5}
+	if err := q.Enqueue(context.Background(), event); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+
+	waitFor(t, func() bool { return len(proc.processed()) == 1 })
+	got := proc.processed()[0]
+	if got.BidID != "bid-1" || got.Bidder != "appnexus" || got.AuctionID != "auction-1" || got.Price != 1.5 {
+		t.Errorf("unexpected event: %+v", got)
+	}
+	if got.ReceivedAt.IsZero() {
+		t.Error("expected ReceivedAt to be set on enqueue")
+	}
+	waitFor(t, func() bool { return metrics.get("win:processed") == 1 })
+	if metrics.get("win:enqueued") != 1 {
+		t.Errorf("expected one enqueued event, got %v", metrics.counts)
+	}
+}
+
+func TestQueue_StreamRetriesPendingEntries(t *testing.T) {
+	metrics := &mockMetrics{counts: map[string]int{}}
+	proc := &collector{failures: 1}
+	q := newStreamQueue(t, Config{Workers: 1, Block: 20 * time.Millisecond, ClaimIdle: 50 * time.Millisecond}, metrics, proc)
+
+	if err := q.Enqueue(context.Background(), Event{Type: EventBilling, BidID: "bid-1"}); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+
+	waitFor(t, func() bool { return len(proc.processed()) == 1 })
+	if metrics.get("billing:retried") != 1 {
+		t.Errorf("expected one retry, got %v", metrics.counts)
+	}
+}
+
+func TestQueue_StreamDropsAfterMaxAttempts(t *testing.T) {
+	metrics := &mockMetrics{counts: map[string]int{}}
+	proc := &collector{failures: 100}
+	q := newStreamQueue(t, Config{Workers: 1, Block: 20 * time.Millisecond, ClaimIdle: 20 * time.Millisecond, MaxAttempts: 2}, metrics, proc)
+
+	if err := q.Enqueue(context.Background(), Event{Type: EventWin, BidID: "bid-1"}); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+
+	waitFor(t, func() bool { return metrics.get("win:dropped") == 1 })
+	if len(proc.processed()) != 0 {
+		t.Errorf("expected no successful processing, got %+v", proc.processed())
+	}
+}
+
+// degradedStreams is a Redis over its error budget that counts stream writes
+type degradedStreams struct {
+	mu   sync.Mutex
+	adds int
+}
+
+func (d *degradedStreams) Degraded() bool { return true }
+
+func (d *degradedStreams) XAdd(ctx context.Context, stream string, maxLen int64, values map[string]interface{}) (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.adds++
+	return "1-0", nil
+}
+
+func (d *degradedStreams) XGroupCreate(ctx context.Context, stream, group string) error { return nil }
+
+func (d *degradedStreams) XReadGroup(ctx context.Context, stream, group, consumer string, count int64, block time.Duration) ([]redis.StreamMessage, error) {
+	time.Sleep(block)
+	return nil, nil
+}
+
+func (d *degradedStreams) XAck(ctx context.Context, stream, group string, ids ...string) error {
+	return nil
+}
+
+func (d *degradedStreams) XAutoClaim(ctx context.Context, stream, group, consumer string, minIdle time.Duration, count int64) ([]redis.StreamMessage, error) {
+	return nil, nil
+}
+
+func (d *degradedStreams) XDeliveries(ctx context.Context, stream, group, id string) (int64, error) {
+	return 0, nil
+}
+
+func TestQueue_DegradedStreamsUseLocalBuffer(t *testing.T) {
+	metrics := &mockMetrics{counts: map[string]int{}}
+	proc := &collector{}
+	streams := &degradedStreams{}
+	q := New(streams, Config{Workers: 1, Block: 10 * time.Millisecond}, metrics, proc)
+	if err := q.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer q.Stop()
+
+	if err := q.Enqueue(context.Background(), Event{Type: EventWin, BidID: "bid-1"}); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+
+	waitFor(t, func() bool { return len(proc.processed()) == 1 })
+	streams.mu.Lock()
+	defer streams.mu.Unlock()
+	if streams.adds != 0 {
+		t.Errorf("expected no stream writes while degraded, got %d", streams.adds)
+	}
+}
+
+func TestQueue_Local(t *testing.T) {
+	metrics := &mockMetrics{counts: map[string]int{}}
+	proc := &collector{}
+	q := New(nil, Config{Workers: 1}, metrics, proc)
+	if err := q.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer q.Stop()
+
+	for _, id := range []string{"bid-1", "bid-2"} {
+		if err := q.Enqueue(context.Background(), Event{Type: EventWin, BidID: id}); err != nil {
+			t.Fatalf("Enqueue failed: %v", err)
+		}
+	}
+
+	waitFor(t, func() bool { return len(proc.processed()) == 2 })
+	waitFor(t, func() bool { return metrics.get("win:processed") == 2 })
+}
+
+func TestQueue_LocalBufferFull(t *testing.T) {
+	metrics := &mockMetrics{counts: map[string]int{}}
+	q := New(nil, Config{BufferSize: 1}, metrics)
+
+	// Not started, so the buffer is never drained
+	if err := q.Enqueue(context.Background(), Event{Type: EventWin, BidID: "bid-1"}); err != nil {
+		t.Fatalf("first Enqueue failed: %v", err)
+	}
+	if err := q.Enqueue(context.Background(), Event{Type: EventWin, BidID: "bid-2"}); err == nil {
+		t.Error("expected error when buffer is full")
+	}
+	if metrics.get("win:dropped") != 1 {
+		t.Errorf("expected one dropped event, got %v", metrics.counts)
+	}
+}
+
+type mockNoticeMetrics struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (m *mockNoticeMetrics) RecordBidderNotice(bidder, noticeType, status string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.counts[bidder+":"+noticeType+":"+status]++
+}
+
+func TestNoticeFirer(t *testing.T) {
+	var gotQuery string
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.RawQuery
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	metrics := &mockNoticeMetrics{counts: make(map[string]int)}
+	f := NewNoticeFirer(time.Second, metrics)
+	event := Event{Type: EventWin, Bidder: "rubicon", Price: 2.35, URL: server.URL + "/win?price=${AUCTION_PRICE}"}
+
+	if err := f.Process(context.Background(), event); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if gotQuery != "price=2.35" {
+		t.Errorf("expected price macro substituted, got %q", gotQuery)
+	}
+
+	status = http.StatusBadRequest
+	if err := f.Process(context.Background(), event); err != nil {
+		t.Errorf("expected client error not to be retried, got %v", err)
+	}
+
+	status = http.StatusServiceUnavailable
+	if err := f.Process(context.Background(), event); err == nil {
+		t.Error("expected server error to be retried")
+	}
+
+	if err := f.Process(context.Background(), Event{Type: EventBilling}); err != nil {
+		t.Errorf("expected event without URL to be skipped, got %v", err)
+	}
+
+	for key, want := range map[string]int{"rubicon:win:sent": 1, "rubicon:win:rejected": 1, "rubicon:win:failed": 1} {
+		if metrics.counts[key] != want {
+			t.Errorf("expected %s counted %d times, got %v", key, want, metrics.counts)
+		}
+	}
+}
+
+func TestExpandMacros(t *testing.T) {
+	loss := Event{
+		Type:       EventLoss,
+		AuctionID:  "auction 1",
+		BidID:      "bid-1",
+		ImpID:      "imp-1",
+		Bidder:     "rubicon",
+		CreativeID: "cr-1",
+		Price:      4.2,
+		Currency:   "USD",
+		LossReason: 102,
+	}
+	url := "https://bidder.example/loss?a=${AUCTION_ID}&b=${AUCTION_BID_ID}&i=${AUCTION_IMP_ID}&s=${AUCTION_SEAT_ID}" +
+		"&ad=${AUCTION_AD_ID}&c=${AUCTION_CURRENCY}&r=${AUCTION_LOSS}&m=${AUCTION_MIN_TO_WIN}"
+	want := "https://bidder.example/loss?a=auction+1&b=bid-1&i=imp-1&s=rubicon&ad=cr-1&c=USD&r=102&m=4.2"
+	if got := expandMacros(url, loss); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	win := Event{Type: EventWin, Price: 4.2}
+	if got := expandMacros("https://bidder.example/win?p=${AUCTION_PRICE}&r=${AUCTION_LOSS}", win); got != "https://bidder.example/win?p=4.2&r=" {
+		t.Errorf("expected loss macros empty on a win, got %q", got)
+	}
+}
+
+type mockWinRecorder struct {
+	auctionID, bidder, country, deviceType, mediaType, adSize, publisherID string
+	cpm                                                                    float64
+	calls                                                                  int
+}
+
+func (m *mockWinRecorder) RecordWin(auctionID, bidderCode string, winCPM float64, country, deviceType, mediaType, adSize, publisherID string) {
+	m.calls++
+	m.auctionID, m.bidder, m.cpm, m.mediaType, m.publisherID = auctionID, bidderCode, winCPM, mediaType, publisherID
+	m.country, m.deviceType, m.adSize = country, deviceType, adSize
+}
+
+func TestWinAnalytics(t *testing.T) {
+	rec := &mockWinRecorder{}
+	a := NewWinAnalytics(rec)
+
+	_ = a.Process(context.Background(), Event{Type: EventBilling, BidID: "bid-1"})
+	if rec.calls != 0 {
+		t.Error("expected billing events to be ignored")
+	}
+
+	_ = a.Process(context.Background(), Event{
+		Type: EventWin, AuctionID: "auction-1", Bidder: "rubicon", Price: 3.1, MediaType: "video", PublisherID: "pub-1",
+		Country: "USA", DeviceType: "ctv", AdSize: "1920x1080",
+	})
+	if rec.calls != 1 || rec.auctionID != "auction-1" || rec.bidder != "rubicon" || rec.cpm != 3.1 ||
+		rec.mediaType != "video" || rec.publisherID != "pub-1" ||
+		rec.country != "USA" || rec.deviceType != "ctv" || rec.adSize != "1920x1080" {
+		t.Errorf("unexpected recorded win: %+v", rec)
+	}
+}
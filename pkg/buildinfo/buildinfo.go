@@ -0,0 +1,86 @@
+// Package buildinfo describes the running binary: the version, git SHA and
+// build date injected at build time, plus the adapter registry hash set at
+// startup. Inject the build values with:
+//
+//	go build -ldflags "-X github.com/thenexusengine/tne_springwire/pkg/buildinfo.Version=v1.2.3 \
+//	  -X github.com/thenexusengine/tne_springwire/pkg/buildinfo.GitSHA=$(git rev-parse HEAD) \
+//	  -X github.com/thenexusengine/tne_springwire/pkg/buildinfo.BuildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+)
+
+// Set via -ldflags at build time
+var (
+	Version   = "dev"
+	GitSHA    = ""
+	BuildDate = ""
+)
+
+// Info describes the running build
+type Info struct {
+	Version             string `json:"version"`
+	GitSHA              string `json:"git_sha,omitempty"`
+	BuildDate           string `json:"build_date,omitempty"`
+	GoVersion           string `json:"go_version"`
+	AdapterRegistryHash string `json:"adapter_registry_hash,omitempty"`
+}
+
+var (
+	vcsOnce      sync.Once
+	vcsRevision  string
+	vcsTime      string
+	registryHash atomic.Value // string
+)
+
+// SetAdapterRegistryHash records the hash of the bidder adapters compiled
+// into this binary
+func SetAdapterRegistryHash(hash string) {
+	registryHash.Store(hash)
+}
+
+// AdapterRegistryHash returns the hash set by SetAdapterRegistryHash, or ""
+func AdapterRegistryHash() string {
+	hash, _ := registryHash.Load().(string)
+	return hash
+}
+
+// Get returns the build info. When GitSHA or BuildDate were not injected, the
+// VCS stamp Go embeds in the binary is used instead.
+func Get() Info {
+	info := Info{
+		Version:             Version,
+		GitSHA:              GitSHA,
+		BuildDate:           BuildDate,
+		GoVersion:           runtime.Version(),
+		AdapterRegistryHash: AdapterRegistryHash(),
+	}
+
+	vcsOnce.Do(readVCS)
+	if info.GitSHA == "" {
+		info.GitSHA = vcsRevision
+	}
+	if info.BuildDate == "" {
+		info.BuildDate = vcsTime
+	}
+	return info
+}
+
+func readVCS() {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return
+	}
+	for _, setting := range bi.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			vcsRevision = setting.Value
+		case "vcs.time":
+			vcsTime = setting.Value
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package buildinfo
+
+import "testing"
+
+func TestGet(t *testing.T) {
+	origVersion, origSHA, origDate := Version, GitSHA, BuildDate
+	defer func() { Version, GitSHA, BuildDate = origVersion, origSHA, origDate }()
+
+	Version, GitSHA, BuildDate = "v1.2.3", "abc123", "2026-01-02T03:04:05Z"
+	SetAdapterRegistryHash("deadbeef")
+	defer SetAdapterRegistryHash("")
+
+	info := Get()
+	if info.Version != "v1.2.3" || info.GitSHA != "abc123" || info.BuildDate != "2026-01-02T03:04:05Z" {
+		t.Errorf("expected injected build values, got %+v", info)
+	}
+	if info.AdapterRegistryHash != "deadbeef" {
+		t.Errorf("expected adapter registry hash, got %q", info.AdapterRegistryHash)
+	}
+	if info.GoVersion == "" {
+		t.Error("expected Go version to be set")
+	}
+}
+
+func TestAdapterRegistryHash_Unset(t *testing.T) {
+	if hash := AdapterRegistryHash(); hash != "" {
+		t.Errorf("expected empty hash before it is set, got %q", hash)
+	}
+}
@@ -0,0 +1,91 @@
+// Package deadline caps dependency calls (Postgres, Redis, Memcached, IDR) to the
+// caller's context so a slow dependency can't consume the whole auction tmax
+package deadline
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Dependency names used for caps and timeout metrics
+const (
+	DependencyPostgres  = "postgres"
+	DependencyRedis     = "redis"
+	DependencyMemcached = "memcached"
+	DependencyIDR       = "idr"
+)
+
+// Default per-dependency caps applied on the auction path
+const (
+	DefaultPostgresCap  = 100 * time.Millisecond
+	DefaultRedisCap     = 50 * time.Millisecond
+	DefaultMemcachedCap = 50 * time.Millisecond
+	DefaultIDRCap       = 150 * time.Millisecond
+)
+
+// TimeoutRecorder records dependency calls that hit their deadline
+type TimeoutRecorder interface {
+	RecordDependencyTimeout(dependency string)
+}
+
+var (
+	mu       sync.RWMutex
+	recorder TimeoutRecorder
+	caps     = map[string]time.Duration{
+		DependencyPostgres:  DefaultPostgresCap,
+		DependencyRedis:     DefaultRedisCap,
+		DependencyMemcached: DefaultMemcachedCap,
+		DependencyIDR:       DefaultIDRCap,
+	}
+)
+
+// SetRecorder sets the metrics recorder for dependency timeouts
+func SetRecorder(r TimeoutRecorder) {
+	mu.Lock()
+	defer mu.Unlock()
+	recorder = r
+}
+
+// SetCap overrides the cap for a dependency (0 or negative disables the cap)
+func SetCap(dependency string, cap time.Duration) {
+	mu.Lock()
+	defer mu.Unlock()
+	caps[dependency] = cap
+}
+
+// Cap returns the configured cap for a dependency
+func Cap(dependency string) time.Duration {
+	mu.RLock()
+	defer mu.RUnlock()
+	return caps[dependency]
+}
+
+// WithCap derives a context for a dependency call. The result inherits the
+// parent's deadline and is further limited by the dependency's cap, so the
+// effective deadline is whichever comes first.
+func WithCap(ctx context.Context, dependency string) (context.Context, context.CancelFunc) {
+	cap := Cap(dependency)
+	if cap <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, cap)
+}
+
+// Observe records a dependency timeout when err was caused by ctx's deadline.
+// It returns err unchanged so it can wrap a call's return value.
+func Observe(ctx context.Context, dependency string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		mu.RLock()
+		r := recorder
+		mu.RUnlock()
+		if r != nil {
+			r.RecordDependencyTimeout(dependency)
+		}
+	}
+	return err
+}
@@ -0,0 +1,78 @@
+package deadline
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type mockRecorder struct {
+	timeouts map[string]int
+}
+
+func (m *mockRecorder) RecordDependencyTimeout(dependency string) {
+	m.timeouts[dependency]++
+}
+
+func TestWithCap_AppliesCap(t *testing.T) {
+	ctx, cancel := WithCap(context.Background(), DependencyRedis)
+	defer cancel()
+
+	dl, ok := ctx.Deadline()
+	if !ok {
+		t.Fatal("expected capped context to have a deadline")
+	}
+	if remaining := time.Until(dl); remaining > DefaultRedisCap {
+		t.Errorf("expected deadline within %v, got %v", DefaultRedisCap, remaining)
+	}
+}
+
+func TestWithCap_InheritsEarlierParentDeadline(t *testing.T) {
+	parent, parentCancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer parentCancel()
+
+	ctx, cancel := WithCap(parent, DependencyPostgres)
+	defer cancel()
+
+	parentDeadline, _ := parent.Deadline()
+	dl, _ := ctx.Deadline()
+	if !dl.Equal(parentDeadline) {
+		t.Errorf("expected parent deadline %v to win, got %v", parentDeadline, dl)
+	}
+}
+
+func TestWithCap_DisabledCap(t *testing.T) {
+	SetCap("test-dep", 0)
+	ctx, cancel := WithCap(context.Background(), "test-dep")
+	defer cancel()
+
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("expected no deadline when cap is disabled")
+	}
+}
+
+func TestObserve_RecordsTimeouts(t *testing.T) {
+	rec := &mockRecorder{timeouts: make(map[string]int)}
+	SetRecorder(rec)
+	defer SetRecorder(nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	<-ctx.Done()
+
+	err := Observe(ctx, DependencyIDR, errors.New("request canceled"))
+	if err == nil {
+		t.Error("expected error to be returned unchanged")
+	}
+	if rec.timeouts[DependencyIDR] != 1 {
+		t.Errorf("expected 1 idr timeout, got %d", rec.timeouts[DependencyIDR])
+	}
+
+	// Non-timeout errors and nil errors are not recorded
+	Observe(context.Background(), DependencyRedis, errors.New("connection refused"))
+	Observe(ctx, DependencyRedis, nil)
+	if rec.timeouts[DependencyRedis] != 0 {
+		t.Errorf("expected no redis timeouts, got %d", rec.timeouts[DependencyRedis])
+	}
+}
@@ -0,0 +1,225 @@
+// Package domainmatch matches request domains against publisher allow lists.
+// It is shared by publisher authentication and ads.txt validation so both
+// apply the same normalization (case, scheme, port, trailing dot, IDN) and
+// the same wildcard rules.
+//
+// Allow lists are separated by "|" or ",". Entries are:
+//
+//	example.com        exact host
+//	*.example.com      example.com and any subdomain
+//	*                  any host
+//	~^cdn\d+\.ex\.com$ regular expression over the normalized host
+//
+// Wildcards whose base is a public suffix (e.g. "*.co.uk") are rejected so an
+// entry can't admit every site under a registry.
+package domainmatch
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// regexPrefix marks an allow-list entry as a regular expression
+const regexPrefix = "~"
+
+// maxCachedLists bounds the compiled allow-list cache
+const maxCachedLists = 4096
+
+// Split parses an allow list on "|" and "," delimiters, trimming whitespace.
+// Delimiters inside (), [] or {} are kept so regex entries can use alternation.
+func Split(list string) []string {
+	var entries []string
+	depth := 0
+	start := 0
+	flush := func(end int) {
+		if entry := strings.TrimSpace(list[start:end]); entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	for i := 0; i < len(list); i++ {
+		switch list[i] {
+		case '\\':
+			i++ // skip escaped character
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			if depth > 0 {
+				depth--
+			}
+		case '|', ',':
+			if depth == 0 {
+				flush(i)
+				start = i + 1
+			}
+		}
+	}
+	if start < len(list) {
+		flush(len(list))
+	}
+	return entries
+}
+
+// Normalize converts a domain, host:port or URL to a lowercase ASCII host
+// without port or trailing dot. ok is false when nothing usable remains.
+func Normalize(domain string) (host string, ok bool) {
+	host = strings.TrimSpace(strings.ToLower(domain))
+	if i := strings.Index(host, "://"); i >= 0 {
+		host = host[i+3:]
+	}
+	if i := strings.IndexAny(host, "/?#"); i >= 0 {
+		host = host[:i]
+	}
+	if i := strings.LastIndex(host, "@"); i >= 0 {
+		host = host[i+1:]
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.Trim(host, "[]")
+	host = strings.TrimSuffix(host, ".")
+	if host == "" {
+		return "", false
+	}
+
+	ascii, err := toASCII(host)
+	if err != nil {
+		return "", false
+	}
+	return ascii, true
+}
+
+// Pattern is one compiled allow-list entry
+type Pattern struct {
+	raw      string
+	host     string
+	any      bool
+	wildcard bool
+	re       *regexp.Regexp
+}
+
+// ParsePattern compiles an allow-list entry
+func ParsePattern(entry string) (Pattern, error) {
+	entry = strings.TrimSpace(entry)
+	p := Pattern{raw: entry}
+
+	switch {
+	case entry == "*":
+		p.any = true
+	case strings.HasPrefix(entry, regexPrefix):
+		re, err := regexp.Compile(`^(?:` + entry[len(regexPrefix):] + `)$`)
+		if err != nil {
+			return p, fmt.Errorf("invalid domain regex %q: %w", entry, err)
+		}
+		p.re = re
+	case strings.HasPrefix(entry, "*."):
+		host, ok := Normalize(entry[2:])
+		if !ok {
+			return p, fmt.Errorf("invalid wildcard domain %q", entry)
+		}
+		if IsPublicSuffix(host) {
+			return p, fmt.Errorf("wildcard %q covers a public suffix", entry)
+		}
+		p.host = host
+		p.wildcard = true
+	default:
+		host, ok := Normalize(entry)
+		if !ok || strings.Contains(host, "*") {
+			return p, fmt.Errorf("invalid domain %q", entry)
+		}
+		p.host = host
+	}
+	return p, nil
+}
+
+// String returns the entry as written
+func (p Pattern) String() string {
+	return p.raw
+}
+
+// Match reports whether a normalized host matches the pattern
+func (p Pattern) Match(host string) bool {
+	switch {
+	case p.any:
+		return true
+	case p.re != nil:
+		return p.re.MatchString(host)
+	case p.wildcard:
+		return host == p.host || strings.HasSuffix(host, "."+p.host)
+	default:
+		return host == p.host
+	}
+}
+
+// Matcher is a compiled allow list
+type Matcher struct {
+	patterns []Pattern
+}
+
+// Compile parses an allow list. Invalid entries are returned as errors and
+// left out of the matcher, so one bad entry never widens the list.
+func Compile(list string) (*Matcher, []error) {
+	m := &Matcher{}
+	var errs []error
+	for _, entry := range Split(list) {
+		p, err := ParsePattern(entry)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		m.patterns = append(m.patterns, p)
+	}
+	return m, errs
+}
+
+// Validate reports every invalid entry in an allow list
+func Validate(list string) error {
+	_, errs := Compile(list)
+	if len(errs) == 0 {
+		return nil
+	}
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return fmt.Errorf("%s", strings.Join(msgs, "; "))
+}
+
+// Match reports whether a domain (or URL) matches any entry
+func (m *Matcher) Match(domain string) bool {
+	host, ok := Normalize(domain)
+	if !ok {
+		return false
+	}
+	for _, p := range m.patterns {
+		if p.Match(host) {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	cacheMu sync.RWMutex
+	cache   = make(map[string]*Matcher)
+)
+
+// MatchList matches a domain against an allow-list string, caching the
+// compiled list since the same publisher lists are checked on every request
+func MatchList(domain, list string) bool {
+	cacheMu.RLock()
+	m, ok := cache[list]
+	cacheMu.RUnlock()
+	if !ok {
+		m, _ = Compile(list)
+		cacheMu.Lock()
+		if len(cache) >= maxCachedLists {
+			cache = make(map[string]*Matcher)
+		}
+		cache[list] = m
+		cacheMu.Unlock()
+	}
+	return m.Match(domain)
+}
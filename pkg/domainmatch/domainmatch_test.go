@@ -0,0 +1,100 @@
+package domainmatch
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		list string
+		want []string
+	}{
+		{"a.com|b.com", []string{"a.com", "b.com"}},
+		{"a.com, b.com ,c.com", []string{"a.com", "b.com", "c.com"}},
+		{" a.com | | ", []string{"a.com"}},
+		{`~^(www|m)\.a\.com$|b.com`, []string{`~^(www|m)\.a\.com$`, "b.com"}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := Split(tt.list); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Split(%q) = %v, want %v", tt.list, got, tt.want)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]string{
+		"Example.COM":                    "example.com",
+		"example.com:8443":               "example.com",
+		"https://www.example.com/path?q": "www.example.com",
+		"example.com.":                   "example.com",
+		"bücher.de":                      "xn--bcher-kva.de",
+		"例え.jp":                          "xn--r8jz45g.jp",
+	}
+	for in, want := range tests {
+		if got, ok := Normalize(in); !ok || got != want {
+			t.Errorf("Normalize(%q) = %q, %v; want %q", in, got, ok, want)
+		}
+	}
+	if _, ok := Normalize("  "); ok {
+		t.Error("expected blank domain to be rejected")
+	}
+}
+
+func TestMatchList(t *testing.T) {
+	tests := []struct {
+		domain, list string
+		want         bool
+	}{
+		{"example.com", "example.com", true},
+		{"EXAMPLE.com:443", "example.com", true},
+		{"sub.example.com", "*.example.com", true},
+		{"example.com", "*.example.com", true},
+		{"badexample.com", "*.example.com", false},
+		{"example.com.evil.com", "*.example.com", false},
+		{"b.com", "a.com,b.com", true},
+		{"anything.net", "*", true},
+		{"bbc.co.uk", "*.co.uk", false},
+		{"news.bbc.co.uk", "*.bbc.co.uk", true},
+		{"anything.com", "*.com", false},
+		{"cdn12.example.com", `~cdn\d+\.example\.com`, true},
+		{"cdn12.example.com.evil.com", `~cdn\d+\.example\.com`, false},
+		{"m.example.com", `~(www|m)\.example\.com|other.com`, true},
+		{"xn--bcher-kva.de", "bücher.de", true},
+		{"bücher.de", "*.xn--bcher-kva.de", true},
+		{"", "*", false},
+		{"example.com", "~([", false},
+	}
+	for _, tt := range tests {
+		if got := MatchList(tt.domain, tt.list); got != tt.want {
+			t.Errorf("MatchList(%q, %q) = %v, want %v", tt.domain, tt.list, got, tt.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate("example.com|*.example.org|~^a\\.com$"); err != nil {
+		t.Errorf("expected valid list, got %v", err)
+	}
+	if err := Validate("example.com|*.co.uk|~(["); err == nil {
+		t.Error("expected public-suffix wildcard and bad regex to be reported")
+	}
+}
+
+func TestRegistrableDomain(t *testing.T) {
+	tests := map[string]string{
+		"news.bbc.co.uk":      "bbc.co.uk",
+		"www.example.com":     "example.com",
+		"example.com":         "example.com",
+		"user.github.io":      "user.github.io",
+		"deep.user.github.io": "user.github.io",
+		"co.uk":               "",
+		"com":                 "",
+	}
+	for in, want := range tests {
+		if got := RegistrableDomain(in); got != want {
+			t.Errorf("RegistrableDomain(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
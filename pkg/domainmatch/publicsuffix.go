@@ -0,0 +1,71 @@
+package domainmatch
+
+import "strings"
+
+// multiLabelSuffixes are public suffixes with more than one label. Every
+// single-label TLD is treated as a public suffix. This is a curated subset of
+// the Public Suffix List covering the registries and shared hosting domains
+// seen in publisher traffic.
+var multiLabelSuffixes = map[string]bool{
+	// United Kingdom
+	"co.uk": true, "org.uk": true, "me.uk": true, "ltd.uk": true, "plc.uk": true,
+	"net.uk": true, "ac.uk": true, "gov.uk": true, "sch.uk": true, "nhs.uk": true,
+	// Australia / New Zealand
+	"com.au": true, "net.au": true, "org.au": true, "edu.au": true, "gov.au": true, "id.au": true,
+	"co.nz": true, "net.nz": true, "org.nz": true, "govt.nz": true, "ac.nz": true,
+	// Asia
+	"co.jp": true, "ne.jp": true, "or.jp": true, "ac.jp": true, "go.jp": true, "gr.jp": true,
+	"co.kr": true, "or.kr": true, "ne.kr": true,
+	"com.cn": true, "net.cn": true, "org.cn": true, "gov.cn": true,
+	"com.hk": true, "net.hk": true, "org.hk": true,
+	"com.tw": true, "net.tw": true, "org.tw": true,
+	"com.sg": true, "net.sg": true, "org.sg": true,
+	"co.in": true, "net.in": true, "org.in": true, "firm.in": true, "gen.in": true, "ind.in": true,
+	"co.id": true, "or.id": true, "web.id": true,
+	"com.my": true, "net.my": true, "org.my": true,
+	"com.ph": true, "net.ph": true, "org.ph": true,
+	"com.vn": true, "net.vn": true,
+	"co.th": true, "in.th": true,
+	"com.pk": true, "net.pk": true,
+	"co.il": true, "org.il": true,
+	"com.sa": true, "com.tr": true, "com.ua": true,
+	// Americas
+	"com.br": true, "net.br": true, "org.br": true,
+	"com.mx": true, "org.mx": true, "com.ar": true, "com.co": true, "com.pe": true, "com.ve": true,
+	// Africa
+	"co.za": true, "org.za": true, "com.ng": true, "com.eg": true, "co.ke": true,
+	// Shared hosting (private section of the PSL)
+	"github.io": true, "gitlab.io": true, "herokuapp.com": true, "blogspot.com": true,
+	"appspot.com": true, "cloudfront.net": true, "azurewebsites.net": true,
+	"netlify.app": true, "vercel.app": true, "pages.dev": true, "workers.dev": true,
+	"web.app": true, "firebaseapp.com": true, "s3.amazonaws.com": true,
+}
+
+// IsPublicSuffix reports whether a normalized host is a public suffix, i.e.
+// a name under which unrelated parties can register domains
+func IsPublicSuffix(host string) bool {
+	if host == "" {
+		return false
+	}
+	if !strings.Contains(host, ".") {
+		return true
+	}
+	return multiLabelSuffixes[host]
+}
+
+// RegistrableDomain returns the registrable domain (eTLD+1) of a normalized
+// host, e.g. "news.bbc.co.uk" -> "bbc.co.uk". It returns "" when the host is
+// itself a public suffix. ads.txt files are served from this domain.
+func RegistrableDomain(host string) string {
+	if host == "" || IsPublicSuffix(host) {
+		return ""
+	}
+	labels := strings.Split(host, ".")
+	// Find the longest public suffix, then keep one more label
+	for i := 1; i < len(labels); i++ {
+		if IsPublicSuffix(strings.Join(labels[i:], ".")) {
+			return strings.Join(labels[i-1:], ".")
+		}
+	}
+	return host
+}
@@ -0,0 +1,278 @@
+package featureflags
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/thenexusengine/tne_springwire/pkg/sessionhash"
+)
+
+type staticProvider struct {
+	flags map[string]Flag
+	err   error
+}
+
+func (p *staticProvider) Name() string { return "static" }
+
+func (p *staticProvider) Load(ctx context.Context) (map[string]Flag, error) {
+	return p.flags, p.err
+}
+
+type recordingMetrics struct {
+	evaluations map[string]int
+	refreshes   map[bool]int
+}
+
+func newRecordingMetrics() *recordingMetrics {
+	return &recordingMetrics{evaluations: map[string]int{}, refreshes: map[bool]int{}}
+}
+
+func (m *recordingMetrics) RecordFlagEvaluation(flag string, enabled bool) {
+	m.evaluations[flag]++
+}
+
+func (m *recordingMetrics) RecordFlagRefresh(provider string, success bool) {
+	m.refreshes[success]++
+}
+
+func intPtr(i int) *int { return &i }
+
+func newService(t *testing.T, flags map[string]Flag) (*Service, *recordingMetrics) {
+	t.Helper()
+	m := newRecordingMetrics()
+	s := New(&staticProvider{flags: flags}, m)
+	if err := s.Refresh(context.Background()); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	return s, m
+}
+
+func TestEnabled_Targeting(t *testing.T) {
+	s, m := newService(t, map[string]Flag{
+		"on":       {Enabled: true},
+		"off":      {Enabled: false, Publishers: []string{"pub-1"}},
+		"targeted": {Enabled: true, Publishers: []string{"pub-1"}},
+		"excluded": {Enabled: true, ExcludePublishers: []string{"pub-2"}},
+		"none":     {Enabled: true, Rollout: intPtr(0)},
+	})
+
+	tests := []struct {
+		flag, pub string
+		want      bool
+	}{
+		{"on", "pub-1", true},
+		{"off", "pub-1", false},
+		{"targeted", "pub-1", true},
+		{"targeted", "pub-2", false},
+		{"excluded", "pub-1", true},
+		{"excluded", "pub-2", false},
+		{"none", "pub-1", false},
+	}
+	for _, tt := range tests {
+		if got := s.Enabled(tt.flag, tt.pub); got != tt.want {
+			t.Errorf("Enabled(%q, %q) = %v, want %v", tt.flag, tt.pub, got, tt.want)
+		}
+	}
+	if m.evaluations["targeted"] != 2 {
+		t.Errorf("expected 2 evaluations recorded for targeted, got %d", m.evaluations["targeted"])
+	}
+}
+
+func TestEnabled_PercentageRolloutIsStable(t *testing.T) {
+	s, _ := newService(t, map[string]Flag{"half": {Enabled: true, Rollout: intPtr(50)}})
+
+	on := 0
+	for i := 0; i < 1000; i++ {
+		pub := "pub-" + string(rune('a'+i%26)) + string(rune('a'+i/26))
+		first := s.Enabled("half", pub)
+		if s.Enabled("half", pub) != first {
+			t.Fatalf("rollout not stable for %s", pub)
+		}
+		if first {
+			on++
+		}
+	}
+	if on < 400 || on > 600 {
+		t.Errorf("expected ~50%% of publishers enabled, got %d/1000", on)
+	}
+}
+
+func TestEnabledFor_SessionRollout(t *testing.T) {
+	s, _ := newService(t, map[string]Flag{
+		"experiment": {Enabled: true, Rollout: intPtr(50), BucketBy: BucketSession},
+		"excluded":   {Enabled: true, Rollout: intPtr(100), BucketBy: BucketSession, ExcludePublishers: []string{"pub-2"}},
+	})
+	s.SetSessionHasher(sessionhash.New(sessionhash.Config{Salt: "test"}))
+	replica, _ := newService(t, map[string]Flag{"experiment": {Enabled: true, Rollout: intPtr(50), BucketBy: BucketSession}})
+	replica.SetSessionHasher(sessionhash.New(sessionhash.Config{Salt: "test"}))
+
+	on := 0
+	for i := 0; i < 1000; i++ {
+		session := "session-" + string(rune('a'+i%26)) + string(rune('a'+i/26))
+		got := s.EnabledFor("experiment", "pub-1", session)
+		if replica.EnabledFor("experiment", "pub-1", session) != got {
+			t.Fatalf("replicas disagree on %s", session)
+		}
+		if got {
+			on++
+		}
+	}
+	if on < 400 || on > 600 {
+		t.Errorf("expected ~50%% of sessions enabled, got %d/1000", on)
+	}
+
+	if s.EnabledFor("excluded", "pub-2", "session-a") {
+		t.Error("expected publisher exclusions to apply to session rollouts")
+	}
+	if s.EnabledFor("experiment", "pub-1", "") != s.Enabled("experiment", "pub-1") {
+		t.Error("expected requests without a session to bucket by publisher")
+	}
+}
+
+func TestEnabled_Defaults(t *testing.T) {
+	s, _ := newService(t, map[string]Flag{})
+	if !s.Enabled(IDRSelection, "pub-1") {
+		t.Error("expected IDR selection to default on")
+	}
+	if !s.Enabled(FloorEngine, "pub-1") {
+		t.Error("expected floor engine to default on")
+	}
+	if s.Enabled("undefined_flag", "pub-1") {
+		t.Error("expected flags without a default to be off")
+	}
+
+	var nilService *Service
+	if !nilService.Enabled(IDRSelection, "pub-1") {
+		t.Error("expected nil service to use defaults")
+	}
+}
+
+func TestRefresh_KeepsFlagsOnError(t *testing.T) {
+	p := &staticProvider{flags: map[string]Flag{FloorEngine: {Enabled: true}}}
+	m := newRecordingMetrics()
+	s := New(p, m)
+	if err := s.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	p.err = errors.New("provider down")
+	if err := s.Refresh(context.Background()); err == nil {
+		t.Fatal("expected refresh error")
+	}
+	if !s.Enabled(FloorEngine, "pub-1") {
+		t.Error("expected previous flags to stay in effect")
+	}
+	if m.refreshes[true] != 1 || m.refreshes[false] != 1 {
+		t.Errorf("expected one successful and one failed refresh, got %v", m.refreshes)
+	}
+}
+
+func TestFileProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flags.json")
+	data := `{"floor_engine_v2": {"enabled": true, "publishers": ["pub-1"]}}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := NewProvider(Config{Provider: ProviderFile, File: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := New(p, nil)
+	if err := s.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !s.Enabled(FloorEngine, "pub-1") || s.Enabled(FloorEngine, "pub-2") {
+		t.Error("expected floor engine only for pub-1")
+	}
+}
+
+func TestUnleashProvider(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/client/features" || r.Header.Get("Authorization") != "secret" {
+			http.Error(w, "bad request", http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"features": [
+			{"name": "everyone", "enabled": true, "strategies": [{"name": "default"}]},
+			{"name": "listed", "enabled": true, "strategies": [{"name": "userWithId", "parameters": {"userIds": "pub-1, pub-2"}}]},
+			{"name": "rollout", "enabled": true, "strategies": [{"name": "flexibleRollout", "parameters": {"rollout": "0"}}]},
+			{"name": "disabled", "enabled": false, "strategies": [{"name": "default"}]}
+		]}`))
+	}))
+	defer srv.Close()
+
+	p, err := NewProvider(Config{Provider: ProviderUnleash, URL: srv.URL, APIKey: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := New(p, nil)
+	if err := s.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !s.Enabled("everyone", "pub-9") {
+		t.Error("expected default strategy to enable everyone")
+	}
+	if !s.Enabled("listed", "pub-2") || s.Enabled("listed", "pub-9") {
+		t.Error("expected userWithId to target listed publishers only")
+	}
+	if s.Enabled("rollout", "pub-1") {
+		t.Error("expected 0% rollout to be off")
+	}
+	if s.Enabled("disabled", "pub-1") {
+		t.Error("expected disabled feature to be off")
+	}
+}
+
+func TestLaunchDarklyProvider(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/sdk/latest-flags" || r.Header.Get("Authorization") != "sdk-key" {
+			http.Error(w, "bad request", http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{
+			"shadow_bidders": {"on": true, "variations": [true, false],
+				"targets": [{"values": ["pub-1"], "variation": 0}, {"values": ["pub-2"], "variation": 1}],
+				"fallthrough": {"variation": 1}},
+			"protobuf_path": {"on": true, "variations": [true, false],
+				"fallthrough": {"rollout": {"variations": [{"variation": 0, "weight": 100000}, {"variation": 1, "weight": 0}]}}},
+			"banner_color": {"on": true, "variations": ["red", "blue"]}
+		}`))
+	}))
+	defer srv.Close()
+
+	p, err := NewProvider(Config{Provider: ProviderLaunchDarkly, URL: srv.URL, APIKey: "sdk-key"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := New(p, nil)
+	if err := s.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !s.Enabled("shadow_bidders", "pub-1") || s.Enabled("shadow_bidders", "pub-2") || s.Enabled("shadow_bidders", "pub-3") {
+		t.Error("expected targets and fallthrough to be honored")
+	}
+	if !s.Enabled("protobuf_path", "pub-3") {
+		t.Error("expected 100% rollout to be on")
+	}
+}
+
+func TestNewProvider_Errors(t *testing.T) {
+	for _, cfg := range []Config{
+		{Provider: ProviderFile},
+		{Provider: ProviderUnleash},
+		{Provider: ProviderLaunchDarkly},
+		{Provider: "split"},
+	} {
+		if _, err := NewProvider(cfg); err == nil {
+			t.Errorf("expected error for %+v", cfg)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package featureflags
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Supported providers
+const (
+	ProviderFile         = "file"
+	ProviderUnleash      = "unleash"
+	ProviderLaunchDarkly = "launchdarkly"
+)
+
+// maxProviderResponse caps how much of a provider response is read (4MB)
+const maxProviderResponse = 4 << 20
+
+// Provider loads flag definitions
+type Provider interface {
+	Name() string
+	Load(ctx context.Context) (map[string]Flag, error)
+}
+
+// Config selects and configures a provider
+type Config struct {
+	Provider string
+	File     string
+	URL      string
+	APIKey   string
+	Timeout  time.Duration
+}
+
+// NewProvider builds the provider named in cfg
+func NewProvider(cfg Config) (Provider, error) {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	client := &http.Client{Timeout: timeout}
+
+	switch cfg.Provider {
+	case ProviderFile, "":
+		if cfg.File == "" {
+			return nil, fmt.Errorf("feature flag file is required for the file provider")
+		}
+		return &FileProvider{Path: cfg.File}, nil
+	case ProviderUnleash:
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("feature flag URL is required for unleash")
+		}
+		return &UnleashProvider{URL: cfg.URL, APIKey: cfg.APIKey, Client: client}, nil
+	case ProviderLaunchDarkly:
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("feature flag URL is required for launchdarkly")
+		}
+		return &LaunchDarklyProvider{URL: cfg.URL, SDKKey: cfg.APIKey, Client: client}, nil
+	default:
+		return nil, fmt.Errorf("unknown feature flag provider %q", cfg.Provider)
+	}
+}
+
+// FileProvider reads flags from a JSON file mapping flag names to Flag
+// definitions:
+//
+//	{"floor_engine_v2": {"enabled": true, "publishers": ["pub-1"], "rollout": 10}}
+type FileProvider struct {
+	Path string
+}
+
+// Name implements Provider
+func (p *FileProvider) Name() string { return ProviderFile }
+
+// Load implements Provider
+func (p *FileProvider) Load(ctx context.Context) (map[string]Flag, error) {
+	data, err := os.ReadFile(p.Path)
+	if err != nil {
+		return nil, fmt.Errorf("read feature flags: %w", err)
+	}
+	var flags map[string]Flag
+	if err := json.Unmarshal(data, &flags); err != nil {
+		return nil, fmt.Errorf("parse feature flags %s: %w", p.Path, err)
+	}
+	return flags, nil
+}
+
+// fetchJSON GETs a provider endpoint and decodes the JSON body into out
+func fetchJSON(ctx context.Context, client *http.Client, url, authorization string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: unexpected status %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, maxProviderResponse)).Decode(out)
+}
+
+// UnleashProvider reads flags from the Unleash client API. Strategies map to
+// Flag targeting with the publisher ID as the user ID: "default" enables the
+// flag for everyone, "userWithId" lists publishers, and "flexibleRollout" /
+// "gradualRolloutUserId" set the rollout percentage. Other strategies are
+// ignored.
+type UnleashProvider struct {
+	URL    string
+	APIKey string
+	Client *http.Client
+}
+
+type unleashFeatures struct {
+	Features []struct {
+		Name       string `json:"name"`
+		Enabled    bool   `json:"enabled"`
+		Strategies []struct {
+			Name       string            `json:"name"`
+			Parameters map[string]string `json:"parameters"`
+		} `json:"strategies"`
+	} `json:"features"`
+}
+
+// Name implements Provider
+func (p *UnleashProvider) Name() string { return ProviderUnleash }
+
+// Load implements Provider
+func (p *UnleashProvider) Load(ctx context.Context) (map[string]Flag, error) {
+	var body unleashFeatures
+	url := strings.TrimSuffix(p.URL, "/") + "/api/client/features"
+	if err := fetchJSON(ctx, p.Client, url, p.APIKey, &body); err != nil {
+		return nil, fmt.Errorf("unleash: %w", err)
+	}
+
+	flags := make(map[string]Flag, len(body.Features))
+	for _, feature := range body.Features {
+		flag := Flag{Enabled: feature.Enabled}
+		rollout := 0
+		if len(feature.Strategies) == 0 {
+			rollout = 100
+		}
+		for _, s := range feature.Strategies {
+			switch s.Name {
+			case "default":
+				rollout = 100
+			case "userWithId":
+				for _, id := range strings.Split(s.Parameters["userIds"], ",") {
+					if id = strings.TrimSpace(id); id != "" {
+						flag.Publishers = append(flag.Publishers, id)
+					}
+				}
+			case "flexibleRollout", "gradualRolloutUserId":
+				pct, err := strconv.Atoi(firstNonEmpty(s.Parameters["rollout"], s.Parameters["percentage"]))
+				if err == nil && pct > rollout {
+					rollout = pct
+				}
+			}
+		}
+		flag.Rollout = &rollout
+		flags[feature.Name] = flag
+	}
+	return flags, nil
+}
+
+// LaunchDarklyProvider reads boolean flags from a LaunchDarkly relay proxy or
+// the server-side SDK flags endpoint, with the publisher ID as context key.
+// Individual targets and the fallthrough (fixed or percentage rollout) are
+// honored; targeting rules are not evaluated.
+type LaunchDarklyProvider struct {
+	URL    string
+	SDKKey string
+	Client *http.Client
+}
+
+type ldFlag struct {
+	On         bool   `json:"on"`
+	Variations []bool `json:"variations"`
+	Targets    []struct {
+		Values    []string `json:"values"`
+		Variation int      `json:"variation"`
+	} `json:"targets"`
+	Fallthrough struct {
+		Variation *int `json:"variation"`
+		Rollout   *struct {
+			Variations []struct {
+				Variation int `json:"variation"`
+				Weight    int `json:"weight"` // thousandths of a percent
+			} `json:"variations"`
+		} `json:"rollout"`
+	} `json:"fallthrough"`
+}
+
+// Name implements Provider
+func (p *LaunchDarklyProvider) Name() string { return ProviderLaunchDarkly }
+
+// Load implements Provider
+func (p *LaunchDarklyProvider) Load(ctx context.Context) (map[string]Flag, error) {
+	var raw map[string]json.RawMessage
+	url := strings.TrimSuffix(p.URL, "/") + "/sdk/latest-flags"
+	if err := fetchJSON(ctx, p.Client, url, p.SDKKey, &raw); err != nil {
+		return nil, fmt.Errorf("launchdarkly: %w", err)
+	}
+
+	flags := make(map[string]Flag, len(raw))
+	for key, data := range raw {
+		var ld ldFlag
+		if err := json.Unmarshal(data, &ld); err != nil {
+			// Not a boolean flag; leave it to its default
+			continue
+		}
+		variation := func(i int) bool {
+			return i >= 0 && i < len(ld.Variations) && ld.Variations[i]
+		}
+
+		flag := Flag{Enabled: ld.On}
+		for _, t := range ld.Targets {
+			if variation(t.Variation) {
+				flag.Publishers = append(flag.Publishers, t.Values...)
+			} else {
+				flag.ExcludePublishers = append(flag.ExcludePublishers, t.Values...)
+			}
+		}
+
+		rollout := 0
+		switch {
+		case ld.Fallthrough.Variation != nil:
+			if variation(*ld.Fallthrough.Variation) {
+				rollout = 100
+			}
+		case ld.Fallthrough.Rollout != nil:
+			weight := 0
+			for _, v := range ld.Fallthrough.Rollout.Variations {
+				if variation(v.Variation) {
+					weight += v.Weight
+				}
+			}
+			rollout = weight / 1000
+		}
+		flag.Rollout = &rollout
+		flags[key] = flag
+	}
+	return flags, nil
+}
+
+// firstNonEmpty returns the first non-empty string
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
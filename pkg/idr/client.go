@@ -95,6 +95,9 @@ type MinimalRequest struct {
 	Imp        []MinimalImp `json:"imp"`
 	Geo        *MinimalGeo  `json:"geo,omitempty"`
 	DeviceType string       `json:"device_type,omitempty"`
+	// ExplorationSeed replaces IDR's random draw when deciding whether to
+	// explore, so a session gets the same selection on every replica
+	ExplorationSeed string `json:"exploration_seed,omitempty"`
 }
 
 // MinimalSite contains essential site info for partner selection
@@ -10,6 +10,8 @@ import (
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/thenexusengine/tne_springwire/pkg/buildinfo"
 )
 
 const (
@@ -45,6 +47,7 @@ type EventRecorder struct {
 // BidEvent represents a bid event to record
 type BidEvent struct {
 	AuctionID   string   `json:"auction_id"`
+	RequestID   string   `json:"request_id,omitempty"` // Edge X-Request-ID for end-to-end tracing
 	BidderCode  string   `json:"bidder_code"`
 	EventType   string   `json:"event_type"` // "bid_response" or "win"
 	LatencyMs   float64  `json:"latency_ms,omitempty"`
@@ -60,6 +63,11 @@ type BidEvent struct {
 	TimedOut    bool     `json:"timed_out,omitempty"`
 	HadError    bool     `json:"had_error,omitempty"`
 	ErrorMsg    string   `json:"error_message,omitempty"`
+
+	// Build that recorded the event, set by RecordEvent
+	PBSVersion   string `json:"pbs_version,omitempty"`
+	PBSGitSHA    string `json:"pbs_git_sha,omitempty"`
+	AdaptersHash string `json:"adapters_hash,omitempty"`
 }
 
 // NewEventRecorder creates a new event recorder with a bounded worker pool
@@ -177,8 +185,18 @@ func (r *EventRecorder) RecordBidResponse(
 		ErrorMsg:    errorMsg,
 	}
 
+	r.RecordEvent(event)
+}
+
+// RecordEvent buffers a fully populated event, flushing when the buffer is full
+func (r *EventRecorder) RecordEvent(event BidEvent) {
 	r.totalEvents.Add(1)
 
+	build := buildinfo.Get()
+	event.PBSVersion = build.Version
+	event.PBSGitSHA = build.GitSHA
+	event.AdaptersHash = build.AdapterRegistryHash
+
 	r.mu.Lock()
 	r.buffer = append(r.buffer, event)
 	shouldFlush := len(r.buffer) >= r.bufferSize
@@ -230,34 +248,7 @@ func (r *EventRecorder) RecordWin(
 		PublisherID: publisherID,
 	}
 
-	r.totalEvents.Add(1)
-
-	r.mu.Lock()
-	r.buffer = append(r.buffer, event)
-	shouldFlush := len(r.buffer) >= r.bufferSize
-	var eventsToFlush []BidEvent
-	if shouldFlush {
-		// Copy buffer before unlock to prevent memory corruption
-		eventsToFlush = make([]BidEvent, len(r.buffer))
-		copy(eventsToFlush, r.buffer)
-		r.buffer = make([]BidEvent, 0, r.bufferSize)
-	}
-	r.mu.Unlock()
-
-	// Queue flush if buffer was full (non-blocking send)
-	if eventsToFlush != nil {
-		batchSize := int64(len(eventsToFlush))
-		select {
-		case r.flushQueue <- eventsToFlush:
-			// Queued successfully
-			r.flushedEvents.Add(batchSize)
-		default:
-			// Queue full - drop events rather than block or leak goroutines
-			// Track dropped events for monitoring/alerting
-			r.droppedEvents.Add(batchSize)
-			r.droppedBatches.Add(1)
-		}
-	}
+	r.RecordEvent(event)
 }
 
 // Flush sends buffered events to the IDR service synchronously
@@ -8,6 +8,8 @@ import (
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/thenexusengine/tne_springwire/pkg/buildinfo"
 )
 
 func TestNewEventRecorder(t *testing.T) {
@@ -167,6 +169,42 @@ func TestRecordWin(t *testing.T) {
 	}
 }
 
+func TestRecordEvent_IncludesRequestID(t *testing.T) {
+	var received []BidEvent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Events []BidEvent `json:"events"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		received = body.Events
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	recorder := NewEventRecorder(server.URL, 100)
+	defer recorder.Close()
+
+	recorder.RecordEvent(BidEvent{
+		AuctionID:  "auction-123",
+		RequestID:  "edge-req-1",
+		BidderCode: "appnexus",
+		EventType:  "bid_response",
+	})
+
+	if err := recorder.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if len(received) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(received))
+	}
+	if received[0].RequestID != "edge-req-1" {
+		t.Errorf("Expected request_id edge-req-1, got %q", received[0].RequestID)
+	}
+	if received[0].PBSVersion != buildinfo.Version {
+		t.Errorf("Expected pbs_version %q, got %q", buildinfo.Version, received[0].PBSVersion)
+	}
+}
+
 func TestFlush_EmptyBuffer(t *testing.T) {
 	recorder := NewEventRecorder("http://localhost:8000", 100)
 	defer recorder.Close()
@@ -0,0 +1,91 @@
+// Package kv abstracts the shared key-value state used by PBS (API keys,
+// publisher config, warm cache snapshots) so deployments without Redis can
+// run the full feature set on Memcached or, for single instances, in memory
+package kv
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/pkg/redis"
+)
+
+// Backend names accepted by Open
+const (
+	BackendRedis     = "redis"
+	BackendMemcached = "memcached"
+	BackendMemory    = "memory"
+)
+
+// Store is a key-value store with string values and flat hashes.
+// Missing keys and fields read as "" with a nil error, matching the Redis client.
+type Store interface {
+	Get(ctx context.Context, key string) (string, error)
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
+	// SetNX sets a value only if the key doesn't exist, reporting whether it did
+	SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error)
+	Del(ctx context.Context, keys ...string) error
+	HGet(ctx context.Context, key, field string) (string, error)
+	HGetAll(ctx context.Context, key string) (map[string]string, error)
+	HSet(ctx context.Context, key, field string, value interface{}) error
+	HDel(ctx context.Context, key string, fields ...string) error
+	Ping(ctx context.Context) error
+	Close() error
+}
+
+// Redis client satisfies Store directly
+var _ Store = (*redis.Client)(nil)
+
+// Degrader is implemented by stores that track their own error budget, like
+// the Redis client
+type Degrader interface {
+	Degraded() bool
+}
+
+// IsDegraded reports whether store is over its error budget. Stores that
+// don't track one never are.
+func IsDegraded(store interface{}) bool {
+	d, ok := store.(Degrader)
+	return ok && d.Degraded()
+}
+
+// Config selects and configures a KV backend
+type Config struct {
+	Backend          string   // redis (default), memcached or memory
+	RedisURL         string   // Redis URL for the redis backend
+	MemcachedServers []string // host:port list for the memcached backend
+}
+
+// Open creates the configured Store. It returns nil without error when the
+// redis backend is selected but no URL is configured.
+func Open(cfg Config) (Store, error) {
+	switch strings.ToLower(strings.TrimSpace(cfg.Backend)) {
+	case "", BackendRedis:
+		if cfg.RedisURL == "" {
+			return nil, nil
+		}
+		return redis.New(cfg.RedisURL)
+	case BackendMemcached:
+		return NewMemcached(cfg.MemcachedServers)
+	case BackendMemory:
+		return NewMemory(), nil
+	default:
+		return nil, fmt.Errorf("unknown KV backend %q", cfg.Backend)
+	}
+}
+
+// toString converts a value passed to Set/HSet to its stored form
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,116 @@
+package kv
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/thenexusengine/tne_springwire/pkg/redis"
+)
+
+// testStore exercises the Store contract shared by every backend
+func testStore(t *testing.T, s Store) {
+	t.Helper()
+	ctx := context.Background()
+
+	if v, err := s.Get(ctx, "missing"); err != nil || v != "" {
+		t.Errorf("expected empty value for missing key, got %q, %v", v, err)
+	}
+
+	if err := s.Set(ctx, "snapshot", []byte("payload"), time.Minute); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if v, _ := s.Get(ctx, "snapshot"); v != "payload" {
+		t.Errorf("expected payload, got %q", v)
+	}
+	if err := s.Del(ctx, "snapshot"); err != nil {
+		t.Fatalf("Del failed: %v", err)
+	}
+	if v, _ := s.Get(ctx, "snapshot"); v != "" {
+		t.Errorf("expected deleted key to be empty, got %q", v)
+	}
+
+	if ok, err := s.SetNX(ctx, "claim", "first", time.Minute); err != nil || !ok {
+		t.Fatalf("expected SetNX to claim a new key, got %v, %v", ok, err)
+	}
+	if ok, err := s.SetNX(ctx, "claim", "second", time.Minute); err != nil || ok {
+		t.Errorf("expected SetNX not to overwrite an existing key, got %v, %v", ok, err)
+	}
+	if v, _ := s.Get(ctx, "claim"); v != "first" {
+		t.Errorf("expected the first claim kept, got %q", v)
+	}
+
+	if err := s.HSet(ctx, "publishers", "pub1", "example.com"); err != nil {
+		t.Fatalf("HSet failed: %v", err)
+	}
+	if err := s.HSet(ctx, "publishers", "pub2", "*.example.org"); err != nil {
+		t.Fatalf("HSet failed: %v", err)
+	}
+	if v, _ := s.HGet(ctx, "publishers", "pub1"); v != "example.com" {
+		t.Errorf("expected example.com, got %q", v)
+	}
+	if v, err := s.HGet(ctx, "publishers", "missing"); err != nil || v != "" {
+		t.Errorf("expected empty missing field, got %q, %v", v, err)
+	}
+	all, err := s.HGetAll(ctx, "publishers")
+	if err != nil || len(all) != 2 || all["pub2"] != "*.example.org" {
+		t.Errorf("unexpected HGetAll result %v, %v", all, err)
+	}
+
+	if err := s.HDel(ctx, "publishers", "pub1"); err != nil {
+		t.Fatalf("HDel failed: %v", err)
+	}
+	all, _ = s.HGetAll(ctx, "publishers")
+	if len(all) != 1 {
+		t.Errorf("expected 1 field after HDel, got %v", all)
+	}
+	if all, err := s.HGetAll(ctx, "nohash"); err != nil || len(all) != 0 {
+		t.Errorf("expected empty hash, got %v, %v", all, err)
+	}
+
+	if err := s.Ping(ctx); err != nil {
+		t.Errorf("Ping failed: %v", err)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemory())
+}
+
+func TestMemoryStore_Expiry(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	m := NewMemory()
+	m.now = func() time.Time { return now }
+
+	m.Set(context.Background(), "k", "v", time.Second)
+	now = now.Add(2 * time.Second)
+	if v, _ := m.Get(context.Background(), "k"); v != "" {
+		t.Errorf("expected expired key to be empty, got %q", v)
+	}
+}
+
+func TestRedisStore(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client, err := redis.New("redis://" + mr.Addr())
+	if err != nil {
+		t.Fatalf("redis.New failed: %v", err)
+	}
+	defer client.Close()
+	testStore(t, client)
+}
+
+func TestOpen(t *testing.T) {
+	if s, err := Open(Config{}); err != nil || s != nil {
+		t.Errorf("expected no store without Redis URL, got %v, %v", s, err)
+	}
+	if s, err := Open(Config{Backend: BackendMemory}); err != nil || s == nil {
+		t.Errorf("expected memory store, got %v, %v", s, err)
+	}
+	if _, err := Open(Config{Backend: BackendMemcached}); err == nil {
+		t.Error("expected error for memcached without servers")
+	}
+	if _, err := Open(Config{Backend: "dynamo"}); err == nil {
+		t.Error("expected error for unknown backend")
+	}
+}
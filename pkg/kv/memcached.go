@@ -0,0 +1,438 @@
+package kv
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/pkg/deadline"
+)
+
+const (
+	// memcachedMaxKeyLength is the protocol's key length limit
+	memcachedMaxKeyLength = 250
+	// memcachedMaxRelativeExpiry is the largest expiry memcached treats as relative;
+	// longer expirations must be sent as a unix timestamp
+	memcachedMaxRelativeExpiry = 30 * 24 * time.Hour
+	// memcachedCASRetries bounds read-modify-write retries for hash updates
+	memcachedCASRetries = 10
+	// memcachedPoolSize is the number of idle connections kept per server
+	memcachedPoolSize = 16
+	// memcachedDialTimeout bounds connection setup
+	memcachedDialTimeout = 2 * time.Second
+	// memcachedIOTimeout bounds a command when the context has no deadline
+	memcachedIOTimeout = 1 * time.Second
+)
+
+// errMemcachedProtocol marks responses that leave the connection unusable
+var errMemcachedProtocol = errors.New("memcached protocol error")
+
+// Memcached is a Store backed by one or more memcached servers using the text
+// protocol. Keys are sharded across servers by CRC32. Hashes are stored as a
+// JSON object under the key and updated with gets/cas, so concurrent HSet
+// calls on the same hash don't lose writes.
+type Memcached struct {
+	servers []*memcachedServer
+}
+
+// memcachedServer is a pool of connections to one server
+type memcachedServer struct {
+	addr string
+	pool chan *memcachedConn
+}
+
+// memcachedConn is a buffered connection to a server
+type memcachedConn struct {
+	nc net.Conn
+	rw *bufio.ReadWriter
+}
+
+// NewMemcached creates a store for the given host:port servers. Connections
+// are dialed lazily.
+func NewMemcached(servers []string) (*Memcached, error) {
+	m := &Memcached{}
+	for _, addr := range servers {
+		if addr = strings.TrimSpace(addr); addr == "" {
+			continue
+		}
+		m.servers = append(m.servers, &memcachedServer{
+			addr: addr,
+			pool: make(chan *memcachedConn, memcachedPoolSize),
+		})
+	}
+	if len(m.servers) == 0 {
+		return nil, fmt.Errorf("no memcached servers configured")
+	}
+	return m, nil
+}
+
+// serverFor picks the server owning a key
+func (m *Memcached) serverFor(key string) *memcachedServer {
+	return m.servers[crc32.ChecksumIEEE([]byte(key))%uint32(len(m.servers))]
+}
+
+// Get gets a string value, returning "" when the key doesn't exist
+func (m *Memcached) Get(ctx context.Context, key string) (string, error) {
+	value, _, _, err := m.gets(ctx, key)
+	return string(value), deadline.Observe(ctx, deadline.DependencyMemcached, err)
+}
+
+// Set sets a string value with an expiration (0 = no expiration)
+func (m *Memcached) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	_, err := m.store(ctx, "set", key, []byte(toString(value)), expiration, 0)
+	return deadline.Observe(ctx, deadline.DependencyMemcached, err)
+}
+
+// SetNX sets a string value only if the key doesn't exist, using "add"
+func (m *Memcached) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	stored, err := m.store(ctx, "add", key, []byte(toString(value)), expiration, 0)
+	return stored, deadline.Observe(ctx, deadline.DependencyMemcached, err)
+}
+
+// Del deletes keys
+func (m *Memcached) Del(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		if err := m.delete(ctx, key); err != nil {
+			return deadline.Observe(ctx, deadline.DependencyMemcached, err)
+		}
+	}
+	return nil
+}
+
+// HGet gets a hash field value
+func (m *Memcached) HGet(ctx context.Context, key, field string) (string, error) {
+	hash, _, _, err := m.getHash(ctx, key)
+	if err != nil {
+		return "", deadline.Observe(ctx, deadline.DependencyMemcached, err)
+	}
+	return hash[field], nil
+}
+
+// HGetAll gets all fields and values from a hash
+func (m *Memcached) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	hash, _, _, err := m.getHash(ctx, key)
+	if err != nil {
+		return nil, deadline.Observe(ctx, deadline.DependencyMemcached, err)
+	}
+	if hash == nil {
+		hash = make(map[string]string)
+	}
+	return hash, nil
+}
+
+// HSet sets a hash field value
+func (m *Memcached) HSet(ctx context.Context, key, field string, value interface{}) error {
+	v := toString(value)
+	err := m.updateHash(ctx, key, func(hash map[string]string) bool {
+		hash[field] = v
+		return true
+	})
+	return deadline.Observe(ctx, deadline.DependencyMemcached, err)
+}
+
+// HDel deletes hash fields
+func (m *Memcached) HDel(ctx context.Context, key string, fields ...string) error {
+	err := m.updateHash(ctx, key, func(hash map[string]string) bool {
+		changed := false
+		for _, f := range fields {
+			if _, ok := hash[f]; ok {
+				delete(hash, f)
+				changed = true
+			}
+		}
+		return changed
+	})
+	return deadline.Observe(ctx, deadline.DependencyMemcached, err)
+}
+
+// Ping checks every server responds to the version command
+func (m *Memcached) Ping(ctx context.Context) error {
+	for _, s := range m.servers {
+		err := s.do(ctx, func(c *memcachedConn) error {
+			if _, err := c.rw.WriteString("version\r\n"); err != nil {
+				return err
+			}
+			line, err := c.command()
+			if err != nil {
+				return err
+			}
+			if !strings.HasPrefix(line, "VERSION ") {
+				return fmt.Errorf("%w: unexpected version reply %q", errMemcachedProtocol, line)
+			}
+			return nil
+		})
+		if err != nil {
+			return deadline.Observe(ctx, deadline.DependencyMemcached, fmt.Errorf("memcached %s: %w", s.addr, err))
+		}
+	}
+	return nil
+}
+
+// Close closes all pooled connections
+func (m *Memcached) Close() error {
+	for _, s := range m.servers {
+	drain:
+		for {
+			select {
+			case c := <-s.pool:
+				c.nc.Close()
+			default:
+				break drain
+			}
+		}
+	}
+	return nil
+}
+
+// getHash loads a hash and its CAS token; found is false for missing keys
+func (m *Memcached) getHash(ctx context.Context, key string) (map[string]string, uint64, bool, error) {
+	data, cas, found, err := m.gets(ctx, key)
+	if err != nil || !found {
+		return nil, 0, found, err
+	}
+	var hash map[string]string
+	if err := json.Unmarshal(data, &hash); err != nil {
+		return nil, 0, false, fmt.Errorf("key %q does not hold a hash: %w", key, err)
+	}
+	if hash == nil {
+		hash = make(map[string]string)
+	}
+	return hash, cas, true, nil
+}
+
+// updateHash applies a read-modify-write to a hash, retrying on CAS conflicts.
+// update returns false when it made no change.
+func (m *Memcached) updateHash(ctx context.Context, key string, update func(map[string]string) bool) error {
+	for attempt := 0; attempt < memcachedCASRetries; attempt++ {
+		hash, cas, found, err := m.getHash(ctx, key)
+		if err != nil {
+			return err
+		}
+		if !found {
+			hash = make(map[string]string)
+		}
+		if !update(hash) {
+			return nil
+		}
+		data, err := json.Marshal(hash)
+		if err != nil {
+			return err
+		}
+
+		// "add" only succeeds if nobody created the key meanwhile
+		cmd := "cas"
+		if !found {
+			cmd = "add"
+		}
+		stored, err := m.store(ctx, cmd, key, data, 0, cas)
+		if err != nil {
+			return err
+		}
+		if stored {
+			return nil
+		}
+	}
+	return fmt.Errorf("memcached: too many concurrent updates to %q", key)
+}
+
+// gets fetches a value and its CAS token
+func (m *Memcached) gets(ctx context.Context, key string) (value []byte, cas uint64, found bool, err error) {
+	if err := validateMemcachedKey(key); err != nil {
+		return nil, 0, false, err
+	}
+	err = m.serverFor(key).do(ctx, func(c *memcachedConn) error {
+		if _, err := fmt.Fprintf(c.rw, "gets %s\r\n", key); err != nil {
+			return err
+		}
+		line, err := c.command()
+		if err != nil {
+			return err
+		}
+		if line == "END" {
+			return nil
+		}
+
+		// VALUE <key> <flags> <bytes> <cas>
+		parts := strings.Fields(line)
+		if len(parts) != 5 || parts[0] != "VALUE" {
+			return fmt.Errorf("%w: unexpected gets reply %q", errMemcachedProtocol, line)
+		}
+		size, err := strconv.Atoi(parts[3])
+		if err != nil {
+			return fmt.Errorf("%w: bad value length %q", errMemcachedProtocol, parts[3])
+		}
+		if cas, err = strconv.ParseUint(parts[4], 10, 64); err != nil {
+			return fmt.Errorf("%w: bad cas token %q", errMemcachedProtocol, parts[4])
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(c.rw, buf); err != nil {
+			return err
+		}
+		value = buf[:size]
+		found = true
+
+		if end, err := c.readLine(); err != nil || end != "END" {
+			return fmt.Errorf("%w: missing END after value", errMemcachedProtocol)
+		}
+		return nil
+	})
+	return value, cas, found, err
+}
+
+// store runs set/add/cas and reports whether the value was stored
+func (m *Memcached) store(ctx context.Context, cmd, key string, value []byte, expiration time.Duration, cas uint64) (bool, error) {
+	if err := validateMemcachedKey(key); err != nil {
+		return false, err
+	}
+	exptime := memcachedExptime(expiration)
+	stored := false
+	err := m.serverFor(key).do(ctx, func(c *memcachedConn) error {
+		if cmd == "cas" {
+			fmt.Fprintf(c.rw, "cas %s 0 %d %d %d\r\n", key, exptime, len(value), cas)
+		} else {
+			fmt.Fprintf(c.rw, "%s %s 0 %d %d\r\n", cmd, key, exptime, len(value))
+		}
+		c.rw.Write(value)
+		if _, err := c.rw.WriteString("\r\n"); err != nil {
+			return err
+		}
+		line, err := c.command()
+		if err != nil {
+			return err
+		}
+		switch line {
+		case "STORED":
+			stored = true
+		case "NOT_STORED", "EXISTS", "NOT_FOUND":
+		default:
+			return fmt.Errorf("%w: unexpected %s reply %q", errMemcachedProtocol, cmd, line)
+		}
+		return nil
+	})
+	return stored, err
+}
+
+// delete removes a key; missing keys are not an error
+func (m *Memcached) delete(ctx context.Context, key string) error {
+	if err := validateMemcachedKey(key); err != nil {
+		return err
+	}
+	return m.serverFor(key).do(ctx, func(c *memcachedConn) error {
+		if _, err := fmt.Fprintf(c.rw, "delete %s\r\n", key); err != nil {
+			return err
+		}
+		line, err := c.command()
+		if err != nil {
+			return err
+		}
+		if line != "DELETED" && line != "NOT_FOUND" {
+			return fmt.Errorf("%w: unexpected delete reply %q", errMemcachedProtocol, line)
+		}
+		return nil
+	})
+}
+
+// do runs fn on a pooled connection. Connections that saw an error are
+// discarded since the stream may be mid-response.
+func (s *memcachedServer) do(ctx context.Context, fn func(*memcachedConn) error) error {
+	c, err := s.conn(ctx)
+	if err != nil {
+		return err
+	}
+
+	dl, ok := ctx.Deadline()
+	if !ok {
+		dl = time.Now().Add(memcachedIOTimeout)
+	}
+	c.nc.SetDeadline(dl)
+
+	if err := fn(c); err != nil {
+		c.nc.Close()
+		return err
+	}
+
+	select {
+	case s.pool <- c:
+	default:
+		c.nc.Close()
+	}
+	return nil
+}
+
+// conn returns an idle connection or dials a new one
+func (s *memcachedServer) conn(ctx context.Context) (*memcachedConn, error) {
+	select {
+	case c := <-s.pool:
+		return c, nil
+	default:
+	}
+	dialer := net.Dialer{Timeout: memcachedDialTimeout}
+	nc, err := dialer.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return nil, err
+	}
+	return &memcachedConn{
+		nc: nc,
+		rw: bufio.NewReadWriter(bufio.NewReader(nc), bufio.NewWriter(nc)),
+	}, nil
+}
+
+// command flushes the pending command and reads the first reply line
+func (c *memcachedConn) command() (string, error) {
+	if err := c.rw.Flush(); err != nil {
+		return "", err
+	}
+	line, err := c.readLine()
+	if err != nil {
+		return "", err
+	}
+	if line == "ERROR" || strings.HasPrefix(line, "CLIENT_ERROR") || strings.HasPrefix(line, "SERVER_ERROR") {
+		return "", fmt.Errorf("%w: %s", errMemcachedProtocol, line)
+	}
+	return line, nil
+}
+
+// readLine reads one CRLF-terminated line
+func (c *memcachedConn) readLine() (string, error) {
+	line, err := c.rw.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// validateMemcachedKey rejects keys the text protocol can't carry
+func validateMemcachedKey(key string) error {
+	if key == "" || len(key) > memcachedMaxKeyLength {
+		return fmt.Errorf("invalid memcached key length %d", len(key))
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] <= ' ' || key[i] == 0x7f {
+			return fmt.Errorf("memcached key %q contains whitespace or control characters", key)
+		}
+	}
+	return nil
+}
+
+// memcachedExptime converts an expiration to the protocol's exptime field
+func memcachedExptime(expiration time.Duration) int64 {
+	if expiration <= 0 {
+		return 0
+	}
+	if expiration > memcachedMaxRelativeExpiry {
+		return time.Now().Add(expiration).Unix()
+	}
+	secs := int64(expiration / time.Second)
+	if expiration%time.Second != 0 {
+		secs++
+	}
+	return secs
+}
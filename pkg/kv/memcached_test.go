@@ -0,0 +1,151 @@
+package kv
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeMemcached is a minimal text-protocol server supporting the commands
+// used by the Memcached store
+type fakeMemcached struct {
+	mu     sync.Mutex
+	values map[string][]byte
+	cas    map[string]uint64
+	next   uint64
+}
+
+func startFakeMemcached(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	f := &fakeMemcached{values: map[string][]byte{}, cas: map[string]uint64{}}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func (f *fakeMemcached) serve(conn net.Conn) {
+	defer conn.Close()
+	rw := bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
+	for {
+		line, err := rw.ReadString('\n')
+		if err != nil {
+			return
+		}
+		parts := strings.Fields(line)
+		if len(parts) == 0 {
+			continue
+		}
+
+		f.mu.Lock()
+		switch parts[0] {
+		case "version":
+			rw.WriteString("VERSION 1.6.0\r\n")
+		case "gets":
+			if v, ok := f.values[parts[1]]; ok {
+				fmt.Fprintf(rw, "VALUE %s 0 %d %d\r\n%s\r\n", parts[1], len(v), f.cas[parts[1]], v)
+			}
+			rw.WriteString("END\r\n")
+		case "delete":
+			if _, ok := f.values[parts[1]]; ok {
+				delete(f.values, parts[1])
+				rw.WriteString("DELETED\r\n")
+			} else {
+				rw.WriteString("NOT_FOUND\r\n")
+			}
+		case "set", "add", "cas":
+			size, _ := strconv.Atoi(parts[4])
+			data := make([]byte, size+2)
+			io.ReadFull(rw, data)
+			key := parts[1]
+			_, exists := f.values[key]
+			switch {
+			case parts[0] == "add" && exists:
+				rw.WriteString("NOT_STORED\r\n")
+			case parts[0] == "cas" && !exists:
+				rw.WriteString("NOT_FOUND\r\n")
+			case parts[0] == "cas" && parts[5] != strconv.FormatUint(f.cas[key], 10):
+				rw.WriteString("EXISTS\r\n")
+			default:
+				f.next++
+				f.values[key] = data[:size]
+				f.cas[key] = f.next
+				rw.WriteString("STORED\r\n")
+			}
+		default:
+			rw.WriteString("ERROR\r\n")
+		}
+		f.mu.Unlock()
+		rw.Flush()
+	}
+}
+
+func TestMemcachedStore(t *testing.T) {
+	m, err := NewMemcached([]string{startFakeMemcached(t), startFakeMemcached(t)})
+	if err != nil {
+		t.Fatalf("NewMemcached failed: %v", err)
+	}
+	defer m.Close()
+	testStore(t, m)
+}
+
+func TestMemcachedStore_ConcurrentHSet(t *testing.T) {
+	m, _ := NewMemcached([]string{startFakeMemcached(t)})
+	defer m.Close()
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := m.HSet(ctx, "hash", strconv.Itoa(i), "v"); err != nil {
+				t.Errorf("HSet failed: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	all, _ := m.HGetAll(ctx, "hash")
+	if len(all) != 5 {
+		t.Errorf("expected 5 fields after concurrent HSet, got %v", all)
+	}
+}
+
+func TestMemcachedStore_InvalidKey(t *testing.T) {
+	m, _ := NewMemcached([]string{"127.0.0.1:1"})
+	if err := m.Set(context.Background(), "has space", "v", 0); err == nil {
+		t.Error("expected error for key with whitespace")
+	}
+}
+
+func TestMemcachedExptime(t *testing.T) {
+	if memcachedExptime(0) != 0 {
+		t.Error("expected 0 for no expiration")
+	}
+	if memcachedExptime(1500*time.Millisecond) != 2 {
+		t.Error("expected sub-second remainder to round up")
+	}
+	if memcachedExptime(60*24*time.Hour) < time.Now().Unix() {
+		t.Error("expected absolute timestamp beyond 30 days")
+	}
+}
@@ -0,0 +1,163 @@
+package kv
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// memoryItem is a string value or a hash with an optional expiry
+type memoryItem struct {
+	value     string
+	hash      map[string]string
+	expiresAt time.Time
+}
+
+// expired reports whether the item has passed its expiry
+func (i *memoryItem) expired(now time.Time) bool {
+	return !i.expiresAt.IsZero() && now.After(i.expiresAt)
+}
+
+// Memory is an in-process Store for single-instance deployments and tests.
+// State is not shared between instances and is lost on restart.
+type Memory struct {
+	mu    sync.RWMutex
+	items map[string]*memoryItem
+	now   func() time.Time
+}
+
+// NewMemory creates an empty in-memory store
+func NewMemory() *Memory {
+	return &Memory{
+		items: make(map[string]*memoryItem),
+		now:   time.Now,
+	}
+}
+
+// lookup returns a live item, or nil. Caller must hold mu.
+func (m *Memory) lookup(key string) *memoryItem {
+	item, ok := m.items[key]
+	if !ok || item.expired(m.now()) {
+		return nil
+	}
+	return item
+}
+
+// Get gets a string value, returning "" when the key doesn't exist
+func (m *Memory) Get(ctx context.Context, key string) (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	item := m.lookup(key)
+	if item == nil {
+		return "", nil
+	}
+	if item.hash != nil {
+		return "", fmt.Errorf("key %q holds a hash", key)
+	}
+	return item.value, nil
+}
+
+// Set sets a string value with an expiration (0 = no expiration)
+func (m *Memory) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	item := &memoryItem{value: toString(value)}
+	if expiration > 0 {
+		item.expiresAt = m.now().Add(expiration)
+	}
+	m.mu.Lock()
+	m.items[key] = item
+	m.mu.Unlock()
+	return nil
+}
+
+// SetNX sets a string value only if the key doesn't exist
+func (m *Memory) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	item := &memoryItem{value: toString(value)}
+	if expiration > 0 {
+		item.expiresAt = m.now().Add(expiration)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.lookup(key) != nil {
+		return false, nil
+	}
+	m.items[key] = item
+	return true, nil
+}
+
+// Del deletes keys
+func (m *Memory) Del(ctx context.Context, keys ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, key := range keys {
+		delete(m.items, key)
+	}
+	return nil
+}
+
+// HGet gets a hash field value
+func (m *Memory) HGet(ctx context.Context, key, field string) (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	item := m.lookup(key)
+	if item == nil {
+		return "", nil
+	}
+	return item.hash[field], nil
+}
+
+// HGetAll gets all fields and values from a hash
+func (m *Memory) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := make(map[string]string)
+	if item := m.lookup(key); item != nil {
+		for f, v := range item.hash {
+			result[f] = v
+		}
+	}
+	return result, nil
+}
+
+// HSet sets a hash field value
+func (m *Memory) HSet(ctx context.Context, key, field string, value interface{}) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	item := m.lookup(key)
+	if item == nil {
+		item = &memoryItem{hash: make(map[string]string)}
+		m.items[key] = item
+	}
+	if item.hash == nil {
+		return fmt.Errorf("key %q holds a string value", key)
+	}
+	item.hash[field] = toString(value)
+	return nil
+}
+
+// HDel deletes hash fields
+func (m *Memory) HDel(ctx context.Context, key string, fields ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	item := m.lookup(key)
+	if item == nil || item.hash == nil {
+		return nil
+	}
+	for _, f := range fields {
+		delete(item.hash, f)
+	}
+	if len(item.hash) == 0 {
+		delete(m.items, key)
+	}
+	return nil
+}
+
+// Ping always succeeds for the in-memory store
+func (m *Memory) Ping(ctx context.Context) error {
+	return nil
+}
+
+// Close is a no-op for the in-memory store
+func (m *Memory) Close() error {
+	return nil
+}
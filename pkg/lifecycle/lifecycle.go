@@ -0,0 +1,220 @@
+// Package lifecycle runs the server's shutdown hooks in a fixed order.
+// Subsystems register a hook where they are created, naming the phase it
+// belongs to; Shutdown runs the phases in order, and hooks within a phase
+// in registration order, each under its own timeout with its duration
+// logged. Ordering by phase rather than by where code happens to sit is what
+// keeps buffered events: the HTTP server finishes in-flight requests before
+// the queues and writers those requests record into are flushed, and those
+// hooks run on their own deadline so a slow HTTP drain can't cut them short.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+// Phase orders shutdown hooks
+type Phase int
+
+// Shutdown phases, in the order they run
+const (
+	PhaseDrain      Phase = iota // close long-lived connections the HTTP server would wait on
+	PhaseHTTP                    // stop accepting requests and finish in-flight ones
+	PhaseSchedulers              // stop background refreshes, schedulers and cache snapshots
+	PhaseQueues                  // stop queues and workers that produce events
+	PhaseFlush                   // flush buffered event writers once nothing produces events
+	PhaseClose                   // release what every phase above still used
+)
+
+// String returns the phase's name
+func (p Phase) String() string {
+	switch p {
+	case PhaseDrain:
+		return "drain"
+	case PhaseHTTP:
+		return "http"
+	case PhaseSchedulers:
+		return "schedulers"
+	case PhaseQueues:
+		return "queues"
+	case PhaseFlush:
+		return "flush"
+	case PhaseClose:
+		return "close"
+	default:
+		return fmt.Sprintf("phase(%d)", int(p))
+	}
+}
+
+// ownDeadline reports whether a phase's hooks run on their own timeout
+// alone rather than within the overall shutdown deadline. Queues, flushes
+// and closes hold buffered events, so they get their full timeout however
+// long the phases before them took.
+func (p Phase) ownDeadline() bool {
+	return p >= PhaseQueues
+}
+
+// DefaultTimeout is how long a hook without its own timeout may take
+const DefaultTimeout = 5 * time.Second
+
+// Hook stops one subsystem
+type Hook struct {
+	Name    string
+	Phase   Phase
+	Timeout time.Duration // Longest the hook may take (0 = DefaultTimeout)
+	Stop    func(ctx context.Context) error
+}
+
+// Func adapts a stop function without a context or error
+func Func(stop func()) func(ctx context.Context) error {
+	return func(context.Context) error {
+		stop()
+		return nil
+	}
+}
+
+// ErrFunc adapts a stop function without a context
+func ErrFunc(stop func() error) func(ctx context.Context) error {
+	return func(context.Context) error {
+		return stop()
+	}
+}
+
+// HookError is one hook's failure; Shutdown joins them into its error
+type HookError struct {
+	Hook  string
+	Phase Phase
+	Err   error
+}
+
+// Error implements error
+func (e *HookError) Error() string {
+	return e.Hook + ": " + e.Err.Error()
+}
+
+// Unwrap returns the hook's error
+func (e *HookError) Unwrap() error {
+	return e.Err
+}
+
+// PhaseFailed reports whether a hook of a phase failed, given the error
+// Shutdown returned
+func PhaseFailed(err error, phase Phase) bool {
+	errs := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	}
+	for _, e := range errs {
+		var hookErr *HookError
+		if errors.As(e, &hookErr) && hookErr.Phase == phase {
+			return true
+		}
+	}
+	return false
+}
+
+// Manager holds the registered shutdown hooks
+type Manager struct {
+	mu    sync.Mutex
+	hooks []Hook
+	done  bool
+}
+
+// New creates a manager without hooks
+func New() *Manager {
+	return &Manager{}
+}
+
+// Register adds a shutdown hook. Hooks registered after Shutdown started
+// never run.
+func (m *Manager) Register(h Hook) {
+	if h.Stop == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = append(m.hooks, h)
+}
+
+// Shutdown runs every hook once, phase by phase. ctx's deadline bounds the
+// drain, HTTP and scheduler hooks; queue, flush and close hooks are bounded
+// only by their own timeouts, so they still run after it passes. A hook
+// failing or running past its timeout is logged and doesn't stop the ones
+// after it; a hook that times out is left running in the background. The
+// returned error joins every hook's error as a *HookError. Calling Shutdown
+// again does nothing.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	if m.done {
+		m.mu.Unlock()
+		return nil
+	}
+	m.done = true
+	hooks := append([]Hook(nil), m.hooks...)
+	m.mu.Unlock()
+
+	sort.SliceStable(hooks, func(i, j int) bool {
+		return hooks[i].Phase < hooks[j].Phase
+	})
+
+	log := logger.Log
+	start := time.Now()
+	var errs []error
+	for _, h := range hooks {
+		hookStart := time.Now()
+		err := run(ctx, h)
+		duration := time.Since(hookStart)
+		if err != nil {
+			log.Warn().
+				Err(err).
+				Str("hook", h.Name).
+				Str("phase", h.Phase.String()).
+				Dur("duration", duration).
+				Msg("Shutdown hook failed")
+			errs = append(errs, &HookError{Hook: h.Name, Phase: h.Phase, Err: err})
+			continue
+		}
+		log.Info().
+			Str("hook", h.Name).
+			Str("phase", h.Phase.String()).
+			Dur("duration", duration).
+			Msg("Shutdown hook finished")
+	}
+
+	log.Info().
+		Int("hooks", len(hooks)).
+		Int("failed", len(errs)).
+		Dur("duration", time.Since(start)).
+		Msg("Shutdown hooks complete")
+	return errors.Join(errs...)
+}
+
+// run calls one hook under its timeout
+func run(ctx context.Context, h Hook) error {
+	timeout := h.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	if h.Phase.ownDeadline() {
+		ctx = context.WithoutCancel(ctx)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- h.Stop(ctx)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("did not finish: %w", ctx.Err())
+	}
+}
@@ -0,0 +1,154 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// calls records the order hooks ran in
+type calls struct {
+	mu    sync.Mutex
+	names []string
+}
+
+func (c *calls) hook(name string, phase Phase) Hook {
+	return Hook{Name: name, Phase: phase, Stop: Func(func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.names = append(c.names, name)
+	})}
+}
+
+func (c *calls) list() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.names...)
+}
+
+func TestManager_Order(t *testing.T) {
+	c := &calls{}
+	m := New()
+	m.Register(c.hook("analytics", PhaseFlush))
+	m.Register(c.hook("event recorder", PhaseClose))
+	m.Register(c.hook("http", PhaseHTTP))
+	m.Register(c.hook("win queue", PhaseQueues))
+	m.Register(c.hook("rollup", PhaseFlush))
+	m.Register(c.hook("tail streams", PhaseDrain))
+	m.Register(c.hook("feature flags", PhaseSchedulers))
+
+	if err := m.Shutdown(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"tail streams", "http", "feature flags", "win queue", "analytics", "rollup", "event recorder"}
+	got := c.list()
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+
+	// A second shutdown runs nothing
+	if err := m.Shutdown(context.Background()); err != nil || len(c.list()) != len(want) {
+		t.Errorf("expected a second shutdown to do nothing, got %v and %v", err, c.list())
+	}
+}
+
+func TestManager_FailuresAndTimeouts(t *testing.T) {
+	c := &calls{}
+	m := New()
+	m.Register(Hook{Name: "broken", Phase: PhaseQueues, Stop: ErrFunc(func() error {
+		return errors.New("disk full")
+	})})
+	release := make(chan struct{})
+	defer close(release)
+	m.Register(Hook{Name: "stuck", Phase: PhaseQueues, Timeout: 20 * time.Millisecond, Stop: Func(func() {
+		<-release
+	})})
+	m.Register(c.hook("flush", PhaseFlush))
+
+	start := time.Now()
+	err := m.Shutdown(context.Background())
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the stuck hook abandoned at its timeout, waited %v", elapsed)
+	}
+	if err == nil || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the timeout reported, got %v", err)
+	}
+	if err == nil || err.Error() != "broken: disk full\nstuck: did not finish: context deadline exceeded" {
+		t.Errorf("expected both failures named, got %v", err)
+	}
+	if got := c.list(); len(got) != 1 || got[0] != "flush" {
+		t.Errorf("expected later hooks to run after failures, got %v", got)
+	}
+}
+
+func TestManager_HookContext(t *testing.T) {
+	m := New()
+	var deadline time.Time
+	m.Register(Hook{Name: "http", Phase: PhaseHTTP, Timeout: time.Minute, Stop: func(ctx context.Context) error {
+		deadline, _ = ctx.Deadline()
+		return nil
+	}})
+
+	// The overall deadline bounds the HTTP hook's own timeout
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := m.Shutdown(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if deadline.IsZero() || time.Until(deadline) > time.Second {
+		t.Errorf("expected the hook bounded by the shutdown deadline, got %v", deadline)
+	}
+}
+
+func TestManager_FlushOutlivesDeadline(t *testing.T) {
+	c := &calls{}
+	m := New()
+	m.Register(Hook{Name: "http", Phase: PhaseHTTP, Stop: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}})
+	var flushErr error
+	m.Register(Hook{Name: "analytics", Phase: PhaseFlush, Timeout: time.Minute, Stop: func(ctx context.Context) error {
+		flushErr = ctx.Err()
+		if deadline, ok := ctx.Deadline(); !ok || time.Until(deadline) < 30*time.Second {
+			t.Errorf("expected the flush hook given its own timeout, got %v", deadline)
+		}
+		return nil
+	}})
+	m.Register(c.hook("event recorder", PhaseClose))
+
+	// The HTTP drain uses up the whole deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := m.Shutdown(ctx)
+	if flushErr != nil {
+		t.Errorf("expected the flush hook to run after the deadline, got %v", flushErr)
+	}
+	if got := c.list(); len(got) != 1 || got[0] != "event recorder" {
+		t.Errorf("expected the close hook to run after the deadline, got %v", got)
+	}
+	if !PhaseFailed(err, PhaseHTTP) || PhaseFailed(err, PhaseFlush) {
+		t.Errorf("expected only the HTTP phase failed, got %v", err)
+	}
+}
+
+func TestPhaseFailed(t *testing.T) {
+	m := New()
+	m.Register(Hook{Name: "snapshot", Phase: PhaseSchedulers, Stop: ErrFunc(func() error {
+		return errors.New("disk full")
+	})})
+	err := m.Shutdown(context.Background())
+	if !PhaseFailed(err, PhaseSchedulers) || PhaseFailed(err, PhaseHTTP) {
+		t.Errorf("expected only the scheduler phase failed, got %v", err)
+	}
+	if PhaseFailed(nil, PhaseHTTP) {
+		t.Error("expected no failure without an error")
+	}
+}
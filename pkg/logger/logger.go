@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/rs/zerolog"
+	"github.com/thenexusengine/tne_springwire/pkg/buildinfo"
 )
 
 // ContextKey is the type for context keys
@@ -23,6 +24,9 @@ const (
 var (
 	// Log is the global logger instance
 	Log zerolog.Logger
+
+	// current is the config passed to the last Init, nil before Init
+	current *Config
 )
 
 // Config holds logger configuration
@@ -43,6 +47,7 @@ func DefaultConfig() Config {
 
 // Init initializes the global logger
 func Init(cfg Config) {
+	current = &cfg
 	var output io.Writer = os.Stdout
 
 	// Parse log level
@@ -59,13 +64,32 @@ func Init(cfg Config) {
 		}
 	}
 
-	// Create logger with common fields
-	Log = zerolog.New(output).
+	// Create logger with common fields; build fields identify the exact
+	// binary (and bidder set) that wrote each line
+	build := buildinfo.Get()
+	// Warnings and errors are also kept in memory for the admin UI
+	logCtx := zerolog.New(zerolog.MultiLevelWriter(output, recent)).
 		Level(level).
 		With().
 		Timestamp().
 		Str("service", "pbs").
-		Logger()
+		Str("version", build.Version)
+	if build.GitSHA != "" {
+		logCtx = logCtx.Str("git_sha", build.GitSHA)
+	}
+	if build.AdapterRegistryHash != "" {
+		logCtx = logCtx.Str("adapters_hash", build.AdapterRegistryHash)
+	}
+	Log = logCtx.Logger()
+}
+
+// Restamp rebuilds Log so its build fields reflect the current buildinfo,
+// for values such as the adapter registry hash that are only known after
+// Init. It does nothing before Init.
+func Restamp() {
+	if current != nil {
+		Init(*current)
+	}
 }
 
 // WithRequestID adds a request ID to the logger context
@@ -73,12 +97,22 @@ func WithRequestID(ctx context.Context, requestID string) context.Context {
 	return context.WithValue(ctx, RequestIDKey, requestID)
 }
 
+// RequestIDFromContext returns the request ID stored in the context, or ""
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(RequestIDKey).(string)
+	return requestID
+}
+
 // WithAuctionID adds an auction ID to the logger context
 func WithAuctionID(ctx context.Context, auctionID string) context.Context {
 	return context.WithValue(ctx, AuctionIDKey, auctionID)
 }
 
-// FromContext returns a logger with context values
+// FromContext returns a logger with context values, at the level of any
+// override for the context's publisher or bidder
 func FromContext(ctx context.Context) zerolog.Logger {
 	l := Log.With()
 
@@ -90,7 +124,17 @@ func FromContext(ctx context.Context) zerolog.Logger {
 		l = l.Str("auction_id", auctionID)
 	}
 
-	return l.Logger()
+	publisherID, _ := ctx.Value(PublisherIDKey).(string)
+	if publisherID != "" {
+		l = l.Str("publisher_id", publisherID)
+	}
+
+	bidderCode, _ := ctx.Value(BidderKey).(string)
+	if bidderCode != "" {
+		l = l.Str("bidder", bidderCode)
+	}
+
+	return applyOverrides(l.Logger(), publisherID, bidderCode)
 }
 
 // Auction returns a logger for auction events
@@ -100,7 +144,7 @@ func Auction(auctionID string) zerolog.Logger {
 
 // Bidder returns a logger for bidder events
 func Bidder(bidderCode string) zerolog.Logger {
-	return Log.With().Str("bidder", bidderCode).Logger()
+	return applyOverrides(Log.With().Str("bidder", bidderCode).Logger(), "", bidderCode)
 }
 
 // HTTP returns a logger for HTTP events
@@ -10,6 +10,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/thenexusengine/tne_springwire/pkg/buildinfo"
 )
 
 // Test Helpers
@@ -224,11 +226,31 @@ func TestInit_JSONFormat(t *testing.T) {
 		t.Errorf("Expected service 'pbs', got '%v'", logEntry["service"])
 	}
 
+	if logEntry["version"] != buildinfo.Version {
+		t.Errorf("Expected version '%s', got '%v'", buildinfo.Version, logEntry["version"])
+	}
+
 	if _, ok := logEntry["time"]; !ok {
 		t.Error("Expected 'time' field in log output")
 	}
 }
 
+func TestRestamp_AdapterRegistryHash(t *testing.T) {
+	defer buildinfo.SetAdapterRegistryHash("")
+
+	output := captureLogOutput(t, func() {
+		Init(Config{Level: "info", Format: "json", TimeFormat: time.RFC3339})
+		buildinfo.SetAdapterRegistryHash("abc123")
+		Restamp()
+		Log.Info().Msg("test message")
+	})
+
+	logEntry := parseLogLine(t, output)
+	if logEntry["adapters_hash"] != "abc123" {
+		t.Errorf("Expected adapters_hash 'abc123', got '%v'", logEntry["adapters_hash"])
+	}
+}
+
 func TestInit_ConsoleFormat(t *testing.T) {
 	output := captureLogOutput(t, func() {
 		Init(Config{
@@ -0,0 +1,151 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	// PublisherIDKey is the context key for the publisher an event belongs to
+	PublisherIDKey ContextKey = "publisher_id"
+	// BidderKey is the context key for the bidder an event belongs to
+	BidderKey ContextKey = "bidder"
+)
+
+// Override scopes
+const (
+	ScopePublisher = "publisher"
+	ScopeBidder    = "bidder"
+)
+
+// MaxOverrideTTL bounds how long a level override can stay in place, so a
+// forgotten debug override can't log at full volume indefinitely
+const MaxOverrideTTL = 24 * time.Hour
+
+// ErrInvalidOverride is returned for overrides with an unknown scope, an
+// empty ID or a TTL outside (0, MaxOverrideTTL]
+var ErrInvalidOverride = errors.New("invalid log level override")
+
+// Override raises (or lowers) the log level for one publisher or bidder
+// until it expires
+type Override struct {
+	Scope     string
+	ID        string
+	Level     zerolog.Level
+	ExpiresAt time.Time
+}
+
+type overrideKey struct {
+	scope string
+	id    string
+}
+
+var (
+	overridesMu sync.RWMutex
+	overrides   = make(map[overrideKey]Override)
+	// overrideNow is replaced in tests
+	overrideNow = time.Now
+)
+
+// SetOverride logs events for the publisher or bidder at level for ttl,
+// replacing any existing override for it
+func SetOverride(scope, id string, level zerolog.Level, ttl time.Duration) (Override, error) {
+	if (scope != ScopePublisher && scope != ScopeBidder) || id == "" || ttl <= 0 || ttl > MaxOverrideTTL {
+		return Override{}, ErrInvalidOverride
+	}
+
+	o := Override{Scope: scope, ID: id, Level: level, ExpiresAt: overrideNow().Add(ttl)}
+	overridesMu.Lock()
+	overrides[overrideKey{scope, id}] = o
+	overridesMu.Unlock()
+
+	Log.Info().
+		Str("scope", scope).
+		Str("id", id).
+		Str("level", level.String()).
+		Time("expires_at", o.ExpiresAt).
+		Msg("Log level override set")
+	return o, nil
+}
+
+// ClearOverride removes an override, reporting whether one was active
+func ClearOverride(scope, id string) bool {
+	key := overrideKey{scope, id}
+	overridesMu.Lock()
+	o, ok := overrides[key]
+	delete(overrides, key)
+	overridesMu.Unlock()
+	return ok && overrideNow().Before(o.ExpiresAt)
+}
+
+// Overrides returns the active overrides, ordered by scope and ID, dropping
+// any that have expired
+func Overrides() []Override {
+	now := overrideNow()
+	overridesMu.Lock()
+	active := make([]Override, 0, len(overrides))
+	for key, o := range overrides {
+		if !now.Before(o.ExpiresAt) {
+			delete(overrides, key)
+			continue
+		}
+		active = append(active, o)
+	}
+	overridesMu.Unlock()
+
+	sort.Slice(active, func(i, j int) bool {
+		if active[i].Scope != active[j].Scope {
+			return active[i].Scope < active[j].Scope
+		}
+		return active[i].ID < active[j].ID
+	})
+	return active
+}
+
+// overrideLevel returns the active override for scope/id, if any. Expired
+// overrides are ignored here and dropped by the next Overrides call.
+func overrideLevel(scope, id string) (zerolog.Level, bool) {
+	if id == "" {
+		return zerolog.NoLevel, false
+	}
+	overridesMu.RLock()
+	o, ok := overrides[overrideKey{scope, id}]
+	overridesMu.RUnlock()
+	if !ok || !overrideNow().Before(o.ExpiresAt) {
+		return zerolog.NoLevel, false
+	}
+	return o.Level, true
+}
+
+// applyOverrides sets l's level from the publisher and bidder overrides;
+// when both are set the more verbose one wins
+func applyOverrides(l zerolog.Logger, publisherID, bidderCode string) zerolog.Logger {
+	level, ok := overrideLevel(ScopePublisher, publisherID)
+	if bidderLevel, bidderOK := overrideLevel(ScopeBidder, bidderCode); bidderOK && (!ok || bidderLevel < level) {
+		level, ok = bidderLevel, true
+	}
+	if !ok {
+		return l
+	}
+	return l.Level(level)
+}
+
+// WithPublisherID adds a publisher ID to the logger context
+func WithPublisherID(ctx context.Context, publisherID string) context.Context {
+	return context.WithValue(ctx, PublisherIDKey, publisherID)
+}
+
+// WithBidder adds a bidder code to the logger context
+func WithBidder(ctx context.Context, bidderCode string) context.Context {
+	return context.WithValue(ctx, BidderKey, bidderCode)
+}
+
+// Publisher returns a logger for publisher events
+func Publisher(publisherID string) zerolog.Logger {
+	return applyOverrides(Log.With().Str("publisher_id", publisherID).Logger(), publisherID, "")
+}
@@ -0,0 +1,124 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// withOverrideClock points the global logger at a buffer and the override
+// registry at a controllable clock, restoring both afterwards
+func withOverrideClock(t *testing.T) (*bytes.Buffer, *time.Time) {
+	t.Helper()
+	origLog, origNow := Log, overrideNow
+	buf := &bytes.Buffer{}
+	Log = zerolog.New(buf).Level(zerolog.InfoLevel)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	overrideNow = func() time.Time { return now }
+	t.Cleanup(func() {
+		Log, overrideNow = origLog, origNow
+		overridesMu.Lock()
+		overrides = make(map[overrideKey]Override)
+		overridesMu.Unlock()
+	})
+	return buf, &now
+}
+
+func logDebug(l zerolog.Logger, msg string) { l.Debug().Msg(msg) }
+
+func logInfo(l zerolog.Logger, msg string) { l.Info().Msg(msg) }
+
+func TestOverride_PublisherDebug(t *testing.T) {
+	buf, now := withOverrideClock(t)
+
+	if _, err := SetOverride(ScopePublisher, "pub-1", zerolog.DebugLevel, 10*time.Minute); err != nil {
+		t.Fatalf("SetOverride: %v", err)
+	}
+	buf.Reset()
+
+	logDebug(FromContext(WithPublisherID(context.Background(), "pub-1")), "pub-1 debug")
+	logDebug(FromContext(WithPublisherID(context.Background(), "pub-2")), "pub-2 debug")
+	logDebug(FromContext(context.Background()), "no publisher debug")
+
+	out := buf.String()
+	if !strings.Contains(out, "pub-1 debug") || !strings.Contains(out, `"publisher_id":"pub-1"`) {
+		t.Errorf("expected pub-1 debug line, got %q", out)
+	}
+	if strings.Contains(out, "pub-2 debug") || strings.Contains(out, "no publisher debug") {
+		t.Errorf("override leaked to other publishers: %q", out)
+	}
+
+	// Past the TTL the publisher is back to the global level
+	*now = now.Add(10 * time.Minute)
+	buf.Reset()
+	logDebug(FromContext(WithPublisherID(context.Background(), "pub-1")), "expired debug")
+	if buf.Len() != 0 {
+		t.Errorf("expected no output after expiry, got %q", buf.String())
+	}
+	if active := Overrides(); len(active) != 0 {
+		t.Errorf("expected expired override dropped, got %+v", active)
+	}
+}
+
+func TestOverride_BidderAndMostVerboseWins(t *testing.T) {
+	buf, _ := withOverrideClock(t)
+
+	SetOverride(ScopePublisher, "pub-1", zerolog.WarnLevel, time.Minute)
+	SetOverride(ScopeBidder, "appnexus", zerolog.DebugLevel, time.Minute)
+	buf.Reset()
+
+	logDebug(Bidder("appnexus"), "bidder debug")
+	ctx := WithBidder(WithPublisherID(context.Background(), "pub-1"), "appnexus")
+	logDebug(FromContext(ctx), "both debug")
+	logInfo(FromContext(WithPublisherID(context.Background(), "pub-1")), "pub info")
+
+	out := buf.String()
+	if !strings.Contains(out, "bidder debug") || !strings.Contains(out, "both debug") {
+		t.Errorf("expected bidder debug lines, got %q", out)
+	}
+	if strings.Contains(out, "pub info") {
+		t.Errorf("expected warn override to drop info for pub-1, got %q", out)
+	}
+}
+
+func TestOverride_Validation(t *testing.T) {
+	withOverrideClock(t)
+
+	tests := []struct {
+		name  string
+		scope string
+		id    string
+		ttl   time.Duration
+	}{
+		{"unknown scope", "site", "x", time.Minute},
+		{"empty id", ScopePublisher, "", time.Minute},
+		{"zero ttl", ScopePublisher, "pub-1", 0},
+		{"ttl too long", ScopePublisher, "pub-1", MaxOverrideTTL + time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := SetOverride(tt.scope, tt.id, zerolog.DebugLevel, tt.ttl); err != ErrInvalidOverride {
+				t.Errorf("expected ErrInvalidOverride, got %v", err)
+			}
+		})
+	}
+}
+
+func TestClearOverride(t *testing.T) {
+	withOverrideClock(t)
+
+	SetOverride(ScopeBidder, "rubicon", zerolog.DebugLevel, time.Minute)
+	if !ClearOverride(ScopeBidder, "rubicon") {
+		t.Error("expected active override to be cleared")
+	}
+	if ClearOverride(ScopeBidder, "rubicon") {
+		t.Error("expected second clear to report nothing removed")
+	}
+	if _, ok := overrideLevel(ScopeBidder, "rubicon"); ok {
+		t.Error("expected no override after clear")
+	}
+}
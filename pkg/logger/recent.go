@@ -0,0 +1,74 @@
+package logger
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+// RecentCapacity is how many warn-or-worse entries are kept in memory for
+// the admin UI
+const RecentCapacity = 200
+
+// RecentEntry is one recently logged warning or error
+type RecentEntry struct {
+	Level zerolog.Level
+	Entry json.RawMessage // The JSON log line as written
+}
+
+// recentBuffer is a ring of the last RecentCapacity entries logged at warn
+// level or above. It is teed off the global logger's output by Init.
+type recentBuffer struct {
+	mu      sync.Mutex
+	entries []RecentEntry
+	next    int
+}
+
+var recent = &recentBuffer{entries: make([]RecentEntry, 0, RecentCapacity)}
+
+// Write implements io.Writer; entries without a level are not kept
+func (b *recentBuffer) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+// WriteLevel implements zerolog.LevelWriter
+func (b *recentBuffer) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	if level < zerolog.WarnLevel || level == zerolog.NoLevel {
+		return len(p), nil
+	}
+
+	// zerolog reuses p after Write returns
+	line := make([]byte, len(p))
+	copy(line, p)
+	for len(line) > 0 && line[len(line)-1] == '\n' {
+		line = line[:len(line)-1]
+	}
+	entry := RecentEntry{Level: level, Entry: line}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.entries) < RecentCapacity {
+		b.entries = append(b.entries, entry)
+	} else {
+		b.entries[b.next] = entry
+	}
+	b.next = (b.next + 1) % RecentCapacity
+	return len(p), nil
+}
+
+// Recent returns up to limit entries logged at minLevel or above, newest
+// first
+func Recent(minLevel zerolog.Level, limit int) []RecentEntry {
+	recent.mu.Lock()
+	defer recent.mu.Unlock()
+
+	out := make([]RecentEntry, 0, min(limit, len(recent.entries)))
+	for i := 1; i <= len(recent.entries) && len(out) < limit; i++ {
+		entry := recent.entries[(recent.next-i+len(recent.entries))%len(recent.entries)]
+		if entry.Level >= minLevel {
+			out = append(out, entry)
+		}
+	}
+	return out
+}
@@ -0,0 +1,71 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestRecent(t *testing.T) {
+	saved := recent
+	defer func() { recent = saved }()
+	recent = &recentBuffer{}
+
+	l := zerolog.New(zerolog.MultiLevelWriter(recent))
+	l.Info().Msg("ignored")
+	l.Warn().Str("bidder", "appnexus").Msg("slow bidder")
+	l.Error().Msg("first error")
+	for i := 0; i < RecentCapacity; i++ {
+		l.Error().Int("n", i).Msg("flood")
+	}
+	l.Error().Msg("latest error")
+
+	entries := Recent(zerolog.WarnLevel, 3)
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+	var newest struct {
+		Level   string `json:"level"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(entries[0].Entry, &newest); err != nil {
+		t.Fatalf("entry is not JSON: %v", err)
+	}
+	if newest.Message != "latest error" || newest.Level != "error" {
+		t.Errorf("expected the latest error first, got %+v", newest)
+	}
+	if string(entries[1].Entry) != fmt.Sprintf(`{"level":"error","n":%d,"message":"flood"}`, RecentCapacity-1) {
+		t.Errorf("unexpected second entry: %s", entries[1].Entry)
+	}
+
+	// The warning and first error were pushed out of the ring
+	all := Recent(zerolog.WarnLevel, 1000)
+	if len(all) != RecentCapacity {
+		t.Fatalf("expected the ring to hold %d entries, got %d", RecentCapacity, len(all))
+	}
+	for _, e := range all {
+		if e.Level == zerolog.WarnLevel {
+			t.Errorf("expected the old warning to be overwritten, found %s", e.Entry)
+		}
+	}
+}
+
+func TestRecent_MinLevel(t *testing.T) {
+	saved := recent
+	defer func() { recent = saved }()
+	recent = &recentBuffer{}
+
+	l := zerolog.New(zerolog.MultiLevelWriter(recent))
+	l.Warn().Msg("warning")
+	l.Error().Msg("error")
+	l.Debug().Msg("debug")
+
+	if got := Recent(zerolog.ErrorLevel, 10); len(got) != 1 || got[0].Level != zerolog.ErrorLevel {
+		t.Errorf("expected only the error, got %+v", got)
+	}
+	if got := Recent(zerolog.WarnLevel, 10); len(got) != 2 {
+		t.Errorf("expected the warning and the error, got %d entries", len(got))
+	}
+}
@@ -0,0 +1,285 @@
+// Package lru provides a size-bounded least-recently-used map for in-memory
+// state keyed by client-supplied IDs (sessions, publishers, client IPs), so a
+// flood of unique keys evicts old entries instead of growing the process
+package lru
+
+import (
+	"container/list"
+	"sync"
+)
+
+// Eviction reasons reported to the Recorder
+const (
+	ReasonEntries = "max_entries" // over Config.MaxEntries
+	ReasonBytes   = "max_bytes"   // over Config.MaxBytes
+	ReasonExpired = "expired"     // removed by RemoveFunc, e.g. a TTL sweep
+)
+
+// Recorder records evictions and occupancy per named cache
+type Recorder interface {
+	RecordLRUEviction(cache, reason string)
+	SetLRUSize(cache string, entries int, bytes int64)
+}
+
+var (
+	recorderMu sync.RWMutex
+	recorder   Recorder
+)
+
+// SetRecorder sets the metrics recorder shared by all caches
+func SetRecorder(r Recorder) {
+	recorderMu.Lock()
+	defer recorderMu.Unlock()
+	recorder = r
+}
+
+func getRecorder() Recorder {
+	recorderMu.RLock()
+	defer recorderMu.RUnlock()
+	return recorder
+}
+
+// Config bounds a cache. A zero limit disables that bound.
+type Config struct {
+	Name       string // metrics label
+	MaxEntries int
+	MaxBytes   int64
+}
+
+// SizeFunc estimates the memory held by an entry, in bytes
+type SizeFunc[K comparable, V any] func(key K, value V) int64
+
+type entry[K comparable, V any] struct {
+	key   K
+	value V
+	size  int64
+}
+
+// Cache is a concurrency-safe LRU map. Reads through Get and writes move an
+// entry to the front; when a write puts the cache over either bound the
+// least recently used entries are evicted.
+type Cache[K comparable, V any] struct {
+	cfg    Config
+	sizeOf SizeFunc[K, V]
+
+	mu    sync.Mutex
+	ll    *list.List
+	items map[K]*list.Element
+	bytes int64
+}
+
+// New creates a cache. sizeOf may be nil when only MaxEntries is set.
+func New[K comparable, V any](cfg Config, sizeOf SizeFunc[K, V]) *Cache[K, V] {
+	if sizeOf == nil {
+		sizeOf = func(K, V) int64 { return 0 }
+	}
+	return &Cache[K, V]{
+		cfg:    cfg,
+		sizeOf: sizeOf,
+		ll:     list.New(),
+		items:  make(map[K]*list.Element),
+	}
+}
+
+// Get returns the value for key and marks it recently used
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	c.ll.MoveToFront(el)
+	return el.Value.(*entry[K, V]).value, true
+}
+
+// Peek returns the value for key without changing its recency
+func (c *Cache[K, V]) Peek(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	return el.Value.(*entry[K, V]).value, true
+}
+
+// Contains reports whether key is cached without changing its recency
+func (c *Cache[K, V]) Contains(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.items[key]
+	return ok
+}
+
+// Set stores value under key, evicting as needed
+func (c *Cache[K, V]) Set(key K, value V) {
+	c.Update(key, func(V, bool) V { return value })
+}
+
+// Add stores value under key only if the key is absent, reporting whether it
+// was stored
+func (c *Cache[K, V]) Add(key K, value V) bool {
+	added := false
+	c.Update(key, func(old V, ok bool) V {
+		if ok {
+			return old
+		}
+		added = true
+		return value
+	})
+	return added
+}
+
+// Update replaces the value for key with fn(old, ok), where ok reports
+// whether key was present. fn runs under the cache lock, so read-modify-write
+// sequences such as token buckets stay atomic; it must not call back into the
+// cache.
+func (c *Cache[K, V]) Update(key K, fn func(old V, ok bool) V) V {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var value V
+	el, existed := c.items[key]
+	if existed {
+		e := el.Value.(*entry[K, V])
+		value = fn(e.value, true)
+		size := c.sizeOf(key, value)
+		c.bytes += size - e.size
+		e.value, e.size = value, size
+		c.ll.MoveToFront(el)
+	} else {
+		var zero V
+		value = fn(zero, false)
+		e := &entry[K, V]{key: key, value: value, size: c.sizeOf(key, value)}
+		c.items[key] = c.ll.PushFront(e)
+		c.bytes += e.size
+	}
+
+	// Occupancy is only republished when the entry count changes, keeping
+	// updates of hot keys cheap
+	if evicted := c.evictLocked(); !existed || evicted > 0 {
+		c.reportLocked()
+	}
+	return value
+}
+
+// evictLocked drops least recently used entries until the cache is within
+// its bounds, always keeping the most recent entry, and returns how many it
+// dropped. Caller must hold c.mu.
+func (c *Cache[K, V]) evictLocked() int {
+	rec := getRecorder()
+	n := 0
+	for c.ll.Len() > 1 {
+		reason := ""
+		switch {
+		case c.cfg.MaxEntries > 0 && c.ll.Len() > c.cfg.MaxEntries:
+			reason = ReasonEntries
+		case c.cfg.MaxBytes > 0 && c.bytes > c.cfg.MaxBytes:
+			reason = ReasonBytes
+		default:
+			return n
+		}
+		c.removeLocked(c.ll.Back())
+		n++
+		if rec != nil {
+			rec.RecordLRUEviction(c.cfg.Name, reason)
+		}
+	}
+	return n
+}
+
+// reportLocked publishes the cache's occupancy. Caller must hold c.mu.
+func (c *Cache[K, V]) reportLocked() {
+	if rec := getRecorder(); rec != nil {
+		rec.SetLRUSize(c.cfg.Name, c.ll.Len(), c.bytes)
+	}
+}
+
+func (c *Cache[K, V]) removeLocked(el *list.Element) {
+	e := c.ll.Remove(el).(*entry[K, V])
+	delete(c.items, e.key)
+	c.bytes -= e.size
+}
+
+// Delete removes key, reporting whether it was present
+func (c *Cache[K, V]) Delete(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		return false
+	}
+	c.removeLocked(el)
+	c.reportLocked()
+	return true
+}
+
+// RemoveFunc removes every entry for which fn returns true, e.g. expired
+// ones, and returns how many were removed. Removals are reported as
+// ReasonExpired evictions.
+func (c *Cache[K, V]) RemoveFunc(fn func(key K, value V) bool) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	rec := getRecorder()
+	n := 0
+	for el := c.ll.Back(); el != nil; {
+		prev := el.Prev()
+		e := el.Value.(*entry[K, V])
+		if fn(e.key, e.value) {
+			c.removeLocked(el)
+			n++
+			if rec != nil {
+				rec.RecordLRUEviction(c.cfg.Name, ReasonExpired)
+			}
+		}
+		el = prev
+	}
+	if n > 0 {
+		c.reportLocked()
+	}
+	return n
+}
+
+// Range calls fn for each entry from most to least recently used until fn
+// returns false. fn runs under the cache lock and must not call back into
+// the cache.
+func (c *Cache[K, V]) Range(fn func(key K, value V) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for el := c.ll.Front(); el != nil; el = el.Next() {
+		e := el.Value.(*entry[K, V])
+		if !fn(e.key, e.value) {
+			return
+		}
+	}
+}
+
+// Purge removes every entry and returns how many there were
+func (c *Cache[K, V]) Purge() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := c.ll.Len()
+	c.ll.Init()
+	c.items = make(map[K]*list.Element)
+	c.bytes = 0
+	c.reportLocked()
+	return n
+}
+
+// Len returns the number of entries
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+// Bytes returns the estimated memory held by the entries
+func (c *Cache[K, V]) Bytes() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.bytes
+}
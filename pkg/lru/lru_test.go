@@ -0,0 +1,174 @@
+package lru
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+type fakeRecorder struct {
+	mu        sync.Mutex
+	evictions map[string]int
+	entries   int
+	bytes     int64
+}
+
+func (f *fakeRecorder) RecordLRUEviction(cache, reason string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.evictions[cache+"/"+reason]++
+}
+
+func (f *fakeRecorder) SetLRUSize(_ string, entries int, bytes int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.entries, f.bytes = entries, bytes
+}
+
+func withRecorder(t *testing.T) *fakeRecorder {
+	t.Helper()
+	rec := &fakeRecorder{evictions: make(map[string]int)}
+	SetRecorder(rec)
+	t.Cleanup(func() { SetRecorder(nil) })
+	return rec
+}
+
+func TestCache_MaxEntriesEvictsLeastRecentlyUsed(t *testing.T) {
+	rec := withRecorder(t)
+	c := New[string, int](Config{Name: "test", MaxEntries: 2}, nil)
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Get("a") // a is now more recent than b
+	c.Set("c", 3)
+
+	if c.Contains("b") {
+		t.Error("expected b to be evicted")
+	}
+	if !c.Contains("a") || !c.Contains("c") {
+		t.Error("expected a and c to remain")
+	}
+	if rec.evictions["test/max_entries"] != 1 {
+		t.Errorf("expected 1 max_entries eviction, got %v", rec.evictions)
+	}
+	if rec.entries != 2 {
+		t.Errorf("expected size 2 reported, got %d", rec.entries)
+	}
+}
+
+func TestCache_MaxBytes(t *testing.T) {
+	rec := withRecorder(t)
+	c := New(Config{Name: "bytes", MaxBytes: 10}, func(_ string, v string) int64 { return int64(len(v)) })
+
+	c.Set("a", "1234")
+	c.Set("b", "1234")
+	c.Set("c", "1234")
+
+	if c.Len() != 2 || c.Bytes() != 8 {
+		t.Errorf("expected 2 entries of 8 bytes, got %d entries of %d bytes", c.Len(), c.Bytes())
+	}
+	if c.Contains("a") {
+		t.Error("expected a to be evicted")
+	}
+	if rec.evictions["bytes/max_bytes"] != 1 {
+		t.Errorf("expected 1 max_bytes eviction, got %v", rec.evictions)
+	}
+
+	// Growing an entry in place is accounted for too
+	c.Update("c", func(v string, _ bool) string { return v + "123456" })
+	if c.Len() != 1 || c.Bytes() != 10 {
+		t.Errorf("expected only c left at 10 bytes, got %d entries of %d bytes", c.Len(), c.Bytes())
+	}
+}
+
+func TestCache_OversizedEntryIsKept(t *testing.T) {
+	c := New(Config{Name: "big", MaxBytes: 4}, func(_ string, v string) int64 { return int64(len(v)) })
+
+	c.Set("a", "123456789")
+	if v, ok := c.Get("a"); !ok || v != "123456789" {
+		t.Errorf("expected the most recent entry to be kept, got %q %v", v, ok)
+	}
+}
+
+func TestCache_UpdateAndAdd(t *testing.T) {
+	c := New[string, int](Config{Name: "update"}, nil)
+
+	for i := 0; i < 3; i++ {
+		c.Update("n", func(v int, _ bool) int { return v + 1 })
+	}
+	if v, _ := c.Peek("n"); v != 3 {
+		t.Errorf("expected 3, got %d", v)
+	}
+
+	if c.Add("n", 100) {
+		t.Error("expected Add to keep the existing value")
+	}
+	if !c.Add("m", 1) {
+		t.Error("expected Add to store a new key")
+	}
+	if v, _ := c.Peek("n"); v != 3 {
+		t.Errorf("expected 3 after Add, got %d", v)
+	}
+}
+
+func TestCache_RemoveFuncPurgeDelete(t *testing.T) {
+	rec := withRecorder(t)
+	c := New[string, int](Config{Name: "rm"}, nil)
+	for i := 0; i < 10; i++ {
+		c.Set(fmt.Sprint(i), i)
+	}
+
+	if n := c.RemoveFunc(func(_ string, v int) bool { return v%2 == 0 }); n != 5 {
+		t.Errorf("expected 5 removed, got %d", n)
+	}
+	if rec.evictions["rm/expired"] != 5 {
+		t.Errorf("expected 5 expired evictions, got %v", rec.evictions)
+	}
+	if !c.Delete("1") || c.Delete("1") {
+		t.Error("expected Delete to report presence once")
+	}
+	if n := c.Purge(); n != 4 {
+		t.Errorf("expected 4 purged, got %d", n)
+	}
+	if c.Len() != 0 || rec.entries != 0 {
+		t.Errorf("expected empty cache, got %d (reported %d)", c.Len(), rec.entries)
+	}
+}
+
+func TestCache_RangeOrder(t *testing.T) {
+	c := New[string, int](Config{Name: "range"}, nil)
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Set("c", 3)
+	c.Get("a")
+
+	var keys []string
+	c.Range(func(k string, _ int) bool {
+		keys = append(keys, k)
+		return len(keys) < 2
+	})
+	if fmt.Sprint(keys) != "[a c]" {
+		t.Errorf("expected most recent first, got %v", keys)
+	}
+}
+
+func TestCache_Concurrent(t *testing.T) {
+	c := New[int, int](Config{Name: "concurrent", MaxEntries: 100}, nil)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				c.Update(i%200, func(v int, _ bool) int { return v + 1 })
+				c.Get(g)
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if c.Len() > 100 {
+		t.Errorf("expected at most 100 entries, got %d", c.Len())
+	}
+}
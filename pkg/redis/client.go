@@ -9,11 +9,14 @@ import (
 
 	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog/log"
+	"github.com/thenexusengine/tne_springwire/pkg/deadline"
 )
 
-// Client wraps a Redis connection pool
+// Client wraps a Redis connection pool. Every command is timed and counted
+// against an error budget (see Degraded).
 type Client struct {
 	client *redis.Client
+	budget *errorBudget
 }
 
 // ClientConfig holds configuration for the Redis client
@@ -32,6 +35,14 @@ type ClientConfig struct {
 	WriteTimeout time.Duration
 	// Timeout for getting connection from pool
 	PoolTimeout time.Duration
+	// Commands slower than this count against the error budget
+	// (default: DefaultSlowThreshold)
+	SlowThreshold time.Duration
+	// Share of failed or slow commands per window tolerated before the
+	// client is degraded (default: DefaultErrorBudget)
+	ErrorBudget float64
+	// Window the error budget is evaluated over (default: DefaultBudgetWindow)
+	BudgetWindow time.Duration
 }
 
 // DefaultClientConfig returns production-ready configuration
@@ -44,6 +55,10 @@ func DefaultClientConfig() *ClientConfig {
 		ReadTimeout:  3 * time.Second,
 		WriteTimeout: 3 * time.Second,
 		PoolTimeout:  4 * time.Second,
+
+		SlowThreshold: DefaultSlowThreshold,
+		ErrorBudget:   DefaultErrorBudget,
+		BudgetWindow:  DefaultBudgetWindow,
 	}
 }
 
@@ -78,6 +93,8 @@ func NewWithConfig(redisURL string, cfg *ClientConfig) (*Client, error) {
 	opts.PoolTimeout = cfg.PoolTimeout
 
 	client := redis.NewClient(opts)
+	budget := newErrorBudget(cfg)
+	client.AddHook(budget)
 
 	// Test connection
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -94,7 +111,50 @@ func NewWithConfig(redisURL string, cfg *ClientConfig) (*Client, error) {
 			Msg("Redis connected with connection pooling")
 	}
 
-	return &Client{client: client}, nil
+	return &Client{client: client, budget: budget}, nil
+}
+
+// Degraded reports whether more of the last window's commands failed or were
+// slow than the error budget allows. Callers shed optional Redis work while
+// it is set.
+func (c *Client) Degraded() bool {
+	return c.budget != nil && c.budget.degraded.Load()
+}
+
+// Get gets a string value, returning "" when the key doesn't exist
+func (c *Client) Get(ctx context.Context, key string) (string, error) {
+	result, err := c.client.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	return result, deadline.Observe(ctx, deadline.DependencyRedis, err)
+}
+
+// Set sets a string value with an expiration (0 = no expiration)
+func (c *Client) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	return deadline.Observe(ctx, deadline.DependencyRedis, c.client.Set(ctx, key, value, expiration).Err())
+}
+
+// SetNX sets a value with an expiration only if the key doesn't exist, and
+// reports whether it was set
+func (c *Client) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	set, err := c.client.SetNX(ctx, key, value, expiration).Result()
+	return set, deadline.Observe(ctx, deadline.DependencyRedis, err)
+}
+
+// Eval runs a Lua script atomically. A nil script result is returned as nil
+// without error.
+func (c *Client) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+	result, err := c.client.Eval(ctx, script, keys, args...).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	return result, deadline.Observe(ctx, deadline.DependencyRedis, err)
+}
+
+// Del deletes keys
+func (c *Client) Del(ctx context.Context, keys ...string) error {
+	return deadline.Observe(ctx, deadline.DependencyRedis, c.client.Del(ctx, keys...).Err())
 }
 
 // HGet gets a hash field value
@@ -103,32 +163,34 @@ func (c *Client) HGet(ctx context.Context, key, field string) (string, error) {
 	if errors.Is(err, redis.Nil) {
 		return "", nil
 	}
-	return result, err
+	return result, deadline.Observe(ctx, deadline.DependencyRedis, err)
 }
 
 // HGetAll gets all fields and values from a hash
 func (c *Client) HGetAll(ctx context.Context, key string) (map[string]string, error) {
-	return c.client.HGetAll(ctx, key).Result()
+	result, err := c.client.HGetAll(ctx, key).Result()
+	return result, deadline.Observe(ctx, deadline.DependencyRedis, err)
 }
 
 // HSet sets a hash field value
 func (c *Client) HSet(ctx context.Context, key, field string, value interface{}) error {
-	return c.client.HSet(ctx, key, field, value).Err()
+	return deadline.Observe(ctx, deadline.DependencyRedis, c.client.HSet(ctx, key, field, value).Err())
 }
 
 // HDel deletes hash fields
 func (c *Client) HDel(ctx context.Context, key string, fields ...string) error {
-	return c.client.HDel(ctx, key, fields...).Err()
+	return deadline.Observe(ctx, deadline.DependencyRedis, c.client.HDel(ctx, key, fields...).Err())
 }
 
 // SMembers gets all members of a set
 func (c *Client) SMembers(ctx context.Context, key string) ([]string, error) {
-	return c.client.SMembers(ctx, key).Result()
+	result, err := c.client.SMembers(ctx, key).Result()
+	return result, deadline.Observe(ctx, deadline.DependencyRedis, err)
 }
 
 // Ping tests the connection
 func (c *Client) Ping(ctx context.Context) error {
-	return c.client.Ping(ctx).Err()
+	return deadline.Observe(ctx, deadline.DependencyRedis, c.client.Ping(ctx).Err())
 }
 
 // Close closes the connection pool
@@ -168,6 +168,64 @@ func TestClient_HGet_Success(t *testing.T) {
 	}
 }
 
+func TestClient_SetNX(t *testing.T) {
+	mr, redisURL := setupTestRedis(t)
+	defer mr.Close()
+
+	client, err := New(redisURL)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+
+	set, err := client.SetNX(ctx, "dedup-key", "1", 10*time.Second)
+	if err != nil || !set {
+		t.Fatalf("Expected first SetNX to set the key, got %v, %v", set, err)
+	}
+	set, err = client.SetNX(ctx, "dedup-key", "1", 10*time.Second)
+	if err != nil || set {
+		t.Errorf("Expected second SetNX to be rejected, got %v, %v", set, err)
+	}
+
+	// The key can be claimed again once it expires
+	mr.FastForward(11 * time.Second)
+	set, err = client.SetNX(ctx, "dedup-key", "1", 10*time.Second)
+	if err != nil || !set {
+		t.Errorf("Expected SetNX to set the expired key, got %v, %v", set, err)
+	}
+}
+
+func TestClient_Eval(t *testing.T) {
+	mr, redisURL := setupTestRedis(t)
+	defer mr.Close()
+
+	client, err := New(redisURL)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	script := `return redis.call('INCRBY', KEYS[1], ARGV[1])`
+
+	for _, want := range []int64{5, 10} {
+		result, err := client.Eval(ctx, script, []string{"counter"}, 5)
+		if err != nil {
+			t.Fatalf("Eval failed: %v", err)
+		}
+		if result != want {
+			t.Errorf("Expected %d, got %v", want, result)
+		}
+	}
+
+	result, err := client.Eval(ctx, `return nil`, nil)
+	if err != nil || result != nil {
+		t.Errorf("Expected nil result without error, got %v, %v", result, err)
+	}
+}
+
 func TestClient_HGet_NotFound(t *testing.T) {
 	mr, redisURL := setupTestRedis(t)
 	defer mr.Close()
@@ -597,3 +655,35 @@ func TestClient_MultipleOperations(t *testing.T) {
 		t.Errorf("Expected 2 fields after delete, got %d", len(all))
 	}
 }
+
+func TestClient_GetSet(t *testing.T) {
+	mr, redisURL := setupTestRedis(t)
+	defer mr.Close()
+
+	client, err := New(redisURL)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	if err := client.Set(ctx, "key", "value", time.Minute); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	val, err := client.Get(ctx, "key")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if val != "value" {
+		t.Errorf("Expected 'value', got %q", val)
+	}
+
+	missing, err := client.Get(ctx, "missing")
+	if err != nil {
+		t.Errorf("Expected no error for missing key, got %v", err)
+	}
+	if missing != "" {
+		t.Errorf("Expected empty string for missing key, got %q", missing)
+	}
+}
@@ -0,0 +1,197 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+)
+
+// Error budget defaults. A window in which more than ErrorBudget of the
+// commands failed or took longer than SlowThreshold marks the client
+// degraded; the next window within budget clears it.
+const (
+	DefaultSlowThreshold = 50 * time.Millisecond
+	DefaultErrorBudget   = 0.05
+	DefaultBudgetWindow  = 10 * time.Second
+)
+
+// minBudgetSamples is the fewest commands a window needs before it can
+// change the degraded flag, so a handful of slow calls at low traffic
+// doesn't flap it
+const minBudgetSamples = 20
+
+// pipelineCommand labels pipelined and transactional commands
+const pipelineCommand = "pipeline"
+
+// Recorder records Redis command latency, errors and the degraded flag
+type Recorder interface {
+	RecordRedisCommand(command string, duration time.Duration, failed bool)
+	SetRedisDegraded(degraded bool)
+}
+
+var (
+	recorderMu sync.RWMutex
+	recorder   Recorder
+)
+
+// SetRecorder sets the metrics recorder for every client
+func SetRecorder(r Recorder) {
+	recorderMu.Lock()
+	defer recorderMu.Unlock()
+	recorder = r
+}
+
+func currentRecorder() Recorder {
+	recorderMu.RLock()
+	defer recorderMu.RUnlock()
+	return recorder
+}
+
+// errorBudget tracks the share of failed or slow commands per window and
+// flags the client degraded while it exceeds the budget. It is installed as
+// a go-redis hook, so every command the client sends is counted.
+type errorBudget struct {
+	slow   time.Duration
+	budget float64
+	window time.Duration
+	now    func() time.Time
+
+	degraded atomic.Bool
+
+	mu          sync.Mutex
+	windowStart time.Time
+	calls       int
+	over        int // failed or slow
+}
+
+func newErrorBudget(cfg *ClientConfig) *errorBudget {
+	b := &errorBudget{
+		slow:   cfg.SlowThreshold,
+		budget: cfg.ErrorBudget,
+		window: cfg.BudgetWindow,
+		now:    time.Now,
+	}
+	if b.slow <= 0 {
+		b.slow = DefaultSlowThreshold
+	}
+	if b.budget <= 0 {
+		b.budget = DefaultErrorBudget
+	}
+	if b.window <= 0 {
+		b.window = DefaultBudgetWindow
+	}
+	b.windowStart = b.now()
+	return b
+}
+
+// observe counts one command, closing the window when it has elapsed.
+// Blocking commands wait on purpose, so only their errors count.
+func (b *errorBudget) observe(command string, duration time.Duration, blocking bool, err error) {
+	failed := isFailure(err)
+	if r := currentRecorder(); r != nil {
+		r.RecordRedisCommand(command, duration, failed)
+	}
+
+	now := b.now()
+	b.mu.Lock()
+	if now.Sub(b.windowStart) >= b.window {
+		b.closeWindow()
+		b.windowStart = now
+	}
+	b.calls++
+	if failed || (!blocking && duration > b.slow) {
+		b.over++
+	}
+	b.mu.Unlock()
+}
+
+// closeWindow updates the degraded flag from the finished window's counts
+// and resets them. Callers hold mu.
+func (b *errorBudget) closeWindow() {
+	calls, over := b.calls, b.over
+	b.calls, b.over = 0, 0
+	if calls < minBudgetSamples {
+		return
+	}
+
+	degraded := float64(over)/float64(calls) > b.budget
+	if b.degraded.Swap(degraded) == degraded {
+		return
+	}
+	if r := currentRecorder(); r != nil {
+		r.SetRedisDegraded(degraded)
+	}
+	if degraded {
+		log.Warn().
+			Int("commands", calls).
+			Int("failed_or_slow", over).
+			Dur("slow_threshold", b.slow).
+			Msg("Redis degraded: error budget exceeded")
+	} else {
+		log.Info().Int("commands", calls).Msg("Redis recovered: back within error budget")
+	}
+}
+
+// isFailure reports whether err is a Redis failure. A missing key is a
+// result, and a caller cancelling its own request says nothing about Redis.
+func isFailure(err error) bool {
+	return err != nil && !errors.Is(err, redis.Nil) && !errors.Is(err, context.Canceled)
+}
+
+// blockingCommands wait for data to arrive, up to their timeout
+var blockingCommands = map[string]bool{
+	"blpop": true, "brpop": true, "brpoplpush": true, "blmove": true, "blmpop": true,
+	"bzpopmin": true, "bzpopmax": true, "bzmpop": true,
+}
+
+// isBlocking reports whether cmd waits for data: a blocking list or sorted
+// set pop, or a stream read with BLOCK
+func isBlocking(cmd redis.Cmder) bool {
+	name := strings.ToLower(cmd.Name())
+	if blockingCommands[name] {
+		return true
+	}
+	if name != "xread" && name != "xreadgroup" {
+		return false
+	}
+	for _, arg := range cmd.Args() {
+		if s, ok := arg.(string); ok && strings.EqualFold(s, "block") {
+			return true
+		}
+	}
+	return false
+}
+
+// DialHook implements redis.Hook
+func (b *errorBudget) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+// ProcessHook implements redis.Hook, timing each command
+func (b *errorBudget) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmd)
+		b.observe(strings.ToLower(cmd.Name()), time.Since(start), isBlocking(cmd), err)
+		return err
+	}
+}
+
+// ProcessPipelineHook implements redis.Hook, timing a pipeline as one command
+func (b *errorBudget) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmds)
+		b.observe(pipelineCommand, time.Since(start), false, err)
+		return err
+	}
+}
@@ -0,0 +1,149 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type mockRecorder struct {
+	mu       sync.Mutex
+	commands map[string]int
+	failures map[string]int
+	degraded []bool
+}
+
+func (m *mockRecorder) RecordRedisCommand(command string, duration time.Duration, failed bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.commands == nil {
+		m.commands = make(map[string]int)
+		m.failures = make(map[string]int)
+	}
+	m.commands[command]++
+	if failed {
+		m.failures[command]++
+	}
+}
+
+func (m *mockRecorder) SetRedisDegraded(degraded bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.degraded = append(m.degraded, degraded)
+}
+
+func TestErrorBudget_Degraded(t *testing.T) {
+	rec := &mockRecorder{}
+	SetRecorder(rec)
+	defer SetRecorder(nil)
+
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	b := newErrorBudget(&ClientConfig{})
+	b.now = func() time.Time { return now }
+	b.windowStart = now
+
+	// runWindow observes n commands, over of them slow, then starts the next
+	// window so the finished one is evaluated
+	runWindow := func(n, over int) {
+		for i := 0; i < n; i++ {
+			d := time.Millisecond
+			if i < over {
+				d = time.Second
+			}
+			b.observe("get", d, false, nil)
+		}
+		now = now.Add(DefaultBudgetWindow)
+		b.observe("get", time.Millisecond, false, nil)
+		b.calls, b.over = 0, 0
+	}
+
+	runWindow(40, 2)
+	if b.degraded.Load() {
+		t.Fatal("expected 5% slow commands to be within budget")
+	}
+	runWindow(40, 3)
+	if !b.degraded.Load() {
+		t.Fatal("expected over 5% slow commands to degrade")
+	}
+	runWindow(10, 10)
+	if !b.degraded.Load() {
+		t.Error("expected a window below the minimum samples to keep the flag")
+	}
+	runWindow(40, 0)
+	if b.degraded.Load() {
+		t.Error("expected a window within budget to recover")
+	}
+	if len(rec.degraded) != 2 || !rec.degraded[0] || rec.degraded[1] {
+		t.Errorf("expected degraded then recovered recorded, got %v", rec.degraded)
+	}
+}
+
+func TestErrorBudget_WhatCounts(t *testing.T) {
+	b := newErrorBudget(&ClientConfig{})
+	b.observe("xreadgroup", 5*time.Second, true, redis.Nil)
+	b.observe("get", time.Millisecond, false, context.Canceled)
+	if b.over != 0 {
+		t.Errorf("expected blocking waits, missing keys and cancelled calls not to count, got %d", b.over)
+	}
+	b.observe("get", time.Millisecond, false, context.DeadlineExceeded)
+	b.observe("set", time.Millisecond, false, errors.New("READONLY"))
+	if b.over != 2 {
+		t.Errorf("expected timeouts and errors to count, got %d", b.over)
+	}
+}
+
+func TestIsBlocking(t *testing.T) {
+	tests := []struct {
+		cmd  redis.Cmder
+		want bool
+	}{
+		{redis.NewCmd(context.Background(), "get", "k"), false},
+		{redis.NewCmd(context.Background(), "BLPOP", "k", 1), true},
+		{redis.NewCmd(context.Background(), "xreadgroup", "group", "g", "c", "block", 5000, "streams", "s", ">"), true},
+		{redis.NewCmd(context.Background(), "xreadgroup", "group", "g", "c", "streams", "s", ">"), false},
+	}
+	for _, tt := range tests {
+		if got := isBlocking(tt.cmd); got != tt.want {
+			t.Errorf("%v: expected %v, got %v", tt.cmd.Args(), tt.want, got)
+		}
+	}
+}
+
+func TestClient_RecordsCommands(t *testing.T) {
+	mr, redisURL := setupTestRedis(t)
+	rec := &mockRecorder{}
+	SetRecorder(rec)
+	defer SetRecorder(nil)
+
+	client, err := New(redisURL)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	if err := client.Set(ctx, "k", "v", 0); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if _, err := client.Get(ctx, "missing"); err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if rec.commands["set"] != 1 || rec.commands["get"] != 1 || rec.failures["get"] != 0 {
+		t.Errorf("expected set and get recorded without failures, got %v / %v", rec.commands, rec.failures)
+	}
+	if client.Degraded() {
+		t.Error("expected a healthy client")
+	}
+
+	mr.Close()
+	if _, err := client.Get(ctx, "k"); err == nil {
+		t.Fatal("expected an error with Redis down")
+	}
+	if rec.failures["get"] != 1 {
+		t.Errorf("expected the failed get recorded, got %v", rec.failures)
+	}
+}
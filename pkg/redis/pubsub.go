@@ -0,0 +1,47 @@
+package redis
+
+import (
+	"context"
+
+	"github.com/thenexusengine/tne_springwire/pkg/deadline"
+)
+
+// Publish sends a message to every subscriber of a channel
+func (c *Client) Publish(ctx context.Context, channel, message string) error {
+	err := c.client.Publish(ctx, channel, message).Err()
+	return deadline.Observe(ctx, deadline.DependencyRedis, err)
+}
+
+// Subscribe listens on a channel and returns its message payloads. The
+// subscription reconnects on its own and is closed when ctx is cancelled.
+func (c *Client) Subscribe(ctx context.Context, channel string) (<-chan string, error) {
+	ps := c.client.Subscribe(ctx, channel)
+	// Wait for the subscription to be confirmed so publishes aren't missed
+	if _, err := ps.Receive(ctx); err != nil {
+		ps.Close()
+		return nil, err
+	}
+
+	out := make(chan string)
+	go func() {
+		defer close(out)
+		defer ps.Close()
+		msgs := ps.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-msgs:
+				if !ok {
+					return
+				}
+				select {
+				case out <- msg.Payload:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out, nil
+}
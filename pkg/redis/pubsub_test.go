@@ -0,0 +1,48 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPubSub(t *testing.T) {
+	mr, redisURL := setupTestRedis(t)
+	defer mr.Close()
+
+	client, err := New(redisURL)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	msgs, err := client.Subscribe(ctx, "updates")
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+
+	if err := client.Publish(context.Background(), "updates", "hello"); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	select {
+	case msg := <-msgs:
+		if msg != "hello" {
+			t.Errorf("expected hello, got %q", msg)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for message")
+	}
+
+	// Cancelling the context closes the channel
+	cancel()
+	select {
+	case _, ok := <-msgs:
+		if ok {
+			t.Error("expected channel to be closed")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for channel to close")
+	}
+}
@@ -0,0 +1,112 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/thenexusengine/tne_springwire/pkg/deadline"
+)
+
+// StreamMessage is one entry read from a stream
+type StreamMessage struct {
+	ID     string
+	Values map[string]interface{}
+}
+
+// XAdd appends an entry to a stream, trimming it to roughly maxLen entries
+// (0 = untrimmed), and returns the entry ID
+func (c *Client) XAdd(ctx context.Context, stream string, maxLen int64, values map[string]interface{}) (string, error) {
+	args := &redis.XAddArgs{Stream: stream, Values: values}
+	if maxLen > 0 {
+		args.MaxLen = maxLen
+		args.Approx = true
+	}
+	id, err := c.client.XAdd(ctx, args).Result()
+	return id, deadline.Observe(ctx, deadline.DependencyRedis, err)
+}
+
+// XGroupCreate creates a consumer group reading new entries, creating the
+// stream if needed. An existing group is not an error.
+func (c *Client) XGroupCreate(ctx context.Context, stream, group string) error {
+	err := c.client.XGroupCreateMkStream(ctx, stream, group, "$").Err()
+	if err != nil && strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return nil
+	}
+	return deadline.Observe(ctx, deadline.DependencyRedis, err)
+}
+
+// XReadGroup reads up to count new entries for a consumer, blocking up to
+// block for entries to arrive. It returns no messages and a nil error when
+// the block expires.
+func (c *Client) XReadGroup(ctx context.Context, stream, group, consumer string, count int64, block time.Duration) ([]StreamMessage, error) {
+	streams, err := c.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    group,
+		Consumer: consumer,
+		Streams:  []string{stream, ">"},
+		Count:    count,
+		Block:    block,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, deadline.Observe(ctx, deadline.DependencyRedis, err)
+	}
+
+	var messages []StreamMessage
+	for _, s := range streams {
+		for _, m := range s.Messages {
+			messages = append(messages, StreamMessage{ID: m.ID, Values: m.Values})
+		}
+	}
+	return messages, nil
+}
+
+// XAck acknowledges processed entries for a consumer group
+func (c *Client) XAck(ctx context.Context, stream, group string, ids ...string) error {
+	return deadline.Observe(ctx, deadline.DependencyRedis, c.client.XAck(ctx, stream, group, ids...).Err())
+}
+
+// XAutoClaim takes over up to count entries that have been pending in the
+// group for at least minIdle, e.g. because their consumer crashed
+func (c *Client) XAutoClaim(ctx context.Context, stream, group, consumer string, minIdle time.Duration, count int64) ([]StreamMessage, error) {
+	msgs, _, err := c.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+		Stream:   stream,
+		Group:    group,
+		Consumer: consumer,
+		MinIdle:  minIdle,
+		Start:    "0-0",
+		Count:    count,
+	}).Result()
+	if err != nil {
+		return nil, deadline.Observe(ctx, deadline.DependencyRedis, err)
+	}
+
+	messages := make([]StreamMessage, 0, len(msgs))
+	for _, m := range msgs {
+		messages = append(messages, StreamMessage{ID: m.ID, Values: m.Values})
+	}
+	return messages, nil
+}
+
+// XDeliveries returns how many times a pending entry has been delivered to
+// consumers of the group, or 0 when it is not pending
+func (c *Client) XDeliveries(ctx context.Context, stream, group, id string) (int64, error) {
+	pending, err := c.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: stream,
+		Group:  group,
+		Start:  id,
+		End:    id,
+		Count:  1,
+	}).Result()
+	if err != nil {
+		return 0, deadline.Observe(ctx, deadline.DependencyRedis, err)
+	}
+	if len(pending) == 0 {
+		return 0, nil
+	}
+	return pending[0].RetryCount, nil
+}
@@ -0,0 +1,69 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestStreams_ConsumerGroup(t *testing.T) {
+	mr, redisURL := setupTestRedis(t)
+	defer mr.Close()
+
+	client, err := New(redisURL)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	if err := client.XGroupCreate(ctx, "events", "workers"); err != nil {
+		t.Fatalf("XGroupCreate failed: %v", err)
+	}
+	// Creating the group again is not an error
+	if err := client.XGroupCreate(ctx, "events", "workers"); err != nil {
+		t.Fatalf("XGroupCreate on existing group failed: %v", err)
+	}
+
+	id, err := client.XAdd(ctx, "events", 1000, map[string]interface{}{"event": "win"})
+	if err != nil || id == "" {
+		t.Fatalf("XAdd failed: %q %v", id, err)
+	}
+
+	msgs, err := client.XReadGroup(ctx, "events", "workers", "c1", 10, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("XReadGroup failed: %v", err)
+	}
+	if len(msgs) != 1 || msgs[0].ID != id || msgs[0].Values["event"] != "win" {
+		t.Fatalf("unexpected messages: %+v", msgs)
+	}
+
+	// Unacknowledged entries can be claimed by another consumer
+	claimed, err := client.XAutoClaim(ctx, "events", "workers", "c2", 0, 10)
+	if err != nil {
+		t.Fatalf("XAutoClaim failed: %v", err)
+	}
+	if len(claimed) != 1 || claimed[0].ID != id {
+		t.Fatalf("expected pending entry to be claimed, got %+v", claimed)
+	}
+	if n, err := client.XDeliveries(ctx, "events", "workers", id); err != nil || n != 2 {
+		t.Errorf("expected 2 deliveries after claim, got %d %v", n, err)
+	}
+
+	if err := client.XAck(ctx, "events", "workers", id); err != nil {
+		t.Fatalf("XAck failed: %v", err)
+	}
+	claimed, err = client.XAutoClaim(ctx, "events", "workers", "c2", 0, 10)
+	if err != nil || len(claimed) != 0 {
+		t.Errorf("expected nothing pending after ack, got %+v %v", claimed, err)
+	}
+	if n, _ := client.XDeliveries(ctx, "events", "workers", id); n != 0 {
+		t.Errorf("expected no deliveries for acknowledged entry, got %d", n)
+	}
+
+	// Empty read returns after the block timeout
+	msgs, err = client.XReadGroup(ctx, "events", "workers", "c1", 10, 10*time.Millisecond)
+	if err != nil || len(msgs) != 0 {
+		t.Errorf("expected empty read, got %+v %v", msgs, err)
+	}
+}
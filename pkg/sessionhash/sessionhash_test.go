@@ -0,0 +1,60 @@
+package sessionhash
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHasher_Deterministic(t *testing.T) {
+	a := New(Config{Salt: "s1"})
+	b := New(Config{Salt: "s1"})
+
+	for _, id := range []string{"session-1", "session-2", "ifa-3"} {
+		if a.Bucket("exp", id, 10) != b.Bucket("exp", id, 10) {
+			t.Errorf("expected replicas with the same salt to agree on %q", id)
+		}
+		if a.Key("cap", id) != b.Key("cap", id) || len(a.Key("cap", id)) != 16 {
+			t.Errorf("expected a stable 16-character key for %q, got %q", id, a.Key("cap", id))
+		}
+	}
+	if a.Sum("exp", "session-1") == a.Sum("other", "session-1") {
+		t.Error("expected namespaces to hash independently")
+	}
+	if a.Sum("exp", "session-1") == New(Config{Salt: "s2"}).Sum("exp", "session-1") {
+		t.Error("expected the salt to change the hash")
+	}
+}
+
+func TestHasher_Rotation(t *testing.T) {
+	rotateAt := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	h := New(Config{Salt: "old", NextSalt: "new", RotateAt: rotateAt})
+	old, next := New(Config{Salt: "old"}), New(Config{Salt: "new"})
+
+	h.now = func() time.Time { return rotateAt.Add(-time.Second) }
+	if h.Sum("exp", "s") != old.Sum("exp", "s") {
+		t.Error("expected the current salt before RotateAt")
+	}
+	h.now = func() time.Time { return rotateAt }
+	if h.Sum("exp", "s") != next.Sum("exp", "s") {
+		t.Error("expected the next salt from RotateAt on")
+	}
+}
+
+func TestHasher_Sample(t *testing.T) {
+	var h *Hasher // nil hashers are unsalted
+	sampled := 0
+	for i := 0; i < 10000; i++ {
+		if h.Sample("tail", string(rune('a'+i%26))+time.Duration(i).String(), 0.25) {
+			sampled++
+		}
+	}
+	if sampled < 2200 || sampled > 2800 {
+		t.Errorf("expected about 25%% sampled, got %d of 10000", sampled)
+	}
+	if h.Sample("tail", "s", 0) || !h.Sample("tail", "s", 1) {
+		t.Error("expected rates of 0 and 1 to sample none and all")
+	}
+	if h.Bucket("exp", "s", 1) != 0 {
+		t.Error("expected a single bucket to be 0")
+	}
+}
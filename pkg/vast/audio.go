@@ -0,0 +1,63 @@
+package vast
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// DefaultAudioMIMEType is used for audio ads when the impression lists no
+// MIME types
+const DefaultAudioMIMEType = "audio/mpeg"
+
+// daastDocument is a DAAST (Digital Audio Ad Serving Template) document.
+// DAAST 1.0 shares VAST 3's ad structure under a <DAAST> root.
+type daastDocument struct {
+	XMLName xml.Name `xml:"DAAST"`
+	Version string   `xml:"version,attr"`
+	Ads     []Ad     `xml:"Ad"`
+	Error   string   `xml:"Error,omitempty"`
+}
+
+// parseDAAST reads a DAAST document as VAST
+func parseDAAST(data []byte) (*VAST, error) {
+	var d daastDocument
+	if err := xml.Unmarshal(data, &d); err != nil {
+		return nil, fmt.Errorf("failed to parse DAAST: %w", err)
+	}
+	return &VAST{Version: d.Version, Ads: d.Ads, Error: d.Error}, nil
+}
+
+// rootElement returns the name of the document's root element, or ""
+func rootElement(data []byte) string {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return ""
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local
+		}
+	}
+}
+
+// IsAudioMIMEType reports whether mimeType is an audio media type
+func IsAudioMIMEType(mimeType string) bool {
+	return strings.HasPrefix(strings.ToLower(mimeType), "audio/")
+}
+
+// IsAudio reports whether the linear creative's media files are all audio
+func (v *VAST) IsAudio() bool {
+	files := v.GetMediaFiles()
+	if len(files) == 0 {
+		return false
+	}
+	for _, mf := range files {
+		if !IsAudioMIMEType(mf.Type) {
+			return false
+		}
+	}
+	return true
+}
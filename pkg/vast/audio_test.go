@@ -0,0 +1,91 @@
+package vast
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDAAST(t *testing.T) {
+	daastXML := `<?xml version="1.0" encoding="UTF-8"?>
+<DAAST version="1.0">
+  <Ad id="audio-1">
+    <InLine>
+      <AdSystem>TNEVideo</AdSystem>
+      <AdTitle>Audio Spot</AdTitle>
+      <Impression><![CDATA[https://example.com/impression]]></Impression>
+      <Creatives>
+        <Creative id="creative-1">
+          <Linear>
+            <Duration>00:00:15</Duration>
+            <MediaFiles>
+              <MediaFile delivery="progressive" type="audio/mpeg" bitrate="128"><![CDATA[https://example.com/spot.mp3]]></MediaFile>
+            </MediaFiles>
+          </Linear>
+        </Creative>
+      </Creatives>
+    </InLine>
+  </Ad>
+</DAAST>`
+
+	v, err := Parse([]byte(daastXML))
+	if err != nil {
+		t.Fatalf("Failed to parse DAAST: %v", err)
+	}
+	if v.Version != "1.0" || len(v.Ads) != 1 || v.Ads[0].ID != "audio-1" {
+		t.Fatalf("Unexpected document: %+v", v)
+	}
+	if !v.IsAudio() {
+		t.Error("Expected an audio document")
+	}
+	if result := v.Validate(); !result.Valid {
+		t.Errorf("Expected audio media files without dimensions to be valid, got %v", result.Errors)
+	}
+}
+
+func TestBuilder_Audio(t *testing.T) {
+	v, err := NewBuilder("4.0").
+		AddAd("audio-ad").
+		WithInLine("TNEVideo", "Audio Ad").
+		WithImpression("https://example.com/impression").
+		WithLinearCreative("creative-1", 30*time.Second).
+		WithAudioMediaFile("https://example.com/spot.aac", "audio/aac", WithBitrate(64)).
+		EndLinear().
+		Done().
+		Build()
+	if err != nil {
+		t.Fatalf("Failed to build VAST: %v", err)
+	}
+	if !v.IsAudio() {
+		t.Error("Expected an audio document")
+	}
+
+	data, err := v.Marshal()
+	if err != nil {
+		t.Fatalf("Failed to marshal VAST: %v", err)
+	}
+	if strings.Contains(string(data), "width=") || strings.Contains(string(data), "height=") {
+		t.Errorf("Expected audio media files without dimensions, got %s", data)
+	}
+	if result := v.Validate(); !result.Valid {
+		t.Errorf("Expected a valid document, got %v", result.Errors)
+	}
+}
+
+func TestIsAudio_Video(t *testing.T) {
+	v, _ := NewBuilder("4.0").
+		AddAd("video-ad").
+		WithInLine("TNEVideo", "Video Ad").
+		WithLinearCreative("creative-1", 30*time.Second).
+		WithMediaFile("https://example.com/video.mp4", "video/mp4", 1920, 1080).
+		WithAudioMediaFile("https://example.com/spot.mp3", DefaultAudioMIMEType).
+		EndLinear().
+		Done().
+		Build()
+	if v.IsAudio() {
+		t.Error("Expected a document with video files not to be audio")
+	}
+	if CreateEmptyVAST().IsAudio() {
+		t.Error("Expected an empty document not to be audio")
+	}
+}
@@ -34,6 +34,15 @@ func (b *Builder) AddAd(id string) *Builder {
 	return b
 }
 
+// WithSequence sets the current ad's position within an ad pod
+func (b *Builder) WithSequence(sequence int) *Builder {
+	if b.err != nil || b.current == nil {
+		return b
+	}
+	b.current.Sequence = sequence
+	return b
+}
+
 // WithInLine sets the current ad as an inline ad
 func (b *Builder) WithInLine(adSystem, adTitle string) *Builder {
 	if b.err != nil || b.current == nil {
@@ -91,6 +100,27 @@ func (b *Builder) WithError(url string) *Builder {
 	return b
 }
 
+// WithVerification adds Open Measurement verification resources to the
+// current ad
+func (b *Builder) WithVerification(verifications ...Verification) *Builder {
+	if b.err != nil || b.current == nil || len(verifications) == 0 {
+		return b
+	}
+	var target **AdVerifications
+	if b.current.InLine != nil {
+		target = &b.current.InLine.AdVerifications
+	} else if b.current.Wrapper != nil {
+		target = &b.current.Wrapper.AdVerifications
+	} else {
+		return b
+	}
+	if *target == nil {
+		*target = &AdVerifications{}
+	}
+	(*target).Verification = append((*target).Verification, verifications...)
+	return b
+}
+
 // WithLinearCreative adds a linear creative to the current ad
 func (b *Builder) WithLinearCreative(id string, duration time.Duration) *LinearBuilder {
 	if b.err != nil || b.current == nil {
@@ -170,6 +200,11 @@ func (lb *LinearBuilder) WithMediaFile(url, mimeType string, width, height int,
 	return lb
 }
 
+// WithAudioMediaFile adds an audio media file, which has no dimensions
+func (lb *LinearBuilder) WithAudioMediaFile(url, mimeType string, opts ...MediaFileOption) *LinearBuilder {
+	return lb.WithMediaFile(url, mimeType, 0, 0, opts...)
+}
+
 // MediaFileOption is a function that modifies a MediaFile
 type MediaFileOption func(*MediaFile)
 
@@ -1,8 +1,12 @@
 package vast
 
 import (
+	"bytes"
+	"encoding/xml"
 	"fmt"
+	"io"
 	"net/url"
+	"strconv"
 	"strings"
 )
 
@@ -219,12 +223,15 @@ func validateMediaFile(mf *MediaFile, prefix string, result *ValidationResult) {
 		result.AddError(prefix+".type", "Invalid MIME type")
 	}
 
-	if mf.Width <= 0 {
-		result.AddError(prefix+".width", "width must be greater than 0")
-	}
+	// Audio files have no dimensions
+	if !IsAudioMIMEType(mf.Type) {
+		if mf.Width <= 0 {
+			result.AddError(prefix+".width", "width must be greater than 0")
+		}
 
-	if mf.Height <= 0 {
-		result.AddError(prefix+".height", "height must be greater than 0")
+		if mf.Height <= 0 {
+			result.AddError(prefix+".height", "height must be greater than 0")
+		}
 	}
 
 	if mf.Value == "" {
@@ -335,8 +342,8 @@ func isValidMIMEType(mimeType string) bool {
 		}
 	}
 
-	// Also accept any video/* type
-	return strings.HasPrefix(mimeType, "video/")
+	// Also accept any video/* or audio/* type
+	return strings.HasPrefix(mimeType, "video/") || IsAudioMIMEType(mimeType)
 }
 
 func isValidEventType(event string) bool {
@@ -364,3 +371,132 @@ func isValidEventType(event string) bool {
 	}
 	return validEvents[event]
 }
+
+// ValidateDocument checks serialized VAST against the rules of the target
+// version: the XML must be well-formed with a VAST root, carry that version,
+// pass Validate, and use the spec's time formats. An empty document is the
+// spec's no-fill response and passes.
+func ValidateDocument(data []byte, version string) *ValidationResult {
+	result := &ValidationResult{Valid: true}
+
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		if _, err := decoder.Token(); err == io.EOF {
+			break
+		} else if err != nil {
+			result.AddError("VAST", fmt.Sprintf("malformed XML: %v", err))
+			return result
+		}
+	}
+	if root := rootElement(data); root != "VAST" {
+		result.AddError("VAST", fmt.Sprintf("root element must be VAST, got %q", root))
+		return result
+	}
+
+	var v VAST
+	if err := xml.Unmarshal(data, &v); err != nil {
+		result.AddError("VAST", fmt.Sprintf("failed to parse: %v", err))
+		return result
+	}
+	if version != "" && v.Version != version {
+		result.AddError("VAST.version", fmt.Sprintf("expected version %s, got %s", version, v.Version))
+	}
+	if v.IsEmpty() && v.Error == "" {
+		if !isValidVersion(v.Version) {
+			result.AddError("VAST.version", fmt.Sprintf("unsupported version: %s", v.Version))
+		}
+		return result
+	}
+
+	for _, err := range v.Validate().Errors {
+		result.AddError(err.Field, err.Message)
+	}
+
+	// Pods and skippable linears arrived in VAST 3.0
+	pre3 := v.Version == "1.0" || v.Version == "2.0"
+	for i, ad := range v.Ads {
+		prefix := fmt.Sprintf("VAST.Ad[%d]", i)
+		if ad.Sequence != 0 && pre3 {
+			result.AddError(prefix+".sequence", fmt.Sprintf("sequence is not supported in VAST %s", v.Version))
+		}
+		if ad.InLine == nil {
+			continue
+		}
+		for j, creative := range ad.InLine.Creatives.Creative {
+			if creative.Linear == nil {
+				continue
+			}
+			linearPrefix := fmt.Sprintf("%s.InLine.Creative[%d].Linear", prefix, j)
+			if creative.Linear.Duration != "" && !isValidTimecode(string(creative.Linear.Duration)) {
+				result.AddError(linearPrefix+".Duration", fmt.Sprintf("Duration must be HH:MM:SS or HH:MM:SS.mmm, got %q", creative.Linear.Duration))
+			}
+			if offset := creative.Linear.SkipOffset; offset != "" {
+				if pre3 {
+					result.AddError(linearPrefix+".skipoffset", fmt.Sprintf("skipoffset is not supported in VAST %s", v.Version))
+				} else if !isValidTimecode(offset) && !isValidPercentage(offset) {
+					result.AddError(linearPrefix+".skipoffset", fmt.Sprintf("skipoffset must be HH:MM:SS, HH:MM:SS.mmm or n%%, got %q", offset))
+				}
+			}
+		}
+	}
+
+	return result
+}
+
+// AdIndex returns the index of the Ad the error was found in, or -1 for
+// document-level errors
+func (e *ValidationError) AdIndex() int {
+	rest, ok := strings.CutPrefix(e.Field, "VAST.Ad[")
+	if !ok {
+		return -1
+	}
+	end := strings.IndexByte(rest, ']')
+	if end < 0 {
+		return -1
+	}
+	index, err := strconv.Atoi(rest[:end])
+	if err != nil {
+		return -1
+	}
+	return index
+}
+
+// isValidTimecode reports whether s is a VAST time: HH:MM:SS or HH:MM:SS.mmm
+func isValidTimecode(s string) bool {
+	clock, millis, hasMillis := strings.Cut(s, ".")
+	if hasMillis && (len(millis) != 3 || !isDigits(millis)) {
+		return false
+	}
+	parts := strings.Split(clock, ":")
+	if len(parts) != 3 {
+		return false
+	}
+	for i, part := range parts {
+		if len(part) != 2 || !isDigits(part) {
+			return false
+		}
+		if n, _ := strconv.Atoi(part); i > 0 && n > 59 {
+			return false
+		}
+	}
+	return true
+}
+
+// isValidPercentage reports whether s is a VAST percentage offset, 0% to 100%
+func isValidPercentage(s string) bool {
+	digits, ok := strings.CutSuffix(s, "%")
+	if !ok || digits == "" || !isDigits(digits) {
+		return false
+	}
+	n, err := strconv.Atoi(digits)
+	return err == nil && n <= 100
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
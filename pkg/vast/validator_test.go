@@ -1,6 +1,7 @@
 package vast
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -651,3 +652,96 @@ func findSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+func TestValidateDocument(t *testing.T) {
+	build := func(t *testing.T, skipOffset string) []byte {
+		t.Helper()
+		v, err := NewBuilder("4.0").
+			AddAd("bid-1").
+			WithSequence(1).
+			WithInLine("TestSystem", "Test Ad").
+			WithImpression("https://example.com/imp").
+			WithLinearCreative("creative-1", 30*time.Second).
+			WithMediaFile("https://example.com/video.mp4", "video/mp4", 1920, 1080).
+			WithSkipOffset(skipOffset).
+			EndLinear().
+			Done().
+			Build()
+		require.NoError(t, err)
+		data, err := v.Marshal()
+		require.NoError(t, err)
+		return data
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		result := ValidateDocument(build(t, "00:00:05.000"), "4.0")
+		assert.True(t, result.Valid, "unexpected errors: %v", result.Errors)
+
+		result = ValidateDocument(build(t, "25%"), "4.0")
+		assert.True(t, result.Valid, "unexpected errors: %v", result.Errors)
+	})
+
+	t.Run("Empty_no_fill", func(t *testing.T) {
+		data, err := CreateEmptyVAST().Marshal()
+		require.NoError(t, err)
+		assert.True(t, ValidateDocument(data, "4.0").Valid)
+	})
+
+	t.Run("Malformed_XML", func(t *testing.T) {
+		result := ValidateDocument([]byte(`<VAST version="4.0"><Ad></VAST>`), "4.0")
+		assert.False(t, result.Valid)
+		assertHasError(t, result, "VAST")
+	})
+
+	t.Run("Wrong_root", func(t *testing.T) {
+		result := ValidateDocument([]byte(`<DAAST version="1.0"></DAAST>`), "4.0")
+		assert.False(t, result.Valid)
+	})
+
+	t.Run("Version_mismatch", func(t *testing.T) {
+		result := ValidateDocument(build(t, ""), "4.2")
+		assertHasError(t, result, "VAST.version")
+	})
+
+	t.Run("Bad_skipoffset", func(t *testing.T) {
+		result := ValidateDocument(build(t, "00:00:75"), "4.0")
+		assert.False(t, result.Valid)
+		assertHasError(t, result, "VAST.Ad[0].InLine.Creative[0].Linear.skipoffset")
+	})
+
+	t.Run("Bad_duration", func(t *testing.T) {
+		data := strings.Replace(string(build(t, "")), "00:00:30", "30", 1)
+		result := ValidateDocument([]byte(data), "4.0")
+		assertHasError(t, result, "Linear.Duration")
+	})
+
+	t.Run("VAST_2_features", func(t *testing.T) {
+		data := strings.Replace(string(build(t, "00:00:05")), `version="4.0"`, `version="2.0"`, 1)
+		result := ValidateDocument([]byte(data), "2.0")
+		assertHasError(t, result, "VAST.Ad[0].sequence")
+		assertHasError(t, result, "skipoffset")
+	})
+
+	t.Run("Missing_required_element", func(t *testing.T) {
+		data := strings.Replace(string(build(t, "")), "<AdTitle>Test Ad</AdTitle>", "", 1)
+		result := ValidateDocument([]byte(data), "4.0")
+		assertHasError(t, result, "VAST.Ad[0].InLine.AdTitle")
+		assert.Equal(t, 0, result.Errors[0].AdIndex())
+	})
+}
+
+func TestValidationError_AdIndex(t *testing.T) {
+	tests := []struct {
+		field string
+		want  int
+	}{
+		{"VAST.Ad[3].InLine.AdTitle", 3},
+		{"VAST.Ad[12]", 12},
+		{"VAST.version", -1},
+		{"VAST", -1},
+	}
+	for _, tt := range tests {
+		err := ValidationError{Field: tt.field}
+		assert.Equal(t, tt.want, err.AdIndex(), tt.field)
+	}
+}
@@ -18,37 +18,39 @@ type VAST struct {
 
 // Ad represents a single ad in VAST
 type Ad struct {
-	ID       string    `xml:"id,attr,omitempty"`
-	Sequence int       `xml:"sequence,attr,omitempty"`
-	InLine   *InLine   `xml:"InLine,omitempty"`
-	Wrapper  *Wrapper  `xml:"Wrapper,omitempty"`
+	ID       string   `xml:"id,attr,omitempty"`
+	Sequence int      `xml:"sequence,attr,omitempty"`
+	InLine   *InLine  `xml:"InLine,omitempty"`
+	Wrapper  *Wrapper `xml:"Wrapper,omitempty"`
 }
 
 // InLine represents an inline ad
 type InLine struct {
-	AdSystem    AdSystem     `xml:"AdSystem"`
-	AdTitle     string       `xml:"AdTitle"`
-	Description string       `xml:"Description,omitempty"`
-	Advertiser  string       `xml:"Advertiser,omitempty"`
-	Pricing     *Pricing     `xml:"Pricing,omitempty"`
-	Survey      string       `xml:"Survey,omitempty"`
-	Error       string       `xml:"Error,omitempty"`
-	Impressions []Impression `xml:"Impression"`
-	Creatives   Creatives    `xml:"Creatives"`
-	Extensions  *Extensions  `xml:"Extensions,omitempty"`
+	AdSystem        AdSystem         `xml:"AdSystem"`
+	AdTitle         string           `xml:"AdTitle"`
+	Description     string           `xml:"Description,omitempty"`
+	Advertiser      string           `xml:"Advertiser,omitempty"`
+	Pricing         *Pricing         `xml:"Pricing,omitempty"`
+	Survey          string           `xml:"Survey,omitempty"`
+	Error           string           `xml:"Error,omitempty"`
+	Impressions     []Impression     `xml:"Impression"`
+	AdVerifications *AdVerifications `xml:"AdVerifications,omitempty"`
+	Creatives       Creatives        `xml:"Creatives"`
+	Extensions      *Extensions      `xml:"Extensions,omitempty"`
 }
 
 // Wrapper represents a wrapper ad that references another VAST
 type Wrapper struct {
-	AdSystem               AdSystem     `xml:"AdSystem"`
-	VASTAdTagURI           string       `xml:"VASTAdTagURI"`
-	Error                  string       `xml:"Error,omitempty"`
-	Impressions            []Impression `xml:"Impression"`
-	Creatives              Creatives    `xml:"Creatives,omitempty"`
-	Extensions             *Extensions  `xml:"Extensions,omitempty"`
-	FollowAdditionalWraps  bool         `xml:"followAdditionalWrappers,attr,omitempty"`
-	AllowMultipleAds       bool         `xml:"allowMultipleAds,attr,omitempty"`
-	FallbackOnNoAd         bool         `xml:"fallbackOnNoAd,attr,omitempty"`
+	AdSystem              AdSystem         `xml:"AdSystem"`
+	VASTAdTagURI          string           `xml:"VASTAdTagURI"`
+	Error                 string           `xml:"Error,omitempty"`
+	Impressions           []Impression     `xml:"Impression"`
+	AdVerifications       *AdVerifications `xml:"AdVerifications,omitempty"`
+	Creatives             Creatives        `xml:"Creatives,omitempty"`
+	Extensions            *Extensions      `xml:"Extensions,omitempty"`
+	FollowAdditionalWraps bool             `xml:"followAdditionalWrappers,attr,omitempty"`
+	AllowMultipleAds      bool             `xml:"allowMultipleAds,attr,omitempty"`
+	FallbackOnNoAd        bool             `xml:"fallbackOnNoAd,attr,omitempty"`
 }
 
 // AdSystem identifies the ad server
@@ -77,14 +79,14 @@ type Creatives struct {
 
 // Creative represents a single creative
 type Creative struct {
-	ID               string            `xml:"id,attr,omitempty"`
-	AdID             string            `xml:"adId,attr,omitempty"`
-	Sequence         int               `xml:"sequence,attr,omitempty"`
-	APIFramework     string            `xml:"apiFramework,attr,omitempty"`
-	Linear           *Linear           `xml:"Linear,omitempty"`
-	NonLinearAds     *NonLinearAds     `xml:"NonLinearAds,omitempty"`
-	CompanionAds     *CompanionAds     `xml:"CompanionAds,omitempty"`
-	UniversalAdId    *UniversalAdId    `xml:"UniversalAdId,omitempty"`
+	ID                 string              `xml:"id,attr,omitempty"`
+	AdID               string              `xml:"adId,attr,omitempty"`
+	Sequence           int                 `xml:"sequence,attr,omitempty"`
+	APIFramework       string              `xml:"apiFramework,attr,omitempty"`
+	Linear             *Linear             `xml:"Linear,omitempty"`
+	NonLinearAds       *NonLinearAds       `xml:"NonLinearAds,omitempty"`
+	CompanionAds       *CompanionAds       `xml:"CompanionAds,omitempty"`
+	UniversalAdId      *UniversalAdId      `xml:"UniversalAdId,omitempty"`
 	CreativeExtensions *CreativeExtensions `xml:"CreativeExtensions,omitempty"`
 }
 
@@ -97,13 +99,13 @@ type UniversalAdId struct {
 
 // Linear represents a linear (video) creative
 type Linear struct {
-	SkipOffset    string         `xml:"skipoffset,attr,omitempty"`
-	Duration      Duration       `xml:"Duration"`
-	AdParameters  *AdParameters  `xml:"AdParameters,omitempty"`
-	MediaFiles    MediaFiles     `xml:"MediaFiles"`
+	SkipOffset     string         `xml:"skipoffset,attr,omitempty"`
+	Duration       Duration       `xml:"Duration"`
+	AdParameters   *AdParameters  `xml:"AdParameters,omitempty"`
+	MediaFiles     MediaFiles     `xml:"MediaFiles"`
 	TrackingEvents TrackingEvents `xml:"TrackingEvents,omitempty"`
-	VideoClicks   *VideoClicks   `xml:"VideoClicks,omitempty"`
-	Icons         *Icons         `xml:"Icons,omitempty"`
+	VideoClicks    *VideoClicks   `xml:"VideoClicks,omitempty"`
+	Icons          *Icons         `xml:"Icons,omitempty"`
 }
 
 // Duration represents a time duration in HH:MM:SS format
@@ -146,8 +148,8 @@ type MediaFile struct {
 	Bitrate             int    `xml:"bitrate,attr,omitempty"`
 	MinBitrate          int    `xml:"minBitrate,attr,omitempty"`
 	MaxBitrate          int    `xml:"maxBitrate,attr,omitempty"`
-	Width               int    `xml:"width,attr"`
-	Height              int    `xml:"height,attr"`
+	Width               int    `xml:"width,attr,omitempty"` // 0 for audio
+	Height              int    `xml:"height,attr,omitempty"`
 	Scalable            bool   `xml:"scalable,attr,omitempty"`
 	MaintainAspectRatio bool   `xml:"maintainAspectRatio,attr,omitempty"`
 	Codec               string `xml:"codec,attr,omitempty"`
@@ -193,9 +195,9 @@ const (
 
 // VideoClicks contains click tracking elements
 type VideoClicks struct {
-	ClickThrough  *ClickThrough  `xml:"ClickThrough,omitempty"`
+	ClickThrough  *ClickThrough   `xml:"ClickThrough,omitempty"`
 	ClickTracking []ClickTracking `xml:"ClickTracking,omitempty"`
-	CustomClick   []CustomClick  `xml:"CustomClick,omitempty"`
+	CustomClick   []CustomClick   `xml:"CustomClick,omitempty"`
 }
 
 // ClickThrough represents the click-through URL
@@ -223,20 +225,20 @@ type Icons struct {
 
 // Icon represents an icon overlay
 type Icon struct {
-	Program       string         `xml:"program,attr,omitempty"`
-	Width         int            `xml:"width,attr,omitempty"`
-	Height        int            `xml:"height,attr,omitempty"`
-	XPosition     string         `xml:"xPosition,attr,omitempty"`
-	YPosition     string         `xml:"yPosition,attr,omitempty"`
-	Duration      string         `xml:"duration,attr,omitempty"`
-	Offset        string         `xml:"offset,attr,omitempty"`
-	APIFramework  string         `xml:"apiFramework,attr,omitempty"`
-	PxRatio       string         `xml:"pxratio,attr,omitempty"`
-	StaticResource *StaticResource `xml:"StaticResource,omitempty"`
-	IFrameResource string         `xml:"IFrameResource,omitempty"`
-	HTMLResource   *HTMLResource  `xml:"HTMLResource,omitempty"`
-	IconClicks     *IconClicks    `xml:"IconClicks,omitempty"`
-	IconViewTracking []string     `xml:"IconViewTracking,omitempty"`
+	Program          string          `xml:"program,attr,omitempty"`
+	Width            int             `xml:"width,attr,omitempty"`
+	Height           int             `xml:"height,attr,omitempty"`
+	XPosition        string          `xml:"xPosition,attr,omitempty"`
+	YPosition        string          `xml:"yPosition,attr,omitempty"`
+	Duration         string          `xml:"duration,attr,omitempty"`
+	Offset           string          `xml:"offset,attr,omitempty"`
+	APIFramework     string          `xml:"apiFramework,attr,omitempty"`
+	PxRatio          string          `xml:"pxratio,attr,omitempty"`
+	StaticResource   *StaticResource `xml:"StaticResource,omitempty"`
+	IFrameResource   string          `xml:"IFrameResource,omitempty"`
+	HTMLResource     *HTMLResource   `xml:"HTMLResource,omitempty"`
+	IconClicks       *IconClicks     `xml:"IconClicks,omitempty"`
+	IconViewTracking []string        `xml:"IconViewTracking,omitempty"`
 }
 
 // StaticResource represents a static resource
@@ -265,21 +267,21 @@ type NonLinearAds struct {
 
 // NonLinear represents a non-linear ad (overlay)
 type NonLinear struct {
-	ID              string          `xml:"id,attr,omitempty"`
-	Width           int             `xml:"width,attr"`
-	Height          int             `xml:"height,attr"`
-	ExpandedWidth   int             `xml:"expandedWidth,attr,omitempty"`
-	ExpandedHeight  int             `xml:"expandedHeight,attr,omitempty"`
-	Scalable        bool            `xml:"scalable,attr,omitempty"`
-	MaintainAspect  bool            `xml:"maintainAspectRatio,attr,omitempty"`
-	MinSuggestedDur string          `xml:"minSuggestedDuration,attr,omitempty"`
-	APIFramework    string          `xml:"apiFramework,attr,omitempty"`
-	StaticResource  *StaticResource `xml:"StaticResource,omitempty"`
-	IFrameResource  string          `xml:"IFrameResource,omitempty"`
-	HTMLResource    *HTMLResource   `xml:"HTMLResource,omitempty"`
-	AdParameters    *AdParameters   `xml:"AdParameters,omitempty"`
-	NonLinearClickThrough string    `xml:"NonLinearClickThrough,omitempty"`
-	NonLinearClickTracking []string `xml:"NonLinearClickTracking,omitempty"`
+	ID                     string          `xml:"id,attr,omitempty"`
+	Width                  int             `xml:"width,attr"`
+	Height                 int             `xml:"height,attr"`
+	ExpandedWidth          int             `xml:"expandedWidth,attr,omitempty"`
+	ExpandedHeight         int             `xml:"expandedHeight,attr,omitempty"`
+	Scalable               bool            `xml:"scalable,attr,omitempty"`
+	MaintainAspect         bool            `xml:"maintainAspectRatio,attr,omitempty"`
+	MinSuggestedDur        string          `xml:"minSuggestedDuration,attr,omitempty"`
+	APIFramework           string          `xml:"apiFramework,attr,omitempty"`
+	StaticResource         *StaticResource `xml:"StaticResource,omitempty"`
+	IFrameResource         string          `xml:"IFrameResource,omitempty"`
+	HTMLResource           *HTMLResource   `xml:"HTMLResource,omitempty"`
+	AdParameters           *AdParameters   `xml:"AdParameters,omitempty"`
+	NonLinearClickThrough  string          `xml:"NonLinearClickThrough,omitempty"`
+	NonLinearClickTracking []string        `xml:"NonLinearClickTracking,omitempty"`
 }
 
 // CompanionAds contains companion ad elements
@@ -290,24 +292,24 @@ type CompanionAds struct {
 
 // Companion represents a companion ad
 type Companion struct {
-	ID                string          `xml:"id,attr,omitempty"`
-	Width             int             `xml:"width,attr"`
-	Height            int             `xml:"height,attr"`
-	AssetWidth        int             `xml:"assetWidth,attr,omitempty"`
-	AssetHeight       int             `xml:"assetHeight,attr,omitempty"`
-	ExpandedWidth     int             `xml:"expandedWidth,attr,omitempty"`
-	ExpandedHeight    int             `xml:"expandedHeight,attr,omitempty"`
-	APIFramework      string          `xml:"apiFramework,attr,omitempty"`
-	AdSlotID          string          `xml:"adSlotId,attr,omitempty"`
-	PxRatio           string          `xml:"pxratio,attr,omitempty"`
-	StaticResource    *StaticResource `xml:"StaticResource,omitempty"`
-	IFrameResource    string          `xml:"IFrameResource,omitempty"`
-	HTMLResource      *HTMLResource   `xml:"HTMLResource,omitempty"`
-	AdParameters      *AdParameters   `xml:"AdParameters,omitempty"`
-	AltText           string          `xml:"AltText,omitempty"`
-	CompanionClickThrough  string     `xml:"CompanionClickThrough,omitempty"`
-	CompanionClickTracking []string   `xml:"CompanionClickTracking,omitempty"`
-	TrackingEvents    TrackingEvents  `xml:"TrackingEvents,omitempty"`
+	ID                     string          `xml:"id,attr,omitempty"`
+	Width                  int             `xml:"width,attr"`
+	Height                 int             `xml:"height,attr"`
+	AssetWidth             int             `xml:"assetWidth,attr,omitempty"`
+	AssetHeight            int             `xml:"assetHeight,attr,omitempty"`
+	ExpandedWidth          int             `xml:"expandedWidth,attr,omitempty"`
+	ExpandedHeight         int             `xml:"expandedHeight,attr,omitempty"`
+	APIFramework           string          `xml:"apiFramework,attr,omitempty"`
+	AdSlotID               string          `xml:"adSlotId,attr,omitempty"`
+	PxRatio                string          `xml:"pxratio,attr,omitempty"`
+	StaticResource         *StaticResource `xml:"StaticResource,omitempty"`
+	IFrameResource         string          `xml:"IFrameResource,omitempty"`
+	HTMLResource           *HTMLResource   `xml:"HTMLResource,omitempty"`
+	AdParameters           *AdParameters   `xml:"AdParameters,omitempty"`
+	AltText                string          `xml:"AltText,omitempty"`
+	CompanionClickThrough  string          `xml:"CompanionClickThrough,omitempty"`
+	CompanionClickTracking []string        `xml:"CompanionClickTracking,omitempty"`
+	TrackingEvents         TrackingEvents  `xml:"TrackingEvents,omitempty"`
 }
 
 // Extensions contains extension elements
@@ -332,8 +334,43 @@ type CreativeExtension struct {
 	Value string `xml:",innerxml"`
 }
 
-// Parse parses a VAST XML document
+// AdVerifications contains Open Measurement verification resources (VAST 4.1)
+type AdVerifications struct {
+	Verification []Verification `xml:"Verification"`
+}
+
+// Verification is one measurement vendor's verification script
+type Verification struct {
+	Vendor                 string                  `xml:"vendor,attr,omitempty"`
+	JavaScriptResource     []JavaScriptResource    `xml:"JavaScriptResource,omitempty"`
+	TrackingEvents         *TrackingEvents         `xml:"TrackingEvents,omitempty"`
+	VerificationParameters *VerificationParameters `xml:"VerificationParameters,omitempty"`
+}
+
+// JavaScriptResource is the URL of a verification script
+type JavaScriptResource struct {
+	APIFramework    string `xml:"apiFramework,attr,omitempty"`
+	BrowserOptional bool   `xml:"browserOptional,attr,omitempty"`
+	Value           string `xml:",cdata"`
+}
+
+// VerificationParameters is opaque data passed to the verification script
+type VerificationParameters struct {
+	Value string `xml:",cdata"`
+}
+
+// APIFrameworkOMID is the apiFramework of Open Measurement verification scripts
+const APIFrameworkOMID = "omid"
+
+// EventVerificationNotExecuted is the Verification tracking event fired when
+// the player can't run the vendor's script
+const EventVerificationNotExecuted = "verificationNotExecuted"
+
+// Parse parses a VAST XML document. DAAST audio documents are read as VAST.
 func Parse(data []byte) (*VAST, error) {
+	if rootElement(data) == "DAAST" {
+		return parseDAAST(data)
+	}
 	var v VAST
 	if err := xml.Unmarshal(data, &v); err != nil {
 		return nil, fmt.Errorf("failed to parse VAST: %w", err)
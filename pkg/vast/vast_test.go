@@ -217,3 +217,48 @@ func TestCreateErrorVAST(t *testing.T) {
 		t.Errorf("Expected error URL, got %s", v.Error)
 	}
 }
+
+func TestWithVerification(t *testing.T) {
+	v, err := NewBuilder("4.0").
+		AddAd("ad-1").
+		WithInLine("TNEVideo", "Test").
+		WithVerification(Verification{
+			Vendor: "iabtechlab.com-omid",
+			JavaScriptResource: []JavaScriptResource{{
+				APIFramework:    APIFrameworkOMID,
+				BrowserOptional: true,
+				Value:           "https://verify.example.com/omid.js",
+			}},
+			VerificationParameters: &VerificationParameters{Value: `{"id":1}`},
+		}).
+		Done().
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	data, err := v.Marshal()
+	if err != nil {
+		t.Fatalf("Failed to marshal VAST: %v", err)
+	}
+	parsed, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse marshaled VAST: %v", err)
+	}
+
+	av := parsed.Ads[0].InLine.AdVerifications
+	if av == nil || len(av.Verification) != 1 {
+		t.Fatalf("expected one verification, got %+v", av)
+	}
+	got := av.Verification[0]
+	if got.Vendor != "iabtechlab.com-omid" {
+		t.Errorf("expected vendor iabtechlab.com-omid, got %s", got.Vendor)
+	}
+	if len(got.JavaScriptResource) != 1 || got.JavaScriptResource[0].APIFramework != APIFrameworkOMID ||
+		got.JavaScriptResource[0].Value != "https://verify.example.com/omid.js" {
+		t.Errorf("unexpected script resource: %+v", got.JavaScriptResource)
+	}
+	if got.VerificationParameters == nil || got.VerificationParameters.Value != `{"id":1}` {
+		t.Errorf("unexpected verification parameters: %+v", got.VerificationParameters)
+	}
+}
@@ -0,0 +1,321 @@
+//go:build e2e
+
+// Package e2e runs auction, video, player config and event flows against a
+// real server binary wired to Postgres, Redis, a fake IDR service and two
+// simulated bidders (see docker-compose.yml). Run with `make test-e2e`.
+package e2e
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+const (
+	apiKey      = "e2e-key"
+	publisherID = "e2e-pub"
+
+	// Matches PLAYER_CONFIG_SIGNING_KEY in docker-compose.yml
+	playerSigningSeed = "e2e-player-signing-key-000000000"
+
+	// bidder-b bids above bidder-a
+	winningPrice = 2.75
+)
+
+var (
+	serverURL  = envOrDefault("E2E_SERVER_URL", "http://localhost:18000")
+	idrURL     = envOrDefault("E2E_IDR_URL", "http://localhost:19000")
+	bidderAURL = envOrDefault("E2E_BIDDER_A_URL", "http://localhost:19001")
+	bidderBURL = envOrDefault("E2E_BIDDER_B_URL", "http://localhost:19002")
+
+	client = &http.Client{Timeout: 5 * time.Second}
+)
+
+func envOrDefault(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+func TestMain(m *testing.M) {
+	// The compose healthcheck covers the server; wait for readiness too so
+	// Postgres-backed publisher lookups are up
+	deadline := time.Now().Add(60 * time.Second)
+	for {
+		resp, err := client.Get(serverURL + "/health/ready")
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				break
+			}
+		}
+		if time.Now().After(deadline) {
+			fmt.Fprintf(os.Stderr, "server at %s not ready: %v\n", serverURL, err)
+			os.Exit(1)
+		}
+		time.Sleep(time.Second)
+	}
+	os.Exit(m.Run())
+}
+
+func TestAuction_HighestBidderWins(t *testing.T) {
+	resp := runAuction(t, "e2e-banner-"+strconv.FormatInt(time.Now().UnixNano(), 10))
+
+	bid := winningBid(t, resp)
+	if bid.Price != winningPrice {
+		t.Errorf("expected bidder-b's %.2f to win, got %.2f", winningPrice, bid.Price)
+	}
+	if !strings.Contains(bid.AdM, "e2e ad") {
+		t.Errorf("expected the simulated creative, got %q", bid.AdM)
+	}
+
+	for name, base := range map[string]string{"bidder-a": bidderAURL, "bidder-b": bidderBURL} {
+		if fakeStats(t, base)["bid_requests"] == 0 {
+			t.Errorf("expected %s to receive bid requests", name)
+		}
+	}
+	if fakeStats(t, idrURL)["selections"] == 0 {
+		t.Error("expected the server to ask IDR for bidder selection")
+	}
+}
+
+func TestWinAndBillingNotices_FireBidderURLs(t *testing.T) {
+	before := fakeStats(t, bidderBURL)
+	bid := winningBid(t, runAuction(t, "e2e-win-"+strconv.FormatInt(time.Now().UnixNano(), 10)))
+
+	for _, typ := range []string{"", "billing"} {
+		u := serverURL + "/event/win?bid_id=" + url.QueryEscape(bid.ID)
+		if typ != "" {
+			u += "&type=" + typ
+		}
+		resp := do(t, http.MethodGet, u, nil)
+		if resp.StatusCode != http.StatusNoContent {
+			t.Fatalf("win notice %q: expected 204, got %d", typ, resp.StatusCode)
+		}
+	}
+
+	eventually(t, "bidder-b to receive nurl and burl", func() bool {
+		after := fakeStats(t, bidderBURL)
+		return after["wins"] > before["wins"] && after["billings"] > before["billings"]
+	})
+
+	eventually(t, "win queue to report processed notices", func() bool {
+		return metricValue(t, "pbs_win_queue_events_total", `status="processed"`) >= 2
+	})
+}
+
+func TestVideo_VASTAndOpenRTB(t *testing.T) {
+	q := url.Values{
+		"id":      {"e2e-vast-" + strconv.FormatInt(time.Now().UnixNano(), 10)},
+		"w":       {"1920"},
+		"h":       {"1080"},
+		"site_id": {publisherID},
+		"domain":  {"e2e.example"},
+	}
+	resp := do(t, http.MethodGet, serverURL+"/video/vast?"+q.Encode(), nil)
+	if body := readBody(t, resp); !strings.Contains(body, "cdn.example/ad.mp4") {
+		t.Errorf("expected the simulated VAST creative, got %s", body)
+	}
+
+	req := openrtb.BidRequest{
+		ID:   "e2e-video-" + strconv.FormatInt(time.Now().UnixNano(), 10),
+		Imp:  []openrtb.Imp{{ID: "1", Video: &openrtb.Video{Mimes: []string{"video/mp4"}, W: 1920, H: 1080, MinDuration: 5, MaxDuration: 30}}},
+		Site: &openrtb.Site{Domain: "e2e.example", Publisher: &openrtb.Publisher{ID: publisherID}},
+		TMax: 500,
+	}
+	resp = do(t, http.MethodPost, serverURL+"/video/openrtb", req)
+	if body := readBody(t, resp); !strings.Contains(body, "cdn.example/ad.mp4") {
+		t.Errorf("expected the simulated VAST creative, got %s", body)
+	}
+}
+
+func TestVideoEvents_Deduplicated(t *testing.T) {
+	u := serverURL + "/api/v1/video/event?event=start&bid_id=e2e-dedup-" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	before := metricValue(t, "pbs_video_events_deduplicated_total", `event="start"`)
+	for i := 0; i < 2; i++ {
+		resp := do(t, http.MethodGet, u, nil)
+		readBody(t, resp)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected 200 for the tracking pixel, got %d", resp.StatusCode)
+		}
+	}
+	if after := metricValue(t, "pbs_video_events_deduplicated_total", `event="start"`); after != before+1 {
+		t.Errorf("expected the repeat start pixel to be deduplicated, got %v -> %v", before, after)
+	}
+}
+
+func TestPlayerConfig_PauseAdsSigned(t *testing.T) {
+	resp, err := client.Get(serverURL + "/video/config?pub=" + publisherID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := []byte(readBody(t, resp))
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", resp.StatusCode, body)
+	}
+
+	var cfg struct {
+		PublisherID     string `json:"publisher_id"`
+		PauseAdsEnabled bool   `json:"pause_ads_enabled"`
+	}
+	if err := json.Unmarshal(body, &cfg); err != nil || cfg.PublisherID != publisherID || !cfg.PauseAdsEnabled {
+		t.Errorf("expected pause ads enabled for %s, got %s", publisherID, body)
+	}
+
+	keyID, sig, _ := strings.Cut(resp.Header.Get("X-Config-Signature"), ".")
+	raw, err := base64.RawURLEncoding.DecodeString(sig)
+	public := ed25519.NewKeyFromSeed([]byte(playerSigningSeed)).Public().(ed25519.PublicKey)
+	if keyID != "e2e" || err != nil || !ed25519.Verify(public, body, raw) {
+		t.Errorf("expected a valid e2e signature, got %q", resp.Header.Get("X-Config-Signature"))
+	}
+}
+
+func TestEventsAndMetrics(t *testing.T) {
+	// IDR events are sent in batches of 100, two per auction here
+	for i := 0; i < 60; i++ {
+		runAuction(t, fmt.Sprintf("e2e-batch-%d-%d", time.Now().UnixNano(), i))
+	}
+	eventually(t, "IDR to receive an event batch", func() bool {
+		return fakeStats(t, idrURL)["events"] >= 100
+	})
+
+	checks := []struct{ name, labels string }{
+		{"pbs_auctions_total", ""},
+		{"pbs_bidder_requests_total", `bidder="sim_a"`},
+		{"pbs_bidder_requests_total", `bidder="sim_b"`},
+		{"pbs_auction_registry_records_total", `status="written"`},
+	}
+	for _, c := range checks {
+		c := c
+		eventually(t, c.name+"{"+c.labels+"}", func() bool {
+			return metricValue(t, c.name, c.labels) > 0
+		})
+	}
+}
+
+func runAuction(t *testing.T, id string) *openrtb.BidResponse {
+	t.Helper()
+	req := openrtb.BidRequest{
+		ID:   id,
+		Imp:  []openrtb.Imp{{ID: "1", Banner: &openrtb.Banner{W: 300, H: 250}}},
+		Site: &openrtb.Site{Domain: "e2e.example", Page: "https://e2e.example/", Publisher: &openrtb.Publisher{ID: publisherID}},
+		TMax: 500,
+	}
+	resp := do(t, http.MethodPost, serverURL+"/openrtb2/auction", req)
+	body := readBody(t, resp)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("auction %s: expected 200, got %d: %s", id, resp.StatusCode, body)
+	}
+	var br openrtb.BidResponse
+	if err := json.Unmarshal([]byte(body), &br); err != nil {
+		t.Fatalf("auction %s: invalid response: %v", id, err)
+	}
+	return &br
+}
+
+func winningBid(t *testing.T, resp *openrtb.BidResponse) openrtb.Bid {
+	t.Helper()
+	if len(resp.SeatBid) == 0 || len(resp.SeatBid[0].Bid) == 0 {
+		t.Fatalf("expected a winning bid, got %+v", resp)
+	}
+	return resp.SeatBid[0].Bid[0]
+}
+
+func do(t *testing.T, method, u string, body interface{}) *http.Response {
+	t.Helper()
+	var r io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, u, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("X-API-Key", apiKey)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, u, err)
+	}
+	return resp
+}
+
+func readBody(t *testing.T, resp *http.Response) string {
+	t.Helper()
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// fakeStats returns the request counts of a fake IDR service or bidder
+func fakeStats(t *testing.T, base string) map[string]int {
+	t.Helper()
+	resp, err := client.Get(base + "/fake/stats")
+	if err != nil {
+		t.Fatalf("fake stats %s: %v", base, err)
+	}
+	defer resp.Body.Close()
+	counts := make(map[string]int)
+	if err := json.NewDecoder(resp.Body).Decode(&counts); err != nil {
+		t.Fatalf("fake stats %s: %v", base, err)
+	}
+	return counts
+}
+
+// metricValue sums the samples of a metric whose labels contain labels
+func metricValue(t *testing.T, name, labels string) float64 {
+	t.Helper()
+	resp := do(t, http.MethodGet, serverURL+"/metrics", nil)
+	defer resp.Body.Close()
+
+	var total float64
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, name+"{") && !strings.HasPrefix(line, name+" ") {
+			continue
+		}
+		if labels != "" && !strings.Contains(line, labels) {
+			continue
+		}
+		fields := strings.Fields(line)
+		if v, err := strconv.ParseFloat(fields[len(fields)-1], 64); err == nil {
+			total += v
+		}
+	}
+	return total
+}
+
+// eventually polls cond for up to 15 seconds
+func eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(15 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+}
@@ -0,0 +1,163 @@
+// Command fakes serves the stand-ins the e2e harness runs the server
+// against: a fake IDR service (-role=idr) or a simulated OpenRTB bidder
+// (-role=bidder). Both count what they receive and report the counts at
+// GET /fake/stats so tests can assert on the server's outbound traffic.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/pkg/idr"
+)
+
+// stats counts requests by kind
+type stats struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (s *stats) inc(kind string, n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counts[kind] += n
+}
+
+func (s *stats) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.counts)
+}
+
+func main() {
+	role := flag.String("role", "bidder", "idr or bidder")
+	addr := flag.String("addr", ":9000", "listen address")
+	price := flag.Float64("price", 1.0, "bidder CPM")
+	host := flag.String("host", "localhost:9000", "bidder host used in nurl/burl")
+	flag.Parse()
+
+	st := &stats{counts: make(map[string]int)}
+	mux := http.NewServeMux()
+	mux.Handle("/fake/stats", st)
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"ok"}`))
+	})
+
+	switch *role {
+	case "idr":
+		registerIDR(mux, st)
+	case "bidder":
+		registerBidder(mux, st, *price, *host)
+	default:
+		log.Fatalf("unknown role %q", *role)
+	}
+
+	log.Printf("fake %s listening on %s", *role, *addr)
+	log.Fatal(http.ListenAndServe(*addr, mux))
+}
+
+// registerIDR selects only the simulated bidders (sim_*) so the server never
+// calls real SSPs, and accepts event batches
+func registerIDR(mux *http.ServeMux, st *stats) {
+	mux.HandleFunc("/internal/select", func(w http.ResponseWriter, r *http.Request) {
+		var req idr.SelectPartnersRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		st.inc("selections", 1)
+
+		resp := idr.SelectPartnersResponse{Mode: "normal"}
+		for _, code := range req.AvailableBidders {
+			if strings.HasPrefix(code, "sim_") {
+				resp.SelectedBidders = append(resp.SelectedBidders, idr.SelectedBidder{BidderCode: code, Score: 1, Confidence: 1, Reason: "ANCHOR"})
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	})
+
+	mux.HandleFunc("/api/events", func(w http.ResponseWriter, r *http.Request) {
+		var batch struct {
+			Events []idr.BidEvent `json:"events"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		st.inc("event_batches", 1)
+		st.inc("events", len(batch.Events))
+		w.WriteHeader(http.StatusOK)
+	})
+
+	mux.HandleFunc("/api/config", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	})
+}
+
+// registerBidder bids price on every imp, with VAST for video imps, and
+// counts win and billing notices
+func registerBidder(mux *http.ServeMux, st *stats, price float64, host string) {
+	mux.HandleFunc("/openrtb2", func(w http.ResponseWriter, r *http.Request) {
+		var req openrtb.BidRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		st.inc("bid_requests", 1)
+
+		bids := make([]openrtb.Bid, 0, len(req.Imp))
+		for i, imp := range req.Imp {
+			bid := openrtb.Bid{
+				ID:      fmt.Sprintf("%s-%d", req.ID, i),
+				ImpID:   imp.ID,
+				Price:   price,
+				CRID:    "e2e-creative",
+				ADomain: []string{"advertiser.example"},
+				NURL:    "http://" + host + "/win?price=${AUCTION_PRICE}",
+				BURL:    "http://" + host + "/bill?price=${AUCTION_PRICE}",
+			}
+			if imp.Video != nil {
+				bid.AdM = vastMarkup(bid.ID)
+			} else {
+				bid.AdM = `<div>e2e ad</div>`
+				if imp.Banner != nil {
+					bid.W, bid.H = imp.Banner.W, imp.Banner.H
+				}
+			}
+			bids = append(bids, bid)
+		}
+
+		resp := openrtb.BidResponse{
+			ID:      req.ID,
+			Cur:     "USD",
+			SeatBid: []openrtb.SeatBid{{Bid: bids}},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	})
+
+	mux.HandleFunc("/win", func(w http.ResponseWriter, r *http.Request) {
+		st.inc("wins", 1)
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/bill", func(w http.ResponseWriter, r *http.Request) {
+		st.inc("billings", 1)
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func vastMarkup(id string) string {
+	return `<VAST version="4.0"><Ad id="` + id + `"><InLine><AdSystem>e2e</AdSystem><AdTitle>e2e</AdTitle>` +
+		`<Impression><![CDATA[https://tracker.example/imp]]></Impression><Creatives><Creative><Linear>` +
+		`<Duration>00:00:15</Duration><MediaFiles><MediaFile delivery="progressive" type="video/mp4" width="1920" height="1080">` +
+		`<![CDATA[https://cdn.example/ad.mp4]]></MediaFile></MediaFiles></Linear></Creative></Creatives></InLine></Ad></VAST>`
+}
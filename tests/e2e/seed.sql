@@ -0,0 +1,9 @@
+-- Publisher used by the e2e suite; runs after the deployment migrations
+INSERT INTO publishers (publisher_id, name, allowed_domains, bidder_params, player_config)
+VALUES (
+    'e2e-pub',
+    'E2E Publisher',
+    '*',
+    '{"sim_a": {}, "sim_b": {}}',
+    '{"pause_ads_enabled": true}'
+);